@@ -0,0 +1,138 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	adminCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(adminCmd)
+
+	adminRescheduleCmd.Flags().StringSlice("ring", []string{}, "The ID of a ring whose pending work to reschedule. Accepts multiple values.")
+	adminRescheduleCmd.Flags().StringSlice("installation-group", []string{}, "The ID of an installation group whose pending work to reschedule. Accepts multiple values.")
+	adminRescheduleCmd.Flags().String("position", "", "Move the work to the front or the back of the queue.")
+	adminRescheduleCmd.Flags().Int("priority", 0, "Set the work priority explicitly instead of a position. Higher priorities are worked on first.")
+
+	adminSchemaCmd.Flags().String("format", "json", "The output format: json, or dot or mermaid for the entity relationship diagram of the schema.")
+
+	adminCmd.AddCommand(adminReloadConfigCmd)
+	adminCmd.AddCommand(adminRescheduleCmd)
+	adminCmd.AddCommand(adminSchemaCmd)
+}
+
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Administer the elrond server.",
+}
+
+var adminReloadConfigCmd = &cobra.Command{
+	Use:   "reload-config",
+	Short: "Reload the configuration file of the server, like sending it SIGHUP.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		if err := client.ReloadConfig(); err != nil {
+			return errors.Wrap(err, "failed to reload server configuration")
+		}
+
+		return nil
+	},
+}
+
+var adminRescheduleCmd = &cobra.Command{
+	Use:   "reschedule",
+	Short: "Change the order in which the supervisors work on pending rings and installation groups, without changing their states.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		ringIDs, _ := command.Flags().GetStringSlice("ring")
+		installationGroupIDs, _ := command.Flags().GetStringSlice("installation-group")
+		position, _ := command.Flags().GetString("position")
+		var priority *int
+		if command.Flags().Changed("priority") {
+			value, _ := command.Flags().GetInt("priority")
+			priority = &value
+		}
+
+		request := &model.RescheduleWorkRequest{}
+		for _, ringID := range ringIDs {
+			request.Items = append(request.Items, &model.RescheduleWorkItem{Type: model.TypeRing, ID: ringID, Priority: priority, Position: position})
+		}
+		for _, installationGroupID := range installationGroupIDs {
+			request.Items = append(request.Items, &model.RescheduleWorkItem{Type: model.TypeInstallationGroup, ID: installationGroupID, Priority: priority, Position: position})
+		}
+		if err := request.Validate(); err != nil {
+			return errors.Wrap(err, "invalid reschedule request")
+		}
+
+		client := newClient(command, serverAddress)
+
+		rescheduledWork, err := client.RescheduleWork(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to reschedule work")
+		}
+
+		return printJSON(rescheduledWork)
+	},
+}
+
+var adminSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the schema version, tables, row counts and index health of the database of the server.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		format, _ := command.Flags().GetString("format")
+
+		client := newClient(command, serverAddress)
+
+		schema, err := client.GetDatabaseSchema()
+		if err != nil {
+			return errors.Wrap(err, "failed to query database schema")
+		}
+
+		return printDatabaseSchema(schema, format)
+	},
+}
+
+// printDatabaseSchema prints the given database schema as JSON, or its
+// entity relationship diagram in the given format.
+func printDatabaseSchema(schema *model.DatabaseSchema, format string) error {
+	if format == "json" {
+		return printJSON(schema)
+	}
+
+	rendered, err := schema.Render(format)
+	if err != nil {
+		return err
+	}
+	fmt.Print(rendered)
+
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/mattermost/elrond/internal/metrics"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	alertsCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(alertsCmd)
+
+	alertsGenerateCmd.Flags().StringSlice("metrics-labeled-rings", []string{}, "The ring names the server uses as metric labels, as set by its --metrics-labeled-rings. Stuck release alerts are only generated for these rings.")
+	alertsGenerateCmd.Flags().Int("default-soak-time", model.DefaultSoakTime, "The soak time in seconds of the server, used for the installation groups that do not set one.")
+	alertsGenerateCmd.Flags().Int("installation-group-release-allowance", 3600, "The time in seconds the release of an installation group is expected to take at most, excluding its soak.")
+	alertsGenerateCmd.Flags().Float64("failure-rate-threshold", 0.25, "The ratio of failed releases of a ring above which an alert fires.")
+	alertsGenerateCmd.Flags().Int("failure-rate-window", 7*24*3600, "The time in seconds over which the failure rate of the ring releases is computed.")
+	alertsGenerateCmd.Flags().Int("supervisor-stall-threshold", 600, "The time in seconds after which a supervisor that has not completed a cycle is considered stalled.")
+
+	alertsCmd.AddCommand(alertsGenerateCmd)
+}
+
+var alertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "Generate Prometheus alerting rules for the elrond metrics.",
+}
+
+var alertsGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Print Prometheus alerting rules for stuck releases, release failure rates and stalled supervisors, tailored to the current rings.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		labeledRings, _ := command.Flags().GetStringSlice("metrics-labeled-rings")
+		defaultSoakTime, _ := command.Flags().GetInt("default-soak-time")
+		releaseAllowance, _ := command.Flags().GetInt("installation-group-release-allowance")
+		failureRateThreshold, _ := command.Flags().GetFloat64("failure-rate-threshold")
+		failureRateWindow, _ := command.Flags().GetInt("failure-rate-window")
+		supervisorStallThreshold, _ := command.Flags().GetInt("supervisor-stall-threshold")
+		if releaseAllowance <= 0 || failureRateWindow <= 0 || supervisorStallThreshold <= 0 {
+			return errors.New("release allowance, failure rate window and supervisor stall threshold must be positive")
+		}
+		if failureRateThreshold <= 0 || failureRateThreshold > 1 {
+			return errors.New("failure rate threshold must be between 0 and 1")
+		}
+
+		client := newClient(command, serverAddress)
+
+		rings, err := client.GetRings(&model.GetRingsRequest{PerPage: model.AllPerPage})
+		if err != nil {
+			return errors.Wrap(err, "failed to query rings")
+		}
+
+		rules := metrics.NewAlertRules(rings, metrics.AlertRuleOptions{
+			LabeledRings:                      labeledRings,
+			SoakTimeDefaults:                  model.SoakTimeDefaults{Server: defaultSoakTime},
+			InstallationGroupReleaseAllowance: time.Duration(releaseAllowance) * time.Second,
+			FailureRateThreshold:              failureRateThreshold,
+			FailureRateWindow:                 time.Duration(failureRateWindow) * time.Second,
+			SupervisorStallThreshold:          time.Duration(supervisorStallThreshold) * time.Second,
+		})
+
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err = encoder.Encode(rules); err != nil {
+			return errors.Wrap(err, "failed to print alerting rules")
+		}
+
+		return encoder.Close()
+	},
+}
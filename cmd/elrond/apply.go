@@ -0,0 +1,68 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	applyCmd.Flags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	applyCmd.Flags().String("file", "", "The JSON file holding the fleet spec to apply.")
+	applyCmd.Flags().Bool("confirm", false, "Make the changes instead of only printing them.")
+	applyCmd.MarkFlagRequired("file") //nolint
+	addAPITokenFlag(applyCmd)
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Reconcile the rings and installation groups with a declarative fleet spec.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		file, _ := command.Flags().GetString("file")
+		confirm, _ := command.Flags().GetBool("confirm")
+
+		specData, err := os.ReadFile(file)
+		if err != nil {
+			return errors.Wrap(err, "failed to read fleet spec")
+		}
+		if err = model.ValidateRequestJSON(model.SchemaFleetSpec, specData); err != nil {
+			return errors.Wrap(err, "invalid fleet spec")
+		}
+
+		spec, err := model.NewFleetSpecFromReader(bytes.NewReader(specData))
+		if err != nil {
+			return err
+		}
+
+		plan, err := client.Apply(spec, confirm)
+		if err != nil {
+			return errors.Wrap(err, "failed to apply fleet spec")
+		}
+
+		if err = printJSON(plan); err != nil {
+			return errors.Wrap(err, "failed to print apply plan")
+		}
+		if rejected := plan.Rejected(); len(rejected) > 0 {
+			return errors.Errorf("the provisioner would reject %d releases", len(rejected))
+		}
+
+		return nil
+	},
+}
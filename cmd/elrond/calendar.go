@@ -0,0 +1,87 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	calendarCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(calendarCmd)
+
+	calendarCmd.Flags().String("from", "", "The RFC3339 time the calendar starts at. Defaults to two weeks ago.")
+	calendarCmd.Flags().String("to", "", "The RFC3339 time the calendar ends at. Defaults to two weeks from now.")
+	calendarCmd.Flags().Bool("ical", false, "Whether to print the calendar in the iCalendar format instead of JSON.")
+}
+
+var calendarCmd = &cobra.Command{
+	Use:   "calendar",
+	Short: "Show the past, ongoing and planned releases, freeze windows and scheduled deletions of the rings.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		request := &model.GetCalendarRequest{}
+		var err error
+		if request.From, err = getTimeFlag(command, "from"); err != nil {
+			return err
+		}
+		if request.To, err = getTimeFlag(command, "to"); err != nil {
+			return err
+		}
+
+		ical, _ := command.Flags().GetBool("ical")
+		if ical {
+			data, err := client.GetCalendarICal(request)
+			if err != nil {
+				return errors.Wrap(err, "failed to query calendar")
+			}
+			if _, err = os.Stdout.Write(data); err != nil {
+				return errors.Wrap(err, "failed to print calendar")
+			}
+			return nil
+		}
+
+		calendar, err := client.GetCalendar(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to query calendar")
+		}
+
+		if err = printJSON(calendar); err != nil {
+			return errors.Wrap(err, "failed to print calendar response")
+		}
+
+		return nil
+	},
+}
+
+// getTimeFlag returns the time, in milliseconds, of the given RFC3339 time
+// flag, or 0 if it is not set.
+func getTimeFlag(command *cobra.Command, name string) (int64, error) {
+	value, _ := command.Flags().GetString(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid --%s time", name)
+	}
+
+	return t.UnixMilli(), nil
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mattermost/elrond/internal/elrond"
+	"github.com/mattermost/elrond/internal/outbound"
+	"github.com/mattermost/elrond/internal/secrets"
+	"github.com/mattermost/elrond/internal/serverconfig"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// webhookLookupTimeout bounds the DNS resolution of each webhook target.
+const webhookLookupTimeout = 5 * time.Second
+
+// serverCheckReport is the outcome of the startup self-check of the server.
+type serverCheckReport struct {
+	OK     bool
+	Checks []*serverCheck
+}
+
+// serverCheck is the outcome of a single check. Checks that could not run
+// because an earlier one failed are skipped.
+type serverCheck struct {
+	Name    string
+	OK      bool
+	Skipped bool   `json:",omitempty"`
+	Message string `json:",omitempty"`
+}
+
+// add records the outcome of a check, given the error it failed with, if any.
+func (r *serverCheckReport) add(name string, err error) {
+	check := &serverCheck{Name: name, OK: err == nil}
+	if err != nil {
+		check.Message = err.Error()
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// skip records a check that could not run.
+func (r *serverCheckReport) skip(name, reason string) {
+	r.Checks = append(r.Checks, &serverCheck{Name: name, Skipped: true, Message: reason})
+}
+
+// runServerCheck checks that the server can start with its settings: the
+// configuration is valid, the database is reachable with an up to date
+// schema, the provisioner is reachable and accepts its credentials, and the
+// targets of the webhooks resolve. The report is printed, and an error is
+// returned if any check failed.
+func runServerCheck(command *cobra.Command, configErr error) error {
+	flags := command.Flags()
+	report := &serverCheckReport{}
+
+	if configErr == nil {
+		configErr = serverconfig.Validate(flags)
+	}
+	report.add("config", configErr)
+
+	var sqlStore *store.SQLStore
+	if configErr != nil {
+		report.skip("database", "the configuration is invalid")
+		report.skip("schema", "the configuration is invalid")
+	} else {
+		var err error
+		sqlStore, err = checkDatabase(command)
+		report.add("database", err)
+		if sqlStore != nil {
+			report.add("schema", checkSchema(sqlStore))
+		} else {
+			report.skip("schema", "the database is unreachable")
+		}
+	}
+
+	if configErr != nil {
+		report.skip("provisioner", "the configuration is invalid")
+	} else {
+		report.add("provisioner", checkProvisioner(command, sqlStore))
+	}
+
+	switch {
+	case configErr != nil:
+		report.skip("webhooks", "the configuration is invalid")
+	case sqlStore == nil:
+		report.skip("webhooks", "the database is unreachable")
+	default:
+		report.add("webhooks", checkWebhooks(sqlStore))
+	}
+
+	report.OK = true
+	for _, check := range report.Checks {
+		if !check.OK && !check.Skipped {
+			report.OK = false
+		}
+	}
+
+	if err := printJSON(report); err != nil {
+		return err
+	}
+	if !report.OK {
+		return errors.New("server check failed")
+	}
+
+	return nil
+}
+
+// checkDatabase connects to the database, returning the store if it is
+// reachable.
+func checkDatabase(command *cobra.Command) (*store.SQLStore, error) {
+	sqlStore, err := sqlStore(command)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the database")
+	}
+	if _, err = sqlStore.GetCurrentVersion(); err != nil {
+		return nil, errors.Wrap(err, "failed to query the database")
+	}
+
+	return sqlStore, nil
+}
+
+// checkSchema checks that the database schema is supported by this server,
+// with the same rule as when the server starts.
+func checkSchema(sqlStore *store.SQLStore) error {
+	currentVersion, err := sqlStore.GetCurrentVersion()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the schema version")
+	}
+	serverVersion := store.LatestVersion()
+	if currentVersion.LT(serverVersion) || currentVersion.Major != serverVersion.Major {
+		return errors.Errorf("server requires at least schema %s, current is %s", serverVersion, currentVersion)
+	}
+
+	return nil
+}
+
+// checkProvisioner checks that the provisioner is reachable and accepts the
+// credentials stored in the database, if any.
+func checkProvisioner(command *cobra.Command, sqlStore *store.SQLStore) error {
+	// The provisioner client always uses the default transport.
+	transport, err := serverconfig.OutboundSettings(command.Flags()).Transport(outbound.TargetProvisioner)
+	if err != nil {
+		return errors.Wrap(err, "invalid outbound settings")
+	}
+	http.DefaultTransport = transport
+
+	provisionerServer, _ := command.Flags().GetString("provisioner-server")
+	provisioner := elrond.NewElrondProvisioner(elrond.ProvisioningParams{}, logger, provisionerServer)
+
+	credentialsEncryptionKey, _ := command.Flags().GetString("credentials-encryption-key")
+	if credentialsEncryptionKey != "" && sqlStore != nil {
+		credentialsCipher, err := secrets.NewCipher(credentialsEncryptionKey)
+		if err != nil {
+			return errors.Wrap(err, "invalid credentials encryption key")
+		}
+		if err = provisioner.RefreshCredentials(sqlStore, credentialsCipher); err != nil {
+			return errors.Wrap(err, "failed to load provisioner credentials")
+		}
+	}
+
+	return provisioner.CheckConnection()
+}
+
+// checkWebhooks checks that the host of every webhook target resolves.
+func checkWebhooks(sqlStore *store.SQLStore) error {
+	webhooks, err := sqlStore.GetWebhooks(&model.WebhookFilter{PerPage: model.AllPerPage})
+	if err != nil {
+		return errors.Wrap(err, "failed to get webhooks")
+	}
+
+	resolved := make(map[string]bool)
+	var unresolved []string
+	for _, webhook := range webhooks {
+		webhookURL, err := url.Parse(webhook.URL)
+		if err != nil {
+			unresolved = append(unresolved, webhook.URL)
+			continue
+		}
+		host := webhookURL.Hostname()
+		if _, ok := resolved[host]; ok || net.ParseIP(host) != nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), webhookLookupTimeout)
+		_, err = net.DefaultResolver.LookupHost(ctx, host)
+		cancel()
+		resolved[host] = err == nil
+		if err != nil {
+			unresolved = append(unresolved, host)
+		}
+	}
+	if len(unresolved) > 0 {
+		return errors.Errorf("failed to resolve %d webhook targets: %v", len(unresolved), unresolved)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"os"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/spf13/cobra"
+)
+
+// apiTokenEnv is the environment variable providing the default API token.
+const apiTokenEnv = "ELROND_API_TOKEN"
+
+// addAPITokenFlag adds the flag used to authenticate the API client of the
+// given command and its subcommands.
+func addAPITokenFlag(command *cobra.Command) {
+	command.PersistentFlags().String("api-token", os.Getenv(apiTokenEnv), "The API token to authenticate with. Defaults to the "+apiTokenEnv+" environment variable.")
+}
+
+// newClient creates a client to the given elrond server, authenticating with
+// the API token of the command if one is set.
+func newClient(command *cobra.Command, serverAddress string) *model.Client {
+	apiToken, _ := command.Flags().GetString("api-token")
+	if apiToken == "" {
+		return model.NewClient(serverAddress)
+	}
+
+	return model.NewClientWithToken(serverAddress, apiToken)
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	configCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	configCmd.PersistentFlags().Bool("webhooks", false, "Include the webhooks. Both webhooks and notifiers are included when neither is set.")
+	configCmd.PersistentFlags().Bool("notifiers", false, "Include the notification targets of the rings and the notification templates. Both webhooks and notifiers are included when neither is set.")
+	addAPITokenFlag(configCmd)
+
+	configApplyCmd.Flags().String("file", "", "The YAML file holding the delivery configuration to apply.")
+	configApplyCmd.Flags().Bool("prune", false, "Delete the webhooks and notification templates absent from the file, and clear the notifiers of the rings absent from it.")
+	configApplyCmd.Flags().Bool("confirm", false, "Make the changes instead of only printing them.")
+	configApplyCmd.MarkFlagRequired("file") //nolint
+
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configApplyCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Export and apply the configuration of where notifications are delivered.",
+}
+
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print the webhooks and notifiers as reviewable YAML, with secrets referenced rather than inlined.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+		webhooks, notifiers := deliveryConfigSections(command)
+
+		config := &model.DeliveryConfig{}
+		if webhooks {
+			list, err := client.GetWebhooks(&model.GetWebhooksRequest{PerPage: model.AllPerPage})
+			if err != nil {
+				return errors.Wrap(err, "failed to query webhooks")
+			}
+			config.Webhooks = model.NewWebhookConfigs(list)
+		}
+		if notifiers {
+			rings, err := client.GetRings(&model.GetRingsRequest{PerPage: model.AllPerPage})
+			if err != nil {
+				return errors.Wrap(err, "failed to query rings")
+			}
+			templates, err := client.GetNotificationTemplates(&model.GetNotificationTemplatesRequest{PerPage: model.AllPerPage})
+			if err != nil {
+				return errors.Wrap(err, "failed to query notification templates")
+			}
+			config.Notifiers = model.NewNotifiersConfig(rings, templates)
+		}
+
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(config); err != nil {
+			return errors.Wrap(err, "failed to print delivery configuration")
+		}
+
+		return encoder.Close()
+	},
+}
+
+var configApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Reconcile the webhooks and notifiers with an exported delivery configuration.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		file, _ := command.Flags().GetString("file")
+		prune, _ := command.Flags().GetBool("prune")
+		confirm, _ := command.Flags().GetBool("confirm")
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return errors.Wrap(err, "failed to read delivery configuration")
+		}
+		config := &model.DeliveryConfig{}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err = decoder.Decode(config); err != nil {
+			return errors.Wrap(err, "failed to decode delivery configuration")
+		}
+		if err = config.Validate(); err != nil {
+			return errors.Wrap(err, "invalid delivery configuration")
+		}
+
+		webhooks, notifiers := deliveryConfigSections(command)
+		if !webhooks {
+			config.Webhooks = nil
+		}
+		if !notifiers {
+			config.Notifiers = nil
+		}
+
+		var currentWebhooks []*model.Webhook
+		if config.Webhooks != nil {
+			currentWebhooks, err = client.GetWebhooks(&model.GetWebhooksRequest{PerPage: model.AllPerPage})
+			if err != nil {
+				return errors.Wrap(err, "failed to query webhooks")
+			}
+		}
+		var rings []*model.Ring
+		var templates []*model.NotificationTemplate
+		if config.Notifiers != nil {
+			rings, err = client.GetRings(&model.GetRingsRequest{PerPage: model.AllPerPage})
+			if err != nil {
+				return errors.Wrap(err, "failed to query rings")
+			}
+			templates, err = client.GetNotificationTemplates(&model.GetNotificationTemplatesRequest{PerPage: model.AllPerPage})
+			if err != nil {
+				return errors.Wrap(err, "failed to query notification templates")
+			}
+		}
+
+		plan, err := model.PlanDeliveryConfig(config, currentWebhooks, templates, rings, prune)
+		if err != nil {
+			return errors.Wrap(err, "failed to plan delivery configuration")
+		}
+
+		if confirm {
+			// Resolve every secret first, so a missing one changes nothing.
+			secrets := make(map[string]string)
+			for _, change := range plan.Changes {
+				if change.Webhook == nil || change.Webhook.SecretRef == "" {
+					continue
+				}
+				if secrets[change.Webhook.SecretRef], err = resolveSecretRef(change.Webhook.SecretRef); err != nil {
+					return errors.Wrapf(err, "failed to resolve the secret of webhook %s", change.Name)
+				}
+			}
+
+			for _, change := range plan.Changes {
+				if err = applyDeliveryConfigChange(client, change, secrets); err != nil {
+					return errors.Wrapf(err, "failed to %s %s %s", change.Action, change.ResourceType, change.Name)
+				}
+			}
+			plan.Applied = true
+		}
+
+		return printJSON(plan)
+	},
+}
+
+// deliveryConfigSections returns whether the command includes the webhooks
+// and the notifiers, defaulting to both.
+func deliveryConfigSections(command *cobra.Command) (bool, bool) {
+	webhooks, _ := command.Flags().GetBool("webhooks")
+	notifiers, _ := command.Flags().GetBool("notifiers")
+	if !webhooks && !notifiers {
+		return true, true
+	}
+
+	return webhooks, notifiers
+}
+
+// resolveSecretRef reads the secret referenced as env:<variable> or
+// file:<path>.
+func resolveSecretRef(ref string) (string, error) {
+	if name := strings.TrimPrefix(ref, model.SecretRefEnv); name != ref {
+		secret, ok := os.LookupEnv(name)
+		if !ok || secret == "" {
+			return "", errors.Errorf("environment variable %s is not set", name)
+		}
+		return secret, nil
+	}
+
+	path := strings.TrimPrefix(ref, model.SecretRefFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read secret file")
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", errors.Errorf("secret file %s is empty", path)
+	}
+
+	return secret, nil
+}
+
+// applyDeliveryConfigChange makes the given change. Webhooks cannot be
+// updated, and are replaced instead.
+func applyDeliveryConfigChange(client *model.Client, change *model.DeliveryConfigChange, secrets map[string]string) error {
+	switch change.ResourceType {
+	case model.TypeWebhook:
+		if change.Action == model.ApplyActionUpdate || change.Action == model.ApplyActionDelete {
+			if err := client.DeleteWebhook(change.ID); err != nil {
+				return err
+			}
+		}
+		if change.Action == model.ApplyActionCreate || change.Action == model.ApplyActionUpdate {
+			_, err := client.CreateWebhook(change.Webhook.CreateWebhookRequest(secrets[change.Webhook.SecretRef]))
+			return err
+		}
+	case model.TypeRing:
+		_, err := client.UpdateRing(change.ID, change.RingNotifier.UpdateRingRequest())
+		return err
+	case model.TypeNotificationTemplate:
+		switch change.Action {
+		case model.ApplyActionCreate:
+			_, err := client.CreateNotificationTemplate(&model.CreateNotificationTemplateRequest{
+				Channel:  change.NotificationTemplate.Channel,
+				Event:    change.NotificationTemplate.Event,
+				Language: change.NotificationTemplate.Language,
+				Subject:  change.NotificationTemplate.Subject,
+				Body:     change.NotificationTemplate.Body,
+			})
+			return err
+		case model.ApplyActionUpdate:
+			_, err := client.UpdateNotificationTemplate(change.ID, &model.UpdateNotificationTemplateRequest{
+				Subject: &change.NotificationTemplate.Subject,
+				Body:    &change.NotificationTemplate.Body,
+			})
+			return err
+		case model.ApplyActionDelete:
+			return client.DeleteNotificationTemplate(change.ID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	eventCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(eventCmd)
+
+	eventListCmd.Flags().String("ring", "", "The id of the ring whose events to fetch.")
+	eventListCmd.Flags().String("resource-type", "", "The type of the resources whose events to fetch.")
+	eventListCmd.Flags().String("resource-id", "", "The id of the resource whose events to fetch.")
+	eventListCmd.Flags().String("release", "", "The id of the ring release whose events to fetch.")
+	eventListCmd.Flags().String("state", "", "The new state of the events to fetch.")
+	eventListCmd.Flags().String("from", "", "The RFC3339 time to fetch the events from.")
+	eventListCmd.Flags().String("to", "", "The RFC3339 time to fetch the events until.")
+	eventListCmd.Flags().String("after", "", "The cursor returned by a previous page, to resume fetching events after it.")
+	eventListCmd.Flags().Int("per-page", 100, "The number of events to fetch per page.")
+
+	eventCmd.AddCommand(eventListCmd)
+}
+
+var eventCmd = &cobra.Command{
+	Use:   "event",
+	Short: "View the state change events recorded by the elrond server.",
+}
+
+var eventListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List a page of state change events, in chronological order.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		ringID, _ := command.Flags().GetString("ring")
+		resourceType, _ := command.Flags().GetString("resource-type")
+		resourceID, _ := command.Flags().GetString("resource-id")
+		releaseID, _ := command.Flags().GetString("release")
+		state, _ := command.Flags().GetString("state")
+		after, _ := command.Flags().GetString("after")
+		perPage, _ := command.Flags().GetInt("per-page")
+
+		request := &model.GetStateChangeEventsRequest{
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			ReleaseID:    releaseID,
+			State:        state,
+			After:        after,
+			PerPage:      perPage,
+		}
+		var err error
+		if request.From, err = getTimeFlag(command, "from"); err != nil {
+			return err
+		}
+		if request.To, err = getTimeFlag(command, "to"); err != nil {
+			return err
+		}
+
+		var page *model.StateChangeEventsPage
+		if ringID != "" {
+			page, err = client.GetRingStateChangeEvents(ringID, request)
+		} else {
+			page, err = client.GetStateChangeEvents(request)
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to query state change events")
+		}
+
+		if err = printJSON(page); err != nil {
+			return errors.Wrap(err, "failed to print state change events response")
+		}
+
+		return nil
+	},
+}
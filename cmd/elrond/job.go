@@ -0,0 +1,144 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	jobCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(jobCmd)
+
+	jobCreateCmd.Flags().String("type", "", "The type of the job, one of prune or resync.")
+	jobCreateCmd.Flags().StringToString("parameter", map[string]string{}, "A parameter of the job, as <name>=<value>, such as older-than-days=30 for prune jobs. Accepts multiple values.")
+	jobCreateCmd.Flags().Int("max-attempts", model.DefaultJobMaxAttempts, "The number of times the job is attempted before it fails.")
+	jobCreateCmd.MarkFlagRequired("type") //nolint
+
+	jobGetCmd.Flags().String("job", "", "The id of the job to be fetched.")
+	jobGetCmd.MarkFlagRequired("job") //nolint
+
+	jobListCmd.Flags().String("type", "", "Only list the jobs of this type.")
+	jobListCmd.Flags().String("state", "", "Only list the jobs in this state, one of pending, running, succeeded or failed.")
+	jobListCmd.Flags().Int("page", 0, "The page of jobs to fetch, starting at 0.")
+	jobListCmd.Flags().Int("per-page", 100, "The number of jobs to fetch per page.")
+
+	jobCmd.AddCommand(jobCreateCmd)
+	jobCmd.AddCommand(jobGetCmd)
+	jobCmd.AddCommand(jobListCmd)
+}
+
+var jobCmd = &cobra.Command{
+	Use:   "job",
+	Short: "Run long administrative actions as background jobs.",
+}
+
+var jobCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Queue a background job, such as prune or resync.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		jobType, _ := command.Flags().GetString("type")
+		parameters, _ := command.Flags().GetStringToString("parameter")
+		maxAttempts, _ := command.Flags().GetInt("max-attempts")
+
+		request := &model.CreateJobRequest{
+			Type:        jobType,
+			Parameters:  parameters,
+			MaxAttempts: maxAttempts,
+		}
+		if err := request.Validate(); err != nil {
+			return errors.Wrap(err, "invalid request")
+		}
+
+		job, err := client.CreateJob(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to create job")
+		}
+
+		if err = printJSON(job); err != nil {
+			return errors.Wrap(err, "failed to print job response")
+		}
+
+		return nil
+	},
+}
+
+var jobGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get a particular job, with its state and result.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		jobID, _ := command.Flags().GetString("job")
+		job, err := client.GetJob(jobID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query job %s", jobID)
+		}
+		if job == nil {
+			return nil
+		}
+
+		if err = printJSON(job); err != nil {
+			return errors.Wrapf(err, "failed to print job %s response", jobID)
+		}
+
+		return nil
+	},
+}
+
+var jobListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the jobs, newest first.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		jobType, _ := command.Flags().GetString("type")
+		state, _ := command.Flags().GetString("state")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		jobs, err := client.GetJobs(&model.GetJobsRequest{
+			Type:    jobType,
+			State:   state,
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query jobs")
+		}
+
+		if err = printJSON(jobs); err != nil {
+			return errors.Wrap(err, "failed to print jobs response")
+		}
+
+		return nil
+	},
+}
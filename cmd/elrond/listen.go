@@ -0,0 +1,48 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net"
+	"os"
+
+	"github.com/mattermost/elrond/internal/serverconfig"
+	"github.com/pkg/errors"
+)
+
+// listen opens a listener on each of the given --listen values. The listeners
+// already opened are closed if any of them fails.
+func listen(values []string) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(values))
+	closeAll := func() {
+		for _, listener := range listeners {
+			listener.Close() //nolint
+		}
+	}
+
+	for _, value := range values {
+		network, address, err := serverconfig.ParseListenAddress(value)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		if network == "unix" {
+			// A socket left behind by a server that did not shut down
+			// cleanly would otherwise fail the bind.
+			if info, statErr := os.Stat(address); statErr == nil && info.Mode()&os.ModeSocket != 0 {
+				os.Remove(address) //nolint
+			}
+		}
+
+		listener, err := net.Listen(network, address)
+		if err != nil {
+			closeAll()
+			return nil, errors.Wrapf(err, "failed to listen on %s", value)
+		}
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
@@ -29,6 +29,21 @@ func init() {
 	rootCmd.AddCommand(schemaCmd)
 	rootCmd.AddCommand(webhookCmd)
 	rootCmd.AddCommand(securityCmd)
+	rootCmd.AddCommand(tokenCmd)
+	rootCmd.AddCommand(provisionerCmd)
+	rootCmd.AddCommand(eventCmd)
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(operatorCmd)
+	rootCmd.AddCommand(adminCmd)
+	rootCmd.AddCommand(calendarCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(rolloutCmd)
+	rootCmd.AddCommand(alertsCmd)
+	rootCmd.AddCommand(stateMachineCmd)
+	rootCmd.AddCommand(noteCmd)
+	rootCmd.AddCommand(jobCmd)
+	rootCmd.AddCommand(notificationTemplateCmd)
+	rootCmd.AddCommand(configCmd)
 }
 
 func main() {
@@ -0,0 +1,126 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	noteCmd.PersistentFlags().String("ring", "", "The id of the ring whose notes to manage.")
+	noteCmd.PersistentFlags().String("installation-group", "", "The id of the installation group whose notes to manage.")
+	noteCmd.PersistentFlags().String("release", "", "The id of the ring release whose notes to manage.")
+
+	noteAddCmd.Flags().String("message", "", "The text of the note.")
+	noteAddCmd.MarkFlagRequired("message") //nolint
+
+	noteCmd.AddCommand(noteListCmd)
+	noteCmd.AddCommand(noteAddCmd)
+}
+
+var noteCmd = &cobra.Command{
+	Use:   "note",
+	Short: "Manage the operator notes left on rings, installation groups and releases.",
+}
+
+var noteListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the notes of a ring, installation group or release, oldest first.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		resourceType, resourceID, err := getNoteResourceFlags(command)
+		if err != nil {
+			return err
+		}
+
+		notes, err := client.GetNotes(resourceType, resourceID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query notes of %s %s", resourceType, resourceID)
+		}
+
+		if err = printJSON(notes); err != nil {
+			return errors.Wrapf(err, "failed to print notes of %s %s response", resourceType, resourceID)
+		}
+
+		return nil
+	},
+}
+
+var noteAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Leave a note on a ring, installation group or release.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		resourceType, resourceID, err := getNoteResourceFlags(command)
+		if err != nil {
+			return err
+		}
+		message, _ := command.Flags().GetString("message")
+
+		request := &model.NoteRequest{Message: message}
+		if err = request.Validate(); err != nil {
+			return errors.Wrap(err, "invalid request")
+		}
+
+		note, err := client.AddNote(resourceType, resourceID, request)
+		if err != nil {
+			return errors.Wrapf(err, "failed to add note to %s %s", resourceType, resourceID)
+		}
+
+		if err = printJSON(note); err != nil {
+			return errors.Wrapf(err, "failed to print note of %s %s response", resourceType, resourceID)
+		}
+
+		return nil
+	},
+}
+
+// getNoteResourceFlags returns the type and ID of the resource whose notes
+// to manage, exactly one of which must be given.
+func getNoteResourceFlags(command *cobra.Command) (string, string, error) {
+	ringID, _ := command.Flags().GetString("ring")
+	installationGroupID, _ := command.Flags().GetString("installation-group")
+	releaseID, _ := command.Flags().GetString("release")
+
+	var resourceType, resourceID string
+	for _, resource := range []struct{ resourceType, resourceID string }{
+		{model.TypeRing, ringID},
+		{model.TypeInstallationGroup, installationGroupID},
+		{model.TypeRelease, releaseID},
+	} {
+		if resource.resourceID == "" {
+			continue
+		}
+		if resourceID != "" {
+			return "", "", errors.New("only one of --ring, --installation-group and --release can be set")
+		}
+		resourceType, resourceID = resource.resourceType, resource.resourceID
+	}
+	if resourceID == "" {
+		return "", "", errors.New("one of --ring, --installation-group and --release must be set")
+	}
+
+	return resourceType, resourceID, nil
+}
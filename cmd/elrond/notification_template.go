@@ -0,0 +1,269 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+	"os"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	notificationTemplateCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(notificationTemplateCmd)
+
+	notificationTemplateCreateCmd.Flags().String("channel", "", "The channel of the notifications: email or teams.")
+	notificationTemplateCreateCmd.Flags().String("event", "", "The event of the notifications: release-started, release-completed or release-failed for email, state-change for teams.")
+	notificationTemplateCreateCmd.Flags().String("language", "", "The language of the template, e.g. de or pt-BR.")
+	notificationTemplateCreateCmd.Flags().String("subject", "", "The Go template of the email subject or Teams card title. Leave empty to keep the built-in one.")
+	notificationTemplateCreateCmd.Flags().String("body", "", "The Go template of the notification body.")
+	notificationTemplateCreateCmd.Flags().String("body-file", "", "A file holding the Go template of the notification body, instead of --body.")
+	notificationTemplateCreateCmd.MarkFlagRequired("channel")  //nolint
+	notificationTemplateCreateCmd.MarkFlagRequired("event")    //nolint
+	notificationTemplateCreateCmd.MarkFlagRequired("language") //nolint
+
+	notificationTemplateGetCmd.Flags().String("notification-template", "", "The id of the notification template to be fetched.")
+	notificationTemplateGetCmd.MarkFlagRequired("notification-template") //nolint
+
+	notificationTemplateListCmd.Flags().String("channel", "", "The channel by which to filter notification templates.")
+	notificationTemplateListCmd.Flags().String("event", "", "The event by which to filter notification templates.")
+	notificationTemplateListCmd.Flags().String("language", "", "The language by which to filter notification templates.")
+	notificationTemplateListCmd.Flags().Int("page", 0, "The page of notification templates to fetch, starting at 0.")
+	notificationTemplateListCmd.Flags().Int("per-page", 100, "The number of notification templates to fetch per page.")
+	notificationTemplateListCmd.Flags().Bool("table", false, "Whether to display the returned notification template list in a table or not")
+
+	notificationTemplateUpdateCmd.Flags().String("notification-template", "", "The id of the notification template to be updated.")
+	notificationTemplateUpdateCmd.Flags().String("subject", "", "The Go template of the email subject or Teams card title.")
+	notificationTemplateUpdateCmd.Flags().String("body", "", "The Go template of the notification body.")
+	notificationTemplateUpdateCmd.Flags().String("body-file", "", "A file holding the Go template of the notification body, instead of --body.")
+	notificationTemplateUpdateCmd.MarkFlagRequired("notification-template") //nolint
+
+	notificationTemplateDeleteCmd.Flags().String("notification-template", "", "The id of the notification template to be deleted.")
+	notificationTemplateDeleteCmd.MarkFlagRequired("notification-template") //nolint
+
+	notificationTemplateCmd.AddCommand(notificationTemplateCreateCmd)
+	notificationTemplateCmd.AddCommand(notificationTemplateGetCmd)
+	notificationTemplateCmd.AddCommand(notificationTemplateListCmd)
+	notificationTemplateCmd.AddCommand(notificationTemplateUpdateCmd)
+	notificationTemplateCmd.AddCommand(notificationTemplateDeleteCmd)
+}
+
+var notificationTemplateCmd = &cobra.Command{
+	Use:   "notification-template",
+	Short: "Manage the localized templates of the email and Teams notifications.",
+}
+
+var notificationTemplateCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a notification template.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		channel, _ := command.Flags().GetString("channel")
+		event, _ := command.Flags().GetString("event")
+		language, _ := command.Flags().GetString("language")
+		subject, _ := command.Flags().GetString("subject")
+		body, err := getNotificationTemplateBodyFlag(command)
+		if err != nil {
+			return err
+		}
+
+		request := &model.CreateNotificationTemplateRequest{
+			Channel:  channel,
+			Event:    event,
+			Language: language,
+			Subject:  subject,
+			Body:     body,
+		}
+		if err = request.Validate(); err != nil {
+			return errors.Wrap(err, "invalid request")
+		}
+
+		notificationTemplate, err := client.CreateNotificationTemplate(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to create notification template")
+		}
+
+		if err = printJSON(notificationTemplate); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var notificationTemplateGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get a particular notification template.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		notificationTemplateID, _ := command.Flags().GetString("notification-template")
+		notificationTemplate, err := client.GetNotificationTemplate(notificationTemplateID)
+		if err != nil {
+			return errors.Wrap(err, "failed to query notification template")
+		}
+		if notificationTemplate == nil {
+			return nil
+		}
+
+		if err = printJSON(notificationTemplate); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var notificationTemplateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List notification templates.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		channel, _ := command.Flags().GetString("channel")
+		event, _ := command.Flags().GetString("event")
+		language, _ := command.Flags().GetString("language")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		notificationTemplates, err := client.GetNotificationTemplates(&model.GetNotificationTemplatesRequest{
+			Channel:  channel,
+			Event:    event,
+			Language: language,
+			Page:     page,
+			PerPage:  perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query notification templates")
+		}
+
+		outputToTable, _ := command.Flags().GetBool("table")
+		if outputToTable {
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetAlignment(tablewriter.ALIGN_LEFT)
+			table.SetHeader([]string{"ID", "CHANNEL", "EVENT", "LANGUAGE", "SUBJECT"})
+
+			for _, notificationTemplate := range notificationTemplates {
+				table.Append([]string{notificationTemplate.ID, notificationTemplate.Channel, notificationTemplate.Event, notificationTemplate.Language, notificationTemplate.Subject})
+			}
+			table.Render()
+
+			return nil
+		}
+
+		if err = printJSON(notificationTemplates); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var notificationTemplateUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the subject or body of a notification template.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		notificationTemplateID, _ := command.Flags().GetString("notification-template")
+		request := &model.UpdateNotificationTemplateRequest{}
+		if command.Flags().Changed("subject") {
+			subject, _ := command.Flags().GetString("subject")
+			request.Subject = &subject
+		}
+		if command.Flags().Changed("body") || command.Flags().Changed("body-file") {
+			body, err := getNotificationTemplateBodyFlag(command)
+			if err != nil {
+				return err
+			}
+			request.Body = &body
+		}
+
+		notificationTemplate, err := client.UpdateNotificationTemplate(notificationTemplateID, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to update notification template")
+		}
+
+		if err = printJSON(notificationTemplate); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var notificationTemplateDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a notification template. Its notifications are sent with the built-in text again.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		notificationTemplateID, _ := command.Flags().GetString("notification-template")
+		if err := client.DeleteNotificationTemplate(notificationTemplateID); err != nil {
+			return errors.Wrap(err, "failed to delete notification template")
+		}
+
+		return nil
+	},
+}
+
+// getNotificationTemplateBodyFlag returns the body of the notification
+// template, given inline with --body or read from --body-file.
+func getNotificationTemplateBodyFlag(command *cobra.Command) (string, error) {
+	body, _ := command.Flags().GetString("body")
+	bodyFile, _ := command.Flags().GetString("body-file")
+	if bodyFile == "" {
+		return body, nil
+	}
+	if body != "" {
+		return "", errors.New("only one of --body and --body-file can be set")
+	}
+
+	data, err := os.ReadFile(bodyFile)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read %s", bodyFile)
+	}
+
+	return string(data), nil
+}
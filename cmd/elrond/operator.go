@@ -0,0 +1,74 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mattermost/elrond/internal/operator"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func init() {
+	operatorCmd.Flags().String("server", defaultLocalServerAPI, "The elrond server to mirror the custom resources into.")
+	operatorCmd.Flags().String("kubeconfig", "", "The kubeconfig of the cluster to watch. Defaults to the in-cluster configuration.")
+	operatorCmd.Flags().String("namespace", "", "The namespace to watch. Defaults to every namespace.")
+	operatorCmd.Flags().Duration("resync", 5*time.Minute, "The interval at which every resource is reconciled again.")
+	addAPITokenFlag(operatorCmd)
+}
+
+var operatorCmd = &cobra.Command{
+	Use:   "operator",
+	Short: "Mirror Ring and RingRelease custom resources of a Kubernetes cluster into elrond.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		kubeconfig, _ := command.Flags().GetString("kubeconfig")
+		namespace, _ := command.Flags().GetString("namespace")
+		resync, _ := command.Flags().GetDuration("resync")
+
+		var config *rest.Config
+		var err error
+		if kubeconfig == "" {
+			config, err = rest.InClusterConfig()
+		} else {
+			config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to load kubernetes configuration")
+		}
+
+		dynamicClient, err := dynamic.NewForConfig(config)
+		if err != nil {
+			return errors.Wrap(err, "failed to create kubernetes client")
+		}
+
+		stop := make(chan struct{})
+		go func() {
+			c := make(chan os.Signal, 1)
+			signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+			sig := <-c
+			logger.WithField("shutdown-signal", sig.String()).Info("Shutting down")
+			close(stop)
+		}()
+
+		logger.WithFields(map[string]interface{}{"server": serverAddress, "namespace": namespace}).Info("Starting elrond operator")
+
+		return operator.NewOperator(dynamicClient, newClient(command, serverAddress), namespace, resync, logger).Run(stop)
+	},
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	provisionerCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(provisionerCmd)
+
+	provisionerCredentialsRotateCmd.Flags().StringArray("header", []string{}, "A header to authenticate against the provisioner with, as Name=Value. Accepts multiple values.")
+	provisionerCredentialsRotateCmd.MarkFlagRequired("header") //nolint
+
+	provisionerCredentialsCmd.AddCommand(provisionerCredentialsRotateCmd)
+	provisionerCredentialsCmd.AddCommand(provisionerCredentialsGetCmd)
+	provisionerCmd.AddCommand(provisionerCredentialsCmd)
+}
+
+var provisionerCmd = &cobra.Command{
+	Use:   "provisioner",
+	Short: "Manage the provisioner integration of the elrond server.",
+}
+
+var provisionerCredentialsCmd = &cobra.Command{
+	Use:   "credentials",
+	Short: "Manage the credentials used to authenticate against the provisioner.",
+}
+
+var provisionerCredentialsRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Rotate the provisioner credentials without restarting the server.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		headerFlags, _ := command.Flags().GetStringArray("header")
+		headers := make(map[string]string, len(headerFlags))
+		for _, header := range headerFlags {
+			parts := strings.SplitN(header, "=", 2)
+			if len(parts) != 2 {
+				return errors.Errorf("invalid header %q, expected Name=Value", header)
+			}
+			headers[parts[0]] = parts[1]
+		}
+
+		credentials, err := client.RotateProvisionerCredentials(&model.RotateProvisionerCredentialsRequest{
+			Headers: headers,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to rotate provisioner credentials")
+		}
+
+		if err = printJSON(credentials); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var provisionerCredentialsGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get the metadata of the provisioner credentials in use.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		credentials, err := client.GetProvisionerCredentials()
+		if err != nil {
+			return errors.Wrap(err, "failed to query provisioner credentials")
+		}
+		if credentials == nil {
+			return nil
+		}
+
+		if err = printJSON(credentials); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
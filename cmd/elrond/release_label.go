@@ -0,0 +1,90 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	ringReleaseLabelsCmd.PersistentFlags().String("release", "", "The id of the release whose labels to manage.")
+	ringReleaseLabelsCmd.MarkPersistentFlagRequired("release") //nolint
+
+	ringReleaseLabelsAddCmd.Flags().StringArray("label", []string{}, "A label to add, such as security-patch. Accepts multiple values.")
+	ringReleaseLabelsAddCmd.MarkFlagRequired("label") //nolint
+
+	ringReleaseLabelsRemoveCmd.Flags().String("label", "", "The label to remove.")
+	ringReleaseLabelsRemoveCmd.MarkFlagRequired("label") //nolint
+
+	ringReleaseLabelsCmd.AddCommand(ringReleaseLabelsAddCmd)
+	ringReleaseLabelsCmd.AddCommand(ringReleaseLabelsRemoveCmd)
+}
+
+var ringReleaseLabelsCmd = &cobra.Command{
+	Use:   "release-labels",
+	Short: "Manage the labels of a ring release, such as security-patch or quarterly.",
+}
+
+var ringReleaseLabelsAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add labels to a ring release.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		releaseID, _ := command.Flags().GetString("release")
+		labelFlags, _ := command.Flags().GetStringArray("label")
+
+		labels := model.ReleaseLabels(labelFlags)
+		if err := labels.Validate(); err != nil {
+			return errors.Wrap(err, "invalid labels")
+		}
+
+		labels, err := client.AddRingReleaseLabels(releaseID, labels)
+		if err != nil {
+			return errors.Wrapf(err, "failed to add labels to ring release %s", releaseID)
+		}
+
+		if err = printJSON(labels); err != nil {
+			return errors.Wrapf(err, "failed to print labels of ring release %s response", releaseID)
+		}
+
+		return nil
+	},
+}
+
+var ringReleaseLabelsRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove a label from a ring release.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		releaseID, _ := command.Flags().GetString("release")
+		label, _ := command.Flags().GetString("label")
+
+		if err := client.RemoveRingReleaseLabel(releaseID, label); err != nil {
+			return errors.Wrapf(err, "failed to remove label from ring release %s", releaseID)
+		}
+
+		return nil
+	},
+}
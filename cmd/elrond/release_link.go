@@ -0,0 +1,146 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	ringReleaseLinksCmd.PersistentFlags().String("release", "", "The id of the release whose links to manage.")
+	ringReleaseLinksCmd.MarkPersistentFlagRequired("release") //nolint
+
+	ringReleaseLinksAddCmd.Flags().String("type", "", "The link type, one of pr, build, incident or change-ticket.")
+	ringReleaseLinksAddCmd.Flags().String("url", "", "The URL of the link.")
+	ringReleaseLinksAddCmd.Flags().String("title", "", "An optional title of the link.")
+	ringReleaseLinksAddCmd.MarkFlagRequired("type") //nolint
+	ringReleaseLinksAddCmd.MarkFlagRequired("url")  //nolint
+
+	ringReleaseLinksRemoveCmd.Flags().String("type", "", "The type of the link to remove.")
+	ringReleaseLinksRemoveCmd.Flags().String("url", "", "The URL of the link to remove.")
+	ringReleaseLinksRemoveCmd.MarkFlagRequired("type") //nolint
+	ringReleaseLinksRemoveCmd.MarkFlagRequired("url")  //nolint
+
+	ringReleaseLinksCmd.AddCommand(ringReleaseLinksListCmd)
+	ringReleaseLinksCmd.AddCommand(ringReleaseLinksAddCmd)
+	ringReleaseLinksCmd.AddCommand(ringReleaseLinksRemoveCmd)
+}
+
+var ringReleaseLinksCmd = &cobra.Command{
+	Use:   "release-links",
+	Short: "Manage the pull request, build, incident and change ticket links of a ring release.",
+}
+
+var ringReleaseLinksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the links of a ring release.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		releaseID, _ := command.Flags().GetString("release")
+		links, err := client.GetRingReleaseLinks(releaseID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query links of ring release %s", releaseID)
+		}
+
+		if err = printJSON(links); err != nil {
+			return errors.Wrapf(err, "failed to print links of ring release %s response", releaseID)
+		}
+
+		return nil
+	},
+}
+
+var ringReleaseLinksAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a link to a ring release.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		releaseID, _ := command.Flags().GetString("release")
+		linkType, _ := command.Flags().GetString("type")
+		linkURL, _ := command.Flags().GetString("url")
+		title, _ := command.Flags().GetString("title")
+
+		link := &model.ReleaseLink{Type: linkType, URL: linkURL, Title: title}
+		if err := link.Validate(); err != nil {
+			return errors.Wrap(err, "invalid link")
+		}
+
+		links, err := client.AddRingReleaseLink(releaseID, link)
+		if err != nil {
+			return errors.Wrapf(err, "failed to add link to ring release %s", releaseID)
+		}
+
+		if err = printJSON(links); err != nil {
+			return errors.Wrapf(err, "failed to print links of ring release %s response", releaseID)
+		}
+
+		return nil
+	},
+}
+
+var ringReleaseLinksRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove a link from a ring release.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		releaseID, _ := command.Flags().GetString("release")
+		linkType, _ := command.Flags().GetString("type")
+		linkURL, _ := command.Flags().GetString("url")
+
+		if err := client.RemoveRingReleaseLink(releaseID, linkType, linkURL); err != nil {
+			return errors.Wrapf(err, "failed to remove link from ring release %s", releaseID)
+		}
+
+		return nil
+	},
+}
+
+// getReleaseLinkFlag parses the release links given as <type>=<url>.
+func getReleaseLinkFlag(command *cobra.Command) (model.ReleaseLinks, error) {
+	linkFlags, _ := command.Flags().GetStringArray("link")
+	if len(linkFlags) == 0 {
+		return nil, nil
+	}
+
+	links := make(model.ReleaseLinks, 0, len(linkFlags))
+	for _, flag := range linkFlags {
+		linkType, linkURL, found := strings.Cut(flag, "=")
+		if !found || linkType == "" || linkURL == "" {
+			return nil, errors.Errorf("invalid release link %q, expected <type>=<url>", flag)
+		}
+		links = append(links, &model.ReleaseLink{Type: linkType, URL: linkURL})
+	}
+
+	return links, nil
+}
@@ -0,0 +1,335 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/elrond/internal/config"
+	"github.com/mattermost/elrond/internal/github"
+	"github.com/mattermost/elrond/internal/jira"
+	"github.com/mattermost/elrond/internal/maintenance"
+	"github.com/mattermost/elrond/internal/notify"
+	"github.com/mattermost/elrond/internal/outbound"
+	"github.com/mattermost/elrond/internal/serverconfig"
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	logrus "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+)
+
+// reloadableSettings are the server settings applied on reload. Other
+// settings require a restart.
+var reloadableSettings = map[string]bool{
+	"debug":                     true,
+	"poll":                      true,
+	"drift-reconcile-interval":  true,
+	"soak-analysis-interval":    true,
+	"supervisor-unready-cycles": true,
+	"default-soak-time":         true,
+	"hotfix-soak-time":          true,
+	"environment-soak-times":    true,
+	"smtp-server":               true,
+	"smtp-username":             true,
+	"smtp-password":             true,
+	"smtp-from":                 true,
+	"jira-url":                  true,
+	"jira-username":             true,
+	"jira-api-token":            true,
+	"jira-issue-type":           true,
+	"jira-statuses":             true,
+	"maintenance-conflict-url":  true,
+	"maintenance-window":        true,
+	"github-url":                true,
+	"github-repository":         true,
+	"github-token":              true,
+	"failure-issue-threshold":   true,
+	"failure-issue-window":      true,
+	"failure-issue-labels":      true,
+	"public-url":                true,
+}
+
+// serverReloader reloads the configuration file of the server, applying the
+// settings that are safe to change without interrupting the supervisors.
+type serverReloader struct {
+	configFile       string
+	flags            *pflag.FlagSet
+	logger           logrus.FieldLogger
+	templates        notify.TemplateStore
+	ringSupervisor   *supervisor.RingSupervisor
+	scheduler        *supervisor.Scheduler
+	driftReconciler  *supervisor.Scheduler
+	soakTimeAnalyzer *supervisor.Scheduler
+	healthMonitor    *supervisor.HealthMonitor
+	// maintenanceChecker is the maintenance conflict checks of the API.
+	maintenanceChecker *reloadableMaintenanceChecker
+
+	lock sync.Mutex
+}
+
+// Reload reads the configuration file again and applies the reloadable
+// settings. Nothing is applied if any setting is invalid.
+func (r *serverReloader) Reload() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.configFile == "" {
+		return errors.New("the server was not started with a configuration file")
+	}
+
+	flags, err := config.Reload(r.configFile, serverconfig.AddFlags, r.flags, "config", "validate-config")
+	if err != nil {
+		return errors.Wrapf(err, "invalid configuration file %s", r.configFile)
+	}
+	if err = serverconfig.Validate(flags); err != nil {
+		return errors.Wrap(err, "invalid server configuration")
+	}
+
+	emailNotifier, err := newEmailNotifier(flags, r.templates)
+	if err != nil {
+		return errors.Wrap(err, "failed to create email notifier")
+	}
+	issueTracker, err := newIssueTracker(flags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create Jira release tracker")
+	}
+	maintenanceCheckers, err := newMaintenanceCheckers(flags)
+	if err != nil {
+		return errors.Wrap(err, "failed to create maintenance conflict checks")
+	}
+	failureIssueCreator, err := newFailureIssueCreator(flags, issueTracker)
+	if err != nil {
+		return errors.Wrap(err, "failed to create failure issue creator")
+	}
+
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if !reloadableSettings[flag.Name] && flag.Value.String() != r.flags.Lookup(flag.Name).Value.String() {
+			r.logger.Warnf("Changing %s requires a restart of the server", flag.Name)
+		}
+	})
+
+	debug, _ := flags.GetBool("debug")
+	if debug {
+		logger.SetLevel(logrus.DebugLevel)
+	} else {
+		logger.SetLevel(logrus.InfoLevel)
+	}
+
+	poll, _ := flags.GetInt("poll")
+	r.scheduler.SetPeriod(time.Duration(poll) * time.Second)
+	r.healthMonitor.SetPeriod(time.Duration(poll)*time.Second, supervisorRollout, supervisorRing, supervisorInstallationGroup)
+
+	supervisorUnreadyCycles, _ := flags.GetInt("supervisor-unready-cycles")
+	r.healthMonitor.SetMaxMissedCycles(supervisorUnreadyCycles)
+
+	driftReconcileInterval, _ := flags.GetInt("drift-reconcile-interval")
+	if r.driftReconciler != nil {
+		r.driftReconciler.SetPeriod(time.Duration(driftReconcileInterval) * time.Second)
+		r.healthMonitor.SetPeriod(time.Duration(driftReconcileInterval)*time.Second, supervisorDriftReconciler)
+	} else if driftReconcileInterval > 0 {
+		r.logger.Warn("Enabling the drift reconciler requires a restart of the server")
+	}
+
+	soakAnalysisInterval, _ := flags.GetInt("soak-analysis-interval")
+	if r.soakTimeAnalyzer != nil {
+		r.soakTimeAnalyzer.SetPeriod(time.Duration(soakAnalysisInterval) * time.Second)
+		r.healthMonitor.SetPeriod(time.Duration(soakAnalysisInterval)*time.Second, supervisorSoakTimeAnalyzer)
+	} else if soakAnalysisInterval > 0 {
+		r.logger.Warn("Enabling the soak time analyzer requires a restart of the server")
+	}
+
+	if r.maintenanceChecker != nil {
+		r.maintenanceChecker.setCheckers(maintenanceCheckers)
+	}
+
+	if r.ringSupervisor != nil {
+		r.ringSupervisor.SetSoakTimeDefaults(soakTimeDefaults(flags))
+
+		var notifiers []supervisor.Notifier
+		if emailNotifier != nil {
+			notifiers = append(notifiers, emailNotifier)
+		}
+		r.ringSupervisor.SetNotifiers(notifiers)
+
+		var tracker supervisor.IssueTracker
+		if issueTracker != nil {
+			tracker = issueTracker
+		}
+		r.ringSupervisor.SetIssueTracker(tracker)
+
+		var maintenanceChecker supervisor.MaintenanceChecker
+		if maintenanceCheckers != nil {
+			maintenanceChecker = maintenanceCheckers
+		}
+		r.ringSupervisor.SetMaintenanceChecker(maintenanceChecker)
+		r.ringSupervisor.SetFailureIssueCreator(failureIssueCreator, serverconfig.FailureIssueSettings(flags))
+	}
+
+	r.flags = flags
+	r.logger.WithFields(logrus.Fields{"config": r.configFile, "poll": poll, "debug": debug}).Info("Reloaded server configuration")
+
+	return nil
+}
+
+// reloadableMaintenanceChecker looks up the maintenance conflicts with the
+// maintenance conflict checks of the server, which change on reload, finding
+// none when there are no checks.
+type reloadableMaintenanceChecker struct {
+	lock     sync.RWMutex
+	checkers maintenance.Checkers
+}
+
+func (c *reloadableMaintenanceChecker) setCheckers(checkers maintenance.Checkers) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.checkers = checkers
+}
+
+func (c *reloadableMaintenanceChecker) Conflicts(query *model.MaintenanceConflictQuery) ([]*model.MaintenanceConflict, error) {
+	c.lock.RLock()
+	checkers := c.checkers
+	c.lock.RUnlock()
+
+	if checkers == nil {
+		return nil, nil
+	}
+
+	return checkers.Conflicts(query)
+}
+
+// soakTimeDefaults returns the soak time defaults of the server.
+func soakTimeDefaults(flags *pflag.FlagSet) model.SoakTimeDefaults {
+	environment, _ := flags.GetString("environment")
+	defaultSoakTime, _ := flags.GetInt("default-soak-time")
+	environmentSoakTimes, _ := flags.GetStringToInt("environment-soak-times")
+	hotfixSoakTime, _ := flags.GetInt("hotfix-soak-time")
+
+	return model.SoakTimeDefaults{
+		Server:      defaultSoakTime,
+		Environment: environmentSoakTimes[environment],
+		Hotfix:      hotfixSoakTime,
+	}
+}
+
+// newEmailNotifier returns the email notifier of the server, rendering the
+// emails with the notification templates of the given store, or nil if email
+// notifications are disabled.
+func newEmailNotifier(flags *pflag.FlagSet, templates notify.TemplateStore) (*notify.EmailNotifier, error) {
+	smtpServer, _ := flags.GetString("smtp-server")
+	if smtpServer == "" {
+		return nil, nil
+	}
+
+	smtpUsername, _ := flags.GetString("smtp-username")
+	smtpPassword, _ := flags.GetString("smtp-password")
+	smtpFrom, _ := flags.GetString("smtp-from")
+
+	emailNotifier, err := notify.NewEmailNotifier(notify.EmailConfig{
+		Server:   smtpServer,
+		Username: smtpUsername,
+		Password: smtpPassword,
+		From:     smtpFrom,
+	})
+	if err != nil {
+		return nil, err
+	}
+	emailNotifier.SetTemplateStore(templates)
+
+	return emailNotifier, nil
+}
+
+// newIssueTracker returns the Jira release tracker of the server, or nil if
+// Jira tracking is disabled.
+func newIssueTracker(flags *pflag.FlagSet) (*jira.ReleaseTracker, error) {
+	jiraURL, _ := flags.GetString("jira-url")
+	if jiraURL == "" {
+		return nil, nil
+	}
+
+	jiraUsername, _ := flags.GetString("jira-username")
+	jiraAPIToken, _ := flags.GetString("jira-api-token")
+	jiraIssueType, _ := flags.GetString("jira-issue-type")
+	jiraStatuses, _ := flags.GetStringToString("jira-statuses")
+
+	transport, err := serverconfig.OutboundSettings(flags).Transport(outbound.TargetJira)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set up the Jira transport")
+	}
+	client := jira.NewClient(jiraURL, jiraUsername, jiraAPIToken)
+	client.SetTransport(transport)
+
+	return jira.NewReleaseTracker(client, jiraIssueType, jiraStatuses), nil
+}
+
+// newMaintenanceCheckers returns where the server looks up the maintenance
+// conflicting with ring releases: the maintenance conflict URL and the
+// internal maintenance calendar, or nil if neither is configured.
+func newMaintenanceCheckers(flags *pflag.FlagSet) (maintenance.Checkers, error) {
+	var checkers maintenance.Checkers
+
+	maintenanceConflictURL, _ := flags.GetString("maintenance-conflict-url")
+	if maintenanceConflictURL != "" {
+		transport, err := serverconfig.OutboundSettings(flags).Transport(outbound.TargetMaintenance)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to set up the maintenance transport")
+		}
+		checker, err := maintenance.NewHTTPChecker(maintenanceConflictURL, transport)
+		if err != nil {
+			return nil, err
+		}
+		checkers = append(checkers, checker)
+	}
+
+	maintenanceWindows, _ := flags.GetStringArray("maintenance-window")
+	if len(maintenanceWindows) > 0 {
+		windows := make([]*model.MaintenanceWindow, 0, len(maintenanceWindows))
+		for _, value := range maintenanceWindows {
+			window, err := model.ParseMaintenanceWindow(value)
+			if err != nil {
+				return nil, err
+			}
+			windows = append(windows, window)
+		}
+		checkers = append(checkers, maintenance.NewCalendar(windows))
+	}
+
+	return checkers, nil
+}
+
+// newFailureIssueCreator returns where the server opens the issues tracking
+// the repeated release failures of rings: the GitHub repository if any, or
+// else the Jira project of each ring through the Jira release tracker. It
+// returns nil if failure issues are disabled.
+func newFailureIssueCreator(flags *pflag.FlagSet, issueTracker *jira.ReleaseTracker) (supervisor.FailureIssueCreator, error) {
+	if serverconfig.FailureIssueSettings(flags).Threshold <= 0 {
+		return nil, nil
+	}
+
+	githubRepository, _ := flags.GetString("github-repository")
+	if githubRepository == "" {
+		if issueTracker == nil {
+			return nil, nil
+		}
+		return issueTracker, nil
+	}
+
+	githubURL, _ := flags.GetString("github-url")
+	githubToken, _ := flags.GetString("github-token")
+	failureIssueLabels, _ := flags.GetStringSlice("failure-issue-labels")
+
+	transport, err := serverconfig.OutboundSettings(flags).Transport(outbound.TargetGitHub)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set up the GitHub transport")
+	}
+	client, err := github.NewClient(githubURL, githubRepository, githubToken)
+	if err != nil {
+		return nil, err
+	}
+	client.SetTransport(transport)
+
+	return github.NewFailureIssueCreator(client, failureIssueLabels), nil
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+	"os"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	reportCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(reportCmd)
+
+	reportVersionsCmd.Flags().Bool("csv", false, "Whether to print the report as CSV instead of JSON.")
+
+	reportCompareCmd.Flags().String("ring-a", "", "The ID of the first ring to compare.")
+	reportCompareCmd.Flags().String("ring-b", "", "The ID of the second ring to compare.")
+	reportCompareCmd.MarkFlagRequired("ring-a") //nolint
+	reportCompareCmd.MarkFlagRequired("ring-b") //nolint
+
+	reportCmd.AddCommand(reportSoakTimeCmd)
+	reportCmd.AddCommand(reportVersionsCmd)
+	reportLeadTimeCmd.Flags().String("label", "", "The label of the releases whose lead time to report, such as security-patch.")
+	reportLeadTimeCmd.MarkFlagRequired("label") //nolint
+
+	reportReplayCmd.Flags().String("ring", "", "Only replay the events of this ring.")
+	reportReplayCmd.Flags().String("from", "", "The RFC3339 time to replay the events from.")
+	reportReplayCmd.Flags().String("to", "", "The RFC3339 time to replay the events until.")
+	reportReplayCmd.Flags().String("failure-policy", "", "The failure policy to replay every ring with instead of its own: halt, rollback-ring, rollback-ig-only or continue-remaining-igs.")
+	reportReplayCmd.Flags().Int("max-release-duration", 0, "The max release duration, in seconds, to replay every ring with instead of its own. Pass 0 for no limit.")
+
+	reportCmd.AddCommand(reportCompareCmd)
+	reportCmd.AddCommand(reportLeadTimeCmd)
+	reportCmd.AddCommand(reportReplayCmd)
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "View the reports built by the elrond server.",
+}
+
+var reportSoakTimeCmd = &cobra.Command{
+	Use:   "soak-time",
+	Short: "Show the soak time suggested for every ring from the outcomes of its previous soaks.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		report, err := client.GetSoakTimeReport()
+		if err != nil {
+			return errors.Wrap(err, "failed to query soak time report")
+		}
+
+		if err = printJSON(report); err != nil {
+			return errors.Wrap(err, "failed to print soak time report")
+		}
+
+		return nil
+	},
+}
+
+var reportVersionsCmd = &cobra.Command{
+	Use:   "versions",
+	Short: "Show the active and desired release of every ring and installation group.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		if asCSV, _ := command.Flags().GetBool("csv"); asCSV {
+			data, err := client.GetVersionReportCSV()
+			if err != nil {
+				return errors.Wrap(err, "failed to query version report")
+			}
+			if _, err = os.Stdout.Write(data); err != nil {
+				return errors.Wrap(err, "failed to print version report")
+			}
+
+			return nil
+		}
+
+		report, err := client.GetVersionReport()
+		if err != nil {
+			return errors.Wrap(err, "failed to query version report")
+		}
+
+		if err = printJSON(report); err != nil {
+			return errors.Wrap(err, "failed to print version report")
+		}
+
+		return nil
+	},
+}
+
+var reportCompareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Show the differences between the active release, soak configuration and labels of two rings.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		ringA, _ := command.Flags().GetString("ring-a")
+		ringB, _ := command.Flags().GetString("ring-b")
+
+		comparison, err := client.CompareRings(ringA, ringB)
+		if err != nil {
+			return errors.Wrap(err, "failed to compare rings")
+		}
+
+		if err = printJSON(comparison); err != nil {
+			return errors.Wrap(err, "failed to print ring comparison")
+		}
+
+		return nil
+	},
+}
+
+var reportLeadTimeCmd = &cobra.Command{
+	Use:   "lead-time",
+	Short: "Show how long the releases with a label took to reach every ring.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		label, _ := command.Flags().GetString("label")
+		report, err := client.GetReleaseLeadTimeReport(label)
+		if err != nil {
+			return errors.Wrap(err, "failed to query lead time report")
+		}
+
+		if err = printJSON(report); err != nil {
+			return errors.Wrap(err, "failed to print lead time report")
+		}
+
+		return nil
+	},
+}
+
+var reportReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Replay the recorded events against the current state machine and policy configuration, showing where they would have behaved differently.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		from, err := getTimeFlag(command, "from")
+		if err != nil {
+			return err
+		}
+		to, err := getTimeFlag(command, "to")
+		if err != nil {
+			return err
+		}
+		ringID, _ := command.Flags().GetString("ring")
+		failurePolicy, _ := command.Flags().GetString("failure-policy")
+		request := &model.GetReplayReportRequest{
+			RingID:        ringID,
+			From:          from,
+			To:            to,
+			FailurePolicy: failurePolicy,
+		}
+		if command.Flags().Changed("max-release-duration") {
+			maxReleaseDuration, _ := command.Flags().GetInt("max-release-duration")
+			request.MaxReleaseDuration = &maxReleaseDuration
+		}
+
+		client := newClient(command, serverAddress)
+
+		report, err := client.GetReplayReport(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to replay events")
+		}
+
+		if err = printJSON(report); err != nil {
+			return errors.Wrap(err, "failed to print replay report")
+		}
+
+		return nil
+	},
+}
@@ -22,6 +22,7 @@ import (
 
 func init() {
 	ringCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The ring server whose API will be queried.")
+	addAPITokenFlag(ringCmd)
 	ringCmd.PersistentFlags().Bool("dry-run", false, "When set to true, only print the API request without sending it.")
 
 	ringCreateCmd.Flags().String("name", "", "The name that identifies the deployment ring.")
@@ -29,8 +30,31 @@ func init() {
 	ringCreateCmd.Flags().String("installation-group-name", "", "The installation group name to register with the ring.")
 	ringCreateCmd.Flags().Int("installation-group-soak-time", 0, "The installation group soak time.")
 	ringCreateCmd.Flags().String("installation-group-provisioner-group-id", "", "The installation group provisioner group ID to associate.")
-
-	ringCreateCmd.Flags().Int("soak-time", 7200, "The soak time to consider a ring release stable.")
+	ringCreateCmd.Flags().String("installation-group-release-strategy", "", "How the installation group is released: rolling (default) or blue-green.")
+	ringCreateCmd.Flags().String("installation-group-failure-domain", "", "The failure domain of the installation group, such as its availability zone or region.")
+	ringCreateCmd.Flags().StringArray("annotation", []string{}, "An annotation forwarded to the provisioner with every call made for the ring, as Name=Value. Accepts multiple values.")
+	ringCreateCmd.Flags().String("metadata", "", "A JSON object included verbatim in the webhook payloads and events of the ring, e.g. '{\"team\":\"payments\"}'.")
+	ringCreateCmd.Flags().StringArray("notification-email", []string{}, "An email address notified when a release of the ring starts, completes or fails. Accepts multiple values.")
+	ringCreateCmd.Flags().String("notification-language", "", "The language of the release notification emails of the ring, e.g. de or pt-BR. Emails are sent in English when no template matches.")
+	ringCreateCmd.Flags().String("jira-project", "", "The key of the Jira project to track every release of the ring in.")
+	ringCreateCmd.Flags().String("owner-team", "", "The team owning the ring, included in release notifications and failure alerts.")
+	ringCreateCmd.Flags().String("slack-channel", "", "The Slack channel of the team owning the ring, such as #platform-alerts.")
+	ringCreateCmd.Flags().String("escalation-policy", "", "The escalation policy paged when a release of the ring fails, as an ID or an https URL.")
+	ringCreateCmd.Flags().String("installation-group-policy", "", "How installation groups registered or removed during a release are handled: queue (default) or join.")
+	ringCreateCmd.Flags().String("failure-policy", "", "What happens when the release or soak of an installation group of the ring fails: halt (default), rollback-ring, rollback-ig-only or continue-remaining-igs.")
+	ringCreateCmd.Flags().Bool("auto-rollback", false, "Whether to roll the ring back when the release or soak of one of its installation groups fails. Same as --failure-policy rollback-ring.")
+	ringCreateCmd.Flags().Int("force-approval-window", 0, "When set, forced releases and API unlocks of the ring must be confirmed by a second API token within this many seconds.")
+	ringCreateCmd.Flags().StringArray("soak-window", []string{}, "A window the releases of the ring soak within, as \"<start>-<end> [<time zone>] [<weekday>,...]\", such as \"09:00-17:00 Europe/Berlin Monday,Tuesday\". Accepts multiple values.")
+	ringCreateCmd.Flags().StringArray("release-window", []string{}, "A window the releases of the ring start within, in the same format as --soak-window. Accepts multiple values.")
+	ringCreateCmd.Flags().StringArray("depends-on", []string{}, "The ID of a ring which completes its releases, soak included, before the ring starts its own. Rings with dependencies are released along the dependency graph instead of by priority. Accepts multiple values.")
+	ringCreateCmd.Flags().Int("max-concurrency", 0, "The number of installation groups of the ring released at once. Defaults to 1.")
+	ringCreateCmd.Flags().Int("max-per-failure-domain", 0, "The number of installation groups of the ring in the same failure domain released at once. Defaults to 1.")
+	ringCreateCmd.Flags().Int("max-release-duration", 0, "The time, in seconds, a release of the ring may run for across all of its installation groups before it fails following the failure policy of the ring. Defaults to no limit.")
+	ringCreateCmd.Flags().Bool("protected", false, "Whether to protect the ring from deletion and forced releases, even by admins, until an admin removes the protection with a reason.")
+	ringCreateCmd.Flags().Bool("rehearsal", false, "Whether to create a rehearsal ring, whose releases run the whole release workflow against a fake provisioner without touching real installations, to practice releasing.")
+	ringCreateCmd.Flags().Int("rehearsal-speedup", 0, "The number of times the soaks of a rehearsal ring are accelerated by. Defaults to 60, soaking an hour in a minute.")
+
+	ringCreateCmd.Flags().Int("soak-time", 0, "The soak time to consider a ring release stable. Defaults to the server soak time.")
 	ringCreateCmd.Flags().String("image", "", "The Mattermost image to associate with this release ring.")
 	ringCreateCmd.Flags().String("version", "", "The Mattermost version to associate with this release ring.")
 
@@ -42,6 +66,24 @@ func init() {
 	ringUpdateCmd.Flags().Int("soak-time", 0, "The soak time to set to the deployment ring.")
 	ringUpdateCmd.Flags().String("image", "", "The Mattermost image to set to the deployment ring. This will not force a release.")
 	ringUpdateCmd.Flags().String("version", "", "The Mattermost version to set to the deployment ring. This will not force a release.")
+	ringUpdateCmd.Flags().StringArray("annotation", []string{}, "An annotation forwarded to the provisioner with every call made for the ring, replacing the current ones, as Name=Value. Accepts multiple values.")
+	ringUpdateCmd.Flags().String("metadata", "", "A JSON object included verbatim in the webhook payloads and events of the ring, replacing the current one.")
+	ringUpdateCmd.Flags().StringArray("notification-email", []string{}, "An email address notified when a release of the ring starts, completes or fails, replacing the current ones. Pass an empty value to remove them all. Accepts multiple values.")
+	ringUpdateCmd.Flags().String("notification-language", "", "The language of the release notification emails of the ring, e.g. de or pt-BR. Pass an empty value to send them in English.")
+	ringUpdateCmd.Flags().String("jira-project", "", "The key of the Jira project to track every release of the ring in. Pass an empty value to stop tracking releases.")
+	ringUpdateCmd.Flags().String("owner-team", "", "The team owning the ring. Pass an empty value to remove it.")
+	ringUpdateCmd.Flags().String("slack-channel", "", "The Slack channel of the team owning the ring, such as #platform-alerts. Pass an empty value to remove it.")
+	ringUpdateCmd.Flags().String("escalation-policy", "", "The escalation policy paged when a release of the ring fails, as an ID or an https URL. Pass an empty value to remove it.")
+	ringUpdateCmd.Flags().String("installation-group-policy", "", "How installation groups registered or removed during a release are handled: queue or join.")
+	ringUpdateCmd.Flags().String("failure-policy", "", "What happens when the release or soak of an installation group of the ring fails: halt, rollback-ring, rollback-ig-only or continue-remaining-igs.")
+	ringUpdateCmd.Flags().Bool("auto-rollback", false, "Whether to roll the ring back when the release or soak of one of its installation groups fails, unless it has a failure policy.")
+	ringUpdateCmd.Flags().Int("force-approval-window", 0, "The time in seconds a second API token has to confirm a forced release or API unlock of the ring. Pass 0 to disable the two-person rule, which requires the admin role.")
+	ringUpdateCmd.Flags().StringArray("soak-window", []string{}, "A window the releases of the ring soak within, replacing the current ones, as \"<start>-<end> [<time zone>] [<weekday>,...]\". Pass an empty value to remove them all. Accepts multiple values.")
+	ringUpdateCmd.Flags().StringArray("release-window", []string{}, "A window the releases of the ring start within, replacing the current ones, in the same format as --soak-window. Pass an empty value to remove them all. Accepts multiple values.")
+	ringUpdateCmd.Flags().StringArray("depends-on", []string{}, "The ID of a ring which completes its releases before the ring starts its own, replacing the current ones. Pass an empty value to remove them all. Accepts multiple values.")
+	ringUpdateCmd.Flags().Int("max-concurrency", 0, "The number of installation groups of the ring released at once. Pass 0 for the default of 1.")
+	ringUpdateCmd.Flags().Int("max-per-failure-domain", 0, "The number of installation groups of the ring in the same failure domain released at once. Pass 0 for the default of 1.")
+	ringUpdateCmd.Flags().Int("max-release-duration", 0, "The time, in seconds, a release of the ring may run for across all of its installation groups before it fails following the failure policy of the ring. Pass 0 for no limit.")
 
 	ringUpdateCmd.MarkFlagRequired("ring") //nolint
 
@@ -49,31 +91,84 @@ func init() {
 	ringReleaseCmd.Flags().String("image", "", "The Mattermost image to release to.")
 	ringReleaseCmd.Flags().String("version", "", "The Mattermost version to release to.")
 	ringReleaseCmd.Flags().Bool("force", false, "When set to true a release is forced and soaking times are ignored.")
+	ringReleaseCmd.Flags().String("type", model.ReleaseTypeStandard, "The release type, one of standard, hotfix or rollback. Hotfixes are soaked for a shorter time.")
+	ringReleaseCmd.Flags().Int("soak-time", 0, "The soak time in seconds overriding the ring and installation group soak times for this release.")
+	ringReleaseCmd.Flags().StringArray("installation-group-env", []string{}, "An environment variable set on the installations of one installation group by this release, as <installation-group>:<NAME>=<value>. Accepts multiple values.")
+	ringReleaseCmd.Flags().StringArray("link", []string{}, "A link of the release, as <type>=<url> where type is one of pr, build, incident or change-ticket. Accepts multiple values.")
+	ringReleaseCmd.Flags().StringArray("label", []string{}, "A label of the release, such as security-patch, to filter timelines and reports by. Accepts multiple values.")
 	ringReleaseCmd.Flags().Bool("all-rings", false, "Whether all rings should be released.")
-	ringReleaseCmd.Flags().Bool("pause", false, "Whether to pause a release in progress.")
-	ringReleaseCmd.Flags().Bool("resume", false, "Whether to resume a paused release.")
+	ringReleaseCmd.Flags().Bool("pause", false, "Whether to pause the pending and in flight releases of all rings.")
+	ringReleaseCmd.Flags().Bool("resume", false, "Whether to resume the paused releases of all rings.")
 	ringReleaseCmd.Flags().Bool("cancel", false, "Whether to cancel a release.")
+	ringReleaseCmd.Flags().Bool("prepare", false, "Whether to prepare the release of the ring, leaving it in release-prepared until committed.")
+	ringReleaseCmd.Flags().Bool("commit", false, "Whether to start the prepared release of the ring.")
+	ringReleaseCmd.Flags().String("schedule-at", "", "The RFC3339 time before which the release does not start. The release starts as soon as possible when not set.")
 
 	ringReleaseGetCmd.Flags().String("release", "", "The id of the release to return info.")
 	ringReleaseGetCmd.MarkFlagRequired("release") //nolint
 
+	ringReleaseHealthCmd.Flags().String("release", "", "The id of the release whose installation group health to compare.")
+	ringReleaseHealthCmd.Flags().String("ring", "", "When set, only compare the installation groups of this ring.")
+	ringReleaseHealthCmd.MarkFlagRequired("release") //nolint
+
 	ringDeleteCmd.Flags().String("ring", "", "The id of the ring to be deleted.")
+	ringDeleteCmd.Flags().Int("delete-after", 0, "The grace period in seconds during which the deletion can be cancelled. The ring is deleted right away when not set.")
 	ringDeleteCmd.MarkFlagRequired("ring") //nolint
 
+	ringCancelDeletionCmd.Flags().String("ring", "", "The id of the ring whose pending deletion to cancel.")
+	ringCancelDeletionCmd.MarkFlagRequired("ring") //nolint
+
+	ringCancelReleaseCmd.Flags().String("ring", "", "The id of the ring whose scheduled release to cancel.")
+	ringCancelReleaseCmd.MarkFlagRequired("ring") //nolint
+
+	ringPauseCmd.Flags().String("ring", "", "The id of the ring whose release to pause.")
+	ringPauseCmd.MarkFlagRequired("ring") //nolint
+
+	ringResumeCmd.Flags().String("ring", "", "The id of the ring whose paused release to resume.")
+	ringResumeCmd.MarkFlagRequired("ring") //nolint
+
 	ringGetCmd.Flags().String("ring", "", "The id of the ring to be fetched.")
 	ringGetCmd.MarkFlagRequired("ring") //nolint
 
+	ringRollbackSnapshotCmd.Flags().String("ring", "", "The id of the ring whose rollback snapshot to fetch.")
+	ringRollbackSnapshotCmd.MarkFlagRequired("ring") //nolint
+
+	ringTimelineCmd.Flags().String("ring", "", "The id of the ring whose timeline to fetch.")
+	ringTimelineCmd.Flags().Int("releases", model.DefaultTimelineReleases, "The number of latest releases to include in the timeline.")
+	ringTimelineCmd.Flags().String("label", "", "Only include the releases with this label.")
+	ringTimelineCmd.MarkFlagRequired("ring") //nolint
+
+	ringBlockersCmd.Flags().String("ring", "", "The id of the ring whose release blockers to fetch.")
+	ringBlockersCmd.MarkFlagRequired("ring") //nolint
+
+	ringWatchCmd.Flags().String("ring", "", "The id of the ring to watch.")
+	ringWatchCmd.Flags().Duration("interval", 10*time.Second, "How often to poll the ring.")
+	ringWatchCmd.MarkFlagRequired("ring") //nolint
+
 	ringListCmd.Flags().Int("page", 0, "The page of rings to fetch, starting at 0.")
 	ringListCmd.Flags().Int("per-page", 100, "The number of rings to fetch per page.")
 	ringListCmd.Flags().Bool("include-deleted", false, "Whether to include deleted rings.")
 	ringListCmd.Flags().Bool("table", false, "Whether to display the returned ring list in a table or not")
+	ringListCmd.Flags().String("as-of", "", "The RFC3339 time to list the rings, with their states and releases, as they were at.")
 
 	ringCmd.AddCommand(ringCreateCmd)
 	ringCmd.AddCommand(ringReleaseCmd)
 	ringCmd.AddCommand(ringReleaseGetCmd)
+	ringCmd.AddCommand(ringReleaseHealthCmd)
+	ringCmd.AddCommand(ringReleaseLinksCmd)
+	ringCmd.AddCommand(ringReleaseLabelsCmd)
+	ringCmd.AddCommand(ringRollbackSnapshotCmd)
+	ringCmd.AddCommand(ringTimelineCmd)
+	ringCmd.AddCommand(ringBlockersCmd)
 	ringCmd.AddCommand(ringUpdateCmd)
 	ringCmd.AddCommand(ringDeleteCmd)
+	ringCmd.AddCommand(ringCancelDeletionCmd)
+	ringCmd.AddCommand(ringScheduledReleasesCmd)
+	ringCmd.AddCommand(ringCancelReleaseCmd)
+	ringCmd.AddCommand(ringPauseCmd)
+	ringCmd.AddCommand(ringResumeCmd)
 	ringCmd.AddCommand(ringGetCmd)
+	ringCmd.AddCommand(ringWatchCmd)
 	ringCmd.AddCommand(ringListCmd)
 	ringCmd.AddCommand(ringInstallationGroupCmd)
 }
@@ -100,30 +195,89 @@ var ringCreateCmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		name, _ := command.Flags().GetString("name")
 		priority, _ := command.Flags().GetInt("priority")
 		installationGroupName, _ := command.Flags().GetString("installation-group-name")
 		installationGroupSoakTime, _ := command.Flags().GetInt("installation-group-soak-time")
 		installationGroupProvisionerGroupID, _ := command.Flags().GetString("installation-group-provisioner-group-id")
+		installationGroupReleaseStrategy, _ := command.Flags().GetString("installation-group-release-strategy")
+		installationGroupFailureDomain, _ := command.Flags().GetString("installation-group-failure-domain")
 		soakTime, _ := command.Flags().GetInt("soak-time")
 		image, _ := command.Flags().GetString("image")
 		version, _ := command.Flags().GetString("version")
+		annotations, err := getAnnotationsFlag(command)
+		if err != nil {
+			return err
+		}
 
 		installationGroup := &model.InstallationGroup{
 			Name:               installationGroupName,
 			SoakTime:           installationGroupSoakTime,
 			ProvisionerGroupID: installationGroupProvisionerGroupID,
+			ReleaseStrategy:    installationGroupReleaseStrategy,
+			FailureDomain:      installationGroupFailureDomain,
+		}
+
+		metadata := getMetadataFlag(command)
+		notificationEmails, _ := command.Flags().GetStringArray("notification-email")
+		notificationLanguage, _ := command.Flags().GetString("notification-language")
+		jiraProject, _ := command.Flags().GetString("jira-project")
+		ownerTeam, _ := command.Flags().GetString("owner-team")
+		slackChannel, _ := command.Flags().GetString("slack-channel")
+		escalationPolicy, _ := command.Flags().GetString("escalation-policy")
+		installationGroupPolicy, _ := command.Flags().GetString("installation-group-policy")
+		failurePolicy, _ := command.Flags().GetString("failure-policy")
+		autoRollback, _ := command.Flags().GetBool("auto-rollback")
+		forceApprovalWindow, _ := command.Flags().GetInt("force-approval-window")
+		soakWindows, err := getTimeWindowsFlag(command, "soak-window")
+		if err != nil {
+			return err
 		}
+		releaseWindows, err := getTimeWindowsFlag(command, "release-window")
+		if err != nil {
+			return err
+		}
+		maxConcurrency, _ := command.Flags().GetInt("max-concurrency")
+		maxPerFailureDomain, _ := command.Flags().GetInt("max-per-failure-domain")
+		maxReleaseDuration, _ := command.Flags().GetInt("max-release-duration")
+		protected, _ := command.Flags().GetBool("protected")
+		rehearsal, _ := command.Flags().GetBool("rehearsal")
+		rehearsalSpeedup, _ := command.Flags().GetInt("rehearsal-speedup")
 
 		request := &model.CreateRingRequest{
-			Name:              name,
-			Priority:          priority,
-			InstallationGroup: installationGroup,
-			SoakTime:          soakTime,
-			Image:             image,
-			Version:           version,
+			Name:                    name,
+			Priority:                priority,
+			InstallationGroup:       installationGroup,
+			SoakTime:                soakTime,
+			Image:                   image,
+			Version:                 version,
+			Annotations:             annotations,
+			Metadata:                metadata,
+			NotificationEmails:      notificationEmails,
+			NotificationLanguage:    notificationLanguage,
+			JiraProject:             jiraProject,
+			OwnerTeam:               ownerTeam,
+			SlackChannel:            slackChannel,
+			EscalationPolicy:        escalationPolicy,
+			InstallationGroupPolicy: installationGroupPolicy,
+			FailurePolicy:           failurePolicy,
+			AutoRollback:            autoRollback,
+			ForceApprovalWindow:     forceApprovalWindow,
+			SoakWindows:             soakWindows,
+			ReleaseWindows:          releaseWindows,
+			DependsOn:               getRingDependenciesFlag(command, "depends-on"),
+			MaxConcurrency:          maxConcurrency,
+			MaxPerFailureDomain:     maxPerFailureDomain,
+			MaxReleaseDuration:      maxReleaseDuration,
+			Protected:               protected,
+			Rehearsal:               rehearsal,
+			RehearsalSpeedup:        rehearsalSpeedup,
+		}
+
+		if err := request.Validate(); err != nil {
+			return errors.Wrap(err, "invalid request")
 		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
@@ -160,7 +314,7 @@ var ringUpdateCmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		ringID, _ := command.Flags().GetString("ring")
 		name, _ := command.Flags().GetString("name")
@@ -168,13 +322,95 @@ var ringUpdateCmd = &cobra.Command{
 		soakTime, _ := command.Flags().GetInt("soak-time")
 		image, _ := command.Flags().GetString("image")
 		version, _ := command.Flags().GetString("version")
+		annotations, err := getAnnotationsFlag(command)
+		if err != nil {
+			return err
+		}
 
 		request := &model.UpdateRingRequest{
-			Name:     name,
-			Priority: priority,
-			SoakTime: soakTime,
-			Image:    image,
-			Version:  version,
+			Name:        name,
+			Priority:    priority,
+			SoakTime:    soakTime,
+			Image:       image,
+			Version:     version,
+			Annotations: annotations,
+			Metadata:    getMetadataFlag(command),
+		}
+		if command.Flags().Changed("notification-email") {
+			notificationEmails, _ := command.Flags().GetStringArray("notification-email")
+			emails := model.NotificationEmails{}
+			for _, email := range notificationEmails {
+				if email != "" {
+					emails = append(emails, email)
+				}
+			}
+			request.NotificationEmails = &emails
+		}
+		if command.Flags().Changed("notification-language") {
+			notificationLanguage, _ := command.Flags().GetString("notification-language")
+			request.NotificationLanguage = &notificationLanguage
+		}
+		if command.Flags().Changed("jira-project") {
+			jiraProject, _ := command.Flags().GetString("jira-project")
+			request.JiraProject = &jiraProject
+		}
+		if command.Flags().Changed("owner-team") {
+			ownerTeam, _ := command.Flags().GetString("owner-team")
+			request.OwnerTeam = &ownerTeam
+		}
+		if command.Flags().Changed("slack-channel") {
+			slackChannel, _ := command.Flags().GetString("slack-channel")
+			request.SlackChannel = &slackChannel
+		}
+		if command.Flags().Changed("escalation-policy") {
+			escalationPolicy, _ := command.Flags().GetString("escalation-policy")
+			request.EscalationPolicy = &escalationPolicy
+		}
+		installationGroupPolicy, _ := command.Flags().GetString("installation-group-policy")
+		request.InstallationGroupPolicy = installationGroupPolicy
+		failurePolicy, _ := command.Flags().GetString("failure-policy")
+		request.FailurePolicy = failurePolicy
+		if command.Flags().Changed("auto-rollback") {
+			autoRollback, _ := command.Flags().GetBool("auto-rollback")
+			request.AutoRollback = &autoRollback
+		}
+		if command.Flags().Changed("force-approval-window") {
+			forceApprovalWindow, _ := command.Flags().GetInt("force-approval-window")
+			request.ForceApprovalWindow = &forceApprovalWindow
+		}
+		if command.Flags().Changed("soak-window") {
+			soakWindows, err := getTimeWindowsFlag(command, "soak-window")
+			if err != nil {
+				return err
+			}
+			request.SoakWindows = &soakWindows
+		}
+		if command.Flags().Changed("release-window") {
+			releaseWindows, err := getTimeWindowsFlag(command, "release-window")
+			if err != nil {
+				return err
+			}
+			request.ReleaseWindows = &releaseWindows
+		}
+		if command.Flags().Changed("depends-on") {
+			dependsOn := getRingDependenciesFlag(command, "depends-on")
+			request.DependsOn = &dependsOn
+		}
+		if command.Flags().Changed("max-concurrency") {
+			maxConcurrency, _ := command.Flags().GetInt("max-concurrency")
+			request.MaxConcurrency = &maxConcurrency
+		}
+		if command.Flags().Changed("max-per-failure-domain") {
+			maxPerFailureDomain, _ := command.Flags().GetInt("max-per-failure-domain")
+			request.MaxPerFailureDomain = &maxPerFailureDomain
+		}
+		if command.Flags().Changed("max-release-duration") {
+			maxReleaseDuration, _ := command.Flags().GetInt("max-release-duration")
+			request.MaxReleaseDuration = &maxReleaseDuration
+		}
+
+		if err := request.Validate(); err != nil {
+			return errors.Wrap(err, "invalid request")
 		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
@@ -211,20 +447,60 @@ var ringReleaseCmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 		ringID, _ := command.Flags().GetString("ring")
 		image, _ := command.Flags().GetString("image")
 		version, _ := command.Flags().GetString("version")
 		force, _ := command.Flags().GetBool("force")
+		soakTime, _ := command.Flags().GetInt("soak-time")
+		releaseType, _ := command.Flags().GetString("type")
 		releaseAllRings, _ := command.Flags().GetBool("all-rings")
 		pauseRelease, _ := command.Flags().GetBool("pause")
 		resumeRelease, _ := command.Flags().GetBool("resume")
 		cancelRelease, _ := command.Flags().GetBool("cancel")
+		prepareRelease, _ := command.Flags().GetBool("prepare")
+		commitRelease, _ := command.Flags().GetBool("commit")
+
+		if commitRelease {
+			ring, err := client.CommitRingRelease(ringID)
+			if err != nil {
+				return errors.Wrapf(err, "failed to commit the prepared release of ring %s", ringID)
+			}
+			if err = printJSON(ring); err != nil {
+				return errors.Wrapf(err, "failed to print ring %s release response", ringID)
+			}
+
+			return nil
+		}
+
+		parameters, err := getInstallationGroupEnvFlag(command)
+		if err != nil {
+			return err
+		}
+		links, err := getReleaseLinkFlag(command)
+		if err != nil {
+			return err
+		}
+		labels, _ := command.Flags().GetStringArray("label")
+		scheduleAt, err := getTimeFlag(command, "schedule-at")
+		if err != nil {
+			return err
+		}
 
 		request := &model.RingReleaseRequest{
-			Image:   image,
-			Version: version,
-			Force:   force,
+			Image:      image,
+			Version:    version,
+			Force:      force,
+			SoakTime:   soakTime,
+			Type:       releaseType,
+			Parameters: parameters,
+			Links:      links,
+			Labels:     labels,
+			ScheduleAt: scheduleAt * int64(time.Millisecond),
+		}
+
+		if err := request.Validate(); err != nil {
+			return errors.Wrap(err, "invalid request")
 		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
@@ -247,10 +523,13 @@ var ringReleaseCmd = &cobra.Command{
 		}
 
 		if resumeRelease {
-			err := client.ResumeRelease()
+			rings, err := client.ResumeRelease()
 			if err != nil {
 				return errors.Wrap(err, "failed to resume all paused releases")
 			}
+			if err = printJSON(rings); err != nil {
+				return errors.Wrap(err, "failed to print resumed rings")
+			}
 
 			return nil
 		}
@@ -264,6 +543,18 @@ var ringReleaseCmd = &cobra.Command{
 			return nil
 		}
 
+		if prepareRelease {
+			ring, err := client.PrepareRingRelease(ringID, request)
+			if err != nil {
+				return errors.Wrapf(err, "failed to prepare the release of ring %s", ringID)
+			}
+			if err = printJSON(ring); err != nil {
+				return errors.Wrapf(err, "failed to print ring %s release response", ringID)
+			}
+
+			return nil
+		}
+
 		if releaseAllRings {
 			rings, err := client.ReleaseAllRings(request)
 			if err != nil {
@@ -286,6 +577,34 @@ var ringReleaseCmd = &cobra.Command{
 	},
 }
 
+var ringReleaseHealthCmd = &cobra.Command{
+	Use:   "release-health",
+	Short: "Compare the health of the installation groups of a release before the release and after the soak.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		releaseID, _ := command.Flags().GetString("release")
+		ringID, _ := command.Flags().GetString("ring")
+		diffs, err := client.GetRingReleaseHealth(releaseID, ringID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query health of ring release %s", releaseID)
+		}
+
+		if err = printJSON(diffs); err != nil {
+			return errors.Wrapf(err, "failed to print health of ring release %s response", releaseID)
+		}
+
+		return nil
+	},
+}
+
 var ringReleaseGetCmd = &cobra.Command{
 	Use:   "get-release",
 	Short: "Get a particular ring release.",
@@ -297,7 +616,7 @@ var ringReleaseGetCmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		releaseID, _ := command.Flags().GetString("release")
 		ringRelease, err := client.GetRingRelease(releaseID)
@@ -327,11 +646,12 @@ var ringDeleteCmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		ringID, _ := command.Flags().GetString("ring")
+		deleteAfter, _ := command.Flags().GetInt("delete-after")
 
-		err := client.DeleteRing(ringID)
+		err := client.DeleteRingWithRequest(ringID, &model.DeleteRingRequest{DeleteAfter: deleteAfter})
 		if err != nil {
 			return errors.Wrapf(err, "failed to delete ring %s", ringID)
 		}
@@ -340,6 +660,140 @@ var ringDeleteCmd = &cobra.Command{
 	},
 }
 
+var ringCancelDeletionCmd = &cobra.Command{
+	Use:   "cancel-deletion",
+	Short: "Cancel the pending deletion of a ring during its grace period.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		ringID, _ := command.Flags().GetString("ring")
+		ring, err := client.CancelRingDeletion(ringID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to cancel ring %s deletion", ringID)
+		}
+
+		if err = printJSON(ring); err != nil {
+			return errors.Wrapf(err, "failed to print ring %s response", ringID)
+		}
+
+		return nil
+	},
+}
+
+var ringScheduledReleasesCmd = &cobra.Command{
+	Use:   "scheduled-releases",
+	Short: "List the releases of the rings scheduled for later.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		scheduledReleases, err := client.GetScheduledReleases()
+		if err != nil {
+			return errors.Wrap(err, "failed to get scheduled releases")
+		}
+
+		if err = printJSON(scheduledReleases); err != nil {
+			return errors.Wrap(err, "failed to print scheduled releases")
+		}
+
+		return nil
+	},
+}
+
+var ringCancelReleaseCmd = &cobra.Command{
+	Use:   "cancel-release",
+	Short: "Cancel the scheduled release of a ring before it starts.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		ringID, _ := command.Flags().GetString("ring")
+		ring, err := client.CancelScheduledRelease(ringID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to cancel ring %s scheduled release", ringID)
+		}
+
+		if err = printJSON(ring); err != nil {
+			return errors.Wrapf(err, "failed to print ring %s response", ringID)
+		}
+
+		return nil
+	},
+}
+
+var ringPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause the pending or in flight release of a ring.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		ringID, _ := command.Flags().GetString("ring")
+		ring, err := client.PauseRing(ringID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to pause ring %s release", ringID)
+		}
+
+		if err = printJSON(ring); err != nil {
+			return errors.Wrapf(err, "failed to print ring %s response", ringID)
+		}
+
+		return nil
+	},
+}
+
+var ringResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume the paused release of a ring where it was paused.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		ringID, _ := command.Flags().GetString("ring")
+		ring, err := client.ResumeRing(ringID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to resume ring %s release", ringID)
+		}
+
+		if err = printJSON(ring); err != nil {
+			return errors.Wrapf(err, "failed to print ring %s response", ringID)
+		}
+
+		return nil
+	},
+}
+
 var ringGetCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Get a particular ring.",
@@ -351,7 +805,7 @@ var ringGetCmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		ringID, _ := command.Flags().GetString("ring")
 		ring, err := client.GetRing(ringID)
@@ -370,6 +824,250 @@ var ringGetCmd = &cobra.Command{
 	},
 }
 
+var ringWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch the release progress of a ring until it is no longer releasing.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		ringID, _ := command.Flags().GetString("ring")
+		interval, _ := command.Flags().GetDuration("interval")
+		for {
+			ring, err := client.GetRing(ringID)
+			if err != nil {
+				return errors.Wrapf(err, "failed to query ring %s", ringID)
+			}
+			if ring == nil {
+				return errors.Errorf("ring %s not found", ringID)
+			}
+
+			var igs []string
+			for _, ig := range ring.InstallationGroups {
+				igs = append(igs, fmt.Sprintf("%s: %s", ig.Name, ig.State))
+			}
+			fmt.Printf("%s  state: %s  eta: %s  lock: %s  installation groups: [%s]\n",
+				time.Now().Format(time.RFC3339), ring.State, formatEstimatedCompletion(ring.EstimatedCompletionAt), formatLock(ring.LockAcquiredBy, ring.LockAcquiredAt), strings.Join(igs, ", "))
+
+			if !ring.HasUnfinishedRelease() {
+				return nil
+			}
+
+			time.Sleep(interval)
+		}
+	},
+}
+
+// getAnnotationsFlag parses the annotation flags as Name=Value pairs,
+// returning nil when none were given.
+func getAnnotationsFlag(command *cobra.Command) (model.Annotations, error) {
+	annotationFlags, _ := command.Flags().GetStringArray("annotation")
+	if len(annotationFlags) == 0 {
+		return nil, nil
+	}
+
+	annotations := make(model.Annotations, len(annotationFlags))
+	for _, annotation := range annotationFlags {
+		parts := strings.SplitN(annotation, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid annotation %q, expected Name=Value", annotation)
+		}
+		annotations[parts[0]] = parts[1]
+	}
+
+	return annotations, nil
+}
+
+// getMetadataFlag returns the JSON object given with the metadata flag, or nil
+// when none was given.
+func getMetadataFlag(command *cobra.Command) model.Metadata {
+	metadata, _ := command.Flags().GetString("metadata")
+	if metadata == "" {
+		return nil
+	}
+
+	return model.Metadata(metadata)
+}
+
+// getTimeWindowsFlag parses the time window flags of the given name, ignoring
+// empty values.
+func getTimeWindowsFlag(command *cobra.Command, name string) (model.TimeWindows, error) {
+	values, _ := command.Flags().GetStringArray(name)
+
+	windows := model.TimeWindows{}
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		window, err := model.ParseTimeWindow(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", name)
+		}
+		windows = append(windows, window)
+	}
+
+	return windows, nil
+}
+
+// getRingDependenciesFlag returns the ring IDs of the given flag, ignoring
+// empty values.
+func getRingDependenciesFlag(command *cobra.Command, name string) model.RingDependencies {
+	values, _ := command.Flags().GetStringArray(name)
+
+	dependencies := model.RingDependencies{}
+	for _, value := range values {
+		if value != "" {
+			dependencies = append(dependencies, value)
+		}
+	}
+
+	return dependencies
+}
+
+// getInstallationGroupEnvFlag parses the installation group environment
+// variable flags as <installation-group>:<NAME>=<value>, returning nil when
+// none were given.
+func getInstallationGroupEnvFlag(command *cobra.Command) (model.ReleaseParameters, error) {
+	envFlags, _ := command.Flags().GetStringArray("installation-group-env")
+	if len(envFlags) == 0 {
+		return nil, nil
+	}
+
+	parameters := make(model.ReleaseParameters)
+	for _, env := range envFlags {
+		installationGroup, variable, found := strings.Cut(env, ":")
+		name, value, hasValue := strings.Cut(variable, "=")
+		if !found || !hasValue || installationGroup == "" || name == "" {
+			return nil, errors.Errorf("invalid installation group environment variable %q, expected <installation-group>:<NAME>=<value>", env)
+		}
+		if parameters[installationGroup] == nil {
+			parameters[installationGroup] = &model.InstallationGroupReleaseParameters{MattermostEnv: make(map[string]string)}
+		}
+		parameters[installationGroup].MattermostEnv[name] = value
+	}
+
+	return parameters, nil
+}
+
+// formatLock formats the instance holding a lock and for how long it has
+// been held, for display.
+func formatLock(lockAcquiredBy *string, lockAcquiredAt int64) string {
+	if lockAcquiredBy == nil || lockAcquiredAt == 0 {
+		return "unlocked"
+	}
+
+	return fmt.Sprintf("%s (for %s)", *lockAcquiredBy, time.Since(time.UnixMilli(lockAcquiredAt)).Round(time.Second))
+}
+
+// formatEstimatedCompletion formats the estimated completion time of a ring
+// release for display.
+func formatEstimatedCompletion(estimatedCompletionAt int64) string {
+	if estimatedCompletionAt == 0 {
+		return "n/a"
+	}
+
+	eta := time.UnixMilli(estimatedCompletionAt)
+	return fmt.Sprintf("%s (in %s)", eta.Format(time.RFC3339), time.Until(eta).Round(time.Second))
+}
+
+var ringRollbackSnapshotCmd = &cobra.Command{
+	Use:   "rollback-snapshot",
+	Short: "Get the snapshot of the release a ring would roll back to.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		ringID, _ := command.Flags().GetString("ring")
+		snapshot, err := client.GetRingRollbackSnapshot(ringID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query ring %s rollback snapshot", ringID)
+		}
+		if snapshot == nil {
+			return nil
+		}
+
+		if err = printJSON(snapshot); err != nil {
+			return errors.Wrapf(err, "failed to print ring %s rollback snapshot response", ringID)
+		}
+
+		return nil
+	},
+}
+
+var ringTimelineCmd = &cobra.Command{
+	Use:   "timeline",
+	Short: "Get the timeline of the latest releases of a ring.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		ringID, _ := command.Flags().GetString("ring")
+		releases, _ := command.Flags().GetInt("releases")
+		label, _ := command.Flags().GetString("label")
+		timeline, err := client.GetRingTimelineForLabel(ringID, releases, label)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query ring %s timeline", ringID)
+		}
+		if timeline == nil {
+			return nil
+		}
+
+		if err = printJSON(timeline); err != nil {
+			return errors.Wrapf(err, "failed to print ring %s timeline response", ringID)
+		}
+
+		return nil
+	},
+}
+
+var ringBlockersCmd = &cobra.Command{
+	Use:   "blockers",
+	Short: "Get what keeps the release of a ring, or of its installation groups, from moving forward.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		ringID, _ := command.Flags().GetString("ring")
+		blockers, err := client.GetRingBlockers(ringID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query ring %s release blockers", ringID)
+		}
+		if blockers == nil {
+			return nil
+		}
+
+		if err = printJSON(blockers); err != nil {
+			return errors.Wrapf(err, "failed to print ring %s release blockers response", ringID)
+		}
+
+		return nil
+	},
+}
+
 var ringListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List created rings.",
@@ -381,15 +1079,20 @@ var ringListCmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		page, _ := command.Flags().GetInt("page")
 		perPage, _ := command.Flags().GetInt("per-page")
 		includeDeleted, _ := command.Flags().GetBool("include-deleted")
+		asOf, err := getTimeFlag(command, "as-of")
+		if err != nil {
+			return err
+		}
 		rings, err := client.GetRings(&model.GetRingsRequest{
 			Page:           page,
 			PerPage:        perPage,
 			IncludeDeleted: includeDeleted,
+			AsOf:           asOf,
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to query rings")
@@ -400,7 +1103,7 @@ var ringListCmd = &cobra.Command{
 			table := tablewriter.NewWriter(os.Stdout)
 			table.SetAlignment(tablewriter.ALIGN_LEFT)
 			table.SetRowLine(true)
-			table.SetHeader([]string{"ID", "STATE", "NAME", "PRIORITY", "INSTALLATION GROUPS", "SOAK TIME", "REMAINING SOAK TIME", "ACTIVERELEASE", "DESIREDRELEASE", "FORCE", "RELEASE AT"})
+			table.SetHeader([]string{"ID", "STATE", "NAME", "PRIORITY", "INSTALLATION GROUPS", "SOAK TIME", "REMAINING SOAK TIME", "ETA", "ACTIVERELEASE", "DESIREDRELEASE", "FORCE", "TYPE", "RELEASE AT", "LOCK"})
 
 			for _, ring := range rings {
 				activeRelease, err := client.GetRingRelease(ring.ActiveReleaseID)
@@ -415,14 +1118,18 @@ var ringListCmd = &cobra.Command{
 				var igs []string
 				if len(ring.InstallationGroups) > 0 {
 					for _, ig := range ring.InstallationGroups {
-						igs = append(igs, fmt.Sprintf("Name: %s, State: %s, Soaking: %d, Provisioner Group: %s, ReleaseAt: %d", ig.Name, ig.State, ig.SoakTime, ig.ProvisionerGroupID, ig.ReleaseAt))
+						line := fmt.Sprintf("Name: %s, State: %s, Soaking: %d, Provisioner Group: %s, ReleaseAt: %d, Lock: %s", ig.Name, ig.State, ig.CurrentSoakTime(), ig.ProvisionerGroupID, ig.ReleaseAt, formatLock(ig.LockAcquiredBy, ig.LockAcquiredAt))
+						if ig.Drifted {
+							line += fmt.Sprintf(", DRIFTED: running %s", ig.ObservedRelease)
+						}
+						igs = append(igs, line)
 					}
 
 				}
 				var remainTime = int64(0)
 				timePassed := ((time.Now().UnixNano() - ring.ReleaseAt) / int64(time.Second))
-				if timePassed < int64(ring.SoakTime) {
-					remainTime = int64(ring.SoakTime) - timePassed
+				if timePassed < int64(ring.CurrentSoakTime()) {
+					remainTime = int64(ring.CurrentSoakTime()) - timePassed
 				}
 				table.Append([]string{
 					ring.ID,
@@ -430,12 +1137,15 @@ var ringListCmd = &cobra.Command{
 					ring.Name,
 					strconv.Itoa(ring.Priority),
 					strings.Join(igs, "\n"),
-					strconv.Itoa(ring.SoakTime),
+					strconv.Itoa(ring.CurrentSoakTime()),
 					strconv.FormatInt(remainTime, 10),
+					formatEstimatedCompletion(ring.EstimatedCompletionAt),
 					fmt.Sprintf("%s:%s", activeRelease.Image, activeRelease.Version),
 					fmt.Sprintf("%s:%s", desiredRelease.Image, desiredRelease.Version),
 					strconv.FormatBool(desiredRelease.Force),
+					desiredRelease.Type,
 					strconv.FormatInt(ring.ReleaseAt, 10),
+					formatLock(ring.LockAcquiredBy, ring.LockAcquiredAt),
 				})
 			}
 			table.Render()
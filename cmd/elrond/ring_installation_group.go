@@ -16,6 +16,20 @@ func init() {
 	ringInstallationGroupRegisterCmd.Flags().String("ring", "", "The id of the ring to register the installation groups.")
 	ringInstallationGroupRegisterCmd.Flags().String("provisioner-group-id", "", "The id of the provisioner group that will have 1to1 relationship with the elrond installation group.")
 	ringInstallationGroupRegisterCmd.Flags().Int("soak-time", 0, "The soak time to consider an installation group release stable.")
+	ringInstallationGroupRegisterCmd.Flags().String("release-strategy", "", "How the installation group is released: rolling (default) or blue-green.")
+	ringInstallationGroupRegisterCmd.Flags().String("failure-domain", "", "The failure domain of the installation group, such as its availability zone or region.")
+	ringInstallationGroupRegisterCmd.Flags().StringArray("annotation", []string{}, "An annotation forwarded to the provisioner with every call made for the installation group, as Name=Value. Accepts multiple values.")
+	ringInstallationGroupRegisterCmd.Flags().String("metadata", "", "A JSON object included verbatim in the webhook payloads and events of the installation group, e.g. '{\"customer\":\"acme\"}'.")
+	ringInstallationGroupRegisterCmd.Flags().Int64("release-load-threshold", 0, "The number of active users of an installation above which the installation group releases are deferred. Set to 0 to disable.")
+	ringInstallationGroupRegisterCmd.Flags().Int("release-load-max-wait", 0, "The time in seconds a release is deferred by the installation load at most. Defaults to 3600.")
+	ringInstallationGroupRegisterCmd.Flags().StringArray("soak-check", []string{}, "A Prometheus query that must hold while the installation group soaks, as \"<name>=<query> <comparison> <threshold>\", such as \"error-rate=sum(rate(errors[5m])) < 0.01\". Accepts multiple values.")
+	ringInstallationGroupRegisterCmd.Flags().StringArray("release-window", []string{}, "A window the releases of the installation group start within, such as its maintenance hours, in the same format as the ring --soak-window. Accepts multiple values.")
+	ringInstallationGroupRegisterCmd.Flags().String("verification-url", "", "The URL starting the verification job of each release of the installation group, which must pass before it soaks.")
+	ringInstallationGroupRegisterCmd.Flags().Int("verification-timeout", 0, "The time in seconds the verification job of a release runs at most. Defaults to 1800.")
+	ringInstallationGroupRegisterCmd.Flags().Bool("database-snapshot", false, "Whether to snapshot the databases of the installations before each release of the installation group.")
+	ringInstallationGroupRegisterCmd.Flags().Int64("installation-count", 0, "The number of installations of the installation group, until it is synced from the provisioner.")
+	ringInstallationGroupRegisterCmd.Flags().Int64("customer-count", 0, "The number of customers of the installation group, until it is synced from the provisioner.")
+	ringInstallationGroupRegisterCmd.Flags().Int64("capacity-target", 0, "The number of installations the installation group is planned to hold.")
 	ringInstallationGroupRegisterCmd.MarkFlagRequired("ring")
 	ringInstallationGroupRegisterCmd.MarkFlagRequired("installation-group-name")
 	ringInstallationGroupRegisterCmd.MarkFlagRequired("provisioner-group-id")
@@ -24,6 +38,20 @@ func init() {
 	ringInstallationGroupUpdateCmd.Flags().String("name", "", "The name to set to the installation group.")
 	ringInstallationGroupUpdateCmd.Flags().String("provisioner-group-id", "", "The id of the provisioner group that will have 1to1 relationship with the elrond installation group.")
 	ringInstallationGroupUpdateCmd.Flags().Int("soak-time", 0, "The soak time to set to the installation group.")
+	ringInstallationGroupUpdateCmd.Flags().String("release-strategy", "", "How the installation group is released: rolling or blue-green.")
+	ringInstallationGroupUpdateCmd.Flags().String("failure-domain", "", "The failure domain of the installation group, such as its availability zone or region. Pass an empty value to remove it.")
+	ringInstallationGroupUpdateCmd.Flags().StringArray("annotation", []string{}, "An annotation forwarded to the provisioner with every call made for the installation group, replacing the current ones, as Name=Value. Accepts multiple values.")
+	ringInstallationGroupUpdateCmd.Flags().String("metadata", "", "A JSON object included verbatim in the webhook payloads and events of the installation group, replacing the current one.")
+	ringInstallationGroupUpdateCmd.Flags().Int64("release-load-threshold", 0, "The number of active users of an installation above which the installation group releases are deferred. Pass 0 to disable.")
+	ringInstallationGroupUpdateCmd.Flags().Int("release-load-max-wait", 0, "The time in seconds a release is deferred by the installation load at most. Pass 0 for the default of 3600.")
+	ringInstallationGroupUpdateCmd.Flags().StringArray("soak-check", []string{}, "A Prometheus query that must hold while the installation group soaks, replacing the current ones, as \"<name>=<query> <comparison> <threshold>\". Pass an empty value to remove them all. Accepts multiple values.")
+	ringInstallationGroupUpdateCmd.Flags().StringArray("release-window", []string{}, "A window the releases of the installation group start within, replacing the current ones, in the same format as the ring --soak-window. Pass an empty value to remove them all. Accepts multiple values.")
+	ringInstallationGroupUpdateCmd.Flags().String("verification-url", "", "The URL starting the verification job of each release of the installation group. Pass an empty value to disable the verification.")
+	ringInstallationGroupUpdateCmd.Flags().Int("verification-timeout", 0, "The time in seconds the verification job of a release runs at most. Pass 0 for the default of 1800.")
+	ringInstallationGroupUpdateCmd.Flags().Bool("database-snapshot", false, "Whether to snapshot the databases of the installations before each release of the installation group.")
+	ringInstallationGroupUpdateCmd.Flags().Int64("installation-count", 0, "The number of installations of the installation group, until it is synced from the provisioner again.")
+	ringInstallationGroupUpdateCmd.Flags().Int64("customer-count", 0, "The number of customers of the installation group, until it is synced from the provisioner again.")
+	ringInstallationGroupUpdateCmd.Flags().Int64("capacity-target", 0, "The number of installations the installation group is planned to hold. Pass 0 to remove it.")
 	ringInstallationGroupUpdateCmd.MarkFlagRequired("installation-group")
 
 	ringInstallationGroupDeleteCmd.Flags().String("installation-group", "", "ID of the installation group to be removed from the ring.")
@@ -31,9 +59,29 @@ func init() {
 	ringInstallationGroupDeleteCmd.MarkFlagRequired("ring")
 	ringInstallationGroupDeleteCmd.MarkFlagRequired("installation-group")
 
+	ringInstallationGroupArchiveCmd.Flags().String("installation-group", "", "The id of the installation group to be archived.")
+	ringInstallationGroupArchiveCmd.Flags().Bool("unarchive", false, "Unarchive the installation group instead, protecting it from deletion again while it is referenced.")
+	ringInstallationGroupArchiveCmd.MarkFlagRequired("installation-group")
+
+	ringInstallationGroupReleaseCmd.Flags().String("installation-group", "", "The id of the installation group whose failed release is released again.")
+	ringInstallationGroupReleaseCmd.Flags().Bool("force", false, "Skip the soak of the installation group.")
+	ringInstallationGroupReleaseCmd.MarkFlagRequired("installation-group")
+
+	ringInstallationGroupGetCmd.Flags().String("installation-group", "", "The id of the installation group to be fetched.")
+	ringInstallationGroupGetCmd.MarkFlagRequired("installation-group")
+
+	ringInstallationGroupSoakChecksCmd.Flags().String("installation-group", "", "The id of the installation group whose soak check results are fetched.")
+	ringInstallationGroupSoakChecksCmd.Flags().Int("page", 0, "The page of soak check results to fetch, starting at 0.")
+	ringInstallationGroupSoakChecksCmd.Flags().Int("per-page", 100, "The number of soak check results to fetch per page.")
+	ringInstallationGroupSoakChecksCmd.MarkFlagRequired("installation-group")
+
 	ringInstallationGroupCmd.AddCommand(ringInstallationGroupRegisterCmd)
+	ringInstallationGroupCmd.AddCommand(ringInstallationGroupGetCmd)
 	ringInstallationGroupCmd.AddCommand(ringInstallationGroupUpdateCmd)
 	ringInstallationGroupCmd.AddCommand(ringInstallationGroupDeleteCmd)
+	ringInstallationGroupCmd.AddCommand(ringInstallationGroupArchiveCmd)
+	ringInstallationGroupCmd.AddCommand(ringInstallationGroupReleaseCmd)
+	ringInstallationGroupCmd.AddCommand(ringInstallationGroupSoakChecksCmd)
 }
 
 var ringInstallationGroupCmd = &cobra.Command{
@@ -48,17 +96,57 @@ var ringInstallationGroupRegisterCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		ringID, _ := command.Flags().GetString("ring")
 		installationGroupName, _ := command.Flags().GetString("installation-group-name")
 		soakTime, _ := command.Flags().GetInt("soak-time")
 		provisionerGroupID, _ := command.Flags().GetString("provisioner-group-id")
+		releaseStrategy, _ := command.Flags().GetString("release-strategy")
+		releaseLoadThreshold, _ := command.Flags().GetInt64("release-load-threshold")
+		releaseLoadMaxWait, _ := command.Flags().GetInt("release-load-max-wait")
+		annotations, err := getAnnotationsFlag(command)
+		if err != nil {
+			return err
+		}
+		soakChecks, err := getSoakChecksFlag(command)
+		if err != nil {
+			return err
+		}
+		releaseWindows, err := getTimeWindowsFlag(command, "release-window")
+		if err != nil {
+			return err
+		}
+		verificationURL, _ := command.Flags().GetString("verification-url")
+		verificationTimeout, _ := command.Flags().GetInt("verification-timeout")
+		databaseSnapshot, _ := command.Flags().GetBool("database-snapshot")
+		installationCount, _ := command.Flags().GetInt64("installation-count")
+		customerCount, _ := command.Flags().GetInt64("customer-count")
+		capacityTarget, _ := command.Flags().GetInt64("capacity-target")
+		failureDomain, _ := command.Flags().GetString("failure-domain")
 
 		request := &model.RegisterInstallationGroupRequest{
-			Name:               installationGroupName,
-			SoakTime:           soakTime,
-			ProvisionerGroupID: provisionerGroupID,
+			Name:                 installationGroupName,
+			SoakTime:             soakTime,
+			ProvisionerGroupID:   provisionerGroupID,
+			Annotations:          annotations,
+			Metadata:             getMetadataFlag(command),
+			ReleaseStrategy:      releaseStrategy,
+			ReleaseLoadThreshold: releaseLoadThreshold,
+			ReleaseLoadMaxWait:   releaseLoadMaxWait,
+			SoakChecks:           soakChecks,
+			ReleaseWindows:       releaseWindows,
+			VerificationURL:      verificationURL,
+			VerificationTimeout:  verificationTimeout,
+			DatabaseSnapshot:     databaseSnapshot,
+			InstallationCount:    installationCount,
+			CustomerCount:        customerCount,
+			CapacityTarget:       capacityTarget,
+			FailureDomain:        failureDomain,
+		}
+
+		if err := request.Validate(); err != nil {
+			return errors.Wrap(err, "invalid request")
 		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
@@ -87,7 +175,7 @@ var ringInstallationGroupDeleteCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		RingID, _ := command.Flags().GetString("ring")
 		installationGroup, _ := command.Flags().GetString("installation-group")
@@ -101,6 +189,116 @@ var ringInstallationGroupDeleteCmd = &cobra.Command{
 	},
 }
 
+var ringInstallationGroupArchiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Archive an installation group, allowing its deletion although recent release history or a rollback target still references it.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(command, serverAddress)
+
+		installationGroupID, _ := command.Flags().GetString("installation-group")
+		unarchive, _ := command.Flags().GetBool("unarchive")
+
+		var installationGroup *model.InstallationGroup
+		var err error
+		if unarchive {
+			installationGroup, err = client.UnarchiveInstallationGroup(installationGroupID)
+		} else {
+			installationGroup, err = client.ArchiveInstallationGroup(installationGroupID)
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to change installation group archive")
+		}
+
+		if err = printJSON(installationGroup); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var ringInstallationGroupReleaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Release again, on its own, an installation group whose release failed, resuming the release of its ring.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(command, serverAddress)
+
+		installationGroupID, _ := command.Flags().GetString("installation-group")
+		force, _ := command.Flags().GetBool("force")
+
+		installationGroup, err := client.ReleaseInstallationGroup(installationGroupID, &model.InstallationGroupReleaseRequest{Force: force})
+		if err != nil {
+			return errors.Wrap(err, "failed to release installation group")
+		}
+
+		if err = printJSON(installationGroup); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var ringInstallationGroupGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get a particular installation group, including the instance holding its lock.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(command, serverAddress)
+
+		installationGroupID, _ := command.Flags().GetString("installation-group")
+		installationGroup, err := client.GetInstallationGroup(installationGroupID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query installation group %s", installationGroupID)
+		}
+		if installationGroup == nil {
+			return nil
+		}
+
+		if err = printJSON(installationGroup); err != nil {
+			return errors.Wrapf(err, "failed to print installation group %s response", installationGroupID)
+		}
+
+		return nil
+	},
+}
+
+var ringInstallationGroupSoakChecksCmd = &cobra.Command{
+	Use:   "soakchecks",
+	Short: "Get the soak check results of an installation group, newest first.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		client := newClient(command, serverAddress)
+
+		installationGroupID, _ := command.Flags().GetString("installation-group")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		results, err := client.GetInstallationGroupSoakChecks(installationGroupID, &model.GetSoakCheckResultsRequest{
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to query soak check results of installation group %s", installationGroupID)
+		}
+
+		if err = printJSON(results); err != nil {
+			return errors.Wrap(err, "failed to print soak check results")
+		}
+
+		return nil
+	},
+}
+
 var ringInstallationGroupUpdateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Updates installation group from the ring.",
@@ -108,17 +306,79 @@ var ringInstallationGroupUpdateCmd = &cobra.Command{
 		command.SilenceUsage = true
 
 		serverAddress, _ := command.Flags().GetString("server")
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		installationGroupID, _ := command.Flags().GetString("installation-group")
 		name, _ := command.Flags().GetString("name")
 		soakTime, _ := command.Flags().GetInt("soak-time")
 		provisionerGroupID, _ := command.Flags().GetString("provisioner-group-id")
+		releaseStrategy, _ := command.Flags().GetString("release-strategy")
+		annotations, err := getAnnotationsFlag(command)
+		if err != nil {
+			return err
+		}
 
 		request := &model.UpdateInstallationGroupRequest{
 			Name:               name,
 			SoakTime:           soakTime,
 			ProvisionerGroupID: provisionerGroupID,
+			Annotations:        annotations,
+			Metadata:           getMetadataFlag(command),
+			ReleaseStrategy:    releaseStrategy,
+		}
+		if command.Flags().Changed("release-load-threshold") {
+			releaseLoadThreshold, _ := command.Flags().GetInt64("release-load-threshold")
+			request.ReleaseLoadThreshold = &releaseLoadThreshold
+		}
+		if command.Flags().Changed("release-load-max-wait") {
+			releaseLoadMaxWait, _ := command.Flags().GetInt("release-load-max-wait")
+			request.ReleaseLoadMaxWait = &releaseLoadMaxWait
+		}
+		if command.Flags().Changed("soak-check") {
+			soakChecks, err := getSoakChecksFlag(command)
+			if err != nil {
+				return err
+			}
+			request.SoakChecks = &soakChecks
+		}
+		if command.Flags().Changed("release-window") {
+			releaseWindows, err := getTimeWindowsFlag(command, "release-window")
+			if err != nil {
+				return err
+			}
+			request.ReleaseWindows = &releaseWindows
+		}
+		if command.Flags().Changed("verification-url") {
+			verificationURL, _ := command.Flags().GetString("verification-url")
+			request.VerificationURL = &verificationURL
+		}
+		if command.Flags().Changed("verification-timeout") {
+			verificationTimeout, _ := command.Flags().GetInt("verification-timeout")
+			request.VerificationTimeout = &verificationTimeout
+		}
+		if command.Flags().Changed("database-snapshot") {
+			databaseSnapshot, _ := command.Flags().GetBool("database-snapshot")
+			request.DatabaseSnapshot = &databaseSnapshot
+		}
+		if command.Flags().Changed("installation-count") {
+			installationCount, _ := command.Flags().GetInt64("installation-count")
+			request.InstallationCount = &installationCount
+		}
+		if command.Flags().Changed("customer-count") {
+			customerCount, _ := command.Flags().GetInt64("customer-count")
+			request.CustomerCount = &customerCount
+		}
+		if command.Flags().Changed("capacity-target") {
+			capacityTarget, _ := command.Flags().GetInt64("capacity-target")
+			request.CapacityTarget = &capacityTarget
+		}
+		if command.Flags().Changed("failure-domain") {
+			failureDomain, _ := command.Flags().GetString("failure-domain")
+			request.FailureDomain = &failureDomain
+		}
+
+		if err := request.Validate(); err != nil {
+			return errors.Wrap(err, "invalid request")
 		}
 
 		dryRun, _ := command.Flags().GetBool("dry-run")
@@ -152,3 +412,22 @@ func runDryRun(request interface{}) error {
 
 	return nil
 }
+
+// getSoakChecksFlag parses the soak check flags, ignoring empty values.
+func getSoakChecksFlag(command *cobra.Command) (model.SoakChecks, error) {
+	values, _ := command.Flags().GetStringArray("soak-check")
+
+	checks := model.SoakChecks{}
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		check, err := model.ParseSoakCheck(value)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid soak-check")
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
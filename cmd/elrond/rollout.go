@@ -0,0 +1,319 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rolloutCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(rolloutCmd)
+
+	rolloutCreateCmd.Flags().String("name", "", "The name of the rollout, such as the version it ships.")
+	rolloutCreateCmd.Flags().String("image", "", "The Mattermost image to release to.")
+	rolloutCreateCmd.Flags().String("version", "", "The Mattermost version to release to.")
+	rolloutCreateCmd.Flags().Bool("force", false, "When set to true a release is forced and soaking times are ignored.")
+	rolloutCreateCmd.Flags().String("type", model.ReleaseTypeStandard, "The release type, one of standard, hotfix or rollback. Hotfixes are soaked for a shorter time.")
+	rolloutCreateCmd.Flags().Int("soak-time", 0, "The soak time in seconds overriding the ring and installation group soak times for this release.")
+	rolloutCreateCmd.Flags().StringArray("installation-group-env", []string{}, "An environment variable set on the installations of one installation group by this release, as <installation-group>:<NAME>=<value>. Accepts multiple values.")
+	rolloutCreateCmd.Flags().StringArray("step", []string{}, "A step of the rollout, as <name>=<ring-id>[,<ring-id>...]. Steps are released in the given order. Accepts multiple values.")
+	rolloutCreateCmd.Flags().Int64("promotion-window", 0, "The time in seconds the rings of each step but the last must run the release with passing promotion checks before the next step starts.")
+	rolloutCreateCmd.Flags().StringArray("promotion-check", []string{}, "A Prometheus query that must hold during the promotion window of each step but the last, as \"<name>=<query> <comparison> <threshold>\". A breach pauses the rollout. Accepts multiple values.")
+	rolloutCreateCmd.Flags().Bool("dry-run", false, "When set to true, only print the API request without sending it.")
+	rolloutCreateCmd.MarkFlagRequired("image")   //nolint
+	rolloutCreateCmd.MarkFlagRequired("version") //nolint
+	rolloutCreateCmd.MarkFlagRequired("step")    //nolint
+
+	rolloutGetCmd.Flags().String("rollout", "", "The id of the rollout to be fetched.")
+	rolloutGetCmd.MarkFlagRequired("rollout") //nolint
+
+	rolloutListCmd.Flags().Int("page", 0, "The page of rollouts to fetch, starting at 0.")
+	rolloutListCmd.Flags().Int("per-page", 100, "The number of rollouts to fetch per page.")
+
+	rolloutCancelCmd.Flags().String("rollout", "", "The id of the rollout to be cancelled.")
+	rolloutCancelCmd.MarkFlagRequired("rollout") //nolint
+
+	rolloutRollbackCmd.Flags().String("rollout", "", "The id of the rollout to be rolled back.")
+	rolloutRollbackCmd.MarkFlagRequired("rollout") //nolint
+
+	rolloutResumeCmd.Flags().String("rollout", "", "The id of the paused rollout to be resumed.")
+	rolloutResumeCmd.MarkFlagRequired("rollout") //nolint
+
+	rolloutCmd.AddCommand(rolloutCreateCmd)
+	rolloutCmd.AddCommand(rolloutGetCmd)
+	rolloutCmd.AddCommand(rolloutListCmd)
+	rolloutCmd.AddCommand(rolloutCancelCmd)
+	rolloutCmd.AddCommand(rolloutRollbackCmd)
+	rolloutCmd.AddCommand(rolloutResumeCmd)
+}
+
+var rolloutCmd = &cobra.Command{
+	Use:   "rollout",
+	Short: "Release a release to rings in a sequence of steps, tracked as a single rollout.",
+}
+
+var rolloutCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a rollout.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+		name, _ := command.Flags().GetString("name")
+		image, _ := command.Flags().GetString("image")
+		version, _ := command.Flags().GetString("version")
+		force, _ := command.Flags().GetBool("force")
+		soakTime, _ := command.Flags().GetInt("soak-time")
+		releaseType, _ := command.Flags().GetString("type")
+
+		parameters, err := getInstallationGroupEnvFlag(command)
+		if err != nil {
+			return err
+		}
+		steps, err := getRolloutStepFlag(command)
+		if err != nil {
+			return err
+		}
+
+		request := &model.CreateRolloutRequest{
+			Name: name,
+			RingReleaseRequest: model.RingReleaseRequest{
+				Image:      image,
+				Version:    version,
+				Force:      force,
+				SoakTime:   soakTime,
+				Type:       releaseType,
+				Parameters: parameters,
+			},
+			Steps: steps,
+		}
+
+		if err := request.Validate(); err != nil {
+			return errors.Wrap(err, "invalid request")
+		}
+
+		dryRun, _ := command.Flags().GetBool("dry-run")
+		if dryRun {
+			err = printJSON(request)
+			if err != nil {
+				return errors.Wrap(err, "failed to print API request")
+			}
+
+			return nil
+		}
+
+		rollout, err := client.CreateRollout(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to create rollout")
+		}
+
+		err = printJSON(rollout)
+		if err != nil {
+			return errors.Wrap(err, "failed to print rollout response")
+		}
+
+		return nil
+	},
+}
+
+// getRolloutStepFlag parses the steps of a rollout given as
+// <name>=<ring-id>[,<ring-id>...].
+func getRolloutStepFlag(command *cobra.Command) ([]*model.RolloutStep, error) {
+	values, _ := command.Flags().GetStringArray("step")
+
+	var steps []*model.RolloutStep
+	for _, value := range values {
+		name, ringIDs, found := strings.Cut(value, "=")
+		if !found || ringIDs == "" {
+			return nil, errors.Errorf("invalid step %q: must be <name>=<ring-id>[,<ring-id>...]", value)
+		}
+		steps = append(steps, &model.RolloutStep{
+			Name:    name,
+			RingIDs: strings.Split(ringIDs, ","),
+		})
+	}
+
+	promotionWindow, _ := command.Flags().GetInt64("promotion-window")
+	promotionChecks, err := getPromotionCheckFlag(command)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(steps)-1; i++ {
+		steps[i].PromotionWindow = promotionWindow
+		steps[i].PromotionChecks = promotionChecks
+	}
+
+	return steps, nil
+}
+
+// getPromotionCheckFlag parses the promotion checks of a rollout given as
+// <name>=<query> <comparison> <threshold>.
+func getPromotionCheckFlag(command *cobra.Command) (model.SoakChecks, error) {
+	values, _ := command.Flags().GetStringArray("promotion-check")
+
+	var checks model.SoakChecks
+	for _, value := range values {
+		check, err := model.ParseSoakCheck(value)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid promotion-check")
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+var rolloutGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get a particular rollout, with the progress of its rings.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		rolloutID, _ := command.Flags().GetString("rollout")
+		rollout, err := client.GetRollout(rolloutID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query rollout %s", rolloutID)
+		}
+		if rollout == nil {
+			return nil
+		}
+
+		if err = printJSON(rollout); err != nil {
+			return errors.Wrapf(err, "failed to print rollout %s response", rolloutID)
+		}
+
+		return nil
+	},
+}
+
+var rolloutListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the rollouts, newest first.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		rollouts, err := client.GetRollouts(&model.GetRolloutsRequest{
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query rollouts")
+		}
+
+		if err = printJSON(rollouts); err != nil {
+			return errors.Wrap(err, "failed to print rollouts response")
+		}
+
+		return nil
+	},
+}
+
+var rolloutCancelCmd = &cobra.Command{
+	Use:   "cancel",
+	Short: "Cancel the pending releases and remaining steps of a rollout.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		rolloutID, _ := command.Flags().GetString("rollout")
+		rollout, err := client.CancelRollout(rolloutID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to cancel rollout %s", rolloutID)
+		}
+
+		if err = printJSON(rollout); err != nil {
+			return errors.Wrapf(err, "failed to print rollout %s response", rolloutID)
+		}
+
+		return nil
+	},
+}
+
+var rolloutRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Release the rings of a rollout back to the release they ran before it.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		rolloutID, _ := command.Flags().GetString("rollout")
+		rollout, err := client.RollbackRollout(rolloutID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to roll back rollout %s", rolloutID)
+		}
+
+		if err = printJSON(rollout); err != nil {
+			return errors.Wrapf(err, "failed to print rollout %s response", rolloutID)
+		}
+
+		return nil
+	},
+}
+
+var rolloutResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume a rollout paused by a breached promotion check, restarting the promotion window of its current step.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		rolloutID, _ := command.Flags().GetString("rollout")
+		rollout, err := client.ResumeRollout(rolloutID)
+		if err != nil {
+			return errors.Wrapf(err, "failed to resume rollout %s", rolloutID)
+		}
+
+		if err = printJSON(rollout); err != nil {
+			return errors.Wrapf(err, "failed to print rollout %s response", rolloutID)
+		}
+
+		return nil
+	},
+}
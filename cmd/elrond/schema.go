@@ -22,7 +22,9 @@ var schemaCmd = &cobra.Command{
 
 func sqlStore(command *cobra.Command) (*store.SQLStore, error) {
 	database, _ := command.Flags().GetString("database")
-	sqlStore, err := store.New(database, logger)
+	// Only the server fails over to other databases.
+	failoverDatabases, _ := command.Flags().GetStringArray("database-failover")
+	sqlStore, err := store.New(database, logger, failoverDatabases...)
 	if err != nil {
 		return nil, err
 	}
@@ -14,13 +14,26 @@ import (
 
 func init() {
 	securityCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(securityCmd)
 
 	securityRingCmd.PersistentFlags().String("ring", "", "The id of the ring.")
 	securityRingCmd.MarkPersistentFlagRequired("ring") //nolint
 
+	securityRingProtectCmd.Flags().String("reason", "", "The reason to protect the ring.")
+	securityRingUnprotectCmd.Flags().String("reason", "", "The reason to remove the protection of the ring.")
+	securityRingUnprotectCmd.MarkFlagRequired("reason") //nolint
+
+	securityForceApprovalsCmd.Flags().String("ring", "", "When set, only list the forced actions on this ring.")
+	securityForceApprovalsCmd.Flags().Int("page", 0, "The page of force approvals to fetch, starting at 0.")
+	securityForceApprovalsCmd.Flags().Int("per-page", 100, "The number of force approvals to fetch per page.")
+
 	securityCmd.AddCommand(securityRingCmd)
+	securityCmd.AddCommand(securityForceApprovalsCmd)
 	securityRingCmd.AddCommand(securityRingLockAPICmd)
 	securityRingCmd.AddCommand(securityRingUnlockAPICmd)
+	securityRingCmd.AddCommand(securityRingProtectCmd)
+	securityRingCmd.AddCommand(securityRingUnprotectCmd)
+	securityRingCmd.AddCommand(securityRingProtectionHistoryCmd)
 }
 
 var securityCmd = &cobra.Command{
@@ -44,7 +57,7 @@ var securityRingLockAPICmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		ringID, _ := command.Flags().GetString("ring")
 		err := client.LockAPIForRing(ringID)
@@ -67,7 +80,7 @@ var securityRingUnlockAPICmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		ringID, _ := command.Flags().GetString("ring")
 		err := client.UnlockAPIForRing(ringID)
@@ -78,3 +91,111 @@ var securityRingUnlockAPICmd = &cobra.Command{
 		return nil
 	},
 }
+
+var securityRingProtectCmd = &cobra.Command{
+	Use:   "protect",
+	Short: "Protect a given ring from deletion and forced releases",
+	RunE: func(command *cobra.Command, args []string) error {
+		return setRingProtected(command, true)
+	},
+}
+
+var securityRingUnprotectCmd = &cobra.Command{
+	Use:   "unprotect",
+	Short: "Remove the protection of a given ring. Requires the admin role.",
+	RunE: func(command *cobra.Command, args []string) error {
+		return setRingProtected(command, false)
+	},
+}
+
+func setRingProtected(command *cobra.Command, protected bool) error {
+	command.SilenceUsage = true
+
+	serverAddress, _ := command.Flags().GetString("server")
+	if _, err := url.Parse(serverAddress); err != nil {
+		return errors.Wrap(err, "provided server address not a valid address")
+	}
+
+	client := newClient(command, serverAddress)
+
+	ringID, _ := command.Flags().GetString("ring")
+	reason, _ := command.Flags().GetString("reason")
+	request := &model.RingProtectionRequest{Reason: reason}
+
+	var ring *model.Ring
+	var err error
+	if protected {
+		ring, err = client.ProtectRing(ringID, request)
+	} else {
+		ring, err = client.UnprotectRing(ringID, request)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to set ring protection")
+	}
+
+	if err = printJSON(ring); err != nil {
+		return errors.Wrap(err, "failed to print ring response")
+	}
+
+	return nil
+}
+
+var securityRingProtectionHistoryCmd = &cobra.Command{
+	Use:   "protection-history",
+	Short: "List the protection changes of a given ring, newest first.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		ringID, _ := command.Flags().GetString("ring")
+		changes, err := client.GetRingProtectionChanges(ringID)
+		if err != nil {
+			return errors.Wrap(err, "failed to query ring protection changes")
+		}
+
+		if err = printJSON(changes); err != nil {
+			return errors.Wrap(err, "failed to print ring protection changes response")
+		}
+
+		return nil
+	},
+}
+
+var securityForceApprovalsCmd = &cobra.Command{
+	Use:   "force-approvals",
+	Short: "List the forced releases and API unlocks requested and confirmed under the two-person rule, newest first.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		ringID, _ := command.Flags().GetString("ring")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		approvals, err := client.GetForceApprovals(&model.GetForceApprovalsRequest{
+			RingID:  ringID,
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query force approvals")
+		}
+
+		if err = printJSON(approvals); err != nil {
+			return errors.Wrap(err, "failed to print force approvals response")
+		}
+
+		return nil
+	},
+}
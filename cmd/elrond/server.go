@@ -7,6 +7,7 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,14 +16,30 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/certreload"
+	"github.com/mattermost/elrond/internal/config"
 	"github.com/mattermost/elrond/internal/elrond"
+	"github.com/mattermost/elrond/internal/eventsink"
+	"github.com/mattermost/elrond/internal/evidence"
+	"github.com/mattermost/elrond/internal/metrics"
+	"github.com/mattermost/elrond/internal/outbound"
+	"github.com/mattermost/elrond/internal/policy"
+	"github.com/mattermost/elrond/internal/secrets"
+	"github.com/mattermost/elrond/internal/serverconfig"
+	"github.com/mattermost/elrond/internal/soakcheck"
 	"github.com/mattermost/elrond/internal/store"
 	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/internal/verification"
+	"github.com/mattermost/elrond/internal/webhook"
+	"github.com/mattermost/elrond/internal/webui"
 
 	"github.com/mattermost/elrond/model"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	logrus "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -31,21 +48,22 @@ const (
 
 var instanceID string
 
+// The names the supervisors are reported under by /readyz and the metrics.
+const (
+	supervisorRollout           = "rollout"
+	supervisorRing              = "ring"
+	supervisorInstallationGroup = "installationgroup"
+	supervisorDriftReconciler   = "drift-reconciler"
+	supervisorSoakTimeAnalyzer  = "soak-time-analyzer"
+	supervisorEventRetention    = "event-retention"
+	supervisorWebhookDelivery   = "webhook-delivery"
+	supervisorJob               = "job"
+)
+
 func init() {
 	instanceID = model.NewID()
 
-	// General
-	serverCmd.PersistentFlags().String("database", "sqlite://elrond.db", "The database backing the elrond server.")
-	serverCmd.PersistentFlags().String("listen", ":3018", "The interface and port on which to listen.")
-	serverCmd.PersistentFlags().Bool("debug", false, "Whether to output debug logs.")
-	serverCmd.PersistentFlags().Bool("machine-readable-logs", false, "Output the logs in machine readable format.")
-	serverCmd.PersistentFlags().String("provisioner-server", "http://localhost:8075", "The provisioning server whose API will be queried.")
-	serverCmd.PersistentFlags().Int("provisioner-group-release-timeout", 3600, "The provisioner group release timeout")
-
-	// Supervisors
-	serverCmd.PersistentFlags().Int("poll", 30, "The interval in seconds to poll for background work.")
-	serverCmd.PersistentFlags().Bool("ring-supervisor", true, "Whether this server will run a ring supervisor or not.")
-	serverCmd.PersistentFlags().Bool("installationgroup-supervisor", true, "Whether this server will run an installation group supervisor or not.")
+	serverconfig.AddFlags(serverCmd.PersistentFlags())
 }
 
 var serverCmd = &cobra.Command{
@@ -54,6 +72,27 @@ var serverCmd = &cobra.Command{
 	RunE: func(command *cobra.Command, args []string) error {
 		command.SilenceUsage = true
 
+		configFile, _ := command.Flags().GetString("config")
+		var configErr error
+		if configFile != "" {
+			if err := config.Load(configFile, command.Flags(), "config", "validate-config", "check"); err != nil {
+				configErr = errors.Wrapf(err, "invalid configuration file %s", configFile)
+			}
+		}
+		if check, _ := command.Flags().GetBool("check"); check {
+			return runServerCheck(command, configErr)
+		}
+		if configErr != nil {
+			return configErr
+		}
+		if err := serverconfig.Validate(command.Flags()); err != nil {
+			return errors.Wrap(err, "invalid server configuration")
+		}
+		if validateConfig, _ := command.Flags().GetBool("validate-config"); validateConfig {
+			logger.Info("Server configuration is valid")
+			return nil
+		}
+
 		debug, _ := command.Flags().GetBool("debug")
 		if debug {
 			logger.SetLevel(logrus.DebugLevel)
@@ -74,6 +113,7 @@ var serverCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		sqlStore.SetInstanceID(instanceID)
 
 		currentVersion, err := sqlStore.GetCurrentVersion()
 		if err != nil {
@@ -87,9 +127,45 @@ var serverCmd = &cobra.Command{
 			return errors.Errorf("server requires at least schema %s, current is %s", serverVersion, currentVersion)
 		}
 
+		outboundConfig := serverconfig.OutboundSettings(command.Flags())
+		webhookTransport, err := outboundConfig.Transport(outbound.TargetWebhook)
+		if err != nil {
+			return errors.Wrap(err, "failed to set up the webhook transport")
+		}
+		webhook.SetTransport(webhookTransport)
+		webhook.SetTemplateStore(sqlStore)
+		webhookMaxWorkers, _ := command.Flags().GetInt("webhook-max-workers")
+		webhookMaxPerTarget, _ := command.Flags().GetInt("webhook-max-per-target")
+		webhook.SetDispatcher(webhook.NewDispatcher(webhookMaxWorkers, webhookMaxPerTarget))
+		webhookTimeout, _ := command.Flags().GetInt("webhook-timeout")
+		webhookMaxRedirects, _ := command.Flags().GetInt("webhook-max-redirects")
+		webhookMaxPayloadSize, _ := command.Flags().GetInt("webhook-max-payload-size")
+		webhook.SetLimits(webhook.Limits{
+			Timeout:        time.Duration(webhookTimeout) * time.Second,
+			MaxRedirects:   webhookMaxRedirects,
+			MaxPayloadSize: webhookMaxPayloadSize,
+		})
+
+		eventSinkURL, _ := command.Flags().GetString("event-sink-elasticsearch-url")
+		if eventSinkURL != "" {
+			eventSinkIndexPattern, _ := command.Flags().GetString("event-sink-index-pattern")
+			transport, err := outboundConfig.Transport(outbound.TargetEventSink)
+			if err != nil {
+				return errors.Wrap(err, "failed to set up the event sink transport")
+			}
+			eventSink, err := eventsink.NewElasticsearchSink(eventSinkURL, eventSinkIndexPattern, transport, logger)
+			if err != nil {
+				return errors.Wrap(err, "failed to create event sink")
+			}
+			logger.WithField("event-sink-index-pattern", eventSinkIndexPattern).Info("Elasticsearch event sink is enabled")
+			sqlStore.SetEventSink(eventSink)
+			defer eventSink.Close()
+		}
+
 		ringSupervisor, _ := command.Flags().GetBool("ring-supervisor")
 		installationGroupSupervisor, _ := command.Flags().GetBool("installationgroup-supervisor")
-		if !ringSupervisor && !installationGroupSupervisor {
+		jobSupervisor, _ := command.Flags().GetBool("job-supervisor")
+		if !ringSupervisor && !installationGroupSupervisor && !jobSupervisor {
 			logger.Warn("Server will be running with no supervisors. Only API functionality will work.")
 		}
 
@@ -103,14 +179,84 @@ var serverCmd = &cobra.Command{
 			"build-hash":                   model.BuildHash,
 			"ring-supervisor":              ringSupervisor,
 			"installationgroup-supervisor": installationGroupSupervisor,
+			"job-supervisor":               jobSupervisor,
 			"store-version":                currentVersion,
 			"working-directory":            wd,
 		}).Info("Starting Mattermost Elrond Server")
 
 		deprecationWarnings(logger, command)
 
+		var soakChecker *soakcheck.PrometheusChecker
+		if prometheusURL, _ := command.Flags().GetString("soak-check-prometheus-url"); prometheusURL != "" {
+			transport, err := outboundConfig.Transport(outbound.TargetPrometheus)
+			if err != nil {
+				return errors.Wrap(err, "failed to set up the prometheus transport")
+			}
+			soakChecker, err = soakcheck.NewPrometheusChecker(prometheusURL, transport)
+			if err != nil {
+				return errors.Wrap(err, "failed to create soak checker")
+			}
+			logger.Info("Soak checks are enabled")
+		}
+
+		var policyEngine *policy.OPAEngine
+		if policyURL, _ := command.Flags().GetString("policy-url"); policyURL != "" {
+			transport, err := outboundConfig.Transport(outbound.TargetPolicy)
+			if err != nil {
+				return errors.Wrap(err, "failed to set up the policy transport")
+			}
+			policyEngine, err = policy.NewOPAEngine(policyURL, transport)
+			if err != nil {
+				return errors.Wrap(err, "failed to create policy engine")
+			}
+			logger.Info("Authorization policy is enabled")
+		}
+
+		verificationTransport, err := outboundConfig.Transport(outbound.TargetVerification)
+		if err != nil {
+			return errors.Wrap(err, "failed to set up the verification transport")
+		}
+		releaseVerifier := verification.NewHTTPVerifier(verificationTransport)
+
+		installationLoadURL, _ := command.Flags().GetString("installation-load-url")
+		provisionerLookupCacheTTL, _ := command.Flags().GetInt("provisioner-lookup-cache-ttl")
+		databaseSnapshotURL, _ := command.Flags().GetString("database-snapshot-url")
+		databaseSnapshotTimeout, _ := command.Flags().GetInt("database-snapshot-timeout")
 		provisioningParams := elrond.ProvisioningParams{
 			ProvisionerGroupReleaseTimeout: provisionerGroupReleaseTimeout,
+			InstallationLoadURL:            installationLoadURL,
+			DatabaseSnapshotURL:            databaseSnapshotURL,
+			DatabaseSnapshotTimeout:        databaseSnapshotTimeout,
+			LookupCacheTTL:                 provisionerLookupCacheTTL,
+		}
+
+		// The provisioner client cannot be given an HTTP client, so the
+		// connections to the provisioner are pooled by the default transport,
+		// falling back to a transport with the outbound settings for the
+		// other requests.
+		provisionerOutbound := outboundConfig.For(outbound.TargetProvisioner)
+		provisionerTLSConfig, err := provisionerOutbound.TLSConfig()
+		if err != nil {
+			return errors.Wrap(err, "failed to set up the provisioner transport")
+		}
+		defaultTransport, err := outboundConfig.Config.Transport()
+		if err != nil {
+			return errors.Wrap(err, "failed to set up the default transport")
+		}
+		provisionerMaxIdleConns, _ := command.Flags().GetInt("provisioner-max-idle-conns")
+		provisionerIdleConnTimeout, _ := command.Flags().GetInt("provisioner-idle-conn-timeout")
+		provisionerKeepAlive, _ := command.Flags().GetInt("provisioner-keep-alive")
+		provisionerRequestTimeout, _ := command.Flags().GetInt("provisioner-request-timeout")
+		http.DefaultTransport, err = elrond.NewProvisionerTransport(provisionerServer, elrond.ProvisionerTransportParams{
+			MaxIdleConns:    provisionerMaxIdleConns,
+			IdleConnTimeout: time.Duration(provisionerIdleConnTimeout) * time.Second,
+			KeepAlive:       time.Duration(provisionerKeepAlive) * time.Second,
+			RequestTimeout:  time.Duration(provisionerRequestTimeout) * time.Second,
+			Proxy:           provisionerOutbound.ProxyFunc(),
+			TLSClientConfig: provisionerTLSConfig,
+		}, defaultTransport)
+		if err != nil {
+			return errors.Wrap(err, "failed to set up the provisioner transport")
 		}
 
 		// Setup the provisioner.
@@ -120,14 +266,143 @@ var serverCmd = &cobra.Command{
 			provisionerServer,
 		)
 
-		var multiDoer supervisor.MultiDoer
-		if ringSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewRingSupervisor(sqlStore, elrondProvisioner, instanceID, logger))
+		var shadowProvisioner *elrond.ShadowProvisioner
+		shadowProvisionerServer, _ := command.Flags().GetString("shadow-provisioner-server")
+		if shadowProvisionerServer != "" {
+			// The shadow provisioner must see the changes of the primary
+			// provisioner right away, so its lookups are never cached.
+			shadowProvisioningParams := provisioningParams
+			shadowProvisioningParams.LookupCacheTTL = 0
+			shadowProvisionerMode, _ := command.Flags().GetString("shadow-provisioner-mode")
+			shadowProvisioner, err = elrond.NewShadowProvisioner(
+				elrondProvisioner,
+				elrond.NewElrondProvisioner(shadowProvisioningParams, logger.WithField("shadow", true), shadowProvisionerServer),
+				shadowProvisionerMode,
+				logger,
+			)
+			if err != nil {
+				return errors.Wrap(err, "failed to set up the shadow provisioner")
+			}
+			logger.WithFields(logrus.Fields{
+				"shadow-provisioner-server": shadowProvisionerServer,
+				"shadow-provisioner-mode":   shadowProvisionerMode,
+			}).Info("Installation group releases are mirrored to the shadow provisioner")
 		}
-		if installationGroupSupervisor {
-			multiDoer = append(multiDoer, supervisor.NewInstallationGroupSupervisor(sqlStore, elrondProvisioner, instanceID, logger))
+
+		var credentialsCipher *secrets.Cipher
+		credentialsEncryptionKey, _ := command.Flags().GetString("credentials-encryption-key")
+		if credentialsEncryptionKey != "" {
+			credentialsCipher, err = secrets.NewCipher(credentialsEncryptionKey)
+			if err != nil {
+				return errors.Wrap(err, "invalid credentials encryption key")
+			}
+			if err = elrondProvisioner.RefreshCredentials(sqlStore, credentialsCipher); err != nil {
+				return errors.Wrap(err, "failed to load provisioner credentials")
+			}
+		} else {
+			logger.Warn("No credentials encryption key set. Provisioner credentials cannot be rotated through the API.")
+		}
+
+		var metricsBackend metrics.Backend
+		var metricsRegistry *prometheus.Registry
+		metricsBackendName, _ := command.Flags().GetString("metrics-backend")
+		switch metricsBackendName {
+		case metrics.BackendStatsD:
+			statsdAddress, _ := command.Flags().GetString("statsd-address")
+			statsdTags, _ := command.Flags().GetStringSlice("statsd-tags")
+			var statsdBackend *metrics.StatsD
+			statsdBackend, err = metrics.NewStatsD(statsdAddress, statsdTags)
+			if err != nil {
+				return errors.Wrap(err, "failed to set up the statsd metrics backend")
+			}
+			defer statsdBackend.Close()
+			metricsBackend = statsdBackend
+			logger.WithField("statsd-address", statsdAddress).Info("Metrics are sent to the StatsD agent")
+		default:
+			prometheusBackend := metrics.NewPrometheus()
+			metricsBackend = prometheusBackend
+			metricsRegistry = prometheusBackend.Registry()
+		}
+		metricsLabeledRings, _ := command.Flags().GetStringSlice("metrics-labeled-rings")
+		elrondMetrics := metrics.New(metricsLabeledRings, metricsBackend)
+
+		environment, _ := command.Flags().GetString("environment")
+		soakTimeDefaults := soakTimeDefaults(command.Flags())
+		logger.WithFields(logrus.Fields{
+			"environment":       environment,
+			"default-soak-time": soakTimeDefaults.Server,
+			"environment-soak":  soakTimeDefaults.Environment,
+			"hotfix-soak-time":  soakTimeDefaults.Hotfix,
+		}).Info("Soak time defaults")
+
+		var evidenceArchiver supervisor.EvidenceArchiver
+		evidenceBucket, _ := command.Flags().GetString("evidence-bucket")
+		if evidenceBucket != "" {
+			evidencePrefix, _ := command.Flags().GetString("evidence-prefix")
+			evidenceRegion, _ := command.Flags().GetString("evidence-region")
+			evidenceEndpoint, _ := command.Flags().GetString("evidence-endpoint")
+			evidenceForcePathStyle, _ := command.Flags().GetBool("evidence-force-path-style")
+			archiver, err := evidence.NewArchiver(evidence.Config{
+				Bucket:         evidenceBucket,
+				Prefix:         evidencePrefix,
+				Region:         evidenceRegion,
+				Endpoint:       evidenceEndpoint,
+				ForcePathStyle: evidenceForcePathStyle,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to create release evidence archiver")
+			}
+			logger.WithField("evidence-bucket", evidenceBucket).Info("Release evidence archiving is enabled")
+			evidenceArchiver = archiver
+		}
+
+		emailNotifier, err := newEmailNotifier(command.Flags(), sqlStore)
+		if err != nil {
+			return errors.Wrap(err, "failed to create email notifier")
+		}
+		if emailNotifier != nil {
+			smtpServer, _ := command.Flags().GetString("smtp-server")
+			logger.WithField("smtp-server", smtpServer).Info("Email release notifications are enabled")
+		}
+
+		issueTracker, err := newIssueTracker(command.Flags())
+		if err != nil {
+			return errors.Wrap(err, "failed to create Jira release tracker")
+		}
+		if issueTracker != nil {
+			jiraURL, _ := command.Flags().GetString("jira-url")
+			logger.WithField("jira-url", jiraURL).Info("Jira release tracking is enabled")
+		}
+
+		maintenanceCheckers, err := newMaintenanceCheckers(command.Flags())
+		if err != nil {
+			return errors.Wrap(err, "failed to create maintenance conflict checks")
+		}
+		if maintenanceCheckers != nil {
+			logger.Info("Maintenance conflict checks are enabled")
+		}
+
+		failureIssueCreator, err := newFailureIssueCreator(command.Flags(), issueTracker)
+		if err != nil {
+			return errors.Wrap(err, "failed to create failure issue creator")
+		}
+		if failureIssueCreator != nil {
+			failureIssueThreshold, _ := command.Flags().GetInt("failure-issue-threshold")
+			logger.WithField("failure-issue-threshold", failureIssueThreshold).Info("Failure issues are enabled")
+		}
+
+		reloader := &serverReloader{
+			configFile:         configFile,
+			flags:              command.Flags(),
+			logger:             logger,
+			templates:          sqlStore,
+			maintenanceChecker: &reloadableMaintenanceChecker{checkers: maintenanceCheckers},
 		}
 
+		lockBatchSize, _ := command.Flags().GetInt("supervisor-lock-batch-size")
+		parallelism, _ := command.Flags().GetInt("supervisor-parallelism")
+		semaphore := supervisor.NewSemaphore(parallelism)
+
 		// Setup the supervisor to effect any requested changes. It is wrapped in a
 		// scheduler to trigger it periodically in addition to being poked by the API
 		// layer.
@@ -135,23 +410,233 @@ var serverCmd = &cobra.Command{
 		if poll == 0 {
 			logger.WithField("poll", poll).Info("Scheduler is disabled")
 		}
+		pollPeriod := time.Duration(poll) * time.Second
+
+		supervisorUnreadyCycles, _ := command.Flags().GetInt("supervisor-unready-cycles")
+		healthMonitor := supervisor.NewHealthMonitor(supervisorUnreadyCycles, elrondMetrics)
+		healthMonitor.SetDatabase(sqlStore)
+		reloader.healthMonitor = healthMonitor
+
+		databaseCheckInterval, _ := command.Flags().GetInt("database-check-interval")
+		if databaseCheckInterval > 0 {
+			stopDatabaseMonitor := make(chan struct{})
+			defer close(stopDatabaseMonitor)
+			go sqlStore.MonitorConnection(time.Duration(databaseCheckInterval)*time.Second, stopDatabaseMonitor)
+		}
+
+		var multiDoer supervisor.MultiDoer
+		if ringSupervisor {
+			// Rollouts run first, so the rings of a step they start are
+			// released in the same run.
+			rolloutSupervisor := supervisor.NewRolloutSupervisor(sqlStore, instanceID, logger)
+			rolloutSupervisor.SetLockBatchSize(lockBatchSize)
+			if soakChecker != nil {
+				rolloutSupervisor.SetSoakChecker(soakChecker)
+			}
+			multiDoer = append(multiDoer, healthMonitor.Track(supervisorRollout, rolloutSupervisor, pollPeriod))
+
+			ringSupervisor := supervisor.NewRingSupervisor(sqlStore, elrondProvisioner, instanceID, logger, elrondMetrics, soakTimeDefaults)
+			ringSupervisor.SetLockBatchSize(lockBatchSize)
+			ringSupervisor.SetSemaphore(semaphore)
+			ringSupervisor.SetEvidenceArchiver(evidenceArchiver)
+			if emailNotifier != nil {
+				ringSupervisor.AddNotifier(emailNotifier)
+			}
+			if issueTracker != nil {
+				ringSupervisor.SetIssueTracker(issueTracker)
+			}
+			if policyEngine != nil {
+				ringSupervisor.SetPolicyEngine(policyEngine)
+			}
+			if maintenanceCheckers != nil {
+				ringSupervisor.SetMaintenanceChecker(maintenanceCheckers)
+			}
+			if failureIssueCreator != nil {
+				ringSupervisor.SetFailureIssueCreator(failureIssueCreator, serverconfig.FailureIssueSettings(command.Flags()))
+			}
+			multiDoer = append(multiDoer, healthMonitor.Track(supervisorRing, ringSupervisor, pollPeriod))
+			reloader.ringSupervisor = ringSupervisor
+		}
+		if installationGroupSupervisor {
+			var installationGroupSupervisor *supervisor.InstallationGroupSupervisor
+			if shadowProvisioner != nil {
+				installationGroupSupervisor = supervisor.NewInstallationGroupSupervisor(sqlStore, shadowProvisioner, instanceID, logger, elrondMetrics)
+			} else {
+				installationGroupSupervisor = supervisor.NewInstallationGroupSupervisor(sqlStore, elrondProvisioner, instanceID, logger, elrondMetrics)
+			}
+			installationGroupSupervisor.SetLockBatchSize(lockBatchSize)
+			installationGroupSupervisor.SetSemaphore(semaphore)
+			if soakChecker != nil {
+				installationGroupSupervisor.SetSoakChecker(soakChecker)
+			}
+			installationGroupSupervisor.SetReleaseVerifier(releaseVerifier)
+			multiDoer = append(multiDoer, healthMonitor.Track(supervisorInstallationGroup, installationGroupSupervisor, pollPeriod))
+		}
+		if jobSupervisor {
+			jobSupervisor := supervisor.NewJobSupervisor(sqlStore, instanceID, logger, elrondMetrics)
+			jobSupervisor.SetLockBatchSize(lockBatchSize)
+			jobSupervisor.SetJobRunner(model.JobTypePrune, supervisor.NewPruner(sqlStore))
+			jobSupervisor.SetJobRunner(model.JobTypeResync, supervisor.NewDriftReconciler(sqlStore, elrondProvisioner, logger))
+			multiDoer = append(multiDoer, healthMonitor.Track(supervisorJob, jobSupervisor, pollPeriod))
+		}
+
+		// The schedulers are closed once the API is drained on shutdown, so
+		// that work triggered by in-flight requests is picked up, waiting for
+		// the work in progress until the shutdown deadline.
+		var schedulers []*supervisor.Scheduler
+		var shutdownDeadline time.Time
+		defer func() {
+			ctx := context.Background()
+			if !shutdownDeadline.IsZero() {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, shutdownDeadline)
+				defer cancel()
+			}
+			var timedOut bool
+			for _, scheduler := range schedulers {
+				if err := scheduler.CloseContext(ctx); err != nil {
+					timedOut = true
+				}
+			}
+			if timedOut {
+				logger.Warn("Timed out waiting for background work to finish")
+			}
+		}()
+
+		driftReconcileInterval, _ := command.Flags().GetInt("drift-reconcile-interval")
+		if driftReconcileInterval > 0 {
+			driftReconcilePeriod := time.Duration(driftReconcileInterval) * time.Second
+			driftReconciler := supervisor.NewScheduler(healthMonitor.Track(supervisorDriftReconciler, supervisor.NewDriftReconciler(sqlStore, elrondProvisioner, logger), driftReconcilePeriod), driftReconcilePeriod)
+			schedulers = append(schedulers, driftReconciler)
+			reloader.driftReconciler = driftReconciler
+		} else {
+			logger.Info("Drift reconciler is disabled")
+		}
+
+		eventRetentionDays, _ := command.Flags().GetInt("event-retention-days")
+		if eventRetentionDays > 0 {
+			eventCleanupInterval, _ := command.Flags().GetInt("event-cleanup-interval")
+			eventCleanupPeriod := time.Duration(eventCleanupInterval) * time.Second
+			eventRetention := supervisor.NewEventRetention(sqlStore, time.Duration(eventRetentionDays)*24*time.Hour, logger)
+			schedulers = append(schedulers, supervisor.NewScheduler(healthMonitor.Track(supervisorEventRetention, eventRetention, eventCleanupPeriod), eventCleanupPeriod))
+		} else {
+			logger.Info("Event retention is disabled")
+		}
 
-		supervisor := supervisor.NewScheduler(multiDoer, time.Duration(poll)*time.Second)
-		defer supervisor.Close()
+		webhookDeliveryAttempts, _ := command.Flags().GetInt("webhook-delivery-attempts")
+		if webhookDeliveryAttempts > 0 {
+			webhookDeliveryBackoff, _ := command.Flags().GetInt("webhook-delivery-backoff")
+			webhookDeliveryPeriod := time.Duration(webhookDeliveryBackoff) * time.Second
+			deliverer := webhook.NewDeliverer(sqlStore, webhookDeliveryAttempts, webhookDeliveryPeriod, logger)
+			webhook.SetDeliverer(deliverer)
+			schedulers = append(schedulers, supervisor.NewScheduler(healthMonitor.Track(supervisorWebhookDelivery, deliverer, webhookDeliveryPeriod), webhookDeliveryPeriod))
+		} else {
+			logger.Info("Webhook delivery retries are disabled")
+		}
+
+		var soakTimeAnalyzer *supervisor.SoakTimeAnalyzer
+		soakAnalysisInterval, _ := command.Flags().GetInt("soak-analysis-interval")
+		if soakAnalysisInterval > 0 {
+			soakTimeAnalyzer = supervisor.NewSoakTimeAnalyzer(sqlStore, logger)
+			soakAnalysisPeriod := time.Duration(soakAnalysisInterval) * time.Second
+			soakAnalysisScheduler := supervisor.NewScheduler(healthMonitor.Track(supervisorSoakTimeAnalyzer, soakTimeAnalyzer, soakAnalysisPeriod), soakAnalysisPeriod)
+			schedulers = append(schedulers, soakAnalysisScheduler)
+			// Build the first report right away rather than after a whole interval.
+			soakAnalysisScheduler.Do() //nolint
+			reloader.soakTimeAnalyzer = soakAnalysisScheduler
+		} else {
+			logger.Info("Soak time analyzer is disabled")
+		}
+
+		supervisor := supervisor.NewScheduler(multiDoer, pollPeriod)
+		schedulers = append(schedulers, supervisor)
+		reloader.scheduler = supervisor
+
+		// Pick up provisioner credentials rotated through other servers.
+		if credentialsCipher != nil && poll > 0 {
+			stopCredentialsRefresh := make(chan struct{})
+			defer close(stopCredentialsRefresh)
+			go func() {
+				ticker := time.NewTicker(time.Duration(poll) * time.Second)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ticker.C:
+						if err := elrondProvisioner.RefreshCredentials(sqlStore, credentialsCipher); err != nil {
+							logger.WithError(err).Error("Failed to refresh provisioner credentials")
+						}
+					case <-stopCredentialsRefresh:
+						return
+					}
+				}
+			}()
+		}
+
+		webhookDigestInterval, _ := command.Flags().GetInt("webhook-digest-interval")
+		if webhookDigestInterval > 0 {
+			logger.WithField("webhook-digest-interval", webhookDigestInterval).Info("Webhook digest mode is enabled")
+			digester := webhook.NewDigester(sqlStore, time.Duration(webhookDigestInterval)*time.Second, logger)
+			webhook.SetDigester(digester)
+			defer digester.Close()
+		}
+
+		webhookBatchWindow, _ := command.Flags().GetInt("webhook-batch-window")
+		if webhookBatchWindow > 0 {
+			logger.WithField("webhook-batch-window", webhookBatchWindow).Info("Webhook batch mode is enabled")
+			batcher := webhook.NewBatcher(time.Duration(webhookBatchWindow)*time.Second, logger)
+			webhook.SetBatcher(batcher)
+			defer batcher.Close()
+		}
+
+		tenantQuotas, err := serverconfig.TenantQuotas(command.Flags())
+		if err != nil {
+			return errors.Wrap(err, "invalid tenant quotas")
+		}
+		requireAPIToken, _ := command.Flags().GetBool("require-api-token")
+		readCacheTTL, _ := command.Flags().GetInt("api-read-cache-ttl")
+		webUI, _ := command.Flags().GetBool("web-ui")
+		credentialsRotationInterval, _ := command.Flags().GetInt("provisioner-credentials-rotation-interval")
 
 		router := mux.NewRouter()
+		if metricsRegistry != nil {
+			router.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{
+				EnableOpenMetrics: true,
+			}))
+		}
+		router.Handle("/readyz", api.NewReadinessHandler(healthMonitor))
+		if webUI {
+			webui.Register(router)
+		}
 
-		api.Register(router, &api.Context{
-			Store:             sqlStore,
-			Supervisor:        supervisor,
-			Elrond:            elrondProvisioner,
-			Logger:            logger,
-			ProvisionerServer: provisionerServer,
-		})
+		apiContext := &api.Context{
+			Store:              sqlStore,
+			Supervisor:         supervisor,
+			Elrond:             elrondProvisioner,
+			Logger:             logger,
+			Environment:        environment,
+			ProvisionerServer:  provisionerServer,
+			TenantQuotas:       tenantQuotas,
+			RequireToken:       requireAPIToken,
+			Reloader:           reloader,
+			MaintenanceChecker: reloader.maintenanceChecker,
+
+			CredentialsRotationLimiter: rate.NewLimiter(rate.Every(time.Duration(credentialsRotationInterval)*time.Second), 1),
+		}
+		if credentialsCipher != nil {
+			apiContext.CredentialsEncrypter = credentialsCipher
+		}
+		if soakTimeAnalyzer != nil {
+			apiContext.SoakTimeReporter = soakTimeAnalyzer
+		}
+		if policyEngine != nil {
+			apiContext.Policy = policyEngine
+		}
+		if readCacheTTL > 0 {
+			apiContext.ReadCache = api.NewReadCache(time.Duration(readCacheTTL) * time.Second)
+		}
+		api.Register(router, apiContext)
 
-		listen, _ := command.Flags().GetString("listen")
 		srv := &http.Server{
-			Addr:           listen,
 			Handler:        router,
 			ReadTimeout:    180 * time.Second,
 			WriteTimeout:   180 * time.Second,
@@ -160,28 +645,76 @@ var serverCmd = &cobra.Command{
 			ErrorLog:       log.New(&logrusWriter{logger}, "", 0),
 		}
 
-		go func() {
-			logger.WithField("addr", srv.Addr).Info("Listening")
-			err := srv.ListenAndServe()
-			if err != nil && err != http.ErrServerClosed {
-				logger.WithError(err).Error("Failed to listen and serve")
+		tlsCertFile, _ := command.Flags().GetString("tls-cert-file")
+		tlsKeyFile, _ := command.Flags().GetString("tls-key-file")
+		tlsEnabled := tlsCertFile != ""
+		if tlsEnabled {
+			tlsReloadInterval, _ := command.Flags().GetInt("tls-reload-interval")
+			certReloader, err := certreload.NewReloader(tlsCertFile, tlsKeyFile, time.Duration(tlsReloadInterval)*time.Second, logger)
+			if err != nil {
+				return errors.Wrap(err, "failed to load TLS certificate")
 			}
-		}()
+			defer certReloader.Close()
+			srv.TLSConfig = certReloader.TLSConfig()
+		}
+
+		listenAddresses, _ := command.Flags().GetStringArray("listen")
+		listeners, err := listen(listenAddresses)
+		if err != nil {
+			return err
+		}
+		for _, listener := range listeners {
+			go func(listener net.Listener) {
+				logger.WithFields(logrus.Fields{
+					"addr":    listener.Addr().String(),
+					"network": listener.Addr().Network(),
+					"tls":     tlsEnabled,
+				}).Info("Listening")
+				var err error
+				if tlsEnabled {
+					// The certificate is served by the TLS config, so no files are passed here.
+					err = srv.ServeTLS(listener, "", "")
+				} else {
+					err = srv.Serve(listener)
+				}
+				if err != nil && err != http.ErrServerClosed {
+					logger.WithError(err).Error("Failed to serve")
+				}
+			}(listener)
+		}
 
 		c := make(chan os.Signal, 1)
 		// We'll accept graceful shutdowns when quit via:
 		//  - SIGINT (Ctrl+C)
 		//  - SIGTERM (Ctrl+/) (Kubernetes pod rolling termination)
-		// SIGKILL and SIGQUIT will not be caught.
-		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+		// SIGKILL and SIGQUIT will not be caught. SIGHUP reloads the
+		// configuration file.
+		signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
 		// Block until we receive a valid signal.
 		sig := <-c
-		logger.WithField("shutdown-signal", sig.String()).Info("Shutting down")
-
-		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		for sig == syscall.SIGHUP {
+			if err = reloader.Reload(); err != nil {
+				logger.WithError(err).Error("Failed to reload server configuration")
+			}
+			sig = <-c
+		}
+		shutdownTimeout, _ := command.Flags().GetInt("shutdown-timeout")
+		logger.WithFields(logrus.Fields{
+			"shutdown-signal":  sig.String(),
+			"shutdown-timeout": shutdownTimeout,
+		}).Info("Shutting down")
+
+		// Stop accepting new connections and let the in-flight requests
+		// finish, then drain the supervisors within what is left of the
+		// deadline.
+		shutdownDeadline = time.Now().Add(time.Duration(shutdownTimeout) * time.Second)
+		ctx, cancel := context.WithDeadline(context.Background(), shutdownDeadline)
 		defer cancel()
-		srv.Shutdown(ctx) //nolint
+		if err = srv.Shutdown(ctx); err != nil {
+			logger.WithError(err).Warn("Timed out waiting for in-flight API requests to finish")
+			srv.Close() //nolint
+		}
 
 		return nil
 	},
@@ -0,0 +1,72 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	stateMachineCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(stateMachineCmd)
+
+	stateMachineExportCmd.Flags().String("format", model.StateMachineFormatDOT, "The format of the graphs: dot or mermaid.")
+	stateMachineExportCmd.Flags().String("resource-type", "", "Only export the graph of this resource type: ring or installationgroup.")
+	stateMachineExportCmd.Flags().Int("version", 0, "The state machine version to export. Defaults to the current version of the server.")
+
+	stateMachineCmd.AddCommand(stateMachineExportCmd)
+}
+
+var stateMachineCmd = &cobra.Command{
+	Use:   "state-machine",
+	Short: "View the state machines of rings and installation groups.",
+}
+
+var stateMachineExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the ring and installation group transition graphs of the server as DOT or Mermaid.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		format, _ := command.Flags().GetString("format")
+		if format != model.StateMachineFormatDOT && format != model.StateMachineFormatMermaid {
+			return errors.Errorf("unsupported format %q, must be %s or %s", format, model.StateMachineFormatDOT, model.StateMachineFormatMermaid)
+		}
+		resourceTypes := []string{model.TypeRing, model.TypeInstallationGroup}
+		if resourceType, _ := command.Flags().GetString("resource-type"); resourceType != "" {
+			resourceTypes = []string{resourceType}
+		}
+		version, _ := command.Flags().GetInt("version")
+
+		client := newClient(command, serverAddress)
+
+		for i, resourceType := range resourceTypes {
+			graph, err := client.GetStateMachineGraph(resourceType, version)
+			if err != nil {
+				return errors.Wrapf(err, "failed to query %s state machine", resourceType)
+			}
+			rendered, err := graph.Render(format)
+			if err != nil {
+				return errors.Wrapf(err, "failed to render %s state machine", resourceType)
+			}
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Print(rendered)
+		}
+
+		return nil
+	},
+}
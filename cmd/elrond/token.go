@@ -0,0 +1,239 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	tokenCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(tokenCmd)
+
+	tokenCreateCmd.Flags().String("name", "", "A name describing the service account using the token.")
+	tokenCreateCmd.Flags().String("tenant", "", "The tenant the token is scoped to. Required for non-admin tokens.")
+	tokenCreateCmd.Flags().String("role", model.TokenRoleRead, "The role of the token: admin, write or read.")
+	tokenCreateCmd.Flags().Int64("expires-in", model.DefaultTokenExpiresIn, "The lifetime of the token in seconds. Set to -1 for a token that never expires.")
+	tokenCreateCmd.Flags().StringSlice("ring", []string{}, "The ID of a ring the token is restricted to changing. Accepts multiple values.")
+	tokenCreateCmd.Flags().String("ring-selector", "", "Restrict the token to changing the rings whose annotations match the selector, such as env=dev.")
+	tokenCreateCmd.MarkFlagRequired("name") //nolint
+
+	tokenGetCmd.Flags().String("token", "", "The id of the token to be fetched.")
+	tokenGetCmd.MarkFlagRequired("token") //nolint
+
+	tokenListCmd.Flags().String("tenant", "", "The tenant by which to filter tokens.")
+	tokenListCmd.Flags().Int("page", 0, "The page of tokens to fetch, starting at 0.")
+	tokenListCmd.Flags().Int("per-page", 100, "The number of tokens to fetch per page.")
+	tokenListCmd.Flags().Bool("include-deleted", false, "Whether to include revoked tokens.")
+	tokenListCmd.Flags().Bool("table", false, "Whether to display the returned token list in a table or not")
+
+	tokenDeleteCmd.Flags().String("token", "", "The id of the token to be revoked.")
+	tokenDeleteCmd.MarkFlagRequired("token") //nolint
+
+	tokenBootstrapCmd.Flags().String("database", "sqlite://elrond.db", "The database backing the elrond server.")
+	tokenBootstrapCmd.Flags().String("name", "bootstrap", "A name describing the admin token.")
+
+	tokenCmd.AddCommand(tokenCreateCmd)
+	tokenCmd.AddCommand(tokenGetCmd)
+	tokenCmd.AddCommand(tokenListCmd)
+	tokenCmd.AddCommand(tokenDeleteCmd)
+	tokenCmd.AddCommand(tokenBootstrapCmd)
+}
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage the API tokens of service accounts.",
+}
+
+var tokenCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a token. The token secret is only displayed once.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		name, _ := command.Flags().GetString("name")
+		tenant, _ := command.Flags().GetString("tenant")
+		role, _ := command.Flags().GetString("role")
+		expiresIn, _ := command.Flags().GetInt64("expires-in")
+		ringIDs, _ := command.Flags().GetStringSlice("ring")
+		ringSelector, _ := command.Flags().GetString("ring-selector")
+
+		response, err := client.CreateToken(&model.CreateTokenRequest{
+			Name:         name,
+			TenantID:     tenant,
+			Role:         role,
+			ExpiresIn:    expiresIn,
+			RingIDs:      ringIDs,
+			RingSelector: ringSelector,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to create token")
+		}
+
+		if err = printJSON(response); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var tokenGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Get a particular token.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		tokenID, _ := command.Flags().GetString("token")
+		token, err := client.GetToken(tokenID)
+		if err != nil {
+			return errors.Wrap(err, "failed to query token")
+		}
+		if token == nil {
+			return nil
+		}
+
+		if err = printJSON(token); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var tokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List created tokens.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		tenant, _ := command.Flags().GetString("tenant")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+		includeDeleted, _ := command.Flags().GetBool("include-deleted")
+		tokens, err := client.GetTokens(&model.GetTokensRequest{
+			TenantID:       tenant,
+			Page:           page,
+			PerPage:        perPage,
+			IncludeDeleted: includeDeleted,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query tokens")
+		}
+
+		outputToTable, _ := command.Flags().GetBool("table")
+		if outputToTable {
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetAlignment(tablewriter.ALIGN_LEFT)
+			table.SetHeader([]string{"ID", "NAME", "TENANT", "ROLE", "EXPIRES", "RINGS"})
+
+			for _, token := range tokens {
+				expires := "never"
+				if token.ExpireAt != 0 {
+					expires = time.UnixMilli(token.ExpireAt).UTC().Format(time.RFC3339)
+				}
+				rings := "all"
+				if token.IsRingScoped() {
+					rings = strings.Trim(token.RingIDs+" "+token.RingSelector, " ")
+				}
+				table.Append([]string{token.ID, token.Name, token.TenantID, token.Role, expires, rings})
+			}
+			table.Render()
+
+			return nil
+		}
+
+		if err = printJSON(tokens); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
+
+var tokenDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Revoke a token.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		tokenID, _ := command.Flags().GetString("token")
+		if err := client.DeleteToken(tokenID); err != nil {
+			return errors.Wrap(err, "failed to revoke token")
+		}
+
+		return nil
+	},
+}
+
+var tokenBootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Create a non-expiring admin token directly in the database, to mint the first tokens of a server requiring API tokens.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		sqlStore, err := sqlStore(command)
+		if err != nil {
+			return err
+		}
+
+		secret, err := model.NewTokenSecret()
+		if err != nil {
+			return err
+		}
+
+		name, _ := command.Flags().GetString("name")
+		token := &model.Token{
+			Name:      name,
+			Role:      model.TokenRoleAdmin,
+			TokenHash: model.HashTokenSecret(secret),
+		}
+		if err = sqlStore.CreateToken(token); err != nil {
+			return errors.Wrap(err, "failed to create admin token")
+		}
+
+		if err = printJSON(model.CreateTokenResponse{Token: token, Secret: secret}); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -16,9 +16,18 @@ import (
 
 func init() {
 	webhookCmd.PersistentFlags().String("server", defaultLocalServerAPI, "The elrond server whose API will be queried.")
+	addAPITokenFlag(webhookCmd)
 
 	webhookCreateCmd.Flags().String("owner", "", "An opaque identifier describing the owner of the webhook.")
 	webhookCreateCmd.Flags().String("url", "", "The callback URL of the webhook.")
+	webhookCreateCmd.Flags().String("format", model.WebhookFormatElrond, "The format of the payloads sent to the webhook: elrond for the raw JSON payload, or teams for Microsoft Teams incoming webhooks.")
+	webhookCreateCmd.Flags().String("label-selector", "", "Only send the payloads of resources whose ring annotations match the selector, as comma-separated key=value or key!=value requirements, e.g. env=prod.")
+	webhookCreateCmd.Flags().String("secret", "", "A shared secret to sign the payloads sent to the webhook with HMAC-SHA256, in the X-Elrond-Signature header.")
+	webhookCreateCmd.Flags().String("language", "", "The language of the Teams cards sent to the webhook, e.g. de or pt-BR. Cards are sent in English when no template matches.")
+	webhookCreateCmd.Flags().Int("rate-limit", 0, "The maximum number of payloads sent to the webhook per minute. Payloads over the limit are summarized once the minute is over; failures are always sent. 0 means no limit.")
+	webhookCreateCmd.Flags().Int("timeout", 0, "The time in seconds a request to the webhook may take. 0 uses the timeout of the server.")
+	webhookCreateCmd.Flags().Int("max-redirects", 0, "The maximum number of redirects followed when sending to the webhook. 0 uses the limit of the server, and -1 follows none.")
+	webhookCreateCmd.Flags().Int("max-payload-size", 0, "The maximum size in bytes of the payloads sent to the webhook. Larger payloads are truncated and flagged as such. 0 uses the limit of the server.")
 	webhookCreateCmd.MarkFlagRequired("owner") //nolint
 	webhookCreateCmd.MarkFlagRequired("url")   //nolint
 
@@ -37,7 +46,27 @@ func init() {
 	webhookCmd.AddCommand(webhookCreateCmd)
 	webhookCmd.AddCommand(webhookGetCmd)
 	webhookCmd.AddCommand(webhookListCmd)
+	webhookReplayCmd.Flags().String("webhook", "", "The id of the webhook to replay the events to.")
+	webhookReplayCmd.Flags().String("from", "", "The RFC3339 time to replay the events from.")
+	webhookReplayCmd.Flags().String("to", "", "The RFC3339 time to replay the events until. Defaults to now.")
+	webhookReplayCmd.MarkFlagRequired("webhook") //nolint
+	webhookReplayCmd.MarkFlagRequired("from")    //nolint
+
+	webhookDeliveriesCmd.Flags().String("webhook", "", "The id of the webhook whose deliveries to list.")
+	webhookDeliveriesCmd.Flags().String("state", "", "Only list the deliveries in this state: pending, delivered or failed.")
+	webhookDeliveriesCmd.Flags().Int("page", 0, "The page of deliveries to fetch, starting at 0.")
+	webhookDeliveriesCmd.Flags().Int("per-page", 100, "The number of deliveries to fetch per page.")
+	webhookDeliveriesCmd.MarkFlagRequired("webhook") //nolint
+
+	webhookRedeliverCmd.Flags().String("webhook", "", "The id of the webhook of the delivery.")
+	webhookRedeliverCmd.Flags().String("delivery", "", "The id of the failed delivery to attempt again.")
+	webhookRedeliverCmd.MarkFlagRequired("webhook")  //nolint
+	webhookRedeliverCmd.MarkFlagRequired("delivery") //nolint
+
 	webhookCmd.AddCommand(webhookDeleteCmd)
+	webhookCmd.AddCommand(webhookReplayCmd)
+	webhookCmd.AddCommand(webhookDeliveriesCmd)
+	webhookCmd.AddCommand(webhookRedeliverCmd)
 }
 
 var webhookCmd = &cobra.Command{
@@ -56,14 +85,30 @@ var webhookCreateCmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		ownerID, _ := command.Flags().GetString("owner")
 		url, _ := command.Flags().GetString("url")
+		format, _ := command.Flags().GetString("format")
+		labelSelector, _ := command.Flags().GetString("label-selector")
+		secret, _ := command.Flags().GetString("secret")
+		rateLimit, _ := command.Flags().GetInt("rate-limit")
+		timeout, _ := command.Flags().GetInt("timeout")
+		maxRedirects, _ := command.Flags().GetInt("max-redirects")
+		maxPayloadSize, _ := command.Flags().GetInt("max-payload-size")
+		language, _ := command.Flags().GetString("language")
 
 		webhook, err := client.CreateWebhook(&model.CreateWebhookRequest{
-			OwnerID: ownerID,
-			URL:     url,
+			OwnerID:        ownerID,
+			URL:            url,
+			Format:         format,
+			LabelSelector:  labelSelector,
+			Secret:         secret,
+			RateLimit:      rateLimit,
+			Timeout:        timeout,
+			MaxRedirects:   maxRedirects,
+			MaxPayloadSize: maxPayloadSize,
+			Language:       language,
 		})
 		if err != nil {
 			return errors.Wrap(err, "failed to create webhook")
@@ -88,7 +133,7 @@ var webhookGetCmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		webhookID, err := command.Flags().GetString("webhook")
 		if err != nil {
@@ -122,7 +167,7 @@ var webhookListCmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		owner, _ := command.Flags().GetString("owner")
 		page, _ := command.Flags().GetInt("page")
@@ -142,10 +187,10 @@ var webhookListCmd = &cobra.Command{
 		if outputToTable {
 			table := tablewriter.NewWriter(os.Stdout)
 			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.SetHeader([]string{"ID", "OWNER", "URL"})
+			table.SetHeader([]string{"ID", "OWNER", "URL", "FORMAT", "LABEL SELECTOR"})
 
 			for _, webhook := range webhooks {
-				table.Append([]string{webhook.ID, webhook.OwnerID, webhook.URL})
+				table.Append([]string{webhook.ID, webhook.OwnerID, webhook.URL, webhook.Format, webhook.LabelSelector})
 			}
 			table.Render()
 
@@ -171,7 +216,7 @@ var webhookDeleteCmd = &cobra.Command{
 			return errors.Wrap(err, "provided server address not a valid address")
 		}
 
-		client := model.NewClient(serverAddress)
+		client := newClient(command, serverAddress)
 
 		webhookID, err := command.Flags().GetString("webhook")
 		if err != nil {
@@ -186,3 +231,103 @@ var webhookDeleteCmd = &cobra.Command{
 		return nil
 	},
 }
+
+var webhookReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Re-deliver the state change events of a time range to a webhook.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		webhookID, _ := command.Flags().GetString("webhook")
+		request := &model.ReplayWebhookRequest{}
+		var err error
+		if request.From, err = getTimeFlag(command, "from"); err != nil {
+			return err
+		}
+		if request.To, err = getTimeFlag(command, "to"); err != nil {
+			return err
+		}
+
+		replay, err := client.ReplayWebhook(webhookID, request)
+		if err != nil {
+			return errors.Wrap(err, "failed to replay webhook")
+		}
+
+		if err = printJSON(replay); err != nil {
+			return errors.Wrap(err, "failed to print webhook replay")
+		}
+
+		return nil
+	},
+}
+
+var webhookDeliveriesCmd = &cobra.Command{
+	Use:   "deliveries",
+	Short: "List the deliveries of a webhook, with their attempts and last error.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		webhookID, _ := command.Flags().GetString("webhook")
+		state, _ := command.Flags().GetString("state")
+		page, _ := command.Flags().GetInt("page")
+		perPage, _ := command.Flags().GetInt("per-page")
+
+		deliveries, err := client.GetWebhookDeliveries(webhookID, &model.GetWebhookDeliveriesRequest{
+			State:   state,
+			Page:    page,
+			PerPage: perPage,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to query webhook deliveries")
+		}
+
+		if err = printJSON(deliveries); err != nil {
+			return errors.Wrap(err, "failed to print webhook deliveries")
+		}
+
+		return nil
+	},
+}
+
+var webhookRedeliverCmd = &cobra.Command{
+	Use:   "redeliver",
+	Short: "Attempt a failed webhook delivery again.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		serverAddress, _ := command.Flags().GetString("server")
+		if _, err := url.Parse(serverAddress); err != nil {
+			return errors.Wrap(err, "provided server address not a valid address")
+		}
+
+		client := newClient(command, serverAddress)
+
+		webhookID, _ := command.Flags().GetString("webhook")
+		deliveryID, _ := command.Flags().GetString("delivery")
+
+		delivery, err := client.RedeliverWebhookDelivery(webhookID, deliveryID)
+		if err != nil {
+			return errors.Wrap(err, "failed to redeliver webhook delivery")
+		}
+
+		if err = printJSON(delivery); err != nil {
+			return errors.Wrap(err, "failed to print webhook delivery")
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"github.com/mattermost/elrond/internal/config"
+	"github.com/mattermost/elrond/internal/serverconfig"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func init() {
+	configValidateCmd.Flags().String("config", "", "The YAML or TOML configuration file of the elrond server to validate.")
+	configValidateCmd.MarkFlagRequired("config") //nolint
+
+	configCmd.AddCommand(configValidateCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Check the configuration of the elrond server.",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a configuration file of the elrond server, like elrond server --validate-config.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		configFile, _ := command.Flags().GetString("config")
+		flags := pflag.NewFlagSet("server", pflag.ContinueOnError)
+		serverconfig.AddFlags(flags)
+		if err := config.Load(configFile, flags, "config", "validate-config", "check"); err != nil {
+			return errors.Wrapf(err, "invalid configuration file %s", configFile)
+		}
+		if err := serverconfig.Validate(flags); err != nil {
+			return errors.Wrap(err, "invalid server configuration")
+		}
+		logger.Infof("Server configuration %s is valid", configFile)
+
+		return nil
+	},
+}
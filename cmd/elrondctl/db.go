@@ -0,0 +1,179 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	dbBackupCmd.Flags().String("output", "", "The file to write the backup to. It holds the API tokens and provisioner credentials, so it is only readable by its owner.")
+	dbBackupCmd.MarkFlagRequired("output") //nolint
+
+	dbRestoreCmd.Flags().String("input", "", "The backup file to restore.")
+	dbRestoreCmd.MarkFlagRequired("input") //nolint
+
+	dbSchemaCmd.Flags().String("format", "json", "The output format: json, or dot or mermaid for the entity relationship diagram of the schema.")
+
+	dbCmd.AddCommand(dbMigrateCmd)
+	dbCmd.AddCommand(dbVersionCmd)
+	dbCmd.AddCommand(dbSchemaCmd)
+	dbCmd.AddCommand(dbBackupCmd)
+	dbCmd.AddCommand(dbRestoreCmd)
+}
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Migrate, back up and restore the database.",
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the schema to the latest supported version.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		sqlStore, err := sqlStore(command)
+		if err != nil {
+			return err
+		}
+
+		return sqlStore.Migrate()
+	},
+}
+
+var dbVersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the schema version of the database and the latest supported one.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		sqlStore, err := sqlStore(command)
+		if err != nil {
+			return err
+		}
+		currentVersion, err := sqlStore.GetCurrentVersion()
+		if err != nil {
+			return err
+		}
+
+		return printJSON(map[string]string{
+			"Current": currentVersion.String(),
+			"Latest":  store.LatestVersion().String(),
+		})
+	},
+}
+
+var dbSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the schema version, tables, row counts and index health of the database.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		format, _ := command.Flags().GetString("format")
+
+		sqlStore, err := sqlStore(command)
+		if err != nil {
+			return err
+		}
+		schema, err := sqlStore.GetDatabaseSchema()
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			return printJSON(schema)
+		}
+		rendered, err := schema.Render(format)
+		if err != nil {
+			return err
+		}
+		fmt.Print(rendered)
+
+		return nil
+	},
+}
+
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Write every row of the database to a backup file.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		sqlStore, err := sqlStore(command)
+		if err != nil {
+			return err
+		}
+		backup, err := sqlStore.Backup()
+		if err != nil {
+			return err
+		}
+
+		output, _ := command.Flags().GetString("output")
+		file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return errors.Wrap(err, "failed to create the backup file")
+		}
+		if err = json.NewEncoder(file).Encode(backup); err != nil {
+			file.Close()
+			return errors.Wrap(err, "failed to write the backup file")
+		}
+		if err = file.Close(); err != nil {
+			return errors.Wrap(err, "failed to write the backup file")
+		}
+
+		logger.Infof("Backed up %d rows of schema version %s to %s", backup.Rows(), backup.SchemaVersion, output)
+
+		return nil
+	},
+}
+
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a backup file into an empty database.",
+	Long:  "Restore a backup file into an empty database, which is first migrated to the schema version of the backup. A backup of an older schema is migrated to the latest one once restored.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		input, _ := command.Flags().GetString("input")
+		file, err := os.Open(input)
+		if err != nil {
+			return errors.Wrap(err, "failed to open the backup file")
+		}
+		defer file.Close()
+
+		backup, err := model.DatabaseBackupFromReader(file)
+		if err != nil {
+			return err
+		}
+
+		sqlStore, err := sqlStore(command)
+		if err != nil {
+			return err
+		}
+		if err = sqlStore.MigrateTo(backup.SchemaVersion); err != nil {
+			return err
+		}
+		if err = sqlStore.Restore(backup); err != nil {
+			return err
+		}
+		logger.Infof("Restored %d rows of schema version %s from %s", backup.Rows(), backup.SchemaVersion, input)
+
+		return sqlStore.Migrate()
+	},
+}
+
+func printJSON(data interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(data)
+}
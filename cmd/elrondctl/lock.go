@@ -0,0 +1,45 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	lockCleanupCmd.Flags().Int("older-than", 0, "The age in seconds of the locks to release. Set to 0 to release every lock, only once every elrond server is stopped.")
+
+	lockCmd.AddCommand(lockCleanupCmd)
+}
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Manage the locks held on rings, installation groups, rollouts and jobs.",
+}
+
+var lockCleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Release the locks left behind by elrond servers that stopped while working.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		sqlStore, err := sqlStore(command)
+		if err != nil {
+			return err
+		}
+
+		olderThan, _ := command.Flags().GetInt("older-than")
+		before := model.GetMillis() - (time.Duration(olderThan) * time.Second).Milliseconds()
+		unlocked, err := sqlStore.UnlockRowsLockedBefore(before)
+		if err != nil {
+			return err
+		}
+
+		return printJSON(unlocked)
+	},
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package main is the entry point to elrondctl, which runs the maintenance
+// operations of elrond directly against its database, without a running
+// server, such as when the server does not start.
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/elrond/internal/store"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "elrondctl",
+	Short: "Elrondctl runs the maintenance operations of elrond directly against its database.",
+	// SilenceErrors allows us to explicitly log the error returned from rootCmd below.
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.PersistentFlags().String("database", "sqlite://elrond.db", "The database backing the elrond server.")
+
+	rootCmd.AddCommand(dbCmd)
+	rootCmd.AddCommand(pruneCmd)
+	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// sqlStore connects to the database given by the --database flag.
+func sqlStore(command *cobra.Command) (*store.SQLStore, error) {
+	database, _ := command.Flags().GetString("database")
+
+	return store.New(database, logger)
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		logger.WithError(err).Error("command failed")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/model"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	pruneCmd.Flags().Int("older-than-days", 0, "The age in days of the state change events and delivered webhook deliveries to delete.")
+	pruneCmd.MarkFlagRequired("older-than-days") //nolint
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the state change events and delivered webhook deliveries older than the given age, like a prune job.",
+	RunE: func(command *cobra.Command, args []string) error {
+		command.SilenceUsage = true
+
+		sqlStore, err := sqlStore(command)
+		if err != nil {
+			return err
+		}
+
+		olderThanDays, _ := command.Flags().GetInt("older-than-days")
+		result, err := supervisor.NewPruner(sqlStore).RunJob(&model.Job{
+			Type:       model.JobTypePrune,
+			Parameters: model.JobParameters{model.JobParameterOlderThanDays: strconv.Itoa(olderThanDays)},
+		})
+		if err != nil {
+			return err
+		}
+		logger.Infof("Pruned the database: %s", result)
+
+		return nil
+	},
+}
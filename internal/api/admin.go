@@ -0,0 +1,186 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// initAdmin registers server administration endpoints on the given router.
+func initAdmin(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	adminRouter := apiRouter.PathPrefix("/admin").Subrouter()
+	adminRouter.Handle("/reload", addContext(handleReloadConfig)).Methods("POST")
+	adminRouter.Handle("/reschedule", addContext(validateRequestBody(model.SchemaRescheduleWorkRequest, handleRescheduleWork))).Methods("POST")
+	adminRouter.Handle("/schema", addContext(handleGetDatabaseSchema)).Methods("GET")
+}
+
+// handleReloadConfig responds to POST /api/admin/reload, reloading the
+// configuration file of the server.
+func handleReloadConfig(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !requireAdminToken(c, w) {
+		return
+	}
+	if c.Reloader == nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "configuration reloading is not enabled on this server")
+		return
+	}
+
+	if err := c.Reloader.Reload(); err != nil {
+		c.Logger.WithError(err).Error("failed to reload configuration")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to reload configuration: %s", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleRescheduleWork responds to POST /api/admin/reschedule, changing the
+// order in which the supervisors work on the given rings and installation
+// groups pending work, without changing their states.
+func handleRescheduleWork(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	rescheduleWorkRequest, err := model.NewRescheduleWorkRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode request: %s", err))
+		return
+	}
+
+	// Check every item before rescheduling any of them.
+	rescheduledWork := make([]*model.RescheduledWork, 0, len(rescheduleWorkRequest.Items))
+	for _, item := range rescheduleWorkRequest.Items {
+		var state string
+		var pendingStates []string
+		switch item.Type {
+		case model.TypeRing:
+			ring, err := c.Store.GetRing(item.ID)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to query ring")
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+				return
+			}
+			if ring == nil {
+				outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, fmt.Sprintf("ring %s not found", item.ID))
+				return
+			}
+			state, pendingStates = ring.State, model.AllRingStatesPendingWork
+		case model.TypeInstallationGroup:
+			installationGroup, err := c.Store.GetInstallationGroupByID(item.ID)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to query installation group")
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query installation group")
+				return
+			}
+			if installationGroup == nil {
+				outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, fmt.Sprintf("installation group %s not found", item.ID))
+				return
+			}
+			state, pendingStates = installationGroup.State, model.AllInstallationGroupStatesPendingWork
+		}
+
+		if !isPendingState(state, pendingStates) {
+			outputError(c, w, http.StatusConflict, model.ErrorCodeConflict, fmt.Sprintf("%s %s in state %s has no pending work", item.Type, item.ID, state))
+			return
+		}
+		rescheduledWork = append(rescheduledWork, &model.RescheduledWork{Type: item.Type, ID: item.ID, State: state})
+	}
+
+	for i, item := range rescheduleWorkRequest.Items {
+		work := rescheduledWork[i]
+		logger := c.Logger.WithField(item.Type, item.ID)
+
+		if item.Priority != nil {
+			work.WorkPriority = *item.Priority
+		} else {
+			lowest, highest, err := c.Store.GetWorkPriorityRange(item.Type)
+			if err != nil {
+				logger.WithError(err).Error("failed to get work priority range")
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get work priority range")
+				return
+			}
+			work.WorkPriority = lowest - 1
+			if item.Position == model.WorkPositionFront {
+				work.WorkPriority = highest + 1
+			}
+		}
+
+		rescheduled, err := c.Store.SetWorkPriority(item.Type, item.ID, work.WorkPriority)
+		if err != nil {
+			logger.WithError(err).Error("failed to reschedule work")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to reschedule work")
+			return
+		}
+		if !rescheduled {
+			outputError(c, w, http.StatusConflict, model.ErrorCodeConflict, fmt.Sprintf("%s %s no longer has pending work", item.Type, item.ID))
+			return
+		}
+		logger.Infof("Rescheduled pending work with priority %d", work.WorkPriority)
+	}
+
+	c.Supervisor.Do() //nolint
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, rescheduledWork)
+}
+
+// handleGetDatabaseSchema responds to GET /api/admin/schema, returning the
+// schema version, tables, row counts and index health of the database. With
+// format=dot or format=mermaid, the entity relationship diagram of the schema
+// is rendered in that format instead.
+func handleGetDatabaseSchema(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	schema, err := c.Store.GetDatabaseSchema()
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query database schema")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query database schema")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" || format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		outputJSON(c, w, schema)
+		return
+	}
+
+	rendered, err := schema.Render(format)
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write([]byte(rendered)); err != nil {
+		c.Logger.WithError(err).Warn("failed to write database schema diagram")
+	}
+}
+
+// isPendingState returns whether the given state is one of the given states
+// pending work.
+func isPendingState(state string, pendingStates []string) bool {
+	for _, pendingState := range pendingStates {
+		if state == pendingState {
+			return true
+		}
+	}
+
+	return false
+}
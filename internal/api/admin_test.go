@@ -0,0 +1,248 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockReloader struct {
+	calls int
+	err   error
+}
+
+func (r *mockReloader) Reload() error {
+	r.calls++
+	return r.err
+}
+
+func TestReloadConfig(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	reloader := &mockReloader{}
+	context := &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	}
+	router := mux.NewRouter()
+	api.Register(router, context)
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := newAdminClient(t, sqlStore, ts.URL)
+
+	t.Run("not enabled", func(t *testing.T) {
+		err := client.ReloadConfig()
+		requireAPIError(t, err, 400)
+	})
+
+	context.Reloader = reloader
+
+	t.Run("reload", func(t *testing.T) {
+		require.NoError(t, client.ReloadConfig())
+		require.Equal(t, 1, reloader.calls)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		reloader.err = errors.New("invalid poll")
+		defer func() { reloader.err = nil }()
+
+		err := client.ReloadConfig()
+		apiErr := requireAPIError(t, err, 400)
+		require.Contains(t, apiErr.Message, "invalid poll")
+	})
+
+	t.Run("non-admin token", func(t *testing.T) {
+		secret, err := model.NewTokenSecret()
+		require.NoError(t, err)
+		require.NoError(t, sqlStore.CreateToken(&model.Token{
+			Name:      "writer",
+			Role:      model.TokenRoleWrite,
+			TokenHash: model.HashTokenSecret(secret),
+		}))
+
+		err = model.NewClientWithToken(ts.URL, secret).ReloadConfig()
+		requireAPIError(t, err, 403)
+		require.Equal(t, 2, reloader.calls)
+	})
+}
+
+func TestRescheduleWork(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := newAdminClient(t, sqlStore, ts.URL)
+
+	devRing := &model.Ring{Name: "dev", State: model.RingStateCreationRequested}
+	require.NoError(t, sqlStore.CreateRing(devRing, nil))
+	prodRing := &model.Ring{Name: "prod", State: model.RingStateReleasePending}
+	require.NoError(t, sqlStore.CreateRing(prodRing, nil))
+	stableRing := &model.Ring{Name: "stable", State: model.RingStateStable}
+	require.NoError(t, sqlStore.CreateRing(stableRing, nil))
+	installationGroup := &model.InstallationGroup{Name: "group1", State: model.InstallationGroupReleasePending}
+	_, err := sqlStore.CreateRingInstallationGroup(prodRing.ID, installationGroup)
+	require.NoError(t, err)
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := client.RescheduleWork(&model.RescheduleWorkRequest{})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("unknown ring", func(t *testing.T) {
+		_, err := client.RescheduleWork(&model.RescheduleWorkRequest{Items: []*model.RescheduleWorkItem{
+			{Type: model.TypeRing, ID: model.NewID(), Position: model.WorkPositionFront},
+		}})
+		requireAPIError(t, err, 404)
+	})
+
+	t.Run("no pending work", func(t *testing.T) {
+		_, err := client.RescheduleWork(&model.RescheduleWorkRequest{Items: []*model.RescheduleWorkItem{
+			{Type: model.TypeRing, ID: prodRing.ID, Position: model.WorkPositionFront},
+			{Type: model.TypeRing, ID: stableRing.ID, Position: model.WorkPositionFront},
+		}})
+		requireAPIError(t, err, 409)
+
+		ring, err := sqlStore.GetRing(prodRing.ID)
+		require.NoError(t, err)
+		require.Zero(t, ring.WorkPriority)
+	})
+
+	t.Run("reschedule", func(t *testing.T) {
+		priority := 3
+		rescheduled, err := client.RescheduleWork(&model.RescheduleWorkRequest{Items: []*model.RescheduleWorkItem{
+			{Type: model.TypeRing, ID: devRing.ID, Position: model.WorkPositionBack},
+			{Type: model.TypeRing, ID: prodRing.ID, Position: model.WorkPositionFront},
+			{Type: model.TypeInstallationGroup, ID: installationGroup.ID, Priority: &priority},
+		}})
+		require.NoError(t, err)
+		require.Equal(t, []*model.RescheduledWork{
+			{Type: model.TypeRing, ID: devRing.ID, State: model.RingStateCreationRequested, WorkPriority: -1},
+			{Type: model.TypeRing, ID: prodRing.ID, State: model.RingStateReleasePending, WorkPriority: 1},
+			{Type: model.TypeInstallationGroup, ID: installationGroup.ID, State: model.InstallationGroupReleasePending, WorkPriority: 3},
+		}, rescheduled)
+
+		rings, _, err := sqlStore.LockRingsPendingWork(model.NewID(), 10)
+		require.NoError(t, err)
+		require.Len(t, rings, 2)
+		require.Equal(t, prodRing.ID, rings[0].ID)
+		require.Equal(t, model.RingStateReleasePending, rings[0].State)
+		require.Equal(t, devRing.ID, rings[1].ID)
+	})
+
+	t.Run("requires a token", func(t *testing.T) {
+		_, err := model.NewClient(ts.URL).GetDatabaseSchema()
+		requireAPIError(t, err, 401)
+	})
+
+	t.Run("requires the admin role", func(t *testing.T) {
+		secret, err := model.NewTokenSecret()
+		require.NoError(t, err)
+		token := &model.Token{Name: "writer", Role: model.TokenRoleWrite, TokenHash: model.HashTokenSecret(secret)}
+		require.NoError(t, sqlStore.CreateToken(token))
+
+		_, err = model.NewClientWithToken(ts.URL, secret).RescheduleWork(&model.RescheduleWorkRequest{Items: []*model.RescheduleWorkItem{
+			{Type: model.TypeRing, ID: devRing.ID, Position: model.WorkPositionFront},
+		}})
+		requireAPIError(t, err, 403)
+	})
+}
+
+func TestGetDatabaseSchema(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := newAdminClient(t, sqlStore, ts.URL)
+
+	require.NoError(t, sqlStore.CreateRing(&model.Ring{Name: "dev", State: model.RingStateStable}, nil))
+
+	t.Run("schema", func(t *testing.T) {
+		schema, err := client.GetDatabaseSchema()
+		require.NoError(t, err)
+		require.Equal(t, store.LatestVersion().String(), schema.Version)
+		require.Equal(t, store.LatestVersion().String(), schema.LatestVersion)
+		require.NotZero(t, schema.Indexes.Total)
+
+		var ring *model.DatabaseTable
+		for _, table := range schema.Tables {
+			if table.Name == "Ring" {
+				ring = table
+			}
+		}
+		require.NotNil(t, ring)
+		require.Equal(t, int64(1), ring.Rows)
+	})
+
+	t.Run("entity relationship diagram", func(t *testing.T) {
+		secret := createTokenSecret(t, sqlStore, model.TokenRoleAdmin, "")
+		get := func(url string) (*http.Response, error) {
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+secret)
+			return http.DefaultClient.Do(req)
+		}
+
+		resp, err := get(ts.URL + "/api/v1/admin/schema?format=mermaid")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(body), "Ring ||--o{ RingInstallationGroup : RingID")
+
+		resp, err = get(ts.URL + "/api/v1/admin/schema?format=svg")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("requires a token", func(t *testing.T) {
+		_, err := model.NewClient(ts.URL).GetDatabaseSchema()
+		requireAPIError(t, err, 401)
+	})
+
+	t.Run("requires the admin role", func(t *testing.T) {
+		secret, err := model.NewTokenSecret()
+		require.NoError(t, err)
+		token := &model.Token{Name: "reader", Role: model.TokenRoleRead, TokenHash: model.HashTokenSecret(secret)}
+		require.NoError(t, sqlStore.CreateToken(token))
+
+		_, err = model.NewClientWithToken(ts.URL, secret).GetDatabaseSchema()
+		requireAPIError(t, err, 403)
+	})
+}
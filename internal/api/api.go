@@ -5,15 +5,58 @@
 package api
 
 import (
+	"net/http"
+	"strings"
+	"time"
+
 	"github.com/gorilla/mux"
 )
 
+// LegacyAPISunset is when the unversioned /api routes are due to be removed
+// in favor of /api/v1.
+var LegacyAPISunset = time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+
 // Register registers the API endpoints on the given router.
 func Register(rootRouter *mux.Router, context *Context) {
-	// api handler at /api
-	apiRouter := rootRouter.PathPrefix("/api").Subrouter()
+	// api handler at /api/v1
+	initRoutes(rootRouter.PathPrefix("/api/v1").Subrouter(), context)
+
+	// legacy api handler at /api, kept as an alias of /api/v1
+	legacyRouter := rootRouter.PathPrefix("/api").Subrouter()
+	legacyRouter.Use(deprecated)
+	initRoutes(legacyRouter, context)
+}
+
+func initRoutes(apiRouter *mux.Router, context *Context) {
 	initRing(apiRouter, context)
 	initInstallationGroup(apiRouter, context)
 	initWebhook(apiRouter, context)
 	initSecurity(apiRouter, context)
+	initToken(apiRouter, context)
+	initProvisioner(apiRouter, context)
+	initEvent(apiRouter, context)
+	initGraphQL(apiRouter, context)
+	initApply(apiRouter, context)
+	initImport(apiRouter, context)
+	initAdmin(apiRouter, context)
+	initCalendar(apiRouter, context)
+	initReport(apiRouter, context)
+	initRollout(apiRouter, context)
+	initForceApproval(apiRouter, context)
+	initStateMachine(apiRouter, context)
+	initJob(apiRouter, context)
+	initSchema(apiRouter, context)
+	initNotificationTemplate(apiRouter, context)
+	initCapabilities(apiRouter, context)
+}
+
+// deprecated marks the responses of the legacy routes as deprecated, linking
+// to the equivalent /api/v1 route.
+func deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Sunset", LegacyAPISunset.Format(http.TimeFormat))
+		w.Header().Set("Link", "<"+strings.Replace(r.URL.Path, "/api", "/api/v1", 1)+`>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
 }
@@ -0,0 +1,101 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIVersions(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	get := func(t *testing.T, path, version string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		require.NoError(t, err)
+		if version != "" {
+			req.Header.Set(model.HeaderAPIVersion, version)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp
+	}
+
+	t.Run("versioned route", func(t *testing.T) {
+		resp := get(t, "/api/v1/rings", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "1", resp.Header.Get(model.HeaderAPIVersion))
+		require.Empty(t, resp.Header.Get("Deprecation"))
+	})
+
+	t.Run("legacy route", func(t *testing.T) {
+		resp := get(t, "/api/rings", "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "true", resp.Header.Get("Deprecation"))
+		require.Equal(t, api.LegacyAPISunset.Format(http.TimeFormat), resp.Header.Get("Sunset"))
+		require.Equal(t, `</api/v1/rings>; rel="successor-version"`, resp.Header.Get("Link"))
+	})
+
+	t.Run("requested version", func(t *testing.T) {
+		resp := get(t, "/api/v1/rings", "v1")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "1", resp.Header.Get(model.HeaderAPIVersion))
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		resp := get(t, "/api/v1/rings", "2")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("supported versions", func(t *testing.T) {
+		resp := get(t, "/api/v1/rings", "3, 2, 1")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "1", resp.Header.Get(model.HeaderAPIVersion))
+	})
+
+	t.Run("capabilities", func(t *testing.T) {
+		resp := get(t, "/api/v1/rings", "")
+		capabilities := model.ParseCapabilities(resp.Header.Get(model.HeaderCapabilities))
+		require.Contains(t, capabilities, model.CapabilityEvents)
+		require.Contains(t, capabilities, model.CapabilityPagination)
+		require.NotContains(t, capabilities, model.CapabilityGRPC)
+
+		apiCapabilities, err := model.NewClient(ts.URL).GetAPICapabilities()
+		require.NoError(t, err)
+		require.Equal(t, model.APIVersion1, apiCapabilities.APIVersion)
+		require.Equal(t, model.SupportedAPIVersions, apiCapabilities.APIVersions)
+		require.Equal(t, capabilities, apiCapabilities.Capabilities)
+	})
+
+	t.Run("legacy server capabilities", func(t *testing.T) {
+		legacy := httptest.NewServer(http.NotFoundHandler())
+		defer legacy.Close()
+
+		apiCapabilities, err := model.NewClient(legacy.URL).GetAPICapabilities()
+		require.NoError(t, err)
+		require.Equal(t, model.LegacyAPICapabilities(), apiCapabilities)
+	})
+}
@@ -0,0 +1,352 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/webhook"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// initApply registers the declarative apply endpoint on the given router.
+func initApply(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	apiRouter.Handle("/apply", addContext(validateRequestBody(model.SchemaFleetSpec, handleApply))).Methods("POST")
+}
+
+// handleApply responds to POST /api/apply, returning the changes reconciling
+// the rings and installation groups with the given fleet spec. The changes
+// are made when confirm=true is set.
+func handleApply(c *Context, w http.ResponseWriter, r *http.Request) {
+	confirm, err := parseBool(r.URL, "confirm", false)
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to parse confirm: %s", err))
+		return
+	}
+
+	spec, err := model.NewFleetSpecFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode request: %s", err))
+		return
+	}
+
+	rings, err := getRingsWithInstallationGroups(c)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query rings")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings")
+		return
+	}
+
+	plan, err := model.PlanFleet(spec, rings)
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return
+	}
+
+	if !confirm && c.Elrond != nil {
+		if err = validateReleases(c, spec, plan, rings); err != nil {
+			c.Logger.WithError(err).Error("failed to validate releases")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to validate releases")
+			return
+		}
+	}
+
+	if !confirm || len(plan.Changes) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		outputJSON(c, w, plan)
+		return
+	}
+
+	ringsByName := make(map[string]*model.Ring)
+	var ringIDs []string
+	for _, ring := range rings {
+		if ring.DeleteAt == 0 {
+			ringsByName[ring.Name] = ring
+			ringIDs = append(ringIDs, ring.ID)
+		}
+	}
+
+	if len(ringIDs) > 0 {
+		status, unlockOnce := lockRings(c, ringIDs)
+		if status != 0 {
+			outputStatusError(c, w, status, "rings")
+			return
+		}
+		defer unlockOnce()
+	}
+
+	// Check the changes can be made before making any of them.
+	for _, change := range plan.Changes {
+		ring := ringsByName[change.Name]
+		if change.ResourceType == model.TypeInstallationGroup {
+			ring = ringsByName[change.Ring]
+		}
+		if ring == nil {
+			continue
+		}
+		if ring.APISecurityLock {
+			logSecurityLockConflict("ring", c.Logger)
+			outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, fmt.Sprintf("API changes are locked for ring %s", ring.Name))
+			return
+		}
+		if change.ResourceType == model.TypeRing && change.Action == model.ApplyActionDelete && !ring.ValidTransitionState(model.RingStateDeletionRequested) {
+			c.Logger.Warnf("unable to delete ring %s while in state %s", ring.Name, ring.State)
+			outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to delete ring %s while in state %s", ring.Name, ring.State), map[string]string{"state": ring.State})
+			return
+		}
+		if change.ResourceType == model.TypeRing && change.Action == model.ApplyActionDelete && !checkRingsNotProtected(c, w, "delete", []*model.Ring{ring}) {
+			return
+		}
+		if change.ResourceType == model.TypeInstallationGroup && change.Action == model.ApplyActionDelete && !checkInstallationGroupDeletable(c, w, ring, findInstallationGroup(ring.InstallationGroups, change.Name)) {
+			return
+		}
+	}
+
+	if err = applyChanges(c, spec, plan, ringsByName); err != nil {
+		c.Logger.WithError(err).Error("failed to apply fleet spec")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, err.Error())
+		return
+	}
+	plan.Applied = true
+	c.Logger.Infof("Applied %d changes from fleet spec", len(plan.Changes))
+
+	c.Supervisor.Do() //nolint
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, plan)
+}
+
+// getRingsWithInstallationGroups returns the rings not deleted along with
+// their installation groups.
+func getRingsWithInstallationGroups(c *Context) ([]*model.Ring, error) {
+	filter := &model.RingFilter{PerPage: model.AllPerPage}
+
+	rings, err := c.Store.GetRings(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	installationGroups, err := c.Store.GetInstallationGroupsForRings(filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, ring := range rings {
+		ring.InstallationGroups = installationGroups[ring.ID]
+	}
+
+	return rings, nil
+}
+
+// validateReleases asks the provisioner whether it would accept the releases
+// the changes of the plan would make: the release of a ring to the
+// provisioner group of each installation group added to it or moved to
+// another provisioner group. Provisioner failures are reported as rejections
+// so that the rest of the plan is still returned.
+func validateReleases(c *Context, spec *model.FleetSpec, plan *model.ApplyPlan, rings []*model.Ring) error {
+	ringsByName := make(map[string]*model.Ring)
+	for _, ring := range rings {
+		if ring.DeleteAt == 0 {
+			ringsByName[ring.Name] = ring
+		}
+	}
+	ringSpecs := make(map[string]*model.RingSpec)
+	installationGroupSpecs := make(map[string]*model.InstallationGroupSpec)
+	for _, ringSpec := range spec.Rings {
+		ringSpecs[ringSpec.Name] = ringSpec
+		for _, installationGroupSpec := range ringSpec.InstallationGroups {
+			installationGroupSpecs[installationGroupSpec.Name] = installationGroupSpec
+		}
+	}
+
+	for _, change := range plan.Changes {
+		if change.ResourceType != model.TypeInstallationGroup {
+			continue
+		}
+		if change.Action != model.ApplyActionCreate && !(change.Action == model.ApplyActionUpdate && hasField(change.Fields, "provisionerGroupID")) {
+			continue
+		}
+		installationGroupSpec := installationGroupSpecs[change.Name]
+		if installationGroupSpec.ProvisionerGroupID == "" {
+			continue
+		}
+
+		// New rings start with the release of their spec, while existing
+		// rings release their desired release to new provisioner groups.
+		image, version := ringSpecs[change.Ring].Image, ringSpecs[change.Ring].Version
+		if ring := ringsByName[change.Ring]; ring != nil {
+			release, err := c.Store.GetRingRelease(ring.DesiredReleaseID)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get desired release of ring %s", ring.Name)
+			}
+			if release != nil {
+				image, version = release.Image, release.Version
+			}
+		}
+
+		installationGroup := &model.InstallationGroup{}
+		installationGroupSpec.Apply(installationGroup)
+		validation, err := c.Elrond.ValidateRelease(installationGroup, image, version)
+		if err != nil {
+			c.Logger.WithError(err).Warnf("Failed to validate release of installation group %s", change.Name)
+			validation = &model.ReleaseValidation{
+				InstallationGroup:  installationGroup.Name,
+				ProvisionerGroupID: installationGroup.ProvisionerGroupID,
+				Image:              image,
+				Version:            version,
+				Reasons:            []string{fmt.Sprintf("failed to validate release: %s", err)},
+			}
+		}
+		validation.Ring = change.Ring
+		plan.Validations = append(plan.Validations, validation)
+	}
+
+	return nil
+}
+
+func hasField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyChanges makes the changes of the plan in order. Changes made before a
+// failure are kept, so applying the spec again resumes where it stopped.
+func applyChanges(c *Context, spec *model.FleetSpec, plan *model.ApplyPlan, ringsByName map[string]*model.Ring) error {
+	ringSpecs := make(map[string]*model.RingSpec)
+	installationGroupSpecs := make(map[string]*model.InstallationGroupSpec)
+	for _, ringSpec := range spec.Rings {
+		ringSpecs[ringSpec.Name] = ringSpec
+		for _, installationGroupSpec := range ringSpec.InstallationGroups {
+			installationGroupSpecs[installationGroupSpec.Name] = installationGroupSpec
+		}
+	}
+
+	for _, change := range plan.Changes {
+		var err error
+		switch change.ResourceType {
+		case model.TypeRing:
+			err = applyRingChange(c, change, ringSpecs[change.Name], ringsByName)
+		case model.TypeInstallationGroup:
+			err = applyInstallationGroupChange(c, change, installationGroupSpecs[change.Name], ringsByName[change.Ring])
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to %s %s %s", change.Action, change.ResourceType, change.Name)
+		}
+	}
+
+	return nil
+}
+
+func applyRingChange(c *Context, change *model.ApplyChange, ringSpec *model.RingSpec, ringsByName map[string]*model.Ring) error {
+	switch change.Action {
+	case model.ApplyActionCreate:
+		release, err := c.Store.GetOrCreateRingRelease(&model.RingRelease{
+			Version:  ringSpec.Version,
+			Image:    ringSpec.Image,
+			CreateAt: time.Now().UnixNano(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to get or create ring release")
+		}
+
+		ring := &model.Ring{
+			ActiveReleaseID:  release.ID,
+			DesiredReleaseID: release.ID,
+			Provisioner:      "elrond",
+			State:            model.RingStateCreationRequested,
+		}
+		ringSpec.Apply(ring)
+		if err = c.Store.CreateRing(ring, nil); err != nil {
+			return err
+		}
+		ringsByName[ring.Name] = ring
+
+		sendRingStateChange(c, ring, "n/a", model.RingStateCreationRequested)
+
+	case model.ApplyActionUpdate:
+		ring := ringsByName[change.Name]
+		ringSpec.Apply(ring)
+		return c.Store.UpdateRing(ring)
+
+	case model.ApplyActionDelete:
+		ring := ringsByName[change.Name]
+		oldState := ring.State
+		ring.State = model.RingStateDeletionRequested
+		if err := c.Store.UpdateRing(ring); err != nil {
+			return err
+		}
+
+		sendRingStateChange(c, ring, oldState, ring.State)
+	}
+
+	return nil
+}
+
+func applyInstallationGroupChange(c *Context, change *model.ApplyChange, installationGroupSpec *model.InstallationGroupSpec, ring *model.Ring) error {
+	switch change.Action {
+	case model.ApplyActionCreate:
+		installationGroup := &model.InstallationGroup{State: model.InstallationGroupStable}
+		installationGroupSpec.Apply(installationGroup)
+		_, _, err := c.Store.RegisterRingInstallationGroup(ring.ID, installationGroup)
+		return err
+
+	case model.ApplyActionUpdate:
+		installationGroup := findInstallationGroup(ring.InstallationGroups, change.Name)
+		installationGroupSpec.Apply(installationGroup)
+		return c.Store.UpdateInstallationGroup(installationGroup)
+
+	case model.ApplyActionDelete:
+		installationGroup := findInstallationGroup(ring.InstallationGroups, change.Name)
+		_, err := c.Store.DeregisterRingInstallationGroup(ring.ID, installationGroup.ID)
+		return err
+	}
+
+	return nil
+}
+
+// findInstallationGroup returns the installation group with the given name.
+func findInstallationGroup(installationGroups []*model.InstallationGroup, name string) *model.InstallationGroup {
+	for _, installationGroup := range installationGroups {
+		if installationGroup.Name == name {
+			return installationGroup
+		}
+	}
+
+	return nil
+}
+
+// sendRingStateChange records the state change of a ring and notifies the
+// webhooks of it.
+func sendRingStateChange(c *Context, ring *model.Ring, oldState, newState string) {
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeRing,
+		ID:        ring.ID,
+		NewState:  newState,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		Labels:    ring.Annotations,
+		Metadata:  ring.Metadata,
+	}
+	recordRingStateChange(c, ring, oldState, newState)
+
+	if err := webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	existing, err := client.CreateRing(&model.CreateRingRequest{
+		Name:              "apply-existing",
+		Priority:          1,
+		Image:             "mattermost/mattermost-enterprise-edition",
+		Version:           "7.0.0",
+		InstallationGroup: &model.InstallationGroup{Name: "apply-ig1", ProvisionerGroupID: "group1"},
+	})
+	require.NoError(t, err)
+	removed, err := client.CreateRing(&model.CreateRingRequest{Name: "apply-removed", Priority: 2})
+	require.NoError(t, err)
+	for _, ring := range []*model.Ring{existing, removed} {
+		ring.State = model.RingStateStable
+		require.NoError(t, sqlStore.UpdateRing(ring))
+	}
+
+	spec := &model.FleetSpec{Rings: []*model.RingSpec{
+		{
+			Name:     "apply-existing",
+			Priority: 1,
+			SoakTime: 300,
+			InstallationGroups: []*model.InstallationGroupSpec{
+				{Name: "apply-ig1", ProvisionerGroupID: "group1", SoakTime: 60},
+				{Name: "apply-ig2", ProvisionerGroupID: "group2"},
+			},
+		},
+		{
+			Name:               "apply-created",
+			Priority:           3,
+			Image:              "mattermost/mattermost-enterprise-edition",
+			Version:            "7.0.0",
+			InstallationGroups: []*model.InstallationGroupSpec{{Name: "apply-ig3"}},
+		},
+	}}
+	expectedChanges := []*model.ApplyChange{
+		{Action: model.ApplyActionUpdate, ResourceType: model.TypeRing, Name: "apply-existing", Fields: []string{"soakTime"}},
+		{Action: model.ApplyActionUpdate, ResourceType: model.TypeInstallationGroup, Name: "apply-ig1", Ring: "apply-existing", Fields: []string{"soakTime"}},
+		{Action: model.ApplyActionCreate, ResourceType: model.TypeInstallationGroup, Name: "apply-ig2", Ring: "apply-existing"},
+		{Action: model.ApplyActionCreate, ResourceType: model.TypeRing, Name: "apply-created"},
+		{Action: model.ApplyActionCreate, ResourceType: model.TypeInstallationGroup, Name: "apply-ig3", Ring: "apply-created"},
+		{Action: model.ApplyActionDelete, ResourceType: model.TypeRing, Name: "apply-removed"},
+	}
+
+	t.Run("invalid spec", func(t *testing.T) {
+		_, err := client.Apply(&model.FleetSpec{Rings: []*model.RingSpec{{Name: "no-priority"}}}, false)
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("plan", func(t *testing.T) {
+		plan, err := client.Apply(spec, false)
+		require.NoError(t, err)
+		require.False(t, plan.Applied)
+		require.Equal(t, expectedChanges, plan.Changes)
+
+		ring, err := client.GetRing(existing.ID)
+		require.NoError(t, err)
+		require.Zero(t, ring.SoakTime)
+	})
+
+	t.Run("security lock", func(t *testing.T) {
+		require.NoError(t, sqlStore.LockRingAPI(existing.ID))
+		defer func() { require.NoError(t, sqlStore.UnlockRingAPI(existing.ID)) }()
+
+		_, err := client.Apply(spec, true)
+		apiErr := requireAPIError(t, err, 403)
+		require.Equal(t, model.ErrorCodeAPISecurityLock, apiErr.Code)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		plan, err := client.Apply(spec, true)
+		require.NoError(t, err)
+		require.True(t, plan.Applied)
+		require.Equal(t, expectedChanges, plan.Changes)
+
+		ring, err := client.GetRing(existing.ID)
+		require.NoError(t, err)
+		require.Equal(t, 300, ring.SoakTime)
+		require.Len(t, ring.InstallationGroups, 2)
+
+		ring, err = client.GetRing(removed.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateDeletionRequested, ring.State)
+
+		plan, err = client.Apply(spec, true)
+		require.NoError(t, err)
+		require.False(t, plan.Applied)
+		require.Empty(t, plan.Changes)
+	})
+}
+
+func TestApplyValidateReleases(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	elrond := &mockElrond{rejectedGroups: map[string]string{"group-locked": "API changes are locked for provisioner group group-locked"}}
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Elrond:     elrond,
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	_, err := client.CreateRing(&model.CreateRingRequest{
+		Name:              "validate-existing",
+		Priority:          1,
+		Image:             "mattermost/mattermost-enterprise-edition",
+		Version:           "7.0.0",
+		InstallationGroup: &model.InstallationGroup{Name: "validate-ig1", ProvisionerGroupID: "group1"},
+	})
+	require.NoError(t, err)
+
+	spec := &model.FleetSpec{Rings: []*model.RingSpec{
+		{
+			Name:     "validate-existing",
+			Priority: 1,
+			InstallationGroups: []*model.InstallationGroupSpec{
+				{Name: "validate-ig1", ProvisionerGroupID: "group1", SoakTime: 60},
+				{Name: "validate-ig2", ProvisionerGroupID: "group-locked"},
+			},
+		},
+		{
+			Name:     "validate-created",
+			Priority: 2,
+			Image:    "mattermost/mattermost-enterprise-edition",
+			Version:  "7.1.0",
+			InstallationGroups: []*model.InstallationGroupSpec{
+				{Name: "validate-ig3", ProvisionerGroupID: "group3"},
+				{Name: "validate-ig4"},
+			},
+		},
+	}}
+
+	t.Run("plan", func(t *testing.T) {
+		plan, err := client.Apply(spec, false)
+		require.NoError(t, err)
+		require.False(t, plan.Applied)
+		require.Equal(t, []*model.ReleaseValidation{
+			{
+				InstallationGroup:  "validate-ig2",
+				Ring:               "validate-existing",
+				ProvisionerGroupID: "group-locked",
+				Image:              "mattermost/mattermost-enterprise-edition",
+				Version:            "7.0.0",
+				Reasons:            []string{"API changes are locked for provisioner group group-locked"},
+			},
+			{
+				InstallationGroup:  "validate-ig3",
+				Ring:               "validate-created",
+				ProvisionerGroupID: "group3",
+				Image:              "mattermost/mattermost-enterprise-edition",
+				Version:            "7.1.0",
+				Accepted:           true,
+			},
+		}, plan.Validations)
+		require.Len(t, plan.Rejected(), 1)
+	})
+
+	t.Run("provisioner failure", func(t *testing.T) {
+		elrond.validationError = errors.New("provisioner unavailable")
+		defer func() { elrond.validationError = nil }()
+
+		plan, err := client.Apply(spec, false)
+		require.NoError(t, err)
+		require.Len(t, plan.Rejected(), 2)
+		require.Equal(t, []string{"failed to validate release: provisioner unavailable"}, plan.Validations[0].Reasons)
+	})
+
+	t.Run("not validated when applied", func(t *testing.T) {
+		plan, err := client.Apply(spec, true)
+		require.NoError(t, err)
+		require.True(t, plan.Applied)
+		require.Empty(t, plan.Validations)
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// initCalendar registers calendar endpoints on the given router.
+func initCalendar(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	apiRouter.Handle("/calendar", addContext(handleGetCalendar)).Methods("GET")
+}
+
+// handleGetCalendar responds to GET /api/calendar, returning the releases,
+// freeze windows and scheduled deletions of the rings between the from and to
+// times, in milliseconds. With format=ical, the calendar is exported in the
+// iCalendar format.
+func handleGetCalendar(c *Context, w http.ResponseWriter, r *http.Request) {
+	now := model.GetMillis()
+	from, err := parseInt64(r.URL, "from", now-model.DefaultCalendarRange)
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "from must be a time in milliseconds")
+		return
+	}
+	to, err := parseInt64(r.URL, "to", now+model.DefaultCalendarRange)
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "to must be a time in milliseconds")
+		return
+	}
+	if to < from {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "to must not be before from")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "ical" {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "format must be json or ical")
+		return
+	}
+
+	filter := &model.RingFilter{
+		PerPage:        model.AllPerPage,
+		IncludeDeleted: true,
+	}
+	rings, err := c.Store.GetRings(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query rings")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings")
+		return
+	}
+
+	installationGroups, err := c.Store.GetInstallationGroupsForRings(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get installation groups for rings")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get installation groups for rings")
+		return
+	}
+
+	events, err := c.Store.GetStateChangeEvents(&model.StateChangeEventFilter{
+		PerPage: model.MaxStateChangeEventsPerQuery,
+		Latest:  true,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query state change events")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query state change events")
+		return
+	}
+	ringEvents := make(map[string][]*model.StateChangeEvent)
+	for _, event := range events {
+		ringEvents[event.RingID] = append(ringEvents[event.RingID], event)
+	}
+
+	calendar := &model.Calendar{From: from, To: to, Entries: []*model.CalendarEntry{}}
+	for _, ring := range rings {
+		if ring.HasUnfinishedRelease() {
+			ring.EstimatedCompletionAt = model.EstimateReleaseCompletion(ring, installationGroups[ring.ID], ringEvents[ring.ID], now)
+		}
+		calendar.AddEntries(model.BuildRingCalendarEntries(ring, ringEvents[ring.ID]))
+	}
+
+	releases := make(map[string]*model.RingRelease)
+	for _, entry := range calendar.Entries {
+		if entry.Type != model.CalendarEntryRelease || entry.ReleaseID == "" {
+			continue
+		}
+		release, ok := releases[entry.ReleaseID]
+		if !ok {
+			release, err = c.Store.GetRingRelease(entry.ReleaseID)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to query ring release")
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring release")
+				return
+			}
+			releases[entry.ReleaseID] = release
+		}
+		if release != nil {
+			entry.Image = release.Image
+			entry.Version = release.Version
+		}
+	}
+
+	if format == "ical" {
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if _, err = w.Write(calendar.ICal()); err != nil {
+			c.Logger.WithError(err).Warn("failed to write iCalendar response")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, calendar)
+}
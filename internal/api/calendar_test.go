@@ -0,0 +1,131 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCalendar(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("invalid range", func(t *testing.T) {
+		_, err := client.GetCalendar(&model.GetCalendarRequest{From: 2000, To: 1000})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("%s/api/v1/calendar?format=csv", ts.URL))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		calendar, err := client.GetCalendar(&model.GetCalendarRequest{})
+		require.NoError(t, err)
+		require.Empty(t, calendar.Entries)
+		require.Less(t, calendar.From, calendar.To)
+	})
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Name: "production", Priority: 1, SoakTime: 60})
+	require.NoError(t, err)
+
+	release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.8.0"})
+	require.NoError(t, err)
+
+	events := []*model.StateChangeEvent{
+		{ResourceType: model.TypeRing, ResourceID: ring.ID, NewState: model.RingStateReleasePending, Timestamp: 1000},
+		{ResourceType: model.TypeRing, ResourceID: ring.ID, NewState: model.RingStateReleaseRequested, Timestamp: 2000},
+		{ResourceType: model.TypeRing, ResourceID: ring.ID, NewState: model.RingStateStable, Timestamp: 5000},
+	}
+	for _, event := range events {
+		event.RingID = ring.ID
+		event.ReleaseID = release.ID
+		require.NoError(t, sqlStore.CreateStateChangeEvent(event))
+	}
+
+	t.Run("historical release", func(t *testing.T) {
+		calendar, err := client.GetCalendar(&model.GetCalendarRequest{From: 1, To: 10000})
+		require.NoError(t, err)
+		require.Len(t, calendar.Entries, 1)
+		entry := calendar.Entries[0]
+		require.Equal(t, model.CalendarEntryRelease, entry.Type)
+		require.Equal(t, ring.ID, entry.RingID)
+		require.Equal(t, "production", entry.RingName)
+		require.Equal(t, "7.8.0", entry.Version)
+		require.Equal(t, int64(1000), entry.StartAt)
+		require.Equal(t, int64(5000), entry.EndAt)
+
+		calendar, err = client.GetCalendar(&model.GetCalendarRequest{From: 6000, To: 10000})
+		require.NoError(t, err)
+		require.Empty(t, calendar.Entries)
+	})
+
+	t.Run("paused release", func(t *testing.T) {
+		ring, err = client.GetRing(ring.ID)
+		require.NoError(t, err)
+		ring.State = model.RingStateReleasePending
+		require.NoError(t, sqlStore.UpdateRing(ring))
+		require.NoError(t, client.PauseRelease())
+
+		freeze := func() *model.CalendarEntry {
+			calendar, err := client.GetCalendar(&model.GetCalendarRequest{})
+			require.NoError(t, err)
+			for _, entry := range calendar.Entries {
+				if entry.Type == model.CalendarEntryFreeze {
+					return entry
+				}
+			}
+			return nil
+		}
+
+		entry := freeze()
+		require.NotNil(t, entry)
+		require.Equal(t, ring.ID, entry.RingID)
+		require.Zero(t, entry.EndAt)
+
+		// Events of the same millisecond are not ordered by time.
+		time.Sleep(1 * time.Millisecond)
+		_, err = client.ResumeRelease()
+		require.NoError(t, err)
+
+		entry = freeze()
+		require.NotNil(t, entry)
+		require.NotZero(t, entry.EndAt)
+	})
+
+	t.Run("ical", func(t *testing.T) {
+		data, err := client.GetCalendarICal(&model.GetCalendarRequest{From: 1, To: 10000})
+		require.NoError(t, err)
+		// Unfold the long lines.
+		ical := strings.ReplaceAll(string(data), "\r\n ", "")
+		require.True(t, strings.HasPrefix(ical, "BEGIN:VCALENDAR\r\n"))
+		require.Contains(t, ical, "SUMMARY:Release of ring production to mattermost/mattermost-enterprise-edition:7.8.0 (stable)")
+	})
+}
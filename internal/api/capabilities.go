@@ -0,0 +1,42 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// serverCapabilities are the optional features of the API served. elrond
+// serves no gRPC API, so model.CapabilityGRPC is not one of them.
+var serverCapabilities = []string{
+	model.CapabilityEvents,
+	model.CapabilityPagination,
+}
+
+// initCapabilities registers the capability negotiation endpoint on the given
+// router.
+func initCapabilities(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	apiRouter.Handle("/capabilities", addContext(handleGetCapabilities)).Methods("GET")
+}
+
+// handleGetCapabilities responds to GET /api/capabilities, returning the API
+// version selected for the client, the API versions supported and the
+// capabilities of the server.
+func handleGetCapabilities(c *Context, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, &model.APICapabilities{
+		APIVersion:   c.APIVersion,
+		APIVersions:  model.SupportedAPIVersions,
+		Capabilities: serverCapabilities,
+	})
+}
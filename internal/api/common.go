@@ -6,7 +6,11 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"net/http"
+
+	"github.com/mattermost/elrond/model"
 )
 
 // outputJSON is a helper method to write the given data as JSON to the given writer.
@@ -20,3 +24,53 @@ func outputJSON(c *Context, w io.Writer, data interface{}) {
 		c.Logger.WithError(err).Error("failed to encode result")
 	}
 }
+
+// outputError writes the JSON error envelope with the given status code.
+func outputError(c *Context, w http.ResponseWriter, statusCode int, code, message string) {
+	outputErrorWithDetails(c, w, statusCode, code, message, nil)
+}
+
+// outputErrorWithDetails writes the JSON error envelope with the given status
+// code, including additional machine-readable details.
+func outputErrorWithDetails(c *Context, w http.ResponseWriter, statusCode int, code, message string, details map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	outputJSON(c, w, model.ErrorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: c.RequestID,
+	})
+}
+
+// outputStatusError writes the JSON error envelope for a status code returned
+// by one of the locking helpers.
+func outputStatusError(c *Context, w http.ResponseWriter, statusCode int, resourceType string) {
+	switch statusCode {
+	case http.StatusNotFound:
+		outputError(c, w, statusCode, model.ErrorCodeNotFound, fmt.Sprintf("%s not found", resourceType))
+	case http.StatusConflict:
+		outputError(c, w, statusCode, model.ErrorCodeLockConflict, fmt.Sprintf("%s is locked by another request", resourceType))
+	default:
+		outputError(c, w, statusCode, model.ErrorCodeFromStatus(statusCode), http.StatusText(statusCode))
+	}
+}
+
+// recordRingStateChange records the state change event of the given ring. The
+// event history is informational, so failures are only logged.
+func recordRingStateChange(c *Context, ring *model.Ring, oldState, newState string) {
+	if err := c.Store.CreateStateChangeEvent(model.NewStateChangeEvent(model.TypeRing, ring.ID, ring, oldState, newState)); err != nil {
+		c.Logger.WithError(err).Warn("failed to record ring state change event")
+	}
+}
+
+// recordInstallationGroupStateChange records the state change event of the
+// given installation group against its ring. The event history is
+// informational, so failures are only logged.
+func recordInstallationGroupStateChange(c *Context, installationGroup *model.InstallationGroup, ring *model.Ring, oldState, newState string) {
+	event := model.NewStateChangeEvent(model.TypeInstallationGroup, installationGroup.ID, ring, oldState, newState)
+	event.Metadata = installationGroup.Metadata
+	if err := c.Store.CreateStateChangeEvent(event); err != nil {
+		c.Logger.WithError(err).Warn("failed to record installation group state change event")
+	}
+}
@@ -4,9 +4,64 @@
 
 package api_test
 
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
 type mockSupervisor struct {
 }
 
 func (s *mockSupervisor) Do() error {
 	return nil
 }
+
+// requireAPIError asserts that err is an API error envelope with the given status code.
+func requireAPIError(t *testing.T, err error, statusCode int) *model.APIError {
+	t.Helper()
+
+	require.Error(t, err)
+	apiErr, ok := err.(*model.APIError)
+	require.True(t, ok, "expected *model.APIError, got %T", err)
+	require.Equal(t, statusCode, apiErr.StatusCode)
+	require.NotEmpty(t, apiErr.Code)
+	require.NotEmpty(t, apiErr.RequestID)
+
+	return apiErr
+}
+
+// newTenantClient returns a client authenticated with a new write token of
+// the given tenant.
+func newTenantClient(t *testing.T, sqlStore *store.SQLStore, address, tenantID string) *model.Client {
+	t.Helper()
+
+	return model.NewClientWithToken(address, createTokenSecret(t, sqlStore, model.TokenRoleWrite, tenantID))
+}
+
+// newAdminClient returns a client authenticated with a new admin token of no
+// tenant.
+func newAdminClient(t *testing.T, sqlStore *store.SQLStore, address string) *model.Client {
+	t.Helper()
+
+	return model.NewClientWithToken(address, createTokenSecret(t, sqlStore, model.TokenRoleAdmin, ""))
+}
+
+// createTokenSecret creates a token with the given role and tenant, returning
+// its secret.
+func createTokenSecret(t *testing.T, sqlStore *store.SQLStore, role, tenantID string) string {
+	t.Helper()
+
+	secret, err := model.NewTokenSecret()
+	require.NoError(t, err)
+	require.NoError(t, sqlStore.CreateToken(&model.Token{
+		Name:      role + tenantID,
+		TenantID:  tenantID,
+		Role:      role,
+		TokenHash: model.HashTokenSecret(secret),
+	}))
+
+	return secret
+}
@@ -7,6 +7,7 @@ package api
 import (
 	"github.com/mattermost/elrond/model"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 )
 
 // Supervisor describes the interface to notify the background jobs of an actionable change.
@@ -21,36 +22,135 @@ type Store interface {
 	GetRings(filter *model.RingFilter) ([]*model.Ring, error)
 	UpdateRing(ring *model.Ring) error
 	UpdateRings(rings []*model.Ring) error
+	UpdateRingAndInstallationGroups(ring *model.Ring, installationGroups []*model.InstallationGroup) error
 	LockRing(ringID, lockerID string) (bool, error)
 	LockRings(rings []string, lockerID string) (bool, error)
 	UnlockRing(ringID, lockerID string, force bool) (bool, error)
 	UnlockRings(rings []string, lockerID string, force bool) (bool, error)
 	LockRingAPI(ringID string) error
 	UnlockRingAPI(ringID string) error
+	SetRingProtected(change *model.RingProtectionChange) error
+	GetRingProtectionChanges(ringID string) ([]*model.RingProtectionChange, error)
+	CreateNote(note *model.Note) error
+	GetNotes(filter *model.NoteFilter) ([]*model.Note, error)
 	DeleteRing(ringID string) error
 
 	GetInstallationGroupsForRings(filter *model.RingFilter) (map[string][]*model.InstallationGroup, error)
 	GetInstallationGroupsForRing(ringID string) ([]*model.InstallationGroup, error)
-	CreateRingInstallationGroup(ringID string, installationGroup *model.InstallationGroup) (*model.InstallationGroup, error)
-	DeleteRingInstallationGroup(ringID string, installationGroup string) error
+	RegisterRingInstallationGroup(ringID string, installationGroup *model.InstallationGroup) (*model.InstallationGroup, *model.RingInstallationGroupChange, error)
+	DeregisterRingInstallationGroup(ringID, installationGroupID string) (*model.RingInstallationGroupChange, error)
 	UpdateInstallationGroup(installationGroup *model.InstallationGroup) error
 	GetInstallationGroupByID(installationGroupID string) (*model.InstallationGroup, error)
+	GetInstallationGroupByName(name string) (*model.InstallationGroup, error)
+	GetRingFromInstallationGroupID(installationGroupID string) (*model.Ring, error)
+	UpdateInstallationGroupArchivedAt(installationGroupID string, archivedAt int64) error
+	GetInstallationGroupsByProvisionerGroupID(provisionerGroupID string) ([]*model.InstallationGroup, error)
+	UpdateInstallationGroupReleaseProgress(installationGroupID string, progress int) error
+	DelayRingInstallationGroupsSoak(ringID string, delay int64) error
+	GetInstallationGroupsLocked() ([]*model.InstallationGroup, error)
+	GetInstallationGroupsReleaseInProgress() ([]*model.InstallationGroup, error)
 	LockRingInstallationGroup(installationGroupID, lockerID string) (bool, error)
 	UnlockRingInstallationGroup(installationGroupID, lockerID string, force bool) (bool, error)
 
 	GetRingRelease(releaseID string) (*model.RingRelease, error)
+	GetRingReleases(filter *model.RingReleaseFilter) ([]*model.RingRelease, error)
 	GetOrCreateRingRelease(ringRelease *model.RingRelease) (*model.RingRelease, error)
+	UpdateRingReleaseLinks(releaseID string, links model.ReleaseLinks) error
+	UpdateRingReleaseLabels(releaseID string, labels model.ReleaseLabels) error
+	GetRingReleaseSnapshot(snapshotID string) (*model.RingReleaseSnapshot, error)
+	CreateStateChangeEvent(event *model.StateChangeEvent) error
+	GetStateChangeEvents(filter *model.StateChangeEventFilter) ([]*model.StateChangeEvent, error)
+	GetSoakCheckResults(filter *model.SoakCheckResultFilter) ([]*model.SoakCheckResult, error)
+	GetHealthSnapshots(filter *model.HealthSnapshotFilter) ([]*model.HealthSnapshot, error)
+	GetDatabaseSnapshots(filter *model.DatabaseSnapshotFilter) ([]*model.DatabaseSnapshot, error)
 	GetUnlockedRingsPendingWork() ([]*model.Ring, error)
 	GetRingsInPendingState() ([]*model.Ring, error)
+	GetRingsReleaseScheduled() ([]*model.Ring, error)
+	GetRingsLocked() ([]*model.Ring, error)
+	GetRingsReleaseInProgress() ([]*model.Ring, error)
+
+	CreateRollout(rollout *model.Rollout) error
+	GetRollout(rolloutID string) (*model.Rollout, error)
+	GetRollouts(filter *model.RolloutFilter) ([]*model.Rollout, error)
+	UpdateRollout(rollout *model.Rollout) error
+	LockRollout(rolloutID, lockerID string) (bool, error)
+	UnlockRollout(rolloutID, lockerID string, force bool) (bool, error)
+	CreateJob(job *model.Job) error
+	GetJob(jobID string) (*model.Job, error)
+	GetJobs(filter *model.JobFilter) ([]*model.Job, error)
 
 	CreateWebhook(webhook *model.Webhook) error
 	GetWebhook(webhookID string) (*model.Webhook, error)
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
 	DeleteWebhook(webhookID string) error
+	GetWebhookDelivery(deliveryID string) (*model.WebhookDelivery, error)
+	GetWebhookDeliveries(filter *model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, error)
+	UpdateWebhookDelivery(delivery *model.WebhookDelivery) error
+
+	CreateNotificationTemplate(notificationTemplate *model.NotificationTemplate) error
+	GetNotificationTemplate(id string) (*model.NotificationTemplate, error)
+	GetNotificationTemplates(filter *model.NotificationTemplateFilter) ([]*model.NotificationTemplate, error)
+	UpdateNotificationTemplate(notificationTemplate *model.NotificationTemplate) error
+	DeleteNotificationTemplate(id string) error
+
+	CreateToken(token *model.Token) error
+	GetToken(tokenID string) (*model.Token, error)
+	GetTokenByHash(tokenHash string) (*model.Token, error)
+	GetTokens(filter *model.TokenFilter) ([]*model.Token, error)
+	DeleteToken(tokenID string) error
+
+	CreateProvisionerCredentials(credentials *model.ProvisionerCredentials) error
+	GetLatestProvisionerCredentials() (*model.ProvisionerCredentials, error)
+
+	GetIdempotencyKey(key string) (*model.IdempotencyKey, error)
+	ReserveIdempotencyKey(idempotencyKey *model.IdempotencyKey) (bool, error)
+	CompleteIdempotencyKey(key, resourceID string) error
+	ReleaseIdempotencyKey(idempotencyKey *model.IdempotencyKey) error
+
+	GetPendingForceApproval(action, target string, now int64) (*model.ForceApproval, error)
+	GetForceApprovals(filter *model.ForceApprovalFilter) ([]*model.ForceApproval, error)
+	CreateForceApproval(approval *model.ForceApproval) error
+	ConfirmForceApproval(approval *model.ForceApproval, confirmedBy string) (bool, error)
+
+	GetWorkPriorityRange(resourceType string) (int, int, error)
+	SetWorkPriority(resourceType, id string, priority int) (bool, error)
+
+	GetDatabaseSchema() (*model.DatabaseSchema, error)
 }
 
 // Elrond describes the interface.
 type Elrond interface {
+	SetProvisionerCredentials(credentialsID string, headers map[string]string)
+	HandleGroupCallback(callback *model.ProvisionerCallback)
+	ValidateRelease(installationGroup *model.InstallationGroup, image, version string) (*model.ReleaseValidation, error)
+}
+
+// Reloader describes the interface to reload the configuration of the server.
+type Reloader interface {
+	Reload() error
+}
+
+// SoakTimeReporter describes the interface to get the latest soak time
+// report built in the background.
+type SoakTimeReporter interface {
+	Report() *model.SoakTimeReport
+}
+
+// PolicyEngine describes the interface to evaluate the authorization policy
+// of the requests changing elrond.
+type PolicyEngine interface {
+	Evaluate(input *model.PolicyInput) (*model.PolicyDecision, error)
+}
+
+// MaintenanceChecker describes the interface to look up the infrastructure
+// maintenance overlapping the release of a ring.
+type MaintenanceChecker interface {
+	Conflicts(query *model.MaintenanceConflictQuery) ([]*model.MaintenanceConflict, error)
+}
+
+// Encrypter describes the interface to encrypt secrets before they are stored.
+type Encrypter interface {
+	Encrypt(plaintext []byte) ([]byte, error)
 }
 
 // Context provides the API with all necessary data and interfaces for responding to requests.
@@ -61,17 +161,50 @@ type Context struct {
 	Supervisor        Supervisor
 	Elrond            Elrond
 	RequestID         string
+	APIVersion        int
+	TenantID          string
+	TokenID           string
+	TokenName         string
+	TokenRole         string
 	Environment       string
 	Logger            logrus.FieldLogger
 	ProvisionerServer string
+	TenantQuotas      *model.TenantQuotas
+	RequireToken      bool
+	Reloader          Reloader
+	SoakTimeReporter  SoakTimeReporter
+	ReadCache         *ReadCache
+	// MaintenanceChecker, when set, holds prepared releases overlapping
+	// infrastructure maintenance as they are committed.
+	MaintenanceChecker MaintenanceChecker
+	// Policy, when set, authorizes every request changing elrond.
+	Policy PolicyEngine
+
+	CredentialsEncrypter       Encrypter
+	CredentialsRotationLimiter *rate.Limiter
+
+	// RingScopedToken is the token of the request when it is restricted to
+	// changing some rings.
+	RingScopedToken *model.Token
 }
 
 // Clone creates a shallow copy of context, allowing clones to apply per-request changes.
 func (c *Context) Clone() *Context {
 	return &Context{
-		Store:      c.Store,
-		Supervisor: c.Supervisor,
-		Elrond:     c.Elrond,
-		Logger:     c.Logger,
+		Store:              c.Store,
+		Supervisor:         c.Supervisor,
+		Elrond:             c.Elrond,
+		Logger:             c.Logger,
+		Environment:        c.Environment,
+		TenantQuotas:       c.TenantQuotas,
+		RequireToken:       c.RequireToken,
+		Reloader:           c.Reloader,
+		SoakTimeReporter:   c.SoakTimeReporter,
+		ReadCache:          c.ReadCache,
+		Policy:             c.Policy,
+		MaintenanceChecker: c.MaintenanceChecker,
+
+		CredentialsEncrypter:       c.CredentialsEncrypter,
+		CredentialsRotationLimiter: c.CredentialsRotationLimiter,
 	}
 }
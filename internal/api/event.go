@@ -0,0 +1,138 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// maxEventsPerPage is the largest page of the events stream served at once.
+const maxEventsPerPage = 1000
+
+// initEvent registers event endpoints on the given router.
+func initEvent(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	eventsRouter := apiRouter.PathPrefix("/events").Subrouter()
+	eventsRouter.Handle("", addContext(handleGetStateChangeEvents)).Methods("GET")
+}
+
+// handleGetStateChangeEvents responds to GET /api/events, returning the page
+// of state change events following the given after cursor.
+func handleGetStateChangeEvents(c *Context, w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseStateChangeEventFilter(c, w, r)
+	if !ok {
+		return
+	}
+	filter.RingID = r.URL.Query().Get("ring")
+
+	outputStateChangeEventsPage(c, w, filter, r.URL.Query().Get("after"))
+}
+
+// handleGetRingStateChangeEvents responds to GET /api/ring/{ring}/events,
+// returning the page of state change events of the ring and its installation
+// groups following the given after cursor.
+func handleGetRingStateChangeEvents(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ringID := vars["ring"]
+	c.Logger = c.Logger.WithField("ring", ringID)
+
+	filter, ok := parseStateChangeEventFilter(c, w, r)
+	if !ok {
+		return
+	}
+	filter.RingID = ringID
+
+	ring, err := c.Store.GetRing(ringID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+		return
+	}
+	if ring == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring not found")
+		return
+	}
+
+	outputStateChangeEventsPage(c, w, filter, r.URL.Query().Get("after"))
+}
+
+// parseStateChangeEventFilter parses the filter of the state change events
+// requested, writing the error if it is invalid.
+func parseStateChangeEventFilter(c *Context, w http.ResponseWriter, r *http.Request) (*model.StateChangeEventFilter, bool) {
+	perPage, err := parseInt(r.URL, "per_page", 100)
+	if err != nil || perPage < 1 || perPage > maxEventsPerPage {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("per_page must be between 1 and %d", maxEventsPerPage))
+		return nil, false
+	}
+	from, err := parseInt64(r.URL, "from", 0)
+	if err != nil || from < 0 {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "from must be a time in milliseconds")
+		return nil, false
+	}
+	to, err := parseInt64(r.URL, "to", 0)
+	if err != nil || to < 0 {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "to must be a time in milliseconds")
+		return nil, false
+	}
+	if to != 0 && to <= from {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "to must be after from")
+		return nil, false
+	}
+
+	query := r.URL.Query()
+	filter := &model.StateChangeEventFilter{
+		ResourceType: query.Get("resource_type"),
+		ResourceID:   query.Get("resource_id"),
+		ReleaseID:    query.Get("release"),
+		State:        query.Get("state"),
+		From:         from,
+		To:           to,
+		PerPage:      perPage,
+	}
+
+	// The stream is paged by cursor, starting before the first event.
+	filter.After = &model.EventCursor{}
+	after := query.Get("after")
+	if after != "" {
+		filter.After, err = model.ParseEventCursor(after)
+		if err != nil {
+			c.Logger.WithError(err).Debug("invalid event cursor")
+			outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "invalid after cursor")
+			return nil, false
+		}
+	}
+
+	return filter, true
+}
+
+// outputStateChangeEventsPage writes the page of the state change events
+// matching the given filter, following the given after cursor.
+func outputStateChangeEventsPage(c *Context, w http.ResponseWriter, filter *model.StateChangeEventFilter, after string) {
+	events, err := c.Store.GetStateChangeEvents(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query state change events")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query state change events")
+		return
+	}
+
+	page := &model.StateChangeEventsPage{Events: events, After: after}
+	if page.Events == nil {
+		page.Events = []*model.StateChangeEvent{}
+	}
+	if len(page.Events) > 0 {
+		page.After = page.Events[len(page.Events)-1].Cursor()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, page)
+}
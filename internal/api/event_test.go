@@ -0,0 +1,174 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStateChangeEvents(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("no events", func(t *testing.T) {
+		page, err := client.GetStateChangeEvents(&model.GetStateChangeEventsRequest{})
+		require.NoError(t, err)
+		require.Empty(t, page.Events)
+		require.Empty(t, page.After)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"after=invalid", "per_page=0", "per_page=invalid", "per_page=100000", "from=invalid", "to=-1", "from=2&to=1"} {
+			resp, err := http.Get(fmt.Sprintf("%s/api/events?%s", ts.URL, query))
+			require.NoError(t, err)
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		}
+	})
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, sqlStore.CreateStateChangeEvent(&model.StateChangeEvent{
+			ResourceType: model.TypeRing,
+			ResourceID:   "ring1",
+			RingID:       "ring1",
+			NewState:     model.RingStateStable,
+			Timestamp:    int64(i),
+		}))
+	}
+
+	t.Run("tail events with cursors", func(t *testing.T) {
+		page, err := client.GetStateChangeEvents(&model.GetStateChangeEventsRequest{PerPage: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 2)
+		require.Equal(t, int64(1), page.Events[0].Timestamp)
+		require.NotEmpty(t, page.After)
+
+		page, err = client.GetStateChangeEvents(&model.GetStateChangeEventsRequest{After: page.After, PerPage: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 1)
+		require.Equal(t, int64(3), page.Events[0].Timestamp)
+
+		after := page.After
+		page, err = client.GetStateChangeEvents(&model.GetStateChangeEventsRequest{After: after, PerPage: 2})
+		require.NoError(t, err)
+		require.Empty(t, page.Events)
+		require.Equal(t, after, page.After)
+
+		require.NoError(t, sqlStore.CreateStateChangeEvent(&model.StateChangeEvent{
+			ResourceType: model.TypeRing,
+			ResourceID:   "ring1",
+			RingID:       "ring1",
+			NewState:     model.RingStateReleasePending,
+			Timestamp:    4,
+		}))
+
+		page, err = client.GetStateChangeEvents(&model.GetStateChangeEventsRequest{After: after, PerPage: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 1)
+		require.Equal(t, model.RingStateReleasePending, page.Events[0].NewState)
+	})
+
+	t.Run("filter by ring", func(t *testing.T) {
+		page, err := client.GetStateChangeEvents(&model.GetStateChangeEventsRequest{RingID: "ring2"})
+		require.NoError(t, err)
+		require.Empty(t, page.Events)
+	})
+
+	t.Run("filter by state and time range", func(t *testing.T) {
+		page, err := client.GetStateChangeEvents(&model.GetStateChangeEventsRequest{State: model.RingStateStable, From: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 2)
+		require.Equal(t, int64(2), page.Events[0].Timestamp)
+
+		page, err = client.GetStateChangeEvents(&model.GetStateChangeEventsRequest{State: model.RingStateStable, From: 1, To: 3})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 2)
+		require.Equal(t, int64(1), page.Events[0].Timestamp)
+	})
+}
+
+func TestGetRingStateChangeEvents(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Priority: 1})
+	require.NoError(t, err)
+
+	t.Run("unknown ring", func(t *testing.T) {
+		_, err := client.GetRingStateChangeEvents(model.NewID(), &model.GetStateChangeEventsRequest{})
+		requireAPIError(t, err, http.StatusNotFound)
+	})
+
+	require.NoError(t, sqlStore.CreateStateChangeEvent(&model.StateChangeEvent{
+		ResourceType: model.TypeInstallationGroup,
+		ResourceID:   "ig1",
+		RingID:       ring.ID,
+		ReleaseID:    "release1",
+		OldState:     model.InstallationGroupReleaseRequested,
+		NewState:     model.InstallationGroupReleaseFailed,
+		Error:        "failed to release installation group",
+	}))
+	require.NoError(t, sqlStore.CreateStateChangeEvent(&model.StateChangeEvent{
+		ResourceType: model.TypeRing,
+		ResourceID:   "ring2",
+		RingID:       "ring2",
+		NewState:     model.RingStateCreationRequested,
+	}))
+
+	t.Run("ring events", func(t *testing.T) {
+		page, err := client.GetRingStateChangeEvents(ring.ID, &model.GetStateChangeEventsRequest{})
+		require.NoError(t, err)
+		require.NotEmpty(t, page.Events)
+		for _, event := range page.Events {
+			require.Equal(t, ring.ID, event.RingID)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		page, err := client.GetRingStateChangeEvents(ring.ID, &model.GetStateChangeEventsRequest{
+			ResourceType: model.TypeInstallationGroup,
+			ReleaseID:    "release1",
+			State:        model.InstallationGroupReleaseFailed,
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 1)
+		require.Equal(t, "failed to release installation group", page.Events[0].Error)
+
+		page, err = client.GetRingStateChangeEvents(ring.ID, &model.GetStateChangeEventsRequest{State: model.InstallationGroupStable})
+		require.NoError(t, err)
+		require.Empty(t, page.Events)
+	})
+}
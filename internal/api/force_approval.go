@@ -0,0 +1,185 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+	"github.com/sirupsen/logrus"
+)
+
+// initForceApproval registers force approval endpoints on the given router.
+func initForceApproval(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	forceApprovalsRouter := apiRouter.PathPrefix("/force-approvals").Subrouter()
+	forceApprovalsRouter.Handle("", addContext(handleGetForceApprovals)).Methods("GET")
+}
+
+// handleGetForceApprovals responds to GET /api/force-approvals, returning the
+// requested and confirmed forced actions, newest first.
+func handleGetForceApprovals(c *Context, w http.ResponseWriter, r *http.Request) {
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to parse paging parameters: %s", err))
+		return
+	}
+
+	approvals, err := c.Store.GetForceApprovals(&model.ForceApprovalFilter{
+		RingID:  r.URL.Query().Get("ring"),
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query force approvals")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query force approvals")
+		return
+	}
+	if approvals == nil {
+		approvals = []*model.ForceApproval{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, approvals)
+}
+
+// requireForceApproval enforces the two-person rule on a forced action on the
+// given rings. The first request of the action is recorded and rejected until
+// a token of a second account sends the same request within the shortest
+// force approval window of the rings. It writes an error response and returns false if the
+// action must not take effect yet.
+func requireForceApproval(c *Context, w http.ResponseWriter, action string, rings []*model.Ring, request interface{}) bool {
+	var ringIDs []string
+	window := 0
+	for _, ring := range rings {
+		if ring.ForceApprovalWindow <= 0 {
+			continue
+		}
+		ringIDs = append(ringIDs, ring.ID)
+		if window == 0 || ring.ForceApprovalWindow < window {
+			window = ring.ForceApprovalWindow
+		}
+	}
+	if len(ringIDs) == 0 {
+		return true
+	}
+
+	target := model.ForceApprovalTarget(ringIDs)
+	logger := c.Logger.WithFields(logrus.Fields{"action": action, "target": target})
+
+	if c.TokenID == "" {
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeForbidden, fmt.Sprintf("forced %s of rings %s must be requested with an API token to be confirmed by a second token", action, target))
+		return false
+	}
+
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		logger.WithError(err).Error("failed to encode forced request")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to encode forced request")
+		return false
+	}
+	sum := sha256.Sum256(requestJSON)
+	requestHash := hex.EncodeToString(sum[:])
+
+	approval, err := c.Store.GetPendingForceApproval(action, target, model.GetMillis())
+	if err != nil {
+		logger.WithError(err).Error("failed to query pending force approval")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query pending force approval")
+		return false
+	}
+
+	requestedBySameAccount := false
+	if approval != nil && approval.RequestHash == requestHash {
+		requestedBySameAccount, err = isForceApprovalRequester(c, approval)
+		if err != nil {
+			logger.WithError(err).Error("failed to query the token that requested the force approval")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query the token that requested the force approval")
+			return false
+		}
+	}
+
+	if approval != nil && approval.RequestHash == requestHash && !requestedBySameAccount {
+		confirmed, err := c.Store.ConfirmForceApproval(approval, c.TokenID)
+		if err != nil {
+			logger.WithError(err).Error("failed to confirm force approval")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to confirm force approval")
+			return false
+		}
+		if !confirmed {
+			outputError(c, w, http.StatusConflict, model.ErrorCodeConflict, fmt.Sprintf("force approval %s was confirmed by another request or expired", approval.ID))
+			return false
+		}
+
+		logger.WithFields(logrus.Fields{
+			"approval":     approval.ID,
+			"requested-by": approval.RequestedBy,
+			"confirmed-by": approval.ConfirmedBy,
+		}).Info("Forced action confirmed by a second token")
+
+		return true
+	}
+
+	// A new request, or a different one, replaces any pending approval,
+	// and the account that requested the action cannot confirm it.
+	if approval == nil || approval.RequestHash != requestHash {
+		approval = &model.ForceApproval{
+			Action:      action,
+			Target:      target,
+			RequestHash: requestHash,
+			RequestedBy: c.TokenID,
+			ExpireAt:    model.GetMillis() + int64(window)*1000,
+		}
+		if err = c.Store.CreateForceApproval(approval); err != nil {
+			logger.WithError(err).Error("failed to create force approval")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to create force approval")
+			return false
+		}
+		logger.WithFields(logrus.Fields{
+			"approval":     approval.ID,
+			"requested-by": approval.RequestedBy,
+		}).Info("Forced action awaiting the confirmation of a second token")
+	}
+
+	outputErrorWithDetails(c, w, http.StatusPreconditionRequired, model.ErrorCodeForceApprovalRequired,
+		fmt.Sprintf("forced %s of rings %s must be confirmed by a token of another account sending the same request before it takes effect", action, target),
+		map[string]string{
+			"approval":    approval.ID,
+			"requestedBy": approval.RequestedBy,
+			"expireAt":    strconv.FormatInt(approval.ExpireAt, 10),
+		})
+	return false
+}
+
+// isForceApprovalRequester returns whether the pending approval was requested
+// by the account of the request's token, through the same token or another
+// one. Tokens are issued to service accounts, so the tokens of the same name
+// and tenant act for the same account and cannot confirm each other's
+// requests.
+func isForceApprovalRequester(c *Context, approval *model.ForceApproval) (bool, error) {
+	if approval.RequestedBy == c.TokenID {
+		return true, nil
+	}
+
+	requester, err := c.Store.GetToken(approval.RequestedBy)
+	if err != nil {
+		return false, err
+	}
+	if requester == nil {
+		return false, nil
+	}
+
+	return requester.Name == c.TokenName && requester.TenantID == c.TenantID, nil
+}
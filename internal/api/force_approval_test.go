@@ -0,0 +1,172 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForceApprovals(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	newTokenClient := func(name, role string) (*model.Client, string) {
+		secret, err := model.NewTokenSecret()
+		require.NoError(t, err)
+		token := &model.Token{Name: name, Role: role, TokenHash: model.HashTokenSecret(secret)}
+		require.NoError(t, sqlStore.CreateToken(token))
+		return model.NewClientWithToken(ts.URL, secret), token.ID
+	}
+	client1, token1 := newTokenClient("alice", model.TokenRoleWrite)
+	client2, token2 := newTokenClient("bob", model.TokenRoleWrite)
+	adminClient, _ := newTokenClient("admin", model.TokenRoleAdmin)
+
+	ring, err := client1.CreateRing(&model.CreateRingRequest{
+		Priority:            1,
+		ForceApprovalWindow: 600,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 600, ring.ForceApprovalWindow)
+	ring.State = model.RingStateStable
+	require.NoError(t, sqlStore.UpdateRing(ring))
+
+	forcedRelease := &model.RingReleaseRequest{
+		Image:   "mattermost/mattermost-enterprise-edition",
+		Version: "7.0.1",
+		Force:   true,
+	}
+
+	t.Run("forced release requires an API token", func(t *testing.T) {
+		_, err := model.NewClient(ts.URL).ReleaseRing(ring.ID, forcedRelease)
+		requireAPIError(t, err, 403)
+	})
+
+	t.Run("forced release is confirmed by a second token", func(t *testing.T) {
+		_, err := client1.ReleaseRing(ring.ID, forcedRelease)
+		apiErr := requireAPIError(t, err, 428)
+		require.Equal(t, model.ErrorCodeForceApprovalRequired, apiErr.Code)
+		approvalID := apiErr.Details["approval"]
+		require.NotEmpty(t, approvalID)
+		require.Equal(t, token1, apiErr.Details["requestedBy"])
+
+		// The requester cannot confirm their own request.
+		_, err = client1.ReleaseRing(ring.ID, forcedRelease)
+		apiErr = requireAPIError(t, err, 428)
+		require.Equal(t, approvalID, apiErr.Details["approval"])
+
+		// A different request is not a confirmation.
+		_, err = client2.ReleaseRing(ring.ID, &model.RingReleaseRequest{
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Version: "7.0.2",
+			Force:   true,
+		})
+		apiErr = requireAPIError(t, err, 428)
+		require.NotEqual(t, approvalID, apiErr.Details["approval"])
+		require.Equal(t, token2, apiErr.Details["requestedBy"])
+
+		_, err = client1.ReleaseRing(ring.ID, &model.RingReleaseRequest{
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Version: "7.0.2",
+			Force:   true,
+		})
+		require.NoError(t, err)
+
+		ring, err = client1.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePending, ring.State)
+
+		approvals, err := client1.GetForceApprovals(&model.GetForceApprovalsRequest{RingID: ring.ID, PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, approvals, 2)
+		require.Equal(t, model.ForceApprovalActionRelease, approvals[0].Action)
+		require.Equal(t, token2, approvals[0].RequestedBy)
+		require.Equal(t, token1, approvals[0].ConfirmedBy)
+		require.False(t, approvals[1].IsConfirmed())
+	})
+
+	t.Run("releases that are not forced need no approval", func(t *testing.T) {
+		ring.State = model.RingStateStable
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		_, err := client1.ReleaseRing(ring.ID, &model.RingReleaseRequest{
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Version: "7.0.3",
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("API unlock is confirmed by a second token", func(t *testing.T) {
+		require.NoError(t, client1.LockAPIForRing(ring.ID))
+
+		err := client1.UnlockAPIForRing(ring.ID)
+		apiErr := requireAPIError(t, err, 428)
+		require.Equal(t, model.ErrorCodeForceApprovalRequired, apiErr.Code)
+
+		ring, err = client1.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.True(t, ring.APISecurityLock)
+
+		require.NoError(t, client2.UnlockAPIForRing(ring.ID))
+
+		ring, err = client1.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.False(t, ring.APISecurityLock)
+	})
+
+	t.Run("tokens of the same account cannot confirm each other", func(t *testing.T) {
+		otherClient1, _ := newTokenClient("alice", model.TokenRoleWrite)
+		require.NoError(t, client1.LockAPIForRing(ring.ID))
+
+		err := client1.UnlockAPIForRing(ring.ID)
+		apiErr := requireAPIError(t, err, 428)
+		approvalID := apiErr.Details["approval"]
+
+		err = otherClient1.UnlockAPIForRing(ring.ID)
+		apiErr = requireAPIError(t, err, 428)
+		require.Equal(t, approvalID, apiErr.Details["approval"])
+
+		ring, err = client1.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.True(t, ring.APISecurityLock)
+
+		require.NoError(t, client2.UnlockAPIForRing(ring.ID))
+	})
+
+	t.Run("shortening the window requires the admin role", func(t *testing.T) {
+		window := 0
+		_, err := model.NewClient(ts.URL).UpdateRing(ring.ID, &model.UpdateRingRequest{ForceApprovalWindow: &window})
+		requireAPIError(t, err, 401)
+
+		_, err = client1.UpdateRing(ring.ID, &model.UpdateRingRequest{ForceApprovalWindow: &window})
+		requireAPIError(t, err, 403)
+
+		ring, err = adminClient.UpdateRing(ring.ID, &model.UpdateRingRequest{ForceApprovalWindow: &window})
+		require.NoError(t, err)
+		require.Zero(t, ring.ForceApprovalWindow)
+
+		window = 60
+		ring, err = client1.UpdateRing(ring.ID, &model.UpdateRingRequest{ForceApprovalWindow: &window})
+		require.NoError(t, err)
+		require.Equal(t, 60, ring.ForceApprovalWindow)
+	})
+}
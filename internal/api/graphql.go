@@ -0,0 +1,435 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// graphQLMaxDepth bounds the nesting of GraphQL queries, which would otherwise
+// allow a single query to walk the ring, installation group and event
+// relationships indefinitely.
+const graphQLMaxDepth = 8
+
+// graphQLMaxPerPage is the largest page of rings or events a GraphQL query
+// can request at once, as for the events stream.
+const graphQLMaxPerPage = maxEventsPerPage
+
+// graphQLSchema is the read-only GraphQL schema of rings, installation groups,
+// releases and state change events. Timestamps are in the same units as in
+// the REST API.
+const graphQLSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	rings(page: Int = 0, perPage: Int = 100, includeDeleted: Boolean = false): [Ring!]!
+	ring(id: ID!): Ring
+	installationGroup(id: ID!): InstallationGroup
+	release(id: ID!): Release
+	events(ringID: ID, resourceType: String, resourceID: ID, page: Int = 0, perPage: Int = 100): [Event!]!
+}
+
+type Ring {
+	id: ID!
+	name: String!
+	state: String!
+	priority: Int!
+	soakTime: Int!
+	provisioner: String!
+	createAt: Float!
+	deleteAt: Float!
+	releaseAt: Float!
+	installationGroups: [InstallationGroup!]!
+	activeRelease: Release
+	desiredRelease: Release
+	events(page: Int = 0, perPage: Int = 100): [Event!]!
+}
+
+type InstallationGroup {
+	id: ID!
+	name: String!
+	state: String!
+	provisionerGroupID: String!
+	soakTime: Int!
+	releaseAt: Float!
+	releaseProgress: Int!
+	ring: Ring
+	events(page: Int = 0, perPage: Int = 100): [Event!]!
+}
+
+type Release {
+	id: ID!
+	image: String!
+	version: String!
+	type: String!
+	force: Boolean!
+	soakTime: Int!
+	createAt: Float!
+	links: [ReleaseLink!]!
+	labels: [String!]!
+}
+
+type ReleaseLink {
+	type: String!
+	url: String!
+	title: String!
+}
+
+type Event {
+	id: ID!
+	resourceType: String!
+	resourceID: ID!
+	ringID: ID!
+	releaseID: ID!
+	oldState: String!
+	newState: String!
+	timestamp: Float!
+	instanceID: String!
+	error: String!
+	ring: Ring
+	release: Release
+}
+`
+
+// graphQLRequest is the body of a GraphQL query.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// initGraphQL registers the GraphQL endpoint on the given router.
+func initGraphQL(apiRouter *mux.Router, context *Context) {
+	schema := graphql.MustParseSchema(graphQLSchema,
+		&graphQLResolver{store: context.Store, logger: context.Logger},
+		graphql.MaxDepth(graphQLMaxDepth),
+	)
+
+	handler := newReadOnlyContextHandler(context, func(c *Context, w http.ResponseWriter, r *http.Request) {
+		handleGraphQL(c, w, r, schema)
+	})
+	apiRouter.Handle("/graphql", handler).Methods("GET", "POST")
+}
+
+// handleGraphQL responds to GET and POST /api/graphql, executing a read-only
+// GraphQL query. The query is given in the query string of GET requests and
+// in the JSON body of POST requests.
+func handleGraphQL(c *Context, w http.ResponseWriter, r *http.Request, schema *graphql.Schema) {
+	var request graphQLRequest
+	if r.Method == http.MethodGet {
+		request.Query = r.URL.Query().Get("query")
+		request.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode variables: %s", err))
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode request: %s", err))
+		return
+	}
+	if request.Query == "" {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "must specify a query")
+		return
+	}
+
+	response := schema.Exec(r.Context(), request.Query, request.OperationName, request.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, response)
+}
+
+// graphQLResolver resolves the root queries of the GraphQL schema.
+type graphQLResolver struct {
+	store  Store
+	logger logrus.FieldLogger
+}
+
+// internalError logs the given error and returns the error reported to the
+// client, which does not leak the details of the failure.
+func (r *graphQLResolver) internalError(err error, message string) error {
+	r.logger.WithError(err).Error(message)
+	return errors.New(message)
+}
+
+// validateGraphQLPaging returns an error if the given page is negative or the
+// given page size is not between 1 and graphQLMaxPerPage.
+func validateGraphQLPaging(page, perPage int32) error {
+	if page < 0 || perPage < 1 || perPage > graphQLMaxPerPage {
+		return errors.Errorf("page must not be negative and perPage must be between 1 and %d", graphQLMaxPerPage)
+	}
+
+	return nil
+}
+
+func (r *graphQLResolver) Rings(args struct {
+	Page           int32
+	PerPage        int32
+	IncludeDeleted bool
+}) ([]*ringResolver, error) {
+	if err := validateGraphQLPaging(args.Page, args.PerPage); err != nil {
+		return nil, err
+	}
+
+	rings, err := r.store.GetRings(&model.RingFilter{
+		Page:           int(args.Page),
+		PerPage:        int(args.PerPage),
+		IncludeDeleted: args.IncludeDeleted,
+	})
+	if err != nil {
+		return nil, r.internalError(err, "failed to query rings")
+	}
+
+	resolvers := make([]*ringResolver, 0, len(rings))
+	for _, ring := range rings {
+		resolvers = append(resolvers, &ringResolver{root: r, ring: ring})
+	}
+
+	return resolvers, nil
+}
+
+func (r *graphQLResolver) Ring(args struct{ ID graphql.ID }) (*ringResolver, error) {
+	return r.ring(string(args.ID))
+}
+
+func (r *graphQLResolver) ring(ringID string) (*ringResolver, error) {
+	ring, err := r.store.GetRing(ringID)
+	if err != nil {
+		return nil, r.internalError(err, "failed to query ring")
+	}
+	if ring == nil {
+		return nil, nil
+	}
+
+	return &ringResolver{root: r, ring: ring}, nil
+}
+
+func (r *graphQLResolver) InstallationGroup(args struct{ ID graphql.ID }) (*installationGroupResolver, error) {
+	installationGroup, err := r.store.GetInstallationGroupByID(string(args.ID))
+	if err != nil {
+		return nil, r.internalError(err, "failed to query installation group")
+	}
+	if installationGroup == nil {
+		return nil, nil
+	}
+
+	return &installationGroupResolver{root: r, installationGroup: installationGroup}, nil
+}
+
+func (r *graphQLResolver) Release(args struct{ ID graphql.ID }) (*releaseResolver, error) {
+	return r.release(string(args.ID))
+}
+
+func (r *graphQLResolver) release(releaseID string) (*releaseResolver, error) {
+	if releaseID == "" {
+		return nil, nil
+	}
+
+	release, err := r.store.GetRingRelease(releaseID)
+	if err != nil {
+		return nil, r.internalError(err, "failed to query release")
+	}
+	if release == nil {
+		return nil, nil
+	}
+
+	return &releaseResolver{release: release}, nil
+}
+
+func (r *graphQLResolver) Events(args struct {
+	RingID       *graphql.ID
+	ResourceType *string
+	ResourceID   *graphql.ID
+	Page         int32
+	PerPage      int32
+}) ([]*eventResolver, error) {
+	filter := &model.StateChangeEventFilter{}
+	if args.RingID != nil {
+		filter.RingID = string(*args.RingID)
+	}
+	if args.ResourceType != nil {
+		filter.ResourceType = *args.ResourceType
+	}
+	if args.ResourceID != nil {
+		filter.ResourceID = string(*args.ResourceID)
+	}
+
+	return r.events(filter, args.Page, args.PerPage)
+}
+
+func (r *graphQLResolver) events(filter *model.StateChangeEventFilter, page, perPage int32) ([]*eventResolver, error) {
+	if err := validateGraphQLPaging(page, perPage); err != nil {
+		return nil, err
+	}
+	filter.Page = int(page)
+	filter.PerPage = int(perPage)
+
+	events, err := r.store.GetStateChangeEvents(filter)
+	if err != nil {
+		return nil, r.internalError(err, "failed to query events")
+	}
+
+	resolvers := make([]*eventResolver, 0, len(events))
+	for _, event := range events {
+		resolvers = append(resolvers, &eventResolver{root: r, event: event})
+	}
+
+	return resolvers, nil
+}
+
+// graphQLPage holds the paging arguments of nested event lists.
+type graphQLPage struct {
+	Page    int32
+	PerPage int32
+}
+
+type ringResolver struct {
+	root *graphQLResolver
+	ring *model.Ring
+}
+
+func (r *ringResolver) ID() graphql.ID      { return graphql.ID(r.ring.ID) }
+func (r *ringResolver) Name() string        { return r.ring.Name }
+func (r *ringResolver) State() string       { return r.ring.State }
+func (r *ringResolver) Priority() int32     { return int32(r.ring.Priority) }
+func (r *ringResolver) SoakTime() int32     { return int32(r.ring.SoakTime) }
+func (r *ringResolver) Provisioner() string { return r.ring.Provisioner }
+func (r *ringResolver) CreateAt() float64   { return float64(r.ring.CreateAt) }
+func (r *ringResolver) DeleteAt() float64   { return float64(r.ring.DeleteAt) }
+func (r *ringResolver) ReleaseAt() float64  { return float64(r.ring.ReleaseAt) }
+
+func (r *ringResolver) InstallationGroups() ([]*installationGroupResolver, error) {
+	installationGroups, err := r.root.store.GetInstallationGroupsForRing(r.ring.ID)
+	if err != nil {
+		return nil, r.root.internalError(err, "failed to query installation groups for ring")
+	}
+
+	resolvers := make([]*installationGroupResolver, 0, len(installationGroups))
+	for _, installationGroup := range installationGroups {
+		resolvers = append(resolvers, &installationGroupResolver{root: r.root, installationGroup: installationGroup})
+	}
+
+	return resolvers, nil
+}
+
+func (r *ringResolver) ActiveRelease() (*releaseResolver, error) {
+	return r.root.release(r.ring.ActiveReleaseID)
+}
+
+func (r *ringResolver) DesiredRelease() (*releaseResolver, error) {
+	return r.root.release(r.ring.DesiredReleaseID)
+}
+
+func (r *ringResolver) Events(args graphQLPage) ([]*eventResolver, error) {
+	return r.root.events(&model.StateChangeEventFilter{RingID: r.ring.ID}, args.Page, args.PerPage)
+}
+
+type installationGroupResolver struct {
+	root              *graphQLResolver
+	installationGroup *model.InstallationGroup
+}
+
+func (r *installationGroupResolver) ID() graphql.ID { return graphql.ID(r.installationGroup.ID) }
+func (r *installationGroupResolver) Name() string   { return r.installationGroup.Name }
+func (r *installationGroupResolver) State() string  { return r.installationGroup.State }
+func (r *installationGroupResolver) ProvisionerGroupID() string {
+	return r.installationGroup.ProvisionerGroupID
+}
+func (r *installationGroupResolver) SoakTime() int32 { return int32(r.installationGroup.SoakTime) }
+func (r *installationGroupResolver) ReleaseAt() float64 {
+	return float64(r.installationGroup.ReleaseAt)
+}
+func (r *installationGroupResolver) ReleaseProgress() int32 {
+	return int32(r.installationGroup.ReleaseProgress)
+}
+
+func (r *installationGroupResolver) Ring() (*ringResolver, error) {
+	ring, err := r.root.store.GetRingFromInstallationGroupID(r.installationGroup.ID)
+	if err != nil {
+		return nil, r.root.internalError(err, "failed to query ring of installation group")
+	}
+	if ring == nil {
+		return nil, nil
+	}
+
+	return &ringResolver{root: r.root, ring: ring}, nil
+}
+
+func (r *installationGroupResolver) Events(args graphQLPage) ([]*eventResolver, error) {
+	return r.root.events(&model.StateChangeEventFilter{
+		ResourceType: model.TypeInstallationGroup,
+		ResourceID:   r.installationGroup.ID,
+	}, args.Page, args.PerPage)
+}
+
+type releaseResolver struct {
+	release *model.RingRelease
+}
+
+func (r *releaseResolver) ID() graphql.ID    { return graphql.ID(r.release.ID) }
+func (r *releaseResolver) Image() string     { return r.release.Image }
+func (r *releaseResolver) Version() string   { return r.release.Version }
+func (r *releaseResolver) Type() string      { return r.release.Type }
+func (r *releaseResolver) Force() bool       { return r.release.Force }
+func (r *releaseResolver) SoakTime() int32   { return int32(r.release.SoakTime) }
+func (r *releaseResolver) CreateAt() float64 { return float64(r.release.CreateAt) }
+
+func (r *releaseResolver) Links() []*releaseLinkResolver {
+	links := make([]*releaseLinkResolver, 0, len(r.release.Links))
+	for _, link := range r.release.Links {
+		links = append(links, &releaseLinkResolver{link: link})
+	}
+
+	return links
+}
+
+func (r *releaseResolver) Labels() []string {
+	return append([]string{}, r.release.Labels...)
+}
+
+type releaseLinkResolver struct {
+	link *model.ReleaseLink
+}
+
+func (r *releaseLinkResolver) Type() string  { return r.link.Type }
+func (r *releaseLinkResolver) URL() string   { return r.link.URL }
+func (r *releaseLinkResolver) Title() string { return r.link.Title }
+
+type eventResolver struct {
+	root  *graphQLResolver
+	event *model.StateChangeEvent
+}
+
+func (r *eventResolver) ID() graphql.ID         { return graphql.ID(r.event.ID) }
+func (r *eventResolver) ResourceType() string   { return r.event.ResourceType }
+func (r *eventResolver) ResourceID() graphql.ID { return graphql.ID(r.event.ResourceID) }
+func (r *eventResolver) RingID() graphql.ID     { return graphql.ID(r.event.RingID) }
+func (r *eventResolver) ReleaseID() graphql.ID  { return graphql.ID(r.event.ReleaseID) }
+func (r *eventResolver) OldState() string       { return r.event.OldState }
+func (r *eventResolver) NewState() string       { return r.event.NewState }
+func (r *eventResolver) Timestamp() float64     { return float64(r.event.Timestamp) }
+func (r *eventResolver) InstanceID() string     { return r.event.InstanceID }
+func (r *eventResolver) Error() string          { return r.event.Error }
+
+func (r *eventResolver) Ring() (*ringResolver, error) {
+	return r.root.ring(r.event.RingID)
+}
+
+func (r *eventResolver) Release() (*releaseResolver, error) {
+	return r.root.release(r.event.ReleaseID)
+}
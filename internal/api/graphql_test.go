@@ -0,0 +1,134 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQL(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.0.0", Type: model.ReleaseTypeStandard})
+	require.NoError(t, err)
+
+	ring := &model.Ring{Name: "graphql-ring", Priority: 1, State: model.RingStateStable, ActiveReleaseID: release.ID, DesiredReleaseID: release.ID}
+	require.NoError(t, sqlStore.CreateRing(ring, &model.InstallationGroup{Name: "graphql-ig", ProvisionerGroupID: "group1"}))
+	installationGroups, err := sqlStore.GetInstallationGroupsForRing(ring.ID)
+	require.NoError(t, err)
+	require.Len(t, installationGroups, 1)
+
+	event := model.NewStateChangeEvent(model.TypeInstallationGroup, installationGroups[0].ID, ring, model.InstallationGroupReleaseRequested, model.InstallationGroupStable)
+	require.NoError(t, sqlStore.CreateStateChangeEvent(event))
+
+	type graphQLResponse struct {
+		Data   json.RawMessage
+		Errors []struct{ Message string }
+	}
+
+	post := func(t *testing.T, query string, variables map[string]interface{}) *graphQLResponse {
+		body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+		require.NoError(t, err)
+
+		resp, err := http.Post(ts.URL+"/api/v1/graphql", "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response graphQLResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+
+		return &response
+	}
+
+	t.Run("nested ring query", func(t *testing.T) {
+		response := post(t, `query($id: ID!) {
+			ring(id: $id) {
+				name
+				activeRelease { version }
+				installationGroups { name provisionerGroupID events { newState } }
+			}
+		}`, map[string]interface{}{"id": ring.ID})
+		require.Empty(t, response.Errors)
+		require.JSONEq(t, `{"ring": {
+			"name": "graphql-ring",
+			"activeRelease": {"version": "7.0.0"},
+			"installationGroups": [{"name": "graphql-ig", "provisionerGroupID": "group1", "events": [{"newState": "stable"}]}]
+		}}`, string(response.Data))
+	})
+
+	t.Run("events with their ring", func(t *testing.T) {
+		response := post(t, `{ events(ringID: "`+ring.ID+`") { resourceType ring { name } release { image } } }`, nil)
+		require.Empty(t, response.Errors)
+		require.JSONEq(t, `{"events": [{
+			"resourceType": "installationgroup",
+			"ring": {"name": "graphql-ring"},
+			"release": {"image": "mattermost/mattermost-enterprise-edition"}
+		}]}`, string(response.Data))
+	})
+
+	t.Run("unknown ring", func(t *testing.T) {
+		response := post(t, `{ ring(id: "unknown") { name } }`, nil)
+		require.Empty(t, response.Errors)
+		require.JSONEq(t, `{"ring": null}`, string(response.Data))
+	})
+
+	t.Run("invalid paging", func(t *testing.T) {
+		response := post(t, `{ rings(perPage: 0) { name } }`, nil)
+		require.Len(t, response.Errors, 1)
+
+		response = post(t, `{ rings(perPage: 1001) { name } }`, nil)
+		require.Len(t, response.Errors, 1)
+
+		response = post(t, `{ events(perPage: 1001) { id } }`, nil)
+		require.Len(t, response.Errors, 1)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		response := post(t, `{ rings { unknownField } }`, nil)
+		require.NotEmpty(t, response.Errors)
+	})
+
+	t.Run("get query", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/v1/graphql?query=" + url.QueryEscape(`{ rings { name } }`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response graphQLResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.JSONEq(t, `{"rings": [{"name": "graphql-ring"}]}`, string(response.Data))
+	})
+
+	t.Run("missing query", func(t *testing.T) {
+		resp, err := http.Post(ts.URL+"/api/v1/graphql", "application/json", bytes.NewReader([]byte(`{}`)))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -6,7 +6,10 @@ package api
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/gorilla/mux"
 	"github.com/mattermost/elrond/model"
 	log "github.com/sirupsen/logrus"
 )
@@ -16,6 +19,12 @@ type contextHandlerFunc func(c *Context, w http.ResponseWriter, r *http.Request)
 type contextHandler struct {
 	context *Context
 	handler contextHandlerFunc
+	// readOnly marks handlers that make no changes whatever the request
+	// method, allowing tokens with the read role to use them.
+	readOnly bool
+	// cached marks hot read handlers whose responses are served from the
+	// read cache, when enabled.
+	cached bool
 }
 
 func (h contextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -26,7 +35,136 @@ func (h contextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		"request": context.RequestID,
 	})
 
+	if !negotiateAPIVersion(context, w, r) {
+		return
+	}
+	if !authenticate(context, w, r, h.readOnly) {
+		return
+	}
+	if context.TenantID != "" {
+		context.Logger = context.Logger.WithField("tenant", context.TenantID)
+	}
+	if r.Method != http.MethodGet && !h.readOnly && !authorizePolicy(context, w, r) {
+		return
+	}
+
+	if h.cached && context.ReadCache != nil {
+		context.ReadCache.serve(context, w, r, h.handler)
+		return
+	}
+
 	h.handler(context, w, r)
+
+	if r.Method != http.MethodGet && !h.readOnly && context.ReadCache != nil {
+		context.ReadCache.Invalidate()
+	}
+}
+
+// negotiateAPIVersion selects the version of the API responses requested by
+// the client, echoing it in the response along with the capabilities of the
+// server. It writes an error response and returns false if the version is not
+// supported.
+func negotiateAPIVersion(c *Context, w http.ResponseWriter, r *http.Request) bool {
+	version, err := model.ParseAPIVersion(r.Header.Get(model.HeaderAPIVersion))
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return false
+	}
+
+	c.APIVersion = version
+	w.Header().Set(model.HeaderAPIVersion, strconv.Itoa(version))
+	w.Header().Set(model.HeaderCapabilities, strings.Join(serverCapabilities, ","))
+
+	return true
+}
+
+// authenticate resolves the API token of the request, if any, scoping the
+// context to the tenant and role of the token. It writes an error response
+// and returns false if the request must not be handled.
+func authenticate(c *Context, w http.ResponseWriter, r *http.Request, readOnly bool) bool {
+	secret := model.TokenSecretFromAuthorization(r.Header.Get("Authorization"))
+	if secret == "" {
+		if c.RequireToken {
+			outputError(c, w, http.StatusUnauthorized, model.ErrorCodeUnauthorized, "an API token is required")
+			return false
+		}
+		return true
+	}
+
+	token, err := c.Store.GetTokenByHash(model.HashTokenSecret(secret))
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query token")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query token")
+		return false
+	}
+	if token == nil || token.IsDeleted() || token.IsExpired(model.GetMillis()) {
+		outputError(c, w, http.StatusUnauthorized, model.ErrorCodeUnauthorized, "invalid or expired API token")
+		return false
+	}
+
+	c.TenantID = token.TenantID
+	c.TokenID = token.ID
+	c.TokenName = token.Name
+	c.TokenRole = token.Role
+	c.Logger = c.Logger.WithField("token", token.ID)
+
+	if token.Role == model.TokenRoleRead && r.Method != http.MethodGet && !readOnly {
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeForbidden, "token role does not allow changes")
+		return false
+	}
+
+	if token.IsRingScoped() {
+		c.RingScopedToken = token
+		return authorizeRingScope(c, w, r, readOnly)
+	}
+
+	return true
+}
+
+// authorizeRingScope checks that a request authenticated with a ring-scoped
+// token only changes the rings in the scope of the token, through their
+// ring or installation group routes. Ring-scoped tokens can make any request
+// making no changes. It writes an error response and returns false if the
+// request must not be handled.
+func authorizeRingScope(c *Context, w http.ResponseWriter, r *http.Request, readOnly bool) bool {
+	if r.Method == http.MethodGet || readOnly {
+		return true
+	}
+
+	vars := mux.Vars(r)
+	var ring *model.Ring
+	var err error
+	switch {
+	case vars["ring"] != "":
+		ring, err = c.Store.GetRing(vars["ring"])
+	case vars["installationgroup"] != "":
+		ring, err = c.Store.GetRingFromInstallationGroupID(vars["installationgroup"])
+	default:
+		c.Logger.Warn("ring-scoped token used outside of a ring")
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeForbidden, "token is restricted to changing some rings")
+		return false
+	}
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+		return false
+	}
+	// Targets resolving to no ring, whether missing or installation groups
+	// registered to no ring, are out of the scope of any token: only
+	// unscoped tokens get them reported as not found by the handler.
+	if ring == nil {
+		c.Logger.Warn("ring-scoped token used on a target registered to no ring")
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeForbidden, "token is not allowed to change this ring")
+		return false
+	}
+
+	if !c.RingScopedToken.AllowsRing(ring) {
+		c.Logger.WithField("ring", ring.ID).Warn("ring is out of the scope of the token")
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeForbidden, "token is not allowed to change this ring")
+		return false
+	}
+
+	return true
 }
 
 func newContextHandler(context *Context, handler contextHandlerFunc) *contextHandler {
@@ -35,3 +173,19 @@ func newContextHandler(context *Context, handler contextHandlerFunc) *contextHan
 		handler: handler,
 	}
 }
+
+func newCachedContextHandler(context *Context, handler contextHandlerFunc) *contextHandler {
+	return &contextHandler{
+		context: context,
+		handler: handler,
+		cached:  true,
+	}
+}
+
+func newReadOnlyContextHandler(context *Context, handler contextHandlerFunc) *contextHandler {
+	return &contextHandler{
+		context:  context,
+		handler:  handler,
+		readOnly: true,
+	}
+}
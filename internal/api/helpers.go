@@ -30,6 +30,20 @@ func parseInt(u *url.URL, name string, defaultValue int) (int, error) {
 	return value, nil
 }
 
+func parseInt64(u *url.URL, name string, defaultValue int64) (int64, error) {
+	valueStr := u.Query().Get(name)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.ParseInt(valueStr, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse %s as integer", name)
+	}
+
+	return value, nil
+}
+
 func parseBool(u *url.URL, name string, defaultValue bool) (bool, error) {
 	valueStr := u.Query().Get(name)
 	if valueStr == "" {
@@ -0,0 +1,109 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+)
+
+// idempotencyKeyReservationTimeout is how long an idempotency key stays
+// reserved by a create request that neither created its resource nor released
+// the key, e.g. because the server stopped while handling it.
+const idempotencyKeyReservationTimeout = time.Minute
+
+// reserveIdempotencyKey reserves the idempotency key of a create request, if
+// any, before the resource is created, so that concurrent requests with the
+// same key do not both create one. It returns the reservation, or the ID of
+// the resource already created with the key. It writes an error response and
+// returns false if the request must not be handled, including while another
+// request holds the key.
+func reserveIdempotencyKey(c *Context, w http.ResponseWriter, r *http.Request, resourceType string) (*model.IdempotencyKey, string, bool) {
+	key := r.Header.Get(model.HeaderIdempotencyKey)
+	if key == "" {
+		return nil, "", true
+	}
+	if err := model.ValidateIdempotencyKey(key); err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return nil, "", false
+	}
+
+	// A stale reservation is released and the key reserved again once.
+	for attempt := 0; attempt < 2; attempt++ {
+		reservation := &model.IdempotencyKey{ID: key, ResourceType: resourceType}
+		reserved, err := c.Store.ReserveIdempotencyKey(reservation)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to reserve idempotency key")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to reserve idempotency key")
+			return nil, "", false
+		}
+		if reserved {
+			return reservation, "", true
+		}
+
+		idempotencyKey, err := c.Store.GetIdempotencyKey(key)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query idempotency key")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query idempotency key")
+			return nil, "", false
+		}
+		if idempotencyKey == nil {
+			// Released in the meantime.
+			continue
+		}
+		if idempotencyKey.ResourceType != resourceType {
+			outputError(c, w, http.StatusConflict, model.ErrorCodeConflict, fmt.Sprintf("idempotency key already used to create a %s", idempotencyKey.ResourceType))
+			return nil, "", false
+		}
+		if idempotencyKey.ResourceID != "" {
+			c.Logger.Debugf("Idempotency key already used to create %s %s", resourceType, idempotencyKey.ResourceID)
+			return nil, idempotencyKey.ResourceID, true
+		}
+		if time.Since(time.Unix(0, idempotencyKey.CreateAt*int64(time.Millisecond))) < idempotencyKeyReservationTimeout {
+			break
+		}
+
+		c.Logger.Warnf("Releasing stale reservation of idempotency key for %s", resourceType)
+		if err = c.Store.ReleaseIdempotencyKey(idempotencyKey); err != nil {
+			c.Logger.WithError(err).Error("failed to release idempotency key")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to release idempotency key")
+			return nil, "", false
+		}
+	}
+
+	outputError(c, w, http.StatusConflict, model.ErrorCodeConflict, "a request with this idempotency key is in progress, retry later")
+	return nil, "", false
+}
+
+// completeIdempotencyKey records the resource created with the reserved
+// idempotency key of the request, if any. The resource is already created, so
+// failures are only logged.
+func completeIdempotencyKey(c *Context, reservation *model.IdempotencyKey, resourceID string) {
+	if reservation == nil {
+		return
+	}
+
+	if err := c.Store.CompleteIdempotencyKey(reservation.ID, resourceID); err != nil {
+		c.Logger.WithError(err).Warn("failed to record idempotency key")
+		return
+	}
+	reservation.ResourceID = resourceID
+}
+
+// releaseIdempotencyKey releases the reserved idempotency key of a request
+// that did not create its resource, so that the request can be retried. It is
+// deferred by the create handlers right after the reservation.
+func releaseIdempotencyKey(c *Context, reservation *model.IdempotencyKey) {
+	if reservation == nil || reservation.ResourceID != "" {
+		return
+	}
+
+	if err := c.Store.ReleaseIdempotencyKey(reservation); err != nil {
+		c.Logger.WithError(err).Warn("failed to release idempotency key")
+	}
+}
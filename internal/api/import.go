@@ -0,0 +1,79 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// initImport registers the endpoints looking resources up by name, used to
+// import existing resources into external tools managing them by ID.
+func initImport(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	importRouter := apiRouter.PathPrefix("/import").Subrouter()
+	importRouter.Handle("/ring/{name}", addContext(handleImportRing)).Methods("GET")
+	importRouter.Handle("/installationgroup/{name}", addContext(handleImportInstallationGroup)).Methods("GET")
+}
+
+// handleImportRing responds to GET /api/import/ring/{name}, returning the
+// ring with the given name.
+func handleImportRing(c *Context, w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	rings, err := c.Store.GetRings(&model.RingFilter{PerPage: model.AllPerPage, Name: name})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query rings")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings")
+		return
+	}
+	if len(rings) == 0 {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring not found")
+		return
+	}
+	if len(rings) > 1 {
+		outputError(c, w, http.StatusConflict, model.ErrorCodeConflict, fmt.Sprintf("multiple rings are named %s; import the ring by ID", name))
+		return
+	}
+	ring := rings[0]
+
+	ring.InstallationGroups, err = c.Store.GetInstallationGroupsForRing(ring.ID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get installation groups for ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get installation groups for ring")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, ring)
+}
+
+// handleImportInstallationGroup responds to GET /api/import/installationgroup/{name},
+// returning the installation group with the given name.
+func handleImportInstallationGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	installationGroup, err := c.Store.GetInstallationGroupByName(name)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation group")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query installation group")
+		return
+	}
+	if installationGroup == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "installation group not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, installationGroup)
+}
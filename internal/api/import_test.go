@@ -0,0 +1,177 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{
+		Name:              "import-ring",
+		Priority:          1,
+		InstallationGroup: &model.InstallationGroup{Name: "import-ig", ProvisionerGroupID: "group1"},
+	})
+	require.NoError(t, err)
+
+	t.Run("ring", func(t *testing.T) {
+		imported, err := client.ImportRing("import-ring")
+		require.NoError(t, err)
+		require.NotNil(t, imported)
+		require.Equal(t, ring.ID, imported.ID)
+		require.Len(t, imported.InstallationGroups, 1)
+	})
+
+	t.Run("unknown ring", func(t *testing.T) {
+		imported, err := client.ImportRing("unknown")
+		require.NoError(t, err)
+		require.Nil(t, imported)
+	})
+
+	t.Run("ambiguous ring name", func(t *testing.T) {
+		_, err := client.CreateRing(&model.CreateRingRequest{Name: "import-duplicate", Priority: 2})
+		require.NoError(t, err)
+		_, err = client.CreateRing(&model.CreateRingRequest{Name: "import-duplicate", Priority: 3})
+		require.NoError(t, err)
+
+		_, err = client.ImportRing("import-duplicate")
+		requireAPIError(t, err, 409)
+	})
+
+	t.Run("installation group", func(t *testing.T) {
+		imported, err := client.ImportInstallationGroup("import-ig")
+		require.NoError(t, err)
+		require.NotNil(t, imported)
+		require.Equal(t, ring.InstallationGroups[0].ID, imported.ID)
+	})
+
+	t.Run("unknown installation group", func(t *testing.T) {
+		imported, err := client.ImportInstallationGroup("unknown")
+		require.NoError(t, err)
+		require.Nil(t, imported)
+	})
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("ring", func(t *testing.T) {
+		keyClient := client.WithIdempotencyKey("ring-key")
+		ring1, err := keyClient.CreateRing(&model.CreateRingRequest{Name: "idempotent-ring", Priority: 1})
+		require.NoError(t, err)
+		ring2, err := keyClient.CreateRing(&model.CreateRingRequest{Name: "idempotent-ring", Priority: 1})
+		require.NoError(t, err)
+		require.Equal(t, ring1.ID, ring2.ID)
+
+		rings, err := client.GetRings(&model.GetRingsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, rings, 1)
+	})
+
+	t.Run("installation group", func(t *testing.T) {
+		ring, err := client.CreateRing(&model.CreateRingRequest{Name: "idempotent-ig-ring", Priority: 2})
+		require.NoError(t, err)
+
+		keyClient := client.WithIdempotencyKey("ig-key")
+		request := &model.RegisterInstallationGroupRequest{Name: "idempotent-ig", ProvisionerGroupID: "group1"}
+		_, err = keyClient.RegisterRingInstallationGroup(ring.ID, request)
+		require.NoError(t, err)
+		ring, err = keyClient.RegisterRingInstallationGroup(ring.ID, request)
+		require.NoError(t, err)
+		require.Len(t, ring.InstallationGroups, 1)
+	})
+
+	t.Run("webhook", func(t *testing.T) {
+		keyClient := client.WithIdempotencyKey("webhook-key")
+		request := &model.CreateWebhookRequest{OwnerID: "owner", URL: "https://example.com/hook"}
+		webhook1, err := keyClient.CreateWebhook(request)
+		require.NoError(t, err)
+		webhook2, err := keyClient.CreateWebhook(request)
+		require.NoError(t, err)
+		require.Equal(t, webhook1.ID, webhook2.ID)
+	})
+
+	t.Run("ring release", func(t *testing.T) {
+		ring, err := client.CreateRing(&model.CreateRingRequest{Name: "idempotent-release-ring", Priority: 5})
+		require.NoError(t, err)
+		ring.State = model.RingStateStable
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		keyClient := client.WithIdempotencyKey("release-key")
+		request := &model.RingReleaseRequest{Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"}
+		ring, err = keyClient.ReleaseRing(ring.ID, request)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePending, ring.State)
+
+		// Retrying once the release completed does not release the ring again.
+		ring.State = model.RingStateStable
+		ring.ActiveReleaseID = ring.DesiredReleaseID
+		require.NoError(t, sqlStore.UpdateRing(ring))
+		request.Version = "7.2.0"
+		retried, err := keyClient.ReleaseRing(ring.ID, request)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, retried.State)
+		require.Equal(t, ring.DesiredReleaseID, retried.DesiredReleaseID)
+	})
+
+	t.Run("key reused for another resource type", func(t *testing.T) {
+		_, err := client.WithIdempotencyKey("ring-key").CreateWebhook(&model.CreateWebhookRequest{OwnerID: "owner", URL: "https://example.com/other"})
+		requireAPIError(t, err, 409)
+	})
+
+	t.Run("key reserved by a request in progress", func(t *testing.T) {
+		reserved, err := sqlStore.ReserveIdempotencyKey(&model.IdempotencyKey{ID: "in-progress-key", ResourceType: model.TypeRing})
+		require.NoError(t, err)
+		require.True(t, reserved)
+
+		_, err = client.WithIdempotencyKey("in-progress-key").CreateRing(&model.CreateRingRequest{Name: "idempotent-in-progress", Priority: 4})
+		requireAPIError(t, err, 409)
+	})
+
+	t.Run("key too long", func(t *testing.T) {
+		key := make([]byte, model.MaxIdempotencyKeyLength+1)
+		for i := range key {
+			key[i] = 'k'
+		}
+		_, err := client.WithIdempotencyKey(string(key)).CreateRing(&model.CreateRingRequest{Name: "idempotent-long", Priority: 3})
+		requireAPIError(t, err, 400)
+	})
+}
@@ -5,6 +5,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -16,9 +17,49 @@ func initInstallationGroup(apiRouter *mux.Router, context *Context) {
 	addContext := func(handler contextHandlerFunc) *contextHandler {
 		return newContextHandler(context, handler)
 	}
+	addCachedContext := func(handler contextHandlerFunc) *contextHandler {
+		return newCachedContextHandler(context, handler)
+	}
 
 	installationGroupRouter := apiRouter.PathPrefix("/installationgroup/{installationgroup:[A-Za-z0-9]{26}}").Subrouter()
-	installationGroupRouter.Handle("/update", addContext(handleUpdateInstallationGroup)).Methods("POST")
+	installationGroupRouter.Handle("", addCachedContext(handleGetInstallationGroup)).Methods("GET")
+	installationGroupRouter.Handle("/update", addContext(validateRequestBody(model.SchemaUpdateInstallationGroupRequest, handleUpdateInstallationGroup))).Methods("POST")
+	installationGroupRouter.Handle("/release", addContext(validateRequestBody(model.SchemaInstallationGroupReleaseRequest, handleReleaseInstallationGroup))).Methods("POST")
+	installationGroupRouter.Handle("/archive", addContext(handleArchiveInstallationGroup)).Methods("POST")
+	installationGroupRouter.Handle("/archive", addContext(handleUnarchiveInstallationGroup)).Methods("DELETE")
+	installationGroupRouter.Handle("/soakchecks", addContext(handleGetInstallationGroupSoakChecks)).Methods("GET")
+	installationGroupRouter.Handle("/notes", addContext(handleGetNotes(model.TypeInstallationGroup))).Methods("GET")
+	installationGroupRouter.Handle("/notes", addContext(validateRequestBody(model.SchemaNoteRequest, handleAddNote(model.TypeInstallationGroup)))).Methods("POST")
+}
+
+// handleGetInstallationGroup responds to GET /api/installationgroup/{installationgroup},
+// returning the installation group in question, including its lock holder.
+func handleGetInstallationGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationGroupID := vars["installationgroup"]
+	c.Logger = c.Logger.WithField("installationgroup", installationGroupID)
+
+	installationGroup, err := c.Store.GetInstallationGroupByID(installationGroupID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation group")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query installation group")
+		return
+	}
+	if installationGroup == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "installation group not found")
+		return
+	}
+
+	installationGroup.Notes, err = c.Store.GetNotes(&model.NoteFilter{ResourceType: model.TypeInstallationGroup, ResourceIDs: []string{installationGroup.ID}})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get notes for installation group")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get notes for installation group")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, installationGroup)
 }
 
 // handleUpdateInstallationGroup responds to POST /api/installationgroup/{installationgroup}/update,
@@ -33,14 +74,18 @@ func handleUpdateInstallationGroup(c *Context, w http.ResponseWriter, r *http.Re
 	installationGroup, err := c.Store.GetInstallationGroupByID(installationGroupID)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to update installation group")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update installation group")
+		return
+	}
+	if installationGroup == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "installation group not found")
 		return
 	}
 
 	updateInstallationGroupRequest, err := model.NewUpdateInstallationGroupRequestFromReader(r.Body)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to deserialize ring update request body")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to deserialize ring update request body")
 		return
 	}
 
@@ -56,9 +101,68 @@ func handleUpdateInstallationGroup(c *Context, w http.ResponseWriter, r *http.Re
 		installationGroup.ProvisionerGroupID = updateInstallationGroupRequest.ProvisionerGroupID
 	}
 
+	if updateInstallationGroupRequest.Annotations != nil {
+		installationGroup.Annotations = updateInstallationGroupRequest.Annotations
+	}
+
+	if updateInstallationGroupRequest.Metadata != nil {
+		installationGroup.Metadata = updateInstallationGroupRequest.Metadata
+	}
+
+	if updateInstallationGroupRequest.FailureDomain != nil {
+		installationGroup.FailureDomain = *updateInstallationGroupRequest.FailureDomain
+	}
+
+	if updateInstallationGroupRequest.ReleaseStrategy != "" {
+		installationGroup.ReleaseStrategy = updateInstallationGroupRequest.ReleaseStrategy
+	}
+
+	if updateInstallationGroupRequest.ReleaseLoadThreshold != nil {
+		installationGroup.ReleaseLoadThreshold = *updateInstallationGroupRequest.ReleaseLoadThreshold
+	}
+
+	if updateInstallationGroupRequest.ReleaseLoadMaxWait != nil {
+		installationGroup.ReleaseLoadMaxWait = *updateInstallationGroupRequest.ReleaseLoadMaxWait
+	}
+
+	if updateInstallationGroupRequest.SoakChecks != nil {
+		installationGroup.SoakChecks = *updateInstallationGroupRequest.SoakChecks
+	}
+
+	if updateInstallationGroupRequest.ReleaseWindows != nil {
+		installationGroup.ReleaseWindows = *updateInstallationGroupRequest.ReleaseWindows
+	}
+
+	if updateInstallationGroupRequest.VerificationURL != nil {
+		installationGroup.VerificationURL = *updateInstallationGroupRequest.VerificationURL
+	}
+
+	if updateInstallationGroupRequest.VerificationTimeout != nil {
+		installationGroup.VerificationTimeout = *updateInstallationGroupRequest.VerificationTimeout
+	}
+
+	if updateInstallationGroupRequest.DatabaseSnapshot != nil {
+		installationGroup.DatabaseSnapshot = *updateInstallationGroupRequest.DatabaseSnapshot
+	}
+
+	// Counts set manually hold until the next sync from the provisioner.
+	if updateInstallationGroupRequest.InstallationCount != nil {
+		installationGroup.InstallationCount = *updateInstallationGroupRequest.InstallationCount
+		installationGroup.CapacitySyncedAt = 0
+	}
+
+	if updateInstallationGroupRequest.CustomerCount != nil {
+		installationGroup.CustomerCount = *updateInstallationGroupRequest.CustomerCount
+		installationGroup.CapacitySyncedAt = 0
+	}
+
+	if updateInstallationGroupRequest.CapacityTarget != nil {
+		installationGroup.CapacityTarget = *updateInstallationGroupRequest.CapacityTarget
+	}
+
 	if err = c.Store.UpdateInstallationGroup(installationGroup); err != nil {
 		c.Logger.WithError(err).Error("failed to update installation group")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update installation group")
 		return
 	}
 
@@ -66,3 +170,47 @@ func handleUpdateInstallationGroup(c *Context, w http.ResponseWriter, r *http.Re
 	w.WriteHeader(http.StatusAccepted)
 	outputJSON(c, w, installationGroup)
 }
+
+// handleGetInstallationGroupSoakChecks responds to GET /api/installationgroup/{installationgroup}/soakchecks,
+// returning the specified page of soak check results of the installation group, newest first.
+func handleGetInstallationGroupSoakChecks(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationGroupID := vars["installationgroup"]
+	c.Logger = c.Logger.WithField("installationgroup", installationGroupID)
+
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to parse paging parameters: %s", err))
+		return
+	}
+
+	installationGroup, err := c.Store.GetInstallationGroupByID(installationGroupID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation group")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query installation group")
+		return
+	}
+	if installationGroup == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "installation group not found")
+		return
+	}
+
+	results, err := c.Store.GetSoakCheckResults(&model.SoakCheckResultFilter{
+		InstallationGroupID: installationGroupID,
+		Page:                page,
+		PerPage:             perPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query soak check results")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query soak check results")
+		return
+	}
+	if results == nil {
+		results = []*model.SoakCheckResult{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, results)
+}
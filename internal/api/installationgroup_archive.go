@@ -0,0 +1,125 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// recentReleaseHistory is how long the transitions of an installation group
+// keep it from being deleted before being archived.
+const recentReleaseHistory = 7 * 24 * time.Hour
+
+// handleArchiveInstallationGroup responds to POST /api/installationgroup/{installationgroup}/archive,
+// archiving the installation group so that it can be deleted although it is
+// still referenced.
+func handleArchiveInstallationGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	handleInstallationGroupArchiveChange(c, w, r, true)
+}
+
+// handleUnarchiveInstallationGroup responds to DELETE /api/installationgroup/{installationgroup}/archive,
+// protecting the installation group from deletion again while it is
+// referenced.
+func handleUnarchiveInstallationGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	handleInstallationGroupArchiveChange(c, w, r, false)
+}
+
+func handleInstallationGroupArchiveChange(c *Context, w http.ResponseWriter, r *http.Request, archive bool) {
+	vars := mux.Vars(r)
+	installationGroupID := vars["installationgroup"]
+	c.Logger = c.Logger.WithField("installationgroup", installationGroupID)
+
+	installationGroup, err := c.Store.GetInstallationGroupByID(installationGroupID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation group")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query installation group")
+		return
+	}
+	if installationGroup == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "installation group not found")
+		return
+	}
+
+	if archive && installationGroup.ArchivedAt == 0 {
+		// Archiving an installation group being released would let it be
+		// deleted before its release history is complete.
+		if installationGroup.State != model.InstallationGroupStable && installationGroup.State != model.InstallationGroupReleaseFailed {
+			c.Logger.Warnf("unable to archive installation group while in state %s", installationGroup.State)
+			outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to archive installation group while in state %s", installationGroup.State), map[string]string{"state": installationGroup.State})
+			return
+		}
+		installationGroup.ArchivedAt = model.GetMillis()
+	} else if !archive {
+		installationGroup.ArchivedAt = 0
+	}
+
+	if err = c.Store.UpdateInstallationGroupArchivedAt(installationGroup.ID, installationGroup.ArchivedAt); err != nil {
+		c.Logger.WithError(err).Error("failed to update installation group")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update installation group")
+		return
+	}
+	c.Logger.Infof("Installation group archived: %t", archive)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, installationGroup)
+}
+
+// checkInstallationGroupDeletable checks that the given installation group of
+// the ring can be deleted: it must be archived first while anything still
+// references it. It writes an error response and returns false otherwise.
+func checkInstallationGroupDeletable(c *Context, w http.ResponseWriter, ring *model.Ring, installationGroup *model.InstallationGroup) bool {
+	if installationGroup.ArchivedAt != 0 {
+		return true
+	}
+
+	references, err := getInstallationGroupReferences(c, ring, installationGroup)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get installation group references")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get installation group references")
+		return false
+	}
+	if len(references) > 0 {
+		c.Logger.Warnf("unable to delete referenced installation group %s: %s", installationGroup.Name, strings.Join(references, "; "))
+		outputErrorWithDetails(c, w, http.StatusConflict, model.ErrorCodeInstallationGroupReferenced, fmt.Sprintf("installation group %s must be archived before being deleted", installationGroup.Name), map[string]string{"references": strings.Join(references, "; ")})
+		return false
+	}
+
+	return true
+}
+
+// getInstallationGroupReferences describes what keeps the given installation
+// group of the ring from being deleted before being archived: its recent
+// release history, which the timeline and reports of the ring are built
+// from, and the rollback target of the ring, which it would be rolled back to.
+func getInstallationGroupReferences(c *Context, ring *model.Ring, installationGroup *model.InstallationGroup) ([]string, error) {
+	var references []string
+
+	since := time.Now().Add(-recentReleaseHistory)
+	events, err := c.Store.GetStateChangeEvents(&model.StateChangeEventFilter{
+		ResourceType: model.TypeInstallationGroup,
+		ResourceID:   installationGroup.ID,
+		From:         since.UnixMilli(),
+		PerPage:      1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > 0 {
+		references = append(references, fmt.Sprintf("release history since %s", since.UTC().Format(time.RFC3339)))
+	}
+
+	if ring.RollbackSnapshotID != "" && installationGroup.PreviousReleaseID != "" {
+		references = append(references, fmt.Sprintf("rollback target of ring %s to release %s", ring.Name, installationGroup.PreviousReleaseID))
+	}
+
+	return references, nil
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallationGroupDeletionProtection(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring := &model.Ring{Name: "ring1", State: model.RingStateStable}
+	installationGroup := &model.InstallationGroup{Name: "group1", State: model.InstallationGroupStable}
+	require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+
+	t.Run("unknown installation group", func(t *testing.T) {
+		_, err := client.ArchiveInstallationGroup(model.NewID())
+		requireAPIError(t, err, 404)
+	})
+
+	t.Run("old history does not protect", func(t *testing.T) {
+		old := &model.InstallationGroup{Name: "old", State: model.InstallationGroupStable}
+		old, err := sqlStore.CreateRingInstallationGroup(ring.ID, old)
+		require.NoError(t, err)
+		require.NoError(t, sqlStore.CreateStateChangeEvent(&model.StateChangeEvent{
+			ResourceType: model.TypeInstallationGroup,
+			ResourceID:   old.ID,
+			RingID:       ring.ID,
+			OldState:     model.InstallationGroupReleasePending,
+			NewState:     model.InstallationGroupStable,
+			Timestamp:    time.Now().Add(-30 * 24 * time.Hour).UnixMilli(),
+		}))
+
+		require.NoError(t, client.DeleteRingInstallationGroup(ring.ID, old.ID))
+	})
+
+	t.Run("recent release history protects", func(t *testing.T) {
+		require.NoError(t, sqlStore.CreateStateChangeEvent(&model.StateChangeEvent{
+			ResourceType: model.TypeInstallationGroup,
+			ResourceID:   installationGroup.ID,
+			RingID:       ring.ID,
+			OldState:     model.InstallationGroupReleasePending,
+			NewState:     model.InstallationGroupStable,
+		}))
+
+		err := client.DeleteRingInstallationGroup(ring.ID, installationGroup.ID)
+		apiErr := requireAPIError(t, err, 409)
+		require.Equal(t, model.ErrorCodeInstallationGroupReferenced, apiErr.Code)
+		require.Contains(t, apiErr.Details["references"], "release history since")
+	})
+
+	t.Run("rollback target protects", func(t *testing.T) {
+		installationGroup.PreviousReleaseID = model.NewID()
+		require.NoError(t, sqlStore.UpdateInstallationGroupActiveRelease(installationGroup.ID, model.NewID(), installationGroup.PreviousReleaseID))
+		ring.RollbackSnapshotID = model.NewID()
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		err := client.DeleteRingInstallationGroup(ring.ID, installationGroup.ID)
+		apiErr := requireAPIError(t, err, 409)
+		require.Contains(t, apiErr.Details["references"], "rollback target of ring ring1 to release "+installationGroup.PreviousReleaseID)
+	})
+
+	t.Run("apply protects", func(t *testing.T) {
+		spec := &model.FleetSpec{Rings: []*model.RingSpec{{Name: "ring1", Priority: 1}}}
+		plan, err := client.Apply(spec, false)
+		require.NoError(t, err)
+		require.Contains(t, plan.Changes, &model.ApplyChange{Action: model.ApplyActionDelete, ResourceType: model.TypeInstallationGroup, Name: "group1", Ring: "ring1"})
+
+		_, err = client.Apply(spec, true)
+		apiErr := requireAPIError(t, err, 409)
+		require.Equal(t, model.ErrorCodeInstallationGroupReferenced, apiErr.Code)
+		require.Contains(t, apiErr.Details["references"], "release history since")
+
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, installationGroups, 1)
+	})
+
+	t.Run("cannot archive while releasing", func(t *testing.T) {
+		releasing := &model.InstallationGroup{Name: "releasing", State: model.InstallationGroupReleaseRequested}
+		releasing, err := sqlStore.CreateRingInstallationGroup(ring.ID, releasing)
+		require.NoError(t, err)
+
+		_, err = client.ArchiveInstallationGroup(releasing.ID)
+		apiErr := requireAPIError(t, err, 400)
+		require.Equal(t, model.ErrorCodeInvalidStateTransition, apiErr.Code)
+	})
+
+	t.Run("unarchive", func(t *testing.T) {
+		archived, err := client.ArchiveInstallationGroup(installationGroup.ID)
+		require.NoError(t, err)
+		require.NotZero(t, archived.ArchivedAt)
+
+		unarchived, err := client.UnarchiveInstallationGroup(installationGroup.ID)
+		require.NoError(t, err)
+		require.Zero(t, unarchived.ArchivedAt)
+
+		err = client.DeleteRingInstallationGroup(ring.ID, installationGroup.ID)
+		requireAPIError(t, err, 409)
+	})
+
+	t.Run("archived installation group can be deleted", func(t *testing.T) {
+		archived, err := client.ArchiveInstallationGroup(installationGroup.ID)
+		require.NoError(t, err)
+		require.NotZero(t, archived.ArchivedAt)
+
+		fetched, err := client.GetInstallationGroup(installationGroup.ID)
+		require.NoError(t, err)
+		require.Equal(t, archived.ArchivedAt, fetched.ArchivedAt)
+
+		require.NoError(t, client.DeleteRingInstallationGroup(ring.ID, installationGroup.ID))
+
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, installationGroups, 1)
+		require.Equal(t, "releasing", installationGroups[0].Name)
+	})
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/webhook"
+	"github.com/mattermost/elrond/model"
+)
+
+// handleReleaseInstallationGroup responds to POST /api/installationgroup/{installationgroup}/release,
+// releasing again, on its own, an installation group whose release failed
+// along with its ring. The ring goes back to release-in-progress, so that
+// once the installation group is done the supervisor works out the state of
+// the ring from all of its installation groups: failed again while others
+// still are, and soaking, or stable for forced releases, otherwise.
+func handleReleaseInstallationGroup(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	installationGroupID := vars["installationgroup"]
+	c.Logger = c.Logger.
+		WithField("installationgroup", installationGroupID).
+		WithField("action", "release-installation-group")
+
+	installationGroupReleaseRequest, err := model.NewInstallationGroupReleaseRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "failed to decode request")
+		return
+	}
+
+	ring, err := c.Store.GetRingFromInstallationGroupID(installationGroupID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation group ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query installation group ring")
+		return
+	}
+	if ring == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "installation group not found in any ring")
+		return
+	}
+
+	ring, status, unlockRingOnce := lockRing(c, ring.ID)
+	if status != 0 {
+		outputStatusError(c, w, status, "ring")
+		return
+	}
+	defer unlockRingOnce()
+
+	installationGroup, status, unlockInstallationGroupOnce := lockRingInstallationGroup(c, installationGroupID)
+	if status != 0 {
+		outputStatusError(c, w, status, "installation group")
+		return
+	}
+	defer unlockInstallationGroupOnce()
+
+	if ring.APISecurityLock {
+		logSecurityLockConflict("ring", c.Logger)
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, "API changes are locked for this ring")
+		return
+	}
+
+	if !installationGroup.HasFailedRelease() || !installationGroup.ValidInstallationGroupTransitionState(model.InstallationGroupReleasePending) {
+		c.Logger.Warnf("unable to release installation group while in state %s", installationGroup.State)
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to release installation group while in state %s", installationGroup.State), map[string]string{"state": installationGroup.State})
+		return
+	}
+	if !ring.ValidTransitionState(model.RingStateReleaseInProgress) {
+		c.Logger.Warnf("unable to release installation group while its ring is in state %s", ring.State)
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to release installation group while its ring is in state %s", ring.State), map[string]string{"ringState": ring.State})
+		return
+	}
+	// Resuming a release past its deadline would only fail it again on the
+	// next supervisor run; the ring has to be released anew.
+	if ring.ReleaseDeadlineExceeded(time.Now()) {
+		c.Logger.Warnf("unable to release installation group of a ring past its max release duration of %d seconds", ring.MaxReleaseDuration)
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, ring.ReleaseDeadlineError()+"; release the ring again instead", map[string]string{"maxReleaseDuration": strconv.Itoa(ring.MaxReleaseDuration)})
+		return
+	}
+
+	if installationGroupReleaseRequest.Force {
+		if !checkRingsNotProtected(c, w, "force release", []*model.Ring{ring}) {
+			return
+		}
+		// The approval is bound to the installation group, so that it does
+		// not confirm the forced release of another one of the ring.
+		forcedRequest := map[string]interface{}{"installationGroupID": installationGroup.ID, "request": installationGroupReleaseRequest}
+		if !requireForceApproval(c, w, model.ForceApprovalActionRelease, []*model.Ring{ring}, forcedRequest) {
+			return
+		}
+	}
+
+	oldInstallationGroupState := installationGroup.State
+	installationGroup.State = model.InstallationGroupReleasePending
+	installationGroup.ForceRelease = installationGroupReleaseRequest.Force
+	if err = c.Store.UpdateInstallationGroup(installationGroup); err != nil {
+		c.Logger.WithError(err).Error("failed to update installation group")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update installation group")
+		return
+	}
+	recordInstallationGroupStateChange(c, installationGroup, ring, oldInstallationGroupState, installationGroup.State)
+
+	oldRingState := ring.State
+	ring.State = model.RingStateReleaseInProgress
+	if err = c.Store.UpdateRing(ring); err != nil {
+		c.Logger.WithError(err).Error("failed to update ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update ring")
+		return
+	}
+	recordRingStateChange(c, ring, oldRingState, ring.State)
+
+	for _, webhookPayload := range []*model.WebhookPayload{{
+		Type:         model.TypeInstallationGroup,
+		ID:           installationGroup.ID,
+		NewState:     installationGroup.State,
+		OldState:     oldInstallationGroupState,
+		Timestamp:    time.Now().UnixNano(),
+		RingID:       ring.ID,
+		Labels:       ring.Annotations,
+		Metadata:     installationGroup.Metadata,
+		RingMetadata: ring.Metadata,
+	}, {
+		Type:      model.TypeRing,
+		ID:        ring.ID,
+		NewState:  ring.State,
+		OldState:  oldRingState,
+		Timestamp: time.Now().UnixNano(),
+		Labels:    ring.Annotations,
+		Metadata:  ring.Metadata,
+	}} {
+		if err := webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+			c.Logger.WithError(err).Error("Unable to process and send webhooks")
+		}
+	}
+
+	unlockInstallationGroupOnce()
+	unlockRingOnce()
+	c.Supervisor.Do() //nolint
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, installationGroup)
+}
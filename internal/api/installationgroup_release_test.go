@@ -0,0 +1,99 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseInstallationGroup(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring := &model.Ring{Name: "ring1", State: model.RingStateReleaseFailed}
+	failed := &model.InstallationGroup{Name: "group1", State: model.InstallationGroupReleaseFailed}
+	require.NoError(t, sqlStore.CreateRing(ring, failed))
+	stable, err := sqlStore.CreateRingInstallationGroup(ring.ID, &model.InstallationGroup{Name: "group2", State: model.InstallationGroupStable})
+	require.NoError(t, err)
+
+	t.Run("unknown installation group", func(t *testing.T) {
+		_, err := client.ReleaseInstallationGroup(model.NewID(), &model.InstallationGroupReleaseRequest{})
+		requireAPIError(t, err, 404)
+	})
+
+	t.Run("installation group without a failed release", func(t *testing.T) {
+		_, err := client.ReleaseInstallationGroup(stable.ID, &model.InstallationGroupReleaseRequest{})
+		apiErr := requireAPIError(t, err, 400)
+		require.Equal(t, model.ErrorCodeInvalidStateTransition, apiErr.Code)
+	})
+
+	t.Run("ring of an older state machine version", func(t *testing.T) {
+		ring.StateMachineVersion = 3
+		require.NoError(t, sqlStore.UpdateRing(ring))
+		defer func() {
+			ring.StateMachineVersion = model.CurrentStateMachineVersion
+			require.NoError(t, sqlStore.UpdateRing(ring))
+		}()
+
+		_, err := client.ReleaseInstallationGroup(failed.ID, &model.InstallationGroupReleaseRequest{})
+		apiErr := requireAPIError(t, err, 400)
+		require.Equal(t, model.ErrorCodeInvalidStateTransition, apiErr.Code)
+	})
+
+	t.Run("ring past its max release duration", func(t *testing.T) {
+		ring.MaxReleaseDuration = 3600
+		ring.ReleaseStartAt = time.Now().Add(-2 * time.Hour).UnixNano()
+		require.NoError(t, sqlStore.UpdateRing(ring))
+		defer func() {
+			ring.MaxReleaseDuration = 0
+			ring.ReleaseStartAt = 0
+			require.NoError(t, sqlStore.UpdateRing(ring))
+		}()
+
+		_, err := client.ReleaseInstallationGroup(failed.ID, &model.InstallationGroupReleaseRequest{})
+		apiErr := requireAPIError(t, err, 400)
+		require.Equal(t, model.ErrorCodeInvalidStateTransition, apiErr.Code)
+		require.Equal(t, "3600", apiErr.Details["maxReleaseDuration"])
+	})
+
+	t.Run("release", func(t *testing.T) {
+		installationGroup, err := client.ReleaseInstallationGroup(failed.ID, &model.InstallationGroupReleaseRequest{Force: true})
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationGroupReleasePending, installationGroup.State)
+		require.True(t, installationGroup.ForceRelease)
+
+		ring, err := client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseInProgress, ring.State)
+
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{RingID: ring.ID, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+
+		// The ring release is in progress again.
+		_, err = client.ReleaseInstallationGroup(failed.ID, &model.InstallationGroupReleaseRequest{})
+		requireAPIError(t, err, 400)
+	})
+}
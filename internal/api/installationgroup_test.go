@@ -0,0 +1,253 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetInstallationGroup(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("unknown installation group", func(t *testing.T) {
+		installationGroup, err := client.GetInstallationGroup(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, installationGroup)
+	})
+
+	ring := &model.Ring{}
+	installationGroup := &model.InstallationGroup{Name: "group1"}
+	err := sqlStore.CreateRing(ring, installationGroup)
+	require.NoError(t, err)
+
+	t.Run("unlocked", func(t *testing.T) {
+		fetched, err := client.GetInstallationGroup(installationGroup.ID)
+		require.NoError(t, err)
+		require.Equal(t, "group1", fetched.Name)
+		require.Nil(t, fetched.LockAcquiredBy)
+		require.Zero(t, fetched.LockAcquiredAt)
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		locked, err := sqlStore.LockRingInstallationGroup(installationGroup.ID, "instance1")
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		fetched, err := client.GetInstallationGroup(installationGroup.ID)
+		require.NoError(t, err)
+		require.NotNil(t, fetched.LockAcquiredBy)
+		require.Equal(t, "instance1", *fetched.LockAcquiredBy)
+		require.NotZero(t, fetched.LockAcquiredAt)
+
+		rings, err := client.GetRings(&model.GetRingsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, rings, 1)
+		require.Len(t, rings[0].InstallationGroups, 1)
+		require.Equal(t, "instance1", *rings[0].InstallationGroups[0].LockAcquiredBy)
+	})
+}
+
+func TestInstallationGroupReleaseStrategy(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{
+		Priority: 1,
+		InstallationGroup: &model.InstallationGroup{
+			Name:            "group1",
+			ReleaseStrategy: model.ReleaseStrategyBlueGreen,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, ring.InstallationGroups, 1)
+	require.Equal(t, model.ReleaseStrategyBlueGreen, ring.InstallationGroups[0].ReleaseStrategy)
+
+	t.Run("register", func(t *testing.T) {
+		ring, err = client.RegisterRingInstallationGroup(ring.ID, &model.RegisterInstallationGroupRequest{
+			Name:            "group2",
+			ReleaseStrategy: model.ReleaseStrategyBlueGreen,
+		})
+		require.NoError(t, err)
+
+		installationGroup, err := sqlStore.GetInstallationGroupByName("group2")
+		require.NoError(t, err)
+		require.Equal(t, model.ReleaseStrategyBlueGreen, installationGroup.ReleaseStrategy)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		installationGroup, err := sqlStore.GetInstallationGroupByName("group1")
+		require.NoError(t, err)
+
+		updated, err := client.UpdateInstallationGroup(installationGroup.ID, &model.UpdateInstallationGroupRequest{
+			ReleaseStrategy: model.ReleaseStrategyRolling,
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.ReleaseStrategyRolling, updated.ReleaseStrategy)
+
+		updated, err = client.UpdateInstallationGroup(installationGroup.ID, &model.UpdateInstallationGroupRequest{SoakTime: 60})
+		require.NoError(t, err)
+		require.Equal(t, model.ReleaseStrategyRolling, updated.ReleaseStrategy)
+	})
+
+	t.Run("invalid strategy", func(t *testing.T) {
+		_, err := client.CreateRing(&model.CreateRingRequest{
+			Priority:          1,
+			InstallationGroup: &model.InstallationGroup{Name: "group3", ReleaseStrategy: "canary"},
+		})
+		requireAPIError(t, err, 400)
+	})
+}
+
+func TestInstallationGroupCapacity(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{
+		Priority:          1,
+		InstallationGroup: &model.InstallationGroup{Name: "group1", InstallationCount: 10, CustomerCount: 8, CapacityTarget: 20},
+	})
+	require.NoError(t, err)
+
+	ring, err = client.RegisterRingInstallationGroup(ring.ID, &model.RegisterInstallationGroupRequest{Name: "group2"})
+	require.NoError(t, err)
+
+	t.Run("ring capacity", func(t *testing.T) {
+		fetched, err := client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, &model.RingCapacity{Installations: 10, Customers: 8, Target: 20, Manual: 1, Unknown: 1}, fetched.Capacity)
+
+		rings, err := client.GetRings(&model.GetRingsRequest{Page: 0, PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, rings, 1)
+		require.Equal(t, fetched.Capacity, rings[0].Capacity)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		installationGroup, err := sqlStore.GetInstallationGroupByName("group2")
+		require.NoError(t, err)
+		require.NoError(t, sqlStore.UpdateInstallationGroupCapacity(installationGroup.ID, 3, 2, 1234))
+
+		installations := int64(4)
+		updated, err := client.UpdateInstallationGroup(installationGroup.ID, &model.UpdateInstallationGroupRequest{InstallationCount: &installations})
+		require.NoError(t, err)
+		require.Equal(t, int64(4), updated.InstallationCount)
+		require.Equal(t, int64(2), updated.CustomerCount)
+		require.Zero(t, updated.CapacitySyncedAt)
+
+		fetched, err := client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, &model.RingCapacity{Installations: 14, Customers: 10, Target: 20, Manual: 2}, fetched.Capacity)
+	})
+}
+
+func TestGetInstallationGroupSoakChecks(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("unknown installation group", func(t *testing.T) {
+		_, err := client.GetInstallationGroupSoakChecks(model.NewID(), &model.GetSoakCheckResultsRequest{PerPage: 10})
+		apiErr := requireAPIError(t, err, http.StatusNotFound)
+		require.Equal(t, model.ErrorCodeNotFound, apiErr.Code)
+	})
+
+	ring := &model.Ring{}
+	installationGroup := &model.InstallationGroup{Name: "group1"}
+	err := sqlStore.CreateRing(ring, installationGroup)
+	require.NoError(t, err)
+
+	t.Run("no results", func(t *testing.T) {
+		results, err := client.GetInstallationGroupSoakChecks(installationGroup.ID, &model.GetSoakCheckResultsRequest{PerPage: 10})
+		require.NoError(t, err)
+		require.Empty(t, results)
+	})
+
+	soakTime := &model.SoakCheckResult{
+		InstallationGroupID: installationGroup.ID,
+		RingID:              ring.ID,
+		Name:                model.SoakCheckSoakTime,
+		ObservedValue:       61,
+		Threshold:           60,
+		Passed:              true,
+		CreateAt:            1,
+	}
+	provisioner := &model.SoakCheckResult{
+		InstallationGroupID: installationGroup.ID,
+		RingID:              ring.ID,
+		Name:                model.SoakCheckProvisioner,
+		Threshold:           1,
+		Message:             "group unhealthy",
+		CreateAt:            2,
+	}
+	other := &model.SoakCheckResult{InstallationGroupID: model.NewID(), Name: model.SoakCheckSoakTime}
+	for _, result := range []*model.SoakCheckResult{soakTime, provisioner, other} {
+		require.NoError(t, sqlStore.CreateSoakCheckResult(result))
+	}
+
+	t.Run("newest first", func(t *testing.T) {
+		results, err := client.GetInstallationGroupSoakChecks(installationGroup.ID, &model.GetSoakCheckResultsRequest{PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []*model.SoakCheckResult{provisioner, soakTime}, results)
+	})
+
+	t.Run("paged", func(t *testing.T) {
+		results, err := client.GetInstallationGroupSoakChecks(installationGroup.ID, &model.GetSoakCheckResultsRequest{Page: 1, PerPage: 1})
+		require.NoError(t, err)
+		require.Equal(t, []*model.SoakCheckResult{soakTime}, results)
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// initJob registers job endpoints on the given router.
+func initJob(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	jobsRouter := apiRouter.PathPrefix("/jobs").Subrouter()
+	jobsRouter.Handle("", addContext(handleGetJobs)).Methods("GET")
+	jobsRouter.Handle("", addContext(validateRequestBody(model.SchemaCreateJobRequest, handleCreateJob))).Methods("POST")
+
+	jobRouter := apiRouter.PathPrefix("/job/{job:[A-Za-z0-9]{26}}").Subrouter()
+	jobRouter.Handle("", addContext(handleGetJob)).Methods("GET")
+}
+
+// handleGetJobs responds to GET /api/jobs, returning a page of jobs, newest
+// first, optionally of a type or in a state.
+func handleGetJobs(c *Context, w http.ResponseWriter, r *http.Request) {
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to parse paging parameters: %s", err))
+		return
+	}
+
+	jobs, err := c.Store.GetJobs(&model.JobFilter{
+		Type:    r.URL.Query().Get("type"),
+		State:   r.URL.Query().Get("state"),
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query jobs")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query jobs")
+		return
+	}
+	if jobs == nil {
+		jobs = []*model.Job{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, jobs)
+}
+
+// handleCreateJob responds to POST /api/jobs, queueing a job for the
+// supervisors to run in the background.
+func handleCreateJob(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	createJobRequest, err := model.NewCreateJobRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode request: %s", err))
+		return
+	}
+
+	job := &model.Job{
+		Type:        createJobRequest.Type,
+		Parameters:  createJobRequest.Parameters,
+		State:       model.JobStatePending,
+		MaxAttempts: createJobRequest.MaxAttempts,
+		CreatedBy:   c.TokenID,
+	}
+	if err = c.Store.CreateJob(job); err != nil {
+		c.Logger.WithError(err).Error("failed to create job")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to create job")
+		return
+	}
+
+	c.Logger.WithField("job", job.ID).Infof("Queued %s job", job.Type)
+
+	c.Supervisor.Do() //nolint
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, job)
+}
+
+// handleGetJob responds to GET /api/job/{job}, returning the job in question.
+func handleGetJob(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["job"]
+	c.Logger = c.Logger.WithField("job", jobID)
+
+	job, err := c.Store.GetJob(jobID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query job")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query job")
+		return
+	}
+	if job == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "job not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, job)
+}
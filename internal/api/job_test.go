@@ -0,0 +1,96 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobs(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := newAdminClient(t, sqlStore, ts.URL)
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err := client.CreateJob(&model.CreateJobRequest{Type: "backup"})
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		_, err = client.CreateJob(&model.CreateJobRequest{Type: model.JobTypePrune})
+		requireAPIError(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		job, err := client.GetJob(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, job)
+	})
+
+	var prune *model.Job
+	t.Run("create", func(t *testing.T) {
+		var err error
+		prune, err = client.CreateJob(&model.CreateJobRequest{
+			Type:       model.JobTypePrune,
+			Parameters: model.JobParameters{model.JobParameterOlderThanDays: "30"},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, prune.ID)
+		require.Equal(t, model.JobStatePending, prune.State)
+		require.Equal(t, model.DefaultJobMaxAttempts, prune.MaxAttempts)
+
+		_, err = client.CreateJob(&model.CreateJobRequest{Type: model.JobTypeResync, MaxAttempts: 1})
+		require.NoError(t, err)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		job, err := client.GetJob(prune.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.JobTypePrune, job.Type)
+		require.Equal(t, "30", job.Parameters[model.JobParameterOlderThanDays])
+	})
+
+	t.Run("list", func(t *testing.T) {
+		jobs, err := client.GetJobs(&model.GetJobsRequest{PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+
+		jobs, err = client.GetJobs(&model.GetJobsRequest{Type: model.JobTypePrune, State: model.JobStatePending, PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		require.Equal(t, prune.ID, jobs[0].ID)
+
+		jobs, err = client.GetJobs(&model.GetJobsRequest{State: model.JobStateFailed, PerPage: 10})
+		require.NoError(t, err)
+		require.Empty(t, jobs)
+	})
+
+	t.Run("requires the admin role", func(t *testing.T) {
+		secret, err := model.NewTokenSecret()
+		require.NoError(t, err)
+		token := &model.Token{Name: "writer", Role: model.TokenRoleWrite, TokenHash: model.HashTokenSecret(secret)}
+		require.NoError(t, sqlStore.CreateToken(token))
+
+		_, err = model.NewClientWithToken(ts.URL, secret).CreateJob(&model.CreateJobRequest{Type: model.JobTypeResync})
+		requireAPIError(t, err, http.StatusForbidden)
+	})
+}
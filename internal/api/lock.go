@@ -105,3 +105,46 @@ func lockRingInstallationGroup(c *Context, installationGroupID string) (*model.I
 		})
 	}
 }
+
+// lockRollout synchronizes access to the given rollout across potentially
+// multiple elrond servers. The rollout is fetched once locked, so it reflects
+// any work of the supervisors.
+func lockRollout(c *Context, rolloutID string) (*model.Rollout, int, func()) {
+	locked, err := c.Store.LockRollout(rolloutID, c.RequestID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to lock rollout")
+		return nil, http.StatusInternalServerError, nil
+	} else if !locked {
+		rollout, err := c.Store.GetRollout(rolloutID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query rollout")
+			return nil, http.StatusInternalServerError, nil
+		}
+		if rollout == nil {
+			return nil, http.StatusNotFound, nil
+		}
+		c.Logger.Error("failed to acquire lock for rollout")
+		return nil, http.StatusConflict, nil
+	}
+
+	unlockOnce := sync.Once{}
+	unlock := func() {
+		unlockOnce.Do(func() {
+			unlocked, err := c.Store.UnlockRollout(rolloutID, c.RequestID, false)
+			if err != nil {
+				c.Logger.WithError(err).Errorf("failed to unlock rollout")
+			} else if !unlocked {
+				c.Logger.Error("failed to release lock for rollout")
+			}
+		})
+	}
+
+	rollout, err := c.Store.GetRollout(rolloutID)
+	if err != nil {
+		unlock()
+		c.Logger.WithError(err).Error("failed to query rollout")
+		return nil, http.StatusInternalServerError, nil
+	}
+
+	return rollout, 0, unlock
+}
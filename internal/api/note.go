@@ -0,0 +1,128 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// noteResourceVars are the route variables holding the ID of the resource
+// notes are managed on, by resource type.
+var noteResourceVars = map[string]string{
+	model.TypeRing:              "ring",
+	model.TypeInstallationGroup: "installationgroup",
+	model.TypeRelease:           "release",
+}
+
+// handleGetNotes returns the handler responding to GET /api/<resource>/{id}/notes,
+// returning the notes left on the resource of the given type, oldest first.
+func handleGetNotes(resourceType string) contextHandlerFunc {
+	return func(c *Context, w http.ResponseWriter, r *http.Request) {
+		resourceID := mux.Vars(r)[noteResourceVars[resourceType]]
+		c.Logger = c.Logger.WithField(resourceType, resourceID)
+
+		exists, err := noteResourceExists(c, resourceType, resourceID)
+		if err != nil {
+			c.Logger.WithError(err).Errorf("failed to query %s", resourceType)
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query "+resourceType)
+			return
+		}
+		if !exists {
+			outputStatusError(c, w, http.StatusNotFound, resourceType)
+			return
+		}
+
+		notes, err := getResourceNotes(c, resourceType, resourceID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query notes")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query notes")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		outputJSON(c, w, notes)
+	}
+}
+
+// handleAddNote returns the handler responding to POST /api/<resource>/{id}/notes,
+// adding a note to the resource of the given type on behalf of the token of
+// the request.
+func handleAddNote(resourceType string) contextHandlerFunc {
+	return func(c *Context, w http.ResponseWriter, r *http.Request) {
+		resourceID := mux.Vars(r)[noteResourceVars[resourceType]]
+		c.Logger = c.Logger.WithField(resourceType, resourceID)
+
+		noteRequest, err := model.NewNoteRequestFromReader(r.Body)
+		if err != nil {
+			outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+			return
+		}
+
+		exists, err := noteResourceExists(c, resourceType, resourceID)
+		if err != nil {
+			c.Logger.WithError(err).Errorf("failed to query %s", resourceType)
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query "+resourceType)
+			return
+		}
+		if !exists {
+			outputStatusError(c, w, http.StatusNotFound, resourceType)
+			return
+		}
+
+		note := &model.Note{
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			Message:      noteRequest.Message,
+			Author:       c.TokenID,
+		}
+		if err = c.Store.CreateNote(note); err != nil {
+			c.Logger.WithError(err).Error("failed to create note")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to create note")
+			return
+		}
+		c.Logger.Infof("Added note %s", note.ID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		outputJSON(c, w, note)
+	}
+}
+
+// noteResourceExists returns whether the resource of the given type and ID
+// notes are left on exists.
+func noteResourceExists(c *Context, resourceType, resourceID string) (bool, error) {
+	switch resourceType {
+	case model.TypeRing:
+		ring, err := c.Store.GetRing(resourceID)
+		return ring != nil, err
+	case model.TypeInstallationGroup:
+		installationGroup, err := c.Store.GetInstallationGroupByID(resourceID)
+		return installationGroup != nil, err
+	case model.TypeRelease:
+		release, err := c.Store.GetRingRelease(resourceID)
+		return release != nil, err
+	}
+
+	return false, errors.Errorf("unsupported note resource type %q", resourceType)
+}
+
+// getResourceNotes returns the notes left on the resource of the given type
+// and ID, oldest first.
+func getResourceNotes(c *Context, resourceType, resourceID string) ([]*model.Note, error) {
+	notes, err := c.Store.GetNotes(&model.NoteFilter{ResourceType: resourceType, ResourceIDs: []string{resourceID}})
+	if err != nil {
+		return nil, err
+	}
+	if notes == nil {
+		notes = []*model.Note{}
+	}
+
+	return notes, nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotes(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	secret, err := model.NewTokenSecret()
+	require.NoError(t, err)
+	token := &model.Token{Name: "alice", Role: model.TokenRoleWrite, TokenHash: model.HashTokenSecret(secret)}
+	require.NoError(t, sqlStore.CreateToken(token))
+	client := model.NewClientWithToken(ts.URL, secret)
+
+	release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.2.0"})
+	require.NoError(t, err)
+	ring := &model.Ring{Name: "ring1", Priority: 1, State: model.RingStateStable}
+	installationGroup := &model.InstallationGroup{Name: "group1", State: model.InstallationGroupStable}
+	require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+
+	t.Run("no notes", func(t *testing.T) {
+		notes, err := client.GetNotes(model.TypeRing, ring.ID)
+		require.NoError(t, err)
+		require.Empty(t, notes)
+
+		fetched, err := client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Empty(t, fetched.Notes)
+	})
+
+	t.Run("empty note", func(t *testing.T) {
+		_, err := client.AddNote(model.TypeRing, ring.ID, &model.NoteRequest{})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("unknown resource", func(t *testing.T) {
+		_, err := client.AddNote(model.TypeInstallationGroup, model.NewID(), &model.NoteRequest{Message: "note"})
+		requireAPIError(t, err, 404)
+		_, err = client.GetNotes(model.TypeRelease, model.NewID())
+		requireAPIError(t, err, 404)
+	})
+
+	t.Run("notes are shown on the resource", func(t *testing.T) {
+		note, err := client.AddNote(model.TypeRing, ring.ID, &model.NoteRequest{Message: "holding this ring pending a customer blackout"})
+		require.NoError(t, err)
+		require.Equal(t, model.TypeRing, note.ResourceType)
+		require.Equal(t, ring.ID, note.ResourceID)
+		require.Equal(t, token.ID, note.Author)
+		require.NotZero(t, note.CreateAt)
+
+		fetched, err := client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, fetched.Notes, 1)
+		require.Equal(t, note.ID, fetched.Notes[0].ID)
+
+		_, err = client.AddNote(model.TypeInstallationGroup, installationGroup.ID, &model.NoteRequest{Message: "flaky health checks"})
+		require.NoError(t, err)
+		fetchedInstallationGroup, err := client.GetInstallationGroup(installationGroup.ID)
+		require.NoError(t, err)
+		require.Len(t, fetchedInstallationGroup.Notes, 1)
+		require.Equal(t, "flaky health checks", fetchedInstallationGroup.Notes[0].Message)
+
+		_, err = client.AddNote(model.TypeRelease, release.ID, &model.NoteRequest{Message: "ships the hotfix"})
+		require.NoError(t, err)
+		fetchedRelease, err := client.GetRingRelease(release.ID)
+		require.NoError(t, err)
+		require.Len(t, fetchedRelease.Notes, 1)
+
+		notes, err := client.GetNotes(model.TypeRelease, release.ID)
+		require.NoError(t, err)
+		require.Len(t, notes, 1)
+		require.Equal(t, "ships the hotfix", notes[0].Message)
+	})
+
+	t.Run("notes are shown in the timeline", func(t *testing.T) {
+		timeline, err := client.GetRingTimeline(ring.ID, model.DefaultTimelineReleases)
+		require.NoError(t, err)
+		require.Len(t, timeline.Notes, 2)
+		require.ElementsMatch(t, []string{model.TypeRing, model.TypeInstallationGroup}, []string{timeline.Notes[0].ResourceType, timeline.Notes[1].ResourceType})
+	})
+}
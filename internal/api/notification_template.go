@@ -0,0 +1,202 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// initNotificationTemplate registers notification template endpoints on the
+// given router.
+func initNotificationTemplate(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	notificationTemplatesRouter := apiRouter.PathPrefix("/notificationtemplates").Subrouter()
+	notificationTemplatesRouter.Handle("", addContext(handleGetNotificationTemplates)).Methods("GET")
+	notificationTemplatesRouter.Handle("", addContext(validateRequestBody(model.SchemaCreateNotificationTemplateRequest, handleCreateNotificationTemplate))).Methods("POST")
+
+	notificationTemplateRouter := apiRouter.PathPrefix("/notificationtemplate/{notificationtemplate:[A-Za-z0-9]{26}}").Subrouter()
+	notificationTemplateRouter.Handle("", addContext(handleGetNotificationTemplate)).Methods("GET")
+	notificationTemplateRouter.Handle("", addContext(handleDeleteNotificationTemplate)).Methods("DELETE")
+	notificationTemplateRouter.Handle("/update", addContext(validateRequestBody(model.SchemaUpdateNotificationTemplateRequest, handleUpdateNotificationTemplate))).Methods("POST")
+}
+
+// handleCreateNotificationTemplate responds to POST /api/notificationtemplates,
+// creating a new notification template.
+func handleCreateNotificationTemplate(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	createRequest, err := model.NewCreateNotificationTemplateRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode request: %s", err))
+		return
+	}
+
+	existing, err := c.Store.GetNotificationTemplates(&model.NotificationTemplateFilter{
+		Channel: createRequest.Channel,
+		Event:   createRequest.Event,
+		PerPage: model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query notification templates")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query notification templates")
+		return
+	}
+	for _, notificationTemplate := range existing {
+		if strings.EqualFold(notificationTemplate.Language, createRequest.Language) {
+			outputError(c, w, http.StatusConflict, model.ErrorCodeConflict, fmt.Sprintf("notification template %s already exists for %s %s in %s", notificationTemplate.ID, createRequest.Channel, createRequest.Event, notificationTemplate.Language))
+			return
+		}
+	}
+
+	notificationTemplate := model.NotificationTemplate{
+		Channel:  createRequest.Channel,
+		Event:    createRequest.Event,
+		Language: createRequest.Language,
+		Subject:  createRequest.Subject,
+		Body:     createRequest.Body,
+	}
+	if err = c.Store.CreateNotificationTemplate(&notificationTemplate); err != nil {
+		c.Logger.WithError(err).Error("failed to create notification template")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to create notification template")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, notificationTemplate)
+}
+
+// handleGetNotificationTemplates responds to GET /api/notificationtemplates,
+// returning the specified page of notification templates.
+func handleGetNotificationTemplates(c *Context, w http.ResponseWriter, r *http.Request) {
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to parse paging parameters: %s", err))
+		return
+	}
+
+	notificationTemplates, err := c.Store.GetNotificationTemplates(&model.NotificationTemplateFilter{
+		Channel:  r.URL.Query().Get("channel"),
+		Event:    r.URL.Query().Get("event"),
+		Language: r.URL.Query().Get("language"),
+		Page:     page,
+		PerPage:  perPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query notification templates")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query notification templates")
+		return
+	}
+	if notificationTemplates == nil {
+		notificationTemplates = []*model.NotificationTemplate{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, notificationTemplates)
+}
+
+// getNotificationTemplate returns the notification template of the request,
+// writing the error if it cannot be found.
+func getNotificationTemplate(c *Context, w http.ResponseWriter, r *http.Request) (*model.NotificationTemplate, bool) {
+	notificationTemplateID := mux.Vars(r)["notificationtemplate"]
+	c.Logger = c.Logger.WithField("notificationtemplate", notificationTemplateID)
+
+	notificationTemplate, err := c.Store.GetNotificationTemplate(notificationTemplateID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query notification template")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query notification template")
+		return nil, false
+	}
+	if notificationTemplate == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "notification template not found")
+		return nil, false
+	}
+
+	return notificationTemplate, true
+}
+
+// handleGetNotificationTemplate responds to GET
+// /api/notificationtemplate/{notificationtemplate}, returning the notification
+// template in question.
+func handleGetNotificationTemplate(c *Context, w http.ResponseWriter, r *http.Request) {
+	notificationTemplate, ok := getNotificationTemplate(c, w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, notificationTemplate)
+}
+
+// handleUpdateNotificationTemplate responds to POST
+// /api/notificationtemplate/{notificationtemplate}/update, updating the
+// subject or body of the notification template.
+func handleUpdateNotificationTemplate(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	notificationTemplate, ok := getNotificationTemplate(c, w, r)
+	if !ok {
+		return
+	}
+
+	updateRequest, err := model.NewUpdateNotificationTemplateRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode request: %s", err))
+		return
+	}
+	if err = updateRequest.Apply(notificationTemplate); err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("invalid notification template: %s", err))
+		return
+	}
+
+	if err = c.Store.UpdateNotificationTemplate(notificationTemplate); err != nil {
+		c.Logger.WithError(err).Error("failed to update notification template")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update notification template")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, notificationTemplate)
+}
+
+// handleDeleteNotificationTemplate responds to DELETE
+// /api/notificationtemplate/{notificationtemplate}, deleting the notification
+// template. Its notifications are sent with the built-in text again.
+func handleDeleteNotificationTemplate(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	notificationTemplate, ok := getNotificationTemplate(c, w, r)
+	if !ok {
+		return
+	}
+
+	if err := c.Store.DeleteNotificationTemplate(notificationTemplate.ID); err != nil {
+		c.Logger.WithError(err).Error("failed to delete notification template")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to delete notification template")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationTemplates(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	newTokenClient := func(name, role string) *model.Client {
+		secret, err := model.NewTokenSecret()
+		require.NoError(t, err)
+		token := &model.Token{Name: name, Role: role, TokenHash: model.HashTokenSecret(secret)}
+		require.NoError(t, sqlStore.CreateToken(token))
+		return model.NewClientWithToken(ts.URL, secret)
+	}
+	writeClient := newTokenClient("alice", model.TokenRoleWrite)
+	adminClient := newTokenClient("admin", model.TokenRoleAdmin)
+
+	createRequest := &model.CreateNotificationTemplateRequest{
+		Channel:  model.NotificationChannelEmail,
+		Event:    model.NotificationReleaseFailed,
+		Language: "de",
+		Subject:  "[Elrond] Release von {{.RingName}} fehlgeschlagen",
+		Body:     "{{.Release}} ist auf {{.RingName}} fehlgeschlagen.",
+	}
+
+	t.Run("no templates", func(t *testing.T) {
+		notificationTemplates, err := writeClient.GetNotificationTemplates(&model.GetNotificationTemplatesRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Empty(t, notificationTemplates)
+
+		notificationTemplate, err := writeClient.GetNotificationTemplate(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, notificationTemplate)
+	})
+
+	t.Run("creating requires an admin token", func(t *testing.T) {
+		_, err := writeClient.CreateNotificationTemplate(createRequest)
+		requireAPIError(t, err, 403)
+	})
+
+	var notificationTemplate *model.NotificationTemplate
+	t.Run("create", func(t *testing.T) {
+		var err error
+		notificationTemplate, err = adminClient.CreateNotificationTemplate(createRequest)
+		require.NoError(t, err)
+		require.NotEmpty(t, notificationTemplate.ID)
+		require.Equal(t, "de", notificationTemplate.Language)
+		require.Equal(t, createRequest.Body, notificationTemplate.Body)
+
+		fetched, err := writeClient.GetNotificationTemplate(notificationTemplate.ID)
+		require.NoError(t, err)
+		require.Equal(t, notificationTemplate, fetched)
+	})
+
+	t.Run("duplicate language", func(t *testing.T) {
+		duplicate := *createRequest
+		duplicate.Language = "DE"
+		_, err := adminClient.CreateNotificationTemplate(&duplicate)
+		requireAPIError(t, err, 409)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		teams, err := adminClient.CreateNotificationTemplate(&model.CreateNotificationTemplateRequest{
+			Channel:  model.NotificationChannelTeams,
+			Event:    model.NotificationStateChange,
+			Language: "de",
+			Body:     "{{.Type}} {{.ID}} ist jetzt {{.NewState}}",
+		})
+		require.NoError(t, err)
+
+		notificationTemplates, err := writeClient.GetNotificationTemplates(&model.GetNotificationTemplatesRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.NotificationTemplate{notificationTemplate, teams}, notificationTemplates)
+
+		notificationTemplates, err = writeClient.GetNotificationTemplates(&model.GetNotificationTemplatesRequest{Channel: model.NotificationChannelTeams, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.NotificationTemplate{teams}, notificationTemplates)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		body := "{{.Release}} auf {{.RingName}}: {{.NewState}}"
+		_, err := writeClient.UpdateNotificationTemplate(notificationTemplate.ID, &model.UpdateNotificationTemplateRequest{Body: &body})
+		requireAPIError(t, err, 403)
+
+		updated, err := adminClient.UpdateNotificationTemplate(notificationTemplate.ID, &model.UpdateNotificationTemplateRequest{Body: &body})
+		require.NoError(t, err)
+		require.Equal(t, body, updated.Body)
+		require.Equal(t, createRequest.Subject, updated.Subject)
+
+		invalid := "{{.Unknown}}"
+		_, err = adminClient.UpdateNotificationTemplate(notificationTemplate.ID, &model.UpdateNotificationTemplateRequest{Body: &invalid})
+		requireAPIError(t, err, 400)
+
+		_, err = adminClient.UpdateNotificationTemplate(model.NewID(), &model.UpdateNotificationTemplateRequest{Body: &body})
+		requireAPIError(t, err, 404)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := writeClient.DeleteNotificationTemplate(notificationTemplate.ID)
+		requireAPIError(t, err, 403)
+
+		require.NoError(t, adminClient.DeleteNotificationTemplate(notificationTemplate.ID))
+
+		fetched, err := writeClient.GetNotificationTemplate(notificationTemplate.ID)
+		require.NoError(t, err)
+		require.Nil(t, fetched)
+	})
+}
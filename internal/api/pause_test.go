@@ -0,0 +1,178 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseRing(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring1, err := client.CreateRing(&model.CreateRingRequest{
+		Priority:          1,
+		InstallationGroup: &model.InstallationGroup{Name: "prod-1"},
+	})
+	require.NoError(t, err)
+	ring2, err := client.CreateRing(&model.CreateRingRequest{
+		Priority:          2,
+		InstallationGroup: &model.InstallationGroup{Name: "prod-2"},
+	})
+	require.NoError(t, err)
+
+	t.Run("unknown ring", func(t *testing.T) {
+		_, err := client.PauseRing(model.NewID())
+		requireAPIError(t, err, http.StatusNotFound)
+
+		_, err = client.ResumeRing(model.NewID())
+		requireAPIError(t, err, http.StatusNotFound)
+	})
+
+	t.Run("stable ring", func(t *testing.T) {
+		ring1.State = model.RingStateStable
+		require.NoError(t, sqlStore.UpdateRing(ring1))
+
+		_, err := client.PauseRing(ring1.ID)
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		_, err = client.ResumeRing(ring1.ID)
+		requireAPIError(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("soaking ring", func(t *testing.T) {
+		soakStart := time.Now().Add(-time.Minute).UnixNano()
+		ring1.State = model.RingStateSoakingRequested
+		ring1.ReleaseAt = soakStart
+		require.NoError(t, sqlStore.UpdateRing(ring1))
+
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(ring1.ID)
+		require.NoError(t, err)
+		require.Len(t, installationGroups, 1)
+		installationGroup := installationGroups[0]
+		installationGroup.State = model.InstallationGroupReleaseSoakingRequested
+		installationGroup.ReleaseAt = soakStart
+		require.NoError(t, sqlStore.UpdateInstallationGroup(installationGroup))
+
+		ring, err := client.PauseRing(ring1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePaused, ring.State)
+		require.Equal(t, model.RingStateSoakingRequested, ring.PausedState)
+
+		_, err = client.PauseRing(ring1.ID)
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		_, err = client.ReleaseRing(ring1.ID, &model.RingReleaseRequest{
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Version: "7.0.1",
+		})
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		ring2.State = model.RingStateReleasePending
+		require.NoError(t, sqlStore.UpdateRing(ring2))
+		blockers, err := client.GetRingBlockers(ring2.ID)
+		require.NoError(t, err)
+		require.Len(t, blockers.Blockers, 1)
+		require.Equal(t, model.ReleaseBlockerRingReleasing, blockers.Blockers[0].Reason)
+		require.Equal(t, ring1.ID, blockers.Blockers[0].RingID)
+		ring2.State = model.RingStateStable
+		require.NoError(t, sqlStore.UpdateRing(ring2))
+
+		require.NoError(t, client.CancelRelease())
+		ring, err = client.GetRing(ring1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePaused, ring.State)
+
+		ring, err = client.ResumeRing(ring1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateSoakingRequested, ring.State)
+		require.Empty(t, ring.PausedState)
+		require.Greater(t, ring.ReleaseAt, soakStart)
+
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		require.Equal(t, ring.ReleaseAt-soakStart, installationGroup.ReleaseAt-soakStart)
+	})
+
+	t.Run("all rings", func(t *testing.T) {
+		ring1.State = model.RingStateReleaseInProgress
+		require.NoError(t, sqlStore.UpdateRing(ring1))
+		ring2.State = model.RingStateReleasePending
+		require.NoError(t, sqlStore.UpdateRing(ring2))
+
+		require.NoError(t, client.PauseRelease())
+		for _, ringID := range []string{ring1.ID, ring2.ID} {
+			ring, err := client.GetRing(ringID)
+			require.NoError(t, err)
+			require.Equal(t, model.RingStateReleasePaused, ring.State)
+		}
+
+		resumed, err := client.ResumeRelease()
+		require.NoError(t, err)
+		require.Len(t, resumed, 2)
+		ring, err := client.GetRing(ring1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseInProgress, ring.State)
+		ring, err = client.GetRing(ring2.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePending, ring.State)
+	})
+
+	t.Run("all rings with a ring under lock", func(t *testing.T) {
+		lockerID := model.NewID()
+		locked, err := sqlStore.LockRing(ring1.ID, lockerID)
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		err = client.PauseRelease()
+		requireAPIError(t, err, http.StatusConflict)
+
+		ring, err := client.GetRing(ring2.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePaused, ring.State)
+
+		locked, err = sqlStore.LockRing(ring2.ID, lockerID)
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		// Rings under lock are left paused.
+		_, err = client.ResumeRelease()
+		require.NoError(t, err)
+		ring, err = client.GetRing(ring2.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePaused, ring.State)
+
+		unlocked, err := sqlStore.UnlockRing(ring2.ID, lockerID, false)
+		require.NoError(t, err)
+		require.True(t, unlocked)
+
+		_, err = client.ResumeRelease()
+		require.NoError(t, err)
+		ring, err = client.GetRing(ring2.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePending, ring.State)
+	})
+}
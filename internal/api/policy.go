@@ -0,0 +1,73 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// authorizePolicy evaluates the authorization policy, if any, on a request
+// changing elrond, with its token and the ring it changes, if any. Requests
+// are denied when the policy cannot be evaluated. It writes an error response
+// and returns false if the request must not be handled.
+func authorizePolicy(c *Context, w http.ResponseWriter, r *http.Request) bool {
+	if c.Policy == nil {
+		return true
+	}
+
+	vars := mux.Vars(r)
+	input := &model.PolicyInput{
+		Action: model.PolicyActionRequest,
+		Time:   time.Now().UTC().Format(time.RFC3339),
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Vars:   vars,
+	}
+	if c.TokenID != "" {
+		input.Token = &model.PolicyToken{
+			ID:       c.TokenID,
+			Name:     c.TokenName,
+			Role:     c.TokenRole,
+			TenantID: c.TenantID,
+		}
+	}
+
+	var ring *model.Ring
+	var err error
+	switch {
+	case vars["ring"] != "":
+		ring, err = c.Store.GetRing(vars["ring"])
+	case vars["installationgroup"] != "":
+		ring, err = c.Store.GetRingFromInstallationGroupID(vars["installationgroup"])
+	}
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+		return false
+	}
+	input.Ring = model.NewPolicyRing(ring)
+
+	decision, err := c.Policy.Evaluate(input)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to evaluate authorization policy")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to evaluate authorization policy")
+		return false
+	}
+	if !decision.Allow {
+		message := "request denied by the authorization policy"
+		if decision.Reason != "" {
+			message += ": " + decision.Reason
+		}
+		c.Logger.WithField("reason", decision.Reason).Warn("request denied by the authorization policy")
+		outputError(c, w, http.StatusForbidden, model.ErrorCodePolicyDenied, message)
+		return false
+	}
+
+	return true
+}
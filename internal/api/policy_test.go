@@ -0,0 +1,120 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// mockPolicyEngine allows the requests of tokens named ci, and records the
+// inputs it is evaluated on.
+type mockPolicyEngine struct {
+	Err    error
+	Inputs []*model.PolicyInput
+}
+
+func (e *mockPolicyEngine) Evaluate(input *model.PolicyInput) (*model.PolicyDecision, error) {
+	e.Inputs = append(e.Inputs, input)
+	if e.Err != nil {
+		return nil, e.Err
+	}
+	if input.Token == nil || input.Token.Name != "ci" {
+		return &model.PolicyDecision{Reason: "changes only from CI"}, nil
+	}
+
+	return &model.PolicyDecision{Allow: true}, nil
+}
+
+func TestAuthorizationPolicy(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	policy := &mockPolicyEngine{}
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+		Policy:     policy,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	newTokenClient := func(name string) *model.Client {
+		secret, err := model.NewTokenSecret()
+		require.NoError(t, err)
+		token := &model.Token{Name: name, Role: model.TokenRoleWrite, TokenHash: model.HashTokenSecret(secret)}
+		require.NoError(t, sqlStore.CreateToken(token))
+		return model.NewClientWithToken(ts.URL, secret)
+	}
+	ciClient := newTokenClient("ci")
+	userClient := newTokenClient("alice")
+
+	ring, err := ciClient.CreateRing(&model.CreateRingRequest{Name: "production", Priority: 1})
+	require.NoError(t, err)
+	ring.State = model.RingStateStable
+	require.NoError(t, sqlStore.UpdateRing(ring))
+
+	release := &model.RingReleaseRequest{
+		Image:   "mattermost/mattermost-enterprise-edition",
+		Version: "7.0.1",
+	}
+
+	t.Run("requests making no changes are not evaluated", func(t *testing.T) {
+		policy.Inputs = nil
+
+		_, err := userClient.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Empty(t, policy.Inputs)
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		policy.Inputs = nil
+
+		_, err := userClient.ReleaseRing(ring.ID, release)
+		apiErr := requireAPIError(t, err, http.StatusForbidden)
+		require.Equal(t, model.ErrorCodePolicyDenied, apiErr.Code)
+		require.Contains(t, apiErr.Message, "changes only from CI")
+
+		require.Len(t, policy.Inputs, 1)
+		input := policy.Inputs[0]
+		require.Equal(t, model.PolicyActionRequest, input.Action)
+		require.Equal(t, http.MethodPost, input.Method)
+		require.Equal(t, "/api/v1/ring/"+ring.ID+"/release", input.Path)
+		require.Equal(t, ring.ID, input.Vars["ring"])
+		require.Equal(t, "alice", input.Token.Name)
+		require.Equal(t, model.TokenRoleWrite, input.Token.Role)
+		require.Equal(t, "production", input.Ring.Name)
+
+		ring, err = ciClient.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, ring.State)
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		ring, err := ciClient.ReleaseRing(ring.ID, release)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePending, ring.State)
+	})
+
+	t.Run("policy unavailable", func(t *testing.T) {
+		policy.Err = errors.New("connection refused")
+		defer func() { policy.Err = nil }()
+
+		_, err := ciClient.CreateRing(&model.CreateRingRequest{Name: "staging", Priority: 2})
+		requireAPIError(t, err, http.StatusInternalServerError)
+	})
+}
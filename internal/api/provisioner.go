@@ -0,0 +1,145 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// initProvisioner registers provisioner endpoints on the given router.
+func initProvisioner(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	provisionerRouter := apiRouter.PathPrefix("/provisioner").Subrouter()
+	provisionerRouter.Handle("/credentials", addContext(handleGetProvisionerCredentials)).Methods("GET")
+	provisionerRouter.Handle("/credentials", addContext(validateRequestBody(model.SchemaRotateProvisionerCredentialsRequest, handleRotateProvisionerCredentials))).Methods("POST")
+	provisionerRouter.Handle("/callback", addContext(validateRequestBody(model.SchemaProvisionerCallback, handleProvisionerCallback))).Methods("POST")
+}
+
+// handleGetProvisionerCredentials responds to GET /api/provisioner/credentials,
+// returning the metadata of the provisioner credentials in use.
+func handleGetProvisionerCredentials(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	credentials, err := c.Store.GetLatestProvisionerCredentials()
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query provisioner credentials")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query provisioner credentials")
+		return
+	}
+	if credentials == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "provisioner credentials not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, credentials)
+}
+
+// handleRotateProvisionerCredentials responds to POST /api/provisioner/credentials,
+// storing new provisioner credentials and applying them without a restart.
+func handleRotateProvisionerCredentials(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	if c.CredentialsEncrypter == nil {
+		c.Logger.Warn("unable to rotate provisioner credentials without an encryption key")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "provisioner credentials storage is not configured on this server")
+		return
+	}
+
+	rotateRequest, err := model.NewRotateProvisionerCredentialsRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode request: %s", err))
+		return
+	}
+
+	if c.CredentialsRotationLimiter != nil && !c.CredentialsRotationLimiter.Allow() {
+		c.Logger.Warn("provisioner credentials rotation rate limit exceeded")
+		outputError(c, w, http.StatusTooManyRequests, model.ErrorCodeLimitExceeded, "provisioner credentials were rotated too recently, try again later")
+		return
+	}
+
+	plaintext, err := json.Marshal(rotateRequest.Headers)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to encode provisioner credentials")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to encode provisioner credentials")
+		return
+	}
+	encryptedHeaders, err := c.CredentialsEncrypter.Encrypt(plaintext)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to encrypt provisioner credentials")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to encrypt provisioner credentials")
+		return
+	}
+
+	credentials := model.ProvisionerCredentials{EncryptedHeaders: encryptedHeaders}
+	if err = c.Store.CreateProvisionerCredentials(&credentials); err != nil {
+		c.Logger.WithError(err).Error("failed to store provisioner credentials")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to store provisioner credentials")
+		return
+	}
+
+	c.Elrond.SetProvisionerCredentials(credentials.ID, rotateRequest.Headers)
+	c.Logger.WithField("credentials", credentials.ID).Info("Rotated provisioner credentials")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, credentials)
+}
+
+// handleProvisionerCallback responds to POST /api/provisioner/callback,
+// recording the status the provisioner reports for a group update and waking
+// up the release waiting on it. Only admin tokens may report on groups.
+func handleProvisionerCallback(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	callback, err := model.NewProvisionerCallbackFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode request: %s", err))
+		return
+	}
+	c.Logger = c.Logger.WithField("provisionergroup", callback.ProvisionerGroupID)
+
+	installationGroups, err := c.Store.GetInstallationGroupsByProvisionerGroupID(callback.ProvisionerGroupID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation groups")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query installation groups")
+		return
+	}
+	if len(installationGroups) == 0 {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, fmt.Sprintf("no installation group found for provisioner group %s", callback.ProvisionerGroupID))
+		return
+	}
+
+	for _, installationGroup := range installationGroups {
+		if err = c.Store.UpdateInstallationGroupReleaseProgress(installationGroup.ID, callback.Progress); err != nil {
+			c.Logger.WithError(err).Error("failed to update installation group release progress")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update installation group release progress")
+			return
+		}
+	}
+
+	c.Logger.Debugf("Received %s callback from the provisioner (%d%%)", callback.Status, callback.Progress)
+	c.Elrond.HandleGroupCallback(callback)
+	c.Supervisor.Do()
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/secrets"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+type mockElrond struct {
+	credentialsID string
+	headers       map[string]string
+	callbacks     []*model.ProvisionerCallback
+	// rejectedGroups maps the provisioner groups rejecting releases to the
+	// reason they are rejected.
+	rejectedGroups  map[string]string
+	validationError error
+}
+
+func (e *mockElrond) SetProvisionerCredentials(credentialsID string, headers map[string]string) {
+	e.credentialsID = credentialsID
+	e.headers = headers
+}
+
+func (e *mockElrond) HandleGroupCallback(callback *model.ProvisionerCallback) {
+	e.callbacks = append(e.callbacks, callback)
+}
+
+func (e *mockElrond) ValidateRelease(installationGroup *model.InstallationGroup, image, version string) (*model.ReleaseValidation, error) {
+	if e.validationError != nil {
+		return nil, e.validationError
+	}
+
+	validation := &model.ReleaseValidation{
+		InstallationGroup:  installationGroup.Name,
+		ProvisionerGroupID: installationGroup.ProvisionerGroupID,
+		Image:              image,
+		Version:            version,
+		Accepted:           true,
+	}
+	if reason, ok := e.rejectedGroups[installationGroup.ProvisionerGroupID]; ok {
+		validation.Accepted = false
+		validation.Reasons = []string{reason}
+	}
+
+	return validation, nil
+}
+
+func TestProvisionerCredentials(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	cipher, err := secrets.NewCipher(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", secrets.KeySize))))
+	require.NoError(t, err)
+	elrond := &mockElrond{}
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:                      sqlStore,
+		Supervisor:                 &mockSupervisor{},
+		Elrond:                     elrond,
+		Logger:                     logger,
+		CredentialsEncrypter:       cipher,
+		CredentialsRotationLimiter: rate.NewLimiter(rate.Every(time.Hour), 1),
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := newAdminClient(t, sqlStore, ts.URL)
+
+	t.Run("no credentials yet", func(t *testing.T) {
+		credentials, err := client.GetProvisionerCredentials()
+		require.NoError(t, err)
+		require.Nil(t, credentials)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := client.RotateProvisionerCredentials(&model.RotateProvisionerCredentialsRequest{})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		headers := map[string]string{"Authorization": "Bearer provisioner"}
+		credentials, err := client.RotateProvisionerCredentials(&model.RotateProvisionerCredentialsRequest{
+			Headers: headers,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, credentials.ID)
+		require.Equal(t, credentials.ID, elrond.credentialsID)
+		require.Equal(t, headers, elrond.headers)
+
+		stored, err := sqlStore.GetLatestProvisionerCredentials()
+		require.NoError(t, err)
+		require.NotContains(t, string(stored.EncryptedHeaders), "Bearer provisioner")
+		plaintext, err := cipher.Decrypt(stored.EncryptedHeaders)
+		require.NoError(t, err)
+		var storedHeaders map[string]string
+		require.NoError(t, json.Unmarshal(plaintext, &storedHeaders))
+		require.Equal(t, headers, storedHeaders)
+
+		current, err := client.GetProvisionerCredentials()
+		require.NoError(t, err)
+		require.Equal(t, credentials.ID, current.ID)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		_, err := client.RotateProvisionerCredentials(&model.RotateProvisionerCredentialsRequest{
+			Headers: map[string]string{"Authorization": "Bearer other"},
+		})
+		apiErr := requireAPIError(t, err, 429)
+		require.Equal(t, model.ErrorCodeLimitExceeded, apiErr.Code)
+	})
+}
+
+func TestProvisionerCallback(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	elrond := &mockElrond{}
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Elrond:     elrond,
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := newAdminClient(t, sqlStore, ts.URL)
+
+	installationGroup := &model.InstallationGroup{Name: "callback-ig", ProvisionerGroupID: "callback-group"}
+	require.NoError(t, sqlStore.CreateInstallationGroup(installationGroup))
+
+	t.Run("unauthenticated callback", func(t *testing.T) {
+		err := model.NewClient(ts.URL).SendProvisionerCallback(&model.ProvisionerCallback{ProvisionerGroupID: "callback-group", Status: model.ProvisionerCallbackProgress, Progress: 30})
+		requireAPIError(t, err, 401)
+		require.Empty(t, elrond.callbacks)
+
+		updated, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		require.Zero(t, updated.ReleaseProgress)
+	})
+
+	t.Run("write token callback", func(t *testing.T) {
+		writeClient := model.NewClientWithToken(ts.URL, createTokenSecret(t, sqlStore, model.TokenRoleWrite, ""))
+		err := writeClient.SendProvisionerCallback(&model.ProvisionerCallback{ProvisionerGroupID: "callback-group", Status: model.ProvisionerCallbackProgress, Progress: 30})
+		requireAPIError(t, err, 403)
+		require.Empty(t, elrond.callbacks)
+	})
+
+	t.Run("invalid callback", func(t *testing.T) {
+		err := client.SendProvisionerCallback(&model.ProvisionerCallback{ProvisionerGroupID: "callback-group", Status: "unknown"})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("unknown provisioner group", func(t *testing.T) {
+		err := client.SendProvisionerCallback(&model.ProvisionerCallback{ProvisionerGroupID: "unknown", Status: model.ProvisionerCallbackStarted})
+		requireAPIError(t, err, 404)
+		require.Empty(t, elrond.callbacks)
+	})
+
+	t.Run("progress", func(t *testing.T) {
+		callback := &model.ProvisionerCallback{ProvisionerGroupID: "callback-group", Status: model.ProvisionerCallbackProgress, Progress: 30}
+		require.NoError(t, client.SendProvisionerCallback(callback))
+		require.Equal(t, []*model.ProvisionerCallback{callback}, elrond.callbacks)
+
+		updated, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		require.Equal(t, 30, updated.ReleaseProgress)
+	})
+
+	t.Run("completed", func(t *testing.T) {
+		require.NoError(t, client.SendProvisionerCallback(&model.ProvisionerCallback{ProvisionerGroupID: "callback-group", Status: model.ProvisionerCallbackCompleted}))
+		require.Len(t, elrond.callbacks, 2)
+
+		updated, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		require.Equal(t, 100, updated.ReleaseProgress)
+	})
+}
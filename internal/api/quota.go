@@ -0,0 +1,159 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mattermost/elrond/model"
+)
+
+// tenantQuota returns the quota of the given tenant, and whether the request
+// is subject to it. Resources without a tenant and requests authenticated
+// with an admin token are not subject to any quota.
+func tenantQuota(c *Context, tenantID string) (model.TenantQuota, bool) {
+	if tenantID == "" || c.TokenRole == model.TokenRoleAdmin {
+		return model.TenantQuota{}, false
+	}
+
+	return c.TenantQuotas.For(tenantID), true
+}
+
+// outputQuotaExceeded writes the error of a request exceeding the given quota
+// of a tenant.
+func outputQuotaExceeded(c *Context, w http.ResponseWriter, statusCode int, tenantID, quota string, limit int) {
+	message := fmt.Sprintf("tenant %s has reached its %s quota of %d", tenantID, quota, limit)
+	c.Logger.Warn(message)
+	outputErrorWithDetails(c, w, statusCode, model.ErrorCodeLimitExceeded, message, map[string]string{
+		"tenant": tenantID,
+		"quota":  quota,
+		"limit":  strconv.Itoa(limit),
+	})
+}
+
+// checkRingsQuota returns whether the tenant of the request can create
+// another ring, writing the error otherwise.
+func checkRingsQuota(c *Context, w http.ResponseWriter) bool {
+	quota, ok := tenantQuota(c, c.TenantID)
+	if !ok || quota.MaxRings == 0 {
+		return true
+	}
+
+	rings, err := c.Store.GetRings(&model.RingFilter{TenantID: c.TenantID, PerPage: model.AllPerPage})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query rings of tenant")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings of tenant")
+		return false
+	}
+	if len(rings) >= quota.MaxRings {
+		outputQuotaExceeded(c, w, http.StatusForbidden, c.TenantID, model.TenantQuotaMaxRings, quota.MaxRings)
+		return false
+	}
+
+	return true
+}
+
+// checkInstallationGroupsQuota returns whether another installation group can
+// be registered to the given ring, writing the error otherwise.
+func checkInstallationGroupsQuota(c *Context, w http.ResponseWriter, ring *model.Ring) bool {
+	quota, ok := tenantQuota(c, ring.TenantID)
+	if !ok || quota.MaxInstallationGroupsPerRing == 0 {
+		return true
+	}
+
+	installationGroups, err := c.Store.GetInstallationGroupsForRing(ring.ID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get ring installation groups")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get ring installation groups")
+		return false
+	}
+	if len(installationGroups) >= quota.MaxInstallationGroupsPerRing {
+		outputQuotaExceeded(c, w, http.StatusForbidden, ring.TenantID, model.TenantQuotaMaxInstallationGroupsPerRing, quota.MaxInstallationGroupsPerRing)
+		return false
+	}
+
+	return true
+}
+
+// checkConcurrentReleasesQuota returns whether the given rings can start a
+// release without their tenants exceeding their concurrent releases quota,
+// writing the error otherwise. Rings already releasing are not counted twice.
+func checkConcurrentReleasesQuota(c *Context, w http.ResponseWriter, rings []*model.Ring) bool {
+	releasingByTenant := make(map[string]map[string]bool)
+	for _, ring := range rings {
+		if _, ok := tenantQuota(c, ring.TenantID); !ok {
+			continue
+		}
+		if releasingByTenant[ring.TenantID] == nil {
+			releasingByTenant[ring.TenantID] = make(map[string]bool)
+		}
+		releasingByTenant[ring.TenantID][ring.ID] = true
+	}
+
+	for tenantID, releasing := range releasingByTenant {
+		quota, _ := tenantQuota(c, tenantID)
+		if quota.MaxConcurrentReleases == 0 {
+			continue
+		}
+
+		tenantRings, err := c.Store.GetRings(&model.RingFilter{TenantID: tenantID, PerPage: model.AllPerPage})
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query rings of tenant")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings of tenant")
+			return false
+		}
+		for _, ring := range tenantRings {
+			if ringReleaseActive(ring) {
+				releasing[ring.ID] = true
+			}
+		}
+		if len(releasing) > quota.MaxConcurrentReleases {
+			outputQuotaExceeded(c, w, http.StatusTooManyRequests, tenantID, model.TenantQuotaMaxConcurrentReleases, quota.MaxConcurrentReleases)
+			return false
+		}
+	}
+
+	return true
+}
+
+// ringReleaseActive returns whether the given ring has a release pending or in
+// progress, paused or not.
+func ringReleaseActive(ring *model.Ring) bool {
+	for _, states := range [][]string{model.AllRingStatesReleasePending, model.AllRingStatesReleaseInProgress} {
+		for _, state := range states {
+			if ring.State == state {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// checkWebhooksQuota returns whether another webhook can be registered for the
+// given owner, writing the error otherwise. The webhooks of a tenant are the
+// ones it owns, so the quota applies to every owner, whether or not the
+// request has a tenant.
+func checkWebhooksQuota(c *Context, w http.ResponseWriter, ownerID string) bool {
+	quota, ok := tenantQuota(c, ownerID)
+	if !ok || quota.MaxWebhooks == 0 {
+		return true
+	}
+
+	webhooks, err := c.Store.GetWebhooks(&model.WebhookFilter{OwnerID: ownerID, PerPage: model.AllPerPage})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhooks for owner")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query webhooks for owner")
+		return false
+	}
+	if len(webhooks) >= quota.MaxWebhooks {
+		outputQuotaExceeded(c, w, http.StatusForbidden, ownerID, model.TenantQuotaMaxWebhooks, quota.MaxWebhooks)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantQuotas(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	quotas, err := model.NewTenantQuotas(model.TenantQuota{
+		MaxRings:                     2,
+		MaxInstallationGroupsPerRing: 2,
+		MaxConcurrentReleases:        1,
+		MaxWebhooks:                  1,
+	}, map[string]int{"tenant2.max-rings": 3})
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:        sqlStore,
+		Supervisor:   &mockSupervisor{},
+		Logger:       logger,
+		TenantQuotas: quotas,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client1 := newTenantClient(t, sqlStore, ts.URL, "tenant1")
+	client2 := newTenantClient(t, sqlStore, ts.URL, "tenant2")
+
+	adminSecret, err := model.NewTokenSecret()
+	require.NoError(t, err)
+	require.NoError(t, sqlStore.CreateToken(&model.Token{
+		Name:      "admin",
+		TenantID:  "tenant1",
+		Role:      model.TokenRoleAdmin,
+		TokenHash: model.HashTokenSecret(adminSecret),
+	}))
+	adminClient := model.NewClientWithToken(ts.URL, adminSecret)
+
+	requireQuotaError := func(t *testing.T, err error, status int, quota string) {
+		apiErr := requireAPIError(t, err, status)
+		require.Equal(t, model.ErrorCodeLimitExceeded, apiErr.Code)
+		require.Equal(t, quota, apiErr.Details["quota"])
+	}
+
+	var rings []*model.Ring
+	t.Run("max rings", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			ring, err := client1.CreateRing(&model.CreateRingRequest{Priority: i + 1})
+			require.NoError(t, err)
+			require.Equal(t, "tenant1", ring.TenantID)
+			rings = append(rings, ring)
+		}
+
+		_, err := client1.CreateRing(&model.CreateRingRequest{Priority: 3})
+		requireQuotaError(t, err, http.StatusForbidden, model.TenantQuotaMaxRings)
+
+		t.Run("tenant override", func(t *testing.T) {
+			for i := 0; i < 3; i++ {
+				_, err := client2.CreateRing(&model.CreateRingRequest{Priority: i + 10})
+				require.NoError(t, err)
+			}
+			_, err := client2.CreateRing(&model.CreateRingRequest{Priority: 13})
+			requireQuotaError(t, err, http.StatusForbidden, model.TenantQuotaMaxRings)
+		})
+
+		t.Run("requests without tenant", func(t *testing.T) {
+			ring, err := model.NewClient(ts.URL).CreateRing(&model.CreateRingRequest{Priority: 20})
+			require.NoError(t, err)
+			require.Empty(t, ring.TenantID)
+		})
+
+		t.Run("admin override", func(t *testing.T) {
+			ring, err := adminClient.CreateRing(&model.CreateRingRequest{Priority: 21})
+			require.NoError(t, err)
+			require.Equal(t, "tenant1", ring.TenantID)
+			require.NoError(t, sqlStore.DeleteRing(ring.ID))
+		})
+	})
+
+	t.Run("max installation groups per ring", func(t *testing.T) {
+		for _, name := range []string{"group-1", "group-2"} {
+			_, err := client1.RegisterRingInstallationGroup(rings[0].ID, &model.RegisterInstallationGroupRequest{Name: name})
+			require.NoError(t, err)
+		}
+
+		_, err := client1.RegisterRingInstallationGroup(rings[0].ID, &model.RegisterInstallationGroupRequest{Name: "group-3"})
+		requireQuotaError(t, err, http.StatusForbidden, model.TenantQuotaMaxInstallationGroupsPerRing)
+
+		_, err = adminClient.RegisterRingInstallationGroup(rings[0].ID, &model.RegisterInstallationGroupRequest{Name: "group-3"})
+		require.NoError(t, err)
+	})
+
+	t.Run("max concurrent releases", func(t *testing.T) {
+		for _, ring := range rings {
+			ring.State = model.RingStateStable
+			require.NoError(t, sqlStore.UpdateRing(ring))
+		}
+		release := &model.RingReleaseRequest{
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Version: "7.0.1",
+		}
+
+		_, err := client1.ReleaseRing(rings[0].ID, release)
+		require.NoError(t, err)
+
+		_, err = client1.ReleaseRing(rings[1].ID, release)
+		requireQuotaError(t, err, http.StatusTooManyRequests, model.TenantQuotaMaxConcurrentReleases)
+
+		t.Run("releasing ring is not counted twice", func(t *testing.T) {
+			ring, err := client1.ReleaseRing(rings[0].ID, release)
+			require.NoError(t, err)
+			require.Equal(t, model.RingStateReleasePending, ring.State)
+		})
+
+		t.Run("admin override", func(t *testing.T) {
+			_, err := adminClient.ReleaseRing(rings[1].ID, release)
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("max webhooks", func(t *testing.T) {
+		_, err := client1.CreateWebhook(&model.CreateWebhookRequest{URL: "https://tenant1.com/1"})
+		require.NoError(t, err)
+
+		_, err = client1.CreateWebhook(&model.CreateWebhookRequest{URL: "https://tenant1.com/2"})
+		requireQuotaError(t, err, http.StatusForbidden, model.TenantQuotaMaxWebhooks)
+
+		_, err = client2.CreateWebhook(&model.CreateWebhookRequest{URL: "https://tenant2.com/1"})
+		require.NoError(t, err)
+	})
+}
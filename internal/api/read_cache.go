@@ -0,0 +1,138 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxReadCacheEntries bounds the number of responses kept by a ReadCache.
+const maxReadCacheEntries = 1024
+
+// ReadCache caches the responses of hot read endpoints, such as ring and
+// installation group GETs, for a short time, so that dashboards polling the
+// API do not each query the database. It is invalidated by every API request
+// making changes; changes made by the supervisors, or by other servers, are
+// visible once the cached responses expire.
+type ReadCache struct {
+	ttl time.Duration
+
+	lock    sync.Mutex
+	entries map[string]*readCacheEntry
+	// generation is incremented on every invalidation, so that responses
+	// built before an invalidation are not cached after it.
+	generation uint64
+}
+
+type readCacheEntry struct {
+	contentType string
+	body        []byte
+	expireAt    time.Time
+}
+
+// NewReadCache creates a cache keeping responses for the given time.
+func NewReadCache(ttl time.Duration) *ReadCache {
+	return &ReadCache{
+		ttl:     ttl,
+		entries: make(map[string]*readCacheEntry),
+	}
+}
+
+// Invalidate discards every cached response.
+func (rc *ReadCache) Invalidate() {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	rc.entries = make(map[string]*readCacheEntry)
+	rc.generation++
+}
+
+// get returns the unexpired response cached under the given key, if any, and
+// the current generation.
+func (rc *ReadCache) get(key string, now time.Time) (*readCacheEntry, uint64) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	entry := rc.entries[key]
+	if entry != nil && !now.Before(entry.expireAt) {
+		delete(rc.entries, key)
+		entry = nil
+	}
+
+	return entry, rc.generation
+}
+
+// put caches the given response under the given key, unless the cache was
+// invalidated since the given generation.
+func (rc *ReadCache) put(key string, generation uint64, entry *readCacheEntry, now time.Time) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	if generation != rc.generation {
+		return
+	}
+	if len(rc.entries) >= maxReadCacheEntries {
+		for key, entry := range rc.entries {
+			if !now.Before(entry.expireAt) {
+				delete(rc.entries, key)
+			}
+		}
+		if len(rc.entries) >= maxReadCacheEntries {
+			return
+		}
+	}
+
+	rc.entries[key] = entry
+}
+
+// serve responds to the request from the cache, or with the given handler,
+// caching its response if successful. Responses are cached per tenant and API
+// version, as both change their content.
+func (rc *ReadCache) serve(c *Context, w http.ResponseWriter, r *http.Request, handler contextHandlerFunc) {
+	key := c.TenantID + "|" + strconv.Itoa(c.APIVersion) + "|" + r.URL.RequestURI()
+	now := time.Now()
+
+	entry, generation := rc.get(key, now)
+	if entry != nil {
+		w.Header().Set("Content-Type", entry.contentType)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(entry.body)
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	handler(c, recorder, r)
+	if recorder.status != http.StatusOK {
+		return
+	}
+
+	rc.put(key, generation, &readCacheEntry{
+		contentType: w.Header().Get("Content-Type"),
+		body:        recorder.body.Bytes(),
+		expireAt:    now.Add(rc.ttl),
+	}, now)
+}
+
+// responseRecorder copies the status and body of a response as it is
+// written.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCache(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+		ReadCache:  api.NewReadCache(time.Minute),
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		ring, err := client.GetRing(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, ring)
+	})
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Name: "ring1", Priority: 1})
+	require.NoError(t, err)
+
+	t.Run("changes made outside of the API are cached", func(t *testing.T) {
+		cached, err := client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, "ring1", cached.Name)
+
+		ring.Name = "ring2"
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		cached, err = client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, "ring1", cached.Name)
+
+		rings, err := client.GetRings(&model.GetRingsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, rings, 1)
+		require.Equal(t, "ring2", rings[0].Name)
+	})
+
+	t.Run("changes made through the API clear the cache", func(t *testing.T) {
+		_, err := client.UpdateRing(ring.ID, &model.UpdateRingRequest{Name: "ring3"})
+		require.NoError(t, err)
+
+		cached, err := client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, "ring3", cached.Name)
+
+		rings, err := client.GetRings(&model.GetRingsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, "ring3", rings[0].Name)
+	})
+
+	t.Run("reads through the API do not clear the cache", func(t *testing.T) {
+		ring.Name = "ring4"
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		_, err := client.GetRingBlockers(ring.ID)
+		require.NoError(t, err)
+		_, err = client.GetRingTimeline(ring.ID, model.DefaultTimelineReleases)
+		require.NoError(t, err)
+
+		cached, err := client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, "ring3", cached.Name)
+	})
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+)
+
+// HealthReporter reports the health of the supervisors of the server.
+type HealthReporter interface {
+	Health(now time.Time) *model.Readiness
+}
+
+// NewReadinessHandler returns the handler of GET /readyz, responding 200 when
+// every supervisor is healthy and 503 otherwise. With verbose=true, the health
+// of each supervisor is returned as JSON. It requires no API token, so that
+// orchestrators can probe it.
+func NewReadinessHandler(reporter HealthReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		readiness := reporter.Health(time.Now())
+		status := http.StatusOK
+		if !readiness.Ready {
+			status = http.StatusServiceUnavailable
+		}
+
+		if verbose, _ := parseBool(r.URL, "verbose", false); verbose {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(readiness)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(status)
+		if readiness.Ready {
+			_, _ = w.Write([]byte("ok\n"))
+		} else {
+			_, _ = w.Write([]byte("not ready\n"))
+		}
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHealthReporter struct {
+	readiness *model.Readiness
+}
+
+func (m *mockHealthReporter) Health(now time.Time) *model.Readiness {
+	return m.readiness
+}
+
+func TestReadiness(t *testing.T) {
+	reporter := &mockHealthReporter{}
+	ts := httptest.NewServer(api.NewReadinessHandler(reporter))
+	defer ts.Close()
+
+	t.Run("ready", func(t *testing.T) {
+		reporter.readiness = &model.Readiness{
+			Ready:       true,
+			Supervisors: []*model.SupervisorHealth{{Name: "ring", Period: 30, Cycles: 2, Healthy: true}},
+		}
+
+		resp, err := http.Get(ts.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, "ok\n", string(body))
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		reporter.readiness = &model.Readiness{
+			Ready:       false,
+			Supervisors: []*model.SupervisorHealth{{Name: "ring", Period: 30, Errors: 3, LastError: "failed", Healthy: false}},
+		}
+
+		resp, err := http.Get(ts.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+		resp, err = http.Get(ts.URL + "?verbose=true")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		readiness, err := model.ReadinessFromReader(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, reporter.readiness, readiness)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		resp, err := http.Post(ts.URL, "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/mattermost/elrond/model"
+)
+
+// addRingReleaseLabels adds the given labels to the ring release, if any.
+func addRingReleaseLabels(c *Context, ringRelease *model.RingRelease, labels model.ReleaseLabels) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	updated := ringRelease.Labels.Add(labels...)
+	if err := c.Store.UpdateRingReleaseLabels(ringRelease.ID, updated); err != nil {
+		return err
+	}
+	ringRelease.Labels = updated
+
+	return nil
+}
+
+// handleAddRingReleaseLabels responds to POST /api/release/{release}/labels,
+// adding the labels of the body to the ring release and returning its
+// labels.
+func handleAddRingReleaseLabels(c *Context, w http.ResponseWriter, r *http.Request) {
+	labels, err := model.ReleaseLabelsFromReader(r.Body)
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "failed to decode release labels")
+		return
+	}
+	if err = labels.Validate(); err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return
+	}
+
+	ringRelease := getRingReleaseForLinks(c, w, r)
+	if ringRelease == nil {
+		return
+	}
+
+	if err = addRingReleaseLabels(c, ringRelease, labels); err != nil {
+		c.Logger.WithError(err).Error("failed to add ring release labels")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to add ring release labels")
+		return
+	}
+
+	outputLabels := ringRelease.Labels
+	if outputLabels == nil {
+		outputLabels = model.ReleaseLabels{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, outputLabels)
+}
+
+// handleRemoveRingReleaseLabel responds to DELETE
+// /api/release/{release}/labels, removing the given label from the ring
+// release.
+func handleRemoveRingReleaseLabel(c *Context, w http.ResponseWriter, r *http.Request) {
+	label := r.URL.Query().Get("label")
+	if label == "" {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "label is required")
+		return
+	}
+
+	ringRelease := getRingReleaseForLinks(c, w, r)
+	if ringRelease == nil {
+		return
+	}
+
+	labels, found := ringRelease.Labels.Remove(label)
+	if !found {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring release label not found")
+		return
+	}
+	if err := c.Store.UpdateRingReleaseLabels(ringRelease.ID, labels); err != nil {
+		c.Logger.WithError(err).Error("failed to remove ring release label")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to remove ring release label")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
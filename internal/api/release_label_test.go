@@ -0,0 +1,139 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingReleaseLabels(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("unknown release", func(t *testing.T) {
+		_, err := client.AddRingReleaseLabels(model.NewID(), model.ReleaseLabels{"quarterly"})
+		requireAPIError(t, err, http.StatusNotFound)
+
+		err = client.RemoveRingReleaseLabel(model.NewID(), "quarterly")
+		requireAPIError(t, err, http.StatusNotFound)
+	})
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{
+		Name:              "production",
+		Priority:          1,
+		InstallationGroup: &model.InstallationGroup{Name: "prod-12345"},
+	})
+	require.NoError(t, err)
+	ring.State = model.RingStateStable
+	require.NoError(t, sqlStore.UpdateRing(ring))
+
+	t.Run("invalid label", func(t *testing.T) {
+		_, err := client.ReleaseRing(ring.ID, &model.RingReleaseRequest{
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Version: "7.0.1",
+			Labels:  model.ReleaseLabels{"Security Patch"},
+		})
+		require.Error(t, err)
+	})
+
+	ring, err = client.ReleaseRing(ring.ID, &model.RingReleaseRequest{
+		Image:   "mattermost/mattermost-enterprise-edition",
+		Version: "7.0.1",
+		Labels:  model.ReleaseLabels{"security-patch"},
+	})
+	require.NoError(t, err)
+	patchID := ring.DesiredReleaseID
+
+	feature, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"})
+	require.NoError(t, err)
+
+	t.Run("labels given with the release", func(t *testing.T) {
+		release, err := client.GetRingRelease(patchID)
+		require.NoError(t, err)
+		require.Equal(t, model.ReleaseLabels{"security-patch"}, release.Labels)
+	})
+
+	t.Run("add and remove labels once immutable", func(t *testing.T) {
+		require.NoError(t, sqlStore.MarkRingReleaseImmutable(patchID))
+
+		labels, err := client.AddRingReleaseLabels(patchID, model.ReleaseLabels{"quarterly", "security-patch"})
+		require.NoError(t, err)
+		require.Equal(t, model.ReleaseLabels{"quarterly", "security-patch"}, labels)
+
+		_, err = client.AddRingReleaseLabels(patchID, model.ReleaseLabels{"Quarterly"})
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		require.NoError(t, client.RemoveRingReleaseLabel(patchID, "quarterly"))
+		err = client.RemoveRingReleaseLabel(patchID, "quarterly")
+		requireAPIError(t, err, http.StatusNotFound)
+	})
+
+	// The release of the ring above is the latest, so these events come
+	// before it.
+	for _, event := range []*model.StateChangeEvent{
+		{ResourceType: model.TypeRing, ResourceID: ring.ID, RingID: ring.ID, ReleaseID: patchID, NewState: model.RingStateReleasePending, Timestamp: 1000},
+		{ResourceType: model.TypeRing, ResourceID: ring.ID, RingID: ring.ID, ReleaseID: patchID, NewState: model.RingStateStable, Timestamp: 4000},
+		{ResourceType: model.TypeRing, ResourceID: ring.ID, RingID: ring.ID, ReleaseID: feature.ID, NewState: model.RingStateReleasePending, Timestamp: 5000},
+		{ResourceType: model.TypeRing, ResourceID: ring.ID, RingID: ring.ID, ReleaseID: feature.ID, NewState: model.RingStateStable, Timestamp: 6000},
+	} {
+		require.NoError(t, sqlStore.CreateStateChangeEvent(event))
+	}
+
+	t.Run("timeline filtered by label", func(t *testing.T) {
+		timeline, err := client.GetRingTimeline(ring.ID, model.DefaultTimelineReleases)
+		require.NoError(t, err)
+		require.Len(t, timeline.Releases, 3)
+		require.Equal(t, model.ReleaseLabels{"security-patch"}, timeline.Releases[0].Labels)
+		require.Empty(t, timeline.Releases[1].Labels)
+
+		timeline, err = client.GetRingTimelineForLabel(ring.ID, model.DefaultTimelineReleases, "security-patch")
+		require.NoError(t, err)
+		require.Len(t, timeline.Releases, 2)
+		require.Equal(t, patchID, timeline.Releases[0].ReleaseID)
+		require.Equal(t, patchID, timeline.Releases[1].ReleaseID)
+
+		timeline, err = client.GetRingTimelineForLabel(ring.ID, 1, "security-patch")
+		require.NoError(t, err)
+		require.Len(t, timeline.Releases, 1)
+		require.Equal(t, model.RingStateReleasePending, timeline.Releases[0].FinalState)
+
+		timeline, err = client.GetRingTimelineForLabel(ring.ID, model.DefaultTimelineReleases, "quarterly")
+		require.NoError(t, err)
+		require.Empty(t, timeline.Releases)
+	})
+
+	t.Run("lead time report", func(t *testing.T) {
+		_, err := client.GetReleaseLeadTimeReport("")
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		report, err := client.GetReleaseLeadTimeReport("security-patch")
+		require.NoError(t, err)
+		require.Equal(t, "security-patch", report.Label)
+		require.Len(t, report.Releases, 1)
+		require.Equal(t, patchID, report.Releases[0].ReleaseID)
+		require.Equal(t, []*model.RingReached{{RingID: ring.ID, ReachedAt: 4000, LeadTime: 3000}}, report.Releases[0].Rings)
+		require.Equal(t, []*model.RingLeadTime{{RingID: ring.ID, RingName: "production", Releases: 1, AverageLeadTime: 3000, MaxLeadTime: 3000}}, report.Rings)
+	})
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// addRingReleaseLinks adds the given links to the ring release, if any.
+func addRingReleaseLinks(c *Context, ringRelease *model.RingRelease, links model.ReleaseLinks) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	updated := ringRelease.Links.Add(links...)
+	if err := c.Store.UpdateRingReleaseLinks(ringRelease.ID, updated); err != nil {
+		return err
+	}
+	ringRelease.Links = updated
+
+	return nil
+}
+
+// getRingReleaseForLinks fetches the ring release of the request, to manage
+// its links or labels, writing an error response and returning nil if it
+// cannot.
+func getRingReleaseForLinks(c *Context, w http.ResponseWriter, r *http.Request) *model.RingRelease {
+	ringReleaseID := mux.Vars(r)["release"]
+	c.Logger = c.Logger.WithField("release", ringReleaseID)
+
+	ringRelease, err := c.Store.GetRingRelease(ringReleaseID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring release")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring release")
+		return nil
+	}
+	if ringRelease == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring release not found")
+		return nil
+	}
+
+	return ringRelease
+}
+
+// handleGetRingReleaseLinks responds to GET /api/release/{release}/links,
+// returning the links of the ring release.
+func handleGetRingReleaseLinks(c *Context, w http.ResponseWriter, r *http.Request) {
+	ringRelease := getRingReleaseForLinks(c, w, r)
+	if ringRelease == nil {
+		return
+	}
+
+	links := ringRelease.Links
+	if links == nil {
+		links = model.ReleaseLinks{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, links)
+}
+
+// handleAddRingReleaseLink responds to POST /api/release/{release}/links,
+// adding a link to the ring release. Adding a link already present updates
+// its title.
+func handleAddRingReleaseLink(c *Context, w http.ResponseWriter, r *http.Request) {
+	link, err := model.NewReleaseLinkFromReader(r.Body)
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return
+	}
+
+	ringRelease := getRingReleaseForLinks(c, w, r)
+	if ringRelease == nil {
+		return
+	}
+
+	if err = addRingReleaseLinks(c, ringRelease, model.ReleaseLinks{link}); err != nil {
+		c.Logger.WithError(err).Error("failed to add ring release link")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to add ring release link")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, ringRelease.Links)
+}
+
+// handleRemoveRingReleaseLink responds to DELETE
+// /api/release/{release}/links, removing the link of the given type and url
+// from the ring release.
+func handleRemoveRingReleaseLink(c *Context, w http.ResponseWriter, r *http.Request) {
+	linkType := r.URL.Query().Get("type")
+	linkURL := r.URL.Query().Get("url")
+	if linkType == "" || linkURL == "" {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "type and url are required")
+		return
+	}
+
+	ringRelease := getRingReleaseForLinks(c, w, r)
+	if ringRelease == nil {
+		return
+	}
+
+	links, found := ringRelease.Links.Remove(linkType, linkURL)
+	if !found {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring release link not found")
+		return
+	}
+	if err := c.Store.UpdateRingReleaseLinks(ringRelease.ID, links); err != nil {
+		c.Logger.WithError(err).Error("failed to remove ring release link")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to remove ring release link")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
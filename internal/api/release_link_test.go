@@ -0,0 +1,108 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingReleaseLinks(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	pr := &model.ReleaseLink{Type: model.ReleaseLinkTypePullRequest, URL: "https://github.com/mattermost/mattermost/pull/1"}
+	ticket := &model.ReleaseLink{Type: model.ReleaseLinkTypeChangeTicket, URL: "https://tickets.example.com/CHG-1", Title: "CHG-1"}
+
+	t.Run("unknown release", func(t *testing.T) {
+		_, err := client.GetRingReleaseLinks(model.NewID())
+		requireAPIError(t, err, http.StatusNotFound)
+
+		_, err = client.AddRingReleaseLink(model.NewID(), pr)
+		requireAPIError(t, err, http.StatusNotFound)
+
+		err = client.RemoveRingReleaseLink(model.NewID(), pr.Type, pr.URL)
+		requireAPIError(t, err, http.StatusNotFound)
+	})
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{
+		Priority:          1,
+		InstallationGroup: &model.InstallationGroup{Name: "prod-12345"},
+	})
+	require.NoError(t, err)
+	ring.State = model.RingStateStable
+	require.NoError(t, sqlStore.UpdateRing(ring))
+
+	ring, err = client.ReleaseRing(ring.ID, &model.RingReleaseRequest{
+		Image:   "mattermost/mattermost-enterprise-edition",
+		Version: "7.0.1",
+		Links:   model.ReleaseLinks{pr},
+	})
+	require.NoError(t, err)
+	releaseID := ring.DesiredReleaseID
+
+	t.Run("links given with the release", func(t *testing.T) {
+		links, err := client.GetRingReleaseLinks(releaseID)
+		require.NoError(t, err)
+		require.Equal(t, model.ReleaseLinks{pr}, links)
+
+		release, err := client.GetRingRelease(releaseID)
+		require.NoError(t, err)
+		require.Equal(t, model.ReleaseLinks{pr}, release.Links)
+	})
+
+	t.Run("invalid link", func(t *testing.T) {
+		_, err := client.AddRingReleaseLink(releaseID, &model.ReleaseLink{Type: "wiki", URL: "https://wiki.example.com"})
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		_, err = client.AddRingReleaseLink(releaseID, &model.ReleaseLink{Type: model.ReleaseLinkTypeBuild, URL: "not a url"})
+		requireAPIError(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("add links once immutable", func(t *testing.T) {
+		require.NoError(t, sqlStore.MarkRingReleaseImmutable(releaseID))
+
+		links, err := client.AddRingReleaseLink(releaseID, ticket)
+		require.NoError(t, err)
+		require.Equal(t, model.ReleaseLinks{pr, ticket}, links)
+
+		retitled := &model.ReleaseLink{Type: ticket.Type, URL: ticket.URL, Title: "CHG-1 approved"}
+		links, err = client.AddRingReleaseLink(releaseID, retitled)
+		require.NoError(t, err)
+		require.Equal(t, model.ReleaseLinks{pr, retitled}, links)
+	})
+
+	t.Run("remove link", func(t *testing.T) {
+		err := client.RemoveRingReleaseLink(releaseID, pr.Type, pr.URL)
+		require.NoError(t, err)
+
+		err = client.RemoveRingReleaseLink(releaseID, pr.Type, pr.URL)
+		requireAPIError(t, err, http.StatusNotFound)
+
+		links, err := client.GetRingReleaseLinks(releaseID)
+		require.NoError(t, err)
+		require.Len(t, links, 1)
+		require.Equal(t, ticket.URL, links[0].URL)
+	})
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// initReport registers report endpoints on the given router.
+func initReport(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	reportsRouter := apiRouter.PathPrefix("/reports").Subrouter()
+	reportsRouter.Handle("/soak-time", addContext(handleGetSoakTimeReport)).Methods("GET")
+	reportsRouter.Handle("/versions", addContext(handleGetVersionReport)).Methods("GET")
+	reportsRouter.Handle("/compare", addContext(handleGetRingComparison)).Methods("GET")
+	reportsRouter.Handle("/lead-time", addContext(handleGetReleaseLeadTimeReport)).Methods("GET")
+	reportsRouter.Handle("/replay", addContext(handleGetReplayReport)).Methods("GET")
+}
+
+// handleGetSoakTimeReport responds to GET /api/reports/soak-time, returning
+// the soak time suggested for every ring. The report built in the background
+// is returned if there is one, otherwise it is built on demand.
+func handleGetSoakTimeReport(c *Context, w http.ResponseWriter, r *http.Request) {
+	var report *model.SoakTimeReport
+	if c.SoakTimeReporter != nil {
+		report = c.SoakTimeReporter.Report()
+	}
+
+	if report == nil {
+		rings, err := c.Store.GetRings(&model.RingFilter{PerPage: model.AllPerPage})
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query rings")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings")
+			return
+		}
+
+		events, err := c.Store.GetStateChangeEvents(&model.StateChangeEventFilter{
+			ResourceType: model.TypeRing,
+			PerPage:      model.MaxStateChangeEventsPerQuery,
+			Latest:       true,
+		})
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query ring state change events")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring state change events")
+			return
+		}
+
+		report = model.BuildSoakTimeReport(rings, events, model.GetMillis())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, report)
+}
+
+// handleGetVersionReport responds to GET /api/reports/versions, returning the
+// active and desired release of every ring and installation group. With
+// format=csv, the report is exported as CSV.
+func handleGetVersionReport(c *Context, w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "format must be json or csv")
+		return
+	}
+
+	filter := &model.RingFilter{PerPage: model.AllPerPage}
+	rings, err := c.Store.GetRings(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query rings")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings")
+		return
+	}
+
+	installationGroups, err := c.Store.GetInstallationGroupsForRings(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get installation groups for rings")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get installation groups for rings")
+		return
+	}
+
+	releases := make(map[string]*model.RingRelease)
+	for _, ring := range rings {
+		for _, releaseID := range []string{ring.ActiveReleaseID, ring.DesiredReleaseID} {
+			if _, ok := releases[releaseID]; ok || releaseID == "" {
+				continue
+			}
+			release, err := c.Store.GetRingRelease(releaseID)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to query ring release")
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring release")
+				return
+			}
+			releases[releaseID] = release
+		}
+	}
+
+	report := model.BuildVersionReport(rings, installationGroups, releases, model.GetMillis())
+
+	if format == "csv" {
+		data, err := report.CSV()
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to build CSV version report")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to build CSV version report")
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if _, err = w.Write(data); err != nil {
+			c.Logger.WithError(err).Warn("failed to write CSV response")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, report)
+}
+
+// handleGetRingComparison responds to GET /api/reports/compare, returning the
+// differences between the active release, soak configuration and labels of
+// the rings given as ringA and ringB.
+func handleGetRingComparison(c *Context, w http.ResponseWriter, r *http.Request) {
+	ringAID := r.URL.Query().Get("ringA")
+	ringBID := r.URL.Query().Get("ringB")
+	if ringAID == "" || ringBID == "" {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "ringA and ringB are required")
+		return
+	}
+
+	var rings [2]*model.Ring
+	var actives [2]*model.RingRelease
+	for i, ringID := range []string{ringAID, ringBID} {
+		ring, err := c.Store.GetRing(ringID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query ring")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+			return
+		}
+		if ring == nil {
+			outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, fmt.Sprintf("ring %s not found", ringID))
+			return
+		}
+		rings[i] = ring
+
+		if ring.ActiveReleaseID != "" {
+			actives[i], err = c.Store.GetRingRelease(ring.ActiveReleaseID)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to query ring release")
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring release")
+				return
+			}
+		}
+	}
+
+	comparison := model.BuildRingComparison(rings[0], actives[0], rings[1], actives[1], model.GetMillis())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, comparison)
+}
+
+// handleGetReleaseLeadTimeReport responds to GET /api/reports/lead-time,
+// returning how long the releases with the given label took to reach every
+// ring.
+func handleGetReleaseLeadTimeReport(c *Context, w http.ResponseWriter, r *http.Request) {
+	label := r.URL.Query().Get("label")
+	if label == "" {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "label is required")
+		return
+	}
+
+	releases, err := c.Store.GetRingReleases(&model.RingReleaseFilter{
+		Label:   label,
+		PerPage: model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring releases")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring releases")
+		return
+	}
+
+	rings, err := c.Store.GetRings(&model.RingFilter{PerPage: model.AllPerPage})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query rings")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings")
+		return
+	}
+
+	var events []*model.StateChangeEvent
+	if len(releases) > 0 {
+		events, err = c.Store.GetStateChangeEvents(&model.StateChangeEventFilter{
+			ResourceType: model.TypeRing,
+			PerPage:      model.MaxStateChangeEventsPerQuery,
+			Latest:       true,
+		})
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query ring state change events")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring state change events")
+			return
+		}
+	}
+
+	report := model.BuildReleaseLeadTimeReport(label, rings, releases, events, model.GetMillis())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, report)
+}
+
+// handleGetReplayReport responds to GET /api/reports/replay, replaying the
+// recorded state change events between from and to, optionally of a single
+// ring, against the current state machine, the authorization policy of the
+// server and the current configuration of the rings, overridden by the given
+// failure_policy and max_release_duration, if any. The replay works on copies
+// of the rings and never changes them.
+func handleGetReplayReport(c *Context, w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, err := parseInt64(r.URL, "from", 0)
+	if err != nil || from < 0 {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "from must be a time in milliseconds")
+		return
+	}
+	to, err := parseInt64(r.URL, "to", 0)
+	if err != nil || to < 0 {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "to must be a time in milliseconds")
+		return
+	}
+	if to != 0 && to <= from {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "to must be after from")
+		return
+	}
+	failurePolicy := query.Get("failure_policy")
+	if err = model.ValidateFailurePolicy(failurePolicy); err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return
+	}
+	maxReleaseDuration, err := parseInt(r.URL, "max_release_duration", -1)
+	if err != nil || (query.Get("max_release_duration") != "" && model.ValidateMaxReleaseDuration(maxReleaseDuration) != nil) {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "max_release_duration must be a duration in seconds")
+		return
+	}
+
+	ringID := query.Get("ring")
+	var rings []*model.Ring
+	if ringID != "" {
+		ring, err := c.Store.GetRing(ringID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query ring")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+			return
+		}
+		if ring == nil {
+			outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring not found")
+			return
+		}
+		rings = append(rings, ring)
+	} else {
+		rings, err = c.Store.GetRings(&model.RingFilter{PerPage: model.AllPerPage})
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query rings")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings")
+			return
+		}
+	}
+	for _, ring := range rings {
+		if failurePolicy != "" {
+			ring.FailurePolicy = failurePolicy
+		}
+		if maxReleaseDuration >= 0 {
+			ring.MaxReleaseDuration = maxReleaseDuration
+		}
+	}
+
+	events, err := c.Store.GetStateChangeEvents(&model.StateChangeEventFilter{
+		RingID:  ringID,
+		From:    from,
+		To:      to,
+		PerPage: model.MaxStateChangeEventsPerQuery + 1,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query state change events")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query state change events")
+		return
+	}
+	if len(events) > model.MaxStateChangeEventsPerQuery {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("more than %d events to replay, narrow the range", model.MaxStateChangeEventsPerQuery))
+		return
+	}
+
+	var evaluate model.ReplayPolicyFunc
+	if c.Policy != nil {
+		evaluate = c.Policy.Evaluate
+	}
+	report, err := model.ReplayEvents(rings, events, evaluate)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to replay state change events")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to replay state change events")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, report)
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSoakTimeReporter struct {
+	report *model.SoakTimeReport
+}
+
+func (r *mockSoakTimeReporter) Report() *model.SoakTimeReport {
+	return r.report
+}
+
+func TestGetSoakTimeReport(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	reporter := &mockSoakTimeReporter{}
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:            sqlStore,
+		Supervisor:       &mockSupervisor{},
+		SoakTimeReporter: reporter,
+		Logger:           logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Name: "production", Priority: 1, SoakTime: 3600})
+	require.NoError(t, err)
+
+	t.Run("built on demand", func(t *testing.T) {
+		report, err := client.GetSoakTimeReport()
+		require.NoError(t, err)
+		require.Len(t, report.Rings, 1)
+		require.Equal(t, ring.ID, report.Rings[0].RingID)
+		require.Equal(t, 3600, report.Rings[0].SoakTime)
+		require.False(t, report.Rings[0].Changed())
+	})
+
+	t.Run("built in the background", func(t *testing.T) {
+		reporter.report = &model.SoakTimeReport{
+			GeneratedAt: 1000,
+			Rings:       []*model.SoakTimeSuggestion{{RingID: ring.ID, SoakTime: 3600, SuggestedSoakTime: 2700}},
+		}
+
+		report, err := client.GetSoakTimeReport()
+		require.NoError(t, err)
+		require.Equal(t, reporter.report, report)
+	})
+}
+
+func TestGetVersionReport(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{
+		Name:              "production",
+		Priority:          1,
+		Image:             "mattermost/mattermost-enterprise-edition",
+		Version:           "9.5.0",
+		InstallationGroup: &model.InstallationGroup{Name: "group1"},
+	})
+	require.NoError(t, err)
+
+	t.Run("json", func(t *testing.T) {
+		report, err := client.GetVersionReport()
+		require.NoError(t, err)
+		require.Len(t, report.Entries, 2)
+		require.Equal(t, ring.ID, report.Entries[0].ID)
+		require.Equal(t, "9.5.0", report.Entries[0].ActiveVersion)
+		require.Equal(t, model.TypeInstallationGroup, report.Entries[1].Type)
+		require.Equal(t, "group1", report.Entries[1].Name)
+		require.Equal(t, "9.5.0", report.Entries[1].DesiredVersion)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		data, err := client.GetVersionReportCSV()
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 3)
+		require.True(t, strings.HasPrefix(lines[0], "type,id,name"))
+		require.Contains(t, lines[2], "group1")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/v1/reports/versions?format=xml")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestGetRingComparison(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	staging, err := client.CreateRing(&model.CreateRingRequest{
+		Name:        "staging",
+		Priority:    1,
+		SoakTime:    3600,
+		Image:       "mattermost/mattermost-enterprise-edition",
+		Version:     "9.5.1",
+		Annotations: model.Annotations{"env": "staging"},
+	})
+	require.NoError(t, err)
+	production, err := client.CreateRing(&model.CreateRingRequest{
+		Name:        "production",
+		Priority:    2,
+		SoakTime:    3600,
+		Image:       "mattermost/mattermost-enterprise-edition",
+		Version:     "9.5.0",
+		Annotations: model.Annotations{"env": "prod"},
+	})
+	require.NoError(t, err)
+
+	t.Run("compare", func(t *testing.T) {
+		comparison, err := client.CompareRings(staging.ID, production.ID)
+		require.NoError(t, err)
+		require.False(t, comparison.Identical)
+		require.Equal(t, "staging", comparison.RingA.Name)
+		require.Equal(t, "production", comparison.RingB.Name)
+		require.Equal(t, []*model.RingDifference{
+			{Field: "ActiveVersion", A: "9.5.1", B: "9.5.0"},
+			{Field: "Labels.env", A: "staging", B: "prod"},
+		}, comparison.Differences)
+	})
+
+	t.Run("same ring", func(t *testing.T) {
+		comparison, err := client.CompareRings(staging.ID, staging.ID)
+		require.NoError(t, err)
+		require.True(t, comparison.Identical)
+	})
+
+	t.Run("missing ring", func(t *testing.T) {
+		_, err := client.CompareRings(staging.ID, "unknown")
+		requireAPIError(t, err, http.StatusNotFound)
+
+		_, err = client.CompareRings(staging.ID, "")
+		requireAPIError(t, err, http.StatusBadRequest)
+	})
+}
+
+func TestGetReplayReport(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Name: "production", Priority: 1, SoakTime: 3600})
+	require.NoError(t, err)
+
+	now := model.GetMillis()
+	for i, event := range []*model.StateChangeEvent{
+		{ResourceType: model.TypeRing, ResourceID: ring.ID, OldState: model.RingStateReleasePending, NewState: model.RingStateReleaseRequested},
+		{ResourceType: model.TypeRing, ResourceID: ring.ID, OldState: model.RingStateReleaseRequested, NewState: model.RingStateReleaseInProgress},
+		{ResourceType: model.TypeInstallationGroup, ResourceID: "ig1", OldState: model.InstallationGroupReleaseRequested, NewState: model.InstallationGroupReleaseFailed},
+		{ResourceType: model.TypeRing, ResourceID: ring.ID, OldState: model.RingStateReleaseInProgress, NewState: model.RingStateReleaseFailed},
+	} {
+		event.RingID = ring.ID
+		event.ReleaseID = "release1"
+		event.Timestamp = now + int64(i+1)*1000
+		require.NoError(t, sqlStore.CreateStateChangeEvent(event))
+	}
+
+	t.Run("current configuration", func(t *testing.T) {
+		report, err := client.GetReplayReport(&model.GetReplayReportRequest{})
+		require.NoError(t, err)
+		require.Equal(t, model.CurrentStateMachineVersion, report.StateMachineVersion)
+		require.False(t, report.PolicyEvaluated)
+		require.Equal(t, 5, report.Replayed)
+		require.Empty(t, report.Differences)
+	})
+
+	t.Run("failure policy", func(t *testing.T) {
+		report, err := client.GetReplayReport(&model.GetReplayReportRequest{
+			RingID:        ring.ID,
+			From:          now + 1,
+			FailurePolicy: model.FailurePolicyRollbackRing,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 4, report.Replayed)
+		require.Len(t, report.Differences, 1)
+		require.Equal(t, model.ReplayDifferenceFailurePolicy, report.Differences[0].Reason)
+		require.Equal(t, model.RingStateReleaseRollbackRequested, report.Differences[0].ExpectedState)
+
+		fetched, err := client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Empty(t, fetched.FailurePolicy)
+	})
+
+	t.Run("max release duration", func(t *testing.T) {
+		maxReleaseDuration := 1
+		report, err := client.GetReplayReport(&model.GetReplayReportRequest{MaxReleaseDuration: &maxReleaseDuration})
+		require.NoError(t, err)
+		require.Empty(t, report.Differences)
+
+		report, err = client.GetReplayReport(&model.GetReplayReportRequest{
+			FailurePolicy:      model.FailurePolicyRollbackRing,
+			MaxReleaseDuration: &maxReleaseDuration,
+		})
+		require.NoError(t, err)
+		require.Len(t, report.Differences, 2)
+		require.Equal(t, model.ReplayDifferenceFailurePolicy, report.Differences[0].Reason)
+		require.Equal(t, model.ReplayDifferenceMaxReleaseDuration, report.Differences[1].Reason)
+		require.Equal(t, model.RingStateReleaseRollbackRequested, report.Differences[1].ExpectedState)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := client.GetReplayReport(&model.GetReplayReportRequest{FailurePolicy: "unknown"})
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		_, err = client.GetReplayReport(&model.GetReplayReportRequest{From: 2000, To: 1000})
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		_, err = client.GetReplayReport(&model.GetReplayReportRequest{RingID: "unknown"})
+		requireAPIError(t, err, http.StatusNotFound)
+	})
+}
@@ -20,7 +20,6 @@ func TestNewCreateRingRequestFromReader(t *testing.T) {
 			InstallationGroup: &model.InstallationGroup{
 				Name: "prod-1234",
 			},
-			SoakTime: 7200,
 		}
 	}
 
@@ -52,8 +51,7 @@ func TestNewCreateRingRequestFromReader(t *testing.T) {
 			InstallationGroup: &model.InstallationGroup{
 				Name: "prod-12345",
 			},
-			Name:     "test",
-			SoakTime: 7200,
+			Name: "test",
 		}, ringRequest)
 	})
 }
@@ -5,10 +5,13 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 
 	"github.com/mattermost/elrond/internal/webhook"
 	"github.com/mattermost/elrond/model"
@@ -19,27 +22,51 @@ func initRing(apiRouter *mux.Router, context *Context) {
 	addContext := func(handler contextHandlerFunc) *contextHandler {
 		return newContextHandler(context, handler)
 	}
+	addCachedContext := func(handler contextHandlerFunc) *contextHandler {
+		return newCachedContextHandler(context, handler)
+	}
 
 	ringsRouter := apiRouter.PathPrefix("/rings").Subrouter()
-	ringsRouter.Handle("", addContext(handleGetRings)).Methods("GET")
-	ringsRouter.Handle("", addContext(handleCreateRing)).Methods("POST")
-	ringsRouter.Handle("/release", addContext(handleReleaseAllRings)).Methods("POST")
+	ringsRouter.Handle("", addCachedContext(handleGetRings)).Methods("GET")
+	ringsRouter.Handle("", addContext(validateRequestBody(model.SchemaCreateRingRequest, handleCreateRing))).Methods("POST")
+	ringsRouter.Handle("/release", addContext(validateRequestBody(model.SchemaRingReleaseRequest, handleReleaseAllRings))).Methods("POST")
 	ringsRouter.Handle("/release/pause", addContext(handlePauseReleaseRing)).Methods("POST")
 	ringsRouter.Handle("/release/resume", addContext(handleResumeReleaseRing)).Methods("POST")
 	ringsRouter.Handle("/release/cancel", addContext(handleCancelReleaseRing)).Methods("POST")
+	ringsRouter.Handle("/release/scheduled", addContext(handleGetScheduledReleases)).Methods("GET")
 
 	ringRouter := apiRouter.PathPrefix("/ring/{ring:[A-Za-z0-9]{26}}").Subrouter()
-	ringRouter.Handle("", addContext(handleGetRing)).Methods("GET")
+	ringRouter.Handle("", addCachedContext(handleGetRing)).Methods("GET")
 	ringRouter.Handle("", addContext(handleRetryCreateRing)).Methods("POST")
-	ringRouter.Handle("/update", addContext(handleUpdateRing)).Methods("POST")
-	ringRouter.Handle("/release", addContext(handleReleaseRing)).Methods("POST")
+	ringRouter.Handle("/rollback-snapshot", addContext(handleGetRingRollbackSnapshot)).Methods("GET")
+	ringRouter.Handle("/timeline", addContext(handleGetRingTimeline)).Methods("GET")
+	ringRouter.Handle("/events", addContext(handleGetRingStateChangeEvents)).Methods("GET")
+	ringRouter.Handle("/blockers", addCachedContext(handleGetRingBlockers)).Methods("GET")
+	ringRouter.Handle("/notes", addContext(handleGetNotes(model.TypeRing))).Methods("GET")
+	ringRouter.Handle("/notes", addContext(validateRequestBody(model.SchemaNoteRequest, handleAddNote(model.TypeRing)))).Methods("POST")
+	ringRouter.Handle("/update", addContext(validateRequestBody(model.SchemaUpdateRingRequest, handleUpdateRing))).Methods("POST")
+	ringRouter.Handle("/release", addContext(validateRequestBody(model.SchemaRingReleaseRequest, handleReleaseRing))).Methods("POST")
 	ringRouter.Handle("/release", addContext(handleRetryReleaseRing)).Methods("POST")
-	ringRouter.Handle("/installationgroup", addContext(handleRegisterRingInstallationGroup)).Methods("POST")
+	ringRouter.Handle("/release/cancel", addContext(handleCancelScheduledRelease)).Methods("POST")
+	ringRouter.Handle("/release/prepare", addContext(validateRequestBody(model.SchemaRingReleaseRequest, handlePrepareReleaseRing))).Methods("POST")
+	ringRouter.Handle("/release/commit", addContext(handleCommitReleaseRing)).Methods("POST")
+	ringRouter.Handle("/pause", addContext(handlePauseRing)).Methods("POST")
+	ringRouter.Handle("/resume", addContext(handleResumeRing)).Methods("POST")
+	ringRouter.Handle("/installationgroup", addContext(validateRequestBody(model.SchemaRegisterInstallationGroupRequest, handleRegisterRingInstallationGroup))).Methods("POST")
 	ringRouter.Handle("/installationgroup/{installation-group-id}", addContext(handleDeleteRingInstallationGroup)).Methods("DELETE")
 	ringRouter.Handle("", addContext(handleDeleteRing)).Methods("DELETE")
+	ringRouter.Handle("/deletion/cancel", addContext(handleCancelDeleteRing)).Methods("POST")
 
 	ringReleaseRouter := apiRouter.PathPrefix("/release/{release:[A-Za-z0-9]{26}}").Subrouter()
 	ringReleaseRouter.Handle("", addContext(handleGetRingRelease)).Methods("GET")
+	ringReleaseRouter.Handle("/health", addContext(handleGetRingReleaseHealth)).Methods("GET")
+	ringReleaseRouter.Handle("/links", addContext(handleGetRingReleaseLinks)).Methods("GET")
+	ringReleaseRouter.Handle("/links", addContext(validateRequestBody(model.SchemaReleaseLink, handleAddRingReleaseLink))).Methods("POST")
+	ringReleaseRouter.Handle("/links", addContext(handleRemoveRingReleaseLink)).Methods("DELETE")
+	ringReleaseRouter.Handle("/labels", addContext(validateRequestBody(model.SchemaReleaseLabels, handleAddRingReleaseLabels))).Methods("POST")
+	ringReleaseRouter.Handle("/labels", addContext(handleRemoveRingReleaseLabel)).Methods("DELETE")
+	ringReleaseRouter.Handle("/notes", addContext(handleGetNotes(model.TypeRelease))).Methods("GET")
+	ringReleaseRouter.Handle("/notes", addContext(validateRequestBody(model.SchemaNoteRequest, handleAddNote(model.TypeRelease)))).Methods("POST")
 
 }
 
@@ -52,34 +79,353 @@ func handleGetRing(c *Context, w http.ResponseWriter, r *http.Request) {
 	ring, err := c.Store.GetRing(ringID)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to query ring")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
 		return
 	}
 	if ring == nil {
-		w.WriteHeader(http.StatusNotFound)
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring not found")
 		return
 	}
 
 	installationGroups, err := c.Store.GetInstallationGroupsForRing(ringID)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to get installation groups for ring")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get installation groups for ring")
 		return
 	}
 
 	ring.InstallationGroups = installationGroups
 
+	ring.Notes, err = c.Store.GetNotes(&model.NoteFilter{ResourceType: model.TypeRing, ResourceIDs: []string{ring.ID}})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get notes for ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get notes for ring")
+		return
+	}
+
+	if err = setEstimatedCompletion(c, ring); err != nil {
+		c.Logger.WithError(err).Error("failed to estimate ring release completion")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to estimate ring release completion")
+		return
+	}
+	ring.Capacity = model.NewRingCapacity(ring.InstallationGroups)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	outputJSON(c, w, ring)
 }
 
-// handleGetRings responds to GET /api/rings, returning the specified page of rings.
+// setEstimatedCompletion sets the estimated completion time of the release in
+// progress of the ring from the durations of its previous releases.
+func setEstimatedCompletion(c *Context, ring *model.Ring) error {
+	return setEstimatedCompletions(c, []*model.Ring{ring})
+}
+
+// setEstimatedCompletions sets the estimated completion time of the releases
+// in progress of the given rings, loading the events of all of them at once.
+func setEstimatedCompletions(c *Context, rings []*model.Ring) error {
+	ringIDs := []string{}
+	for _, ring := range rings {
+		if ring.HasUnfinishedRelease() {
+			ringIDs = append(ringIDs, ring.ID)
+		}
+	}
+	if len(ringIDs) == 0 {
+		return nil
+	}
+
+	events, err := c.Store.GetStateChangeEvents(&model.StateChangeEventFilter{
+		RingIDs: ringIDs,
+		PerPage: model.MaxStateChangeEventsPerQuery,
+		Latest:  true,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to query ring state change events")
+	}
+	ringEvents := make(map[string][]*model.StateChangeEvent)
+	for _, event := range events {
+		ringEvents[event.RingID] = append(ringEvents[event.RingID], event)
+	}
+
+	now := model.GetMillis()
+	for _, ring := range rings {
+		if ring.HasUnfinishedRelease() {
+			ring.EstimatedCompletionAt = model.EstimateReleaseCompletion(ring, ring.InstallationGroups, ringEvents[ring.ID], now)
+		}
+	}
+
+	return nil
+}
+
+// handleGetRingRollbackSnapshot responds to GET /api/ring/{ring}/rollback-snapshot,
+// returning the snapshot of the release the ring would roll back to.
+func handleGetRingRollbackSnapshot(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ringID := vars["ring"]
+	c.Logger = c.Logger.WithField("ring", ringID)
+
+	ring, err := c.Store.GetRing(ringID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+		return
+	}
+	if ring == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring not found")
+		return
+	}
+	if ring.RollbackSnapshotID == "" {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring has no rollback snapshot")
+		return
+	}
+
+	snapshot, err := c.Store.GetRingReleaseSnapshot(ring.RollbackSnapshotID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring release snapshot")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring release snapshot")
+		return
+	}
+	if snapshot == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring release snapshot not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, snapshot)
+}
+
+// handleGetRingTimeline responds to GET /api/ring/{ring}/timeline, returning
+// the timeline of the latest releases of the ring. With label, only the
+// releases with the label are included.
+func handleGetRingTimeline(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ringID := vars["ring"]
+	c.Logger = c.Logger.WithField("ring", ringID)
+
+	releases, err := parseInt(r.URL, "releases", model.DefaultTimelineReleases)
+	if err != nil || releases < 1 {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "releases must be a positive integer")
+		return
+	}
+
+	ring, err := c.Store.GetRing(ringID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+		return
+	}
+	if ring == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring not found")
+		return
+	}
+
+	events, err := c.Store.GetStateChangeEvents(&model.StateChangeEventFilter{
+		RingID:  ringID,
+		PerPage: model.MaxStateChangeEventsPerQuery,
+		Latest:  true,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring state change events")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring state change events")
+		return
+	}
+
+	// The releases of the timeline are filtered by label once it is built,
+	// so every release is built first.
+	label := r.URL.Query().Get("label")
+	timelineReleases := releases
+	if label != "" {
+		timelineReleases = 0
+	}
+	timeline := model.BuildRingTimeline(ringID, events, timelineReleases)
+
+	ringReleases := make(map[string]*model.RingRelease)
+	filtered := make([]*model.ReleaseTimeline, 0, len(timeline.Releases))
+	for _, release := range timeline.Releases {
+		ringRelease, ok := ringReleases[release.ReleaseID]
+		if !ok {
+			ringRelease, err = c.Store.GetRingRelease(release.ReleaseID)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to query ring release")
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring release")
+				return
+			}
+			ringReleases[release.ReleaseID] = ringRelease
+		}
+		if ringRelease != nil {
+			release.Labels = ringRelease.Labels
+		}
+		if label != "" && !release.Labels.Has(label) {
+			continue
+		}
+		filtered = append(filtered, release)
+	}
+	if len(filtered) > releases {
+		filtered = filtered[len(filtered)-releases:]
+	}
+	timeline.Releases = filtered
+
+	installationGroups, err := c.Store.GetInstallationGroupsForRing(ringID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get installation groups for ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get installation groups for ring")
+		return
+	}
+	noteResourceIDs := []string{ringID}
+	for _, installationGroup := range installationGroups {
+		noteResourceIDs = append(noteResourceIDs, installationGroup.ID)
+	}
+	for _, release := range timeline.Releases {
+		noteResourceIDs = append(noteResourceIDs, release.ReleaseID)
+	}
+	timeline.Notes, err = c.Store.GetNotes(&model.NoteFilter{ResourceIDs: noteResourceIDs})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring notes")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring notes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, timeline)
+}
+
+// handleGetRingBlockers responds to GET /api/ring/{ring}/blockers, returning
+// what keeps the release of the ring, or of its installation groups, from
+// moving forward.
+func handleGetRingBlockers(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ringID := vars["ring"]
+	c.Logger = c.Logger.WithField("ring", ringID)
+
+	ring, err := c.Store.GetRing(ringID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+		return
+	}
+	if ring == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring not found")
+		return
+	}
+
+	blockers, err := getRingReleaseBlockers(c, ring)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to determine ring release blockers")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to determine ring release blockers")
+		return
+	}
+	if blocker := model.RingFailureIssueBlocker(ring); blocker != nil {
+		blockers = append([]*model.ReleaseBlocker{blocker}, blockers...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, &model.ReleaseBlockers{RingID: ring.ID, State: ring.State, Blockers: blockers})
+}
+
+// getRingReleaseBlockers evaluates the same checks as the supervisors for a
+// ring waiting to release, or for the installation groups of a ring
+// releasing that are waiting for their turn.
+func getRingReleaseBlockers(c *Context, ring *model.Ring) ([]*model.ReleaseBlocker, error) {
+	switch ring.State {
+	case model.RingStateReleasePaused:
+		return []*model.ReleaseBlocker{{
+			Reason:  model.ReleaseBlockerPaused,
+			Message: fmt.Sprintf("releases of ring %s are paused", ring.Name),
+			RingID:  ring.ID,
+		}}, nil
+
+	case model.RingStateReleasePending, model.RingStateReleaseBlocked, model.RingStateReleasePrepared:
+		ringsLocked, err := c.Store.GetRingsLocked()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query locked rings")
+		}
+		ringsReleaseInProgress, err := c.Store.GetRingsReleaseInProgress()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query rings with a release in progress")
+		}
+		ringsPendingWork, err := c.Store.GetUnlockedRingsPendingWork()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query rings pending work")
+		}
+		rings, err := c.Store.GetRings(&model.RingFilter{PerPage: model.AllPerPage})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query rings")
+		}
+
+		return model.RingReleaseBlockers(ring, rings, ringsLocked, ringsReleaseInProgress, ringsPendingWork, time.Now()), nil
+
+	case model.RingStateReleaseRequested, model.RingStateReleaseInProgress:
+		installationGroups, err := c.Store.GetInstallationGroupsForRing(ring.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query installation groups of the ring")
+		}
+		installationGroupsLocked, err := c.Store.GetInstallationGroupsLocked()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query locked installation groups")
+		}
+		installationGroupsReleaseInProgress, err := c.Store.GetInstallationGroupsReleaseInProgress()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query installation groups with a release in progress")
+		}
+		rings, err := c.Store.GetRings(&model.RingFilter{PerPage: model.AllPerPage})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query rings")
+		}
+		installationGroupsByRing, err := c.Store.GetInstallationGroupsForRings(&model.RingFilter{PerPage: model.AllPerPage})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query installation groups of the rings")
+		}
+		installationGroupsLocked = model.RehearsalInstallationGroups(installationGroupsLocked, ring.Rehearsal, rings, installationGroupsByRing)
+		installationGroupsReleaseInProgress = model.RehearsalInstallationGroups(installationGroupsReleaseInProgress, ring.Rehearsal, rings, installationGroupsByRing)
+
+		// Every waiting installation group is blocked by the same others, so
+		// each blocker is only reported once.
+		blockers := []*model.ReleaseBlocker{}
+		seen := make(map[string]bool)
+		for _, installationGroup := range installationGroups {
+			if installationGroup.State != model.InstallationGroupReleasePending {
+				continue
+			}
+			for _, blocker := range model.InstallationGroupReleaseBlockers(installationGroup, ring, rings, installationGroups, installationGroupsLocked, installationGroupsReleaseInProgress, time.Now()) {
+				key := blocker.Reason + "/" + blocker.InstallationGroupID
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				blockers = append(blockers, blocker)
+			}
+		}
+
+		return blockers, nil
+	}
+
+	return []*model.ReleaseBlocker{}, nil
+}
+
+// handleGetRings responds to GET /api/rings, returning the specified page of
+// rings, or of the rings as they were at the time given by as_of.
 func handleGetRings(c *Context, w http.ResponseWriter, r *http.Request) {
 	page, perPage, includeDeleted, err := parsePaging(r.URL)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to parse paging parameters")
-		w.WriteHeader(http.StatusBadRequest)
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to parse paging parameters: %s", err))
+		return
+	}
+
+	asOf, err := parseInt64(r.URL, "as_of", 0)
+	if err != nil || asOf < 0 {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "as_of must be a time in milliseconds")
+		return
+	}
+	if asOf > model.GetMillis() {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "as_of cannot be in the future")
+		return
+	}
+	if asOf != 0 {
+		getRingsAsOf(c, w, asOf, page, perPage)
 		return
 	}
 
@@ -92,7 +438,7 @@ func handleGetRings(c *Context, w http.ResponseWriter, r *http.Request) {
 	rings, err := c.Store.GetRings(filter)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to query rings")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings")
 		return
 	}
 
@@ -103,12 +449,71 @@ func handleGetRings(c *Context, w http.ResponseWriter, r *http.Request) {
 	installationGroups, err := c.Store.GetInstallationGroupsForRings(filter)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to get installation groups for ring")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get installation groups for ring")
 		return
 	}
 
 	for _, r := range rings {
 		r.InstallationGroups = installationGroups[r.ID]
+		r.Capacity = model.NewRingCapacity(r.InstallationGroups)
+	}
+	if err = setEstimatedCompletions(c, rings); err != nil {
+		c.Logger.WithError(err).Error("failed to estimate ring release completion")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to estimate ring release completion")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, rings)
+}
+
+// getRingsAsOf responds with the given page of the rings as they were at the
+// given time in milliseconds, reconstructed from the state change events.
+func getRingsAsOf(c *Context, w http.ResponseWriter, asOf int64, page, perPage int) {
+	filter := &model.RingFilter{
+		PerPage:        model.AllPerPage,
+		IncludeDeleted: true,
+	}
+
+	rings, err := c.Store.GetRings(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query rings")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings")
+		return
+	}
+
+	installationGroups, err := c.Store.GetInstallationGroupsForRings(filter)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get installation groups for ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get installation groups for ring")
+		return
+	}
+	for _, ring := range rings {
+		ring.InstallationGroups = installationGroups[ring.ID]
+	}
+
+	events, err := c.Store.GetStateChangeEvents(&model.StateChangeEventFilter{
+		To:      asOf + 1,
+		PerPage: model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query state change events")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query state change events")
+		return
+	}
+
+	rings = model.RingsAsOf(rings, events, asOf)
+	if perPage != model.AllPerPage {
+		start := page * perPage
+		if start > len(rings) {
+			start = len(rings)
+		}
+		end := start + perPage
+		if end > len(rings) {
+			end = len(rings)
+		}
+		rings = rings[start:end]
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -127,7 +532,24 @@ func handleCreateRing(c *Context, w http.ResponseWriter, r *http.Request) {
 	createRingRequest, err := model.NewCreateRingRequestFromReader(r.Body)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to decode request")
-		w.WriteHeader(http.StatusBadRequest)
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode request: %s", err))
+		return
+	}
+
+	idempotencyKey, existingRingID, ok := reserveIdempotencyKey(c, w, r, model.TypeRing)
+	if !ok {
+		return
+	}
+	defer releaseIdempotencyKey(c, idempotencyKey)
+	if existingRingID != "" {
+		outputExistingRing(c, w, http.StatusAccepted, existingRingID)
+		return
+	}
+
+	if !checkRingsQuota(c, w) {
+		return
+	}
+	if !checkRingDependencies(c, w, "", createRingRequest.DependsOn) {
 		return
 	}
 
@@ -140,38 +562,76 @@ func handleCreateRing(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to get or create new ring release")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get or create new ring release")
 		return
 	}
 
 	ring := model.Ring{
-		Name:             createRingRequest.Name,
-		Priority:         createRingRequest.Priority,
-		SoakTime:         createRingRequest.SoakTime,
-		ActiveReleaseID:  release.ID,
-		DesiredReleaseID: release.ID,
-		Provisioner:      "elrond",
-		APISecurityLock:  createRingRequest.APISecurityLock,
-		State:            model.RingStateCreationRequested,
+		Name:                    createRingRequest.Name,
+		Priority:                createRingRequest.Priority,
+		SoakTime:                createRingRequest.SoakTime,
+		ActiveReleaseID:         release.ID,
+		DesiredReleaseID:        release.ID,
+		Provisioner:             "elrond",
+		APISecurityLock:         createRingRequest.APISecurityLock,
+		Annotations:             createRingRequest.Annotations,
+		Metadata:                createRingRequest.Metadata,
+		NotificationEmails:      createRingRequest.NotificationEmails,
+		NotificationLanguage:    createRingRequest.NotificationLanguage,
+		JiraProject:             createRingRequest.JiraProject,
+		OwnerTeam:               createRingRequest.OwnerTeam,
+		SlackChannel:            createRingRequest.SlackChannel,
+		EscalationPolicy:        createRingRequest.EscalationPolicy,
+		InstallationGroupPolicy: createRingRequest.InstallationGroupPolicy,
+		FailurePolicy:           createRingRequest.FailurePolicy,
+		AutoRollback:            createRingRequest.AutoRollback,
+		ForceApprovalWindow:     createRingRequest.ForceApprovalWindow,
+		SoakWindows:             createRingRequest.SoakWindows,
+		ReleaseWindows:          createRingRequest.ReleaseWindows,
+		DependsOn:               createRingRequest.DependsOn,
+		MaxConcurrency:          createRingRequest.MaxConcurrency,
+		MaxPerFailureDomain:     createRingRequest.MaxPerFailureDomain,
+		MaxReleaseDuration:      createRingRequest.MaxReleaseDuration,
+		Protected:               createRingRequest.Protected,
+		Rehearsal:               createRingRequest.Rehearsal,
+		RehearsalSpeedup:        createRingRequest.RehearsalSpeedup,
+		TenantID:                c.TenantID,
+		State:                   model.RingStateCreationRequested,
 	}
 	iGroup := model.InstallationGroup{}
 	if createRingRequest.InstallationGroup != nil {
 		if createRingRequest.InstallationGroup.Name != "" {
 			iGroup = model.InstallationGroup{
-				Name:               createRingRequest.InstallationGroup.Name,
-				State:              model.InstallationGroupStable,
-				ProvisionerGroupID: createRingRequest.InstallationGroup.ProvisionerGroupID,
-				SoakTime:           createRingRequest.InstallationGroup.SoakTime,
+				Name:                 createRingRequest.InstallationGroup.Name,
+				State:                model.InstallationGroupStable,
+				ProvisionerGroupID:   createRingRequest.InstallationGroup.ProvisionerGroupID,
+				SoakTime:             createRingRequest.InstallationGroup.SoakTime,
+				Annotations:          createRingRequest.InstallationGroup.Annotations,
+				Metadata:             createRingRequest.InstallationGroup.Metadata,
+				FailureDomain:        createRingRequest.InstallationGroup.FailureDomain,
+				ReleaseStrategy:      createRingRequest.InstallationGroup.ReleaseStrategy,
+				ReleaseLoadThreshold: createRingRequest.InstallationGroup.ReleaseLoadThreshold,
+				ReleaseLoadMaxWait:   createRingRequest.InstallationGroup.ReleaseLoadMaxWait,
+				SoakChecks:           createRingRequest.InstallationGroup.SoakChecks,
+				ReleaseWindows:       createRingRequest.InstallationGroup.ReleaseWindows,
+				VerificationURL:      createRingRequest.InstallationGroup.VerificationURL,
+				VerificationTimeout:  createRingRequest.InstallationGroup.VerificationTimeout,
+				DatabaseSnapshot:     createRingRequest.InstallationGroup.DatabaseSnapshot,
+				InstallationCount:    createRingRequest.InstallationGroup.InstallationCount,
+				CustomerCount:        createRingRequest.InstallationGroup.CustomerCount,
+				CapacityTarget:       createRingRequest.InstallationGroup.CapacityTarget,
 			}
 		}
 	}
 
 	if err = c.Store.CreateRing(&ring, &iGroup); err != nil {
 		c.Logger.WithError(err).Error("failed to create ring")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to create ring")
 		return
 	}
 
+	completeIdempotencyKey(c, idempotencyKey, ring.ID)
+
 	ring.InstallationGroups = append(ring.InstallationGroups, &iGroup)
 
 	webhookPayload := &model.WebhookPayload{
@@ -180,7 +640,11 @@ func handleCreateRing(c *Context, w http.ResponseWriter, r *http.Request) {
 		NewState:  model.RingStateCreationRequested,
 		OldState:  "n/a",
 		Timestamp: time.Now().UnixNano(),
+		Labels:    ring.Annotations,
+		Metadata:  ring.Metadata,
 	}
+	recordRingStateChange(c, &ring, webhookPayload.OldState, webhookPayload.NewState)
+
 	if err = webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
 		c.Logger.WithError(err).Error("Unable to process and send webhooks")
 	}
@@ -201,7 +665,7 @@ func handleRetryCreateRing(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	ring, status, unlockOnce := lockRing(c, ringID)
 	if status != 0 {
-		w.WriteHeader(status)
+		outputStatusError(c, w, status, "ring")
 		return
 	}
 	defer unlockOnce()
@@ -210,7 +674,7 @@ func handleRetryCreateRing(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	if !ring.ValidTransitionState(newState) {
 		c.Logger.Warnf("unable to retry ring creation while in state %s", ring.State)
-		w.WriteHeader(http.StatusBadRequest)
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to retry ring creation while in state %s", ring.State), map[string]string{"state": ring.State})
 		return
 	}
 
@@ -221,15 +685,19 @@ func handleRetryCreateRing(c *Context, w http.ResponseWriter, r *http.Request) {
 			NewState:  newState,
 			OldState:  ring.State,
 			Timestamp: time.Now().UnixNano(),
+			Labels:    ring.Annotations,
+			Metadata:  ring.Metadata,
 		}
 		ring.State = newState
 
 		if err := c.Store.UpdateRing(ring); err != nil {
 			c.Logger.WithError(err).Errorf("failed to retry ring creation")
-			w.WriteHeader(http.StatusInternalServerError)
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to retry ring creation")
 			return
 		}
 
+		recordRingStateChange(c, ring, webhookPayload.OldState, webhookPayload.NewState)
+
 		if err := webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
 			c.Logger.WithError(err).Error("Unable to process and send webhooks")
 		}
@@ -253,21 +721,21 @@ func handleUpdateRing(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	ring, status, unlockOnce := lockRing(c, ringID)
 	if status != 0 {
-		w.WriteHeader(status)
+		outputStatusError(c, w, status, "ring")
 		return
 	}
 	defer unlockOnce()
 
 	if ring.APISecurityLock {
 		logSecurityLockConflict("ring", c.Logger)
-		w.WriteHeader(http.StatusForbidden)
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, "API changes are locked for this ring")
 		return
 	}
 
 	updateRingRequest, err := model.NewUpdateRingRequestFromReader(r.Body)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to deserialize ring update request body")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to deserialize ring update request body")
 		return
 	}
 
@@ -283,9 +751,90 @@ func handleUpdateRing(c *Context, w http.ResponseWriter, r *http.Request) {
 		ring.Priority = updateRingRequest.Priority
 	}
 
+	if updateRingRequest.Annotations != nil {
+		ring.Annotations = updateRingRequest.Annotations
+	}
+
+	if updateRingRequest.Metadata != nil {
+		ring.Metadata = updateRingRequest.Metadata
+	}
+
+	if updateRingRequest.NotificationEmails != nil {
+		ring.NotificationEmails = *updateRingRequest.NotificationEmails
+	}
+
+	if updateRingRequest.NotificationLanguage != nil {
+		ring.NotificationLanguage = *updateRingRequest.NotificationLanguage
+	}
+
+	if updateRingRequest.JiraProject != nil {
+		ring.JiraProject = *updateRingRequest.JiraProject
+	}
+
+	if updateRingRequest.OwnerTeam != nil {
+		ring.OwnerTeam = *updateRingRequest.OwnerTeam
+	}
+
+	if updateRingRequest.SlackChannel != nil {
+		ring.SlackChannel = *updateRingRequest.SlackChannel
+	}
+
+	if updateRingRequest.EscalationPolicy != nil {
+		ring.EscalationPolicy = *updateRingRequest.EscalationPolicy
+	}
+
+	if updateRingRequest.InstallationGroupPolicy != "" {
+		ring.InstallationGroupPolicy = updateRingRequest.InstallationGroupPolicy
+	}
+
+	if updateRingRequest.FailurePolicy != "" {
+		ring.FailurePolicy = updateRingRequest.FailurePolicy
+	}
+
+	if updateRingRequest.AutoRollback != nil {
+		ring.AutoRollback = *updateRingRequest.AutoRollback
+	}
+
+	if updateRingRequest.ForceApprovalWindow != nil {
+		// Shortening or disabling the window would bypass the two-person rule.
+		if *updateRingRequest.ForceApprovalWindow < ring.ForceApprovalWindow {
+			if !requireAdminToken(c, w) {
+				return
+			}
+		}
+		ring.ForceApprovalWindow = *updateRingRequest.ForceApprovalWindow
+	}
+
+	if updateRingRequest.SoakWindows != nil {
+		ring.SoakWindows = *updateRingRequest.SoakWindows
+	}
+
+	if updateRingRequest.ReleaseWindows != nil {
+		ring.ReleaseWindows = *updateRingRequest.ReleaseWindows
+	}
+
+	if updateRingRequest.DependsOn != nil {
+		if !checkRingDependencies(c, w, ring.ID, *updateRingRequest.DependsOn) {
+			return
+		}
+		ring.DependsOn = *updateRingRequest.DependsOn
+	}
+
+	if updateRingRequest.MaxConcurrency != nil {
+		ring.MaxConcurrency = *updateRingRequest.MaxConcurrency
+	}
+
+	if updateRingRequest.MaxPerFailureDomain != nil {
+		ring.MaxPerFailureDomain = *updateRingRequest.MaxPerFailureDomain
+	}
+
+	if updateRingRequest.MaxReleaseDuration != nil {
+		ring.MaxReleaseDuration = *updateRingRequest.MaxReleaseDuration
+	}
+
 	if err = c.Store.UpdateRing(ring); err != nil {
 		c.Logger.WithError(err).Error("failed to update ring")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update ring")
 		return
 	}
 
@@ -294,13 +843,35 @@ func handleUpdateRing(c *Context, w http.ResponseWriter, r *http.Request) {
 	outputJSON(c, w, ring)
 }
 
+// checkRingDependencies returns whether the ring with the given ID, empty for
+// a new ring, can depend on the given rings without forming a cycle, writing
+// the error otherwise.
+func checkRingDependencies(c *Context, w http.ResponseWriter, ringID string, dependsOn model.RingDependencies) bool {
+	if len(dependsOn) == 0 {
+		return true
+	}
+
+	rings, err := c.Store.GetRings(&model.RingFilter{PerPage: model.AllPerPage})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query rings")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings")
+		return false
+	}
+	if err = model.ValidateRingDependencies(ringID, dependsOn, rings); err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return false
+	}
+
+	return true
+}
+
 // handleReleaseAllRings responds to POST /api/rings/release,
 // releasing a deployment in all rings.
 func handleReleaseAllRings(c *Context, w http.ResponseWriter, r *http.Request) {
 	ringReleaseRequest, err := model.NewRingReleaseRequestFromReader(r.Body)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to deserialize ring release request body")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to deserialize ring release request body")
 		return
 	}
 
@@ -311,7 +882,8 @@ func handleReleaseAllRings(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to get all rings from store")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get all rings from store")
+		return
 	}
 
 	var ringIDs []string
@@ -319,42 +891,80 @@ func handleReleaseAllRings(c *Context, w http.ResponseWriter, r *http.Request) {
 		ringIDs = append(ringIDs, ring.ID)
 	}
 
-	status, unlockOnce := lockRings(c, ringIDs)
-	if status != 0 {
-		w.WriteHeader(status)
-		return
-	}
+	if len(ringReleaseRequest.Parameters) > 0 {
+		installationGroupsByRing, err := c.Store.GetInstallationGroupsForRings(&model.RingFilter{PerPage: model.AllPerPage})
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to get installation groups of all rings")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get installation groups of all rings")
+			return
+		}
+		var installationGroups []*model.InstallationGroup
+		for _, ringID := range ringIDs {
+			installationGroups = append(installationGroups, installationGroupsByRing[ringID]...)
+		}
+		if err = ringReleaseRequest.Parameters.ValidateInstallationGroups(installationGroups); err != nil {
+			outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+			return
+		}
+	}
+
+	status, unlockOnce := lockRings(c, ringIDs)
+	if status != 0 {
+		outputStatusError(c, w, status, "rings")
+		return
+	}
 	defer unlockOnce()
 
 	var webhookPayloads []*model.WebhookPayload
+	var releasedRings []*model.Ring
 
 	c.Logger.Debug("Checking if all rings can be released")
 
 	ringsPending, err := c.Store.GetRingsInPendingState()
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to get all rings pending work")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get all rings pending work")
 		return
 	}
 
 	if len(ringsPending) > 0 {
 		c.Logger.Error("Cannot start an all rings release, while another release is pending work")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "Cannot start an all rings release, while another release is pending work")
+		return
+	}
+
+	if ringReleaseRequest.Force && !checkRingsNotProtected(c, w, "force release", rings) {
+		return
+	}
+	if ringReleaseRequest.Force && !requireForceApproval(c, w, model.ForceApprovalActionRelease, rings, ringReleaseRequest) {
 		return
 	}
 
 	ringRelease := model.RingRelease{
-		Version:  ringReleaseRequest.Version,
-		Image:    ringReleaseRequest.Image,
-		Force:    ringReleaseRequest.Force,
-		CreateAt: time.Now().UnixNano(),
+		Version:    ringReleaseRequest.Version,
+		Image:      ringReleaseRequest.Image,
+		Force:      ringReleaseRequest.Force,
+		SoakTime:   ringReleaseRequest.SoakTime,
+		Type:       ringReleaseRequest.Type,
+		Parameters: ringReleaseRequest.Parameters,
+		CreateAt:   time.Now().UnixNano(),
 	}
 
 	//Proactively checking or creating a ring release entry so that all rings to be released get the same release version
 	desiredRelease, err := c.Store.GetOrCreateRingRelease(&ringRelease)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to get or create new ring release")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get or create new ring release")
+		return
+	}
+	if err = addRingReleaseLinks(c, desiredRelease, ringReleaseRequest.Links); err != nil {
+		c.Logger.WithError(err).Error("failed to add ring release links")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to add ring release links")
+		return
+	}
+	if err = addRingReleaseLabels(c, desiredRelease, ringReleaseRequest.Labels); err != nil {
+		c.Logger.WithError(err).Error("failed to add ring release labels")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to add ring release labels")
 		return
 	}
 
@@ -363,12 +973,12 @@ func handleReleaseAllRings(c *Context, w http.ResponseWriter, r *http.Request) {
 
 		if ring.APISecurityLock {
 			logSecurityLockConflict("ring", c.Logger)
-			w.WriteHeader(http.StatusForbidden)
+			outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, "API changes are locked for this ring")
 			return
 		}
-		if !ring.ValidTransitionState(model.RingStateReleasePending) {
+		if !ring.ValidTransitionState(model.RingStateReleasePending) || ring.ReleasePausedInFlight() {
 			c.Logger.Warnf("unable to do a ring release while in state %s", ring.State)
-			w.WriteHeader(http.StatusBadRequest)
+			outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to do a ring release while in state %s", ring.State), map[string]string{"state": ring.State})
 			return
 		}
 		if ring.State != model.RingStateReleasePending {
@@ -378,31 +988,41 @@ func handleReleaseAllRings(c *Context, w http.ResponseWriter, r *http.Request) {
 				NewState:  model.RingStateReleasePending,
 				OldState:  ring.State,
 				Timestamp: time.Now().UnixNano(),
-				ExtraData: map[string]string{"Environment": c.Environment},
+				ExtraData: map[string]string{"Environment": c.Environment, "ReleaseType": ringReleaseRequest.Type},
+				Labels:    ring.Annotations,
+				Metadata:  ring.Metadata,
 			}
 			activeRelease, err := c.Store.GetRingRelease(ring.ActiveReleaseID)
 			if err != nil {
 				c.Logger.WithError(err).Error("failed to get ring active release details")
-				w.WriteHeader(http.StatusInternalServerError)
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get ring active release details")
 				return
 			}
-			if activeRelease.Image != ringReleaseRequest.Image || activeRelease.Version != ringReleaseRequest.Version {
+			if activeRelease.Image != ringReleaseRequest.Image || activeRelease.Version != ringReleaseRequest.Version || !activeRelease.Parameters.Equal(ringReleaseRequest.Parameters) {
 				ring.State = model.RingStateReleasePending
 				ring.DesiredReleaseID = desiredRelease.ID
+				ring.ReleaseScheduledAt = ringReleaseRequest.ScheduleAt
 
 				webhookPayloads = append(webhookPayloads, webhookPayload)
+				releasedRings = append(releasedRings, ring)
 			}
 		}
 	}
 
+	if !checkConcurrentReleasesQuota(c, w, releasedRings) {
+		return
+	}
+
 	c.Logger.Debug("Updating all rings in a single transaction")
 	if err = c.Store.UpdateRings(rings); err != nil {
 		c.Logger.WithError(err).Error("failed to update rings in a single transaction")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update rings in a single transaction")
 		return
 	}
 
-	for _, payload := range webhookPayloads {
+	for i, payload := range webhookPayloads {
+		recordRingStateChange(c, releasedRings[i], payload.OldState, payload.NewState)
+
 		if err := webhook.SendToAllWebhooks(c.Store, payload, c.Logger.WithField("webhookEvent", payload.NewState)); err != nil {
 			c.Logger.WithError(err).Error("unable to process and send webhooks")
 		}
@@ -425,27 +1045,53 @@ func handleReleaseRing(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	ring, status, unlockOnce := lockRing(c, ringID)
 	if status != 0 {
-		w.WriteHeader(status)
+		outputStatusError(c, w, status, "ring")
 		return
 	}
 	defer unlockOnce()
 
 	if ring.APISecurityLock {
 		logSecurityLockConflict("ring", c.Logger)
-		w.WriteHeader(http.StatusForbidden)
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, "API changes are locked for this ring")
 		return
 	}
 
 	ringReleaseRequest, err := model.NewRingReleaseRequestFromReader(r.Body)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to deserialize ring release request body")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to deserialize ring release request body")
 		return
 	}
 
-	if !ring.ValidTransitionState(model.RingStateReleasePending) {
+	idempotencyKey, existingReleaseID, ok := reserveIdempotencyKey(c, w, r, model.TypeRelease)
+	if !ok {
+		return
+	}
+	defer releaseIdempotencyKey(c, idempotencyKey)
+	if existingReleaseID != "" {
+		unlockOnce()
+		outputExistingRing(c, w, http.StatusAccepted, ringID)
+		return
+	}
+
+	if !checkRingReleaseParameters(c, w, ring, ringReleaseRequest) {
+		return
+	}
+
+	if !ring.ValidTransitionState(model.RingStateReleasePending) || ring.ReleasePausedInFlight() {
 		c.Logger.Warnf("unable to do a ring release while in state %s", ring.State)
-		w.WriteHeader(http.StatusBadRequest)
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to do a ring release while in state %s", ring.State), map[string]string{"state": ring.State})
+		return
+	}
+
+	if !checkConcurrentReleasesQuota(c, w, []*model.Ring{ring}) {
+		return
+	}
+
+	if ringReleaseRequest.Force && !checkRingsNotProtected(c, w, "force release", []*model.Ring{ring}) {
+		return
+	}
+	if ringReleaseRequest.Force && !requireForceApproval(c, w, model.ForceApprovalActionRelease, []*model.Ring{ring}, ringReleaseRequest) {
 		return
 	}
 
@@ -456,46 +1102,65 @@ func handleReleaseRing(c *Context, w http.ResponseWriter, r *http.Request) {
 			NewState:  model.RingStateReleasePending,
 			OldState:  ring.State,
 			Timestamp: time.Now().UnixNano(),
-			ExtraData: map[string]string{"Environment": c.Environment},
+			ExtraData: map[string]string{"Environment": c.Environment, "ReleaseType": ringReleaseRequest.Type},
+			Labels:    ring.Annotations,
+			Metadata:  ring.Metadata,
 		}
 
 		activeRelease, err := c.Store.GetRingRelease(ring.ActiveReleaseID)
 		if err != nil {
 			c.Logger.WithError(err).Error("failed to get ring active release details")
-			w.WriteHeader(http.StatusInternalServerError)
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get ring active release details")
 			return
 		}
 
-		if activeRelease.Image != ringReleaseRequest.Image || activeRelease.Version != ringReleaseRequest.Version {
+		if activeRelease.Image != ringReleaseRequest.Image || activeRelease.Version != ringReleaseRequest.Version || !activeRelease.Parameters.Equal(ringReleaseRequest.Parameters) {
 
 			ringRelease := model.RingRelease{
-				Version:  ringReleaseRequest.Version,
-				Image:    ringReleaseRequest.Image,
-				Force:    ringReleaseRequest.Force,
-				CreateAt: time.Now().UnixNano(),
+				Version:    ringReleaseRequest.Version,
+				Image:      ringReleaseRequest.Image,
+				Force:      ringReleaseRequest.Force,
+				SoakTime:   ringReleaseRequest.SoakTime,
+				Type:       ringReleaseRequest.Type,
+				Parameters: ringReleaseRequest.Parameters,
+				CreateAt:   time.Now().UnixNano(),
 			}
 
 			desiredRelease, err := c.Store.GetOrCreateRingRelease(&ringRelease)
 			if err != nil {
 				c.Logger.WithError(err).Error("failed to get or create new ring release")
-				w.WriteHeader(http.StatusInternalServerError)
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get or create new ring release")
+				return
+			}
+			if err = addRingReleaseLinks(c, desiredRelease, ringReleaseRequest.Links); err != nil {
+				c.Logger.WithError(err).Error("failed to add ring release links")
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to add ring release links")
+				return
+			}
+			if err = addRingReleaseLabels(c, desiredRelease, ringReleaseRequest.Labels); err != nil {
+				c.Logger.WithError(err).Error("failed to add ring release labels")
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to add ring release labels")
 				return
 			}
 
 			ring.State = model.RingStateReleasePending
 			ring.DesiredReleaseID = desiredRelease.ID
+			ring.ReleaseScheduledAt = ringReleaseRequest.ScheduleAt
 
 			if err = c.Store.UpdateRing(ring); err != nil {
 				c.Logger.WithError(err).Error("failed to update ring")
-				w.WriteHeader(http.StatusInternalServerError)
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update ring")
 				return
 			}
 
+			recordRingStateChange(c, ring, webhookPayload.OldState, webhookPayload.NewState)
+
 			if err := webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
 				c.Logger.WithError(err).Error("unable to process and send webhooks")
 			}
 		}
 	}
+	completeIdempotencyKey(c, idempotencyKey, ring.DesiredReleaseID)
 
 	// Notify even if we didn't make changes, to expedite even the no-op operations above.
 	unlockOnce()
@@ -506,6 +1171,28 @@ func handleReleaseRing(c *Context, w http.ResponseWriter, r *http.Request) {
 	outputJSON(c, w, ring)
 }
 
+// checkRingReleaseParameters checks that the parameters of the given release
+// request target installation groups of the ring, responding with the error
+// otherwise.
+func checkRingReleaseParameters(c *Context, w http.ResponseWriter, ring *model.Ring, ringReleaseRequest *model.RingReleaseRequest) bool {
+	if len(ringReleaseRequest.Parameters) == 0 {
+		return true
+	}
+
+	installationGroups, err := c.Store.GetInstallationGroupsForRing(ring.ID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get ring installation groups")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get ring installation groups")
+		return false
+	}
+	if err = ringReleaseRequest.Parameters.ValidateInstallationGroups(installationGroups); err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return false
+	}
+
+	return true
+}
+
 // handleRetryReleaseRing responds to POST /api/ring/{ring}/release, retrying a previously
 // failed creation.
 func handleRetryReleaseRing(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -515,7 +1202,7 @@ func handleRetryReleaseRing(c *Context, w http.ResponseWriter, r *http.Request)
 
 	ring, status, unlockOnce := lockRing(c, ringID)
 	if status != 0 {
-		w.WriteHeader(status)
+		outputStatusError(c, w, status, "ring")
 		return
 	}
 	defer unlockOnce()
@@ -524,7 +1211,7 @@ func handleRetryReleaseRing(c *Context, w http.ResponseWriter, r *http.Request)
 
 	if !ring.ValidTransitionState(newState) {
 		c.Logger.Warnf("unable to retry ring release while in state %s", ring.State)
-		w.WriteHeader(http.StatusBadRequest)
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to retry ring release while in state %s", ring.State), map[string]string{"state": ring.State})
 		return
 	}
 
@@ -535,15 +1222,19 @@ func handleRetryReleaseRing(c *Context, w http.ResponseWriter, r *http.Request)
 			NewState:  newState,
 			OldState:  ring.State,
 			Timestamp: time.Now().UnixNano(),
+			Labels:    ring.Annotations,
+			Metadata:  ring.Metadata,
 		}
 		ring.State = newState
 
 		if err := c.Store.UpdateRing(ring); err != nil {
 			c.Logger.WithError(err).Errorf("failed to retry ring release")
-			w.WriteHeader(http.StatusInternalServerError)
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to retry ring release")
 			return
 		}
 
+		recordRingStateChange(c, ring, webhookPayload.OldState, webhookPayload.NewState)
+
 		if err := webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
 			c.Logger.WithError(err).Error("Unable to process and send webhooks")
 		}
@@ -558,74 +1249,366 @@ func handleRetryReleaseRing(c *Context, w http.ResponseWriter, r *http.Request)
 	outputJSON(c, w, ring)
 }
 
-// handlePauseReleaseRing responds to POST /api/rings/release/pause, pausing all pending releases
+// handlePauseReleaseRing responds to POST /api/rings/release/pause, pausing
+// the releases of all rings, whether pending or in flight. Rings under lock
+// are skipped, and the request fails with a conflict so that it is retried.
 func handlePauseReleaseRing(c *Context, w http.ResponseWriter, r *http.Request) {
 	ringsPending, err := c.Store.GetRingsInPendingState()
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to get all rings pending work")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get all rings pending work")
+		return
+	}
+	ringsReleaseInProgress, err := c.Store.GetRingsReleaseInProgress()
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get all rings with a release in progress")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get all rings with a release in progress")
 		return
 	}
 
-	c.Logger.Info("pausing all releases in pending state. Releases already in progress cannot be paused")
+	c.Logger.Info("pausing all pending and in flight releases")
 
-	for _, ring := range ringsPending {
-		ring.State = model.RingStateReleasePaused
+	var skipped []string
+	for _, ring := range append(ringsPending, ringsReleaseInProgress...) {
+		if ring.State == model.RingStateReleasePaused {
+			continue
+		}
+		paused, err := pauseRingRelease(c, ring.ID)
+		if err != nil {
+			c.Logger.WithError(err).WithField("ring", ring.ID).Error("failed to pause ring release")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to pause ring release")
+			return
+		}
+		if !paused {
+			skipped = append(skipped, ring.Name)
+		}
 	}
 
-	c.Logger.Debug("Updating all rings in a single transaction")
-	if err = c.Store.UpdateRings(ringsPending); err != nil {
-		c.Logger.WithError(err).Error("failed to update rings status to paused in a single transaction")
-		w.WriteHeader(http.StatusInternalServerError)
+	if len(skipped) > 0 {
+		outputError(c, w, http.StatusConflict, model.ErrorCodeConflict, fmt.Sprintf("failed to pause the releases of rings %s, retry", strings.Join(skipped, ", ")))
 		return
 	}
 }
 
-// handleResumeReleaseRing responds to POST /api/rings/release/pause, pausing all pending releases
+// pauseRingRelease locks the given ring and pauses its release, if it can
+// still be paused. It returns false if the ring is under lock.
+func pauseRingRelease(c *Context, ringID string) (bool, error) {
+	ring, status, unlockOnce := lockRing(c, ringID)
+	if status == http.StatusConflict {
+		return false, nil
+	}
+	if status != 0 {
+		return false, errors.Errorf("failed to lock ring %s", ringID)
+	}
+	defer unlockOnce()
+
+	oldState := ring.State
+	if !ring.PauseRelease(time.Now().UnixNano()) {
+		return true, nil
+	}
+	if err := c.Store.UpdateRing(ring); err != nil {
+		return false, err
+	}
+	recordRingStateChange(c, ring, oldState, ring.State)
+
+	return true, nil
+}
+
+// handleResumeReleaseRing responds to POST /api/rings/release/resume,
+// resuming all paused releases where they were paused and returning the
+// resumed rings. Rings under lock are skipped, and the request fails with a
+// conflict so that it is retried.
 func handleResumeReleaseRing(c *Context, w http.ResponseWriter, r *http.Request) {
 	ringsPaused, err := c.Store.GetRingsInPendingState()
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to get all rings in paused state")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get all rings in paused state")
+		return
+	}
+	ringsReleaseInProgress, err := c.Store.GetRingsReleaseInProgress()
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get all rings with a release in progress")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get all rings with a release in progress")
 		return
 	}
 
 	c.Logger.Info("resuming all releases in paused state")
 
-	for _, ring := range ringsPaused {
-		ring.State = model.RingStateReleasePending
+	// Rings paused in flight are both pending and in progress, so each ring
+	// is only resumed once.
+	rings := []*model.Ring{}
+	seen := make(map[string]bool)
+	var skipped []string
+	for _, ring := range append(ringsPaused, ringsReleaseInProgress...) {
+		if ring.State != model.RingStateReleasePaused || seen[ring.ID] {
+			continue
+		}
+		seen[ring.ID] = true
+		resumedRing, err := resumeRingRelease(c, ring.ID)
+		if err != nil {
+			c.Logger.WithError(err).WithField("ring", ring.ID).Error("failed to resume ring release")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to resume ring release")
+			return
+		}
+		if resumedRing == nil {
+			skipped = append(skipped, ring.Name)
+			continue
+		}
+		rings = append(rings, resumedRing)
 	}
 
-	c.Logger.Debug("Updating all rings in a single transaction")
-	if err = c.Store.UpdateRings(ringsPaused); err != nil {
-		c.Logger.WithError(err).Error("failed to update rings status to pending in a single transaction")
-		w.WriteHeader(http.StatusInternalServerError)
+	c.Supervisor.Do() //nolint
+
+	if len(skipped) > 0 {
+		outputError(c, w, http.StatusConflict, model.ErrorCodeConflict, fmt.Sprintf("failed to resume the releases of rings %s, retry", strings.Join(skipped, ", ")))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, rings)
+}
+
+// resumeRingRelease locks the given ring and resumes its release, if it is
+// still paused, returning the ring. It returns no ring if the ring is under
+// lock.
+func resumeRingRelease(c *Context, ringID string) (*model.Ring, error) {
+	ring, status, unlockOnce := lockRing(c, ringID)
+	if status == http.StatusConflict {
+		return nil, nil
+	}
+	if status != 0 {
+		return nil, errors.Errorf("failed to lock ring %s", ringID)
+	}
+	defer unlockOnce()
+
+	if err := resumeLockedRingRelease(c, ring); err != nil {
+		return nil, err
+	}
+
+	return ring, nil
+}
+
+// resumeLockedRingRelease resumes the paused release of the given ring, which
+// the caller must have locked, leaving the time it was paused out of the soak
+// time of the ring and of its installation groups.
+func resumeLockedRingRelease(c *Context, ring *model.Ring) error {
+	oldState := ring.State
+	pausedFor, resumed := ring.ResumeRelease(time.Now().UnixNano())
+	if !resumed {
+		return nil
+	}
+	if pausedFor > 0 {
+		if err := c.Store.DelayRingInstallationGroupsSoak(ring.ID, pausedFor); err != nil {
+			return err
+		}
+	}
+	if err := c.Store.UpdateRing(ring); err != nil {
+		return err
+	}
+	recordRingStateChange(c, ring, oldState, ring.State)
+
+	return nil
+}
+
+// handlePauseRing responds to POST /api/ring/{ring}/pause, pausing the
+// release of the ring, whether pending or in flight.
+func handlePauseRing(c *Context, w http.ResponseWriter, r *http.Request) {
+	handleRingPauseChange(c, w, r, true)
+}
+
+// handleResumeRing responds to POST /api/ring/{ring}/resume, resuming the
+// paused release of the ring where it was paused.
+func handleResumeRing(c *Context, w http.ResponseWriter, r *http.Request) {
+	handleRingPauseChange(c, w, r, false)
+}
+
+func handleRingPauseChange(c *Context, w http.ResponseWriter, r *http.Request, pause bool) {
+	vars := mux.Vars(r)
+	ringID := vars["ring"]
+	c.Logger = c.Logger.WithField("ring", ringID)
+
+	ring, status, unlockOnce := lockRing(c, ringID)
+	if status != 0 {
+		outputStatusError(c, w, status, "ring")
+		return
+	}
+	defer unlockOnce()
+
+	if ring.APISecurityLock {
+		logSecurityLockConflict("ring", c.Logger)
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, "API changes are locked for this ring")
 		return
 	}
+
+	oldState := ring.State
+	if pause {
+		if !ring.PauseRelease(time.Now().UnixNano()) {
+			c.Logger.Warnf("unable to pause the release of a ring in state %s", ring.State)
+			outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to pause the release of a ring in state %s", ring.State), map[string]string{"state": ring.State})
+			return
+		}
+		if err := c.Store.UpdateRing(ring); err != nil {
+			c.Logger.WithError(err).Error("failed to update ring")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update ring")
+			return
+		}
+		recordRingStateChange(c, ring, oldState, ring.State)
+	} else {
+		if ring.State != model.RingStateReleasePaused {
+			c.Logger.Warnf("unable to resume the release of a ring in state %s", ring.State)
+			outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to resume the release of a ring in state %s", ring.State), map[string]string{"state": ring.State})
+			return
+		}
+		if err := resumeLockedRingRelease(c, ring); err != nil {
+			c.Logger.WithError(err).Error("failed to resume ring release")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to resume ring release")
+			return
+		}
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeRing,
+		ID:        ring.ID,
+		NewState:  ring.State,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"Environment": c.Environment},
+		Labels:    ring.Annotations,
+		Metadata:  ring.Metadata,
+	}
+	if err := webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+		c.Logger.WithError(err).Error("unable to process and send webhooks")
+	}
+
+	unlockOnce()
+	if !pause {
+		c.Supervisor.Do() //nolint
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, ring)
 }
 
-// handleCancelReleaseRing responds to POST /api/rings/release/pause, pausing all pending releases
+// handleCancelReleaseRing responds to POST /api/rings/release/cancel,
+// cancelling all pending releases, including the paused ones that did not
+// start yet. Releases already in progress cannot be cancelled.
 func handleCancelReleaseRing(c *Context, w http.ResponseWriter, r *http.Request) {
 	ringsPending, err := c.Store.GetRingsInPendingState()
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to get all rings in pending state")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get all rings in pending state")
 		return
 	}
 
 	c.Logger.Info("canceling all releases in pending state. Setting desired release same as active. Releases already in progress cannot be cancelled")
 
+	rings := make([]*model.Ring, 0, len(ringsPending))
 	for _, ring := range ringsPending {
+		if ring.ReleasePausedInFlight() {
+			continue
+		}
 		ring.State = model.RingStateStable
 		ring.DesiredReleaseID = ring.ActiveReleaseID
+		ring.ReleaseScheduledAt = 0
+		ring.PausedState = ""
+		ring.PausedAt = 0
+		rings = append(rings, ring)
 	}
 
 	c.Logger.Debug("Updating all rings in a single transaction")
-	if err = c.Store.UpdateRings(ringsPending); err != nil {
+	if err = c.Store.UpdateRings(rings); err != nil {
 		c.Logger.WithError(err).Error("failed to update rings status to stable and set desired release in a single transaction")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update rings status to stable and set desired release in a single transaction")
+		return
+	}
+}
+
+// handleGetScheduledReleases responds to GET /api/rings/release/scheduled,
+// returning the pending releases of the rings scheduled for later, by
+// schedule time.
+func handleGetScheduledReleases(c *Context, w http.ResponseWriter, r *http.Request) {
+	rings, err := c.Store.GetRingsReleaseScheduled()
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query rings with a scheduled release")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rings with a scheduled release")
+		return
+	}
+
+	scheduledReleases := []*model.ScheduledRelease{}
+	for _, ring := range rings {
+		release, err := c.Store.GetRingRelease(ring.DesiredReleaseID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query ring release")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring release")
+			return
+		}
+		scheduledReleases = append(scheduledReleases, model.NewScheduledRelease(ring, release))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, scheduledReleases)
+}
+
+// handleCancelScheduledRelease responds to POST /api/ring/{ring}/release/cancel,
+// cancelling the scheduled release of the ring before it starts. The ring
+// returns to stable on its active release.
+func handleCancelScheduledRelease(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ringID := vars["ring"]
+	c.Logger = c.Logger.WithField("ring", ringID)
+
+	ring, status, unlockOnce := lockRing(c, ringID)
+	if status != 0 {
+		outputStatusError(c, w, status, "ring")
+		return
+	}
+	defer unlockOnce()
+
+	if ring.APISecurityLock {
+		logSecurityLockConflict("ring", c.Logger)
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, "API changes are locked for this ring")
+		return
+	}
+
+	if (ring.State != model.RingStateReleasePending && ring.State != model.RingStateReleaseBlocked) || ring.ReleaseScheduledAt == 0 {
+		c.Logger.Warnf("unable to cancel a scheduled release while in state %s", ring.State)
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("ring has no scheduled release to cancel in state %s", ring.State), map[string]string{"state": ring.State})
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeRing,
+		ID:        ring.ID,
+		NewState:  model.RingStateStable,
+		OldState:  ring.State,
+		Timestamp: time.Now().UnixNano(),
+		Labels:    ring.Annotations,
+		Metadata:  ring.Metadata,
+	}
+	ring.State = model.RingStateStable
+	ring.DesiredReleaseID = ring.ActiveReleaseID
+	ring.ReleaseScheduledAt = 0
+
+	if err := c.Store.UpdateRing(ring); err != nil {
+		c.Logger.WithError(err).Error("failed to cancel scheduled ring release")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to cancel scheduled ring release")
 		return
 	}
+
+	recordRingStateChange(c, ring, webhookPayload.OldState, webhookPayload.NewState)
+
+	if err := webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	unlockOnce()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, ring)
 }
 
 // handleGetRingRelease responds to GET /api/release/{release}, returning the ring release in question.
@@ -637,11 +1620,25 @@ func handleGetRingRelease(c *Context, w http.ResponseWriter, r *http.Request) {
 	ringRelease, err := c.Store.GetRingRelease(ringReleaseID)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to query ring release")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring release")
 		return
 	}
 	if ringRelease == nil {
-		w.WriteHeader(http.StatusNotFound)
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring release not found")
+		return
+	}
+
+	ringRelease.Notes, err = c.Store.GetNotes(&model.NoteFilter{ResourceType: model.TypeRelease, ResourceIDs: []string{ringRelease.ID}})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get notes for ring release")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get notes for ring release")
+		return
+	}
+
+	ringRelease.DatabaseSnapshots, err = c.Store.GetDatabaseSnapshots(&model.DatabaseSnapshotFilter{ReleaseID: ringRelease.ID, PerPage: model.AllPerPage})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get database snapshots for ring release")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get database snapshots for ring release")
 		return
 	}
 
@@ -650,34 +1647,95 @@ func handleGetRingRelease(c *Context, w http.ResponseWriter, r *http.Request) {
 	outputJSON(c, w, ringRelease)
 }
 
+// handleGetRingReleaseHealth responds to GET /api/release/{release}/health,
+// comparing the health of each installation group released to the ring
+// release before the release with its health after the soak.
+func handleGetRingReleaseHealth(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ringReleaseID := vars["release"]
+	c.Logger = c.Logger.WithField("release", ringReleaseID)
+
+	ringRelease, err := c.Store.GetRingRelease(ringReleaseID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring release")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring release")
+		return
+	}
+	if ringRelease == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring release not found")
+		return
+	}
+
+	snapshots, err := c.Store.GetHealthSnapshots(&model.HealthSnapshotFilter{
+		ReleaseID: ringRelease.ID,
+		RingID:    r.URL.Query().Get("ring"),
+		PerPage:   model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query health snapshots")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query health snapshots")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, model.BuildHealthSnapshotDiffs(snapshots))
+}
+
 // handleDeleteRing responds to DELETE /api/ring/{ring}, beginning the process of
-// deleting the ring.
+// deleting the ring. With a delete_after grace period in seconds, the ring is
+// only deleted once the period ends, unless the deletion is cancelled.
 func handleDeleteRing(c *Context, w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	ringID := vars["ring"]
 	c.Logger = c.Logger.WithField("ring", ringID)
 
+	deleteAfter, err := parseInt(r.URL, "delete_after", 0)
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to parse delete_after: %s", err))
+		return
+	}
+	deleteRingRequest := &model.DeleteRingRequest{DeleteAfter: deleteAfter}
+	if err = deleteRingRequest.Validate(); err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return
+	}
+
 	ring, status, unlockOnce := lockRing(c, ringID)
 	if status != 0 {
-		w.WriteHeader(status)
+		outputStatusError(c, w, status, "ring")
 		return
 	}
 	defer unlockOnce()
 
 	if ring.APISecurityLock {
 		logSecurityLockConflict("ring", c.Logger)
-		w.WriteHeader(http.StatusForbidden)
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, "API changes are locked for this ring")
+		return
+	}
+
+	if !checkRingsNotProtected(c, w, "delete", []*model.Ring{ring}) {
 		return
 	}
 
 	newState := model.RingStateDeletionRequested
+	if deleteRingRequest.DeleteAfter > 0 {
+		newState = model.RingStateDeletionPending
+	}
 
 	if !ring.ValidTransitionState(newState) {
 		c.Logger.Warnf("unable to delete ring while in state %s", ring.State)
-		w.WriteHeader(http.StatusBadRequest)
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to delete ring while in state %s", ring.State), map[string]string{"state": ring.State})
 		return
 	}
 
+	if newState == model.RingStateDeletionPending {
+		ring.DeletionScheduledAt = time.Now().Add(time.Duration(deleteRingRequest.DeleteAfter) * time.Second).UnixNano()
+		if ring.State != model.RingStateDeletionPending {
+			ring.PreDeletionState = ring.State
+		}
+	}
+
 	if ring.State != newState {
 		webhookPayload := &model.WebhookPayload{
 			Type:      model.TypeRing,
@@ -685,18 +1743,29 @@ func handleDeleteRing(c *Context, w http.ResponseWriter, r *http.Request) {
 			NewState:  newState,
 			OldState:  ring.State,
 			Timestamp: time.Now().UnixNano(),
+			Labels:    ring.Annotations,
+			Metadata:  ring.Metadata,
 		}
 		ring.State = newState
 
 		if err := c.Store.UpdateRing(ring); err != nil {
 			c.Logger.WithError(err).Error("failed to mark ring for deletion")
-			w.WriteHeader(http.StatusInternalServerError)
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to mark ring for deletion")
 			return
 		}
 
+		recordRingStateChange(c, ring, webhookPayload.OldState, webhookPayload.NewState)
+
 		if err := webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
 			c.Logger.WithError(err).Error("Unable to process and send webhooks")
 		}
+	} else if newState == model.RingStateDeletionPending {
+		// Already pending deletion, so only reschedule it.
+		if err := c.Store.UpdateRing(ring); err != nil {
+			c.Logger.WithError(err).Error("failed to reschedule ring deletion")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to reschedule ring deletion")
+			return
+		}
 	}
 
 	unlockOnce()
@@ -705,6 +1774,71 @@ func handleDeleteRing(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// handleCancelDeleteRing responds to POST /api/ring/{ring}/deletion/cancel,
+// cancelling the deletion of a ring still within its grace period.
+func handleCancelDeleteRing(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ringID := vars["ring"]
+	c.Logger = c.Logger.WithField("ring", ringID)
+
+	ring, status, unlockOnce := lockRing(c, ringID)
+	if status != 0 {
+		outputStatusError(c, w, status, "ring")
+		return
+	}
+	defer unlockOnce()
+
+	if ring.APISecurityLock {
+		logSecurityLockConflict("ring", c.Logger)
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, "API changes are locked for this ring")
+		return
+	}
+
+	if ring.State != model.RingStateDeletionPending {
+		c.Logger.Warnf("unable to cancel ring deletion while in state %s", ring.State)
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to cancel ring deletion while in state %s", ring.State), map[string]string{"state": ring.State})
+		return
+	}
+
+	// Rings scheduled for deletion before the state they were in was
+	// recorded return to stable.
+	newState := ring.PreDeletionState
+	if newState == "" {
+		newState = model.RingStateStable
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeRing,
+		ID:        ring.ID,
+		NewState:  newState,
+		OldState:  ring.State,
+		Timestamp: time.Now().UnixNano(),
+		Labels:    ring.Annotations,
+		Metadata:  ring.Metadata,
+	}
+	ring.State = newState
+	ring.DeletionScheduledAt = 0
+	ring.PreDeletionState = ""
+
+	if err := c.Store.UpdateRing(ring); err != nil {
+		c.Logger.WithError(err).Error("failed to cancel ring deletion")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to cancel ring deletion")
+		return
+	}
+
+	recordRingStateChange(c, ring, webhookPayload.OldState, webhookPayload.NewState)
+
+	if err := webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	unlockOnce()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, ring)
+}
+
 // handleRegisterRingInstallationGroup responds to POST /api/ring/{ring}/installationgroup,
 // registers the set of installation groups to the Ring.
 func handleRegisterRingInstallationGroup(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -713,42 +1847,80 @@ func handleRegisterRingInstallationGroup(c *Context, w http.ResponseWriter, r *h
 	c.Logger = c.Logger.WithField("ring", ringID).WithField("action", "register-ring-installation-groups")
 	ring, status, unlockOnce := lockRing(c, ringID)
 	if status != 0 {
-		w.WriteHeader(status)
+		outputStatusError(c, w, status, "ring")
 		return
 	}
 	defer unlockOnce()
 
 	if ring.APISecurityLock {
 		logSecurityLockConflict("ring", c.Logger)
-		w.WriteHeader(http.StatusForbidden)
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, "API changes are locked for this ring")
 		return
 	}
 
 	installationGroupRequest, err := model.NewRegisterInstallationGroupRequestFromReader(r.Body)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to decode request")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to decode request")
 		return
 	}
 
-	iGroup := model.InstallationGroup{
-		Name:               installationGroupRequest.Name,
-		SoakTime:           installationGroupRequest.SoakTime,
-		State:              model.InstallationGroupStable,
-		ProvisionerGroupID: installationGroupRequest.ProvisionerGroupID,
+	idempotencyKey, existingInstallationGroupID, ok := reserveIdempotencyKey(c, w, r, model.TypeInstallationGroup)
+	if !ok {
+		return
+	}
+	defer releaseIdempotencyKey(c, idempotencyKey)
+	if existingInstallationGroupID != "" {
+		unlockOnce()
+		outputExistingRing(c, w, http.StatusOK, ringID)
+		return
 	}
 
-	installationGroup, err := c.Store.CreateRingInstallationGroup(ringID, &iGroup)
+	if !checkInstallationGroupsQuota(c, w, ring) {
+		return
+	}
+
+	iGroup := model.InstallationGroup{
+		Name:                 installationGroupRequest.Name,
+		SoakTime:             installationGroupRequest.SoakTime,
+		State:                model.InstallationGroupStable,
+		ProvisionerGroupID:   installationGroupRequest.ProvisionerGroupID,
+		Annotations:          installationGroupRequest.Annotations,
+		Metadata:             installationGroupRequest.Metadata,
+		FailureDomain:        installationGroupRequest.FailureDomain,
+		ReleaseStrategy:      installationGroupRequest.ReleaseStrategy,
+		ReleaseLoadThreshold: installationGroupRequest.ReleaseLoadThreshold,
+		ReleaseLoadMaxWait:   installationGroupRequest.ReleaseLoadMaxWait,
+		SoakChecks:           installationGroupRequest.SoakChecks,
+		ReleaseWindows:       installationGroupRequest.ReleaseWindows,
+		VerificationURL:      installationGroupRequest.VerificationURL,
+		VerificationTimeout:  installationGroupRequest.VerificationTimeout,
+		DatabaseSnapshot:     installationGroupRequest.DatabaseSnapshot,
+		InstallationCount:    installationGroupRequest.InstallationCount,
+		CustomerCount:        installationGroupRequest.CustomerCount,
+		CapacityTarget:       installationGroupRequest.CapacityTarget,
+	}
+
+	installationGroup, change, err := c.Store.RegisterRingInstallationGroup(ringID, &iGroup)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to create ring installation groups")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to create ring installation groups")
 		return
 	}
+	completeIdempotencyKey(c, idempotencyKey, installationGroup.ID)
 
-	ring.InstallationGroups = append(ring.InstallationGroups, installationGroup)
+	// While the ring is releasing, the registration is queued and the
+	// installation group is not part of the ring yet.
+	status = http.StatusAccepted
+	if change == nil {
+		status = http.StatusOK
+		ring.InstallationGroups = append(ring.InstallationGroups, installationGroup)
+	} else {
+		c.Logger.Infof("Queued the registration of installation group %s until the ring release ends", installationGroup.ID)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 	outputJSON(c, w, ring)
 }
 
@@ -765,24 +1937,66 @@ func handleDeleteRingInstallationGroup(c *Context, w http.ResponseWriter, r *htt
 
 	ring, status, unlockOnce := lockRing(c, ringID)
 	if status != 0 {
-		w.WriteHeader(status)
+		outputStatusError(c, w, status, "ring")
 		return
 	}
 	defer unlockOnce()
 
 	if ring.APISecurityLock {
 		logSecurityLockConflict("ring", c.Logger)
-		w.WriteHeader(http.StatusForbidden)
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, "API changes are locked for this ring")
+		return
+	}
+
+	installationGroup, err := c.Store.GetInstallationGroupByID(installationGroupID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query installation group")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query installation group")
+		return
+	}
+	if installationGroup != nil && !checkInstallationGroupDeletable(c, w, ring, installationGroup) {
 		return
 	}
 
-	err := c.Store.DeleteRingInstallationGroup(ringID, installationGroupID)
+	change, err := c.Store.DeregisterRingInstallationGroup(ringID, installationGroupID)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed delete ring installation group")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed delete ring installation group")
+		return
+	}
+	if change != nil {
+		c.Logger.Info("Queued the removal of the installation group until the ring release ends")
+		w.WriteHeader(http.StatusAccepted)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// outputExistingRing responds with the given ring, along with its installation
+// groups, as already created by a previous request with the same idempotency
+// key. The status matches the response to the original request.
+func outputExistingRing(c *Context, w http.ResponseWriter, status int, ringID string) {
+	ring, err := c.Store.GetRing(ringID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+		return
+	}
+	if ring == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring not found")
+		return
+	}
+
+	ring.InstallationGroups, err = c.Store.GetInstallationGroupsForRing(ringID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get installation groups for ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get installation groups for ring")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	outputJSON(c, w, ring)
+}
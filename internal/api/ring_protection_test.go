@@ -0,0 +1,139 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingProtection(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	newTokenClient := func(name, role string) (*model.Client, string) {
+		secret, err := model.NewTokenSecret()
+		require.NoError(t, err)
+		token := &model.Token{Name: name, Role: role, TokenHash: model.HashTokenSecret(secret)}
+		require.NoError(t, sqlStore.CreateToken(token))
+		return model.NewClientWithToken(ts.URL, secret), token.ID
+	}
+	writeClient, writeToken := newTokenClient("alice", model.TokenRoleWrite)
+	adminClient, adminToken := newTokenClient("admin", model.TokenRoleAdmin)
+
+	ring, err := writeClient.CreateRing(&model.CreateRingRequest{
+		Priority:  1,
+		Protected: true,
+	})
+	require.NoError(t, err)
+	require.True(t, ring.Protected)
+	ring.State = model.RingStateStable
+	require.NoError(t, sqlStore.UpdateRing(ring))
+
+	forcedRelease := &model.RingReleaseRequest{
+		Image:   "mattermost/mattermost-enterprise-edition",
+		Version: "7.0.1",
+		Force:   true,
+	}
+
+	t.Run("protected ring cannot be deleted", func(t *testing.T) {
+		err := adminClient.DeleteRing(ring.ID)
+		apiErr := requireAPIError(t, err, 403)
+		require.Equal(t, model.ErrorCodeRingProtected, apiErr.Code)
+		require.Equal(t, ring.ID, apiErr.Details["rings"])
+
+		ring, err = adminClient.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, ring.State)
+	})
+
+	t.Run("protected ring cannot be force released", func(t *testing.T) {
+		_, err := adminClient.ReleaseRing(ring.ID, forcedRelease)
+		apiErr := requireAPIError(t, err, 403)
+		require.Equal(t, model.ErrorCodeRingProtected, apiErr.Code)
+
+		_, err = adminClient.ReleaseAllRings(forcedRelease)
+		apiErr = requireAPIError(t, err, 403)
+		require.Equal(t, model.ErrorCodeRingProtected, apiErr.Code)
+	})
+
+	t.Run("protected ring can be released without force", func(t *testing.T) {
+		_, err := writeClient.ReleaseRing(ring.ID, &model.RingReleaseRequest{
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Version: "7.0.1",
+		})
+		require.NoError(t, err)
+
+		ring.State = model.RingStateStable
+		require.NoError(t, sqlStore.UpdateRing(ring))
+	})
+
+	t.Run("removing the protection requires a reason", func(t *testing.T) {
+		_, err := adminClient.UnprotectRing(ring.ID, &model.RingProtectionRequest{})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("removing the protection requires the admin role", func(t *testing.T) {
+		_, err := writeClient.UnprotectRing(ring.ID, &model.RingProtectionRequest{Reason: "decommission"})
+		requireAPIError(t, err, 403)
+	})
+
+	t.Run("unknown ring", func(t *testing.T) {
+		_, err := adminClient.ProtectRing(model.NewID(), &model.RingProtectionRequest{})
+		requireAPIError(t, err, 404)
+	})
+
+	t.Run("unprotected ring can be deleted", func(t *testing.T) {
+		ring, err = adminClient.UnprotectRing(ring.ID, &model.RingProtectionRequest{Reason: "decommission"})
+		require.NoError(t, err)
+		require.False(t, ring.Protected)
+
+		require.NoError(t, writeClient.DeleteRing(ring.ID))
+	})
+
+	t.Run("protection changes are recorded", func(t *testing.T) {
+		other, err := writeClient.CreateRing(&model.CreateRingRequest{Priority: 2})
+		require.NoError(t, err)
+		require.False(t, other.Protected)
+
+		other, err = writeClient.ProtectRing(other.ID, &model.RingProtectionRequest{Reason: "legal hold"})
+		require.NoError(t, err)
+		require.True(t, other.Protected)
+
+		// Protecting a protected ring is not recorded again.
+		_, err = writeClient.ProtectRing(other.ID, &model.RingProtectionRequest{})
+		require.NoError(t, err)
+
+		changes, err := writeClient.GetRingProtectionChanges(other.ID)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.True(t, changes[0].Protected)
+		require.Equal(t, "legal hold", changes[0].Reason)
+		require.Equal(t, writeToken, changes[0].ChangedBy)
+
+		changes, err = writeClient.GetRingProtectionChanges(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.False(t, changes[0].Protected)
+		require.Equal(t, adminToken, changes[0].ChangedBy)
+	})
+}
@@ -0,0 +1,277 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/webhook"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// handlePrepareReleaseRing responds to POST /api/ring/{ring}/release/prepare,
+// preparing a release of the ring to be committed later. The release goes
+// through the same checks as any other, and the supervisor then gets the ring
+// and its installation groups ready before leaving it in release-prepared.
+func handlePrepareReleaseRing(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ringID := vars["ring"]
+	c.Logger = c.Logger.WithField("ring", ringID)
+
+	ring, status, unlockOnce := lockRing(c, ringID)
+	if status != 0 {
+		outputStatusError(c, w, status, "ring")
+		return
+	}
+	defer unlockOnce()
+
+	if ring.APISecurityLock {
+		logSecurityLockConflict("ring", c.Logger)
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, "API changes are locked for this ring")
+		return
+	}
+
+	ringReleaseRequest, err := model.NewRingReleaseRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to deserialize ring release request body")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to deserialize ring release request body")
+		return
+	}
+	if ringReleaseRequest.ScheduleAt != 0 {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "a prepared release starts when committed and cannot be scheduled")
+		return
+	}
+
+	if !checkRingReleaseParameters(c, w, ring, ringReleaseRequest) {
+		return
+	}
+
+	// Preparing a release came with the state machine version 3, which
+	// stable rings of older versions move to right away.
+	if ring.UpgradeStateMachine() {
+		c.Logger.Infof("Upgrading ring to state machine version %d", ring.StateMachineVersion)
+	}
+
+	if !ring.ValidTransitionState(model.RingStateReleasePrepareRequested) {
+		c.Logger.Warnf("unable to prepare a ring release while in state %s", ring.State)
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to prepare a ring release while in state %s", ring.State), map[string]string{"state": ring.State})
+		return
+	}
+
+	if !checkConcurrentReleasesQuota(c, w, []*model.Ring{ring}) {
+		return
+	}
+
+	if ringReleaseRequest.Force && !checkRingsNotProtected(c, w, "force release", []*model.Ring{ring}) {
+		return
+	}
+	if ringReleaseRequest.Force && !requireForceApproval(c, w, model.ForceApprovalActionRelease, []*model.Ring{ring}, ringReleaseRequest) {
+		return
+	}
+
+	ringRelease := model.RingRelease{
+		Version:    ringReleaseRequest.Version,
+		Image:      ringReleaseRequest.Image,
+		Force:      ringReleaseRequest.Force,
+		SoakTime:   ringReleaseRequest.SoakTime,
+		Type:       ringReleaseRequest.Type,
+		Parameters: ringReleaseRequest.Parameters,
+		CreateAt:   time.Now().UnixNano(),
+	}
+
+	desiredRelease, err := c.Store.GetOrCreateRingRelease(&ringRelease)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get or create new ring release")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get or create new ring release")
+		return
+	}
+	if err = addRingReleaseLinks(c, desiredRelease, ringReleaseRequest.Links); err != nil {
+		c.Logger.WithError(err).Error("failed to add ring release links")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to add ring release links")
+		return
+	}
+	if err = addRingReleaseLabels(c, desiredRelease, ringReleaseRequest.Labels); err != nil {
+		c.Logger.WithError(err).Error("failed to add ring release labels")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to add ring release labels")
+		return
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeRing,
+		ID:        ring.ID,
+		NewState:  model.RingStateReleasePrepareRequested,
+		OldState:  ring.State,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"Environment": c.Environment, "ReleaseType": ringReleaseRequest.Type},
+		Labels:    ring.Annotations,
+		Metadata:  ring.Metadata,
+	}
+
+	ring.State = model.RingStateReleasePrepareRequested
+	ring.DesiredReleaseID = desiredRelease.ID
+	ring.ReleaseScheduledAt = 0
+
+	if err = c.Store.UpdateRing(ring); err != nil {
+		c.Logger.WithError(err).Error("failed to update ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update ring")
+		return
+	}
+
+	recordRingStateChange(c, ring, webhookPayload.OldState, webhookPayload.NewState)
+
+	if err := webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+		c.Logger.WithError(err).Error("unable to process and send webhooks")
+	}
+
+	unlockOnce()
+	c.Supervisor.Do() //nolint
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, ring)
+}
+
+// handleCommitReleaseRing responds to POST /api/ring/{ring}/release/commit,
+// starting the prepared release of the ring. The release starts right away,
+// its installation groups moving to release-pending, unless something would
+// keep the ring waiting, in which case nothing changes.
+func handleCommitReleaseRing(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ringID := vars["ring"]
+	c.Logger = c.Logger.WithField("ring", ringID)
+
+	ring, status, unlockOnce := lockRing(c, ringID)
+	if status != 0 {
+		outputStatusError(c, w, status, "ring")
+		return
+	}
+	defer unlockOnce()
+
+	if ring.APISecurityLock {
+		logSecurityLockConflict("ring", c.Logger)
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, "API changes are locked for this ring")
+		return
+	}
+
+	if ring.State != model.RingStateReleasePrepared {
+		c.Logger.Warnf("unable to commit a ring release while in state %s", ring.State)
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to commit a ring release while in state %s", ring.State), map[string]string{"state": ring.State})
+		return
+	}
+
+	installationGroups, err := c.Store.GetInstallationGroupsForRing(ring.ID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get ring installation groups")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get ring installation groups")
+		return
+	}
+
+	// Maintenance may have been scheduled since the release was prepared.
+	if err = checkMaintenanceConflicts(c, ring, installationGroups); err != nil {
+		c.Logger.WithError(err).Error("failed to check maintenance conflicts")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to check maintenance conflicts")
+		return
+	}
+
+	blockers, err := getRingReleaseBlockers(c, ring)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get ring release blockers")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get ring release blockers")
+		return
+	}
+	if len(blockers) > 0 {
+		messages := make([]string, 0, len(blockers))
+		for _, blocker := range blockers {
+			messages = append(messages, blocker.Message)
+		}
+		outputErrorWithDetails(c, w, http.StatusConflict, model.ErrorCodeReleaseBlocked, "the prepared release of the ring is blocked", map[string]string{"blockers": strings.Join(messages, "; ")})
+		return
+	}
+
+	for _, installationGroup := range installationGroups {
+		if !installationGroup.ValidInstallationGroupTransitionState(model.InstallationGroupReleasePending) {
+			outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to release installation group %s while in state %s", installationGroup.Name, installationGroup.State), map[string]string{"installationGroup": installationGroup.ID, "state": installationGroup.State})
+			return
+		}
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeRing,
+		ID:        ring.ID,
+		NewState:  model.RingStateReleaseRequested,
+		OldState:  ring.State,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"Environment": c.Environment},
+		Labels:    ring.Annotations,
+		Metadata:  ring.Metadata,
+	}
+
+	// Soak times were resolved while preparing the release, so only the
+	// states are left to change, all at once.
+	for _, installationGroup := range installationGroups {
+		installationGroup.State = model.InstallationGroupReleasePending
+	}
+	ring.State = model.RingStateReleaseRequested
+	ring.ReleaseStartAt = time.Now().UnixNano()
+
+	if err = c.Store.UpdateRingAndInstallationGroups(ring, installationGroups); err != nil {
+		c.Logger.WithError(err).Error("failed to update ring and installation groups")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update ring and installation groups")
+		return
+	}
+
+	recordRingStateChange(c, ring, webhookPayload.OldState, webhookPayload.NewState)
+
+	if err := webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+		c.Logger.WithError(err).Error("unable to process and send webhooks")
+	}
+
+	unlockOnce()
+	c.Supervisor.Do() //nolint
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, ring)
+}
+
+// checkMaintenanceConflicts records on the prepared ring the infrastructure
+// maintenance overlapping its release, from now until its estimated
+// completion, as the ring supervisor does for pending rings, so that it holds
+// the release as a release blocker. Maintenance that cannot be checked holds
+// the release too.
+func checkMaintenanceConflicts(c *Context, ring *model.Ring, installationGroups []*model.InstallationGroup) error {
+	var maintenanceConflict string
+	if c.MaintenanceChecker != nil {
+		events, err := c.Store.GetStateChangeEvents(&model.StateChangeEventFilter{RingID: ring.ID, PerPage: model.MaxStateChangeEventsPerQuery, Latest: true})
+		if err != nil {
+			return errors.Wrap(err, "failed to get ring state change events")
+		}
+
+		now := model.GetMillis()
+		completionAt := model.EstimateReleaseCompletion(ring, installationGroups, events, now)
+		if completionAt < now {
+			completionAt = now
+		}
+		conflicts, err := c.MaintenanceChecker.Conflicts(model.NewMaintenanceConflictQuery(ring, installationGroups, now, completionAt))
+		if err != nil {
+			c.Logger.WithError(err).Warn("Failed to look up maintenance conflicts, holding the release")
+			maintenanceConflict = "maintenance conflicts could not be checked"
+		} else if len(conflicts) > 0 {
+			maintenanceConflict = model.DescribeMaintenanceConflicts(conflicts)
+		}
+	}
+
+	if maintenanceConflict == ring.MaintenanceConflict {
+		return nil
+	}
+	ring.MaintenanceConflict = maintenanceConflict
+
+	return c.Store.UpdateRing(ring)
+}
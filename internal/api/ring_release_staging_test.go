@@ -0,0 +1,158 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMaintenanceChecker struct {
+	conflicts []*model.MaintenanceConflict
+}
+
+func (c *mockMaintenanceChecker) Conflicts(query *model.MaintenanceConflictQuery) ([]*model.MaintenanceConflict, error) {
+	return c.conflicts, nil
+}
+
+func TestRingReleaseStaging(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	maintenanceChecker := &mockMaintenanceChecker{}
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:              sqlStore,
+		Supervisor:         &mockSupervisor{},
+		Logger:             logger,
+		MaintenanceChecker: maintenanceChecker,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring := &model.Ring{
+		Name:                "ring1",
+		Priority:            2,
+		State:               model.RingStateStable,
+		StateMachineVersion: 2,
+	}
+	installationGroup := &model.InstallationGroup{
+		Name:  "group1",
+		State: model.InstallationGroupStable,
+	}
+	require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+
+	releaseRequest := &model.RingReleaseRequest{
+		Image:   "mattermost/mattermost-enterprise-edition",
+		Version: "7.2.0",
+	}
+
+	t.Run("commit requires a prepared release", func(t *testing.T) {
+		_, err := client.CommitRingRelease(ring.ID)
+		apiErr := requireAPIError(t, err, 400)
+		require.Equal(t, model.ErrorCodeInvalidStateTransition, apiErr.Code)
+	})
+
+	t.Run("prepared release cannot be scheduled", func(t *testing.T) {
+		_, err := client.PrepareRingRelease(ring.ID, &model.RingReleaseRequest{
+			Image:      releaseRequest.Image,
+			Version:    releaseRequest.Version,
+			ScheduleAt: 1,
+		})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("unknown ring", func(t *testing.T) {
+		_, err := client.PrepareRingRelease(model.NewID(), releaseRequest)
+		requireAPIError(t, err, 404)
+		_, err = client.CommitRingRelease(model.NewID())
+		requireAPIError(t, err, 404)
+	})
+
+	t.Run("prepare", func(t *testing.T) {
+		prepared, err := client.PrepareRingRelease(ring.ID, releaseRequest)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePrepareRequested, prepared.State)
+		require.Equal(t, model.CurrentStateMachineVersion, prepared.StateMachineVersion)
+
+		release, err := client.GetRingRelease(prepared.DesiredReleaseID)
+		require.NoError(t, err)
+		require.Equal(t, releaseRequest.Version, release.Version)
+
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationGroupStable, installationGroup.State)
+
+		// The supervisor then prepares the release.
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		ring.State = model.RingStateReleasePrepared
+		require.NoError(t, sqlStore.UpdateRing(ring))
+	})
+
+	t.Run("commit is blocked by the rings released first", func(t *testing.T) {
+		other := &model.Ring{Name: "ring0", Priority: 1, State: model.RingStateReleasePending}
+		require.NoError(t, sqlStore.CreateRing(other, nil))
+
+		_, err := client.CommitRingRelease(ring.ID)
+		apiErr := requireAPIError(t, err, 409)
+		require.Equal(t, model.ErrorCodeReleaseBlocked, apiErr.Code)
+		require.Contains(t, apiErr.Details["blockers"], "ring ring0 with priority 1 is released first")
+
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePrepared, ring.State)
+
+		other.State = model.RingStateStable
+		require.NoError(t, sqlStore.UpdateRing(other))
+	})
+
+	t.Run("commit is held by maintenance scheduled since the release was prepared", func(t *testing.T) {
+		maintenanceChecker.conflicts = []*model.MaintenanceConflict{{Name: "db-upgrade", StartAt: 1654513200000, EndAt: 1654520400000}}
+
+		_, err := client.CommitRingRelease(ring.ID)
+		apiErr := requireAPIError(t, err, 409)
+		require.Equal(t, model.ErrorCodeReleaseBlocked, apiErr.Code)
+		require.Contains(t, apiErr.Details["blockers"], "maintenance db-upgrade from 2022-06-06T11:00:00Z to 2022-06-06T13:00:00Z")
+
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePrepared, ring.State)
+		require.NotEmpty(t, ring.MaintenanceConflict)
+
+		maintenanceChecker.conflicts = nil
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		committed, err := client.CommitRingRelease(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseRequested, committed.State)
+		require.NotZero(t, committed.ReleaseStartAt)
+		require.Empty(t, committed.MaintenanceConflict)
+
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationGroupReleasePending, installationGroup.State)
+
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{
+			RingID:  ring.ID,
+			State:   model.RingStateReleaseRequested,
+			PerPage: model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, model.RingStateReleasePrepared, events[0].OldState)
+	})
+}
@@ -290,7 +290,7 @@ func TestCreateRing(t *testing.T) {
 			InstallationGroup: &model.InstallationGroup{Name: "prod-12345"},
 			SoakTime:          3600,
 		})
-		require.EqualError(t, err, "failed with status code 400")
+		requireAPIError(t, err, 400)
 	})
 
 	t.Run("valid", func(t *testing.T) {
@@ -305,6 +305,160 @@ func TestCreateRing(t *testing.T) {
 		require.Equal(t, 1, ring.Priority)
 		require.Equal(t, 3600, ring.SoakTime)
 	})
+
+	t.Run("invalid annotations", func(t *testing.T) {
+		_, err := client.CreateRing(&model.CreateRingRequest{
+			Priority:          1,
+			InstallationGroup: &model.InstallationGroup{Name: "prod-12345"},
+			Annotations:       model.Annotations{"change ticket": "CHG-1234"},
+		})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("annotations", func(t *testing.T) {
+		ring, err := client.CreateRing(&model.CreateRingRequest{
+			Priority: 1,
+			InstallationGroup: &model.InstallationGroup{
+				Name:        "prod-annotated",
+				Annotations: model.Annotations{"team": "platform"},
+			},
+			Annotations: model.Annotations{"Change-Ticket": "CHG-1234"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.Annotations{"Change-Ticket": "CHG-1234"}, ring.Annotations)
+
+		ring, err = client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.Annotations{"Change-Ticket": "CHG-1234"}, ring.Annotations)
+		require.Equal(t, model.Annotations{"team": "platform"}, ring.InstallationGroups[0].Annotations)
+
+		ring, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{
+			Annotations: model.Annotations{"Change-Ticket": "CHG-5678"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.Annotations{"Change-Ticket": "CHG-5678"}, ring.Annotations)
+	})
+
+	t.Run("invalid metadata", func(t *testing.T) {
+		_, err := client.CreateRing(&model.CreateRingRequest{
+			Priority:          1,
+			InstallationGroup: &model.InstallationGroup{Name: "prod-12345"},
+			Metadata:          model.Metadata(`["payments"]`),
+		})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		ring, err := client.CreateRing(&model.CreateRingRequest{
+			Priority: 1,
+			InstallationGroup: &model.InstallationGroup{
+				Name:     "prod-metadata",
+				Metadata: model.Metadata(`{"customer":"acme"}`),
+			},
+			Metadata: model.Metadata(`{"team":"payments","routing":{"pager":"p1"}}`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.Metadata(`{"team":"payments","routing":{"pager":"p1"}}`), ring.Metadata)
+
+		ring, err = client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.Metadata(`{"customer":"acme"}`), ring.InstallationGroups[0].Metadata)
+		installationGroupID := ring.InstallationGroups[0].ID
+
+		ring, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{
+			Metadata: model.Metadata(`{"team":"platform"}`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.Metadata(`{"team":"platform"}`), ring.Metadata)
+
+		installationGroup, err := client.UpdateInstallationGroup(installationGroupID, &model.UpdateInstallationGroupRequest{
+			Metadata: model.Metadata(`{"customer":"globex"}`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.Metadata(`{"customer":"globex"}`), installationGroup.Metadata)
+	})
+
+	t.Run("invalid notification emails", func(t *testing.T) {
+		_, err := client.CreateRing(&model.CreateRingRequest{
+			Priority:           1,
+			InstallationGroup:  &model.InstallationGroup{Name: "prod-12345"},
+			NotificationEmails: model.NotificationEmails{"not an email"},
+		})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("notification emails", func(t *testing.T) {
+		ring, err := client.CreateRing(&model.CreateRingRequest{
+			Priority:           1,
+			InstallationGroup:  &model.InstallationGroup{Name: "prod-notified"},
+			NotificationEmails: model.NotificationEmails{"release-managers@example.com"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.NotificationEmails{"release-managers@example.com"}, ring.NotificationEmails)
+
+		ring, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{Priority: 2})
+		require.NoError(t, err)
+		require.Equal(t, model.NotificationEmails{"release-managers@example.com"}, ring.NotificationEmails)
+
+		ring, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{NotificationEmails: &model.NotificationEmails{}})
+		require.NoError(t, err)
+		require.Empty(t, ring.NotificationEmails)
+	})
+
+	t.Run("jira project", func(t *testing.T) {
+		_, err := client.CreateRing(&model.CreateRingRequest{
+			Priority:          1,
+			InstallationGroup: &model.InstallationGroup{Name: "prod-12345"},
+			JiraProject:       "rel",
+		})
+		requireAPIError(t, err, 400)
+
+		ring, err := client.CreateRing(&model.CreateRingRequest{
+			Priority:          1,
+			InstallationGroup: &model.InstallationGroup{Name: "prod-jira"},
+			JiraProject:       "REL",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "REL", ring.JiraProject)
+
+		noProject := ""
+		ring, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{JiraProject: &noProject})
+		require.NoError(t, err)
+		require.Empty(t, ring.JiraProject)
+	})
+
+	t.Run("contacts", func(t *testing.T) {
+		_, err := client.CreateRing(&model.CreateRingRequest{
+			Priority:          1,
+			InstallationGroup: &model.InstallationGroup{Name: "prod-12345"},
+			SlackChannel:      "platform-alerts",
+		})
+		requireAPIError(t, err, 400)
+
+		ring, err := client.CreateRing(&model.CreateRingRequest{
+			Priority:          1,
+			InstallationGroup: &model.InstallationGroup{Name: "prod-contacts"},
+			OwnerTeam:         "Platform",
+			SlackChannel:      "#platform-alerts",
+			EscalationPolicy:  "PX12345",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Platform", ring.OwnerTeam)
+		require.Equal(t, "#platform-alerts", ring.SlackChannel)
+		require.Equal(t, "PX12345", ring.EscalationPolicy)
+
+		invalidPolicy := "http://pager.example.com/policy"
+		_, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{EscalationPolicy: &invalidPolicy})
+		requireAPIError(t, err, 500)
+
+		policy := "https://example.pagerduty.com/escalation_policies/PX12345"
+		noChannel := ""
+		ring, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{EscalationPolicy: &policy, SlackChannel: &noChannel})
+		require.NoError(t, err)
+		require.Equal(t, "Platform", ring.OwnerTeam)
+		require.Empty(t, ring.SlackChannel)
+		require.Equal(t, policy, ring.EscalationPolicy)
+	})
 }
 
 func TestRetryCreateRing(t *testing.T) {
@@ -332,7 +486,7 @@ func TestRetryCreateRing(t *testing.T) {
 
 	t.Run("unknown ring", func(t *testing.T) {
 		err := client.RetryCreateRing(model.NewID())
-		require.EqualError(t, err, "failed with status code 404")
+		requireAPIError(t, err, 404)
 	})
 
 	t.Run("while locked", func(t *testing.T) {
@@ -352,7 +506,8 @@ func TestRetryCreateRing(t *testing.T) {
 		}()
 
 		err = client.RetryCreateRing(ring1.ID)
-		require.EqualError(t, err, "failed with status code 409")
+		apiErr := requireAPIError(t, err, 409)
+		require.Equal(t, model.ErrorCodeLockConflict, apiErr.Code)
 	})
 
 	t.Run("while creating", func(t *testing.T) {
@@ -374,7 +529,7 @@ func TestRetryCreateRing(t *testing.T) {
 		require.NoError(t, err)
 
 		err = client.RetryCreateRing(ring1.ID)
-		require.EqualError(t, err, "failed with status code 400")
+		requireAPIError(t, err, 400)
 	})
 
 	t.Run("while creation failed", func(t *testing.T) {
@@ -416,7 +571,7 @@ func TestReleaseRing(t *testing.T) {
 
 	t.Run("unknown ring", func(t *testing.T) {
 		ringResp, err := client.ReleaseRing(model.NewID(), nil)
-		require.EqualError(t, err, "failed with status code 404")
+		requireAPIError(t, err, 404)
 		assert.Nil(t, ringResp)
 	})
 
@@ -437,7 +592,7 @@ func TestReleaseRing(t *testing.T) {
 		}()
 
 		ringResp, err := client.ReleaseRing(ring1.ID, nil)
-		require.EqualError(t, err, "failed with status code 409")
+		requireAPIError(t, err, 409)
 		assert.Nil(t, ringResp)
 	})
 
@@ -446,20 +601,89 @@ func TestReleaseRing(t *testing.T) {
 		require.NoError(t, err)
 
 		ringResp, err := client.ReleaseRing(ring1.ID, nil)
-		require.EqualError(t, err, "failed with status code 403")
+		requireAPIError(t, err, 403)
 		assert.Nil(t, ringResp)
 
 		err = sqlStore.UnlockRingAPI(ring1.ID)
 		require.NoError(t, err)
 	})
 
+	t.Run("hotfix release", func(t *testing.T) {
+		ring1.State = model.RingStateStable
+		err = sqlStore.UpdateRing(ring1)
+		require.NoError(t, err)
+
+		ringResp, err := client.ReleaseRing(ring1.ID, &model.RingReleaseRequest{
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Version: "7.0.1",
+			Type:    model.ReleaseTypeHotfix,
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePending, ringResp.State)
+
+		release, err := client.GetRingRelease(ringResp.DesiredReleaseID)
+		require.NoError(t, err)
+		require.Equal(t, model.ReleaseTypeHotfix, release.Type)
+		require.Empty(t, release.DatabaseSnapshots)
+
+		snapshot := &model.DatabaseSnapshot{
+			ReleaseID:      release.ID,
+			RingID:         ring1.ID,
+			InstallationID: "installation1",
+			SnapshotID:     "backup1",
+			Source:         model.DatabaseSnapshotSourceProvisioner,
+		}
+		require.NoError(t, sqlStore.CreateDatabaseSnapshots([]*model.DatabaseSnapshot{snapshot}))
+		release, err = client.GetRingRelease(ringResp.DesiredReleaseID)
+		require.NoError(t, err)
+		require.Equal(t, []*model.DatabaseSnapshot{snapshot}, release.DatabaseSnapshots)
+	})
+
+	t.Run("unknown release type", func(t *testing.T) {
+		ring1.State = model.RingStateStable
+		err = sqlStore.UpdateRing(ring1)
+		require.NoError(t, err)
+
+		_, err := client.ReleaseRing(ring1.ID, &model.RingReleaseRequest{Image: "image", Version: "1.0.0", Type: "urgent"})
+		require.Error(t, err)
+	})
+
+	t.Run("release parameters", func(t *testing.T) {
+		ring1.State = model.RingStateStable
+		err = sqlStore.UpdateRing(ring1)
+		require.NoError(t, err)
+
+		parameters := model.ReleaseParameters{
+			"prod-12345": {MattermostEnv: map[string]string{"MM_DATA_RESIDENCY": "eu"}},
+		}
+
+		_, err := client.ReleaseRing(ring1.ID, &model.RingReleaseRequest{
+			Image:      "mattermost/mattermost-enterprise-edition",
+			Version:    "7.0.2",
+			Parameters: model.ReleaseParameters{"unknown-ig": {MattermostEnv: map[string]string{"MM_DATA_RESIDENCY": "us"}}},
+		})
+		requireAPIError(t, err, 400)
+
+		ringResp, err := client.ReleaseRing(ring1.ID, &model.RingReleaseRequest{
+			Image:      "mattermost/mattermost-enterprise-edition",
+			Version:    "7.0.2",
+			Parameters: parameters,
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePending, ringResp.State)
+
+		release, err := client.GetRingRelease(ringResp.DesiredReleaseID)
+		require.NoError(t, err)
+		require.Equal(t, parameters, release.Parameters)
+	})
+
 	t.Run("while releasing", func(t *testing.T) {
 		ring1.State = model.RingStateReleaseRequested
 		err = sqlStore.UpdateRing(ring1)
 		require.NoError(t, err)
 
 		ringResp, err := client.ReleaseRing(ring1.ID, nil)
-		require.EqualError(t, err, "failed with status code 400")
+		requireAPIError(t, err, 400)
 		assert.Nil(t, ringResp)
 
 		ring1, err = client.GetRing(ring1.ID)
@@ -473,11 +697,148 @@ func TestReleaseRing(t *testing.T) {
 		require.NoError(t, err)
 
 		ringResp, err := client.ReleaseRing(ring1.ID, nil)
-		require.EqualError(t, err, "failed with status code 400")
+		requireAPIError(t, err, 400)
 		assert.Nil(t, ringResp)
 	})
 }
 
+func TestScheduledReleases(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring1, err := client.CreateRing(&model.CreateRingRequest{
+		Priority:          1,
+		InstallationGroup: &model.InstallationGroup{Name: "prod-12345"},
+		SoakTime:          3600,
+	})
+	require.NoError(t, err)
+	ring1.State = model.RingStateStable
+	require.NoError(t, sqlStore.UpdateRing(ring1))
+
+	t.Run("none scheduled", func(t *testing.T) {
+		scheduledReleases, err := client.GetScheduledReleases()
+		require.NoError(t, err)
+		require.Empty(t, scheduledReleases)
+
+		_, err = client.CancelScheduledRelease(ring1.ID)
+		requireAPIError(t, err, 400)
+	})
+
+	scheduleAt := time.Now().Add(time.Hour).UnixNano()
+
+	t.Run("schedule", func(t *testing.T) {
+		_, err := client.ReleaseRing(ring1.ID, &model.RingReleaseRequest{
+			Image:      "mattermost/mattermost-enterprise-edition",
+			Version:    "7.0.2",
+			ScheduleAt: -1,
+		})
+		requireAPIError(t, err, 500)
+
+		ringResp, err := client.ReleaseRing(ring1.ID, &model.RingReleaseRequest{
+			Image:      "mattermost/mattermost-enterprise-edition",
+			Version:    "7.0.2",
+			ScheduleAt: scheduleAt,
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePending, ringResp.State)
+		require.Equal(t, scheduleAt, ringResp.ReleaseScheduledAt)
+
+		scheduledReleases, err := client.GetScheduledReleases()
+		require.NoError(t, err)
+		require.Equal(t, []*model.ScheduledRelease{{
+			RingID:     ring1.ID,
+			ReleaseID:  ringResp.DesiredReleaseID,
+			Image:      "mattermost/mattermost-enterprise-edition",
+			Version:    "7.0.2",
+			Type:       model.ReleaseTypeStandard,
+			ScheduleAt: scheduleAt,
+		}}, scheduledReleases)
+
+		blockers, err := client.GetRingBlockers(ring1.ID)
+		require.NoError(t, err)
+		require.Len(t, blockers.Blockers, 1)
+		require.Equal(t, model.ReleaseBlockerReleaseScheduled, blockers.Blockers[0].Reason)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		_, err := client.CancelScheduledRelease(model.NewID())
+		requireAPIError(t, err, 404)
+
+		ringResp, err := client.CancelScheduledRelease(ring1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, ringResp.State)
+		require.Equal(t, ringResp.ActiveReleaseID, ringResp.DesiredReleaseID)
+		require.Zero(t, ringResp.ReleaseScheduledAt)
+
+		scheduledReleases, err := client.GetScheduledReleases()
+		require.NoError(t, err)
+		require.Empty(t, scheduledReleases)
+	})
+}
+
+func TestResumeAllReleases(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring1, err := client.CreateRing(&model.CreateRingRequest{
+		Priority:          1,
+		InstallationGroup: &model.InstallationGroup{Name: "prod-12345"},
+	})
+	require.NoError(t, err)
+	ring1.State = model.RingStateReleaseInProgress
+	require.NoError(t, sqlStore.UpdateRing(ring1))
+
+	_, err = client.PauseRing(ring1.ID)
+	require.NoError(t, err)
+
+	resumeAll := func() []*model.Ring {
+		resp, err := http.Post(ts.URL+"/api/v1/rings/release/resume", "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+		rings, err := model.RingsFromReader(resp.Body)
+		require.NoError(t, err)
+		return rings
+	}
+
+	t.Run("paused ring", func(t *testing.T) {
+		rings := resumeAll()
+		require.Len(t, rings, 1)
+		require.Equal(t, ring1.ID, rings[0].ID)
+		require.Equal(t, model.RingStateReleaseInProgress, rings[0].State)
+	})
+
+	t.Run("no paused ring", func(t *testing.T) {
+		require.Empty(t, resumeAll())
+	})
+}
+
 func TestDeleteRing(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := store.MakeTestSQLStore(t, logger)
@@ -502,7 +863,7 @@ func TestDeleteRing(t *testing.T) {
 
 	t.Run("unknown ring", func(t *testing.T) {
 		err := client.DeleteRing(model.NewID())
-		require.EqualError(t, err, "failed with status code 404")
+		requireAPIError(t, err, 404)
 	})
 
 	t.Run("while locked", func(t *testing.T) {
@@ -526,7 +887,7 @@ func TestDeleteRing(t *testing.T) {
 		}()
 
 		err = client.DeleteRing(ring1.ID)
-		require.EqualError(t, err, "failed with status code 409")
+		requireAPIError(t, err, 409)
 	})
 
 	t.Run("while api-security-locked", func(t *testing.T) {
@@ -534,7 +895,7 @@ func TestDeleteRing(t *testing.T) {
 		require.NoError(t, err)
 
 		err := client.DeleteRing(ring1.ID)
-		require.EqualError(t, err, "failed with status code 403")
+		requireAPIError(t, err, 403)
 
 		err = sqlStore.UnlockRingAPI(ring1.ID)
 		require.NoError(t, err)
@@ -546,6 +907,7 @@ func TestDeleteRing(t *testing.T) {
 		model.RingStateCreationRequested,
 		model.RingStateCreationFailed,
 		model.RingStateReleaseFailed,
+		model.RingStateDeletionPending,
 		model.RingStateDeletionRequested,
 		model.RingStateDeletionFailed,
 	}
@@ -566,4 +928,791 @@ func TestDeleteRing(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("with a negative grace period", func(t *testing.T) {
+		resp, err := httpDelete(fmt.Sprintf("%s/api/ring/%s?delete_after=-1", ts.URL, ring1.ID))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("with a grace period", func(t *testing.T) {
+		ring1.State = model.RingStateStable
+		err = sqlStore.UpdateRing(ring1)
+		require.NoError(t, err)
+
+		err = client.DeleteRingWithRequest(ring1.ID, &model.DeleteRingRequest{DeleteAfter: 3600})
+		require.NoError(t, err)
+
+		ring1, err = client.GetRing(ring1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateDeletionPending, ring1.State)
+		require.Greater(t, ring1.DeletionScheduledAt, time.Now().Add(59*time.Minute).UnixNano())
+
+		t.Run("cancel deletion", func(t *testing.T) {
+			ring1, err = client.CancelRingDeletion(ring1.ID)
+			require.NoError(t, err)
+			require.Equal(t, model.RingStateStable, ring1.State)
+			require.Zero(t, ring1.DeletionScheduledAt)
+		})
+
+		t.Run("cancel deletion when not pending", func(t *testing.T) {
+			_, err = client.CancelRingDeletion(ring1.ID)
+			apiErr := requireAPIError(t, err, http.StatusBadRequest)
+			require.Equal(t, model.ErrorCodeInvalidStateTransition, apiErr.Code)
+		})
+
+		t.Run("cancel deletion restores the state before deletion", func(t *testing.T) {
+			ring1.State = model.RingStateReleaseFailed
+			err = sqlStore.UpdateRing(ring1)
+			require.NoError(t, err)
+
+			err = client.DeleteRingWithRequest(ring1.ID, &model.DeleteRingRequest{DeleteAfter: 3600})
+			require.NoError(t, err)
+			err = client.DeleteRingWithRequest(ring1.ID, &model.DeleteRingRequest{DeleteAfter: 7200})
+			require.NoError(t, err)
+
+			ring1, err = client.CancelRingDeletion(ring1.ID)
+			require.NoError(t, err)
+			require.Equal(t, model.RingStateReleaseFailed, ring1.State)
+			require.Empty(t, ring1.PreDeletionState)
+		})
+	})
+}
+
+func httpDelete(u string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return http.DefaultClient.Do(req)
+}
+
+func TestGetRingTimeline(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("unknown ring", func(t *testing.T) {
+		timeline, err := client.GetRingTimeline(model.NewID(), model.DefaultTimelineReleases)
+		require.NoError(t, err)
+		require.Nil(t, timeline)
+	})
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Priority: 1, SoakTime: 60})
+	require.NoError(t, err)
+
+	t.Run("invalid releases", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("%s/api/ring/%s/timeline?releases=0", ts.URL, ring.ID))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("timeline from state change events", func(t *testing.T) {
+		ring.DesiredReleaseID = model.NewID()
+		events := []*model.StateChangeEvent{
+			{ResourceType: model.TypeRing, ResourceID: ring.ID, NewState: model.RingStateReleasePending, Timestamp: 1000},
+			{ResourceType: model.TypeRing, ResourceID: ring.ID, NewState: model.RingStateReleaseRequested, Timestamp: 2000},
+			{ResourceType: model.TypeRing, ResourceID: ring.ID, NewState: model.RingStateStable, Timestamp: 5000},
+		}
+		for _, event := range events {
+			event.RingID = ring.ID
+			event.ReleaseID = ring.DesiredReleaseID
+			require.NoError(t, sqlStore.CreateStateChangeEvent(event))
+		}
+
+		timeline, err := client.GetRingTimeline(ring.ID, model.DefaultTimelineReleases)
+		require.NoError(t, err)
+		require.Equal(t, ring.ID, timeline.RingID)
+		require.Len(t, timeline.Releases, 1)
+		require.Equal(t, ring.DesiredReleaseID, timeline.Releases[0].ReleaseID)
+		require.Equal(t, int64(4000), timeline.Releases[0].Duration)
+		require.Equal(t, model.RingStateStable, timeline.Releases[0].FinalState)
+		require.Len(t, timeline.Releases[0].Spans, 2)
+	})
+}
+
+func TestGetRingsAsOf(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("invalid as of", func(t *testing.T) {
+		resp, err := http.Get(fmt.Sprintf("%s/api/rings?as_of=invalid", ts.URL))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, err = client.GetRings(&model.GetRingsRequest{PerPage: 10, AsOf: model.GetMillis() + time.Hour.Milliseconds()})
+		require.EqualError(t, err, "failed with status code 400: as_of cannot be in the future")
+	})
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Priority: 1, SoakTime: 60})
+	require.NoError(t, err)
+	previousReleaseID := ring.ActiveReleaseID
+	releaseID := model.NewID()
+
+	events := []*model.StateChangeEvent{
+		{ResourceType: model.TypeRing, ReleaseID: previousReleaseID, OldState: model.RingStateCreationRequested, NewState: model.RingStateStable, Timestamp: ring.CreateAt + 1},
+		{ResourceType: model.TypeRing, ReleaseID: releaseID, OldState: model.RingStateStable, NewState: model.RingStateReleaseInProgress, Timestamp: ring.CreateAt + 2},
+		{ResourceType: model.TypeRing, ReleaseID: releaseID, OldState: model.RingStateSoakingRequested, NewState: model.RingStateStable, Timestamp: ring.CreateAt + 3},
+	}
+	for _, event := range events {
+		event.ResourceID = ring.ID
+		event.RingID = ring.ID
+		require.NoError(t, sqlStore.CreateStateChangeEvent(event))
+	}
+	// Wait for the events to be in the past.
+	time.Sleep(5 * time.Millisecond)
+
+	t.Run("before the ring was created", func(t *testing.T) {
+		rings, err := client.GetRings(&model.GetRingsRequest{PerPage: 10, AsOf: ring.CreateAt - 1})
+		require.NoError(t, err)
+		require.Empty(t, rings)
+	})
+
+	t.Run("during a release", func(t *testing.T) {
+		rings, err := client.GetRings(&model.GetRingsRequest{PerPage: 10, AsOf: ring.CreateAt + 2})
+		require.NoError(t, err)
+		require.Len(t, rings, 1)
+		require.Equal(t, ring.ID, rings[0].ID)
+		require.Equal(t, ring.CreateAt+2, rings[0].AsOf)
+		require.Equal(t, model.RingStateReleaseInProgress, rings[0].State)
+		require.Equal(t, previousReleaseID, rings[0].ActiveReleaseID)
+		require.Equal(t, releaseID, rings[0].DesiredReleaseID)
+	})
+
+	t.Run("after the release", func(t *testing.T) {
+		rings, err := client.GetRings(&model.GetRingsRequest{PerPage: 10, AsOf: ring.CreateAt + 3})
+		require.NoError(t, err)
+		require.Len(t, rings, 1)
+		require.Equal(t, model.RingStateStable, rings[0].State)
+		require.Equal(t, releaseID, rings[0].ActiveReleaseID)
+
+		rings, err = client.GetRings(&model.GetRingsRequest{Page: 1, PerPage: 10, AsOf: ring.CreateAt + 3})
+		require.NoError(t, err)
+		require.Empty(t, rings)
+	})
+}
+
+func TestGetRingReleaseHealth(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("unknown release", func(t *testing.T) {
+		_, err := client.GetRingReleaseHealth(model.NewID(), "")
+		requireAPIError(t, err, http.StatusNotFound)
+	})
+
+	release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{
+		Image:   "mattermost/mattermost-enterprise-edition",
+		Version: "7.0.1",
+	})
+	require.NoError(t, err)
+
+	ring1 := model.NewID()
+	ring2 := model.NewID()
+	snapshots := []*model.HealthSnapshot{
+		{InstallationGroupID: "ig1", RingID: ring1, Stage: model.HealthSnapshotPreRelease, InstallationsTotal: 2},
+		{InstallationGroupID: "ig1", RingID: ring1, Stage: model.HealthSnapshotPostSoak, InstallationsTotal: 2, InstallationsUpdated: 2},
+		{InstallationGroupID: "ig2", RingID: ring2, Stage: model.HealthSnapshotPreRelease, InstallationsTotal: 1},
+	}
+	for _, snapshot := range snapshots {
+		snapshot.ReleaseID = release.ID
+		require.NoError(t, sqlStore.CreateHealthSnapshot(snapshot))
+	}
+
+	t.Run("all rings", func(t *testing.T) {
+		diffs, err := client.GetRingReleaseHealth(release.ID, "")
+		require.NoError(t, err)
+		require.Len(t, diffs, 2)
+		require.Equal(t, "ig1", diffs[0].InstallationGroupID)
+		require.Equal(t, map[string]int64{"InstallationsUpdated": 2}, diffs[0].Changes)
+		require.Equal(t, "ig2", diffs[1].InstallationGroupID)
+		require.Nil(t, diffs[1].After)
+	})
+
+	t.Run("one ring", func(t *testing.T) {
+		diffs, err := client.GetRingReleaseHealth(release.ID, ring2)
+		require.NoError(t, err)
+		require.Len(t, diffs, 1)
+		require.Equal(t, "ig2", diffs[0].InstallationGroupID)
+	})
+}
+
+func TestGetRingEstimatedCompletion(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Priority: 1, SoakTime: 60})
+	require.NoError(t, err)
+
+	t.Run("no release in progress", func(t *testing.T) {
+		ring, err = client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Zero(t, ring.EstimatedCompletionAt)
+	})
+
+	t.Run("release pending", func(t *testing.T) {
+		ring.State = model.RingStateReleasePending
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		now := model.GetMillis()
+		ring, err = client.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, ring.EstimatedCompletionAt, now+60*1000)
+
+		stable, err := client.CreateRing(&model.CreateRingRequest{Priority: 2, SoakTime: 60})
+		require.NoError(t, err)
+
+		rings, err := client.GetRings(&model.GetRingsRequest{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, rings, 2)
+		for _, r := range rings {
+			if r.ID == stable.ID {
+				require.Zero(t, r.EstimatedCompletionAt)
+			} else {
+				require.NotZero(t, r.EstimatedCompletionAt)
+			}
+		}
+	})
+}
+
+func TestGetRingBlockers(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("unknown ring", func(t *testing.T) {
+		blockers, err := client.GetRingBlockers(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, blockers)
+	})
+
+	first := &model.Ring{Name: "first", Priority: 1, State: model.RingStateStable}
+	firstGroup := &model.InstallationGroup{Name: "first-group", State: model.InstallationGroupStable}
+	require.NoError(t, sqlStore.CreateRing(first, firstGroup))
+	second := &model.Ring{Name: "second", Priority: 2, State: model.RingStateStable}
+	secondGroup := &model.InstallationGroup{Name: "second-group", State: model.InstallationGroupStable}
+	require.NoError(t, sqlStore.CreateRing(second, secondGroup))
+
+	t.Run("stable", func(t *testing.T) {
+		blockers, err := client.GetRingBlockers(second.ID)
+		require.NoError(t, err)
+		require.Equal(t, &model.ReleaseBlockers{RingID: second.ID, State: model.RingStateStable, Blockers: []*model.ReleaseBlocker{}}, blockers)
+	})
+
+	t.Run("outside of release windows", func(t *testing.T) {
+		second.State = model.RingStateReleasePending
+		require.NoError(t, sqlStore.UpdateRing(second))
+
+		// A window starting in two hours does not contain the current time.
+		now := time.Now().UTC()
+		windows := model.TimeWindows{{Start: now.Add(2 * time.Hour).Format("15:04"), End: now.Add(3 * time.Hour).Format("15:04")}}
+		updated, err := client.UpdateRing(second.ID, &model.UpdateRingRequest{ReleaseWindows: &windows})
+		require.NoError(t, err)
+		require.Equal(t, windows, updated.ReleaseWindows)
+
+		blockers, err := client.GetRingBlockers(second.ID)
+		require.NoError(t, err)
+		require.Len(t, blockers.Blockers, 1)
+		require.Equal(t, model.ReleaseBlockerReleaseWindow, blockers.Blockers[0].Reason)
+
+		windows = model.TimeWindows{}
+		updated, err = client.UpdateRing(second.ID, &model.UpdateRingRequest{ReleaseWindows: &windows})
+		require.NoError(t, err)
+		require.Empty(t, updated.ReleaseWindows)
+
+		blockers, err = client.GetRingBlockers(second.ID)
+		require.NoError(t, err)
+		require.Empty(t, blockers.Blockers)
+	})
+
+	t.Run("paused", func(t *testing.T) {
+		second.State = model.RingStateReleasePaused
+		require.NoError(t, sqlStore.UpdateRing(second))
+
+		blockers, err := client.GetRingBlockers(second.ID)
+		require.NoError(t, err)
+		require.Len(t, blockers.Blockers, 1)
+		require.Equal(t, model.ReleaseBlockerPaused, blockers.Blockers[0].Reason)
+	})
+
+	t.Run("ring with priority pending", func(t *testing.T) {
+		first.State = model.RingStateReleasePending
+		require.NoError(t, sqlStore.UpdateRing(first))
+		second.State = model.RingStateReleasePending
+		require.NoError(t, sqlStore.UpdateRing(second))
+
+		blockers, err := client.GetRingBlockers(second.ID)
+		require.NoError(t, err)
+		require.Len(t, blockers.Blockers, 1)
+		require.Equal(t, model.ReleaseBlockerRingPriority, blockers.Blockers[0].Reason)
+		require.Equal(t, first.ID, blockers.Blockers[0].RingID)
+
+		blockers, err = client.GetRingBlockers(first.ID)
+		require.NoError(t, err)
+		require.Empty(t, blockers.Blockers)
+	})
+
+	t.Run("ring releasing", func(t *testing.T) {
+		first.State = model.RingStateReleaseInProgress
+		require.NoError(t, sqlStore.UpdateRing(first))
+
+		// A ring releasing is also pending work, so its priority is reported too.
+		blockers, err := client.GetRingBlockers(second.ID)
+		require.NoError(t, err)
+		require.Len(t, blockers.Blockers, 2)
+		require.Equal(t, model.ReleaseBlockerRingReleasing, blockers.Blockers[0].Reason)
+		require.Equal(t, first.ID, blockers.Blockers[0].RingID)
+		require.Equal(t, model.ReleaseBlockerRingPriority, blockers.Blockers[1].Reason)
+	})
+
+	t.Run("installation group locked", func(t *testing.T) {
+		first.State = model.RingStateStable
+		require.NoError(t, sqlStore.UpdateRing(first))
+		second.State = model.RingStateReleaseInProgress
+		require.NoError(t, sqlStore.UpdateRing(second))
+		secondGroup.State = model.InstallationGroupReleasePending
+		require.NoError(t, sqlStore.UpdateInstallationGroup(secondGroup))
+		locked, err := sqlStore.LockRingInstallationGroup(firstGroup.ID, "server1")
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		blockers, err := client.GetRingBlockers(second.ID)
+		require.NoError(t, err)
+		require.Equal(t, []*model.ReleaseBlocker{{
+			Reason:              model.ReleaseBlockerInstallationGroupLocked,
+			Message:             "installation group first-group is under lock by server1",
+			InstallationGroupID: firstGroup.ID,
+		}}, blockers.Blockers)
+	})
+
+	t.Run("repeated failures", func(t *testing.T) {
+		first.State = model.RingStateReleaseFailed
+		first.FailureIssueURL = "https://github.com/mattermost/elrond/issues/1"
+		require.NoError(t, sqlStore.UpdateRing(first))
+
+		blockers, err := client.GetRingBlockers(first.ID)
+		require.NoError(t, err)
+		require.Equal(t, []*model.ReleaseBlocker{{
+			Reason:  model.ReleaseBlockerRepeatedFailures,
+			Message: "repeated release failures of ring first are tracked in https://github.com/mattermost/elrond/issues/1",
+			RingID:  first.ID,
+			URL:     "https://github.com/mattermost/elrond/issues/1",
+		}}, blockers.Blockers)
+	})
+}
+
+func TestRingInstallationGroupMembershipDuringRelease(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := client.CreateRing(&model.CreateRingRequest{Priority: 1, InstallationGroupPolicy: "later"})
+		require.Error(t, err)
+	})
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Priority: 1, InstallationGroupPolicy: model.InstallationGroupPolicyJoin})
+	require.NoError(t, err)
+	require.Equal(t, model.InstallationGroupPolicyJoin, ring.InstallationGroupPolicy)
+
+	ring.State = model.RingStateReleaseInProgress
+	require.NoError(t, sqlStore.UpdateRing(ring))
+
+	t.Run("registration is queued", func(t *testing.T) {
+		fetched, err := client.RegisterRingInstallationGroup(ring.ID, &model.RegisterInstallationGroupRequest{Name: "group1", ProvisionerGroupID: "group1"})
+		require.NoError(t, err)
+		require.Empty(t, fetched.InstallationGroups)
+
+		changes, err := sqlStore.GetRingInstallationGroupChanges(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, model.RingInstallationGroupRegister, changes[0].Action)
+	})
+
+	t.Run("removal is queued", func(t *testing.T) {
+		installationGroup, err := sqlStore.GetInstallationGroupByName("group1")
+		require.NoError(t, err)
+
+		require.NoError(t, client.DeleteRingInstallationGroup(ring.ID, installationGroup.ID))
+
+		changes, err := sqlStore.GetRingInstallationGroupChanges(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		require.Equal(t, model.RingInstallationGroupDeregister, changes[1].Action)
+	})
+
+	t.Run("registration is applied once the release ends", func(t *testing.T) {
+		ring.State = model.RingStateStable
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		fetched, err := client.RegisterRingInstallationGroup(ring.ID, &model.RegisterInstallationGroupRequest{Name: "group2", ProvisionerGroupID: "group2"})
+		require.NoError(t, err)
+		require.Len(t, fetched.InstallationGroups, 1)
+		require.Equal(t, "group2", fetched.InstallationGroups[0].Name)
+	})
+
+	t.Run("update policy", func(t *testing.T) {
+		_, err := client.UpdateRing(ring.ID, &model.UpdateRingRequest{InstallationGroupPolicy: "later"})
+		require.Error(t, err)
+
+		updated, err := client.UpdateRing(ring.ID, &model.UpdateRingRequest{InstallationGroupPolicy: model.InstallationGroupPolicyQueue})
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationGroupPolicyQueue, updated.InstallationGroupPolicy)
+	})
+}
+
+func TestRingFailurePolicy(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := client.CreateRing(&model.CreateRingRequest{Priority: 1, FailurePolicy: "retry"})
+		requireAPIError(t, err, 400)
+	})
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Priority: 1})
+	require.NoError(t, err)
+	require.Empty(t, ring.FailurePolicy)
+	require.Equal(t, model.FailurePolicyHalt, ring.CurrentFailurePolicy())
+
+	ring, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{FailurePolicy: model.FailurePolicyRollbackInstallationGroup})
+	require.NoError(t, err)
+	require.Equal(t, model.FailurePolicyRollbackInstallationGroup, ring.FailurePolicy)
+
+	t.Run("unchanged when not set", func(t *testing.T) {
+		ring, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{Priority: 2})
+		require.NoError(t, err)
+		require.Equal(t, model.FailurePolicyRollbackInstallationGroup, ring.FailurePolicy)
+	})
+
+	t.Run("invalid update", func(t *testing.T) {
+		_, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{FailurePolicy: "retry"})
+		require.Error(t, err)
+	})
+}
+
+func TestRingAutoRollback(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("conflicting failure policy", func(t *testing.T) {
+		_, err := client.CreateRing(&model.CreateRingRequest{Priority: 1, AutoRollback: true, FailurePolicy: model.FailurePolicyContinue})
+		requireAPIError(t, err, 400)
+	})
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Priority: 1, AutoRollback: true})
+	require.NoError(t, err)
+	require.True(t, ring.AutoRollback)
+	require.Equal(t, model.FailurePolicyRollbackRing, ring.CurrentFailurePolicy())
+
+	ring, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{Priority: 2})
+	require.NoError(t, err)
+	require.True(t, ring.AutoRollback)
+
+	autoRollback := false
+	ring, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{AutoRollback: &autoRollback})
+	require.NoError(t, err)
+	require.False(t, ring.AutoRollback)
+	require.Equal(t, model.FailurePolicyHalt, ring.CurrentFailurePolicy())
+}
+
+func TestRingDependencies(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	canary, err := client.CreateRing(&model.CreateRingRequest{Name: "canary", Priority: 1})
+	require.NoError(t, err)
+	production, err := client.CreateRing(&model.CreateRingRequest{
+		Name:           "production",
+		Priority:       2,
+		DependsOn:      model.RingDependencies{canary.ID},
+		MaxConcurrency: 3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, model.RingDependencies{canary.ID}, production.DependsOn)
+	require.Equal(t, 3, production.MaxConcurrency)
+
+	t.Run("unknown ring", func(t *testing.T) {
+		_, err := client.CreateRing(&model.CreateRingRequest{Name: "staging", Priority: 2, DependsOn: model.RingDependencies{model.NewID()}})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		_, err := client.UpdateRing(canary.ID, &model.UpdateRingRequest{DependsOn: &model.RingDependencies{production.ID}})
+		requireAPIError(t, err, 400)
+
+		_, err = client.UpdateRing(canary.ID, &model.UpdateRingRequest{DependsOn: &model.RingDependencies{canary.ID}})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("blockers", func(t *testing.T) {
+		canary.State = model.RingStateSoakingRequested
+		require.NoError(t, sqlStore.UpdateRing(canary))
+		production.State = model.RingStateReleasePending
+		require.NoError(t, sqlStore.UpdateRing(production))
+
+		blockers, err := client.GetRingBlockers(production.ID)
+		require.NoError(t, err)
+		require.Len(t, blockers.Blockers, 1)
+		require.Equal(t, model.ReleaseBlockerRingDependency, blockers.Blockers[0].Reason)
+		require.Equal(t, canary.ID, blockers.Blockers[0].RingID)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		ring, err := client.UpdateRing(production.ID, &model.UpdateRingRequest{DependsOn: &model.RingDependencies{}})
+		require.NoError(t, err)
+		require.Empty(t, ring.DependsOn)
+
+		ring, err = client.GetRing(production.ID)
+		require.NoError(t, err)
+		require.Empty(t, ring.DependsOn)
+		require.Equal(t, 3, ring.MaxConcurrency)
+	})
+}
+
+func TestRingMaxReleaseDuration(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	_, err := client.CreateRing(&model.CreateRingRequest{Name: "production", Priority: 1, MaxReleaseDuration: -1})
+	requireAPIError(t, err, 400)
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Name: "production", Priority: 1, MaxReleaseDuration: 7200})
+	require.NoError(t, err)
+	require.Equal(t, 7200, ring.MaxReleaseDuration)
+
+	maxReleaseDuration := 0
+	ring, err = client.UpdateRing(ring.ID, &model.UpdateRingRequest{MaxReleaseDuration: &maxReleaseDuration})
+	require.NoError(t, err)
+	require.Zero(t, ring.MaxReleaseDuration)
+}
+
+func TestRingRehearsal(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	_, err := client.CreateRing(&model.CreateRingRequest{Name: "game-day", Priority: 1, RehearsalSpeedup: 120})
+	requireAPIError(t, err, 400)
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{Name: "game-day", Priority: 1, Rehearsal: true, RehearsalSpeedup: 120})
+	require.NoError(t, err)
+	require.True(t, ring.Rehearsal)
+	require.Equal(t, 120, ring.RehearsalSpeedup)
+
+	ring, err = client.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.True(t, ring.Rehearsal)
+	require.Equal(t, 120, ring.CurrentRehearsalSpeedup())
+}
+
+func TestRingFailureDomains(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := client.CreateRing(&model.CreateRingRequest{Name: "production", Priority: 1, MaxPerFailureDomain: -1})
+		requireAPIError(t, err, 400)
+
+		_, err = client.CreateRing(&model.CreateRingRequest{
+			Name:              "production",
+			Priority:          1,
+			InstallationGroup: &model.InstallationGroup{Name: "ig-1", ProvisionerGroupID: "pg1", FailureDomain: "us east"},
+		})
+		requireAPIError(t, err, 400)
+	})
+
+	ring, err := client.CreateRing(&model.CreateRingRequest{
+		Name:              "production",
+		Priority:          1,
+		MaxConcurrency:    3,
+		InstallationGroup: &model.InstallationGroup{Name: "ig-1", ProvisionerGroupID: "pg1", FailureDomain: "us-east-1a"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, ring.CurrentMaxPerFailureDomain())
+	require.Len(t, ring.InstallationGroups, 1)
+	require.Equal(t, "us-east-1a", ring.InstallationGroups[0].FailureDomain)
+
+	_, err = client.RegisterRingInstallationGroup(ring.ID, &model.RegisterInstallationGroupRequest{Name: "ig-2", ProvisionerGroupID: "pg2", FailureDomain: "us-east-1a"})
+	require.NoError(t, err)
+	_, err = client.RegisterRingInstallationGroup(ring.ID, &model.RegisterInstallationGroupRequest{Name: "ig-3", ProvisionerGroupID: "pg3"})
+	require.NoError(t, err)
+	ring, err = client.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Len(t, ring.InstallationGroups, 3)
+
+	installationGroups := make(map[string]*model.InstallationGroup)
+	for _, installationGroup := range ring.InstallationGroups {
+		installationGroups[installationGroup.Name] = installationGroup
+	}
+
+	t.Run("update", func(t *testing.T) {
+		failureDomain := "us-east-1b"
+		installationGroup, err := client.UpdateInstallationGroup(installationGroups["ig-3"].ID, &model.UpdateInstallationGroupRequest{FailureDomain: &failureDomain})
+		require.NoError(t, err)
+		require.Equal(t, failureDomain, installationGroup.FailureDomain)
+	})
+
+	t.Run("blockers", func(t *testing.T) {
+		ring.State = model.RingStateReleaseInProgress
+		require.NoError(t, sqlStore.UpdateRing(ring))
+		for name, state := range map[string]string{
+			"ig-1": model.InstallationGroupReleasePending,
+			"ig-2": model.InstallationGroupReleaseRequested,
+			"ig-3": model.InstallationGroupReleaseRequested,
+		} {
+			installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroups[name].ID)
+			require.NoError(t, err)
+			installationGroup.State = state
+			require.NoError(t, sqlStore.UpdateInstallationGroup(installationGroup))
+		}
+
+		blockers, err := client.GetRingBlockers(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, blockers.Blockers, 1)
+		require.Equal(t, model.ReleaseBlockerFailureDomain, blockers.Blockers[0].Reason)
+		require.Equal(t, installationGroups["ig-2"].ID, blockers.Blockers[0].InstallationGroupID)
+
+		maxPerFailureDomain := 2
+		updated, err := client.UpdateRing(ring.ID, &model.UpdateRingRequest{MaxPerFailureDomain: &maxPerFailureDomain})
+		require.NoError(t, err)
+		require.Equal(t, 2, updated.MaxPerFailureDomain)
+
+		blockers, err = client.GetRingBlockers(ring.ID)
+		require.NoError(t, err)
+		require.Empty(t, blockers.Blockers)
+	})
 }
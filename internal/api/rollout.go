@@ -0,0 +1,476 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/elrond/internal/webhook"
+	"github.com/mattermost/elrond/model"
+)
+
+// initRollout registers rollout endpoints on the given router.
+func initRollout(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	rolloutsRouter := apiRouter.PathPrefix("/rollouts").Subrouter()
+	rolloutsRouter.Handle("", addContext(handleGetRollouts)).Methods("GET")
+	rolloutsRouter.Handle("", addContext(validateRequestBody(model.SchemaCreateRolloutRequest, handleCreateRollout))).Methods("POST")
+
+	rolloutRouter := apiRouter.PathPrefix("/rollout/{rollout:[A-Za-z0-9]{26}}").Subrouter()
+	rolloutRouter.Handle("", addContext(handleGetRollout)).Methods("GET")
+	rolloutRouter.Handle("/cancel", addContext(handleCancelRollout)).Methods("POST")
+	rolloutRouter.Handle("/rollback", addContext(handleRollbackRollout)).Methods("POST")
+	rolloutRouter.Handle("/resume", addContext(handleResumeRollout)).Methods("POST")
+}
+
+// handleGetRollouts responds to GET /api/rollouts, returning a page of rollouts, newest first.
+func handleGetRollouts(c *Context, w http.ResponseWriter, r *http.Request) {
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to parse paging parameters: %s", err))
+		return
+	}
+
+	rollouts, err := c.Store.GetRollouts(&model.RolloutFilter{
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query rollouts")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rollouts")
+		return
+	}
+	if rollouts == nil {
+		rollouts = []*model.Rollout{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, rollouts)
+}
+
+// handleCreateRollout responds to POST /api/rollouts, starting the release of
+// a release to the rings of each step of the rollout in turn.
+func handleCreateRollout(c *Context, w http.ResponseWriter, r *http.Request) {
+	createRolloutRequest, err := model.NewCreateRolloutRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode request: %s", err))
+		return
+	}
+
+	rollout := &model.Rollout{
+		Name:  createRolloutRequest.Name,
+		Steps: createRolloutRequest.Steps,
+		State: model.RolloutStateInProgress,
+	}
+
+	var installationGroups []*model.InstallationGroup
+	var rings []*model.Ring
+	for _, ringID := range rollout.RingIDs() {
+		ring, err := c.Store.GetRing(ringID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query ring")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+			return
+		}
+		if ring == nil || ring.DeleteAt != 0 {
+			outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("ring %s not found", ringID))
+			return
+		}
+		if ring.APISecurityLock {
+			logSecurityLockConflict("ring", c.Logger.WithField("ring", ring.ID))
+			outputError(c, w, http.StatusForbidden, model.ErrorCodeAPISecurityLock, fmt.Sprintf("API changes are locked for ring %s", ring.ID))
+			return
+		}
+		rings = append(rings, ring)
+
+		if len(createRolloutRequest.Parameters) > 0 {
+			ringInstallationGroups, err := c.Store.GetInstallationGroupsForRing(ring.ID)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to get ring installation groups")
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get ring installation groups")
+				return
+			}
+			installationGroups = append(installationGroups, ringInstallationGroups...)
+		}
+	}
+
+	if len(createRolloutRequest.Parameters) > 0 {
+		if err = createRolloutRequest.Parameters.ValidateInstallationGroups(installationGroups); err != nil {
+			outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+			return
+		}
+	}
+
+	rolloutsInProgress, err := c.Store.GetRollouts(&model.RolloutFilter{
+		States:  model.AllRolloutStatesHoldingRings,
+		PerPage: model.AllPerPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query rollouts in progress")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rollouts in progress")
+		return
+	}
+	ringIDs := make(map[string]bool)
+	for _, ringID := range rollout.RingIDs() {
+		ringIDs[ringID] = true
+	}
+	for _, rolloutInProgress := range rolloutsInProgress {
+		for _, ringID := range rolloutInProgress.RingIDs() {
+			if ringIDs[ringID] {
+				outputError(c, w, http.StatusConflict, model.ErrorCodeConflict, fmt.Sprintf("ring %s is part of rollout %s in progress", ringID, rolloutInProgress.ID))
+				return
+			}
+		}
+	}
+
+	if createRolloutRequest.Force && !checkRingsNotProtected(c, w, "force release", rings) {
+		return
+	}
+	if createRolloutRequest.Force && !requireForceApproval(c, w, model.ForceApprovalActionRelease, rings, createRolloutRequest) {
+		return
+	}
+
+	release, err := c.Store.GetOrCreateRingRelease(&model.RingRelease{
+		Version:    createRolloutRequest.Version,
+		Image:      createRolloutRequest.Image,
+		Force:      createRolloutRequest.Force,
+		SoakTime:   createRolloutRequest.SoakTime,
+		Type:       createRolloutRequest.Type,
+		Parameters: createRolloutRequest.Parameters,
+		CreateAt:   time.Now().UnixNano(),
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to get or create new ring release")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get or create new ring release")
+		return
+	}
+	rollout.ReleaseID = release.ID
+
+	if err = c.Store.CreateRollout(rollout); err != nil {
+		c.Logger.WithError(err).Error("failed to create rollout")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to create rollout")
+		return
+	}
+
+	c.Logger.WithField("rollout", rollout.ID).Infof("Created rollout of release %s", release.ID)
+
+	c.Supervisor.Do() //nolint
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, rollout)
+}
+
+// handleGetRollout responds to GET /api/rollout/{rollout}, returning the
+// rollout in question with the progress of its rings.
+func handleGetRollout(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	rolloutID := vars["rollout"]
+	c.Logger = c.Logger.WithField("rollout", rolloutID)
+
+	rollout, err := c.Store.GetRollout(rolloutID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query rollout")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query rollout")
+		return
+	}
+	if rollout == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "rollout not found")
+		return
+	}
+
+	rings := make(map[string]*model.Ring)
+	for _, ringID := range rollout.RingIDs() {
+		ring, err := c.Store.GetRing(ringID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query ring")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+			return
+		}
+		rings[ringID] = ring
+	}
+	rollout.Progress = model.NewRolloutProgress(rollout, rings)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, rollout)
+}
+
+// handleCancelRollout responds to POST /api/rollout/{rollout}/cancel,
+// cancelling the pending releases of the current step of the rollout and
+// every step after it. Rings already releasing finish their release.
+func handleCancelRollout(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	rolloutID := vars["rollout"]
+	c.Logger = c.Logger.WithField("rollout", rolloutID)
+
+	rollout, status, unlockOnce := lockRollout(c, rolloutID)
+	if status != 0 {
+		outputStatusError(c, w, status, "rollout")
+		return
+	}
+	defer unlockOnce()
+
+	if rollout.State != model.RolloutStateInProgress && rollout.State != model.RolloutStatePaused {
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to cancel a rollout while in state %s", rollout.State), map[string]string{"state": rollout.State})
+		return
+	}
+
+	if rollout.StepStartAt != 0 {
+		ringIDs := rollout.Steps[rollout.CurrentStep].RingIDs
+		status, unlockRings := lockRings(c, ringIDs)
+		if status != 0 {
+			outputStatusError(c, w, status, "rings")
+			return
+		}
+		defer unlockRings()
+
+		if !cancelRolloutReleases(c, w, rollout, ringIDs) {
+			return
+		}
+	}
+
+	rollout.State = model.RolloutStateCancelled
+	if err := c.Store.UpdateRollout(rollout); err != nil {
+		c.Logger.WithError(err).Error("failed to cancel rollout")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to cancel rollout")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, rollout)
+}
+
+// handleResumeRollout responds to POST /api/rollout/{rollout}/resume,
+// resuming a rollout paused by a breached promotion check. The promotion
+// window of its current step starts over.
+func handleResumeRollout(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	rolloutID := vars["rollout"]
+	c.Logger = c.Logger.WithField("rollout", rolloutID)
+
+	rollout, status, unlockOnce := lockRollout(c, rolloutID)
+	if status != 0 {
+		outputStatusError(c, w, status, "rollout")
+		return
+	}
+	defer unlockOnce()
+
+	if rollout.State != model.RolloutStatePaused {
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to resume a rollout while in state %s", rollout.State), map[string]string{"state": rollout.State})
+		return
+	}
+
+	oldState := rollout.State
+	rollout.State = model.RolloutStateInProgress
+	rollout.Message = ""
+	rollout.StepReleasedAt = 0
+	if err := c.Store.UpdateRollout(rollout); err != nil {
+		c.Logger.WithError(err).Error("failed to resume rollout")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to resume rollout")
+		return
+	}
+	unlockOnce()
+
+	c.Logger.Info("Resumed rollout")
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeRollout,
+		ID:        rollout.ID,
+		NewState:  rollout.State,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"Environment": c.Environment},
+	}
+	if err := webhook.SendToAllWebhooks(c.Store, webhookPayload, c.Logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+		c.Logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+
+	c.Supervisor.Do() //nolint
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, rollout)
+}
+
+// cancelRolloutReleases cancels the pending releases of the rollout on the
+// given rings, which the caller must have locked. It returns whether it
+// succeeded, having output an error otherwise.
+func cancelRolloutReleases(c *Context, w http.ResponseWriter, rollout *model.Rollout, ringIDs []string) bool {
+	var rings []*model.Ring
+	for _, ringID := range ringIDs {
+		ring, err := c.Store.GetRing(ringID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query ring")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+			return false
+		}
+		if ring == nil || ring.DesiredReleaseID != rollout.ReleaseID {
+			continue
+		}
+		if ring.State == model.RingStateReleasePending || ring.State == model.RingStateReleaseBlocked || ring.State == model.RingStateReleasePaused {
+			rings = append(rings, ring)
+		}
+	}
+
+	oldStates := make(map[string]string, len(rings))
+	for _, ring := range rings {
+		oldStates[ring.ID] = ring.State
+		ring.State = model.RingStateStable
+		ring.DesiredReleaseID = ring.ActiveReleaseID
+	}
+
+	if err := c.Store.UpdateRings(rings); err != nil {
+		c.Logger.WithError(err).Error("failed to cancel the ring releases of the rollout")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to cancel the ring releases of the rollout")
+		return false
+	}
+
+	for _, ring := range rings {
+		recordRingStateChange(c, ring, oldStates[ring.ID], ring.State)
+	}
+
+	return true
+}
+
+// handleRollbackRollout responds to POST /api/rollout/{rollout}/rollback,
+// cancelling the rollout and releasing every ring that released it back to
+// the release recorded in its rollback snapshot.
+func handleRollbackRollout(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	rolloutID := vars["rollout"]
+	c.Logger = c.Logger.WithField("rollout", rolloutID)
+
+	rollout, status, unlockOnce := lockRollout(c, rolloutID)
+	if status != 0 {
+		outputStatusError(c, w, status, "rollout")
+		return
+	}
+	defer unlockOnce()
+
+	if rollout.State == model.RolloutStateRolledBack {
+		outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to roll back a rollout while in state %s", rollout.State), map[string]string{"state": rollout.State})
+		return
+	}
+
+	ringIDs := rollout.StartedRingIDs()
+	if len(ringIDs) > 0 {
+		status, unlockRings := lockRings(c, ringIDs)
+		if status != 0 {
+			outputStatusError(c, w, status, "rings")
+			return
+		}
+		defer unlockRings()
+	}
+
+	var rings []*model.Ring
+	for _, ringID := range ringIDs {
+		ring, err := c.Store.GetRing(ringID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query ring")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+			return
+		}
+		if ring == nil || ring.DeleteAt != 0 {
+			continue
+		}
+		if ring.HasReleaseInProgress() {
+			outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to roll back ring %s while in state %s", ring.ID, ring.State), map[string]string{"state": ring.State})
+			return
+		}
+		if ring.ActiveReleaseID != rollout.ReleaseID || ring.RollbackSnapshotID == "" {
+			continue
+		}
+		if !ring.ValidTransitionState(model.RingStateReleasePending) {
+			outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeInvalidStateTransition, fmt.Sprintf("unable to roll back ring %s while in state %s", ring.ID, ring.State), map[string]string{"state": ring.State})
+			return
+		}
+		rings = append(rings, ring)
+	}
+
+	if !cancelRolloutReleases(c, w, rollout, ringIDs) {
+		return
+	}
+
+	var webhookPayloads []*model.WebhookPayload
+	var rolledBackRings []*model.Ring
+	for _, ring := range rings {
+		snapshot, err := c.Store.GetRingReleaseSnapshot(ring.RollbackSnapshotID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query ring release snapshot")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring release snapshot")
+			return
+		}
+		if snapshot == nil {
+			c.Logger.Warnf("Rollback snapshot %s of ring %s no longer exists; skipping its rollback", ring.RollbackSnapshotID, ring.ID)
+			continue
+		}
+
+		release, err := c.Store.GetOrCreateRingRelease(&model.RingRelease{
+			Image:    snapshot.Image,
+			Version:  snapshot.Version,
+			Force:    snapshot.Force,
+			Type:     model.ReleaseTypeRollback,
+			CreateAt: time.Now().UnixNano(),
+		})
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to get or create rollback release")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to get or create rollback release")
+			return
+		}
+
+		webhookPayloads = append(webhookPayloads, &model.WebhookPayload{
+			Type:      model.TypeRing,
+			ID:        ring.ID,
+			NewState:  model.RingStateReleasePending,
+			OldState:  ring.State,
+			Timestamp: time.Now().UnixNano(),
+			ExtraData: map[string]string{"Environment": c.Environment, "ReleaseType": model.ReleaseTypeRollback, "Rollout": rollout.ID},
+			Labels:    ring.Annotations,
+			Metadata:  ring.Metadata,
+		})
+		ring.State = model.RingStateReleasePending
+		ring.DesiredReleaseID = release.ID
+		rolledBackRings = append(rolledBackRings, ring)
+	}
+
+	if err := c.Store.UpdateRings(rolledBackRings); err != nil {
+		c.Logger.WithError(err).Error("failed to request the rollback of the rings of the rollout")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to request the rollback of the rings of the rollout")
+		return
+	}
+
+	rollout.State = model.RolloutStateRolledBack
+	if err := c.Store.UpdateRollout(rollout); err != nil {
+		c.Logger.WithError(err).Error("failed to roll back rollout")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to roll back rollout")
+		return
+	}
+
+	for i, payload := range webhookPayloads {
+		recordRingStateChange(c, rolledBackRings[i], payload.OldState, payload.NewState)
+
+		if err := webhook.SendToAllWebhooks(c.Store, payload, c.Logger.WithField("webhookEvent", payload.NewState)); err != nil {
+			c.Logger.WithError(err).Error("unable to process and send webhooks")
+		}
+	}
+
+	c.Supervisor.Do() //nolint
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, rollout)
+}
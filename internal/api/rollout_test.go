@@ -0,0 +1,206 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollouts(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	previous, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "9.5.0"})
+	require.NoError(t, err)
+
+	staging := &model.Ring{Name: "staging", State: model.RingStateStable, ActiveReleaseID: previous.ID, DesiredReleaseID: previous.ID}
+	require.NoError(t, sqlStore.CreateRing(staging, nil))
+	production := &model.Ring{Name: "production", State: model.RingStateStable, ActiveReleaseID: previous.ID, DesiredReleaseID: previous.ID}
+	require.NoError(t, sqlStore.CreateRing(production, nil))
+
+	request := &model.CreateRolloutRequest{
+		Name: "9.5.1 everywhere",
+		RingReleaseRequest: model.RingReleaseRequest{
+			Image:   "mattermost/mattermost-enterprise-edition",
+			Version: "9.5.1",
+		},
+		Steps: []*model.RolloutStep{
+			{Name: "staging", RingIDs: []string{staging.ID}},
+			{Name: "production", RingIDs: []string{production.ID}},
+		},
+	}
+
+	getRing := func(ringID string) *model.Ring {
+		ring, err := sqlStore.GetRing(ringID)
+		require.NoError(t, err)
+		return ring
+	}
+	setRing := func(ringID, state, activeReleaseID, desiredReleaseID string) {
+		ring := getRing(ringID)
+		ring.State = state
+		ring.ActiveReleaseID = activeReleaseID
+		ring.DesiredReleaseID = desiredReleaseID
+		require.NoError(t, sqlStore.UpdateRing(ring))
+	}
+	setStep := func(rolloutID string, step int) {
+		rollout, err := sqlStore.GetRollout(rolloutID)
+		require.NoError(t, err)
+		rollout.CurrentStep = step
+		rollout.StepStartAt = model.GetMillis()
+		require.NoError(t, sqlStore.UpdateRollout(rollout))
+	}
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err := client.CreateRollout(&model.CreateRolloutRequest{})
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		_, err = client.CreateRollout(&model.CreateRolloutRequest{
+			Steps: []*model.RolloutStep{{RingIDs: []string{staging.ID}}, {RingIDs: []string{staging.ID}}},
+		})
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		_, err = client.CreateRollout(&model.CreateRolloutRequest{
+			Steps: []*model.RolloutStep{{RingIDs: []string{model.NewID()}}},
+		})
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		// The last step has no step to promote to.
+		_, err = client.CreateRollout(&model.CreateRolloutRequest{
+			RingReleaseRequest: request.RingReleaseRequest,
+			Steps:              []*model.RolloutStep{{RingIDs: []string{staging.ID}, PromotionWindow: 60}},
+		})
+		requireAPIError(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("unknown rollout", func(t *testing.T) {
+		rollout, err := client.GetRollout(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, rollout)
+
+		_, err = client.CancelRollout(model.NewID())
+		requireAPIError(t, err, http.StatusNotFound)
+	})
+
+	var rollout *model.Rollout
+	t.Run("create", func(t *testing.T) {
+		rollout, err = client.CreateRollout(request)
+		require.NoError(t, err)
+		require.NotEmpty(t, rollout.ID)
+		require.NotEmpty(t, rollout.ReleaseID)
+		require.Equal(t, model.RolloutStateInProgress, rollout.State)
+
+		_, err = client.CreateRollout(request)
+		apiErr := requireAPIError(t, err, http.StatusConflict)
+		require.Equal(t, model.ErrorCodeConflict, apiErr.Code)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		setRing(staging.ID, model.RingStateStable, rollout.ReleaseID, rollout.ReleaseID)
+		setStep(rollout.ID, 1)
+
+		fetched, err := client.GetRollout(rollout.ID)
+		require.NoError(t, err)
+		require.Equal(t, 1, fetched.CurrentStep)
+		require.Equal(t, 1, fetched.Progress.ReleasedRings)
+		require.Equal(t, 2, fetched.Progress.TotalRings)
+		require.Equal(t, &model.RolloutRing{Step: 0, RingID: staging.ID, RingName: "staging", State: model.RingStateStable, Released: true}, fetched.Progress.Rings[0])
+
+		rollouts, err := client.GetRollouts(&model.GetRolloutsRequest{PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, rollouts, 1)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		setRing(production.ID, model.RingStateReleasePending, previous.ID, rollout.ReleaseID)
+
+		cancelled, err := client.CancelRollout(rollout.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RolloutStateCancelled, cancelled.State)
+
+		ring := getRing(production.ID)
+		require.Equal(t, model.RingStateStable, ring.State)
+		require.Equal(t, previous.ID, ring.DesiredReleaseID)
+		// Rings of the previous steps keep the release.
+		require.Equal(t, rollout.ReleaseID, getRing(staging.ID).ActiveReleaseID)
+
+		_, err = client.CancelRollout(rollout.ID)
+		apiErr := requireAPIError(t, err, http.StatusBadRequest)
+		require.Equal(t, model.ErrorCodeInvalidStateTransition, apiErr.Code)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		snapshot, err := sqlStore.CreateRingReleaseSnapshot(staging.ID, previous)
+		require.NoError(t, err)
+		ring := getRing(staging.ID)
+		ring.RollbackSnapshotID = snapshot.ID
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		setRing(production.ID, model.RingStateReleaseInProgress, previous.ID, rollout.ReleaseID)
+		_, err = client.RollbackRollout(rollout.ID)
+		requireAPIError(t, err, http.StatusBadRequest)
+		// Rings not running the release of the rollout are left alone.
+		setRing(production.ID, model.RingStateStable, previous.ID, previous.ID)
+
+		rolledBack, err := client.RollbackRollout(rollout.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RolloutStateRolledBack, rolledBack.State)
+
+		ring = getRing(staging.ID)
+		require.Equal(t, model.RingStateReleasePending, ring.State)
+		release, err := sqlStore.GetRingRelease(ring.DesiredReleaseID)
+		require.NoError(t, err)
+		require.Equal(t, model.ReleaseTypeRollback, release.Type)
+		require.Equal(t, "9.5.0", release.Version)
+
+		_, err = client.RollbackRollout(rollout.ID)
+		requireAPIError(t, err, http.StatusBadRequest)
+	})
+	t.Run("resume", func(t *testing.T) {
+		paused, err := client.CreateRollout(request)
+		require.NoError(t, err)
+
+		_, err = client.ResumeRollout(paused.ID)
+		apiErr := requireAPIError(t, err, http.StatusBadRequest)
+		require.Equal(t, model.ErrorCodeInvalidStateTransition, apiErr.Code)
+
+		paused.State = model.RolloutStatePaused
+		paused.Message = "promotion check error-rate breached"
+		paused.StepReleasedAt = model.GetMillis()
+		require.NoError(t, sqlStore.UpdateRollout(paused))
+
+		// A paused rollout still holds its rings.
+		_, err = client.CreateRollout(request)
+		requireAPIError(t, err, http.StatusConflict)
+
+		resumed, err := client.ResumeRollout(paused.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RolloutStateInProgress, resumed.State)
+		require.Empty(t, resumed.Message)
+		require.Zero(t, resumed.StepReleasedAt)
+
+		_, err = client.CancelRollout(paused.ID)
+		require.NoError(t, err)
+	})
+}
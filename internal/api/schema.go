@@ -0,0 +1,74 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// initSchema registers request schema endpoints on the given router.
+func initSchema(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	apiRouter.Handle("/schemas", addContext(handleGetRequestSchemas)).Methods("GET")
+	apiRouter.Handle("/schema/{schema}", addContext(handleGetRequestSchema)).Methods("GET")
+}
+
+// handleGetRequestSchemas responds to GET /api/schemas, returning the JSON
+// Schema of every request payload by name.
+func handleGetRequestSchemas(c *Context, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, model.RequestSchemas())
+}
+
+// handleGetRequestSchema responds to GET /api/schema/{schema}, returning the
+// JSON Schema of the request payload in question.
+func handleGetRequestSchema(c *Context, w http.ResponseWriter, r *http.Request) {
+	schema := model.RequestSchema(mux.Vars(r)["schema"])
+	if schema == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "request schema not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, schema)
+}
+
+// validateRequestBody returns a handler validating the body of the request
+// against the request schema with the given name before calling the given
+// handler. Requests not matching the schema are rejected with the path and
+// error of every mismatching value in the details of the error.
+func validateRequestBody(schemaName string, handler contextHandlerFunc) contextHandlerFunc {
+	return func(c *Context, w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if err = model.ValidateRequestJSON(schemaName, body); err != nil {
+			schemaErrors, ok := err.(model.SchemaErrors)
+			if !ok {
+				c.Logger.WithError(err).Error("failed to validate request body")
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to validate request body")
+				return
+			}
+			outputErrorWithDetails(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "request body does not match the "+schemaName+" schema: "+schemaErrors.Error(), schemaErrors.Details())
+			return
+		}
+
+		handler(c, w, r)
+	}
+}
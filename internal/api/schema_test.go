@@ -0,0 +1,94 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestSchemas(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	t.Run("get schemas", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/v1/schemas")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		schemas := make(map[string]json.RawMessage)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&schemas))
+		require.Len(t, schemas, len(model.RequestSchemaNames()))
+		require.Contains(t, schemas, model.SchemaCreateRingRequest)
+	})
+
+	t.Run("get schema", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/v1/schema/" + model.SchemaFleetSpec)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/schema+json", resp.Header.Get("Content-Type"))
+
+		var schema model.Schema
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&schema))
+		require.Equal(t, model.SchemaFleetSpec, schema.Title)
+		require.Contains(t, schema.Properties, "rings")
+	})
+
+	t.Run("unknown schema", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/v1/schema/Unknown")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("request not matching its schema", func(t *testing.T) {
+		body := []byte(`{"name":"ring1","priority":"1","soakTme":60}`)
+		resp, err := http.Post(ts.URL+"/api/v1/rings", "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		errorResponse, err := model.ErrorResponseFromReader(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, model.ErrorCodeBadRequest, errorResponse.Code)
+		require.Equal(t, "request body does not match the CreateRingRequest schema: $.priority: expected integer, got string; $.soakTme: unknown property", errorResponse.Message)
+		require.Equal(t, map[string]string{
+			"$.priority": "expected integer, got string",
+			"$.soakTme":  "unknown property",
+		}, errorResponse.Details)
+
+		rings, err := sqlStore.GetRings(&model.RingFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Empty(t, rings)
+	})
+
+	t.Run("request matching its schema", func(t *testing.T) {
+		client := model.NewClient(ts.URL)
+		ring, err := client.CreateRing(&model.CreateRingRequest{Name: "ring1", Priority: 1})
+		require.NoError(t, err)
+		require.Equal(t, "ring1", ring.Name)
+	})
+}
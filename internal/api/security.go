@@ -5,9 +5,12 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
 )
 
 // initSecurity registers security endpoints on the given router.
@@ -21,6 +24,9 @@ func initSecurity(apiRouter *mux.Router, context *Context) {
 	securityClusterRouter := securityRouter.PathPrefix("/ring/{ring:[A-Za-z0-9]{26}}").Subrouter()
 	securityClusterRouter.Handle("/api/lock", addContext(handleRingLockAPI)).Methods("POST")
 	securityClusterRouter.Handle("/api/unlock", addContext(handleRingUnlockAPI)).Methods("POST")
+	securityClusterRouter.Handle("/protection", addContext(handleGetRingProtectionChanges)).Methods("GET")
+	securityClusterRouter.Handle("/protect", addContext(validateRequestBody(model.SchemaRingProtectionRequest, handleProtectRing))).Methods("POST")
+	securityClusterRouter.Handle("/unprotect", addContext(validateRequestBody(model.SchemaRingProtectionRequest, handleUnprotectRing))).Methods("POST")
 }
 
 // handleRingLockAPI responds to POST /api/security/ring/{ring}/api/lock,
@@ -33,18 +39,18 @@ func handleRingLockAPI(c *Context, w http.ResponseWriter, r *http.Request) {
 	ring, err := c.Store.GetRing(ringID)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to query ring")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
 		return
 	}
 	if ring == nil {
-		w.WriteHeader(http.StatusNotFound)
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring not found")
 		return
 	}
 
 	if !ring.APISecurityLock {
 		if err := c.Store.LockRingAPI(ring.ID); err != nil {
 			c.Logger.WithError(err).Error("failed to lock ring API")
-			w.WriteHeader(http.StatusInternalServerError)
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to lock ring API")
 			return
 		}
 	}
@@ -62,21 +68,143 @@ func handleRingUnlockAPI(c *Context, w http.ResponseWriter, r *http.Request) {
 	ring, err := c.Store.GetRing(ringID)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to query ring")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
 		return
 	}
 	if ring == nil {
-		w.WriteHeader(http.StatusNotFound)
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring not found")
 		return
 	}
 
 	if ring.APISecurityLock {
+		if !requireForceApproval(c, w, model.ForceApprovalActionUnlock, []*model.Ring{ring}, nil) {
+			return
+		}
 		if err = c.Store.UnlockRingAPI(ring.ID); err != nil {
 			c.Logger.WithError(err).Error("failed to unlock ring API")
-			w.WriteHeader(http.StatusInternalServerError)
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to unlock ring API")
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleGetRingProtectionChanges responds to GET
+// /api/security/ring/{ring}/protection, returning the protection changes of
+// the ring, newest first.
+func handleGetRingProtectionChanges(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ringID := vars["ring"]
+	c.Logger = c.Logger.WithField("ring", ringID)
+
+	ring, err := c.Store.GetRing(ringID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+		return
+	}
+	if ring == nil {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "ring not found")
+		return
+	}
+
+	changes, err := c.Store.GetRingProtectionChanges(ring.ID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query ring protection changes")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring protection changes")
+		return
+	}
+	if changes == nil {
+		changes = []*model.RingProtectionChange{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, changes)
+}
+
+// handleProtectRing responds to POST /api/security/ring/{ring}/protect,
+// protecting the ring from deletion and forced releases.
+func handleProtectRing(c *Context, w http.ResponseWriter, r *http.Request) {
+	setRingProtected(c, w, r, true)
+}
+
+// handleUnprotectRing responds to POST /api/security/ring/{ring}/unprotect,
+// removing the protection of the ring. It requires the admin role, a reason
+// and, for rings with a force approval window, a second token.
+func handleUnprotectRing(c *Context, w http.ResponseWriter, r *http.Request) {
+	setRingProtected(c, w, r, false)
+}
+
+// setRingProtected sets the protection of the requested ring, recording the
+// change for auditing.
+func setRingProtected(c *Context, w http.ResponseWriter, r *http.Request, protected bool) {
+	vars := mux.Vars(r)
+	ringID := vars["ring"]
+	c.Logger = c.Logger.WithField("ring", ringID)
+
+	request, err := model.NewRingProtectionRequestFromReader(r.Body)
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return
+	}
+	if err = request.Validate(protected); err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return
+	}
+	if !protected && !requireAdminToken(c, w) {
+		return
+	}
+
+	ring, status, unlockOnce := lockRing(c, ringID)
+	if status != 0 {
+		outputStatusError(c, w, status, "ring")
+		return
+	}
+	defer unlockOnce()
+
+	if ring.Protected != protected {
+		if !protected && !requireForceApproval(c, w, model.ForceApprovalActionUnprotect, []*model.Ring{ring}, request) {
+			return
+		}
+		change := &model.RingProtectionChange{
+			RingID:    ring.ID,
+			Protected: protected,
+			Reason:    request.Reason,
+			ChangedBy: c.TokenID,
+		}
+		if err = c.Store.SetRingProtected(change); err != nil {
+			c.Logger.WithError(err).Error("failed to store ring protection")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to store ring protection")
 			return
 		}
+		c.Logger.WithField("changed-by", c.TokenID).Warnf("Ring protection set to %t: %s", protected, request.Reason)
+		ring.Protected = protected
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, ring)
+}
+
+// checkRingsNotProtected returns whether none of the given rings is
+// protected, writing the error for the given action otherwise. Protected
+// rings reject the action whatever the role of the token.
+func checkRingsNotProtected(c *Context, w http.ResponseWriter, action string, rings []*model.Ring) bool {
+	var protected []string
+	for _, ring := range rings {
+		if ring.Protected {
+			protected = append(protected, ring.ID)
+		}
+	}
+	if len(protected) == 0 {
+		return true
+	}
+
+	c.Logger.Warnf("Rejected %s of protected rings %s", action, strings.Join(protected, ","))
+	outputErrorWithDetails(c, w, http.StatusForbidden, model.ErrorCodeRingProtected,
+		fmt.Sprintf("unable to %s protected rings %s; remove their protection first", action, strings.Join(protected, ",")),
+		map[string]string{"rings": strings.Join(protected, ",")})
+	return false
 }
@@ -0,0 +1,64 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// initStateMachine registers state machine endpoints on the given router.
+func initStateMachine(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	apiRouter.Handle("/state-machine", addContext(handleGetStateMachine)).Methods("GET")
+}
+
+// handleGetStateMachine responds to GET /api/state-machine, returning the
+// transition graph of the rings, or of the installation groups with
+// resource_type=installationgroup, for the current state machine version or
+// the given version. With format=dot or format=mermaid, the graph is rendered
+// in that format.
+func handleGetStateMachine(c *Context, w http.ResponseWriter, r *http.Request) {
+	resourceType := r.URL.Query().Get("resource_type")
+	if resourceType == "" {
+		resourceType = model.TypeRing
+	}
+
+	version, err := parseInt(r.URL, "version", 0)
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return
+	}
+
+	graph, err := model.NewStateMachineGraph(resourceType, version)
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" || format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		outputJSON(c, w, graph)
+		return
+	}
+
+	rendered, err := graph.Render(format)
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write([]byte(rendered)); err != nil {
+		c.Logger.WithError(err).Warn("failed to write state machine graph")
+	}
+}
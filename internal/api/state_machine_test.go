@@ -0,0 +1,71 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStateMachine(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	t.Run("json", func(t *testing.T) {
+		graph, err := client.GetStateMachineGraph(model.TypeInstallationGroup, 0)
+		require.NoError(t, err)
+		require.Equal(t, model.TypeInstallationGroup, graph.ResourceType)
+		require.Equal(t, model.CurrentStateMachineVersion, graph.Version)
+		require.Equal(t, model.AllInstallationGroupStates, graph.States)
+		require.NotEmpty(t, graph.Transitions)
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/v1/state-machine?format=mermaid")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(body), "stateDiagram-v2\n"))
+		require.Contains(t, string(body), "stable --> release_pending: api")
+
+		resp, err = http.Get(ts.URL + "/api/v1/state-machine?format=svg")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := client.GetStateMachineGraph(model.TypeRing, model.CurrentStateMachineVersion+1)
+		requireAPIError(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("unsupported resource type", func(t *testing.T) {
+		_, err := client.GetStateMachineGraph("installation", 0)
+		requireAPIError(t, err, http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/model"
+)
+
+// initToken registers token endpoints on the given router.
+func initToken(apiRouter *mux.Router, context *Context) {
+	addContext := func(handler contextHandlerFunc) *contextHandler {
+		return newContextHandler(context, handler)
+	}
+
+	tokensRouter := apiRouter.PathPrefix("/tokens").Subrouter()
+	tokensRouter.Handle("", addContext(handleGetTokens)).Methods("GET")
+	tokensRouter.Handle("", addContext(validateRequestBody(model.SchemaCreateTokenRequest, handleCreateToken))).Methods("POST")
+
+	tokenRouter := apiRouter.PathPrefix("/token/{token:[A-Za-z0-9]{26}}").Subrouter()
+	tokenRouter.Handle("", addContext(handleGetToken)).Methods("GET")
+	tokenRouter.Handle("", addContext(handleDeleteToken)).Methods("DELETE")
+}
+
+// requireAdminToken writes an error response and returns false unless the
+// request was authenticated with an admin token of no tenant. The admin
+// endpoints manage the whole server, so neither requests without a token nor
+// admin tokens of a tenant can use them.
+func requireAdminToken(c *Context, w http.ResponseWriter) bool {
+	if c.TokenID == "" {
+		c.Logger.Warn("request requires an admin token")
+		outputError(c, w, http.StatusUnauthorized, model.ErrorCodeUnauthorized, "this request requires an admin token")
+		return false
+	}
+	if c.TokenRole != model.TokenRoleAdmin || c.TenantID != "" {
+		c.Logger.Warn("request requires the admin role")
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeForbidden, "this request requires the admin role")
+		return false
+	}
+
+	return true
+}
+
+// handleCreateToken responds to POST /api/tokens, minting a new token.
+func handleCreateToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	createTokenRequest, err := model.NewCreateTokenRequestFromReader(r.Body)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to decode request")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode request: %s", err))
+		return
+	}
+
+	for _, ringID := range createTokenRequest.RingIDs {
+		ring, err := c.Store.GetRing(ringID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query ring")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+			return
+		}
+		if ring == nil {
+			outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("ring %s not found", ringID))
+			return
+		}
+	}
+
+	secret, err := model.NewTokenSecret()
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to generate token secret")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to generate token secret")
+		return
+	}
+
+	token := model.Token{
+		Name:      createTokenRequest.Name,
+		TenantID:  createTokenRequest.TenantID,
+		Role:      createTokenRequest.Role,
+		TokenHash: model.HashTokenSecret(secret),
+
+		RingIDs:      strings.Join(createTokenRequest.RingIDs, ","),
+		RingSelector: createTokenRequest.RingSelector,
+	}
+	if createTokenRequest.ExpiresIn > 0 {
+		token.ExpireAt = model.GetMillis() + createTokenRequest.ExpiresIn*1000
+	}
+
+	if err = c.Store.CreateToken(&token); err != nil {
+		c.Logger.WithError(err).Error("failed to create token")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to create token")
+		return
+	}
+
+	c.Logger.WithField("created-token", token.ID).Infof("Created %s token %s", token.Role, token.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, model.CreateTokenResponse{Token: &token, Secret: secret})
+}
+
+// handleGetToken responds to GET /api/token/{token}, returning the token in question.
+func handleGetToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tokenID := vars["token"]
+	c.Logger = c.Logger.WithField("requested-token", tokenID)
+
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	token, err := c.Store.GetToken(tokenID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query token")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query token")
+		return
+	}
+	if token == nil || !tokenVisibleToTenant(c, token) {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "token not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, token)
+}
+
+// handleGetTokens responds to GET /api/tokens, returning the specified page of tokens.
+func handleGetTokens(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	tenant := r.URL.Query().Get("tenant")
+	page, perPage, includeDeleted, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to parse paging parameters: %s", err))
+		return
+	}
+
+	if c.TenantID != "" {
+		if tenant != "" && tenant != c.TenantID {
+			c.Logger.Warnf("unable to list tokens of tenant %s on behalf of another tenant", tenant)
+			outputError(c, w, http.StatusForbidden, model.ErrorCodeForbidden, fmt.Sprintf("unable to list tokens of tenant %s on behalf of another tenant", tenant))
+			return
+		}
+		tenant = c.TenantID
+	}
+
+	tokens, err := c.Store.GetTokens(&model.TokenFilter{
+		TenantID:       tenant,
+		Page:           page,
+		PerPage:        perPage,
+		IncludeDeleted: includeDeleted,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query tokens")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query tokens")
+		return
+	}
+	if tokens == nil {
+		tokens = []*model.Token{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, tokens)
+}
+
+// handleDeleteToken responds to DELETE /api/token/{token}, revoking the token.
+func handleDeleteToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tokenID := vars["token"]
+	c.Logger = c.Logger.WithField("requested-token", tokenID)
+
+	if !requireAdminToken(c, w) {
+		return
+	}
+
+	token, err := c.Store.GetToken(tokenID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query token")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query token")
+		return
+	}
+	if token == nil || !tokenVisibleToTenant(c, token) {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "token not found")
+		return
+	}
+	if token.IsDeleted() {
+		c.Logger.Warn("unable to revoke token that is already revoked")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "unable to revoke token that is already revoked")
+		return
+	}
+
+	if err = c.Store.DeleteToken(tokenID); err != nil {
+		c.Logger.WithError(err).Error("failed to revoke token")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to revoke token")
+		return
+	}
+
+	c.Logger.Infof("Revoked token %s", token.Name)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// tokenVisibleToTenant returns whether the tenant of the request, if any,
+// owns the given token. Tokens of other tenants are reported as not found.
+func tokenVisibleToTenant(c *Context, token *model.Token) bool {
+	return c.TenantID == "" || token.TenantID == c.TenantID
+}
@@ -0,0 +1,283 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokens(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:        sqlStore,
+		Supervisor:   &mockSupervisor{},
+		Logger:       logger,
+		RequireToken: true,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	adminSecret, err := model.NewTokenSecret()
+	require.NoError(t, err)
+	err = sqlStore.CreateToken(&model.Token{
+		Name:      "bootstrap",
+		Role:      model.TokenRoleAdmin,
+		TokenHash: model.HashTokenSecret(adminSecret),
+	})
+	require.NoError(t, err)
+	adminClient := model.NewClientWithToken(ts.URL, adminSecret)
+
+	t.Run("missing token", func(t *testing.T) {
+		_, err := model.NewClient(ts.URL).GetTokens(&model.GetTokensRequest{PerPage: 10})
+		apiErr := requireAPIError(t, err, 401)
+		require.Equal(t, model.ErrorCodeUnauthorized, apiErr.Code)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, err := model.NewClientWithToken(ts.URL, "unknown").GetTokens(&model.GetTokensRequest{PerPage: 10})
+		requireAPIError(t, err, 401)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := adminClient.CreateToken(&model.CreateTokenRequest{Name: "ci", Role: model.TokenRoleWrite})
+		requireAPIError(t, err, 400)
+
+		_, err = adminClient.CreateToken(&model.CreateTokenRequest{Name: "ci", TenantID: "tenant1", Role: "superuser"})
+		requireAPIError(t, err, 400)
+	})
+
+	var writeToken *model.CreateTokenResponse
+	t.Run("create write token", func(t *testing.T) {
+		writeToken, err = adminClient.CreateToken(&model.CreateTokenRequest{
+			Name:     "ci",
+			TenantID: "tenant1",
+			Role:     model.TokenRoleWrite,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, writeToken.Secret)
+		require.Equal(t, "tenant1", writeToken.Token.TenantID)
+		require.NotZero(t, writeToken.Token.ExpireAt)
+		require.Empty(t, writeToken.Token.TokenHash)
+	})
+
+	t.Run("token is scoped to its tenant", func(t *testing.T) {
+		client := model.NewClientWithToken(ts.URL, writeToken.Secret)
+
+		webhook, err := client.CreateWebhook(&model.CreateWebhookRequest{URL: "https://tenant1.com"})
+		require.NoError(t, err)
+		require.Equal(t, "tenant1", webhook.OwnerID)
+
+		_, err = client.CreateWebhook(&model.CreateWebhookRequest{OwnerID: "tenant2", URL: "https://tenant2.com"})
+		requireAPIError(t, err, 403)
+	})
+
+	t.Run("non-admin token cannot manage tokens", func(t *testing.T) {
+		client := model.NewClientWithToken(ts.URL, writeToken.Secret)
+
+		_, err := client.CreateToken(&model.CreateTokenRequest{Name: "escalate", Role: model.TokenRoleAdmin})
+		requireAPIError(t, err, 403)
+	})
+
+	t.Run("read token cannot make changes", func(t *testing.T) {
+		readToken, err := adminClient.CreateToken(&model.CreateTokenRequest{
+			Name:     "dashboard",
+			TenantID: "tenant1",
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.TokenRoleRead, readToken.Token.Role)
+
+		client := model.NewClientWithToken(ts.URL, readToken.Secret)
+
+		webhooks, err := client.GetWebhooks(&model.GetWebhooksRequest{PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+
+		_, err = client.CreateWebhook(&model.CreateWebhookRequest{URL: "https://tenant1.com/2"})
+		requireAPIError(t, err, 403)
+
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/v1/graphql", strings.NewReader(`{"query": "{ rings { name } }"}`))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+readToken.Secret)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("list and get tokens", func(t *testing.T) {
+		tokens, err := adminClient.GetTokens(&model.GetTokensRequest{PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, tokens, 3)
+
+		tokens, err = adminClient.GetTokens(&model.GetTokensRequest{TenantID: "tenant1", PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+
+		token, err := adminClient.GetToken(writeToken.Token.ID)
+		require.NoError(t, err)
+		require.Equal(t, writeToken.Token.Name, token.Name)
+
+		token, err = adminClient.GetToken(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, token)
+	})
+
+	t.Run("revoke token", func(t *testing.T) {
+		err := adminClient.DeleteToken(writeToken.Token.ID)
+		require.NoError(t, err)
+
+		err = adminClient.DeleteToken(writeToken.Token.ID)
+		requireAPIError(t, err, 400)
+
+		_, err = model.NewClientWithToken(ts.URL, writeToken.Secret).GetWebhooks(&model.GetWebhooksRequest{PerPage: 10})
+		requireAPIError(t, err, 401)
+	})
+
+	t.Run("tenant admin token cannot manage tokens", func(t *testing.T) {
+		client := model.NewClientWithToken(ts.URL, createTokenSecret(t, sqlStore, model.TokenRoleAdmin, "tenant1"))
+
+		_, err := client.CreateToken(&model.CreateTokenRequest{Name: "ci", TenantID: "tenant1", Role: model.TokenRoleWrite})
+		requireAPIError(t, err, 403)
+
+		_, err = client.GetTokens(&model.GetTokensRequest{PerPage: 10})
+		requireAPIError(t, err, 403)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		secret, err := model.NewTokenSecret()
+		require.NoError(t, err)
+		err = sqlStore.CreateToken(&model.Token{
+			Name:      "expired",
+			TenantID:  "tenant1",
+			Role:      model.TokenRoleRead,
+			TokenHash: model.HashTokenSecret(secret),
+			ExpireAt:  model.GetMillis() - 1,
+		})
+		require.NoError(t, err)
+
+		_, err = model.NewClientWithToken(ts.URL, secret).GetWebhooks(&model.GetWebhooksRequest{PerPage: 10})
+		requireAPIError(t, err, 401)
+	})
+}
+
+func TestRingScopedTokens(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:        sqlStore,
+		Supervisor:   &mockSupervisor{},
+		Logger:       logger,
+		RequireToken: true,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	adminSecret, err := model.NewTokenSecret()
+	require.NoError(t, err)
+	err = sqlStore.CreateToken(&model.Token{
+		Name:      "bootstrap",
+		Role:      model.TokenRoleAdmin,
+		TokenHash: model.HashTokenSecret(adminSecret),
+	})
+	require.NoError(t, err)
+	adminClient := model.NewClientWithToken(ts.URL, adminSecret)
+
+	dev := &model.Ring{Name: "dev", Priority: 1, State: model.RingStateStable}
+	devGroup := &model.InstallationGroup{Name: "dev-group", State: model.InstallationGroupStable}
+	require.NoError(t, sqlStore.CreateRing(dev, devGroup))
+	staging := &model.Ring{Name: "staging", Priority: 2, State: model.RingStateStable, Annotations: model.Annotations{"env": "staging"}}
+	require.NoError(t, sqlStore.CreateRing(staging, nil))
+	prod := &model.Ring{Name: "prod", Priority: 3, State: model.RingStateStable}
+	prodGroup := &model.InstallationGroup{Name: "prod-group", State: model.InstallationGroupStable}
+	require.NoError(t, sqlStore.CreateRing(prod, prodGroup))
+
+	t.Run("invalid request", func(t *testing.T) {
+		_, err := adminClient.CreateToken(&model.CreateTokenRequest{Name: "ci", Role: model.TokenRoleAdmin, RingIDs: []string{dev.ID}})
+		requireAPIError(t, err, 400)
+
+		_, err = adminClient.CreateToken(&model.CreateTokenRequest{Name: "ci", TenantID: "tenant1", Role: model.TokenRoleWrite, RingIDs: []string{model.NewID()}})
+		requireAPIError(t, err, 400)
+
+		_, err = adminClient.CreateToken(&model.CreateTokenRequest{Name: "ci", TenantID: "tenant1", Role: model.TokenRoleWrite, RingSelector: "invalid"})
+		requireAPIError(t, err, 400)
+	})
+
+	scopedToken, err := adminClient.CreateToken(&model.CreateTokenRequest{
+		Name:         "ci",
+		TenantID:     "tenant1",
+		Role:         model.TokenRoleWrite,
+		RingIDs:      []string{dev.ID},
+		RingSelector: "env=staging",
+	})
+	require.NoError(t, err)
+	require.Equal(t, dev.ID, scopedToken.Token.RingIDs)
+	require.Equal(t, "env=staging", scopedToken.Token.RingSelector)
+	client := model.NewClientWithToken(ts.URL, scopedToken.Secret)
+
+	t.Run("rings in scope can be changed", func(t *testing.T) {
+		_, err := client.UpdateRing(dev.ID, &model.UpdateRingRequest{SoakTime: 60})
+		require.NoError(t, err)
+		_, err = client.UpdateRing(staging.ID, &model.UpdateRingRequest{SoakTime: 60})
+		require.NoError(t, err)
+		_, err = client.UpdateInstallationGroup(devGroup.ID, &model.UpdateInstallationGroupRequest{SoakTime: 60})
+		require.NoError(t, err)
+	})
+
+	t.Run("rings out of scope cannot be changed", func(t *testing.T) {
+		_, err := client.UpdateRing(prod.ID, &model.UpdateRingRequest{SoakTime: 60})
+		apiErr := requireAPIError(t, err, 403)
+		require.Equal(t, model.ErrorCodeForbidden, apiErr.Code)
+
+		_, err = client.UpdateInstallationGroup(prodGroup.ID, &model.UpdateInstallationGroupRequest{SoakTime: 60})
+		requireAPIError(t, err, 403)
+
+		_, err = client.ReleaseAllRings(&model.RingReleaseRequest{Image: "mattermost/mattermost-enterprise-edition", Version: "7.2.0"})
+		requireAPIError(t, err, 403)
+
+		_, err = client.CreateRing(&model.CreateRingRequest{Name: "other", Priority: 4})
+		requireAPIError(t, err, 403)
+	})
+
+	t.Run("unknown ring", func(t *testing.T) {
+		_, err := client.UpdateRing(model.NewID(), &model.UpdateRingRequest{SoakTime: 60})
+		requireAPIError(t, err, 403)
+
+		_, err = adminClient.UpdateRing(model.NewID(), &model.UpdateRingRequest{SoakTime: 60})
+		requireAPIError(t, err, 404)
+	})
+
+	t.Run("unregistered installation group", func(t *testing.T) {
+		installationGroup := &model.InstallationGroup{Name: "unregistered-group", State: model.InstallationGroupStable}
+		require.NoError(t, sqlStore.CreateInstallationGroup(installationGroup))
+
+		_, err := client.UpdateInstallationGroup(installationGroup.ID, &model.UpdateInstallationGroupRequest{SoakTime: 60})
+		requireAPIError(t, err, 403)
+
+		_, err = adminClient.UpdateInstallationGroup(installationGroup.ID, &model.UpdateInstallationGroupRequest{SoakTime: 60})
+		require.NoError(t, err)
+	})
+
+	t.Run("every ring can be read", func(t *testing.T) {
+		ring, err := client.GetRing(prod.ID)
+		require.NoError(t, err)
+		require.Equal(t, prod.ID, ring.ID)
+	})
+}
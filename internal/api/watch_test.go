@@ -0,0 +1,123 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchRing(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	// Fail requests on demand, as an unreachable server would.
+	var failing int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		router.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL).WithWatchInterval(10 * time.Millisecond)
+
+	ring := &model.Ring{Priority: 1, State: model.RingStateStable}
+	require.NoError(t, sqlStore.CreateRing(ring, &model.InstallationGroup{Name: "group1", State: model.InstallationGroupStable}))
+	createEvent := func(t *testing.T, newState string) {
+		// Events are ordered by their time in milliseconds.
+		time.Sleep(2 * time.Millisecond)
+		require.NoError(t, sqlStore.CreateStateChangeEvent(model.NewStateChangeEvent(model.TypeRing, ring.ID, ring, ring.State, newState)))
+	}
+	receive := func(t *testing.T, watch *model.RingWatch) *model.StateChangeEvent {
+		select {
+		case event := <-watch.Events:
+			return event
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "no event received")
+			return nil
+		}
+	}
+
+	createEvent(t, model.RingStateReleasePending)
+
+	t.Run("unknown ring", func(t *testing.T) {
+		_, err := client.WatchRing(context.Background(), model.NewID())
+		require.Error(t, err)
+	})
+
+	t.Run("watch from now on and resume", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		watch, err := client.WatchRing(ctx, ring.ID)
+		require.NoError(t, err)
+
+		createEvent(t, model.RingStateReleaseRequested)
+		event := receive(t, watch)
+		require.Equal(t, model.RingStateReleaseRequested, event.NewState)
+		require.Equal(t, event.Cursor(), watch.Cursor())
+
+		// Events created while the server is unreachable are received once
+		// it is back.
+		atomic.StoreInt32(&failing, 1)
+		time.Sleep(50 * time.Millisecond)
+		createEvent(t, model.RingStateReleaseInProgress)
+		createEvent(t, model.RingStateSoakingRequested)
+		atomic.StoreInt32(&failing, 0)
+
+		require.Equal(t, model.RingStateReleaseInProgress, receive(t, watch).NewState)
+		require.Equal(t, model.RingStateSoakingRequested, receive(t, watch).NewState)
+
+		cancel()
+		for range watch.Events {
+		}
+		require.Equal(t, context.Canceled, watch.Err())
+
+		createEvent(t, model.RingStateStable)
+		resumed, err := client.WatchRingAfter(context.Background(), ring.ID, watch.Cursor())
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, receive(t, resumed).NewState)
+	})
+
+	t.Run("wait for ring state", func(t *testing.T) {
+		actual, err := client.WaitForRingState(context.Background(), ring.ID, model.RingStateStable)
+		require.NoError(t, err)
+		require.Equal(t, ring.ID, actual.ID)
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			ring.State = model.RingStateReleasePending
+			require.NoError(t, sqlStore.UpdateRing(ring))
+			createEvent(t, model.RingStateReleasePending)
+		}()
+		actual, err = client.WaitForRingState(context.Background(), ring.ID, model.RingStateReleasePending)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePending, actual.State)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = client.WaitForRingState(ctx, ring.ID, model.RingStateReleaseFailed)
+		require.Equal(t, context.DeadlineExceeded, err)
+	})
+}
@@ -5,9 +5,11 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/webhook"
 	"github.com/mattermost/elrond/model"
 )
 
@@ -19,32 +21,82 @@ func initWebhook(apiRouter *mux.Router, context *Context) {
 
 	webhooksRouter := apiRouter.PathPrefix("/webhooks").Subrouter()
 	webhooksRouter.Handle("", addContext(handleGetWebhooks)).Methods("GET")
-	webhooksRouter.Handle("", addContext(handleCreateWebhook)).Methods("POST")
+	webhooksRouter.Handle("", addContext(validateRequestBody(model.SchemaCreateWebhookRequest, handleCreateWebhook))).Methods("POST")
 
 	webhookRouter := apiRouter.PathPrefix("/webhook/{webhook:[A-Za-z0-9]{26}}").Subrouter()
 	webhookRouter.Handle("", addContext(handleGetWebhook)).Methods("GET")
 	webhookRouter.Handle("", addContext(handleDeleteWebhook)).Methods("DELETE")
+	webhookRouter.Handle("/replay", addContext(handleReplayWebhook)).Methods("POST")
+	webhookRouter.Handle("/deliveries", addContext(handleGetWebhookDeliveries)).Methods("GET")
+	webhookRouter.Handle("/delivery/{delivery:[A-Za-z0-9]{26}}/redeliver", addContext(handleRedeliverWebhookDelivery)).Methods("POST")
 }
 
+// maxReplayEvents is the largest number of events replayed to a webhook at
+// once.
+const maxReplayEvents = 10000
+
 // handleCreateWebhook responds to POST /api/webhooks, creating a new webhook.
 func handleCreateWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
-	createWebhookRequest, err := model.NewCreateWebhookRequestFromReader(r.Body)
+	createWebhookRequest, err := model.NewCreateWebhookRequestFromReader(r.Body, c.TenantID)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to decode request")
-		w.WriteHeader(http.StatusBadRequest)
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to decode request: %s", err))
+		return
+	}
+
+	if c.TenantID != "" && createWebhookRequest.OwnerID != c.TenantID {
+		c.Logger.Warnf("unable to create webhook for owner %s on behalf of another tenant", createWebhookRequest.OwnerID)
+		outputError(c, w, http.StatusForbidden, model.ErrorCodeForbidden, fmt.Sprintf("unable to create webhook for owner %s on behalf of another tenant", createWebhookRequest.OwnerID))
+		return
+	}
+
+	idempotencyKey, existingWebhookID, ok := reserveIdempotencyKey(c, w, r, model.TypeWebhook)
+	if !ok {
+		return
+	}
+	defer releaseIdempotencyKey(c, idempotencyKey)
+	if existingWebhookID != "" {
+		webhook, err := c.Store.GetWebhook(existingWebhookID)
+		if err != nil {
+			c.Logger.WithError(err).Error("failed to query webhook")
+			outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query webhook")
+			return
+		}
+		if webhook == nil || !webhookVisibleToTenant(c, webhook) {
+			outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "webhook not found")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		outputJSON(c, w, webhook)
+		return
+	}
+
+	if !checkWebhooksQuota(c, w, createWebhookRequest.OwnerID) {
 		return
 	}
 
 	webhook := model.Webhook{
-		OwnerID: createWebhookRequest.OwnerID,
-		URL:     createWebhookRequest.URL,
+		OwnerID:        createWebhookRequest.OwnerID,
+		URL:            createWebhookRequest.URL,
+		Format:         createWebhookRequest.Format,
+		Language:       createWebhookRequest.Language,
+		LabelSelector:  createWebhookRequest.LabelSelector,
+		Secret:         createWebhookRequest.Secret,
+		Signed:         createWebhookRequest.Secret != "",
+		RateLimit:      createWebhookRequest.RateLimit,
+		Timeout:        createWebhookRequest.Timeout,
+		MaxRedirects:   createWebhookRequest.MaxRedirects,
+		MaxPayloadSize: createWebhookRequest.MaxPayloadSize,
 	}
 
 	if err = c.Store.CreateWebhook(&webhook); err != nil {
 		c.Logger.WithError(err).Error("failed to create webhook")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to create webhook")
 		return
 	}
+	completeIdempotencyKey(c, idempotencyKey, webhook.ID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
@@ -60,11 +112,11 @@ func handleGetWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
 	webhook, err := c.Store.GetWebhook(webhookID)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to query webhook")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query webhook")
 		return
 	}
-	if webhook == nil {
-		w.WriteHeader(http.StatusNotFound)
+	if webhook == nil || !webhookVisibleToTenant(c, webhook) {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "webhook not found")
 		return
 	}
 
@@ -80,10 +132,19 @@ func handleGetWebhooks(c *Context, w http.ResponseWriter, r *http.Request) {
 	page, perPage, includeDeleted, err := parsePaging(r.URL)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to parse paging parameters")
-		w.WriteHeader(http.StatusBadRequest)
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to parse paging parameters: %s", err))
 		return
 	}
 
+	if c.TenantID != "" {
+		if owner != "" && owner != c.TenantID {
+			c.Logger.Warnf("unable to list webhooks of owner %s on behalf of another tenant", owner)
+			outputError(c, w, http.StatusForbidden, model.ErrorCodeForbidden, fmt.Sprintf("unable to list webhooks of owner %s on behalf of another tenant", owner))
+			return
+		}
+		owner = c.TenantID
+	}
+
 	filter := &model.WebhookFilter{
 		OwnerID:        owner,
 		Page:           page,
@@ -94,7 +155,7 @@ func handleGetWebhooks(c *Context, w http.ResponseWriter, r *http.Request) {
 	webhooks, err := c.Store.GetWebhooks(filter)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to query webhooks")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query webhooks")
 		return
 	}
 	if webhooks == nil {
@@ -115,24 +176,217 @@ func handleDeleteWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
 	webhook, err := c.Store.GetWebhook(webhookID)
 	if err != nil {
 		c.Logger.WithError(err).Error("failed to query webhook")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	if webhook == nil {
-		w.WriteHeader(http.StatusNotFound)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query webhook")
 		return
 	}
-	if webhook.IsDeleted() {
-		c.Logger.Warn("unable to delete webhook that is already deleted")
-		w.WriteHeader(http.StatusBadRequest)
+	// A deleted webhook is reported as not found, like any deleted resource.
+	if webhook == nil || webhook.IsDeleted() || !webhookVisibleToTenant(c, webhook) {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "webhook not found")
 		return
 	}
 
 	if err = c.Store.DeleteWebhook(webhookID); err != nil {
 		c.Logger.WithError(err).Error("failed to mark webhook as deleted")
-		w.WriteHeader(http.StatusInternalServerError)
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to mark webhook as deleted")
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// handleReplayWebhook responds to POST /api/webhook/{webhook}/replay,
+// re-delivering the state change events that occurred between the from and
+// to times, in milliseconds, to the webhook. To defaults to now.
+func handleReplayWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID := vars["webhook"]
+	c.Logger = c.Logger.WithField("webhook", webhookID)
+
+	from, err := parseInt64(r.URL, "from", 0)
+	if err != nil || from <= 0 {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "from must be a time in milliseconds")
+		return
+	}
+	to, err := parseInt64(r.URL, "to", model.GetMillis())
+	if err != nil {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "to must be a time in milliseconds")
+		return
+	}
+	if to <= from {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "to must be after from")
+		return
+	}
+
+	hook, err := c.Store.GetWebhook(webhookID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query webhook")
+		return
+	}
+	if hook == nil || !webhookVisibleToTenant(c, hook) {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "webhook not found")
+		return
+	}
+	if hook.IsDeleted() {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "webhook is deleted")
+		return
+	}
+
+	events, err := c.Store.GetStateChangeEvents(&model.StateChangeEventFilter{
+		From:    from,
+		To:      to,
+		PerPage: maxReplayEvents + 1,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query state change events")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query state change events")
+		return
+	}
+	if len(events) > maxReplayEvents {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("more than %d events to replay, narrow the range", maxReplayEvents))
+		return
+	}
+
+	// Payloads carry the current annotations of their ring as labels.
+	rings := make(map[string]*model.Ring)
+	payloads := make([]*model.WebhookPayload, 0, len(events))
+	for _, event := range events {
+		ring, ok := rings[event.RingID]
+		if !ok {
+			ring, err = c.Store.GetRing(event.RingID)
+			if err != nil {
+				c.Logger.WithError(err).Error("failed to query ring")
+				outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query ring")
+				return
+			}
+			rings[event.RingID] = ring
+		}
+
+		var labels map[string]string
+		if ring != nil {
+			labels = ring.Annotations
+		}
+		payloads = append(payloads, event.WebhookPayload(labels))
+	}
+
+	replay := &model.WebhookReplay{
+		WebhookID: hook.ID,
+		From:      from,
+		To:        to,
+		Events:    webhook.Replay(hook, payloads, c.Logger.WithField("webhookEvent", "replay")),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, replay)
+}
+
+// handleGetWebhookDeliveries responds to GET /api/webhook/{webhook}/deliveries,
+// returning the specified page of the deliveries of the webhook, optionally
+// in the given state.
+func handleGetWebhookDeliveries(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID := vars["webhook"]
+	c.Logger = c.Logger.WithField("webhook", webhookID)
+
+	page, perPage, _, err := parsePaging(r.URL)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to parse paging parameters")
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("failed to parse paging parameters: %s", err))
+		return
+	}
+	state := r.URL.Query().Get("state")
+	if state != "" && !model.ValidWebhookDeliveryState(state) {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("unsupported delivery state %q", state))
+		return
+	}
+
+	hook, err := c.Store.GetWebhook(webhookID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query webhook")
+		return
+	}
+	if hook == nil || !webhookVisibleToTenant(c, hook) {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "webhook not found")
+		return
+	}
+
+	deliveries, err := c.Store.GetWebhookDeliveries(&model.WebhookDeliveryFilter{
+		WebhookID: webhookID,
+		State:     state,
+		Page:      page,
+		PerPage:   perPage,
+	})
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook deliveries")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query webhook deliveries")
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*model.WebhookDelivery{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	outputJSON(c, w, deliveries)
+}
+
+// handleRedeliverWebhookDelivery responds to POST
+// /api/webhook/{webhook}/delivery/{delivery}/redeliver, scheduling a failed
+// delivery to be attempted again, with a fresh set of attempts.
+func handleRedeliverWebhookDelivery(c *Context, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID := vars["webhook"]
+	deliveryID := vars["delivery"]
+	c.Logger = c.Logger.WithField("webhook", webhookID).WithField("delivery", deliveryID)
+
+	hook, err := c.Store.GetWebhook(webhookID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query webhook")
+		return
+	}
+	if hook == nil || !webhookVisibleToTenant(c, hook) {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "webhook not found")
+		return
+	}
+	if hook.IsDeleted() {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, "webhook is deleted")
+		return
+	}
+
+	delivery, err := c.Store.GetWebhookDelivery(deliveryID)
+	if err != nil {
+		c.Logger.WithError(err).Error("failed to query webhook delivery")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to query webhook delivery")
+		return
+	}
+	if delivery == nil || delivery.WebhookID != webhookID {
+		outputError(c, w, http.StatusNotFound, model.ErrorCodeNotFound, "webhook delivery not found")
+		return
+	}
+	if delivery.State != model.WebhookDeliveryFailed {
+		outputError(c, w, http.StatusBadRequest, model.ErrorCodeBadRequest, fmt.Sprintf("webhook delivery is %s, only failed deliveries can be redelivered", delivery.State))
+		return
+	}
+
+	delivery.State = model.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = model.GetMillis()
+	if err = c.Store.UpdateWebhookDelivery(delivery); err != nil {
+		c.Logger.WithError(err).Error("failed to update webhook delivery")
+		outputError(c, w, http.StatusInternalServerError, model.ErrorCodeInternal, "failed to update webhook delivery")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	outputJSON(c, w, delivery)
+}
+
+// webhookVisibleToTenant returns whether the tenant of the request, if any,
+// owns the given webhook. Webhooks of other tenants are reported as not found.
+func webhookVisibleToTenant(c *Context, webhook *model.Webhook) bool {
+	return c.TenantID == "" || webhook.OwnerID == c.TenantID
+}
@@ -6,9 +6,11 @@ package api_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -51,14 +53,14 @@ func TestCreateWebhook(t *testing.T) {
 		_, err := client.CreateWebhook(&model.CreateWebhookRequest{
 			URL: "https://validurl.com",
 		})
-		require.EqualError(t, err, "failed with status code 400")
+		requireAPIError(t, err, 400)
 	})
 
 	t.Run("missing url", func(t *testing.T) {
 		_, err := client.CreateWebhook(&model.CreateWebhookRequest{
 			OwnerID: "owner",
 		})
-		require.EqualError(t, err, "failed with status code 400")
+		requireAPIError(t, err, 400)
 	})
 
 	t.Run("invalid url", func(t *testing.T) {
@@ -66,7 +68,7 @@ func TestCreateWebhook(t *testing.T) {
 			OwnerID: "owner",
 			URL:     "htp://invalidurl.com",
 		})
-		require.EqualError(t, err, "failed with status code 400")
+		requireAPIError(t, err, 400)
 	})
 
 	t.Run("valid", func(t *testing.T) {
@@ -78,9 +80,69 @@ func TestCreateWebhook(t *testing.T) {
 		require.NotEmpty(t, webhook.ID)
 		require.Equal(t, "owner", webhook.OwnerID)
 		require.Equal(t, "https://validurl.com", webhook.URL)
+		require.Equal(t, model.WebhookFormatElrond, webhook.Format)
 		require.NotEqual(t, 0, webhook.CreateAt)
 		require.EqualValues(t, 0, webhook.DeleteAt)
 	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := client.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID: "owner",
+			URL:     "https://teams.example.com",
+			Format:  "carrier-pigeon",
+		})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("teams", func(t *testing.T) {
+		webhook, err := client.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID: "owner",
+			URL:     "https://teams.example.com",
+			Format:  model.WebhookFormatTeams,
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.WebhookFormatTeams, webhook.Format)
+	})
+
+	t.Run("label selector", func(t *testing.T) {
+		webhook, err := client.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID:       "owner",
+			URL:           "https://team.example.com",
+			LabelSelector: " env = prod , team!=payments",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "env=prod,team!=payments", webhook.LabelSelector)
+
+		webhook, err = client.GetWebhook(webhook.ID)
+		require.NoError(t, err)
+		require.Equal(t, "env=prod,team!=payments", webhook.LabelSelector)
+	})
+
+	t.Run("invalid label selector", func(t *testing.T) {
+		_, err := client.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID:       "owner",
+			URL:           "https://team.example.com",
+			LabelSelector: "env",
+		})
+		requireAPIError(t, err, 400)
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		webhook, err := client.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID:   "owner",
+			URL:       "https://small.example.com",
+			RateLimit: 30,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 30, webhook.RateLimit)
+
+		_, err = client.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID:   "owner",
+			URL:       "https://small.example.com",
+			RateLimit: -1,
+		})
+		requireAPIError(t, err, 400)
+	})
 }
 
 func TestGetWebhooks(t *testing.T) {
@@ -290,7 +352,7 @@ func TestDeleteWebhook(t *testing.T) {
 
 	t.Run("unknown webhook", func(t *testing.T) {
 		err := client.DeleteWebhook(model.NewID())
-		require.EqualError(t, err, "failed with status code 404")
+		requireAPIError(t, err, 404)
 	})
 
 	t.Run("known webhook", func(t *testing.T) {
@@ -300,7 +362,7 @@ func TestDeleteWebhook(t *testing.T) {
 
 	t.Run("already deleted webhook", func(t *testing.T) {
 		err := client.DeleteWebhook(webhook.ID)
-		require.Error(t, err)
+		requireAPIError(t, err, 404)
 	})
 
 	t.Run("ensure webhook is deleted", func(t *testing.T) {
@@ -309,3 +371,250 @@ func TestDeleteWebhook(t *testing.T) {
 		require.True(t, webhook.IsDeleted())
 	})
 }
+
+func TestWebhookTenantScoping(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:        sqlStore,
+		Supervisor:   &mockSupervisor{},
+		Logger:       logger,
+		TenantQuotas: &model.TenantQuotas{Default: model.TenantQuota{MaxWebhooks: 2}},
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client1 := newTenantClient(t, sqlStore, ts.URL, "tenant1")
+	client2 := newTenantClient(t, sqlStore, ts.URL, "tenant2")
+
+	t.Run("owner defaults to tenant", func(t *testing.T) {
+		webhook, err := client1.CreateWebhook(&model.CreateWebhookRequest{
+			URL: "https://tenant1.com/1",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "tenant1", webhook.OwnerID)
+	})
+
+	t.Run("owner must match tenant", func(t *testing.T) {
+		_, err := client1.CreateWebhook(&model.CreateWebhookRequest{
+			OwnerID: "tenant2",
+			URL:     "https://tenant1.com/2",
+		})
+		requireAPIError(t, err, 403)
+	})
+
+	t.Run("per-owner limit", func(t *testing.T) {
+		_, err := client1.CreateWebhook(&model.CreateWebhookRequest{
+			URL: "https://tenant1.com/3",
+		})
+		require.NoError(t, err)
+
+		_, err = client1.CreateWebhook(&model.CreateWebhookRequest{
+			URL: "https://tenant1.com/4",
+		})
+		apiErr := requireAPIError(t, err, 403)
+		require.Equal(t, model.ErrorCodeLimitExceeded, apiErr.Code)
+		require.Equal(t, "tenant1", apiErr.Details["tenant"])
+	})
+
+	t.Run("listing is scoped to tenant", func(t *testing.T) {
+		webhooks, err := client2.GetWebhooks(&model.GetWebhooksRequest{PerPage: 100})
+		require.NoError(t, err)
+		require.Empty(t, webhooks)
+
+		_, err = client2.GetWebhooks(&model.GetWebhooksRequest{OwnerID: "tenant1", PerPage: 100})
+		requireAPIError(t, err, 403)
+
+		webhooks, err = client1.GetWebhooks(&model.GetWebhooksRequest{PerPage: 100})
+		require.NoError(t, err)
+		require.Len(t, webhooks, 2)
+	})
+
+	t.Run("other tenant webhooks are not found", func(t *testing.T) {
+		webhooks, err := client1.GetWebhooks(&model.GetWebhooksRequest{PerPage: 100})
+		require.NoError(t, err)
+
+		webhook, err := client2.GetWebhook(webhooks[0].ID)
+		require.NoError(t, err)
+		require.Nil(t, webhook)
+
+		err = client2.DeleteWebhook(webhooks[0].ID)
+		requireAPIError(t, err, 404)
+
+		err = client1.DeleteWebhook(webhooks[0].ID)
+		require.NoError(t, err)
+	})
+}
+
+func TestReplayWebhook(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	var lock sync.Mutex
+	received := []*model.WebhookPayload{}
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := &model.WebhookPayload{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(payload))
+		lock.Lock()
+		received = append(received, payload)
+		lock.Unlock()
+	}))
+	defer target.Close()
+
+	production, err := client.CreateRing(&model.CreateRingRequest{Name: "production", Priority: 1, Annotations: model.Annotations{"env": "prod"}})
+	require.NoError(t, err)
+	staging, err := client.CreateRing(&model.CreateRingRequest{Name: "staging", Priority: 2, Annotations: model.Annotations{"env": "staging"}})
+	require.NoError(t, err)
+
+	for i, ring := range []*model.Ring{production, staging, production, production} {
+		require.NoError(t, sqlStore.CreateStateChangeEvent(&model.StateChangeEvent{
+			ResourceType: model.TypeRing,
+			ResourceID:   ring.ID,
+			RingID:       ring.ID,
+			OldState:     model.RingStateStable,
+			NewState:     model.RingStateReleasePending,
+			Timestamp:    int64(1000 + i),
+		}))
+	}
+
+	hook, err := client.CreateWebhook(&model.CreateWebhookRequest{
+		OwnerID:       "owner",
+		URL:           target.URL,
+		LabelSelector: "env=prod",
+	})
+	require.NoError(t, err)
+
+	t.Run("replay", func(t *testing.T) {
+		replay, err := client.ReplayWebhook(hook.ID, &model.ReplayWebhookRequest{From: 1000, To: 1003})
+		require.NoError(t, err)
+		require.Equal(t, &model.WebhookReplay{WebhookID: hook.ID, From: 1000, To: 1003, Events: 2}, replay)
+
+		require.Eventually(t, func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+			return len(received) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+		lock.Lock()
+		defer lock.Unlock()
+		require.Equal(t, int64(1000*time.Millisecond), received[0].Timestamp)
+		require.Equal(t, int64(1002*time.Millisecond), received[1].Timestamp)
+		for _, payload := range received {
+			require.Equal(t, production.ID, payload.ID)
+			require.Equal(t, "true", payload.ExtraData["Replayed"])
+			require.Equal(t, "prod", payload.Labels["env"])
+		}
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		_, err := client.ReplayWebhook(hook.ID, &model.ReplayWebhookRequest{})
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		_, err = client.ReplayWebhook(hook.ID, &model.ReplayWebhookRequest{From: 2000, To: 1000})
+		requireAPIError(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("unknown webhook", func(t *testing.T) {
+		_, err := client.ReplayWebhook(model.NewID(), &model.ReplayWebhookRequest{From: 1000})
+		requireAPIError(t, err, http.StatusNotFound)
+	})
+
+	t.Run("deleted webhook", func(t *testing.T) {
+		require.NoError(t, client.DeleteWebhook(hook.ID))
+
+		_, err := client.ReplayWebhook(hook.ID, &model.ReplayWebhookRequest{From: 1000})
+		requireAPIError(t, err, http.StatusBadRequest)
+	})
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := model.NewClient(ts.URL)
+
+	webhook, err := client.CreateWebhook(&model.CreateWebhookRequest{
+		OwnerID: "owner",
+		URL:     "https://validurl.com",
+		Secret:  "secret",
+	})
+	require.NoError(t, err)
+	require.Empty(t, webhook.Secret)
+
+	stored, err := sqlStore.GetWebhook(webhook.ID)
+	require.NoError(t, err)
+	require.Equal(t, "secret", stored.Secret)
+
+	failed := &model.WebhookDelivery{
+		WebhookID: webhook.ID,
+		Payload:   `{"id":"1"}`,
+		State:     model.WebhookDeliveryFailed,
+		Attempts:  5,
+		LastError: "webhook responded with status 503",
+	}
+	delivered := &model.WebhookDelivery{
+		WebhookID: webhook.ID,
+		Payload:   `{"id":"2"}`,
+		State:     model.WebhookDeliveryDelivered,
+		Attempts:  1,
+	}
+	require.NoError(t, sqlStore.CreateWebhookDelivery(failed))
+	require.NoError(t, sqlStore.CreateWebhookDelivery(delivered))
+
+	t.Run("list", func(t *testing.T) {
+		deliveries, err := client.GetWebhookDeliveries(webhook.ID, &model.GetWebhookDeliveriesRequest{PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+
+		deliveries, err = client.GetWebhookDeliveries(webhook.ID, &model.GetWebhookDeliveriesRequest{State: model.WebhookDeliveryFailed, PerPage: 10})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, failed.ID, deliveries[0].ID)
+		require.Equal(t, "webhook responded with status 503", deliveries[0].LastError)
+
+		_, err = client.GetWebhookDeliveries(webhook.ID, &model.GetWebhookDeliveriesRequest{State: "unknown", PerPage: 10})
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		_, err = client.GetWebhookDeliveries(model.NewID(), &model.GetWebhookDeliveriesRequest{PerPage: 10})
+		requireAPIError(t, err, http.StatusNotFound)
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		_, err := client.RedeliverWebhookDelivery(webhook.ID, delivered.ID)
+		requireAPIError(t, err, http.StatusBadRequest)
+
+		_, err = client.RedeliverWebhookDelivery(webhook.ID, model.NewID())
+		requireAPIError(t, err, http.StatusNotFound)
+
+		delivery, err := client.RedeliverWebhookDelivery(webhook.ID, failed.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.WebhookDeliveryPending, delivery.State)
+		require.Zero(t, delivery.Attempts)
+
+		due, err := sqlStore.GetWebhookDeliveriesDue(model.GetMillis(), 10)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, failed.ID, due[0].ID)
+	})
+}
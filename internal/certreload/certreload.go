@@ -0,0 +1,144 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package certreload provides TLS certificates that are reloaded from disk
+// whenever the underlying files change.
+package certreload
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Reloader serves a TLS certificate loaded from a cert and key file pair,
+// reloading it when either file is modified on disk.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	logger   log.FieldLogger
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewReloader loads the given cert and key files and starts checking them
+// for changes at the given interval. An interval of 0 disables reloading.
+func NewReloader(certFile, keyFile string, interval time.Duration, logger log.FieldLogger) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger.WithField("cert-file", certFile),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	if interval <= 0 {
+		close(r.done)
+		return r, nil
+	}
+
+	go r.run(interval)
+
+	return r, nil
+}
+
+// GetCertificate returns the currently loaded certificate. It is intended to
+// be used as the GetCertificate callback of a tls.Config.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.certificate, nil
+}
+
+// TLSConfig returns a tls.Config serving the reloaded certificate.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+// Reload loads the cert and key files if either was modified since the last
+// load, returning whether a new certificate was loaded. A failed reload keeps
+// serving the previous certificate.
+func (r *Reloader) Reload() (bool, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to stat cert file")
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to stat key file")
+	}
+
+	r.mu.RLock()
+	unchanged := r.certificate != nil &&
+		certInfo.ModTime().Equal(r.certModTime) &&
+		keyInfo.ModTime().Equal(r.keyModTime)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to load TLS key pair")
+	}
+
+	r.mu.Lock()
+	r.certificate = &certificate
+	r.certModTime = certInfo.ModTime()
+	r.keyModTime = keyInfo.ModTime()
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+// Close stops checking the cert files for changes.
+func (r *Reloader) Close() {
+	select {
+	case <-r.stop:
+	default:
+		close(r.stop)
+	}
+	<-r.done
+}
+
+func (r *Reloader) run(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				r.logger.WithError(err).Error("Failed to reload TLS certificate; continuing with the previous one")
+				continue
+			}
+			if reloaded {
+				r.logger.Info("Reloaded TLS certificate")
+			}
+		case <-r.stop:
+			return
+		}
+	}
+}
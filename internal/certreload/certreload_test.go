@@ -0,0 +1,116 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package certreload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyPair(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func commonName(t *testing.T, r *Reloader) string {
+	certificate, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	require.NoError(t, err)
+
+	return leaf.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	now := time.Now()
+
+	t.Run("missing files", func(t *testing.T) {
+		_, err := NewReloader(certFile, keyFile, 0, logger)
+		require.Error(t, err)
+	})
+
+	writeKeyPair(t, certFile, keyFile, "first", now.Add(-time.Minute))
+
+	r, err := NewReloader(certFile, keyFile, 0, logger)
+	require.NoError(t, err)
+	defer r.Close()
+	require.Equal(t, "first", commonName(t, r))
+
+	t.Run("unchanged files", func(t *testing.T) {
+		reloaded, err := r.Reload()
+		require.NoError(t, err)
+		require.False(t, reloaded)
+	})
+
+	t.Run("changed files", func(t *testing.T) {
+		writeKeyPair(t, certFile, keyFile, "second", now)
+
+		reloaded, err := r.Reload()
+		require.NoError(t, err)
+		require.True(t, reloaded)
+		require.Equal(t, "second", commonName(t, r))
+	})
+
+	t.Run("invalid files keep previous certificate", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(certFile, []byte("invalid"), 0600))
+		require.NoError(t, os.Chtimes(certFile, now.Add(time.Minute), now.Add(time.Minute)))
+
+		_, err := r.Reload()
+		require.Error(t, err)
+		require.Equal(t, "second", commonName(t, r))
+	})
+}
+
+func TestReloaderPolling(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	now := time.Now()
+
+	writeKeyPair(t, certFile, keyFile, "first", now.Add(-time.Minute))
+
+	r, err := NewReloader(certFile, keyFile, 10*time.Millisecond, logger)
+	require.NoError(t, err)
+	defer r.Close()
+
+	writeKeyPair(t, certFile, keyFile, "second", now)
+
+	require.Eventually(t, func() bool {
+		return commonName(t, r) == "second"
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package config loads command configuration files. A configuration file
+// sets the flags of a command: each key is the name of a flag, and nested
+// sections are joined to their keys with dashes, so that `smtp: {server: x}`
+// sets --smtp-server.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// Load sets the flags from the given YAML or TOML file, chosen by its
+// extension. Flags set on the command line take precedence over the file.
+// Unknown keys and invalid values are rejected, along with the given flags
+// that cannot be set from a file.
+func Load(path string, flags *pflag.FlagSet, excluded ...string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read configuration file")
+	}
+
+	settings := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &settings)
+	case ".toml":
+		err = toml.Unmarshal(content, &settings)
+	default:
+		return errors.Errorf("unsupported configuration file extension %q, expected .yaml, .yml or .toml", filepath.Ext(path))
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to parse configuration file")
+	}
+
+	values := make(map[string]interface{})
+	if err = flatten("", settings, flags, values); err != nil {
+		return err
+	}
+
+	// Set the values in a stable order so that errors are reproducible.
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, excludedName := range excluded {
+			if name == excludedName {
+				return errors.Errorf("%s cannot be set in the configuration file", name)
+			}
+		}
+		if flags.Changed(name) {
+			continue
+		}
+		if err = set(flags.Lookup(name), values[name]); err != nil {
+			return errors.Wrapf(err, "invalid value for %s", name)
+		}
+	}
+
+	return nil
+}
+
+// Reload returns a new flag set, defined by the given function, with the flags
+// set on the command line of the current flag set along with the settings of
+// the file. Settings removed from the file since the current flag set was
+// loaded return to their defaults.
+func Reload(path string, define func(flags *pflag.FlagSet), current *pflag.FlagSet, excluded ...string) (*pflag.FlagSet, error) {
+	flags := pflag.NewFlagSet("reload", pflag.ContinueOnError)
+	define(flags)
+
+	var err error
+	current.Visit(func(currentFlag *pflag.Flag) {
+		flag := flags.Lookup(currentFlag.Name)
+		if err != nil || flag == nil {
+			return
+		}
+		if err = copyValue(flag, currentFlag); err != nil {
+			err = errors.Wrapf(err, "failed to copy %s", currentFlag.Name)
+			return
+		}
+		flag.Changed = true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = Load(path, flags, excluded...); err != nil {
+		return nil, err
+	}
+
+	return flags, nil
+}
+
+// copyValue sets the value of the flag to the value of another flag of the
+// same type.
+func copyValue(flag, from *pflag.Flag) error {
+	if sliceValue, ok := from.Value.(pflag.SliceValue); ok {
+		return flag.Value.(pflag.SliceValue).Replace(sliceValue.GetSlice())
+	}
+	if strings.HasPrefix(from.Value.Type(), "stringTo") {
+		// Maps are formatted as [key=value,...].
+		return flag.Value.Set(strings.TrimSuffix(strings.TrimPrefix(from.Value.String(), "["), "]"))
+	}
+
+	return flag.Value.Set(from.Value.String())
+}
+
+// flatten maps the settings to the names of the flags they set.
+func flatten(prefix string, settings map[string]interface{}, flags *pflag.FlagSet, values map[string]interface{}) error {
+	for key, value := range settings {
+		name := key
+		if prefix != "" {
+			name = prefix + "-" + key
+		}
+
+		if flags.Lookup(name) != nil {
+			values[name] = value
+			continue
+		}
+		section, ok := value.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("unknown setting %s", name)
+		}
+		if err := flatten(name, section, flags, values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// set sets the flag to the given value, as decoded from the file.
+func set(flag *pflag.Flag, value interface{}) error {
+	switch typed := value.(type) {
+	case []interface{}:
+		sliceValue, ok := flag.Value.(pflag.SliceValue)
+		if !ok {
+			return errors.Errorf("expected a %s, got a list", flag.Value.Type())
+		}
+		items := make([]string, 0, len(typed))
+		for _, item := range typed {
+			items = append(items, fmt.Sprint(item))
+		}
+		return sliceValue.Replace(items)
+
+	case map[string]interface{}:
+		if !strings.HasPrefix(flag.Value.Type(), "stringTo") {
+			return errors.Errorf("expected a %s, got a section", flag.Value.Type())
+		}
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(typed))
+		for _, key := range keys {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", key, typed[key]))
+		}
+		return flag.Value.Set(strings.Join(pairs, ","))
+
+	case nil:
+		return errors.New("value cannot be empty")
+
+	default:
+		return flag.Value.Set(fmt.Sprint(typed))
+	}
+}
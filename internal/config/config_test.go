@@ -0,0 +1,153 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func newFlags() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("config", "", "")
+	flags.String("database", "sqlite://elrond.db", "")
+	flags.Int("poll", 30, "")
+	flags.Bool("require-api-token", false, "")
+	flags.String("smtp-server", "", "")
+	flags.String("smtp-from", "elrond@localhost", "")
+	flags.StringSlice("metrics-labeled-rings", []string{}, "")
+	flags.StringToString("jira-statuses", map[string]string{"stable": "Done"}, "")
+	flags.StringToInt("environment-soak-times", map[string]int{}, "")
+
+	return flags
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		flags := newFlags()
+		path := writeFile(t, "elrond.yaml", `
+database: postgres://localhost/elrond
+poll: 10
+require-api-token: true
+smtp:
+  server: smtp.example.com:587
+metrics-labeled-rings: [canary, production]
+jira-statuses:
+  release-pending: In Progress
+environment-soak-times:
+  production: 3600
+`)
+		require.NoError(t, Load(path, flags, "config"))
+
+		database, _ := flags.GetString("database")
+		require.Equal(t, "postgres://localhost/elrond", database)
+		poll, _ := flags.GetInt("poll")
+		require.Equal(t, 10, poll)
+		requireAPIToken, _ := flags.GetBool("require-api-token")
+		require.True(t, requireAPIToken)
+		smtpServer, _ := flags.GetString("smtp-server")
+		require.Equal(t, "smtp.example.com:587", smtpServer)
+		smtpFrom, _ := flags.GetString("smtp-from")
+		require.Equal(t, "elrond@localhost", smtpFrom)
+		rings, _ := flags.GetStringSlice("metrics-labeled-rings")
+		require.Equal(t, []string{"canary", "production"}, rings)
+		statuses, _ := flags.GetStringToString("jira-statuses")
+		require.Equal(t, map[string]string{"release-pending": "In Progress"}, statuses)
+		soakTimes, _ := flags.GetStringToInt("environment-soak-times")
+		require.Equal(t, map[string]int{"production": 3600}, soakTimes)
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		flags := newFlags()
+		path := writeFile(t, "elrond.toml", `
+poll = 15
+
+[smtp]
+server = "smtp.example.com:25"
+from = "releases@example.com"
+`)
+		require.NoError(t, Load(path, flags, "config"))
+
+		poll, _ := flags.GetInt("poll")
+		require.Equal(t, 15, poll)
+		smtpFrom, _ := flags.GetString("smtp-from")
+		require.Equal(t, "releases@example.com", smtpFrom)
+	})
+
+	t.Run("command line takes precedence", func(t *testing.T) {
+		flags := newFlags()
+		require.NoError(t, flags.Parse([]string{"--poll", "5"}))
+		path := writeFile(t, "elrond.yaml", "poll: 10\n")
+		require.NoError(t, Load(path, flags, "config"))
+
+		poll, _ := flags.GetInt("poll")
+		require.Equal(t, 5, poll)
+	})
+
+	for name, test := range map[string]struct {
+		file    string
+		content string
+		err     string
+	}{
+		"unknown setting":      {"elrond.yaml", "unknown: true\n", "unknown setting unknown"},
+		"unknown section key":  {"elrond.yaml", "smtp:\n  port: 25\n", "unknown setting smtp-port"},
+		"invalid type":         {"elrond.yaml", "poll: often\n", "invalid value for poll"},
+		"list for scalar":      {"elrond.yaml", "poll: [1, 2]\n", "invalid value for poll"},
+		"empty value":          {"elrond.yaml", "database:\n", "invalid value for database"},
+		"excluded setting":     {"elrond.yaml", "config: other.yaml\n", "config cannot be set in the configuration file"},
+		"invalid syntax":       {"elrond.toml", "poll = \n", "failed to parse configuration file"},
+		"unsupported format":   {"elrond.json", "{}", "unsupported configuration file extension"},
+		"invalid section type": {"elrond.yaml", "database: {host: localhost}\n", "invalid value for database"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := Load(writeFile(t, test.file, test.content), newFlags(), "config")
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.err)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		require.Error(t, Load(filepath.Join(t.TempDir(), "missing.yaml"), newFlags()))
+	})
+}
+
+func TestReload(t *testing.T) {
+	current := newFlags()
+	require.NoError(t, current.Parse([]string{"--metrics-labeled-rings", "canary,production", "--jira-statuses", "stable=Closed"}))
+	path := writeFile(t, "elrond.yaml", "poll: 10\nsmtp-from: releases@example.com\n")
+	require.NoError(t, Load(path, current, "config"))
+
+	path = writeFile(t, "elrond.yaml", "poll: 20\n")
+	define := func(flags *pflag.FlagSet) { flags.AddFlagSet(newFlags()) }
+	flags, err := Reload(path, define, current, "config")
+	require.NoError(t, err)
+
+	poll, _ := flags.GetInt("poll")
+	require.Equal(t, 20, poll)
+	smtpFrom, _ := flags.GetString("smtp-from")
+	require.Equal(t, "elrond@localhost", smtpFrom, "settings removed from the file return to their defaults")
+	rings, _ := flags.GetStringSlice("metrics-labeled-rings")
+	require.Equal(t, []string{"canary", "production"}, rings)
+	statuses, _ := flags.GetStringToString("jira-statuses")
+	require.Equal(t, map[string]string{"stable": "Closed"}, statuses)
+
+	// The current flags are left untouched.
+	poll, _ = current.GetInt("poll")
+	require.Equal(t, 10, poll)
+
+	_, err = Reload(writeFile(t, "elrond.yaml", "poll: often\n"), define, current, "config")
+	require.Error(t, err)
+}
@@ -5,12 +5,32 @@
 package elrond
 
 import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mattermost/elrond/model"
 	log "github.com/sirupsen/logrus"
 )
 
 // ProvisioningParams represent configuration used during various provisioning operations.
 type ProvisioningParams struct {
 	ProvisionerGroupReleaseTimeout int
+	// InstallationLoadURL, when set, is the URL the load of each
+	// installation is fetched from, with {installation} replaced by the ID
+	// of the installation.
+	InstallationLoadURL string
+	// DatabaseSnapshotURL, when set, is the hook the databases of the
+	// installations are snapshotted through before their installation
+	// groups are released, instead of installation backups.
+	DatabaseSnapshotURL string
+	// DatabaseSnapshotTimeout is the time, in seconds, the snapshots of the
+	// databases of an installation group are waited for at most.
+	DatabaseSnapshotTimeout int
+	// LookupCacheTTL is the time, in seconds, read-only lookups of
+	// provisioner groups, their status and their installations are cached
+	// for. A TTL of 0 disables the cache.
+	LookupCacheTTL int
 }
 
 // ElProvisioner provisions release rings.
@@ -18,6 +38,11 @@ type ElProvisioner struct {
 	params            ProvisioningParams
 	logger            log.FieldLogger
 	ProvisionerServer string
+	credentials       atomic.Value
+	lookups           *lookupCache
+
+	callbacksLock sync.Mutex
+	callbacks     map[string]chan *model.ProvisionerCallback
 }
 
 // NewElrondProvisioner creates a new ElrondProvisioner.
@@ -28,5 +53,7 @@ func NewElrondProvisioner(provisioningParams ProvisioningParams, logger log.Fiel
 		params:            provisioningParams,
 		logger:            logger,
 		ProvisionerServer: provisionerServer,
+		lookups:           newLookupCache(time.Duration(provisioningParams.LookupCacheTTL) * time.Second),
+		callbacks:         make(map[string]chan *model.ProvisionerCallback),
 	}
 }
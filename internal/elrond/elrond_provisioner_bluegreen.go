@@ -0,0 +1,174 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"fmt"
+
+	"github.com/mattermost/elrond/model"
+	cmodel "github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+// StandUpGreenInstallationGroup stands up the green provisioner group of a
+// blue/green release of an installation group, copying the settings of its
+// current, blue, group with the given release. A green group left over by a
+// previous attempt of the release is updated instead. It returns the ID of
+// the green group.
+func (provisioner *ElProvisioner) StandUpGreenInstallationGroup(installationGroup *model.InstallationGroup, image, version string, parameters *model.InstallationGroupReleaseParameters) (string, error) {
+	logger := provisioner.logger.WithField("installationgroup", installationGroup.ID)
+	client := provisioner.newAnnotatedProvisionerClient(installationGroup.Annotations)
+	defer provisioner.InvalidateLookupCache(installationGroup.GreenProvisionerGroupID)
+	env := releaseEnv(parameters)
+
+	if installationGroup.GreenProvisionerGroupID != "" {
+		green, err := client.GetGroup(installationGroup.GreenProvisionerGroupID)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get green group %s", installationGroup.GreenProvisionerGroupID)
+		}
+		if green != nil && green.DeleteAt == 0 {
+			if green.Image != image || green.Version != version || envChanged(green.MattermostEnv, env) {
+				logger.Infof("Updating green provisioner group %s to %s:%s", green.ID, image, version)
+				_, err = client.UpdateGroup(&cmodel.PatchGroupRequest{
+					ID:            green.ID,
+					Version:       &version,
+					Image:         &image,
+					MattermostEnv: env,
+				})
+				if err != nil {
+					return "", errors.Wrap(err, "failed to patch green provisioner group")
+				}
+			}
+			return green.ID, nil
+		}
+	}
+
+	blue, err := client.GetGroup(installationGroup.ProvisionerGroupID)
+	if blue == nil || err != nil {
+		return "", errors.Wrapf(err, "failed to get group %s, make sure it exists", installationGroup.ProvisionerGroupID)
+	}
+
+	groupEnv := cmodel.EnvVarMap{}
+	for name, value := range blue.MattermostEnv {
+		groupEnv[name] = value
+	}
+	for name, value := range env {
+		groupEnv[name] = value
+	}
+	var annotations []string
+	for _, annotation := range blue.Annotations {
+		annotations = append(annotations, annotation.Name)
+	}
+
+	logger.Infof("Standing up green provisioner group for %s:%s next to provisioner group %s", image, version, blue.ID)
+	green, err := client.CreateGroup(&cmodel.CreateGroupRequest{
+		Name:          fmt.Sprintf("%s-%s", installationGroup.Name, version),
+		Description:   fmt.Sprintf("Green group of the blue/green release of %s to %s:%s", installationGroup.Name, image, version),
+		Version:       version,
+		Image:         image,
+		MaxRolling:    blue.MaxRolling,
+		MattermostEnv: groupEnv,
+		Annotations:   annotations,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create green provisioner group")
+	}
+
+	return green.ID, nil
+}
+
+// VerifyGreenInstallationGroup verifies the green provisioner group of a
+// blue/green release of an installation group is ready for its installations
+// to be switched over to it.
+func (provisioner *ElProvisioner) VerifyGreenInstallationGroup(installationGroup *model.InstallationGroup, image, version string) error {
+	client := provisioner.newAnnotatedProvisionerClient(installationGroup.Annotations)
+
+	green, err := client.GetGroup(installationGroup.GreenProvisionerGroupID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get green group %s", installationGroup.GreenProvisionerGroupID)
+	}
+	if green == nil || green.DeleteAt != 0 {
+		return errors.Errorf("green provisioner group %s not found", installationGroup.GreenProvisionerGroupID)
+	}
+	if green.Image != image || green.Version != version {
+		return errors.Errorf("green provisioner group %s runs %s:%s instead of %s:%s", green.ID, green.Image, green.Version, image, version)
+	}
+	if green.APISecurityLock {
+		return errors.Errorf("API changes are locked for green provisioner group %s", green.ID)
+	}
+
+	status, err := client.GetGroupStatus(green.ID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get status of green group %s", green.ID)
+	}
+	if status != nil && status.InstallationsAwaitingUpdate+status.InstallationsUpdating > 0 {
+		return errors.Errorf("green provisioner group %s is still updating %d installations", green.ID, status.InstallationsAwaitingUpdate+status.InstallationsUpdating)
+	}
+
+	return nil
+}
+
+// SwitchInstallationGroup moves the installations of the blue provisioner
+// group of an installation group to its green group, and waits for them to
+// run the release of the green group.
+func (provisioner *ElProvisioner) SwitchInstallationGroup(installationGroup *model.InstallationGroup) error {
+	logger := provisioner.logger.WithField("installationgroup", installationGroup.ID)
+	client := provisioner.newAnnotatedProvisionerClient(installationGroup.Annotations)
+	defer provisioner.InvalidateLookupCache(installationGroup.ProvisionerGroupID)
+	defer provisioner.InvalidateLookupCache(installationGroup.GreenProvisionerGroupID)
+
+	installations, err := client.GetInstallations(&cmodel.GetInstallationsRequest{
+		GroupID: installationGroup.ProvisionerGroupID,
+		Paging:  cmodel.AllPagesNotDeleted(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get installations of group %s", installationGroup.ProvisionerGroupID)
+	}
+
+	logger.Infof("Switching %d installations from provisioner group %s to green provisioner group %s", len(installations), installationGroup.ProvisionerGroupID, installationGroup.GreenProvisionerGroupID)
+	for _, installation := range installations {
+		if err = client.JoinGroup(installationGroup.GreenProvisionerGroupID, installation.ID); err != nil {
+			return errors.Wrapf(err, "failed to switch installation %s to green group %s", installation.ID, installationGroup.GreenProvisionerGroupID)
+		}
+	}
+
+	return provisioner.waitForGroupRelease(client, installationGroup.GreenProvisionerGroupID)
+}
+
+// TearDownBlueInstallationGroup deletes the blue provisioner group of an
+// installation group once all its installations were switched over to the
+// green group. A blue group already deleted is not an error.
+func (provisioner *ElProvisioner) TearDownBlueInstallationGroup(installationGroup *model.InstallationGroup) error {
+	logger := provisioner.logger.WithField("installationgroup", installationGroup.ID)
+	client := provisioner.newAnnotatedProvisionerClient(installationGroup.Annotations)
+	defer provisioner.InvalidateLookupCache(installationGroup.ProvisionerGroupID)
+
+	blue, err := client.GetGroup(installationGroup.ProvisionerGroupID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get group %s", installationGroup.ProvisionerGroupID)
+	}
+	if blue == nil || blue.DeleteAt != 0 {
+		logger.Infof("Blue provisioner group %s is already deleted", installationGroup.ProvisionerGroupID)
+		return nil
+	}
+
+	installations, err := client.GetInstallations(&cmodel.GetInstallationsRequest{
+		GroupID: blue.ID,
+		Paging:  cmodel.AllPagesNotDeleted(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get installations of group %s", blue.ID)
+	}
+	if len(installations) > 0 {
+		return errors.Errorf("blue provisioner group %s still has %d installations", blue.ID, len(installations))
+	}
+
+	logger.Infof("Tearing down blue provisioner group %s", blue.ID)
+	if err = client.DeleteGroup(blue.ID); err != nil {
+		return errors.Wrapf(err, "failed to delete blue group %s", blue.ID)
+	}
+
+	return nil
+}
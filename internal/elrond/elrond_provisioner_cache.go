@@ -0,0 +1,145 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"sync"
+	"time"
+
+	cmodel "github.com/mattermost/mattermost-cloud/model"
+)
+
+// lookupCache caches the responses of read-only provisioner lookups, such as
+// provisioner groups, their status and their installations, for a short time,
+// so that release plans, impact reports and releases asking for the same data
+// within seconds make a single call. Entries of a provisioner group are
+// invalidated whenever elrond changes the group, or the provisioner reports
+// on it. Calls acting on the provisioner always bypass the cache.
+type lookupCache struct {
+	ttl time.Duration
+
+	lock    sync.Mutex
+	entries map[string]*lookupCacheEntry
+	// generation is incremented on every invalidation, so that responses
+	// fetched before an invalidation are not cached after it.
+	generation uint64
+}
+
+type lookupCacheEntry struct {
+	value    interface{}
+	expireAt time.Time
+}
+
+const (
+	lookupGroup              = "group"
+	lookupGroupStatus        = "status"
+	lookupGroupInstallations = "installations"
+)
+
+// newLookupCache creates a cache keeping lookups for the given time. A cache
+// without a time caches nothing.
+func newLookupCache(ttl time.Duration) *lookupCache {
+	return &lookupCache{
+		ttl:     ttl,
+		entries: make(map[string]*lookupCacheEntry),
+	}
+}
+
+// lookup returns the value cached for the given lookup of the given
+// provisioner group, or fetches and caches it.
+func (lc *lookupCache) lookup(lookup, groupID string, fetch func() (interface{}, error)) (interface{}, error) {
+	if lc.ttl <= 0 {
+		return fetch()
+	}
+
+	key := lookup + "/" + groupID
+	now := time.Now()
+
+	lc.lock.Lock()
+	entry := lc.entries[key]
+	generation := lc.generation
+	lc.lock.Unlock()
+	if entry != nil && now.Before(entry.expireAt) {
+		return entry.value, nil
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+	if generation == lc.generation {
+		lc.entries[key] = &lookupCacheEntry{value: value, expireAt: now.Add(lc.ttl)}
+	}
+
+	return value, nil
+}
+
+// invalidate discards the cached lookups of the given provisioner group, and
+// expired lookups of other groups.
+func (lc *lookupCache) invalidate(groupID string) {
+	lc.lock.Lock()
+	defer lc.lock.Unlock()
+
+	now := time.Now()
+	for key, entry := range lc.entries {
+		if !now.Before(entry.expireAt) {
+			delete(lc.entries, key)
+		}
+	}
+	for _, lookup := range []string{lookupGroup, lookupGroupStatus, lookupGroupInstallations} {
+		delete(lc.entries, lookup+"/"+groupID)
+	}
+	lc.generation++
+}
+
+// InvalidateLookupCache discards the cached lookups of the given provisioner
+// group, so that the next lookups reflect changes made outside of elrond.
+func (provisioner *ElProvisioner) InvalidateLookupCache(groupID string) {
+	provisioner.lookups.invalidate(groupID)
+}
+
+// lookupGroup returns the given provisioner group, possibly from the cache.
+func (provisioner *ElProvisioner) lookupGroup(client *cmodel.Client, groupID string) (*cmodel.GroupDTO, error) {
+	value, err := provisioner.lookups.lookup(lookupGroup, groupID, func() (interface{}, error) {
+		return client.GetGroup(groupID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*cmodel.GroupDTO), nil
+}
+
+// lookupGroupStatus returns the status of the given provisioner group,
+// possibly from the cache.
+func (provisioner *ElProvisioner) lookupGroupStatus(client *cmodel.Client, groupID string) (*cmodel.GroupStatus, error) {
+	value, err := provisioner.lookups.lookup(lookupGroupStatus, groupID, func() (interface{}, error) {
+		return client.GetGroupStatus(groupID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*cmodel.GroupStatus), nil
+}
+
+// lookupGroupInstallations returns the installations of the given
+// provisioner group that are not deleted, possibly from the cache.
+func (provisioner *ElProvisioner) lookupGroupInstallations(client *cmodel.Client, groupID string) ([]*cmodel.InstallationDTO, error) {
+	value, err := provisioner.lookups.lookup(lookupGroupInstallations, groupID, func() (interface{}, error) {
+		return client.GetInstallations(&cmodel.GetInstallationsRequest{
+			GroupID: groupID,
+			Paging:  cmodel.AllPagesNotDeleted(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.([]*cmodel.InstallationDTO), nil
+}
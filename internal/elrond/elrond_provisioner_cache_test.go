@@ -0,0 +1,113 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupCache(t *testing.T) {
+	calls := 0
+	fetch := func() (interface{}, error) {
+		calls++
+		return calls, nil
+	}
+
+	t.Run("cached", func(t *testing.T) {
+		calls = 0
+		cache := newLookupCache(time.Minute)
+
+		value, err := cache.lookup(lookupGroup, "group1", fetch)
+		require.NoError(t, err)
+		require.Equal(t, 1, value)
+		value, err = cache.lookup(lookupGroup, "group1", fetch)
+		require.NoError(t, err)
+		require.Equal(t, 1, value)
+
+		// Lookups and groups are cached separately.
+		value, err = cache.lookup(lookupGroupStatus, "group1", fetch)
+		require.NoError(t, err)
+		require.Equal(t, 2, value)
+		value, err = cache.lookup(lookupGroup, "group2", fetch)
+		require.NoError(t, err)
+		require.Equal(t, 3, value)
+	})
+
+	t.Run("invalidated", func(t *testing.T) {
+		calls = 0
+		cache := newLookupCache(time.Minute)
+
+		_, err := cache.lookup(lookupGroup, "group1", fetch)
+		require.NoError(t, err)
+		_, err = cache.lookup(lookupGroupInstallations, "group2", fetch)
+		require.NoError(t, err)
+
+		cache.invalidate("group1")
+
+		value, err := cache.lookup(lookupGroup, "group1", fetch)
+		require.NoError(t, err)
+		require.Equal(t, 3, value)
+		value, err = cache.lookup(lookupGroupInstallations, "group2", fetch)
+		require.NoError(t, err)
+		require.Equal(t, 2, value)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		calls = 0
+		cache := newLookupCache(time.Millisecond)
+
+		_, err := cache.lookup(lookupGroup, "group1", fetch)
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+
+		value, err := cache.lookup(lookupGroup, "group1", fetch)
+		require.NoError(t, err)
+		require.Equal(t, 2, value)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		calls = 0
+		cache := newLookupCache(0)
+
+		_, err := cache.lookup(lookupGroup, "group1", fetch)
+		require.NoError(t, err)
+		value, err := cache.lookup(lookupGroup, "group1", fetch)
+		require.NoError(t, err)
+		require.Equal(t, 2, value)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		calls = 0
+		cache := newLookupCache(time.Minute)
+
+		_, err := cache.lookup(lookupGroup, "group1", func() (interface{}, error) {
+			return nil, errors.New("unavailable")
+		})
+		require.Error(t, err)
+
+		value, err := cache.lookup(lookupGroup, "group1", fetch)
+		require.NoError(t, err)
+		require.Equal(t, 1, value)
+	})
+
+	t.Run("fetched before an invalidation", func(t *testing.T) {
+		calls = 0
+		cache := newLookupCache(time.Minute)
+
+		_, err := cache.lookup(lookupGroup, "group1", func() (interface{}, error) {
+			cache.invalidate("group1")
+			return 0, nil
+		})
+		require.NoError(t, err)
+
+		value, err := cache.lookup(lookupGroup, "group1", fetch)
+		require.NoError(t, err)
+		require.Equal(t, 1, value)
+	})
+}
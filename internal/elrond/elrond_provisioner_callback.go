@@ -0,0 +1,51 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"github.com/mattermost/elrond/model"
+)
+
+// HandleGroupCallback delivers a status callback posted by the provisioner to
+// the release waiting on the given group, if any, so it can react without
+// waiting for its next poll.
+func (provisioner *ElProvisioner) HandleGroupCallback(callback *model.ProvisionerCallback) {
+	logger := provisioner.logger.WithField("provisionergroup", callback.ProvisionerGroupID)
+
+	// The provisioner reports on a group that changed.
+	provisioner.InvalidateLookupCache(callback.ProvisionerGroupID)
+
+	provisioner.callbacksLock.Lock()
+	waiter, ok := provisioner.callbacks[callback.ProvisionerGroupID]
+	provisioner.callbacksLock.Unlock()
+	if !ok {
+		logger.Debugf("No release waiting on provisioner group; ignoring %s callback", callback.Status)
+		return
+	}
+
+	select {
+	case waiter <- callback:
+	default:
+		logger.Warnf("Release waiting on provisioner group is busy; dropping %s callback", callback.Status)
+	}
+}
+
+// waitForGroupCallbacks registers a channel receiving the callbacks of the
+// given group, returning it along with a function unregistering it.
+func (provisioner *ElProvisioner) waitForGroupCallbacks(groupID string) (<-chan *model.ProvisionerCallback, func()) {
+	waiter := make(chan *model.ProvisionerCallback, 10)
+
+	provisioner.callbacksLock.Lock()
+	defer provisioner.callbacksLock.Unlock()
+	provisioner.callbacks[groupID] = waiter
+
+	return waiter, func() {
+		provisioner.callbacksLock.Lock()
+		defer provisioner.callbacksLock.Unlock()
+		if provisioner.callbacks[groupID] == waiter {
+			delete(provisioner.callbacks, groupID)
+		}
+	}
+}
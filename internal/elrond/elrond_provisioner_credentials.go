@@ -0,0 +1,114 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"encoding/json"
+
+	"github.com/mattermost/elrond/model"
+	cmodel "github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+// provisionerCredentials is an immutable snapshot of the provisioner
+// credentials, swapped atomically on rotation.
+type provisionerCredentials struct {
+	id      string
+	headers map[string]string
+}
+
+// CredentialsStore describes the store used to load provisioner credentials.
+type CredentialsStore interface {
+	GetLatestProvisionerCredentials() (*model.ProvisionerCredentials, error)
+}
+
+// Decrypter describes the interface to decrypt stored provisioner credentials.
+type Decrypter interface {
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// SetProvisionerCredentials replaces the headers used to authenticate against
+// the provisioner. Calls already in flight keep using the previous credentials.
+func (provisioner *ElProvisioner) SetProvisionerCredentials(credentialsID string, headers map[string]string) {
+	copied := make(map[string]string, len(headers))
+	for name, value := range headers {
+		copied[name] = value
+	}
+
+	provisioner.credentials.Store(&provisionerCredentials{id: credentialsID, headers: copied})
+	provisioner.logger.WithField("credentials", credentialsID).Info("Provisioner credentials updated")
+}
+
+// RefreshCredentials loads the latest provisioner credentials from the store,
+// applying them if they differ from the ones in use. This allows credentials
+// rotated through another server to be picked up without a restart.
+func (provisioner *ElProvisioner) RefreshCredentials(store CredentialsStore, decrypter Decrypter) error {
+	credentials, err := store.GetLatestProvisionerCredentials()
+	if err != nil {
+		return errors.Wrap(err, "failed to get latest provisioner credentials")
+	}
+	if credentials == nil {
+		return nil
+	}
+	if current := provisioner.currentCredentials(); current != nil && current.id == credentials.ID {
+		return nil
+	}
+
+	plaintext, err := decrypter.Decrypt(credentials.EncryptedHeaders)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decrypt provisioner credentials %s", credentials.ID)
+	}
+	var headers map[string]string
+	if err = json.Unmarshal(plaintext, &headers); err != nil {
+		return errors.Wrapf(err, "failed to decode provisioner credentials %s", credentials.ID)
+	}
+
+	provisioner.SetProvisionerCredentials(credentials.ID, headers)
+
+	return nil
+}
+
+// CheckConnection verifies that the provisioner is reachable and accepts the
+// current credentials by listing a single provisioner group.
+func (provisioner *ElProvisioner) CheckConnection() error {
+	_, err := provisioner.newProvisionerClient().GetGroups(&cmodel.GetGroupsRequest{
+		Paging: cmodel.Paging{Page: 0, PerPage: 1},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to list groups of provisioner %s", provisioner.ProvisionerServer)
+	}
+
+	return nil
+}
+
+func (provisioner *ElProvisioner) currentCredentials() *provisionerCredentials {
+	credentials, _ := provisioner.credentials.Load().(*provisionerCredentials)
+	return credentials
+}
+
+// newProvisionerClient returns a provisioner client authenticated with the
+// current credentials, if any.
+func (provisioner *ElProvisioner) newProvisionerClient() *cmodel.Client {
+	return provisioner.newAnnotatedProvisionerClient(nil)
+}
+
+// newAnnotatedProvisionerClient returns a provisioner client authenticated
+// with the current credentials, if any, that forwards the given annotations
+// as headers so the provisioner can record the context of each call.
+func (provisioner *ElProvisioner) newAnnotatedProvisionerClient(annotations model.Annotations) *cmodel.Client {
+	credentials := provisioner.currentCredentials()
+	if credentials == nil && len(annotations) == 0 {
+		return cmodel.NewClient(provisioner.ProvisionerServer)
+	}
+
+	headers := annotations.Headers()
+	if credentials != nil {
+		for name, value := range credentials.headers {
+			headers[name] = value
+		}
+	}
+
+	return cmodel.NewClientWithHeaders(provisioner.ProvisionerServer, headers)
+}
@@ -5,6 +5,7 @@
 package elrond
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/mattermost/elrond/model"
@@ -12,12 +13,15 @@ import (
 	"github.com/pkg/errors"
 )
 
-// ReleaseInstallationGroup releases an installation group ring.
-func (provisioner *ElProvisioner) ReleaseInstallationGroup(installationGroup *model.InstallationGroup, image, version string) error {
+// ReleaseInstallationGroup releases an installation group ring, setting the
+// release parameters of the installation group, if any, on its provisioner
+// group.
+func (provisioner *ElProvisioner) ReleaseInstallationGroup(installationGroup *model.InstallationGroup, image, version string, parameters *model.InstallationGroupReleaseParameters) error {
 	logger := provisioner.logger.WithField("installationgroup", installationGroup.ID)
 	logger.Infof("Releasing installation group %s", installationGroup.ID)
 
-	client := cmodel.NewClient(provisioner.ProvisionerServer)
+	client := provisioner.newAnnotatedProvisionerClient(installationGroup.Annotations)
+	defer provisioner.InvalidateLookupCache(installationGroup.ProvisionerGroupID)
 
 	logger.Info("Getting provisioner installation groups")
 
@@ -26,12 +30,17 @@ func (provisioner *ElProvisioner) ReleaseInstallationGroup(installationGroup *mo
 		return errors.Wrapf(err, "failed to get group %s, make sure it exists", installationGroup.ProvisionerGroupID)
 	}
 
-	if group.Image != image || group.Version != version {
+	env := releaseEnv(parameters)
+	if group.Image != image || group.Version != version || envChanged(group.MattermostEnv, env) {
 		logger.Infof("Current provisioner group image is %s:%s and should be updated to %s:%s", group.Image, group.Version, image, version)
+		if len(env) > 0 {
+			logger.Infof("Setting %d environment variables on provisioner group %s", len(env), installationGroup.ProvisionerGroupID)
+		}
 		request := &cmodel.PatchGroupRequest{
-			ID:      installationGroup.ProvisionerGroupID,
-			Version: &version,
-			Image:   &image,
+			ID:            installationGroup.ProvisionerGroupID,
+			Version:       &version,
+			Image:         &image,
+			MattermostEnv: env,
 		}
 
 		logger.Infof("Updating provisioner group %s", installationGroup.ProvisionerGroupID)
@@ -42,7 +51,7 @@ func (provisioner *ElProvisioner) ReleaseInstallationGroup(installationGroup *mo
 		}
 
 		logger.Infof("Update provisioner group %s successful. Waiting up to %d seconds for the group release to complete...", installationGroup.ProvisionerGroupID, provisioner.params.ProvisionerGroupReleaseTimeout)
-		err = waitForGroupRelease(client, provisioner.params.ProvisionerGroupReleaseTimeout, installationGroup.ProvisionerGroupID)
+		err = provisioner.waitForGroupRelease(client, installationGroup.ProvisionerGroupID)
 		if err != nil {
 			return err
 		}
@@ -53,24 +62,85 @@ func (provisioner *ElProvisioner) ReleaseInstallationGroup(installationGroup *mo
 	return nil
 }
 
-func waitForGroupRelease(client *cmodel.Client, timeout int, groupID string) error {
-	timer := time.NewTimer(time.Duration(timeout) * time.Second)
+// RollbackInstallationGroup rolls the provisioner group of an installation
+// group back to the given image and version. The environment variables set by
+// the release parameters of the failed release are left as they are.
+func (provisioner *ElProvisioner) RollbackInstallationGroup(installationGroup *model.InstallationGroup, image, version string) error {
+	provisioner.logger.WithField("installationgroup", installationGroup.ID).Infof("Rolling back installation group %s to %s:%s", installationGroup.ID, image, version)
+
+	if err := provisioner.ReleaseInstallationGroup(installationGroup, image, version, nil); err != nil {
+		return errors.Wrap(err, "failed to roll back provisioner group")
+	}
+
+	return nil
+}
+
+// releaseEnv returns the environment variables to set on the provisioner
+// group for the given release parameters.
+func releaseEnv(parameters *model.InstallationGroupReleaseParameters) cmodel.EnvVarMap {
+	if parameters == nil || len(parameters.MattermostEnv) == 0 {
+		return nil
+	}
+
+	env := make(cmodel.EnvVarMap, len(parameters.MattermostEnv))
+	for name, value := range parameters.MattermostEnv {
+		env[name] = cmodel.EnvVar{Value: value}
+	}
+
+	return env
+}
+
+// envChanged returns whether setting the given environment variables would
+// change those of the provisioner group.
+func envChanged(current, env cmodel.EnvVarMap) bool {
+	for name, value := range env {
+		currentValue, ok := current[name]
+		if !ok || currentValue.Value != value.Value || currentValue.ValueFrom != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// waitForGroupRelease waits for the provisioner group to finish updating its
+// installations. The group status is polled every minute, or as soon as the
+// provisioner calls back reporting the update completed.
+func (provisioner *ElProvisioner) waitForGroupRelease(client *cmodel.Client, groupID string) error {
+	callbacks, stop := provisioner.waitForGroupCallbacks(groupID)
+	defer stop()
+
+	timer := time.NewTimer(time.Duration(provisioner.params.ProvisionerGroupReleaseTimeout) * time.Second)
 	defer timer.Stop()
+	poll := time.NewTicker(60 * time.Second)
+	defer poll.Stop()
 
 	for {
-		select {
-		case <-timer.C:
-			return errors.New("timed out waiting for group release to complete")
-		default:
-			status, err := client.GetGroupStatus(groupID)
-			if err != nil {
-				return errors.Wrap(err, "failed to get provisioner group status")
-			}
-			if status.InstallationsAwaitingUpdate == 0 && status.InstallationsUpdating == 0 {
-				return nil
+		status, err := client.GetGroupStatus(groupID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get provisioner group status")
+		}
+		if status.InstallationsAwaitingUpdate == 0 && status.InstallationsUpdating == 0 {
+			return nil
+		}
+		logger.Infof("Provisioner group %s release in progress...", groupID)
+
+		for recheck := false; !recheck; {
+			select {
+			case <-timer.C:
+				return errors.New("timed out waiting for group release to complete")
+			case <-poll.C:
+				recheck = true
+			case callback := <-callbacks:
+				switch callback.Status {
+				case model.ProvisionerCallbackFailed:
+					return errors.Errorf("provisioner reported the group release failed: %s", callback.Message)
+				case model.ProvisionerCallbackCompleted:
+					recheck = true
+				default:
+					logger.Infof("Provisioner group %s release %s (%d%%)", groupID, callback.Status, callback.Progress)
+				}
 			}
-			logger.Infof("Provisioner group %s release in progress...", groupID)
-			time.Sleep(60 * time.Second)
 		}
 	}
 }
@@ -85,3 +155,112 @@ func (provisioner *ElProvisioner) SoakInstallationGroup(installationGroup *model
 	// }
 	return nil
 }
+
+// RegisterInstallationGroup ensures the provisioner group backing an
+// installation group exists. It makes no changes and is safe to retry.
+func (provisioner *ElProvisioner) RegisterInstallationGroup(installationGroup *model.InstallationGroup) error {
+	logger := provisioner.logger.WithField("installationgroup", installationGroup.ID)
+
+	if installationGroup.ProvisionerGroupID == "" {
+		logger.Warn("Installation group has no provisioner group; skipping registration")
+		return nil
+	}
+
+	logger.Infof("Registering installation group %s with provisioner group %s", installationGroup.ID, installationGroup.ProvisionerGroupID)
+
+	client := provisioner.newAnnotatedProvisionerClient(installationGroup.Annotations)
+	group, err := client.GetGroup(installationGroup.ProvisionerGroupID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get group %s", installationGroup.ProvisionerGroupID)
+	}
+	if group == nil {
+		return errors.Errorf("provisioner group %s not found", installationGroup.ProvisionerGroupID)
+	}
+
+	return nil
+}
+
+// ValidateRelease asks the provisioner whether it would accept releasing the
+// given image and version to the provisioner group backing an installation
+// group. It makes no changes.
+func (provisioner *ElProvisioner) ValidateRelease(installationGroup *model.InstallationGroup, image, version string) (*model.ReleaseValidation, error) {
+	validation := &model.ReleaseValidation{
+		InstallationGroup:  installationGroup.Name,
+		ProvisionerGroupID: installationGroup.ProvisionerGroupID,
+		Image:              image,
+		Version:            version,
+	}
+	if image == "" || version == "" {
+		validation.Reasons = append(validation.Reasons, "release image and version must be set")
+	}
+
+	client := provisioner.newAnnotatedProvisionerClient(installationGroup.Annotations)
+	group, err := provisioner.lookupGroup(client, installationGroup.ProvisionerGroupID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get group %s", installationGroup.ProvisionerGroupID)
+	}
+	if group == nil {
+		validation.Reasons = append(validation.Reasons, fmt.Sprintf("provisioner group %s not found", installationGroup.ProvisionerGroupID))
+		return validation, nil
+	}
+	if group.DeleteAt != 0 {
+		validation.Reasons = append(validation.Reasons, fmt.Sprintf("provisioner group %s is deleted", group.ID))
+	}
+	if group.APISecurityLock {
+		validation.Reasons = append(validation.Reasons, fmt.Sprintf("API changes are locked for provisioner group %s", group.ID))
+	}
+	if group.MaxRolling <= 0 {
+		validation.Reasons = append(validation.Reasons, fmt.Sprintf("provisioner group %s does not allow rolling updates", group.ID))
+	}
+
+	status, err := provisioner.lookupGroupStatus(client, installationGroup.ProvisionerGroupID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get status of group %s", installationGroup.ProvisionerGroupID)
+	}
+	if status != nil && status.InstallationsAwaitingUpdate+status.InstallationsUpdating > 0 {
+		validation.Reasons = append(validation.Reasons, fmt.Sprintf("provisioner group %s is still updating %d installations", group.ID, status.InstallationsAwaitingUpdate+status.InstallationsUpdating))
+	}
+
+	validation.Accepted = len(validation.Reasons) == 0
+
+	return validation, nil
+}
+
+// GetInstallationGroupHealth returns the health of the provisioner group
+// backing an installation group, as reported by the provisioner.
+func (provisioner *ElProvisioner) GetInstallationGroupHealth(installationGroup *model.InstallationGroup) (*model.HealthSnapshot, error) {
+	client := provisioner.newAnnotatedProvisionerClient(installationGroup.Annotations)
+
+	status, err := provisioner.lookupGroupStatus(client, installationGroup.ProvisionerGroupID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get status of group %s", installationGroup.ProvisionerGroupID)
+	}
+	if status == nil {
+		return nil, errors.Errorf("provisioner group %s not found", installationGroup.ProvisionerGroupID)
+	}
+
+	return &model.HealthSnapshot{
+		InstallationGroupID:         installationGroup.ID,
+		InstallationsTotal:          status.InstallationsTotal,
+		InstallationsUpdated:        status.InstallationsUpdated,
+		InstallationsUpdating:       status.InstallationsUpdating,
+		InstallationsHibernating:    status.InstallationsHibernating,
+		InstallationsAwaitingUpdate: status.InstallationsAwaitingUpdate,
+	}, nil
+}
+
+// GetInstallationGroupRelease returns the image and version the provisioner
+// group backing an installation group is running.
+func (provisioner *ElProvisioner) GetInstallationGroupRelease(installationGroup *model.InstallationGroup) (string, string, error) {
+	client := provisioner.newProvisionerClient()
+
+	group, err := provisioner.lookupGroup(client, installationGroup.ProvisionerGroupID)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to get group %s", installationGroup.ProvisionerGroupID)
+	}
+	if group == nil {
+		return "", "", errors.Errorf("provisioner group %s not found", installationGroup.ProvisionerGroupID)
+	}
+
+	return group.Image, group.Version, nil
+}
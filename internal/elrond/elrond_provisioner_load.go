@@ -0,0 +1,73 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// installationLoadTimeout bounds each request to the installation load
+// source.
+const installationLoadTimeout = 10 * time.Second
+
+// GetInstallationGroupLoad returns the load of the installations of the
+// provisioner group backing an installation group, or nil if no installation
+// load source is configured. The installations are listed by the provisioner,
+// and the load of each is fetched from the installation load URL with
+// {installation} replaced by its ID.
+func (provisioner *ElProvisioner) GetInstallationGroupLoad(installationGroup *model.InstallationGroup) (*model.InstallationGroupLoad, error) {
+	if provisioner.params.InstallationLoadURL == "" {
+		return nil, nil
+	}
+
+	client := provisioner.newAnnotatedProvisionerClient(installationGroup.Annotations)
+	installations, err := provisioner.lookupGroupInstallations(client, installationGroup.ProvisionerGroupID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get installations of group %s", installationGroup.ProvisionerGroupID)
+	}
+
+	httpClient := &http.Client{Timeout: installationLoadTimeout}
+	load := &model.InstallationGroupLoad{Installations: []*model.InstallationLoad{}}
+	for _, installation := range installations {
+		loadURL := strings.ReplaceAll(provisioner.params.InstallationLoadURL, "{installation}", url.PathEscape(installation.ID))
+		installationLoad, err := getInstallationLoad(httpClient, loadURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get load of installation %s", installation.ID)
+		}
+		installationLoad.InstallationID = installation.ID
+		load.Installations = append(load.Installations, installationLoad)
+	}
+
+	return load, nil
+}
+
+// getInstallationLoad fetches the load of an installation from the given URL,
+// which responds with the active users and connections of the installation as
+// JSON.
+func getInstallationLoad(client *http.Client, loadURL string) (*model.InstallationLoad, error) {
+	resp, err := client.Get(loadURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("installation load source responded %d", resp.StatusCode)
+	}
+
+	var load model.InstallationLoad
+	if err = json.NewDecoder(resp.Body).Decode(&load); err != nil {
+		return nil, errors.Wrap(err, "failed to decode installation load")
+	}
+
+	return &load, nil
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetInstallationLoad(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/installations/installation1/load":
+			w.Write([]byte(`{"activeUsers": 120, "connections": 300}`)) //nolint
+		case "/installations/invalid/load":
+			w.Write([]byte(`not json`)) //nolint
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	load, err := getInstallationLoad(ts.Client(), ts.URL+"/installations/installation1/load")
+	require.NoError(t, err)
+	require.Equal(t, int64(120), load.ActiveUsers)
+	require.Equal(t, int64(300), load.Connections)
+
+	_, err = getInstallationLoad(ts.Client(), ts.URL+"/installations/invalid/load")
+	require.Error(t, err)
+
+	_, err = getInstallationLoad(ts.Client(), ts.URL+"/installations/unknown/load")
+	require.Error(t, err)
+}
+
+func TestGetInstallationGroupLoadWithoutSource(t *testing.T) {
+	provisioner := &ElProvisioner{}
+	load, err := provisioner.GetInstallationGroupLoad(nil)
+	require.NoError(t, err)
+	require.Nil(t, load)
+}
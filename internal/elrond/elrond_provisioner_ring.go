@@ -5,7 +5,10 @@
 package elrond
 
 import (
+	"strings"
+
 	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
 )
 
 // PrepareRing ensures a ring object is ready for provisioning.
@@ -47,6 +50,30 @@ func (provisioner *ElProvisioner) ReleaseRing(ring *model.Ring) error {
 	return nil
 }
 
+// PrepareRelease gets the provisioner groups of the given installation groups
+// ready for the given release of a ring, without releasing it. Every group
+// must accept the release.
+func (provisioner *ElProvisioner) PrepareRelease(ring *model.Ring, release *model.RingRelease, installationGroups []*model.InstallationGroup) error {
+	logger := provisioner.logger.WithField("ring", ring.ID)
+	logger.Infof("Preparing release %s:%s of ring %s", release.Image, release.Version, ring.ID)
+
+	for _, installationGroup := range installationGroups {
+		validation, err := provisioner.ValidateRelease(installationGroup, release.Image, release.Version)
+		if err != nil {
+			return errors.Wrapf(err, "failed to validate release for installation group %s", installationGroup.Name)
+		}
+		if !validation.Accepted {
+			return errors.Errorf("provisioner group %s rejected the release: %s", installationGroup.ProvisionerGroupID, strings.Join(validation.Reasons, "; "))
+		}
+	}
+	// err := prePullRelease(provisioner, release, installationGroups, logger)
+	// if err != nil {
+	// 	return err
+	// }
+
+	return nil
+}
+
 // RollBackRing rolls back a ring.
 func (provisioner *ElProvisioner) RollBackRing(ring *model.Ring) error {
 	logger := provisioner.logger.WithField("ring", ring.ID)
@@ -68,3 +95,26 @@ func (provisioner *ElProvisioner) SoakRing(ring *model.Ring) error {
 	// }
 	return nil
 }
+
+// GetReleaseImpact queries the provisioner for the installations and
+// customers behind the given installation groups.
+func (provisioner *ElProvisioner) GetReleaseImpact(installationGroups []*model.InstallationGroup) (*model.ReleaseImpact, error) {
+	client := provisioner.newProvisionerClient()
+
+	impact := &model.ReleaseImpact{}
+	owners := make(map[string]struct{})
+	for _, installationGroup := range installationGroups {
+		installations, err := provisioner.lookupGroupInstallations(client, installationGroup.ProvisionerGroupID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get installations of provisioner group %s", installationGroup.ProvisionerGroupID)
+		}
+
+		impact.Installations += int64(len(installations))
+		for _, installation := range installations {
+			owners[installation.OwnerID] = struct{}{}
+		}
+	}
+	impact.Customers = int64(len(owners))
+
+	return impact, nil
+}
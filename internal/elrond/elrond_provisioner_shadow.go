@@ -0,0 +1,206 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ShadowModeDryRun asks the shadow provisioner whether it would accept
+	// each installation group release made by the primary provisioner.
+	ShadowModeDryRun = "dry-run"
+	// ShadowModeNoOp compares the release the shadow provisioner reports for
+	// each installation group with the release the primary provisioner made.
+	ShadowModeNoOp = "no-op"
+)
+
+// shadowBackend abstracts the read-only operations mirrored to the shadow
+// provisioner.
+type shadowBackend interface {
+	ValidateRelease(installationGroup *model.InstallationGroup, image, version string) (*model.ReleaseValidation, error)
+	GetInstallationGroupRelease(installationGroup *model.InstallationGroup) (string, string, error)
+}
+
+// shadowResult is what the shadow provisioner answered for a mirrored
+// operation.
+type shadowResult struct {
+	validation *model.ReleaseValidation
+	image      string
+	version    string
+	err        error
+}
+
+// ShadowProvisioner provisions release rings with the primary provisioner,
+// mirroring the installation group releases and rollbacks to a shadow
+// provisioner, such as a new backend being migrated to, and logging whether
+// the results of both agree. The shadow provisioner is only queried, never
+// changed, and never affects the primary operations: it is called in the
+// background and its failures are only logged.
+type ShadowProvisioner struct {
+	*ElProvisioner
+	shadow shadowBackend
+	mode   string
+	logger log.FieldLogger
+
+	// mirrors tracks the mirrored operations in progress.
+	mirrors sync.WaitGroup
+}
+
+// NewShadowProvisioner creates a provisioner mirroring the installation group
+// releases of the primary provisioner to the shadow provisioner in the given
+// mode.
+func NewShadowProvisioner(primary, shadow *ElProvisioner, mode string, logger log.FieldLogger) (*ShadowProvisioner, error) {
+	if mode != ShadowModeDryRun && mode != ShadowModeNoOp {
+		return nil, errors.Errorf("invalid shadow provisioner mode %q: must be %s or %s", mode, ShadowModeDryRun, ShadowModeNoOp)
+	}
+
+	return &ShadowProvisioner{
+		ElProvisioner: primary,
+		shadow:        shadow,
+		mode:          mode,
+		logger:        logger.WithFields(log.Fields{"provisioner": "shadow", "shadow-mode": mode}),
+	}, nil
+}
+
+// ReleaseInstallationGroup releases the installation group with the primary
+// provisioner, mirroring the release to the shadow provisioner.
+func (provisioner *ShadowProvisioner) ReleaseInstallationGroup(installationGroup *model.InstallationGroup, image, version string, parameters *model.InstallationGroupReleaseParameters) error {
+	shadowResults := provisioner.mirrorBefore(installationGroup, image, version)
+	err := provisioner.ElProvisioner.ReleaseInstallationGroup(installationGroup, image, version, parameters)
+	provisioner.mirrorAfter("release", installationGroup, image, version, err, shadowResults)
+
+	return err
+}
+
+// RollbackInstallationGroup rolls the installation group back with the
+// primary provisioner, mirroring the rollback to the shadow provisioner.
+func (provisioner *ShadowProvisioner) RollbackInstallationGroup(installationGroup *model.InstallationGroup, image, version string) error {
+	shadowResults := provisioner.mirrorBefore(installationGroup, image, version)
+	err := provisioner.ElProvisioner.RollbackInstallationGroup(installationGroup, image, version)
+	provisioner.mirrorAfter("rollback", installationGroup, image, version, err, shadowResults)
+
+	return err
+}
+
+// Wait waits for the mirrored operations in progress to be compared.
+func (provisioner *ShadowProvisioner) Wait() {
+	provisioner.mirrors.Wait()
+}
+
+// mirrorBefore starts the part of the mirrored operation made before the
+// primary operation, in the background: in dry-run mode, the shadow
+// provisioner validates the release against the groups as they were before
+// the primary release. The result is sent on the returned channel.
+func (provisioner *ShadowProvisioner) mirrorBefore(installationGroup *model.InstallationGroup, image, version string) <-chan *shadowResult {
+	results := make(chan *shadowResult, 1)
+	if provisioner.mode != ShadowModeDryRun {
+		return results
+	}
+
+	provisioner.mirrors.Add(1)
+	go func() {
+		defer provisioner.mirrors.Done()
+		// The result is always sent, so the comparison never waits forever.
+		result := &shadowResult{err: errors.New("shadow provisioner panicked")}
+		defer func() { results <- result }()
+		defer recoverShadowPanic(provisioner.logger, installationGroup)
+
+		result.validation, result.err = provisioner.shadow.ValidateRelease(installationGroup, image, version)
+	}()
+
+	return results
+}
+
+// mirrorAfter completes the mirrored operation in the background, once the
+// primary operation returned the given error, and logs the comparison of the
+// results of both provisioners.
+func (provisioner *ShadowProvisioner) mirrorAfter(operation string, installationGroup *model.InstallationGroup, image, version string, primaryErr error, shadowResults <-chan *shadowResult) {
+	provisioner.mirrors.Add(1)
+	go func() {
+		defer provisioner.mirrors.Done()
+		defer recoverShadowPanic(provisioner.logger, installationGroup)
+
+		var result *shadowResult
+		if provisioner.mode == ShadowModeDryRun {
+			result = <-shadowResults
+		} else {
+			shadowImage, shadowVersion, err := provisioner.shadow.GetInstallationGroupRelease(installationGroup)
+			result = &shadowResult{image: shadowImage, version: shadowVersion, err: err}
+		}
+
+		provisioner.logComparison(operation, installationGroup, image, version, primaryErr, result)
+	}()
+}
+
+// logComparison logs whether the result of the shadow provisioner agrees
+// with the result of the primary provisioner for the operation.
+func (provisioner *ShadowProvisioner) logComparison(operation string, installationGroup *model.InstallationGroup, image, version string, primaryErr error, result *shadowResult) {
+	logger := provisioner.logger.WithFields(log.Fields{
+		"installationgroup": installationGroup.ID,
+		"provisioner-group": installationGroup.ProvisionerGroupID,
+		"operation":         operation,
+		"release":           fmt.Sprintf("%s:%s", image, version),
+		"primary-succeeded": primaryErr == nil,
+	})
+	if primaryErr != nil {
+		logger = logger.WithField("primary-error", primaryErr.Error())
+	}
+
+	if result.err != nil {
+		logger.WithError(result.err).Warn("Shadow provisioner failed to mirror the operation")
+		return
+	}
+
+	agrees, detail := compareShadowResult(provisioner.mode, image, version, primaryErr, result)
+	logger = logger.WithField("shadow-result", detail)
+	if !agrees {
+		logger.Warn("Shadow provisioner result differs from the primary provisioner")
+		return
+	}
+
+	logger.Info("Shadow provisioner result matches the primary provisioner")
+}
+
+// compareShadowResult returns whether the result of the shadow provisioner
+// in the given mode agrees with the primary provisioner releasing the image
+// and version with the given error, and a description of the shadow result.
+//
+// In dry-run mode, the shadow provisioner agrees when it would accept the
+// releases the primary provisioner made, and reject those it failed. In
+// no-op mode, it agrees when it reports the release the primary provisioner
+// made; when the primary provisioner failed, there is nothing to compare.
+func compareShadowResult(mode, image, version string, primaryErr error, result *shadowResult) (bool, string) {
+	if mode == ShadowModeDryRun {
+		if result.validation == nil {
+			return false, "no validation"
+		}
+		if result.validation.Accepted {
+			return primaryErr == nil, "accepted"
+		}
+		return primaryErr != nil, fmt.Sprintf("rejected: %v", result.validation.Reasons)
+	}
+
+	detail := fmt.Sprintf("reports %s:%s", result.image, result.version)
+	if primaryErr != nil {
+		return true, detail
+	}
+
+	return result.image == image && result.version == version, detail
+}
+
+// recoverShadowPanic logs the panics of the shadow provisioner instead of
+// crashing the server, as the shadow provisioner must never affect the
+// primary operations.
+func recoverShadowPanic(logger log.FieldLogger, installationGroup *model.InstallationGroup) {
+	if r := recover(); r != nil {
+		logger.WithField("installationgroup", installationGroup.ID).Errorf("Shadow provisioner panicked: %v", r)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+type mockShadowBackend struct {
+	validation *model.ReleaseValidation
+	image      string
+	version    string
+	err        error
+	panics     bool
+}
+
+func (b *mockShadowBackend) ValidateRelease(installationGroup *model.InstallationGroup, image, version string) (*model.ReleaseValidation, error) {
+	if b.panics {
+		panic("shadow failure")
+	}
+	return b.validation, b.err
+}
+
+func (b *mockShadowBackend) GetInstallationGroupRelease(installationGroup *model.InstallationGroup) (string, string, error) {
+	if b.panics {
+		panic("shadow failure")
+	}
+	return b.image, b.version, b.err
+}
+
+func TestNewShadowProvisioner(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	primary := NewElrondProvisioner(ProvisioningParams{}, logger, "http://primary")
+	shadow := NewElrondProvisioner(ProvisioningParams{}, logger, "http://shadow")
+
+	_, err := NewShadowProvisioner(primary, shadow, "mirror", logger)
+	require.Error(t, err)
+
+	provisioner, err := NewShadowProvisioner(primary, shadow, ShadowModeNoOp, logger)
+	require.NoError(t, err)
+	require.Equal(t, "http://primary", provisioner.ProvisionerServer)
+}
+
+func TestCompareShadowResult(t *testing.T) {
+	primaryErr := errors.New("release failed")
+
+	testCases := []struct {
+		name       string
+		mode       string
+		primaryErr error
+		result     *shadowResult
+		agrees     bool
+	}{
+		{"dry-run accepted release", ShadowModeDryRun, nil, &shadowResult{validation: &model.ReleaseValidation{Accepted: true}}, true},
+		{"dry-run rejected release", ShadowModeDryRun, nil, &shadowResult{validation: &model.ReleaseValidation{Reasons: []string{"locked"}}}, false},
+		{"dry-run accepted failed release", ShadowModeDryRun, primaryErr, &shadowResult{validation: &model.ReleaseValidation{Accepted: true}}, false},
+		{"dry-run rejected failed release", ShadowModeDryRun, primaryErr, &shadowResult{validation: &model.ReleaseValidation{Reasons: []string{"locked"}}}, true},
+		{"dry-run without validation", ShadowModeDryRun, nil, &shadowResult{}, false},
+		{"no-op same release", ShadowModeNoOp, nil, &shadowResult{image: "mattermost/mattermost", version: "7.1.0"}, true},
+		{"no-op other version", ShadowModeNoOp, nil, &shadowResult{image: "mattermost/mattermost", version: "7.0.0"}, false},
+		{"no-op failed release", ShadowModeNoOp, primaryErr, &shadowResult{image: "mattermost/mattermost", version: "7.0.0"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			agrees, _ := compareShadowResult(tc.mode, "mattermost/mattermost", "7.1.0", tc.primaryErr, tc.result)
+			require.Equal(t, tc.agrees, agrees)
+		})
+	}
+}
+
+func TestShadowProvisionerMirror(t *testing.T) {
+	installationGroup := &model.InstallationGroup{ID: model.NewID(), ProvisionerGroupID: "group1"}
+
+	mirror := func(t *testing.T, mode string, shadow *mockShadowBackend, primaryErr error) *log.Entry {
+		logger, hook := test.NewNullLogger()
+		provisioner := &ShadowProvisioner{shadow: shadow, mode: mode, logger: logger}
+
+		shadowResults := provisioner.mirrorBefore(installationGroup, "mattermost/mattermost", "7.1.0")
+		provisioner.mirrorAfter("release", installationGroup, "mattermost/mattermost", "7.1.0", primaryErr, shadowResults)
+		provisioner.Wait()
+
+		return hook.LastEntry()
+	}
+
+	t.Run("dry-run match", func(t *testing.T) {
+		entry := mirror(t, ShadowModeDryRun, &mockShadowBackend{validation: &model.ReleaseValidation{Accepted: true}}, nil)
+		require.Equal(t, log.InfoLevel, entry.Level)
+		require.Equal(t, "Shadow provisioner result matches the primary provisioner", entry.Message)
+		require.Equal(t, "release", entry.Data["operation"])
+		require.Equal(t, "accepted", entry.Data["shadow-result"])
+	})
+
+	t.Run("dry-run mismatch", func(t *testing.T) {
+		entry := mirror(t, ShadowModeDryRun, &mockShadowBackend{validation: &model.ReleaseValidation{Reasons: []string{"provisioner group group1 is deleted"}}}, nil)
+		require.Equal(t, log.WarnLevel, entry.Level)
+		require.Equal(t, "Shadow provisioner result differs from the primary provisioner", entry.Message)
+		require.Equal(t, "rejected: [provisioner group group1 is deleted]", entry.Data["shadow-result"])
+	})
+
+	t.Run("no-op mismatch", func(t *testing.T) {
+		entry := mirror(t, ShadowModeNoOp, &mockShadowBackend{image: "mattermost/mattermost", version: "7.0.0"}, nil)
+		require.Equal(t, log.WarnLevel, entry.Level)
+		require.Equal(t, "reports mattermost/mattermost:7.0.0", entry.Data["shadow-result"])
+	})
+
+	t.Run("primary failure", func(t *testing.T) {
+		entry := mirror(t, ShadowModeNoOp, &mockShadowBackend{image: "mattermost/mattermost", version: "7.0.0"}, errors.New("release timed out"))
+		require.Equal(t, log.InfoLevel, entry.Level)
+		require.Equal(t, false, entry.Data["primary-succeeded"])
+		require.Equal(t, "release timed out", entry.Data["primary-error"])
+	})
+
+	t.Run("shadow error", func(t *testing.T) {
+		entry := mirror(t, ShadowModeNoOp, &mockShadowBackend{err: errors.New("connection refused")}, nil)
+		require.Equal(t, log.WarnLevel, entry.Level)
+		require.Equal(t, "Shadow provisioner failed to mirror the operation", entry.Message)
+	})
+
+	t.Run("shadow panic", func(t *testing.T) {
+		entry := mirror(t, ShadowModeDryRun, &mockShadowBackend{panics: true}, nil)
+		require.Equal(t, log.WarnLevel, entry.Level)
+		require.Equal(t, "Shadow provisioner failed to mirror the operation", entry.Message)
+	})
+}
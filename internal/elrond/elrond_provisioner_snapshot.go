@@ -0,0 +1,152 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	cmodel "github.com/mattermost/mattermost-cloud/model"
+	"github.com/pkg/errors"
+)
+
+// databaseSnapshotPollInterval is how often the installation backups taken
+// by the provisioner are checked for completion.
+var databaseSnapshotPollInterval = 10 * time.Second
+
+// SnapshotInstallationGroupDatabases snapshots the databases of the
+// installations of the provisioner group backing an installation group before
+// the given release, returning the snapshots once all of them are taken. The
+// snapshots are taken by the database snapshot hook when one is configured,
+// and as installation backups by the provisioner otherwise.
+func (provisioner *ElProvisioner) SnapshotInstallationGroupDatabases(installationGroup *model.InstallationGroup, release *model.RingRelease) ([]*model.DatabaseSnapshot, error) {
+	logger := provisioner.logger.WithField("installationgroup", installationGroup.ID)
+
+	client := provisioner.newAnnotatedProvisionerClient(installationGroup.Annotations)
+	installations, err := provisioner.lookupGroupInstallations(client, installationGroup.ProvisionerGroupID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get installations of group %s", installationGroup.ProvisionerGroupID)
+	}
+
+	timeout := time.Duration(provisioner.params.DatabaseSnapshotTimeout) * time.Second
+	if timeout == 0 {
+		timeout = model.DefaultDatabaseSnapshotTimeout * time.Second
+	}
+
+	snapshots := make([]*model.DatabaseSnapshot, 0, len(installations))
+	for _, installation := range installations {
+		snapshot := &model.DatabaseSnapshot{
+			ReleaseID:           release.ID,
+			InstallationGroupID: installationGroup.ID,
+			InstallationID:      installation.ID,
+		}
+		snapshots = append(snapshots, snapshot)
+
+		if provisioner.params.DatabaseSnapshotURL != "" {
+			snapshot.Source = model.DatabaseSnapshotSourceHook
+			snapshot.SnapshotID, err = requestDatabaseSnapshot(&http.Client{Timeout: timeout}, provisioner.params.DatabaseSnapshotURL, &model.DatabaseSnapshotRequest{
+				InstallationID:        installation.ID,
+				InstallationGroupID:   installationGroup.ID,
+				InstallationGroupName: installationGroup.Name,
+				ProvisionerGroupID:    installationGroup.ProvisionerGroupID,
+				ReleaseID:             release.ID,
+				Image:                 release.Image,
+				Version:               release.Version,
+				Annotations:           installationGroup.Annotations,
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to snapshot the database of installation %s", installation.ID)
+			}
+			logger.Infof("Snapshotted the database of installation %s as %s", installation.ID, snapshot.SnapshotID)
+			continue
+		}
+
+		backup, err := client.CreateInstallationBackup(installation.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to request the backup of installation %s", installation.ID)
+		}
+		snapshot.Source = model.DatabaseSnapshotSourceProvisioner
+		snapshot.SnapshotID = backup.ID
+		logger.Infof("Requested backup %s of installation %s", backup.ID, installation.ID)
+	}
+
+	if provisioner.params.DatabaseSnapshotURL == "" && len(snapshots) > 0 {
+		logger.Infof("Waiting up to %s for %d installation backups to complete...", timeout, len(snapshots))
+		if err = waitForInstallationBackups(client, snapshots, timeout); err != nil {
+			return nil, err
+		}
+	}
+
+	return snapshots, nil
+}
+
+// requestDatabaseSnapshot posts the given request to the database snapshot
+// hook, returning the ID of the snapshot taken.
+func requestDatabaseSnapshot(client *http.Client, snapshotURL string, request *model.DatabaseSnapshotRequest) (string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode database snapshot request")
+	}
+
+	resp, err := client.Post(snapshotURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", errors.Errorf("database snapshot hook responded %d", resp.StatusCode)
+	}
+
+	var response model.DatabaseSnapshotResponse
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", errors.Wrap(err, "failed to decode database snapshot response")
+	}
+	if response.ID == "" {
+		return "", errors.New("database snapshot hook responded without a snapshot ID")
+	}
+
+	return response.ID, nil
+}
+
+// waitForInstallationBackups waits for the installation backups of the given
+// snapshots to succeed, failing as soon as one of them fails.
+func waitForInstallationBackups(client *cmodel.Client, snapshots []*model.DatabaseSnapshot, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	poll := time.NewTicker(databaseSnapshotPollInterval)
+	defer poll.Stop()
+
+	pending := snapshots
+	for {
+		var stillPending []*model.DatabaseSnapshot
+		for _, snapshot := range pending {
+			backup, err := client.GetInstallationBackup(snapshot.SnapshotID)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get backup %s", snapshot.SnapshotID)
+			}
+			switch backup.State {
+			case cmodel.InstallationBackupStateBackupSucceeded:
+			case cmodel.InstallationBackupStateBackupRequested, cmodel.InstallationBackupStateBackupInProgress:
+				stillPending = append(stillPending, snapshot)
+			default:
+				return errors.Errorf("backup %s of installation %s is %s", snapshot.SnapshotID, snapshot.InstallationID, backup.State)
+			}
+		}
+		if len(stillPending) == 0 {
+			return nil
+		}
+		pending = stillPending
+
+		select {
+		case <-timer.C:
+			return errors.Errorf("timed out waiting for %d installation backups to complete", len(pending))
+		case <-poll.C:
+		}
+	}
+}
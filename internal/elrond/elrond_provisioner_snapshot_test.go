@@ -0,0 +1,169 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	cmodel "github.com/mattermost/mattermost-cloud/model"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+// mockBackupProvisioner serves the installations of a provisioner group and
+// their backups, which succeed once polled, or fail for the installations of
+// failing.
+type mockBackupProvisioner struct {
+	lock    sync.Mutex
+	backups map[string]*cmodel.InstallationBackup
+	failing string
+}
+
+func (p *mockBackupProvisioner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/installations":
+		json.NewEncoder(w).Encode([]*cmodel.InstallationDTO{ //nolint
+			{Installation: &cmodel.Installation{ID: "installation1"}},
+			{Installation: &cmodel.Installation{ID: "installation2"}},
+		})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/installations/backups":
+		var request cmodel.InstallationBackupRequest
+		json.NewDecoder(r.Body).Decode(&request) //nolint
+		backup := &cmodel.InstallationBackup{
+			ID:             "backup-" + request.InstallationID,
+			InstallationID: request.InstallationID,
+			State:          cmodel.InstallationBackupStateBackupRequested,
+		}
+		p.backups[backup.ID] = backup
+		json.NewEncoder(w).Encode(backup) //nolint
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/installations/backup/"):
+		backup := p.backups[strings.TrimPrefix(r.URL.Path, "/api/installations/backup/")]
+		if backup == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(backup) //nolint
+		if backup.InstallationID == p.failing {
+			backup.State = cmodel.InstallationBackupStateBackupFailed
+		} else {
+			backup.State = cmodel.InstallationBackupStateBackupSucceeded
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSnapshotInstallationGroupDatabases(t *testing.T) {
+	databaseSnapshotPollInterval = 10 * time.Millisecond
+	defer func() { databaseSnapshotPollInterval = 10 * time.Second }()
+
+	logger, _ := test.NewNullLogger()
+	installationGroup := &model.InstallationGroup{ID: "ig1", Name: "ig-1", ProvisionerGroupID: "group1"}
+	release := &model.RingRelease{ID: "release1", Image: "mattermost/mattermost", Version: "7.1.0"}
+
+	t.Run("provisioner backups", func(t *testing.T) {
+		ts := httptest.NewServer(&mockBackupProvisioner{backups: map[string]*cmodel.InstallationBackup{}})
+		defer ts.Close()
+
+		provisioner := NewElrondProvisioner(ProvisioningParams{}, logger, ts.URL)
+		snapshots, err := provisioner.SnapshotInstallationGroupDatabases(installationGroup, release)
+		require.NoError(t, err)
+		require.Equal(t, []*model.DatabaseSnapshot{
+			{ReleaseID: "release1", InstallationGroupID: "ig1", InstallationID: "installation1", SnapshotID: "backup-installation1", Source: model.DatabaseSnapshotSourceProvisioner},
+			{ReleaseID: "release1", InstallationGroupID: "ig1", InstallationID: "installation2", SnapshotID: "backup-installation2", Source: model.DatabaseSnapshotSourceProvisioner},
+		}, snapshots)
+	})
+
+	t.Run("failed provisioner backup", func(t *testing.T) {
+		ts := httptest.NewServer(&mockBackupProvisioner{backups: map[string]*cmodel.InstallationBackup{}, failing: "installation2"})
+		defer ts.Close()
+
+		provisioner := NewElrondProvisioner(ProvisioningParams{}, logger, ts.URL)
+		_, err := provisioner.SnapshotInstallationGroupDatabases(installationGroup, release)
+		require.EqualError(t, err, "backup backup-installation2 of installation installation2 is backup-failed")
+	})
+
+	t.Run("timed out provisioner backups", func(t *testing.T) {
+		ts := httptest.NewServer(&mockBackupProvisioner{backups: map[string]*cmodel.InstallationBackup{}})
+		defer ts.Close()
+
+		databaseSnapshotPollInterval = time.Hour
+		defer func() { databaseSnapshotPollInterval = 10 * time.Millisecond }()
+		provisioner := NewElrondProvisioner(ProvisioningParams{DatabaseSnapshotTimeout: 1}, logger, ts.URL)
+		_, err := provisioner.SnapshotInstallationGroupDatabases(installationGroup, release)
+		require.EqualError(t, err, "timed out waiting for 2 installation backups to complete")
+	})
+
+	t.Run("hook", func(t *testing.T) {
+		provisionerServer := httptest.NewServer(&mockBackupProvisioner{backups: map[string]*cmodel.InstallationBackup{}})
+		defer provisionerServer.Close()
+
+		var requests []*model.DatabaseSnapshotRequest
+		hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var request model.DatabaseSnapshotRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			requests = append(requests, &request)
+			if request.InstallationID == "installation2" {
+				w.Write([]byte(`{"id": "snap-2"}`)) //nolint
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": "snap-1"}`)) //nolint
+		}))
+		defer hook.Close()
+
+		provisioner := NewElrondProvisioner(ProvisioningParams{DatabaseSnapshotURL: hook.URL}, logger, provisionerServer.URL)
+		snapshots, err := provisioner.SnapshotInstallationGroupDatabases(installationGroup, release)
+		require.NoError(t, err)
+		require.Equal(t, []*model.DatabaseSnapshot{
+			{ReleaseID: "release1", InstallationGroupID: "ig1", InstallationID: "installation1", SnapshotID: "snap-1", Source: model.DatabaseSnapshotSourceHook},
+			{ReleaseID: "release1", InstallationGroupID: "ig1", InstallationID: "installation2", SnapshotID: "snap-2", Source: model.DatabaseSnapshotSourceHook},
+		}, snapshots)
+		require.Len(t, requests, 2)
+		require.Equal(t, &model.DatabaseSnapshotRequest{
+			InstallationID:        "installation1",
+			InstallationGroupID:   "ig1",
+			InstallationGroupName: "ig-1",
+			ProvisionerGroupID:    "group1",
+			ReleaseID:             "release1",
+			Image:                 "mattermost/mattermost",
+			Version:               "7.1.0",
+		}, requests[0])
+	})
+}
+
+func TestRequestDatabaseSnapshot(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/snapshot":
+			w.Write([]byte(`{"id": "snap-1"}`)) //nolint
+		case "/empty":
+			w.Write([]byte(`{}`)) //nolint
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	snapshotID, err := requestDatabaseSnapshot(ts.Client(), ts.URL+"/snapshot", &model.DatabaseSnapshotRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "snap-1", snapshotID)
+
+	_, err = requestDatabaseSnapshot(ts.Client(), ts.URL+"/empty", &model.DatabaseSnapshotRequest{})
+	require.EqualError(t, err, "database snapshot hook responded without a snapshot ID")
+
+	_, err = requestDatabaseSnapshot(ts.Client(), ts.URL+"/failing", &model.DatabaseSnapshotRequest{})
+	require.EqualError(t, err, "database snapshot hook responded 500")
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ProvisionerTransportParams configure the connections to the provisioner.
+type ProvisionerTransportParams struct {
+	// MaxIdleConns is the number of idle connections to the provisioner kept
+	// open for reuse.
+	MaxIdleConns int
+	// IdleConnTimeout is how long an idle connection is kept open.
+	IdleConnTimeout time.Duration
+	// KeepAlive is the interval between TCP keep-alive probes of the
+	// connections.
+	KeepAlive time.Duration
+	// RequestTimeout bounds each call to the provisioner, response body
+	// included. Zero disables it.
+	RequestTimeout time.Duration
+	// Proxy selects the proxy of each call to the provisioner, if any. The
+	// proxy environment variables are used when nil.
+	Proxy func(*http.Request) (*url.URL, error)
+	// TLSClientConfig, when set, configures the TLS connections to the
+	// provisioner, such as the certificate authorities they trust.
+	TLSClientConfig *tls.Config
+}
+
+// provisionerTransport sends the requests to the provisioner through a pool of
+// connections of its own, and the other requests to the fallback transport.
+type provisionerTransport struct {
+	host           string
+	pool           *http.Transport
+	fallback       http.RoundTripper
+	requestTimeout time.Duration
+}
+
+// NewProvisionerTransport returns a transport that sends the requests to the
+// given provisioner server through a pool of connections sized and timed by
+// params, and the other requests to fallback.
+//
+// The provisioner client always uses the default transport, so the server
+// installs the returned transport as http.DefaultTransport. Without it, each
+// installation group operation of a release opens its own connection, as the
+// default transport only keeps two idle connections per host.
+func NewProvisionerTransport(provisionerServer string, params ProvisionerTransportParams, fallback http.RoundTripper) (http.RoundTripper, error) {
+	server, err := url.Parse(provisionerServer)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid provisioner server")
+	}
+	if server.Host == "" {
+		return nil, errors.Errorf("invalid provisioner server %q: missing host", provisionerServer)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: params.KeepAlive,
+	}
+
+	proxy := params.Proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+
+	return &provisionerTransport{
+		host: server.Host,
+		pool: &http.Transport{
+			Proxy:                 proxy,
+			DialContext:           dialer.DialContext,
+			TLSClientConfig:       params.TLSClientConfig,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          params.MaxIdleConns,
+			MaxIdleConnsPerHost:   params.MaxIdleConns,
+			IdleConnTimeout:       params.IdleConnTimeout,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+		fallback:       fallback,
+		requestTimeout: params.RequestTimeout,
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *provisionerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.fallback.RoundTrip(req)
+	}
+	if t.requestTimeout <= 0 {
+		return t.pool.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.requestTimeout)
+	resp, err := t.pool.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// The timeout covers reading the body, so it is only released once
+	// the body is closed.
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// cancelOnCloseBody releases the timeout of a provisioner call when its
+// response body is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package elrond
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fallbackTransport struct {
+	calls int32
+}
+
+func (t *fallbackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.calls, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestProvisionerTransport(t *testing.T) {
+	var connections int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") != "" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(`{}`)) //nolint
+	}))
+	ts.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	fallback := &fallbackTransport{}
+	transport, err := NewProvisionerTransport(ts.URL, ProvisionerTransportParams{
+		MaxIdleConns:    10,
+		IdleConnTimeout: time.Minute,
+		KeepAlive:       time.Minute,
+		RequestTimeout:  100 * time.Millisecond,
+	}, fallback)
+	require.NoError(t, err)
+	client := &http.Client{Transport: transport}
+
+	t.Run("connections are reused", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			resp, err := client.Get(ts.URL + "/api/group/1")
+			require.NoError(t, err)
+			io.Copy(io.Discard, resp.Body) //nolint
+			resp.Body.Close()
+		}
+		require.EqualValues(t, 1, atomic.LoadInt32(&connections))
+		require.Zero(t, atomic.LoadInt32(&fallback.calls))
+	})
+
+	t.Run("calls time out", func(t *testing.T) {
+		_, err := client.Get(ts.URL + "/api/group/1?slow=true")
+		require.Error(t, err)
+	})
+
+	t.Run("other hosts use the fallback transport", func(t *testing.T) {
+		other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer other.Close()
+
+		resp, err := client.Get(other.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.EqualValues(t, 1, atomic.LoadInt32(&fallback.calls))
+	})
+
+	t.Run("invalid server", func(t *testing.T) {
+		_, err := NewProvisionerTransport("localhost", ProvisionerTransportParams{}, fallback)
+		require.Error(t, err)
+	})
+}
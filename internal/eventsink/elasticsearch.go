@@ -0,0 +1,194 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package eventsink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultIndexPattern is the default index pattern of the Elasticsearch sink,
+// creating one index per day.
+const DefaultIndexPattern = "elrond-events-%{+yyyy.MM.dd}"
+
+// queueSize is the number of events buffered while Elasticsearch is slow or
+// unreachable. Events beyond it are dropped rather than blocking transitions.
+const queueSize = 1000
+
+var indexDateRegex = regexp.MustCompile(`%\{\+([^}]+)\}`)
+
+// indexDateReplacer translates the Logstash-style date tokens of an index
+// pattern into a Go time layout.
+var indexDateReplacer = strings.NewReplacer(
+	"yyyy", "2006",
+	"yy", "06",
+	"MM", "01",
+	"dd", "02",
+	"HH", "15",
+)
+
+// IndexName returns the name of the index of an event at the given time. The
+// pattern may contain Logstash-style dates such as %{+yyyy.MM.dd}, which are
+// formatted in UTC.
+func IndexName(pattern string, t time.Time) string {
+	return indexDateRegex.ReplaceAllStringFunc(pattern, func(match string) string {
+		layout := indexDateReplacer.Replace(indexDateRegex.FindStringSubmatch(match)[1])
+		return t.UTC().Format(layout)
+	})
+}
+
+// ElasticsearchSink indexes state change events into Elasticsearch or
+// OpenSearch. Events are sent in the background so that a slow or unreachable
+// cluster never delays the supervisors.
+type ElasticsearchSink struct {
+	url          string
+	indexPattern string
+	client       *http.Client
+	logger       log.FieldLogger
+
+	queue chan *model.StateChangeEvent
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewElasticsearchSink creates a new ElasticsearchSink and starts its send
+// loop. Credentials may be given as the user info of the URL. Events are sent
+// through the given transport, or http.DefaultTransport if nil.
+func NewElasticsearchSink(serverURL, indexPattern string, transport http.RoundTripper, logger log.FieldLogger) (*ElasticsearchSink, error) {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse elasticsearch url")
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, errors.Errorf("elasticsearch url must use http or https, got %q", serverURL)
+	}
+	if indexPattern == "" {
+		indexPattern = DefaultIndexPattern
+	}
+	if name := IndexName(indexPattern, time.Now()); name != strings.ToLower(name) {
+		return nil, errors.Errorf("index pattern %q must be lowercase", indexPattern)
+	}
+
+	s := &ElasticsearchSink{
+		url:          strings.TrimSuffix(serverURL, "/"),
+		indexPattern: indexPattern,
+		client:       &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		logger:       logger.WithField("event-sink", "elasticsearch"),
+		queue:        make(chan *model.StateChangeEvent, queueSize),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	go s.run()
+
+	return s, nil
+}
+
+// Send queues the given event to be indexed.
+func (s *ElasticsearchSink) Send(event *model.StateChangeEvent) {
+	select {
+	case s.queue <- event:
+	default:
+		s.logger.WithField("event", event.ID).Warn("Event sink queue is full, dropping event")
+	}
+}
+
+// Close stops the send loop, indexing any queued events first.
+func (s *ElasticsearchSink) Close() {
+	close(s.stop)
+	<-s.done
+}
+
+func (s *ElasticsearchSink) run() {
+	defer close(s.done)
+
+	for {
+		select {
+		case event := <-s.queue:
+			s.send(event)
+		case <-s.stop:
+			for {
+				select {
+				case event := <-s.queue:
+					s.send(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *ElasticsearchSink) send(event *model.StateChangeEvent) {
+	if err := s.index(event); err != nil {
+		s.logger.WithError(err).WithField("event", event.ID).Error("Failed to index event")
+	}
+}
+
+// index stores the event as a document whose ID is the event ID, so that
+// retries never duplicate it.
+func (s *ElasticsearchSink) index(event *model.StateChangeEvent) error {
+	timestamp := time.Unix(0, event.Timestamp*int64(time.Millisecond))
+
+	body, err := json.Marshal(newEventDocument(event, timestamp))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal event document")
+	}
+
+	docURL := fmt.Sprintf("%s/%s/_doc/%s", s.url, url.PathEscape(IndexName(s.indexPattern, timestamp)), url.PathEscape(event.ID))
+	req, err := http.NewRequest(http.MethodPut, docURL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create index request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send index request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("index request failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// eventDocument is the indexed form of a state change event, with the
+// @timestamp field Kibana expects for time-based dashboards.
+type eventDocument struct {
+	Timestamp    string `json:"@timestamp"`
+	ID           string `json:"id"`
+	ResourceType string `json:"resourceType"`
+	ResourceID   string `json:"resourceId"`
+	RingID       string `json:"ringId,omitempty"`
+	ReleaseID    string `json:"releaseId,omitempty"`
+	OldState     string `json:"oldState,omitempty"`
+	NewState     string `json:"newState"`
+}
+
+func newEventDocument(event *model.StateChangeEvent, timestamp time.Time) *eventDocument {
+	return &eventDocument{
+		Timestamp:    timestamp.UTC().Format(time.RFC3339Nano),
+		ID:           event.ID,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		RingID:       event.RingID,
+		ReleaseID:    event.ReleaseID,
+		OldState:     event.OldState,
+		NewState:     event.NewState,
+	}
+}
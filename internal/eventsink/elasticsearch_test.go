@@ -0,0 +1,97 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package eventsink
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexName(t *testing.T) {
+	at := time.Date(2024, time.March, 5, 23, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		pattern  string
+		expected string
+	}{
+		{"elrond-events", "elrond-events"},
+		{DefaultIndexPattern, "elrond-events-2024.03.05"},
+		{"elrond-%{+yyyy.MM}", "elrond-2024.03"},
+		{"elrond-%{+yy}-%{+dd.HH}", "elrond-24-05.23"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			require.Equal(t, tc.expected, IndexName(tc.pattern, at))
+		})
+	}
+}
+
+func TestNewElasticsearchSink(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	_, err := NewElasticsearchSink("localhost:9200", "", nil, logger)
+	require.Error(t, err)
+
+	_, err = NewElasticsearchSink("http://localhost:9200", "Elrond-%{+yyyy}", nil, logger)
+	require.Error(t, err)
+}
+
+func TestElasticsearchSink(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	var lock sync.Mutex
+	paths := []string{}
+	documents := []map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+
+		var document map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&document))
+
+		lock.Lock()
+		paths = append(paths, r.URL.Path)
+		documents = append(documents, document)
+		lock.Unlock()
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	sink, err := NewElasticsearchSink(server.URL+"/", "elrond-%{+yyyy.MM}", nil, logger)
+	require.NoError(t, err)
+
+	sink.Send(&model.StateChangeEvent{
+		ID:           "event1",
+		ResourceType: model.TypeRing,
+		ResourceID:   "ring1",
+		RingID:       "ring1",
+		ReleaseID:    "release1",
+		OldState:     model.RingStateStable,
+		NewState:     model.RingStateReleasePending,
+		Timestamp:    time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond),
+	})
+	sink.Close()
+
+	require.Equal(t, []string{"/elrond-2024.03/_doc/event1"}, paths)
+	require.Equal(t, []map[string]interface{}{{
+		"@timestamp":   "2024-03-05T12:00:00Z",
+		"id":           "event1",
+		"resourceType": model.TypeRing,
+		"resourceId":   "ring1",
+		"ringId":       "ring1",
+		"releaseId":    "release1",
+		"oldState":     model.RingStateStable,
+		"newState":     model.RingStateReleasePending,
+	}}, documents)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package evidence
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// Config is the configuration of the bucket release evidence is archived to.
+// Credentials are read from the standard AWS environment, e.g.
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY. Google Cloud Storage buckets
+// are supported through their S3-compatible endpoint with HMAC keys.
+type Config struct {
+	Bucket string
+	// Prefix is prepended to the key of every evidence bundle.
+	Prefix string
+	Region string
+	// Endpoint overrides the S3 endpoint, e.g. https://storage.googleapis.com.
+	Endpoint string
+	// ForcePathStyle addresses the bucket in the path rather than the host.
+	ForcePathStyle bool
+}
+
+// Archiver uploads release evidence bundles to an S3-compatible bucket.
+type Archiver struct {
+	bucket string
+	prefix string
+	client *s3.S3
+}
+
+// NewArchiver creates a new Archiver.
+func NewArchiver(config Config) (*Archiver, error) {
+	if config.Bucket == "" {
+		return nil, errors.New("evidence bucket must not be empty")
+	}
+
+	awsConfig := aws.NewConfig().
+		WithRegion(config.Region).
+		WithS3ForcePathStyle(config.ForcePathStyle)
+	if config.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(config.Endpoint)
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create aws session")
+	}
+
+	return &Archiver{
+		bucket: config.Bucket,
+		prefix: config.Prefix,
+		client: s3.New(sess),
+	}, nil
+}
+
+// Archive uploads the given release evidence as JSON, returning its key.
+func (a *Archiver) Archive(evidence *model.ReleaseEvidence) (string, error) {
+	data, err := evidence.ToJSON()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal release evidence")
+	}
+
+	key := Key(a.prefix, evidence)
+	_, err = a.client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(a.bucket),
+		Key:         aws.String(key),
+		Body:        strings.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to upload release evidence to %s/%s", a.bucket, key)
+	}
+
+	return key, nil
+}
+
+// Key returns the object key of the given release evidence, grouping the
+// bundles of a ring under its ID, e.g.
+// prefix/ring-id/2024-03-05T12:00:00Z-release-id.json.
+func Key(prefix string, evidence *model.ReleaseEvidence) string {
+	completedAt := time.Unix(0, evidence.CompletedAt*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+	return path.Join(prefix, evidence.Ring.ID, fmt.Sprintf("%s-%s.json", completedAt, evidence.Release.ID))
+}
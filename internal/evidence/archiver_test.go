@@ -0,0 +1,74 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package evidence
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	evidence := &model.ReleaseEvidence{
+		Ring:        &model.Ring{ID: "ring1"},
+		Release:     &model.RingRelease{ID: "release1"},
+		CompletedAt: time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond),
+	}
+
+	require.Equal(t, "ring1/2024-03-05T12:00:00Z-release1.json", Key("", evidence))
+	require.Equal(t, "elrond/prod/ring1/2024-03-05T12:00:00Z-release1.json", Key("elrond/prod/", evidence))
+}
+
+func TestNewArchiver(t *testing.T) {
+	_, err := NewArchiver(Config{Region: "us-east-1"})
+	require.Error(t, err)
+}
+
+func TestArchive(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	var path, contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		var err error
+		body, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	archiver, err := NewArchiver(Config{
+		Bucket:         "evidence",
+		Prefix:         "elrond",
+		Region:         "us-east-1",
+		Endpoint:       server.URL,
+		ForcePathStyle: true,
+	})
+	require.NoError(t, err)
+
+	evidence := &model.ReleaseEvidence{
+		Ring:        &model.Ring{ID: "ring1"},
+		Release:     &model.RingRelease{ID: "release1"},
+		CompletedAt: time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond),
+	}
+	key, err := archiver.Archive(evidence)
+	require.NoError(t, err)
+	require.Equal(t, "elrond/ring1/2024-03-05T12:00:00Z-release1.json", key)
+	require.Equal(t, "/evidence/"+key, path)
+	require.Equal(t, "application/json", contentType)
+
+	var uploaded model.ReleaseEvidence
+	require.NoError(t, json.Unmarshal(body, &uploaded))
+	require.Equal(t, "release1", uploaded.Release.ID)
+}
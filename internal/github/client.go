@@ -0,0 +1,157 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// DefaultAddress is the address of the GitHub REST API.
+const DefaultAddress = "https://api.github.com"
+
+// Client is a minimal client of the GitHub REST API, opening issues in a
+// single repository with a token.
+type Client struct {
+	address    string
+	owner      string
+	repository string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a new Client of the GitHub API at the given address,
+// opening issues in the given <owner>/<repository>.
+func NewClient(address, repository, token string) (*Client, error) {
+	owner, name, err := ParseRepository(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		address:    strings.TrimSuffix(address, "/"),
+		owner:      owner,
+		repository: name,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// ParseRepository splits a repository given as <owner>/<repository>.
+func ParseRepository(repository string) (string, string, error) {
+	owner, name, ok := strings.Cut(repository, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", errors.Errorf("invalid GitHub repository %q, must be <owner>/<repository>", repository)
+	}
+
+	return owner, name, nil
+}
+
+// SetTransport configures the transport the client calls GitHub through,
+// such as one going through a proxy. Passing nil uses http.DefaultTransport.
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
+
+type createIssueRequest struct {
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+type issueResponse struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// CreateIssue opens an issue in the repository with the given labels,
+// returning the URL of the issue.
+func (c *Client) CreateIssue(title, body string, labels []string) (string, error) {
+	path := fmt.Sprintf("/repos/%s/%s/issues", url.PathEscape(c.owner), url.PathEscape(c.repository))
+	response := &issueResponse{}
+	if err := c.do(http.MethodPost, path, &createIssueRequest{Title: title, Body: body, Labels: labels}, response); err != nil {
+		return "", errors.Wrap(err, "failed to create issue")
+	}
+
+	return response.HTMLURL, nil
+}
+
+// FailureIssueCreator opens the issues tracking the repeated release
+// failures of rings in a GitHub repository.
+type FailureIssueCreator struct {
+	client *Client
+	labels []string
+}
+
+// NewFailureIssueCreator creates a new FailureIssueCreator opening issues
+// with the given labels.
+func NewFailureIssueCreator(client *Client, labels []string) *FailureIssueCreator {
+	return &FailureIssueCreator{
+		client: client,
+		labels: labels,
+	}
+}
+
+// CreateFailureIssue opens an issue tracking the given repeated release
+// failures, returning the URL of the issue.
+func (c *FailureIssueCreator) CreateFailureIssue(failures *model.RepeatedFailures) (string, error) {
+	title, body := failures.IssueContent()
+	issueURL, err := c.client.CreateIssue(title, body, c.labels)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create failure issue in %s/%s", c.client.owner, c.client.repository)
+	}
+
+	return issueURL, nil
+}
+
+func (c *Client) do(method, path string, request, response interface{}) error {
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal request")
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.address+path, body)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("github returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	if response == nil {
+		return nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return errors.Wrap(err, "failed to decode response")
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepository(t *testing.T) {
+	owner, name, err := ParseRepository("mattermost/elrond")
+	require.NoError(t, err)
+	require.Equal(t, "mattermost", owner)
+	require.Equal(t, "elrond", name)
+
+	for _, repository := range []string{"", "elrond", "/elrond", "mattermost/", "mattermost/elrond/issues"} {
+		_, _, err = ParseRepository(repository)
+		require.Error(t, err, repository)
+	}
+}
+
+func TestFailureIssueCreator(t *testing.T) {
+	var requests []string
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /repos/mattermost/elrond/issues":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"number":12,"html_url":"https://github.com/mattermost/elrond/issues/12"}`)) //nolint
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Not Found"}`)) //nolint
+		}
+	}))
+	defer server.Close()
+
+	failures := &model.RepeatedFailures{
+		Ring:   &model.Ring{ID: "ring1", Name: "prod"},
+		Window: 24 * time.Hour,
+		Failures: []*model.StateChangeEvent{
+			{ID: "event1", ReleaseID: "release1", OldState: model.RingStateReleaseInProgress, NewState: model.RingStateReleaseFailed},
+			{ID: "event2", ReleaseID: "release2", OldState: model.RingStateReleaseInProgress, NewState: model.RingStateReleaseFailed},
+		},
+		Reasons: map[string]int{},
+	}
+
+	t.Run("created", func(t *testing.T) {
+		client, err := NewClient(server.URL+"/", "mattermost/elrond", "token")
+		require.NoError(t, err)
+
+		issueURL, err := NewFailureIssueCreator(client, []string{"release-failure"}).CreateFailureIssue(failures)
+		require.NoError(t, err)
+		require.Equal(t, "https://github.com/mattermost/elrond/issues/12", issueURL)
+		require.Equal(t, []string{"POST /repos/mattermost/elrond/issues"}, requests)
+		require.Equal(t, "Ring prod failed 2 releases within 1 day", bodies[0]["title"])
+		require.Contains(t, bodies[0]["body"], "release release2 moved from release-in-progress to release-failed")
+		require.Equal(t, []interface{}{"release-failure"}, bodies[0]["labels"])
+	})
+
+	t.Run("unknown repository", func(t *testing.T) {
+		client, err := NewClient(server.URL, "mattermost/unknown", "token")
+		require.NoError(t, err)
+
+		_, err = NewFailureIssueCreator(client, nil).CreateFailureIssue(failures)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "github returned status 404")
+		require.NotContains(t, bodies[len(bodies)-1], "labels")
+	})
+}
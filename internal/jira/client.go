@@ -0,0 +1,171 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Client is a minimal client of the Jira REST API, authenticating with a
+// username and API token.
+type Client struct {
+	address    string
+	username   string
+	apiToken   string
+	httpClient *http.Client
+}
+
+// NewClient creates a new Client of the Jira server at the given address.
+func NewClient(address, username, apiToken string) *Client {
+	return &Client{
+		address:    strings.TrimSuffix(address, "/"),
+		username:   username,
+		apiToken:   apiToken,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SetTransport configures the transport the client calls Jira through, such
+// as one going through a proxy. Passing nil uses http.DefaultTransport.
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
+
+type createIssueRequest struct {
+	Fields createIssueFields `json:"fields"`
+}
+
+type createIssueFields struct {
+	Project     keyField  `json:"project"`
+	IssueType   nameField `json:"issuetype"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description"`
+}
+
+type keyField struct {
+	Key string `json:"key"`
+}
+
+type nameField struct {
+	Name string `json:"name"`
+}
+
+type transitionsResponse struct {
+	Transitions []transition `json:"transitions"`
+}
+
+type transition struct {
+	ID   string    `json:"id"`
+	Name string    `json:"name"`
+	To   nameField `json:"to"`
+}
+
+// CreateIssue creates an issue in the given project, returning its key.
+func (c *Client) CreateIssue(project, issueType, summary, description string) (string, error) {
+	request := &createIssueRequest{Fields: createIssueFields{
+		Project:     keyField{Key: project},
+		IssueType:   nameField{Name: issueType},
+		Summary:     summary,
+		Description: description,
+	}}
+
+	var issue keyField
+	if err := c.do(http.MethodPost, "/rest/api/2/issue", request, &issue); err != nil {
+		return "", errors.Wrap(err, "failed to create issue")
+	}
+
+	return issue.Key, nil
+}
+
+// TransitionIssue moves the given issue to the given status, using the first
+// available transition leading to it.
+func (c *Client) TransitionIssue(issueKey, status string) error {
+	path := fmt.Sprintf("/rest/api/2/issue/%s/transitions", url.PathEscape(issueKey))
+
+	var transitions transitionsResponse
+	if err := c.do(http.MethodGet, path, nil, &transitions); err != nil {
+		return errors.Wrap(err, "failed to get issue transitions")
+	}
+
+	for _, t := range transitions.Transitions {
+		if !strings.EqualFold(t.To.Name, status) {
+			continue
+		}
+
+		request := map[string]interface{}{"transition": map[string]string{"id": t.ID}}
+		if err := c.do(http.MethodPost, path, request, nil); err != nil {
+			return errors.Wrapf(err, "failed to transition issue to %s", status)
+		}
+		return nil
+	}
+
+	return errors.Errorf("issue %s has no transition to status %s", issueKey, status)
+}
+
+// AddComment adds the given comment to the given issue.
+func (c *Client) AddComment(issueKey, body string) error {
+	path := fmt.Sprintf("/rest/api/2/issue/%s/comment", url.PathEscape(issueKey))
+	if err := c.do(http.MethodPost, path, map[string]string{"body": body}, nil); err != nil {
+		return errors.Wrap(err, "failed to add comment")
+	}
+
+	return nil
+}
+
+// IssueURL returns the URL of the issue with the given key in the Jira web
+// interface.
+func (c *Client) IssueURL(issueKey string) string {
+	return c.address + "/browse/" + url.PathEscape(issueKey)
+}
+
+func (c *Client) do(method, path string, request, response interface{}) error {
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal request")
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.address+path, body)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.SetBasicAuth(c.username, c.apiToken)
+	req.Header.Set("Accept", "application/json")
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("jira returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	if response == nil {
+		return nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return errors.Wrap(err, "failed to decode response")
+	}
+
+	return nil
+}
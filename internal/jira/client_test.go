@@ -0,0 +1,88 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package jira
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	var requests []string
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "elrond@example.com", username)
+		require.Equal(t, "token", password)
+
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodPost {
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			bodies = append(bodies, body)
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /rest/api/2/issue":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"10000","key":"REL-1"}`)) //nolint
+		case "GET /rest/api/2/issue/REL-1/transitions":
+			w.Write([]byte(`{"transitions":[{"id":"21","name":"Start","to":{"name":"In Progress"}},{"id":"31","name":"Resolve","to":{"name":"Done"}}]}`)) //nolint
+		case "POST /rest/api/2/issue/REL-1/transitions":
+			w.WriteHeader(http.StatusNoContent)
+		case "POST /rest/api/2/issue/REL-1/comment":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"1"}`)) //nolint
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errorMessages":["Issue does not exist"]}`)) //nolint
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "elrond@example.com", "token")
+
+	t.Run("create issue", func(t *testing.T) {
+		key, err := client.CreateIssue("REL", "Task", "summary", "description")
+		require.NoError(t, err)
+		require.Equal(t, "REL-1", key)
+		require.Equal(t, map[string]interface{}{"fields": map[string]interface{}{
+			"project":     map[string]interface{}{"key": "REL"},
+			"issuetype":   map[string]interface{}{"name": "Task"},
+			"summary":     "summary",
+			"description": "description",
+		}}, bodies[len(bodies)-1])
+	})
+
+	t.Run("transition issue", func(t *testing.T) {
+		require.NoError(t, client.TransitionIssue("REL-1", "done"))
+		require.Equal(t, "POST /rest/api/2/issue/REL-1/transitions", requests[len(requests)-1])
+		require.Equal(t, map[string]interface{}{"transition": map[string]interface{}{"id": "31"}}, bodies[len(bodies)-1])
+	})
+
+	t.Run("transition issue to unknown status", func(t *testing.T) {
+		err := client.TransitionIssue("REL-1", "Failed")
+		require.EqualError(t, err, "issue REL-1 has no transition to status Failed")
+	})
+
+	t.Run("add comment", func(t *testing.T) {
+		require.NoError(t, client.AddComment("REL-1", "comment"))
+		require.Equal(t, map[string]interface{}{"body": "comment"}, bodies[len(bodies)-1])
+	})
+
+	t.Run("unknown issue", func(t *testing.T) {
+		err := client.AddComment("REL-2", "comment")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "jira returned status 404")
+	})
+	t.Run("issue url", func(t *testing.T) {
+		require.Equal(t, server.URL+"/browse/REL-1", client.IssueURL("REL-1"))
+	})
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package jira
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// DefaultStatuses maps the ring states to the Jira statuses their release
+// issue is moved to by default.
+var DefaultStatuses = map[string]string{
+	model.RingStateReleaseRequested: "In Progress",
+	model.RingStateStable:           "Done",
+}
+
+type issueClient interface {
+	CreateIssue(project, issueType, summary, description string) (string, error)
+	TransitionIssue(issueKey, status string) error
+	AddComment(issueKey, body string) error
+	IssueURL(issueKey string) string
+}
+
+// ReleaseTracker tracks every release of the rings with a Jira project in a
+// Jira issue.
+type ReleaseTracker struct {
+	client    issueClient
+	issueType string
+	statuses  map[string]string
+}
+
+// NewReleaseTracker creates a new ReleaseTracker creating issues of the given
+// type, and moving them to the status mapped to each ring state.
+func NewReleaseTracker(client *Client, issueType string, statuses map[string]string) *ReleaseTracker {
+	return &ReleaseTracker{
+		client:    client,
+		issueType: issueType,
+		statuses:  statuses,
+	}
+}
+
+// TrackRelease updates the Jira issue of the release of the given ring after
+// it transitioned between the given states, returning the key of the issue.
+// The issue is created when the ring starts releasing, moved to the status
+// mapped to each new state, and commented on when the release fails or is
+// rolled back.
+func (t *ReleaseTracker) TrackRelease(ring *model.Ring, release *model.RingRelease, installationGroups []*model.InstallationGroup, oldState, newState string) (string, error) {
+	if !isReleaseState(oldState) {
+		return ring.JiraIssueKey, nil
+	}
+
+	issueKey := ring.JiraIssueKey
+	if (oldState == model.RingStateReleasePending || oldState == model.RingStateReleaseBlocked) && newState == model.RingStateReleaseRequested {
+		summary, description := issueContent(ring, release)
+		key, err := t.client.CreateIssue(ring.JiraProject, t.issueType, summary, description)
+		if err != nil {
+			return ring.JiraIssueKey, errors.Wrapf(err, "failed to create release issue in project %s", ring.JiraProject)
+		}
+		issueKey = key
+	}
+	if issueKey == "" {
+		return "", nil
+	}
+
+	if status, ok := t.statuses[newState]; ok {
+		if err := t.client.TransitionIssue(issueKey, status); err != nil {
+			return issueKey, errors.Wrapf(err, "failed to move issue %s to %s", issueKey, status)
+		}
+	}
+
+	if comment := failureComment(ring, installationGroups, oldState, newState); comment != "" {
+		if err := t.client.AddComment(issueKey, comment); err != nil {
+			return issueKey, errors.Wrapf(err, "failed to comment on issue %s", issueKey)
+		}
+	}
+
+	return issueKey, nil
+}
+
+// CreateFailureIssue creates an issue in the Jira project of the ring
+// tracking its repeated release failures, returning the URL of the issue.
+func (t *ReleaseTracker) CreateFailureIssue(failures *model.RepeatedFailures) (string, error) {
+	if failures.Ring.JiraProject == "" {
+		return "", errors.Errorf("ring %s has no Jira project", failures.Ring.Name)
+	}
+
+	summary, description := failures.IssueContent()
+	key, err := t.client.CreateIssue(failures.Ring.JiraProject, t.issueType, summary, description)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create failure issue in project %s", failures.Ring.JiraProject)
+	}
+
+	return t.client.IssueURL(key), nil
+}
+
+// isReleaseState returns whether a ring in the given state is releasing, so
+// that its transitions are part of a release.
+func isReleaseState(state string) bool {
+	switch state {
+	case model.RingStateReleasePending, model.RingStateReleaseBlocked, model.RingStateReleaseRequested, model.RingStateReleaseInProgress,
+		model.RingStateSoakingRequested, model.RingStateReleaseRollbackRequested:
+		return true
+	}
+	return false
+}
+
+func issueContent(ring *model.Ring, release *model.RingRelease) (string, string) {
+	releaseName := "an unknown release"
+	if release != nil {
+		releaseName = fmt.Sprintf("%s:%s", release.Image, release.Version)
+	}
+
+	summary := fmt.Sprintf("Release %s to ring %s", releaseName, ring.Name)
+
+	var description strings.Builder
+	fmt.Fprintf(&description, "Elrond is releasing %s to ring %s (%s).\n", releaseName, ring.Name, ring.ID)
+	if release != nil {
+		fmt.Fprintf(&description, "Release ID: %s\n", release.ID)
+		if release.Type != "" {
+			fmt.Fprintf(&description, "Release type: %s\n", release.Type)
+		}
+	}
+	if ring.ReleaseImpactInstallations > 0 {
+		fmt.Fprintf(&description, "Impact: %d installations, %d customers\n", ring.ReleaseImpactInstallations, ring.ReleaseImpactCustomers)
+	}
+
+	return summary, description.String()
+}
+
+// failureComment returns the comment describing a failed or rolled back
+// release, or an empty string for any other transition.
+func failureComment(ring *model.Ring, installationGroups []*model.InstallationGroup, oldState, newState string) string {
+	switch newState {
+	case model.RingStateReleaseFailed, model.RingStateSoakingFailed, model.RingStateReleaseRollbackFailed, model.RingStateReleaseRollbackComplete:
+	default:
+		return ""
+	}
+
+	var comment strings.Builder
+	fmt.Fprintf(&comment, "Ring %s moved from %s to %s.\n", ring.Name, oldState, newState)
+
+	var failed []string
+	for _, ig := range installationGroups {
+		if ig.HasFailedRelease() {
+			failed = append(failed, fmt.Sprintf("%s (%s)", ig.Name, ig.State))
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(&comment, "Failed installation groups: %s\n", strings.Join(failed, ", "))
+	}
+	if contacts := ring.Contacts(); contacts != "" {
+		fmt.Fprintf(&comment, "Contacts: %s\n", contacts)
+	}
+
+	if ring.RollbackSnapshotID != "" {
+		fmt.Fprintf(&comment, "Rollback target: release snapshot %s\n", ring.RollbackSnapshotID)
+	} else {
+		comment.WriteString("No rollback target is recorded for the ring.\n")
+	}
+
+	return comment.String()
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package jira
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type mockIssueClient struct {
+	Created     []string
+	Transitions []string
+	Comments    []string
+	CreateError error
+}
+
+func (c *mockIssueClient) CreateIssue(project, issueType, summary, description string) (string, error) {
+	if c.CreateError != nil {
+		return "", c.CreateError
+	}
+	c.Created = append(c.Created, summary)
+	return project + "-1", nil
+}
+
+func (c *mockIssueClient) TransitionIssue(issueKey, status string) error {
+	c.Transitions = append(c.Transitions, issueKey+" "+status)
+	return nil
+}
+
+func (c *mockIssueClient) AddComment(issueKey, body string) error {
+	c.Comments = append(c.Comments, body)
+	return nil
+}
+
+func (c *mockIssueClient) IssueURL(issueKey string) string {
+	return "https://jira.example.com/browse/" + issueKey
+}
+
+func TestReleaseTracker(t *testing.T) {
+	release := &model.RingRelease{ID: "release1", Image: "image", Version: "7.1.0"}
+
+	newTracker := func() (*ReleaseTracker, *mockIssueClient) {
+		client := &mockIssueClient{}
+		return &ReleaseTracker{client: client, issueType: "Task", statuses: DefaultStatuses}, client
+	}
+
+	t.Run("release requested", func(t *testing.T) {
+		tracker, client := newTracker()
+		ring := &model.Ring{Name: "prod", JiraProject: "REL"}
+
+		key, err := tracker.TrackRelease(ring, release, nil, model.RingStateReleasePending, model.RingStateReleaseRequested)
+		require.NoError(t, err)
+		require.Equal(t, "REL-1", key)
+		require.Equal(t, []string{"Release image:7.1.0 to ring prod"}, client.Created)
+		require.Equal(t, []string{"REL-1 In Progress"}, client.Transitions)
+		require.Empty(t, client.Comments)
+	})
+
+	t.Run("release completed", func(t *testing.T) {
+		tracker, client := newTracker()
+		ring := &model.Ring{Name: "prod", JiraProject: "REL", JiraIssueKey: "REL-7"}
+
+		key, err := tracker.TrackRelease(ring, release, nil, model.RingStateSoakingRequested, model.RingStateStable)
+		require.NoError(t, err)
+		require.Equal(t, "REL-7", key)
+		require.Empty(t, client.Created)
+		require.Equal(t, []string{"REL-7 Done"}, client.Transitions)
+	})
+
+	t.Run("release failed", func(t *testing.T) {
+		tracker, client := newTracker()
+		ring := &model.Ring{Name: "prod", JiraProject: "REL", JiraIssueKey: "REL-7", RollbackSnapshotID: "snapshot1", OwnerTeam: "Platform", EscalationPolicy: "PX12345"}
+		installationGroups := []*model.InstallationGroup{
+			{Name: "group1", State: model.InstallationGroupStable},
+			{Name: "group2", State: model.InstallationGroupReleaseFailed},
+		}
+
+		key, err := tracker.TrackRelease(ring, release, installationGroups, model.RingStateReleaseInProgress, model.RingStateReleaseFailed)
+		require.NoError(t, err)
+		require.Equal(t, "REL-7", key)
+		require.Empty(t, client.Transitions)
+		require.Equal(t, []string{
+			"Ring prod moved from release-in-progress to release-failed.\n" +
+				"Failed installation groups: group2 (release-failed)\n" +
+				"Contacts: owner team Platform, escalation policy PX12345\n" +
+				"Rollback target: release snapshot snapshot1\n",
+		}, client.Comments)
+	})
+
+	t.Run("transition outside of a release", func(t *testing.T) {
+		tracker, client := newTracker()
+		ring := &model.Ring{Name: "prod", JiraProject: "REL", JiraIssueKey: "REL-7"}
+
+		key, err := tracker.TrackRelease(ring, release, nil, model.RingStateCreationRequested, model.RingStateStable)
+		require.NoError(t, err)
+		require.Equal(t, "REL-7", key)
+		require.Empty(t, client.Transitions)
+	})
+
+	t.Run("issue creation failure", func(t *testing.T) {
+		tracker, client := newTracker()
+		client.CreateError = errors.New("unauthorized")
+		ring := &model.Ring{Name: "prod", JiraProject: "REL", JiraIssueKey: "REL-7"}
+
+		key, err := tracker.TrackRelease(ring, release, nil, model.RingStateReleasePending, model.RingStateReleaseRequested)
+		require.Error(t, err)
+		require.Equal(t, "REL-7", key)
+		require.Empty(t, client.Transitions)
+	})
+}
+
+func TestReleaseTrackerCreateFailureIssue(t *testing.T) {
+	failures := &model.RepeatedFailures{
+		Ring:   &model.Ring{ID: "ring1", Name: "prod", JiraProject: "REL"},
+		Window: 24 * time.Hour,
+		Failures: []*model.StateChangeEvent{
+			{ID: "event1", ReleaseID: "release1", OldState: model.RingStateReleaseInProgress, NewState: model.RingStateReleaseFailed},
+			{ID: "event2", ReleaseID: "release2", OldState: model.RingStateReleaseInProgress, NewState: model.RingStateReleaseFailed},
+		},
+		Reasons: map[string]int{},
+	}
+
+	t.Run("created", func(t *testing.T) {
+		client := &mockIssueClient{}
+		tracker := &ReleaseTracker{client: client, issueType: "Bug"}
+
+		issueURL, err := tracker.CreateFailureIssue(failures)
+		require.NoError(t, err)
+		require.Equal(t, "https://jira.example.com/browse/REL-1", issueURL)
+		require.Equal(t, []string{"Ring prod failed 2 releases within 1 day"}, client.Created)
+	})
+
+	t.Run("no project", func(t *testing.T) {
+		client := &mockIssueClient{}
+		tracker := &ReleaseTracker{client: client, issueType: "Bug"}
+
+		_, err := tracker.CreateFailureIssue(&model.RepeatedFailures{Ring: &model.Ring{Name: "prod"}})
+		require.EqualError(t, err, "ring prod has no Jira project")
+		require.Empty(t, client.Created)
+	})
+
+	t.Run("creation failure", func(t *testing.T) {
+		client := &mockIssueClient{CreateError: errors.New("unauthorized")}
+		tracker := &ReleaseTracker{client: client, issueType: "Bug"}
+
+		_, err := tracker.CreateFailureIssue(failures)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package maintenance looks up the infrastructure maintenance conflicting
+// with ring releases, from an external endpoint or from the internal
+// maintenance calendar of the server.
+package maintenance
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// HTTPChecker looks up maintenance conflicts from an external endpoint: a
+// model.MaintenanceConflictQuery is posted to its URL, which responds with a
+// model.MaintenanceConflictResponse listing the maintenance overlapping the
+// release, such as the changes of a change management system.
+type HTTPChecker struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPChecker creates a new HTTPChecker querying the given URL through the
+// given transport, or http.DefaultTransport if nil.
+func NewHTTPChecker(conflictURL string, transport http.RoundTripper) (*HTTPChecker, error) {
+	parsed, err := url.Parse(conflictURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse maintenance conflict url")
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errors.Errorf("maintenance conflict url must be an http or https URL, got %q", conflictURL)
+	}
+
+	return &HTTPChecker{
+		url:    conflictURL,
+		client: &http.Client{Timeout: 30 * time.Second, Transport: transport},
+	}, nil
+}
+
+// Conflicts returns the maintenance the endpoint reports overlapping the
+// queried release.
+func (c *HTTPChecker) Conflicts(query *model.MaintenanceConflictQuery) ([]*model.MaintenanceConflict, error) {
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode maintenance conflict query")
+	}
+
+	resp, err := c.client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query maintenance conflicts")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.Errorf("maintenance conflict url responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	response, err := model.MaintenanceConflictResponseFromReader(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode maintenance conflicts")
+	}
+	for _, conflict := range response.Conflicts {
+		if conflict.Name == "" {
+			return nil, errors.New("maintenance conflicts must have a name")
+		}
+	}
+
+	return response.Conflicts, nil
+}
+
+// Calendar is the internal maintenance calendar of the server, planned with
+// the maintenance windows given to the server.
+type Calendar struct {
+	windows []*model.MaintenanceWindow
+}
+
+// NewCalendar creates a new Calendar of the given maintenance windows.
+func NewCalendar(windows []*model.MaintenanceWindow) *Calendar {
+	return &Calendar{windows: windows}
+}
+
+// Conflicts returns the maintenance windows of the calendar overlapping the
+// queried release and affecting its installation groups.
+func (c *Calendar) Conflicts(query *model.MaintenanceConflictQuery) ([]*model.MaintenanceConflict, error) {
+	conflicts := []*model.MaintenanceConflict{}
+	for _, window := range c.windows {
+		if conflict := window.Conflict(query); conflict != nil {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// Checker looks up the maintenance conflicting with a release.
+type Checker interface {
+	Conflicts(query *model.MaintenanceConflictQuery) ([]*model.MaintenanceConflict, error)
+}
+
+// Checkers combines the conflicts of several checkers, failing if any of them
+// fails.
+type Checkers []Checker
+
+// Conflicts returns the conflicts of every checker.
+func (c Checkers) Conflicts(query *model.MaintenanceConflictQuery) ([]*model.MaintenanceConflict, error) {
+	conflicts := []*model.MaintenanceConflict{}
+	for _, checker := range c {
+		checkerConflicts, err := checker.Conflicts(query)
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, checkerConflicts...)
+	}
+
+	return conflicts, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package maintenance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPChecker(t *testing.T) {
+	var queried *model.MaintenanceConflictQuery
+	mux := http.NewServeMux()
+	mux.HandleFunc("/conflicts", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		queried = &model.MaintenanceConflictQuery{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(queried))
+		w.Write([]byte(`{"conflicts":[{"name":"db-upgrade","startAt":1000,"endAt":2000,"installationGroups":["group1"]}]}`))
+	})
+	mux.HandleFunc("/none", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"conflicts":[]}`))
+	})
+	mux.HandleFunc("/anonymous", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"conflicts":[{"startAt":1000,"endAt":2000}]}`))
+	})
+	mux.HandleFunc("/failing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ring := &model.Ring{ID: "ring1", Name: "ring-1", DesiredReleaseID: "release1"}
+	installationGroups := []*model.InstallationGroup{{ID: "ig1", Name: "group1", ProvisionerGroupID: "pg1", FailureDomain: "us-east-1a"}}
+	query := model.NewMaintenanceConflictQuery(ring, installationGroups, 500, 1500)
+
+	checker, err := NewHTTPChecker(ts.URL+"/conflicts", nil)
+	require.NoError(t, err)
+	conflicts, err := checker.Conflicts(query)
+	require.NoError(t, err)
+	require.Equal(t, []*model.MaintenanceConflict{{Name: "db-upgrade", StartAt: 1000, EndAt: 2000, InstallationGroups: []string{"group1"}}}, conflicts)
+	require.Equal(t, query, queried)
+
+	checker, err = NewHTTPChecker(ts.URL+"/none", nil)
+	require.NoError(t, err)
+	conflicts, err = checker.Conflicts(query)
+	require.NoError(t, err)
+	require.Empty(t, conflicts)
+
+	for _, path := range []string{"/anonymous", "/failing"} {
+		checker, err = NewHTTPChecker(ts.URL+path, nil)
+		require.NoError(t, err)
+		_, err = checker.Conflicts(query)
+		require.Error(t, err, path)
+	}
+
+	_, err = NewHTTPChecker("ftp://example.com", nil)
+	require.Error(t, err)
+}
+
+func TestCalendar(t *testing.T) {
+	start := time.Date(2024, 5, 4, 22, 0, 0, 0, time.UTC)
+	calendar := NewCalendar([]*model.MaintenanceWindow{
+		{Name: "db-upgrade", Start: start, End: start.Add(4 * time.Hour), InstallationGroups: []string{"group2"}},
+		{Name: "network", Start: start.Add(24 * time.Hour), End: start.Add(25 * time.Hour)},
+	})
+	ring := &model.Ring{ID: "ring1", Name: "ring-1"}
+	installationGroups := []*model.InstallationGroup{{ID: "ig1", Name: "group1"}, {ID: "ig2", Name: "group2"}}
+
+	t.Run("overlapping", func(t *testing.T) {
+		conflicts, err := calendar.Conflicts(model.NewMaintenanceConflictQuery(ring, installationGroups, start.Add(-time.Hour).UnixMilli(), start.Add(time.Hour).UnixMilli()))
+		require.NoError(t, err)
+		require.Len(t, conflicts, 1)
+		require.Equal(t, "db-upgrade", conflicts[0].Name)
+		require.Equal(t, []string{"group2"}, conflicts[0].InstallationGroups)
+	})
+
+	t.Run("not affected", func(t *testing.T) {
+		conflicts, err := calendar.Conflicts(model.NewMaintenanceConflictQuery(ring, installationGroups[:1], start.UnixMilli(), start.Add(time.Hour).UnixMilli()))
+		require.NoError(t, err)
+		require.Empty(t, conflicts)
+	})
+
+	t.Run("after the maintenance", func(t *testing.T) {
+		conflicts, err := calendar.Conflicts(model.NewMaintenanceConflictQuery(ring, installationGroups, start.Add(4*time.Hour).UnixMilli(), start.Add(5*time.Hour).UnixMilli()))
+		require.NoError(t, err)
+		require.Empty(t, conflicts)
+	})
+
+	t.Run("combined", func(t *testing.T) {
+		conflicts, err := Checkers{calendar, calendar}.Conflicts(model.NewMaintenanceConflictQuery(ring, installationGroups, start.UnixMilli(), start.Add(48*time.Hour).UnixMilli()))
+		require.NoError(t, err)
+		require.Len(t, conflicts, 4)
+	})
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+)
+
+// AlertRuleOptions configures the Prometheus alerting rules generated for a
+// fleet of rings.
+type AlertRuleOptions struct {
+	// LabeledRings are the ring names the server uses as metric labels. All
+	// other rings share the "other" label.
+	LabeledRings []string
+	// SoakTimeDefaults resolve the soak times of the installation groups
+	// that do not set one.
+	SoakTimeDefaults model.SoakTimeDefaults
+	// InstallationGroupReleaseAllowance is the time the release of an
+	// installation group is expected to take at most, excluding its soak.
+	InstallationGroupReleaseAllowance time.Duration
+	// FailureRateThreshold is the ratio of failed ring releases over
+	// FailureRateWindow above which an alert fires.
+	FailureRateThreshold float64
+	FailureRateWindow    time.Duration
+	// SupervisorStallThreshold is the time after which a supervisor that has
+	// not completed a cycle is considered stalled.
+	SupervisorStallThreshold time.Duration
+}
+
+// AlertRuleFile is a Prometheus alerting rules file.
+type AlertRuleFile struct {
+	Groups []*AlertRuleGroup `yaml:"groups"`
+}
+
+// AlertRuleGroup is a group of Prometheus alerting rules.
+type AlertRuleGroup struct {
+	Name  string       `yaml:"name"`
+	Rules []*AlertRule `yaml:"rules"`
+}
+
+// AlertRule is a Prometheus alerting rule.
+type AlertRule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// NewAlertRules returns the alerting rules for the given rings: a stuck
+// release alert for each labeled ring, firing once a release has taken longer
+// than its installation groups are expected to, a failure rate alert for each
+// ring label, and a stall alert for the supervisors.
+func NewAlertRules(rings []*model.Ring, options AlertRuleOptions) *AlertRuleFile {
+	m := &Metrics{labeledRings: make(map[string]bool, len(options.LabeledRings))}
+	for _, ring := range options.LabeledRings {
+		m.labeledRings[ring] = true
+	}
+
+	var stuckRules, failureRules []*AlertRule
+	ringLabels := make(map[string]bool)
+	for _, ring := range sortedRings(rings) {
+		label := m.ringLabel(ring.Name)
+		if label != otherRingLabel {
+			stuckRules = append(stuckRules, stuckReleaseRule(ring, options))
+		}
+		if ringLabels[label] {
+			continue
+		}
+		ringLabels[label] = true
+		failureRules = append(failureRules, failureRateRule(label, options))
+	}
+
+	file := &AlertRuleFile{}
+	if len(stuckRules) > 0 {
+		file.Groups = append(file.Groups, &AlertRuleGroup{Name: "elrond-stuck-releases", Rules: stuckRules})
+	}
+	if len(failureRules) > 0 {
+		file.Groups = append(file.Groups, &AlertRuleGroup{Name: "elrond-release-failures", Rules: failureRules})
+	}
+	file.Groups = append(file.Groups, &AlertRuleGroup{Name: "elrond-supervisors", Rules: []*AlertRule{supervisorStallRule(options)}})
+
+	return file
+}
+
+// ExpectedReleaseDuration returns the time the release of the given ring is
+// expected to take at most: the release allowance and soak time of each of
+// its installation groups, which are released one after the other.
+func ExpectedReleaseDuration(ring *model.Ring, options AlertRuleOptions) time.Duration {
+	if len(ring.InstallationGroups) == 0 {
+		return options.InstallationGroupReleaseAllowance
+	}
+
+	var expected time.Duration
+	for _, installationGroup := range ring.InstallationGroups {
+		soakTime := options.SoakTimeDefaults.ResolveInstallationGroupSoakTime(installationGroup, ring, nil)
+		expected += options.InstallationGroupReleaseAllowance + time.Duration(soakTime)*time.Second
+	}
+
+	return expected
+}
+
+func stuckReleaseRule(ring *model.Ring, options AlertRuleOptions) *AlertRule {
+	expected := ExpectedReleaseDuration(ring, options)
+	metric := fmt.Sprintf(`%s_ring_release_start_timestamp_seconds{ring="%s"}`, namespace, escapeLabelValue(ring.Name))
+
+	return &AlertRule{
+		Alert: "ElrondRingReleaseStuck",
+		Expr:  fmt.Sprintf("%s > 0 and time() - %s > %d", metric, metric, int64(expected.Seconds())),
+		Labels: map[string]string{
+			"severity": "warning",
+			"ring":     ring.Name,
+		},
+		Annotations: map[string]string{
+			"summary":     fmt.Sprintf("The release of ring %s is taking longer than expected", ring.Name),
+			"description": fmt.Sprintf("The release of ring %s (%s) started more than %s ago; its %d installation groups are expected to release and soak within that time.", ring.Name, ring.ID, promDuration(expected), len(ring.InstallationGroups)),
+		},
+	}
+}
+
+func failureRateRule(label string, options AlertRuleOptions) *AlertRule {
+	window := promDuration(options.FailureRateWindow)
+	selector := fmt.Sprintf(`ring="%s"`, escapeLabelValue(label))
+	releases := fmt.Sprintf("sum(increase(%s_ring_release_duration_seconds_count{%s}[%s]))", namespace, selector, window)
+	failures := fmt.Sprintf(`sum(increase(%s_ring_release_duration_seconds_count{%s,outcome="%s"}[%s]))`, namespace, selector, OutcomeFailure, window)
+
+	return &AlertRule{
+		Alert: "ElrondRingReleaseFailureRate",
+		Expr:  fmt.Sprintf("%s / %s > %g", failures, releases, options.FailureRateThreshold),
+		Labels: map[string]string{
+			"severity": "warning",
+			"ring":     label,
+		},
+		Annotations: map[string]string{
+			"summary":     fmt.Sprintf("Ring %s releases are failing", label),
+			"description": fmt.Sprintf("More than %g%% of the releases of ring %s failed over the last %s.", options.FailureRateThreshold*100, label, window),
+		},
+	}
+}
+
+func supervisorStallRule(options AlertRuleOptions) *AlertRule {
+	return &AlertRule{
+		Alert: "ElrondSupervisorStalled",
+		Expr:  fmt.Sprintf("time() - %s_supervisor_last_success_timestamp_seconds > %d", namespace, int64(options.SupervisorStallThreshold.Seconds())),
+		Labels: map[string]string{
+			"severity": "critical",
+		},
+		Annotations: map[string]string{
+			"summary":     "An elrond supervisor is stalled",
+			"description": fmt.Sprintf("The {{ $labels.supervisor }} supervisor of {{ $labels.instance }} has not completed a cycle for more than %s.", promDuration(options.SupervisorStallThreshold)),
+		},
+	}
+}
+
+// sortedRings returns the rings by priority then name, so that the generated
+// rules are stable.
+func sortedRings(rings []*model.Ring) []*model.Ring {
+	sorted := make([]*model.Ring, len(rings))
+	copy(sorted, rings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority < sorted[j].Priority
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted
+}
+
+// promDuration formats the given duration, rounded to the second, in the
+// Prometheus duration format.
+func promDuration(d time.Duration) string {
+	seconds := int64(d.Round(time.Second).Seconds())
+	if seconds <= 0 {
+		return "0s"
+	}
+
+	var b strings.Builder
+	for _, unit := range []struct {
+		suffix  string
+		seconds int64
+	}{{"d", 86400}, {"h", 3600}, {"m", 60}, {"s", 1}} {
+		if seconds >= unit.seconds {
+			fmt.Fprintf(&b, "%d%s", seconds/unit.seconds, unit.suffix)
+			seconds %= unit.seconds
+		}
+	}
+
+	return b.String()
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAlertRules(t *testing.T) {
+	options := AlertRuleOptions{
+		LabeledRings:                      []string{"production", "staging"},
+		SoakTimeDefaults:                  model.SoakTimeDefaults{Server: 3600},
+		InstallationGroupReleaseAllowance: 30 * time.Minute,
+		FailureRateThreshold:              0.25,
+		FailureRateWindow:                 7 * 24 * time.Hour,
+		SupervisorStallThreshold:          10 * time.Minute,
+	}
+	rings := []*model.Ring{
+		{ID: "ring2", Name: "production", Priority: 2, InstallationGroups: []*model.InstallationGroup{{SoakTime: 600}, {}}},
+		{ID: "ring1", Name: "staging", Priority: 1},
+		{ID: "ring3", Name: "canary-a", Priority: 3},
+		{ID: "ring4", Name: "canary-b", Priority: 3},
+	}
+
+	file := NewAlertRules(rings, options)
+	require.Len(t, file.Groups, 3)
+
+	stuck := file.Groups[0].Rules
+	require.Len(t, stuck, 2)
+	require.Equal(t, "staging", stuck[0].Labels["ring"])
+	require.Equal(t, `elrond_ring_release_start_timestamp_seconds{ring="staging"} > 0 and time() - elrond_ring_release_start_timestamp_seconds{ring="staging"} > 1800`, stuck[0].Expr)
+	require.Equal(t, "production", stuck[1].Labels["ring"])
+	require.Contains(t, stuck[1].Expr, "> 7800")
+	require.Contains(t, stuck[1].Annotations["description"], "2h10m")
+
+	failures := file.Groups[1].Rules
+	require.Len(t, failures, 3)
+	require.Equal(t, "staging", failures[0].Labels["ring"])
+	require.Equal(t, "production", failures[1].Labels["ring"])
+	require.Equal(t, otherRingLabel, failures[2].Labels["ring"])
+	require.Equal(t, `sum(increase(elrond_ring_release_duration_seconds_count{ring="other",outcome="failure"}[7d])) / sum(increase(elrond_ring_release_duration_seconds_count{ring="other"}[7d])) > 0.25`, failures[2].Expr)
+
+	stall := file.Groups[2].Rules
+	require.Len(t, stall, 1)
+	require.Equal(t, "time() - elrond_supervisor_last_success_timestamp_seconds > 600", stall[0].Expr)
+}
+
+func TestNewAlertRulesWithoutRings(t *testing.T) {
+	file := NewAlertRules(nil, AlertRuleOptions{SupervisorStallThreshold: time.Minute})
+	require.Len(t, file.Groups, 1)
+	require.Equal(t, "elrond-supervisors", file.Groups[0].Name)
+}
+
+func TestPromDuration(t *testing.T) {
+	require.Equal(t, "0s", promDuration(0))
+	require.Equal(t, "45s", promDuration(45*time.Second))
+	require.Equal(t, "1h30m", promDuration(90*time.Minute))
+	require.Equal(t, "1d2h5s", promDuration(26*time.Hour+5*time.Second))
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package metrics exposes the elrond metrics, through Prometheus or StatsD.
+package metrics
+
+import "time"
+
+const (
+	// OutcomeSuccess labels an observation of a step that completed.
+	OutcomeSuccess = "success"
+	// OutcomeFailure labels an observation of a step that failed.
+	OutcomeFailure = "failure"
+
+	// LockAcquired labels the rows pending work locked by a supervisor.
+	LockAcquired = "acquired"
+	// LockContended labels the rows pending work a supervisor found locked
+	// by others.
+	LockContended = "contended"
+
+	// BackendPrometheus serves the metrics on /metrics for Prometheus to
+	// scrape.
+	BackendPrometheus = "prometheus"
+	// BackendStatsD sends the metrics to a StatsD or DogStatsD agent.
+	BackendStatsD = "statsd"
+
+	// otherRingLabel is used for rings that are not in the labeled rings allowlist,
+	// keeping the cardinality of the ring label bounded.
+	otherRingLabel = "other"
+
+	namespace = "elrond"
+)
+
+// The names of the elrond metrics, without the elrond namespace.
+const (
+	ringReleaseDuration   = "ring_release_duration_seconds"
+	ringSoakDuration      = "ring_soak_duration_seconds"
+	ringReleaseStart      = "ring_release_start_timestamp_seconds"
+	lockAcquisitions      = "lock_acquisitions_total"
+	supervisorQueueDepth  = "supervisor_queue_depth"
+	supervisorQueueWait   = "supervisor_queue_wait_seconds"
+	supervisorCycles      = "supervisor_cycles_total"
+	supervisorLastSuccess = "supervisor_last_success_timestamp_seconds"
+	supervisorPanics      = "supervisor_panics_total"
+)
+
+// Labels are the label, or tag, values of an observation, by name.
+type Labels map[string]string
+
+// Backend emits the elrond metrics to a monitoring system. Metrics are named
+// without the elrond namespace, which each backend adds in its own format.
+type Backend interface {
+	// Count adds value to the named counter.
+	Count(name string, value float64, labels Labels)
+	// Gauge sets the named gauge to value.
+	Gauge(name string, value float64, labels Labels)
+	// AddGauge adds delta to the named gauge.
+	AddGauge(name string, delta float64, labels Labels)
+	// Observe records a duration in the named histogram. The exemplar, when
+	// set, links the observation back to what produced it.
+	Observe(name string, duration time.Duration, labels Labels, exemplar Labels)
+}
+
+// Metrics records the elrond metrics with a backend.
+type Metrics struct {
+	backend      Backend
+	labeledRings map[string]bool
+}
+
+// New creates the elrond metrics, recorded with the given backend. Only ring
+// names in labeledRings are used as the ring label value; every other ring is
+// reported as "other".
+func New(labeledRings []string, backend Backend) *Metrics {
+	m := &Metrics{
+		backend:      backend,
+		labeledRings: make(map[string]bool, len(labeledRings)),
+	}
+
+	for _, ring := range labeledRings {
+		m.labeledRings[ring] = true
+	}
+
+	return m
+}
+
+// ObserveRingReleaseDuration records the duration of a ring release.
+func (m *Metrics) ObserveRingReleaseDuration(ringName, outcome, releaseID string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.backend.Observe(ringReleaseDuration, duration, Labels{"ring": m.ringLabel(ringName), "outcome": outcome}, releaseExemplar(releaseID))
+}
+
+// ObserveRingSoakDuration records the duration of a ring soaking period.
+func (m *Metrics) ObserveRingSoakDuration(ringName, outcome, releaseID string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.backend.Observe(ringSoakDuration, duration, Labels{"ring": m.ringLabel(ringName), "outcome": outcome}, releaseExemplar(releaseID))
+}
+
+// ObserveLockAcquisitions records the rows of the given resource type a
+// supervisor locked, and those it found locked by others, when acquiring its
+// batch of work.
+func (m *Metrics) ObserveLockAcquisitions(resource string, acquired, contended int) {
+	if m == nil {
+		return
+	}
+	m.backend.Count(lockAcquisitions, float64(acquired), Labels{"resource": resource, "outcome": LockAcquired})
+	m.backend.Count(lockAcquisitions, float64(contended), Labels{"resource": resource, "outcome": LockContended})
+}
+
+// SetRingReleaseStart records the start of the release in progress of the
+// given ring, or that it is not releasing when start is zero. Rings that are
+// not labeled are not recorded, as they would overwrite each other.
+func (m *Metrics) SetRingReleaseStart(ringName string, start time.Time) {
+	if m == nil || !m.labeledRings[ringName] {
+		return
+	}
+	if start.IsZero() {
+		m.backend.Gauge(ringReleaseStart, 0, Labels{"ring": ringName})
+		return
+	}
+	m.backend.Gauge(ringReleaseStart, float64(start.UnixNano())/float64(time.Second), Labels{"ring": ringName})
+}
+
+// AddSupervisorQueueDepth adds the given delta to the number of rows of the
+// given resource type queued for the given ring.
+func (m *Metrics) AddSupervisorQueueDepth(resource, ringName string, delta int) {
+	if m == nil {
+		return
+	}
+	m.backend.AddGauge(supervisorQueueDepth, float64(delta), Labels{"resource": resource, "ring": m.ringLabel(ringName)})
+}
+
+// ObserveSupervisorQueueWait records the time a row of the given resource
+// type queued for the given ring waited before being worked on.
+func (m *Metrics) ObserveSupervisorQueueWait(resource, ringName string, wait time.Duration) {
+	if m == nil {
+		return
+	}
+	m.backend.Observe(supervisorQueueWait, wait, Labels{"resource": resource, "ring": m.ringLabel(ringName)}, nil)
+}
+
+// ObserveSupervisorCycle records a cycle of the given supervisor, completed
+// at the given time unless it failed with err.
+func (m *Metrics) ObserveSupervisorCycle(supervisor string, err error, at time.Time) {
+	if m == nil {
+		return
+	}
+	if err != nil {
+		m.backend.Count(supervisorCycles, 1, Labels{"supervisor": supervisor, "outcome": OutcomeFailure})
+		return
+	}
+	m.backend.Count(supervisorCycles, 1, Labels{"supervisor": supervisor, "outcome": OutcomeSuccess})
+	m.backend.Gauge(supervisorLastSuccess, float64(at.UnixNano())/float64(time.Second), Labels{"supervisor": supervisor})
+}
+
+// ObserveSupervisorPanic records a panic recovered from while supervising a
+// row of the given resource type.
+func (m *Metrics) ObserveSupervisorPanic(resource string) {
+	if m == nil {
+		return
+	}
+	m.backend.Count(supervisorPanics, 1, Labels{"resource": resource})
+}
+
+func (m *Metrics) ringLabel(ringName string) string {
+	if m.labeledRings[ringName] {
+		return ringName
+	}
+
+	return otherRingLabel
+}
+
+// releaseExemplar returns the exemplar attaching the given release to an
+// observation, so that dashboards can link a bucket back to the release that
+// produced it.
+func releaseExemplar(releaseID string) Labels {
+	if releaseID == "" {
+		return nil
+	}
+
+	return Labels{"release_id": releaseID}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingLabelCardinality(t *testing.T) {
+	p := NewPrometheus()
+	m := New([]string{"ring-1"}, p)
+
+	m.ObserveRingReleaseDuration("ring-1", OutcomeSuccess, "release1", time.Minute)
+	m.ObserveRingReleaseDuration("ring-2", OutcomeSuccess, "release1", time.Minute)
+	m.ObserveRingReleaseDuration("ring-3", OutcomeFailure, "release1", time.Minute)
+	m.ObserveRingSoakDuration("ring-1", OutcomeSuccess, "", time.Hour)
+
+	require.Equal(t, 3, testutil.CollectAndCount(p.RingReleaseDuration))
+	require.Equal(t, 1, testutil.CollectAndCount(p.RingSoakDuration))
+	require.Equal(t, "ring-1", m.ringLabel("ring-1"))
+	require.Equal(t, otherRingLabel, m.ringLabel("ring-2"))
+}
+
+func TestLockAcquisitions(t *testing.T) {
+	p := NewPrometheus()
+	m := New(nil, p)
+
+	m.ObserveLockAcquisitions("ring", 3, 1)
+	m.ObserveLockAcquisitions("ring", 2, 0)
+	m.ObserveLockAcquisitions("installationgroup", 0, 4)
+
+	require.Equal(t, float64(5), testutil.ToFloat64(p.LockAcquisitions.WithLabelValues("ring", LockAcquired)))
+	require.Equal(t, float64(1), testutil.ToFloat64(p.LockAcquisitions.WithLabelValues("ring", LockContended)))
+	require.Equal(t, float64(4), testutil.ToFloat64(p.LockAcquisitions.WithLabelValues("installationgroup", LockContended)))
+}
+
+func TestSupervisorQueue(t *testing.T) {
+	p := NewPrometheus()
+	m := New([]string{"ring-1"}, p)
+
+	m.AddSupervisorQueueDepth("installationgroup", "ring-1", 2)
+	m.AddSupervisorQueueDepth("installationgroup", "ring-2", 1)
+	m.AddSupervisorQueueDepth("installationgroup", "ring-1", -1)
+	m.ObserveSupervisorQueueWait("installationgroup", "ring-1", time.Second)
+
+	require.Equal(t, float64(1), testutil.ToFloat64(p.SupervisorQueueDepth.WithLabelValues("installationgroup", "ring-1")))
+	require.Equal(t, float64(1), testutil.ToFloat64(p.SupervisorQueueDepth.WithLabelValues("installationgroup", otherRingLabel)))
+	require.Equal(t, 1, testutil.CollectAndCount(p.SupervisorQueueWait))
+}
+
+func TestSupervisorCycles(t *testing.T) {
+	p := NewPrometheus()
+	m := New(nil, p)
+
+	m.ObserveSupervisorCycle("ring", nil, time.Unix(100, 0))
+	m.ObserveSupervisorCycle("ring", errors.New("failed"), time.Unix(200, 0))
+	m.ObserveSupervisorCycle("ring", nil, time.Unix(300, 0))
+
+	require.Equal(t, float64(2), testutil.ToFloat64(p.SupervisorCycles.WithLabelValues("ring", OutcomeSuccess)))
+	require.Equal(t, float64(1), testutil.ToFloat64(p.SupervisorCycles.WithLabelValues("ring", OutcomeFailure)))
+	require.Equal(t, float64(300), testutil.ToFloat64(p.SupervisorLastSuccess.WithLabelValues("ring")))
+}
+
+func TestRingReleaseStart(t *testing.T) {
+	p := NewPrometheus()
+	m := New([]string{"ring-1"}, p)
+
+	m.SetRingReleaseStart("ring-1", time.Unix(100, 0))
+	m.SetRingReleaseStart("ring-2", time.Unix(200, 0))
+	require.Equal(t, float64(100), testutil.ToFloat64(p.RingReleaseStart.WithLabelValues("ring-1")))
+	require.Equal(t, 1, testutil.CollectAndCount(p.RingReleaseStart))
+
+	m.SetRingReleaseStart("ring-1", time.Time{})
+	require.Zero(t, testutil.ToFloat64(p.RingReleaseStart.WithLabelValues("ring-1")))
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.ObserveRingReleaseDuration("ring-1", OutcomeSuccess, "release1", time.Minute)
+	m.ObserveRingSoakDuration("ring-1", OutcomeSuccess, "release1", time.Minute)
+	m.ObserveLockAcquisitions("ring", 1, 1)
+	m.AddSupervisorQueueDepth("ring", "ring-1", 1)
+	m.ObserveSupervisorQueueWait("ring", "ring-1", time.Second)
+	m.ObserveSupervisorCycle("ring", nil, time.Now())
+	m.SetRingReleaseStart("ring-1", time.Now())
+	m.ObserveSupervisorPanic("ring")
+}
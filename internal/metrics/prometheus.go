@@ -0,0 +1,182 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus is the backend holding the elrond metrics in prometheus
+// collectors, to be scraped from its registry.
+type Prometheus struct {
+	registry *prometheus.Registry
+
+	counters   map[string]*prometheus.CounterVec
+	gauges     map[string]*prometheus.GaugeVec
+	histograms map[string]*prometheus.HistogramVec
+
+	RingReleaseDuration *prometheus.HistogramVec
+	RingSoakDuration    *prometheus.HistogramVec
+	RingReleaseStart    *prometheus.GaugeVec
+	LockAcquisitions    *prometheus.CounterVec
+
+	SupervisorQueueDepth *prometheus.GaugeVec
+	SupervisorQueueWait  *prometheus.HistogramVec
+
+	SupervisorCycles      *prometheus.CounterVec
+	SupervisorLastSuccess *prometheus.GaugeVec
+	SupervisorPanics      *prometheus.CounterVec
+}
+
+// NewPrometheus creates the prometheus backend and registers its collectors,
+// along with the Go and process ones.
+func NewPrometheus() *Prometheus {
+	p := &Prometheus{
+		registry: prometheus.NewRegistry(),
+
+		RingReleaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "ring",
+			Name:      "release_duration_seconds",
+			Help:      "The duration of ring releases, from release request to the end of the installation group rollout.",
+			Buckets:   prometheus.ExponentialBuckets(60, 2, 10),
+		}, []string{"ring", "outcome"}),
+
+		RingSoakDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "ring",
+			Name:      "soak_duration_seconds",
+			Help:      "The duration of ring soaking periods.",
+			Buckets:   prometheus.ExponentialBuckets(60, 2, 10),
+		}, []string{"ring", "outcome"}),
+
+		RingReleaseStart: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "ring",
+			Name:      "release_start_timestamp_seconds",
+			Help:      "The time the release in progress of each labeled ring started, as a Unix timestamp, or 0 when the ring is not releasing.",
+		}, []string{"ring"}),
+
+		LockAcquisitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "lock",
+			Name:      "acquisitions_total",
+			Help:      "The rows pending work locked by the supervisors, or found locked by other servers, by resource type.",
+		}, []string{"resource", "outcome"}),
+
+		SupervisorQueueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "supervisor",
+			Name:      "queue_depth",
+			Help:      "The rings, or installation groups, queued or being worked on by the supervisors, by resource type and ring.",
+		}, []string{"resource", "ring"}),
+
+		SupervisorQueueWait: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "supervisor",
+			Name:      "queue_wait_seconds",
+			Help:      "The time rings, or installation groups, spent queued before being worked on by the supervisors, by resource type and ring.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+		}, []string{"resource", "ring"}),
+
+		SupervisorCycles: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "supervisor",
+			Name:      "cycles_total",
+			Help:      "The cycles run by each supervisor, by outcome.",
+		}, []string{"supervisor", "outcome"}),
+
+		SupervisorLastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "supervisor",
+			Name:      "last_success_timestamp_seconds",
+			Help:      "The time each supervisor last completed a cycle, as a Unix timestamp.",
+		}, []string{"supervisor"}),
+
+		SupervisorPanics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "supervisor",
+			Name:      "panics_total",
+			Help:      "The panics recovered from while supervising rings or installation groups, by resource type.",
+		}, []string{"resource"}),
+	}
+
+	p.counters = map[string]*prometheus.CounterVec{
+		lockAcquisitions: p.LockAcquisitions,
+		supervisorCycles: p.SupervisorCycles,
+		supervisorPanics: p.SupervisorPanics,
+	}
+	p.gauges = map[string]*prometheus.GaugeVec{
+		ringReleaseStart:      p.RingReleaseStart,
+		supervisorQueueDepth:  p.SupervisorQueueDepth,
+		supervisorLastSuccess: p.SupervisorLastSuccess,
+	}
+	p.histograms = map[string]*prometheus.HistogramVec{
+		ringReleaseDuration: p.RingReleaseDuration,
+		ringSoakDuration:    p.RingSoakDuration,
+		supervisorQueueWait: p.SupervisorQueueWait,
+	}
+
+	p.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		p.RingReleaseDuration,
+		p.RingSoakDuration,
+		p.RingReleaseStart,
+		p.LockAcquisitions,
+		p.SupervisorQueueDepth,
+		p.SupervisorQueueWait,
+		p.SupervisorCycles,
+		p.SupervisorLastSuccess,
+		p.SupervisorPanics,
+	)
+
+	return p
+}
+
+// Registry returns the registry holding the elrond collectors.
+func (p *Prometheus) Registry() *prometheus.Registry {
+	return p.registry
+}
+
+// Count adds value to the named counter.
+func (p *Prometheus) Count(name string, value float64, labels Labels) {
+	if counter, ok := p.counters[name]; ok {
+		counter.With(prometheus.Labels(labels)).Add(value)
+	}
+}
+
+// Gauge sets the named gauge to value.
+func (p *Prometheus) Gauge(name string, value float64, labels Labels) {
+	if gauge, ok := p.gauges[name]; ok {
+		gauge.With(prometheus.Labels(labels)).Set(value)
+	}
+}
+
+// AddGauge adds delta to the named gauge.
+func (p *Prometheus) AddGauge(name string, delta float64, labels Labels) {
+	if gauge, ok := p.gauges[name]; ok {
+		gauge.With(prometheus.Labels(labels)).Add(delta)
+	}
+}
+
+// Observe records a duration in the named histogram, with the exemplar if
+// set.
+func (p *Prometheus) Observe(name string, duration time.Duration, labels Labels, exemplar Labels) {
+	histogram, ok := p.histograms[name]
+	if !ok {
+		return
+	}
+
+	observer := histogram.With(prometheus.Labels(labels))
+	if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok && len(exemplar) > 0 {
+		exemplarObserver.ObserveWithExemplar(duration.Seconds(), prometheus.Labels(exemplar))
+		return
+	}
+
+	observer.Observe(duration.Seconds())
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package metrics
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StatsD is the backend sending the elrond metrics over UDP to a StatsD
+// agent, in the DogStatsD format: labels are sent as tags, as understood by
+// the Datadog agent and by StatsD servers supporting tags, such as Telegraf.
+// Metrics are named elrond.<name>, e.g. elrond.supervisor_cycles_total.
+type StatsD struct {
+	conn net.Conn
+	tags []string
+
+	// gauges holds the value of the gauges changed with AddGauge, as StatsD
+	// agents do not agree on relative gauge updates.
+	gauges     map[string]float64
+	gaugesLock sync.Mutex
+}
+
+// NewStatsD creates the StatsD backend sending the metrics to the agent at
+// the given host:port, with the given constant tags, such as env:production,
+// added to every metric.
+func NewStatsD(address string, tags []string) (*StatsD, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, errors.Wrap(err, "invalid statsd address")
+	}
+	for _, tag := range tags {
+		if tag == "" || strings.ContainsAny(tag, "|,#") {
+			return nil, errors.Errorf("invalid statsd tag %q", tag)
+		}
+	}
+
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the statsd agent")
+	}
+
+	return &StatsD{
+		conn:   conn,
+		tags:   tags,
+		gauges: make(map[string]float64),
+	}, nil
+}
+
+// Close closes the connection to the StatsD agent.
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
+
+// Count adds value to the named counter.
+func (s *StatsD) Count(name string, value float64, labels Labels) {
+	s.send(name, value, "c", labels)
+}
+
+// Gauge sets the named gauge to value.
+func (s *StatsD) Gauge(name string, value float64, labels Labels) {
+	s.send(name, value, "g", labels)
+}
+
+// AddGauge adds delta to the named gauge, sending its new value.
+func (s *StatsD) AddGauge(name string, delta float64, labels Labels) {
+	key := name + "|" + strings.Join(s.formatTags(labels), ",")
+
+	s.gaugesLock.Lock()
+	s.gauges[key] += delta
+	value := s.gauges[key]
+	s.gaugesLock.Unlock()
+
+	s.send(name, value, "g", labels)
+}
+
+// Observe records a duration, in seconds, in the named histogram. StatsD has
+// no exemplars, so the exemplar is dropped.
+func (s *StatsD) Observe(name string, duration time.Duration, labels Labels, _ Labels) {
+	s.send(name, duration.Seconds(), "h", labels)
+}
+
+// send writes a metric to the agent. Metrics are sent on a best effort
+// basis, like any StatsD client does, so failures are ignored.
+func (s *StatsD) send(name string, value float64, metricType string, labels Labels) {
+	var line strings.Builder
+	line.WriteString(namespace)
+	line.WriteString(".")
+	line.WriteString(name)
+	line.WriteString(":")
+	line.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	line.WriteString("|")
+	line.WriteString(metricType)
+	if tags := s.formatTags(labels); len(tags) > 0 {
+		line.WriteString("|#")
+		line.WriteString(strings.Join(tags, ","))
+	}
+
+	_, _ = s.conn.Write([]byte(line.String()))
+}
+
+// formatTags returns the constant tags followed by the labels as name:value
+// tags, sorted by name. Characters separating the fields of the DogStatsD
+// format are replaced in label values.
+func (s *StatsD) formatTags(labels Labels) []string {
+	tags := make([]string, 0, len(s.tags)+len(labels))
+	tags = append(tags, s.tags...)
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tags = append(tags, name+":"+tagReplacer.Replace(labels[name]))
+	}
+
+	return tags
+}
+
+var tagReplacer = strings.NewReplacer("|", "_", ",", "_", "#", "_")
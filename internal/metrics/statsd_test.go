@@ -0,0 +1,68 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package metrics
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsD(t *testing.T) {
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer agent.Close()
+
+	receive := func(t *testing.T) string {
+		buffer := make([]byte, 1024)
+		require.NoError(t, agent.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := agent.ReadFrom(buffer)
+		require.NoError(t, err)
+		return string(buffer[:n])
+	}
+
+	backend, err := NewStatsD(agent.LocalAddr().String(), []string{"env:test"})
+	require.NoError(t, err)
+	defer backend.Close()
+	m := New([]string{"ring-1"}, backend)
+
+	t.Run("histogram", func(t *testing.T) {
+		m.ObserveRingReleaseDuration("ring-2", OutcomeSuccess, "release1", 90*time.Second)
+		require.Equal(t, "elrond.ring_release_duration_seconds:90|h|#env:test,outcome:success,ring:other", receive(t))
+	})
+
+	t.Run("counter", func(t *testing.T) {
+		m.ObserveSupervisorCycle("ring", errors.New("failed"), time.Unix(100, 0))
+		require.Equal(t, "elrond.supervisor_cycles_total:1|c|#env:test,outcome:failure,supervisor:ring", receive(t))
+	})
+
+	t.Run("gauge", func(t *testing.T) {
+		m.SetRingReleaseStart("ring-1", time.Unix(100, 500000000))
+		require.Equal(t, "elrond.ring_release_start_timestamp_seconds:100.5|g|#env:test,ring:ring-1", receive(t))
+	})
+
+	t.Run("relative gauge", func(t *testing.T) {
+		m.AddSupervisorQueueDepth("ring", "ring-1", 2)
+		require.Equal(t, "elrond.supervisor_queue_depth:2|g|#env:test,resource:ring,ring:ring-1", receive(t))
+		m.AddSupervisorQueueDepth("ring", "ring-2", 1)
+		require.Equal(t, "elrond.supervisor_queue_depth:1|g|#env:test,resource:ring,ring:other", receive(t))
+		m.AddSupervisorQueueDepth("ring", "ring-1", -1)
+		require.Equal(t, "elrond.supervisor_queue_depth:1|g|#env:test,resource:ring,ring:ring-1", receive(t))
+	})
+}
+
+func TestNewStatsD(t *testing.T) {
+	_, err := NewStatsD("localhost", nil)
+	require.Error(t, err)
+
+	_, err = NewStatsD("localhost:8125", []string{"env:test|prod"})
+	require.Error(t, err)
+
+	_, err = NewStatsD("localhost:8125", []string{""})
+	require.Error(t, err)
+}
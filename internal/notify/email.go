@@ -0,0 +1,190 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// EmailConfig is the configuration of the SMTP server release notifications
+// are sent through.
+type EmailConfig struct {
+	// Server is the SMTP server address, as host:port.
+	Server   string
+	Username string
+	Password string
+	From     string
+}
+
+// TemplateStore is the store the notification templates are read from.
+type TemplateStore interface {
+	GetNotificationTemplates(filter *model.NotificationTemplateFilter) ([]*model.NotificationTemplate, error)
+}
+
+// EmailNotifier sends release notifications by email to the notification
+// emails of each ring.
+type EmailNotifier struct {
+	server    string
+	auth      smtp.Auth
+	from      string
+	templates TemplateStore
+	sendMail  func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailNotifier creates a new EmailNotifier.
+func NewEmailNotifier(config EmailConfig) (*EmailNotifier, error) {
+	host, _, err := net.SplitHostPort(config.Server)
+	if err != nil {
+		return nil, errors.Wrap(err, "smtp server must be host:port")
+	}
+	if _, err = mail.ParseAddress(config.From); err != nil {
+		return nil, errors.Wrap(err, "invalid from address")
+	}
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, host)
+	}
+
+	return &EmailNotifier{
+		server:   config.Server,
+		auth:     auth,
+		from:     config.From,
+		sendMail: smtp.SendMail,
+	}, nil
+}
+
+// SetTemplateStore sets the store of the notification templates the emails
+// of rings with a notification language are rendered with.
+func (n *EmailNotifier) SetTemplateStore(templates TemplateStore) {
+	n.templates = templates
+}
+
+// Notify emails the given notification to the notification emails of its
+// ring. Rings without notification emails are skipped. The subjects of the
+// notifications of rehearsal rings are flagged as such.
+func (n *EmailNotifier) Notify(notification *model.ReleaseNotification) error {
+	recipients := notification.Ring.NotificationEmails
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	from, err := mail.ParseAddress(n.from)
+	if err != nil {
+		return errors.Wrap(err, "invalid from address")
+	}
+
+	subject, body, err := n.localizedEmailContent(notification)
+	if err != nil {
+		return errors.Wrapf(err, "failed to render %s email", notification.Event)
+	}
+	if notification.Ring.Rehearsal {
+		subject = "[Rehearsal] " + subject
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Unix(0, notification.Timestamp).UTC().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	if err = n.sendMail(n.server, n.auth, from.Address, recipients, msg.Bytes()); err != nil {
+		return errors.Wrapf(err, "failed to send %s email", notification.Event)
+	}
+
+	return nil
+}
+
+// localizedEmailContent returns the subject and body of the email of a
+// notification, rendered with the notification template of the language of
+// its ring if there is one, or the built-in content otherwise. Templates
+// without a subject keep the built-in subject.
+func (n *EmailNotifier) localizedEmailContent(notification *model.ReleaseNotification) (string, string, error) {
+	subject, body := emailContent(notification)
+	if n.templates == nil || notification.Ring.NotificationLanguage == "" {
+		return subject, body, nil
+	}
+
+	templates, err := n.templates.GetNotificationTemplates(&model.NotificationTemplateFilter{
+		Channel: model.NotificationChannelEmail,
+		Event:   notification.Event,
+		PerPage: model.AllPerPage,
+	})
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to get notification templates")
+	}
+	notificationTemplate := model.FindNotificationTemplate(templates, notification.Ring.NotificationLanguage)
+	if notificationTemplate == nil {
+		return subject, body, nil
+	}
+
+	templateSubject, templateBody, err := notificationTemplate.Render(notification.TemplateData())
+	if err != nil {
+		return "", "", err
+	}
+	if strings.TrimSpace(templateSubject) != "" {
+		subject = sanitizeHeader(templateSubject)
+	}
+
+	return subject, templateBody, nil
+}
+
+// sanitizeHeader strips the line breaks of the given header value, to keep
+// free-form text, such as ring names, from injecting headers.
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+// emailContent returns the subject and body of the email of a notification.
+func emailContent(notification *model.ReleaseNotification) (string, string) {
+	ring := notification.Ring
+
+	release := "unknown release"
+	if notification.Release != nil {
+		release = fmt.Sprintf("%s:%s", notification.Release.Image, notification.Release.Version)
+	}
+
+	var summary string
+	switch notification.Event {
+	case model.NotificationReleaseStarted:
+		summary = "started releasing"
+	case model.NotificationReleaseCompleted:
+		summary = "completed the release of"
+	case model.NotificationReleaseFailed:
+		summary = "failed the release of"
+	default:
+		summary = notification.Event
+	}
+
+	// Ring names are free-form, so line breaks are stripped to keep them out
+	// of the headers.
+	subject := sanitizeHeader(fmt.Sprintf("[Elrond] Ring %s %s %s", ring.Name, summary, release))
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Ring %s (%s) %s %s.\n\n", ring.Name, ring.ID, summary, release)
+	fmt.Fprintf(&body, "State: %s -> %s\n", notification.OldState, notification.NewState)
+	fmt.Fprintf(&body, "Time: %s\n", time.Unix(0, notification.Timestamp).UTC().Format(time.RFC3339))
+	if ring.ReleaseImpactInstallations > 0 {
+		fmt.Fprintf(&body, "Impact: %d installations, %d customers\n", ring.ReleaseImpactInstallations, ring.ReleaseImpactCustomers)
+	}
+	if contacts := ring.Contacts(); contacts != "" {
+		fmt.Fprintf(&body, "Contacts: %s\n", contacts)
+	}
+
+	return subject, body.String()
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package notify
+
+import (
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEmailNotifier(t *testing.T) {
+	_, err := NewEmailNotifier(EmailConfig{Server: "smtp.example.com", From: "elrond@example.com"})
+	require.Error(t, err)
+
+	_, err = NewEmailNotifier(EmailConfig{Server: "smtp.example.com:587", From: "not an email"})
+	require.Error(t, err)
+
+	notifier, err := NewEmailNotifier(EmailConfig{Server: "smtp.example.com:587", Username: "user", Password: "password", From: "Elrond <elrond@example.com>"})
+	require.NoError(t, err)
+	require.NotNil(t, notifier.auth)
+}
+
+func TestEmailNotifierNotify(t *testing.T) {
+	notifier, err := NewEmailNotifier(EmailConfig{Server: "smtp.example.com:587", From: "Elrond <elrond@example.com>"})
+	require.NoError(t, err)
+
+	var sent []string
+	var sentTo [][]string
+	var sendErr error
+	notifier.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		require.Equal(t, "smtp.example.com:587", addr)
+		require.Equal(t, "elrond@example.com", from)
+		sent = append(sent, string(msg))
+		sentTo = append(sentTo, to)
+		return sendErr
+	}
+
+	notification := &model.ReleaseNotification{
+		Event:     model.NotificationReleaseFailed,
+		Ring:      &model.Ring{ID: "ring1", Name: "canary"},
+		Release:   &model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"},
+		OldState:  model.RingStateReleaseInProgress,
+		NewState:  model.RingStateReleaseFailed,
+		Timestamp: time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC).UnixNano(),
+	}
+
+	t.Run("no recipients", func(t *testing.T) {
+		require.NoError(t, notifier.Notify(notification))
+		require.Empty(t, sent)
+	})
+
+	t.Run("recipients", func(t *testing.T) {
+		notification.Ring.NotificationEmails = model.NotificationEmails{"a@example.com", "b@example.com"}
+
+		require.NoError(t, notifier.Notify(notification))
+		require.Len(t, sent, 1)
+		require.Equal(t, []string{"a@example.com", "b@example.com"}, sentTo[0])
+		require.Contains(t, sent[0], "To: a@example.com, b@example.com\r\n")
+		require.Contains(t, sent[0], "Subject: [Elrond] Ring canary failed the release of mattermost/mattermost-enterprise-edition:7.1.0\r\n")
+		require.Contains(t, sent[0], "State: release-in-progress -> release-failed\r\n")
+		require.Contains(t, sent[0], "Time: 2024-03-05T12:00:00Z\r\n")
+		require.NotContains(t, sent[0], "Contacts:")
+	})
+
+	t.Run("contacts", func(t *testing.T) {
+		sent = nil
+		notification.Ring.OwnerTeam = "Platform"
+		notification.Ring.SlackChannel = "#platform-alerts"
+		defer func() { notification.Ring.OwnerTeam, notification.Ring.SlackChannel = "", "" }()
+
+		require.NoError(t, notifier.Notify(notification))
+		require.Len(t, sent, 1)
+		require.Contains(t, sent[0], "Contacts: owner team Platform, Slack #platform-alerts\r\n")
+	})
+
+	t.Run("rehearsal", func(t *testing.T) {
+		sent = nil
+		notification.Ring.Rehearsal = true
+		defer func() { notification.Ring.Rehearsal = false }()
+
+		require.NoError(t, notifier.Notify(notification))
+		require.Len(t, sent, 1)
+		require.Contains(t, sent[0], "Subject: [Rehearsal] [Elrond] Ring canary failed the release of mattermost/mattermost-enterprise-edition:7.1.0\r\n")
+	})
+
+	t.Run("localized", func(t *testing.T) {
+		sent = nil
+		templates := &mockTemplateStore{templates: []*model.NotificationTemplate{
+			{Channel: model.NotificationChannelEmail, Event: model.NotificationReleaseFailed, Language: "de", Subject: "[Elrond] Release von {{.RingName}} fehlgeschlagen", Body: "{{.Release}} ist auf {{.RingName}} fehlgeschlagen."},
+		}}
+		notifier.SetTemplateStore(templates)
+		defer notifier.SetTemplateStore(nil)
+
+		notification.Ring.NotificationLanguage = "de-AT"
+		defer func() { notification.Ring.NotificationLanguage = "" }()
+		require.NoError(t, notifier.Notify(notification))
+		require.Len(t, sent, 1)
+		require.Contains(t, sent[0], "Subject: [Elrond] Release von canary fehlgeschlagen\r\n")
+		require.Contains(t, sent[0], "mattermost/mattermost-enterprise-edition:7.1.0 ist auf canary fehlgeschlagen.")
+
+		sent = nil
+		notification.Ring.NotificationLanguage = "fr"
+		require.NoError(t, notifier.Notify(notification))
+		require.Len(t, sent, 1)
+		require.Contains(t, sent[0], "Subject: [Elrond] Ring canary failed the release of mattermost/mattermost-enterprise-edition:7.1.0\r\n")
+
+		templates.err = errors.New("database unavailable")
+		require.Error(t, notifier.Notify(notification))
+	})
+
+	t.Run("send failure", func(t *testing.T) {
+		sendErr = errors.New("connection refused")
+		require.Error(t, notifier.Notify(notification))
+	})
+}
+
+type mockTemplateStore struct {
+	templates []*model.NotificationTemplate
+	err       error
+}
+
+func (s *mockTemplateStore) GetNotificationTemplates(filter *model.NotificationTemplateFilter) ([]*model.NotificationTemplate, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	var templates []*model.NotificationTemplate
+	for _, t := range s.templates {
+		if t.Channel == filter.Channel && t.Event == filter.Event {
+			templates = append(templates, t)
+		}
+	}
+
+	return templates, nil
+}
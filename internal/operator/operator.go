@@ -0,0 +1,196 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package operator mirrors Ring and RingRelease custom resources of a
+// Kubernetes cluster into the elrond API, reporting the state of the
+// resulting rings and releases back to their status.
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// Group is the API group of the elrond custom resources.
+	Group = "elrond.mattermost.com"
+	// Version is the API version of the elrond custom resources.
+	Version = "v1alpha1"
+	// Finalizer is set on Ring resources so that the ring is deleted from
+	// elrond before the resource is removed.
+	Finalizer = Group + "/ring"
+)
+
+var (
+	// RingResource is the resource of the Ring custom resources.
+	RingResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "rings"}
+	// RingReleaseResource is the resource of the RingRelease custom resources.
+	RingReleaseResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "ringreleases"}
+)
+
+// queueItem identifies a custom resource to reconcile.
+type queueItem struct {
+	resource schema.GroupVersionResource
+	key      string
+}
+
+// Operator watches the elrond custom resources and mirrors them into the
+// elrond API.
+type Operator struct {
+	dynamicClient dynamic.Interface
+	client        *model.Client
+	namespace     string
+	resync        time.Duration
+	logger        log.FieldLogger
+
+	informers map[schema.GroupVersionResource]cache.SharedIndexInformer
+	queue     workqueue.RateLimitingInterface
+}
+
+// NewOperator creates a new Operator watching the given namespace, or every
+// namespace if empty. Every resource is reconciled again after the resync
+// period so that the state of the rings is reported even without changes to
+// the resources.
+func NewOperator(dynamicClient dynamic.Interface, client *model.Client, namespace string, resync time.Duration, logger log.FieldLogger) *Operator {
+	return &Operator{
+		dynamicClient: dynamicClient,
+		client:        client,
+		namespace:     namespace,
+		resync:        resync,
+		logger:        logger.WithField("operator", "elrond"),
+		queue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "elrond"),
+	}
+}
+
+// Run watches the custom resources and reconciles them until the stop
+// channel is closed.
+func (o *Operator) Run(stop <-chan struct{}) error {
+	defer o.queue.ShutDown()
+
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(o.dynamicClient, o.resync, o.namespace, nil)
+	o.informers = make(map[schema.GroupVersionResource]cache.SharedIndexInformer)
+	for _, resource := range []schema.GroupVersionResource{RingResource, RingReleaseResource} {
+		informer := factory.ForResource(resource).Informer()
+		informer.AddEventHandler(o.eventHandler(resource))
+		o.informers[resource] = informer
+	}
+
+	factory.Start(stop)
+	for resource, synced := range factory.WaitForCacheSync(stop) {
+		if !synced {
+			return errors.Errorf("failed to sync %s informer", resource.Resource)
+		}
+	}
+	o.logger.Info("Operator caches synced, reconciling resources")
+
+	go func() {
+		for o.processNextItem() {
+		}
+	}()
+
+	<-stop
+
+	return nil
+}
+
+func (o *Operator) eventHandler(resource schema.GroupVersionResource) cache.ResourceEventHandler {
+	enqueue := func(obj interface{}) {
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+		if err != nil {
+			o.logger.WithError(err).Error("Failed to get resource key")
+			return
+		}
+		o.queue.Add(queueItem{resource: resource, key: key})
+	}
+
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc:    enqueue,
+		UpdateFunc: func(_, newObj interface{}) { enqueue(newObj) },
+		DeleteFunc: enqueue,
+	}
+}
+
+// processNextItem reconciles the next resource of the queue, returning false
+// once the queue is shut down. Failed reconciliations are retried with
+// backoff.
+func (o *Operator) processNextItem() bool {
+	next, shutdown := o.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer o.queue.Done(next)
+
+	item := next.(queueItem)
+	logger := o.logger.WithFields(log.Fields{"resource": item.resource.Resource, "key": item.key})
+
+	obj, exists, err := o.informers[item.resource].GetIndexer().GetByKey(item.key)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get resource from cache")
+		o.queue.AddRateLimited(item)
+		return true
+	}
+	if !exists {
+		o.queue.Forget(item)
+		return true
+	}
+
+	resource := obj.(*unstructured.Unstructured).DeepCopy()
+	switch item.resource {
+	case RingResource:
+		err = o.reconcileRing(resource, logger)
+	case RingReleaseResource:
+		err = o.reconcileRingRelease(resource, logger)
+	}
+	if err != nil {
+		logger.WithError(err).Error("Failed to reconcile resource")
+		o.queue.AddRateLimited(item)
+		return true
+	}
+
+	o.queue.Forget(item)
+	return true
+}
+
+// resourceClient returns the client of the resources in the namespace of the
+// given resource.
+func (o *Operator) resourceClient(resource schema.GroupVersionResource, obj *unstructured.Unstructured) dynamic.ResourceInterface {
+	return o.dynamicClient.Resource(resource).Namespace(obj.GetNamespace())
+}
+
+// updateStatus replaces the status of the resource.
+func (o *Operator) updateStatus(resource schema.GroupVersionResource, obj *unstructured.Unstructured, status interface{}) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(status)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode status")
+	}
+	if err = unstructured.SetNestedMap(obj.Object, content, "status"); err != nil {
+		return errors.Wrap(err, "failed to set status")
+	}
+
+	_, err = o.resourceClient(resource, obj).UpdateStatus(context.Background(), obj, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to update status")
+	}
+
+	return nil
+}
+
+// idempotencyKey returns the idempotency key of the elrond resources created
+// for the given custom resource, so that retries never create them twice.
+func idempotencyKey(obj *unstructured.Unstructured, suffix string) string {
+	return fmt.Sprintf("k8s-%s-%s", obj.GetUID(), suffix)
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/api"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+type mockSupervisor struct{}
+
+func (s *mockSupervisor) Do() error {
+	return nil
+}
+
+func newResource(kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": Group + "/" + Version,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":       name,
+			"namespace":  "elrond",
+			"uid":        name + "-uid",
+			"generation": int64(1),
+		},
+		"spec": spec,
+	}}
+
+	return obj
+}
+
+func getResource(t *testing.T, o *Operator, resource schema.GroupVersionResource, name string) *unstructured.Unstructured {
+	obj, err := o.dynamicClient.Resource(resource).Namespace("elrond").Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	return obj
+}
+
+func TestOperator(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	router := mux.NewRouter()
+	api.Register(router, &api.Context{
+		Store:      sqlStore,
+		Supervisor: &mockSupervisor{},
+		Logger:     logger,
+	})
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	ringResource := newResource("Ring", "canary", map[string]interface{}{
+		"priority": int64(1),
+		"image":    "mattermost/mattermost-enterprise-edition",
+		"version":  "7.0.0",
+		"installationGroups": []interface{}{
+			map[string]interface{}{"name": "canary-ig", "provisionerGroupID": "group1"},
+		},
+	})
+	releaseResource := newResource("RingRelease", "canary-7.1.0", map[string]interface{}{
+		"ring":    "canary",
+		"image":   "mattermost/mattermost-enterprise-edition",
+		"version": "7.1.0",
+	})
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		RingResource:        "RingList",
+		RingReleaseResource: "RingReleaseList",
+	}, ringResource, releaseResource)
+
+	o := NewOperator(dynamicClient, model.NewClient(ts.URL), "elrond", time.Minute, logger)
+
+	t.Run("release before the ring is created", func(t *testing.T) {
+		err := o.reconcileRingRelease(getResource(t, o, RingReleaseResource, "canary-7.1.0"), logger)
+		require.Error(t, err)
+
+		status := &RingReleaseStatus{}
+		require.NoError(t, decodeField(getResource(t, o, RingReleaseResource, "canary-7.1.0"), "status", status))
+		require.Equal(t, "ring canary is not created yet", status.Message)
+	})
+
+	var ringID string
+	t.Run("create ring", func(t *testing.T) {
+		require.NoError(t, o.reconcileRing(getResource(t, o, RingResource, "canary"), logger))
+
+		obj := getResource(t, o, RingResource, "canary")
+		require.Equal(t, []string{Finalizer}, obj.GetFinalizers())
+
+		status := &RingStatus{}
+		require.NoError(t, decodeField(obj, "status", status))
+		require.NotEmpty(t, status.ID)
+		require.Equal(t, model.RingStateCreationRequested, status.State)
+		require.Equal(t, int64(1), status.InstallationGroups)
+		require.Equal(t, int64(1), status.ObservedGeneration)
+		ringID = status.ID
+
+		// Reconciling again changes nothing.
+		require.NoError(t, o.reconcileRing(obj, logger))
+		rings, err := sqlStore.GetRings(&model.RingFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, rings, 1)
+	})
+
+	t.Run("update ring", func(t *testing.T) {
+		obj := getResource(t, o, RingResource, "canary")
+		require.NoError(t, unstructured.SetNestedField(obj.Object, int64(600), "spec", "soakTime"))
+		require.NoError(t, unstructured.SetNestedSlice(obj.Object, []interface{}{
+			map[string]interface{}{"name": "canary-ig2", "provisionerGroupID": "group2"},
+		}, "spec", "installationGroups"))
+		obj.SetGeneration(2)
+		obj, err := o.dynamicClient.Resource(RingResource).Namespace("elrond").Update(context.Background(), obj, metav1.UpdateOptions{})
+		require.NoError(t, err)
+
+		require.NoError(t, o.reconcileRing(obj, logger))
+
+		ring, err := sqlStore.GetRing(ringID)
+		require.NoError(t, err)
+		require.Equal(t, 600, ring.SoakTime)
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(ringID)
+		require.NoError(t, err)
+		require.Len(t, installationGroups, 1)
+		require.Equal(t, "canary-ig2", installationGroups[0].Name)
+
+		status := &RingStatus{}
+		require.NoError(t, decodeField(getResource(t, o, RingResource, "canary"), "status", status))
+		require.Equal(t, int64(2), status.ObservedGeneration)
+	})
+
+	t.Run("invalid ring spec", func(t *testing.T) {
+		obj := newResource("Ring", "invalid", map[string]interface{}{})
+		obj, err := o.dynamicClient.Resource(RingResource).Namespace("elrond").Create(context.Background(), obj, metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		require.NoError(t, o.reconcileRing(obj, logger))
+
+		status := &RingStatus{}
+		require.NoError(t, decodeField(getResource(t, o, RingResource, "invalid"), "status", status))
+		require.Empty(t, status.ID)
+		require.Contains(t, status.Message, "priority cannot be zero")
+	})
+
+	t.Run("release ring", func(t *testing.T) {
+		ring, err := sqlStore.GetRing(ringID)
+		require.NoError(t, err)
+		ring.State = model.RingStateStable
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		require.NoError(t, o.reconcileRingRelease(getResource(t, o, RingReleaseResource, "canary-7.1.0"), logger))
+
+		status := &RingReleaseStatus{}
+		require.NoError(t, decodeField(getResource(t, o, RingReleaseResource, "canary-7.1.0"), "status", status))
+		require.Equal(t, ringID, status.RingID)
+		require.NotEmpty(t, status.ReleaseID)
+		require.Equal(t, RingReleasePhaseInProgress, status.Phase)
+		require.Empty(t, status.Message)
+
+		release, err := sqlStore.GetRingRelease(status.ReleaseID)
+		require.NoError(t, err)
+		require.Equal(t, "7.1.0", release.Version)
+
+		obj := getResource(t, o, RingReleaseResource, "canary-7.1.0")
+		key, err := sqlStore.GetIdempotencyKey(idempotencyKey(obj, fmt.Sprintf("release-%d", obj.GetGeneration())))
+		require.NoError(t, err)
+		require.NotNil(t, key)
+		require.Equal(t, status.ReleaseID, key.ResourceID)
+
+		// The release is requested once per generation, and its progress is
+		// reported afterwards.
+		ring, err = sqlStore.GetRing(ringID)
+		require.NoError(t, err)
+		ring.State = model.RingStateStable
+		ring.ActiveReleaseID = ring.DesiredReleaseID
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		require.NoError(t, o.reconcileRingRelease(getResource(t, o, RingReleaseResource, "canary-7.1.0"), logger))
+		require.NoError(t, decodeField(getResource(t, o, RingReleaseResource, "canary-7.1.0"), "status", status))
+		require.Equal(t, RingReleasePhaseReleased, status.Phase)
+		require.Equal(t, model.RingStateStable, status.RingState)
+	})
+
+	t.Run("delete ring", func(t *testing.T) {
+		obj := getResource(t, o, RingResource, "canary")
+		now := metav1.Now()
+		obj.SetDeletionTimestamp(&now)
+
+		require.NoError(t, o.reconcileRing(obj, logger))
+
+		require.Empty(t, getResource(t, o, RingResource, "canary").GetFinalizers())
+		ring, err := sqlStore.GetRing(ringID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateDeletionRequested, ring.State)
+	})
+}
+
+func TestReleasePhase(t *testing.T) {
+	for name, test := range map[string]struct {
+		ring     *model.Ring
+		expected string
+	}{
+		"in progress": {&model.Ring{State: model.RingStateReleaseInProgress, ActiveReleaseID: "old", DesiredReleaseID: "release"}, RingReleasePhaseInProgress},
+		"released":    {&model.Ring{State: model.RingStateStable, ActiveReleaseID: "release", DesiredReleaseID: "release"}, RingReleasePhaseReleased},
+		"failed":      {&model.Ring{State: model.RingStateReleaseFailed, ActiveReleaseID: "old", DesiredReleaseID: "release"}, RingReleasePhaseFailed},
+		"superseded":  {&model.Ring{State: model.RingStateReleaseInProgress, ActiveReleaseID: "release", DesiredReleaseID: "new"}, RingReleasePhaseSuperseded},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, releasePhase(test.ring, "release"))
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package operator
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// RingStatus is the status reported on Ring resources.
+type RingStatus struct {
+	ID                 string `json:"id,omitempty"`
+	State              string `json:"state,omitempty"`
+	ActiveReleaseID    string `json:"activeReleaseID,omitempty"`
+	DesiredReleaseID   string `json:"desiredReleaseID,omitempty"`
+	InstallationGroups int64  `json:"installationGroups,omitempty"`
+	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
+	// Message is the reason the spec could not be mirrored, if any.
+	Message string `json:"message,omitempty"`
+}
+
+// reconcileRing mirrors a Ring resource into elrond. The spec of the
+// resource is a model.RingSpec whose name defaults to the resource name.
+func (o *Operator) reconcileRing(obj *unstructured.Unstructured, logger log.FieldLogger) error {
+	status := &RingStatus{}
+	if err := decodeField(obj, "status", status); err != nil {
+		return err
+	}
+
+	if obj.GetDeletionTimestamp() != nil {
+		return o.finalizeRing(obj, status, logger)
+	}
+
+	if !hasFinalizer(obj) {
+		obj.SetFinalizers(append(obj.GetFinalizers(), Finalizer))
+		updated, err := o.resourceClient(RingResource, obj).Update(context.Background(), obj, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to add finalizer")
+		}
+		obj = updated
+	}
+
+	spec := &model.RingSpec{}
+	if err := decodeField(obj, "spec", spec); err != nil {
+		return err
+	}
+	if spec.Name == "" {
+		spec.Name = obj.GetName()
+	}
+
+	newStatus := &RingStatus{ID: status.ID, ObservedGeneration: obj.GetGeneration()}
+	err := (&model.FleetSpec{Rings: []*model.RingSpec{spec}}).Validate()
+	if err != nil {
+		logger.WithError(err).Warn("Ring spec is invalid")
+		newStatus.Message = err.Error()
+		return o.updateRingStatus(obj, status, newStatus)
+	}
+
+	ring, err := o.syncRing(obj, spec, status.ID, logger)
+	if err != nil {
+		newStatus.Message = err.Error()
+		if statusErr := o.updateRingStatus(obj, status, newStatus); statusErr != nil {
+			logger.WithError(statusErr).Error("Failed to update ring status")
+		}
+		return err
+	}
+
+	newStatus.ID = ring.ID
+	newStatus.State = ring.State
+	newStatus.ActiveReleaseID = ring.ActiveReleaseID
+	newStatus.DesiredReleaseID = ring.DesiredReleaseID
+	newStatus.InstallationGroups = int64(len(ring.InstallationGroups))
+
+	return o.updateRingStatus(obj, status, newStatus)
+}
+
+// syncRing creates or updates the ring of the resource along with its
+// installation groups, returning the ring as stored in elrond.
+func (o *Operator) syncRing(obj *unstructured.Unstructured, spec *model.RingSpec, ringID string, logger log.FieldLogger) (*model.Ring, error) {
+	var ring *model.Ring
+	var err error
+	if ringID != "" {
+		ring, err = o.client.GetRing(ringID)
+	} else {
+		ring, err = o.client.ImportRing(spec.Name)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ring")
+	}
+
+	var existing []*model.Ring
+	if ring != nil {
+		existing = append(existing, ring)
+	}
+	plan, err := model.PlanFleet(&model.FleetSpec{Rings: []*model.RingSpec{spec}}, existing)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Changes) == 0 {
+		return ring, nil
+	}
+
+	installationGroupSpecs := make(map[string]*model.InstallationGroupSpec)
+	for _, installationGroupSpec := range spec.InstallationGroups {
+		installationGroupSpecs[installationGroupSpec.Name] = installationGroupSpec
+	}
+
+	for _, change := range plan.Changes {
+		logger.Debugf("Mirroring %s of %s %s", change.Action, change.ResourceType, change.Name)
+
+		switch {
+		case change.ResourceType == model.TypeRing && change.Action == model.ApplyActionCreate:
+			ring, err = o.client.WithIdempotencyKey(idempotencyKey(obj, "ring")).CreateRing(&model.CreateRingRequest{
+				Name:               spec.Name,
+				Priority:           spec.Priority,
+				SoakTime:           spec.SoakTime,
+				Image:              spec.Image,
+				Version:            spec.Version,
+				Annotations:        spec.Annotations,
+				NotificationEmails: spec.NotificationEmails,
+				JiraProject:        spec.JiraProject,
+				OwnerTeam:          spec.OwnerTeam,
+				SlackChannel:       spec.SlackChannel,
+				EscalationPolicy:   spec.EscalationPolicy,
+			})
+
+		case change.ResourceType == model.TypeRing && change.Action == model.ApplyActionUpdate:
+			_, err = o.client.UpdateRing(ring.ID, &model.UpdateRingRequest{
+				Priority:           spec.Priority,
+				SoakTime:           spec.SoakTime,
+				Annotations:        spec.Annotations,
+				NotificationEmails: &spec.NotificationEmails,
+				JiraProject:        &spec.JiraProject,
+				OwnerTeam:          &spec.OwnerTeam,
+				SlackChannel:       &spec.SlackChannel,
+				EscalationPolicy:   &spec.EscalationPolicy,
+			})
+
+		case change.ResourceType == model.TypeInstallationGroup && change.Action == model.ApplyActionCreate:
+			installationGroupSpec := installationGroupSpecs[change.Name]
+			_, err = o.client.WithIdempotencyKey(idempotencyKey(obj, "installationgroup-"+change.Name)).RegisterRingInstallationGroup(ring.ID, &model.RegisterInstallationGroupRequest{
+				Name:               installationGroupSpec.Name,
+				SoakTime:           installationGroupSpec.SoakTime,
+				ProvisionerGroupID: installationGroupSpec.ProvisionerGroupID,
+				Annotations:        installationGroupSpec.Annotations,
+			})
+
+		case change.ResourceType == model.TypeInstallationGroup && change.Action == model.ApplyActionUpdate:
+			installationGroupSpec := installationGroupSpecs[change.Name]
+			_, err = o.client.UpdateInstallationGroup(findInstallationGroupID(ring, change.Name), &model.UpdateInstallationGroupRequest{
+				SoakTime:           installationGroupSpec.SoakTime,
+				ProvisionerGroupID: installationGroupSpec.ProvisionerGroupID,
+				Annotations:        installationGroupSpec.Annotations,
+			})
+
+		case change.ResourceType == model.TypeInstallationGroup && change.Action == model.ApplyActionDelete:
+			err = o.client.DeleteRingInstallationGroup(ring.ID, findInstallationGroupID(ring, change.Name))
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to %s %s %s", change.Action, change.ResourceType, change.Name)
+		}
+	}
+
+	ring, err = o.client.GetRing(ring.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ring")
+	}
+	if ring == nil {
+		return nil, errors.New("ring not found after update")
+	}
+
+	return ring, nil
+}
+
+// finalizeRing deletes the ring of a Ring resource being deleted, then
+// removes the finalizer so that the resource can be removed.
+func (o *Operator) finalizeRing(obj *unstructured.Unstructured, status *RingStatus, logger log.FieldLogger) error {
+	if !hasFinalizer(obj) {
+		return nil
+	}
+
+	if status.ID != "" {
+		ring, err := o.client.GetRing(status.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get ring")
+		}
+		if ring != nil && ring.DeleteAt == 0 && ring.State != model.RingStateDeletionRequested && ring.State != model.RingStateDeletionPending {
+			// The resource is kept until the ring can be deleted, such as
+			// once a release in progress is over.
+			if !ring.ValidTransitionState(model.RingStateDeletionRequested) {
+				return errors.Errorf("unable to delete ring %s while in state %s", ring.ID, ring.State)
+			}
+			if err = o.client.DeleteRing(ring.ID); err != nil {
+				return errors.Wrap(err, "failed to delete ring")
+			}
+			logger.Infof("Requested deletion of ring %s", ring.ID)
+		}
+	}
+
+	var finalizers []string
+	for _, finalizer := range obj.GetFinalizers() {
+		if finalizer != Finalizer {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	obj.SetFinalizers(finalizers)
+
+	_, err := o.resourceClient(RingResource, obj).Update(context.Background(), obj, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to remove finalizer")
+	}
+
+	return nil
+}
+
+// updateRingStatus writes the new status of the resource, if it changed.
+func (o *Operator) updateRingStatus(obj *unstructured.Unstructured, oldStatus, newStatus *RingStatus) error {
+	if reflect.DeepEqual(oldStatus, newStatus) {
+		return nil
+	}
+
+	return o.updateStatus(RingResource, obj, newStatus)
+}
+
+func hasFinalizer(obj *unstructured.Unstructured) bool {
+	for _, finalizer := range obj.GetFinalizers() {
+		if finalizer == Finalizer {
+			return true
+		}
+	}
+
+	return false
+}
+
+func findInstallationGroupID(ring *model.Ring, name string) string {
+	for _, installationGroup := range ring.InstallationGroups {
+		if installationGroup.Name == name {
+			return installationGroup.ID
+		}
+	}
+
+	return ""
+}
+
+// decodeField decodes the given top-level field of the resource, leaving
+// the value untouched if the field is not set.
+func decodeField(obj *unstructured.Unstructured, field string, value interface{}) error {
+	content, found, err := unstructured.NestedMap(obj.Object, field)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", field)
+	}
+	if !found {
+		return nil
+	}
+
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(content, value); err != nil {
+		return errors.Wrapf(err, "failed to decode %s", field)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// RingReleasePhaseInProgress is the phase of a release being rolled out.
+	RingReleasePhaseInProgress = "InProgress"
+	// RingReleasePhaseReleased is the phase of a release active on its ring.
+	RingReleasePhaseReleased = "Released"
+	// RingReleasePhaseFailed is the phase of a release that failed or was
+	// rolled back.
+	RingReleasePhaseFailed = "Failed"
+	// RingReleasePhaseSuperseded is the phase of a release replaced by
+	// another one before becoming active.
+	RingReleasePhaseSuperseded = "Superseded"
+)
+
+// RingReleaseSpec is the spec of RingRelease resources, releasing the ring
+// of the named Ring resource.
+type RingReleaseSpec struct {
+	// Ring is the name of the Ring resource, in the same namespace.
+	Ring     string `json:"ring"`
+	Image    string `json:"image,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Force    bool   `json:"force,omitempty"`
+	SoakTime int    `json:"soakTime,omitempty"`
+	Type     string `json:"type,omitempty"`
+}
+
+// RingReleaseStatus is the status reported on RingRelease resources.
+type RingReleaseStatus struct {
+	RingID             string `json:"ringID,omitempty"`
+	ReleaseID          string `json:"releaseID,omitempty"`
+	Phase              string `json:"phase,omitempty"`
+	RingState          string `json:"ringState,omitempty"`
+	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
+	// Message is the reason the release could not be requested, if any.
+	Message string `json:"message,omitempty"`
+}
+
+// reconcileRingRelease requests the release of a RingRelease resource once
+// per generation of its spec, then reports the progress of the release.
+func (o *Operator) reconcileRingRelease(obj *unstructured.Unstructured, logger log.FieldLogger) error {
+	if obj.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	spec := &RingReleaseSpec{}
+	if err := decodeField(obj, "spec", spec); err != nil {
+		return err
+	}
+	status := &RingReleaseStatus{}
+	if err := decodeField(obj, "status", status); err != nil {
+		return err
+	}
+
+	newStatus := *status
+	if status.ObservedGeneration != obj.GetGeneration() || status.ReleaseID == "" {
+		newStatus = RingReleaseStatus{ObservedGeneration: obj.GetGeneration()}
+
+		ring, err := o.requestRelease(obj, spec, logger)
+		if err != nil {
+			newStatus.Message = err.Error()
+			if statusErr := o.updateRingReleaseStatus(obj, status, &newStatus); statusErr != nil {
+				logger.WithError(statusErr).Error("Failed to update ring release status")
+			}
+			return err
+		}
+		newStatus.RingID = ring.ID
+		newStatus.ReleaseID = ring.DesiredReleaseID
+	}
+
+	ring, err := o.client.GetRing(newStatus.RingID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get ring")
+	}
+	if ring == nil {
+		newStatus.Message = "ring not found"
+		return o.updateRingReleaseStatus(obj, status, &newStatus)
+	}
+	newStatus.RingState = ring.State
+	newStatus.Phase = releasePhase(ring, newStatus.ReleaseID)
+
+	return o.updateRingReleaseStatus(obj, status, &newStatus)
+}
+
+// requestRelease releases the ring of the Ring resource named by the spec.
+func (o *Operator) requestRelease(obj *unstructured.Unstructured, spec *RingReleaseSpec, logger log.FieldLogger) (*model.Ring, error) {
+	if spec.Ring == "" {
+		return nil, errors.New("ring must be set")
+	}
+
+	ringResource, err := o.resourceClient(RingResource, obj).Get(context.Background(), spec.Ring, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get ring %s", spec.Ring)
+	}
+	ringStatus := &RingStatus{}
+	if err = decodeField(ringResource, "status", ringStatus); err != nil {
+		return nil, err
+	}
+	if ringStatus.ID == "" {
+		return nil, errors.Errorf("ring %s is not created yet", spec.Ring)
+	}
+
+	// Each generation of the resource requests its release once, even if
+	// the status recording the request fails to be updated.
+	ring, err := o.client.WithIdempotencyKey(idempotencyKey(obj, fmt.Sprintf("release-%d", obj.GetGeneration()))).ReleaseRing(ringStatus.ID, &model.RingReleaseRequest{
+		Image:    spec.Image,
+		Version:  spec.Version,
+		Force:    spec.Force,
+		SoakTime: spec.SoakTime,
+		Type:     spec.Type,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to release ring")
+	}
+	logger.Infof("Requested release %s of ring %s", ring.DesiredReleaseID, ring.ID)
+
+	return ring, nil
+}
+
+// releasePhase returns the phase of the given release of the ring.
+func releasePhase(ring *model.Ring, releaseID string) string {
+	if ring.DesiredReleaseID != releaseID {
+		return RingReleasePhaseSuperseded
+	}
+
+	switch ring.State {
+	case model.RingStateReleaseFailed, model.RingStateReleaseRollbackComplete, model.RingStateReleaseRollbackFailed:
+		return RingReleasePhaseFailed
+	case model.RingStateStable:
+		if ring.ActiveReleaseID == releaseID {
+			return RingReleasePhaseReleased
+		}
+	}
+
+	return RingReleasePhaseInProgress
+}
+
+// updateRingReleaseStatus writes the new status of the resource, if it
+// changed.
+func (o *Operator) updateRingReleaseStatus(obj *unstructured.Unstructured, oldStatus, newStatus *RingReleaseStatus) error {
+	if reflect.DeepEqual(oldStatus, newStatus) {
+		return nil
+	}
+
+	return o.updateStatus(RingReleaseResource, obj, newStatus)
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package outbound configures the HTTP clients the server calls other
+// services with, such as the provisioner, webhooks, Jira and the event sink,
+// to go through a proxy and trust custom certificate authorities.
+package outbound
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http/httpproxy"
+)
+
+const (
+	// TargetProvisioner is the provisioner server.
+	TargetProvisioner = "provisioner"
+	// TargetWebhook is every webhook, Microsoft Teams ones included.
+	TargetWebhook = "webhook"
+	// TargetJira is the Jira server releases are tracked in.
+	TargetJira = "jira"
+	// TargetEventSink is the Elasticsearch event sink.
+	TargetEventSink = "event-sink"
+	// TargetPrometheus is the Prometheus server soak checks are queried from.
+	TargetPrometheus = "prometheus"
+	// TargetVerification is every verification URL of installation group
+	// releases.
+	TargetVerification = "verification"
+	// TargetPolicy is the Open Policy Agent server the authorization policy
+	// is evaluated by.
+	TargetPolicy = "policy"
+	// TargetMaintenance is the endpoint maintenance conflicts are looked up
+	// from.
+	TargetMaintenance = "maintenance"
+	// TargetGitHub is the GitHub server failure issues are opened in.
+	TargetGitHub = "github"
+
+	// ProxyDirect, as the proxy of a target, connects to it without a proxy.
+	ProxyDirect = "direct"
+)
+
+// Targets are the services whose settings can be overridden.
+var Targets = []string{TargetProvisioner, TargetWebhook, TargetJira, TargetEventSink, TargetPrometheus, TargetVerification, TargetPolicy, TargetMaintenance, TargetGitHub}
+
+// Config configures the outbound HTTP connections to a service.
+type Config struct {
+	// Proxy is the URL of the proxy to connect through, or ProxyDirect. The
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used
+	// when empty.
+	Proxy string
+	// NoProxy is a comma-separated list of hosts, domains and CIDRs reached
+	// without the proxy, in the NO_PROXY format.
+	NoProxy string
+	// CABundle is the path to a PEM file of certificate authorities trusted
+	// in addition to the system ones.
+	CABundle string
+}
+
+// Settings are the outbound settings of the server, with the overrides of
+// some targets.
+type Settings struct {
+	Config
+	// ProxyOverrides and CABundleOverrides replace the proxy and CA bundle
+	// of some targets.
+	ProxyOverrides    map[string]string
+	CABundleOverrides map[string]string
+}
+
+// Validate validates the settings and loads every CA bundle.
+func (s *Settings) Validate() error {
+	for name, overrides := range map[string]map[string]string{
+		"proxy":     s.ProxyOverrides,
+		"CA bundle": s.CABundleOverrides,
+	} {
+		for target := range overrides {
+			if !validTarget(target) {
+				return errors.Errorf("invalid %s override target %q: must be one of %v", name, target, Targets)
+			}
+		}
+	}
+
+	if err := s.Config.Validate(); err != nil {
+		return err
+	}
+	for _, target := range Targets {
+		config := s.For(target)
+		if err := config.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid %s settings", target)
+		}
+	}
+
+	return nil
+}
+
+// For returns the configuration of the given target, that is the settings
+// with its overrides applied.
+func (s *Settings) For(target string) Config {
+	config := s.Config
+	if proxy, ok := s.ProxyOverrides[target]; ok {
+		config.Proxy = proxy
+	}
+	if caBundle, ok := s.CABundleOverrides[target]; ok {
+		config.CABundle = caBundle
+	}
+
+	return config
+}
+
+// Transport returns a transport configured for the given target.
+func (s *Settings) Transport(target string) (*http.Transport, error) {
+	config := s.For(target)
+	return config.Transport()
+}
+
+func validTarget(target string) bool {
+	for _, valid := range Targets {
+		if target == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the proxy URL and loads the CA bundle.
+func (c *Config) Validate() error {
+	if c.Proxy != "" && c.Proxy != ProxyDirect {
+		proxy, err := url.Parse(c.Proxy)
+		if err != nil {
+			return errors.Wrap(err, "invalid proxy")
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return errors.Errorf("invalid proxy %q: scheme must be http, https or socks5", c.Proxy)
+		}
+		if proxy.Host == "" {
+			return errors.Errorf("invalid proxy %q: missing host", c.Proxy)
+		}
+	}
+
+	_, err := c.TLSConfig()
+
+	return err
+}
+
+// ProxyFunc returns the proxy function of an http.Transport following the
+// configuration.
+func (c *Config) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	switch c.Proxy {
+	case "":
+		return http.ProxyFromEnvironment
+	case ProxyDirect:
+		return func(*http.Request) (*url.URL, error) {
+			return nil, nil
+		}
+	}
+
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  c.Proxy,
+		HTTPSProxy: c.Proxy,
+		NoProxy:    c.NoProxy,
+	}).ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// TLSConfig returns the TLS configuration trusting the CA bundle, or nil to
+// use the system certificate authorities only.
+func (c *Config) TLSConfig() (*tls.Config, error) {
+	if c.CABundle == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(c.CABundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read CA bundle")
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.Errorf("CA bundle %s has no PEM certificates", c.CABundle)
+	}
+
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
+
+// Transport returns a transport with the settings of http.DefaultTransport
+// and the proxy and certificate authorities of the configuration.
+func (c *Config) Transport() (*http.Transport, error) {
+	tlsConfig, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	return &http.Transport{
+		Proxy:                 c.ProxyFunc(),
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}, nil
+}
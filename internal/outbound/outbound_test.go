@@ -0,0 +1,130 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package outbound
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettings(t *testing.T) {
+	settings := &Settings{
+		Config:            Config{Proxy: "http://proxy:3128", NoProxy: "internal", CABundle: "/etc/ca.pem"},
+		ProxyOverrides:    map[string]string{TargetProvisioner: ProxyDirect},
+		CABundleOverrides: map[string]string{TargetJira: "/etc/jira.pem"},
+	}
+
+	assert.Equal(t, Config{Proxy: ProxyDirect, NoProxy: "internal", CABundle: "/etc/ca.pem"}, settings.For(TargetProvisioner))
+	assert.Equal(t, Config{Proxy: "http://proxy:3128", NoProxy: "internal", CABundle: "/etc/jira.pem"}, settings.For(TargetJira))
+	assert.Equal(t, settings.Config, settings.For(TargetWebhook))
+}
+
+func TestSettingsValidate(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	caBundle := writeCABundle(t, server)
+
+	require.NoError(t, (&Settings{}).Validate())
+	require.NoError(t, (&Settings{
+		Config:            Config{Proxy: "http://proxy:3128", CABundle: caBundle},
+		ProxyOverrides:    map[string]string{TargetWebhook: ProxyDirect, TargetEventSink: "socks5://proxy:1080"},
+		CABundleOverrides: map[string]string{TargetProvisioner: caBundle},
+	}).Validate())
+
+	for name, settings := range map[string]*Settings{
+		"proxy without scheme":       {Config: Config{Proxy: "proxy:3128"}},
+		"proxy with bad scheme":      {Config: Config{Proxy: "ftp://proxy"}},
+		"missing CA bundle":          {Config: Config{CABundle: filepath.Join(t.TempDir(), "missing.pem")}},
+		"unknown override target":    {ProxyOverrides: map[string]string{"smtp": "http://proxy:3128"}},
+		"invalid override":           {ProxyOverrides: map[string]string{TargetJira: "ftp://proxy"}},
+		"CA bundle without PEM":      {CABundleOverrides: map[string]string{TargetJira: writeFile(t, "ca.pem", "not a certificate")}},
+		"unknown CA bundle override": {CABundleOverrides: map[string]string{"smtp": caBundle}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, settings.Validate())
+		})
+	}
+}
+
+func TestConfigProxyFunc(t *testing.T) {
+	request := func(rawURL string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+		require.NoError(t, err)
+		return req
+	}
+
+	config := &Config{Proxy: "http://proxy:3128", NoProxy: "internal.example.com,10.0.0.0/8"}
+	proxy, err := config.ProxyFunc()(request("https://hooks.example.com/hook"))
+	require.NoError(t, err)
+	require.NotNil(t, proxy)
+	assert.Equal(t, "proxy:3128", proxy.Host)
+
+	for _, rawURL := range []string{"https://internal.example.com", "http://10.1.2.3:8075"} {
+		proxy, err = config.ProxyFunc()(request(rawURL))
+		require.NoError(t, err)
+		assert.Nil(t, proxy, rawURL)
+	}
+
+	proxy, err = (&Config{Proxy: ProxyDirect}).ProxyFunc()(request("https://hooks.example.com/hook"))
+	require.NoError(t, err)
+	assert.Nil(t, proxy)
+}
+
+func TestConfigTransport(t *testing.T) {
+	t.Run("CA bundle", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		transport, err := (&Config{Proxy: ProxyDirect}).Transport()
+		require.NoError(t, err)
+		_, err = (&http.Client{Transport: transport}).Get(server.URL)
+		require.Error(t, err)
+
+		transport, err = (&Config{Proxy: ProxyDirect, CABundle: writeCABundle(t, server)}).Transport()
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		var proxied string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = r.URL.String()
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer proxy.Close()
+
+		transport, err := (&Config{Proxy: proxy.URL}).Transport()
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: transport}).Post("http://hooks.example.com/hook", "application/json", nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		assert.Equal(t, "http://hooks.example.com/hook", proxied)
+	})
+}
+
+func writeCABundle(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	return writeFile(t, "ca.pem", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})))
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// OPAEngine evaluates the authorization policy as a decision of an Open
+// Policy Agent server, through its data API. The decision is either a
+// boolean, or an object with a boolean allow field and an optional reason
+// field, such as the result of:
+//
+//	package elrond
+//
+//	default decision = {"allow": false, "reason": "prod releases only from CI during business hours"}
+//
+//	decision = {"allow": true} {
+//		not input.ring.protected
+//	}
+type OPAEngine struct {
+	url    string
+	client *http.Client
+}
+
+// NewOPAEngine creates a new OPAEngine evaluating the decision at the given
+// URL of the data API of an OPA server, such as
+// http://localhost:8181/v1/data/elrond/decision. Credentials may be given as
+// the user info of the URL. Requests are sent through the given transport,
+// or http.DefaultTransport if nil.
+func NewOPAEngine(decisionURL string, transport http.RoundTripper) (*OPAEngine, error) {
+	parsed, err := url.Parse(decisionURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse policy url")
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, errors.Errorf("policy url must use http or https, got %q", decisionURL)
+	}
+	if !strings.Contains(parsed.Path, "/v1/data/") {
+		return nil, errors.Errorf("policy url must be a decision of the OPA data API, such as /v1/data/elrond/decision, got %q", decisionURL)
+	}
+
+	return &OPAEngine{
+		url:    decisionURL,
+		client: &http.Client{Timeout: 10 * time.Second, Transport: transport},
+	}, nil
+}
+
+// dataRequest is the request of the OPA data API.
+type dataRequest struct {
+	Input *model.PolicyInput `json:"input"`
+}
+
+// dataResponse is the response of the OPA data API. The result is missing
+// when the decision is undefined.
+type dataResponse struct {
+	Result json.RawMessage `json:"result"`
+}
+
+// Evaluate evaluates the decision of the policy on the given input. An
+// undefined decision denies the input.
+func (e *OPAEngine) Evaluate(input *model.PolicyInput) (*model.PolicyDecision, error) {
+	body, err := json.Marshal(&dataRequest{Input: input})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode policy input")
+	}
+
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query policy")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("policy query failed with status %d", resp.StatusCode)
+	}
+
+	var response dataResponse
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, errors.Wrap(err, "failed to decode policy response")
+	}
+	if len(response.Result) == 0 {
+		return &model.PolicyDecision{Reason: "policy decision is undefined"}, nil
+	}
+
+	var allow bool
+	if err = json.Unmarshal(response.Result, &allow); err == nil {
+		return &model.PolicyDecision{Allow: allow}, nil
+	}
+
+	var decision struct {
+		Allow  *bool  `json:"allow"`
+		Reason string `json:"reason"`
+	}
+	if err = json.Unmarshal(response.Result, &decision); err != nil || decision.Allow == nil {
+		return nil, errors.New("policy decision must be a boolean or an object with a boolean allow field")
+	}
+
+	return &model.PolicyDecision{Allow: *decision.Allow, Reason: decision.Reason}, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package policy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOPAEngine(t *testing.T) {
+	_, err := NewOPAEngine("opa:8181/v1/data/elrond/allow", nil)
+	require.Error(t, err)
+
+	_, err = NewOPAEngine("http://opa:8181/elrond/allow", nil)
+	require.Error(t, err)
+
+	_, err = NewOPAEngine("http://opa:8181/v1/data/elrond/allow", nil)
+	require.NoError(t, err)
+}
+
+func TestOPAEngineEvaluate(t *testing.T) {
+	responses := map[string]string{
+		"allowed":      `{"result":true}`,
+		"denied":       `{"result":false}`,
+		"object":       `{"result":{"allow":false,"reason":"outside of business hours"}}`,
+		"undefined":    `{}`,
+		"invalid":      `{"result":{"reason":"no allow field"}}`,
+		"server-error": `{"code":"internal_error"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/data/elrond/decision", r.URL.Path)
+
+		var request struct {
+			Input *model.PolicyInput `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		response, ok := responses[request.Input.Path]
+		require.True(t, ok)
+		if request.Input.Path == "server-error" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Write([]byte(response)) //nolint
+	}))
+	defer server.Close()
+
+	engine, err := NewOPAEngine(server.URL+"/v1/data/elrond/decision", nil)
+	require.NoError(t, err)
+
+	evaluate := func(path string) (*model.PolicyDecision, error) {
+		return engine.Evaluate(&model.PolicyInput{Action: model.PolicyActionRequest, Method: http.MethodPost, Path: path})
+	}
+
+	decision, err := evaluate("allowed")
+	require.NoError(t, err)
+	require.Equal(t, &model.PolicyDecision{Allow: true}, decision)
+
+	decision, err = evaluate("denied")
+	require.NoError(t, err)
+	require.Equal(t, &model.PolicyDecision{Allow: false}, decision)
+
+	decision, err = evaluate("object")
+	require.NoError(t, err)
+	require.Equal(t, &model.PolicyDecision{Allow: false, Reason: "outside of business hours"}, decision)
+
+	decision, err = evaluate("undefined")
+	require.NoError(t, err)
+	require.False(t, decision.Allow)
+	require.NotEmpty(t, decision.Reason)
+
+	_, err = evaluate("invalid")
+	require.Error(t, err)
+
+	_, err = evaluate("server-error")
+	require.Error(t, err)
+}
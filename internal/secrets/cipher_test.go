@@ -0,0 +1,53 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package secrets
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipher(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", KeySize)))
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := NewCipher("invalid")
+		require.Error(t, err)
+
+		_, err = NewCipher(base64.StdEncoding.EncodeToString([]byte("short")))
+		require.Error(t, err)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		c, err := NewCipher(key)
+		require.NoError(t, err)
+
+		ciphertext, err := c.Encrypt([]byte("secret"))
+		require.NoError(t, err)
+		require.NotContains(t, string(ciphertext), "secret")
+
+		plaintext, err := c.Decrypt(ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, "secret", string(plaintext))
+	})
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		c, err := NewCipher(key)
+		require.NoError(t, err)
+
+		ciphertext, err := c.Encrypt([]byte("secret"))
+		require.NoError(t, err)
+		ciphertext[len(ciphertext)-1] ^= 0xff
+
+		_, err = c.Decrypt(ciphertext)
+		require.Error(t, err)
+
+		_, err = c.Decrypt([]byte("x"))
+		require.Error(t, err)
+	})
+}
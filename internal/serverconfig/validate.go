@@ -0,0 +1,260 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package serverconfig defines and validates the settings of the elrond
+// server, shared by the server and the commands checking its configuration.
+package serverconfig
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/mattermost/elrond/internal/elrond"
+	"github.com/mattermost/elrond/internal/github"
+	"github.com/mattermost/elrond/internal/metrics"
+	"github.com/mattermost/elrond/internal/outbound"
+	"github.com/mattermost/elrond/internal/secrets"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// Validate checks the server settings, whether set with flags or
+// a configuration file, so that invalid settings are reported before the
+// server starts.
+func Validate(flags *pflag.FlagSet) error {
+	database, _ := flags.GetString("database")
+	databaseURL, err := url.Parse(database)
+	if err != nil {
+		return errors.Wrap(err, "invalid database")
+	}
+	switch strings.ToLower(databaseURL.Scheme) {
+	case "sqlite", "sqlite3", "postgres", "postgresql":
+	default:
+		return errors.Errorf("invalid database: unsupported scheme %q, expected sqlite or postgres", databaseURL.Scheme)
+	}
+	failoverDatabases, _ := flags.GetStringArray("database-failover")
+	for _, failoverDatabase := range failoverDatabases {
+		failoverURL, err := url.Parse(failoverDatabase)
+		if err != nil {
+			return errors.Wrap(err, "invalid database-failover")
+		}
+		for _, scheme := range []string{databaseURL.Scheme, failoverURL.Scheme} {
+			if scheme := strings.ToLower(scheme); scheme != "postgres" && scheme != "postgresql" {
+				return errors.Errorf("invalid database-failover: only postgres databases can fail over, got scheme %q", scheme)
+			}
+		}
+	}
+
+	for _, name := range []string{"provisioner-server", "shadow-provisioner-server", "jira-url", "event-sink-elasticsearch-url", "soak-check-prometheus-url", "policy-url", "maintenance-conflict-url", "github-url", "public-url", "database-snapshot-url"} {
+		value, _ := flags.GetString(name)
+		if value == "" && name != "provisioner-server" {
+			continue
+		}
+		parsed, err := url.Parse(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", name)
+		}
+		if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.Errorf("invalid %s: %q must be an http or https URL", name, value)
+		}
+	}
+
+	shadowProvisionerMode, _ := flags.GetString("shadow-provisioner-mode")
+	if shadowProvisionerMode != elrond.ShadowModeDryRun && shadowProvisionerMode != elrond.ShadowModeNoOp {
+		return errors.Errorf("invalid shadow-provisioner-mode: must be %s or %s, got %q", elrond.ShadowModeDryRun, elrond.ShadowModeNoOp, shadowProvisionerMode)
+	}
+
+	metricsBackend, _ := flags.GetString("metrics-backend")
+	switch metricsBackend {
+	case metrics.BackendPrometheus:
+	case metrics.BackendStatsD:
+		statsdAddress, _ := flags.GetString("statsd-address")
+		if _, _, err = net.SplitHostPort(statsdAddress); err != nil {
+			return errors.Wrap(err, "invalid statsd-address")
+		}
+	default:
+		return errors.Errorf("invalid metrics-backend: must be %s or %s, got %q", metrics.BackendPrometheus, metrics.BackendStatsD, metricsBackend)
+	}
+
+	maintenanceWindows, _ := flags.GetStringArray("maintenance-window")
+	for _, value := range maintenanceWindows {
+		if _, err = model.ParseMaintenanceWindow(value); err != nil {
+			return errors.Wrap(err, "invalid maintenance-window")
+		}
+	}
+
+	if err = FailureIssueSettings(flags).Validate(); err != nil {
+		return errors.Wrap(err, "invalid failure issue settings")
+	}
+	githubRepository, _ := flags.GetString("github-repository")
+	if githubRepository != "" {
+		if _, _, err = github.ParseRepository(githubRepository); err != nil {
+			return errors.Wrap(err, "invalid github-repository")
+		}
+	}
+	failureIssueThreshold, _ := flags.GetInt("failure-issue-threshold")
+	jiraURL, _ := flags.GetString("jira-url")
+	if failureIssueThreshold > 0 && githubRepository == "" && jiraURL == "" {
+		return errors.New("invalid failure-issue-threshold: failure issues require --github-repository or --jira-url")
+	}
+
+	if err = OutboundSettings(flags).Validate(); err != nil {
+		return errors.Wrap(err, "invalid outbound settings")
+	}
+
+	smtpServer, _ := flags.GetString("smtp-server")
+	if smtpServer != "" {
+		if _, _, err = net.SplitHostPort(smtpServer); err != nil {
+			return errors.Wrap(err, "invalid smtp-server")
+		}
+	}
+
+	listenAddresses, _ := flags.GetStringArray("listen")
+	if len(listenAddresses) == 0 {
+		return errors.New("invalid listen: at least one address is required")
+	}
+	for _, address := range listenAddresses {
+		if _, _, err = ParseListenAddress(address); err != nil {
+			return err
+		}
+	}
+
+	tlsCertFile, _ := flags.GetString("tls-cert-file")
+	tlsKeyFile, _ := flags.GetString("tls-key-file")
+	if (tlsCertFile == "") != (tlsKeyFile == "") {
+		return errors.New("both --tls-cert-file and --tls-key-file must be set to enable TLS")
+	}
+
+	credentialsEncryptionKey, _ := flags.GetString("credentials-encryption-key")
+	if credentialsEncryptionKey != "" {
+		if _, err = secrets.NewCipher(credentialsEncryptionKey); err != nil {
+			return errors.Wrap(err, "invalid credentials encryption key")
+		}
+	}
+
+	for _, name := range []string{"poll", "provisioner-group-release-timeout", "supervisor-lock-batch-size", "supervisor-parallelism", "event-cleanup-interval", "webhook-delivery-backoff", "webhook-timeout", "database-snapshot-timeout"} {
+		if value, _ := flags.GetInt(name); value <= 0 {
+			return errors.Errorf("invalid %s: must be greater than 0, got %d", name, value)
+		}
+	}
+	for _, name := range []string{
+		"shutdown-timeout",
+		"tls-reload-interval",
+		"provisioner-credentials-rotation-interval",
+		"tenant-max-rings",
+		"tenant-max-installation-groups-per-ring",
+		"tenant-max-concurrent-releases",
+		"tenant-max-webhooks",
+		"api-read-cache-ttl",
+		"webhook-digest-interval",
+		"webhook-batch-window",
+		"webhook-delivery-attempts",
+		"webhook-max-redirects",
+		"default-soak-time",
+		"hotfix-soak-time",
+		"drift-reconcile-interval",
+		"soak-analysis-interval",
+		"supervisor-unready-cycles",
+		"provisioner-max-idle-conns",
+		"provisioner-idle-conn-timeout",
+		"provisioner-keep-alive",
+		"provisioner-request-timeout",
+		"database-check-interval",
+		"event-retention-days",
+	} {
+		if value, _ := flags.GetInt(name); value < 0 {
+			return errors.Errorf("invalid %s: cannot be negative, got %d", name, value)
+		}
+	}
+
+	if webhookMaxPayloadSize, _ := flags.GetInt("webhook-max-payload-size"); webhookMaxPayloadSize != 0 {
+		if err = model.ValidateWebhookLimits(0, 0, webhookMaxPayloadSize); err != nil {
+			return errors.Wrap(err, "invalid webhook-max-payload-size")
+		}
+	}
+
+	environmentSoakTimes, _ := flags.GetStringToInt("environment-soak-times")
+	for environment, soakTime := range environmentSoakTimes {
+		if soakTime < 0 {
+			return errors.Errorf("invalid environment-soak-times: soak time of %s cannot be negative", environment)
+		}
+	}
+
+	if _, err = TenantQuotas(flags); err != nil {
+		return errors.Wrap(err, "invalid tenant-quota-overrides")
+	}
+
+	return nil
+}
+
+// TenantQuotas returns the tenant quotas configured by the given flags.
+func TenantQuotas(flags *pflag.FlagSet) (*model.TenantQuotas, error) {
+	maxRings, _ := flags.GetInt("tenant-max-rings")
+	maxInstallationGroupsPerRing, _ := flags.GetInt("tenant-max-installation-groups-per-ring")
+	maxConcurrentReleases, _ := flags.GetInt("tenant-max-concurrent-releases")
+	maxWebhooks, _ := flags.GetInt("tenant-max-webhooks")
+	overrides, _ := flags.GetStringToInt("tenant-quota-overrides")
+
+	return model.NewTenantQuotas(model.TenantQuota{
+		MaxRings:                     maxRings,
+		MaxInstallationGroupsPerRing: maxInstallationGroupsPerRing,
+		MaxConcurrentReleases:        maxConcurrentReleases,
+		MaxWebhooks:                  maxWebhooks,
+	}, overrides)
+}
+
+// OutboundSettings returns the proxy and CA bundle settings of the HTTP
+// clients of the server.
+func OutboundSettings(flags *pflag.FlagSet) *outbound.Settings {
+	proxy, _ := flags.GetString("outbound-proxy")
+	noProxy, _ := flags.GetString("outbound-no-proxy")
+	caBundle, _ := flags.GetString("outbound-ca-bundle")
+	proxyOverrides, _ := flags.GetStringToString("outbound-proxy-overrides")
+	caBundleOverrides, _ := flags.GetStringToString("outbound-ca-bundle-overrides")
+
+	return &outbound.Settings{
+		Config: outbound.Config{
+			Proxy:    proxy,
+			NoProxy:  noProxy,
+			CABundle: caBundle,
+		},
+		ProxyOverrides:    proxyOverrides,
+		CABundleOverrides: caBundleOverrides,
+	}
+}
+
+// FailureIssueSettings returns when the server opens failure issues.
+func FailureIssueSettings(flags *pflag.FlagSet) model.FailureIssueSettings {
+	threshold, _ := flags.GetInt("failure-issue-threshold")
+	window, _ := flags.GetInt("failure-issue-window")
+	publicURL, _ := flags.GetString("public-url")
+
+	return model.FailureIssueSettings{
+		Threshold: threshold,
+		Window:    window,
+		PublicURL: publicURL,
+	}
+}
+
+// unixListenPrefix marks a listen address as the path of a Unix socket.
+const unixListenPrefix = "unix:"
+
+// ParseListenAddress returns the network and address to listen on for a
+// --listen value, either host:port, [ipv6]:port or unix:/path/to/socket.
+func ParseListenAddress(value string) (string, string, error) {
+	if path := strings.TrimPrefix(value, unixListenPrefix); path != value {
+		if path == "" {
+			return "", "", errors.Errorf("invalid listen address %q: the Unix socket path is empty", value)
+		}
+		return "unix", path, nil
+	}
+
+	if _, _, err := net.SplitHostPort(value); err != nil {
+		return "", "", errors.Wrapf(err, "invalid listen address %q", value)
+	}
+
+	return "tcp", value, nil
+}
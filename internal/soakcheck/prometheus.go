@@ -0,0 +1,111 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package soakcheck
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// PrometheusChecker evaluates soak checks as instant queries of a Prometheus
+// server, or of any server implementing its query API.
+type PrometheusChecker struct {
+	url    string
+	client *http.Client
+}
+
+// NewPrometheusChecker creates a new PrometheusChecker querying the server at
+// the given URL. Credentials may be given as the user info of the URL.
+// Queries are sent through the given transport, or http.DefaultTransport if
+// nil.
+func NewPrometheusChecker(serverURL string, transport http.RoundTripper) (*PrometheusChecker, error) {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse prometheus url")
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, errors.Errorf("prometheus url must use http or https, got %q", serverURL)
+	}
+
+	return &PrometheusChecker{
+		url:    strings.TrimSuffix(serverURL, "/"),
+		client: &http.Client{Timeout: 30 * time.Second, Transport: transport},
+	}, nil
+}
+
+// queryResponse is the response of the Prometheus instant query API.
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// sample is a [timestamp, value] pair of the Prometheus query API.
+type sample [2]interface{}
+
+// Evaluate runs the query of the given check, returning its single value.
+// The query must result in a scalar or in a vector of exactly one sample,
+// which is not NaN.
+func (c *PrometheusChecker) Evaluate(check *model.SoakCheck) (float64, error) {
+	resp, err := c.client.PostForm(c.url+"/api/v1/query", url.Values{"query": {check.Query}})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to send query")
+	}
+	defer resp.Body.Close()
+
+	var response queryResponse
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, errors.Wrapf(err, "failed to decode query response with status %d", resp.StatusCode)
+	}
+	if response.Status != "success" {
+		return 0, errors.Errorf("query failed with status %d: %s", resp.StatusCode, response.Error)
+	}
+
+	var value sample
+	switch response.Data.ResultType {
+	case "scalar":
+		if err = json.Unmarshal(response.Data.Result, &value); err != nil {
+			return 0, errors.Wrap(err, "failed to decode scalar result")
+		}
+	case "vector":
+		var vector []struct {
+			Value sample `json:"value"`
+		}
+		if err = json.Unmarshal(response.Data.Result, &vector); err != nil {
+			return 0, errors.Wrap(err, "failed to decode vector result")
+		}
+		if len(vector) != 1 {
+			return 0, errors.Errorf("query must result in a single sample, got %d", len(vector))
+		}
+		value = vector[0].Value
+	default:
+		return 0, errors.Errorf("query must result in a scalar or vector, got %s", response.Data.ResultType)
+	}
+
+	text, ok := value[1].(string)
+	if !ok {
+		return 0, errors.Errorf("unexpected sample value %v", value[1])
+	}
+	observed, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse sample value %q", text)
+	}
+	if math.IsNaN(observed) {
+		return 0, errors.New("query resulted in NaN")
+	}
+
+	return observed, nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package soakcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPrometheusChecker(t *testing.T) {
+	_, err := NewPrometheusChecker("prometheus:9090", nil)
+	require.Error(t, err)
+
+	_, err = NewPrometheusChecker("http://prometheus:9090", nil)
+	require.NoError(t, err)
+}
+
+func TestPrometheusCheckerEvaluate(t *testing.T) {
+	responses := map[string]string{
+		"scalar":   `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"0.5"]}}`,
+		"vector":   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"2"]}]}}`,
+		"empty":    `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		"multiple": `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"a":"1"},"value":[1700000000,"1"]},{"metric":{"a":"2"},"value":[1700000000,"2"]}]}}`,
+		"matrix":   `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+		"nan":      `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"NaN"]}}`,
+		"invalid":  `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/query", r.URL.Path)
+		require.NoError(t, r.ParseForm())
+
+		response, ok := responses[r.Form.Get("query")]
+		require.True(t, ok)
+		if r.Form.Get("query") == "invalid" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		w.Write([]byte(response)) //nolint
+	}))
+	defer server.Close()
+
+	checker, err := NewPrometheusChecker(server.URL+"/", nil)
+	require.NoError(t, err)
+
+	evaluate := func(query string) (float64, error) {
+		return checker.Evaluate(&model.SoakCheck{Name: "check", Query: query, Comparison: model.SoakCheckBelow, Threshold: 1})
+	}
+
+	value, err := evaluate("scalar")
+	require.NoError(t, err)
+	require.Equal(t, 0.5, value)
+
+	value, err = evaluate("vector")
+	require.NoError(t, err)
+	require.Equal(t, 2.0, value)
+
+	for _, query := range []string{"empty", "multiple", "matrix", "nan", "invalid"} {
+		_, err = evaluate(query)
+		require.Error(t, err, query)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// backupTables are the tables copied by database backups. The System table
+// is left out, as restored databases are migrated to the schema version of
+// the backup beforehand.
+var backupTables = []string{
+	"Ring",
+	"InstallationGroup",
+	"RingInstallationGroup",
+	"RingRelease",
+	"RingReleaseSnapshot",
+	"RingInstallationGroupChange",
+	"RingProtectionChange",
+	"Rollout",
+	"StateChangeEvent",
+	"SoakCheckResult",
+	"HealthSnapshot",
+	"DatabaseSnapshot",
+	"Webhooks",
+	"WebhookDelivery",
+	"NotificationTemplate",
+	"Tokens",
+	"ProvisionerCredentials",
+	"ForceApproval",
+	"IdempotencyKeys",
+	"Note",
+	"Job",
+}
+
+// Backup copies every row of the database.
+func (sqlStore *SQLStore) Backup() (*model.DatabaseBackup, error) {
+	version, err := sqlStore.GetCurrentVersion()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the schema version")
+	}
+
+	backup := &model.DatabaseBackup{
+		SchemaVersion: version.String(),
+		CreateAt:      model.GetMillis(),
+		Tables:        make(map[string][]map[string]interface{}),
+	}
+	for _, table := range backupTables {
+		rows, err := sqlStore.db.Queryx("SELECT * FROM " + table)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to query %s", table)
+		}
+
+		tableRows := []map[string]interface{}{}
+		for rows.Next() {
+			row := make(map[string]interface{})
+			if err = rows.MapScan(row); err != nil {
+				rows.Close()
+				return nil, errors.Wrapf(err, "failed to scan %s", table)
+			}
+			// Text columns may be scanned as bytes, which would be encoded
+			// as base64.
+			for column, value := range row {
+				if bytes, ok := value.([]byte); ok {
+					row[column] = string(bytes)
+				}
+			}
+			tableRows = append(tableRows, row)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", table)
+		}
+
+		backup.Tables[table] = tableRows
+	}
+
+	return backup, nil
+}
+
+// Restore copies the rows of the given backup into the database, which must
+// be empty and migrated to the schema version of the backup. Nothing is
+// restored if any row fails to.
+func (sqlStore *SQLStore) Restore(backup *model.DatabaseBackup) error {
+	version, err := sqlStore.GetCurrentVersion()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the schema version")
+	}
+	if version.String() != backup.SchemaVersion {
+		return errors.Errorf("the backup has schema version %s, but the database has %s", backup.SchemaVersion, version)
+	}
+	for table := range backup.Tables {
+		if !isBackupTable(table) {
+			return errors.Errorf("the backup has unknown table %s", table)
+		}
+	}
+
+	tx, err := sqlStore.beginTransaction(sqlStore.db)
+	if err != nil {
+		return err
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	for _, table := range backupTables {
+		var count int
+		if err = sqlStore.getBuilder(tx, &count, sq.Select("COUNT(*)").From(table)); err != nil {
+			return errors.Wrapf(err, "failed to count the rows of %s", table)
+		}
+		if count > 0 {
+			return errors.Errorf("the database is not empty: %s has %d rows", table, count)
+		}
+
+		for _, row := range backup.Tables[table] {
+			if _, err = sqlStore.execBuilder(tx, sq.Insert(table).SetMap(row)); err != nil {
+				return errors.Wrapf(err, "failed to restore a row of %s", table)
+			}
+		}
+	}
+
+	// The restored events keep their sequence numbers, so the next events are
+	// numbered after them. sqlite does so on its own.
+	if tx.DriverName() == driverPostgres {
+		if _, err = tx.Exec("SELECT setval('StateChangeEvent_Sequence_seq', (SELECT COALESCE(MAX(Sequence), 0) + 1 FROM StateChangeEvent), false)"); err != nil {
+			return errors.Wrap(err, "failed to restore the state change event sequence")
+		}
+	}
+
+	return tx.Commit()
+}
+
+func isBackupTable(table string) bool {
+	for _, backupTable := range backupTables {
+		if backupTable == table {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackup(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	ring := &model.Ring{Name: "test", Priority: 1, SoakTime: 60, State: model.RingStateStable}
+	installationGroup := &model.InstallationGroup{Name: "group1", ProvisionerGroupID: "pg1"}
+	require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+	require.NoError(t, sqlStore.CreateNote(&model.Note{ResourceType: model.TypeRing, ResourceID: ring.ID, Message: "hold"}))
+
+	backup, err := sqlStore.Backup()
+	require.NoError(t, err)
+	require.Equal(t, LatestVersion().String(), backup.SchemaVersion)
+	require.Len(t, backup.Tables["Ring"], 1)
+	require.Len(t, backup.Tables["Note"], 1)
+
+	data, err := json.Marshal(backup)
+	require.NoError(t, err)
+	backup, err = model.DatabaseBackupFromReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	t.Run("restore", func(t *testing.T) {
+		restoreStore := MakeTestSQLStore(t, logger)
+		defer CloseConnection(t, restoreStore)
+
+		require.NoError(t, restoreStore.Restore(backup))
+
+		restoredRing, err := restoreStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, ring, restoredRing)
+
+		restoredInstallationGroups, err := restoreStore.GetInstallationGroupsForRing(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, restoredInstallationGroups, 1)
+		require.Equal(t, installationGroup.ID, restoredInstallationGroups[0].ID)
+
+		notes, err := restoreStore.GetNotes(&model.NoteFilter{ResourceIDs: []string{ring.ID}})
+		require.NoError(t, err)
+		require.Len(t, notes, 1)
+	})
+
+	t.Run("not empty", func(t *testing.T) {
+		err := sqlStore.Restore(backup)
+		require.EqualError(t, err, "the database is not empty: Ring has 1 rows")
+	})
+
+	t.Run("schema version mismatch", func(t *testing.T) {
+		restoreStore := MakeTestSQLStore(t, logger)
+		defer CloseConnection(t, restoreStore)
+
+		err := restoreStore.Restore(&model.DatabaseBackup{SchemaVersion: "0.1.0"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "the backup has schema version 0.1.0")
+	})
+
+	t.Run("unknown table", func(t *testing.T) {
+		restoreStore := MakeTestSQLStore(t, logger)
+		defer CloseConnection(t, restoreStore)
+
+		err := restoreStore.Restore(&model.DatabaseBackup{SchemaVersion: backup.SchemaVersion, Tables: map[string][]map[string]interface{}{"System": {}}})
+		require.EqualError(t, err, "the backup has unknown table System")
+	})
+}
+
+func TestUnlockRowsLockedBefore(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	ring := &model.Ring{Name: "test", State: model.RingStateStable}
+	require.NoError(t, sqlStore.CreateRing(ring, &model.InstallationGroup{Name: "group1"}))
+
+	locked, err := sqlStore.LockRing(ring.ID, "server1")
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	unlocked, err := sqlStore.UnlockRowsLockedBefore(1)
+	require.NoError(t, err)
+	require.Zero(t, unlocked["Ring"])
+
+	unlocked, err = sqlStore.UnlockRowsLockedBefore(model.GetMillis() + 1000)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), unlocked["Ring"])
+
+	ring, err = sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Nil(t, ring.LockAcquiredBy)
+	require.Zero(t, ring.LockAcquiredAt)
+}
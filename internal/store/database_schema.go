@@ -0,0 +1,144 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// columnReferences are the tables whose IDs the columns of the given names
+// hold. The schema declares no foreign keys.
+var columnReferences = map[string]string{
+	"RingID":              "Ring",
+	"InstallationGroupID": "InstallationGroup",
+	"ReleaseID":           "RingRelease",
+	"ActiveReleaseID":     "RingRelease",
+	"DesiredReleaseID":    "RingRelease",
+	"PreviousReleaseID":   "RingRelease",
+	"RollbackSnapshotID":  "RingReleaseSnapshot",
+	"WebhookID":           "Webhooks",
+}
+
+// databaseIndex is a row of the index queries, with the columns of the
+// index separated by commas.
+type databaseIndex struct {
+	Name      string
+	IsUnique  bool
+	Valid     bool
+	Scans     *int64
+	SizeBytes int64
+	Columns   string
+}
+
+const sqliteColumnsQuery = `
+	SELECT name AS Name, type AS Type, pk > 0 AS PrimaryKey
+	FROM pragma_table_info(?)
+	ORDER BY cid
+`
+
+const sqliteIndexesQuery = `
+	SELECT il.name AS Name, il."unique" AS IsUnique, 1 AS Valid,
+		(SELECT group_concat(ii.name, ',') FROM (SELECT name FROM pragma_index_info(il.name) ORDER BY seqno) ii) AS Columns
+	FROM pragma_index_list(?) il
+	ORDER BY il.name
+`
+
+const postgresColumnsQuery = `
+	SELECT c.column_name AS Name, c.data_type AS Type,
+		EXISTS (
+			SELECT 1 FROM information_schema.table_constraints tc
+			JOIN information_schema.key_column_usage k ON k.constraint_schema = tc.constraint_schema AND k.constraint_name = tc.constraint_name
+			WHERE tc.table_schema = c.table_schema AND tc.table_name = c.table_name AND tc.constraint_type = 'PRIMARY KEY' AND k.column_name = c.column_name
+		) AS PrimaryKey
+	FROM information_schema.columns c
+	WHERE c.table_schema = current_schema() AND c.table_name = ?
+	ORDER BY c.ordinal_position
+`
+
+const postgresIndexesQuery = `
+	SELECT i.relname AS Name, ix.indisunique AS IsUnique, ix.indisvalid AS Valid,
+		COALESCE(s.idx_scan, 0) AS Scans, pg_relation_size(i.oid) AS SizeBytes,
+		array_to_string(ARRAY(
+			SELECT a.attname FROM unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+			JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+			ORDER BY k.ord
+		), ',') AS Columns
+	FROM pg_index ix
+	JOIN pg_class t ON t.oid = ix.indrelid
+	JOIN pg_class i ON i.oid = ix.indexrelid
+	JOIN pg_namespace n ON n.oid = t.relnamespace
+	LEFT JOIN pg_stat_all_indexes s ON s.indexrelid = ix.indexrelid
+	WHERE n.nspname = current_schema() AND t.relname = ?
+	ORDER BY i.relname
+`
+
+// GetDatabaseSchema describes the schema version, tables, row counts and
+// indexes of the database.
+func (sqlStore *SQLStore) GetDatabaseSchema() (*model.DatabaseSchema, error) {
+	version, err := sqlStore.GetCurrentVersion()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the schema version")
+	}
+
+	columnsQuery, indexesQuery := sqliteColumnsQuery, sqliteIndexesQuery
+	if sqlStore.db.DriverName() == driverPostgres {
+		columnsQuery, indexesQuery = postgresColumnsQuery, postgresIndexesQuery
+	}
+
+	schema := &model.DatabaseSchema{
+		Driver:        sqlStore.db.DriverName(),
+		Version:       version.String(),
+		LatestVersion: LatestVersion().String(),
+	}
+	for _, name := range append([]string{"System"}, backupTables...) {
+		// Postgres folds the unquoted names of the migrations to lower case.
+		catalogName := name
+		if sqlStore.db.DriverName() == driverPostgres {
+			catalogName = strings.ToLower(name)
+		}
+
+		table := &model.DatabaseTable{Name: name}
+		if err = sqlStore.getBuilder(sqlStore.db, &table.Rows, sq.Select("COUNT(*)").From(name)); err != nil {
+			return nil, errors.Wrapf(err, "failed to count the rows of %s", name)
+		}
+
+		if err = sqlx.Select(sqlStore.db, &table.Columns, sqlStore.db.Rebind(columnsQuery), catalogName); err != nil {
+			return nil, errors.Wrapf(err, "failed to query the columns of %s", name)
+		}
+		for _, column := range table.Columns {
+			for columnName, references := range columnReferences {
+				if strings.EqualFold(column.Name, columnName) {
+					column.References = references
+				}
+			}
+		}
+
+		var indexes []*databaseIndex
+		if err = sqlx.Select(sqlStore.db, &indexes, sqlStore.db.Rebind(indexesQuery), catalogName); err != nil {
+			return nil, errors.Wrapf(err, "failed to query the indexes of %s", name)
+		}
+		table.Indexes = make([]*model.DatabaseIndex, 0, len(indexes))
+		for _, index := range indexes {
+			table.Indexes = append(table.Indexes, &model.DatabaseIndex{
+				Name:      index.Name,
+				Columns:   strings.Split(index.Columns, ","),
+				Unique:    index.IsUnique,
+				Valid:     index.Valid,
+				Scans:     index.Scans,
+				SizeBytes: index.SizeBytes,
+			})
+		}
+
+		schema.Tables = append(schema.Tables, table)
+	}
+	schema.Indexes = model.NewDatabaseIndexSummary(schema.Tables)
+
+	return schema, nil
+}
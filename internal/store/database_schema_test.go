@@ -0,0 +1,59 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDatabaseSchema(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	ring := &model.Ring{Name: "test", State: model.RingStateStable}
+	require.NoError(t, sqlStore.CreateRing(ring, &model.InstallationGroup{Name: "group1"}))
+
+	schema, err := sqlStore.GetDatabaseSchema()
+	require.NoError(t, err)
+	require.Equal(t, sqlStore.db.DriverName(), schema.Driver)
+	require.Equal(t, LatestVersion().String(), schema.Version)
+	require.Equal(t, LatestVersion().String(), schema.LatestVersion)
+	require.Len(t, schema.Tables, len(backupTables)+1)
+	require.Zero(t, schema.Indexes.Invalid)
+
+	tables := make(map[string]*model.DatabaseTable)
+	for _, table := range schema.Tables {
+		tables[table.Name] = table
+	}
+	require.Equal(t, int64(1), tables["System"].Rows)
+	require.Equal(t, int64(1), tables["Ring"].Rows)
+	require.Equal(t, int64(1), tables["RingInstallationGroup"].Rows)
+	require.Zero(t, tables["Job"].Rows)
+
+	columns := make(map[string]*model.DatabaseColumn)
+	for _, column := range tables["RingInstallationGroup"].Columns {
+		columns[strings.ToLower(column.Name)] = column
+	}
+	require.True(t, columns["id"].PrimaryKey)
+	require.Equal(t, "Ring", columns["ringid"].References)
+	require.Equal(t, "InstallationGroup", columns["installationgroupid"].References)
+
+	var index *model.DatabaseIndex
+	for _, tableIndex := range tables["RingInstallationGroup"].Indexes {
+		if strings.EqualFold(tableIndex.Name, "RingInstallationGroup_RingID_InstallationGroupID") {
+			index = tableIndex
+		}
+	}
+	require.NotNil(t, index)
+	require.True(t, index.Unique)
+	require.True(t, index.Valid)
+	require.Len(t, index.Columns, 2)
+}
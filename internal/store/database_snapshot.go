@@ -0,0 +1,87 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var databaseSnapshotSelect sq.SelectBuilder
+
+func init() {
+	databaseSnapshotSelect = sq.
+		Select("ID", "ReleaseID", "RingID", "InstallationGroupID", "InstallationID",
+			"SnapshotID", "Source", "CreateAt").
+		From("DatabaseSnapshot")
+}
+
+// CreateDatabaseSnapshots records the given database snapshots, assigning each
+// a unique ID. Either all of them are recorded or none.
+func (sqlStore *SQLStore) CreateDatabaseSnapshots(snapshots []*model.DatabaseSnapshot) error {
+	tx, err := sqlStore.beginTransaction(sqlStore.db)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	createAt := GetMillis()
+	for _, snapshot := range snapshots {
+		snapshot.ID = model.NewID()
+		if snapshot.CreateAt == 0 {
+			snapshot.CreateAt = createAt
+		}
+
+		_, err = sqlStore.execBuilder(tx, sq.
+			Insert("DatabaseSnapshot").
+			SetMap(map[string]interface{}{
+				"ID":                  snapshot.ID,
+				"ReleaseID":           snapshot.ReleaseID,
+				"RingID":              snapshot.RingID,
+				"InstallationGroupID": snapshot.InstallationGroupID,
+				"InstallationID":      snapshot.InstallationID,
+				"SnapshotID":          snapshot.SnapshotID,
+				"Source":              snapshot.Source,
+				"CreateAt":            snapshot.CreateAt,
+			}),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to create database snapshot")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// GetDatabaseSnapshots fetches the given page of database snapshots, oldest
+// first. The first page is 0.
+func (sqlStore *SQLStore) GetDatabaseSnapshots(filter *model.DatabaseSnapshotFilter) ([]*model.DatabaseSnapshot, error) {
+	builder := databaseSnapshotSelect.
+		OrderBy("CreateAt ASC", "ID ASC")
+	if filter.ReleaseID != "" {
+		builder = builder.Where("ReleaseID = ?", filter.ReleaseID)
+	}
+	if filter.InstallationGroupID != "" {
+		builder = builder.Where("InstallationGroupID = ?", filter.InstallationGroupID)
+	}
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	snapshots := []*model.DatabaseSnapshot{}
+	err := sqlStore.selectBuilder(sqlStore.db, &snapshots, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for database snapshots")
+	}
+
+	return snapshots, nil
+}
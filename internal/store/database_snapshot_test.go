@@ -0,0 +1,67 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseSnapshots(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	release1 := model.NewID()
+	release2 := model.NewID()
+	installationGroup1 := model.NewID()
+	installationGroup2 := model.NewID()
+
+	snapshot1 := &model.DatabaseSnapshot{
+		ReleaseID:           release1,
+		RingID:              model.NewID(),
+		InstallationGroupID: installationGroup1,
+		InstallationID:      "installation1",
+		SnapshotID:          "backup1",
+		Source:              model.DatabaseSnapshotSourceProvisioner,
+		CreateAt:            1,
+	}
+	snapshot2 := &model.DatabaseSnapshot{
+		ReleaseID:           release1,
+		RingID:              snapshot1.RingID,
+		InstallationGroupID: installationGroup2,
+		InstallationID:      "installation2",
+		SnapshotID:          "snap-2",
+		Source:              model.DatabaseSnapshotSourceHook,
+		CreateAt:            2,
+	}
+	other := &model.DatabaseSnapshot{
+		ReleaseID:           release2,
+		RingID:              snapshot1.RingID,
+		InstallationGroupID: installationGroup1,
+		InstallationID:      "installation1",
+		SnapshotID:          "backup3",
+		Source:              model.DatabaseSnapshotSourceProvisioner,
+	}
+	require.NoError(t, sqlStore.CreateDatabaseSnapshots([]*model.DatabaseSnapshot{snapshot2, snapshot1}))
+	require.NoError(t, sqlStore.CreateDatabaseSnapshots([]*model.DatabaseSnapshot{other}))
+	require.NoError(t, sqlStore.CreateDatabaseSnapshots(nil))
+	require.NotEmpty(t, snapshot1.ID)
+	require.NotZero(t, other.CreateAt)
+
+	snapshots, err := sqlStore.GetDatabaseSnapshots(&model.DatabaseSnapshotFilter{ReleaseID: release1, PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Equal(t, []*model.DatabaseSnapshot{snapshot1, snapshot2}, snapshots)
+
+	snapshots, err = sqlStore.GetDatabaseSnapshots(&model.DatabaseSnapshotFilter{InstallationGroupID: installationGroup1, PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Equal(t, []*model.DatabaseSnapshot{snapshot1, other}, snapshots)
+
+	snapshots, err = sqlStore.GetDatabaseSnapshots(&model.DatabaseSnapshotFilter{ReleaseID: model.NewID(), PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Empty(t, snapshots)
+}
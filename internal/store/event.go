@@ -0,0 +1,158 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var stateChangeEventSelect sq.SelectBuilder
+
+func init() {
+	stateChangeEventSelect = sq.
+		Select("ID", "Sequence", "ResourceType", "ResourceID", "RingID", "ReleaseID", "OldState", "NewState", "Timestamp", "InstanceID", "Error", "Bypassed", "Metadata", "RingMetadata").
+		From("StateChangeEvent")
+}
+
+// CreateStateChangeEvent records the given state change event, assigning it a
+// unique ID and the next sequence number, numbered by the database. Events
+// without an instance are recorded as made by the instance of the store.
+func (sqlStore *SQLStore) CreateStateChangeEvent(event *model.StateChangeEvent) error {
+	event.ID = model.NewID()
+	if event.Timestamp == 0 {
+		event.Timestamp = GetMillis()
+	}
+	if event.InstanceID == "" {
+		event.InstanceID = sqlStore.instanceID
+	}
+
+	insert := sq.
+		Insert("StateChangeEvent").
+		SetMap(map[string]interface{}{
+			"ID":           event.ID,
+			"ResourceType": event.ResourceType,
+			"ResourceID":   event.ResourceID,
+			"RingID":       event.RingID,
+			"ReleaseID":    event.ReleaseID,
+			"OldState":     event.OldState,
+			"NewState":     event.NewState,
+			"Timestamp":    event.Timestamp,
+			"InstanceID":   event.InstanceID,
+			"Error":        event.Error,
+			"Bypassed":     event.Bypassed,
+			"Metadata":     event.Metadata,
+			"RingMetadata": event.RingMetadata,
+		})
+
+	if sqlStore.db.DriverName() == driverPostgres {
+		if err := sqlStore.getBuilder(sqlStore.db, &event.Sequence, insert.Suffix("RETURNING Sequence")); err != nil {
+			return errors.Wrap(err, "failed to create state change event")
+		}
+	} else {
+		// The sequence number is the autoincremented row ID on sqlite.
+		result, err := sqlStore.execBuilder(sqlStore.db, insert)
+		if err != nil {
+			return errors.Wrap(err, "failed to create state change event")
+		}
+		if event.Sequence, err = result.LastInsertId(); err != nil {
+			return errors.Wrap(err, "failed to get state change event sequence")
+		}
+	}
+
+	if sqlStore.eventSink != nil {
+		sqlStore.eventSink.Send(event)
+	}
+
+	return nil
+}
+
+// GetStateChangeEvents fetches the given page of state change events, in
+// chronological order. The first page is 0. Pages can also be requested by
+// cursor, with the filter After set and Page left at 0, in which case the
+// events are in the order they were recorded, that of their sequence numbers.
+// With the filter Latest set, pages are counted back from the latest events.
+func (sqlStore *SQLStore) GetStateChangeEvents(filter *model.StateChangeEventFilter) ([]*model.StateChangeEvent, error) {
+	builder := stateChangeEventSelect.
+		OrderBy("Timestamp ASC", "ID ASC")
+	latest := filter.Latest && filter.After == nil
+	if latest {
+		builder = stateChangeEventSelect.
+			OrderBy("Timestamp DESC", "ID DESC")
+	}
+	if filter.After != nil {
+		builder = stateChangeEventSelect.
+			OrderBy("Sequence ASC")
+	}
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	if filter.RingID != "" {
+		builder = builder.Where("RingID = ?", filter.RingID)
+	}
+	if filter.RingIDs != nil {
+		builder = builder.Where(sq.Eq{"RingID": filter.RingIDs})
+	}
+	if filter.ResourceType != "" {
+		builder = builder.Where("ResourceType = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		builder = builder.Where("ResourceID = ?", filter.ResourceID)
+	}
+	if filter.ReleaseID != "" {
+		builder = builder.Where("ReleaseID = ?", filter.ReleaseID)
+	}
+	if filter.State != "" {
+		builder = builder.Where("NewState = ?", filter.State)
+	}
+	if filter.From != 0 {
+		builder = builder.Where("Timestamp >= ?", filter.From)
+	}
+	if filter.To != 0 {
+		builder = builder.Where("Timestamp < ?", filter.To)
+	}
+	if filter.After != nil && filter.After.ID != "" {
+		builder = builder.Where("Sequence > (SELECT CursorEvent.Sequence FROM StateChangeEvent CursorEvent WHERE CursorEvent.ID = ?)", filter.After.ID)
+	} else if filter.After != nil {
+		builder = builder.Where("Sequence > ?", filter.After.Sequence)
+	}
+
+	var events []*model.StateChangeEvent
+	err := sqlStore.selectBuilder(sqlStore.db, &events, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for state change events")
+	}
+	if latest {
+		for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+			events[i], events[j] = events[j], events[i]
+		}
+	}
+
+	return events, nil
+}
+
+// DeleteStateChangeEventsBefore deletes the state change events recorded
+// before the given time in milliseconds, returning how many were deleted.
+func (sqlStore *SQLStore) DeleteStateChangeEventsBefore(timestamp int64) (int64, error) {
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Delete("StateChangeEvent").
+		Where("Timestamp < ?", timestamp),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete state change events")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count deleted state change events")
+	}
+
+	return deleted, nil
+}
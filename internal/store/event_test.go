@@ -0,0 +1,214 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateChangeEvents(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	event1 := &model.StateChangeEvent{
+		ResourceType: model.TypeRing,
+		ResourceID:   "ring1",
+		RingID:       "ring1",
+		ReleaseID:    "release1",
+		OldState:     model.RingStateStable,
+		NewState:     model.RingStateReleasePending,
+		Timestamp:    1,
+	}
+	event2 := &model.StateChangeEvent{
+		ResourceType: model.TypeInstallationGroup,
+		ResourceID:   "ig1",
+		RingID:       "ring1",
+		ReleaseID:    "release1",
+		OldState:     model.InstallationGroupStable,
+		NewState:     model.InstallationGroupReleasePending,
+		Timestamp:    2,
+	}
+	event3 := &model.StateChangeEvent{
+		ResourceType: model.TypeRing,
+		ResourceID:   "ring2",
+		RingID:       "ring2",
+		NewState:     model.RingStateCreationRequested,
+	}
+
+	for _, event := range []*model.StateChangeEvent{event2, event1, event3} {
+		require.NoError(t, sqlStore.CreateStateChangeEvent(event))
+	}
+	require.NotZero(t, event3.Timestamp)
+	require.Equal(t, event2.Sequence+1, event1.Sequence)
+	require.Equal(t, event1.Sequence+1, event3.Sequence)
+
+	events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{RingID: "ring1", PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Equal(t, []*model.StateChangeEvent{event1, event2}, events)
+
+	events, err = sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{ResourceType: model.TypeInstallationGroup, ResourceID: "ig1", PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Equal(t, []*model.StateChangeEvent{event2}, events)
+
+	events, err = sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{PerPage: 1, Page: 1})
+	require.NoError(t, err)
+	require.Equal(t, []*model.StateChangeEvent{event2}, events)
+
+	t.Run("after cursor", func(t *testing.T) {
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{
+			After:   &model.EventCursor{},
+			PerPage: model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event2, event1, event3}, events)
+
+		events, err = sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{
+			After:   &model.EventCursor{Sequence: event2.Sequence},
+			PerPage: model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event1, event3}, events)
+
+		events, err = sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{
+			After:   &model.EventCursor{Sequence: event3.Sequence},
+			PerPage: model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Empty(t, events)
+
+		// Cursors issued before events were numbered point after their event.
+		events, err = sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{
+			After:   &model.EventCursor{ID: event2.ID},
+			PerPage: model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event1, event3}, events)
+	})
+
+	t.Run("time range", func(t *testing.T) {
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{From: 2, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event2, event3}, events)
+
+		events, err = sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{From: 1, To: 2, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event1}, events)
+	})
+
+	t.Run("rings", func(t *testing.T) {
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{RingIDs: []string{"ring1", "ring2"}, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event1, event2, event3}, events)
+
+		events, err = sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{RingIDs: []string{"ring2"}, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event3}, events)
+	})
+
+	t.Run("latest", func(t *testing.T) {
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{RingID: "ring1", Latest: true, PerPage: 1})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event2}, events)
+
+		events, err = sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{RingID: "ring1", Latest: true, PerPage: 5})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event1, event2}, events)
+	})
+
+	t.Run("after cursor with the same timestamp", func(t *testing.T) {
+		event4 := &model.StateChangeEvent{ResourceType: model.TypeRing, ResourceID: "ring3", RingID: "ring3", Timestamp: 2}
+		require.NoError(t, sqlStore.CreateStateChangeEvent(event4))
+		event5 := &model.StateChangeEvent{ResourceType: model.TypeRing, ResourceID: "ring3", RingID: "ring3", Timestamp: 2}
+		require.NoError(t, sqlStore.CreateStateChangeEvent(event5))
+
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{
+			After:   &model.EventCursor{Sequence: event4.Sequence},
+			PerPage: 1,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event5}, events)
+	})
+
+	t.Run("state and release", func(t *testing.T) {
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{State: model.RingStateCreationRequested, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event3}, events)
+
+		events, err = sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{ReleaseID: "release1", PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event1, event2}, events)
+	})
+
+	t.Run("instance and error", func(t *testing.T) {
+		sqlStore.SetInstanceID("instance1")
+		defer sqlStore.SetInstanceID("")
+
+		event := &model.StateChangeEvent{
+			ResourceType: model.TypeRing,
+			ResourceID:   "ring5",
+			RingID:       "ring5",
+			OldState:     model.RingStateReleaseInProgress,
+			NewState:     model.RingStateReleaseFailed,
+			Error:        "installation group ig1 release ended in state release-failed",
+		}
+		require.NoError(t, sqlStore.CreateStateChangeEvent(event))
+		require.Equal(t, "instance1", event.InstanceID)
+
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{RingID: "ring5", PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.StateChangeEvent{event}, events)
+	})
+
+	t.Run("event sink", func(t *testing.T) {
+		sink := &mockEventSink{}
+		sqlStore.SetEventSink(sink)
+		defer sqlStore.SetEventSink(nil)
+
+		event := &model.StateChangeEvent{ResourceType: model.TypeRing, ResourceID: "ring4", RingID: "ring4"}
+		require.NoError(t, sqlStore.CreateStateChangeEvent(event))
+		require.Equal(t, []*model.StateChangeEvent{event}, sink.events)
+	})
+}
+
+func TestDeleteStateChangeEventsBefore(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	for _, timestamp := range []int64{1, 2, 3} {
+		require.NoError(t, sqlStore.CreateStateChangeEvent(&model.StateChangeEvent{ResourceType: model.TypeRing, ResourceID: "ring1", RingID: "ring1", Timestamp: timestamp}))
+	}
+
+	deleted, err := sqlStore.DeleteStateChangeEventsBefore(3)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), deleted)
+
+	events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, int64(3), events[0].Timestamp)
+
+	t.Run("sequence does not start over", func(t *testing.T) {
+		last := events[0]
+		deleted, err := sqlStore.DeleteStateChangeEventsBefore(4)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		event := &model.StateChangeEvent{ResourceType: model.TypeRing, ResourceID: "ring1", RingID: "ring1", Timestamp: 4}
+		require.NoError(t, sqlStore.CreateStateChangeEvent(event))
+		require.Greater(t, event.Sequence, last.Sequence)
+	})
+}
+
+type mockEventSink struct {
+	events []*model.StateChangeEvent
+}
+
+func (s *mockEventSink) Send(event *model.StateChangeEvent) {
+	s.events = append(s.events, event)
+}
@@ -0,0 +1,311 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// connectionCheckTimeout is the timeout of each check of the connection
+	// to the database.
+	connectionCheckTimeout = 5 * time.Second
+	// minReconnectBackoff is the delay before the first check following a
+	// failed one, doubled after each further failure.
+	minReconnectBackoff = time.Second
+)
+
+// failoverDriverConn is a driver connection supporting what the store uses.
+type failoverDriverConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.QueryerContext
+	driver.ExecerContext
+	driver.Pinger
+}
+
+// failoverConnector connects to the first of its databases accepting writes,
+// starting with the one last connected to, so that the store follows a
+// primary switch without restarting the server.
+type failoverConnector struct {
+	connectors []driver.Connector
+	hosts      []string
+	logger     logrus.FieldLogger
+
+	lock sync.Mutex
+	// current is the index of the database last connected to.
+	current int
+	// generation is increased every time the connections are invalidated,
+	// so that connections opened before are closed rather than reused.
+	generation     int64
+	connected      bool
+	failovers      int64
+	lastFailoverAt time.Time
+	lastError      string
+}
+
+// newFailoverConnector creates a connector to the given databases, in order
+// of preference. The hosts are reported instead of the connectors, which may
+// hold credentials.
+func newFailoverConnector(connectors []driver.Connector, hosts []string, logger logrus.FieldLogger) *failoverConnector {
+	return &failoverConnector{
+		connectors: connectors,
+		hosts:      hosts,
+		logger:     logger,
+		connected:  true,
+	}
+}
+
+// Connect opens a connection to the current database, or to the next one
+// accepting writes if it cannot.
+func (c *failoverConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.lock.Lock()
+	start := c.current
+	c.lock.Unlock()
+
+	var failures []string
+	for i := range c.connectors {
+		index := (start + i) % len(c.connectors)
+
+		conn, err := c.connect(ctx, index)
+		if err != nil {
+			failures = append(failures, errors.Wrap(err, c.hosts[index]).Error())
+			continue
+		}
+
+		return &failoverConn{
+			failoverDriverConn: conn,
+			connector:          c,
+			generation:         c.connectedTo(index),
+		}, nil
+	}
+
+	err := errors.Errorf("failed to connect to any database: %s", strings.Join(failures, "; "))
+	c.disconnected(err)
+
+	return nil, err
+}
+
+// connect opens a connection to the database at the given index, making sure
+// it accepts writes when there are other databases to fail over to.
+func (c *failoverConnector) connect(ctx context.Context, index int) (failoverDriverConn, error) {
+	conn, err := c.connectors[index].Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	driverConn, ok := conn.(failoverDriverConn)
+	if !ok {
+		conn.Close()
+		return nil, errors.New("unsupported database driver connection")
+	}
+	if len(c.connectors) == 1 {
+		return driverConn, nil
+	}
+
+	readOnly, err := inRecovery(ctx, driverConn)
+	if err != nil {
+		driverConn.Close()
+		return nil, errors.Wrap(err, "failed to check whether the database accepts writes")
+	}
+	if readOnly {
+		driverConn.Close()
+		return nil, errors.New("database is read-only")
+	}
+
+	return driverConn, nil
+}
+
+// inRecovery returns whether the given connection is to a postgres standby,
+// which does not accept writes.
+func inRecovery(ctx context.Context, conn driver.QueryerContext) (bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT pg_is_in_recovery()", nil)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	values := make([]driver.Value, 1)
+	if err = rows.Next(values); err != nil {
+		if err == io.EOF {
+			return false, errors.New("no result")
+		}
+		return false, err
+	}
+	readOnly, ok := values[0].(bool)
+	if !ok {
+		return false, errors.Errorf("unexpected result %v", values[0])
+	}
+
+	return readOnly, nil
+}
+
+// connectedTo records a connection to the database at the given index,
+// returning the generation of the connection. Connecting to another database
+// than the current one is a failover, invalidating the connections to the
+// previous one.
+func (c *failoverConnector) connectedTo(index int) int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if index != c.current {
+		c.logger.WithFields(logrus.Fields{
+			"from": c.hosts[c.current],
+			"to":   c.hosts[index],
+		}).Warn("Failed over to another database")
+		c.current = index
+		c.generation++
+		c.failovers++
+		c.lastFailoverAt = time.Now()
+	}
+	if !c.connected {
+		c.logger.WithField("database", c.hosts[index]).Info("Reconnected to the database")
+	}
+	c.connected = true
+	c.lastError = ""
+
+	return c.generation
+}
+
+// disconnected records a failure to connect to any database.
+func (c *failoverConnector) disconnected(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.connected = false
+	c.lastError = err.Error()
+}
+
+// invalidate closes every open connection once it is released, so that the
+// next ones reconnect, after the given connection check failure.
+func (c *failoverConnector) invalidate(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.generation++
+	c.connected = false
+	c.lastError = err.Error()
+}
+
+// valid returns whether connections of the given generation can be reused.
+func (c *failoverConnector) valid(generation int64) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return generation == c.generation
+}
+
+// health returns the state of the connection to the databases.
+func (c *failoverConnector) health() *model.DatabaseHealth {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	health := &model.DatabaseHealth{
+		Connected: c.connected,
+		Host:      c.hosts[c.current],
+		Databases: len(c.connectors),
+		Failovers: c.failovers,
+		LastError: c.lastError,
+	}
+	if !c.lastFailoverAt.IsZero() {
+		health.LastFailoverAt = c.lastFailoverAt.UnixMilli()
+	}
+
+	return health
+}
+
+// Driver returns the driver of the databases.
+func (c *failoverConnector) Driver() driver.Driver {
+	return c.connectors[0].Driver()
+}
+
+// failoverConn is a connection opened by a failoverConnector, discarded by
+// the connection pool once invalidated.
+type failoverConn struct {
+	failoverDriverConn
+	connector  *failoverConnector
+	generation int64
+}
+
+// IsValid returns whether the connection can be reused.
+func (c *failoverConn) IsValid() bool {
+	return c.connector.valid(c.generation)
+}
+
+// checkConnection checks that the database is reachable and, when failing
+// over is possible, that it still accepts writes, invalidating the open
+// connections otherwise.
+func (sqlStore *SQLStore) checkConnection() error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionCheckTimeout)
+	defer cancel()
+
+	if sqlStore.connector == nil {
+		return sqlStore.db.PingContext(ctx)
+	}
+
+	var err error
+	if len(sqlStore.connector.connectors) == 1 {
+		err = sqlStore.db.PingContext(ctx)
+	} else {
+		var readOnly bool
+		err = sqlStore.db.GetContext(ctx, &readOnly, "SELECT pg_is_in_recovery()")
+		if err == nil && readOnly {
+			err = errors.New("database is read-only")
+		}
+	}
+	if err != nil {
+		sqlStore.connector.invalidate(err)
+	}
+
+	return err
+}
+
+// MonitorConnection checks the connection to the database every interval
+// until stop is closed. After a failed check, the connections are reopened,
+// failing over to the next database if needed, and checked again with an
+// exponential backoff capped at the interval.
+func (sqlStore *SQLStore) MonitorConnection(interval time.Duration, stop <-chan struct{}) {
+	var backoff time.Duration
+	for {
+		wait := interval
+		if err := sqlStore.checkConnection(); err != nil {
+			backoff *= 2
+			if backoff < minReconnectBackoff {
+				backoff = minReconnectBackoff
+			}
+			if backoff > interval {
+				backoff = interval
+			}
+			wait = backoff
+			sqlStore.logger.WithError(err).Warnf("Database connection check failed, retrying in %s", wait)
+		} else {
+			backoff = 0
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// DatabaseHealth returns the state of the connection to the database.
+func (sqlStore *SQLStore) DatabaseHealth() *model.DatabaseHealth {
+	if sqlStore.connector == nil {
+		return &model.DatabaseHealth{Connected: true, Databases: 1}
+	}
+
+	return sqlStore.connector.health()
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDatabase struct {
+	down     bool
+	readOnly bool
+	connects int
+}
+
+func (d *mockDatabase) Connect(ctx context.Context) (driver.Conn, error) {
+	if d.down {
+		return nil, errors.New("connection refused")
+	}
+	d.connects++
+
+	return &mockDatabaseConn{database: d}, nil
+}
+
+func (d *mockDatabase) Driver() driver.Driver {
+	return nil
+}
+
+type mockDatabaseConn struct {
+	database *mockDatabase
+}
+
+func (c *mockDatabaseConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *mockDatabaseConn) Close() error {
+	return nil
+}
+
+func (c *mockDatabaseConn) Begin() (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *mockDatabaseConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *mockDatabaseConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *mockDatabaseConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &mockRecoveryRows{readOnly: c.database.readOnly}, nil
+}
+
+func (c *mockDatabaseConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *mockDatabaseConn) Ping(ctx context.Context) error {
+	return nil
+}
+
+type mockRecoveryRows struct {
+	readOnly bool
+	read     bool
+}
+
+func (r *mockRecoveryRows) Columns() []string {
+	return []string{"pg_is_in_recovery"}
+}
+
+func (r *mockRecoveryRows) Close() error {
+	return nil
+}
+
+func (r *mockRecoveryRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = r.readOnly
+
+	return nil
+}
+
+func TestFailoverConnector(t *testing.T) {
+	primary := &mockDatabase{}
+	replica := &mockDatabase{readOnly: true}
+	connector := newFailoverConnector([]driver.Connector{primary, replica}, []string{"primary:5432", "replica:5432"}, testlib.MakeLogger(t))
+
+	conn, err := connector.Connect(context.Background())
+	require.NoError(t, err)
+	primaryConn := conn.(*failoverConn)
+	assert.True(t, primaryConn.IsValid())
+	assert.Equal(t, 1, primary.connects)
+
+	t.Run("read-only databases are skipped", func(t *testing.T) {
+		primary.down = true
+		_, err := connector.Connect(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "database is read-only")
+
+		health := connector.health()
+		assert.False(t, health.Connected)
+		assert.Equal(t, "primary:5432", health.Host)
+		assert.Equal(t, 2, health.Databases)
+		assert.Zero(t, health.Failovers)
+		assert.NotEmpty(t, health.LastError)
+	})
+
+	t.Run("failover", func(t *testing.T) {
+		replica.readOnly = false
+		conn, err := connector.Connect(context.Background())
+		require.NoError(t, err)
+		assert.True(t, conn.(*failoverConn).IsValid())
+		assert.False(t, primaryConn.IsValid())
+
+		health := connector.health()
+		assert.True(t, health.Connected)
+		assert.Equal(t, "replica:5432", health.Host)
+		assert.Equal(t, int64(1), health.Failovers)
+		assert.NotZero(t, health.LastFailoverAt)
+		assert.Empty(t, health.LastError)
+	})
+
+	t.Run("current database is preferred", func(t *testing.T) {
+		primary.down = false
+		_, err := connector.Connect(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, primary.connects)
+		assert.Equal(t, "replica:5432", connector.health().Host)
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		conn, err := connector.Connect(context.Background())
+		require.NoError(t, err)
+
+		connector.invalidate(errors.New("database is read-only"))
+		assert.False(t, conn.(*failoverConn).IsValid())
+		assert.False(t, connector.health().Connected)
+
+		replica.readOnly = true
+		_, err = connector.Connect(context.Background())
+		require.NoError(t, err)
+		health := connector.health()
+		assert.True(t, health.Connected)
+		assert.Equal(t, "primary:5432", health.Host)
+		assert.Equal(t, int64(2), health.Failovers)
+	})
+}
+
+func TestNewFailoverDatabases(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	_, err := New("sqlite3://file:failover.db?mode=memory", logger, "postgres://replica:5432/elrond")
+	require.EqualError(t, err, "failover databases are only supported with postgres")
+
+	_, err = New("postgres://primary:5432/elrond", logger, "sqlite3://file:failover.db?mode=memory")
+	require.EqualError(t, err, "unsupported failover dsn scheme sqlite3, must be postgres")
+}
+
+func TestDatabaseHealth(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	require.NoError(t, sqlStore.checkConnection())
+	assert.True(t, sqlStore.DatabaseHealth().Connected)
+}
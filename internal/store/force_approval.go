@@ -0,0 +1,125 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var forceApprovalSelect sq.SelectBuilder
+
+func init() {
+	forceApprovalSelect = sq.
+		Select("ID", "Action", "Target", "RequestHash", "RequestedBy", "ConfirmedBy",
+			"CreateAt", "ExpireAt", "ConfirmAt").
+		From("ForceApproval")
+}
+
+// GetPendingForceApproval fetches the latest force approval of the given
+// action and target that is neither confirmed nor expired at the given time,
+// in milliseconds.
+func (sqlStore *SQLStore) GetPendingForceApproval(action, target string, now int64) (*model.ForceApproval, error) {
+	var approval model.ForceApproval
+	err := sqlStore.getBuilder(sqlStore.db, &approval, forceApprovalSelect.
+		Where(sq.Eq{"Action": action, "Target": target, "ConfirmedBy": ""}).
+		Where("ExpireAt > ?", now).
+		OrderBy("CreateAt DESC", "ID DESC").
+		Limit(1),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get pending force approval")
+	}
+
+	return &approval, nil
+}
+
+// GetForceApprovals fetches the given page of force approvals, newest first.
+// The first page is 0.
+func (sqlStore *SQLStore) GetForceApprovals(filter *model.ForceApprovalFilter) ([]*model.ForceApproval, error) {
+	builder := forceApprovalSelect.
+		OrderBy("CreateAt DESC", "ID DESC")
+	if filter.RingID != "" {
+		builder = builder.Where(sq.Like{"Target": "%" + filter.RingID + "%"})
+	}
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	var approvals []*model.ForceApproval
+	err := sqlStore.selectBuilder(sqlStore.db, &approvals, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for force approvals")
+	}
+
+	return approvals, nil
+}
+
+// CreateForceApproval records the given force approval, assigning it a unique
+// ID.
+func (sqlStore *SQLStore) CreateForceApproval(approval *model.ForceApproval) error {
+	approval.ID = model.NewID()
+	approval.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("ForceApproval").
+		SetMap(map[string]interface{}{
+			"ID":          approval.ID,
+			"Action":      approval.Action,
+			"Target":      approval.Target,
+			"RequestHash": approval.RequestHash,
+			"RequestedBy": approval.RequestedBy,
+			"ConfirmedBy": approval.ConfirmedBy,
+			"CreateAt":    approval.CreateAt,
+			"ExpireAt":    approval.ExpireAt,
+			"ConfirmAt":   approval.ConfirmAt,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create force approval")
+	}
+
+	return nil
+}
+
+// ConfirmForceApproval records the confirmation of the given force approval
+// by the given token. It returns false if the approval was confirmed by
+// another request in the meantime or expired.
+func (sqlStore *SQLStore) ConfirmForceApproval(approval *model.ForceApproval, confirmedBy string) (bool, error) {
+	confirmAt := GetMillis()
+
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("ForceApproval").
+		SetMap(map[string]interface{}{
+			"ConfirmedBy": confirmedBy,
+			"ConfirmAt":   confirmAt,
+		}).
+		Where(sq.Eq{"ID": approval.ID, "ConfirmedBy": ""}).
+		Where("ExpireAt > ?", confirmAt),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to confirm force approval")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to count confirmed force approvals")
+	}
+	if rows != 1 {
+		return false, nil
+	}
+
+	approval.ConfirmedBy = confirmedBy
+	approval.ConfirmAt = confirmAt
+
+	return true, nil
+}
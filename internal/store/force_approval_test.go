@@ -0,0 +1,91 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForceApprovals(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	ring1 := model.NewID()
+	ring2 := model.NewID()
+	now := GetMillis()
+
+	approval, err := sqlStore.GetPendingForceApproval(model.ForceApprovalActionRelease, ring1, now)
+	require.NoError(t, err)
+	require.Nil(t, approval)
+
+	expired := &model.ForceApproval{
+		Action:      model.ForceApprovalActionRelease,
+		Target:      ring1,
+		RequestHash: "hash",
+		RequestedBy: "token1",
+		ExpireAt:    now - 1,
+	}
+	require.NoError(t, sqlStore.CreateForceApproval(expired))
+
+	pending := &model.ForceApproval{
+		Action:      model.ForceApprovalActionRelease,
+		Target:      model.ForceApprovalTarget([]string{ring2, ring1}),
+		RequestHash: "hash",
+		RequestedBy: "token1",
+		ExpireAt:    now + 60000,
+	}
+	require.NoError(t, sqlStore.CreateForceApproval(pending))
+	require.NotEmpty(t, pending.ID)
+
+	t.Run("expired approvals are not pending", func(t *testing.T) {
+		approval, err = sqlStore.GetPendingForceApproval(model.ForceApprovalActionRelease, ring1, now)
+		require.NoError(t, err)
+		require.Nil(t, approval)
+
+		confirmed, err := sqlStore.ConfirmForceApproval(expired, "token2")
+		require.NoError(t, err)
+		require.False(t, confirmed)
+	})
+
+	t.Run("get pending approval", func(t *testing.T) {
+		approval, err = sqlStore.GetPendingForceApproval(model.ForceApprovalActionRelease, pending.Target, now)
+		require.NoError(t, err)
+		require.Equal(t, pending, approval)
+
+		approval, err = sqlStore.GetPendingForceApproval(model.ForceApprovalActionUnlock, pending.Target, now)
+		require.NoError(t, err)
+		require.Nil(t, approval)
+	})
+
+	t.Run("confirm once", func(t *testing.T) {
+		confirmed, err := sqlStore.ConfirmForceApproval(pending, "token2")
+		require.NoError(t, err)
+		require.True(t, confirmed)
+		require.Equal(t, "token2", pending.ConfirmedBy)
+		require.NotZero(t, pending.ConfirmAt)
+
+		confirmed, err = sqlStore.ConfirmForceApproval(&model.ForceApproval{ID: pending.ID}, "token3")
+		require.NoError(t, err)
+		require.False(t, confirmed)
+
+		approval, err = sqlStore.GetPendingForceApproval(model.ForceApprovalActionRelease, pending.Target, now)
+		require.NoError(t, err)
+		require.Nil(t, approval)
+	})
+
+	t.Run("list approvals", func(t *testing.T) {
+		approvals, err := sqlStore.GetForceApprovals(&model.ForceApprovalFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []*model.ForceApproval{pending, expired}, approvals)
+
+		approvals, err = sqlStore.GetForceApprovals(&model.ForceApprovalFilter{RingID: ring2, PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []*model.ForceApproval{pending}, approvals)
+	})
+}
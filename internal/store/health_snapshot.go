@@ -0,0 +1,88 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var healthSnapshotSelect sq.SelectBuilder
+
+func init() {
+	healthSnapshotSelect = sq.
+		Select("ID", "InstallationGroupID", "RingID", "ReleaseID", "Stage",
+			"InstallationsTotal", "InstallationsUpdated", "InstallationsUpdating",
+			"InstallationsHibernating", "InstallationsAwaitingUpdate", "Error", "CreateAt").
+		From("HealthSnapshot")
+}
+
+// CreateHealthSnapshot records the given health snapshot, assigning it a unique ID.
+func (sqlStore *SQLStore) CreateHealthSnapshot(snapshot *model.HealthSnapshot) error {
+	snapshot.ID = model.NewID()
+	if snapshot.CreateAt == 0 {
+		snapshot.CreateAt = GetMillis()
+	}
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("HealthSnapshot").
+		SetMap(map[string]interface{}{
+			"ID":                          snapshot.ID,
+			"InstallationGroupID":         snapshot.InstallationGroupID,
+			"RingID":                      snapshot.RingID,
+			"ReleaseID":                   snapshot.ReleaseID,
+			"Stage":                       snapshot.Stage,
+			"InstallationsTotal":          snapshot.InstallationsTotal,
+			"InstallationsUpdated":        snapshot.InstallationsUpdated,
+			"InstallationsUpdating":       snapshot.InstallationsUpdating,
+			"InstallationsHibernating":    snapshot.InstallationsHibernating,
+			"InstallationsAwaitingUpdate": snapshot.InstallationsAwaitingUpdate,
+			"Error":                       snapshot.Error,
+			"CreateAt":                    snapshot.CreateAt,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create health snapshot")
+	}
+
+	return nil
+}
+
+// GetHealthSnapshots fetches the given page of health snapshots, oldest
+// first. The first page is 0, or that of the latest snapshots with the filter
+// Latest set.
+func (sqlStore *SQLStore) GetHealthSnapshots(filter *model.HealthSnapshotFilter) ([]*model.HealthSnapshot, error) {
+	builder := healthSnapshotSelect.
+		OrderBy("CreateAt ASC", "ID ASC")
+	if filter.Latest {
+		builder = healthSnapshotSelect.
+			OrderBy("CreateAt DESC", "ID DESC")
+	}
+	if filter.ReleaseID != "" {
+		builder = builder.Where("ReleaseID = ?", filter.ReleaseID)
+	}
+	if filter.RingID != "" {
+		builder = builder.Where("RingID = ?", filter.RingID)
+	}
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	var snapshots []*model.HealthSnapshot
+	err := sqlStore.selectBuilder(sqlStore.db, &snapshots, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for health snapshots")
+	}
+	if filter.Latest {
+		for i, j := 0, len(snapshots)-1; i < j; i, j = i+1, j-1 {
+			snapshots[i], snapshots[j] = snapshots[j], snapshots[i]
+		}
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthSnapshots(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	release1 := model.NewID()
+	release2 := model.NewID()
+	ring1 := model.NewID()
+	ring2 := model.NewID()
+
+	before := &model.HealthSnapshot{
+		InstallationGroupID: model.NewID(),
+		RingID:              ring1,
+		ReleaseID:           release1,
+		Stage:               model.HealthSnapshotPreRelease,
+		InstallationsTotal:  3,
+		CreateAt:            1,
+	}
+	after := &model.HealthSnapshot{
+		InstallationGroupID:  before.InstallationGroupID,
+		RingID:               ring1,
+		ReleaseID:            release1,
+		Stage:                model.HealthSnapshotPostSoak,
+		InstallationsTotal:   3,
+		InstallationsUpdated: 3,
+		CreateAt:             2,
+	}
+	failed := &model.HealthSnapshot{
+		InstallationGroupID: model.NewID(),
+		RingID:              ring2,
+		ReleaseID:           release1,
+		Stage:               model.HealthSnapshotPreRelease,
+		Error:               "provisioner unavailable",
+		CreateAt:            3,
+	}
+	other := &model.HealthSnapshot{
+		InstallationGroupID: before.InstallationGroupID,
+		RingID:              ring1,
+		ReleaseID:           release2,
+		Stage:               model.HealthSnapshotPreRelease,
+	}
+	for _, snapshot := range []*model.HealthSnapshot{after, before, failed, other} {
+		require.NoError(t, sqlStore.CreateHealthSnapshot(snapshot))
+		require.NotEmpty(t, snapshot.ID)
+	}
+	require.NotZero(t, other.CreateAt)
+
+	snapshots, err := sqlStore.GetHealthSnapshots(&model.HealthSnapshotFilter{ReleaseID: release1, PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Equal(t, []*model.HealthSnapshot{before, after, failed}, snapshots)
+
+	snapshots, err = sqlStore.GetHealthSnapshots(&model.HealthSnapshotFilter{ReleaseID: release1, RingID: ring2, PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Equal(t, []*model.HealthSnapshot{failed}, snapshots)
+
+	snapshots, err = sqlStore.GetHealthSnapshots(&model.HealthSnapshotFilter{PerPage: 1, Page: 1})
+	require.NoError(t, err)
+	require.Equal(t, []*model.HealthSnapshot{after}, snapshots)
+
+	snapshots, err = sqlStore.GetHealthSnapshots(&model.HealthSnapshotFilter{ReleaseID: release1, Latest: true, PerPage: 2})
+	require.NoError(t, err)
+	require.Equal(t, []*model.HealthSnapshot{after, failed}, snapshots)
+}
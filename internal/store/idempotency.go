@@ -0,0 +1,98 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var idempotencyKeySelect sq.SelectBuilder
+
+func init() {
+	idempotencyKeySelect = sq.
+		Select("ID", "ResourceType", "ResourceID", "CreateAt").From("IdempotencyKeys")
+}
+
+// GetIdempotencyKey fetches the given idempotency key, if it was used.
+func (sqlStore *SQLStore) GetIdempotencyKey(key string) (*model.IdempotencyKey, error) {
+	var idempotencyKey model.IdempotencyKey
+	err := sqlStore.getBuilder(sqlStore.db, &idempotencyKey,
+		idempotencyKeySelect.Where("ID = ?", key),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get idempotency key")
+	}
+
+	return &idempotencyKey, nil
+}
+
+// ReserveIdempotencyKey records the given idempotency key, with no resource
+// yet, unless the key is already recorded. It returns whether the key was
+// reserved, so that only one of the requests using the key creates its
+// resource.
+func (sqlStore *SQLStore) ReserveIdempotencyKey(idempotencyKey *model.IdempotencyKey) (bool, error) {
+	idempotencyKey.ResourceID = ""
+	idempotencyKey.CreateAt = GetMillis()
+
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("IdempotencyKeys").
+		SetMap(map[string]interface{}{
+			"ID":           idempotencyKey.ID,
+			"ResourceType": idempotencyKey.ResourceType,
+			"ResourceID":   idempotencyKey.ResourceID,
+			"CreateAt":     idempotencyKey.CreateAt,
+		}).
+		Suffix("ON CONFLICT (ID) DO NOTHING"),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to reserve idempotency key")
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to count rows affected")
+	}
+
+	return count == 1, nil
+}
+
+// CompleteIdempotencyKey records the resource created with the reserved
+// idempotency key.
+func (sqlStore *SQLStore) CompleteIdempotencyKey(key, resourceID string) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("IdempotencyKeys").
+		Set("ResourceID", resourceID).
+		Where("ID = ?", key),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to complete idempotency key")
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes the given reservation of an idempotency key,
+// unless a resource was recorded with it since, so that the key can be used
+// again.
+func (sqlStore *SQLStore) ReleaseIdempotencyKey(idempotencyKey *model.IdempotencyKey) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Delete("IdempotencyKeys").
+		Where(sq.Eq{
+			"ID":         idempotencyKey.ID,
+			"ResourceID": "",
+			"CreateAt":   idempotencyKey.CreateAt,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to release idempotency key")
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	idempotencyKey, err := sqlStore.GetIdempotencyKey("key1")
+	require.NoError(t, err)
+	require.Nil(t, idempotencyKey)
+
+	key1 := &model.IdempotencyKey{ID: "key1", ResourceType: model.TypeRing}
+	reserved, err := sqlStore.ReserveIdempotencyKey(key1)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	require.NotZero(t, key1.CreateAt)
+
+	reserved, err = sqlStore.ReserveIdempotencyKey(&model.IdempotencyKey{ID: "key1", ResourceType: model.TypeWebhook})
+	require.NoError(t, err)
+	require.False(t, reserved)
+
+	idempotencyKey, err = sqlStore.GetIdempotencyKey("key1")
+	require.NoError(t, err)
+	require.Equal(t, key1, idempotencyKey)
+
+	t.Run("release", func(t *testing.T) {
+		require.NoError(t, sqlStore.ReleaseIdempotencyKey(key1))
+
+		idempotencyKey, err = sqlStore.GetIdempotencyKey("key1")
+		require.NoError(t, err)
+		require.Nil(t, idempotencyKey)
+
+		reserved, err = sqlStore.ReserveIdempotencyKey(key1)
+		require.NoError(t, err)
+		require.True(t, reserved)
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		resourceID := model.NewID()
+		require.NoError(t, sqlStore.CompleteIdempotencyKey("key1", resourceID))
+
+		// Completed keys are no longer released.
+		require.NoError(t, sqlStore.ReleaseIdempotencyKey(key1))
+
+		idempotencyKey, err = sqlStore.GetIdempotencyKey("key1")
+		require.NoError(t, err)
+		require.Equal(t, resourceID, idempotencyKey.ResourceID)
+	})
+}
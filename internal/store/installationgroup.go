@@ -7,6 +7,7 @@ package store
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/mattermost/elrond/model"
@@ -25,23 +26,127 @@ var installationGroupColumns = []string{
 	"InstallationGroup.SoakTime",
 	"InstallationGroup.ReleaseAt",
 	"InstallationGroup.ProvisionerGroupID",
+	"InstallationGroup.Annotations",
+	"InstallationGroup.Metadata",
+	"InstallationGroup.FailureDomain",
+	"InstallationGroup.ForceRelease",
+	"InstallationGroup.ReleaseSoakTime",
+	"InstallationGroup.Drifted",
+	"InstallationGroup.ObservedRelease",
+	"InstallationGroup.ReleaseProgress",
+	"InstallationGroup.StateMachineVersion",
+	"InstallationGroup.WorkPriority",
+	"InstallationGroup.ReleaseStrategy",
+	"InstallationGroup.GreenProvisionerGroupID",
+	"InstallationGroup.ReleaseLoadThreshold",
+	"InstallationGroup.ReleaseLoadMaxWait",
+	"InstallationGroup.LoadDeferredAt",
+	"InstallationGroup.SoakChecks",
+	"InstallationGroup.ReleaseWindows",
+	"InstallationGroup.VerificationURL",
+	"InstallationGroup.VerificationTimeout",
+	"InstallationGroup.VerificationJobID",
+	"InstallationGroup.VerificationStatus",
+	"InstallationGroup.VerificationOutputURL",
+	"InstallationGroup.VerificationStartAt",
+	"InstallationGroup.DatabaseSnapshot",
+	"InstallationGroup.ActiveReleaseID",
+	"InstallationGroup.PreviousReleaseID",
+	"InstallationGroup.InstallationCount",
+	"InstallationGroup.CustomerCount",
+	"InstallationGroup.CapacityTarget",
+	"InstallationGroup.CapacitySyncedAt",
+	"InstallationGroup.ArchivedAt",
 	"InstallationGroup.LockAcquiredBy",
 	"InstallationGroup.LockAcquiredAt",
 }
 
 type ringInstallationGroup struct {
-	RingID                              string
-	InstallationGroupID                 string
-	InstallationGroupName               string
-	InstallationGroupState              string
-	InstallationGroupReleaseAt          int64
-	InstallationGroupSoakTime           int
-	InstallationGroupProvisionerGroupID string
+	RingID                                   string
+	InstallationGroupID                      string
+	InstallationGroupName                    string
+	InstallationGroupState                   string
+	InstallationGroupReleaseAt               int64
+	InstallationGroupSoakTime                int
+	InstallationGroupProvisionerGroupID      string
+	InstallationGroupAnnotations             model.Annotations
+	InstallationGroupFailureDomain           string
+	InstallationGroupForceRelease            bool
+	InstallationGroupMetadata                model.Metadata
+	InstallationGroupReleaseSoakTime         int
+	InstallationGroupDrifted                 bool
+	InstallationGroupObservedRelease         string
+	InstallationGroupReleaseProgress         int
+	InstallationGroupStateMachineVersion     int
+	InstallationGroupReleaseStrategy         string
+	InstallationGroupGreenProvisionerGroupID string
+	InstallationGroupReleaseLoadThreshold    int64
+	InstallationGroupReleaseLoadMaxWait      int
+	InstallationGroupLoadDeferredAt          int64
+	InstallationGroupSoakChecks              model.SoakChecks
+	InstallationGroupReleaseWindows          model.TimeWindows
+	InstallationGroupVerificationURL         string
+	InstallationGroupVerificationTimeout     int
+	InstallationGroupVerificationJobID       string
+	InstallationGroupVerificationStatus      string
+	InstallationGroupVerificationOutputURL   string
+	InstallationGroupVerificationStartAt     int64
+	InstallationGroupDatabaseSnapshot        bool
+	InstallationGroupActiveReleaseID         string
+	InstallationGroupPreviousReleaseID       string
+	InstallationGroupInstallationCount       int64
+	InstallationGroupCustomerCount           int64
+	InstallationGroupCapacityTarget          int64
+	InstallationGroupCapacitySyncedAt        int64
+	InstallationGroupArchivedAt              int64
+	InstallationGroupLockAcquiredBy          *string
+	InstallationGroupLockAcquiredAt          int64
 }
 
+// installationGroupWork is an installation group joined with its ring and the
+// desired release of the ring, which is null if the ring has none.
+type installationGroupWork struct {
+	InstallationGroup model.InstallationGroup
+	Ring              model.Ring
+	Release           struct {
+		ID         sql.NullString
+		Image      sql.NullString
+		Version    sql.NullString
+		CreateAt   sql.NullInt64
+		Force      sql.NullBool
+		SoakTime   sql.NullInt64
+		Type       sql.NullString
+		Parameters model.ReleaseParameters
+		Checksum   sql.NullString
+		Immutable  sql.NullBool
+		Links      model.ReleaseLinks
+		Labels     model.ReleaseLabels
+	}
+}
+
+var installationGroupWorkSelect sq.SelectBuilder
+
 func init() {
 	installationGroupSelect = sq.Select(installationGroupColumns...).
 		From("InstallationGroup")
+
+	// The columns of each table are aliased after the field of
+	// installationGroupWork they are scanned into.
+	var columns []string
+	alias := func(field string, tableColumns []string) {
+		for _, column := range tableColumns {
+			columns = append(columns, fmt.Sprintf(`%s AS "%s.%s"`, column, field, column[strings.Index(column, ".")+1:]))
+		}
+	}
+	alias("InstallationGroup", installationGroupColumns)
+	alias("Ring", ringColumns)
+	alias("Release", ringReleaseColumns)
+
+	installationGroupWorkSelect = sq.Select(columns...).
+		From("InstallationGroup").
+		Join(fmt.Sprintf("%s ON %s.InstallationGroupID = InstallationGroup.ID", ringInstallationGroupTable, ringInstallationGroupTable)).
+		Join(fmt.Sprintf("Ring ON Ring.ID = %s.RingID", ringInstallationGroupTable)).
+		LeftJoin("RingRelease ON RingRelease.ID = Ring.DesiredReleaseID")
 }
 
 // GetInstallationGroupByName fetches the given installation group by name.
@@ -95,17 +200,48 @@ func (sqlStore *SQLStore) CreateInstallationGroup(installationGroup *model.Insta
 
 func (sqlStore *SQLStore) createInstallationGroup(db execer, installationGroup *model.InstallationGroup) error {
 	installationGroup.ID = model.NewID()
+	installationGroup.StateMachineVersion = installationGroup.CurrentStateMachineVersion()
 
 	_, err := sqlStore.execBuilder(db, sq.Insert("InstallationGroup").
 		SetMap(map[string]interface{}{
-			"ID":                 installationGroup.ID,
-			"Name":               installationGroup.Name,
-			"State":              installationGroup.State,
-			"ReleaseAt":          installationGroup.ReleaseAt,
-			"SoakTime":           installationGroup.SoakTime,
-			"ProvisionerGroupID": installationGroup.ProvisionerGroupID,
-			"LockAcquiredBy":     nil,
-			"LockAcquiredAt":     0,
+			"ID":                      installationGroup.ID,
+			"Name":                    installationGroup.Name,
+			"State":                   installationGroup.State,
+			"ReleaseAt":               installationGroup.ReleaseAt,
+			"SoakTime":                installationGroup.SoakTime,
+			"ProvisionerGroupID":      installationGroup.ProvisionerGroupID,
+			"Annotations":             installationGroup.Annotations,
+			"Metadata":                installationGroup.Metadata,
+			"FailureDomain":           installationGroup.FailureDomain,
+			"ForceRelease":            false,
+			"ReleaseSoakTime":         installationGroup.ReleaseSoakTime,
+			"Drifted":                 false,
+			"ObservedRelease":         "",
+			"ReleaseProgress":         0,
+			"StateMachineVersion":     installationGroup.StateMachineVersion,
+			"ReleaseStrategy":         installationGroup.ReleaseStrategy,
+			"GreenProvisionerGroupID": "",
+			"ReleaseLoadThreshold":    installationGroup.ReleaseLoadThreshold,
+			"ReleaseLoadMaxWait":      installationGroup.ReleaseLoadMaxWait,
+			"LoadDeferredAt":          0,
+			"SoakChecks":              installationGroup.SoakChecks,
+			"ReleaseWindows":          installationGroup.ReleaseWindows,
+			"VerificationURL":         installationGroup.VerificationURL,
+			"VerificationTimeout":     installationGroup.VerificationTimeout,
+			"VerificationJobID":       "",
+			"VerificationStatus":      "",
+			"VerificationOutputURL":   "",
+			"VerificationStartAt":     0,
+			"DatabaseSnapshot":        installationGroup.DatabaseSnapshot,
+			"ActiveReleaseID":         "",
+			"PreviousReleaseID":       "",
+			"InstallationCount":       installationGroup.InstallationCount,
+			"CustomerCount":           installationGroup.CustomerCount,
+			"CapacityTarget":          installationGroup.CapacityTarget,
+			"CapacitySyncedAt":        0,
+			"ArchivedAt":              0,
+			"LockAcquiredBy":          nil,
+			"LockAcquiredAt":          0,
 		}))
 	if err != nil {
 		return errors.Wrap(err, "failed to create installation group")
@@ -191,7 +327,8 @@ func (sqlStore *SQLStore) getInstallationGroupsForRing(db dbInterface, ringID st
 	return installationGroups, nil
 }
 
-// GetRingFromInstallationGroupID gets the ring that has the associated installation group.
+// GetRingFromInstallationGroupID gets the ring that has the associated installation group,
+// or nil if the installation group is not registered to any ring.
 func (sqlStore *SQLStore) GetRingFromInstallationGroupID(installationGroupID string) (*model.Ring, error) {
 	return sqlStore.getRingFromInstallationGroupID(sqlStore.db, installationGroupID)
 }
@@ -206,6 +343,9 @@ func (sqlStore *SQLStore) getRingFromInstallationGroupID(db dbInterface, install
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get installation groups for Ring")
 	}
+	if len(ringID) == 0 {
+		return nil, nil
+	}
 
 	return sqlStore.GetRing(ringID[0])
 }
@@ -253,7 +393,39 @@ func (sqlStore *SQLStore) GetInstallationGroupsForRings(filter *model.RingFilter
 		"InstallationGroup.State as InstallationGroupState",
 		"InstallationGroup.ReleaseAt as InstallationGroupReleaseAt",
 		"InstallationGroup.SoakTime as InstallationGroupSoakTime",
-		"InstallationGroup.ProvisionerGroupID as InstallationGroupProvisionerGroupID").
+		"InstallationGroup.ProvisionerGroupID as InstallationGroupProvisionerGroupID",
+		"InstallationGroup.Annotations as InstallationGroupAnnotations",
+		"InstallationGroup.Metadata as InstallationGroupMetadata",
+		"InstallationGroup.FailureDomain as InstallationGroupFailureDomain",
+		"InstallationGroup.ForceRelease as InstallationGroupForceRelease",
+		"InstallationGroup.ReleaseSoakTime as InstallationGroupReleaseSoakTime",
+		"InstallationGroup.Drifted as InstallationGroupDrifted",
+		"InstallationGroup.ObservedRelease as InstallationGroupObservedRelease",
+		"InstallationGroup.ReleaseProgress as InstallationGroupReleaseProgress",
+		"InstallationGroup.StateMachineVersion as InstallationGroupStateMachineVersion",
+		"InstallationGroup.ReleaseStrategy as InstallationGroupReleaseStrategy",
+		"InstallationGroup.GreenProvisionerGroupID as InstallationGroupGreenProvisionerGroupID",
+		"InstallationGroup.ReleaseLoadThreshold as InstallationGroupReleaseLoadThreshold",
+		"InstallationGroup.ReleaseLoadMaxWait as InstallationGroupReleaseLoadMaxWait",
+		"InstallationGroup.LoadDeferredAt as InstallationGroupLoadDeferredAt",
+		"InstallationGroup.SoakChecks as InstallationGroupSoakChecks",
+		"InstallationGroup.ReleaseWindows as InstallationGroupReleaseWindows",
+		"InstallationGroup.VerificationURL as InstallationGroupVerificationURL",
+		"InstallationGroup.VerificationTimeout as InstallationGroupVerificationTimeout",
+		"InstallationGroup.VerificationJobID as InstallationGroupVerificationJobID",
+		"InstallationGroup.VerificationStatus as InstallationGroupVerificationStatus",
+		"InstallationGroup.VerificationOutputURL as InstallationGroupVerificationOutputURL",
+		"InstallationGroup.VerificationStartAt as InstallationGroupVerificationStartAt",
+		"InstallationGroup.DatabaseSnapshot as InstallationGroupDatabaseSnapshot",
+		"InstallationGroup.ActiveReleaseID as InstallationGroupActiveReleaseID",
+		"InstallationGroup.PreviousReleaseID as InstallationGroupPreviousReleaseID",
+		"InstallationGroup.InstallationCount as InstallationGroupInstallationCount",
+		"InstallationGroup.CustomerCount as InstallationGroupCustomerCount",
+		"InstallationGroup.CapacityTarget as InstallationGroupCapacityTarget",
+		"InstallationGroup.CapacitySyncedAt as InstallationGroupCapacitySyncedAt",
+		"InstallationGroup.ArchivedAt as InstallationGroupArchivedAt",
+		"InstallationGroup.LockAcquiredBy as InstallationGroupLockAcquiredBy",
+		"InstallationGroup.LockAcquiredAt as InstallationGroupLockAcquiredAt").
 		From("Ring").
 		LeftJoin(fmt.Sprintf("%s ON %s.RingID = Ring.ID", ringInstallationGroupTable, ringInstallationGroupTable)).
 		Join("InstallationGroup ON InstallationGroup.ID=InstallationGroupID")
@@ -269,12 +441,44 @@ func (sqlStore *SQLStore) GetInstallationGroupsForRings(filter *model.RingFilter
 		installationGroups[rig.RingID] = append(
 			installationGroups[rig.RingID],
 			&model.InstallationGroup{
-				ID:                 rig.InstallationGroupID,
-				Name:               rig.InstallationGroupName,
-				State:              rig.InstallationGroupState,
-				ReleaseAt:          rig.InstallationGroupReleaseAt,
-				SoakTime:           rig.InstallationGroupSoakTime,
-				ProvisionerGroupID: rig.InstallationGroupProvisionerGroupID,
+				ID:                      rig.InstallationGroupID,
+				Name:                    rig.InstallationGroupName,
+				State:                   rig.InstallationGroupState,
+				ReleaseAt:               rig.InstallationGroupReleaseAt,
+				SoakTime:                rig.InstallationGroupSoakTime,
+				ProvisionerGroupID:      rig.InstallationGroupProvisionerGroupID,
+				Annotations:             rig.InstallationGroupAnnotations,
+				Metadata:                rig.InstallationGroupMetadata,
+				FailureDomain:           rig.InstallationGroupFailureDomain,
+				ForceRelease:            rig.InstallationGroupForceRelease,
+				ReleaseSoakTime:         rig.InstallationGroupReleaseSoakTime,
+				Drifted:                 rig.InstallationGroupDrifted,
+				ObservedRelease:         rig.InstallationGroupObservedRelease,
+				ReleaseProgress:         rig.InstallationGroupReleaseProgress,
+				StateMachineVersion:     rig.InstallationGroupStateMachineVersion,
+				ReleaseStrategy:         rig.InstallationGroupReleaseStrategy,
+				GreenProvisionerGroupID: rig.InstallationGroupGreenProvisionerGroupID,
+				ReleaseLoadThreshold:    rig.InstallationGroupReleaseLoadThreshold,
+				ReleaseLoadMaxWait:      rig.InstallationGroupReleaseLoadMaxWait,
+				LoadDeferredAt:          rig.InstallationGroupLoadDeferredAt,
+				SoakChecks:              rig.InstallationGroupSoakChecks,
+				ReleaseWindows:          rig.InstallationGroupReleaseWindows,
+				VerificationURL:         rig.InstallationGroupVerificationURL,
+				VerificationTimeout:     rig.InstallationGroupVerificationTimeout,
+				VerificationJobID:       rig.InstallationGroupVerificationJobID,
+				VerificationStatus:      rig.InstallationGroupVerificationStatus,
+				VerificationOutputURL:   rig.InstallationGroupVerificationOutputURL,
+				VerificationStartAt:     rig.InstallationGroupVerificationStartAt,
+				DatabaseSnapshot:        rig.InstallationGroupDatabaseSnapshot,
+				ActiveReleaseID:         rig.InstallationGroupActiveReleaseID,
+				PreviousReleaseID:       rig.InstallationGroupPreviousReleaseID,
+				InstallationCount:       rig.InstallationGroupInstallationCount,
+				CustomerCount:           rig.InstallationGroupCustomerCount,
+				CapacityTarget:          rig.InstallationGroupCapacityTarget,
+				CapacitySyncedAt:        rig.InstallationGroupCapacitySyncedAt,
+				ArchivedAt:              rig.InstallationGroupArchivedAt,
+				LockAcquiredBy:          rig.InstallationGroupLockAcquiredBy,
+				LockAcquiredAt:          rig.InstallationGroupLockAcquiredAt,
 			},
 		)
 	}
@@ -292,11 +496,15 @@ func (sqlStore *SQLStore) DeleteRingInstallationGroup(ringID string, installatio
 		return nil
 	}
 
+	return sqlStore.deleteRingInstallationGroup(sqlStore.db, ringID, installationGroup.ID)
+}
+
+func (sqlStore *SQLStore) deleteRingInstallationGroup(db execer, ringID, installationGroupID string) error {
 	builder := sq.Delete(ringInstallationGroupTable).
 		Where("RingID = ?", ringID).
-		Where("InstallationGroupID = ?", installationGroup.ID)
+		Where("InstallationGroupID = ?", installationGroupID)
 
-	_, err = sqlStore.execBuilder(sqlStore.db, builder)
+	_, err := sqlStore.execBuilder(db, builder)
 	if err != nil {
 		return errors.Wrap(err, "failed to delete ring installation group")
 	}
@@ -376,19 +584,214 @@ func (sqlStore *SQLStore) GetRingInstallationGroupsPendingWork(ringID string) ([
 
 // UpdateInstallationGroup updates the given installation group in the database.
 func (sqlStore *SQLStore) UpdateInstallationGroup(installationGroup *model.InstallationGroup) error {
+	return sqlStore.updateInstallationGroup(sqlStore.db, installationGroup)
+}
+
+func (sqlStore *SQLStore) updateInstallationGroup(db execer, installationGroup *model.InstallationGroup) error {
+	update := sq.
+		Update("InstallationGroup").
+		SetMap(map[string]interface{}{
+			"Name":                 installationGroup.Name,
+			"State":                installationGroup.State,
+			"ReleaseAt":            installationGroup.ReleaseAt,
+			"SoakTime":             installationGroup.SoakTime,
+			"ProvisionerGroupID":   installationGroup.ProvisionerGroupID,
+			"Annotations":          installationGroup.Annotations,
+			"Metadata":             installationGroup.Metadata,
+			"FailureDomain":        installationGroup.FailureDomain,
+			"ForceRelease":         installationGroup.ForceRelease,
+			"ReleaseSoakTime":      installationGroup.ReleaseSoakTime,
+			"StateMachineVersion":  installationGroup.StateMachineVersion,
+			"ReleaseStrategy":      installationGroup.ReleaseStrategy,
+			"ReleaseLoadThreshold": installationGroup.ReleaseLoadThreshold,
+			"ReleaseLoadMaxWait":   installationGroup.ReleaseLoadMaxWait,
+			"SoakChecks":           installationGroup.SoakChecks,
+			"ReleaseWindows":       installationGroup.ReleaseWindows,
+			"VerificationURL":      installationGroup.VerificationURL,
+			"VerificationTimeout":  installationGroup.VerificationTimeout,
+			"DatabaseSnapshot":     installationGroup.DatabaseSnapshot,
+			"InstallationCount":    installationGroup.InstallationCount,
+			"CustomerCount":        installationGroup.CustomerCount,
+			"CapacityTarget":       installationGroup.CapacityTarget,
+			"CapacitySyncedAt":     installationGroup.CapacitySyncedAt,
+		}).
+		Where("ID = ?", installationGroup.ID)
+	if _, err := sqlStore.execBuilder(db, clearFinishedWorkPriority(update, installationGroup.State, model.AllInstallationGroupStatesPendingWork)); err != nil {
+		return errors.Wrap(err, "failed to update installation group")
+	}
+
+	return nil
+}
 
+// DelayRingInstallationGroupsSoak moves forward, by the given delay in
+// nanoseconds, the release time of the soaking installation groups of the
+// given ring, so that the delay does not count towards their soak time.
+func (sqlStore *SQLStore) DelayRingInstallationGroupsSoak(ringID string, delay int64) error {
+	if _, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("InstallationGroup").
+		Set("ReleaseAt", sq.Expr("ReleaseAt + ?", delay)).
+		Where(sq.Eq{"State": model.InstallationGroupReleaseSoakingRequested}).
+		Where(sq.Expr("ID IN (SELECT InstallationGroupID FROM RingInstallationGroup WHERE RingID = ?)", ringID)),
+	); err != nil {
+		return errors.Wrap(err, "failed to delay soak of ring installation groups")
+	}
+
+	return nil
+}
+
+// UpdateInstallationGroupDrift records whether the given installation group
+// has drifted from its expected release, and the release it was observed
+// running. Only the drift columns are written, so concurrent updates by the
+// supervisors are not overwritten.
+func (sqlStore *SQLStore) UpdateInstallationGroupDrift(installationGroupID string, drifted bool, observedRelease string) error {
 	if _, err := sqlStore.execBuilder(sqlStore.db, sq.
 		Update("InstallationGroup").
 		SetMap(map[string]interface{}{
-			"Name":               installationGroup.Name,
-			"State":              installationGroup.State,
-			"ReleaseAt":          installationGroup.ReleaseAt,
-			"SoakTime":           installationGroup.SoakTime,
-			"ProvisionerGroupID": installationGroup.ProvisionerGroupID,
+			"Drifted":         drifted,
+			"ObservedRelease": observedRelease,
 		}).
-		Where("ID = ?", installationGroup.ID),
+		Where("ID = ?", installationGroupID),
 	); err != nil {
-		return errors.Wrap(err, "failed to update installation group")
+		return errors.Wrap(err, "failed to update installation group drift")
+	}
+
+	return nil
+}
+
+// UpdateInstallationGroupCapacity records the installation and customer
+// counts of the given installation group, as synced from the provisioner at
+// the given time, in milliseconds. Only the capacity columns are written.
+func (sqlStore *SQLStore) UpdateInstallationGroupCapacity(installationGroupID string, installations, customers, syncedAt int64) error {
+	if _, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("InstallationGroup").
+		SetMap(map[string]interface{}{
+			"InstallationCount": installations,
+			"CustomerCount":     customers,
+			"CapacitySyncedAt":  syncedAt,
+		}).
+		Where("ID = ?", installationGroupID),
+	); err != nil {
+		return errors.Wrap(err, "failed to update installation group capacity")
+	}
+
+	return nil
+}
+
+// GetInstallationGroupsByProvisionerGroupID fetches the installation groups
+// backed by the given provisioner group.
+func (sqlStore *SQLStore) GetInstallationGroupsByProvisionerGroupID(provisionerGroupID string) ([]*model.InstallationGroup, error) {
+	var installationGroups []*model.InstallationGroup
+	err := sqlStore.selectBuilder(sqlStore.db, &installationGroups,
+		installationGroupSelect.Where("ProvisionerGroupID = ?", provisionerGroupID),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get installation groups by provisioner group ID")
+	}
+
+	return installationGroups, nil
+}
+
+// UpdateInstallationGroupReleaseProgress records the progress of the current
+// release of the given installation group. Only the progress column is
+// written, so concurrent updates by the supervisors are not overwritten.
+func (sqlStore *SQLStore) UpdateInstallationGroupReleaseProgress(installationGroupID string, progress int) error {
+	if _, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("InstallationGroup").
+		Set("ReleaseProgress", progress).
+		Where("ID = ?", installationGroupID),
+	); err != nil {
+		return errors.Wrap(err, "failed to update installation group release progress")
+	}
+
+	return nil
+}
+
+// UpdateInstallationGroupLoadDeferredAt records the time, in nanoseconds,
+// since which the release of the given installation group is deferred by the
+// load of its installations, or zero once it is no longer deferred. Only the
+// deferral column is written, so concurrent updates by the supervisors are not
+// overwritten.
+func (sqlStore *SQLStore) UpdateInstallationGroupLoadDeferredAt(installationGroupID string, deferredAt int64) error {
+	if _, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("InstallationGroup").
+		Set("LoadDeferredAt", deferredAt).
+		Where("ID = ?", installationGroupID),
+	); err != nil {
+		return errors.Wrap(err, "failed to update installation group load deferral")
+	}
+
+	return nil
+}
+
+// UpdateInstallationGroupArchivedAt records the time, in milliseconds, the
+// given installation group was archived at, or zero to unarchive it. Only the
+// archive column is written, so concurrent updates by the supervisors are not
+// overwritten.
+func (sqlStore *SQLStore) UpdateInstallationGroupArchivedAt(installationGroupID string, archivedAt int64) error {
+	if _, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("InstallationGroup").
+		Set("ArchivedAt", archivedAt).
+		Where("ID = ?", installationGroupID),
+	); err != nil {
+		return errors.Wrap(err, "failed to update installation group archive")
+	}
+
+	return nil
+}
+
+// UpdateInstallationGroupVerification records the verification job of the
+// current release of the given installation group, started at the given
+// time in nanoseconds. An empty job clears it. Only the verification job
+// columns are written, so concurrent updates by the supervisors are not
+// overwritten.
+func (sqlStore *SQLStore) UpdateInstallationGroupVerification(installationGroupID string, job *model.VerificationJob, startAt int64) error {
+	if _, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("InstallationGroup").
+		SetMap(map[string]interface{}{
+			"VerificationJobID":     job.ID,
+			"VerificationStatus":    job.Status,
+			"VerificationOutputURL": job.OutputURL,
+			"VerificationStartAt":   startAt,
+		}).
+		Where("ID = ?", installationGroupID),
+	); err != nil {
+		return errors.Wrap(err, "failed to update installation group verification")
+	}
+
+	return nil
+}
+
+// UpdateInstallationGroupActiveRelease records the release the given
+// installation group last completed, and the one it ran before, which it is
+// rolled back to. Only the release columns are written, so concurrent updates
+// by the supervisors are not overwritten.
+func (sqlStore *SQLStore) UpdateInstallationGroupActiveRelease(installationGroupID, activeReleaseID, previousReleaseID string) error {
+	if _, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("InstallationGroup").
+		Set("ActiveReleaseID", activeReleaseID).
+		Set("PreviousReleaseID", previousReleaseID).
+		Where("ID = ?", installationGroupID),
+	); err != nil {
+		return errors.Wrap(err, "failed to update installation group active release")
+	}
+
+	return nil
+}
+
+// UpdateInstallationGroupProvisionerGroups records the provisioner group and
+// the green provisioner group of a blue/green release of the given
+// installation group. Only the provisioner group columns are written, so
+// concurrent updates by the supervisors are not overwritten.
+func (sqlStore *SQLStore) UpdateInstallationGroupProvisionerGroups(installationGroupID, provisionerGroupID, greenProvisionerGroupID string) error {
+	if _, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("InstallationGroup").
+		SetMap(map[string]interface{}{
+			"ProvisionerGroupID":      provisionerGroupID,
+			"GreenProvisionerGroupID": greenProvisionerGroupID,
+		}).
+		Where("ID = ?", installationGroupID),
+	); err != nil {
+		return errors.Wrap(err, "failed to update installation group provisioner groups")
 	}
 
 	return nil
@@ -411,6 +814,114 @@ func (sqlStore *SQLStore) LockRingInstallationGroup(installationGroupID, lockerI
 	return sqlStore.lockRows("InstallationGroup", []string{installationGroupID}, lockerID)
 }
 
+// LockInstallationGroupsPendingWork locks up to limit unlocked installation
+// groups pending work for exclusive use by the caller. It returns the locked
+// installation groups along with their rings and releases, and the number of
+// installation groups pending work already locked by others. The ring of an
+// installation group not registered to any ring is nil.
+func (sqlStore *SQLStore) LockInstallationGroupsPendingWork(lockerID string, limit int) ([]*model.InstallationGroupWork, int, error) {
+	ids, contended, err := sqlStore.lockRowsPendingWork("InstallationGroup", model.AllInstallationGroupStatesPendingWork, installationGroupWorkOrder, lockerID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(ids) == 0 {
+		return nil, contended, nil
+	}
+
+	work, err := sqlStore.getInstallationGroupsWork(ids)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to query for locked installation groups pending work")
+	}
+
+	if len(work) < len(ids) {
+		registered := make(map[string]bool, len(work))
+		for _, w := range work {
+			registered[w.InstallationGroup.ID] = true
+		}
+		var unregisteredIDs []string
+		for _, id := range ids {
+			if !registered[id] {
+				unregisteredIDs = append(unregisteredIDs, id)
+			}
+		}
+
+		var unregistered []*model.InstallationGroup
+		err = sqlStore.selectBuilder(sqlStore.db, &unregistered, installationGroupSelect.
+			Where(sq.Eq{"ID": unregisteredIDs}),
+		)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to query for locked installation groups not registered to any ring")
+		}
+		for _, installationGroup := range unregistered {
+			work = append(work, &model.InstallationGroupWork{InstallationGroup: installationGroup})
+		}
+	}
+
+	return work, contended, nil
+}
+
+// GetInstallationGroupWork fetches the given installation group along with
+// its ring and the release the ring is rolling out, or nil if the
+// installation group does not exist or is not registered to any ring.
+func (sqlStore *SQLStore) GetInstallationGroupWork(installationGroupID string) (*model.InstallationGroupWork, error) {
+	work, err := sqlStore.getInstallationGroupsWork([]string{installationGroupID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get installation group work")
+	}
+	if len(work) == 0 {
+		return nil, nil
+	}
+
+	return work[0], nil
+}
+
+// getInstallationGroupsWork fetches the given installation groups along with
+// their rings and releases in a single query, in the order they are worked
+// on.
+func (sqlStore *SQLStore) getInstallationGroupsWork(installationGroupIDs []string) ([]*model.InstallationGroupWork, error) {
+	var rows []*installationGroupWork
+	err := sqlStore.selectBuilder(sqlStore.db, &rows, installationGroupWorkSelect.
+		Where(sq.Eq{"InstallationGroup.ID": installationGroupIDs}).
+		OrderBy("InstallationGroup.WorkPriority DESC", "InstallationGroup.ID ASC"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	work := make([]*model.InstallationGroupWork, 0, len(rows))
+	for i, row := range rows {
+		// An installation group belongs to a single ring; ignore any
+		// duplicate registration.
+		if i > 0 && row.InstallationGroup.ID == rows[i-1].InstallationGroup.ID {
+			continue
+		}
+
+		w := &model.InstallationGroupWork{
+			InstallationGroup: &row.InstallationGroup,
+			Ring:              &row.Ring,
+		}
+		if row.Release.ID.Valid {
+			w.Release = &model.RingRelease{
+				ID:         row.Release.ID.String,
+				Image:      row.Release.Image.String,
+				Version:    row.Release.Version.String,
+				CreateAt:   row.Release.CreateAt.Int64,
+				Force:      row.Release.Force.Bool,
+				SoakTime:   int(row.Release.SoakTime.Int64),
+				Type:       row.Release.Type.String,
+				Parameters: row.Release.Parameters,
+				Checksum:   row.Release.Checksum.String,
+				Immutable:  row.Release.Immutable.Bool,
+				Links:      row.Release.Links,
+				Labels:     row.Release.Labels,
+			}
+		}
+		work = append(work, w)
+	}
+
+	return work, nil
+}
+
 // UnlockRingInstallationGroup releases a lock previously acquired against a caller.
 func (sqlStore *SQLStore) UnlockRingInstallationGroup(installationGroupID, lockerID string, force bool) (bool, error) {
 	return sqlStore.unlockRows("InstallationGroup", []string{installationGroupID}, lockerID, force)
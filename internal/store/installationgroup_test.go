@@ -7,6 +7,7 @@ package store
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mattermost/elrond/internal/testlib"
 	"github.com/mattermost/elrond/model"
@@ -71,6 +72,19 @@ func TestInstallationGroups_Ring(t *testing.T) {
 		assert.True(t, model.ContainsInstallationGroup(installationGroupsForRing, &installationGroup2))
 	})
 
+	t.Run("installation groups for rings include the lock holder", func(t *testing.T) {
+		locked, err := sqlStore.LockRingInstallationGroup(installationGroup2.ID, "instance1")
+		require.NoError(t, err)
+		require.True(t, locked)
+
+		installationGroups, err := sqlStore.GetInstallationGroupsForRings(&model.RingFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, installationGroups[ring2.ID], 1)
+		require.NotNil(t, installationGroups[ring2.ID][0].LockAcquiredBy)
+		assert.Equal(t, "instance1", *installationGroups[ring2.ID][0].LockAcquiredBy)
+		assert.NotZero(t, installationGroups[ring2.ID][0].LockAcquiredAt)
+	})
+
 	t.Run("delete ring installation group", func(t *testing.T) {
 		err = sqlStore.DeleteRingInstallationGroup(ring1.ID, installationGroup1.ID)
 		require.NoError(t, err)
@@ -89,3 +103,246 @@ func TestInstallationGroups_Ring(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestInstallationGroups_ProvisionerGroups(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	installationGroup1 := model.InstallationGroup{Name: "bluegreen1", ProvisionerGroupID: "blue", ReleaseStrategy: model.ReleaseStrategyBlueGreen}
+	require.NoError(t, sqlStore.CreateInstallationGroup(&installationGroup1))
+
+	installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.ReleaseStrategyBlueGreen, installationGroup.ReleaseStrategy)
+	assert.Empty(t, installationGroup.GreenProvisionerGroupID)
+
+	t.Run("stand up green", func(t *testing.T) {
+		require.NoError(t, sqlStore.UpdateInstallationGroupProvisionerGroups(installationGroup1.ID, "blue", "green"))
+
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "blue", installationGroup.ProvisionerGroupID)
+		assert.Equal(t, "green", installationGroup.GreenProvisionerGroupID)
+	})
+
+	t.Run("full updates keep the green provisioner group", func(t *testing.T) {
+		installationGroup.GreenProvisionerGroupID = ""
+		installationGroup.ReleaseStrategy = model.ReleaseStrategyRolling
+		require.NoError(t, sqlStore.UpdateInstallationGroup(installationGroup))
+
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "green", installationGroup.GreenProvisionerGroupID)
+		assert.Equal(t, model.ReleaseStrategyRolling, installationGroup.ReleaseStrategy)
+	})
+
+	t.Run("switch to green", func(t *testing.T) {
+		require.NoError(t, sqlStore.UpdateInstallationGroupProvisionerGroups(installationGroup1.ID, "green", ""))
+
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "green", installationGroup.ProvisionerGroupID)
+		assert.Empty(t, installationGroup.GreenProvisionerGroupID)
+	})
+}
+
+func TestInstallationGroups_ReleaseProgress(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	installationGroup1 := model.InstallationGroup{Name: "progress1", ProvisionerGroupID: "group1"}
+	installationGroup2 := model.InstallationGroup{Name: "progress2", ProvisionerGroupID: "group2"}
+	require.NoError(t, sqlStore.CreateInstallationGroup(&installationGroup1))
+	require.NoError(t, sqlStore.CreateInstallationGroup(&installationGroup2))
+
+	t.Run("get by provisioner group", func(t *testing.T) {
+		installationGroups, err := sqlStore.GetInstallationGroupsByProvisionerGroupID("group1")
+		require.NoError(t, err)
+		require.Equal(t, []*model.InstallationGroup{&installationGroup1}, installationGroups)
+
+		installationGroups, err = sqlStore.GetInstallationGroupsByProvisionerGroupID("unknown")
+		require.NoError(t, err)
+		require.Empty(t, installationGroups)
+	})
+
+	t.Run("update release progress", func(t *testing.T) {
+		require.NoError(t, sqlStore.UpdateInstallationGroupReleaseProgress(installationGroup1.ID, 40))
+
+		installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 40, installationGroup.ReleaseProgress)
+
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup2.ID)
+		require.NoError(t, err)
+		assert.Zero(t, installationGroup.ReleaseProgress)
+	})
+
+	t.Run("update active release", func(t *testing.T) {
+		require.NoError(t, sqlStore.UpdateInstallationGroupActiveRelease(installationGroup1.ID, "release2", "release1"))
+
+		installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "release2", installationGroup.ActiveReleaseID)
+		assert.Equal(t, "release1", installationGroup.PreviousReleaseID)
+	})
+
+	t.Run("update archive", func(t *testing.T) {
+		require.NoError(t, sqlStore.UpdateInstallationGroupArchivedAt(installationGroup1.ID, 1234))
+
+		installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1234), installationGroup.ArchivedAt)
+
+		require.NoError(t, sqlStore.UpdateInstallationGroupArchivedAt(installationGroup1.ID, 0))
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+		require.NoError(t, err)
+		assert.Zero(t, installationGroup.ArchivedAt)
+	})
+
+	t.Run("update capacity", func(t *testing.T) {
+		require.NoError(t, sqlStore.UpdateInstallationGroupCapacity(installationGroup1.ID, 12, 9, 1234))
+
+		installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), installationGroup.InstallationCount)
+		assert.Equal(t, int64(9), installationGroup.CustomerCount)
+		assert.Equal(t, int64(1234), installationGroup.CapacitySyncedAt)
+
+		installationGroup.CapacityTarget = 20
+		require.NoError(t, sqlStore.UpdateInstallationGroup(installationGroup))
+
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), installationGroup.InstallationCount)
+		assert.Equal(t, int64(20), installationGroup.CapacityTarget)
+		assert.Equal(t, int64(1234), installationGroup.CapacitySyncedAt)
+	})
+
+	t.Run("full updates keep the release progress", func(t *testing.T) {
+		installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+		require.NoError(t, err)
+		installationGroup.ReleaseProgress = 0
+		installationGroup.SoakTime = 60
+		require.NoError(t, sqlStore.UpdateInstallationGroup(installationGroup))
+
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 40, installationGroup.ReleaseProgress)
+		assert.Equal(t, 60, installationGroup.SoakTime)
+	})
+}
+
+func TestInstallationGroups_ReleaseLoad(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	installationGroup1 := model.InstallationGroup{Name: "load1", ReleaseLoadThreshold: 500, ReleaseLoadMaxWait: 1800}
+	require.NoError(t, sqlStore.CreateInstallationGroup(&installationGroup1))
+
+	installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), installationGroup.ReleaseLoadThreshold)
+	assert.Equal(t, 1800, installationGroup.ReleaseLoadMaxWait)
+	assert.Zero(t, installationGroup.LoadDeferredAt)
+
+	require.NoError(t, sqlStore.UpdateInstallationGroupLoadDeferredAt(installationGroup1.ID, 1234))
+	installationGroup.ReleaseLoadThreshold = 0
+	installationGroup.LoadDeferredAt = 0
+	require.NoError(t, sqlStore.UpdateInstallationGroup(installationGroup))
+
+	installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup1.ID)
+	require.NoError(t, err)
+	assert.Zero(t, installationGroup.ReleaseLoadThreshold)
+	assert.Equal(t, int64(1234), installationGroup.LoadDeferredAt)
+}
+
+func TestLockInstallationGroupsPendingWork(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{
+		Image:      "image",
+		Version:    "version",
+		Parameters: model.ReleaseParameters{"pending1": {MattermostEnv: map[string]string{"KEY": "value"}}},
+	})
+	require.NoError(t, err)
+
+	ring1 := model.Ring{Name: "ring1", DesiredReleaseID: release.ID}
+	require.NoError(t, sqlStore.CreateRing(&ring1, nil))
+	ring2 := model.Ring{Name: "ring2"}
+	require.NoError(t, sqlStore.CreateRing(&ring2, nil))
+
+	installationGroup1 := model.InstallationGroup{Name: "pending1", State: model.InstallationGroupReleaseRequested}
+	installationGroup2 := model.InstallationGroup{Name: "pending2", State: model.InstallationGroupReleaseRequested}
+	installationGroup3 := model.InstallationGroup{Name: "stable", State: model.InstallationGroupStable}
+	installationGroup4 := model.InstallationGroup{Name: "unregistered", State: model.InstallationGroupReleaseRequested}
+	_, err = sqlStore.CreateRingInstallationGroup(ring1.ID, &installationGroup1)
+	require.NoError(t, err)
+	_, err = sqlStore.CreateRingInstallationGroup(ring2.ID, &installationGroup2)
+	require.NoError(t, err)
+	_, err = sqlStore.CreateRingInstallationGroup(ring1.ID, &installationGroup3)
+	require.NoError(t, err)
+	require.NoError(t, sqlStore.CreateInstallationGroup(&installationGroup4))
+
+	expectedWork := map[string]*model.InstallationGroupWork{
+		installationGroup1.ID: {Ring: &ring1, Release: release},
+		installationGroup2.ID: {Ring: &ring2},
+	}
+	requireWork := func(t *testing.T, work *model.InstallationGroupWork) {
+		t.Helper()
+		expected, ok := expectedWork[work.InstallationGroup.ID]
+		require.True(t, ok, "unexpected installation group %s", work.InstallationGroup.Name)
+		require.Equal(t, expected.Ring.ID, work.Ring.ID)
+		require.Equal(t, expected.Ring.Name, work.Ring.Name)
+		require.Equal(t, expected.Ring.DesiredReleaseID, work.Ring.DesiredReleaseID)
+		require.Equal(t, expected.Release, work.Release)
+	}
+
+	locker1 := model.NewID()
+	locker2 := model.NewID()
+
+	work, contended, err := sqlStore.LockInstallationGroupsPendingWork(locker1, 2)
+	require.NoError(t, err)
+	require.Zero(t, contended)
+	require.Len(t, work, 2)
+
+	time.Sleep(1 * time.Millisecond)
+
+	moreWork, contended, err := sqlStore.LockInstallationGroupsPendingWork(locker2, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, contended)
+	require.Len(t, moreWork, 1)
+	work = append(work, moreWork...)
+
+	var unregistered int
+	for _, w := range work {
+		if w.InstallationGroup.ID == installationGroup4.ID {
+			unregistered++
+			require.Nil(t, w.Ring)
+			require.Nil(t, w.Release)
+			continue
+		}
+		requireWork(t, w)
+		require.NotNil(t, w.InstallationGroup.LockAcquiredBy)
+		require.NotZero(t, w.InstallationGroup.LockAcquiredAt)
+	}
+	require.Equal(t, 1, unregistered)
+
+	t.Run("get installation group work", func(t *testing.T) {
+		work, err := sqlStore.GetInstallationGroupWork(installationGroup1.ID)
+		require.NoError(t, err)
+		requireWork(t, work)
+
+		work, err = sqlStore.GetInstallationGroupWork(installationGroup4.ID)
+		require.NoError(t, err)
+		require.Nil(t, work)
+
+		work, err = sqlStore.GetInstallationGroupWork(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, work)
+	})
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var jobSelect sq.SelectBuilder
+
+func init() {
+	jobSelect = sq.
+		Select("ID", "Type", "Parameters", "State", "Result", "Message", "Attempts",
+			"MaxAttempts", "CreatedBy", "CreateAt", "StartAt", "EndAt", "LockAcquiredBy",
+			"LockAcquiredAt").
+		From("Job")
+}
+
+// GetJob fetches the given job by id.
+func (sqlStore *SQLStore) GetJob(id string) (*model.Job, error) {
+	var job model.Job
+	err := sqlStore.getBuilder(sqlStore.db, &job, jobSelect.Where("ID = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get job by id")
+	}
+
+	return &job, nil
+}
+
+// GetJobs fetches the given page of jobs, newest first. The first page is 0.
+func (sqlStore *SQLStore) GetJobs(filter *model.JobFilter) ([]*model.Job, error) {
+	builder := jobSelect.
+		OrderBy("CreateAt DESC", "ID DESC")
+	if filter.Type != "" {
+		builder = builder.Where("Type = ?", filter.Type)
+	}
+	if filter.State != "" {
+		builder = builder.Where("State = ?", filter.State)
+	}
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	var jobs []*model.Job
+	err := sqlStore.selectBuilder(sqlStore.db, &jobs, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for jobs")
+	}
+
+	return jobs, nil
+}
+
+// CreateJob records the given job, assigning it a unique ID.
+func (sqlStore *SQLStore) CreateJob(job *model.Job) error {
+	job.ID = model.NewID()
+	job.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("Job").
+		SetMap(map[string]interface{}{
+			"ID":             job.ID,
+			"Type":           job.Type,
+			"Parameters":     job.Parameters,
+			"State":          job.State,
+			"Result":         job.Result,
+			"Message":        job.Message,
+			"Attempts":       job.Attempts,
+			"MaxAttempts":    job.MaxAttempts,
+			"CreatedBy":      job.CreatedBy,
+			"CreateAt":       job.CreateAt,
+			"StartAt":        job.StartAt,
+			"EndAt":          job.EndAt,
+			"LockAcquiredBy": nil,
+			"LockAcquiredAt": 0,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create job")
+	}
+
+	return nil
+}
+
+// UpdateJob updates the progress and state of the given job.
+func (sqlStore *SQLStore) UpdateJob(job *model.Job) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Job").
+		SetMap(map[string]interface{}{
+			"State":    job.State,
+			"Result":   job.Result,
+			"Message":  job.Message,
+			"Attempts": job.Attempts,
+			"StartAt":  job.StartAt,
+			"EndAt":    job.EndAt,
+		}).
+		Where("ID = ?", job.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update job")
+	}
+
+	return nil
+}
+
+// LockJobsPendingWork locks up to limit unlocked jobs pending work, oldest
+// first, for exclusive use by the caller. It returns the locked jobs and the
+// number of jobs pending work already locked by others.
+func (sqlStore *SQLStore) LockJobsPendingWork(lockerID string, limit int) ([]*model.Job, int, error) {
+	ids, contended, err := sqlStore.lockRowsPendingWork("Job", model.AllJobStatesPendingWork, "CreateAt ASC", lockerID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(ids) == 0 {
+		return nil, contended, nil
+	}
+
+	var jobs []*model.Job
+	err = sqlStore.selectBuilder(sqlStore.db, &jobs, jobSelect.
+		Where(sq.Eq{"ID": ids}).
+		OrderBy("CreateAt ASC"),
+	)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to query for locked jobs pending work")
+	}
+
+	return jobs, contended, nil
+}
+
+// UnlockJob releases a lock previously acquired against a caller.
+func (sqlStore *SQLStore) UnlockJob(jobID, lockerID string, force bool) (bool, error) {
+	return sqlStore.unlockRows("Job", []string{jobID}, lockerID, force)
+}
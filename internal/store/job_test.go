@@ -0,0 +1,99 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobs(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	job1 := &model.Job{
+		Type:        model.JobTypePrune,
+		Parameters:  model.JobParameters{model.JobParameterOlderThanDays: "30"},
+		State:       model.JobStatePending,
+		MaxAttempts: 3,
+		CreatedBy:   "token1",
+	}
+	job2 := &model.Job{
+		Type:        model.JobTypeResync,
+		State:       model.JobStateSucceeded,
+		Result:      "resynced",
+		MaxAttempts: 1,
+	}
+
+	require.NoError(t, sqlStore.CreateJob(job1))
+	require.NotEmpty(t, job1.ID)
+	require.NotZero(t, job1.CreateAt)
+	require.NoError(t, sqlStore.CreateJob(job2))
+
+	t.Run("get", func(t *testing.T) {
+		job, err := sqlStore.GetJob(job1.ID)
+		require.NoError(t, err)
+		require.Equal(t, job1, job)
+
+		job, err = sqlStore.GetJob(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, job)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		jobs, err := sqlStore.GetJobs(&model.JobFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+
+		jobs, err = sqlStore.GetJobs(&model.JobFilter{State: model.JobStatePending, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Job{job1}, jobs)
+
+		jobs, err = sqlStore.GetJobs(&model.JobFilter{Type: model.JobTypeResync, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		require.Equal(t, job2.ID, jobs[0].ID)
+
+		jobs, err = sqlStore.GetJobs(&model.JobFilter{PerPage: 1})
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		job1.State = model.JobStateRunning
+		job1.Attempts = 1
+		job1.StartAt = 10
+		require.NoError(t, sqlStore.UpdateJob(job1))
+
+		job, err := sqlStore.GetJob(job1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.JobStateRunning, job.State)
+		require.Equal(t, 1, job.Attempts)
+		require.Equal(t, int64(10), job.StartAt)
+
+		job1.State = model.JobStatePending
+		require.NoError(t, sqlStore.UpdateJob(job1))
+	})
+
+	t.Run("lock pending work", func(t *testing.T) {
+		jobs, contended, err := sqlStore.LockJobsPendingWork("locker", 10)
+		require.NoError(t, err)
+		require.Zero(t, contended)
+		require.Len(t, jobs, 1)
+		require.Equal(t, job1.ID, jobs[0].ID)
+
+		jobs, contended, err = sqlStore.LockJobsPendingWork("other", 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, contended)
+		require.Empty(t, jobs)
+
+		unlocked, err := sqlStore.UnlockJob(job1.ID, "locker", false)
+		require.NoError(t, err)
+		require.True(t, unlocked)
+	})
+}
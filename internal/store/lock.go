@@ -42,6 +42,89 @@ func (sqlStore *SQLStore) lockRows(table string, ids []string, lockerID string)
 	return locked, nil
 }
 
+// lockRowsPendingWork locks, in the given order, up to limit unlocked rows of
+// the given table in one of the given states for exclusive use by the caller,
+// returning the IDs of the locked rows and the number of rows in those states
+// already locked by others.
+func (sqlStore *SQLStore) lockRowsPendingWork(table string, states []string, orderBy string, lockerID string, limit int) ([]string, int, error) {
+	var contended int
+	err := sqlStore.getBuilder(sqlStore.db, &contended, sq.
+		Select("COUNT(*)").
+		From(table).
+		Where(sq.Eq{"State": states}).
+		Where("LockAcquiredAt <> 0"),
+	)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to count locked rows in %s", table)
+	}
+
+	candidates := sq.
+		Select("ID").
+		From(table).
+		Where(sq.Eq{"State": states}).
+		Where("LockAcquiredAt = 0").
+		OrderBy(orderBy).
+		Limit(uint64(limit))
+	lockedAt := GetMillis()
+	lock := sq.
+		Update(table).
+		SetMap(map[string]interface{}{
+			"LockAcquiredBy": lockerID,
+			"LockAcquiredAt": lockedAt,
+		}).
+		Where("LockAcquiredAt = 0")
+
+	var ids []string
+	if sqlStore.db.DriverName() == driverPostgres {
+		// Select and lock the rows in a single statement, skipping the rows
+		// being locked by concurrent callers, so that elrond servers polling
+		// at the same time acquire disjoint batches instead of all contending
+		// for the first rows.
+		candidatesSQL, candidatesArgs, err := candidates.Suffix("FOR UPDATE SKIP LOCKED").ToSql()
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "failed to build sql")
+		}
+		err = sqlStore.selectBuilder(sqlStore.db, &ids, lock.
+			Where("ID IN ("+candidatesSQL+")", candidatesArgs...).
+			Suffix("RETURNING ID"),
+		)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "failed to lock rows pending work in %s", table)
+		}
+
+		return ids, contended, nil
+	}
+
+	// sqlite serializes writes, so the candidates are locked by a conditional
+	// update and the ones this caller won are identified by the lock time.
+	var candidateIDs []string
+	if err = sqlStore.selectBuilder(sqlStore.db, &candidateIDs, candidates); err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to query rows pending work in %s", table)
+	}
+	if len(candidateIDs) == 0 {
+		return nil, contended, nil
+	}
+
+	if _, err = sqlStore.execBuilder(sqlStore.db, lock.Where(sq.Eq{"ID": candidateIDs})); err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to lock rows pending work in %s", table)
+	}
+
+	err = sqlStore.selectBuilder(sqlStore.db, &ids, sq.
+		Select("ID").
+		From(table).
+		Where(sq.Eq{
+			"ID":             candidateIDs,
+			"LockAcquiredBy": lockerID,
+			"LockAcquiredAt": lockedAt,
+		}),
+	)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to query rows locked in %s", table)
+	}
+
+	return ids, contended, nil
+}
+
 // unlockRow releases a lock previously acquired against a caller.
 func (sqlStore *SQLStore) unlockRows(table string, ids []string, lockerID string, force bool) (bool, error) {
 	builder := sq.Update(table).
@@ -84,3 +167,35 @@ func (sqlStore *SQLStore) unlockRows(table string, ids []string, lockerID string
 
 	return unlocked, nil
 }
+
+// lockTables are the tables whose rows are locked for exclusive use.
+var lockTables = []string{"Ring", "InstallationGroup", "Rollout", "Job"}
+
+// UnlockRowsLockedBefore forcibly releases the locks acquired before the given
+// time in milliseconds on the rings, installation groups, rollouts and jobs,
+// such as those left behind by a server that crashed. It returns how many
+// rows were unlocked in each table.
+func (sqlStore *SQLStore) UnlockRowsLockedBefore(timestamp int64) (map[string]int64, error) {
+	unlocked := make(map[string]int64)
+	for _, table := range lockTables {
+		result, err := sqlStore.execBuilder(sqlStore.db, sq.
+			Update(table).
+			SetMap(map[string]interface{}{
+				"LockAcquiredBy": nil,
+				"LockAcquiredAt": 0,
+			}).
+			Where("LockAcquiredAt <> 0").
+			Where("LockAcquiredAt < ?", timestamp),
+		)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to unlock rows in %s", table)
+		}
+
+		unlocked[table], err = result.RowsAffected()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to count rows affected")
+		}
+	}
+
+	return unlocked, nil
+}
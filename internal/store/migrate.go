@@ -16,6 +16,33 @@ func LatestVersion() semver.Version {
 
 // Migrate advances the schema of the configured database to the latest version.
 func (sqlStore *SQLStore) Migrate() error {
+	return sqlStore.migrateTo(LatestVersion())
+}
+
+// MigrateTo advances the schema of the configured database to the given
+// version, such as the version of a backup to restore.
+func (sqlStore *SQLStore) MigrateTo(version string) error {
+	targetVersion, err := semver.Parse(version)
+	if err != nil {
+		return errors.Wrapf(err, "invalid schema version %s", version)
+	}
+	known := false
+	for _, migration := range migrations {
+		known = known || migration.toVersion.EQ(targetVersion)
+	}
+	if !known {
+		return errors.Errorf("unsupported schema version %s, latest version is %s", targetVersion, LatestVersion())
+	}
+	// A database without a schema yet has no version to compare.
+	currentVersion, err := sqlStore.GetCurrentVersion()
+	if err == nil && currentVersion.GT(targetVersion) {
+		return errors.Errorf("schema version %s is newer than %s", currentVersion, targetVersion)
+	}
+
+	return sqlStore.migrateTo(targetVersion)
+}
+
+func (sqlStore *SQLStore) migrateTo(targetVersion semver.Version) error {
 	var currentVersion semver.Version
 	if systemTableExists, err := sqlStore.tableExists("System"); err != nil {
 		return errors.Wrap(err, "failed to check if system table exists")
@@ -31,10 +58,9 @@ func (sqlStore *SQLStore) Migrate() error {
 		currentVersion,
 		LatestVersion(),
 	)
-
 	applied := 0
 	for _, migration := range migrations {
-		if !currentVersion.EQ(migration.fromVersion) {
+		if !currentVersion.EQ(migration.fromVersion) || currentVersion.EQ(targetVersion) {
 			continue
 		}
 
@@ -123,6 +123,1231 @@ var migrations = []migration{
 			return errors.Wrap(err, "failed to create unique webhook index")
 		}
 
+		return nil
+	}},
+	{semver.MustParse("0.1.0"), semver.MustParse("0.2.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN ReleaseStartAt BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ReleaseStartAt to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.2.0"), semver.MustParse("0.3.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			CREATE TABLE Tokens (
+				ID TEXT PRIMARY KEY,
+				Name TEXT NOT NULL,
+				TenantID TEXT NOT NULL,
+				Role TEXT NOT NULL,
+				TokenHash TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL,
+				ExpireAt BIGINT NOT NULL,
+				DeleteAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create Tokens table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE UNIQUE INDEX Tokens_TokenHash ON Tokens (TokenHash);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create unique token hash index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.3.0"), semver.MustParse("0.4.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			CREATE TABLE ProvisionerCredentials (
+				ID TEXT PRIMARY KEY,
+				EncryptedHeaders BYTEA NOT NULL,
+				CreateAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create ProvisionerCredentials table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.4.0"), semver.MustParse("0.5.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN ReleaseImpactInstallations BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ReleaseImpactInstallations to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN ReleaseImpactCustomers BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ReleaseImpactCustomers to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.5.0"), semver.MustParse("0.6.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			CREATE TABLE RingReleaseSnapshot (
+				ID TEXT PRIMARY KEY,
+				RingID TEXT NOT NULL,
+				ReleaseID TEXT NOT NULL,
+				Image TEXT NOT NULL,
+				Version TEXT NOT NULL,
+				Force BOOLEAN NOT NULL,
+				ReleaseCreateAt BIGINT NOT NULL,
+				CreateAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create RingReleaseSnapshot table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN RollbackSnapshotID TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add RollbackSnapshotID to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.6.0"), semver.MustParse("0.7.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			CREATE TABLE StateChangeEvent (
+				ID TEXT PRIMARY KEY,
+				ResourceType TEXT NOT NULL,
+				ResourceID TEXT NOT NULL,
+				RingID TEXT NOT NULL,
+				ReleaseID TEXT NOT NULL,
+				OldState TEXT NOT NULL,
+				NewState TEXT NOT NULL,
+				Timestamp BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create StateChangeEvent table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX StateChangeEvent_RingID_Timestamp ON StateChangeEvent (RingID, Timestamp);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create state change event ring index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.7.0"), semver.MustParse("0.8.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN DeletionScheduledAt BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add DeletionScheduledAt to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.8.0"), semver.MustParse("0.9.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN Annotations TEXT NOT NULL DEFAULT '{}';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Annotations to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN Annotations TEXT NOT NULL DEFAULT '{}';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Annotations to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.9.0"), semver.MustParse("0.10.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN ReleaseSoakTime INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ReleaseSoakTime to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN ReleaseSoakTime INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ReleaseSoakTime to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE RingRelease ADD COLUMN SoakTime INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add SoakTime to RingRelease table")
+		}
+
+		// Releases differing only by their soak time override are distinct.
+		if _, err := e.Exec(`
+			DROP INDEX RingRelease_Image_Version_Force;
+		`); err != nil {
+			return errors.Wrap(err, "failed to drop RingRelease_Image_Version_Force index")
+		}
+
+		if _, err := e.Exec(`
+			CREATE UNIQUE INDEX RingRelease_Image_Version_Force_SoakTime ON RingRelease (Image, Version, Force, SoakTime);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create RingRelease_Image_Version_Force_SoakTime index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.10.0"), semver.MustParse("0.11.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE RingRelease ADD COLUMN Type TEXT NOT NULL DEFAULT 'standard';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Type to RingRelease table")
+		}
+
+		if _, err := e.Exec(`
+			DROP INDEX RingRelease_Image_Version_Force_SoakTime;
+		`); err != nil {
+			return errors.Wrap(err, "failed to drop RingRelease_Image_Version_Force_SoakTime index")
+		}
+
+		if _, err := e.Exec(`
+			CREATE UNIQUE INDEX RingRelease_Image_Version_Force_SoakTime_Type ON RingRelease (Image, Version, Force, SoakTime, Type);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create RingRelease_Image_Version_Force_SoakTime_Type index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.11.0"), semver.MustParse("0.12.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN Drifted BOOLEAN NOT NULL DEFAULT FALSE;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Drifted to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN ObservedRelease TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ObservedRelease to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.12.0"), semver.MustParse("0.13.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN NotificationEmails TEXT NOT NULL DEFAULT '[]';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add NotificationEmails to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.13.0"), semver.MustParse("0.14.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Webhooks ADD COLUMN Format TEXT NOT NULL DEFAULT 'elrond';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Format to Webhooks table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.14.0"), semver.MustParse("0.15.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN JiraProject TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add JiraProject to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN JiraIssueKey TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add JiraIssueKey to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.15.0"), semver.MustParse("0.16.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN ReleaseProgress INTEGER NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ReleaseProgress to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.16.0"), semver.MustParse("0.17.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			CREATE TABLE IdempotencyKeys (
+				ID TEXT PRIMARY KEY,
+				ResourceType TEXT NOT NULL,
+				ResourceID TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create IdempotencyKeys table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.17.0"), semver.MustParse("0.18.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE RingRelease ADD COLUMN Parameters TEXT NOT NULL DEFAULT '{}';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Parameters to RingRelease table")
+		}
+
+		// Releases differing only by their parameters are distinct.
+		if _, err := e.Exec(`
+			DROP INDEX RingRelease_Image_Version_Force_SoakTime_Type;
+		`); err != nil {
+			return errors.Wrap(err, "failed to drop RingRelease_Image_Version_Force_SoakTime_Type index")
+		}
+
+		if _, err := e.Exec(`
+			CREATE UNIQUE INDEX RingRelease_Image_Version_Force_SoakTime_Type_Parameters ON RingRelease (Image, Version, Force, SoakTime, Type, Parameters);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create RingRelease_Image_Version_Force_SoakTime_Type_Parameters index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.18.0"), semver.MustParse("0.19.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN OwnerTeam TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add OwnerTeam to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN SlackChannel TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add SlackChannel to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN EscalationPolicy TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add EscalationPolicy to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.19.0"), semver.MustParse("0.20.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Webhooks ADD COLUMN LabelSelector TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add LabelSelector to Webhooks table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.20.0"), semver.MustParse("0.21.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			CREATE TABLE SoakCheckResult (
+				ID TEXT PRIMARY KEY,
+				InstallationGroupID TEXT NOT NULL,
+				RingID TEXT NOT NULL,
+				ReleaseID TEXT NOT NULL,
+				Name TEXT NOT NULL,
+				Query TEXT NOT NULL,
+				ObservedValue DOUBLE PRECISION NOT NULL,
+				Threshold DOUBLE PRECISION NOT NULL,
+				Passed BOOLEAN NOT NULL,
+				Message TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create SoakCheckResult table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX SoakCheckResult_InstallationGroupID_CreateAt ON SoakCheckResult (InstallationGroupID, CreateAt);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create soak check result installation group index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.21.0"), semver.MustParse("0.22.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN InstallationGroupPolicy TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add InstallationGroupPolicy to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE TABLE RingInstallationGroupChange (
+				ID TEXT PRIMARY KEY,
+				RingID TEXT NOT NULL,
+				InstallationGroupID TEXT NOT NULL,
+				Action TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create RingInstallationGroupChange table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX RingInstallationGroupChange_RingID ON RingInstallationGroupChange (RingID);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create ring installation group change ring index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.22.0"), semver.MustParse("0.23.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			CREATE TABLE Rollout (
+				ID TEXT PRIMARY KEY,
+				Name TEXT NOT NULL,
+				ReleaseID TEXT NOT NULL,
+				Steps TEXT NOT NULL,
+				CurrentStep INT NOT NULL,
+				StepStartAt BIGINT NOT NULL,
+				State TEXT NOT NULL,
+				Message TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL,
+				LockAcquiredBy TEXT NULL,
+				LockAcquiredAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create Rollout table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.23.0"), semver.MustParse("0.24.0"), func(e execer) error {
+		// Existing rings and installation groups follow the transition rules
+		// of the first state machine version.
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN StateMachineVersion INT NOT NULL DEFAULT 1;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add StateMachineVersion to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN StateMachineVersion INT NOT NULL DEFAULT 1;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add StateMachineVersion to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.24.0"), semver.MustParse("0.25.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN ForceApprovalWindow INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ForceApprovalWindow to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE TABLE ForceApproval (
+				ID TEXT PRIMARY KEY,
+				Action TEXT NOT NULL,
+				Target TEXT NOT NULL,
+				RequestHash TEXT NOT NULL,
+				RequestedBy TEXT NOT NULL,
+				ConfirmedBy TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL,
+				ExpireAt BIGINT NOT NULL,
+				ConfirmAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create ForceApproval table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX ForceApproval_Action_Target ON ForceApproval (Action, Target);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create force approval action target index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.25.0"), semver.MustParse("0.26.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			CREATE TABLE HealthSnapshot (
+				ID TEXT PRIMARY KEY,
+				InstallationGroupID TEXT NOT NULL,
+				RingID TEXT NOT NULL,
+				ReleaseID TEXT NOT NULL,
+				Stage TEXT NOT NULL,
+				InstallationsTotal BIGINT NOT NULL,
+				InstallationsUpdated BIGINT NOT NULL,
+				InstallationsUpdating BIGINT NOT NULL,
+				InstallationsHibernating BIGINT NOT NULL,
+				InstallationsAwaitingUpdate BIGINT NOT NULL,
+				Error TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create HealthSnapshot table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX HealthSnapshot_ReleaseID ON HealthSnapshot (ReleaseID);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create health snapshot release index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.26.0"), semver.MustParse("0.27.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE RingRelease ADD COLUMN Checksum TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Checksum to RingRelease table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE RingRelease ADD COLUMN Immutable BOOLEAN NOT NULL DEFAULT FALSE;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Immutable to RingRelease table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.27.0"), semver.MustParse("0.28.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN WorkPriority INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add WorkPriority to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN WorkPriority INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add WorkPriority to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.28.0"), semver.MustParse("0.29.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN ReleaseStrategy TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ReleaseStrategy to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN GreenProvisionerGroupID TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add GreenProvisionerGroupID to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.29.0"), semver.MustParse("0.30.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN SoakWindows TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add SoakWindows to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN ReleaseWindows TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ReleaseWindows to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.30.0"), semver.MustParse("0.31.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN FailurePolicy TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add FailurePolicy to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.31.0"), semver.MustParse("0.32.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN ReleaseLoadThreshold BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ReleaseLoadThreshold to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN ReleaseLoadMaxWait INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ReleaseLoadMaxWait to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN LoadDeferredAt BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add LoadDeferredAt to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.32.0"), semver.MustParse("0.33.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN ActiveReleaseID TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ActiveReleaseID to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN PreviousReleaseID TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add PreviousReleaseID to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN AutoRollback BOOLEAN NOT NULL DEFAULT FALSE;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add AutoRollback to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.33.0"), semver.MustParse("0.34.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE RingRelease ADD COLUMN Links TEXT NOT NULL DEFAULT '[]';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Links to RingRelease table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.34.0"), semver.MustParse("0.35.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN PausedState TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add PausedState to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN PausedAt BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add PausedAt to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.35.0"), semver.MustParse("0.36.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN TenantID TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add TenantID to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.36.0"), semver.MustParse("0.37.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN SoakChecks TEXT NOT NULL DEFAULT '[]';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add SoakChecks to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.37.0"), semver.MustParse("0.38.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE StateChangeEvent ADD COLUMN InstanceID TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add InstanceID to StateChangeEvent table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE StateChangeEvent ADD COLUMN Error TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Error to StateChangeEvent table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX StateChangeEvent_Timestamp ON StateChangeEvent (Timestamp);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create state change event timestamp index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.38.0"), semver.MustParse("0.39.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Webhooks ADD COLUMN Secret TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Secret to Webhooks table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE TABLE WebhookDelivery (
+				ID TEXT PRIMARY KEY,
+				WebhookID TEXT NOT NULL,
+				Payload TEXT NOT NULL,
+				State TEXT NOT NULL,
+				Attempts INT NOT NULL,
+				LastStatusCode INT NOT NULL,
+				LastError TEXT NOT NULL,
+				NextAttemptAt BIGINT NOT NULL,
+				LastAttemptAt BIGINT NOT NULL,
+				CreateAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create WebhookDelivery table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX WebhookDelivery_WebhookID ON WebhookDelivery (WebhookID);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create webhook delivery webhook index")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX WebhookDelivery_State_NextAttemptAt ON WebhookDelivery (State, NextAttemptAt);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create webhook delivery state index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.39.0"), semver.MustParse("0.40.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN ReleaseWindows TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ReleaseWindows to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.40.0"), semver.MustParse("0.41.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN ReleaseScheduledAt BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ReleaseScheduledAt to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.41.0"), semver.MustParse("0.42.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN VerificationURL TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add VerificationURL to InstallationGroup table")
+		}
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN VerificationTimeout INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add VerificationTimeout to InstallationGroup table")
+		}
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN VerificationJobID TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add VerificationJobID to InstallationGroup table")
+		}
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN VerificationStatus TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add VerificationStatus to InstallationGroup table")
+		}
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN VerificationOutputURL TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add VerificationOutputURL to InstallationGroup table")
+		}
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN VerificationStartAt BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add VerificationStartAt to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.42.0"), semver.MustParse("0.43.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN DependsOn TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add DependsOn to Ring table")
+		}
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN MaxConcurrency INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add MaxConcurrency to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.43.0"), semver.MustParse("0.44.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN Protected BOOLEAN NOT NULL DEFAULT FALSE;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Protected to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE TABLE RingProtectionChange (
+				ID TEXT PRIMARY KEY,
+				RingID TEXT NOT NULL,
+				Protected BOOLEAN NOT NULL,
+				Reason TEXT NOT NULL,
+				ChangedBy TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create RingProtectionChange table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX RingProtectionChange_RingID_CreateAt ON RingProtectionChange (RingID, CreateAt);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create ring protection change ring index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.44.0"), semver.MustParse("0.45.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE StateChangeEvent ADD COLUMN Bypassed TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Bypassed to StateChangeEvent table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.45.0"), semver.MustParse("0.46.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			CREATE TABLE Note (
+				ID TEXT PRIMARY KEY,
+				ResourceType TEXT NOT NULL,
+				ResourceID TEXT NOT NULL,
+				Message TEXT NOT NULL,
+				Author TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create Note table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX Note_ResourceID_CreateAt ON Note (ResourceID, CreateAt);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create note resource index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.46.0"), semver.MustParse("0.47.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Tokens ADD COLUMN RingIDs TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add RingIDs to Tokens table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Tokens ADD COLUMN RingSelector TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add RingSelector to Tokens table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.47.0"), semver.MustParse("0.48.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN ArchivedAt BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ArchivedAt to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.48.0"), semver.MustParse("0.49.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Rollout ADD COLUMN StepReleasedAt BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add StepReleasedAt to Rollout table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.49.0"), semver.MustParse("0.50.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			CREATE TABLE Job (
+				ID TEXT PRIMARY KEY,
+				Type TEXT NOT NULL,
+				Parameters TEXT NOT NULL,
+				State TEXT NOT NULL,
+				Result TEXT NOT NULL,
+				Message TEXT NOT NULL,
+				Attempts INT NOT NULL,
+				MaxAttempts INT NOT NULL,
+				CreatedBy TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL,
+				StartAt BIGINT NOT NULL,
+				EndAt BIGINT NOT NULL,
+				LockAcquiredBy TEXT NULL,
+				LockAcquiredAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create Job table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX Job_State_CreateAt ON Job (State, CreateAt);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create job state index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.50.0"), semver.MustParse("0.51.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Webhooks ADD COLUMN RateLimit INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add RateLimit to Webhooks table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.51.0"), semver.MustParse("0.52.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE RingRelease ADD COLUMN Labels TEXT NOT NULL DEFAULT '[]';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Labels to RingRelease table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.52.0"), semver.MustParse("0.53.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			CREATE TABLE NotificationTemplate (
+				ID TEXT PRIMARY KEY,
+				Channel TEXT NOT NULL,
+				Event TEXT NOT NULL,
+				Language TEXT NOT NULL,
+				Subject TEXT NOT NULL,
+				Body TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL,
+				UpdateAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create NotificationTemplate table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE UNIQUE INDEX NotificationTemplate_Channel_Event_Language ON NotificationTemplate (Channel, Event, Language);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create notification template index")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN NotificationLanguage TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add NotificationLanguage to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Webhooks ADD COLUMN Language TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Language to Webhooks table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.53.0"), semver.MustParse("0.54.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN Metadata TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Metadata to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN Metadata TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Metadata to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE StateChangeEvent ADD COLUMN Metadata TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Metadata to StateChangeEvent table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE StateChangeEvent ADD COLUMN RingMetadata TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add RingMetadata to StateChangeEvent table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.54.0"), semver.MustParse("0.55.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN FailureDomain TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add FailureDomain to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN MaxPerFailureDomain INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add MaxPerFailureDomain to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.55.0"), semver.MustParse("0.56.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN ForceRelease BOOLEAN NOT NULL DEFAULT FALSE;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add ForceRelease to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.56.0"), semver.MustParse("0.57.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN MaintenanceConflict TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add MaintenanceConflict to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.57.0"), semver.MustParse("0.58.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN FailureIssueURL TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add FailureIssueURL to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN FailureIssueAt BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add FailureIssueAt to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.58.0"), semver.MustParse("0.59.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN DatabaseSnapshot BOOLEAN NOT NULL DEFAULT FALSE;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add DatabaseSnapshot to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE TABLE DatabaseSnapshot (
+				ID TEXT PRIMARY KEY,
+				ReleaseID TEXT NOT NULL,
+				RingID TEXT NOT NULL,
+				InstallationGroupID TEXT NOT NULL,
+				InstallationID TEXT NOT NULL,
+				SnapshotID TEXT NOT NULL,
+				Source TEXT NOT NULL,
+				CreateAt BIGINT NOT NULL
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create DatabaseSnapshot table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX DatabaseSnapshot_ReleaseID ON DatabaseSnapshot (ReleaseID);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create database snapshot release index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.59.0"), semver.MustParse("0.60.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN MaxReleaseDuration INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add MaxReleaseDuration to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.60.0"), semver.MustParse("0.61.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN BlockedBy TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add BlockedBy to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN BlockedReason TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add BlockedReason to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.61.0"), semver.MustParse("0.62.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN InstallationCount BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add InstallationCount to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN CustomerCount BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add CustomerCount to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN CapacityTarget BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add CapacityTarget to InstallationGroup table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE InstallationGroup ADD COLUMN CapacitySyncedAt BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add CapacitySyncedAt to InstallationGroup table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.62.0"), semver.MustParse("0.63.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Webhooks ADD COLUMN Timeout INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Timeout to Webhooks table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Webhooks ADD COLUMN MaxRedirects INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add MaxRedirects to Webhooks table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Webhooks ADD COLUMN MaxPayloadSize INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add MaxPayloadSize to Webhooks table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.63.0"), semver.MustParse("0.64.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN Rehearsal BOOLEAN NOT NULL DEFAULT FALSE;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Rehearsal to Ring table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN RehearsalSpeedup INT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add RehearsalSpeedup to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.64.0"), semver.MustParse("0.65.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE Ring ADD COLUMN PreDeletionState TEXT NOT NULL DEFAULT '';
+		`); err != nil {
+			return errors.Wrap(err, "failed to add PreDeletionState to Ring table")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.65.0"), semver.MustParse("0.66.0"), func(e execer) error {
+		if _, err := e.Exec(`
+			ALTER TABLE StateChangeEvent ADD COLUMN Sequence BIGINT NOT NULL DEFAULT 0;
+		`); err != nil {
+			return errors.Wrap(err, "failed to add Sequence to StateChangeEvent table")
+		}
+
+		// Existing events are numbered in the order they were paged in.
+		if _, err := e.Exec(`
+			UPDATE StateChangeEvent SET Sequence = (
+				SELECT COUNT(*) FROM StateChangeEvent Previous
+				WHERE Previous.Timestamp < StateChangeEvent.Timestamp
+				OR (Previous.Timestamp = StateChangeEvent.Timestamp AND Previous.ID <= StateChangeEvent.ID)
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to number state change events")
+		}
+
+		if _, err := e.Exec(`
+			CREATE UNIQUE INDEX StateChangeEvent_Sequence ON StateChangeEvent (Sequence);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create StateChangeEvent sequence index")
+		}
+
+		return nil
+	}},
+	{semver.MustParse("0.66.0"), semver.MustParse("0.67.0"), func(e execer) error {
+		// State change events are numbered by the database from now on,
+		// continuing after the events numbered so far.
+		if e.DriverName() == driverPostgres {
+			if _, err := e.Exec(`
+				CREATE SEQUENCE StateChangeEvent_Sequence_seq OWNED BY StateChangeEvent.Sequence;
+			`); err != nil {
+				return errors.Wrap(err, "failed to create StateChangeEvent sequence")
+			}
+
+			if _, err := e.Exec(`
+				SELECT setval('StateChangeEvent_Sequence_seq', (SELECT COALESCE(MAX(Sequence), 0) + 1 FROM StateChangeEvent), false);
+			`); err != nil {
+				return errors.Wrap(err, "failed to start StateChangeEvent sequence")
+			}
+
+			if _, err := e.Exec(`
+				ALTER TABLE StateChangeEvent ALTER COLUMN Sequence SET DEFAULT nextval('StateChangeEvent_Sequence_seq');
+			`); err != nil {
+				return errors.Wrap(err, "failed to number StateChangeEvent rows by sequence")
+			}
+
+			return nil
+		}
+
+		// sqlite only autoincrements the primary key, so the table is rebuilt
+		// with the sequence number as its primary key.
+		if _, err := e.Exec(`
+			CREATE TABLE StateChangeEventSequenced (
+				Sequence INTEGER PRIMARY KEY AUTOINCREMENT,
+				ID TEXT NOT NULL UNIQUE,
+				ResourceType TEXT NOT NULL,
+				ResourceID TEXT NOT NULL,
+				RingID TEXT NOT NULL,
+				ReleaseID TEXT NOT NULL,
+				OldState TEXT NOT NULL,
+				NewState TEXT NOT NULL,
+				Timestamp BIGINT NOT NULL,
+				InstanceID TEXT NOT NULL DEFAULT '',
+				Error TEXT NOT NULL DEFAULT '',
+				Bypassed TEXT NOT NULL DEFAULT '',
+				Metadata TEXT NOT NULL DEFAULT '',
+				RingMetadata TEXT NOT NULL DEFAULT ''
+			);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create StateChangeEventSequenced table")
+		}
+
+		if _, err := e.Exec(`
+			INSERT INTO StateChangeEventSequenced (Sequence, ID, ResourceType, ResourceID, RingID, ReleaseID, OldState, NewState, Timestamp, InstanceID, Error, Bypassed, Metadata, RingMetadata)
+			SELECT Sequence, ID, ResourceType, ResourceID, RingID, ReleaseID, OldState, NewState, Timestamp, InstanceID, Error, Bypassed, Metadata, RingMetadata
+			FROM StateChangeEvent ORDER BY Sequence;
+		`); err != nil {
+			return errors.Wrap(err, "failed to copy StateChangeEvent rows")
+		}
+
+		if _, err := e.Exec(`
+			DROP TABLE StateChangeEvent;
+		`); err != nil {
+			return errors.Wrap(err, "failed to drop StateChangeEvent table")
+		}
+
+		if _, err := e.Exec(`
+			ALTER TABLE StateChangeEventSequenced RENAME TO StateChangeEvent;
+		`); err != nil {
+			return errors.Wrap(err, "failed to rename StateChangeEventSequenced table")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX StateChangeEvent_RingID_Timestamp ON StateChangeEvent (RingID, Timestamp);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create state change event ring index")
+		}
+
+		if _, err := e.Exec(`
+			CREATE INDEX StateChangeEvent_Timestamp ON StateChangeEvent (Timestamp);
+		`); err != nil {
+			return errors.Wrap(err, "failed to create state change event timestamp index")
+		}
+
 		return nil
 	}},
 }
@@ -0,0 +1,61 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var noteSelect sq.SelectBuilder
+
+func init() {
+	noteSelect = sq.
+		Select("ID", "ResourceType", "ResourceID", "Message", "Author", "CreateAt").
+		From("Note")
+}
+
+// CreateNote records the given note, assigning it a unique ID.
+func (sqlStore *SQLStore) CreateNote(note *model.Note) error {
+	note.ID = model.NewID()
+	note.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("Note").
+		SetMap(map[string]interface{}{
+			"ID":           note.ID,
+			"ResourceType": note.ResourceType,
+			"ResourceID":   note.ResourceID,
+			"Message":      note.Message,
+			"Author":       note.Author,
+			"CreateAt":     note.CreateAt,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create note")
+	}
+
+	return nil
+}
+
+// GetNotes fetches the notes matching the given filter, oldest first.
+func (sqlStore *SQLStore) GetNotes(filter *model.NoteFilter) ([]*model.Note, error) {
+	builder := noteSelect.OrderBy("CreateAt ASC", "ID ASC")
+	if filter.ResourceType != "" {
+		builder = builder.Where("ResourceType = ?", filter.ResourceType)
+	}
+	if filter.ResourceIDs != nil {
+		builder = builder.Where(sq.Eq{"ResourceID": filter.ResourceIDs})
+	}
+
+	var notes []*model.Note
+	err := sqlStore.selectBuilder(sqlStore.db, &notes, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for notes")
+	}
+
+	return notes, nil
+}
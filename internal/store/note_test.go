@@ -0,0 +1,50 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotes(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	ringID := model.NewID()
+	installationGroupID := model.NewID()
+
+	ringNote := &model.Note{ResourceType: model.TypeRing, ResourceID: ringID, Message: "holding for a customer blackout", Author: "token1"}
+	require.NoError(t, sqlStore.CreateNote(ringNote))
+	require.NotEmpty(t, ringNote.ID)
+	require.NotZero(t, ringNote.CreateAt)
+
+	time.Sleep(2 * time.Millisecond)
+	installationGroupNote := &model.Note{ResourceType: model.TypeInstallationGroup, ResourceID: installationGroupID, Message: "flaky health checks"}
+	require.NoError(t, sqlStore.CreateNote(installationGroupNote))
+
+	notes, err := sqlStore.GetNotes(&model.NoteFilter{ResourceType: model.TypeRing, ResourceIDs: []string{ringID}})
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	require.Equal(t, ringNote, notes[0])
+
+	notes, err = sqlStore.GetNotes(&model.NoteFilter{ResourceIDs: []string{installationGroupID, ringID}})
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	require.Equal(t, ringNote.ID, notes[0].ID)
+	require.Equal(t, installationGroupNote.ID, notes[1].ID)
+
+	notes, err = sqlStore.GetNotes(&model.NoteFilter{ResourceType: model.TypeRelease, ResourceIDs: []string{ringID}})
+	require.NoError(t, err)
+	require.Empty(t, notes)
+
+	notes, err = sqlStore.GetNotes(&model.NoteFilter{ResourceIDs: []string{}})
+	require.NoError(t, err)
+	require.Empty(t, notes)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var notificationTemplateSelect sq.SelectBuilder
+
+func init() {
+	notificationTemplateSelect = sq.
+		Select("ID", "Channel", "Event", "Language", "Subject", "Body", "CreateAt", "UpdateAt").
+		From("NotificationTemplate")
+}
+
+// GetNotificationTemplate fetches the given notification template by id.
+func (sqlStore *SQLStore) GetNotificationTemplate(id string) (*model.NotificationTemplate, error) {
+	var notificationTemplate model.NotificationTemplate
+	err := sqlStore.getBuilder(sqlStore.db, &notificationTemplate,
+		notificationTemplateSelect.Where("ID = ?", id),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get notification template by id")
+	}
+
+	return &notificationTemplate, nil
+}
+
+// GetNotificationTemplates fetches the given page of notification templates,
+// ordered by channel, event and language. The first page is 0.
+func (sqlStore *SQLStore) GetNotificationTemplates(filter *model.NotificationTemplateFilter) ([]*model.NotificationTemplate, error) {
+	builder := notificationTemplateSelect.
+		OrderBy("Channel ASC", "Event ASC", "Language ASC")
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	if filter.Channel != "" {
+		builder = builder.Where("Channel = ?", filter.Channel)
+	}
+	if filter.Event != "" {
+		builder = builder.Where("Event = ?", filter.Event)
+	}
+	if filter.Language != "" {
+		builder = builder.Where("Language = ?", filter.Language)
+	}
+
+	var notificationTemplates []*model.NotificationTemplate
+	err := sqlStore.selectBuilder(sqlStore.db, &notificationTemplates, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for notification templates")
+	}
+
+	return notificationTemplates, nil
+}
+
+// CreateNotificationTemplate records the given notification template,
+// assigning it a unique ID.
+func (sqlStore *SQLStore) CreateNotificationTemplate(notificationTemplate *model.NotificationTemplate) error {
+	notificationTemplate.ID = model.NewID()
+	notificationTemplate.CreateAt = GetMillis()
+	notificationTemplate.UpdateAt = notificationTemplate.CreateAt
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("NotificationTemplate").
+		SetMap(map[string]interface{}{
+			"ID":       notificationTemplate.ID,
+			"Channel":  notificationTemplate.Channel,
+			"Event":    notificationTemplate.Event,
+			"Language": notificationTemplate.Language,
+			"Subject":  notificationTemplate.Subject,
+			"Body":     notificationTemplate.Body,
+			"CreateAt": notificationTemplate.CreateAt,
+			"UpdateAt": notificationTemplate.UpdateAt,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create notification template")
+	}
+
+	return nil
+}
+
+// UpdateNotificationTemplate updates the subject and body of the given
+// notification template.
+func (sqlStore *SQLStore) UpdateNotificationTemplate(notificationTemplate *model.NotificationTemplate) error {
+	notificationTemplate.UpdateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("NotificationTemplate").
+		SetMap(map[string]interface{}{
+			"Subject":  notificationTemplate.Subject,
+			"Body":     notificationTemplate.Body,
+			"UpdateAt": notificationTemplate.UpdateAt,
+		}).
+		Where("ID = ?", notificationTemplate.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update notification template")
+	}
+
+	return nil
+}
+
+// DeleteNotificationTemplate removes the given notification template.
+func (sqlStore *SQLStore) DeleteNotificationTemplate(id string) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Delete("NotificationTemplate").
+		Where("ID = ?", id),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete notification template")
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationTemplates(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	teams := &model.NotificationTemplate{Channel: model.NotificationChannelTeams, Event: model.NotificationStateChange, Language: "de", Body: "{{.ID}}"}
+	require.NoError(t, sqlStore.CreateNotificationTemplate(teams))
+	require.NotEmpty(t, teams.ID)
+	require.NotZero(t, teams.CreateAt)
+
+	email := &model.NotificationTemplate{Channel: model.NotificationChannelEmail, Event: model.NotificationReleaseFailed, Language: "de", Subject: "{{.RingName}}", Body: "{{.Release}}"}
+	require.NoError(t, sqlStore.CreateNotificationTemplate(email))
+
+	duplicate := &model.NotificationTemplate{Channel: model.NotificationChannelEmail, Event: model.NotificationReleaseFailed, Language: "de", Body: "body"}
+	require.Error(t, sqlStore.CreateNotificationTemplate(duplicate))
+
+	notificationTemplate, err := sqlStore.GetNotificationTemplate(teams.ID)
+	require.NoError(t, err)
+	require.Equal(t, teams, notificationTemplate)
+
+	notificationTemplate, err = sqlStore.GetNotificationTemplate(model.NewID())
+	require.NoError(t, err)
+	require.Nil(t, notificationTemplate)
+
+	notificationTemplates, err := sqlStore.GetNotificationTemplates(&model.NotificationTemplateFilter{PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Equal(t, []*model.NotificationTemplate{email, teams}, notificationTemplates)
+
+	notificationTemplates, err = sqlStore.GetNotificationTemplates(&model.NotificationTemplateFilter{Channel: model.NotificationChannelTeams, PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Equal(t, []*model.NotificationTemplate{teams}, notificationTemplates)
+
+	notificationTemplates, err = sqlStore.GetNotificationTemplates(&model.NotificationTemplateFilter{Language: "fr", PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Empty(t, notificationTemplates)
+
+	email.Subject = "Fehler: {{.RingName}}"
+	require.NoError(t, sqlStore.UpdateNotificationTemplate(email))
+	notificationTemplate, err = sqlStore.GetNotificationTemplate(email.ID)
+	require.NoError(t, err)
+	require.Equal(t, email, notificationTemplate)
+
+	require.NoError(t, sqlStore.DeleteNotificationTemplate(email.ID))
+	notificationTemplate, err = sqlStore.GetNotificationTemplate(email.ID)
+	require.NoError(t, err)
+	require.Nil(t, notificationTemplate)
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var provisionerCredentialsSelect sq.SelectBuilder
+
+func init() {
+	provisionerCredentialsSelect = sq.
+		Select("ID", "EncryptedHeaders", "CreateAt").From("ProvisionerCredentials")
+}
+
+// GetLatestProvisionerCredentials fetches the most recently rotated provisioner
+// credentials, if any.
+func (sqlStore *SQLStore) GetLatestProvisionerCredentials() (*model.ProvisionerCredentials, error) {
+	var credentials model.ProvisionerCredentials
+	err := sqlStore.getBuilder(sqlStore.db, &credentials,
+		provisionerCredentialsSelect.OrderBy("CreateAt DESC", "ID DESC").Limit(1),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get latest provisioner credentials")
+	}
+
+	return &credentials, nil
+}
+
+// CreateProvisionerCredentials records a new version of the provisioner
+// credentials, assigning it a unique ID. Previous versions are kept for auditing.
+func (sqlStore *SQLStore) CreateProvisionerCredentials(credentials *model.ProvisionerCredentials) error {
+	credentials.ID = model.NewID()
+	credentials.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("ProvisionerCredentials").
+		SetMap(map[string]interface{}{
+			"ID":               credentials.ID,
+			"EncryptedHeaders": credentials.EncryptedHeaders,
+			"CreateAt":         credentials.CreateAt,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create provisioner credentials")
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisionerCredentials(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	credentials, err := sqlStore.GetLatestProvisionerCredentials()
+	require.NoError(t, err)
+	require.Nil(t, credentials)
+
+	credentials1 := &model.ProvisionerCredentials{EncryptedHeaders: []byte{0x01, 0x02}}
+	err = sqlStore.CreateProvisionerCredentials(credentials1)
+	require.NoError(t, err)
+
+	credentials, err = sqlStore.GetLatestProvisionerCredentials()
+	require.NoError(t, err)
+	require.Equal(t, credentials1, credentials)
+
+	time.Sleep(1 * time.Millisecond)
+
+	credentials2 := &model.ProvisionerCredentials{EncryptedHeaders: []byte{0x03, 0x04}}
+	err = sqlStore.CreateProvisionerCredentials(credentials2)
+	require.NoError(t, err)
+
+	credentials, err = sqlStore.GetLatestProvisionerCredentials()
+	require.NoError(t, err)
+	require.Equal(t, credentials2, credentials)
+}
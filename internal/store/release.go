@@ -6,6 +6,7 @@ package store
 
 import (
 	"database/sql"
+	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/mattermost/elrond/model"
@@ -23,14 +24,28 @@ var ringReleaseColumns = []string{
 	"RingRelease.Version",
 	"RingRelease.CreateAt",
 	"RingRelease.Force",
+	"RingRelease.SoakTime",
+	"RingRelease.Type",
+	"RingRelease.Parameters",
+	"RingRelease.Checksum",
+	"RingRelease.Immutable",
+	"RingRelease.Links",
+	"RingRelease.Labels",
 }
 
 type ringRelease struct {
-	ID       string
-	Image    string
-	Version  string
-	CreateAt int64
-	Force    bool
+	ID         string
+	Image      string
+	Version    string
+	CreateAt   int64
+	Force      bool
+	SoakTime   int
+	Type       string
+	Parameters model.ReleaseParameters
+	Checksum   string
+	Immutable  bool
+	Links      model.ReleaseLinks
+	Labels     model.ReleaseLabels
 }
 
 func init() {
@@ -43,6 +58,30 @@ func (sqlStore *SQLStore) GetRingRelease(releaseID string) (*model.RingRelease,
 	return sqlStore.getRingRelease(sqlStore.db, releaseID)
 }
 
+// GetRingReleases fetches the given page of ring releases, latest first.
+func (sqlStore *SQLStore) GetRingReleases(filter *model.RingReleaseFilter) ([]*model.RingRelease, error) {
+	builder := ringReleaseSelect.
+		OrderBy("CreateAt DESC", "ID DESC")
+	if filter.Label != "" {
+		// Labels are stored as a JSON list of strings, which cannot contain
+		// quotes, so the quoted label only matches a whole label.
+		builder = builder.Where("Labels LIKE ?", fmt.Sprintf(`%%"%s"%%`, filter.Label))
+	}
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	var ringReleases []*model.RingRelease
+	err := sqlStore.selectBuilder(sqlStore.db, &ringReleases, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for ring releases")
+	}
+
+	return ringReleases, nil
+}
+
 // GetOrCreateRingRelease checks if the given ring release exists otherwise it creates it.
 func (sqlStore *SQLStore) GetOrCreateRingRelease(ringRelease *model.RingRelease) (*model.RingRelease, error) {
 	return sqlStore.getOrCreateRingRelease(sqlStore.db, ringRelease)
@@ -66,25 +105,50 @@ func (sqlStore *SQLStore) getRingRelease(db queryer, releaseID string) (*model.R
 }
 
 func (sqlStore *SQLStore) getOrCreateRingRelease(db execer, ringRelease *model.RingRelease) (*model.RingRelease, error) {
+	if ringRelease.Type == "" {
+		ringRelease.Type = model.ReleaseTypeStandard
+	}
+
 	builder := ringReleaseSelect.
 		Where("Image = ?", ringRelease.Image).
 		Where("Version = ?", ringRelease.Version).
 		Where("Force = ?", ringRelease.Force).
+		Where("SoakTime = ?", ringRelease.SoakTime).
+		Where("Type = ?", ringRelease.Type).
+		Where("Parameters = ?", ringRelease.Parameters).
 		Limit(1)
 
-	err := sqlStore.getBuilder(sqlStore.db, ringRelease, builder)
+	// Query into a separate release, as a failed query may still initialize
+	// the nil maps of the destination.
+	var existing model.RingRelease
+	err := sqlStore.getBuilder(sqlStore.db, &existing, builder)
+	if err == nil {
+		*ringRelease = existing
+	}
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ringRelease.ID = model.NewID()
+			ringRelease.Immutable = false
+			ringRelease.Checksum, err = ringRelease.ComputeChecksum()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to compute ring release checksum")
+			}
 
 			_, err = sqlStore.execBuilder(db, sq.Insert("RingRelease").
 				SetMap(map[string]interface{}{
-					"ID":       ringRelease.ID,
-					"Image":    ringRelease.Image,
-					"Version":  ringRelease.Version,
-					"CreateAt": ringRelease.CreateAt,
-					"Force":    ringRelease.Force,
+					"ID":         ringRelease.ID,
+					"Image":      ringRelease.Image,
+					"Version":    ringRelease.Version,
+					"CreateAt":   ringRelease.CreateAt,
+					"Force":      ringRelease.Force,
+					"SoakTime":   ringRelease.SoakTime,
+					"Type":       ringRelease.Type,
+					"Parameters": ringRelease.Parameters,
+					"Checksum":   ringRelease.Checksum,
+					"Immutable":  ringRelease.Immutable,
+					"Links":      ringRelease.Links,
+					"Labels":     ringRelease.Labels,
 				}))
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to create ring release")
@@ -96,3 +160,100 @@ func (sqlStore *SQLStore) getOrCreateRingRelease(db execer, ringRelease *model.R
 
 	return ringRelease, nil
 }
+
+// MarkRingReleaseImmutable marks the given ring release as applied to a
+// ring, rejecting any later update of its content. The checksum of releases
+// created before checksums were recorded is computed at this point.
+func (sqlStore *SQLStore) MarkRingReleaseImmutable(releaseID string) error {
+	ringRelease, err := sqlStore.GetRingRelease(releaseID)
+	if err != nil {
+		return err
+	}
+	if ringRelease == nil {
+		return errors.Errorf("ring release %s not found", releaseID)
+	}
+	if ringRelease.Immutable {
+		return nil
+	}
+
+	checksum := ringRelease.Checksum
+	if checksum == "" {
+		checksum, err = ringRelease.ComputeChecksum()
+		if err != nil {
+			return errors.Wrap(err, "failed to compute ring release checksum")
+		}
+	}
+
+	// The checksum is only set if the release was not updated since it was
+	// read, so that it matches the content made immutable.
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update(ringReleaseTable).
+		SetMap(map[string]interface{}{
+			"Checksum":  checksum,
+			"Immutable": true,
+		}).
+		Where(sq.Eq{"ID": releaseID, "Checksum": ringRelease.Checksum, "Immutable": false}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to mark ring release immutable")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to count ring releases marked immutable")
+	}
+	if rows != 1 {
+		// The release was updated, or marked immutable, in the meantime.
+		return sqlStore.MarkRingReleaseImmutable(releaseID)
+	}
+
+	return nil
+}
+
+// UpdateRingReleaseLinks replaces the links of the given ring release. Links
+// are not part of the content of the release, so they can be updated once it
+// is immutable.
+func (sqlStore *SQLStore) UpdateRingReleaseLinks(releaseID string, links model.ReleaseLinks) error {
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update(ringReleaseTable).
+		Set("Links", links).
+		Where("ID = ?", releaseID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update ring release links")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to count updated ring releases")
+	}
+	if rows != 1 {
+		return errors.Errorf("ring release %s not found", releaseID)
+	}
+
+	return nil
+}
+
+// UpdateRingReleaseLabels replaces the labels of the given ring release.
+// Labels are not part of the content of the release, so they can be updated
+// once it is immutable.
+func (sqlStore *SQLStore) UpdateRingReleaseLabels(releaseID string, labels model.ReleaseLabels) error {
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update(ringReleaseTable).
+		Set("Labels", labels).
+		Where("ID = ?", releaseID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update ring release labels")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to count updated ring releases")
+	}
+	if rows != 1 {
+		return errors.Errorf("ring release %s not found", releaseID)
+	}
+
+	return nil
+}
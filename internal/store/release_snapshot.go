@@ -0,0 +1,70 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var ringReleaseSnapshotSelect sq.SelectBuilder
+
+func init() {
+	ringReleaseSnapshotSelect = sq.
+		Select("ID", "RingID", "ReleaseID", "Image", "Version", "Force", "ReleaseCreateAt", "CreateAt").
+		From("RingReleaseSnapshot")
+}
+
+// GetRingReleaseSnapshot fetches the given ring release snapshot by id.
+func (sqlStore *SQLStore) GetRingReleaseSnapshot(snapshotID string) (*model.RingReleaseSnapshot, error) {
+	var snapshot model.RingReleaseSnapshot
+	err := sqlStore.getBuilder(sqlStore.db, &snapshot,
+		ringReleaseSnapshotSelect.Where("ID = ?", snapshotID),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get ring release snapshot by id")
+	}
+
+	return &snapshot, nil
+}
+
+// CreateRingReleaseSnapshot records an immutable snapshot of the given ring
+// release for the given ring. Snapshots are never updated or deleted.
+func (sqlStore *SQLStore) CreateRingReleaseSnapshot(ringID string, ringRelease *model.RingRelease) (*model.RingReleaseSnapshot, error) {
+	snapshot := &model.RingReleaseSnapshot{
+		ID:              model.NewID(),
+		RingID:          ringID,
+		ReleaseID:       ringRelease.ID,
+		Image:           ringRelease.Image,
+		Version:         ringRelease.Version,
+		Force:           ringRelease.Force,
+		ReleaseCreateAt: ringRelease.CreateAt,
+		CreateAt:        GetMillis(),
+	}
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("RingReleaseSnapshot").
+		SetMap(map[string]interface{}{
+			"ID":              snapshot.ID,
+			"RingID":          snapshot.RingID,
+			"ReleaseID":       snapshot.ReleaseID,
+			"Image":           snapshot.Image,
+			"Version":         snapshot.Version,
+			"Force":           snapshot.Force,
+			"ReleaseCreateAt": snapshot.ReleaseCreateAt,
+			"CreateAt":        snapshot.CreateAt,
+		}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create ring release snapshot")
+	}
+
+	return snapshot, nil
+}
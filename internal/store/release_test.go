@@ -37,4 +37,185 @@ func TestRingRelease(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, ringRelease1, actualRingRelease1)
 	})
+
+	t.Run("soak time override is part of the release", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+		defer CloseConnection(t, sqlStore)
+
+		ringRelease1, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "test", Version: "test"})
+		require.NoError(t, err)
+
+		ringRelease2, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "test", Version: "test", SoakTime: 60})
+		require.NoError(t, err)
+		require.NotEqual(t, ringRelease1.ID, ringRelease2.ID)
+
+		actualRingRelease2, err := sqlStore.GetRingRelease(ringRelease2.ID)
+		require.NoError(t, err)
+		require.Equal(t, 60, actualRingRelease2.SoakTime)
+
+		ringRelease3, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "test", Version: "test", SoakTime: 60})
+		require.NoError(t, err)
+		require.Equal(t, ringRelease2.ID, ringRelease3.ID)
+	})
+
+	t.Run("parameters are part of the release", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+		defer CloseConnection(t, sqlStore)
+
+		parameters := model.ReleaseParameters{"eu-ig": {MattermostEnv: map[string]string{"MM_DATA_RESIDENCY": "eu", "MM_FEATURE": "on"}}}
+
+		ringRelease1, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "test", Version: "test"})
+		require.NoError(t, err)
+		require.Nil(t, ringRelease1.Parameters)
+
+		ringRelease2, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "test", Version: "test", Parameters: parameters})
+		require.NoError(t, err)
+		require.NotEqual(t, ringRelease1.ID, ringRelease2.ID)
+
+		actualRingRelease2, err := sqlStore.GetRingRelease(ringRelease2.ID)
+		require.NoError(t, err)
+		require.Equal(t, parameters, actualRingRelease2.Parameters)
+
+		ringRelease3, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{
+			Image:      "test",
+			Version:    "test",
+			Parameters: model.ReleaseParameters{"eu-ig": {MattermostEnv: map[string]string{"MM_FEATURE": "on", "MM_DATA_RESIDENCY": "eu"}}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, ringRelease2.ID, ringRelease3.ID)
+	})
+
+	t.Run("releases are immutable once applied", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+		defer CloseConnection(t, sqlStore)
+
+		ringRelease, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "test", Version: "test"})
+		require.NoError(t, err)
+		require.True(t, ringRelease.VerifyChecksum())
+		require.False(t, ringRelease.Immutable)
+
+		require.NoError(t, sqlStore.MarkRingReleaseImmutable(ringRelease.ID))
+		require.NoError(t, sqlStore.MarkRingReleaseImmutable(ringRelease.ID))
+
+		actualRingRelease, err := sqlStore.GetRingRelease(ringRelease.ID)
+		require.NoError(t, err)
+		require.True(t, actualRingRelease.Immutable)
+		require.Equal(t, ringRelease.Checksum, actualRingRelease.Checksum)
+		require.True(t, actualRingRelease.VerifyChecksum())
+
+		require.Error(t, sqlStore.MarkRingReleaseImmutable(model.NewID()))
+	})
+
+	t.Run("links are not part of the release", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+		defer CloseConnection(t, sqlStore)
+
+		links := model.ReleaseLinks{{Type: model.ReleaseLinkTypeBuild, URL: "https://ci.example.com/build/1"}}
+
+		ringRelease, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "test", Version: "test", Links: links})
+		require.NoError(t, err)
+		checksum := ringRelease.Checksum
+		require.NoError(t, sqlStore.MarkRingReleaseImmutable(ringRelease.ID))
+
+		actualRingRelease, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "test", Version: "test"})
+		require.NoError(t, err)
+		require.Equal(t, ringRelease.ID, actualRingRelease.ID)
+		require.Equal(t, links, actualRingRelease.Links)
+
+		links = append(links, &model.ReleaseLink{Type: model.ReleaseLinkTypeIncident, URL: "https://incidents.example.com/1"})
+		require.NoError(t, sqlStore.UpdateRingReleaseLinks(ringRelease.ID, links))
+
+		actualRingRelease, err = sqlStore.GetRingRelease(ringRelease.ID)
+		require.NoError(t, err)
+		require.Equal(t, links, actualRingRelease.Links)
+		require.Equal(t, checksum, actualRingRelease.Checksum)
+		require.True(t, actualRingRelease.VerifyChecksum())
+
+		require.Error(t, sqlStore.UpdateRingReleaseLinks(model.NewID(), links))
+	})
+
+	t.Run("labels", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+		defer CloseConnection(t, sqlStore)
+
+		patch, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "test", Version: "patch", CreateAt: 1, Labels: model.ReleaseLabels{"security-patch"}})
+		require.NoError(t, err)
+		feature, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "test", Version: "feature", CreateAt: 2, Labels: model.ReleaseLabels{"security-patch-review"}})
+		require.NoError(t, err)
+
+		actualRingRelease, err := sqlStore.GetRingRelease(patch.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.ReleaseLabels{"security-patch"}, actualRingRelease.Labels)
+
+		releases, err := sqlStore.GetRingReleases(&model.RingReleaseFilter{Label: "security-patch", PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, releases, 1)
+		require.Equal(t, patch.ID, releases[0].ID)
+
+		require.NoError(t, sqlStore.MarkRingReleaseImmutable(feature.ID))
+		require.NoError(t, sqlStore.UpdateRingReleaseLabels(feature.ID, model.ReleaseLabels{"quarterly", "security-patch"}))
+
+		releases, err = sqlStore.GetRingReleases(&model.RingReleaseFilter{Label: "security-patch", PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, releases, 2)
+		require.Equal(t, feature.ID, releases[0].ID)
+		require.Equal(t, model.ReleaseLabels{"quarterly", "security-patch"}, releases[0].Labels)
+
+		releases, err = sqlStore.GetRingReleases(&model.RingReleaseFilter{PerPage: 1})
+		require.NoError(t, err)
+		require.Len(t, releases, 1)
+
+		require.Error(t, sqlStore.UpdateRingReleaseLabels(model.NewID(), nil))
+	})
+
+	t.Run("releases without checksum get one once applied", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+		defer CloseConnection(t, sqlStore)
+
+		ringRelease, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "test", Version: "test"})
+		require.NoError(t, err)
+		_, err = sqlStore.db.Exec(`UPDATE RingRelease SET Checksum = ''`)
+		require.NoError(t, err)
+
+		require.NoError(t, sqlStore.MarkRingReleaseImmutable(ringRelease.ID))
+
+		actualRingRelease, err := sqlStore.GetRingRelease(ringRelease.ID)
+		require.NoError(t, err)
+		require.Equal(t, ringRelease.Checksum, actualRingRelease.Checksum)
+	})
+}
+
+func TestRingReleaseSnapshot(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	snapshot, err := sqlStore.GetRingReleaseSnapshot("unknown")
+	require.NoError(t, err)
+	require.Nil(t, snapshot)
+
+	ringRelease, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{
+		Image:    "mattermost/mattermost-enterprise-edition",
+		Version:  "7.0.0",
+		CreateAt: 10,
+	})
+	require.NoError(t, err)
+
+	snapshot, err = sqlStore.CreateRingReleaseSnapshot("ring1", ringRelease)
+	require.NoError(t, err)
+	require.Equal(t, "ring1", snapshot.RingID)
+	require.Equal(t, ringRelease.ID, snapshot.ReleaseID)
+	require.Equal(t, ringRelease.Image, snapshot.Image)
+	require.Equal(t, ringRelease.Version, snapshot.Version)
+	require.Equal(t, ringRelease.CreateAt, snapshot.ReleaseCreateAt)
+
+	actualSnapshot, err := sqlStore.GetRingReleaseSnapshot(snapshot.ID)
+	require.NoError(t, err)
+	require.Equal(t, snapshot, actualSnapshot)
 }
@@ -13,10 +13,13 @@ import (
 )
 
 var ringSelect sq.SelectBuilder
+var ringColumns = []string{
+	"Ring.ID", "Ring.Name", "Ring.Priority", "Ring.SoakTime", "Ring.ActiveReleaseID", "Ring.DesiredReleaseID", "Ring.Provisioner", "Ring.State", "Ring.CreateAt", "Ring.DeleteAt", "Ring.ReleaseAt", "Ring.ReleaseStartAt", "Ring.ReleaseImpactInstallations", "Ring.ReleaseImpactCustomers", "Ring.RollbackSnapshotID", "Ring.DeletionScheduledAt", "Ring.PreDeletionState", "Ring.ReleaseScheduledAt", "Ring.PausedState", "Ring.PausedAt", "Ring.ReleaseSoakTime", "Ring.Annotations", "Ring.Metadata", "Ring.NotificationEmails", "Ring.NotificationLanguage", "Ring.JiraProject", "Ring.JiraIssueKey", "Ring.OwnerTeam", "Ring.SlackChannel", "Ring.EscalationPolicy", "Ring.TenantID", "Ring.InstallationGroupPolicy", "Ring.FailurePolicy", "Ring.AutoRollback", "Ring.ForceApprovalWindow", "Ring.SoakWindows", "Ring.ReleaseWindows", "Ring.DependsOn", "Ring.MaxConcurrency", "Ring.MaxPerFailureDomain", "Ring.MaxReleaseDuration", "Ring.MaintenanceConflict", "Ring.BlockedBy", "Ring.BlockedReason", "Ring.FailureIssueURL", "Ring.FailureIssueAt", "Ring.StateMachineVersion", "Ring.WorkPriority", "Ring.Protected", "Ring.Rehearsal", "Ring.RehearsalSpeedup", "Ring.APISecurityLock", "Ring.LockAcquiredBy", "Ring.LockAcquiredAt",
+}
 
 func init() {
 	ringSelect = sq.
-		Select("Ring.ID", "Name", "Priority", "SoakTime", "ActiveReleaseID", "DesiredReleaseID", "Provisioner", "State", "CreateAt", "DeleteAt", "ReleaseAt", "APISecurityLock", "LockAcquiredBy", "LockAcquiredAt").
+		Select(ringColumns...).
 		From("Ring")
 }
 
@@ -59,6 +62,14 @@ func (sqlStore *SQLStore) applyRingsFilter(builder sq.SelectBuilder, filter *mod
 		builder = builder.Where("DeleteAt = 0")
 	}
 
+	if filter.Name != "" {
+		builder = builder.Where("Ring.Name = ?", filter.Name)
+	}
+
+	if filter.TenantID != "" {
+		builder = builder.Where("Ring.TenantID = ?", filter.TenantID)
+	}
+
 	return builder
 }
 
@@ -98,6 +109,26 @@ func (sqlStore *SQLStore) GetRingsInPendingState() ([]*model.Ring, error) {
 	return rings, nil
 }
 
+// GetRingsReleaseScheduled returns the rings whose pending release is
+// scheduled, by schedule time.
+func (sqlStore *SQLStore) GetRingsReleaseScheduled() ([]*model.Ring, error) {
+	builder := ringSelect.
+		Where(sq.Eq{
+			"State":    []string{model.RingStateReleasePending, model.RingStateReleaseBlocked},
+			"DeleteAt": 0,
+		}).
+		Where("ReleaseScheduledAt > 0").
+		OrderBy("ReleaseScheduledAt ASC")
+
+	var rings []*model.Ring
+	err := sqlStore.selectBuilder(sqlStore.db, &rings, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for rings with a scheduled release")
+	}
+
+	return rings, nil
+}
+
 // GetRingsLocked returns all rings that are under lock.
 func (sqlStore *SQLStore) GetRingsLocked() ([]*model.Ring, error) {
 	var rings []*model.Ring
@@ -113,13 +144,15 @@ func (sqlStore *SQLStore) GetRingsLocked() ([]*model.Ring, error) {
 	return rings, nil
 }
 
-// GetRingsReleaseInProgress returns all rings in a releasing state.
+// GetRingsReleaseInProgress returns all rings in a releasing state, including
+// the rings whose release was paused in flight.
 func (sqlStore *SQLStore) GetRingsReleaseInProgress() ([]*model.Ring, error) {
 	var rings []*model.Ring
 
 	builder := ringSelect.
-		Where(sq.Eq{
-			"State": model.AllRingStatesReleaseInProgress,
+		Where(sq.Or{
+			sq.Eq{"State": model.AllRingStatesReleaseInProgress},
+			sq.Eq{"State": model.RingStateReleasePaused, "PausedState": model.AllRingStatesReleaseInProgress},
 		})
 
 	err := sqlStore.selectBuilder(sqlStore.db, &rings, builder)
@@ -183,24 +216,64 @@ func (sqlStore *SQLStore) CreateRing(ring *model.Ring, installationGroup *model.
 func (sqlStore *SQLStore) createRing(execer execer, ring *model.Ring) error {
 	ring.ID = model.NewID()
 	ring.CreateAt = GetMillis()
+	ring.StateMachineVersion = ring.CurrentStateMachineVersion()
 
 	if _, err := sqlStore.execBuilder(execer, sq.
 		Insert("Ring").
 		SetMap(map[string]interface{}{
-			"ID":               ring.ID,
-			"Name":             ring.Name,
-			"Priority":         ring.Priority,
-			"State":            ring.State,
-			"SoakTime":         ring.SoakTime,
-			"ActiveReleaseID":  ring.ActiveReleaseID,
-			"DesiredReleaseID": ring.DesiredReleaseID,
-			"Provisioner":      ring.Provisioner,
-			"CreateAt":         ring.CreateAt,
-			"ReleaseAt":        ring.ReleaseAt,
-			"DeleteAt":         ring.DeleteAt,
-			"APISecurityLock":  ring.APISecurityLock,
-			"LockAcquiredBy":   nil,
-			"LockAcquiredAt":   0,
+			"ID":                         ring.ID,
+			"Name":                       ring.Name,
+			"Priority":                   ring.Priority,
+			"State":                      ring.State,
+			"SoakTime":                   ring.SoakTime,
+			"ActiveReleaseID":            ring.ActiveReleaseID,
+			"DesiredReleaseID":           ring.DesiredReleaseID,
+			"Provisioner":                ring.Provisioner,
+			"CreateAt":                   ring.CreateAt,
+			"ReleaseAt":                  ring.ReleaseAt,
+			"ReleaseStartAt":             ring.ReleaseStartAt,
+			"ReleaseImpactInstallations": ring.ReleaseImpactInstallations,
+			"ReleaseImpactCustomers":     ring.ReleaseImpactCustomers,
+			"RollbackSnapshotID":         ring.RollbackSnapshotID,
+			"DeletionScheduledAt":        ring.DeletionScheduledAt,
+			"PreDeletionState":           ring.PreDeletionState,
+			"ReleaseScheduledAt":         ring.ReleaseScheduledAt,
+			"PausedState":                ring.PausedState,
+			"PausedAt":                   ring.PausedAt,
+			"ReleaseSoakTime":            ring.ReleaseSoakTime,
+			"Annotations":                ring.Annotations,
+			"Metadata":                   ring.Metadata,
+			"NotificationEmails":         ring.NotificationEmails,
+			"NotificationLanguage":       ring.NotificationLanguage,
+			"JiraProject":                ring.JiraProject,
+			"JiraIssueKey":               ring.JiraIssueKey,
+			"OwnerTeam":                  ring.OwnerTeam,
+			"SlackChannel":               ring.SlackChannel,
+			"EscalationPolicy":           ring.EscalationPolicy,
+			"TenantID":                   ring.TenantID,
+			"InstallationGroupPolicy":    ring.InstallationGroupPolicy,
+			"FailurePolicy":              ring.FailurePolicy,
+			"AutoRollback":               ring.AutoRollback,
+			"ForceApprovalWindow":        ring.ForceApprovalWindow,
+			"SoakWindows":                ring.SoakWindows,
+			"ReleaseWindows":             ring.ReleaseWindows,
+			"DependsOn":                  ring.DependsOn,
+			"MaxConcurrency":             ring.MaxConcurrency,
+			"MaxPerFailureDomain":        ring.MaxPerFailureDomain,
+			"MaxReleaseDuration":         ring.MaxReleaseDuration,
+			"MaintenanceConflict":        ring.MaintenanceConflict,
+			"BlockedBy":                  ring.BlockedBy,
+			"BlockedReason":              ring.BlockedReason,
+			"FailureIssueURL":            ring.FailureIssueURL,
+			"FailureIssueAt":             ring.FailureIssueAt,
+			"StateMachineVersion":        ring.StateMachineVersion,
+			"DeleteAt":                   ring.DeleteAt,
+			"Protected":                  ring.Protected,
+			"Rehearsal":                  ring.Rehearsal,
+			"RehearsalSpeedup":           ring.RehearsalSpeedup,
+			"APISecurityLock":            ring.APISecurityLock,
+			"LockAcquiredBy":             nil,
+			"LockAcquiredAt":             0,
 		}),
 	); err != nil {
 		return errors.Wrap(err, "failed to create ring")
@@ -212,20 +285,55 @@ func (sqlStore *SQLStore) createRing(execer execer, ring *model.Ring) error {
 // updateRings updates the given rings to the database when a single transaction is needed.
 func (sqlStore *SQLStore) updateRings(execer execer, rings []*model.Ring) error {
 	for _, ring := range rings {
-		if _, err := sqlStore.execBuilder(execer, sq.
+		update := sq.
 			Update("Ring").
 			SetMap(map[string]interface{}{
-				"Name":             ring.Name,
-				"Priority":         ring.Priority,
-				"State":            ring.State,
-				"SoakTime":         ring.SoakTime,
-				"Provisioner":      ring.Provisioner,
-				"ActiveReleaseID":  ring.ActiveReleaseID,
-				"DesiredReleaseID": ring.DesiredReleaseID,
-				"ReleaseAt":        ring.ReleaseAt,
+				"Name":                       ring.Name,
+				"Priority":                   ring.Priority,
+				"State":                      ring.State,
+				"SoakTime":                   ring.SoakTime,
+				"Provisioner":                ring.Provisioner,
+				"ActiveReleaseID":            ring.ActiveReleaseID,
+				"DesiredReleaseID":           ring.DesiredReleaseID,
+				"ReleaseAt":                  ring.ReleaseAt,
+				"ReleaseStartAt":             ring.ReleaseStartAt,
+				"ReleaseImpactInstallations": ring.ReleaseImpactInstallations,
+				"ReleaseImpactCustomers":     ring.ReleaseImpactCustomers,
+				"RollbackSnapshotID":         ring.RollbackSnapshotID,
+				"DeletionScheduledAt":        ring.DeletionScheduledAt,
+				"PreDeletionState":           ring.PreDeletionState,
+				"ReleaseScheduledAt":         ring.ReleaseScheduledAt,
+				"PausedState":                ring.PausedState,
+				"PausedAt":                   ring.PausedAt,
+				"ReleaseSoakTime":            ring.ReleaseSoakTime,
+				"Annotations":                ring.Annotations,
+				"Metadata":                   ring.Metadata,
+				"NotificationEmails":         ring.NotificationEmails,
+				"NotificationLanguage":       ring.NotificationLanguage,
+				"JiraProject":                ring.JiraProject,
+				"JiraIssueKey":               ring.JiraIssueKey,
+				"OwnerTeam":                  ring.OwnerTeam,
+				"SlackChannel":               ring.SlackChannel,
+				"EscalationPolicy":           ring.EscalationPolicy,
+				"InstallationGroupPolicy":    ring.InstallationGroupPolicy,
+				"FailurePolicy":              ring.FailurePolicy,
+				"AutoRollback":               ring.AutoRollback,
+				"ForceApprovalWindow":        ring.ForceApprovalWindow,
+				"SoakWindows":                ring.SoakWindows,
+				"ReleaseWindows":             ring.ReleaseWindows,
+				"DependsOn":                  ring.DependsOn,
+				"MaxConcurrency":             ring.MaxConcurrency,
+				"MaxPerFailureDomain":        ring.MaxPerFailureDomain,
+				"MaxReleaseDuration":         ring.MaxReleaseDuration,
+				"MaintenanceConflict":        ring.MaintenanceConflict,
+				"BlockedBy":                  ring.BlockedBy,
+				"BlockedReason":              ring.BlockedReason,
+				"FailureIssueURL":            ring.FailureIssueURL,
+				"FailureIssueAt":             ring.FailureIssueAt,
+				"StateMachineVersion":        ring.StateMachineVersion,
 			}).
-			Where("ID = ?", ring.ID),
-		); err != nil {
+			Where("ID = ?", ring.ID)
+		if _, err := sqlStore.execBuilder(execer, clearFinishedWorkPriority(update, ring.State, model.AllRingStatesPendingWork)); err != nil {
 			return errors.Wrap(err, "failed to update ring")
 		}
 	}
@@ -235,20 +343,55 @@ func (sqlStore *SQLStore) updateRings(execer execer, rings []*model.Ring) error
 
 // UpdateRing updates the given ring in the database.
 func (sqlStore *SQLStore) UpdateRing(ring *model.Ring) error {
-	if _, err := sqlStore.execBuilder(sqlStore.db, sq.
+	update := sq.
 		Update("Ring").
 		SetMap(map[string]interface{}{
-			"Name":             ring.Name,
-			"Priority":         ring.Priority,
-			"State":            ring.State,
-			"SoakTime":         ring.SoakTime,
-			"Provisioner":      ring.Provisioner,
-			"ActiveReleaseID":  ring.ActiveReleaseID,
-			"DesiredReleaseID": ring.DesiredReleaseID,
-			"ReleaseAt":        ring.ReleaseAt,
+			"Name":                       ring.Name,
+			"Priority":                   ring.Priority,
+			"State":                      ring.State,
+			"SoakTime":                   ring.SoakTime,
+			"Provisioner":                ring.Provisioner,
+			"ActiveReleaseID":            ring.ActiveReleaseID,
+			"DesiredReleaseID":           ring.DesiredReleaseID,
+			"ReleaseAt":                  ring.ReleaseAt,
+			"ReleaseStartAt":             ring.ReleaseStartAt,
+			"ReleaseImpactInstallations": ring.ReleaseImpactInstallations,
+			"ReleaseImpactCustomers":     ring.ReleaseImpactCustomers,
+			"RollbackSnapshotID":         ring.RollbackSnapshotID,
+			"DeletionScheduledAt":        ring.DeletionScheduledAt,
+			"PreDeletionState":           ring.PreDeletionState,
+			"ReleaseScheduledAt":         ring.ReleaseScheduledAt,
+			"PausedState":                ring.PausedState,
+			"PausedAt":                   ring.PausedAt,
+			"ReleaseSoakTime":            ring.ReleaseSoakTime,
+			"Annotations":                ring.Annotations,
+			"Metadata":                   ring.Metadata,
+			"NotificationEmails":         ring.NotificationEmails,
+			"NotificationLanguage":       ring.NotificationLanguage,
+			"JiraProject":                ring.JiraProject,
+			"JiraIssueKey":               ring.JiraIssueKey,
+			"OwnerTeam":                  ring.OwnerTeam,
+			"SlackChannel":               ring.SlackChannel,
+			"EscalationPolicy":           ring.EscalationPolicy,
+			"InstallationGroupPolicy":    ring.InstallationGroupPolicy,
+			"FailurePolicy":              ring.FailurePolicy,
+			"AutoRollback":               ring.AutoRollback,
+			"ForceApprovalWindow":        ring.ForceApprovalWindow,
+			"SoakWindows":                ring.SoakWindows,
+			"ReleaseWindows":             ring.ReleaseWindows,
+			"DependsOn":                  ring.DependsOn,
+			"MaxConcurrency":             ring.MaxConcurrency,
+			"MaxPerFailureDomain":        ring.MaxPerFailureDomain,
+			"MaxReleaseDuration":         ring.MaxReleaseDuration,
+			"MaintenanceConflict":        ring.MaintenanceConflict,
+			"BlockedBy":                  ring.BlockedBy,
+			"BlockedReason":              ring.BlockedReason,
+			"FailureIssueURL":            ring.FailureIssueURL,
+			"FailureIssueAt":             ring.FailureIssueAt,
+			"StateMachineVersion":        ring.StateMachineVersion,
 		}).
-		Where("ID = ?", ring.ID),
-	); err != nil {
+		Where("ID = ?", ring.ID)
+	if _, err := sqlStore.execBuilder(sqlStore.db, clearFinishedWorkPriority(update, ring.State, model.AllRingStatesPendingWork)); err != nil {
 		return errors.Wrap(err, "failed to update ring")
 	}
 
@@ -275,6 +418,31 @@ func (sqlStore *SQLStore) UpdateRings(rings []*model.Ring) error {
 	return nil
 }
 
+// UpdateRingAndInstallationGroups updates the given ring and installation
+// groups in the database in a single transaction.
+func (sqlStore *SQLStore) UpdateRingAndInstallationGroups(ring *model.Ring, installationGroups []*model.InstallationGroup) error {
+	tx, err := sqlStore.beginTransaction(sqlStore.db)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	for _, installationGroup := range installationGroups {
+		if err = sqlStore.updateInstallationGroup(tx, installationGroup); err != nil {
+			return err
+		}
+	}
+	if err = sqlStore.updateRings(tx, []*model.Ring{ring}); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit the transaction")
+	}
+
+	return nil
+}
+
 // DeleteRing marks the given ring as deleted, but does not remove the record from the
 // database.
 func (sqlStore *SQLStore) DeleteRing(id string) error {
@@ -301,6 +469,30 @@ func (sqlStore *SQLStore) LockRing(ringID, lockerID string) (bool, error) {
 	return sqlStore.lockRows("Ring", []string{ringID}, lockerID)
 }
 
+// LockRingsPendingWork locks up to limit unlocked rings pending work, oldest
+// first, for exclusive use by the caller. It returns the locked rings and the
+// number of rings pending work already locked by others.
+func (sqlStore *SQLStore) LockRingsPendingWork(lockerID string, limit int) ([]*model.Ring, int, error) {
+	ids, contended, err := sqlStore.lockRowsPendingWork("Ring", model.AllRingStatesPendingWork, ringWorkOrder, lockerID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(ids) == 0 {
+		return nil, contended, nil
+	}
+
+	var rings []*model.Ring
+	err = sqlStore.selectBuilder(sqlStore.db, &rings, ringSelect.
+		Where(sq.Eq{"ID": ids}).
+		OrderBy(ringWorkOrder),
+	)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to query for locked rings pending work")
+	}
+
+	return rings, contended, nil
+}
+
 // LockRings marks the rings as locked for exclusive use by the caller.
 func (sqlStore *SQLStore) LockRings(rings []string, lockerID string) (bool, error) {
 	return sqlStore.lockRows("Ring", rings, lockerID)
@@ -0,0 +1,254 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+const ringInstallationGroupChangeTable = "RingInstallationGroupChange"
+
+var ringInstallationGroupChangeSelect sq.SelectBuilder
+
+func init() {
+	ringInstallationGroupChangeSelect = sq.
+		Select("ID", "RingID", "InstallationGroupID", "Action", "CreateAt").
+		From(ringInstallationGroupChangeTable)
+}
+
+// getRingForUpdate fetches the given ring within a transaction, locking its
+// row until the transaction ends where the database supports it.
+func (sqlStore *SQLStore) getRingForUpdate(tx *Transaction, ringID string) (*model.Ring, error) {
+	builder := ringSelect.Where("ID = ?", ringID)
+	if tx.DriverName() == driverPostgres {
+		builder = builder.Suffix("FOR UPDATE")
+	}
+
+	var ring model.Ring
+	err := sqlStore.getBuilder(tx, &ring, builder)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get ring by id")
+	}
+
+	return &ring, nil
+}
+
+// RegisterRingInstallationGroup registers the given installation group to
+// the ring, creating the installation group if it does not exist. While the
+// ring has a release in progress, the registration is queued instead and
+// returned. The state of the ring is checked in the same transaction, so the
+// registration cannot race with the release.
+func (sqlStore *SQLStore) RegisterRingInstallationGroup(ringID string, installationGroup *model.InstallationGroup) (*model.InstallationGroup, *model.RingInstallationGroupChange, error) {
+	tx, err := sqlStore.beginTransaction(sqlStore.db)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	ring, err := sqlStore.getRingForUpdate(tx, ringID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ring == nil {
+		return nil, nil, errors.Errorf("ring %s does not exist", ringID)
+	}
+
+	installationGroup, err = sqlStore.getOrCreateInstallationGroup(tx, installationGroup)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get or create installation group")
+	}
+
+	var change *model.RingInstallationGroupChange
+	if ring.HasReleaseInProgress() {
+		change, err = sqlStore.createRingInstallationGroupChange(tx, ringID, installationGroup.ID, model.RingInstallationGroupRegister)
+	} else {
+		_, err = sqlStore.createRingInstallationGroup(tx, ringID, installationGroup)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to commit the transaction")
+	}
+
+	return installationGroup, change, nil
+}
+
+// DeregisterRingInstallationGroup removes the given installation group from
+// the ring. While the ring has a release in progress, the removal is queued
+// instead and returned. Removing an installation group that does not exist
+// does nothing.
+func (sqlStore *SQLStore) DeregisterRingInstallationGroup(ringID, installationGroupID string) (*model.RingInstallationGroupChange, error) {
+	tx, err := sqlStore.beginTransaction(sqlStore.db)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	ring, err := sqlStore.getRingForUpdate(tx, ringID)
+	if err != nil {
+		return nil, err
+	}
+	if ring == nil {
+		return nil, errors.Errorf("ring %s does not exist", ringID)
+	}
+
+	installationGroup, err := sqlStore.getInstallationGroupByID(tx, installationGroupID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get installation group '%s' by id", installationGroupID)
+	}
+	if installationGroup == nil {
+		return nil, nil
+	}
+
+	var change *model.RingInstallationGroupChange
+	if ring.HasReleaseInProgress() {
+		change, err = sqlStore.createRingInstallationGroupChange(tx, ringID, installationGroupID, model.RingInstallationGroupDeregister)
+	} else {
+		err = sqlStore.deleteRingInstallationGroup(tx, ringID, installationGroupID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "failed to commit the transaction")
+	}
+
+	return change, nil
+}
+
+// createRingInstallationGroupChange queues an installation group change of
+// the ring, which the caller must have locked in the given transaction.
+// Changes are applied in the order of their creation time, so a change
+// queued in the same millisecond as the previous one is created a
+// millisecond after it.
+func (sqlStore *SQLStore) createRingInstallationGroupChange(tx *Transaction, ringID, installationGroupID, action string) (*model.RingInstallationGroupChange, error) {
+	var lastCreateAt int64
+	err := sqlStore.getBuilder(tx, &lastCreateAt, sq.
+		Select("COALESCE(MAX(CreateAt), 0)").
+		From(ringInstallationGroupChangeTable).
+		Where("RingID = ?", ringID),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get last ring installation group change")
+	}
+
+	change := &model.RingInstallationGroupChange{
+		ID:                  model.NewID(),
+		RingID:              ringID,
+		InstallationGroupID: installationGroupID,
+		Action:              action,
+		CreateAt:            GetMillis(),
+	}
+	if change.CreateAt <= lastCreateAt {
+		change.CreateAt = lastCreateAt + 1
+	}
+
+	_, err = sqlStore.execBuilder(tx, sq.
+		Insert(ringInstallationGroupChangeTable).
+		SetMap(map[string]interface{}{
+			"ID":                  change.ID,
+			"RingID":              change.RingID,
+			"InstallationGroupID": change.InstallationGroupID,
+			"Action":              change.Action,
+			"CreateAt":            change.CreateAt,
+		}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to queue ring installation group change")
+	}
+
+	return change, nil
+}
+
+// GetRingInstallationGroupChanges fetches the installation group changes
+// queued for the ring, in the order they were requested.
+func (sqlStore *SQLStore) GetRingInstallationGroupChanges(ringID string) ([]*model.RingInstallationGroupChange, error) {
+	var changes []*model.RingInstallationGroupChange
+	err := sqlStore.selectBuilder(sqlStore.db, &changes, ringInstallationGroupChangeSelect.
+		Where("RingID = ?", ringID).
+		OrderBy("CreateAt ASC", "ID ASC"),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for ring installation group changes")
+	}
+
+	return changes, nil
+}
+
+// ApplyRingInstallationGroupChanges applies the given queued installation
+// group changes and removes them from the queue. The given installation
+// groups are updated in the same transaction, such as the ones joining the
+// release in progress of their ring.
+func (sqlStore *SQLStore) ApplyRingInstallationGroupChanges(changes []*model.RingInstallationGroupChange, installationGroups []*model.InstallationGroup) error {
+	tx, err := sqlStore.beginTransaction(sqlStore.db)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	for _, change := range changes {
+		switch change.Action {
+		case model.RingInstallationGroupRegister:
+			var registered bool
+			registered, err = sqlStore.isRingInstallationGroup(tx, change.RingID, change.InstallationGroupID)
+			if err != nil {
+				return err
+			}
+			if !registered {
+				_, err = sqlStore.createRingInstallationGroup(tx, change.RingID, &model.InstallationGroup{ID: change.InstallationGroupID})
+			}
+		case model.RingInstallationGroupDeregister:
+			err = sqlStore.deleteRingInstallationGroup(tx, change.RingID, change.InstallationGroupID)
+		default:
+			err = errors.Errorf("unknown ring installation group change action %q", change.Action)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to apply ring installation group change %s", change.ID)
+		}
+
+		_, err = sqlStore.execBuilder(tx, sq.Delete(ringInstallationGroupChangeTable).Where("ID = ?", change.ID))
+		if err != nil {
+			return errors.Wrap(err, "failed to remove applied ring installation group change")
+		}
+	}
+
+	for _, installationGroup := range installationGroups {
+		if err = sqlStore.updateInstallationGroup(tx, installationGroup); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit the transaction")
+	}
+
+	return nil
+}
+
+// isRingInstallationGroup returns whether the installation group is
+// registered to the ring.
+func (sqlStore *SQLStore) isRingInstallationGroup(db queryer, ringID, installationGroupID string) (bool, error) {
+	var count int
+	err := sqlStore.getBuilder(db, &count, sq.
+		Select("COUNT(*)").
+		From(ringInstallationGroupTable).
+		Where("RingID = ?", ringID).
+		Where("InstallationGroupID = ?", installationGroupID),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to query ring installation group")
+	}
+
+	return count > 0, nil
+}
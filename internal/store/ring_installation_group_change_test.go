@@ -0,0 +1,104 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingInstallationGroupChanges(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	ring := &model.Ring{Name: "ring1", State: model.RingStateStable}
+	require.NoError(t, sqlStore.CreateRing(ring, nil))
+
+	t.Run("unknown ring", func(t *testing.T) {
+		_, _, err := sqlStore.RegisterRingInstallationGroup(model.NewID(), &model.InstallationGroup{Name: "group0"})
+		require.Error(t, err)
+	})
+
+	t.Run("stable ring", func(t *testing.T) {
+		installationGroup, change, err := sqlStore.RegisterRingInstallationGroup(ring.ID, &model.InstallationGroup{Name: "group1"})
+		require.NoError(t, err)
+		require.Nil(t, change)
+		require.NotEmpty(t, installationGroup.ID)
+
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, installationGroups, 1)
+
+		change, err = sqlStore.DeregisterRingInstallationGroup(ring.ID, installationGroup.ID)
+		require.NoError(t, err)
+		require.Nil(t, change)
+
+		installationGroups, err = sqlStore.GetInstallationGroupsForRing(ring.ID)
+		require.NoError(t, err)
+		require.Empty(t, installationGroups)
+	})
+
+	t.Run("ring releasing", func(t *testing.T) {
+		installationGroup1, _, err := sqlStore.RegisterRingInstallationGroup(ring.ID, &model.InstallationGroup{Name: "group1"})
+		require.NoError(t, err)
+
+		ring.State = model.RingStateReleaseInProgress
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		installationGroup2, register, err := sqlStore.RegisterRingInstallationGroup(ring.ID, &model.InstallationGroup{Name: "group2"})
+		require.NoError(t, err)
+		require.NotNil(t, register)
+		require.Equal(t, model.RingInstallationGroupRegister, register.Action)
+		require.Equal(t, installationGroup2.ID, register.InstallationGroupID)
+
+		deregister, err := sqlStore.DeregisterRingInstallationGroup(ring.ID, installationGroup1.ID)
+		require.NoError(t, err)
+		require.NotNil(t, deregister)
+
+		unknown, err := sqlStore.DeregisterRingInstallationGroup(ring.ID, model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, unknown)
+
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, installationGroups, 1)
+		require.Equal(t, installationGroup1.ID, installationGroups[0].ID)
+
+		changes, err := sqlStore.GetRingInstallationGroupChanges(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		require.Equal(t, register.ID, changes[0].ID)
+		require.Equal(t, deregister.ID, changes[1].ID)
+
+		installationGroup2.State = model.InstallationGroupReleasePending
+		require.NoError(t, sqlStore.ApplyRingInstallationGroupChanges(changes, []*model.InstallationGroup{installationGroup2}))
+
+		installationGroups, err = sqlStore.GetInstallationGroupsForRing(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, installationGroups, 1)
+		require.Equal(t, installationGroup2.ID, installationGroups[0].ID)
+		require.Equal(t, model.InstallationGroupReleasePending, installationGroups[0].State)
+
+		changes, err = sqlStore.GetRingInstallationGroupChanges(ring.ID)
+		require.NoError(t, err)
+		require.Empty(t, changes)
+	})
+
+	t.Run("registering a registered installation group again", func(t *testing.T) {
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(ring.ID)
+		require.NoError(t, err)
+
+		_, change, err := sqlStore.RegisterRingInstallationGroup(ring.ID, &model.InstallationGroup{Name: installationGroups[0].Name})
+		require.NoError(t, err)
+		require.NoError(t, sqlStore.ApplyRingInstallationGroupChanges([]*model.RingInstallationGroupChange{change}, nil))
+
+		installationGroups, err = sqlStore.GetInstallationGroupsForRing(ring.ID)
+		require.NoError(t, err)
+		require.Len(t, installationGroups, 1)
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var ringProtectionChangeSelect sq.SelectBuilder
+
+func init() {
+	ringProtectionChangeSelect = sq.
+		Select("ID", "RingID", "Protected", "Reason", "ChangedBy", "CreateAt").
+		From("RingProtectionChange")
+}
+
+// SetRingProtected sets the protection of the ring of the given change,
+// recording the change, and assigning it a unique ID.
+func (sqlStore *SQLStore) SetRingProtected(change *model.RingProtectionChange) error {
+	change.ID = model.NewID()
+	change.CreateAt = GetMillis()
+
+	tx, err := sqlStore.beginTransaction(sqlStore.db)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.RollbackUnlessCommitted()
+
+	_, err = sqlStore.execBuilder(tx, sq.
+		Update("Ring").
+		Set("Protected", change.Protected).
+		Where("ID = ?", change.RingID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to store ring protection")
+	}
+
+	_, err = sqlStore.execBuilder(tx, sq.
+		Insert("RingProtectionChange").
+		SetMap(map[string]interface{}{
+			"ID":        change.ID,
+			"RingID":    change.RingID,
+			"Protected": change.Protected,
+			"Reason":    change.Reason,
+			"ChangedBy": change.ChangedBy,
+			"CreateAt":  change.CreateAt,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create ring protection change")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit the transaction")
+	}
+
+	return nil
+}
+
+// GetRingProtectionChanges fetches the protection changes of the given ring,
+// newest first.
+func (sqlStore *SQLStore) GetRingProtectionChanges(ringID string) ([]*model.RingProtectionChange, error) {
+	var changes []*model.RingProtectionChange
+	err := sqlStore.selectBuilder(sqlStore.db, &changes, ringProtectionChangeSelect.
+		Where("RingID = ?", ringID).
+		OrderBy("CreateAt DESC", "ID DESC"),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for ring protection changes")
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingProtection(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	ring := &model.Ring{Priority: 1, Protected: true}
+	require.NoError(t, sqlStore.CreateRing(ring, nil))
+
+	actual, err := sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.True(t, actual.Protected)
+
+	changes, err := sqlStore.GetRingProtectionChanges(ring.ID)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	unprotect := &model.RingProtectionChange{RingID: ring.ID, Reason: "decommissioning", ChangedBy: "token1"}
+	require.NoError(t, sqlStore.SetRingProtected(unprotect))
+	require.NotEmpty(t, unprotect.ID)
+
+	actual, err = sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.False(t, actual.Protected)
+
+	protect := &model.RingProtectionChange{RingID: ring.ID, Protected: true}
+	require.NoError(t, sqlStore.SetRingProtected(protect))
+
+	actual, err = sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.True(t, actual.Protected)
+
+	// Protection survives ring updates.
+	require.NoError(t, sqlStore.UpdateRing(actual))
+	actual, err = sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.True(t, actual.Protected)
+
+	changes, err = sqlStore.GetRingProtectionChanges(ring.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.ElementsMatch(t, []string{protect.ID, unprotect.ID}, []string{changes[0].ID, changes[1].ID})
+
+	changes, err = sqlStore.GetRingProtectionChanges(model.NewID())
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
@@ -5,6 +5,7 @@
 package store
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -41,6 +42,8 @@ func TestRings(t *testing.T) {
 
 		err := sqlStore.CreateRing(ring1, &installationGroup)
 		require.NoError(t, err)
+		require.Equal(t, model.CurrentStateMachineVersion, ring1.StateMachineVersion)
+		require.Equal(t, model.CurrentStateMachineVersion, installationGroup.StateMachineVersion)
 
 		actualRing1, err := sqlStore.GetRing(ring1.ID)
 		require.NoError(t, err)
@@ -58,6 +61,18 @@ func TestRings(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, []*model.Ring{ring1}, actualRings)
 
+		actualRings, err = sqlStore.GetRings(&model.RingFilter{PerPage: model.AllPerPage, Name: "test"})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Ring{ring1}, actualRings)
+
+		actualRings, err = sqlStore.GetRings(&model.RingFilter{PerPage: model.AllPerPage, Name: "other"})
+		require.NoError(t, err)
+		require.Empty(t, actualRings)
+
+		installationGroups, err := sqlStore.GetInstallationGroupsForRings(&model.RingFilter{PerPage: model.AllPerPage, Name: "test"})
+		require.NoError(t, err)
+		require.Len(t, installationGroups[ring1.ID], 1)
+
 		actualRings, err = sqlStore.GetRings(&model.RingFilter{Page: 0, PerPage: 1, IncludeDeleted: true})
 		require.NoError(t, err)
 		require.Equal(t, []*model.Ring{ring1}, actualRings)
@@ -101,6 +116,46 @@ func TestRings(t *testing.T) {
 		require.Equal(t, ring1, actualRing1)
 	})
 
+	t.Run("paused releases", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+		defer CloseConnection(t, sqlStore)
+
+		var rings []*model.Ring
+		var installationGroups []*model.InstallationGroup
+		for i, state := range []string{model.RingStateSoakingRequested, model.RingStateReleasePending, model.RingStateReleaseInProgress} {
+			ring := &model.Ring{Provisioner: "elrond", Name: fmt.Sprintf("ring%d", i), Priority: i + 1, State: state}
+			installationGroup := &model.InstallationGroup{Name: fmt.Sprintf("group%d", i)}
+			require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+			installationGroup.State = model.InstallationGroupReleaseSoakingRequested
+			installationGroup.ReleaseAt = 1000
+			require.NoError(t, sqlStore.UpdateInstallationGroup(installationGroup))
+			rings = append(rings, ring)
+			installationGroups = append(installationGroups, installationGroup)
+		}
+
+		require.True(t, rings[0].PauseRelease(2000))
+		require.True(t, rings[1].PauseRelease(2000))
+		require.NoError(t, sqlStore.UpdateRings(rings[:2]))
+
+		actualRing, err := sqlStore.GetRing(rings[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateSoakingRequested, actualRing.PausedState)
+		require.Equal(t, int64(2000), actualRing.PausedAt)
+
+		ringsReleaseInProgress, err := sqlStore.GetRingsReleaseInProgress()
+		require.NoError(t, err)
+		require.Len(t, ringsReleaseInProgress, 2)
+		require.ElementsMatch(t, []string{rings[0].ID, rings[2].ID}, []string{ringsReleaseInProgress[0].ID, ringsReleaseInProgress[1].ID})
+
+		require.NoError(t, sqlStore.DelayRingInstallationGroupsSoak(rings[0].ID, 500))
+		for i, expected := range []int64{1500, 1000, 1000} {
+			installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroups[i].ID)
+			require.NoError(t, err)
+			require.Equal(t, expected, installationGroup.ReleaseAt)
+		}
+	})
+
 	t.Run("delete ring", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := MakeTestSQLStore(t, logger)
@@ -153,6 +208,143 @@ func TestRings(t *testing.T) {
 	})
 }
 
+func TestRingAnnotations(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	ring := &model.Ring{
+		Name:        "test",
+		Priority:    1,
+		Annotations: model.Annotations{"Change-Ticket": "CHG-1234"},
+	}
+	installationGroup := model.InstallationGroup{
+		Name:        "group1",
+		Annotations: model.Annotations{"team": "platform"},
+	}
+
+	err := sqlStore.CreateRing(ring, &installationGroup)
+	require.NoError(t, err)
+
+	actualRing, err := sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Equal(t, ring.Annotations, actualRing.Annotations)
+
+	installationGroups, err := sqlStore.GetInstallationGroupsForRing(ring.ID)
+	require.NoError(t, err)
+	require.Equal(t, installationGroup.Annotations, installationGroups[0].Annotations)
+
+	ring.Annotations = model.Annotations{"Change-Ticket": "CHG-5678"}
+	err = sqlStore.UpdateRing(ring)
+	require.NoError(t, err)
+
+	actualRing, err = sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Equal(t, ring.Annotations, actualRing.Annotations)
+}
+
+func TestRingMetadata(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	ring := &model.Ring{
+		Name:     "test",
+		Priority: 1,
+		Metadata: model.Metadata(`{"team":"payments"}`),
+	}
+	installationGroup := model.InstallationGroup{
+		Name:     "group1",
+		Metadata: model.Metadata(`{"customer":"acme","tier":1}`),
+	}
+	require.NoError(t, sqlStore.CreateRing(ring, &installationGroup))
+
+	actualRing, err := sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Equal(t, ring.Metadata, actualRing.Metadata)
+
+	installationGroups, err := sqlStore.GetInstallationGroupsForRing(ring.ID)
+	require.NoError(t, err)
+	require.Equal(t, installationGroup.Metadata, installationGroups[0].Metadata)
+
+	actualInstallationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+	require.NoError(t, err)
+	require.Equal(t, installationGroup.Metadata, actualInstallationGroup.Metadata)
+
+	ring.Metadata = nil
+	require.NoError(t, sqlStore.UpdateRing(ring))
+	actualRing, err = sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Nil(t, actualRing.Metadata)
+
+	event := model.NewStateChangeEvent(model.TypeInstallationGroup, installationGroup.ID, &model.Ring{ID: ring.ID, Metadata: model.Metadata(`{"team":"payments"}`)}, model.InstallationGroupStable, model.InstallationGroupReleasePending)
+	event.Metadata = installationGroup.Metadata
+	require.NoError(t, sqlStore.CreateStateChangeEvent(event))
+
+	events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{RingID: ring.ID, PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, installationGroup.Metadata, events[0].Metadata)
+	require.Equal(t, model.Metadata(`{"team":"payments"}`), events[0].RingMetadata)
+}
+
+func TestRingTimeWindows(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	ring := &model.Ring{
+		Name:        "test",
+		Priority:    1,
+		SoakWindows: model.TimeWindows{{Start: "09:00", End: "17:00", TimeZone: "Europe/Berlin", Weekdays: []string{"Monday"}}},
+	}
+
+	err := sqlStore.CreateRing(ring, &model.InstallationGroup{Name: "group1"})
+	require.NoError(t, err)
+
+	actualRing, err := sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Equal(t, ring.SoakWindows, actualRing.SoakWindows)
+	require.Nil(t, actualRing.ReleaseWindows)
+
+	ring.SoakWindows = nil
+	ring.ReleaseWindows = model.TimeWindows{{Start: "22:00", End: "02:00"}}
+	err = sqlStore.UpdateRing(ring)
+	require.NoError(t, err)
+
+	actualRing, err = sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Nil(t, actualRing.SoakWindows)
+	require.Equal(t, ring.ReleaseWindows, actualRing.ReleaseWindows)
+}
+
+func TestRingNotificationEmails(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	ring := &model.Ring{
+		Name:               "test",
+		Priority:           1,
+		NotificationEmails: model.NotificationEmails{"release-managers@example.com"},
+	}
+
+	err := sqlStore.CreateRing(ring, &model.InstallationGroup{Name: "group1"})
+	require.NoError(t, err)
+
+	actualRing, err := sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Equal(t, ring.NotificationEmails, actualRing.NotificationEmails)
+
+	ring.NotificationEmails = nil
+	err = sqlStore.UpdateRing(ring)
+	require.NoError(t, err)
+
+	actualRing, err = sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Empty(t, actualRing.NotificationEmails)
+}
+
 func TestGetUnlockedRingsPendingWork(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := MakeTestSQLStore(t, logger)
@@ -224,6 +416,53 @@ func TestGetUnlockedRingsPendingWork(t *testing.T) {
 	require.Empty(t, rings)
 }
 
+func TestLockRingsPendingWork(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	var pendingRings []*model.Ring
+	for i := 0; i < 3; i++ {
+		ring := &model.Ring{State: model.RingStateReleasePending}
+		require.NoError(t, sqlStore.CreateRing(ring, nil))
+		pendingRings = append(pendingRings, ring)
+		time.Sleep(1 * time.Millisecond)
+	}
+	require.NoError(t, sqlStore.CreateRing(&model.Ring{State: model.RingStateStable}, nil))
+
+	locker1 := model.NewID()
+	locker2 := model.NewID()
+
+	rings, contended, err := sqlStore.LockRingsPendingWork(locker1, 2)
+	require.NoError(t, err)
+	require.Zero(t, contended)
+	require.Len(t, rings, 2)
+	require.Equal(t, pendingRings[0].ID, rings[0].ID)
+	require.Equal(t, pendingRings[1].ID, rings[1].ID)
+	require.Equal(t, locker1, *rings[0].LockAcquiredBy)
+
+	time.Sleep(1 * time.Millisecond)
+
+	rings, contended, err = sqlStore.LockRingsPendingWork(locker2, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, contended)
+	require.Len(t, rings, 1)
+	require.Equal(t, pendingRings[2].ID, rings[0].ID)
+
+	rings, contended, err = sqlStore.LockRingsPendingWork(locker2, 2)
+	require.NoError(t, err)
+	require.Equal(t, 3, contended)
+	require.Empty(t, rings)
+
+	unlocked, err := sqlStore.UnlockRing(pendingRings[0].ID, locker1, false)
+	require.NoError(t, err)
+	require.True(t, unlocked)
+
+	rings, _, err = sqlStore.LockRingsPendingWork(locker2, 2)
+	require.NoError(t, err)
+	require.Len(t, rings, 1)
+	require.Equal(t, pendingRings[0].ID, rings[0].ID)
+}
+
 func TestLockRing(t *testing.T) {
 	logger := testlib.MakeLogger(t)
 	sqlStore := MakeTestSQLStore(t, logger)
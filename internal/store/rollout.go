@@ -0,0 +1,140 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var rolloutSelect sq.SelectBuilder
+
+func init() {
+	rolloutSelect = sq.
+		Select("ID", "Name", "ReleaseID", "Steps", "CurrentStep", "StepStartAt", "StepReleasedAt", "State",
+			"Message", "CreateAt", "LockAcquiredBy", "LockAcquiredAt").
+		From("Rollout")
+}
+
+// GetRollout fetches the given rollout by id.
+func (sqlStore *SQLStore) GetRollout(id string) (*model.Rollout, error) {
+	var rollout model.Rollout
+	err := sqlStore.getBuilder(sqlStore.db, &rollout, rolloutSelect.Where("ID = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get rollout by id")
+	}
+
+	return &rollout, nil
+}
+
+// GetRollouts fetches the given page of rollouts, newest first. The first page is 0.
+func (sqlStore *SQLStore) GetRollouts(filter *model.RolloutFilter) ([]*model.Rollout, error) {
+	builder := rolloutSelect.
+		OrderBy("CreateAt DESC", "ID DESC")
+	if len(filter.States) > 0 {
+		builder = builder.Where(sq.Eq{"State": filter.States})
+	}
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	var rollouts []*model.Rollout
+	err := sqlStore.selectBuilder(sqlStore.db, &rollouts, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for rollouts")
+	}
+
+	return rollouts, nil
+}
+
+// CreateRollout records the given rollout, assigning it a unique ID.
+func (sqlStore *SQLStore) CreateRollout(rollout *model.Rollout) error {
+	rollout.ID = model.NewID()
+	rollout.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("Rollout").
+		SetMap(map[string]interface{}{
+			"ID":             rollout.ID,
+			"Name":           rollout.Name,
+			"ReleaseID":      rollout.ReleaseID,
+			"Steps":          rollout.Steps,
+			"CurrentStep":    rollout.CurrentStep,
+			"StepStartAt":    rollout.StepStartAt,
+			"StepReleasedAt": rollout.StepReleasedAt,
+			"State":          rollout.State,
+			"Message":        rollout.Message,
+			"CreateAt":       rollout.CreateAt,
+			"LockAcquiredBy": nil,
+			"LockAcquiredAt": 0,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create rollout")
+	}
+
+	return nil
+}
+
+// UpdateRollout updates the progress and state of the given rollout.
+func (sqlStore *SQLStore) UpdateRollout(rollout *model.Rollout) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Rollout").
+		SetMap(map[string]interface{}{
+			"CurrentStep":    rollout.CurrentStep,
+			"StepStartAt":    rollout.StepStartAt,
+			"StepReleasedAt": rollout.StepReleasedAt,
+			"State":          rollout.State,
+			"Message":        rollout.Message,
+		}).
+		Where("ID = ?", rollout.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update rollout")
+	}
+
+	return nil
+}
+
+// LockRollout marks the rollout as locked for exclusive use by the caller.
+func (sqlStore *SQLStore) LockRollout(rolloutID, lockerID string) (bool, error) {
+	return sqlStore.lockRows("Rollout", []string{rolloutID}, lockerID)
+}
+
+// LockRolloutsPendingWork locks up to limit unlocked rollouts pending work,
+// oldest first, for exclusive use by the caller. It returns the locked
+// rollouts and the number of rollouts pending work already locked by others.
+func (sqlStore *SQLStore) LockRolloutsPendingWork(lockerID string, limit int) ([]*model.Rollout, int, error) {
+	ids, contended, err := sqlStore.lockRowsPendingWork("Rollout", model.AllRolloutStatesPendingWork, "CreateAt ASC", lockerID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(ids) == 0 {
+		return nil, contended, nil
+	}
+
+	var rollouts []*model.Rollout
+	err = sqlStore.selectBuilder(sqlStore.db, &rollouts, rolloutSelect.
+		Where(sq.Eq{"ID": ids}).
+		OrderBy("CreateAt ASC"),
+	)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "failed to query for locked rollouts pending work")
+	}
+
+	return rollouts, contended, nil
+}
+
+// UnlockRollout releases a lock previously acquired against a caller.
+func (sqlStore *SQLStore) UnlockRollout(rolloutID, lockerID string, force bool) (bool, error) {
+	return sqlStore.unlockRows("Rollout", []string{rolloutID}, lockerID, force)
+}
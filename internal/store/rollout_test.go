@@ -0,0 +1,94 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollouts(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	rollout1 := &model.Rollout{
+		Name:      "9.5.1",
+		ReleaseID: "release1",
+		Steps: model.RolloutSteps{
+			{Name: "staging", RingIDs: []string{"ring1"}},
+			{Name: "production", RingIDs: []string{"ring2", "ring3"}},
+		},
+		State: model.RolloutStateInProgress,
+	}
+	rollout2 := &model.Rollout{
+		ReleaseID: "release2",
+		Steps:     model.RolloutSteps{{RingIDs: []string{"ring1"}}},
+		State:     model.RolloutStateComplete,
+	}
+
+	require.NoError(t, sqlStore.CreateRollout(rollout1))
+	require.NotEmpty(t, rollout1.ID)
+	require.NotZero(t, rollout1.CreateAt)
+	require.NoError(t, sqlStore.CreateRollout(rollout2))
+
+	t.Run("get", func(t *testing.T) {
+		rollout, err := sqlStore.GetRollout(rollout1.ID)
+		require.NoError(t, err)
+		require.Equal(t, rollout1, rollout)
+
+		rollout, err = sqlStore.GetRollout(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, rollout)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		rollouts, err := sqlStore.GetRollouts(&model.RolloutFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, rollouts, 2)
+
+		rollouts, err = sqlStore.GetRollouts(&model.RolloutFilter{States: []string{model.RolloutStateInProgress}, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Rollout{rollout1}, rollouts)
+
+		rollouts, err = sqlStore.GetRollouts(&model.RolloutFilter{PerPage: 1})
+		require.NoError(t, err)
+		require.Len(t, rollouts, 1)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		rollout1.CurrentStep = 1
+		rollout1.StepStartAt = 10
+		require.NoError(t, sqlStore.UpdateRollout(rollout1))
+
+		rollout, err := sqlStore.GetRollout(rollout1.ID)
+		require.NoError(t, err)
+		require.Equal(t, 1, rollout.CurrentStep)
+		require.Equal(t, int64(10), rollout.StepStartAt)
+	})
+
+	t.Run("lock pending work", func(t *testing.T) {
+		rollouts, contended, err := sqlStore.LockRolloutsPendingWork("locker", 10)
+		require.NoError(t, err)
+		require.Zero(t, contended)
+		require.Len(t, rollouts, 1)
+		require.Equal(t, rollout1.ID, rollouts[0].ID)
+
+		locked, err := sqlStore.LockRollout(rollout1.ID, "other")
+		require.NoError(t, err)
+		require.False(t, locked)
+
+		rollouts, contended, err = sqlStore.LockRolloutsPendingWork("other", 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, contended)
+		require.Empty(t, rollouts)
+
+		unlocked, err := sqlStore.UnlockRollout(rollout1.ID, "locker", false)
+		require.NoError(t, err)
+		require.True(t, unlocked)
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var soakCheckResultSelect sq.SelectBuilder
+
+func init() {
+	soakCheckResultSelect = sq.
+		Select("ID", "InstallationGroupID", "RingID", "ReleaseID", "Name", "Query",
+			"ObservedValue", "Threshold", "Passed", "Message", "CreateAt").
+		From("SoakCheckResult")
+}
+
+// CreateSoakCheckResult records the given soak check result, assigning it a unique ID.
+func (sqlStore *SQLStore) CreateSoakCheckResult(result *model.SoakCheckResult) error {
+	result.ID = model.NewID()
+	if result.CreateAt == 0 {
+		result.CreateAt = GetMillis()
+	}
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("SoakCheckResult").
+		SetMap(map[string]interface{}{
+			"ID":                  result.ID,
+			"InstallationGroupID": result.InstallationGroupID,
+			"RingID":              result.RingID,
+			"ReleaseID":           result.ReleaseID,
+			"Name":                result.Name,
+			"Query":               result.Query,
+			"ObservedValue":       result.ObservedValue,
+			"Threshold":           result.Threshold,
+			"Passed":              result.Passed,
+			"Message":             result.Message,
+			"CreateAt":            result.CreateAt,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create soak check result")
+	}
+
+	return nil
+}
+
+// GetSoakCheckResults fetches the given page of soak check results, newest
+// first. The first page is 0.
+func (sqlStore *SQLStore) GetSoakCheckResults(filter *model.SoakCheckResultFilter) ([]*model.SoakCheckResult, error) {
+	builder := soakCheckResultSelect.
+		OrderBy("CreateAt DESC", "ID DESC")
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	if filter.InstallationGroupID != "" {
+		builder = builder.Where("InstallationGroupID = ?", filter.InstallationGroupID)
+	}
+
+	var results []*model.SoakCheckResult
+	err := sqlStore.selectBuilder(sqlStore.db, &results, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for soak check results")
+	}
+
+	return results, nil
+}
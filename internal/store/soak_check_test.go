@@ -0,0 +1,64 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoakCheckResults(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	result1 := &model.SoakCheckResult{
+		InstallationGroupID: "ig1",
+		RingID:              "ring1",
+		ReleaseID:           "release1",
+		Name:                model.SoakCheckSoakTime,
+		ObservedValue:       65,
+		Threshold:           60,
+		Passed:              true,
+		CreateAt:            1,
+	}
+	result2 := &model.SoakCheckResult{
+		InstallationGroupID: "ig1",
+		RingID:              "ring1",
+		ReleaseID:           "release1",
+		Name:                model.SoakCheckProvisioner,
+		Threshold:           1,
+		Message:             "group unhealthy",
+		CreateAt:            2,
+	}
+	result3 := &model.SoakCheckResult{
+		InstallationGroupID: "ig2",
+		RingID:              "ring1",
+		ReleaseID:           "release1",
+		Name:                model.SoakCheckSoakTime,
+		ObservedValue:       0.5,
+		Threshold:           60,
+	}
+
+	for _, result := range []*model.SoakCheckResult{result2, result1, result3} {
+		require.NoError(t, sqlStore.CreateSoakCheckResult(result))
+		require.NotEmpty(t, result.ID)
+	}
+	require.NotZero(t, result3.CreateAt)
+
+	results, err := sqlStore.GetSoakCheckResults(&model.SoakCheckResultFilter{InstallationGroupID: "ig1", PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Equal(t, []*model.SoakCheckResult{result2, result1}, results)
+
+	results, err = sqlStore.GetSoakCheckResults(&model.SoakCheckResultFilter{PerPage: 1, Page: 1})
+	require.NoError(t, err)
+	require.Equal(t, []*model.SoakCheckResult{result2}, results)
+
+	results, err = sqlStore.GetSoakCheckResults(&model.SoakCheckResultFilter{InstallationGroupID: "unknown", PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
@@ -7,24 +7,47 @@ package store
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"net/url"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/mattermost/elrond/model"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 
-	// enable the pq driver
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	// enable the sqlite3 driver
 	_ "github.com/mattn/go-sqlite3"
 )
 
 // SQLStore abstracts access to the database.
 type SQLStore struct {
-	db     *sqlx.DB
-	logger logrus.FieldLogger
+	db        *sqlx.DB
+	connector *failoverConnector
+	logger    logrus.FieldLogger
+	eventSink EventSink
+	// instanceID is the elrond server recorded as making the state changes.
+	instanceID string
+}
+
+// EventSink receives every state change event recorded by the store, e.g. to
+// forward it to an external search index.
+type EventSink interface {
+	Send(event *model.StateChangeEvent)
+}
+
+// SetEventSink configures the sink that receives every recorded state change
+// event. Passing nil disables it. It must be set before the store is used.
+func (sqlStore *SQLStore) SetEventSink(sink EventSink) {
+	sqlStore.eventSink = sink
+}
+
+// SetInstanceID configures the elrond server recorded as making the state
+// changes of the events without one. It must be set before the store is used.
+func (sqlStore *SQLStore) SetInstanceID(instanceID string) {
+	sqlStore.instanceID = instanceID
 }
 
 // dbInterface is an interface describing a resource that can execute read and write queries.
@@ -35,21 +58,24 @@ type dbInterface interface {
 	queryer
 }
 
-// New constructs a new instance of SQLStore.
-func New(dsn string, logger logrus.FieldLogger) (*SQLStore, error) {
-	if strings.Contains(dsn, "file:") {
-		dsn = strings.Replace(dsn, "file:", "fileColonPlaceholder", 1)
-	}
-	url, err := url.Parse(dsn)
+// New constructs a new instance of SQLStore. Postgres databases can be given
+// failover databases, connected to in order when the current one is
+// unreachable or no longer accepts writes.
+func New(dsn string, logger logrus.FieldLogger, failoverDSNs ...string) (*SQLStore, error) {
+	url, err := parseDSN(dsn)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to parse dsn as an url")
+		return nil, err
 	}
-	url.Host = strings.Replace(url.Host, "fileColonPlaceholder", "file:", 1)
 
 	var db *sqlx.DB
+	var connector *failoverConnector
 
 	switch strings.ToLower(url.Scheme) {
 	case "sqlite", "sqlite3":
+		if len(failoverDSNs) > 0 {
+			return nil, errors.New("failover databases are only supported with postgres")
+		}
+
 		db, err = sqlx.Connect("sqlite3", fmt.Sprintf("%s?%s", url.Host, url.RawQuery))
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to connect to sqlite database")
@@ -62,18 +88,32 @@ func New(dsn string, logger logrus.FieldLogger) (*SQLStore, error) {
 		db.MapperFunc(func(s string) string { return s })
 
 	case "postgres", "postgresql":
-		url.Scheme = "postgres"
-
-		usePgTemp := false
-		query := url.Query()
-		if _, ok := query["pg_temp"]; ok {
-			usePgTemp = true
-			query.Del("pg_temp")
-			url.RawQuery = query.Encode()
+		usePgTemp := postgresURL(url)
+
+		connectors := []driver.Connector{}
+		hosts := []string{}
+		for _, dsn := range append([]string{url.String()}, failoverDSNs...) {
+			failoverURL, err := parseDSN(dsn)
+			if err != nil {
+				return nil, err
+			}
+			if scheme := strings.ToLower(failoverURL.Scheme); scheme != "postgres" && scheme != "postgresql" {
+				return nil, errors.Errorf("unsupported failover dsn scheme %s, must be postgres", failoverURL.Scheme)
+			}
+			postgresURL(failoverURL)
+
+			pqConnector, err := pq.NewConnector(failoverURL.String())
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid postgres dsn for %s", failoverURL.Host)
+			}
+			connectors = append(connectors, pqConnector)
+			hosts = append(hosts, failoverURL.Host)
 		}
 
-		db, err = sqlx.Connect("postgres", url.String())
-		if err != nil {
+		connector = newFailoverConnector(connectors, hosts, logger)
+		db = sqlx.NewDb(sql.OpenDB(connector), "postgres")
+		if err = db.Ping(); err != nil {
+			db.Close()
 			return nil, errors.Wrap(err, "failed to connect to postgres database")
 		}
 
@@ -90,11 +130,41 @@ func New(dsn string, logger logrus.FieldLogger) (*SQLStore, error) {
 	}
 
 	return &SQLStore{
-		db,
-		logger,
+		db:        db,
+		connector: connector,
+		logger:    logger,
 	}, nil
 }
 
+// parseDSN parses the given dsn as an url.
+func parseDSN(dsn string) (*url.URL, error) {
+	if strings.Contains(dsn, "file:") {
+		dsn = strings.Replace(dsn, "file:", "fileColonPlaceholder", 1)
+	}
+	url, err := url.Parse(dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse dsn as an url")
+	}
+	url.Host = strings.Replace(url.Host, "fileColonPlaceholder", "file:", 1)
+
+	return url, nil
+}
+
+// postgresURL normalizes the given postgres dsn for the pq driver, returning
+// whether it asked to use the session's temporary-table schema.
+func postgresURL(url *url.URL) bool {
+	url.Scheme = "postgres"
+
+	query := url.Query()
+	if _, ok := query["pg_temp"]; ok {
+		query.Del("pg_temp")
+		url.RawQuery = query.Encode()
+		return true
+	}
+
+	return false
+}
+
 // queryer is an interface describing a resource that can query.
 //
 // It exactly matches sqlx.Queryer, existing simply to constrain sqlx usage to this file.
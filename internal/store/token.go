@@ -0,0 +1,121 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var tokenSelect sq.SelectBuilder
+
+func init() {
+	tokenSelect = sq.
+		Select("ID", "Name", "TenantID", "Role", "TokenHash", "CreateAt", "ExpireAt", "DeleteAt", "RingIDs", "RingSelector").
+		From("Tokens")
+}
+
+// GetToken fetches the given token by id.
+func (sqlStore *SQLStore) GetToken(id string) (*model.Token, error) {
+	var token model.Token
+	err := sqlStore.getBuilder(sqlStore.db, &token,
+		tokenSelect.Where("ID = ?", id),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get token by id")
+	}
+
+	return &token, nil
+}
+
+// GetTokenByHash fetches the token with the given secret hash.
+func (sqlStore *SQLStore) GetTokenByHash(tokenHash string) (*model.Token, error) {
+	var token model.Token
+	err := sqlStore.getBuilder(sqlStore.db, &token,
+		tokenSelect.Where("TokenHash = ?", tokenHash),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get token by hash")
+	}
+
+	return &token, nil
+}
+
+// GetTokens fetches the given page of created tokens. The first page is 0.
+func (sqlStore *SQLStore) GetTokens(filter *model.TokenFilter) ([]*model.Token, error) {
+	builder := tokenSelect.
+		OrderBy("CreateAt ASC")
+
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	if filter.TenantID != "" {
+		builder = builder.Where("TenantID = ?", filter.TenantID)
+	}
+	if !filter.IncludeDeleted {
+		builder = builder.Where("DeleteAt = 0")
+	}
+
+	var tokens []*model.Token
+	err := sqlStore.selectBuilder(sqlStore.db, &tokens, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for tokens")
+	}
+
+	return tokens, nil
+}
+
+// CreateToken records the given token to the database, assigning it a unique ID.
+func (sqlStore *SQLStore) CreateToken(token *model.Token) error {
+	token.ID = model.NewID()
+	token.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("Tokens").
+		SetMap(map[string]interface{}{
+			"ID":           token.ID,
+			"Name":         token.Name,
+			"TenantID":     token.TenantID,
+			"Role":         token.Role,
+			"TokenHash":    token.TokenHash,
+			"CreateAt":     token.CreateAt,
+			"ExpireAt":     token.ExpireAt,
+			"DeleteAt":     0,
+			"RingIDs":      token.RingIDs,
+			"RingSelector": token.RingSelector,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create token")
+	}
+
+	return nil
+}
+
+// DeleteToken revokes the given token, but does not remove the record from
+// the database.
+func (sqlStore *SQLStore) DeleteToken(id string) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("Tokens").
+		Set("DeleteAt", GetMillis()).
+		Where("ID = ?", id).
+		Where("DeleteAt = 0"),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to mark token as deleted")
+	}
+
+	return nil
+}
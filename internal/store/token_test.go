@@ -0,0 +1,124 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokens(t *testing.T) {
+	t.Run("get unknown token", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		token, err := sqlStore.GetToken("unknown")
+		require.NoError(t, err)
+		require.Nil(t, token)
+
+		token, err = sqlStore.GetTokenByHash("unknown")
+		require.NoError(t, err)
+		require.Nil(t, token)
+	})
+
+	t.Run("get tokens", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		token1 := &model.Token{
+			Name:      "ci1",
+			TenantID:  "tenant1",
+			Role:      model.TokenRoleWrite,
+			TokenHash: model.HashTokenSecret("secret1"),
+			ExpireAt:  GetMillis() + 1000,
+		}
+		token2 := &model.Token{
+			Name:      "ci2",
+			TenantID:  "tenant2",
+			Role:      model.TokenRoleRead,
+			TokenHash: model.HashTokenSecret("secret2"),
+		}
+
+		err := sqlStore.CreateToken(token1)
+		require.NoError(t, err)
+
+		time.Sleep(1 * time.Millisecond)
+
+		err = sqlStore.CreateToken(token2)
+		require.NoError(t, err)
+
+		actualToken1, err := sqlStore.GetToken(token1.ID)
+		require.NoError(t, err)
+		require.Equal(t, token1, actualToken1)
+
+		actualToken2, err := sqlStore.GetTokenByHash(model.HashTokenSecret("secret2"))
+		require.NoError(t, err)
+		require.Equal(t, token2, actualToken2)
+
+		actualTokens, err := sqlStore.GetTokens(&model.TokenFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Token{token1, token2}, actualTokens)
+
+		actualTokens, err = sqlStore.GetTokens(&model.TokenFilter{TenantID: "tenant2", PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Equal(t, []*model.Token{token2}, actualTokens)
+
+		err = sqlStore.CreateToken(&model.Token{Name: "duplicate", TokenHash: token1.TokenHash})
+		require.Error(t, err)
+	})
+
+	t.Run("delete token", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		token := &model.Token{
+			Name:      "ci1",
+			TenantID:  "tenant1",
+			Role:      model.TokenRoleWrite,
+			TokenHash: model.HashTokenSecret("secret1"),
+		}
+
+		err := sqlStore.CreateToken(token)
+		require.NoError(t, err)
+
+		err = sqlStore.DeleteToken(token.ID)
+		require.NoError(t, err)
+
+		actualToken, err := sqlStore.GetToken(token.ID)
+		require.NoError(t, err)
+		require.True(t, actualToken.IsDeleted())
+
+		actualTokens, err := sqlStore.GetTokens(&model.TokenFilter{PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Empty(t, actualTokens)
+
+		actualTokens, err = sqlStore.GetTokens(&model.TokenFilter{PerPage: model.AllPerPage, IncludeDeleted: true})
+		require.NoError(t, err)
+		require.Len(t, actualTokens, 1)
+	})
+
+	t.Run("ring-scoped token", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := MakeTestSQLStore(t, logger)
+
+		token := &model.Token{
+			Name:         "ci1",
+			TenantID:     "tenant1",
+			Role:         model.TokenRoleWrite,
+			TokenHash:    model.HashTokenSecret("secret1"),
+			RingIDs:      model.NewID(),
+			RingSelector: "env=dev",
+		}
+		require.NoError(t, sqlStore.CreateToken(token))
+
+		actualToken, err := sqlStore.GetTokenByHash(token.TokenHash)
+		require.NoError(t, err)
+		require.Equal(t, token, actualToken)
+	})
+}
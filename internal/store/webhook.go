@@ -16,7 +16,7 @@ var webhookSelect sq.SelectBuilder
 
 func init() {
 	webhookSelect = sq.
-		Select("ID", "OwnerID", "URL", "CreateAt", "DeleteAt").From("Webhooks")
+		Select("ID", "OwnerID", "URL", "Format", "Language", "LabelSelector", "Secret", "RateLimit", "Timeout", "MaxRedirects", "MaxPayloadSize", "CreateAt", "DeleteAt").From("Webhooks")
 }
 
 // GetWebhook fetches the given webhook by id.
@@ -30,6 +30,7 @@ func (sqlStore *SQLStore) GetWebhook(id string) (*model.Webhook, error) {
 	} else if err != nil {
 		return nil, errors.Wrap(err, "failed to get webhook by id")
 	}
+	webhook.Signed = webhook.Secret != ""
 
 	return &webhook, nil
 }
@@ -57,6 +58,9 @@ func (sqlStore *SQLStore) GetWebhooks(filter *model.WebhookFilter) ([]*model.Web
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query for webhooks")
 	}
+	for _, webhook := range webhooks {
+		webhook.Signed = webhook.Secret != ""
+	}
 
 	return webhooks, nil
 }
@@ -65,15 +69,26 @@ func (sqlStore *SQLStore) GetWebhooks(filter *model.WebhookFilter) ([]*model.Web
 func (sqlStore *SQLStore) CreateWebhook(webhook *model.Webhook) error {
 	webhook.ID = model.NewID()
 	webhook.CreateAt = GetMillis()
+	if webhook.Format == "" {
+		webhook.Format = model.WebhookFormatElrond
+	}
 
 	_, err := sqlStore.execBuilder(sqlStore.db, sq.
 		Insert("Webhooks").
 		SetMap(map[string]interface{}{
-			"ID":       webhook.ID,
-			"OwnerID":  webhook.OwnerID,
-			"URL":      webhook.URL,
-			"CreateAt": webhook.CreateAt,
-			"DeleteAt": 0,
+			"ID":             webhook.ID,
+			"OwnerID":        webhook.OwnerID,
+			"URL":            webhook.URL,
+			"Format":         webhook.Format,
+			"Language":       webhook.Language,
+			"LabelSelector":  webhook.LabelSelector,
+			"Secret":         webhook.Secret,
+			"RateLimit":      webhook.RateLimit,
+			"Timeout":        webhook.Timeout,
+			"MaxRedirects":   webhook.MaxRedirects,
+			"MaxPayloadSize": webhook.MaxPayloadSize,
+			"CreateAt":       webhook.CreateAt,
+			"DeleteAt":       0,
 		}),
 	)
 	if err != nil {
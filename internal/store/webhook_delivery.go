@@ -0,0 +1,178 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+var webhookDeliverySelect sq.SelectBuilder
+
+func init() {
+	webhookDeliverySelect = sq.
+		Select("ID", "WebhookID", "Payload", "State", "Attempts", "LastStatusCode",
+			"LastError", "NextAttemptAt", "LastAttemptAt", "CreateAt").
+		From("WebhookDelivery")
+}
+
+// CreateWebhookDelivery records the given webhook delivery, assigning it a
+// unique ID.
+func (sqlStore *SQLStore) CreateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	delivery.ID = model.NewID()
+	delivery.CreateAt = GetMillis()
+
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Insert("WebhookDelivery").
+		SetMap(map[string]interface{}{
+			"ID":             delivery.ID,
+			"WebhookID":      delivery.WebhookID,
+			"Payload":        delivery.Payload,
+			"State":          delivery.State,
+			"Attempts":       delivery.Attempts,
+			"LastStatusCode": delivery.LastStatusCode,
+			"LastError":      delivery.LastError,
+			"NextAttemptAt":  delivery.NextAttemptAt,
+			"LastAttemptAt":  delivery.LastAttemptAt,
+			"CreateAt":       delivery.CreateAt,
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook delivery")
+	}
+
+	return nil
+}
+
+// GetWebhookDelivery fetches the given webhook delivery by id.
+func (sqlStore *SQLStore) GetWebhookDelivery(id string) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := sqlStore.getBuilder(sqlStore.db, &delivery,
+		webhookDeliverySelect.Where("ID = ?", id),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get webhook delivery by id")
+	}
+
+	return &delivery, nil
+}
+
+// GetWebhookDeliveries fetches the given page of webhook deliveries, oldest
+// first. The first page is 0.
+func (sqlStore *SQLStore) GetWebhookDeliveries(filter *model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, error) {
+	builder := webhookDeliverySelect.
+		OrderBy("CreateAt ASC", "ID ASC")
+	if filter.WebhookID != "" {
+		builder = builder.Where("WebhookID = ?", filter.WebhookID)
+	}
+	if filter.State != "" {
+		builder = builder.Where("State = ?", filter.State)
+	}
+	if filter.PerPage != model.AllPerPage {
+		builder = builder.
+			Limit(uint64(filter.PerPage)).
+			Offset(uint64(filter.Page * filter.PerPage))
+	}
+
+	var deliveries []*model.WebhookDelivery
+	err := sqlStore.selectBuilder(sqlStore.db, &deliveries, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+// GetWebhookDeliveriesDue fetches up to limit pending webhook deliveries due
+// to be attempted at the given time, in milliseconds, earliest first.
+func (sqlStore *SQLStore) GetWebhookDeliveriesDue(now int64, limit int) ([]*model.WebhookDelivery, error) {
+	builder := webhookDeliverySelect.
+		Where("State = ?", model.WebhookDeliveryPending).
+		Where("NextAttemptAt <= ?", now).
+		OrderBy("NextAttemptAt ASC", "ID ASC").
+		Limit(uint64(limit))
+
+	var deliveries []*model.WebhookDelivery
+	err := sqlStore.selectBuilder(sqlStore.db, &deliveries, builder)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query for due webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+// ClaimWebhookDelivery postpones the next attempt of the given pending
+// delivery to leaseUntil, unless another server claimed or updated it since it
+// was fetched, returning whether it was claimed.
+func (sqlStore *SQLStore) ClaimWebhookDelivery(delivery *model.WebhookDelivery, leaseUntil int64) (bool, error) {
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("WebhookDelivery").
+		Set("NextAttemptAt", leaseUntil).
+		Where("ID = ?", delivery.ID).
+		Where("State = ?", model.WebhookDeliveryPending).
+		Where("NextAttemptAt = ?", delivery.NextAttemptAt),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to claim webhook delivery")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to count claimed webhook deliveries")
+	}
+	if rows == 0 {
+		return false, nil
+	}
+	delivery.NextAttemptAt = leaseUntil
+
+	return true, nil
+}
+
+// UpdateWebhookDelivery records the outcome of an attempt of the given webhook
+// delivery.
+func (sqlStore *SQLStore) UpdateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	_, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update("WebhookDelivery").
+		SetMap(map[string]interface{}{
+			"State":          delivery.State,
+			"Attempts":       delivery.Attempts,
+			"LastStatusCode": delivery.LastStatusCode,
+			"LastError":      delivery.LastError,
+			"NextAttemptAt":  delivery.NextAttemptAt,
+			"LastAttemptAt":  delivery.LastAttemptAt,
+		}).
+		Where("ID = ?", delivery.ID),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update webhook delivery")
+	}
+
+	return nil
+}
+
+// DeleteWebhookDeliveriesBefore deletes the webhook deliveries in the given
+// state created before the given time, in milliseconds, returning the number
+// of deleted deliveries.
+func (sqlStore *SQLStore) DeleteWebhookDeliveriesBefore(state string, timestamp int64) (int64, error) {
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Delete("WebhookDelivery").
+		Where("State = ?", state).
+		Where("CreateAt < ?", timestamp),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete webhook deliveries")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count deleted webhook deliveries")
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+
+	webhookID := model.NewID()
+	delivery1 := &model.WebhookDelivery{
+		WebhookID:     webhookID,
+		Payload:       `{"id":"1"}`,
+		State:         model.WebhookDeliveryPending,
+		NextAttemptAt: 100,
+	}
+	delivery2 := &model.WebhookDelivery{
+		WebhookID:     webhookID,
+		Payload:       `{"id":"2"}`,
+		State:         model.WebhookDeliveryPending,
+		NextAttemptAt: 200,
+	}
+	delivery3 := &model.WebhookDelivery{
+		WebhookID: model.NewID(),
+		Payload:   `{"id":"3"}`,
+		State:     model.WebhookDeliveryDelivered,
+	}
+	for _, delivery := range []*model.WebhookDelivery{delivery1, delivery2, delivery3} {
+		require.NoError(t, sqlStore.CreateWebhookDelivery(delivery))
+	}
+
+	t.Run("get", func(t *testing.T) {
+		actual, err := sqlStore.GetWebhookDelivery(delivery1.ID)
+		require.NoError(t, err)
+		require.Equal(t, delivery1, actual)
+
+		actual, err = sqlStore.GetWebhookDelivery(model.NewID())
+		require.NoError(t, err)
+		require.Nil(t, actual)
+	})
+
+	t.Run("get deliveries", func(t *testing.T) {
+		deliveries, err := sqlStore.GetWebhookDeliveries(&model.WebhookDeliveryFilter{
+			WebhookID: webhookID,
+			PerPage:   model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+
+		deliveries, err = sqlStore.GetWebhookDeliveries(&model.WebhookDeliveryFilter{
+			State:   model.WebhookDeliveryDelivered,
+			PerPage: model.AllPerPage,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{delivery3}, deliveries)
+	})
+
+	t.Run("due and claim", func(t *testing.T) {
+		due, err := sqlStore.GetWebhookDeliveriesDue(150, 10)
+		require.NoError(t, err)
+		require.Equal(t, []*model.WebhookDelivery{delivery1}, due)
+
+		stale := *due[0]
+		claimed, err := sqlStore.ClaimWebhookDelivery(due[0], 1000)
+		require.NoError(t, err)
+		require.True(t, claimed)
+		require.Equal(t, int64(1000), due[0].NextAttemptAt)
+
+		// A delivery claimed by another server since it was fetched is not
+		// claimed again.
+		claimed, err = sqlStore.ClaimWebhookDelivery(&stale, 1000)
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		due, err = sqlStore.GetWebhookDeliveriesDue(150, 10)
+		require.NoError(t, err)
+		require.Empty(t, due)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		delivery2.State = model.WebhookDeliveryFailed
+		delivery2.Attempts = 3
+		delivery2.LastStatusCode = 503
+		delivery2.LastError = "webhook responded with status 503"
+		delivery2.LastAttemptAt = 300
+		require.NoError(t, sqlStore.UpdateWebhookDelivery(delivery2))
+
+		actual, err := sqlStore.GetWebhookDelivery(delivery2.ID)
+		require.NoError(t, err)
+		require.Equal(t, delivery2, actual)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		deleted, err := sqlStore.DeleteWebhookDeliveriesBefore(model.WebhookDeliveryDelivered, GetMillis()+1)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		actual, err := sqlStore.GetWebhookDelivery(delivery3.ID)
+		require.NoError(t, err)
+		require.Nil(t, actual)
+	})
+}
@@ -33,8 +33,14 @@ func TestWebhooks(t *testing.T) {
 		}
 
 		webhook2 := &model.Webhook{
-			OwnerID: "owner2",
-			URL:     "https://url2.com",
+			OwnerID:        "owner2",
+			URL:            "https://url2.com",
+			Format:         model.WebhookFormatTeams,
+			LabelSelector:  "env=prod",
+			RateLimit:      30,
+			Timeout:        10,
+			MaxRedirects:   -1,
+			MaxPayloadSize: 4096,
 		}
 
 		err := sqlStore.CreateWebhook(webhook1)
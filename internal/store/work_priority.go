@@ -0,0 +1,98 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ringWorkOrder is the order the rings pending work are worked on in.
+	// Rollbacks come first, whatever their work priority, as the emergency
+	// path.
+	ringWorkOrder = "CASE WHEN State = '" + model.RingStateReleaseRollbackRequested + "' THEN 0 ELSE 1 END, WorkPriority DESC, CreateAt ASC"
+	// installationGroupWorkOrder is the order the installation groups
+	// pending work are worked on in, rollbacks first.
+	installationGroupWorkOrder = "CASE WHEN State = '" + model.InstallationGroupReleaseRollbackRequested + "' THEN 0 ELSE 1 END, WorkPriority DESC, ID ASC"
+)
+
+// workTable returns the table and the states pending work of the given
+// resource type.
+func workTable(resourceType string) (string, []string, error) {
+	switch resourceType {
+	case model.TypeRing:
+		return "Ring", model.AllRingStatesPendingWork, nil
+	case model.TypeInstallationGroup:
+		return "InstallationGroup", model.AllInstallationGroupStatesPendingWork, nil
+	}
+
+	return "", nil, errors.Errorf("unsupported resource type %s", resourceType)
+}
+
+// clearFinishedWorkPriority resets the work priority of the row updated by
+// the given update when its new state has no pending work, so that a ring or
+// installation group rescheduled for some work does not keep its place in the
+// queue once that work is done.
+func clearFinishedWorkPriority(update sq.UpdateBuilder, state string, pendingStates []string) sq.UpdateBuilder {
+	for _, pendingState := range pendingStates {
+		if state == pendingState {
+			return update
+		}
+	}
+
+	return update.Set("WorkPriority", 0)
+}
+
+// GetWorkPriorityRange returns the lowest and highest work priorities of the
+// rows of the given resource type pending work, or zeros if there are none.
+func (sqlStore *SQLStore) GetWorkPriorityRange(resourceType string) (int, int, error) {
+	table, states, err := workTable(resourceType)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var priorityRange struct {
+		Lowest  int
+		Highest int
+	}
+	err = sqlStore.getBuilder(sqlStore.db, &priorityRange, sq.
+		Select("COALESCE(MIN(WorkPriority), 0) AS Lowest", "COALESCE(MAX(WorkPriority), 0) AS Highest").
+		From(table).
+		Where(sq.Eq{"State": states}),
+	)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "failed to get work priority range of %s", table)
+	}
+
+	return priorityRange.Lowest, priorityRange.Highest, nil
+}
+
+// SetWorkPriority changes the work priority of the given row of the given
+// resource type without changing its state. It returns false if the row is
+// not pending work.
+func (sqlStore *SQLStore) SetWorkPriority(resourceType, id string, priority int) (bool, error) {
+	table, states, err := workTable(resourceType)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := sqlStore.execBuilder(sqlStore.db, sq.
+		Update(table).
+		Set("WorkPriority", priority).
+		Where(sq.Eq{"ID": id, "State": states}),
+	)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to set work priority in %s", table)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to count rows rescheduled in %s", table)
+	}
+
+	return rows == 1, nil
+}
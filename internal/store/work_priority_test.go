@@ -0,0 +1,158 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkPriority(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := MakeTestSQLStore(t, logger)
+	defer CloseConnection(t, sqlStore)
+
+	var pendingRings []*model.Ring
+	for i := 0; i < 3; i++ {
+		ring := &model.Ring{State: model.RingStateReleasePending}
+		require.NoError(t, sqlStore.CreateRing(ring, nil))
+		pendingRings = append(pendingRings, ring)
+		time.Sleep(1 * time.Millisecond)
+	}
+	stableRing := &model.Ring{State: model.RingStateStable}
+	require.NoError(t, sqlStore.CreateRing(stableRing, nil))
+
+	lowest, highest, err := sqlStore.GetWorkPriorityRange(model.TypeRing)
+	require.NoError(t, err)
+	require.Zero(t, lowest)
+	require.Zero(t, highest)
+
+	t.Run("only pending work is rescheduled", func(t *testing.T) {
+		rescheduled, err := sqlStore.SetWorkPriority(model.TypeRing, stableRing.ID, 10)
+		require.NoError(t, err)
+		require.False(t, rescheduled)
+
+		rescheduled, err = sqlStore.SetWorkPriority(model.TypeRing, model.NewID(), 10)
+		require.NoError(t, err)
+		require.False(t, rescheduled)
+
+		_, err = sqlStore.SetWorkPriority("rollout", pendingRings[0].ID, 10)
+		require.Error(t, err)
+	})
+
+	t.Run("rings are locked by priority", func(t *testing.T) {
+		rescheduled, err := sqlStore.SetWorkPriority(model.TypeRing, pendingRings[2].ID, 5)
+		require.NoError(t, err)
+		require.True(t, rescheduled)
+		rescheduled, err = sqlStore.SetWorkPriority(model.TypeRing, pendingRings[0].ID, -1)
+		require.NoError(t, err)
+		require.True(t, rescheduled)
+
+		ring, err := sqlStore.GetRing(pendingRings[2].ID)
+		require.NoError(t, err)
+		require.Equal(t, 5, ring.WorkPriority)
+		require.Equal(t, model.RingStateReleasePending, ring.State)
+
+		lowest, highest, err := sqlStore.GetWorkPriorityRange(model.TypeRing)
+		require.NoError(t, err)
+		require.Equal(t, -1, lowest)
+		require.Equal(t, 5, highest)
+
+		rings, _, err := sqlStore.LockRingsPendingWork(model.NewID(), 10)
+		require.NoError(t, err)
+		require.Len(t, rings, 3)
+		require.Equal(t, pendingRings[2].ID, rings[0].ID)
+		require.Equal(t, pendingRings[1].ID, rings[1].ID)
+		require.Equal(t, pendingRings[0].ID, rings[2].ID)
+	})
+
+	t.Run("installation groups are locked by priority", func(t *testing.T) {
+		installationGroup1 := model.InstallationGroup{Name: "group1", State: model.InstallationGroupReleasePending}
+		installationGroup2 := model.InstallationGroup{Name: "group2", State: model.InstallationGroupReleasePending}
+		_, err := sqlStore.CreateRingInstallationGroup(stableRing.ID, &installationGroup1)
+		require.NoError(t, err)
+		_, err = sqlStore.CreateRingInstallationGroup(stableRing.ID, &installationGroup2)
+		require.NoError(t, err)
+
+		last := &installationGroup1
+		if installationGroup2.ID > installationGroup1.ID {
+			last = &installationGroup2
+		}
+		rescheduled, err := sqlStore.SetWorkPriority(model.TypeInstallationGroup, last.ID, 1)
+		require.NoError(t, err)
+		require.True(t, rescheduled)
+
+		work, _, err := sqlStore.LockInstallationGroupsPendingWork(model.NewID(), 10)
+		require.NoError(t, err)
+		require.Len(t, work, 2)
+		require.Equal(t, last.ID, work[0].InstallationGroup.ID)
+		require.Equal(t, 1, work[0].InstallationGroup.WorkPriority)
+	})
+
+	t.Run("rollbacks are locked first", func(t *testing.T) {
+		pendingRing := &model.Ring{State: model.RingStateReleasePending}
+		require.NoError(t, sqlStore.CreateRing(pendingRing, nil))
+		_, err := sqlStore.SetWorkPriority(model.TypeRing, pendingRing.ID, 10)
+		require.NoError(t, err)
+		rollbackRing := &model.Ring{State: model.RingStateReleaseRollbackRequested}
+		require.NoError(t, sqlStore.CreateRing(rollbackRing, nil))
+
+		rings, _, err := sqlStore.LockRingsPendingWork(model.NewID(), 1)
+		require.NoError(t, err)
+		require.Len(t, rings, 1)
+		require.Equal(t, rollbackRing.ID, rings[0].ID)
+
+		installationGroup := model.InstallationGroup{Name: "group3", State: model.InstallationGroupReleasePending}
+		_, err = sqlStore.CreateRingInstallationGroup(stableRing.ID, &installationGroup)
+		require.NoError(t, err)
+		_, err = sqlStore.SetWorkPriority(model.TypeInstallationGroup, installationGroup.ID, 10)
+		require.NoError(t, err)
+		rollbackInstallationGroup := model.InstallationGroup{Name: "group4", State: model.InstallationGroupReleaseRollbackRequested}
+		_, err = sqlStore.CreateRingInstallationGroup(stableRing.ID, &rollbackInstallationGroup)
+		require.NoError(t, err)
+
+		work, _, err := sqlStore.LockInstallationGroupsPendingWork(model.NewID(), 1)
+		require.NoError(t, err)
+		require.Len(t, work, 1)
+		require.Equal(t, rollbackInstallationGroup.ID, work[0].InstallationGroup.ID)
+	})
+
+	t.Run("work priority is cleared once the work is done", func(t *testing.T) {
+		ring := &model.Ring{State: model.RingStateReleasePending}
+		require.NoError(t, sqlStore.CreateRing(ring, nil))
+		_, err := sqlStore.SetWorkPriority(model.TypeRing, ring.ID, 10)
+		require.NoError(t, err)
+
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		ring.State = model.RingStateReleaseRequested
+		require.NoError(t, sqlStore.UpdateRing(ring))
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, 10, ring.WorkPriority)
+
+		ring.State = model.RingStateStable
+		require.NoError(t, sqlStore.UpdateRing(ring))
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Zero(t, ring.WorkPriority)
+
+		installationGroup := model.InstallationGroup{Name: "group5", State: model.InstallationGroupReleasePending}
+		_, err = sqlStore.CreateRingInstallationGroup(stableRing.ID, &installationGroup)
+		require.NoError(t, err)
+		_, err = sqlStore.SetWorkPriority(model.TypeInstallationGroup, installationGroup.ID, 10)
+		require.NoError(t, err)
+
+		installationGroup.State = model.InstallationGroupReleaseFailed
+		require.NoError(t, sqlStore.UpdateInstallationGroup(&installationGroup))
+		actual, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		require.Zero(t, actual.WorkPriority)
+	})
+}
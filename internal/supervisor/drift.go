@@ -0,0 +1,212 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattermost/elrond/internal/webhook"
+	"github.com/mattermost/elrond/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DriftDetected is the drift webhook event sent when an installation
+	// group is found running an unexpected release.
+	DriftDetected = "detected"
+	// DriftResolved is the drift webhook event sent when a drifted
+	// installation group is found running its expected release again.
+	DriftResolved = "resolved"
+)
+
+// driftStore abstracts the database operations required to reconcile
+// installation groups with the provisioner.
+type driftStore interface {
+	GetRings(ringFilter *model.RingFilter) ([]*model.Ring, error)
+	GetInstallationGroupsForRing(ringID string) ([]*model.InstallationGroup, error)
+	GetRingRelease(releaseID string) (*model.RingRelease, error)
+	UpdateInstallationGroupDrift(installationGroupID string, drifted bool, observedRelease string) error
+	UpdateInstallationGroupCapacity(installationGroupID string, installations, customers, syncedAt int64) error
+	CreateStateChangeEvent(event *model.StateChangeEvent) error
+	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+}
+
+// driftProvisioner abstracts the provisioning operations required by the drift reconciler.
+type driftProvisioner interface {
+	GetInstallationGroupRelease(installationGroup *model.InstallationGroup) (string, string, error)
+	GetReleaseImpact(installationGroups []*model.InstallationGroup) (*model.ReleaseImpact, error)
+}
+
+// DriftReconciler periodically compares the release each installation group
+// is running according to the provisioner with the one elrond expects,
+// flagging the installation groups that drifted. It also syncs the
+// installation and customer counts of the installation groups.
+type DriftReconciler struct {
+	store       driftStore
+	provisioner driftProvisioner
+	logger      log.FieldLogger
+}
+
+// NewDriftReconciler creates a new DriftReconciler.
+func NewDriftReconciler(store driftStore, provisioner driftProvisioner, logger log.FieldLogger) *DriftReconciler {
+	return &DriftReconciler{
+		store:       store,
+		provisioner: provisioner,
+		logger:      logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the drift reconciler.
+func (r *DriftReconciler) Shutdown() {
+	r.logger.Debug("Shutting down drift reconciler")
+}
+
+// Do syncs the capacity of the installation groups of every ring and
+// reconciles those of every stable ring. Rings with a release in flight are
+// not reconciled, as their installation groups are expected to differ from
+// the active release, and neither are rehearsal rings.
+func (r *DriftReconciler) Do() error {
+	rings, err := r.store.GetRings(&model.RingFilter{PerPage: model.AllPerPage})
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to query for rings to reconcile")
+		return err
+	}
+
+	synced := make(map[string]bool)
+	reconciled := make(map[string]bool)
+	for _, ring := range rings {
+		// Rehearsal rings never touch their installation groups.
+		if ring.Rehearsal {
+			continue
+		}
+		logger := r.logger.WithField("ring", ring.ID)
+
+		installationGroups, err := r.store.GetInstallationGroupsForRing(ring.ID)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to get installation groups for ring")
+			continue
+		}
+
+		for _, installationGroup := range installationGroups {
+			if synced[installationGroup.ID] || installationGroup.ProvisionerGroupID == "" {
+				continue
+			}
+			synced[installationGroup.ID] = true
+
+			r.syncCapacity(installationGroup, logger.WithField("installationgroup", installationGroup.ID))
+		}
+
+		if ring.State != model.RingStateStable || ring.ActiveReleaseID == "" {
+			continue
+		}
+
+		release, err := r.store.GetRingRelease(ring.ActiveReleaseID)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to get the ring active release")
+			continue
+		}
+		if release == nil {
+			continue
+		}
+
+		for _, installationGroup := range installationGroups {
+			if reconciled[installationGroup.ID] || installationGroup.State != model.InstallationGroupStable || installationGroup.ProvisionerGroupID == "" {
+				continue
+			}
+			reconciled[installationGroup.ID] = true
+
+			r.reconcile(ring, installationGroup, release, logger.WithField("installationgroup", installationGroup.ID))
+		}
+	}
+
+	return nil
+}
+
+// RunJob reconciles the installation groups of every stable ring, like Do, on
+// behalf of a resync job.
+func (r *DriftReconciler) RunJob(job *model.Job) (string, error) {
+	if err := r.Do(); err != nil {
+		return "", err
+	}
+
+	return "resynced the installation groups of the stable rings with the provisioner", nil
+}
+
+// syncCapacity records the installations and customers behind the
+// installation group according to the provisioner, replacing any counts set
+// manually.
+func (r *DriftReconciler) syncCapacity(installationGroup *model.InstallationGroup, logger log.FieldLogger) {
+	impact, err := r.provisioner.GetReleaseImpact([]*model.InstallationGroup{installationGroup})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get the installation group capacity from the provisioner")
+		return
+	}
+
+	if err = r.store.UpdateInstallationGroupCapacity(installationGroup.ID, impact.Installations, impact.Customers, model.GetMillis()); err != nil {
+		logger.WithError(err).Error("Failed to record installation group capacity")
+	}
+}
+
+func (r *DriftReconciler) reconcile(ring *model.Ring, installationGroup *model.InstallationGroup, release *model.RingRelease, logger log.FieldLogger) {
+	image, version, err := r.provisioner.GetInstallationGroupRelease(installationGroup)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get the installation group release from the provisioner")
+		return
+	}
+
+	observedRelease := fmt.Sprintf("%s:%s", image, version)
+	drifted := image != release.Image || version != release.Version
+	if drifted == installationGroup.Drifted && observedRelease == installationGroup.ObservedRelease {
+		return
+	}
+
+	if err = r.store.UpdateInstallationGroupDrift(installationGroup.ID, drifted, observedRelease); err != nil {
+		logger.WithError(err).Error("Failed to record installation group drift")
+		return
+	}
+
+	if drifted == installationGroup.Drifted {
+		return
+	}
+
+	// The drift is recorded as an event of the installation group keeping
+	// its state, with the unexpected release as the error while it lasts.
+	stateChangeEvent := model.NewStateChangeEvent(model.TypeInstallationGroup, installationGroup.ID, ring, installationGroup.State, installationGroup.State)
+	stateChangeEvent.Metadata = installationGroup.Metadata
+
+	event := DriftResolved
+	if drifted {
+		event = DriftDetected
+		stateChangeEvent.Error = fmt.Sprintf("drift detected: running %s instead of %s:%s", observedRelease, release.Image, release.Version)
+		logger.Warnf("Installation group is running %s instead of %s:%s", observedRelease, release.Image, release.Version)
+	} else {
+		logger.Infof("Installation group is running its expected release %s again", observedRelease)
+	}
+	if err = r.store.CreateStateChangeEvent(stateChangeEvent); err != nil {
+		logger.WithError(err).Warn("failed to record installation group drift event")
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeInstallationGroup,
+		ID:        installationGroup.ID,
+		NewState:  installationGroup.State,
+		OldState:  installationGroup.State,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{
+			"Drift":           event,
+			"RingID":          ring.ID,
+			"ExpectedRelease": fmt.Sprintf("%s:%s", release.Image, release.Version),
+			"ObservedRelease": observedRelease,
+		},
+		RingID:       ring.ID,
+		Labels:       ring.Annotations,
+		Metadata:     installationGroup.Metadata,
+		RingMetadata: ring.Metadata,
+	}
+	if err = webhook.SendToAllWebhooks(r.store, webhookPayload, logger.WithField("webhookEvent", "drift-"+event)); err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
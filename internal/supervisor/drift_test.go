@@ -0,0 +1,136 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDriftProvisioner struct {
+	Image   string
+	Version string
+	Calls   int
+	Impact  *model.ReleaseImpact
+}
+
+func (p *mockDriftProvisioner) GetInstallationGroupRelease(installationGroup *model.InstallationGroup) (string, string, error) {
+	p.Calls++
+	return p.Image, p.Version, nil
+}
+
+func (p *mockDriftProvisioner) GetReleaseImpact(installationGroups []*model.InstallationGroup) (*model.ReleaseImpact, error) {
+	if p.Impact == nil {
+		return nil, errors.New("impact unavailable")
+	}
+	return p.Impact, nil
+}
+
+func TestDriftReconciler(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.0.0"})
+	require.NoError(t, err)
+
+	ring := &model.Ring{
+		State:           model.RingStateStable,
+		ActiveReleaseID: release.ID,
+	}
+	installationGroup := model.InstallationGroup{
+		Name:               "group1",
+		State:              model.InstallationGroupStable,
+		ProvisionerGroupID: "group-id",
+	}
+	err = sqlStore.CreateRing(ring, &installationGroup)
+	require.NoError(t, err)
+
+	provisioner := &mockDriftProvisioner{Image: release.Image, Version: release.Version}
+	reconciler := supervisor.NewDriftReconciler(sqlStore, provisioner, logger)
+
+	getInstallationGroup := func() *model.InstallationGroup {
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(ring.ID)
+		require.NoError(t, err)
+		return installationGroups[0]
+	}
+
+	t.Run("no drift", func(t *testing.T) {
+		require.NoError(t, reconciler.Do())
+
+		ig := getInstallationGroup()
+		require.False(t, ig.Drifted)
+		require.Equal(t, "mattermost/mattermost-enterprise-edition:7.0.0", ig.ObservedRelease)
+	})
+
+	t.Run("drift detected", func(t *testing.T) {
+		provisioner.Version = "6.9.0"
+		require.NoError(t, reconciler.Do())
+
+		ig := getInstallationGroup()
+		require.True(t, ig.Drifted)
+		require.Equal(t, "mattermost/mattermost-enterprise-edition:6.9.0", ig.ObservedRelease)
+
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{ResourceID: ig.ID, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, model.InstallationGroupStable, events[0].OldState)
+		require.Equal(t, model.InstallationGroupStable, events[0].NewState)
+		require.Contains(t, events[0].Error, "running mattermost/mattermost-enterprise-edition:6.9.0")
+	})
+
+	t.Run("drift resolved", func(t *testing.T) {
+		provisioner.Version = "7.0.0"
+		require.NoError(t, reconciler.Do())
+
+		ig := getInstallationGroup()
+		require.False(t, ig.Drifted)
+
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{ResourceID: ig.ID, After: &model.EventCursor{}, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Empty(t, events[1].Error)
+	})
+
+	t.Run("rings releasing are skipped", func(t *testing.T) {
+		ring.State = model.RingStateReleaseInProgress
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		calls := provisioner.Calls
+		provisioner.Version = "7.1.0"
+		require.NoError(t, reconciler.Do())
+
+		require.Equal(t, calls, provisioner.Calls)
+		require.False(t, getInstallationGroup().Drifted)
+	})
+
+	t.Run("capacity", func(t *testing.T) {
+		ig := getInstallationGroup()
+		require.Zero(t, ig.CapacitySyncedAt)
+
+		ig.InstallationCount = 5
+		ig.CustomerCount = 4
+		require.NoError(t, sqlStore.UpdateInstallationGroup(ig))
+		require.NoError(t, reconciler.Do())
+
+		ig = getInstallationGroup()
+		require.Equal(t, int64(5), ig.InstallationCount)
+		require.Zero(t, ig.CapacitySyncedAt)
+
+		provisioner.Impact = &model.ReleaseImpact{Installations: 12, Customers: 9}
+		require.NoError(t, reconciler.Do())
+
+		ig = getInstallationGroup()
+		require.Equal(t, int64(12), ig.InstallationCount)
+		require.Equal(t, int64(9), ig.CustomerCount)
+		require.NotZero(t, ig.CapacitySyncedAt)
+	})
+}
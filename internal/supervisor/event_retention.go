@@ -0,0 +1,54 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// eventRetentionStore abstracts the database operations required to clean up
+// the state change events.
+type eventRetentionStore interface {
+	DeleteStateChangeEventsBefore(timestamp int64) (int64, error)
+}
+
+// EventRetention periodically deletes the state change events older than the
+// retention period, so that the event history does not grow forever.
+type EventRetention struct {
+	store     eventRetentionStore
+	retention time.Duration
+	logger    log.FieldLogger
+}
+
+// NewEventRetention creates a new EventRetention keeping the events of the
+// given retention period.
+func NewEventRetention(store eventRetentionStore, retention time.Duration, logger log.FieldLogger) *EventRetention {
+	return &EventRetention{
+		store:     store,
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the event retention.
+func (r *EventRetention) Shutdown() {
+	r.logger.Debug("Shutting down event retention")
+}
+
+// Do deletes the state change events older than the retention period.
+func (r *EventRetention) Do() error {
+	deleted, err := r.store.DeleteStateChangeEventsBefore(time.Now().Add(-r.retention).UnixMilli())
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to delete expired state change events")
+		return err
+	}
+	if deleted > 0 {
+		r.logger.Infof("Deleted %d state change events older than %s", deleted, r.retention)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/stretchr/testify/require"
+)
+
+type mockEventRetentionStore struct {
+	before int64
+	err    error
+}
+
+func (s *mockEventRetentionStore) DeleteStateChangeEventsBefore(timestamp int64) (int64, error) {
+	s.before = timestamp
+	return 3, s.err
+}
+
+func TestEventRetention(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	store := &mockEventRetentionStore{}
+	retention := supervisor.NewEventRetention(store, 24*time.Hour, logger)
+
+	require.NoError(t, retention.Do())
+	require.InDelta(t, time.Now().Add(-24*time.Hour).UnixMilli(), store.before, float64(time.Minute.Milliseconds()))
+
+	store.err = errors.New("database is unreachable")
+	require.Error(t, retention.Do())
+}
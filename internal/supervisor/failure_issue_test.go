@@ -0,0 +1,154 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type mockFailureIssueCreator struct {
+	Err      error
+	Failures []*model.RepeatedFailures
+}
+
+func (c *mockFailureIssueCreator) CreateFailureIssue(failures *model.RepeatedFailures) (string, error) {
+	c.Failures = append(c.Failures, failures)
+	if c.Err != nil {
+		return "", c.Err
+	}
+	return "https://github.com/mattermost/elrond/issues/1", nil
+}
+
+func TestRingSupervisorFailureIssues(t *testing.T) {
+	settings := model.FailureIssueSettings{Threshold: 2, Window: 3600, PublicURL: "https://elrond.example.com"}
+
+	testCases := []struct {
+		Description      string
+		PastFailures     []int64
+		Err              error
+		ExpectedCreated  bool
+		ExpectedIssueURL string
+	}{
+		{"first failure", nil, nil, false, ""},
+		{"past failure outside of the window", []int64{model.GetMillis() - 2*3600*1000}, nil, false, ""},
+		{"repeated failure", []int64{model.GetMillis() - 1000}, nil, true, "https://github.com/mattermost/elrond/issues/1"},
+		{"issue creation failure", []int64{model.GetMillis() - 1000}, errors.New("unauthorized"), true, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Description, func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			creator := &mockFailureIssueCreator{Err: tc.Err}
+			provisioner := &mockRingProvisioner{PrepareReleaseError: errors.New("group rejected the release")}
+			ringSupervisor := supervisor.NewRingSupervisor(sqlStore, provisioner, "instanceID", logger, nil, model.SoakTimeDefaults{})
+			ringSupervisor.SetFailureIssueCreator(creator, settings)
+
+			release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+			require.NoError(t, err)
+			ring := &model.Ring{
+				Name:             "production",
+				State:            model.RingStateReleasePrepareRequested,
+				DesiredReleaseID: release.ID,
+			}
+			require.NoError(t, sqlStore.CreateRing(ring, nil))
+			for _, timestamp := range tc.PastFailures {
+				require.NoError(t, sqlStore.CreateStateChangeEvent(&model.StateChangeEvent{
+					ResourceType: model.TypeRing,
+					ResourceID:   ring.ID,
+					RingID:       ring.ID,
+					ReleaseID:    model.NewID(),
+					OldState:     model.RingStateReleaseInProgress,
+					NewState:     model.RingStateReleaseFailed,
+					Timestamp:    timestamp,
+				}))
+			}
+
+			ringSupervisor.Supervise(ring)
+
+			ring, err = sqlStore.GetRing(ring.ID)
+			require.NoError(t, err)
+			require.Equal(t, model.RingStateReleaseFailed, ring.State)
+			require.Equal(t, tc.ExpectedIssueURL, ring.FailureIssueURL)
+			if tc.ExpectedIssueURL != "" {
+				require.NotZero(t, ring.FailureIssueAt)
+			}
+			if !tc.ExpectedCreated {
+				require.Empty(t, creator.Failures)
+				return
+			}
+
+			require.Len(t, creator.Failures, 1)
+			require.Len(t, creator.Failures[0].Failures, 2)
+			require.Equal(t, release.ID, creator.Failures[0].Failures[1].ReleaseID)
+			require.Equal(t, time.Hour, creator.Failures[0].Window)
+			require.Equal(t, "https://elrond.example.com/api/v1/ring/"+ring.ID+"/events", creator.Failures[0].EventsURL)
+		})
+	}
+
+	t.Run("issue is opened once", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		creator := &mockFailureIssueCreator{}
+		provisioner := &mockRingProvisioner{PrepareReleaseError: errors.New("group rejected the release")}
+		ringSupervisor := supervisor.NewRingSupervisor(sqlStore, provisioner, "instanceID", logger, nil, model.SoakTimeDefaults{})
+		ringSupervisor.SetFailureIssueCreator(creator, model.FailureIssueSettings{Threshold: 1, Window: 3600})
+
+		release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+		ring := &model.Ring{
+			Name:             "production",
+			State:            model.RingStateReleasePrepareRequested,
+			DesiredReleaseID: release.ID,
+			FailureIssueURL:  "https://github.com/mattermost/elrond/issues/7",
+		}
+		require.NoError(t, sqlStore.CreateRing(ring, nil))
+
+		ringSupervisor.Supervise(ring)
+
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseFailed, ring.State)
+		require.Equal(t, "https://github.com/mattermost/elrond/issues/7", ring.FailureIssueURL)
+		require.Empty(t, creator.Failures)
+	})
+
+	t.Run("completed release forgets the issue", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		ringSupervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0", Force: true})
+		require.NoError(t, err)
+		ring := &model.Ring{
+			Name:             "production",
+			State:            model.RingStateReleaseInProgress,
+			DesiredReleaseID: release.ID,
+			FailureIssueURL:  "https://github.com/mattermost/elrond/issues/7",
+			FailureIssueAt:   time.Now().UnixNano(),
+		}
+		installationGroup := model.InstallationGroup{
+			Name:  "group1",
+			State: model.InstallationGroupStable,
+		}
+		require.NoError(t, sqlStore.CreateRing(ring, &installationGroup))
+
+		ringSupervisor.Supervise(ring)
+
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, ring.State)
+		require.Empty(t, ring.FailureIssueURL)
+		require.Zero(t, ring.FailureIssueAt)
+	})
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+)
+
+// healthMetrics records the cycles of the supervisors.
+type healthMetrics interface {
+	ObserveSupervisorCycle(supervisor string, err error, at time.Time)
+}
+
+// databaseHealth reports the connection to the database.
+type databaseHealth interface {
+	DatabaseHealth() *model.DatabaseHealth
+}
+
+// HealthMonitor records the cycles of the supervisors, reporting a supervisor
+// unhealthy when it has not completed one within a number of its periods, so
+// that a wedged server can be restarted.
+type HealthMonitor struct {
+	lock            sync.RWMutex
+	maxMissedCycles int
+	supervisors     []*supervisorHealth
+	metrics         healthMetrics
+	database        databaseHealth
+}
+
+// supervisorHealth holds the cycles recorded for a supervisor.
+type supervisorHealth struct {
+	name          string
+	period        time.Duration
+	trackedAt     time.Time
+	cycles        int64
+	errors        int64
+	lastSuccessAt time.Time
+	lastErrorAt   time.Time
+	lastError     string
+}
+
+// NewHealthMonitor creates a new HealthMonitor reporting the supervisors that
+// have not completed a cycle in maxMissedCycles of their periods as
+// unhealthy. Zero disables it.
+func NewHealthMonitor(maxMissedCycles int, metrics healthMetrics) *HealthMonitor {
+	return &HealthMonitor{
+		maxMissedCycles: maxMissedCycles,
+		metrics:         metrics,
+	}
+}
+
+// SetMaxMissedCycles changes the number of periods a supervisor can go
+// without completing a cycle before it is unhealthy. Zero disables it.
+func (m *HealthMonitor) SetMaxMissedCycles(maxMissedCycles int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.maxMissedCycles = maxMissedCycles
+}
+
+// SetDatabase configures the database whose connection is reported with the
+// supervisors. The server is not ready while it is disconnected.
+func (m *HealthMonitor) SetDatabase(database databaseHealth) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.database = database
+}
+
+// Track returns the given doer recording its cycles under the given name,
+// expected to run every period. The returned doer records errors rather than
+// returning them, so that the other doers of a MultiDoer still run.
+func (m *HealthMonitor) Track(name string, doer Doer, period time.Duration) Doer {
+	health := &supervisorHealth{
+		name:      name,
+		period:    period,
+		trackedAt: time.Now(),
+	}
+
+	m.lock.Lock()
+	m.supervisors = append(m.supervisors, health)
+	m.lock.Unlock()
+
+	return &trackedDoer{doer: doer, health: health, monitor: m}
+}
+
+// SetPeriod changes the period the named supervisors are expected to run
+// every.
+func (m *HealthMonitor) SetPeriod(period time.Duration, names ...string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, health := range m.supervisors {
+		for _, name := range names {
+			if health.name == name {
+				health.period = period
+			}
+		}
+	}
+}
+
+// record records a cycle of the given supervisor.
+func (m *HealthMonitor) record(health *supervisorHealth, err error, at time.Time) {
+	m.lock.Lock()
+	health.cycles++
+	if err != nil {
+		health.errors++
+		health.lastErrorAt = at
+		health.lastError = err.Error()
+	} else {
+		health.lastSuccessAt = at
+	}
+	m.lock.Unlock()
+
+	if m.metrics != nil {
+		m.metrics.ObserveSupervisorCycle(health.name, err, at)
+	}
+}
+
+// Health returns the health of every supervisor at the given time, and of
+// the database connection if set. The server is ready when all are healthy.
+func (m *HealthMonitor) Health(now time.Time) *model.Readiness {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	readiness := &model.Readiness{Ready: true, Supervisors: []*model.SupervisorHealth{}}
+	for _, health := range m.supervisors {
+		report := &model.SupervisorHealth{
+			Name:    health.name,
+			Period:  int64(health.period / time.Second),
+			Cycles:  health.cycles,
+			Errors:  health.errors,
+			Healthy: true,
+		}
+		if !health.lastSuccessAt.IsZero() {
+			report.LastSuccessAt = health.lastSuccessAt.UnixMilli()
+		}
+		if !health.lastErrorAt.IsZero() {
+			report.LastErrorAt = health.lastErrorAt.UnixMilli()
+			report.LastError = health.lastError
+		}
+
+		// Supervisors that never completed a cycle are given their periods
+		// from when they started being tracked.
+		if m.maxMissedCycles > 0 && health.period > 0 {
+			since := health.lastSuccessAt
+			if since.IsZero() {
+				since = health.trackedAt
+			}
+			report.Healthy = now.Sub(since) <= time.Duration(m.maxMissedCycles)*health.period
+		}
+		if !report.Healthy {
+			readiness.Ready = false
+		}
+
+		readiness.Supervisors = append(readiness.Supervisors, report)
+	}
+
+	if m.database != nil {
+		readiness.Database = m.database.DatabaseHealth()
+		if !readiness.Database.Connected {
+			readiness.Ready = false
+		}
+	}
+
+	return readiness
+}
+
+// trackedDoer records the cycles of a doer with its HealthMonitor.
+type trackedDoer struct {
+	doer    Doer
+	health  *supervisorHealth
+	monitor *HealthMonitor
+}
+
+// Do runs a cycle of the doer, recording its outcome.
+func (d *trackedDoer) Do() error {
+	err := d.doer.Do()
+	d.monitor.record(d.health, err, time.Now())
+
+	return nil
+}
+
+// Shutdown tells the doer to perform shutdown tasks.
+func (d *trackedDoer) Shutdown() {
+	d.doer.Shutdown()
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHealthMetrics struct {
+	cycles map[string]int
+}
+
+func (m *mockHealthMetrics) ObserveSupervisorCycle(supervisor string, err error, at time.Time) {
+	m.cycles[supervisor]++
+}
+
+func TestHealthMonitor(t *testing.T) {
+	metrics := &mockHealthMetrics{cycles: map[string]int{}}
+	monitor := supervisor.NewHealthMonitor(3, metrics)
+
+	ring := monitor.Track("ring", &errorDoer{}, time.Minute)
+	failing := monitor.Track("drift", &errorDoer{err: errors.New("failed")}, time.Hour)
+
+	t.Run("never run", func(t *testing.T) {
+		readiness := monitor.Health(time.Now())
+		require.True(t, readiness.Ready)
+		require.Len(t, readiness.Supervisors, 2)
+		assert.Zero(t, readiness.Supervisors[0].LastSuccessAt)
+
+		readiness = monitor.Health(time.Now().Add(4 * time.Minute))
+		assert.False(t, readiness.Ready)
+		assert.False(t, readiness.Supervisors[0].Healthy)
+		assert.True(t, readiness.Supervisors[1].Healthy)
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		require.NoError(t, ring.Do())
+		require.NoError(t, failing.Do())
+
+		readiness := monitor.Health(time.Now().Add(2 * time.Minute))
+		require.True(t, readiness.Ready)
+		assert.Equal(t, "ring", readiness.Supervisors[0].Name)
+		assert.Equal(t, int64(60), readiness.Supervisors[0].Period)
+		assert.Equal(t, int64(1), readiness.Supervisors[0].Cycles)
+		assert.NotZero(t, readiness.Supervisors[0].LastSuccessAt)
+		assert.Equal(t, int64(1), readiness.Supervisors[1].Errors)
+		assert.NotZero(t, readiness.Supervisors[1].LastErrorAt)
+		assert.NotEmpty(t, readiness.Supervisors[1].LastError)
+		assert.Zero(t, readiness.Supervisors[1].LastSuccessAt)
+		assert.Equal(t, 1, metrics.cycles["ring"])
+		assert.Equal(t, 1, metrics.cycles["drift"])
+
+		readiness = monitor.Health(time.Now().Add(4 * time.Hour))
+		assert.False(t, readiness.Ready)
+		assert.False(t, readiness.Supervisors[1].Healthy)
+	})
+
+	t.Run("set period", func(t *testing.T) {
+		monitor.SetPeriod(2*time.Hour, "ring", "drift")
+		readiness := monitor.Health(time.Now().Add(5 * time.Hour))
+		assert.True(t, readiness.Supervisors[0].Healthy)
+		assert.True(t, readiness.Supervisors[1].Healthy)
+		assert.Equal(t, int64(7200), readiness.Supervisors[0].Period)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		monitor.SetMaxMissedCycles(0)
+		assert.True(t, monitor.Health(time.Now().Add(100*time.Hour)).Ready)
+	})
+
+	t.Run("database", func(t *testing.T) {
+		database := &mockDatabaseHealth{health: &model.DatabaseHealth{Connected: true, Databases: 2}}
+		monitor.SetDatabase(database)
+		readiness := monitor.Health(time.Now())
+		assert.True(t, readiness.Ready)
+		assert.Equal(t, database.health, readiness.Database)
+
+		database.health = &model.DatabaseHealth{Connected: false, Databases: 2, LastError: "database is read-only"}
+		readiness = monitor.Health(time.Now())
+		assert.False(t, readiness.Ready)
+		assert.Equal(t, "database is read-only", readiness.Database.LastError)
+	})
+}
+
+type mockDatabaseHealth struct {
+	health *model.DatabaseHealth
+}
+
+func (m *mockDatabaseHealth) DatabaseHealth() *model.DatabaseHealth {
+	return m.health
+}
+
+func TestTrackedDoerRecordsErrors(t *testing.T) {
+	monitor := supervisor.NewHealthMonitor(1, nil)
+	doer := monitor.Track("rollout", &errorDoer{err: errors.New("lock failed")}, time.Minute)
+
+	require.NoError(t, doer.Do())
+	readiness := monitor.Health(time.Now())
+	require.Len(t, readiness.Supervisors, 1)
+	assert.Equal(t, "lock failed", readiness.Supervisors[0].LastError)
+}
+
+type errorDoer struct {
+	err error
+}
+
+func (d *errorDoer) Do() error {
+	return d.err
+}
+
+func (d *errorDoer) Shutdown() {}
@@ -5,8 +5,11 @@
 package supervisor
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/mattermost/elrond/internal/metrics"
 	"github.com/mattermost/elrond/internal/webhook"
 	"github.com/mattermost/elrond/model"
 	log "github.com/sirupsen/logrus"
@@ -15,23 +18,58 @@ import (
 // installationGroupStore abstracts the database operations required to manage installation groups.
 type installationGroupStore interface {
 	GetInstallationGroupsPendingWork() ([]*model.InstallationGroup, error)
+	LockInstallationGroupsPendingWork(lockerID string, limit int) ([]*model.InstallationGroupWork, int, error)
+	GetInstallationGroupWork(installationGroupID string) (*model.InstallationGroupWork, error)
 	GetInstallationGroupByID(id string) (*model.InstallationGroup, error)
 	UpdateInstallationGroup(installationGroup *model.InstallationGroup) error
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
-	GetRingFromInstallationGroupID(installationGroupID string) (*model.Ring, error)
 	LockRingInstallationGroup(installationGroupID, lockerID string) (bool, error)
 	UnlockRingInstallationGroup(installationGroupID string, lockerID string, force bool) (bool, error)
 	GetInstallationGroupsLocked() ([]*model.InstallationGroup, error)
 	GetInstallationGroupsReleaseInProgress() ([]*model.InstallationGroup, error)
-	GetRingRelease(releaseID string) (*model.RingRelease, error)
+	UpdateInstallationGroupReleaseProgress(installationGroupID string, progress int) error
+	UpdateInstallationGroupLoadDeferredAt(installationGroupID string, deferredAt int64) error
+	UpdateInstallationGroupProvisionerGroups(installationGroupID, provisionerGroupID, greenProvisionerGroupID string) error
+	UpdateInstallationGroupVerification(installationGroupID string, job *model.VerificationJob, startAt int64) error
+	UpdateInstallationGroupActiveRelease(installationGroupID, activeReleaseID, previousReleaseID string) error
 	GetRingsPendingWork() ([]*model.Ring, error)
+	GetRings(ringFilter *model.RingFilter) ([]*model.Ring, error)
+	GetInstallationGroupsForRing(ringID string) ([]*model.InstallationGroup, error)
+	GetInstallationGroupsForRings(filter *model.RingFilter) (map[string][]*model.InstallationGroup, error)
 	UpdateRings(rings []*model.Ring) error
+	GetRingRelease(releaseID string) (*model.RingRelease, error)
+	CreateStateChangeEvent(event *model.StateChangeEvent) error
+	CreateSoakCheckResult(result *model.SoakCheckResult) error
+	CreateHealthSnapshot(snapshot *model.HealthSnapshot) error
+	CreateDatabaseSnapshots(snapshots []*model.DatabaseSnapshot) error
+	GetDatabaseSnapshots(filter *model.DatabaseSnapshotFilter) ([]*model.DatabaseSnapshot, error)
 }
 
 // installationGroupProvisioner abstracts the provisioning operations required by the installation group supervisor.
 type installationGroupProvisioner interface {
-	ReleaseInstallationGroup(installationGroup *model.InstallationGroup, image, version string) error
+	ReleaseInstallationGroup(installationGroup *model.InstallationGroup, image, version string, parameters *model.InstallationGroupReleaseParameters) error
+	RollbackInstallationGroup(installationGroup *model.InstallationGroup, image, version string) error
 	SoakInstallationGroup(installationGroup *model.InstallationGroup) error
+	GetInstallationGroupHealth(installationGroup *model.InstallationGroup) (*model.HealthSnapshot, error)
+	GetInstallationGroupLoad(installationGroup *model.InstallationGroup) (*model.InstallationGroupLoad, error)
+	SnapshotInstallationGroupDatabases(installationGroup *model.InstallationGroup, release *model.RingRelease) ([]*model.DatabaseSnapshot, error)
+	StandUpGreenInstallationGroup(installationGroup *model.InstallationGroup, image, version string, parameters *model.InstallationGroupReleaseParameters) (string, error)
+	VerifyGreenInstallationGroup(installationGroup *model.InstallationGroup, image, version string) error
+	SwitchInstallationGroup(installationGroup *model.InstallationGroup) error
+	TearDownBlueInstallationGroup(installationGroup *model.InstallationGroup) error
+}
+
+// SoakChecker evaluates the soak checks of installation groups, returning the
+// observed value of the query of a check.
+type SoakChecker interface {
+	Evaluate(check *model.SoakCheck) (float64, error)
+}
+
+// ReleaseVerifier starts the verification jobs of the releases of
+// installation groups and reports their status.
+type ReleaseVerifier interface {
+	StartVerification(installationGroup *model.InstallationGroup, release *model.RingRelease) (*model.VerificationJob, error)
+	GetVerification(installationGroup *model.InstallationGroup, jobID string) (*model.VerificationJob, error)
 }
 
 // InstallationGroupSupervisor finds installation groups pending work and effects the required changes.
@@ -41,36 +79,128 @@ type installationGroupProvisioner interface {
 type InstallationGroupSupervisor struct {
 	store       installationGroupStore
 	provisioner installationGroupProvisioner
+	rehearsal   *rehearsalProvisioner
 	instanceID  string
 	logger      log.FieldLogger
+	metrics     *metrics.Metrics
+	soakChecker SoakChecker
+	verifier    ReleaseVerifier
+
+	lockBatchSize int
+	semaphore     Semaphore
 }
 
 // NewInstallationGroupSupervisor creates a new InstallationGroupSupervisor.
-func NewInstallationGroupSupervisor(store installationGroupStore, installationGroupProvisioner installationGroupProvisioner, instanceID string, logger log.FieldLogger) *InstallationGroupSupervisor {
+func NewInstallationGroupSupervisor(store installationGroupStore, installationGroupProvisioner installationGroupProvisioner, instanceID string, logger log.FieldLogger, metrics *metrics.Metrics) *InstallationGroupSupervisor {
 	return &InstallationGroupSupervisor{
-		store:       store,
-		provisioner: installationGroupProvisioner,
-		instanceID:  instanceID,
-		logger:      logger,
+		store:         store,
+		provisioner:   installationGroupProvisioner,
+		rehearsal:     newRehearsalProvisioner(logger),
+		instanceID:    instanceID,
+		logger:        logger,
+		metrics:       metrics,
+		lockBatchSize: DefaultLockBatchSize,
+		semaphore:     NewSemaphore(DefaultParallelism),
 	}
 }
 
+// SetLockBatchSize changes the number of installation groups pending work
+// locked at once on each run. It must be called before the supervisor is
+// first run.
+func (s *InstallationGroupSupervisor) SetLockBatchSize(lockBatchSize int) {
+	s.lockBatchSize = lockBatchSize
+}
+
+// SetSemaphore changes the semaphore bounding the number of installation
+// groups worked on at once, so that it can be shared with other supervisors.
+// It must be called before the supervisor is first run.
+func (s *InstallationGroupSupervisor) SetSemaphore(semaphore Semaphore) {
+	s.semaphore = semaphore
+}
+
+// SetSoakChecker sets the checker evaluating the soak checks of the
+// installation groups. Without one, installation groups declaring soak checks
+// fail to soak. It must be called before the supervisor is first run.
+func (s *InstallationGroupSupervisor) SetSoakChecker(soakChecker SoakChecker) {
+	s.soakChecker = soakChecker
+}
+
+// SetReleaseVerifier sets the verifier running the verification jobs of the
+// releases of the installation groups. Without one, the releases of
+// installation groups with a verification URL fail. It must be called before
+// the supervisor is first run.
+func (s *InstallationGroupSupervisor) SetReleaseVerifier(verifier ReleaseVerifier) {
+	s.verifier = verifier
+}
+
+// provisionerFor returns the provisioner of the installation group of the
+// given work: the rehearsal provisioner for the installation groups of
+// rehearsal rings, so that they never touch real installations.
+func (s *InstallationGroupSupervisor) provisionerFor(work *model.InstallationGroupWork) installationGroupProvisioner {
+	if work.Ring != nil && work.Ring.Rehearsal {
+		return s.rehearsal
+	}
+
+	return s.provisioner
+}
+
+// verifierFor returns the release verifier of the installation group of the
+// given work, if any: the rehearsal provisioner for the installation groups
+// of rehearsal rings.
+func (s *InstallationGroupSupervisor) verifierFor(work *model.InstallationGroupWork) ReleaseVerifier {
+	if work.Ring != nil && work.Ring.Rehearsal {
+		return s.rehearsal
+	}
+
+	return s.verifier
+}
+
 // Shutdown performs graceful shutdown tasks for the installation group supervisor.
 func (s *InstallationGroupSupervisor) Shutdown() {
 	s.logger.Debug("Shutting down installation group supervisor")
 }
 
-// Do looks for work to be done on any pending rings and attempts to schedule the required work.
+// Do looks for work to be done on any pending installation groups and
+// attempts to schedule the required work. The installation groups of a ring
+// are worked on sequentially, while the rings proceed in parallel.
 func (s *InstallationGroupSupervisor) Do() error {
-	installationGroups, err := s.store.GetInstallationGroupsPendingWork()
+	// The installation groups are fetched along with their rings and releases
+	// once locked, to queue them by ring. Each is fetched again right before
+	// it is worked on, as the earlier installation groups of its queue may
+	// have changed the ring since, e.g. pausing or failing its release.
+	work, contended, err := s.store.LockInstallationGroupsPendingWork(s.instanceID, s.lockBatchSize)
 	if err != nil {
-		s.logger.WithError(err).Warn("Failed to query for installation groups pending work")
-		return nil
+		s.logger.WithError(err).Warn("Failed to lock installation groups pending work")
+		return err
 	}
+	s.metrics.ObserveLockAcquisitions(model.TypeInstallationGroup, len(work), contended)
 
-	for _, installationGroup := range installationGroups {
-		s.Supervise(installationGroup)
+	items := make([]ringQueueItem, 0, len(work))
+	for _, w := range work {
+		w := w
+		item := ringQueueItem{
+			priority: w.InstallationGroup.State == model.InstallationGroupReleaseRollbackRequested,
+			do: func(bypassed []string) {
+				logger := s.logger.WithFields(log.Fields{
+					"installationgroup": w.InstallationGroup.ID,
+				})
+				defer newInstallationGroupLock(w.InstallationGroup.ID, s.instanceID, s.store, logger).Unlock()
+
+				refreshed, ok := s.refreshWork(w, logger)
+				if !ok {
+					return
+				}
+				s.superviseSafely(refreshed, bypassed, logger)
+			},
+		}
+		// Installation groups outside of any ring share a queue.
+		if w.Ring != nil {
+			item.ringID = w.Ring.ID
+			item.ringName = w.Ring.Name
+		}
+		items = append(items, item)
 	}
+	runRingQueues(model.TypeInstallationGroup, items, s.semaphore, s.metrics)
 
 	return nil
 }
@@ -87,26 +217,71 @@ func (s *InstallationGroupSupervisor) Supervise(installationGroup *model.Install
 	}
 	defer lock.Unlock()
 
-	// Before working on the installation group, it is crucial that we ensure that it was
-	// not updated to a new state by another elrond server.
-	originalState := installationGroup.State
-	installationGroup, err := s.store.GetInstallationGroupByID(installationGroup.ID)
+	work, ok := s.refreshWork(&model.InstallationGroupWork{InstallationGroup: installationGroup}, logger)
+	if !ok {
+		return
+	}
+
+	s.superviseSafely(work, nil, logger)
+}
+
+// refreshWork fetches the given work again, along with the current state of
+// its ring, before its locked installation group is worked on. It returns
+// false if the installation group was updated to a new state in the meantime,
+// e.g. by another elrond server, or could not be fetched.
+func (s *InstallationGroupSupervisor) refreshWork(work *model.InstallationGroupWork, logger log.FieldLogger) (*model.InstallationGroupWork, bool) {
+	refreshed, err := s.store.GetInstallationGroupWork(work.InstallationGroup.ID)
 	if err != nil {
 		logger.WithError(err).Errorf("Failed to get refreshed installation group")
-		return
+		return nil, false
 	}
-	if installationGroup.State != originalState {
-		logger.WithField("oldInstallationGroupState", originalState).
-			WithField("newInstallationGroupState", installationGroup.State).
+	if refreshed == nil {
+		refreshed = &model.InstallationGroupWork{InstallationGroup: work.InstallationGroup}
+	}
+	if refreshed.InstallationGroup.State != work.InstallationGroup.State {
+		logger.WithField("oldInstallationGroupState", work.InstallationGroup.State).
+			WithField("newInstallationGroupState", refreshed.InstallationGroup.State).
 			Warn("Another provisioner has worked on this installationGroup; skipping...")
+		return nil, false
+	}
+
+	return refreshed, true
+}
+
+// superviseSafely supervises the installation group of the given work, which
+// the caller must have locked, failing it should its supervision panic.
+func (s *InstallationGroupSupervisor) superviseSafely(work *model.InstallationGroupWork, bypassed []string, logger log.FieldLogger) {
+	superviseRecovering(model.TypeInstallationGroup, s.metrics, logger, func() {
+		s.supervise(work, bypassed, logger)
+	}, func(cause string) {
+		s.failPanickedInstallationGroup(work, cause, logger)
+	})
+}
+
+// supervise schedules the required work on the installation group of the
+// given work, which the caller must have locked, recording the limits its
+// rollback bypassed, if any.
+func (s *InstallationGroupSupervisor) supervise(work *model.InstallationGroupWork, bypassed []string, logger log.FieldLogger) {
+	installationGroup := work.InstallationGroup
+	if !installationGroup.SupportedStateMachine() {
+		logger.WithField("stateMachineVersion", installationGroup.StateMachineVersion).
+			Warn("Installation group follows a state machine version unknown to this server; skipping...")
 		return
 	}
+	if work.Ring != nil && work.Ring.State == model.RingStateReleasePaused {
+		if installationGroup.State != model.InstallationGroupReleaseRollbackRequested {
+			logger.Debug("The release of the ring of the installation group is paused; skipping...")
+			return
+		}
+		// Rollbacks are the emergency path, so they go on regardless.
+		bypassed = append(bypassed, model.BypassReleasePaused)
+	}
 
 	logger.Debugf("Supervising installation group in state %s", installationGroup.State)
 
-	newState := s.transitionInstallationGroup(installationGroup, logger)
+	newState := s.transitionInstallationGroup(work, logger)
 
-	installationGroup, err = s.store.GetInstallationGroupByID(installationGroup.ID)
+	installationGroup, err := s.store.GetInstallationGroupByID(installationGroup.ID)
 	if err != nil {
 		logger.WithError(err).Warnf("failed to get installation group and thus persist state %s", newState)
 		return
@@ -118,72 +293,221 @@ func (s *InstallationGroupSupervisor) Supervise(installationGroup *model.Install
 
 	oldState := installationGroup.State
 	installationGroup.State = newState
-	if oldState == model.InstallationGroupReleaseRequested && (newState == model.InstallationGroupReleaseSoakingRequested || newState == model.InstallationGroupStable) {
+	releaseCompleted := (oldState == model.InstallationGroupReleaseRequested || oldState == model.InstallationGroupBlueGreenTeardownRequested || oldState == model.InstallationGroupReleaseVerificationRequested) &&
+		(newState == model.InstallationGroupReleaseSoakingRequested || newState == model.InstallationGroupStable)
+	if releaseCompleted {
 		installationGroup.ReleaseAt = time.Now().UnixNano()
 	}
+	// A forced release of the installation group alone ends with it.
+	if installationGroup.ForceRelease && (newState == model.InstallationGroupStable || installationGroup.HasFailedRelease()) {
+		installationGroup.ForceRelease = false
+	}
+	if installationGroup.UpgradeStateMachine() {
+		logger.Infof("Upgrading installation group to state machine version %d", installationGroup.StateMachineVersion)
+	}
 
 	if err = s.store.UpdateInstallationGroup(installationGroup); err != nil {
 		logger.WithError(err).Warnf("failed to set installation group state to %s", newState)
 		return
 	}
 
-	//Move rings to release-failed as soon as an IG release fails
-	if newState == model.InstallationGroupReleaseFailed || newState == model.InstallationGroupReleaseSoakingFailed {
-		logger.Info("Installation group release has failed, moving ring to failed state")
-		rings, err := s.store.GetRingsPendingWork()
-		if err != nil {
-			logger.WithError(err).Error("failed to get all rings pending work")
-			return
+	if len(bypassed) > 0 {
+		logger.Warnf("Rolled back the installation group bypassing %s", strings.Join(bypassed, ", "))
+	}
+	s.recordStateChange(installationGroup, work.Ring, oldState, newState, bypassed, logger)
+
+	if releaseCompleted {
+		s.recordActiveRelease(installationGroup, work, logger)
+	}
+
+	if oldState == model.InstallationGroupReleasePending && installationGroup.LoadDeferredAt != 0 {
+		if err = s.store.UpdateInstallationGroupLoadDeferredAt(installationGroup.ID, 0); err != nil {
+			logger.WithError(err).Warn("failed to reset installation group load deferral")
 		}
-		for _, ring := range rings {
-			ring.State = model.RingStateReleaseFailed
+	}
+
+	// Progress reported by provisioner callbacks belongs to a single release.
+	if newState == model.InstallationGroupReleaseRequested && installationGroup.ReleaseProgress != 0 {
+		if err = s.store.UpdateInstallationGroupReleaseProgress(installationGroup.ID, 0); err != nil {
+			logger.WithError(err).Warn("failed to reset installation group release progress")
 		}
+	}
 
-		if err = s.store.UpdateRings(rings); err != nil {
-			logger.WithError(err).Error("failed to move rings to failed state")
-			return
+	// So does the verification job, started anew by each release.
+	if newState == model.InstallationGroupReleaseRequested && installationGroup.VerificationJobID != "" {
+		if err = s.store.UpdateInstallationGroupVerification(installationGroup.ID, &model.VerificationJob{}, 0); err != nil {
+			logger.WithError(err).Warn("failed to reset installation group verification")
 		}
 	}
 
+	s.sendWebhook(installationGroup, work.Ring, oldState, newState, logger)
+
+	logger.Debugf("Transitioned installation group from %s to %s", oldState, newState)
+
+	if newState == model.InstallationGroupReleaseFailed || newState == model.InstallationGroupReleaseSoakingFailed {
+		s.applyFailurePolicy(installationGroup, work.Ring, logger)
+	}
+}
+
+// sendWebhook sends the state change of the installation group to all
+// webhooks.
+func (s *InstallationGroupSupervisor) sendWebhook(installationGroup *model.InstallationGroup, ring *model.Ring, oldState, newState string, logger log.FieldLogger) {
 	webhookPayload := &model.WebhookPayload{
 		Type:      model.TypeRing,
 		ID:        installationGroup.ID,
 		NewState:  newState,
 		OldState:  oldState,
 		Timestamp: time.Now().UnixNano(),
+		Metadata:  installationGroup.Metadata,
+	}
+	if ring != nil {
+		webhookPayload.RingID = ring.ID
+		webhookPayload.Labels = ring.Annotations
+		webhookPayload.RingMetadata = ring.Metadata
 	}
-	if err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+	annotateRehearsal(webhookPayload, ring)
+	if err := webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
 		logger.WithError(err).Error("Unable to process and send webhooks")
 	}
+}
 
-	logger.Debugf("Transitioned installation group from %s to %s", oldState, newState)
+// recordActiveRelease records the release the installation group of the given
+// work completed as its active release, keeping the one it ran before to roll
+// back to.
+func (s *InstallationGroupSupervisor) recordActiveRelease(installationGroup *model.InstallationGroup, work *model.InstallationGroupWork, logger log.FieldLogger) {
+	if work.Release == nil || installationGroup.ActiveReleaseID == work.Release.ID {
+		return
+	}
+
+	previousReleaseID := installationGroup.ActiveReleaseID
+	if previousReleaseID == "" && work.Ring != nil {
+		previousReleaseID = work.Ring.ActiveReleaseID
+	}
+	if err := s.store.UpdateInstallationGroupActiveRelease(installationGroup.ID, work.Release.ID, previousReleaseID); err != nil {
+		logger.WithError(err).Warn("failed to record installation group active release")
+	}
+}
+
+// applyFailurePolicy reacts to the failed release or soak of the given
+// installation group following the failure policy of its ring. Installation
+// groups outside of any ring follow FailurePolicyHalt.
+func (s *InstallationGroupSupervisor) applyFailurePolicy(installationGroup *model.InstallationGroup, ring *model.Ring, logger log.FieldLogger) {
+	policy := model.FailurePolicyHalt
+	if ring != nil {
+		policy = ring.CurrentFailurePolicy()
+	}
+	logger = logger.WithField("failurePolicy", policy)
+
+	switch policy {
+	case model.FailurePolicyContinue:
+		logger.Info("Installation group release has failed, continuing with the other installation groups of the ring")
+		return
+	case model.FailurePolicyRollbackInstallationGroup:
+		logger.Info("Installation group release has failed, rolling back the installation group")
+		oldState := installationGroup.State
+		installationGroup.State = model.InstallationGroupReleaseRollbackRequested
+		if err := s.store.UpdateInstallationGroup(installationGroup); err != nil {
+			logger.WithError(err).Errorf("failed to set installation group state to %s", installationGroup.State)
+			return
+		}
+		s.recordStateChange(installationGroup, ring, oldState, installationGroup.State, nil, logger)
+		s.sendWebhook(installationGroup, ring, oldState, installationGroup.State, logger)
+		return
+	}
+
+	// Move rings to release-failed as soon as an IG release fails, leaving
+	// the rings of the other kind be: rehearsal rings only fail rehearsal
+	// rings.
+	logger.Info("Installation group release has failed, moving rings pending work to failed state")
+	rings, err := s.store.GetRingsPendingWork()
+	if err != nil {
+		logger.WithError(err).Error("failed to get all rings pending work")
+		return
+	}
+	rings = model.RehearsalRings(rings, ring != nil && ring.Rehearsal)
+	oldStates := make(map[string]string, len(rings))
+	for _, pendingRing := range rings {
+		oldStates[pendingRing.ID] = pendingRing.State
+		pendingRing.State = model.RingStateReleaseFailed
+		if policy == model.FailurePolicyRollbackRing && pendingRing.ID == ring.ID {
+			logger.Infof("Rolling back ring %s", ring.ID)
+			pendingRing.State = model.RingStateReleaseRollbackRequested
+		}
+	}
+
+	if err = s.store.UpdateRings(rings); err != nil {
+		logger.WithError(err).Error("failed to move rings to failed state")
+		return
+	}
+
+	for _, pendingRing := range rings {
+		event := model.NewStateChangeEvent(model.TypeRing, pendingRing.ID, pendingRing, oldStates[pendingRing.ID], pendingRing.State)
+		event.Error = fmt.Sprintf("installation group %s release ended in state %s", installationGroup.ID, installationGroup.State)
+		if err = s.store.CreateStateChangeEvent(event); err != nil {
+			logger.WithError(err).Warn("failed to record ring state change event")
+		}
+	}
+}
+
+// recordStateChange records the state change event of the installation group
+// against its ring, along with the limits the transition bypassed, if any. The
+// event history is informational, so failures are only logged.
+func (s *InstallationGroupSupervisor) recordStateChange(installationGroup *model.InstallationGroup, ring *model.Ring, oldState, newState string, bypassed []string, logger log.FieldLogger) {
+	if ring == nil {
+		logger.Warn("the installation group is not registered to any ring; not recording its state change event")
+		return
+	}
+
+	event := model.NewStateChangeEvent(model.TypeInstallationGroup, installationGroup.ID, ring, oldState, newState)
+	event.Metadata = installationGroup.Metadata
+	event.Bypassed = strings.Join(bypassed, ",")
+	if err := s.store.CreateStateChangeEvent(event); err != nil {
+		logger.WithError(err).Warn("failed to record installation group state change event")
+	}
 }
 
 // Do works with the given ring to transition it to a final state.
-func (s *InstallationGroupSupervisor) transitionInstallationGroup(installationGroup *model.InstallationGroup, logger log.FieldLogger) string {
+func (s *InstallationGroupSupervisor) transitionInstallationGroup(work *model.InstallationGroupWork, logger log.FieldLogger) string {
+	installationGroup := work.InstallationGroup
 	switch installationGroup.State {
 	case model.InstallationGroupReleasePending:
-		return s.checkInstallationGroupPending(installationGroup, logger)
+		return s.checkInstallationGroupPending(work, logger)
 	case model.InstallationGroupReleaseRequested:
-		return s.releaseInstallationGroup(installationGroup, logger)
+		return s.releaseInstallationGroup(work, logger)
 	case model.InstallationGroupReleaseSoakingRequested:
-		return s.soakInstallationGroup(installationGroup, logger)
+		return s.soakInstallationGroup(work, logger)
+	case model.InstallationGroupBlueGreenVerifyRequested:
+		return s.verifyGreenInstallationGroup(work, logger)
+	case model.InstallationGroupBlueGreenSwitchRequested:
+		return s.switchInstallationGroup(work, logger)
+	case model.InstallationGroupBlueGreenTeardownRequested:
+		return s.tearDownBlueInstallationGroup(work, logger)
+	case model.InstallationGroupReleaseVerificationRequested:
+		return s.verifyInstallationGroupRelease(work, logger)
+	case model.InstallationGroupReleaseRollbackRequested:
+		return s.rollbackInstallationGroup(work, logger)
 	default:
 		logger.Warnf("Found installation group pending work in unexpected state %s", installationGroup.State)
 		return installationGroup.State
 	}
 }
 
-func (s *InstallationGroupSupervisor) checkInstallationGroupPending(installationGroup *model.InstallationGroup, logger log.FieldLogger) string {
-	logger.Debugf("Checking if installation group %s ring is in state to move forward with installation group releases...", installationGroup.ID)
-	ring, err := s.store.GetRingFromInstallationGroupID(installationGroup.ID)
-	if err != nil {
-		logger.WithError(err).Error("Failed to query for the ring of the installation group")
+func (s *InstallationGroupSupervisor) checkInstallationGroupPending(work *model.InstallationGroupWork, logger log.FieldLogger) string {
+	logger.Debugf("Checking if installation group %s ring is in state to move forward with installation group releases...", work.InstallationGroup.ID)
+	ring := work.Ring
+	if ring == nil {
+		logger.Error("The installation group is not registered to any ring")
 		return model.InstallationGroupReleaseFailed
 	}
 
-	if ring.State == model.RingStateReleaseFailed {
+	switch ring.State {
+	case model.RingStateReleaseFailed:
 		return model.InstallationGroupReleaseFailed
+	case model.RingStateReleaseRollbackRequested, model.RingStateReleaseRollbackComplete, model.RingStateReleaseRollbackFailed:
+		// The installation group still runs the release the ring is rolled
+		// back to.
+		logger.Info("The ring is rolling back; cancelling the installation group release")
+		return model.InstallationGroupStable
 	}
 
 	if ring.State != model.RingStateReleaseRequested && ring.State != model.RingStateReleaseInProgress {
@@ -204,49 +528,386 @@ func (s *InstallationGroupSupervisor) checkInstallationGroupPending(installation
 		return model.InstallationGroupReleaseFailed
 	}
 
-	//The total installation groups locked at this time will be at least 1
-	if len(installationGroupsLocked) > 1 || len(installationGroupsReleaseInProgress) > 0 {
-		logger.Debug("Another installation group is under lock and being updated...")
+	rings, err := s.store.GetRings(&model.RingFilter{PerPage: model.AllPerPage})
+	if err != nil {
+		logger.WithError(err).Error("Failed to query for rings")
+		return model.InstallationGroupReleaseFailed
+	}
+
+	ringInstallationGroups, err := s.store.GetInstallationGroupsForRing(ring.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to query for the installation groups of the ring")
+		return model.InstallationGroupReleaseFailed
+	}
+
+	installationGroupsByRing, err := s.store.GetInstallationGroupsForRings(&model.RingFilter{PerPage: model.AllPerPage})
+	if err != nil {
+		logger.WithError(err).Error("Failed to query for the installation groups of the rings")
+		return model.InstallationGroupReleaseFailed
+	}
+	installationGroupsLocked = model.RehearsalInstallationGroups(installationGroupsLocked, ring.Rehearsal, rings, installationGroupsByRing)
+	installationGroupsReleaseInProgress = model.RehearsalInstallationGroups(installationGroupsReleaseInProgress, ring.Rehearsal, rings, installationGroupsByRing)
+
+	blockers := model.InstallationGroupReleaseBlockers(work.InstallationGroup, ring, rings, ringInstallationGroups, installationGroupsLocked, installationGroupsReleaseInProgress, time.Now())
+	if len(blockers) > 0 {
+		for _, blocker := range blockers {
+			logger.Debugf("Installation group release blocked: %s", blocker.Message)
+		}
+		return model.InstallationGroupReleasePending
+	}
+
+	if !s.checkInstallationLoad(work, logger) {
 		return model.InstallationGroupReleasePending
 	}
 
 	return model.InstallationGroupReleaseRequested
 }
 
-func (s *InstallationGroupSupervisor) releaseInstallationGroup(installationGroup *model.InstallationGroup, logger log.FieldLogger) string {
-	ring, err := s.store.GetRingFromInstallationGroupID(installationGroup.ID)
-	if err != nil {
-		logger.WithError(err).Error("Failed to get the ring from the installation group pending work")
+// checkInstallationLoad returns whether the release of the installation group
+// of the given work can start as far as the load of its installations is
+// concerned. The release is deferred while any installation has more active
+// users than the release load threshold of the installation group, or while
+// the load is unknown, for the release load max wait at most.
+func (s *InstallationGroupSupervisor) checkInstallationLoad(work *model.InstallationGroupWork, logger log.FieldLogger) bool {
+	installationGroup := work.InstallationGroup
+	if installationGroup.ReleaseLoadThreshold == 0 {
+		return true
+	}
+
+	now := time.Now()
+	// A deferral older than the ring release was left over by a previous
+	// release.
+	deferredAt := installationGroup.LoadDeferredAt
+	if deferredAt == 0 || (work.Ring != nil && deferredAt < work.Ring.ReleaseStartAt) {
+		deferredAt = now.UnixNano()
+	}
+	waited := now.Sub(time.Unix(0, deferredAt))
+	maxWait := installationGroup.CurrentReleaseLoadMaxWait()
+
+	load, err := s.provisionerFor(work).GetInstallationGroupLoad(annotatedInstallationGroup(work))
+	switch {
+	case err != nil:
+		logger.WithError(err).Warn("Failed to get the load of the installation group installations")
+	case load == nil:
+		logger.Warn("No installation load source is configured; not checking the load of the installation group installations")
+		return true
+	case load.PeakActiveUsers() <= installationGroup.ReleaseLoadThreshold:
+		logger.Debugf("Installation group load of %d active users is within the release load threshold of %d", load.PeakActiveUsers(), installationGroup.ReleaseLoadThreshold)
+		return true
+	default:
+		logger.Infof("Installation group load of %d active users and %d connections is above the release load threshold of %d", load.PeakActiveUsers(), load.Connections(), installationGroup.ReleaseLoadThreshold)
+	}
+
+	if waited >= maxWait {
+		logger.Warnf("Installation group release was deferred by load for %s; releasing anyway", waited.Round(time.Second))
+		return true
+	}
+
+	if deferredAt != installationGroup.LoadDeferredAt {
+		if err = s.store.UpdateInstallationGroupLoadDeferredAt(installationGroup.ID, deferredAt); err != nil {
+			logger.WithError(err).Warn("Failed to record the installation group load deferral")
+		}
+	}
+	logger.Infof("Deferring installation group release for another %s at most", (maxWait - waited).Round(time.Second))
+
+	return false
+}
+
+func (s *InstallationGroupSupervisor) releaseInstallationGroup(work *model.InstallationGroupWork, logger log.FieldLogger) string {
+	installationGroup, ring, release := work.InstallationGroup, work.Ring, work.Release
+	if ring == nil {
+		logger.Error("The installation group is not registered to any ring")
+		return model.InstallationGroupReleaseFailed
+	}
+	if release == nil {
+		logger.Errorf("The ring release %s for the installation group pending work does not exist", ring.DesiredReleaseID)
 		return model.InstallationGroupReleaseFailed
 	}
 
-	release, err := s.store.GetRingRelease(ring.DesiredReleaseID)
-	if err != nil {
-		logger.WithError(err).Error("Failed to get the ring release for the installation group pending work")
+	s.recordHealthSnapshot(work, model.HealthSnapshotPreRelease, logger)
+
+	if installationGroup.DatabaseSnapshot && !s.snapshotDatabases(work, logger) {
 		return model.InstallationGroupReleaseFailed
 	}
 
-	err = s.provisioner.ReleaseInstallationGroup(installationGroup, release.Image, release.Version)
+	if installationGroup.CurrentReleaseStrategy() == model.ReleaseStrategyBlueGreen {
+		return s.standUpGreenInstallationGroup(work, logger)
+	}
+
+	err := s.provisionerFor(work).ReleaseInstallationGroup(annotatedInstallationGroup(work), release.Image, release.Version, release.Parameters[installationGroup.Name])
 	if err != nil {
 		logger.WithError(err).Error("Failed to release installation group")
 		return model.InstallationGroupReleaseFailed
 	}
 	logger.Infof("Finished releasing installation group %s", installationGroup.ID)
-	if release.Force {
+
+	return s.completeInstallationGroupRelease(work, logger)
+}
+
+// completeInstallationGroupRelease returns the state of an installation group
+// once its installations run the release: verifying the release when the
+// installation group has a verification URL, and soaking otherwise, or stable
+// right away for forced releases.
+func (s *InstallationGroupSupervisor) completeInstallationGroupRelease(work *model.InstallationGroupWork, logger log.FieldLogger) string {
+	if work.InstallationGroup.VerificationURL != "" {
+		return model.InstallationGroupReleaseVerificationRequested
+	}
+
+	return s.soakInstallationGroupRelease(work, logger)
+}
+
+// soakInstallationGroupRelease returns the state of an installation group
+// whose release is complete and verified: soaking, or stable right away for
+// forced releases, of the ring or of the installation group alone.
+func (s *InstallationGroupSupervisor) soakInstallationGroupRelease(work *model.InstallationGroupWork, logger log.FieldLogger) string {
+	if work.Release.Force || work.InstallationGroup.ForceRelease {
 		logger.Info("This is a forced release. Skipping installation group soaking time...")
+		s.recordHealthSnapshot(work, model.HealthSnapshotPostSoak, logger)
 		return model.InstallationGroupStable
 	}
 	return model.InstallationGroupReleaseSoakingRequested
 }
 
-func (s *InstallationGroupSupervisor) soakInstallationGroup(installationGroup *model.InstallationGroup, logger log.FieldLogger) string {
-	timePassed := ((time.Now().UnixNano() - installationGroup.ReleaseAt) / int64(time.Second))
-	if timePassed < int64(installationGroup.SoakTime) {
-		logger.Infof("Installation Group %s will be soaking for another %d seconds...", installationGroup.ID, int64(installationGroup.SoakTime)-timePassed)
+// annotatedInstallationGroup returns a copy of the installation group of the
+// given work forwarding the ring annotations along with the installation
+// group ones, which take precedence.
+func annotatedInstallationGroup(work *model.InstallationGroupWork) *model.InstallationGroup {
+	annotated := *work.InstallationGroup
+	if work.Ring != nil {
+		annotated.Annotations = model.MergeAnnotations(work.Ring.Annotations, work.InstallationGroup.Annotations)
+	}
+
+	return &annotated
+}
+
+// standUpGreenInstallationGroup starts a blue/green release of an
+// installation group by standing up its green provisioner group.
+func (s *InstallationGroupSupervisor) standUpGreenInstallationGroup(work *model.InstallationGroupWork, logger log.FieldLogger) string {
+	installationGroup, release := work.InstallationGroup, work.Release
+
+	greenProvisionerGroupID, err := s.provisionerFor(work).StandUpGreenInstallationGroup(annotatedInstallationGroup(work), release.Image, release.Version, release.Parameters[installationGroup.Name])
+	if err != nil {
+		logger.WithError(err).Error("Failed to stand up green provisioner group")
+		return model.InstallationGroupReleaseFailed
+	}
+	if err = s.store.UpdateInstallationGroupProvisionerGroups(installationGroup.ID, installationGroup.ProvisionerGroupID, greenProvisionerGroupID); err != nil {
+		logger.WithError(err).Error("Failed to record green provisioner group")
+		return model.InstallationGroupReleaseFailed
+	}
+	logger.Infof("Stood up green provisioner group %s", greenProvisionerGroupID)
+
+	return model.InstallationGroupBlueGreenVerifyRequested
+}
+
+func (s *InstallationGroupSupervisor) verifyGreenInstallationGroup(work *model.InstallationGroupWork, logger log.FieldLogger) string {
+	if work.Release == nil {
+		logger.Error("The ring release for the installation group pending work does not exist")
+		return model.InstallationGroupReleaseFailed
+	}
+
+	err := s.provisionerFor(work).VerifyGreenInstallationGroup(annotatedInstallationGroup(work), work.Release.Image, work.Release.Version)
+	if err != nil {
+		logger.WithError(err).Error("Failed to verify green provisioner group")
+		return model.InstallationGroupReleaseFailed
+	}
+	logger.Infof("Verified green provisioner group %s", work.InstallationGroup.GreenProvisionerGroupID)
+
+	return model.InstallationGroupBlueGreenSwitchRequested
+}
+
+func (s *InstallationGroupSupervisor) switchInstallationGroup(work *model.InstallationGroupWork, logger log.FieldLogger) string {
+	err := s.provisionerFor(work).SwitchInstallationGroup(annotatedInstallationGroup(work))
+	if err != nil {
+		logger.WithError(err).Error("Failed to switch installations to green provisioner group")
+		return model.InstallationGroupReleaseFailed
+	}
+	logger.Infof("Switched installations to green provisioner group %s", work.InstallationGroup.GreenProvisionerGroupID)
+
+	return model.InstallationGroupBlueGreenTeardownRequested
+}
+
+func (s *InstallationGroupSupervisor) tearDownBlueInstallationGroup(work *model.InstallationGroupWork, logger log.FieldLogger) string {
+	installationGroup := work.InstallationGroup
+	if work.Release == nil {
+		logger.Error("The ring release for the installation group pending work does not exist")
+		return model.InstallationGroupReleaseFailed
+	}
+
+	err := s.provisionerFor(work).TearDownBlueInstallationGroup(annotatedInstallationGroup(work))
+	if err != nil {
+		logger.WithError(err).Error("Failed to tear down blue provisioner group")
+		return model.InstallationGroupReleaseFailed
+	}
+
+	// The green provisioner group is the group of the installation group
+	// from now on.
+	if err = s.store.UpdateInstallationGroupProvisionerGroups(installationGroup.ID, installationGroup.GreenProvisionerGroupID, ""); err != nil {
+		logger.WithError(err).Error("Failed to record green provisioner group as the installation group provisioner group")
+		return model.InstallationGroupReleaseFailed
+	}
+	logger.Infof("Tore down blue provisioner group %s", installationGroup.ProvisionerGroupID)
+	installationGroup.ProvisionerGroupID, installationGroup.GreenProvisionerGroupID = installationGroup.GreenProvisionerGroupID, ""
+
+	return s.completeInstallationGroupRelease(work, logger)
+}
+
+// verifyInstallationGroupRelease starts the verification job of the release of
+// an installation group, then follows it until it passes, fails or runs out of
+// time. The installation group only soaks once the job passed.
+func (s *InstallationGroupSupervisor) verifyInstallationGroupRelease(work *model.InstallationGroupWork, logger log.FieldLogger) string {
+	installationGroup := work.InstallationGroup
+	if work.Release == nil {
+		logger.Error("The ring release for the installation group pending work does not exist")
+		return model.InstallationGroupReleaseFailed
+	}
+	verifier := s.verifierFor(work)
+	if verifier == nil {
+		logger.Error("Release verification is not enabled on this server")
+		return model.InstallationGroupReleaseFailed
+	}
+
+	if installationGroup.VerificationJobID == "" {
+		job, err := verifier.StartVerification(annotatedInstallationGroup(work), work.Release)
+		if err != nil {
+			logger.WithError(err).Error("Failed to start release verification job")
+			return model.InstallationGroupReleaseFailed
+		}
+		if err = s.store.UpdateInstallationGroupVerification(installationGroup.ID, job, time.Now().UnixNano()); err != nil {
+			logger.WithError(err).Error("Failed to record release verification job")
+			return model.InstallationGroupReleaseFailed
+		}
+		logger.Infof("Started release verification job %s", job.ID)
+
+		return model.InstallationGroupReleaseVerificationRequested
+	}
+
+	// Failures to get the status of the job are retried until it runs out
+	// of time.
+	job, err := verifier.GetVerification(annotatedInstallationGroup(work), installationGroup.VerificationJobID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get release verification job status")
+		job = &model.VerificationJob{ID: installationGroup.VerificationJobID, Status: model.VerificationJobRunning}
+	}
+	job.ID = installationGroup.VerificationJobID
+	if job.OutputURL == "" {
+		job.OutputURL = installationGroup.VerificationOutputURL
+	}
+
+	elapsed := time.Since(time.Unix(0, installationGroup.VerificationStartAt))
+	if job.Status == model.VerificationJobRunning && elapsed >= installationGroup.CurrentVerificationTimeout() {
+		job.Status = model.VerificationJobFailed
+		job.Message = fmt.Sprintf("timed out after %s", elapsed.Round(time.Second))
+	}
+	if job.Status != installationGroup.VerificationStatus || job.OutputURL != installationGroup.VerificationOutputURL {
+		if err = s.store.UpdateInstallationGroupVerification(installationGroup.ID, job, installationGroup.VerificationStartAt); err != nil {
+			logger.WithError(err).Warn("Failed to record release verification job status")
+		}
+	}
+
+	switch job.Status {
+	case model.VerificationJobPassed:
+		logger.Infof("Release verification job %s passed", job.ID)
+		return s.soakInstallationGroupRelease(work, logger)
+	case model.VerificationJobFailed:
+		logger.Errorf("Release verification job %s failed: %s", job.ID, job.Message)
+		return model.InstallationGroupReleaseFailed
+	}
+	logger.Debugf("Release verification job %s is still running", job.ID)
+
+	return model.InstallationGroupReleaseVerificationRequested
+}
+
+// rollbackInstallationGroup rolls an installation group whose release failed,
+// or whose ring is rolling back, back to the release it ran before. See
+// InstallationGroup.RollbackReleaseID. Blue/green releases roll back the blue
+// provisioner group.
+func (s *InstallationGroupSupervisor) rollbackInstallationGroup(work *model.InstallationGroupWork, logger log.FieldLogger) string {
+	installationGroup, ring := work.InstallationGroup, work.Ring
+	if ring == nil {
+		logger.Error("The installation group is not registered to any ring")
+		return model.InstallationGroupReleaseRollbackFailed
+	}
+	releaseID := installationGroup.RollbackReleaseID(ring)
+	if releaseID == "" {
+		logger.Error("The installation group has no previous release to roll back to")
+		return model.InstallationGroupReleaseRollbackFailed
+	}
+
+	release, err := s.store.GetRingRelease(releaseID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the release to roll back to")
+		return model.InstallationGroupReleaseRollbackFailed
+	}
+	if release == nil {
+		logger.Errorf("The release %s to roll back to does not exist", releaseID)
+		return model.InstallationGroupReleaseRollbackFailed
+	}
+
+	s.logDatabaseSnapshots(work, logger)
+
+	err = s.provisionerFor(work).RollbackInstallationGroup(annotatedInstallationGroup(work), release.Image, release.Version)
+	if err != nil {
+		logger.WithError(err).Error("Failed to roll back installation group")
+		return model.InstallationGroupReleaseRollbackFailed
+	}
+	logger.Infof("Rolled back installation group %s to %s:%s", installationGroup.ID, release.Image, release.Version)
+
+	if err = s.store.UpdateInstallationGroupActiveRelease(installationGroup.ID, release.ID, ""); err != nil {
+		logger.WithError(err).Warn("Failed to record installation group active release")
+	}
+
+	return model.InstallationGroupReleaseRollbackComplete
+}
+
+func (s *InstallationGroupSupervisor) soakInstallationGroup(work *model.InstallationGroupWork, logger log.FieldLogger) string {
+	installationGroup := work.InstallationGroup
+	var soakWindows model.TimeWindows
+	if work.Ring != nil {
+		soakWindows = work.Ring.SoakWindows
+	}
+	soakElapsed := soakWindows.ActiveDuration(time.Unix(0, installationGroup.ReleaseAt), time.Now())
+	if work.Ring != nil {
+		soakElapsed = work.Ring.SoakElapsed(soakElapsed)
+	}
+	timePassed := int64(soakElapsed / time.Second)
+	soakTimeElapsed := timePassed >= int64(installationGroup.CurrentSoakTime())
+
+	// The soak checks must hold for the whole soak, so a breach fails it
+	// right away, while the soak only completes once they all pass.
+	checksPassed, breached := s.evaluateSoakChecks(work, soakTimeElapsed, logger)
+	if breached {
+		s.recordHealthSnapshot(work, model.HealthSnapshotPostSoak, logger)
+		logger.Error("Installation group breached its soak checks")
+		return model.InstallationGroupReleaseSoakingFailed
+	}
+	if !soakTimeElapsed {
+		logger.Infof("Installation Group %s will be soaking for another %d seconds...", installationGroup.ID, int64(installationGroup.CurrentSoakTime())-timePassed)
+		return model.InstallationGroupReleaseSoakingRequested
+	}
+	if !checksPassed {
+		logger.Info("Installation group will be soaking until its soak checks can be evaluated...")
 		return model.InstallationGroupReleaseSoakingRequested
 	}
+	s.recordSoakCheck(work, &model.SoakCheckResult{
+		Name:          model.SoakCheckSoakTime,
+		ObservedValue: float64(timePassed),
+		Threshold:     float64(installationGroup.CurrentSoakTime()),
+		Passed:        true,
+	}, logger)
 
-	err := s.provisioner.SoakInstallationGroup(installationGroup)
+	err := s.provisionerFor(work).SoakInstallationGroup(installationGroup)
+	provisionerCheck := &model.SoakCheckResult{
+		Name:      model.SoakCheckProvisioner,
+		Threshold: 1,
+		Passed:    err == nil,
+	}
+	if err != nil {
+		provisionerCheck.Message = err.Error()
+	} else {
+		provisionerCheck.ObservedValue = 1
+	}
+	s.recordSoakCheck(work, provisionerCheck, logger)
+	s.recordHealthSnapshot(work, model.HealthSnapshotPostSoak, logger)
 	if err != nil {
 		logger.WithError(err).Error("Failed to soak ring")
 		return model.InstallationGroupReleaseSoakingFailed
@@ -255,3 +916,130 @@ func (s *InstallationGroupSupervisor) soakInstallationGroup(installationGroup *m
 	logger.Info("Finished soaking installation group")
 	return model.InstallationGroupStable
 }
+
+// evaluateSoakChecks evaluates the soak checks of the installation group,
+// returning whether they all passed and whether any of them was breached. The
+// breaches are recorded, and so are the passing checks once the soak time
+// elapsed and they all passed. Checks failing to be evaluated neither pass
+// nor breach, so that the soak waits for them.
+func (s *InstallationGroupSupervisor) evaluateSoakChecks(work *model.InstallationGroupWork, soakTimeElapsed bool, logger log.FieldLogger) (bool, bool) {
+	passed := true
+	var results []*model.SoakCheckResult
+	for _, check := range work.InstallationGroup.SoakChecks {
+		result := &model.SoakCheckResult{
+			Name:      check.Name,
+			Query:     check.Query,
+			Threshold: check.Threshold,
+		}
+		if s.soakChecker == nil {
+			result.Message = "soak checks are not enabled on this server"
+			s.recordSoakCheck(work, result, logger)
+			return false, true
+		}
+
+		value, err := s.soakChecker.Evaluate(check)
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to evaluate soak check %s", check.Name)
+			passed = false
+			continue
+		}
+		result.ObservedValue = value
+		result.Passed = check.Passes(value)
+		if !result.Passed {
+			result.Message = fmt.Sprintf("observed %g, expected %s %g", value, check.Comparison, check.Threshold)
+			s.recordSoakCheck(work, result, logger)
+			return false, true
+		}
+		results = append(results, result)
+	}
+
+	if passed && soakTimeElapsed {
+		for _, result := range results {
+			s.recordSoakCheck(work, result, logger)
+		}
+	}
+
+	return passed, false
+}
+
+// recordSoakCheck records the result of a soak check of the installation
+// group against its ring and desired release. Like the state change events,
+// the results are informational, so failures are only logged.
+func (s *InstallationGroupSupervisor) recordSoakCheck(work *model.InstallationGroupWork, result *model.SoakCheckResult, logger log.FieldLogger) {
+	result.InstallationGroupID = work.InstallationGroup.ID
+	if work.Ring != nil {
+		result.RingID = work.Ring.ID
+		result.ReleaseID = work.Ring.DesiredReleaseID
+	}
+
+	if err := s.store.CreateSoakCheckResult(result); err != nil {
+		logger.WithError(err).Warnf("failed to record %s soak check result", result.Name)
+	}
+}
+
+// recordHealthSnapshot records the health of the installation group reported
+// by the provisioner at the given stage of its desired release. The snapshots
+// are evidence for the release report and never affect the release, so
+// failures are recorded in the snapshot or only logged.
+func (s *InstallationGroupSupervisor) recordHealthSnapshot(work *model.InstallationGroupWork, stage string, logger log.FieldLogger) {
+	snapshot, err := s.provisionerFor(work).GetInstallationGroupHealth(work.InstallationGroup)
+	if err != nil {
+		logger.WithError(err).Warnf("Failed to get the %s health of the installation group", stage)
+		snapshot = &model.HealthSnapshot{Error: err.Error()}
+	}
+	snapshot.InstallationGroupID = work.InstallationGroup.ID
+	snapshot.Stage = stage
+	if work.Ring != nil {
+		snapshot.RingID = work.Ring.ID
+		snapshot.ReleaseID = work.Ring.DesiredReleaseID
+	}
+
+	if err = s.store.CreateHealthSnapshot(snapshot); err != nil {
+		logger.WithError(err).Warnf("failed to record %s health snapshot", stage)
+	}
+}
+
+// snapshotDatabases snapshots the databases of the installations of the
+// installation group of the given work before its release, recording the
+// snapshots on the release, and returns whether it succeeded. The release
+// must not start without its restore points.
+func (s *InstallationGroupSupervisor) snapshotDatabases(work *model.InstallationGroupWork, logger log.FieldLogger) bool {
+	snapshots, err := s.provisionerFor(work).SnapshotInstallationGroupDatabases(annotatedInstallationGroup(work), work.Release)
+	if err != nil {
+		logger.WithError(err).Error("Failed to snapshot the databases of the installation group")
+		return false
+	}
+	for _, snapshot := range snapshots {
+		snapshot.RingID = work.Ring.ID
+	}
+
+	if err = s.store.CreateDatabaseSnapshots(snapshots); err != nil {
+		logger.WithError(err).Error("Failed to record the database snapshots of the installation group")
+		return false
+	}
+	logger.Infof("Snapshotted the databases of %d installations before the release", len(snapshots))
+
+	return true
+}
+
+// logDatabaseSnapshots logs the database snapshots taken before the release
+// of the installation group of the given work is rolled back, as restoring
+// them is left to the operators.
+func (s *InstallationGroupSupervisor) logDatabaseSnapshots(work *model.InstallationGroupWork, logger log.FieldLogger) {
+	if work.Ring.DesiredReleaseID == "" {
+		return
+	}
+
+	snapshots, err := s.store.GetDatabaseSnapshots(&model.DatabaseSnapshotFilter{
+		ReleaseID:           work.Ring.DesiredReleaseID,
+		InstallationGroupID: work.InstallationGroup.ID,
+		PerPage:             model.AllPerPage,
+	})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get the database snapshots of the rolled back release")
+		return
+	}
+	for _, snapshot := range snapshots {
+		logger.Infof("Database of installation %s was snapshotted as %s (%s) before the rolled back release", snapshot.InstallationID, snapshot.SnapshotID, snapshot.Source)
+	}
+}
@@ -0,0 +1,838 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type mockInstallationGroupProvisioner struct {
+	FailVersion   string
+	Released      []string
+	Load          *model.InstallationGroupLoad
+	SnapshotError error
+}
+
+func (p *mockInstallationGroupProvisioner) ReleaseInstallationGroup(installationGroup *model.InstallationGroup, image, version string, parameters *model.InstallationGroupReleaseParameters) error {
+	p.Released = append(p.Released, version)
+	if version == p.FailVersion {
+		return errors.New("release failed")
+	}
+	return nil
+}
+
+func (p *mockInstallationGroupProvisioner) RollbackInstallationGroup(installationGroup *model.InstallationGroup, image, version string) error {
+	p.Released = append(p.Released, version)
+	return nil
+}
+
+func (p *mockInstallationGroupProvisioner) SoakInstallationGroup(installationGroup *model.InstallationGroup) error {
+	return nil
+}
+
+func (p *mockInstallationGroupProvisioner) GetInstallationGroupHealth(installationGroup *model.InstallationGroup) (*model.HealthSnapshot, error) {
+	return &model.HealthSnapshot{}, nil
+}
+
+func (p *mockInstallationGroupProvisioner) StandUpGreenInstallationGroup(installationGroup *model.InstallationGroup, image, version string, parameters *model.InstallationGroupReleaseParameters) (string, error) {
+	return model.NewID(), nil
+}
+
+func (p *mockInstallationGroupProvisioner) VerifyGreenInstallationGroup(installationGroup *model.InstallationGroup, image, version string) error {
+	return nil
+}
+
+func (p *mockInstallationGroupProvisioner) SwitchInstallationGroup(installationGroup *model.InstallationGroup) error {
+	return nil
+}
+
+func (p *mockInstallationGroupProvisioner) TearDownBlueInstallationGroup(installationGroup *model.InstallationGroup) error {
+	return nil
+}
+
+func (p *mockInstallationGroupProvisioner) GetInstallationGroupLoad(installationGroup *model.InstallationGroup) (*model.InstallationGroupLoad, error) {
+	return p.Load, nil
+}
+
+func (p *mockInstallationGroupProvisioner) SnapshotInstallationGroupDatabases(installationGroup *model.InstallationGroup, release *model.RingRelease) ([]*model.DatabaseSnapshot, error) {
+	if p.SnapshotError != nil {
+		return nil, p.SnapshotError
+	}
+	return []*model.DatabaseSnapshot{{
+		ReleaseID:           release.ID,
+		InstallationGroupID: installationGroup.ID,
+		InstallationID:      "installation1",
+		SnapshotID:          "backup1",
+		Source:              model.DatabaseSnapshotSourceProvisioner,
+	}}, nil
+}
+
+func TestInstallationGroupSupervisorFailurePolicy(t *testing.T) {
+	setup := func(t *testing.T, sqlStore *store.SQLStore, failurePolicy string) (*model.Ring, *model.InstallationGroup, *model.Ring) {
+		active, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.0.0"})
+		require.NoError(t, err)
+		desired, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"})
+		require.NoError(t, err)
+
+		ring := &model.Ring{
+			Priority:         1,
+			State:            model.RingStateReleaseInProgress,
+			ActiveReleaseID:  active.ID,
+			DesiredReleaseID: desired.ID,
+			FailurePolicy:    failurePolicy,
+		}
+		installationGroup := &model.InstallationGroup{
+			Name:  "group1",
+			State: model.InstallationGroupReleaseRequested,
+		}
+		require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+
+		otherRing := &model.Ring{Priority: 2, State: model.RingStateReleasePending}
+		require.NoError(t, sqlStore.CreateRing(otherRing, nil))
+
+		return ring, installationGroup, otherRing
+	}
+
+	testCases := []struct {
+		FailurePolicy             string
+		ExpectedRingState         string
+		ExpectedOtherRingState    string
+		ExpectedInstallationGroup string
+		ExpectedReleasedVersions  []string
+	}{
+		{"", model.RingStateReleaseFailed, model.RingStateReleaseFailed, model.InstallationGroupReleaseFailed, []string{"7.1.0"}},
+		{model.FailurePolicyHalt, model.RingStateReleaseFailed, model.RingStateReleaseFailed, model.InstallationGroupReleaseFailed, []string{"7.1.0"}},
+		{model.FailurePolicyRollbackRing, model.RingStateReleaseRollbackRequested, model.RingStateReleaseFailed, model.InstallationGroupReleaseFailed, []string{"7.1.0"}},
+		{model.FailurePolicyRollbackInstallationGroup, model.RingStateReleaseInProgress, model.RingStateReleasePending, model.InstallationGroupReleaseRollbackComplete, []string{"7.1.0", "7.0.0"}},
+		{model.FailurePolicyContinue, model.RingStateReleaseInProgress, model.RingStateReleasePending, model.InstallationGroupReleaseFailed, []string{"7.1.0"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run("policy "+tc.FailurePolicy, func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			defer store.CloseConnection(t, sqlStore)
+
+			ring, installationGroup, otherRing := setup(t, sqlStore, tc.FailurePolicy)
+			provisioner := &mockInstallationGroupProvisioner{FailVersion: "7.1.0"}
+			installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, provisioner, "instanceID", logger, nil)
+
+			installationGroupSupervisor.Supervise(installationGroup)
+			installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+			require.NoError(t, err)
+			if installationGroup.State == model.InstallationGroupReleaseRollbackRequested {
+				installationGroupSupervisor.Supervise(installationGroup)
+			}
+
+			installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup.ID)
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedInstallationGroup, installationGroup.State)
+			require.Equal(t, tc.ExpectedReleasedVersions, provisioner.Released)
+
+			ring, err = sqlStore.GetRing(ring.ID)
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedRingState, ring.State)
+
+			otherRing, err = sqlStore.GetRing(otherRing.ID)
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedOtherRingState, otherRing.State)
+
+			if tc.ExpectedRingState != model.RingStateReleaseInProgress {
+				events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{
+					RingID:       ring.ID,
+					ResourceType: model.TypeRing,
+					State:        tc.ExpectedRingState,
+					PerPage:      model.AllPerPage,
+				})
+				require.NoError(t, err)
+				require.Len(t, events, 1)
+				require.Contains(t, events[0].Error, installationGroup.ID)
+			}
+		})
+	}
+}
+
+func TestInstallationGroupSupervisorDoRefreshesRing(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	desired, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"})
+	require.NoError(t, err)
+	ring := &model.Ring{
+		Priority:         1,
+		State:            model.RingStateReleaseInProgress,
+		DesiredReleaseID: desired.ID,
+	}
+	failing := &model.InstallationGroup{Name: "group1", State: model.InstallationGroupReleaseRequested}
+	require.NoError(t, sqlStore.CreateRing(ring, failing))
+	// The failing installation group is worked on first, failing the ring.
+	_, err = sqlStore.SetWorkPriority(model.TypeInstallationGroup, failing.ID, 1)
+	require.NoError(t, err)
+	pending, err := sqlStore.CreateRingInstallationGroup(ring.ID, &model.InstallationGroup{Name: "group2", State: model.InstallationGroupReleasePending})
+	require.NoError(t, err)
+
+	provisioner := &mockInstallationGroupProvisioner{FailVersion: "7.1.0"}
+	installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, provisioner, "instanceID", logger, nil)
+	require.NoError(t, installationGroupSupervisor.Do())
+
+	ring, err = sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.RingStateReleaseFailed, ring.State)
+
+	failing, err = sqlStore.GetInstallationGroupByID(failing.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.InstallationGroupReleaseFailed, failing.State)
+
+	// The pending installation group sees the ring failed, rather than the
+	// release in progress it was locked with.
+	pending, err = sqlStore.GetInstallationGroupByID(pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.InstallationGroupReleaseFailed, pending.State)
+	require.Equal(t, []string{"7.1.0"}, provisioner.Released)
+}
+
+func TestRingSupervisorFailsReleaseWithFailedInstallationGroups(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	ring := &model.Ring{
+		State:         model.RingStateReleaseInProgress,
+		FailurePolicy: model.FailurePolicyContinue,
+	}
+	installationGroup := &model.InstallationGroup{
+		Name:  "group1",
+		State: model.InstallationGroupReleaseFailed,
+	}
+	require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+
+	ringSupervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+	ringSupervisor.Supervise(ring)
+
+	ring, err := sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.RingStateReleaseFailed, ring.State)
+}
+
+func TestRingSupervisorResumesReleaseOfReleasedInstallationGroups(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	desired, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"})
+	require.NoError(t, err)
+
+	ring := &model.Ring{
+		Priority:         1,
+		State:            model.RingStateReleaseFailed,
+		DesiredReleaseID: desired.ID,
+		FailurePolicy:    model.FailurePolicyContinue,
+	}
+	released := &model.InstallationGroup{Name: "group1", State: model.InstallationGroupReleaseFailed}
+	require.NoError(t, sqlStore.CreateRing(ring, released))
+	failed, _, err := sqlStore.RegisterRingInstallationGroup(ring.ID, &model.InstallationGroup{Name: "group2", State: model.InstallationGroupReleaseFailed})
+	require.NoError(t, err)
+
+	// The first installation group is force released on its own, as the API
+	// does, resuming the release of the ring.
+	released.State = model.InstallationGroupReleasePending
+	released.ForceRelease = true
+	require.NoError(t, sqlStore.UpdateInstallationGroup(released))
+	ring.State = model.RingStateReleaseInProgress
+	require.NoError(t, sqlStore.UpdateRing(ring))
+
+	provisioner := &mockInstallationGroupProvisioner{}
+	installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, provisioner, "instanceID", logger, nil)
+	ringSupervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+	installationGroupSupervisor.Supervise(released)
+	released, err = sqlStore.GetInstallationGroupByID(released.ID)
+	require.NoError(t, err)
+	installationGroupSupervisor.Supervise(released)
+	released, err = sqlStore.GetInstallationGroupByID(released.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.InstallationGroupStable, released.State)
+	require.False(t, released.ForceRelease)
+	require.Equal(t, []string{"7.1.0"}, provisioner.Released)
+
+	// The other installation group still failed, so does the ring.
+	ringSupervisor.Supervise(ring)
+	ring, err = sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.RingStateReleaseFailed, ring.State)
+
+	// Once it is released again too, the ring soaks.
+	failed.State = model.InstallationGroupStable
+	require.NoError(t, sqlStore.UpdateInstallationGroup(failed))
+	ring.State = model.RingStateReleaseInProgress
+	require.NoError(t, sqlStore.UpdateRing(ring))
+
+	ringSupervisor.Supervise(ring)
+	ring, err = sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.RingStateSoakingRequested, ring.State)
+}
+
+func TestInstallationGroupSupervisorLoadPreCheck(t *testing.T) {
+	load := func(activeUsers ...int64) *model.InstallationGroupLoad {
+		groupLoad := &model.InstallationGroupLoad{}
+		for _, users := range activeUsers {
+			groupLoad.Installations = append(groupLoad.Installations, &model.InstallationLoad{InstallationID: model.NewID(), ActiveUsers: users})
+		}
+		return groupLoad
+	}
+
+	setup := func(t *testing.T, sqlStore *store.SQLStore, threshold int64) *model.InstallationGroup {
+		ring := &model.Ring{Priority: 1, State: model.RingStateReleaseInProgress}
+		installationGroup := &model.InstallationGroup{
+			Name:                 "group1",
+			State:                model.InstallationGroupReleasePending,
+			ReleaseLoadThreshold: threshold,
+			ReleaseLoadMaxWait:   600,
+		}
+		require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+		return installationGroup
+	}
+
+	testCases := []struct {
+		Description   string
+		Threshold     int64
+		Load          *model.InstallationGroupLoad
+		ExpectedState string
+	}{
+		{"no threshold", 0, load(500), model.InstallationGroupReleaseRequested},
+		{"no load source", 100, nil, model.InstallationGroupReleaseRequested},
+		{"below threshold", 100, load(20, 100), model.InstallationGroupReleaseRequested},
+		{"above threshold", 100, load(20, 500), model.InstallationGroupReleasePending},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Description, func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			defer store.CloseConnection(t, sqlStore)
+
+			installationGroup := setup(t, sqlStore, tc.Threshold)
+			provisioner := &mockInstallationGroupProvisioner{Load: tc.Load}
+			installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, provisioner, "instanceID", logger, nil)
+
+			installationGroupSupervisor.Supervise(installationGroup)
+			installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedState, installationGroup.State)
+			if tc.ExpectedState == model.InstallationGroupReleasePending {
+				require.NotZero(t, installationGroup.LoadDeferredAt)
+			} else {
+				require.Zero(t, installationGroup.LoadDeferredAt)
+			}
+		})
+	}
+
+	t.Run("max wait elapsed", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		defer store.CloseConnection(t, sqlStore)
+
+		installationGroup := setup(t, sqlStore, 100)
+		provisioner := &mockInstallationGroupProvisioner{Load: load(500)}
+		installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, provisioner, "instanceID", logger, nil)
+
+		installationGroupSupervisor.Supervise(installationGroup)
+		installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationGroupReleasePending, installationGroup.State)
+
+		deferredAt := time.Now().Add(-11 * time.Minute).UnixNano()
+		require.NoError(t, sqlStore.UpdateInstallationGroupLoadDeferredAt(installationGroup.ID, deferredAt))
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+
+		installationGroupSupervisor.Supervise(installationGroup)
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationGroupReleaseRequested, installationGroup.State)
+		require.Zero(t, installationGroup.LoadDeferredAt)
+	})
+}
+
+func TestInstallationGroupSupervisorDatabaseSnapshots(t *testing.T) {
+	setup := func(t *testing.T, sqlStore *store.SQLStore, databaseSnapshot bool) (*model.RingRelease, *model.InstallationGroup) {
+		release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"})
+		require.NoError(t, err)
+		ring := &model.Ring{Priority: 1, State: model.RingStateReleaseInProgress, DesiredReleaseID: release.ID}
+		installationGroup := &model.InstallationGroup{
+			Name:             "group1",
+			State:            model.InstallationGroupReleaseRequested,
+			DatabaseSnapshot: databaseSnapshot,
+		}
+		require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+		return release, installationGroup
+	}
+
+	testCases := []struct {
+		Description       string
+		DatabaseSnapshot  bool
+		SnapshotError     error
+		ExpectedState     string
+		ExpectedReleased  []string
+		ExpectedSnapshots int
+	}{
+		{"disabled", false, nil, model.InstallationGroupReleaseSoakingRequested, []string{"7.1.0"}, 0},
+		{"enabled", true, nil, model.InstallationGroupReleaseSoakingRequested, []string{"7.1.0"}, 1},
+		{"failed", true, errors.New("backup failed"), model.InstallationGroupReleaseFailed, nil, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Description, func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			defer store.CloseConnection(t, sqlStore)
+
+			release, installationGroup := setup(t, sqlStore, tc.DatabaseSnapshot)
+			provisioner := &mockInstallationGroupProvisioner{SnapshotError: tc.SnapshotError}
+			installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, provisioner, "instanceID", logger, nil)
+
+			installationGroupSupervisor.Supervise(installationGroup)
+			installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedState, installationGroup.State)
+			require.Equal(t, tc.ExpectedReleased, provisioner.Released)
+
+			snapshots, err := sqlStore.GetDatabaseSnapshots(&model.DatabaseSnapshotFilter{ReleaseID: release.ID, PerPage: model.AllPerPage})
+			require.NoError(t, err)
+			require.Len(t, snapshots, tc.ExpectedSnapshots)
+			for _, snapshot := range snapshots {
+				require.Equal(t, installationGroup.ID, snapshot.InstallationGroupID)
+				require.NotEmpty(t, snapshot.RingID)
+				require.Equal(t, "backup1", snapshot.SnapshotID)
+			}
+		})
+	}
+}
+
+func TestInstallationGroupSupervisorPausedRing(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	ring := &model.Ring{Priority: 1, State: model.RingStateReleaseInProgress}
+	installationGroup := &model.InstallationGroup{Name: "group1", State: model.InstallationGroupReleasePending}
+	require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+	require.True(t, ring.PauseRelease(time.Now().UnixNano()))
+	require.NoError(t, sqlStore.UpdateRing(ring))
+
+	installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, &mockInstallationGroupProvisioner{}, "instanceID", logger, nil)
+
+	installationGroupSupervisor.Supervise(installationGroup)
+	actualInstallationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.InstallationGroupReleasePending, actualInstallationGroup.State)
+
+	_, resumed := ring.ResumeRelease(time.Now().UnixNano())
+	require.True(t, resumed)
+	require.NoError(t, sqlStore.UpdateRing(ring))
+
+	installationGroupSupervisor.Supervise(installationGroup)
+	actualInstallationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.InstallationGroupReleaseRequested, actualInstallationGroup.State)
+}
+
+func TestInstallationGroupSupervisorRollbackBypassesPausedRing(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	active, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.0.0"})
+	require.NoError(t, err)
+	desired, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"})
+	require.NoError(t, err)
+	ring := &model.Ring{
+		Priority:         1,
+		State:            model.RingStateReleaseInProgress,
+		ActiveReleaseID:  active.ID,
+		DesiredReleaseID: desired.ID,
+	}
+	installationGroup := &model.InstallationGroup{Name: "group1", State: model.InstallationGroupReleaseRollbackRequested}
+	require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+	require.True(t, ring.PauseRelease(time.Now().UnixNano()))
+	require.NoError(t, sqlStore.UpdateRing(ring))
+
+	provisioner := &mockInstallationGroupProvisioner{}
+	installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, provisioner, "instanceID", logger, nil)
+	require.NoError(t, installationGroupSupervisor.Do())
+
+	installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.InstallationGroupReleaseRollbackComplete, installationGroup.State)
+	require.Equal(t, []string{"7.0.0"}, provisioner.Released)
+
+	events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{
+		RingID:       ring.ID,
+		ResourceType: model.TypeInstallationGroup,
+		PerPage:      model.AllPerPage,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, model.BypassReleasePaused, events[0].Bypassed)
+}
+
+func TestInstallationGroupSupervisorReleaseWindows(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	now := time.Now().UTC()
+	closedWindow := model.TimeWindow{Start: now.Add(2 * time.Hour).Format("15:04"), End: now.Add(3 * time.Hour).Format("15:04")}
+
+	ring := &model.Ring{Priority: 1, State: model.RingStateReleaseInProgress}
+	installationGroup := &model.InstallationGroup{Name: "group1", State: model.InstallationGroupReleasePending, ReleaseWindows: model.TimeWindows{closedWindow}}
+	require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+	otherInstallationGroup := &model.InstallationGroup{Name: "group2", State: model.InstallationGroupReleasePending}
+	_, err := sqlStore.CreateRingInstallationGroup(ring.ID, otherInstallationGroup)
+	require.NoError(t, err)
+
+	installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, &mockInstallationGroupProvisioner{}, "instanceID", logger, nil)
+
+	installationGroupSupervisor.Supervise(installationGroup)
+	actualInstallationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.InstallationGroupReleasePending, actualInstallationGroup.State)
+	require.Equal(t, model.TimeWindows{closedWindow}, actualInstallationGroup.ReleaseWindows)
+
+	installationGroupSupervisor.Supervise(otherInstallationGroup)
+	actualInstallationGroup, err = sqlStore.GetInstallationGroupByID(otherInstallationGroup.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.InstallationGroupReleaseRequested, actualInstallationGroup.State)
+}
+
+func TestAutoRollback(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	active, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.0.0"})
+	require.NoError(t, err)
+	desired, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"})
+	require.NoError(t, err)
+
+	ring := &model.Ring{
+		Priority:         1,
+		State:            model.RingStateReleaseInProgress,
+		ActiveReleaseID:  active.ID,
+		DesiredReleaseID: desired.ID,
+		AutoRollback:     true,
+	}
+	released := &model.InstallationGroup{Name: "released", State: model.InstallationGroupStable}
+	require.NoError(t, sqlStore.CreateRing(ring, released))
+	require.NoError(t, sqlStore.UpdateInstallationGroupActiveRelease(released.ID, desired.ID, active.ID))
+	failing, err := sqlStore.CreateRingInstallationGroup(ring.ID, &model.InstallationGroup{Name: "failing", State: model.InstallationGroupReleaseRequested})
+	require.NoError(t, err)
+	pending, err := sqlStore.CreateRingInstallationGroup(ring.ID, &model.InstallationGroup{Name: "pending", State: model.InstallationGroupReleasePending})
+	require.NoError(t, err)
+
+	provisioner := &mockInstallationGroupProvisioner{FailVersion: "7.1.0"}
+	installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, provisioner, "instanceID", logger, nil)
+	ringSupervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+	requireStates := func(t *testing.T, ringState string, installationGroupStates ...string) {
+		ring, err := sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, ringState, ring.State)
+		for i, installationGroup := range []*model.InstallationGroup{released, failing, pending} {
+			installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup.ID)
+			require.NoError(t, err)
+			require.Equal(t, installationGroupStates[i], installationGroup.State, installationGroup.Name)
+		}
+	}
+	supervise := func(installationGroups ...*model.InstallationGroup) {
+		for _, installationGroup := range installationGroups {
+			installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+			require.NoError(t, err)
+			installationGroupSupervisor.Supervise(installationGroup)
+		}
+	}
+
+	superviseRing := func() {
+		ring, err := sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		ringSupervisor.Supervise(ring)
+	}
+
+	supervise(failing, pending)
+	requireStates(t, model.RingStateReleaseRollbackRequested,
+		model.InstallationGroupStable, model.InstallationGroupReleaseFailed, model.InstallationGroupStable)
+
+	superviseRing()
+	requireStates(t, model.RingStateReleaseRollbackRequested,
+		model.InstallationGroupReleaseRollbackRequested, model.InstallationGroupReleaseRollbackRequested, model.InstallationGroupStable)
+
+	supervise(released, failing)
+	requireStates(t, model.RingStateReleaseRollbackRequested,
+		model.InstallationGroupReleaseRollbackComplete, model.InstallationGroupReleaseRollbackComplete, model.InstallationGroupStable)
+	require.Equal(t, []string{"7.1.0", "7.0.0", "7.0.0"}, provisioner.Released)
+
+	superviseRing()
+	requireStates(t, model.RingStateReleaseRollbackComplete,
+		model.InstallationGroupReleaseRollbackComplete, model.InstallationGroupReleaseRollbackComplete, model.InstallationGroupStable)
+
+	released, err = sqlStore.GetInstallationGroupByID(released.ID)
+	require.NoError(t, err)
+	require.Equal(t, active.ID, released.ActiveReleaseID)
+	require.Empty(t, released.PreviousReleaseID)
+}
+
+type mockSoakChecker struct {
+	Values map[string]float64
+	Err    error
+}
+
+func (c *mockSoakChecker) Evaluate(check *model.SoakCheck) (float64, error) {
+	if c.Err != nil {
+		return 0, c.Err
+	}
+	return c.Values[check.Name], nil
+}
+
+func TestInstallationGroupSupervisorSoakChecks(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	soakChecks := model.SoakChecks{
+		{Name: "error-rate", Query: "sum(rate(errors[5m]))", Comparison: model.SoakCheckBelow, Threshold: 0.01},
+	}
+	newSoakingInstallationGroup := func(t *testing.T, name string, soakedFor time.Duration) *model.InstallationGroup {
+		ring := &model.Ring{Priority: 1, State: model.RingStateSoakingRequested}
+		installationGroup := &model.InstallationGroup{
+			Name:       name,
+			State:      model.InstallationGroupReleaseSoakingRequested,
+			SoakTime:   600,
+			ReleaseAt:  time.Now().Add(-soakedFor).UnixNano(),
+			SoakChecks: soakChecks,
+		}
+		require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+		return installationGroup
+	}
+	supervise := func(t *testing.T, checker supervisor.SoakChecker, installationGroup *model.InstallationGroup) (*model.InstallationGroup, []*model.SoakCheckResult) {
+		installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, &mockInstallationGroupProvisioner{}, "instanceID", logger, nil)
+		if checker != nil {
+			installationGroupSupervisor.SetSoakChecker(checker)
+		}
+		installationGroupSupervisor.Supervise(installationGroup)
+
+		actual, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		results, err := sqlStore.GetSoakCheckResults(&model.SoakCheckResultFilter{InstallationGroupID: installationGroup.ID, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		return actual, results
+	}
+
+	t.Run("checks passing during the soak", func(t *testing.T) {
+		installationGroup := newSoakingInstallationGroup(t, "passing", time.Minute)
+		actual, results := supervise(t, &mockSoakChecker{Values: map[string]float64{"error-rate": 0.001}}, installationGroup)
+		require.Equal(t, model.InstallationGroupReleaseSoakingRequested, actual.State)
+		require.Empty(t, results)
+	})
+
+	t.Run("breach fails the soak right away", func(t *testing.T) {
+		installationGroup := newSoakingInstallationGroup(t, "breaching", time.Minute)
+		actual, results := supervise(t, &mockSoakChecker{Values: map[string]float64{"error-rate": 0.05}}, installationGroup)
+		require.Equal(t, model.InstallationGroupReleaseSoakingFailed, actual.State)
+		require.Len(t, results, 1)
+		require.Equal(t, "error-rate", results[0].Name)
+		require.False(t, results[0].Passed)
+		require.Equal(t, 0.05, results[0].ObservedValue)
+	})
+
+	t.Run("failed evaluation keeps soaking", func(t *testing.T) {
+		installationGroup := newSoakingInstallationGroup(t, "unavailable", time.Hour)
+		actual, results := supervise(t, &mockSoakChecker{Err: errors.New("prometheus unavailable")}, installationGroup)
+		require.Equal(t, model.InstallationGroupReleaseSoakingRequested, actual.State)
+		require.Empty(t, results)
+	})
+
+	t.Run("stable once soaked and checks pass", func(t *testing.T) {
+		installationGroup := newSoakingInstallationGroup(t, "soaked", time.Hour)
+		actual, results := supervise(t, &mockSoakChecker{Values: map[string]float64{"error-rate": 0.001}}, installationGroup)
+		require.Equal(t, model.InstallationGroupStable, actual.State)
+
+		names := []string{}
+		for _, result := range results {
+			require.True(t, result.Passed, result.Name)
+			names = append(names, result.Name)
+		}
+		require.ElementsMatch(t, []string{"error-rate", model.SoakCheckSoakTime, model.SoakCheckProvisioner}, names)
+	})
+
+	t.Run("checks without checker fail the soak", func(t *testing.T) {
+		installationGroup := newSoakingInstallationGroup(t, "unchecked", time.Hour)
+		actual, results := supervise(t, nil, installationGroup)
+		require.Equal(t, model.InstallationGroupReleaseSoakingFailed, actual.State)
+		require.Len(t, results, 1)
+		require.False(t, results[0].Passed)
+	})
+}
+
+type mockReleaseVerifier struct {
+	StartErr error
+	Job      *model.VerificationJob
+	Started  []string
+}
+
+func (v *mockReleaseVerifier) StartVerification(installationGroup *model.InstallationGroup, release *model.RingRelease) (*model.VerificationJob, error) {
+	if v.StartErr != nil {
+		return nil, v.StartErr
+	}
+	v.Started = append(v.Started, release.Version)
+	return &model.VerificationJob{ID: "job1", Status: model.VerificationJobRunning}, nil
+}
+
+func (v *mockReleaseVerifier) GetVerification(installationGroup *model.InstallationGroup, jobID string) (*model.VerificationJob, error) {
+	return v.Job, nil
+}
+
+func TestInstallationGroupSupervisorReleaseVerification(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	desired, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"})
+	require.NoError(t, err)
+
+	newVerifyingInstallationGroup := func(t *testing.T, name string) *model.InstallationGroup {
+		ring := &model.Ring{Priority: 1, State: model.RingStateReleaseInProgress, DesiredReleaseID: desired.ID}
+		installationGroup := &model.InstallationGroup{
+			Name:            name,
+			State:           model.InstallationGroupReleaseVerificationRequested,
+			SoakTime:        600,
+			VerificationURL: "http://verification.example.com/jobs",
+		}
+		require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+		return installationGroup
+	}
+	supervise := func(t *testing.T, verifier supervisor.ReleaseVerifier, installationGroup *model.InstallationGroup) *model.InstallationGroup {
+		installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, &mockInstallationGroupProvisioner{}, "instanceID", logger, nil)
+		if verifier != nil {
+			installationGroupSupervisor.SetReleaseVerifier(verifier)
+		}
+		installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		installationGroupSupervisor.Supervise(installationGroup)
+
+		actual, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		return actual
+	}
+
+	t.Run("job passes before the soak", func(t *testing.T) {
+		installationGroup := newVerifyingInstallationGroup(t, "passing")
+		verifier := &mockReleaseVerifier{Job: &model.VerificationJob{Status: model.VerificationJobRunning}}
+
+		actual := supervise(t, verifier, installationGroup)
+		require.Equal(t, model.InstallationGroupReleaseVerificationRequested, actual.State)
+		require.Equal(t, []string{"7.1.0"}, verifier.Started)
+		require.Equal(t, "job1", actual.VerificationJobID)
+		require.Equal(t, model.VerificationJobRunning, actual.VerificationStatus)
+		require.NotZero(t, actual.VerificationStartAt)
+
+		actual = supervise(t, verifier, installationGroup)
+		require.Equal(t, model.InstallationGroupReleaseVerificationRequested, actual.State)
+
+		verifier.Job = &model.VerificationJob{Status: model.VerificationJobPassed, OutputURL: "http://logs.example.com/job1"}
+		actual = supervise(t, verifier, installationGroup)
+		require.Equal(t, model.InstallationGroupReleaseSoakingRequested, actual.State)
+		require.Equal(t, model.VerificationJobPassed, actual.VerificationStatus)
+		require.Equal(t, "http://logs.example.com/job1", actual.VerificationOutputURL)
+		require.NotZero(t, actual.ReleaseAt)
+		require.Len(t, verifier.Started, 1)
+	})
+
+	t.Run("job fails the release", func(t *testing.T) {
+		installationGroup := newVerifyingInstallationGroup(t, "failing")
+		verifier := &mockReleaseVerifier{Job: &model.VerificationJob{Status: model.VerificationJobFailed, Message: "tests failed"}}
+
+		supervise(t, verifier, installationGroup)
+		actual := supervise(t, verifier, installationGroup)
+		require.Equal(t, model.InstallationGroupReleaseFailed, actual.State)
+		require.Equal(t, model.VerificationJobFailed, actual.VerificationStatus)
+	})
+
+	t.Run("job running past its timeout fails the release", func(t *testing.T) {
+		installationGroup := newVerifyingInstallationGroup(t, "timing-out")
+		verifier := &mockReleaseVerifier{Job: &model.VerificationJob{Status: model.VerificationJobRunning}}
+
+		supervise(t, verifier, installationGroup)
+		job := &model.VerificationJob{ID: "job1", Status: model.VerificationJobRunning}
+		require.NoError(t, sqlStore.UpdateInstallationGroupVerification(installationGroup.ID, job, time.Now().Add(-time.Hour).UnixNano()))
+
+		actual := supervise(t, verifier, installationGroup)
+		require.Equal(t, model.InstallationGroupReleaseFailed, actual.State)
+		require.Equal(t, model.VerificationJobFailed, actual.VerificationStatus)
+	})
+
+	t.Run("job failing to start fails the release", func(t *testing.T) {
+		installationGroup := newVerifyingInstallationGroup(t, "unstarted")
+		actual := supervise(t, &mockReleaseVerifier{StartErr: errors.New("verification unavailable")}, installationGroup)
+		require.Equal(t, model.InstallationGroupReleaseFailed, actual.State)
+		require.Empty(t, actual.VerificationJobID)
+	})
+
+	t.Run("verification without verifier fails the release", func(t *testing.T) {
+		installationGroup := newVerifyingInstallationGroup(t, "unverified")
+		actual := supervise(t, nil, installationGroup)
+		require.Equal(t, model.InstallationGroupReleaseFailed, actual.State)
+	})
+}
+
+func TestInstallationGroupSupervisorRingDependencies(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	canaryEU := &model.Ring{Name: "canary-eu", Priority: 1, State: model.RingStateReleaseInProgress}
+	releasing := &model.InstallationGroup{Name: "canary-eu-1", State: model.InstallationGroupReleaseRequested}
+	require.NoError(t, sqlStore.CreateRing(canaryEU, releasing))
+	concurrent := &model.InstallationGroup{Name: "canary-eu-2", State: model.InstallationGroupReleasePending}
+	_, err := sqlStore.CreateRingInstallationGroup(canaryEU.ID, concurrent)
+	require.NoError(t, err)
+
+	canaryUS := &model.Ring{Name: "canary-us", Priority: 1, State: model.RingStateReleaseInProgress}
+	parallel := &model.InstallationGroup{Name: "canary-us-1", State: model.InstallationGroupReleasePending}
+	require.NoError(t, sqlStore.CreateRing(canaryUS, parallel))
+	for _, ring := range []*model.Ring{canaryEU, canaryUS} {
+		production := &model.Ring{Name: "production-" + ring.Name, Priority: 2, State: model.RingStateStable, DependsOn: model.RingDependencies{ring.ID}}
+		require.NoError(t, sqlStore.CreateRing(production, &model.InstallationGroup{Name: "production-" + ring.Name, State: model.InstallationGroupStable}))
+	}
+
+	installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, &mockInstallationGroupProvisioner{}, "instanceID", logger, nil)
+	requireState := func(t *testing.T, installationGroup *model.InstallationGroup, state string) {
+		installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		installationGroupSupervisor.Supervise(installationGroup)
+
+		actual, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		require.Equal(t, state, actual.State)
+	}
+
+	// The rings are on independent branches of the dependency graph, but
+	// each releases one installation group at a time by default.
+	requireState(t, parallel, model.InstallationGroupReleaseRequested)
+	requireState(t, concurrent, model.InstallationGroupReleasePending)
+
+	canaryEU.MaxConcurrency = 2
+	require.NoError(t, sqlStore.UpdateRing(canaryEU))
+	requireState(t, concurrent, model.InstallationGroupReleaseRequested)
+}
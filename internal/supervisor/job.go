@@ -0,0 +1,204 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattermost/elrond/internal/metrics"
+	"github.com/mattermost/elrond/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// jobStore abstracts the database operations required to run jobs.
+type jobStore interface {
+	GetJob(jobID string) (*model.Job, error)
+	LockJobsPendingWork(lockerID string, limit int) ([]*model.Job, int, error)
+	UnlockJob(jobID, lockerID string, force bool) (bool, error)
+	UpdateJob(job *model.Job) error
+}
+
+// JobRunner runs the jobs of a type, returning a summary of what the job did.
+type JobRunner interface {
+	RunJob(job *model.Job) (string, error)
+}
+
+// JobSupervisor finds pending jobs and runs them with the runner of their
+// type, retrying the failed ones until their attempts run out.
+type JobSupervisor struct {
+	store      jobStore
+	instanceID string
+	logger     log.FieldLogger
+	metrics    *metrics.Metrics
+
+	lockBatchSize int
+	runners       map[string]JobRunner
+}
+
+// NewJobSupervisor creates a new JobSupervisor.
+func NewJobSupervisor(store jobStore, instanceID string, logger log.FieldLogger, metrics *metrics.Metrics) *JobSupervisor {
+	return &JobSupervisor{
+		store:         store,
+		instanceID:    instanceID,
+		logger:        logger,
+		metrics:       metrics,
+		lockBatchSize: DefaultLockBatchSize,
+		runners:       make(map[string]JobRunner),
+	}
+}
+
+// SetLockBatchSize changes the number of pending jobs locked at once on each
+// run.
+func (s *JobSupervisor) SetLockBatchSize(lockBatchSize int) {
+	s.lockBatchSize = lockBatchSize
+}
+
+// SetJobRunner sets the runner of the jobs of the given type. Jobs of a type
+// without a runner fail their attempts.
+func (s *JobSupervisor) SetJobRunner(jobType string, runner JobRunner) {
+	s.runners[jobType] = runner
+}
+
+// Shutdown performs graceful shutdown tasks for the job supervisor.
+func (s *JobSupervisor) Shutdown() {
+	s.logger.Debug("Shutting down job supervisor")
+}
+
+// Do looks for pending jobs and runs them.
+func (s *JobSupervisor) Do() error {
+	jobs, _, err := s.store.LockJobsPendingWork(s.instanceID, s.lockBatchSize)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to lock jobs pending work")
+		return err
+	}
+
+	for _, job := range jobs {
+		logger := s.logger.WithFields(log.Fields{
+			"job":     job.ID,
+			"jobType": job.Type,
+		})
+		s.supervise(job, logger)
+
+		unlocked, err := s.store.UnlockJob(job.ID, s.instanceID, false)
+		if err != nil {
+			logger.WithError(err).Error("failed to unlock job")
+		} else if !unlocked {
+			logger.Error("failed to release lock for job")
+		}
+	}
+
+	return nil
+}
+
+// supervise makes an attempt at the given job, which the caller must have
+// locked.
+func (s *JobSupervisor) supervise(job *model.Job, logger log.FieldLogger) {
+	job, err := s.store.GetJob(job.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get refreshed job")
+		return
+	}
+	if job == nil || job.State != model.JobStatePending {
+		return
+	}
+
+	job.State = model.JobStateRunning
+	job.Attempts++
+	job.StartAt = model.GetMillis()
+	if err = s.store.UpdateJob(job); err != nil {
+		logger.WithError(err).Error("Failed to record the start of the job")
+		return
+	}
+	logger.Infof("Running attempt %d of %d of job", job.Attempts, job.MaxAttempts)
+
+	superviseRecovering("job", s.metrics, logger, func() {
+		result, err := s.run(job)
+		s.finishAttempt(job, result, err, logger)
+	}, func(cause string) {
+		s.finishAttempt(job, "", fmt.Errorf("%s", cause), logger)
+	})
+}
+
+// run runs the job with the runner of its type.
+func (s *JobSupervisor) run(job *model.Job) (string, error) {
+	runner, ok := s.runners[job.Type]
+	if !ok {
+		return "", fmt.Errorf("jobs of type %s are not enabled on this server", job.Type)
+	}
+
+	return runner.RunJob(job)
+}
+
+// finishAttempt records the outcome of an attempt at the job, moving it back
+// to pending while it has attempts left.
+func (s *JobSupervisor) finishAttempt(job *model.Job, result string, jobErr error, logger log.FieldLogger) {
+	switch {
+	case jobErr == nil:
+		job.State = model.JobStateSucceeded
+		job.Result = result
+		job.Message = ""
+		job.EndAt = model.GetMillis()
+	case job.Attempts < job.MaxAttempts:
+		job.State = model.JobStatePending
+		job.Message = jobErr.Error()
+	default:
+		job.State = model.JobStateFailed
+		job.Message = jobErr.Error()
+		job.EndAt = model.GetMillis()
+	}
+
+	if err := s.store.UpdateJob(job); err != nil {
+		logger.WithError(err).Error("Failed to record the outcome of the job")
+		return
+	}
+
+	switch job.State {
+	case model.JobStateSucceeded:
+		logger.Infof("Job succeeded: %s", result)
+	case model.JobStatePending:
+		logger.WithError(jobErr).Warn("Job attempt failed; retrying later")
+	default:
+		logger.WithError(jobErr).Error("Job failed")
+	}
+}
+
+// pruneStore abstracts the database operations required to prune old
+// records.
+type pruneStore interface {
+	DeleteStateChangeEventsBefore(timestamp int64) (int64, error)
+	DeleteWebhookDeliveriesBefore(state string, timestamp int64) (int64, error)
+}
+
+// Pruner runs the prune jobs, deleting the state change events and the
+// delivered webhook deliveries older than the age given by the job.
+type Pruner struct {
+	store pruneStore
+}
+
+// NewPruner creates a new Pruner.
+func NewPruner(store pruneStore) *Pruner {
+	return &Pruner{store: store}
+}
+
+// RunJob deletes the records older than the age given by the prune job.
+func (p *Pruner) RunJob(job *model.Job) (string, error) {
+	days, err := job.Parameters.OlderThanDays()
+	if err != nil {
+		return "", err
+	}
+	before := time.Now().Add(-time.Duration(days) * 24 * time.Hour).UnixMilli()
+
+	events, err := p.store.DeleteStateChangeEventsBefore(before)
+	if err != nil {
+		return "", err
+	}
+	deliveries, err := p.store.DeleteWebhookDeliveriesBefore(model.WebhookDeliveryDelivered, before)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("deleted %d state change events and %d webhook deliveries older than %d days", events, deliveries, days), nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockJobRunner struct {
+	errs  []error
+	panic bool
+	runs  int
+}
+
+func (r *mockJobRunner) RunJob(job *model.Job) (string, error) {
+	r.runs++
+	if r.panic {
+		panic("runner exploded")
+	}
+	if len(r.errs) > 0 {
+		err := r.errs[0]
+		r.errs = r.errs[1:]
+		return "", err
+	}
+	return "done", nil
+}
+
+func TestJobSupervisor(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	runner := &mockJobRunner{}
+	supervisor := supervisor.NewJobSupervisor(sqlStore, "instance", logger, nil)
+	supervisor.SetJobRunner(model.JobTypeResync, runner)
+
+	createJob := func(jobType string, maxAttempts int) *model.Job {
+		job := &model.Job{Type: jobType, State: model.JobStatePending, MaxAttempts: maxAttempts}
+		require.NoError(t, sqlStore.CreateJob(job))
+		return job
+	}
+	getJob := func(jobID string) *model.Job {
+		job, err := sqlStore.GetJob(jobID)
+		require.NoError(t, err)
+		return job
+	}
+
+	t.Run("succeeds", func(t *testing.T) {
+		job := createJob(model.JobTypeResync, 3)
+
+		require.NoError(t, supervisor.Do())
+		current := getJob(job.ID)
+		require.Equal(t, model.JobStateSucceeded, current.State)
+		require.Equal(t, "done", current.Result)
+		require.Equal(t, 1, current.Attempts)
+		require.NotZero(t, current.StartAt)
+		require.NotZero(t, current.EndAt)
+		require.Nil(t, current.LockAcquiredBy)
+
+		// Completed jobs are not run again.
+		runs := runner.runs
+		require.NoError(t, supervisor.Do())
+		require.Equal(t, runs, runner.runs)
+	})
+
+	t.Run("retries until it succeeds", func(t *testing.T) {
+		runner.errs = []error{errors.New("provisioner unreachable")}
+		job := createJob(model.JobTypeResync, 3)
+
+		require.NoError(t, supervisor.Do())
+		current := getJob(job.ID)
+		require.Equal(t, model.JobStatePending, current.State)
+		require.Equal(t, "provisioner unreachable", current.Message)
+		require.Equal(t, 1, current.Attempts)
+
+		require.NoError(t, supervisor.Do())
+		current = getJob(job.ID)
+		require.Equal(t, model.JobStateSucceeded, current.State)
+		require.Empty(t, current.Message)
+		require.Equal(t, 2, current.Attempts)
+	})
+
+	t.Run("fails once its attempts run out", func(t *testing.T) {
+		runner.errs = []error{errors.New("first"), errors.New("second")}
+		job := createJob(model.JobTypeResync, 2)
+
+		require.NoError(t, supervisor.Do())
+		require.NoError(t, supervisor.Do())
+		current := getJob(job.ID)
+		require.Equal(t, model.JobStateFailed, current.State)
+		require.Equal(t, "second", current.Message)
+		require.NotZero(t, current.EndAt)
+	})
+
+	t.Run("jobs without a runner fail", func(t *testing.T) {
+		job := createJob(model.JobTypePrune, 1)
+
+		require.NoError(t, supervisor.Do())
+		current := getJob(job.ID)
+		require.Equal(t, model.JobStateFailed, current.State)
+		require.Equal(t, "jobs of type prune are not enabled on this server", current.Message)
+	})
+
+	t.Run("a panic fails the attempt", func(t *testing.T) {
+		runner.panic = true
+		defer func() { runner.panic = false }()
+		job := createJob(model.JobTypeResync, 1)
+
+		require.NoError(t, supervisor.Do())
+		current := getJob(job.ID)
+		require.Equal(t, model.JobStateFailed, current.State)
+		require.Contains(t, current.Message, "panic: runner exploded")
+		require.Nil(t, current.LockAcquiredBy)
+	})
+}
+
+type mockPruneStore struct {
+	eventsBefore     int64
+	deliveriesBefore int64
+	deliveriesState  string
+	err              error
+}
+
+func (s *mockPruneStore) DeleteStateChangeEventsBefore(timestamp int64) (int64, error) {
+	s.eventsBefore = timestamp
+	return 5, s.err
+}
+
+func (s *mockPruneStore) DeleteWebhookDeliveriesBefore(state string, timestamp int64) (int64, error) {
+	s.deliveriesState = state
+	s.deliveriesBefore = timestamp
+	return 2, nil
+}
+
+func TestPruner(t *testing.T) {
+	store := &mockPruneStore{}
+	pruner := supervisor.NewPruner(store)
+
+	result, err := pruner.RunJob(&model.Job{Parameters: model.JobParameters{model.JobParameterOlderThanDays: "7"}})
+	require.NoError(t, err)
+	require.Equal(t, "deleted 5 state change events and 2 webhook deliveries older than 7 days", result)
+	require.InDelta(t, time.Now().Add(-7*24*time.Hour).UnixMilli(), store.eventsBefore, float64(time.Minute.Milliseconds()))
+	require.Equal(t, store.eventsBefore, store.deliveriesBefore)
+	require.Equal(t, model.WebhookDeliveryDelivered, store.deliveriesState)
+
+	_, err = pruner.RunJob(&model.Job{})
+	require.Error(t, err)
+
+	store.err = errors.New("database is unreachable")
+	_, err = pruner.RunJob(&model.Job{Parameters: model.JobParameters{model.JobParameterOlderThanDays: "7"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMaintenanceChecker struct {
+	Found   []*model.MaintenanceConflict
+	Err     error
+	Queries []*model.MaintenanceConflictQuery
+}
+
+func (c *mockMaintenanceChecker) Conflicts(query *model.MaintenanceConflictQuery) ([]*model.MaintenanceConflict, error) {
+	c.Queries = append(c.Queries, query)
+	return c.Found, c.Err
+}
+
+func TestRingSupervisorMaintenanceConflicts(t *testing.T) {
+	conflict := &model.MaintenanceConflict{Name: "db-upgrade", StartAt: 1000, EndAt: 2000, InstallationGroups: []string{"group1"}}
+
+	testCases := []struct {
+		Description      string
+		Conflicts        []*model.MaintenanceConflict
+		Err              error
+		ExpectedState    string
+		ExpectedConflict string
+	}{
+		{"no conflict", nil, nil, model.RingStateReleaseRequested, ""},
+		{"conflict", []*model.MaintenanceConflict{conflict}, nil, model.RingStateReleasePending, conflict.String()},
+		{"checker unavailable", nil, errors.New("connection refused"), model.RingStateReleasePending, "maintenance conflicts could not be checked"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Description, func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			checker := &mockMaintenanceChecker{Found: tc.Conflicts, Err: tc.Err}
+			ringSupervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+			ringSupervisor.SetMaintenanceChecker(checker)
+
+			ring := &model.Ring{
+				Name:  "production",
+				State: model.RingStateReleasePending,
+			}
+			installationGroup := model.InstallationGroup{
+				Name:  "group1",
+				State: model.InstallationGroupStable,
+			}
+			require.NoError(t, sqlStore.CreateRing(ring, &installationGroup))
+
+			ringSupervisor.Supervise(ring)
+
+			ring, err := sqlStore.GetRing(ring.ID)
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedState, ring.State)
+			require.Equal(t, tc.ExpectedConflict, ring.MaintenanceConflict)
+
+			require.Len(t, checker.Queries, 1)
+			require.Equal(t, ring.ID, checker.Queries[0].RingID)
+			require.Len(t, checker.Queries[0].InstallationGroups, 1)
+			require.Equal(t, "group1", checker.Queries[0].InstallationGroups[0].Name)
+			require.GreaterOrEqual(t, checker.Queries[0].To, checker.Queries[0].From)
+			if tc.ExpectedConflict == "" {
+				return
+			}
+
+			// The release starts once the maintenance is over.
+			checker.Found = nil
+			checker.Err = nil
+			ringSupervisor.Supervise(ring)
+
+			ring, err = sqlStore.GetRing(ring.ID)
+			require.NoError(t, err)
+			require.Equal(t, model.RingStateReleaseRequested, ring.State)
+			require.Empty(t, ring.MaintenanceConflict)
+		})
+	}
+}
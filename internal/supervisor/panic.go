@@ -0,0 +1,174 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/mattermost/elrond/internal/metrics"
+	"github.com/mattermost/elrond/internal/webhook"
+	"github.com/mattermost/elrond/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// superviseRecovering runs the given supervision of a row of the given
+// resource type, recovering from a panic so that one misbehaving row cannot
+// crash the server. The panic is logged and counted, and fail is called with
+// its description, stack trace included, to fail the row. Callers release the
+// lock of the row once it returns.
+func superviseRecovering(resource string, m *metrics.Metrics, logger log.FieldLogger, supervise func(), fail func(cause string)) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		cause := fmt.Sprintf("panic: %v\n\n%s", r, debug.Stack())
+		logger.WithField("panic", r).Errorf("Recovered from a panic while supervising the %s\n%s", resource, cause)
+		m.ObserveSupervisorPanic(resource)
+
+		// Failing the row must not bring the server down either.
+		defer func() {
+			if r := recover(); r != nil {
+				logger.WithField("panic", r).Errorf("Recovered from a panic while failing the %s", resource)
+			}
+		}()
+		fail(cause)
+	}()
+
+	supervise()
+}
+
+// ringPanicFailedState returns the failed state of a ring that panicked while
+// supervised in the given state, or the given state if there is none.
+func ringPanicFailedState(state string) string {
+	switch state {
+	case model.RingStateCreationRequested:
+		return model.RingStateCreationFailed
+	case model.RingStateReleasePending, model.RingStateReleaseBlocked, model.RingStateReleasePrepareRequested, model.RingStateReleaseRequested, model.RingStateReleaseInProgress:
+		return model.RingStateReleaseFailed
+	case model.RingStateSoakingRequested:
+		return model.RingStateSoakingFailed
+	case model.RingStateReleaseRollbackRequested:
+		return model.RingStateReleaseRollbackFailed
+	case model.RingStateDeletionPending, model.RingStateDeletionRequested:
+		return model.RingStateDeletionFailed
+	}
+
+	return state
+}
+
+// installationGroupPanicFailedState returns the failed state of an
+// installation group that panicked while supervised in the given state, or the
+// given state if there is none.
+func installationGroupPanicFailedState(state string) string {
+	switch state {
+	case model.InstallationGroupReleasePending,
+		model.InstallationGroupReleaseRequested,
+		model.InstallationGroupBlueGreenVerifyRequested,
+		model.InstallationGroupBlueGreenSwitchRequested,
+		model.InstallationGroupBlueGreenTeardownRequested,
+		model.InstallationGroupReleaseVerificationRequested:
+		return model.InstallationGroupReleaseFailed
+	case model.InstallationGroupReleaseSoakingRequested:
+		return model.InstallationGroupReleaseSoakingFailed
+	case model.InstallationGroupReleaseRollbackRequested:
+		return model.InstallationGroupReleaseRollbackFailed
+	}
+
+	return state
+}
+
+// failPanickedRing moves the given ring, whose supervision panicked, to the
+// failed state matching its state, recording the cause of the panic in its
+// state change event.
+func (s *RingSupervisor) failPanickedRing(ringID, cause string, logger log.FieldLogger) {
+	ring, err := s.store.GetRing(ringID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the ring to fail")
+		return
+	}
+	if ring == nil {
+		return
+	}
+
+	oldState := ring.State
+	ring.State = ringPanicFailedState(oldState)
+	if ring.State != oldState {
+		if err = s.store.UpdateRing(ring); err != nil {
+			logger.WithError(err).Errorf("Failed to set ring state to %s", ring.State)
+			return
+		}
+		logger.Warnf("Moved ring from %s to %s after a panic", oldState, ring.State)
+	}
+
+	event := model.NewStateChangeEvent(model.TypeRing, ring.ID, ring, oldState, ring.State)
+	event.Error = cause
+	if err = s.store.CreateStateChangeEvent(event); err != nil {
+		logger.WithError(err).Warn("failed to record ring state change event")
+	}
+
+	if ring.State == oldState {
+		return
+	}
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeRing,
+		ID:        ring.ID,
+		NewState:  ring.State,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		Labels:    ring.Annotations,
+		Metadata:  ring.Metadata,
+	}
+	if err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
+
+// failPanickedInstallationGroup moves the installation group of the given
+// work, whose supervision panicked, to the failed state matching its state,
+// recording the cause of the panic in its state change event, and applies the
+// failure policy of its ring.
+func (s *InstallationGroupSupervisor) failPanickedInstallationGroup(work *model.InstallationGroupWork, cause string, logger log.FieldLogger) {
+	installationGroup, err := s.store.GetInstallationGroupByID(work.InstallationGroup.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the installation group to fail")
+		return
+	}
+	if installationGroup == nil {
+		return
+	}
+
+	oldState := installationGroup.State
+	installationGroup.State = installationGroupPanicFailedState(oldState)
+	if installationGroup.State != oldState {
+		if err = s.store.UpdateInstallationGroup(installationGroup); err != nil {
+			logger.WithError(err).Errorf("Failed to set installation group state to %s", installationGroup.State)
+			return
+		}
+		logger.Warnf("Moved installation group from %s to %s after a panic", oldState, installationGroup.State)
+	}
+
+	if work.Ring == nil {
+		logger.Warn("the installation group is not registered to any ring; not recording its state change event")
+	} else {
+		event := model.NewStateChangeEvent(model.TypeInstallationGroup, installationGroup.ID, work.Ring, oldState, installationGroup.State)
+		event.Metadata = installationGroup.Metadata
+		event.Error = cause
+		if err = s.store.CreateStateChangeEvent(event); err != nil {
+			logger.WithError(err).Warn("failed to record installation group state change event")
+		}
+	}
+
+	if installationGroup.State == oldState {
+		return
+	}
+	s.sendWebhook(installationGroup, work.Ring, oldState, installationGroup.State, logger)
+	if installationGroup.State == model.InstallationGroupReleaseFailed || installationGroup.State == model.InstallationGroupReleaseSoakingFailed {
+		s.applyFailurePolicy(installationGroup, work.Ring, logger)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/metrics"
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type panickingRingProvisioner struct {
+	mockRingProvisioner
+}
+
+func (p *panickingRingProvisioner) CreateRing(ring *model.Ring) error {
+	panic("provisioner bug")
+}
+
+type panickingInstallationGroupProvisioner struct {
+	mockInstallationGroupProvisioner
+}
+
+func (p *panickingInstallationGroupProvisioner) ReleaseInstallationGroup(installationGroup *model.InstallationGroup, image, version string, parameters *model.InstallationGroupReleaseParameters) error {
+	panic("provisioner bug")
+}
+
+func TestRingSupervisorRecoversFromPanics(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	panicking := &model.Ring{Priority: 1, State: model.RingStateCreationRequested}
+	require.NoError(t, sqlStore.CreateRing(panicking, nil))
+
+	prometheusMetrics := metrics.NewPrometheus()
+	elrondMetrics := metrics.New(nil, prometheusMetrics)
+	ringSupervisor := supervisor.NewRingSupervisor(sqlStore, &panickingRingProvisioner{}, "instanceID", logger, elrondMetrics, model.SoakTimeDefaults{})
+	require.NoError(t, ringSupervisor.Do())
+
+	ring, err := sqlStore.GetRing(panicking.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.RingStateCreationFailed, ring.State)
+	require.Equal(t, float64(1), testutil.ToFloat64(prometheusMetrics.SupervisorPanics.WithLabelValues(model.TypeRing)))
+
+	locked, err := sqlStore.GetRingsLocked()
+	require.NoError(t, err)
+	require.Empty(t, locked)
+
+	events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{
+		RingID:  ring.ID,
+		State:   model.RingStateCreationFailed,
+		PerPage: model.AllPerPage,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Contains(t, events[0].Error, "panic: provisioner bug")
+	require.Contains(t, events[0].Error, "panickingRingProvisioner")
+}
+
+func TestInstallationGroupSupervisorRecoversFromPanics(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"})
+	require.NoError(t, err)
+	ring := &model.Ring{
+		Priority:         1,
+		State:            model.RingStateReleaseInProgress,
+		DesiredReleaseID: release.ID,
+	}
+	installationGroup := &model.InstallationGroup{
+		Name:  "group1",
+		State: model.InstallationGroupReleaseRequested,
+	}
+	require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+
+	prometheusMetrics := metrics.NewPrometheus()
+	elrondMetrics := metrics.New(nil, prometheusMetrics)
+	installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, &panickingInstallationGroupProvisioner{}, "instanceID", logger, elrondMetrics)
+	require.NoError(t, installationGroupSupervisor.Do())
+
+	installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.InstallationGroupReleaseFailed, installationGroup.State)
+	require.Equal(t, float64(1), testutil.ToFloat64(prometheusMetrics.SupervisorPanics.WithLabelValues(model.TypeInstallationGroup)))
+
+	locked, err := sqlStore.GetInstallationGroupsLocked()
+	require.NoError(t, err)
+	require.Empty(t, locked)
+
+	// The failure policy of the ring applies as for any failed release.
+	ring, err = sqlStore.GetRing(ring.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.RingStateReleaseFailed, ring.State)
+
+	events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{
+		RingID:       ring.ID,
+		ResourceType: model.TypeInstallationGroup,
+		PerPage:      model.AllPerPage,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, model.InstallationGroupReleaseFailed, events[0].NewState)
+	require.Contains(t, events[0].Error, "panic: provisioner bug")
+}
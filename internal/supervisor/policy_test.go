@@ -0,0 +1,81 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPolicyEngine struct {
+	Decision *model.PolicyDecision
+	Err      error
+	Inputs   []*model.PolicyInput
+}
+
+func (e *mockPolicyEngine) Evaluate(input *model.PolicyInput) (*model.PolicyDecision, error) {
+	e.Inputs = append(e.Inputs, input)
+	return e.Decision, e.Err
+}
+
+func TestRingSupervisorPolicy(t *testing.T) {
+	testCases := []struct {
+		Description   string
+		Protected     bool
+		Decision      *model.PolicyDecision
+		Err           error
+		ExpectedState string
+		Evaluated     bool
+	}{
+		{"unprotected ring", false, &model.PolicyDecision{Allow: false}, nil, model.RingStateReleaseRequested, false},
+		{"allowed", true, &model.PolicyDecision{Allow: true}, nil, model.RingStateReleaseRequested, true},
+		{"denied", true, &model.PolicyDecision{Allow: false, Reason: "outside of business hours"}, nil, model.RingStateReleasePending, true},
+		{"policy unavailable", true, nil, errors.New("connection refused"), model.RingStateReleasePending, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Description, func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			policy := &mockPolicyEngine{Decision: tc.Decision, Err: tc.Err}
+			ringSupervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+			ringSupervisor.SetPolicyEngine(policy)
+
+			ring := &model.Ring{
+				Name:      "production",
+				State:     model.RingStateReleasePending,
+				Protected: tc.Protected,
+			}
+			installationGroup := model.InstallationGroup{
+				Name:  "group1",
+				State: model.InstallationGroupStable,
+			}
+			require.NoError(t, sqlStore.CreateRing(ring, &installationGroup))
+
+			ringSupervisor.Supervise(ring)
+
+			ring, err := sqlStore.GetRing(ring.ID)
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedState, ring.State)
+
+			if !tc.Evaluated {
+				require.Empty(t, policy.Inputs)
+				return
+			}
+			require.Len(t, policy.Inputs, 1)
+			require.Equal(t, model.PolicyActionTransition, policy.Inputs[0].Action)
+			require.Equal(t, model.RingStateReleasePending, policy.Inputs[0].State)
+			require.Equal(t, "production", policy.Inputs[0].Ring.Name)
+			require.Equal(t, "instanceID", policy.Inputs[0].InstanceID)
+			require.NotEmpty(t, policy.Inputs[0].Time)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"github.com/mattermost/elrond/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// rehearsalProvisioner stands in for the provisioner and the release verifier
+// of rehearsal rings and their installation groups. Every operation succeeds
+// and is only logged, so that a rehearsal goes through the whole release
+// workflow without touching real installations. The release impact and
+// health it reports are the capacity recorded on the installation groups.
+type rehearsalProvisioner struct {
+	logger log.FieldLogger
+}
+
+// newRehearsalProvisioner creates a rehearsal provisioner logging with the
+// given logger.
+func newRehearsalProvisioner(logger log.FieldLogger) *rehearsalProvisioner {
+	return &rehearsalProvisioner{
+		logger: logger.WithField("provisioner", "rehearsal"),
+	}
+}
+
+func (p *rehearsalProvisioner) PrepareRing(ring *model.Ring) bool {
+	return true
+}
+
+func (p *rehearsalProvisioner) CreateRing(ring *model.Ring) error {
+	p.logger.WithField("ring", ring.ID).Info("Rehearsing the creation of the ring")
+	return nil
+}
+
+func (p *rehearsalProvisioner) ReleaseRing(ring *model.Ring) error {
+	p.logger.WithField("ring", ring.ID).Info("Rehearsing the release of the ring")
+	return nil
+}
+
+func (p *rehearsalProvisioner) PrepareRelease(ring *model.Ring, release *model.RingRelease, installationGroups []*model.InstallationGroup) error {
+	p.logger.WithField("ring", ring.ID).Infof("Rehearsing the preparation of release %s:%s for %d installation groups", release.Image, release.Version, len(installationGroups))
+	return nil
+}
+
+func (p *rehearsalProvisioner) SoakRing(ring *model.Ring) error {
+	p.logger.WithField("ring", ring.ID).Info("Rehearsing the soak of the ring")
+	return nil
+}
+
+func (p *rehearsalProvisioner) RollBackRing(ring *model.Ring) error {
+	p.logger.WithField("ring", ring.ID).Info("Rehearsing the rollback of the ring")
+	return nil
+}
+
+func (p *rehearsalProvisioner) DeleteRing(ring *model.Ring) error {
+	p.logger.WithField("ring", ring.ID).Info("Rehearsing the deletion of the ring")
+	return nil
+}
+
+func (p *rehearsalProvisioner) GetReleaseImpact(installationGroups []*model.InstallationGroup) (*model.ReleaseImpact, error) {
+	impact := &model.ReleaseImpact{}
+	for _, installationGroup := range installationGroups {
+		impact.Installations += installationGroup.InstallationCount
+		impact.Customers += installationGroup.CustomerCount
+	}
+
+	return impact, nil
+}
+
+func (p *rehearsalProvisioner) RegisterInstallationGroup(installationGroup *model.InstallationGroup) error {
+	p.logger.WithField("installationgroup", installationGroup.ID).Info("Rehearsing the registration of the installation group")
+	return nil
+}
+
+func (p *rehearsalProvisioner) ReleaseInstallationGroup(installationGroup *model.InstallationGroup, image, version string, parameters *model.InstallationGroupReleaseParameters) error {
+	p.logger.WithField("installationgroup", installationGroup.ID).Infof("Rehearsing the release of %s:%s to the installation group", image, version)
+	return nil
+}
+
+func (p *rehearsalProvisioner) RollbackInstallationGroup(installationGroup *model.InstallationGroup, image, version string) error {
+	p.logger.WithField("installationgroup", installationGroup.ID).Infof("Rehearsing the rollback of the installation group to %s:%s", image, version)
+	return nil
+}
+
+func (p *rehearsalProvisioner) SoakInstallationGroup(installationGroup *model.InstallationGroup) error {
+	p.logger.WithField("installationgroup", installationGroup.ID).Info("Rehearsing the soak of the installation group")
+	return nil
+}
+
+func (p *rehearsalProvisioner) GetInstallationGroupHealth(installationGroup *model.InstallationGroup) (*model.HealthSnapshot, error) {
+	return &model.HealthSnapshot{
+		InstallationsTotal:   installationGroup.InstallationCount,
+		InstallationsUpdated: installationGroup.InstallationCount,
+	}, nil
+}
+
+func (p *rehearsalProvisioner) GetInstallationGroupLoad(installationGroup *model.InstallationGroup) (*model.InstallationGroupLoad, error) {
+	return &model.InstallationGroupLoad{}, nil
+}
+
+func (p *rehearsalProvisioner) SnapshotInstallationGroupDatabases(installationGroup *model.InstallationGroup, release *model.RingRelease) ([]*model.DatabaseSnapshot, error) {
+	p.logger.WithField("installationgroup", installationGroup.ID).Info("Rehearsing the database snapshots of the installation group")
+	return nil, nil
+}
+
+// StandUpGreenInstallationGroup returns the provisioner group of the
+// installation group as its green group, which it keeps once switched.
+func (p *rehearsalProvisioner) StandUpGreenInstallationGroup(installationGroup *model.InstallationGroup, image, version string, parameters *model.InstallationGroupReleaseParameters) (string, error) {
+	p.logger.WithField("installationgroup", installationGroup.ID).Infof("Rehearsing standing up the green provisioner group with %s:%s", image, version)
+	return installationGroup.ProvisionerGroupID, nil
+}
+
+func (p *rehearsalProvisioner) VerifyGreenInstallationGroup(installationGroup *model.InstallationGroup, image, version string) error {
+	p.logger.WithField("installationgroup", installationGroup.ID).Info("Rehearsing the verification of the green provisioner group")
+	return nil
+}
+
+func (p *rehearsalProvisioner) SwitchInstallationGroup(installationGroup *model.InstallationGroup) error {
+	p.logger.WithField("installationgroup", installationGroup.ID).Info("Rehearsing the switch to the green provisioner group")
+	return nil
+}
+
+func (p *rehearsalProvisioner) TearDownBlueInstallationGroup(installationGroup *model.InstallationGroup) error {
+	p.logger.WithField("installationgroup", installationGroup.ID).Info("Rehearsing the teardown of the blue provisioner group")
+	return nil
+}
+
+// StartVerification starts a rehearsal verification job, which passes once
+// its status is first checked.
+func (p *rehearsalProvisioner) StartVerification(installationGroup *model.InstallationGroup, release *model.RingRelease) (*model.VerificationJob, error) {
+	p.logger.WithField("installationgroup", installationGroup.ID).Info("Rehearsing the release verification job of the installation group")
+	return &model.VerificationJob{
+		ID:     "rehearsal-" + installationGroup.ID,
+		Status: model.VerificationJobRunning,
+	}, nil
+}
+
+func (p *rehearsalProvisioner) GetVerification(installationGroup *model.InstallationGroup, jobID string) (*model.VerificationJob, error) {
+	return &model.VerificationJob{
+		ID:     jobID,
+		Status: model.VerificationJobPassed,
+	}, nil
+}
+
+// annotateRehearsal flags the given webhook payload of a rehearsal ring, or
+// one of its installation groups, so that receivers can tell rehearsals from
+// real releases.
+func annotateRehearsal(payload *model.WebhookPayload, ring *model.Ring) {
+	if ring == nil || !ring.Rehearsal {
+		return
+	}
+	if payload.ExtraData == nil {
+		payload.ExtraData = make(map[string]string)
+	}
+	payload.ExtraData["Rehearsal"] = "true"
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingSupervisorRehearsal(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	t.Run("the provisioner is never called", func(t *testing.T) {
+		provisioner := &mockRingProvisioner{}
+		ringSupervisor := supervisor.NewRingSupervisor(sqlStore, provisioner, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+
+		ring := &model.Ring{
+			State:     model.RingStateCreationRequested,
+			Rehearsal: true,
+		}
+		installationGroup := &model.InstallationGroup{Name: "group1", ProvisionerGroupID: "group-id", InstallationCount: 12, CustomerCount: 3}
+		require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+
+		ringSupervisor.Supervise(ring)
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, ring.State)
+		require.Empty(t, provisioner.RegisteredInstallationGroups)
+
+		ring.State = model.RingStateReleasePrepareRequested
+		ring.DesiredReleaseID = release.ID
+		require.NoError(t, sqlStore.UpdateRing(ring))
+
+		ringSupervisor.Supervise(ring)
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePrepared, ring.State)
+		require.Empty(t, provisioner.PreparedReleases)
+	})
+
+	t.Run("soaks run on accelerated time", func(t *testing.T) {
+		ringSupervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		newSoakingRing := func(t *testing.T, rehearsal bool) *model.Ring {
+			release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "3.0.0"})
+			require.NoError(t, err)
+			ring := &model.Ring{
+				State:            model.RingStateSoakingRequested,
+				SoakTime:         3600,
+				ReleaseAt:        time.Now().Add(-2 * time.Minute).UnixNano(),
+				DesiredReleaseID: release.ID,
+				Rehearsal:        rehearsal,
+			}
+			require.NoError(t, sqlStore.CreateRing(ring, &model.InstallationGroup{Name: model.NewID()}))
+
+			return ring
+		}
+
+		ring := newSoakingRing(t, false)
+		ringSupervisor.Supervise(ring)
+		ring, err := sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateSoakingRequested, ring.State)
+
+		ring = newSoakingRing(t, true)
+		ringSupervisor.Supervise(ring)
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, ring.State)
+	})
+}
+
+func TestInstallationGroupSupervisorRehearsal(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	desired, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"})
+	require.NoError(t, err)
+	ring := &model.Ring{
+		Priority:         1,
+		State:            model.RingStateReleaseInProgress,
+		DesiredReleaseID: desired.ID,
+		Rehearsal:        true,
+		RehearsalSpeedup: 3600,
+	}
+	installationGroup := &model.InstallationGroup{
+		Name:              "group1",
+		State:             model.InstallationGroupReleaseRequested,
+		SoakTime:          3600,
+		VerificationURL:   "http://verification.example.com/jobs",
+		InstallationCount: 5,
+	}
+	require.NoError(t, sqlStore.CreateRing(ring, installationGroup))
+
+	provisioner := &mockInstallationGroupProvisioner{}
+	installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, provisioner, "instanceID", logger, nil)
+	supervise := func(t *testing.T) *model.InstallationGroup {
+		installationGroup, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		installationGroupSupervisor.Supervise(installationGroup)
+
+		actual, err := sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		return actual
+	}
+
+	// The verification job is rehearsed too, without a verifier.
+	actual := supervise(t)
+	require.Equal(t, model.InstallationGroupReleaseVerificationRequested, actual.State)
+	actual = supervise(t)
+	require.Equal(t, model.InstallationGroupReleaseVerificationRequested, actual.State)
+	actual = supervise(t)
+	require.Equal(t, model.InstallationGroupReleaseSoakingRequested, actual.State)
+	require.Equal(t, model.VerificationJobPassed, actual.VerificationStatus)
+
+	// An hour of soak time passes in a second.
+	time.Sleep(time.Second)
+	actual = supervise(t)
+	require.Equal(t, model.InstallationGroupStable, actual.State)
+	require.Empty(t, provisioner.Released)
+
+	snapshots, err := sqlStore.GetHealthSnapshots(&model.HealthSnapshotFilter{RingID: ring.ID, PerPage: model.AllPerPage})
+	require.NoError(t, err)
+	require.NotEmpty(t, snapshots)
+	require.Equal(t, int64(5), snapshots[0].InstallationsTotal)
+}
+
+func TestRehearsalRingIsolation(t *testing.T) {
+	newRing := func(t *testing.T, sqlStore *store.SQLStore, state string, priority int, rehearsal bool) *model.Ring {
+		release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+		ring := &model.Ring{
+			Priority:         priority,
+			State:            state,
+			DesiredReleaseID: release.ID,
+			Rehearsal:        rehearsal,
+		}
+		require.NoError(t, sqlStore.CreateRing(ring, &model.InstallationGroup{Name: model.NewID(), State: model.InstallationGroupStable}))
+
+		return ring
+	}
+
+	for _, rehearsal := range []bool{true, false} {
+		t.Run(fmt.Sprintf("releases are not held back across kinds, rehearsal %t", rehearsal), func(t *testing.T) {
+			logger := testlib.MakeLogger(t)
+			sqlStore := store.MakeTestSQLStore(t, logger)
+			defer store.CloseConnection(t, sqlStore)
+
+			newRing(t, sqlStore, model.RingStateReleaseInProgress, 1, !rehearsal)
+			other := newRing(t, sqlStore, model.RingStateReleasePending, 1, !rehearsal)
+			_, err := sqlStore.LockRing(other.ID, "otherInstanceID")
+			require.NoError(t, err)
+			ring := newRing(t, sqlStore, model.RingStateReleasePending, 2, rehearsal)
+
+			ringSupervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+			ringSupervisor.Supervise(ring)
+
+			ring, err = sqlStore.GetRing(ring.ID)
+			require.NoError(t, err)
+			require.Equal(t, model.RingStateReleaseRequested, ring.State)
+		})
+	}
+
+	t.Run("failed rehearsals only fail rehearsal rings", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		defer store.CloseConnection(t, sqlStore)
+
+		production := newRing(t, sqlStore, model.RingStateReleaseInProgress, 1, false)
+		otherRehearsal := newRing(t, sqlStore, model.RingStateReleaseInProgress, 2, true)
+		ring := newRing(t, sqlStore, model.RingStateReleaseFailed, 3, true)
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(ring.ID)
+		require.NoError(t, err)
+		installationGroup := installationGroups[0]
+		installationGroup.State = model.InstallationGroupReleasePending
+		require.NoError(t, sqlStore.UpdateInstallationGroup(installationGroup))
+
+		installationGroupSupervisor := supervisor.NewInstallationGroupSupervisor(sqlStore, &mockInstallationGroupProvisioner{}, "instanceID", logger, nil)
+		installationGroupSupervisor.Supervise(installationGroup)
+
+		installationGroup, err = sqlStore.GetInstallationGroupByID(installationGroup.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationGroupReleaseFailed, installationGroup.State)
+
+		production, err = sqlStore.GetRing(production.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseInProgress, production.State)
+		otherRehearsal, err = sqlStore.GetRing(otherRehearsal.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseFailed, otherRehearsal.State)
+	})
+}
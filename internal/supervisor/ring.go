@@ -5,11 +5,16 @@
 package supervisor
 
 import (
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/mattermost/elrond/internal/metrics"
 	"github.com/mattermost/elrond/internal/webhook"
 
 	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -17,6 +22,7 @@ import (
 type ringStore interface {
 	GetRing(ringID string) (*model.Ring, error)
 	GetUnlockedRingsPendingWork() ([]*model.Ring, error)
+	LockRingsPendingWork(lockerID string, limit int) ([]*model.Ring, int, error)
 	GetRings(ringFilter *model.RingFilter) ([]*model.Ring, error)
 	CreateRing(ring *model.Ring, installationGroup *model.InstallationGroup) error
 	UpdateRing(ring *model.Ring) error
@@ -30,8 +36,49 @@ type ringStore interface {
 	GetInstallationGroupsForRing(ringID string) ([]*model.InstallationGroup, error)
 	UpdateInstallationGroup(installationGroup *model.InstallationGroup) error
 	GetRingRelease(releaseID string) (*model.RingRelease, error)
+	MarkRingReleaseImmutable(releaseID string) error
 	GetRingsPendingWork() ([]*model.Ring, error)
 	UpdateRings(rings []*model.Ring) error
+	CreateRingReleaseSnapshot(ringID string, ringRelease *model.RingRelease) (*model.RingReleaseSnapshot, error)
+	CreateStateChangeEvent(event *model.StateChangeEvent) error
+	GetStateChangeEvents(filter *model.StateChangeEventFilter) ([]*model.StateChangeEvent, error)
+	GetInstallationGroupByID(id string) (*model.InstallationGroup, error)
+	GetRingInstallationGroupChanges(ringID string) ([]*model.RingInstallationGroupChange, error)
+	ApplyRingInstallationGroupChanges(changes []*model.RingInstallationGroupChange, installationGroups []*model.InstallationGroup) error
+	GetHealthSnapshots(filter *model.HealthSnapshotFilter) ([]*model.HealthSnapshot, error)
+}
+
+// EvidenceArchiver archives the evidence of completed ring releases.
+type EvidenceArchiver interface {
+	Archive(evidence *model.ReleaseEvidence) (string, error)
+}
+
+// Notifier notifies stakeholders of ring release milestones.
+type Notifier interface {
+	Notify(notification *model.ReleaseNotification) error
+}
+
+// IssueTracker tracks the releases of rings in an issue tracker.
+type IssueTracker interface {
+	TrackRelease(ring *model.Ring, release *model.RingRelease, installationGroups []*model.InstallationGroup, oldState, newState string) (string, error)
+}
+
+// PolicyEngine evaluates the authorization policy of the work of the
+// supervisors on protected rings.
+type PolicyEngine interface {
+	Evaluate(input *model.PolicyInput) (*model.PolicyDecision, error)
+}
+
+// MaintenanceChecker looks up the infrastructure maintenance overlapping the
+// release of a ring.
+type MaintenanceChecker interface {
+	Conflicts(query *model.MaintenanceConflictQuery) ([]*model.MaintenanceConflict, error)
+}
+
+// FailureIssueCreator opens the issue tracking the repeated release failures
+// of a ring, returning its URL.
+type FailureIssueCreator interface {
+	CreateFailureIssue(failures *model.RepeatedFailures) (string, error)
 }
 
 // ringProvisioner abstracts the provisioning operations required by the ring supervisor.
@@ -39,9 +86,12 @@ type ringProvisioner interface {
 	PrepareRing(ring *model.Ring) bool
 	CreateRing(ring *model.Ring) error
 	ReleaseRing(ring *model.Ring) error
+	PrepareRelease(ring *model.Ring, release *model.RingRelease, installationGroups []*model.InstallationGroup) error
 	SoakRing(ring *model.Ring) error
 	RollBackRing(ring *model.Ring) error
 	DeleteRing(ring *model.Ring) error
+	GetReleaseImpact(installationGroups []*model.InstallationGroup) (*model.ReleaseImpact, error)
+	RegisterInstallationGroup(installationGroup *model.InstallationGroup) error
 }
 
 // RingSupervisor finds rings pending work and effects the required changes.
@@ -51,18 +101,135 @@ type ringProvisioner interface {
 type RingSupervisor struct {
 	store       ringStore
 	provisioner ringProvisioner
+	rehearsal   *rehearsalProvisioner
 	instanceID  string
 	logger      log.FieldLogger
+	metrics     *metrics.Metrics
+
+	lockBatchSize int
+	semaphore     Semaphore
+
+	// settingsLock guards the settings below, which can be changed while the
+	// supervisor is running.
+	settingsLock       sync.RWMutex
+	soakTimeDefaults   model.SoakTimeDefaults
+	evidenceArchiver   EvidenceArchiver
+	notifiers          []Notifier
+	issueTracker       IssueTracker
+	policy             PolicyEngine
+	maintenanceChecker MaintenanceChecker
+	failureIssues      FailureIssueCreator
+	failureIssueConfig model.FailureIssueSettings
 }
 
 // NewRingSupervisor creates a new RingSupervisor.
-func NewRingSupervisor(store ringStore, ringProvisioner ringProvisioner, instanceID string, logger log.FieldLogger) *RingSupervisor {
+func NewRingSupervisor(store ringStore, ringProvisioner ringProvisioner, instanceID string, logger log.FieldLogger, metrics *metrics.Metrics, soakTimeDefaults model.SoakTimeDefaults) *RingSupervisor {
 	return &RingSupervisor{
-		store:       store,
-		provisioner: ringProvisioner,
-		instanceID:  instanceID,
-		logger:      logger,
+		store:            store,
+		provisioner:      ringProvisioner,
+		rehearsal:        newRehearsalProvisioner(logger),
+		instanceID:       instanceID,
+		logger:           logger,
+		metrics:          metrics,
+		lockBatchSize:    DefaultLockBatchSize,
+		semaphore:        NewSemaphore(DefaultParallelism),
+		soakTimeDefaults: soakTimeDefaults,
+	}
+}
+
+// provisionerFor returns the provisioner of the given ring: the rehearsal
+// provisioner for rehearsal rings, so that they never touch real
+// installations.
+func (s *RingSupervisor) provisionerFor(ring *model.Ring) ringProvisioner {
+	if ring.Rehearsal {
+		return s.rehearsal
 	}
+
+	return s.provisioner
+}
+
+// SetLockBatchSize changes the number of rings pending work locked at once
+// on each run. It must be called before the supervisor is first run.
+func (s *RingSupervisor) SetLockBatchSize(lockBatchSize int) {
+	s.lockBatchSize = lockBatchSize
+}
+
+// SetSemaphore changes the semaphore bounding the number of rings worked on
+// at once, so that it can be shared with other supervisors. It must be called
+// before the supervisor is first run.
+func (s *RingSupervisor) SetSemaphore(semaphore Semaphore) {
+	s.semaphore = semaphore
+}
+
+// SetEvidenceArchiver configures the archiver the evidence of every completed
+// release is uploaded to. Passing nil disables archiving.
+func (s *RingSupervisor) SetEvidenceArchiver(archiver EvidenceArchiver) {
+	s.settingsLock.Lock()
+	defer s.settingsLock.Unlock()
+
+	s.evidenceArchiver = archiver
+}
+
+// AddNotifier adds a notifier of the ring release milestones.
+func (s *RingSupervisor) AddNotifier(notifier Notifier) {
+	s.settingsLock.Lock()
+	defer s.settingsLock.Unlock()
+
+	s.notifiers = append(s.notifiers, notifier)
+}
+
+// SetNotifiers replaces the notifiers of the ring release milestones.
+func (s *RingSupervisor) SetNotifiers(notifiers []Notifier) {
+	s.settingsLock.Lock()
+	defer s.settingsLock.Unlock()
+
+	s.notifiers = notifiers
+}
+
+// SetIssueTracker configures the issue tracker the releases of rings with a
+// Jira project are tracked in. Passing nil disables tracking.
+func (s *RingSupervisor) SetIssueTracker(tracker IssueTracker) {
+	s.settingsLock.Lock()
+	defer s.settingsLock.Unlock()
+
+	s.issueTracker = tracker
+}
+
+// SetPolicyEngine configures the authorization policy evaluated before
+// working on protected rings. Passing nil disables the policy.
+func (s *RingSupervisor) SetPolicyEngine(policy PolicyEngine) {
+	s.settingsLock.Lock()
+	defer s.settingsLock.Unlock()
+
+	s.policy = policy
+}
+
+// SetMaintenanceChecker configures where the maintenance holding the pending
+// releases of rings is looked up. Passing nil disables the check.
+func (s *RingSupervisor) SetMaintenanceChecker(checker MaintenanceChecker) {
+	s.settingsLock.Lock()
+	defer s.settingsLock.Unlock()
+
+	s.maintenanceChecker = checker
+}
+
+// SetFailureIssueCreator configures where the issues tracking the repeated
+// release failures of rings are opened, and when. Passing nil disables them.
+func (s *RingSupervisor) SetFailureIssueCreator(creator FailureIssueCreator, settings model.FailureIssueSettings) {
+	s.settingsLock.Lock()
+	defer s.settingsLock.Unlock()
+
+	s.failureIssues = creator
+	s.failureIssueConfig = settings
+}
+
+// SetSoakTimeDefaults changes the soak time defaults of the releases
+// requested from now on.
+func (s *RingSupervisor) SetSoakTimeDefaults(soakTimeDefaults model.SoakTimeDefaults) {
+	s.settingsLock.Lock()
+	defer s.settingsLock.Unlock()
+
+	s.soakTimeDefaults = soakTimeDefaults
 }
 
 // Shutdown performs graceful shutdown tasks for the ring supervisor.
@@ -70,15 +237,45 @@ func (s *RingSupervisor) Shutdown() {
 	s.logger.Debug("Shutting down ring supervisor")
 }
 
-// Do looks for work to be done on any pending rings and attempts to schedule the required work.
+// Do looks for work to be done on any pending rings and attempts to schedule
+// the required work, working on the rings in parallel.
 func (s *RingSupervisor) Do() error {
-	rings, err := s.store.GetUnlockedRingsPendingWork()
+	rings, contended, err := s.store.LockRingsPendingWork(s.instanceID, s.lockBatchSize)
 	if err != nil {
-		s.logger.WithError(err).Warn("Failed to query for rings pending work")
-		return nil
+		s.logger.WithError(err).Warn("Failed to lock rings pending work")
+		return err
 	}
+	s.metrics.ObserveLockAcquisitions(model.TypeRing, len(rings), contended)
 
+	items := make([]ringQueueItem, 0, len(rings))
+	var ringsReleasePending []*model.Ring
 	for _, ring := range rings {
+		ring := ring
+		// Pending rings evaluate the other rings under lock as release
+		// blockers, so they are not kept locked alongside the rest of the
+		// batch, which would have them block each other, and are evaluated
+		// one at a time once the batch is done instead.
+		if ring.State == model.RingStateReleasePending || ring.State == model.RingStateReleaseBlocked {
+			newRingLock(ring.ID, s.instanceID, s.store, s.logger.WithField("ring", ring.ID)).Unlock()
+			ringsReleasePending = append(ringsReleasePending, ring)
+			continue
+		}
+		items = append(items, ringQueueItem{
+			ringID:   ring.ID,
+			ringName: ring.Name,
+			priority: ring.State == model.RingStateReleaseRollbackRequested,
+			do: func(bypassed []string) {
+				logger := s.logger.WithFields(log.Fields{
+					"ring": ring.ID,
+				})
+				s.superviseSafely(ring, bypassed, logger)
+				newRingLock(ring.ID, s.instanceID, s.store, logger).Unlock()
+			},
+		})
+	}
+	runRingQueues(model.TypeRing, items, s.semaphore, s.metrics)
+
+	for _, ring := range ringsReleasePending {
 		s.Supervise(ring)
 	}
 
@@ -97,6 +294,22 @@ func (s *RingSupervisor) Supervise(ring *model.Ring) {
 	}
 	defer lock.Unlock()
 
+	s.superviseSafely(ring, nil, logger)
+}
+
+// superviseSafely supervises the given ring, which the caller must have
+// locked, failing it should its supervision panic.
+func (s *RingSupervisor) superviseSafely(ring *model.Ring, bypassed []string, logger log.FieldLogger) {
+	superviseRecovering(model.TypeRing, s.metrics, logger, func() {
+		s.supervise(ring, bypassed, logger)
+	}, func(cause string) {
+		s.failPanickedRing(ring.ID, cause, logger)
+	})
+}
+
+// supervise schedules the required work on the given ring, which the caller
+// must have locked, recording the limits its rollback bypassed, if any.
+func (s *RingSupervisor) supervise(ring *model.Ring, bypassed []string, logger log.FieldLogger) {
 	// Before working on the ring, it is crucial that we ensure that it was
 	// not updated to a new state by another elrond server.
 	originalState := ring.State
@@ -111,6 +324,14 @@ func (s *RingSupervisor) Supervise(ring *model.Ring) {
 			Warn("Another provisioner has worked on this ring; skipping...")
 		return
 	}
+	if !ring.SupportedStateMachine() {
+		logger.WithField("stateMachineVersion", ring.StateMachineVersion).
+			Warn("Ring follows a state machine version unknown to this server; skipping...")
+		return
+	}
+	if !s.authorizeTransition(ring, logger) {
+		return
+	}
 
 	logger.Debugf("Supervising ring in state %s", ring.State)
 
@@ -132,20 +353,57 @@ func (s *RingSupervisor) Supervise(ring *model.Ring) {
 	if oldState == model.RingStateReleaseInProgress && (newState == model.RingStateSoakingRequested || newState == model.RingStateStable) {
 		ring.ReleaseAt = time.Now().UnixNano()
 	}
+	if newState == model.RingStateReleaseRequested && oldState != model.RingStateReleaseRequested {
+		ring.ReleaseStartAt = time.Now().UnixNano()
+	}
+	if oldState == model.RingStateReleaseBlocked {
+		logger.Infof("Ring release is no longer blocked by ring %s", ring.BlockedBy)
+		ring.BlockedBy = ""
+		ring.BlockedReason = ""
+	}
+
+	s.observeTransition(ring, oldState, newState)
+
+	if ring.UpgradeStateMachine() {
+		logger.Infof("Upgrading ring to state machine version %d", ring.StateMachineVersion)
+	}
 
 	if err = s.store.UpdateRing(ring); err != nil {
 		logger.WithError(err).Warnf("failed to set ring state to %s", newState)
 		return
 	}
 
+	event := model.NewStateChangeEvent(model.TypeRing, ring.ID, ring, oldState, newState)
+	if len(bypassed) > 0 {
+		logger.Warnf("Rolled back the ring bypassing %s", strings.Join(bypassed, ", "))
+		event.Bypassed = strings.Join(bypassed, ",")
+	}
+	if oldState == model.RingStateReleaseInProgress && newState == ring.ReleaseDeadlineState() && ring.ReleaseDeadlineExceeded(time.Now()) {
+		event.Error = ring.ReleaseDeadlineError()
+	}
+	if err = s.store.CreateStateChangeEvent(event); err != nil {
+		logger.WithError(err).Warn("failed to record ring state change event")
+	}
+
+	if !ring.HasReleaseInProgress() {
+		s.applyInstallationGroupChanges(ring, logger)
+	}
+
+	if newState == model.RingStateStable && (oldState == model.RingStateReleaseInProgress || oldState == model.RingStateSoakingRequested) {
+		s.archiveReleaseEvidence(ring, logger)
+	}
+
 	//Move pending rings to release-failed as soon as an ring release fails
-	if newState == model.RingStateReleaseFailed || newState == model.RingStateSoakingFailed {
+	//A release failing to get prepared never started, and leaves them be.
+	//Rehearsal rings only fail rehearsal rings, and the other way around.
+	if (newState == model.RingStateReleaseFailed && oldState != model.RingStateReleasePrepareRequested) || newState == model.RingStateSoakingFailed {
 		logger.Info("Ring release has failed, moving pending rings to failed state")
 		rings, err := s.store.GetRingsPendingWork()
 		if err != nil {
 			logger.WithError(err).Error("failed to get all rings pending work")
 			return
 		}
+		rings = model.RehearsalRings(rings, ring.Rehearsal)
 		for _, ring := range rings {
 			ring.State = model.RingStateReleaseFailed
 		}
@@ -162,27 +420,284 @@ func (s *RingSupervisor) Supervise(ring *model.Ring) {
 		NewState:  newState,
 		OldState:  oldState,
 		Timestamp: time.Now().UnixNano(),
+		ExtraData: releaseImpactExtraData(ring, newState),
+		Labels:    ring.Annotations,
+		Metadata:  ring.Metadata,
 	}
+	s.annotateReleaseType(webhookPayload, ring, logger)
+	annotateRingContacts(webhookPayload, ring)
+	annotateRingBlocker(webhookPayload, ring)
+	annotateRehearsal(webhookPayload, ring)
 	if err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
 		logger.WithError(err).Error("Unable to process and send webhooks")
 	}
 
+	s.notify(ring, oldState, newState, logger)
+	s.trackRelease(ring, oldState, newState, logger)
+	s.trackRepeatedFailures(ring, oldState, newState, logger)
+
 	logger.Debugf("Transitioned ring from %s to %s", oldState, newState)
 }
 
+// authorizeTransition evaluates the authorization policy, if any, before
+// working on the given ring in its state, when the ring is protected. A ring
+// the policy denies, or cannot be evaluated for, is left in its state and
+// tried again on the next run.
+func (s *RingSupervisor) authorizeTransition(ring *model.Ring, logger log.FieldLogger) bool {
+	s.settingsLock.RLock()
+	policy := s.policy
+	s.settingsLock.RUnlock()
+
+	if policy == nil || !ring.Protected {
+		return true
+	}
+
+	decision, err := policy.Evaluate(&model.PolicyInput{
+		Action:     model.PolicyActionTransition,
+		Time:       time.Now().UTC().Format(time.RFC3339),
+		Ring:       model.NewPolicyRing(ring),
+		State:      ring.State,
+		InstanceID: s.instanceID,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to evaluate the authorization policy; leaving the protected ring in its state")
+		return false
+	}
+	if !decision.Allow {
+		logger.WithField("reason", decision.Reason).Warnf("Authorization policy denied work on the protected ring in state %s", ring.State)
+		return false
+	}
+
+	return true
+}
+
+// notify sends the release notification of the given ring transition, if any,
+// to every notifier. Failures are logged, but never affect the ring.
+func (s *RingSupervisor) notify(ring *model.Ring, oldState, newState string, logger log.FieldLogger) {
+	s.settingsLock.RLock()
+	notifiers := s.notifiers
+	s.settingsLock.RUnlock()
+
+	event := model.ReleaseNotificationEvent(oldState, newState)
+	if event == "" || len(notifiers) == 0 {
+		return
+	}
+
+	release, err := s.store.GetRingRelease(ring.DesiredReleaseID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get the ring release to notify")
+	}
+
+	notification := &model.ReleaseNotification{
+		Event:     event,
+		Ring:      ring,
+		Release:   release,
+		OldState:  oldState,
+		NewState:  newState,
+		Timestamp: time.Now().UnixNano(),
+	}
+	for _, notifier := range notifiers {
+		if err = notifier.Notify(notification); err != nil {
+			logger.WithError(err).Errorf("Failed to send %s notification", event)
+		}
+	}
+}
+
+// trackRelease updates the issue tracking the release of the ring after the
+// given transition, recording the issue on the ring. Failures are logged, but
+// never affect the ring.
+func (s *RingSupervisor) trackRelease(ring *model.Ring, oldState, newState string, logger log.FieldLogger) {
+	s.settingsLock.RLock()
+	issueTracker := s.issueTracker
+	s.settingsLock.RUnlock()
+
+	if issueTracker == nil || ring.JiraProject == "" {
+		return
+	}
+
+	release, err := s.store.GetRingRelease(ring.DesiredReleaseID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get the ring release to track")
+	}
+	installationGroups, err := s.store.GetInstallationGroupsForRing(ring.ID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get the ring installation groups to track")
+	}
+
+	issueKey, err := issueTracker.TrackRelease(ring, release, installationGroups, oldState, newState)
+	if err != nil {
+		logger.WithError(err).Error("Failed to track the ring release")
+	}
+	if issueKey == ring.JiraIssueKey {
+		return
+	}
+
+	ring.JiraIssueKey = issueKey
+	if err = s.store.UpdateRing(ring); err != nil {
+		logger.WithError(err).Errorf("Failed to record release issue %s", issueKey)
+		return
+	}
+	logger.Infof("Tracking release in issue %s", issueKey)
+}
+
+// trackRepeatedFailures opens the issue tracking the repeated release failures
+// of the ring once it failed enough releases within the configured window,
+// recording the issue on the ring, and forgets the issue once a release of the
+// ring completes. Failures are logged, but never affect the ring.
+func (s *RingSupervisor) trackRepeatedFailures(ring *model.Ring, oldState, newState string, logger log.FieldLogger) {
+	s.settingsLock.RLock()
+	creator := s.failureIssues
+	settings := s.failureIssueConfig
+	s.settingsLock.RUnlock()
+
+	if newState == model.RingStateStable && (oldState == model.RingStateReleaseInProgress || oldState == model.RingStateSoakingRequested) {
+		if ring.FailureIssueURL == "" {
+			return
+		}
+		logger.Infof("Release completed, no longer tracking repeated failures in %s", ring.FailureIssueURL)
+		ring.FailureIssueURL = ""
+		ring.FailureIssueAt = 0
+		if err := s.store.UpdateRing(ring); err != nil {
+			logger.WithError(err).Error("Failed to clear the failure issue of the ring")
+		}
+		return
+	}
+
+	if creator == nil || settings.Threshold <= 0 || ring.FailureIssueURL != "" || !model.IsRingFailureState(newState) {
+		return
+	}
+
+	events, err := s.store.GetStateChangeEvents(&model.StateChangeEventFilter{RingID: ring.ID, PerPage: model.MaxStateChangeEventsPerQuery, Latest: true})
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the ring events to count release failures")
+		return
+	}
+	failures := model.NewRepeatedFailures(ring, events, time.Duration(settings.Window)*time.Second, model.GetMillis())
+	if len(failures.Failures) < settings.Threshold {
+		return
+	}
+	if settings.PublicURL != "" {
+		failures.EventsURL = model.RingEventsURL(settings.PublicURL, ring.ID)
+	}
+
+	issueURL, err := creator.CreateFailureIssue(failures)
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to open an issue for %d release failures", len(failures.Failures))
+		return
+	}
+
+	ring.FailureIssueURL = issueURL
+	ring.FailureIssueAt = time.Now().UnixNano()
+	if err = s.store.UpdateRing(ring); err != nil {
+		logger.WithError(err).Errorf("Failed to record failure issue %s", issueURL)
+		return
+	}
+	logger.Warnf("Ring failed %d releases, tracking them in %s", len(failures.Failures), issueURL)
+}
+
+// archiveReleaseEvidence uploads the evidence of the release the ring just
+// completed. Failures are logged, but never affect the ring.
+func (s *RingSupervisor) archiveReleaseEvidence(ring *model.Ring, logger log.FieldLogger) {
+	s.settingsLock.RLock()
+	evidenceArchiver := s.evidenceArchiver
+	s.settingsLock.RUnlock()
+
+	if evidenceArchiver == nil {
+		return
+	}
+
+	release, err := s.store.GetRingRelease(ring.ActiveReleaseID)
+	if err != nil || release == nil {
+		logger.WithError(err).Error("Failed to get the completed release to archive its evidence")
+		return
+	}
+	installationGroups, err := s.store.GetInstallationGroupsForRing(ring.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the ring installation groups to archive the release evidence")
+		return
+	}
+	events, err := s.store.GetStateChangeEvents(&model.StateChangeEventFilter{
+		RingID:    ring.ID,
+		ReleaseID: release.ID,
+		PerPage:   model.MaxStateChangeEventsPerQuery,
+		Latest:    true,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the ring events to archive the release evidence")
+		return
+	}
+
+	healthSnapshots, err := s.store.GetHealthSnapshots(&model.HealthSnapshotFilter{ReleaseID: release.ID, RingID: ring.ID, PerPage: model.MaxHealthSnapshotsPerQuery, Latest: true})
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the installation group health snapshots to archive the release evidence")
+		return
+	}
+
+	evidence := model.NewReleaseEvidence(ring, release, installationGroups, events, model.GetMillis())
+	evidence.Health = model.BuildHealthSnapshotDiffs(healthSnapshots)
+	key, err := evidenceArchiver.Archive(evidence)
+	if err != nil {
+		logger.WithError(err).Error("Failed to archive release evidence")
+		return
+	}
+
+	logger.Infof("Archived evidence of release %s as %s", release.ID, key)
+}
+
+// observeTransition records the release start, release duration and soak
+// duration metrics for the given ring transition.
+func (s *RingSupervisor) observeTransition(ring *model.Ring, oldState, newState string) {
+	now := time.Now().UnixNano()
+
+	var releaseStart time.Time
+	if (newState == model.RingStateReleaseRequested || newState == model.RingStateReleaseInProgress) && ring.ReleaseStartAt != 0 {
+		releaseStart = time.Unix(0, ring.ReleaseStartAt)
+	}
+	s.metrics.SetRingReleaseStart(ring.Name, releaseStart)
+
+	switch oldState {
+	case model.RingStateReleaseRequested, model.RingStateReleaseInProgress:
+		if ring.ReleaseStartAt == 0 {
+			return
+		}
+		outcome := metrics.OutcomeSuccess
+		if newState == model.RingStateReleaseFailed {
+			outcome = metrics.OutcomeFailure
+		} else if newState != model.RingStateSoakingRequested && newState != model.RingStateStable {
+			return
+		}
+		s.metrics.ObserveRingReleaseDuration(ring.Name, outcome, ring.DesiredReleaseID, time.Duration(now-ring.ReleaseStartAt))
+	case model.RingStateSoakingRequested:
+		if ring.ReleaseAt == 0 {
+			return
+		}
+		outcome := metrics.OutcomeSuccess
+		if newState == model.RingStateSoakingFailed {
+			outcome = metrics.OutcomeFailure
+		} else if newState != model.RingStateStable {
+			return
+		}
+		s.metrics.ObserveRingSoakDuration(ring.Name, outcome, ring.DesiredReleaseID, time.Duration(now-ring.ReleaseAt))
+	}
+}
+
 // Do works with the given ring to transition it to a final state.
 func (s *RingSupervisor) transitionRing(ring *model.Ring, logger log.FieldLogger) string {
 	switch ring.State {
 	case model.RingStateCreationRequested:
 		return s.createRing(ring, logger)
-	case model.RingStateReleasePending:
+	case model.RingStateReleasePending, model.RingStateReleaseBlocked:
 		return s.checkRingReleasePending(ring, logger)
+	case model.RingStateReleasePrepareRequested:
+		return s.prepareRelease(ring, logger)
 	case model.RingStateReleaseRequested:
 		return s.releaseRing(ring, logger)
 	case model.RingStateReleaseInProgress:
 		return s.checkReleaseProgress(ring, logger)
 	case model.RingStateSoakingRequested:
 		return s.soakRing(ring, logger)
+	case model.RingStateDeletionPending:
+		return s.checkRingDeletionPending(ring, logger)
 	case model.RingStateDeletionRequested:
 		return s.deleteRing(ring, logger)
 	case model.RingStateReleaseRollbackRequested:
@@ -193,17 +708,49 @@ func (s *RingSupervisor) transitionRing(ring *model.Ring, logger log.FieldLogger
 	}
 }
 
+// createRing registers the installation groups of the ring with the
+// provisioner before creating the ring itself. Every step is idempotent and
+// the ring only leaves the creation-requested state once all of them have
+// succeeded, so a creation interrupted by a restart is simply run again.
 func (s *RingSupervisor) createRing(ring *model.Ring, logger log.FieldLogger) string {
 	var err error
 
-	if s.provisioner.PrepareRing(ring) {
+	if s.provisionerFor(ring).PrepareRing(ring) {
 		if err = s.store.UpdateRing(ring); err != nil {
 			logger.WithError(err).Error("Failed to record updated ring after creation")
 			return model.RingStateCreationFailed
 		}
 	}
 
-	if err = s.provisioner.CreateRing(ring); err != nil {
+	installationGroups, err := s.store.GetInstallationGroupsForRing(ring.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get installation groups for ring")
+		return model.RingStateCreationRequested
+	}
+
+	for _, installationGroup := range installationGroups {
+		annotated := *installationGroup
+		annotated.Annotations = model.MergeAnnotations(ring.Annotations, installationGroup.Annotations)
+
+		// The provisioner may be briefly unavailable, so the registration is
+		// retried on the next tick rather than failing the ring.
+		if err = s.provisionerFor(ring).RegisterInstallationGroup(&annotated); err != nil {
+			logger.WithError(err).Errorf("Failed to register installation group %s", installationGroup.ID)
+			return model.RingStateCreationRequested
+		}
+
+		// Installation groups may be shared between rings, so only seed the
+		// state of the ones that were never initialized.
+		if installationGroup.State == "" {
+			installationGroup.State = model.InstallationGroupStable
+			if err = s.store.UpdateInstallationGroup(installationGroup); err != nil {
+				logger.WithError(err).Errorf("Failed to seed state of installation group %s", installationGroup.ID)
+				return model.RingStateCreationRequested
+			}
+		}
+	}
+
+	if err = s.provisionerFor(ring).CreateRing(ring); err != nil {
 		logger.WithError(err).Error("Failed to create ring")
 		return model.RingStateCreationFailed
 	}
@@ -213,7 +760,16 @@ func (s *RingSupervisor) createRing(ring *model.Ring, logger log.FieldLogger) st
 }
 
 func (s *RingSupervisor) releaseRing(ring *model.Ring, logger log.FieldLogger) string {
-	err := s.provisioner.ReleaseRing(ring)
+	// Once applied to a ring, the content of a release must match its
+	// checksum for later audits.
+	if ring.DesiredReleaseID != "" {
+		if err := s.store.MarkRingReleaseImmutable(ring.DesiredReleaseID); err != nil {
+			logger.WithError(err).Error("Failed to mark ring release immutable")
+			return model.RingStateReleaseFailed
+		}
+	}
+
+	err := s.provisionerFor(ring).ReleaseRing(ring)
 	if err != nil {
 		logger.WithError(err).Error("Failed to release ring")
 		return model.RingStateReleaseFailed
@@ -234,6 +790,15 @@ func (s *RingSupervisor) releaseRing(ring *model.Ring, logger log.FieldLogger) s
 }
 
 func (s *RingSupervisor) checkRingReleasePending(ring *model.Ring, logger log.FieldLogger) string {
+	// Changes queued during a release that ended without the supervisor,
+	// such as a cancelled one, are applied before the next release.
+	s.applyInstallationGroupChanges(ring, logger)
+
+	if err := s.checkMaintenanceConflicts(ring, logger); err != nil {
+		logger.WithError(err).Error("Failed to check maintenance conflicts")
+		return model.RingStateReleaseFailed
+	}
+
 	logger.Debug("Checking if other Rings are locked...")
 
 	ringsLocked, err := s.store.GetRingsLocked()
@@ -248,12 +813,6 @@ func (s *RingSupervisor) checkRingReleasePending(ring *model.Ring, logger log.Fi
 		return model.RingStateReleaseFailed
 	}
 
-	//The total rings locked at this time will be at least 1
-	if len(ringsLocked) > 1 || len(ringsReleaseInProgress) > 0 {
-		logger.Debug("Another ring is under lock and being updated...")
-		return model.InstallationGroupReleasePending
-	}
-
 	logger.Debugf("Checking ring %s prioritization", ring.ID)
 	rings, err := s.store.GetUnlockedRingsPendingWork()
 	if err != nil {
@@ -261,11 +820,32 @@ func (s *RingSupervisor) checkRingReleasePending(ring *model.Ring, logger log.Fi
 		return model.RingStateReleaseFailed
 	}
 
-	for _, rg := range rings {
-		if rg.Priority < ring.Priority {
-			logger.Debugf("Ring %s is in priority", rg.ID)
-			return model.RingStateReleasePending
+	allRings, err := s.store.GetRings(&model.RingFilter{PerPage: model.AllPerPage})
+	if err != nil {
+		logger.WithError(err).Error("Failed to get rings for dependency check")
+		return model.RingStateReleaseFailed
+	}
+
+	blockers := model.RingReleaseBlockers(ring, allRings, ringsLocked, ringsReleaseInProgress, rings, time.Now())
+	if len(blockers) > 0 {
+		for _, blocker := range blockers {
+			logger.Debugf("Ring release blocked: %s", blocker.Message)
+		}
+		// Rings waiting for another ring are blocked until it is done,
+		// unless their state machine version predates the blocked state.
+		if blocker := model.RingBlocker(blockers); blocker != nil && ring.ValidTransitionState(model.RingStateReleaseBlocked) {
+			if ring.BlockedBy != blocker.RingID || ring.BlockedReason != blocker.Message {
+				logger.Infof("Ring release is blocked: %s", blocker.Message)
+				ring.BlockedBy = blocker.RingID
+				ring.BlockedReason = blocker.Message
+				if err = s.store.UpdateRing(ring); err != nil {
+					logger.WithError(err).Error("Failed to record the ring blocking the release")
+					return ring.State
+				}
+			}
+			return model.RingStateReleaseBlocked
 		}
+		return model.RingStateReleasePending
 	}
 
 	installationGroups, err := s.store.GetInstallationGroupsForRing(ring.ID)
@@ -274,6 +854,25 @@ func (s *RingSupervisor) checkRingReleasePending(ring *model.Ring, logger log.Fi
 		return model.RingStateReleaseFailed
 	}
 
+	// Soak times are resolved once, when the release is requested, so that
+	// later changes to the defaults do not affect a release in progress.
+	release, err := s.store.GetRingRelease(ring.DesiredReleaseID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the desired ring release")
+		return model.RingStateReleaseFailed
+	}
+
+	s.settingsLock.RLock()
+	soakTimeDefaults := s.soakTimeDefaults
+	s.settingsLock.RUnlock()
+
+	ring.ReleaseSoakTime = soakTimeDefaults.ResolveRingSoakTime(ring, release)
+	ring.ReleaseScheduledAt = 0
+	if err = s.store.UpdateRing(ring); err != nil {
+		logger.WithError(err).Error("Failed to record the ring release soak time")
+		return model.RingStateReleaseFailed
+	}
+
 	for _, ig := range installationGroups {
 		newInstallationGroupState := model.InstallationGroupReleasePending
 
@@ -287,16 +886,312 @@ func (s *RingSupervisor) checkRingReleasePending(ring *model.Ring, logger log.Fi
 		logger.Infof("Setting Installation group %s to %s state", ig.Name, newInstallationGroupState)
 
 		ig.State = model.InstallationGroupReleasePending
+		ig.ReleaseSoakTime = soakTimeDefaults.ResolveInstallationGroupSoakTime(ig, ring, release)
 		if err = s.store.UpdateInstallationGroup(ig); err != nil {
 			logger.WithError(err).Error("failed to update installation group")
 			return model.RingStateReleaseFailed
 		}
 	}
 
+	s.annotateReleaseImpact(ring, installationGroups, logger)
+
 	return model.RingStateReleaseRequested
 }
 
+// checkMaintenanceConflicts records on the pending ring the infrastructure
+// maintenance overlapping its release, from now until its estimated
+// completion, which holds the release as a release blocker until the
+// maintenance is over. Maintenance that cannot be checked holds the release
+// too.
+func (s *RingSupervisor) checkMaintenanceConflicts(ring *model.Ring, logger log.FieldLogger) error {
+	s.settingsLock.RLock()
+	checker := s.maintenanceChecker
+	s.settingsLock.RUnlock()
+
+	var maintenanceConflict string
+	if checker != nil {
+		installationGroups, err := s.store.GetInstallationGroupsForRing(ring.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get installation groups for ring")
+		}
+		events, err := s.store.GetStateChangeEvents(&model.StateChangeEventFilter{RingID: ring.ID, PerPage: model.MaxStateChangeEventsPerQuery, Latest: true})
+		if err != nil {
+			return errors.Wrap(err, "failed to get ring state change events")
+		}
+
+		now := model.GetMillis()
+		completionAt := model.EstimateReleaseCompletion(ring, installationGroups, events, now)
+		if completionAt < now {
+			completionAt = now
+		}
+		conflicts, err := checker.Conflicts(model.NewMaintenanceConflictQuery(ring, installationGroups, now, completionAt))
+		if err != nil {
+			logger.WithError(err).Warn("Failed to look up maintenance conflicts, holding the release")
+			maintenanceConflict = "maintenance conflicts could not be checked"
+		} else if len(conflicts) > 0 {
+			maintenanceConflict = model.DescribeMaintenanceConflicts(conflicts)
+		}
+	}
+
+	if maintenanceConflict == ring.MaintenanceConflict {
+		return nil
+	}
+	if maintenanceConflict != "" {
+		logger.Infof("Ring release is held: %s", maintenanceConflict)
+	} else {
+		logger.Info("Ring release is no longer held by maintenance")
+	}
+	ring.MaintenanceConflict = maintenanceConflict
+
+	return s.store.UpdateRing(ring)
+}
+
+// prepareRelease gets the ring and its installation groups ready for the
+// desired release without starting it: soak times are resolved and the
+// provisioner pre-pulls the release, so that committing the release later
+// only has to flip states.
+func (s *RingSupervisor) prepareRelease(ring *model.Ring, logger log.FieldLogger) string {
+	s.applyInstallationGroupChanges(ring, logger)
+
+	installationGroups, err := s.store.GetInstallationGroupsForRing(ring.ID)
+	if err != nil {
+		logger.WithError(err).Error("failed to get installation groups for ring")
+		return model.RingStateReleaseFailed
+	}
+
+	release, err := s.store.GetRingRelease(ring.DesiredReleaseID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the desired ring release")
+		return model.RingStateReleaseFailed
+	}
+
+	for _, ig := range installationGroups {
+		if !ig.ValidInstallationGroupTransitionState(model.InstallationGroupReleasePending) {
+			logger.Warnf("Unable to release installation group %s while in state %s", ig.Name, ig.State)
+			return model.RingStateReleaseFailed
+		}
+	}
+
+	if err = s.provisionerFor(ring).PrepareRelease(ring, release, installationGroups); err != nil {
+		logger.WithError(err).Error("Failed to prepare ring release")
+		return model.RingStateReleaseFailed
+	}
+
+	s.settingsLock.RLock()
+	soakTimeDefaults := s.soakTimeDefaults
+	s.settingsLock.RUnlock()
+
+	ring.ReleaseSoakTime = soakTimeDefaults.ResolveRingSoakTime(ring, release)
+	if err = s.store.UpdateRing(ring); err != nil {
+		logger.WithError(err).Error("Failed to record the ring release soak time")
+		return model.RingStateReleaseFailed
+	}
+
+	for _, ig := range installationGroups {
+		ig.ReleaseSoakTime = soakTimeDefaults.ResolveInstallationGroupSoakTime(ig, ring, release)
+		if err = s.store.UpdateInstallationGroup(ig); err != nil {
+			logger.WithError(err).Error("failed to update installation group")
+			return model.RingStateReleaseFailed
+		}
+	}
+
+	s.annotateReleaseImpact(ring, installationGroups, logger)
+
+	logger.Infof("Finished preparing the release of ring %s", ring.ID)
+	return model.RingStateReleasePrepared
+}
+
+// applyInstallationGroupChanges applies the installation group changes
+// queued while the ring was releasing. Failures are logged and the changes
+// are retried on the next release.
+func (s *RingSupervisor) applyInstallationGroupChanges(ring *model.Ring, logger log.FieldLogger) {
+	changes, err := s.store.GetRingInstallationGroupChanges(ring.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get queued installation group changes")
+		return
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	if err = s.store.ApplyRingInstallationGroupChanges(changes, nil); err != nil {
+		logger.WithError(err).Error("Failed to apply queued installation group changes")
+		return
+	}
+
+	logger.Infof("Applied %d installation group changes queued during the release", len(changes))
+}
+
+// joinInstallationGroups registers the installation groups queued for the
+// ring and releases them with the release in progress. Installation groups
+// that cannot start a release, such as ones releasing with another ring,
+// stay queued until the release ends.
+func (s *RingSupervisor) joinInstallationGroups(ring *model.Ring, logger log.FieldLogger) {
+	changes, err := s.store.GetRingInstallationGroupChanges(ring.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get queued installation group changes")
+		return
+	}
+
+	var joining []*model.RingInstallationGroupChange
+	var installationGroups []*model.InstallationGroup
+	for _, change := range changes {
+		if change.Action != model.RingInstallationGroupRegister {
+			continue
+		}
+		installationGroup, err := s.store.GetInstallationGroupByID(change.InstallationGroupID)
+		if err != nil {
+			logger.WithError(err).Errorf("Failed to get installation group %s joining the release", change.InstallationGroupID)
+			return
+		}
+		if installationGroup == nil || !installationGroup.ValidInstallationGroupTransitionState(model.InstallationGroupReleasePending) {
+			continue
+		}
+		joining = append(joining, change)
+		installationGroups = append(installationGroups, installationGroup)
+	}
+	if len(joining) == 0 {
+		return
+	}
+
+	release, err := s.store.GetRingRelease(ring.DesiredReleaseID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the desired ring release")
+		return
+	}
+
+	s.settingsLock.RLock()
+	soakTimeDefaults := s.soakTimeDefaults
+	s.settingsLock.RUnlock()
+
+	for _, installationGroup := range installationGroups {
+		installationGroup.State = model.InstallationGroupReleasePending
+		installationGroup.ReleaseSoakTime = soakTimeDefaults.ResolveInstallationGroupSoakTime(installationGroup, ring, release)
+	}
+
+	if err = s.store.ApplyRingInstallationGroupChanges(joining, installationGroups); err != nil {
+		logger.WithError(err).Error("Failed to register installation groups joining the release")
+		return
+	}
+
+	for _, installationGroup := range installationGroups {
+		logger.Infof("Installation group %s joined the release in progress", installationGroup.Name)
+	}
+}
+
+// annotateReleaseImpact records on the ring the installations and customers
+// affected by its upcoming release. The impact is informational, so failing
+// to compute it does not block the release: the capacity recorded on the
+// installation groups is used instead.
+func (s *RingSupervisor) annotateReleaseImpact(ring *model.Ring, installationGroups []*model.InstallationGroup, logger log.FieldLogger) {
+	impact, err := s.provisionerFor(ring).GetReleaseImpact(installationGroups)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get the release impact from the provisioner, using the recorded installation group capacity")
+		impact = model.RecordedReleaseImpact(installationGroups)
+	}
+
+	logger.Infof("Ring release will affect %d installations of %d customers", impact.Installations, impact.Customers)
+
+	ring.ReleaseImpactInstallations = impact.Installations
+	ring.ReleaseImpactCustomers = impact.Customers
+	if err = s.store.UpdateRing(ring); err != nil {
+		logger.WithError(err).Warn("Failed to record the ring release impact")
+	}
+}
+
+// releaseImpactExtraData returns the release impact of the ring to include in
+// the webhooks of the release start and failures.
+func releaseImpactExtraData(ring *model.Ring, newState string) map[string]string {
+	switch newState {
+	case model.RingStateReleaseRequested, model.RingStateReleaseFailed, model.RingStateSoakingFailed:
+	default:
+		return nil
+	}
+
+	return map[string]string{
+		"ReleaseImpactInstallations": strconv.FormatInt(ring.ReleaseImpactInstallations, 10),
+		"ReleaseImpactCustomers":     strconv.FormatInt(ring.ReleaseImpactCustomers, 10),
+	}
+}
+
+// annotateReleaseType adds the type of the desired release of the ring to
+// the given webhook payload, so that hotfixes stand out to their receivers,
+// along with its links by type, as Link.<type>, so that the change can be
+// traced back to its pull requests, builds, incidents and change tickets.
+func (s *RingSupervisor) annotateReleaseType(payload *model.WebhookPayload, ring *model.Ring, logger log.FieldLogger) {
+	if ring.DesiredReleaseID == "" {
+		return
+	}
+
+	release, err := s.store.GetRingRelease(ring.DesiredReleaseID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to get the desired release type for the webhook")
+		return
+	}
+	if release == nil {
+		return
+	}
+
+	if payload.ExtraData == nil {
+		payload.ExtraData = make(map[string]string)
+	}
+	if release.Type != "" {
+		payload.ExtraData["ReleaseType"] = release.Type
+	}
+	for linkType, urls := range release.Links.ByType() {
+		payload.ExtraData["Link."+linkType] = strings.Join(urls, " ")
+	}
+	if len(payload.ExtraData) == 0 {
+		payload.ExtraData = nil
+	}
+}
+
+// annotateRingContacts adds the contacts of the ring to the webhooks of
+// release failures, so that alerts reach the team owning the ring.
+func annotateRingContacts(payload *model.WebhookPayload, ring *model.Ring) {
+	if payload.NewState != model.RingStateReleaseFailed && payload.NewState != model.RingStateSoakingFailed {
+		return
+	}
+
+	contacts := map[string]string{
+		"OwnerTeam":        ring.OwnerTeam,
+		"SlackChannel":     ring.SlackChannel,
+		"EscalationPolicy": ring.EscalationPolicy,
+	}
+	for name, value := range contacts {
+		if value == "" {
+			continue
+		}
+		if payload.ExtraData == nil {
+			payload.ExtraData = make(map[string]string)
+		}
+		payload.ExtraData[name] = value
+	}
+}
+
+// annotateRingBlocker adds the ring blocking the release of the given ring,
+// and why, to the given webhook payload of a ring moving to
+// RingStateReleaseBlocked.
+func annotateRingBlocker(payload *model.WebhookPayload, ring *model.Ring) {
+	if payload.NewState != model.RingStateReleaseBlocked || ring.BlockedBy == "" {
+		return
+	}
+	if payload.ExtraData == nil {
+		payload.ExtraData = make(map[string]string)
+	}
+	payload.ExtraData["BlockedBy"] = ring.BlockedBy
+	payload.ExtraData["BlockedReason"] = ring.BlockedReason
+}
+
 func (s *RingSupervisor) checkReleaseProgress(ring *model.Ring, logger log.FieldLogger) string {
+	if ring.ReleaseDeadlineExceeded(time.Now()) {
+		logger.Warnf("Ring release has run for longer than its max release duration of %d seconds, applying the %s failure policy", ring.MaxReleaseDuration, ring.CurrentFailurePolicy())
+		return ring.ReleaseDeadlineState()
+	}
+
+	if ring.CurrentInstallationGroupPolicy() == model.InstallationGroupPolicyJoin {
+		s.joinInstallationGroups(ring, logger)
+	}
 
 	installationGroups, err := s.store.GetRingInstallationGroupsPendingWork(ring.ID)
 	if err != nil {
@@ -308,6 +1203,20 @@ func (s *RingSupervisor) checkReleaseProgress(ring *model.Ring, logger log.Field
 		return model.RingStateReleaseInProgress
 	}
 
+	// Failure policies carrying on with the release despite failed
+	// installation groups fail the ring once the others are done.
+	installationGroups, err = s.store.GetInstallationGroupsForRing(ring.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get ring installation groups")
+		return model.RingStateReleaseInProgress
+	}
+	for _, installationGroup := range installationGroups {
+		if installationGroup.HasFailedRelease() {
+			logger.Warnf("Installation group %s release ended in state %s, failing the ring release", installationGroup.ID, installationGroup.State)
+			return model.RingStateReleaseFailed
+		}
+	}
+
 	logger.Infof("Finished releasing ring %s", ring.ID)
 
 	release, err := s.store.GetRingRelease(ring.DesiredReleaseID)
@@ -320,7 +1229,10 @@ func (s *RingSupervisor) checkReleaseProgress(ring *model.Ring, logger log.Field
 		logger.Info("This is a forced release. Skipping ring soaking time...")
 		logger.Infof("Ring %s release is now complete. Setting active release ID and moving ring to stable.", ring.ID)
 
-		ring.ActiveReleaseID = ring.DesiredReleaseID
+		if err = s.activateDesiredRelease(ring, logger); err != nil {
+			logger.WithError(err).Error("Failed to snapshot the previous active release")
+			return model.RingStateReleaseFailed
+		}
 
 		if err = s.store.UpdateRing(ring); err != nil {
 			logger.WithError(err).Error("Failed to record updated ring version and image")
@@ -333,13 +1245,13 @@ func (s *RingSupervisor) checkReleaseProgress(ring *model.Ring, logger log.Field
 
 func (s *RingSupervisor) soakRing(ring *model.Ring, logger log.FieldLogger) string {
 
-	timePassed := ((time.Now().UnixNano() - ring.ReleaseAt) / int64(time.Second))
-	if timePassed < int64(ring.SoakTime) {
-		logger.Infof("Ring %s will be soaking for another %d seconds...", ring.ID, int64(ring.SoakTime)-timePassed)
+	timePassed := int64(ring.SoakElapsed(ring.SoakWindows.ActiveDuration(time.Unix(0, ring.ReleaseAt), time.Now())) / time.Second)
+	if timePassed < int64(ring.CurrentSoakTime()) {
+		logger.Infof("Ring %s will be soaking for another %d seconds...", ring.ID, int64(ring.CurrentSoakTime())-timePassed)
 		return model.RingStateSoakingRequested
 	}
 
-	err := s.provisioner.SoakRing(ring)
+	err := s.provisionerFor(ring).SoakRing(ring)
 	if err != nil {
 		logger.WithError(err).Error("Failed to soak ring")
 		return model.RingStateSoakingFailed
@@ -348,7 +1260,10 @@ func (s *RingSupervisor) soakRing(ring *model.Ring, logger log.FieldLogger) stri
 	logger.Infof("Finished soaking ring %s", ring.ID)
 	logger.Infof("Ring %s release is now complete. Setting active release ID and moving ring to stable.", ring.ID)
 
-	ring.ActiveReleaseID = ring.DesiredReleaseID
+	if err = s.activateDesiredRelease(ring, logger); err != nil {
+		logger.WithError(err).Error("Failed to snapshot the previous active release")
+		return model.RingStateSoakingFailed
+	}
 
 	if err = s.store.UpdateRing(ring); err != nil {
 		logger.WithError(err).Error("Failed to record updated ring version and image")
@@ -357,8 +1272,66 @@ func (s *RingSupervisor) soakRing(ring *model.Ring, logger log.FieldLogger) stri
 	return model.RingStateStable
 }
 
+// activateDesiredRelease makes the desired release of the ring its active
+// release, first recording an immutable snapshot of the release it replaces
+// as the ring rollback target. The caller is responsible for persisting the ring.
+func (s *RingSupervisor) activateDesiredRelease(ring *model.Ring, logger log.FieldLogger) error {
+	if ring.ActiveReleaseID != "" && ring.ActiveReleaseID != ring.DesiredReleaseID {
+		previousRelease, err := s.store.GetRingRelease(ring.ActiveReleaseID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the previous active release")
+		}
+		if previousRelease == nil {
+			logger.Warnf("Previous active release %s no longer exists; keeping the existing rollback snapshot", ring.ActiveReleaseID)
+		} else {
+			snapshot, err := s.store.CreateRingReleaseSnapshot(ring.ID, previousRelease)
+			if err != nil {
+				return errors.Wrap(err, "failed to create rollback snapshot")
+			}
+			logger.Infof("Recorded rollback snapshot %s of release %s", snapshot.ID, previousRelease.ID)
+			ring.RollbackSnapshotID = snapshot.ID
+		}
+	}
+
+	ring.ActiveReleaseID = ring.DesiredReleaseID
+
+	return nil
+}
+
+// rollbackRing rolls back the installation groups of the ring that failed to
+// release its desired release or completed it, waiting until every one of
+// them is rolled back before rolling back the ring itself.
 func (s *RingSupervisor) rollbackRing(ring *model.Ring, logger log.FieldLogger) string {
-	err := s.provisioner.RollBackRing(ring)
+	installationGroups, err := s.store.GetInstallationGroupsForRing(ring.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the ring installation groups")
+		return model.RingStateReleaseRollbackRequested
+	}
+
+	for _, installationGroup := range installationGroups {
+		if installationGroup.NeedsRollback(ring) {
+			s.requestInstallationGroupRollback(installationGroup, ring, logger)
+		}
+	}
+
+	pending, err := s.store.GetRingInstallationGroupsPendingWork(ring.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the ring installation groups pending work")
+		return model.RingStateReleaseRollbackRequested
+	}
+	if len(pending) > 0 {
+		logger.Infof("Waiting for %d installation groups to roll back", len(pending))
+		return model.RingStateReleaseRollbackRequested
+	}
+
+	for _, installationGroup := range installationGroups {
+		if installationGroup.State == model.InstallationGroupReleaseRollbackFailed {
+			logger.Errorf("Installation group %s failed to roll back", installationGroup.ID)
+			return model.RingStateReleaseRollbackFailed
+		}
+	}
+
+	err = s.provisionerFor(ring).RollBackRing(ring)
 	if err != nil {
 		logger.WithError(err).Error("Failed to rollback ring")
 		return model.RingStateReleaseRollbackFailed
@@ -368,8 +1341,53 @@ func (s *RingSupervisor) rollbackRing(ring *model.Ring, logger log.FieldLogger)
 	return model.RingStateReleaseRollbackComplete
 }
 
+// requestInstallationGroupRollback moves the given installation group of the
+// ring to release-rollback-requested, for the installation group supervisor
+// to roll it back, recording the state change and sending webhooks.
+func (s *RingSupervisor) requestInstallationGroupRollback(installationGroup *model.InstallationGroup, ring *model.Ring, logger log.FieldLogger) {
+	oldState := installationGroup.State
+	installationGroup.State = model.InstallationGroupReleaseRollbackRequested
+	if err := s.store.UpdateInstallationGroup(installationGroup); err != nil {
+		logger.WithError(err).Errorf("Failed to request the rollback of installation group %s", installationGroup.ID)
+		return
+	}
+	logger.Infof("Requested the rollback of installation group %s", installationGroup.ID)
+
+	event := model.NewStateChangeEvent(model.TypeInstallationGroup, installationGroup.ID, ring, oldState, installationGroup.State)
+	event.Metadata = installationGroup.Metadata
+	if err := s.store.CreateStateChangeEvent(event); err != nil {
+		logger.WithError(err).Warn("failed to record installation group state change event")
+	}
+
+	webhookPayload := &model.WebhookPayload{
+		Type:         model.TypeRing,
+		ID:           installationGroup.ID,
+		NewState:     installationGroup.State,
+		OldState:     oldState,
+		Timestamp:    time.Now().UnixNano(),
+		RingID:       ring.ID,
+		Labels:       ring.Annotations,
+		Metadata:     installationGroup.Metadata,
+		RingMetadata: ring.Metadata,
+	}
+	if err := webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
+
+func (s *RingSupervisor) checkRingDeletionPending(ring *model.Ring, logger log.FieldLogger) string {
+	remaining := time.Duration(ring.DeletionScheduledAt - time.Now().UnixNano())
+	if remaining > 0 {
+		logger.Debugf("Ring %s will be deleted in %s unless the deletion is cancelled", ring.ID, remaining.Round(time.Second))
+		return model.RingStateDeletionPending
+	}
+
+	logger.Infof("Ring %s deletion grace period is over", ring.ID)
+	return model.RingStateDeletionRequested
+}
+
 func (s *RingSupervisor) deleteRing(ring *model.Ring, logger log.FieldLogger) string {
-	err := s.provisioner.DeleteRing(ring)
+	err := s.provisionerFor(ring).DeleteRing(ring)
 	if err != nil {
 		logger.WithError(err).Error("Failed to delete ring")
 		return model.RingStateDeletionFailed
@@ -8,6 +8,10 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// DefaultLockBatchSize is the default number of rings, or installation
+// groups, pending work a supervisor locks at once on each run.
+const DefaultLockBatchSize = 10
+
 type ringLockStore interface {
 	LockRing(ringID, lockerID string) (bool, error)
 	UnlockRing(ringID, lockerID string, force bool) (bool, error)
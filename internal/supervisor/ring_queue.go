@@ -0,0 +1,69 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/elrond/internal/metrics"
+	"github.com/mattermost/elrond/model"
+)
+
+// ringQueueItem is a unit of work of a supervisor pass on a ring.
+type ringQueueItem struct {
+	ringID   string
+	ringName string
+	// priority items, such as rollbacks, do not wait for the semaphore.
+	priority bool
+	// do does the work, given the limits it bypassed, if any, for auditing.
+	do func(bypassed []string)
+}
+
+// runRingQueues queues the given work by ring and runs each queue in its own
+// goroutine: the work of a ring is done sequentially, in the given order,
+// while the rings proceed in parallel, each unit of work holding the
+// semaphore, except for the priority ones. It returns once all the work is
+// done.
+func runRingQueues(resource string, items []ringQueueItem, semaphore Semaphore, metrics *metrics.Metrics) {
+	var ringIDs []string
+	queues := make(map[string][]ringQueueItem)
+	for _, item := range items {
+		if _, ok := queues[item.ringID]; !ok {
+			ringIDs = append(ringIDs, item.ringID)
+		}
+		queues[item.ringID] = append(queues[item.ringID], item)
+		metrics.AddSupervisorQueueDepth(resource, item.ringName, 1)
+	}
+
+	queuedAt := time.Now()
+
+	var wg sync.WaitGroup
+	for _, ringID := range ringIDs {
+		wg.Add(1)
+		go func(queue []ringQueueItem) {
+			defer wg.Done()
+			for _, item := range queue {
+				if item.priority {
+					var bypassed []string
+					if semaphore.Full() {
+						bypassed = append(bypassed, model.BypassSupervisorParallelism)
+					}
+					metrics.ObserveSupervisorQueueWait(resource, item.ringName, time.Since(queuedAt))
+					item.do(bypassed)
+					metrics.AddSupervisorQueueDepth(resource, item.ringName, -1)
+					continue
+				}
+
+				semaphore.Acquire()
+				metrics.ObserveSupervisorQueueWait(resource, item.ringName, time.Since(queuedAt))
+				item.do(nil)
+				semaphore.Release()
+				metrics.AddSupervisorQueueDepth(resource, item.ringName, -1)
+			}
+		}(queues[ringID])
+	}
+	wg.Wait()
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/metrics"
+	"github.com/mattermost/elrond/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunRingQueues(t *testing.T) {
+	prometheusMetrics := metrics.NewPrometheus()
+	m := metrics.New([]string{"ring1", "ring2"}, prometheusMetrics)
+
+	var lock sync.Mutex
+	var done []string
+	var active, maxActive int
+	item := func(ringID, name string) ringQueueItem {
+		return ringQueueItem{
+			ringID:   ringID,
+			ringName: ringID,
+			do: func(bypassed []string) {
+				lock.Lock()
+				active++
+				if active > maxActive {
+					maxActive = active
+				}
+				lock.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				lock.Lock()
+				active--
+				done = append(done, name)
+				lock.Unlock()
+			},
+		}
+	}
+
+	t.Run("rings in parallel, installation groups in order", func(t *testing.T) {
+		runRingQueues("installationgroup", []ringQueueItem{
+			item("ring1", "ig1"),
+			item("ring2", "ig2"),
+			item("ring1", "ig3"),
+			item("ring2", "ig4"),
+		}, NewSemaphore(2), m)
+
+		require.Len(t, done, 4)
+		require.Equal(t, 2, maxActive)
+		require.Less(t, indexOf(done, "ig1"), indexOf(done, "ig3"))
+		require.Less(t, indexOf(done, "ig2"), indexOf(done, "ig4"))
+
+		require.Zero(t, testutil.ToFloat64(prometheusMetrics.SupervisorQueueDepth.WithLabelValues("installationgroup", "ring1")))
+		require.Equal(t, 2, testutil.CollectAndCount(prometheusMetrics.SupervisorQueueWait))
+	})
+
+	t.Run("bounded by the semaphore", func(t *testing.T) {
+		done = nil
+		maxActive = 0
+		runRingQueues("ring", []ringQueueItem{
+			item("ring1", "ring1"),
+			item("ring2", "ring2"),
+			item("ring3", "ring3"),
+		}, NewSemaphore(1), nil)
+
+		require.Len(t, done, 3)
+		require.Equal(t, 1, maxActive)
+	})
+
+	t.Run("priority work does not wait for the semaphore", func(t *testing.T) {
+		semaphore := NewSemaphore(1)
+		semaphore.Acquire()
+		defer semaphore.Release()
+
+		var bypassed []string
+		runRingQueues("ring", []ringQueueItem{{
+			ringID:   "ring1",
+			priority: true,
+			do: func(b []string) {
+				bypassed = b
+			},
+		}}, semaphore, nil)
+
+		require.Equal(t, []string{model.BypassSupervisorParallelism}, bypassed)
+	})
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -5,12 +5,17 @@
 package supervisor_test
 
 import (
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/mattermost/elrond/internal/metrics"
 	"github.com/mattermost/elrond/internal/store"
 	"github.com/mattermost/elrond/internal/supervisor"
 	"github.com/mattermost/elrond/internal/testlib"
 	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -31,6 +36,10 @@ func (s *mockRingStore) GetUnlockedRingsPendingWork() ([]*model.Ring, error) {
 	return s.UnlockedRingsPendingWork, nil
 }
 
+func (s *mockRingStore) LockRingsPendingWork(lockerID string, limit int) ([]*model.Ring, int, error) {
+	return s.UnlockedRingsPendingWork, 0, nil
+}
+
 func (s *mockRingStore) GetRings(RingFilter *model.RingFilter) ([]*model.Ring, error) {
 	return s.Rings, nil
 }
@@ -96,7 +105,45 @@ func (s *mockRingStore) GetRingRelease(releaseID string) (*model.RingRelease, er
 	return nil, nil
 }
 
-type mockRingProvisioner struct{}
+func (s *mockRingStore) MarkRingReleaseImmutable(releaseID string) error {
+	return nil
+}
+
+func (s *mockRingStore) CreateRingReleaseSnapshot(ringID string, ringRelease *model.RingRelease) (*model.RingReleaseSnapshot, error) {
+	return &model.RingReleaseSnapshot{ID: model.NewID(), RingID: ringID, ReleaseID: ringRelease.ID}, nil
+}
+
+func (s *mockRingStore) CreateStateChangeEvent(event *model.StateChangeEvent) error {
+	return nil
+}
+
+func (s *mockRingStore) GetStateChangeEvents(filter *model.StateChangeEventFilter) ([]*model.StateChangeEvent, error) {
+	return nil, nil
+}
+
+func (s *mockRingStore) GetInstallationGroupByID(id string) (*model.InstallationGroup, error) {
+	return nil, nil
+}
+
+func (s *mockRingStore) GetRingInstallationGroupChanges(ringID string) ([]*model.RingInstallationGroupChange, error) {
+	return nil, nil
+}
+
+func (s *mockRingStore) ApplyRingInstallationGroupChanges(changes []*model.RingInstallationGroupChange, installationGroups []*model.InstallationGroup) error {
+	return nil
+}
+
+func (s *mockRingStore) GetHealthSnapshots(filter *model.HealthSnapshotFilter) ([]*model.HealthSnapshot, error) {
+	return nil, nil
+}
+
+type mockRingProvisioner struct {
+	RegisterInstallationGroupError error
+	RegisteredInstallationGroups   []*model.InstallationGroup
+
+	PrepareReleaseError error
+	PreparedReleases    []*model.RingRelease
+}
 
 func (p *mockRingProvisioner) PrepareRing(Ring *model.Ring) bool {
 	return true
@@ -110,6 +157,11 @@ func (p *mockRingProvisioner) ReleaseRing(Ring *model.Ring) error {
 	return nil
 }
 
+func (p *mockRingProvisioner) PrepareRelease(ring *model.Ring, release *model.RingRelease, installationGroups []*model.InstallationGroup) error {
+	p.PreparedReleases = append(p.PreparedReleases, release)
+	return p.PrepareReleaseError
+}
+
 func (p *mockRingProvisioner) SoakRing(Ring *model.Ring) error {
 	return nil
 }
@@ -122,12 +174,79 @@ func (p *mockRingProvisioner) DeleteRing(Ring *model.Ring) error {
 	return nil
 }
 
+func (p *mockRingProvisioner) GetReleaseImpact(installationGroups []*model.InstallationGroup) (*model.ReleaseImpact, error) {
+	return &model.ReleaseImpact{Installations: int64(len(installationGroups)), Customers: 1}, nil
+}
+
+func (p *mockRingProvisioner) RegisterInstallationGroup(installationGroup *model.InstallationGroup) error {
+	p.RegisteredInstallationGroups = append(p.RegisteredInstallationGroups, installationGroup)
+	return p.RegisterInstallationGroupError
+}
+
+type mockEvidenceArchiver struct {
+	Archived []*model.ReleaseEvidence
+}
+
+func (a *mockEvidenceArchiver) Archive(evidence *model.ReleaseEvidence) (string, error) {
+	a.Archived = append(a.Archived, evidence)
+	return evidence.Ring.ID, nil
+}
+
+type mockNotifier struct {
+	Notifications []*model.ReleaseNotification
+}
+
+func (n *mockNotifier) Notify(notification *model.ReleaseNotification) error {
+	n.Notifications = append(n.Notifications, notification)
+	return nil
+}
+
+type mockIssueTracker struct {
+	Tracked []string
+}
+
+func (t *mockIssueTracker) TrackRelease(ring *model.Ring, release *model.RingRelease, installationGroups []*model.InstallationGroup, oldState, newState string) (string, error) {
+	t.Tracked = append(t.Tracked, oldState+" "+newState)
+	return ring.JiraProject + "-1", nil
+}
+
+// overlappingMaintenanceChecker holds each pending ring in its maintenance
+// check until the given number of rings are being checked, or for a while,
+// so that rings evaluated in parallel overlap.
+type overlappingMaintenanceChecker struct {
+	Rings int
+
+	lock    sync.Mutex
+	checked int
+	all     chan struct{}
+}
+
+func (c *overlappingMaintenanceChecker) Conflicts(query *model.MaintenanceConflictQuery) ([]*model.MaintenanceConflict, error) {
+	c.lock.Lock()
+	if c.all == nil {
+		c.all = make(chan struct{})
+	}
+	all := c.all
+	c.checked++
+	if c.checked == c.Rings {
+		close(all)
+	}
+	c.lock.Unlock()
+
+	select {
+	case <-all:
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	return nil, nil
+}
+
 func TestRingSupervisorDo(t *testing.T) {
 	t.Run("no Rings pending work", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		mockStore := &mockRingStore{}
 
-		supervisor := supervisor.NewRingSupervisor(mockStore, &mockRingProvisioner{}, "instanceID", logger)
+		supervisor := supervisor.NewRingSupervisor(mockStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
 		err := supervisor.Do()
 		require.NoError(t, err)
 
@@ -145,13 +264,70 @@ func TestRingSupervisorDo(t *testing.T) {
 		mockStore.Ring = mockStore.UnlockedRingsPendingWork[0]
 		mockStore.UnlockChan = make(chan interface{})
 
-		supervisor := supervisor.NewRingSupervisor(mockStore, &mockRingProvisioner{}, "instanceID", logger)
+		supervisor := supervisor.NewRingSupervisor(mockStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
 		err := supervisor.Do()
 		require.NoError(t, err)
 
 		<-mockStore.UnlockChan
 		require.Equal(t, 2, mockStore.UpdateRingCalls)
 	})
+
+	t.Run("batch of rings locked by other servers", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		defer store.CloseConnection(t, sqlStore)
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, sqlStore.CreateRing(&model.Ring{State: model.RingStateReleasePending}, nil))
+		}
+		rings, _, err := sqlStore.LockRingsPendingWork("otherInstanceID", 1)
+		require.NoError(t, err)
+		require.Len(t, rings, 1)
+
+		prometheusMetrics := metrics.NewPrometheus()
+		elrondMetrics := metrics.New(nil, prometheusMetrics)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, elrondMetrics, model.SoakTimeDefaults{})
+		supervisor.SetLockBatchSize(1)
+		require.NoError(t, supervisor.Do())
+
+		require.Equal(t, float64(1), testutil.ToFloat64(prometheusMetrics.LockAcquisitions.WithLabelValues(model.TypeRing, metrics.LockAcquired)))
+		require.Equal(t, float64(1), testutil.ToFloat64(prometheusMetrics.LockAcquisitions.WithLabelValues(model.TypeRing, metrics.LockContended)))
+
+		lockedRings, err := sqlStore.GetRingsLocked()
+		require.NoError(t, err)
+		require.Len(t, lockedRings, 1)
+		require.Equal(t, rings[0].ID, lockedRings[0].ID)
+	})
+
+	t.Run("batch of pending rings", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		defer store.CloseConnection(t, sqlStore)
+
+		release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+		ring1 := &model.Ring{Priority: 1, State: model.RingStateReleasePending, DesiredReleaseID: release.ID}
+		require.NoError(t, sqlStore.CreateRing(ring1, &model.InstallationGroup{Name: "group1", State: model.InstallationGroupStable}))
+		ring2 := &model.Ring{Priority: 2, State: model.RingStateReleasePending, DesiredReleaseID: release.ID}
+		require.NoError(t, sqlStore.CreateRing(ring2, &model.InstallationGroup{Name: "group2", State: model.InstallationGroupStable}))
+
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+		supervisor.SetMaintenanceChecker(&overlappingMaintenanceChecker{Rings: 2})
+		require.NoError(t, supervisor.Do())
+
+		// The rings locked in the same batch do not block each other: the
+		// ring released first by priority starts its release.
+		ring1, err = sqlStore.GetRing(ring1.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseRequested, ring1.State)
+		ring2, err = sqlStore.GetRing(ring2.ID)
+		require.NoError(t, err)
+		require.NotEqual(t, model.RingStateReleaseRequested, ring2.State)
+
+		lockedRings, err := sqlStore.GetRingsLocked()
+		require.NoError(t, err)
+		require.Empty(t, lockedRings)
+	})
 }
 
 func TestRingSupervisorSupervise(t *testing.T) {
@@ -163,6 +339,7 @@ func TestRingSupervisorSupervise(t *testing.T) {
 		{"unexpected state", model.RingStateStable, model.RingStateStable},
 		{"creation requested", model.RingStateCreationRequested, model.RingStateStable},
 		{"release pending", model.RingStateReleasePending, model.RingStateReleaseRequested},
+		{"release blocked", model.RingStateReleaseBlocked, model.RingStateReleaseRequested},
 		{"release requested", model.RingStateReleaseRequested, model.RingStateSoakingRequested},
 		{"soaking requested", model.RingStateSoakingRequested, model.RingStateStable},
 		{"rollback requested", model.RingStateReleaseRollbackRequested, model.RingStateReleaseRollbackComplete},
@@ -172,7 +349,7 @@ func TestRingSupervisorSupervise(t *testing.T) {
 		t.Run(tc.Description, func(t *testing.T) {
 			logger := testlib.MakeLogger(t)
 			sqlStore := store.MakeTestSQLStore(t, logger)
-			supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger)
+			supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
 
 			Ring := &model.Ring{
 				State:           tc.InitialState,
@@ -195,10 +372,669 @@ func TestRingSupervisorSupervise(t *testing.T) {
 		})
 	}
 
+	t.Run("release impact is recorded when the release is requested", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		Ring := &model.Ring{
+			State: model.RingStateReleasePending,
+		}
+		installationGroup := model.InstallationGroup{
+			Name:  "group1",
+			State: model.InstallationGroupStable,
+		}
+
+		err := sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseRequested, Ring.State)
+		require.Equal(t, int64(1), Ring.ReleaseImpactInstallations)
+		require.Equal(t, int64(1), Ring.ReleaseImpactCustomers)
+	})
+
+	t.Run("scheduled release waits for its schedule", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		Ring := &model.Ring{
+			State:              model.RingStateReleasePending,
+			ReleaseScheduledAt: time.Now().Add(time.Hour).UnixNano(),
+		}
+		installationGroup := model.InstallationGroup{
+			Name:  "group1",
+			State: model.InstallationGroupStable,
+		}
+
+		err := sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePending, Ring.State)
+
+		Ring.ReleaseScheduledAt = time.Now().Add(-time.Minute).UnixNano()
+		require.NoError(t, sqlStore.UpdateRing(Ring))
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseRequested, Ring.State)
+		require.Zero(t, Ring.ReleaseScheduledAt)
+	})
+
+	t.Run("release waiting for a dependency is blocked until it is done", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		upstream := &model.Ring{Name: "staging", State: model.RingStateReleaseFailed}
+		require.NoError(t, sqlStore.CreateRing(upstream, &model.InstallationGroup{Name: "group1", State: model.InstallationGroupStable}))
+		ring := &model.Ring{
+			Name:      "production",
+			State:     model.RingStateReleasePending,
+			DependsOn: model.RingDependencies{upstream.ID},
+		}
+		require.NoError(t, sqlStore.CreateRing(ring, &model.InstallationGroup{Name: "group2", State: model.InstallationGroupStable}))
+
+		supervisor.Supervise(ring)
+
+		ring, err := sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseBlocked, ring.State)
+		require.Equal(t, upstream.ID, ring.BlockedBy)
+		require.Equal(t, "ring production depends on ring staging, which is release-failed", ring.BlockedReason)
+
+		supervisor.Supervise(ring)
+
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseBlocked, ring.State)
+
+		upstream.State = model.RingStateStable
+		require.NoError(t, sqlStore.UpdateRing(upstream))
+
+		supervisor.Supervise(ring)
+
+		ring, err = sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseRequested, ring.State)
+		require.Empty(t, ring.BlockedBy)
+		require.Empty(t, ring.BlockedReason)
+
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{RingID: ring.ID, ResourceType: model.TypeRing, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		require.Equal(t, model.RingStateReleasePending, events[0].OldState)
+		require.Equal(t, model.RingStateReleaseBlocked, events[0].NewState)
+		require.Equal(t, model.RingStateReleaseBlocked, events[1].OldState)
+		require.Equal(t, model.RingStateReleaseRequested, events[1].NewState)
+	})
+
+	t.Run("rings of an older state machine version are not blocked", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		upstream := &model.Ring{Name: "staging", State: model.RingStateReleaseFailed}
+		require.NoError(t, sqlStore.CreateRing(upstream, &model.InstallationGroup{Name: "group1", State: model.InstallationGroupStable}))
+		ring := &model.Ring{
+			Name:                "production",
+			State:               model.RingStateReleasePending,
+			DependsOn:           model.RingDependencies{upstream.ID},
+			StateMachineVersion: 4,
+		}
+		require.NoError(t, sqlStore.CreateRing(ring, &model.InstallationGroup{Name: "group2", State: model.InstallationGroupStable}))
+
+		supervisor.Supervise(ring)
+
+		ring, err := sqlStore.GetRing(ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePending, ring.State)
+		require.Empty(t, ring.BlockedBy)
+	})
+
+	t.Run("soak times are resolved when the release is requested", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{Server: 3600, Environment: 1800})
+
+		release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+
+		Ring := &model.Ring{
+			State:            model.RingStateReleasePending,
+			DesiredReleaseID: release.ID,
+		}
+		installationGroup := model.InstallationGroup{
+			Name:     "group1",
+			State:    model.InstallationGroupStable,
+			SoakTime: 60,
+		}
+
+		err = sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseRequested, Ring.State)
+		require.Equal(t, 1800, Ring.ReleaseSoakTime)
+
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, 60, installationGroups[0].ReleaseSoakTime)
+	})
+
+	t.Run("release is prepared without starting it", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockRingProvisioner{}
+		supervisor := supervisor.NewRingSupervisor(sqlStore, provisioner, "instanceID", logger, nil, model.SoakTimeDefaults{Server: 3600})
+
+		release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+
+		Ring := &model.Ring{
+			State:            model.RingStateReleasePrepareRequested,
+			DesiredReleaseID: release.ID,
+		}
+		installationGroup := model.InstallationGroup{
+			Name:     "group1",
+			State:    model.InstallationGroupStable,
+			SoakTime: 60,
+		}
+		err = sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePrepared, Ring.State)
+		require.Equal(t, 3600, Ring.ReleaseSoakTime)
+		require.Len(t, provisioner.PreparedReleases, 1)
+		require.Equal(t, release.ID, provisioner.PreparedReleases[0].ID)
+
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationGroupStable, installationGroups[0].State)
+		require.Equal(t, 60, installationGroups[0].ReleaseSoakTime)
+	})
+
+	t.Run("release that fails to be prepared leaves the other rings be", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockRingProvisioner{PrepareReleaseError: errors.New("group rejected the release")}
+		supervisor := supervisor.NewRingSupervisor(sqlStore, provisioner, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+
+		Ring := &model.Ring{
+			State:            model.RingStateReleasePrepareRequested,
+			DesiredReleaseID: release.ID,
+		}
+		require.NoError(t, sqlStore.CreateRing(Ring, nil))
+		pending := &model.Ring{
+			Priority:           2,
+			State:              model.RingStateReleasePending,
+			ReleaseScheduledAt: time.Now().Add(time.Hour).UnixNano(),
+		}
+		require.NoError(t, sqlStore.CreateRing(pending, nil))
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseFailed, Ring.State)
+
+		pending, err = sqlStore.GetRing(pending.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleasePending, pending.State)
+	})
+
+	t.Run("release is made immutable once applied", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+		require.False(t, release.Immutable)
+
+		Ring := &model.Ring{
+			State:            model.RingStateReleaseRequested,
+			DesiredReleaseID: release.ID,
+		}
+		installationGroup := model.InstallationGroup{Name: "group1", State: model.InstallationGroupStable}
+		err = sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		supervisor.Supervise(Ring)
+
+		release, err = sqlStore.GetRingRelease(release.ID)
+		require.NoError(t, err)
+		require.True(t, release.Immutable)
+		require.True(t, release.VerifyChecksum())
+	})
+
+	t.Run("previous active release is snapshotted when the release completes", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		activeRelease, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "1.0.0"})
+		require.NoError(t, err)
+		desiredRelease, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+
+		Ring := &model.Ring{
+			State:            model.RingStateSoakingRequested,
+			ActiveReleaseID:  activeRelease.ID,
+			DesiredReleaseID: desiredRelease.ID,
+		}
+		installationGroup := model.InstallationGroup{Name: "group1"}
+
+		err = sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, Ring.State)
+		require.Equal(t, desiredRelease.ID, Ring.ActiveReleaseID)
+		require.NotEmpty(t, Ring.RollbackSnapshotID)
+
+		snapshot, err := sqlStore.GetRingReleaseSnapshot(Ring.RollbackSnapshotID)
+		require.NoError(t, err)
+		require.Equal(t, activeRelease.ID, snapshot.ReleaseID)
+		require.Equal(t, "1.0.0", snapshot.Version)
+	})
+
+	t.Run("installation group changes queued during the release are applied when it ends", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		desiredRelease, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+
+		Ring := &model.Ring{
+			State:            model.RingStateSoakingRequested,
+			DesiredReleaseID: desiredRelease.ID,
+		}
+		installationGroup := model.InstallationGroup{Name: "group1"}
+		err = sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		_, change, err := sqlStore.RegisterRingInstallationGroup(Ring.ID, &model.InstallationGroup{Name: "group2", State: model.InstallationGroupStable})
+		require.NoError(t, err)
+		require.NotNil(t, change)
+		change, err = sqlStore.DeregisterRingInstallationGroup(Ring.ID, installationGroup.ID)
+		require.NoError(t, err)
+		require.NotNil(t, change)
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, Ring.State)
+
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(Ring.ID)
+		require.NoError(t, err)
+		require.Len(t, installationGroups, 1)
+		require.Equal(t, "group2", installationGroups[0].Name)
+
+		changes, err := sqlStore.GetRingInstallationGroupChanges(Ring.ID)
+		require.NoError(t, err)
+		require.Empty(t, changes)
+	})
+
+	t.Run("installation groups join the release in progress", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{Server: 3600})
+
+		desiredRelease, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+
+		Ring := &model.Ring{
+			State:                   model.RingStateReleaseInProgress,
+			DesiredReleaseID:        desiredRelease.ID,
+			InstallationGroupPolicy: model.InstallationGroupPolicyJoin,
+		}
+		installationGroup := model.InstallationGroup{Name: "group1", State: model.InstallationGroupStable}
+		err = sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		joining, change, err := sqlStore.RegisterRingInstallationGroup(Ring.ID, &model.InstallationGroup{Name: "group2", State: model.InstallationGroupStable, SoakTime: 60})
+		require.NoError(t, err)
+		require.NotNil(t, change)
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseInProgress, Ring.State)
+
+		installationGroups, err := sqlStore.GetRingInstallationGroupsPendingWork(Ring.ID)
+		require.NoError(t, err)
+		require.Len(t, installationGroups, 1)
+		require.Equal(t, joining.ID, installationGroups[0].ID)
+		require.Equal(t, model.InstallationGroupReleasePending, installationGroups[0].State)
+		require.Equal(t, 60, installationGroups[0].ReleaseSoakTime)
+	})
+
+	t.Run("release running past its max release duration follows the failure policy", func(t *testing.T) {
+		for _, tc := range []struct {
+			FailurePolicy string
+			ExpectedState string
+		}{
+			{model.FailurePolicyHalt, model.RingStateReleaseFailed},
+			{model.FailurePolicyRollbackRing, model.RingStateReleaseRollbackRequested},
+		} {
+			t.Run(tc.FailurePolicy, func(t *testing.T) {
+				logger := testlib.MakeLogger(t)
+				sqlStore := store.MakeTestSQLStore(t, logger)
+				supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+				Ring := &model.Ring{
+					State:              model.RingStateReleaseInProgress,
+					FailurePolicy:      tc.FailurePolicy,
+					MaxReleaseDuration: 3600,
+					ReleaseStartAt:     time.Now().Add(-2 * time.Hour).UnixNano(),
+				}
+				installationGroup := model.InstallationGroup{Name: "group1", State: model.InstallationGroupReleasePending}
+				err := sqlStore.CreateRing(Ring, &installationGroup)
+				require.NoError(t, err)
+
+				supervisor.Supervise(Ring)
+
+				Ring, err = sqlStore.GetRing(Ring.ID)
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedState, Ring.State)
+
+				events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{
+					RingID:       Ring.ID,
+					ResourceType: model.TypeRing,
+					PerPage:      model.AllPerPage,
+				})
+				require.NoError(t, err)
+				require.Len(t, events, 1)
+				require.Equal(t, "release exceeded the max release duration of 1h0m0s", events[0].Error)
+			})
+		}
+	})
+
+	t.Run("release within its max release duration goes on", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		Ring := &model.Ring{
+			State:              model.RingStateReleaseInProgress,
+			MaxReleaseDuration: 3600,
+			ReleaseStartAt:     time.Now().Add(-30 * time.Minute).UnixNano(),
+		}
+		installationGroup := model.InstallationGroup{Name: "group1", State: model.InstallationGroupReleasePending}
+		err := sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateReleaseInProgress, Ring.State)
+	})
+
+	t.Run("release evidence is archived when the release completes", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		archiver := &mockEvidenceArchiver{}
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+		supervisor.SetEvidenceArchiver(archiver)
+
+		desiredRelease, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+
+		Ring := &model.Ring{
+			State:            model.RingStateSoakingRequested,
+			DesiredReleaseID: desiredRelease.ID,
+		}
+		installationGroup := model.InstallationGroup{Name: "group-evidence"}
+
+		err = sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+		pendingEvent := model.NewStateChangeEvent(model.TypeRing, Ring.ID, Ring, model.RingStateStable, model.RingStateReleasePending)
+		pendingEvent.Timestamp = model.GetMillis() - 1000
+		err = sqlStore.CreateStateChangeEvent(pendingEvent)
+		require.NoError(t, err)
+		for stage, updated := range map[string]int64{model.HealthSnapshotPreRelease: 0, model.HealthSnapshotPostSoak: 3} {
+			err = sqlStore.CreateHealthSnapshot(&model.HealthSnapshot{
+				InstallationGroupID:  installationGroup.ID,
+				RingID:               Ring.ID,
+				ReleaseID:            desiredRelease.ID,
+				Stage:                stage,
+				InstallationsTotal:   3,
+				InstallationsUpdated: updated,
+			})
+			require.NoError(t, err)
+		}
+
+		supervisor.Supervise(Ring)
+
+		require.Len(t, archiver.Archived, 1)
+		evidence := archiver.Archived[0]
+		require.Equal(t, desiredRelease.ID, evidence.Release.ID)
+		require.Equal(t, model.RingStateStable, evidence.Ring.State)
+		require.Len(t, evidence.InstallationGroups, 1)
+		require.Len(t, evidence.Events, 2)
+		require.Equal(t, model.RingStateStable, evidence.Timeline.FinalState)
+		require.Len(t, evidence.Health, 1)
+		require.Equal(t, map[string]int64{"InstallationsUpdated": 3}, evidence.Health[0].Changes)
+	})
+
+	t.Run("release completion is notified", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		notifier := &mockNotifier{}
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+		supervisor.AddNotifier(notifier)
+
+		desiredRelease, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+
+		Ring := &model.Ring{
+			State:              model.RingStateSoakingRequested,
+			DesiredReleaseID:   desiredRelease.ID,
+			NotificationEmails: model.NotificationEmails{"release-managers@example.com"},
+		}
+		installationGroup := model.InstallationGroup{Name: "group-notified"}
+
+		err = sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		supervisor.Supervise(Ring)
+
+		require.Len(t, notifier.Notifications, 1)
+		notification := notifier.Notifications[0]
+		require.Equal(t, model.NotificationReleaseCompleted, notification.Event)
+		require.Equal(t, desiredRelease.ID, notification.Release.ID)
+		require.Equal(t, Ring.NotificationEmails, notification.Ring.NotificationEmails)
+	})
+
+	t.Run("release is tracked in the ring jira project", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		tracker := &mockIssueTracker{}
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+		supervisor.SetIssueTracker(tracker)
+
+		desiredRelease, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "image", Version: "2.0.0"})
+		require.NoError(t, err)
+
+		trackedRing := &model.Ring{
+			State:            model.RingStateSoakingRequested,
+			DesiredReleaseID: desiredRelease.ID,
+			JiraProject:      "REL",
+		}
+		err = sqlStore.CreateRing(trackedRing, &model.InstallationGroup{Name: "group-tracked"})
+		require.NoError(t, err)
+
+		untrackedRing := &model.Ring{
+			State:            model.RingStateSoakingRequested,
+			DesiredReleaseID: desiredRelease.ID,
+		}
+		err = sqlStore.CreateRing(untrackedRing, &model.InstallationGroup{Name: "group-untracked"})
+		require.NoError(t, err)
+
+		supervisor.Supervise(trackedRing)
+		supervisor.Supervise(untrackedRing)
+
+		require.Equal(t, []string{model.RingStateSoakingRequested + " " + model.RingStateStable}, tracker.Tracked)
+
+		trackedRing, err = sqlStore.GetRing(trackedRing.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, trackedRing.State)
+		require.Equal(t, "REL-1", trackedRing.JiraIssueKey)
+	})
+
+	t.Run("creation registers and seeds installation groups", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockRingProvisioner{}
+		supervisor := supervisor.NewRingSupervisor(sqlStore, provisioner, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		Ring := &model.Ring{
+			State:       model.RingStateCreationRequested,
+			Annotations: model.Annotations{"team": "platform"},
+		}
+		installationGroup := model.InstallationGroup{Name: "group1", ProvisionerGroupID: "group-id"}
+
+		err := sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, Ring.State)
+
+		require.Len(t, provisioner.RegisteredInstallationGroups, 1)
+		require.Equal(t, "group-id", provisioner.RegisteredInstallationGroups[0].ProvisionerGroupID)
+		require.Equal(t, model.Annotations{"team": "platform"}, provisioner.RegisteredInstallationGroups[0].Annotations)
+
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationGroupStable, installationGroups[0].State)
+	})
+
+	t.Run("creation is retried when an installation group cannot be registered", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		provisioner := &mockRingProvisioner{RegisterInstallationGroupError: errors.New("provisioner unavailable")}
+		supervisor := supervisor.NewRingSupervisor(sqlStore, provisioner, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		Ring := &model.Ring{State: model.RingStateCreationRequested}
+		installationGroup := model.InstallationGroup{Name: "group1", ProvisionerGroupID: "group-id"}
+
+		err := sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateCreationRequested, Ring.State)
+
+		installationGroups, err := sqlStore.GetInstallationGroupsForRing(Ring.ID)
+		require.NoError(t, err)
+		require.Empty(t, installationGroups[0].State)
+
+		provisioner.RegisterInstallationGroupError = nil
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateStable, Ring.State)
+		require.Len(t, provisioner.RegisteredInstallationGroups, 2)
+
+		installationGroups, err = sqlStore.GetInstallationGroupsForRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.InstallationGroupStable, installationGroups[0].State)
+	})
+
+	t.Run("deletion pending", func(t *testing.T) {
+		testCases := []struct {
+			Description         string
+			DeletionScheduledAt int64
+			ExpectedState       string
+		}{
+			{"within the grace period", time.Now().Add(time.Hour).UnixNano(), model.RingStateDeletionPending},
+			{"after the grace period", time.Now().Add(-time.Second).UnixNano(), model.RingStateDeletionRequested},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.Description, func(t *testing.T) {
+				logger := testlib.MakeLogger(t)
+				sqlStore := store.MakeTestSQLStore(t, logger)
+				supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+				Ring := &model.Ring{
+					State:               model.RingStateDeletionPending,
+					DeletionScheduledAt: tc.DeletionScheduledAt,
+				}
+				installationGroup := model.InstallationGroup{Name: "group1"}
+
+				err := sqlStore.CreateRing(Ring, &installationGroup)
+				require.NoError(t, err)
+
+				supervisor.Supervise(Ring)
+
+				Ring, err = sqlStore.GetRing(Ring.ID)
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedState, Ring.State)
+			})
+		}
+	})
+
+	t.Run("rings of an unknown state machine version are left alone", func(t *testing.T) {
+		logger := testlib.MakeLogger(t)
+		sqlStore := store.MakeTestSQLStore(t, logger)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
+
+		Ring := &model.Ring{
+			State:               model.RingStateSoakingRequested,
+			StateMachineVersion: model.CurrentStateMachineVersion + 1,
+		}
+		installationGroup := model.InstallationGroup{Name: "group3"}
+
+		err := sqlStore.CreateRing(Ring, &installationGroup)
+		require.NoError(t, err)
+
+		supervisor.Supervise(Ring)
+
+		Ring, err = sqlStore.GetRing(Ring.ID)
+		require.NoError(t, err)
+		require.Equal(t, model.RingStateSoakingRequested, Ring.State)
+		require.Equal(t, model.CurrentStateMachineVersion+1, Ring.StateMachineVersion)
+	})
+
 	t.Run("state has changed since Ring was selected to be worked on", func(t *testing.T) {
 		logger := testlib.MakeLogger(t)
 		sqlStore := store.MakeTestSQLStore(t, logger)
-		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger)
+		supervisor := supervisor.NewRingSupervisor(sqlStore, &mockRingProvisioner{}, "instanceID", logger, nil, model.SoakTimeDefaults{})
 
 		Ring := &model.Ring{
 			State: model.RingStateDeletionRequested,
@@ -0,0 +1,335 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattermost/elrond/internal/webhook"
+	"github.com/mattermost/elrond/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// rolloutStore abstracts the database operations required to release rollouts.
+type rolloutStore interface {
+	GetRollout(rolloutID string) (*model.Rollout, error)
+	LockRolloutsPendingWork(lockerID string, limit int) ([]*model.Rollout, int, error)
+	UnlockRollout(rolloutID, lockerID string, force bool) (bool, error)
+	UpdateRollout(rollout *model.Rollout) error
+	GetRing(ringID string) (*model.Ring, error)
+	LockRings(rings []string, lockerID string) (bool, error)
+	UnlockRings(rings []string, lockerID string, force bool) (bool, error)
+	UpdateRings(rings []*model.Ring) error
+	GetRingRelease(releaseID string) (*model.RingRelease, error)
+	CreateStateChangeEvent(event *model.StateChangeEvent) error
+	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
+}
+
+// RolloutSupervisor finds rollouts in progress and releases their steps in
+// sequence, leaving the release of each ring to the ring supervisor.
+type RolloutSupervisor struct {
+	store      rolloutStore
+	instanceID string
+	logger     log.FieldLogger
+
+	lockBatchSize int
+	soakChecker   SoakChecker
+}
+
+// NewRolloutSupervisor creates a new RolloutSupervisor.
+func NewRolloutSupervisor(store rolloutStore, instanceID string, logger log.FieldLogger) *RolloutSupervisor {
+	return &RolloutSupervisor{
+		store:         store,
+		instanceID:    instanceID,
+		logger:        logger,
+		lockBatchSize: DefaultLockBatchSize,
+	}
+}
+
+// SetLockBatchSize changes the number of rollouts in progress locked at once
+// on each run.
+func (s *RolloutSupervisor) SetLockBatchSize(lockBatchSize int) {
+	s.lockBatchSize = lockBatchSize
+}
+
+// SetSoakChecker sets the checker evaluating the promotion checks of the
+// steps of rollouts. Without one, steps with promotion checks pause their
+// rollout instead of promoting it.
+func (s *RolloutSupervisor) SetSoakChecker(soakChecker SoakChecker) {
+	s.soakChecker = soakChecker
+}
+
+// Shutdown performs graceful shutdown tasks for the rollout supervisor.
+func (s *RolloutSupervisor) Shutdown() {
+	s.logger.Debug("Shutting down rollout supervisor")
+}
+
+// Do looks for rollouts in progress and moves them forward.
+func (s *RolloutSupervisor) Do() error {
+	rollouts, _, err := s.store.LockRolloutsPendingWork(s.instanceID, s.lockBatchSize)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to lock rollouts pending work")
+		return err
+	}
+
+	for _, rollout := range rollouts {
+		logger := s.logger.WithFields(log.Fields{
+			"rollout": rollout.ID,
+		})
+		s.supervise(rollout, logger)
+
+		unlocked, err := s.store.UnlockRollout(rollout.ID, s.instanceID, false)
+		if err != nil {
+			logger.WithError(err).Error("failed to unlock rollout")
+		} else if !unlocked {
+			logger.Error("failed to release lock for rollout")
+		}
+	}
+
+	return nil
+}
+
+// supervise moves the given rollout, which the caller must have locked,
+// through as many steps as possible.
+func (s *RolloutSupervisor) supervise(rollout *model.Rollout, logger log.FieldLogger) {
+	// The rollout may have been cancelled since it was locked.
+	rollout, err := s.store.GetRollout(rollout.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get refreshed rollout")
+		return
+	}
+	if rollout == nil {
+		return
+	}
+
+	for rollout.State == model.RolloutStateInProgress {
+		if rollout.StepStartAt == 0 && !s.startStep(rollout, logger) {
+			return
+		}
+		if rollout.State != model.RolloutStateInProgress || !s.checkStep(rollout, logger) {
+			return
+		}
+	}
+}
+
+// startStep requests the release of the rings of the current step of the
+// rollout, returning whether the step started. The step waits while any of
+// its rings is busy.
+func (s *RolloutSupervisor) startStep(rollout *model.Rollout, logger log.FieldLogger) bool {
+	step := rollout.Steps[rollout.CurrentStep]
+
+	locked, err := s.store.LockRings(step.RingIDs, s.instanceID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to lock the rings of the rollout step")
+		return false
+	} else if !locked {
+		logger.Debugf("Rings of step %d are locked; retrying later", rollout.CurrentStep)
+		return false
+	}
+	defer func() {
+		if _, err := s.store.UnlockRings(step.RingIDs, s.instanceID, false); err != nil {
+			logger.WithError(err).Error("Failed to unlock the rings of the rollout step")
+		}
+	}()
+
+	release, err := s.store.GetRingRelease(rollout.ReleaseID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the rollout release")
+		return false
+	}
+	if release == nil {
+		s.failRollout(rollout, fmt.Sprintf("release %s does not exist", rollout.ReleaseID), logger)
+		return true
+	}
+
+	var rings []*model.Ring
+	for _, ringID := range step.RingIDs {
+		ring, err := s.store.GetRing(ringID)
+		if err != nil {
+			logger.WithError(err).Errorf("Failed to get ring %s of the rollout step", ringID)
+			return false
+		}
+		if ring == nil || ring.DeleteAt != 0 {
+			s.failRollout(rollout, fmt.Sprintf("ring %s was deleted", ringID), logger)
+			return true
+		}
+		if rollout.RingReleased(ring) || ((ring.State == model.RingStateReleasePending || ring.State == model.RingStateReleaseBlocked) && ring.DesiredReleaseID == rollout.ReleaseID) {
+			continue
+		}
+		if ring.APISecurityLock {
+			s.failRollout(rollout, fmt.Sprintf("API changes are locked for ring %s", ring.Name), logger)
+			return true
+		}
+		if !ring.ValidTransitionState(model.RingStateReleasePending) {
+			logger.Debugf("Ring %s of step %d is %s; retrying later", ring.ID, rollout.CurrentStep, ring.State)
+			return false
+		}
+		rings = append(rings, ring)
+	}
+
+	oldStates := make(map[string]string, len(rings))
+	for _, ring := range rings {
+		oldStates[ring.ID] = ring.State
+		ring.State = model.RingStateReleasePending
+		ring.DesiredReleaseID = rollout.ReleaseID
+	}
+
+	if err = s.store.UpdateRings(rings); err != nil {
+		logger.WithError(err).Error("Failed to request the release of the rings of the rollout step")
+		return false
+	}
+
+	for _, ring := range rings {
+		if oldStates[ring.ID] == ring.State {
+			continue
+		}
+
+		if err = s.store.CreateStateChangeEvent(model.NewStateChangeEvent(model.TypeRing, ring.ID, ring, oldStates[ring.ID], ring.State)); err != nil {
+			logger.WithError(err).Warn("failed to record ring state change event")
+		}
+
+		webhookPayload := &model.WebhookPayload{
+			Type:      model.TypeRing,
+			ID:        ring.ID,
+			NewState:  ring.State,
+			OldState:  oldStates[ring.ID],
+			Timestamp: time.Now().UnixNano(),
+			ExtraData: map[string]string{"ReleaseType": release.Type, "Rollout": rollout.ID},
+			Labels:    ring.Annotations,
+			Metadata:  ring.Metadata,
+		}
+		if err = webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+			logger.WithError(err).Error("Unable to process and send webhooks")
+		}
+	}
+
+	rollout.StepStartAt = model.GetMillis()
+	if err = s.store.UpdateRollout(rollout); err != nil {
+		logger.WithError(err).Error("Failed to record the start of the rollout step")
+		return false
+	}
+
+	logger.Infof("Started step %d of rollout %s", rollout.CurrentStep, rollout.ID)
+	return true
+}
+
+// checkStep moves the rollout to its next step once every ring of the
+// current step released and its promotion gate, if any, passed, or fails it
+// once any of them will not release. It returns whether the rollout moved.
+func (s *RolloutSupervisor) checkStep(rollout *model.Rollout, logger log.FieldLogger) bool {
+	step := rollout.Steps[rollout.CurrentStep]
+	for _, ringID := range step.RingIDs {
+		ring, err := s.store.GetRing(ringID)
+		if err != nil {
+			logger.WithError(err).Errorf("Failed to get ring %s of the rollout step", ringID)
+			return false
+		}
+		if failure := rollout.RingFailure(ringID, ring); failure != "" {
+			s.failRollout(rollout, failure, logger)
+			return true
+		}
+		if !rollout.RingReleased(ring) {
+			return false
+		}
+	}
+
+	if step.HasPromotionGate() && rollout.CurrentStep < len(rollout.Steps)-1 && !s.checkPromotion(rollout, step, logger) {
+		return rollout.State != model.RolloutStateInProgress
+	}
+
+	rollout.CurrentStep++
+	rollout.StepStartAt = 0
+	rollout.StepReleasedAt = 0
+	if rollout.CurrentStep == len(rollout.Steps) {
+		rollout.State = model.RolloutStateComplete
+	}
+	if err := s.store.UpdateRollout(rollout); err != nil {
+		logger.WithError(err).Error("Failed to record the end of the rollout step")
+		return false
+	}
+
+	logger.Infof("Finished step %d of rollout %s", rollout.CurrentStep-1, rollout.ID)
+	if rollout.State == model.RolloutStateComplete {
+		logger.Infof("Finished rollout %s", rollout.ID)
+	}
+	return true
+}
+
+// checkPromotion evaluates the promotion checks of the released step of the
+// rollout, returning whether they held for the whole promotion window of
+// the step. The window starts once every ring of the step is released, and
+// the first breach pauses the rollout. Checks failing to be evaluated
+// neither pass nor breach, so that the promotion waits for them.
+func (s *RolloutSupervisor) checkPromotion(rollout *model.Rollout, step *model.RolloutStep, logger log.FieldLogger) bool {
+	if rollout.StepReleasedAt == 0 {
+		rollout.StepReleasedAt = model.GetMillis()
+		if err := s.store.UpdateRollout(rollout); err != nil {
+			logger.WithError(err).Error("Failed to record the release of the rollout step")
+			return false
+		}
+		logger.Infof("Step %d of rollout %s released; promotion window started", rollout.CurrentStep, rollout.ID)
+	}
+
+	passed := true
+	for _, check := range step.PromotionChecks {
+		if s.soakChecker == nil {
+			s.pauseRollout(rollout, step, fmt.Sprintf("promotion check %s cannot be evaluated: soak checks are not enabled on this server", check.Name), logger)
+			return false
+		}
+
+		value, err := s.soakChecker.Evaluate(check)
+		if err != nil {
+			logger.WithError(err).Warnf("Failed to evaluate promotion check %s", check.Name)
+			passed = false
+			continue
+		}
+		if !check.Passes(value) {
+			s.pauseRollout(rollout, step, fmt.Sprintf("promotion check %s breached: observed %g, expected %s %g", check.Name, value, check.Comparison, check.Threshold), logger)
+			return false
+		}
+	}
+
+	windowEnd := rollout.StepReleasedAt + step.PromotionWindow*int64(time.Second/time.Millisecond)
+	return passed && model.GetMillis() >= windowEnd
+}
+
+// pauseRollout stops the promotion of the rollout past the given step for
+// the given reason, and alerts the webhooks.
+func (s *RolloutSupervisor) pauseRollout(rollout *model.Rollout, step *model.RolloutStep, message string, logger log.FieldLogger) {
+	oldState := rollout.State
+	rollout.State = model.RolloutStatePaused
+	rollout.Message = message
+	if err := s.store.UpdateRollout(rollout); err != nil {
+		logger.WithError(err).Error("Failed to record the rollout pause")
+		return
+	}
+
+	logger.Warnf("Rollout %s paused: %s", rollout.ID, message)
+
+	webhookPayload := &model.WebhookPayload{
+		Type:      model.TypeRollout,
+		ID:        rollout.ID,
+		NewState:  rollout.State,
+		OldState:  oldState,
+		Timestamp: time.Now().UnixNano(),
+		ExtraData: map[string]string{"Step": step.Name, "Message": message},
+	}
+	if err := webhook.SendToAllWebhooks(s.store, webhookPayload, logger.WithField("webhookEvent", webhookPayload.NewState)); err != nil {
+		logger.WithError(err).Error("Unable to process and send webhooks")
+	}
+}
+
+// failRollout stops the rollout for the given reason.
+func (s *RolloutSupervisor) failRollout(rollout *model.Rollout, message string, logger log.FieldLogger) {
+	rollout.State = model.RolloutStateFailed
+	rollout.Message = message
+	if err := s.store.UpdateRollout(rollout); err != nil {
+		logger.WithError(err).Error("Failed to record the rollout failure")
+		return
+	}
+
+	logger.Warnf("Rollout %s failed: %s", rollout.ID, message)
+}
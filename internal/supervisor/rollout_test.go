@@ -0,0 +1,226 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolloutSupervisor(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	previous, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "9.5.0"})
+	require.NoError(t, err)
+	release, err := sqlStore.GetOrCreateRingRelease(&model.RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "9.5.1"})
+	require.NoError(t, err)
+
+	createRing := func(name string) *model.Ring {
+		ring := &model.Ring{
+			Name:             name,
+			State:            model.RingStateStable,
+			ActiveReleaseID:  previous.ID,
+			DesiredReleaseID: previous.ID,
+		}
+		require.NoError(t, sqlStore.CreateRing(ring, nil))
+		return ring
+	}
+	getRing := func(ringID string) *model.Ring {
+		ring, err := sqlStore.GetRing(ringID)
+		require.NoError(t, err)
+		return ring
+	}
+	setRing := func(ringID, state, activeReleaseID string) {
+		ring := getRing(ringID)
+		ring.State = state
+		ring.ActiveReleaseID = activeReleaseID
+		require.NoError(t, sqlStore.UpdateRing(ring))
+	}
+	getRollout := func(rolloutID string) *model.Rollout {
+		rollout, err := sqlStore.GetRollout(rolloutID)
+		require.NoError(t, err)
+		return rollout
+	}
+
+	supervisor := supervisor.NewRolloutSupervisor(sqlStore, "instance", logger)
+
+	t.Run("steps are released in sequence", func(t *testing.T) {
+		staging := createRing("staging")
+		production1 := createRing("production1")
+		production2 := createRing("production2")
+
+		rollout := &model.Rollout{
+			ReleaseID: release.ID,
+			Steps: model.RolloutSteps{
+				{Name: "staging", RingIDs: []string{staging.ID}},
+				{Name: "production", RingIDs: []string{production1.ID, production2.ID}},
+			},
+			State: model.RolloutStateInProgress,
+		}
+		require.NoError(t, sqlStore.CreateRollout(rollout))
+
+		require.NoError(t, supervisor.Do())
+		ring := getRing(staging.ID)
+		require.Equal(t, model.RingStateReleasePending, ring.State)
+		require.Equal(t, release.ID, ring.DesiredReleaseID)
+		require.Equal(t, model.RingStateStable, getRing(production1.ID).State)
+		require.NotZero(t, getRollout(rollout.ID).StepStartAt)
+
+		// The step waits for its rings to release.
+		setRing(staging.ID, model.RingStateReleaseInProgress, previous.ID)
+		require.NoError(t, supervisor.Do())
+		require.Equal(t, 0, getRollout(rollout.ID).CurrentStep)
+
+		setRing(staging.ID, model.RingStateStable, release.ID)
+		// A ring of the next step that already runs the release is skipped.
+		setRing(production2.ID, model.RingStateStable, release.ID)
+		require.NoError(t, supervisor.Do())
+		current := getRollout(rollout.ID)
+		require.Equal(t, 1, current.CurrentStep)
+		require.NotZero(t, current.StepStartAt)
+		require.Equal(t, model.RingStateReleasePending, getRing(production1.ID).State)
+		require.Equal(t, model.RingStateStable, getRing(production2.ID).State)
+
+		setRing(production1.ID, model.RingStateStable, release.ID)
+		require.NoError(t, supervisor.Do())
+		current = getRollout(rollout.ID)
+		require.Equal(t, model.RolloutStateComplete, current.State)
+		require.Equal(t, 2, current.CurrentStep)
+
+		events, err := sqlStore.GetStateChangeEvents(&model.StateChangeEventFilter{RingID: production1.ID, PerPage: model.AllPerPage})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, model.RingStateReleasePending, events[0].NewState)
+	})
+
+	t.Run("a failed ring fails the rollout", func(t *testing.T) {
+		staging := createRing("staging-failure")
+		production := createRing("production-failure")
+
+		rollout := &model.Rollout{
+			ReleaseID: release.ID,
+			Steps: model.RolloutSteps{
+				{Name: "staging", RingIDs: []string{staging.ID}},
+				{Name: "production", RingIDs: []string{production.ID}},
+			},
+			State: model.RolloutStateInProgress,
+		}
+		require.NoError(t, sqlStore.CreateRollout(rollout))
+
+		require.NoError(t, supervisor.Do())
+		setRing(staging.ID, model.RingStateSoakingFailed, previous.ID)
+		require.NoError(t, supervisor.Do())
+
+		current := getRollout(rollout.ID)
+		require.Equal(t, model.RolloutStateFailed, current.State)
+		require.Equal(t, "ring staging-failure is soaking-failed", current.Message)
+		require.Equal(t, model.RingStateStable, getRing(production.ID).State)
+	})
+
+	t.Run("a busy ring delays its step", func(t *testing.T) {
+		ring := createRing("busy")
+		setRing(ring.ID, model.RingStateCreationRequested, previous.ID)
+
+		rollout := &model.Rollout{
+			ReleaseID: release.ID,
+			Steps:     model.RolloutSteps{{RingIDs: []string{ring.ID}}},
+			State:     model.RolloutStateInProgress,
+		}
+		require.NoError(t, sqlStore.CreateRollout(rollout))
+
+		require.NoError(t, supervisor.Do())
+		require.Zero(t, getRollout(rollout.ID).StepStartAt)
+
+		setRing(ring.ID, model.RingStateStable, previous.ID)
+		require.NoError(t, supervisor.Do())
+		require.NotZero(t, getRollout(rollout.ID).StepStartAt)
+		require.Equal(t, model.RingStateReleasePending, getRing(ring.ID).State)
+	})
+	t.Run("promotion waits for its window and checks", func(t *testing.T) {
+		checker := &mockSoakChecker{Values: map[string]float64{"error-rate": 0.001}}
+		supervisor.SetSoakChecker(checker)
+		defer supervisor.SetSoakChecker(nil)
+
+		staging := createRing("staging-promotion")
+		production := createRing("production-promotion")
+
+		rollout := &model.Rollout{
+			ReleaseID: release.ID,
+			Steps: model.RolloutSteps{
+				{
+					Name:            "staging",
+					RingIDs:         []string{staging.ID},
+					PromotionWindow: 3600,
+					PromotionChecks: model.SoakChecks{{Name: "error-rate", Query: "sum(rate(errors[5m]))", Comparison: model.SoakCheckBelow, Threshold: 0.01}},
+				},
+				{Name: "production", RingIDs: []string{production.ID}},
+			},
+			State: model.RolloutStateInProgress,
+		}
+		require.NoError(t, sqlStore.CreateRollout(rollout))
+
+		require.NoError(t, supervisor.Do())
+		setRing(staging.ID, model.RingStateStable, release.ID)
+		require.NoError(t, supervisor.Do())
+		current := getRollout(rollout.ID)
+		require.Equal(t, 0, current.CurrentStep)
+		require.NotZero(t, current.StepReleasedAt)
+		require.Equal(t, model.RingStateStable, getRing(production.ID).State)
+
+		// The step is promoted once the window elapsed.
+		current.StepReleasedAt -= 3600 * 1000
+		require.NoError(t, sqlStore.UpdateRollout(current))
+		require.NoError(t, supervisor.Do())
+		current = getRollout(rollout.ID)
+		require.Equal(t, 1, current.CurrentStep)
+		require.Zero(t, current.StepReleasedAt)
+		require.Equal(t, model.RingStateReleasePending, getRing(production.ID).State)
+	})
+
+	t.Run("a breached promotion check pauses the rollout", func(t *testing.T) {
+		checker := &mockSoakChecker{Values: map[string]float64{"error-rate": 0.05}}
+		supervisor.SetSoakChecker(checker)
+		defer supervisor.SetSoakChecker(nil)
+
+		staging := createRing("staging-breach")
+		production := createRing("production-breach")
+
+		rollout := &model.Rollout{
+			ReleaseID: release.ID,
+			Steps: model.RolloutSteps{
+				{
+					Name:            "staging",
+					RingIDs:         []string{staging.ID},
+					PromotionChecks: model.SoakChecks{{Name: "error-rate", Query: "sum(rate(errors[5m]))", Comparison: model.SoakCheckBelow, Threshold: 0.01}},
+				},
+				{Name: "production", RingIDs: []string{production.ID}},
+			},
+			State: model.RolloutStateInProgress,
+		}
+		require.NoError(t, sqlStore.CreateRollout(rollout))
+
+		require.NoError(t, supervisor.Do())
+		setRing(staging.ID, model.RingStateStable, release.ID)
+		require.NoError(t, supervisor.Do())
+
+		current := getRollout(rollout.ID)
+		require.Equal(t, model.RolloutStatePaused, current.State)
+		require.Equal(t, 0, current.CurrentStep)
+		require.Equal(t, "promotion check error-rate breached: observed 0.05, expected < 0.01", current.Message)
+		require.Equal(t, model.RingStateStable, getRing(production.ID).State)
+
+		// Paused rollouts are not worked on.
+		checker.Values["error-rate"] = 0.001
+		require.NoError(t, supervisor.Do())
+		require.Equal(t, model.RolloutStatePaused, getRollout(rollout.ID).State)
+	})
+}
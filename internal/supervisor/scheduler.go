@@ -5,6 +5,7 @@
 package supervisor
 
 import (
+	"context"
 	"time"
 )
 
@@ -13,6 +14,7 @@ type Scheduler struct {
 	doer   Doer
 	period time.Duration
 	notify chan bool
+	reset  chan time.Duration
 	stop   chan bool
 	done   chan bool
 }
@@ -26,6 +28,7 @@ func NewScheduler(doer Doer, period time.Duration) *Scheduler {
 		doer:   doer,
 		period: period,
 		notify: make(chan bool, 1),
+		reset:  make(chan time.Duration),
 		stop:   make(chan bool),
 		done:   make(chan bool),
 	}
@@ -49,6 +52,15 @@ func (s *Scheduler) Do() error {
 	return nil
 }
 
+// SetPeriod changes the period of the scheduler, taking effect once any
+// active doer finishes. A period of 0 stops scheduling the doer.
+func (s *Scheduler) SetPeriod(period time.Duration) {
+	select {
+	case s.reset <- period:
+	case <-s.done:
+	}
+}
+
 // run is the main thread of the scheduler and responsible for triggering the doer as required.
 func (s *Scheduler) run() {
 	for {
@@ -65,6 +77,8 @@ func (s *Scheduler) run() {
 			_ = s.doer.Do()
 		case <-notify:
 			_ = s.doer.Do()
+		case period := <-s.reset:
+			s.period = period
 		case <-s.stop:
 			s.doer.Shutdown()
 			close(s.done)
@@ -76,8 +90,18 @@ func (s *Scheduler) run() {
 // Close waits for any active doer to finish, terminates the main thread of the scheduler, and
 // ensures the doer is no longer invoked.
 func (s *Scheduler) Close() error {
-	close(s.stop)
-	<-s.done
+	return s.CloseContext(context.Background())
+}
 
-	return nil
+// CloseContext terminates the main thread of the scheduler like Close, but
+// stops waiting for any active doer to finish once the given context is done,
+// returning the error of the context.
+func (s *Scheduler) CloseContext(ctx context.Context) error {
+	close(s.stop)
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,70 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerSetPeriod(t *testing.T) {
+	doer := &testDoer{calls: make(chan bool, 10)}
+	scheduler := supervisor.NewScheduler(doer, 0)
+	defer scheduler.Close()
+
+	require.NoError(t, scheduler.Do())
+	select {
+	case <-doer.calls:
+		require.Fail(t, "doer should not be invoked while the scheduler is disabled")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	scheduler.SetPeriod(10 * time.Millisecond)
+	select {
+	case <-doer.calls:
+	case <-time.After(time.Second):
+		require.Fail(t, "doer not invoked after enabling the scheduler")
+	}
+
+	scheduler.SetPeriod(0)
+	for len(doer.calls) > 0 {
+		<-doer.calls
+	}
+	select {
+	case <-doer.calls:
+		require.Fail(t, "doer should not be invoked after disabling the scheduler")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSchedulerCloseContext(t *testing.T) {
+	t.Run("idle", func(t *testing.T) {
+		doer := &testDoer{calls: make(chan bool)}
+		scheduler := supervisor.NewScheduler(doer, time.Hour)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, scheduler.CloseContext(ctx))
+	})
+
+	t.Run("doer still active", func(t *testing.T) {
+		doer := &testDoer{calls: make(chan bool)}
+		scheduler := supervisor.NewScheduler(doer, time.Hour)
+		require.NoError(t, scheduler.Do())
+		// Give the scheduler time to invoke the doer, which then blocks
+		// until its call is received.
+		time.Sleep(50 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		require.Equal(t, context.DeadlineExceeded, scheduler.CloseContext(ctx))
+
+		<-doer.calls
+	})
+}
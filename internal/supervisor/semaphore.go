@@ -0,0 +1,37 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+// DefaultParallelism is the default number of rings, or installation groups,
+// worked on at once by the supervisors sharing a semaphore.
+const DefaultParallelism = 4
+
+// Semaphore bounds the work done at once by the supervisors sharing it.
+type Semaphore chan struct{}
+
+// NewSemaphore creates a semaphore allowing the given number of holders at
+// once. A size lower than 1 allows a single holder.
+func NewSemaphore(size int) Semaphore {
+	if size < 1 {
+		size = 1
+	}
+
+	return make(Semaphore, size)
+}
+
+// Acquire blocks until the semaphore can be held.
+func (s Semaphore) Acquire() {
+	s <- struct{}{}
+}
+
+// Full returns whether the semaphore has as many holders as it allows.
+func (s Semaphore) Full() bool {
+	return len(s) == cap(s)
+}
+
+// Release releases the semaphore, which must be held.
+func (s Semaphore) Release() {
+	<-s
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor
+
+import (
+	"sync"
+
+	"github.com/mattermost/elrond/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// soakAnalyzerStore abstracts the database operations required to analyze
+// the soak outcomes of the rings.
+type soakAnalyzerStore interface {
+	GetRings(ringFilter *model.RingFilter) ([]*model.Ring, error)
+	GetStateChangeEvents(filter *model.StateChangeEventFilter) ([]*model.StateChangeEvent, error)
+}
+
+// SoakTimeAnalyzer periodically suggests soak time adjustments for every
+// ring from the outcomes of its previous soaks, keeping the latest report.
+type SoakTimeAnalyzer struct {
+	store  soakAnalyzerStore
+	logger log.FieldLogger
+
+	mutex  sync.RWMutex
+	report *model.SoakTimeReport
+}
+
+// NewSoakTimeAnalyzer creates a new SoakTimeAnalyzer.
+func NewSoakTimeAnalyzer(store soakAnalyzerStore, logger log.FieldLogger) *SoakTimeAnalyzer {
+	return &SoakTimeAnalyzer{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Shutdown performs graceful shutdown tasks for the soak time analyzer.
+func (a *SoakTimeAnalyzer) Shutdown() {
+	a.logger.Debug("Shutting down soak time analyzer")
+}
+
+// Do builds a new soak time report, logging the rings whose soak time should
+// be adjusted.
+func (a *SoakTimeAnalyzer) Do() error {
+	rings, err := a.store.GetRings(&model.RingFilter{PerPage: model.AllPerPage})
+	if err != nil {
+		a.logger.WithError(err).Warn("Failed to query rings to analyze")
+		return err
+	}
+
+	events, err := a.store.GetStateChangeEvents(&model.StateChangeEventFilter{
+		ResourceType: model.TypeRing,
+		PerPage:      model.MaxStateChangeEventsPerQuery,
+		Latest:       true,
+	})
+	if err != nil {
+		a.logger.WithError(err).Warn("Failed to query ring state change events to analyze")
+		return err
+	}
+
+	report := model.BuildSoakTimeReport(rings, events, model.GetMillis())
+
+	for _, suggestion := range report.Rings {
+		if suggestion.Changed() {
+			a.logger.WithField("ring", suggestion.RingID).Infof("Suggesting a soak time of %ds instead of %ds: %s", suggestion.SuggestedSoakTime, suggestion.SoakTime, suggestion.Reason)
+		}
+	}
+
+	a.mutex.Lock()
+	a.report = report
+	a.mutex.Unlock()
+
+	return nil
+}
+
+// Report returns the latest soak time report, or nil if none was built yet.
+func (a *SoakTimeAnalyzer) Report() *model.SoakTimeReport {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.report
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package supervisor_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/internal/store"
+	"github.com/mattermost/elrond/internal/supervisor"
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoakTimeAnalyzer(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+	sqlStore := store.MakeTestSQLStore(t, logger)
+	defer store.CloseConnection(t, sqlStore)
+
+	ring := &model.Ring{Name: "production", State: model.RingStateStable, SoakTime: 3600}
+	require.NoError(t, sqlStore.CreateRing(ring, nil))
+
+	for i := int64(0); i < model.MinSoakTimeSamples; i++ {
+		for j, state := range []string{model.RingStateSoakingRequested, model.RingStateStable} {
+			require.NoError(t, sqlStore.CreateStateChangeEvent(&model.StateChangeEvent{
+				ResourceType: model.TypeRing,
+				ResourceID:   ring.ID,
+				RingID:       ring.ID,
+				NewState:     state,
+				Timestamp:    i*10000 + int64(j) + 1,
+			}))
+		}
+	}
+
+	analyzer := supervisor.NewSoakTimeAnalyzer(sqlStore, logger)
+	require.Nil(t, analyzer.Report())
+
+	require.NoError(t, analyzer.Do())
+	report := analyzer.Report()
+	require.NotNil(t, report)
+	require.Len(t, report.Rings, 1)
+	require.Equal(t, ring.ID, report.Rings[0].RingID)
+	require.Equal(t, model.MinSoakTimeSamples, report.Rings[0].Soaks)
+	require.Equal(t, 2700, report.Rings[0].SuggestedSoakTime)
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package verification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// HTTPVerifier runs the verification jobs of the releases of installation
+// groups through their verification URL: a model.VerificationJobRequest
+// posted to the URL starts a job, and the status of the job is then fetched
+// from the URL followed by the ID of the job. Both respond with a
+// model.VerificationJob. Kubernetes Jobs, or any test suite, are run this way
+// behind an HTTP endpoint.
+type HTTPVerifier struct {
+	client *http.Client
+}
+
+// NewHTTPVerifier creates a new HTTPVerifier sending its requests through the
+// given transport, or http.DefaultTransport if nil.
+func NewHTTPVerifier(transport http.RoundTripper) *HTTPVerifier {
+	return &HTTPVerifier{
+		client: &http.Client{Timeout: 30 * time.Second, Transport: transport},
+	}
+}
+
+// StartVerification starts the verification job of the given release of the
+// installation group.
+func (v *HTTPVerifier) StartVerification(installationGroup *model.InstallationGroup, release *model.RingRelease) (*model.VerificationJob, error) {
+	body, err := json.Marshal(&model.VerificationJobRequest{
+		InstallationGroupID:   installationGroup.ID,
+		InstallationGroupName: installationGroup.Name,
+		ProvisionerGroupID:    installationGroup.ProvisionerGroupID,
+		ReleaseID:             release.ID,
+		Image:                 release.Image,
+		Version:               release.Version,
+		Annotations:           installationGroup.Annotations,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode verification job request")
+	}
+
+	resp, err := v.client.Post(installationGroup.VerificationURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start verification job")
+	}
+	defer resp.Body.Close()
+
+	job, err := readJob(resp)
+	if err != nil {
+		return nil, err
+	}
+	if job.ID == "" {
+		return nil, errors.New("verification job started without an ID")
+	}
+
+	return job, nil
+}
+
+// GetVerification fetches the status of the given verification job of the
+// installation group.
+func (v *HTTPVerifier) GetVerification(installationGroup *model.InstallationGroup, jobID string) (*model.VerificationJob, error) {
+	resp, err := v.client.Get(fmt.Sprintf("%s/%s", strings.TrimSuffix(installationGroup.VerificationURL, "/"), url.PathEscape(jobID)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get verification job")
+	}
+	defer resp.Body.Close()
+
+	return readJob(resp)
+}
+
+// readJob decodes the verification job of a successful response.
+func readJob(resp *http.Response) (*model.VerificationJob, error) {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.Errorf("verification URL responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	job, err := model.VerificationJobFromReader(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode verification job")
+	}
+
+	return job, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package verification
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPVerifier(t *testing.T) {
+	var started *model.VerificationJobRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		started = &model.VerificationJobRequest{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(started))
+		w.Write([]byte(`{"id":"job 1","status":"running"}`))
+	})
+	mux.HandleFunc("/jobs/job 1", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodGet, r.Method)
+		w.Write([]byte(`{"id":"job 1","status":"passed","outputURL":"http://logs/job1"}`))
+	})
+	mux.HandleFunc("/unknown/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"job1","status":"pending"}`))
+	})
+	mux.HandleFunc("/anonymous/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"running"}`))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	verifier := NewHTTPVerifier(nil)
+	installationGroup := &model.InstallationGroup{
+		ID:                 "ig1",
+		Name:               "group1",
+		ProvisionerGroupID: "pg1",
+		VerificationURL:    ts.URL + "/jobs",
+	}
+	release := &model.RingRelease{ID: "release1", Image: "mattermost/mattermost-enterprise-edition", Version: "7.1.0"}
+
+	job, err := verifier.StartVerification(installationGroup, release)
+	require.NoError(t, err)
+	require.Equal(t, &model.VerificationJob{ID: "job 1", Status: model.VerificationJobRunning}, job)
+	require.Equal(t, &model.VerificationJobRequest{
+		InstallationGroupID:   "ig1",
+		InstallationGroupName: "group1",
+		ProvisionerGroupID:    "pg1",
+		ReleaseID:             "release1",
+		Image:                 "mattermost/mattermost-enterprise-edition",
+		Version:               "7.1.0",
+	}, started)
+
+	job, err = verifier.GetVerification(installationGroup, "job 1")
+	require.NoError(t, err)
+	require.Equal(t, model.VerificationJobPassed, job.Status)
+	require.Equal(t, "http://logs/job1", job.OutputURL)
+
+	_, err = verifier.GetVerification(installationGroup, "job2")
+	require.Error(t, err)
+
+	installationGroup.VerificationURL = ts.URL + "/unknown/jobs"
+	_, err = verifier.StartVerification(installationGroup, release)
+	require.Error(t, err)
+
+	installationGroup.VerificationURL = ts.URL + "/anonymous/jobs"
+	_, err = verifier.StartVerification(installationGroup, release)
+	require.Error(t, err)
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	batcherLock sync.RWMutex
+	batcher     *Batcher
+)
+
+// SetBatcher configures the batcher used by SendToAllWebhooks to coalesce
+// bursts of payloads. Passing nil disables batching.
+func SetBatcher(b *Batcher) {
+	batcherLock.Lock()
+	defer batcherLock.Unlock()
+	batcher = b
+}
+
+func getBatcher() *Batcher {
+	batcherLock.RLock()
+	defer batcherLock.RUnlock()
+	return batcher
+}
+
+// webhookBatch is the payloads queued for a webhook.
+type webhookBatch struct {
+	hook     *model.Webhook
+	payloads []*model.WebhookPayload
+}
+
+// Batcher coalesces the payloads sent within a window, such as the
+// installation group transitions following a ring transition, into a single
+// batch payload per webhook. The window starts with the first payload of a
+// burst.
+type Batcher struct {
+	window time.Duration
+	logger *log.Entry
+
+	lock    sync.Mutex
+	pending map[string]*webhookBatch
+	order   []string
+	timer   *time.Timer
+}
+
+// NewBatcher creates a new Batcher coalescing the payloads sent within the
+// given window.
+func NewBatcher(window time.Duration, logger log.FieldLogger) *Batcher {
+	return &Batcher{
+		window:  window,
+		logger:  logger.WithField("webhook-batch", true),
+		pending: make(map[string]*webhookBatch),
+	}
+}
+
+// Add queues a payload to be sent to the given webhooks with the current
+// batch, starting a new batch if there is none.
+func (b *Batcher) Add(hooks []*model.Webhook, payload *model.WebhookPayload) {
+	if len(hooks) == 0 {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for _, hook := range hooks {
+		batch, ok := b.pending[hook.ID]
+		if !ok {
+			batch = &webhookBatch{hook: hook}
+			b.pending[hook.ID] = batch
+			b.order = append(b.order, hook.ID)
+		}
+		batch.payloads = append(batch.payloads, payload)
+	}
+
+	if b.timer == nil {
+		b.timer = time.AfterFunc(b.window, b.Flush)
+	}
+}
+
+// Flush sends the current batch of every webhook. Webhooks with a single
+// queued payload receive it as is.
+func (b *Batcher) Flush() {
+	b.lock.Lock()
+	pending := b.pending
+	order := b.order
+	b.pending = make(map[string]*webhookBatch)
+	b.order = nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.lock.Unlock()
+
+	for _, id := range order {
+		batch := pending[id]
+		critical := false
+		for _, payload := range batch.payloads {
+			critical = critical || payload.IsFailure()
+		}
+		if !limiter.allow(batch.hook, len(batch.payloads), critical, b.logger) {
+			continue
+		}
+
+		if len(batch.payloads) == 1 {
+			hook, payload := batch.hook, batch.payloads[0]
+			dispatch(hook, func() { sendWebhook(hook, payload, b.logger) }) //nolint
+			continue
+		}
+
+		b.logger.Debugf("Sending batch of %d event(s) to webhook %s", len(batch.payloads), batch.hook.ID)
+		payloadStr, err := formatBatch(batch.hook, &model.WebhookBatchPayload{
+			Timestamp: time.Now().UnixNano(),
+			Type:      model.TypeBatch,
+			Events:    batch.payloads,
+		})
+		if err != nil {
+			b.logger.WithError(err).Error("Unable to create batch payload string")
+			continue
+		}
+		hook := batch.hook
+		dispatch(hook, func() { postWebhook(hook, payloadStr, b.logger) }) //nolint
+	}
+}
+
+// Close sends any queued payloads.
+func (b *Batcher) Close() {
+	b.Flush()
+}
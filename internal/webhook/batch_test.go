@@ -0,0 +1,129 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatcher(t *testing.T) {
+	logger := testlib.MakeLogger(t).WithFields(log.Fields{
+		"webhooks-tests": true,
+	})
+
+	var lock sync.Mutex
+	received := make(map[string][]*model.WebhookBatchPayload)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		batch := model.WebhookBatchPayload{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		received[r.URL.Path] = append(received[r.URL.Path], &batch)
+	}))
+	defer ts.Close()
+
+	mockStore := &mockWebhookStore{
+		Webhooks: []*model.Webhook{
+			{ID: model.NewID(), URL: ts.URL + "/all"},
+			{ID: model.NewID(), URL: ts.URL + "/prod", LabelSelector: "env=prod"},
+			{ID: model.NewID(), URL: ts.URL + "/staging", LabelSelector: "env=staging"},
+		},
+	}
+
+	batcher := NewBatcher(time.Hour, logger)
+	SetBatcher(batcher)
+	defer SetBatcher(nil)
+
+	ringID := model.NewID()
+	err := SendToAllWebhooks(mockStore, &model.WebhookPayload{
+		Type:     model.TypeRing,
+		ID:       ringID,
+		NewState: model.RingStateReleaseInProgress,
+		Labels:   map[string]string{"env": "prod"},
+	}, logger)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		err = SendToAllWebhooks(mockStore, &model.WebhookPayload{
+			Type:     model.TypeInstallationGroup,
+			ID:       model.NewID(),
+			NewState: model.InstallationGroupReleaseRequested,
+		}, logger)
+		require.NoError(t, err)
+	}
+
+	lock.Lock()
+	require.Empty(t, received)
+	lock.Unlock()
+
+	batcher.Close()
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(received) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Len(t, received["/all"], 1)
+	require.Equal(t, model.TypeBatch, received["/all"][0].Type)
+	require.Len(t, received["/all"][0].Events, 4)
+	require.Equal(t, ringID, received["/all"][0].Events[0].ID)
+
+	// A single event is sent as a regular payload.
+	require.Len(t, received["/prod"], 1)
+	require.Equal(t, model.TypeRing, received["/prod"][0].Type)
+	require.Empty(t, received["/prod"][0].Events)
+}
+
+func TestBatcherWindow(t *testing.T) {
+	logger := testlib.MakeLogger(t).WithFields(log.Fields{
+		"webhooks-tests": true,
+	})
+
+	var lock sync.Mutex
+	var received []*model.WebhookBatchPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		batch := model.WebhookBatchPayload{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		received = append(received, &batch)
+	}))
+	defer ts.Close()
+
+	hooks := []*model.Webhook{{ID: model.NewID(), URL: ts.URL}}
+	batcher := NewBatcher(50*time.Millisecond, logger)
+	defer batcher.Close()
+
+	for _, state := range []string{model.InstallationGroupReleaseRequested, model.InstallationGroupReleaseFailed} {
+		batcher.Add(hooks, &model.WebhookPayload{
+			Type:     model.TypeInstallationGroup,
+			ID:       model.NewID(),
+			NewState: state,
+		})
+	}
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(received) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, model.TypeBatch, received[0].Type)
+	require.Len(t, received[0].Events, 2)
+}
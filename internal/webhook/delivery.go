@@ -0,0 +1,200 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// deliveryLease is how long a delivery being attempted is hidden from
+	// the retries, so that it is not attempted twice at once.
+	deliveryLease = time.Minute
+	// maxDeliveryBackoff caps the delay between two attempts of a delivery.
+	maxDeliveryBackoff = time.Hour
+	// deliveryBatchSize is the number of due deliveries retried on each run.
+	deliveryBatchSize = 100
+	// deliveredRetention is how long successful deliveries are kept for.
+	deliveredRetention = 24 * time.Hour
+)
+
+var (
+	delivererLock sync.RWMutex
+	deliverer     *Deliverer
+)
+
+// SetDeliverer configures the deliverer recording and retrying the payloads
+// sent to webhooks. Passing nil sends payloads once, without recording them.
+func SetDeliverer(d *Deliverer) {
+	delivererLock.Lock()
+	defer delivererLock.Unlock()
+	deliverer = d
+}
+
+func getDeliverer() *Deliverer {
+	delivererLock.RLock()
+	defer delivererLock.RUnlock()
+	return deliverer
+}
+
+// deliveryStore abstracts the database operations required to deliver
+// webhook payloads.
+type deliveryStore interface {
+	GetWebhook(id string) (*model.Webhook, error)
+	CreateWebhookDelivery(delivery *model.WebhookDelivery) error
+	GetWebhookDeliveriesDue(now int64, limit int) ([]*model.WebhookDelivery, error)
+	ClaimWebhookDelivery(delivery *model.WebhookDelivery, leaseUntil int64) (bool, error)
+	UpdateWebhookDelivery(delivery *model.WebhookDelivery) error
+	DeleteWebhookDeliveriesBefore(state string, timestamp int64) (int64, error)
+}
+
+// Deliverer records every payload sent to a webhook as a delivery, so that a
+// failed delivery is retried with an exponential backoff, across restarts,
+// until the webhook accepts it or the attempts run out.
+type Deliverer struct {
+	store       deliveryStore
+	maxAttempts int
+	backoff     time.Duration
+	logger      *log.Entry
+}
+
+// NewDeliverer creates a new Deliverer making up to maxAttempts attempts of
+// each delivery, the first retry being made after backoff.
+func NewDeliverer(store deliveryStore, maxAttempts int, backoff time.Duration, logger log.FieldLogger) *Deliverer {
+	return &Deliverer{
+		store:       store,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		logger:      logger.WithField("webhook-delivery", true),
+	}
+}
+
+// Deliver records the delivery of the given payload to the webhook and
+// makes its first attempt.
+func (d *Deliverer) Deliver(hook *model.Webhook, payloadStr string) error {
+	delivery := &model.WebhookDelivery{
+		WebhookID:     hook.ID,
+		Payload:       payloadStr,
+		State:         model.WebhookDeliveryPending,
+		NextAttemptAt: time.Now().Add(deliveryLease).UnixMilli(),
+	}
+	if err := d.store.CreateWebhookDelivery(delivery); err != nil {
+		d.logger.WithField("webhook", hook.ID).WithError(err).Error("Failed to record webhook delivery, sending it once")
+		_, err = post(hook, payloadStr, "")
+		return err
+	}
+
+	return d.attempt(hook, delivery)
+}
+
+// attempt sends the given delivery to the webhook and records the outcome,
+// scheduling the next attempt on failure.
+func (d *Deliverer) attempt(hook *model.Webhook, delivery *model.WebhookDelivery) error {
+	logger := d.logger.WithFields(log.Fields{
+		"webhook":  hook.ID,
+		"delivery": delivery.ID,
+	})
+
+	statusCode, err := post(hook, delivery.Payload, delivery.ID)
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = now.UnixMilli()
+	delivery.LastStatusCode = statusCode
+	if err == nil {
+		delivery.State = model.WebhookDeliveryDelivered
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= d.maxAttempts {
+			delivery.State = model.WebhookDeliveryFailed
+			logger.WithError(err).Errorf("Webhook delivery failed after %d attempt(s)", delivery.Attempts)
+		} else {
+			delay := d.retryDelay(delivery.Attempts)
+			delivery.NextAttemptAt = now.Add(delay).UnixMilli()
+			logger.WithError(err).Warnf("Webhook delivery failed, retrying in %s", delay)
+		}
+	}
+
+	if updateErr := d.store.UpdateWebhookDelivery(delivery); updateErr != nil {
+		logger.WithError(updateErr).Error("Failed to record webhook delivery attempt")
+	}
+
+	return err
+}
+
+// retryDelay returns the delay before the attempt following the given number
+// of attempts, doubling the backoff after each failed attempt.
+func (d *Deliverer) retryDelay(attempts int) time.Duration {
+	delay := d.backoff
+	for i := 1; i < attempts && delay < maxDeliveryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxDeliveryBackoff {
+		delay = maxDeliveryBackoff
+	}
+
+	return delay
+}
+
+// Shutdown performs graceful shutdown tasks for the deliverer.
+func (d *Deliverer) Shutdown() {
+	d.logger.Debug("Shutting down webhook deliverer")
+}
+
+// Do retries the deliveries due in parallel, through the dispatcher when
+// there is one, and deletes the successful deliveries older than a day.
+func (d *Deliverer) Do() error {
+	if _, err := d.store.DeleteWebhookDeliveriesBefore(model.WebhookDeliveryDelivered, time.Now().Add(-deliveredRetention).UnixMilli()); err != nil {
+		d.logger.WithError(err).Warn("Failed to delete past webhook deliveries")
+	}
+
+	deliveries, err := d.store.GetWebhookDeliveriesDue(model.GetMillis(), deliveryBatchSize)
+	if err != nil {
+		d.logger.WithError(err).Error("Failed to query due webhook deliveries")
+		return errors.Wrap(err, "failed to query due webhook deliveries")
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for _, delivery := range deliveries {
+		claimed, err := d.store.ClaimWebhookDelivery(delivery, time.Now().Add(deliveryLease).UnixMilli())
+		if err != nil {
+			d.logger.WithError(err).Error("Failed to claim webhook delivery")
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		hook, err := d.store.GetWebhook(delivery.WebhookID)
+		if err != nil {
+			d.logger.WithError(err).Error("Failed to query webhook")
+			continue
+		}
+		if hook == nil || hook.IsDeleted() {
+			delivery.State = model.WebhookDeliveryFailed
+			delivery.LastError = "webhook deleted"
+			if err = d.store.UpdateWebhookDelivery(delivery); err != nil {
+				d.logger.WithError(err).Error("Failed to update webhook delivery")
+			}
+			continue
+		}
+
+		delivery := delivery
+		wg.Add(1)
+		dispatch(hook, func() {
+			defer wg.Done()
+			d.attempt(hook, delivery) //nolint
+		})
+	}
+
+	return nil
+}
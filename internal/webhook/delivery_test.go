@@ -0,0 +1,217 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDeliveryStore struct {
+	lock       sync.Mutex
+	webhooks   map[string]*model.Webhook
+	deliveries map[string]*model.WebhookDelivery
+}
+
+func newMockDeliveryStore(hooks ...*model.Webhook) *mockDeliveryStore {
+	s := &mockDeliveryStore{
+		webhooks:   make(map[string]*model.Webhook),
+		deliveries: make(map[string]*model.WebhookDelivery),
+	}
+	for _, hook := range hooks {
+		s.webhooks[hook.ID] = hook
+	}
+
+	return s
+}
+
+func (s *mockDeliveryStore) GetWebhook(id string) (*model.Webhook, error) {
+	return s.webhooks[id], nil
+}
+
+func (s *mockDeliveryStore) CreateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delivery.ID = model.NewID()
+	copied := *delivery
+	s.deliveries[delivery.ID] = &copied
+	return nil
+}
+
+func (s *mockDeliveryStore) GetWebhookDeliveriesDue(now int64, limit int) ([]*model.WebhookDelivery, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var due []*model.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if delivery.State == model.WebhookDeliveryPending && delivery.NextAttemptAt <= now {
+			copied := *delivery
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (s *mockDeliveryStore) ClaimWebhookDelivery(delivery *model.WebhookDelivery, leaseUntil int64) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	stored := s.deliveries[delivery.ID]
+	if stored.NextAttemptAt != delivery.NextAttemptAt {
+		return false, nil
+	}
+	stored.NextAttemptAt = leaseUntil
+	delivery.NextAttemptAt = leaseUntil
+	return true, nil
+}
+
+func (s *mockDeliveryStore) UpdateWebhookDelivery(delivery *model.WebhookDelivery) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	copied := *delivery
+	s.deliveries[delivery.ID] = &copied
+	return nil
+}
+
+func (s *mockDeliveryStore) DeleteWebhookDeliveriesBefore(state string, timestamp int64) (int64, error) {
+	return 0, nil
+}
+
+func (s *mockDeliveryStore) only(t *testing.T) *model.WebhookDelivery {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	require.Len(t, s.deliveries, 1)
+	for _, delivery := range s.deliveries {
+		copied := *delivery
+		return &copied
+	}
+	return nil
+}
+
+// due makes the pending deliveries due right away.
+func (s *mockDeliveryStore) due() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, delivery := range s.deliveries {
+		delivery.NextAttemptAt = 0
+	}
+}
+
+func TestDeliverer(t *testing.T) {
+	logger := testlib.MakeLogger(t)
+
+	var lock sync.Mutex
+	var statuses []int
+	var signatures, deliveryIDs []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, `{"id":"ring"}`, string(body))
+		signatures = append(signatures, r.Header.Get(model.WebhookSignatureHeader))
+		deliveryIDs = append(deliveryIDs, r.Header.Get(model.WebhookDeliveryHeader))
+		status := http.StatusOK
+		if len(statuses) > 0 {
+			status = statuses[0]
+			statuses = statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	hook := &model.Webhook{ID: model.NewID(), URL: ts.URL, Secret: "secret"}
+
+	t.Run("delivered", func(t *testing.T) {
+		store := newMockDeliveryStore(hook)
+		deliverer := NewDeliverer(store, 3, time.Minute, logger)
+
+		require.NoError(t, deliverer.Deliver(hook, `{"id":"ring"}`))
+		delivery := store.only(t)
+		require.Equal(t, model.WebhookDeliveryDelivered, delivery.State)
+		require.Equal(t, 1, delivery.Attempts)
+		require.Equal(t, http.StatusOK, delivery.LastStatusCode)
+
+		lock.Lock()
+		defer lock.Unlock()
+		require.Equal(t, model.SignWebhookPayload("secret", `{"id":"ring"}`), signatures[len(signatures)-1])
+		require.Equal(t, delivery.ID, deliveryIDs[len(deliveryIDs)-1])
+	})
+
+	t.Run("retried until delivered", func(t *testing.T) {
+		lock.Lock()
+		statuses = []int{http.StatusServiceUnavailable}
+		lock.Unlock()
+
+		store := newMockDeliveryStore(hook)
+		deliverer := NewDeliverer(store, 3, time.Minute, logger)
+
+		require.Error(t, deliverer.Deliver(hook, `{"id":"ring"}`))
+		delivery := store.only(t)
+		require.Equal(t, model.WebhookDeliveryPending, delivery.State)
+		require.Equal(t, 1, delivery.Attempts)
+		require.Equal(t, http.StatusServiceUnavailable, delivery.LastStatusCode)
+		require.Equal(t, "webhook responded with status 503", delivery.LastError)
+		require.Greater(t, delivery.NextAttemptAt, model.GetMillis())
+
+		// Not due yet.
+		require.NoError(t, deliverer.Do())
+		require.Equal(t, 1, store.only(t).Attempts)
+
+		store.due()
+		require.NoError(t, deliverer.Do())
+		delivery = store.only(t)
+		require.Equal(t, model.WebhookDeliveryDelivered, delivery.State)
+		require.Equal(t, 2, delivery.Attempts)
+		require.Empty(t, delivery.LastError)
+	})
+
+	t.Run("failed after the last attempt", func(t *testing.T) {
+		lock.Lock()
+		statuses = []int{http.StatusInternalServerError, http.StatusInternalServerError}
+		lock.Unlock()
+
+		store := newMockDeliveryStore(hook)
+		deliverer := NewDeliverer(store, 2, time.Minute, logger)
+
+		require.Error(t, deliverer.Deliver(hook, `{"id":"ring"}`))
+		store.due()
+		require.NoError(t, deliverer.Do())
+		delivery := store.only(t)
+		require.Equal(t, model.WebhookDeliveryFailed, delivery.State)
+		require.Equal(t, 2, delivery.Attempts)
+	})
+
+	t.Run("webhook deleted", func(t *testing.T) {
+		lock.Lock()
+		statuses = []int{http.StatusInternalServerError}
+		lock.Unlock()
+
+		store := newMockDeliveryStore(hook)
+		deliverer := NewDeliverer(store, 3, time.Minute, logger)
+
+		require.Error(t, deliverer.Deliver(hook, `{"id":"ring"}`))
+		delete(store.webhooks, hook.ID)
+		store.due()
+		require.NoError(t, deliverer.Do())
+		delivery := store.only(t)
+		require.Equal(t, model.WebhookDeliveryFailed, delivery.State)
+		require.Equal(t, "webhook deleted", delivery.LastError)
+	})
+}
+
+func TestDelivererRetryDelay(t *testing.T) {
+	deliverer := NewDeliverer(newMockDeliveryStore(), 10, 30*time.Second, testlib.MakeLogger(t))
+	require.Equal(t, 30*time.Second, deliverer.retryDelay(1))
+	require.Equal(t, time.Minute, deliverer.retryDelay(2))
+	require.Equal(t, 4*time.Minute, deliverer.retryDelay(4))
+	require.Equal(t, maxDeliveryBackoff, deliverer.retryDelay(20))
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	digesterLock sync.RWMutex
+	digester     *Digester
+)
+
+// SetDigester configures the digester used by SendToAllWebhooks to batch
+// non-critical payloads. Passing nil disables digest mode.
+func SetDigester(d *Digester) {
+	digesterLock.Lock()
+	defer digesterLock.Unlock()
+	digester = d
+}
+
+func getDigester() *Digester {
+	digesterLock.RLock()
+	defer digesterLock.RUnlock()
+	return digester
+}
+
+// Digester batches non-critical webhook payloads per ring and sends them
+// as a single digest payload once per interval. Failures bypass the digester
+// and are always sent immediately.
+type Digester struct {
+	store    webhookStore
+	interval time.Duration
+	logger   *log.Entry
+
+	lock    sync.Mutex
+	pending map[string][]*model.WebhookPayload
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewDigester creates a new Digester and starts its flush loop.
+func NewDigester(store webhookStore, interval time.Duration, logger log.FieldLogger) *Digester {
+	d := &Digester{
+		store:    store,
+		interval: interval,
+		logger:   logger.WithField("webhook-digest", true),
+		pending:  make(map[string][]*model.WebhookPayload),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go d.run()
+
+	return d
+}
+
+// Add queues a payload to be sent with the next digest of its ring, so that
+// the events of a ring and of its installation groups are sent together.
+func (d *Digester) Add(payload *model.WebhookPayload) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	ringID := payload.ID
+	if payload.RingID != "" {
+		ringID = payload.RingID
+	}
+	d.pending[ringID] = append(d.pending[ringID], payload)
+}
+
+// Flush sends a digest for every ring with queued payloads.
+func (d *Digester) Flush() {
+	d.lock.Lock()
+	pending := d.pending
+	d.pending = make(map[string][]*model.WebhookPayload)
+	d.lock.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	hooks, err := d.store.GetWebhooks(&model.WebhookFilter{
+		PerPage:        model.AllPerPage,
+		IncludeDeleted: false,
+	})
+	if err != nil {
+		d.logger.WithError(err).Error("Failed to find webhooks for digest")
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	for id, events := range pending {
+		d.logger.Debugf("Sending digest of %d event(s) for %s to %d webhook(s)", len(events), id, len(hooks))
+		for _, hook := range hooks {
+			// Each webhook only receives the events matching its label
+			// selector.
+			var hookEvents []*model.WebhookPayload
+			for _, event := range events {
+				if hook.Matches(event) {
+					hookEvents = append(hookEvents, event)
+				}
+			}
+			if len(hookEvents) == 0 || !limiter.allow(hook, len(hookEvents), false, d.logger) {
+				continue
+			}
+
+			digest := &model.WebhookDigestPayload{
+				Timestamp: time.Now().UnixNano(),
+				ID:        id,
+				Type:      model.TypeDigest,
+				Events:    hookEvents,
+			}
+			payloadStr, err := formatDigest(hook, digest)
+			if err != nil {
+				d.logger.WithError(err).Error("Unable to create digest payload string")
+				continue
+			}
+			hook := hook
+			dispatch(hook, func() { postWebhook(hook, payloadStr, d.logger) }) //nolint
+		}
+	}
+}
+
+// Close stops the flush loop, sending any queued payloads first.
+func (d *Digester) Close() {
+	close(d.stop)
+	<-d.done
+}
+
+func (d *Digester) run() {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.Flush()
+		case <-d.stop:
+			d.Flush()
+			close(d.done)
+			return
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigester(t *testing.T) {
+	logger := testlib.MakeLogger(t).WithFields(log.Fields{
+		"webhooks-tests": true,
+	})
+
+	var lock sync.Mutex
+	var received []*model.WebhookDigestPayload
+	var immediate []*model.WebhookPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		digest := model.WebhookDigestPayload{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&digest))
+		if digest.Type == model.TypeDigest {
+			received = append(received, &digest)
+		} else {
+			immediate = append(immediate, &model.WebhookPayload{ID: digest.ID, Type: digest.Type})
+		}
+	}))
+	defer ts.Close()
+
+	mockStore := &mockWebhookStore{
+		Webhooks: []*model.Webhook{{ID: model.NewID(), URL: ts.URL}},
+	}
+
+	digester := NewDigester(mockStore, time.Hour, logger)
+	SetDigester(digester)
+	defer SetDigester(nil)
+
+	ringID := model.NewID()
+	for _, state := range []string{model.RingStateReleasePending, model.RingStateReleaseRequested} {
+		err := SendToAllWebhooks(mockStore, &model.WebhookPayload{
+			Type:     model.TypeRing,
+			ID:       ringID,
+			NewState: state,
+		}, logger)
+		require.NoError(t, err)
+	}
+
+	err := SendToAllWebhooks(mockStore, &model.WebhookPayload{
+		Type:     model.TypeRing,
+		ID:       ringID,
+		NewState: model.RingStateReleaseFailed,
+	}, logger)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(immediate) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lock.Lock()
+	require.Empty(t, received)
+	lock.Unlock()
+
+	digester.Close()
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(received) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, ringID, received[0].ID)
+	require.Equal(t, model.TypeDigest, received[0].Type)
+	require.Len(t, received[0].Events, 2)
+}
+
+func TestDigesterInstallationGroups(t *testing.T) {
+	logger := testlib.MakeLogger(t).WithFields(log.Fields{
+		"webhooks-tests": true,
+	})
+
+	var lock sync.Mutex
+	var received []*model.WebhookDigestPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		digest := model.WebhookDigestPayload{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&digest))
+		received = append(received, &digest)
+	}))
+	defer ts.Close()
+
+	mockStore := &mockWebhookStore{
+		Webhooks: []*model.Webhook{{ID: model.NewID(), URL: ts.URL}},
+	}
+
+	digester := NewDigester(mockStore, time.Hour, logger)
+	SetDigester(digester)
+	defer SetDigester(nil)
+
+	ringID := model.NewID()
+	for _, installationGroupID := range []string{model.NewID(), model.NewID()} {
+		err := SendToAllWebhooks(mockStore, &model.WebhookPayload{
+			Type:     model.TypeInstallationGroup,
+			ID:       installationGroupID,
+			RingID:   ringID,
+			NewState: model.InstallationGroupReleaseRequested,
+		}, logger)
+		require.NoError(t, err)
+	}
+
+	digester.Close()
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(received) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, ringID, received[0].ID)
+	require.Len(t, received[0].Events, 2)
+	require.NotEqual(t, received[0].Events[0].ID, received[0].Events[1].ID)
+}
+
+func TestDigesterLabelSelector(t *testing.T) {
+	logger := testlib.MakeLogger(t).WithFields(log.Fields{
+		"webhooks-tests": true,
+	})
+
+	var lock sync.Mutex
+	received := make(map[string]*model.WebhookDigestPayload)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		digest := model.WebhookDigestPayload{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&digest))
+		received[r.URL.Path] = &digest
+	}))
+	defer ts.Close()
+
+	mockStore := &mockWebhookStore{
+		Webhooks: []*model.Webhook{
+			{ID: model.NewID(), URL: ts.URL + "/all"},
+			{ID: model.NewID(), URL: ts.URL + "/prod", LabelSelector: "env=prod"},
+			{ID: model.NewID(), URL: ts.URL + "/staging", LabelSelector: "env=staging"},
+		},
+	}
+
+	digester := NewDigester(mockStore, time.Hour, logger)
+	SetDigester(digester)
+	defer SetDigester(nil)
+
+	ringID := model.NewID()
+	for _, labels := range []map[string]string{{"env": "prod"}, nil} {
+		err := SendToAllWebhooks(mockStore, &model.WebhookPayload{
+			Type:     model.TypeRing,
+			ID:       ringID,
+			NewState: model.RingStateReleasePending,
+			Labels:   labels,
+		}, logger)
+		require.NoError(t, err)
+	}
+
+	digester.Close()
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(received) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Len(t, received["/all"].Events, 2)
+	require.Len(t, received["/prod"].Events, 1)
+	require.Equal(t, "prod", received["/prod"].Events[0].Labels["env"])
+	require.NotContains(t, received, "/staging")
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"net/url"
+	"sync"
+
+	"github.com/mattermost/elrond/model"
+)
+
+var (
+	dispatcherLock sync.RWMutex
+	dispatcher     *Dispatcher
+)
+
+// SetDispatcher configures the dispatcher bounding the concurrent sends of
+// webhook payloads. Passing nil sends every payload in its own goroutine,
+// without limits.
+func SetDispatcher(d *Dispatcher) {
+	dispatcherLock.Lock()
+	defer dispatcherLock.Unlock()
+	dispatcher = d
+}
+
+func getDispatcher() *Dispatcher {
+	dispatcherLock.RLock()
+	defer dispatcherLock.RUnlock()
+	return dispatcher
+}
+
+// dispatchTarget holds the send slots of a target.
+type dispatchTarget struct {
+	slots chan struct{}
+	users int
+}
+
+// Dispatcher sends webhook payloads to distinct targets in parallel, bounding
+// the sends in progress to each target, so that a slow endpoint only delays
+// its own payloads, and the sends in progress overall. Webhooks sharing a
+// host are the same target.
+type Dispatcher struct {
+	maxPerTarget int
+	workers      chan struct{}
+
+	lock    sync.Mutex
+	targets map[string]*dispatchTarget
+}
+
+// NewDispatcher creates a new Dispatcher making up to maxWorkers sends at
+// once, and up to maxPerTarget to the same target. Limits of 0 or less are
+// treated as 1.
+func NewDispatcher(maxWorkers, maxPerTarget int) *Dispatcher {
+	if maxWorkers < 1 {
+		maxWorkers = 1
+	}
+	if maxPerTarget < 1 {
+		maxPerTarget = 1
+	}
+
+	return &Dispatcher{
+		maxPerTarget: maxPerTarget,
+		workers:      make(chan struct{}, maxWorkers),
+		targets:      make(map[string]*dispatchTarget),
+	}
+}
+
+// Run waits for a send slot of the target of the webhook, then for a worker,
+// and calls send. Payloads waiting on a busy target do not hold a worker.
+func (d *Dispatcher) Run(hook *model.Webhook, send func()) {
+	target := d.acquireTarget(webhookTarget(hook))
+	defer d.releaseTarget(webhookTarget(hook), target)
+
+	d.workers <- struct{}{}
+	defer func() { <-d.workers }()
+
+	send()
+}
+
+func (d *Dispatcher) acquireTarget(key string) *dispatchTarget {
+	d.lock.Lock()
+	target, ok := d.targets[key]
+	if !ok {
+		target = &dispatchTarget{slots: make(chan struct{}, d.maxPerTarget)}
+		d.targets[key] = target
+	}
+	target.users++
+	d.lock.Unlock()
+
+	target.slots <- struct{}{}
+
+	return target
+}
+
+func (d *Dispatcher) releaseTarget(key string, target *dispatchTarget) {
+	<-target.slots
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	target.users--
+	if target.users == 0 {
+		delete(d.targets, key)
+	}
+}
+
+// webhookTarget returns the target of the webhook, its host, falling back to
+// its URL when it cannot be parsed.
+func webhookTarget(hook *model.Webhook) string {
+	parsed, err := url.Parse(hook.URL)
+	if err != nil || parsed.Host == "" {
+		return hook.URL
+	}
+
+	return parsed.Host
+}
+
+// dispatch calls send in the background, through the dispatcher when there
+// is one.
+func dispatch(hook *model.Webhook, send func()) {
+	if d := getDispatcher(); d != nil {
+		go d.Run(hook, send)
+		return
+	}
+
+	go send()
+}
+
+// runDispatched calls send once the dispatcher, if any, allows it, such as
+// to send the payloads of a replay in order.
+func runDispatched(hook *model.Webhook, send func()) {
+	if d := getDispatcher(); d != nil {
+		d.Run(hook, send)
+		return
+	}
+
+	send()
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher(t *testing.T) {
+	slow := &model.Webhook{ID: model.NewID(), URL: "https://slow.example.com/hook"}
+	slowOther := &model.Webhook{ID: model.NewID(), URL: "https://slow.example.com/other"}
+	fast := &model.Webhook{ID: model.NewID(), URL: "https://fast.example.com/hook"}
+
+	t.Run("a slow target does not delay the others", func(t *testing.T) {
+		d := NewDispatcher(4, 1)
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		go d.Run(slow, func() {
+			close(started)
+			<-release
+		})
+		<-started
+
+		// The same host waits for the slow send.
+		sameHost := make(chan struct{})
+		go d.Run(slowOther, func() { close(sameHost) })
+
+		done := make(chan struct{})
+		go d.Run(fast, func() { close(done) })
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "send to another target was delayed")
+		}
+
+		select {
+		case <-sameHost:
+			require.Fail(t, "send to a busy target was not delayed")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		select {
+		case <-sameHost:
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "send to the target was not resumed")
+		}
+	})
+
+	t.Run("workers are capped", func(t *testing.T) {
+		d := NewDispatcher(2, 10)
+
+		var lock sync.Mutex
+		var running, maxRunning int
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			hook := &model.Webhook{ID: model.NewID(), URL: "https://" + model.NewID() + ".example.com"}
+			wg.Add(1)
+			go d.Run(hook, func() {
+				defer wg.Done()
+				lock.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				lock.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				lock.Lock()
+				running--
+				lock.Unlock()
+			})
+		}
+		wg.Wait()
+
+		require.LessOrEqual(t, maxRunning, 2)
+
+		// Idle targets are forgotten.
+		require.Eventually(t, func() bool {
+			d.lock.Lock()
+			defer d.lock.Unlock()
+			return len(d.targets) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestWebhookTarget(t *testing.T) {
+	require.Equal(t, "example.com:8080", webhookTarget(&model.Webhook{URL: "https://example.com:8080/hook"}))
+	require.Equal(t, "not a url", webhookTarget(&model.Webhook{URL: "not a url"}))
+}
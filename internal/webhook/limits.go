@@ -0,0 +1,225 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// minTruncatedString is the length, in bytes, strings are never truncated
+// below, so that short values such as types keep their meaning.
+const minTruncatedString = 64
+
+// truncatedSuffix marks the truncated strings of a payload.
+const truncatedSuffix = "..."
+
+// Limits are the limits of the requests sent to webhooks, which each webhook
+// may override.
+type Limits struct {
+	// Timeout is the time a request to a webhook may take.
+	Timeout time.Duration
+	// MaxRedirects is the maximum number of redirects followed.
+	MaxRedirects int
+	// MaxPayloadSize is the maximum size, in bytes, of the payloads sent.
+	// Larger payloads are truncated. 0 means no limit.
+	MaxPayloadSize int
+}
+
+// DefaultLimits are the limits of the requests sent to webhooks unless
+// configured otherwise.
+var DefaultLimits = Limits{
+	Timeout:      5 * time.Second,
+	MaxRedirects: 10,
+}
+
+var (
+	limitsLock sync.RWMutex
+	limits     = DefaultLimits
+)
+
+// SetLimits configures the limits of the requests sent to the webhooks not
+// overriding them.
+func SetLimits(l Limits) {
+	limitsLock.Lock()
+	defer limitsLock.Unlock()
+	limits = l
+}
+
+// getLimits returns the limits of the requests sent to the given webhook.
+func getLimits(hook *model.Webhook) Limits {
+	limitsLock.RLock()
+	l := limits
+	limitsLock.RUnlock()
+
+	if hook.Timeout > 0 {
+		l.Timeout = time.Duration(hook.Timeout) * time.Second
+	}
+	if hook.MaxRedirects > 0 {
+		l.MaxRedirects = hook.MaxRedirects
+	} else if hook.MaxRedirects < 0 {
+		l.MaxRedirects = 0
+	}
+	if hook.MaxPayloadSize > 0 {
+		l.MaxPayloadSize = hook.MaxPayloadSize
+	}
+
+	return l
+}
+
+// newClient returns the client sending requests within the given limits.
+func newClient(l Limits) *http.Client {
+	return &http.Client{
+		Timeout:   l.Timeout,
+		Transport: getTransport(),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > l.MaxRedirects {
+				return errors.Errorf("stopped after %d redirects", l.MaxRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// truncatePayload shrinks the given JSON payload to at most maxSize bytes,
+// returning whether it did. The truncated payload stays valid JSON, and is
+// flagged with "truncated" and the "original_size" of the payload. The
+// longest strings are cut first, down to minTruncatedString, then the
+// longest arrays lose their last items, so that a payload is always
+// truncated the same way. A payload that cannot be shrunk enough is replaced
+// by its flags alone.
+func truncatePayload(payloadStr string, maxSize int) (string, bool) {
+	if maxSize <= 0 || len(payloadStr) <= maxSize {
+		return payloadStr, false
+	}
+
+	flags := map[string]interface{}{
+		"truncated":     true,
+		"original_size": len(payloadStr),
+	}
+	// Numbers are kept as they are, as timestamps in nanoseconds do not fit
+	// in a float64.
+	var payload map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(payloadStr))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil || payload == nil {
+		return marshalPayload(flags), true
+	}
+	for key, value := range flags {
+		payload[key] = value
+	}
+
+	for maxString := longestString(payload) / 2; maxString >= minTruncatedString; maxString /= 2 {
+		truncated := marshalPayload(shrinkValue(payload, maxString, -1))
+		if len(truncated) <= maxSize {
+			return truncated, true
+		}
+	}
+	for maxItems := longestArray(payload) / 2; ; maxItems /= 2 {
+		truncated := marshalPayload(shrinkValue(payload, minTruncatedString, maxItems))
+		if len(truncated) <= maxSize {
+			return truncated, true
+		}
+		if maxItems == 0 {
+			break
+		}
+	}
+
+	return marshalPayload(flags), true
+}
+
+// shrinkValue returns a copy of the given decoded JSON value with its
+// strings cut to maxString bytes, and its arrays to maxItems items unless
+// negative.
+func shrinkValue(value interface{}, maxString, maxItems int) interface{} {
+	switch v := value.(type) {
+	case string:
+		if len(v) <= maxString {
+			return v
+		}
+		cut := maxString - len(truncatedSuffix)
+		for cut > 0 && !utf8.RuneStart(v[cut]) {
+			cut--
+		}
+		return v[:cut] + truncatedSuffix
+	case []interface{}:
+		if maxItems >= 0 && len(v) > maxItems {
+			v = v[:maxItems]
+		}
+		shrunk := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			shrunk = append(shrunk, shrinkValue(item, maxString, maxItems))
+		}
+		return shrunk
+	case map[string]interface{}:
+		shrunk := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			shrunk[key] = shrinkValue(item, maxString, maxItems)
+		}
+		return shrunk
+	}
+
+	return value
+}
+
+// longestString returns the length of the longest string of the given
+// decoded JSON value.
+func longestString(value interface{}) int {
+	longest := 0
+	walkValue(value, func(v interface{}) {
+		if s, ok := v.(string); ok && len(s) > longest {
+			longest = len(s)
+		}
+	})
+
+	return longest
+}
+
+// longestArray returns the length of the longest array of the given decoded
+// JSON value.
+func longestArray(value interface{}) int {
+	longest := 0
+	walkValue(value, func(v interface{}) {
+		if a, ok := v.([]interface{}); ok && len(a) > longest {
+			longest = len(a)
+		}
+	})
+
+	return longest
+}
+
+// walkValue calls visit on the given decoded JSON value and every value it
+// holds.
+func walkValue(value interface{}, visit func(interface{})) {
+	visit(value)
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			walkValue(item, visit)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			walkValue(item, visit)
+		}
+	}
+}
+
+// marshalPayload encodes the given decoded JSON value. Maps are encoded with
+// sorted keys.
+func marshalPayload(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLimits(t *testing.T) {
+	SetLimits(Limits{Timeout: 5 * time.Second, MaxRedirects: 10, MaxPayloadSize: 2048})
+	defer SetLimits(DefaultLimits)
+
+	require.Equal(t, Limits{Timeout: 5 * time.Second, MaxRedirects: 10, MaxPayloadSize: 2048}, getLimits(&model.Webhook{}))
+	require.Equal(t, Limits{Timeout: 30 * time.Second, MaxRedirects: 3, MaxPayloadSize: 4096}, getLimits(&model.Webhook{Timeout: 30, MaxRedirects: 3, MaxPayloadSize: 4096}))
+	require.Equal(t, 0, getLimits(&model.Webhook{MaxRedirects: -1}).MaxRedirects)
+}
+
+func TestPostLimits(t *testing.T) {
+	defer SetLimits(DefaultLimits)
+
+	redirects := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			redirects++
+			http.Redirect(w, r, "/ok", http.StatusTemporaryRedirect)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("redirects", func(t *testing.T) {
+		_, err := post(&model.Webhook{URL: server.URL + "/redirect"}, "{}", "")
+		require.NoError(t, err)
+
+		_, err = post(&model.Webhook{URL: server.URL + "/redirect", MaxRedirects: -1}, "{}", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "stopped after 0 redirects")
+		require.Equal(t, 2, redirects)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		SetLimits(Limits{Timeout: 50 * time.Millisecond, MaxRedirects: 10})
+		_, err := post(&model.Webhook{URL: server.URL + "/slow"}, "{}", "")
+		require.Error(t, err)
+
+		_, err = post(&model.Webhook{URL: server.URL + "/slow", Timeout: 1}, "{}", "")
+		require.NoError(t, err)
+	})
+}
+
+func TestTruncatePayload(t *testing.T) {
+	payload := &model.WebhookPayload{
+		Timestamp: 1700000000123456789,
+		ID:        "ring1",
+		Type:      model.TypeRing,
+		NewState:  model.RingStateStable,
+		OldState:  model.RingStateReleaseInProgress,
+		ExtraData: map[string]string{
+			"Message": strings.Repeat("a", 3000),
+			"RingID":  "ring1",
+		},
+	}
+	payloadStr, err := payload.ToJSON()
+	require.NoError(t, err)
+
+	t.Run("within limit", func(t *testing.T) {
+		truncated, ok := truncatePayload(payloadStr, 0)
+		require.False(t, ok)
+		require.Equal(t, payloadStr, truncated)
+
+		truncated, ok = truncatePayload(payloadStr, len(payloadStr))
+		require.False(t, ok)
+		require.Equal(t, payloadStr, truncated)
+	})
+
+	t.Run("long strings", func(t *testing.T) {
+		truncated, ok := truncatePayload(payloadStr, 1024)
+		require.True(t, ok)
+		require.LessOrEqual(t, len(truncated), 1024)
+
+		again, _ := truncatePayload(payloadStr, 1024)
+		require.Equal(t, truncated, again)
+
+		var decoded map[string]interface{}
+		decoder := json.NewDecoder(strings.NewReader(truncated))
+		decoder.UseNumber()
+		require.NoError(t, decoder.Decode(&decoded))
+		require.Equal(t, true, decoded["truncated"])
+		require.Equal(t, json.Number("1700000000123456789"), decoded["timestamp"])
+		require.Equal(t, model.RingStateStable, decoded["new_state"])
+		extraData := decoded["extra_data"].(map[string]interface{})
+		require.Equal(t, "ring1", extraData["RingID"])
+		require.True(t, strings.HasSuffix(extraData["Message"].(string), truncatedSuffix))
+	})
+
+	t.Run("long arrays", func(t *testing.T) {
+		digest := &model.WebhookDigestPayload{ID: "ring1", Type: model.TypeDigest}
+		for i := 0; i < 100; i++ {
+			digest.Events = append(digest.Events, payload)
+		}
+		digestStr, err := digest.ToJSON()
+		require.NoError(t, err)
+
+		truncated, ok := truncatePayload(digestStr, 4096)
+		require.True(t, ok)
+		require.LessOrEqual(t, len(truncated), 4096)
+
+		decoded := &model.WebhookDigestPayload{}
+		require.NoError(t, json.Unmarshal([]byte(truncated), decoded))
+		require.NotEmpty(t, decoded.Events)
+		require.Less(t, len(decoded.Events), 100)
+		require.Equal(t, payload.Timestamp, decoded.Events[0].Timestamp)
+	})
+
+	t.Run("not json", func(t *testing.T) {
+		truncated, ok := truncatePayload(strings.Repeat("a", 2000), 1024)
+		require.True(t, ok)
+		require.Equal(t, `{"original_size":2000,"truncated":true}`, truncated)
+	})
+}
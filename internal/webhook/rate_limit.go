@@ -0,0 +1,113 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// rateLimitWindow is the period the rate limits of webhooks apply to.
+const rateLimitWindow = time.Minute
+
+var limiter = newRateLimiter(rateLimitWindow)
+
+// webhookRate is the use of the rate limit of a webhook in its current
+// window.
+type webhookRate struct {
+	start      time.Time
+	sent       int
+	suppressed int
+}
+
+// rateLimiter caps the payloads sent to each webhook per window, to protect
+// small receivers during mass operations. The payloads over the limit are
+// counted and summarized to the webhook once the window is over.
+type rateLimiter struct {
+	window time.Duration
+
+	lock  sync.Mutex
+	rates map[string]*webhookRate
+}
+
+func newRateLimiter(window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		window: window,
+		rates:  make(map[string]*webhookRate),
+	}
+}
+
+// allow returns whether a send of the given number of events to the webhook
+// is within its rate limit, counting it. Critical sends, such as failures,
+// are always allowed, but count towards the limit. The events of a send that
+// is not allowed are suppressed.
+func (l *rateLimiter) allow(hook *model.Webhook, events int, critical bool, logger *log.Entry) bool {
+	if hook.RateLimit <= 0 {
+		return true
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	rate, ok := l.rates[hook.ID]
+	if !ok {
+		rate = &webhookRate{start: now}
+		l.rates[hook.ID] = rate
+	} else if now.Sub(rate.start) >= l.window {
+		rate.start = now
+		rate.sent = 0
+	}
+
+	if critical || rate.sent < hook.RateLimit {
+		rate.sent++
+		return true
+	}
+
+	if rate.suppressed == 0 {
+		// The summary follows the end of the window the first suppressed
+		// payload fell in.
+		time.AfterFunc(rate.start.Add(l.window).Sub(now), func() { l.summarize(hook, logger) })
+	}
+	rate.suppressed += events
+	logger.Debugf("Suppressed %d event(s) over the rate limit of webhook %s", events, hook.ID)
+
+	return false
+}
+
+// summarize sends the webhook the number of events suppressed since its last
+// summary, if any.
+func (l *rateLimiter) summarize(hook *model.Webhook, logger *log.Entry) {
+	l.lock.Lock()
+	rate, ok := l.rates[hook.ID]
+	var suppressed int
+	if ok {
+		suppressed = rate.suppressed
+		rate.suppressed = 0
+	}
+	l.lock.Unlock()
+
+	if suppressed == 0 {
+		return
+	}
+
+	logger.Infof("Suppressed %d event(s) over the rate limit of webhook %s", suppressed, hook.ID)
+
+	payload := &model.WebhookPayload{
+		Timestamp: time.Now().UnixNano(),
+		ID:        hook.ID,
+		Type:      model.TypeSuppressed,
+		ExtraData: map[string]string{
+			"Suppressed": strconv.Itoa(suppressed),
+			"Message":    fmt.Sprintf("%d more transitions suppressed", suppressed),
+		},
+	}
+	dispatch(hook, func() { sendWebhook(hook, payload, logger) }) //nolint
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/internal/testlib"
+	"github.com/mattermost/elrond/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	logger := testlib.MakeLogger(t).WithFields(log.Fields{
+		"webhooks-tests": true,
+	})
+
+	var lock sync.Mutex
+	var received []*model.WebhookPayload
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		payload := model.WebhookPayload{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		received = append(received, &payload)
+	}))
+	defer ts.Close()
+
+	limiter := newRateLimiter(200 * time.Millisecond)
+
+	t.Run("no limit", func(t *testing.T) {
+		hook := &model.Webhook{ID: model.NewID(), URL: ts.URL}
+		for i := 0; i < 10; i++ {
+			require.True(t, limiter.allow(hook, 1, false, logger))
+		}
+	})
+
+	t.Run("payloads over the limit are summarized", func(t *testing.T) {
+		hook := &model.Webhook{ID: model.NewID(), URL: ts.URL, RateLimit: 2}
+		require.True(t, limiter.allow(hook, 1, false, logger))
+		require.True(t, limiter.allow(hook, 1, false, logger))
+		require.False(t, limiter.allow(hook, 1, false, logger))
+		require.False(t, limiter.allow(hook, 3, false, logger))
+		// Failures are always sent.
+		require.True(t, limiter.allow(hook, 1, true, logger))
+
+		require.Eventually(t, func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+			return len(received) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		lock.Lock()
+		summary := received[0]
+		lock.Unlock()
+		require.Equal(t, model.TypeSuppressed, summary.Type)
+		require.Equal(t, hook.ID, summary.ID)
+		require.Equal(t, "4", summary.ExtraData["Suppressed"])
+		require.Equal(t, "4 more transitions suppressed", summary.ExtraData["Message"])
+
+		// The next window starts afresh.
+		require.True(t, limiter.allow(hook, 1, false, logger))
+	})
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// teamsMessage is a Microsoft Teams incoming webhook message carrying a
+// single Adaptive Card.
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string        `json:"$schema"`
+	Type    string        `json:"type"`
+	Version string        `json:"version"`
+	Body    []interface{} `json:"body"`
+}
+
+type teamsTextBlock struct {
+	Type   string `json:"type"`
+	Text   string `json:"text"`
+	Weight string `json:"weight,omitempty"`
+	Size   string `json:"size,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Wrap   bool   `json:"wrap"`
+}
+
+type teamsFactSet struct {
+	Type  string      `json:"type"`
+	Facts []teamsFact `json:"facts"`
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// formatPayload returns the body of the given payload in the format of the
+// given webhook.
+func formatPayload(hook *model.Webhook, payload *model.WebhookPayload) (string, error) {
+	if hook.Format != model.WebhookFormatTeams {
+		return payload.ToJSON()
+	}
+	if payload.Type == model.TypeSuppressed {
+		return toTeamsMessage([]interface{}{teamsTextBlock{
+			Type:   "TextBlock",
+			Text:   payload.ExtraData["Message"],
+			Weight: "Bolder",
+			Wrap:   true,
+		}})
+	}
+
+	notificationTemplate, err := teamsTemplate(hook)
+	if err != nil {
+		return "", err
+	}
+	blocks, err := teamsPayloadBlocks(notificationTemplate, payload)
+	if err != nil {
+		return "", err
+	}
+
+	return toTeamsMessage(blocks)
+}
+
+// formatDigest returns the body of the given digest in the format of the
+// given webhook.
+func formatDigest(hook *model.Webhook, digest *model.WebhookDigestPayload) (string, error) {
+	if hook.Format != model.WebhookFormatTeams {
+		return digest.ToJSON()
+	}
+
+	blocks := []interface{}{teamsTextBlock{
+		Type:   "TextBlock",
+		Text:   fmt.Sprintf("Digest of %d events for %s", len(digest.Events), digest.ID),
+		Weight: "Bolder",
+		Size:   "Large",
+		Wrap:   true,
+	}}
+
+	return toTeamsEventsMessage(hook, blocks, digest.Events)
+}
+
+// formatBatch returns the body of the given batch in the format of the given
+// webhook.
+func formatBatch(hook *model.Webhook, batch *model.WebhookBatchPayload) (string, error) {
+	if hook.Format != model.WebhookFormatTeams {
+		return batch.ToJSON()
+	}
+
+	blocks := []interface{}{teamsTextBlock{
+		Type:   "TextBlock",
+		Text:   fmt.Sprintf("Batch of %d events", len(batch.Events)),
+		Weight: "Bolder",
+		Size:   "Large",
+		Wrap:   true,
+	}}
+
+	return toTeamsEventsMessage(hook, blocks, batch.Events)
+}
+
+// toTeamsEventsMessage returns the Teams message of the given header blocks
+// followed by the blocks of each of the given events, rendered for the given
+// webhook.
+func toTeamsEventsMessage(hook *model.Webhook, blocks []interface{}, events []*model.WebhookPayload) (string, error) {
+	notificationTemplate, err := teamsTemplate(hook)
+	if err != nil {
+		return "", err
+	}
+	for _, event := range events {
+		eventBlocks, err := teamsPayloadBlocks(notificationTemplate, event)
+		if err != nil {
+			return "", err
+		}
+		blocks = append(blocks, eventBlocks...)
+	}
+
+	return toTeamsMessage(blocks)
+}
+
+// teamsPayloadBlocks returns the card elements describing a payload: a title
+// followed by the facts of the transition. With a notification template, the
+// title is its rendered subject, unless empty, and its rendered body follows
+// the title.
+func teamsPayloadBlocks(notificationTemplate *model.NotificationTemplate, payload *model.WebhookPayload) ([]interface{}, error) {
+	title := teamsTextBlock{
+		Type:   "TextBlock",
+		Text:   fmt.Sprintf("%s %s is now %s", payload.Type, payload.ID, payload.NewState),
+		Weight: "Bolder",
+		Size:   "Medium",
+		Wrap:   true,
+	}
+	if payload.IsFailure() {
+		title.Color = "Attention"
+	}
+	blocks := []interface{}{title}
+
+	if notificationTemplate != nil {
+		subject, body, err := notificationTemplate.Render(payload.TemplateData())
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(subject) != "" {
+			title.Text = subject
+			blocks[0] = title
+		}
+		blocks = append(blocks, teamsTextBlock{Type: "TextBlock", Text: body, Wrap: true})
+	}
+
+	facts := []teamsFact{
+		{Title: "Old state", Value: payload.OldState},
+		{Title: "New state", Value: payload.NewState},
+		{Title: "Time", Value: time.Unix(0, payload.Timestamp).UTC().Format(time.RFC3339)},
+	}
+	keys := make([]string, 0, len(payload.ExtraData))
+	for key := range payload.ExtraData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		facts = append(facts, teamsFact{Title: key, Value: payload.ExtraData[key]})
+	}
+
+	return append(blocks, teamsFactSet{Type: "FactSet", Facts: facts}), nil
+}
+
+func toTeamsMessage(body []interface{}) (string, error) {
+	message := teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: teamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+			},
+		}},
+	}
+
+	b, err := json.Marshal(message)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal teams message")
+	}
+
+	return string(b), nil
+}
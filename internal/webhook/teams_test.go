@@ -0,0 +1,134 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatPayload(t *testing.T) {
+	payload := &model.WebhookPayload{
+		Type:      model.TypeRing,
+		ID:        "ring1",
+		NewState:  model.RingStateReleaseFailed,
+		OldState:  model.RingStateReleaseInProgress,
+		Timestamp: time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC).UnixNano(),
+		ExtraData: map[string]string{"ReleaseType": "hotfix", "Environment": "prod"},
+	}
+
+	t.Run("elrond", func(t *testing.T) {
+		body, err := formatPayload(&model.Webhook{Format: model.WebhookFormatElrond}, payload)
+		require.NoError(t, err)
+
+		expected, err := payload.ToJSON()
+		require.NoError(t, err)
+		require.Equal(t, expected, body)
+	})
+
+	t.Run("teams", func(t *testing.T) {
+		body, err := formatPayload(&model.Webhook{Format: model.WebhookFormatTeams}, payload)
+		require.NoError(t, err)
+
+		var message teamsMessage
+		require.NoError(t, json.Unmarshal([]byte(body), &message))
+		require.Equal(t, "message", message.Type)
+		require.Len(t, message.Attachments, 1)
+		require.Equal(t, "application/vnd.microsoft.card.adaptive", message.Attachments[0].ContentType)
+
+		card := message.Attachments[0].Content
+		require.Equal(t, "AdaptiveCard", card.Type)
+		require.Len(t, card.Body, 2)
+
+		title := card.Body[0].(map[string]interface{})
+		require.Equal(t, "ring ring1 is now release-failed", title["text"])
+		require.Equal(t, "Attention", title["color"])
+
+		facts := card.Body[1].(map[string]interface{})["facts"].([]interface{})
+		require.Equal(t, []interface{}{
+			map[string]interface{}{"title": "Old state", "value": model.RingStateReleaseInProgress},
+			map[string]interface{}{"title": "New state", "value": model.RingStateReleaseFailed},
+			map[string]interface{}{"title": "Time", "value": "2024-03-05T12:00:00Z"},
+			map[string]interface{}{"title": "Environment", "value": "prod"},
+			map[string]interface{}{"title": "ReleaseType", "value": "hotfix"},
+		}, facts)
+	})
+
+	t.Run("teams localized", func(t *testing.T) {
+		SetTemplateStore(&mockTemplateStore{templates: []*model.NotificationTemplate{
+			{Channel: model.NotificationChannelTeams, Event: model.NotificationStateChange, Language: "de", Subject: "{{.Type}} {{.ID}} ist jetzt {{.NewState}}", Body: "Umgebung: {{index .ExtraData \"Environment\"}}"},
+		}})
+		defer SetTemplateStore(nil)
+
+		body, err := formatPayload(&model.Webhook{Format: model.WebhookFormatTeams, Language: "de"}, payload)
+		require.NoError(t, err)
+
+		var message teamsMessage
+		require.NoError(t, json.Unmarshal([]byte(body), &message))
+		card := message.Attachments[0].Content
+		require.Len(t, card.Body, 3)
+		require.Equal(t, "ring ring1 ist jetzt release-failed", card.Body[0].(map[string]interface{})["text"])
+		require.Equal(t, "Attention", card.Body[0].(map[string]interface{})["color"])
+		require.Equal(t, "Umgebung: prod", card.Body[1].(map[string]interface{})["text"])
+
+		body, err = formatPayload(&model.Webhook{Format: model.WebhookFormatTeams, Language: "fr"}, payload)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal([]byte(body), &message))
+		card = message.Attachments[0].Content
+		require.Len(t, card.Body, 2)
+		require.Equal(t, "ring ring1 is now release-failed", card.Body[0].(map[string]interface{})["text"])
+	})
+}
+
+func TestFormatDigest(t *testing.T) {
+	digest := &model.WebhookDigestPayload{
+		Type: model.TypeDigest,
+		ID:   "ring1",
+		Events: []*model.WebhookPayload{
+			{Type: model.TypeRing, ID: "ring1", OldState: model.RingStateReleasePending, NewState: model.RingStateReleaseRequested},
+			{Type: model.TypeRing, ID: "ring1", OldState: model.RingStateReleaseRequested, NewState: model.RingStateReleaseInProgress},
+		},
+	}
+
+	t.Run("elrond", func(t *testing.T) {
+		body, err := formatDigest(&model.Webhook{}, digest)
+		require.NoError(t, err)
+
+		expected, err := digest.ToJSON()
+		require.NoError(t, err)
+		require.Equal(t, expected, body)
+	})
+
+	t.Run("teams", func(t *testing.T) {
+		body, err := formatDigest(&model.Webhook{Format: model.WebhookFormatTeams}, digest)
+		require.NoError(t, err)
+
+		var message teamsMessage
+		require.NoError(t, json.Unmarshal([]byte(body), &message))
+
+		card := message.Attachments[0].Content
+		require.Len(t, card.Body, 5)
+		require.Equal(t, "Digest of 2 events for ring1", card.Body[0].(map[string]interface{})["text"])
+	})
+}
+
+type mockTemplateStore struct {
+	templates []*model.NotificationTemplate
+}
+
+func (s *mockTemplateStore) GetNotificationTemplates(filter *model.NotificationTemplateFilter) ([]*model.NotificationTemplate, error) {
+	var templates []*model.NotificationTemplate
+	for _, t := range s.templates {
+		if t.Channel == filter.Channel && t.Event == filter.Event {
+			templates = append(templates, t)
+		}
+	}
+
+	return templates, nil
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webhook
+
+import (
+	"sync"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/pkg/errors"
+)
+
+// templateStore abstracts the database operations required to localize the
+// payloads sent to webhooks.
+type templateStore interface {
+	GetNotificationTemplates(filter *model.NotificationTemplateFilter) ([]*model.NotificationTemplate, error)
+}
+
+var (
+	templatesLock sync.RWMutex
+	templates     templateStore
+)
+
+// SetTemplateStore configures the store of the notification templates the
+// Teams cards of webhooks with a language are rendered with. Passing nil
+// sends the built-in cards to every webhook.
+func SetTemplateStore(store templateStore) {
+	templatesLock.Lock()
+	defer templatesLock.Unlock()
+	templates = store
+}
+
+func getTemplateStore() templateStore {
+	templatesLock.RLock()
+	defer templatesLock.RUnlock()
+	return templates
+}
+
+// teamsTemplate returns the notification template the Teams cards sent to
+// the given webhook are rendered with, or nil if the built-in cards are sent.
+func teamsTemplate(hook *model.Webhook) (*model.NotificationTemplate, error) {
+	store := getTemplateStore()
+	if store == nil || hook.Language == "" {
+		return nil, nil
+	}
+
+	notificationTemplates, err := store.GetNotificationTemplates(&model.NotificationTemplateFilter{
+		Channel: model.NotificationChannelTeams,
+		Event:   model.NotificationStateChange,
+		PerPage: model.AllPerPage,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get notification templates")
+	}
+
+	return model.FindNotificationTemplate(notificationTemplates, hook.Language), nil
+}
@@ -6,19 +6,41 @@ package webhook
 
 import (
 	"bytes"
+	"io"
+	"io/ioutil"
 	"net/http"
-	"time"
+	"sync"
 
 	"github.com/mattermost/elrond/model"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
+var (
+	transportLock sync.RWMutex
+	transport     http.RoundTripper
+)
+
+// SetTransport configures the transport webhooks are sent through, such as
+// one going through a proxy. Passing nil uses http.DefaultTransport.
+func SetTransport(t http.RoundTripper) {
+	transportLock.Lock()
+	defer transportLock.Unlock()
+	transport = t
+}
+
+func getTransport() http.RoundTripper {
+	transportLock.RLock()
+	defer transportLock.RUnlock()
+	return transport
+}
+
 type webhookStore interface {
 	GetWebhooks(filter *model.WebhookFilter) ([]*model.Webhook, error)
 }
 
-// SendToAllWebhooks sends a given payload to all webhooks.
+// SendToAllWebhooks sends a given payload to all webhooks whose label selector
+// matches it.
 func SendToAllWebhooks(store webhookStore, payload *model.WebhookPayload, logger *log.Entry) error {
 	hooks, err := store.GetWebhooks(&model.WebhookFilter{
 		PerPage:        model.AllPerPage,
@@ -28,13 +50,37 @@ func SendToAllWebhooks(store webhookStore, payload *model.WebhookPayload, logger
 		return errors.Wrap(err, "Failed to find webhooks")
 	}
 
-	sendWebhooks(hooks, payload, logger)
+	if d := getDigester(); d != nil && payload != nil && !payload.IsFailure() {
+		d.Add(payload)
+		return nil
+	}
+
+	if b := getBatcher(); b != nil && payload != nil {
+		b.Add(matchingWebhooks(hooks, payload), payload)
+		return nil
+	}
+
+	sendWebhooks(matchingWebhooks(hooks, payload), payload, logger)
 
 	return nil
 }
 
-// sendWebhooks sends webhooks via fire-and-forget goroutines. The send-webhook
-// failures are logged, but not handled.
+// matchingWebhooks returns the webhooks whose label selector matches the
+// given payload.
+func matchingWebhooks(hooks []*model.Webhook, payload *model.WebhookPayload) []*model.Webhook {
+	var matching []*model.Webhook
+	for _, hook := range hooks {
+		if hook.Matches(payload) {
+			matching = append(matching, hook)
+		}
+	}
+
+	return matching
+}
+
+// sendWebhooks sends webhooks in the background, through the dispatcher when
+// there is one, within their rate limits. The send-webhook failures are
+// logged, but not handled.
 func sendWebhooks(hooks []*model.Webhook, payload *model.WebhookPayload, logger *log.Entry) {
 	if len(hooks) == 0 {
 		return
@@ -42,30 +88,99 @@ func sendWebhooks(hooks []*model.Webhook, payload *model.WebhookPayload, logger
 
 	logger.Debugf("Sending %d webhook(s)", len(hooks))
 
+	critical := payload != nil && payload.IsFailure()
 	for _, hook := range hooks {
-		go sendWebhook(hook, payload, logger) //nolint
+		if !limiter.allow(hook, 1, critical, logger) {
+			continue
+		}
+		hook := hook
+		dispatch(hook, func() { sendWebhook(hook, payload, logger) }) //nolint
 	}
 }
 
+// Replay sends the given payloads to the webhook in order, in the
+// background, skipping those its label selector does not match. Failures are
+// logged, but do not stop the replay. It returns the number of payloads to be
+// sent.
+func Replay(hook *model.Webhook, payloads []*model.WebhookPayload, logger *log.Entry) int {
+	matching := []*model.WebhookPayload{}
+	for _, payload := range payloads {
+		if hook.Matches(payload) {
+			matching = append(matching, payload)
+		}
+	}
+	if len(matching) == 0 {
+		return 0
+	}
+
+	logger.Infof("Replaying %d payload(s) to webhook %s", len(matching), hook.ID)
+
+	go func() {
+		for _, payload := range matching {
+			payload := payload
+			runDispatched(hook, func() { sendWebhook(hook, payload, logger) }) //nolint
+		}
+	}()
+
+	return len(matching)
+}
+
 func sendWebhook(hook *model.Webhook, payload *model.WebhookPayload, logger *log.Entry) error {
-	payloadStr, err := payload.ToJSON()
+	payloadStr, err := formatPayload(hook, payload)
 	if err != nil {
 		logger.WithField("webhookURL", hook.URL).WithError(err).Error("Unable to create payload string to send to webhook")
 		return errors.Wrap(err, "unable to create payload string to send to webhook")
 	}
 
+	return postWebhook(hook, payloadStr, logger)
+}
+
+// postWebhook sends the given payload to the webhook, through the deliverer
+// when there is one, truncated to the max payload size of the webhook.
+func postWebhook(hook *model.Webhook, payloadStr string, logger *log.Entry) error {
+	if truncated, ok := truncatePayload(payloadStr, getLimits(hook).MaxPayloadSize); ok {
+		logger.WithField("webhookURL", hook.URL).Warnf("Truncated webhook payload of %d bytes to %d bytes", len(payloadStr), len(truncated))
+		payloadStr = truncated
+	}
+
+	if d := getDeliverer(); d != nil {
+		return d.Deliver(hook, payloadStr)
+	}
+
+	if _, err := post(hook, payloadStr, ""); err != nil {
+		logger.WithField("webhookURL", hook.URL).WithError(err).Error("Unable to send webhook")
+		return err
+	}
+
+	return nil
+}
+
+// post sends the given payload to the webhook, signed with its secret if it
+// has one, returning the response status code. Responses other than 2xx are
+// failures.
+func post(hook *model.Webhook, payloadStr string, deliveryID string) (int, error) {
 	req, err := http.NewRequest("POST", hook.URL, bytes.NewBuffer([]byte(payloadStr)))
 	if err != nil {
-		logger.WithField("webhookURL", hook.URL).WithError(err).Error("Unable to create webhook request")
-		return errors.Wrap(err, "unable to create webhook request")
+		return 0, errors.Wrap(err, "unable to create webhook request")
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if hook.Secret != "" {
+		req.Header.Set(model.WebhookSignatureHeader, model.SignWebhookPayload(hook.Secret, payloadStr))
+	}
+	if deliveryID != "" {
+		req.Header.Set(model.WebhookDeliveryHeader, deliveryID)
+	}
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	if _, err = client.Do(req); err != nil {
-		logger.WithField("webhookURL", hook.URL).WithError(err).Error("Unable to send webhook")
-		return errors.Wrap(err, "unable to send webhook")
+	resp, err := newClient(getLimits(hook)).Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to send webhook")
 	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body) //nolint
 
-	return nil
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, errors.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
 }
@@ -0,0 +1,252 @@
+// Read-only web UI of elrond, rendering the rings and recent events from the
+// API of the server it is served by.
+"use strict";
+
+const refreshInterval = 15000;
+const eventsWindow = 24 * 3600 * 1000;
+const maxEvents = 50;
+const tokenKey = "elrond-api-token";
+
+const failedStates = ["creation-failed", "release-failed", "soaking-failed", "release-rollback-failed", "deletion-failed"];
+const activeStates = ["release-requested", "release-prepare-requested", "release-prepared", "release-in-progress", "soaking-requested", "release-rollback-requested"];
+
+class UnauthorizedError extends Error {}
+
+async function getJSON(path) {
+  const headers = { Accept: "application/json" };
+  const token = sessionStorage.getItem(tokenKey);
+  if (token) {
+    headers.Authorization = "Bearer " + token;
+  }
+
+  const response = await fetch(path, { headers });
+  if (response.status === 401) {
+    throw new UnauthorizedError();
+  }
+  if (!response.ok) {
+    throw new Error(path + " returned status " + response.status);
+  }
+
+  return response.json();
+}
+
+async function getRecentEvents() {
+  const events = [];
+  let after = "";
+  // Events are served oldest first, so page through the window.
+  for (;;) {
+    let path = "/api/v1/events?per_page=1000&from=" + (Date.now() - eventsWindow);
+    if (after) {
+      path += "&after=" + encodeURIComponent(after);
+    }
+    const page = await getJSON(path);
+    events.push(...page.Events);
+    if (page.Events.length < 1000) {
+      break;
+    }
+    after = page.After;
+  }
+
+  return events.slice(-maxEvents).reverse();
+}
+
+function element(tag, text, className) {
+  const node = document.createElement(tag);
+  if (text !== undefined) {
+    node.textContent = text;
+  }
+  if (className) {
+    node.className = className;
+  }
+  return node;
+}
+
+function stateBadge(state) {
+  let className = "state";
+  if (state === "stable") {
+    className += " stable";
+  } else if (failedStates.includes(state)) {
+    className += " failed";
+  } else if (activeStates.includes(state)) {
+    className += " active";
+  }
+  return element("span", state, className);
+}
+
+function formatTime(millis) {
+  return new Date(millis).toLocaleString();
+}
+
+function formatDuration(millis) {
+  const seconds = Math.max(0, Math.round(millis / 1000));
+  const hours = Math.floor(seconds / 3600);
+  const minutes = Math.floor((seconds % 3600) / 60);
+  const parts = [];
+  if (hours > 0) {
+    parts.push(hours + "h");
+  }
+  if (hours > 0 || minutes > 0) {
+    parts.push(minutes + "m");
+  }
+  parts.push((seconds % 60) + "s");
+  return parts.join(" ");
+}
+
+// countdown renders the time left until the given time, in milliseconds,
+// updated every second. Soak windows, if any, may extend the soak further.
+function countdown(label, endsAt) {
+  const node = element("div");
+  node.dataset.label = label;
+  node.dataset.endsAt = endsAt;
+  updateCountdown(node);
+  return node;
+}
+
+function updateCountdown(node) {
+  const left = Number(node.dataset.endsAt) - Date.now();
+  node.textContent = node.dataset.label + (left > 0 ? formatDuration(left) + " left" : "ending");
+}
+
+function releaseProgress(ring) {
+  const groups = ring.installationGroups || [];
+  const cell = element("td");
+  if (!activeStates.includes(ring.State) || groups.length === 0) {
+    cell.textContent = "—";
+    return cell;
+  }
+
+  const released = groups.filter((group) => group.state === "stable").length;
+  const progress = element("progress");
+  progress.max = groups.length;
+  progress.value = released;
+  cell.append(progress, " " + released + "/" + groups.length + " installation groups");
+
+  const list = element("ul", undefined, "groups");
+  for (const group of groups) {
+    if (group.state === "stable" || group.state === "release-pending") {
+      continue;
+    }
+    let text = group.name + ": " + group.state;
+    if (group.releaseProgress) {
+      text += " (" + group.releaseProgress + "%)";
+    }
+    list.append(element("li", text));
+  }
+  cell.append(list);
+  return cell;
+}
+
+function soak(ring) {
+  const cell = element("td");
+  if (ring.State === "soaking-requested" && ring.ReleaseAt) {
+    cell.append(countdown("ring: ", ring.ReleaseAt / 1e6 + ring.ReleaseSoakTime * 1000));
+  }
+  for (const group of ring.installationGroups || []) {
+    if (group.state === "release-soaking-requested" && group.releaseAt) {
+      cell.append(countdown(group.name + ": ", group.releaseAt / 1e6 + (group.releaseSoakTime || 0) * 1000));
+    }
+  }
+  if (!cell.hasChildNodes()) {
+    cell.textContent = "—";
+  }
+  return cell;
+}
+
+function renderRings(rings) {
+  const body = document.querySelector("#rings tbody");
+  const rows = rings
+    .slice()
+    .sort((a, b) => a.Priority - b.Priority || a.Name.localeCompare(b.Name))
+    .map((ring) => {
+      const row = element("tr");
+      const name = element("td", ring.Name);
+      name.title = ring.ID;
+      const state = element("td");
+      state.append(stateBadge(ring.State));
+      row.append(
+        element("td", String(ring.Priority)),
+        name,
+        state,
+        releaseProgress(ring),
+        soak(ring),
+        element("td", ring.EstimatedCompletionAt ? formatTime(ring.EstimatedCompletionAt) : "—"),
+      );
+      return row;
+    });
+  if (rows.length === 0) {
+    const row = element("tr");
+    const cell = element("td", "No rings.");
+    cell.colSpan = 6;
+    row.append(cell);
+    rows.push(row);
+  }
+  body.replaceChildren(...rows);
+}
+
+function renderEvents(events, ringNames) {
+  const body = document.querySelector("#events tbody");
+  const rows = events.map((event) => {
+    const row = element("tr");
+    let resource = ringNames[event.RingID] || event.RingID;
+    if (event.ResourceType !== "ring") {
+      resource += " / " + event.ResourceType + " " + event.ResourceID;
+    }
+    const transition = element("td");
+    transition.append(stateBadge(event.OldState), " → ", stateBadge(event.NewState));
+    row.append(
+      element("td", formatTime(event.Timestamp)),
+      element("td", resource),
+      transition,
+      element("td", event.Error || "", "error"),
+    );
+    return row;
+  });
+  if (rows.length === 0) {
+    const row = element("tr");
+    const cell = element("td", "No events in the last 24 hours.");
+    cell.colSpan = 4;
+    row.append(cell);
+    rows.push(row);
+  }
+  body.replaceChildren(...rows);
+}
+
+function setStatus(text, isError) {
+  const status = document.getElementById("status");
+  status.textContent = text;
+  status.className = isError ? "error" : "";
+}
+
+async function refresh() {
+  try {
+    const [rings, events] = await Promise.all([getJSON("/api/v1/rings?per_page=-1"), getRecentEvents()]);
+    const ringNames = {};
+    for (const ring of rings) {
+      ringNames[ring.ID] = ring.Name;
+    }
+    renderRings(rings);
+    renderEvents(events, ringNames);
+    document.getElementById("token-form").hidden = true;
+    setStatus("Updated " + new Date().toLocaleTimeString());
+  } catch (error) {
+    if (error instanceof UnauthorizedError) {
+      sessionStorage.removeItem(tokenKey);
+      document.getElementById("token-form").hidden = false;
+      setStatus("Sign in required", true);
+      return;
+    }
+    setStatus("Failed to refresh: " + error.message, true);
+  }
+}
+
+document.getElementById("token-form").addEventListener("submit", (submitEvent) => {
+  submitEvent.preventDefault();
+  const input = document.getElementById("token");
+  sessionStorage.setItem(tokenKey, input.value.trim());
+  input.value = "";
+  refresh();
+});
+
+setInterval(() => document.querySelectorAll("[data-ends-at]").forEach(updateCountdown), 1000);
+setInterval(refresh, refreshInterval);
+refresh();
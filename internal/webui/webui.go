@@ -0,0 +1,51 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+// Package webui serves the read-only web UI embedded in the elrond binary,
+// showing the rings, their release progress and soak countdowns, and the
+// recent events, through the API of the server.
+package webui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Path is where the web UI is served.
+const Path = "/ui/"
+
+//go:embed static
+var static embed.FS
+
+// Register serves the web UI under Path on the given router.
+func Register(router *mux.Router) {
+	router.Handle(Path[:len(Path)-1], http.RedirectHandler(Path, http.StatusMovedPermanently))
+	router.PathPrefix(Path).Handler(Handler())
+}
+
+// Handler returns the handler of the web UI files, to be served under Path.
+func Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	fileServer := http.StripPrefix(Path, http.FileServer(http.FS(files)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		// The UI only loads its own files and calls the API of the server.
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "no-cache")
+		fileServer.ServeHTTP(w, r)
+	})
+}
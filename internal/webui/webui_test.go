@@ -0,0 +1,70 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package webui_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/elrond/internal/webui"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebUI(t *testing.T) {
+	router := mux.NewRouter()
+	webui.Register(router)
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	t.Run("index", func(t *testing.T) {
+		resp, err := client.Get(ts.URL + "/ui/")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+		require.Equal(t, "default-src 'self'; frame-ancestors 'none'", resp.Header.Get("Content-Security-Policy"))
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(body), `src="app.js"`)
+	})
+
+	t.Run("script", func(t *testing.T) {
+		resp, err := client.Get(ts.URL + "/ui/app.js")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Contains(t, resp.Header.Get("Content-Type"), "javascript")
+		require.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		resp, err := client.Get(ts.URL + "/ui")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+		require.Equal(t, "/ui/", resp.Header.Get("Location"))
+	})
+
+	t.Run("unknown file", func(t *testing.T) {
+		resp, err := client.Get(ts.URL + "/ui/unknown.js")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("post", func(t *testing.T) {
+		resp, err := client.Post(ts.URL+"/ui/", "text/plain", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
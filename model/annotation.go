@@ -0,0 +1,117 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// AnnotationHeaderPrefix prefixes the headers that forward annotations to
+	// the provisioner, e.g. X-Elrond-Annotation-Change-Ticket.
+	AnnotationHeaderPrefix = "X-Elrond-Annotation-"
+
+	// MaxAnnotations is the maximum number of annotations of a ring or
+	// installation group.
+	MaxAnnotations = 20
+
+	// MaxAnnotationValueLength is the maximum length of an annotation value.
+	MaxAnnotationValueLength = 256
+)
+
+var annotationNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]{0,62}$`)
+
+// Annotations are free-form metadata of a ring or installation group, such
+// as a change-ticket ID or the requesting team, forwarded to the provisioner
+// with every call made on their behalf.
+type Annotations map[string]string
+
+// Validate validates the annotation names and values. Names are sent as
+// header names, so they are limited to alphanumerics and dashes.
+func (a Annotations) Validate() error {
+	if len(a) > MaxAnnotations {
+		return errors.Errorf("cannot have more than %d annotations", MaxAnnotations)
+	}
+
+	for name, value := range a {
+		if !annotationNameRegex.MatchString(name) {
+			return errors.Errorf("invalid annotation name %q: must be alphanumerics and dashes, up to 63 characters", name)
+		}
+		if len(value) > MaxAnnotationValueLength {
+			return errors.Errorf("annotation %s value cannot be longer than %d characters", name, MaxAnnotationValueLength)
+		}
+	}
+
+	return nil
+}
+
+// Headers returns the annotations as the headers forwarded to the provisioner.
+func (a Annotations) Headers() map[string]string {
+	headers := make(map[string]string, len(a))
+	for name, value := range a {
+		headers[AnnotationHeaderPrefix+name] = value
+	}
+
+	return headers
+}
+
+// MergeAnnotations returns the union of the given annotations, with the
+// annotations in override taking precedence.
+func MergeAnnotations(base, override Annotations) Annotations {
+	merged := make(Annotations, len(base)+len(override))
+	for name, value := range base {
+		merged[name] = value
+	}
+	for name, value := range override {
+		merged[name] = value
+	}
+
+	return merged
+}
+
+// Value implements driver.Valuer, storing the annotations as JSON.
+func (a Annotations) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal annotations")
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner, loading the annotations from JSON.
+func (a *Annotations) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.Errorf("cannot scan %T into annotations", src)
+	}
+
+	var annotations Annotations
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return errors.Wrap(err, "failed to unmarshal annotations")
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	*a = annotations
+
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotationsValidate(t *testing.T) {
+	tooMany := model.Annotations{}
+	for i := 0; i <= model.MaxAnnotations; i++ {
+		tooMany[strings.Repeat("a", i+1)] = "value"
+	}
+
+	var testCases = []struct {
+		testName     string
+		annotations  model.Annotations
+		requireError bool
+	}{
+		{"nil", nil, false},
+		{"valid", model.Annotations{"Change-Ticket": "CHG-1234", "team": "platform"}, false},
+		{"invalid name", model.Annotations{"change ticket": "CHG-1234"}, true},
+		{"name starting with a dash", model.Annotations{"-team": "platform"}, true},
+		{"value too long", model.Annotations{"team": strings.Repeat("a", model.MaxAnnotationValueLength+1)}, true},
+		{"too many", tooMany, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.annotations.Validate())
+			} else {
+				assert.NoError(t, tc.annotations.Validate())
+			}
+		})
+	}
+}
+
+func TestAnnotationsHeaders(t *testing.T) {
+	annotations := model.Annotations{"Change-Ticket": "CHG-1234"}
+	require.Equal(t, map[string]string{"X-Elrond-Annotation-Change-Ticket": "CHG-1234"}, annotations.Headers())
+}
+
+func TestMergeAnnotations(t *testing.T) {
+	base := model.Annotations{"team": "platform", "Change-Ticket": "CHG-1"}
+	override := model.Annotations{"Change-Ticket": "CHG-2"}
+
+	merged := model.MergeAnnotations(base, override)
+	require.Equal(t, model.Annotations{"team": "platform", "Change-Ticket": "CHG-2"}, merged)
+	require.Equal(t, "CHG-1", base["Change-Ticket"])
+}
+
+func TestAnnotationsScan(t *testing.T) {
+	annotations := model.Annotations{"team": "platform"}
+	value, err := annotations.Value()
+	require.NoError(t, err)
+
+	var scanned model.Annotations
+	require.NoError(t, scanned.Scan(value))
+	require.Equal(t, annotations, scanned)
+
+	require.NoError(t, scanned.Scan([]byte("{}")))
+	require.Nil(t, scanned)
+
+	require.Error(t, scanned.Scan(1))
+}
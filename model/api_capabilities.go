@@ -0,0 +1,85 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// HeaderCapabilities is the response header listing the capabilities of the
+// server, separated by commas.
+const HeaderCapabilities = "X-Elrond-Capabilities"
+
+const (
+	// CapabilityPagination is reported by servers paginating their lists
+	// with the page and per_page parameters.
+	CapabilityPagination = "pagination"
+	// CapabilityEvents is reported by servers serving the state change
+	// events API.
+	CapabilityEvents = "events"
+	// CapabilityGRPC is reported by servers serving the gRPC API.
+	CapabilityGRPC = "grpc"
+)
+
+// APICapabilities describes the API versions and optional features of an
+// elrond server, so that clients can degrade gracefully when talking to
+// older servers.
+type APICapabilities struct {
+	// APIVersion is the API version the server selected for the client.
+	APIVersion int
+	// APIVersions are the API versions the server supports.
+	APIVersions []int
+	// Capabilities are the optional features of the API the server
+	// supports, such as CapabilityEvents.
+	Capabilities []string
+}
+
+// LegacyAPICapabilities are the capabilities assumed of servers predating
+// capability negotiation: the first API version, without any optional
+// feature.
+func LegacyAPICapabilities() *APICapabilities {
+	return &APICapabilities{
+		APIVersion:   APIVersion1,
+		APIVersions:  []int{APIVersion1},
+		Capabilities: []string{},
+	}
+}
+
+// Has returns whether the server supports the given capability.
+func (c *APICapabilities) Has(capability string) bool {
+	for _, supported := range c.Capabilities {
+		if supported == capability {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseCapabilities parses the capabilities listed in HeaderCapabilities.
+func ParseCapabilities(value string) []string {
+	capabilities := []string{}
+	for _, capability := range strings.Split(value, ",") {
+		if capability = strings.TrimSpace(capability); capability != "" {
+			capabilities = append(capabilities, capability)
+		}
+	}
+
+	return capabilities
+}
+
+// APICapabilitiesFromReader decodes a json-encoded APICapabilities from the
+// given io.Reader.
+func APICapabilitiesFromReader(reader io.Reader) (*APICapabilities, error) {
+	capabilities := APICapabilities{}
+	err := json.NewDecoder(reader).Decode(&capabilities)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &capabilities, nil
+}
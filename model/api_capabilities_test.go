@@ -0,0 +1,43 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPICapabilitiesHas(t *testing.T) {
+	capabilities := &APICapabilities{Capabilities: []string{CapabilityEvents, CapabilityPagination}}
+	require.True(t, capabilities.Has(CapabilityEvents))
+	require.False(t, capabilities.Has(CapabilityGRPC))
+	require.False(t, LegacyAPICapabilities().Has(CapabilityPagination))
+}
+
+func TestParseCapabilities(t *testing.T) {
+	require.Equal(t, []string{}, ParseCapabilities(""))
+	require.Equal(t, []string{CapabilityEvents, CapabilityPagination}, ParseCapabilities("events, pagination,"))
+}
+
+func TestAPICapabilitiesFromReader(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		capabilities, err := APICapabilitiesFromReader(bytes.NewReader([]byte("")))
+		require.NoError(t, err)
+		require.Equal(t, &APICapabilities{}, capabilities)
+	})
+
+	t.Run("capabilities", func(t *testing.T) {
+		expected := &APICapabilities{APIVersion: 1, APIVersions: []int{1}, Capabilities: []string{CapabilityEvents}}
+		data, err := json.Marshal(expected)
+		require.NoError(t, err)
+
+		capabilities, err := APICapabilitiesFromReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, expected, capabilities)
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// HeaderAPIVersion is the request header selecting the version of the
+	// API responses, or listing the versions the client supports by
+	// preference, such as "2, 1". The server echoes the version used in the
+	// response.
+	HeaderAPIVersion = "X-Elrond-Api-Version"
+
+	// APIVersion1 is the first version of the API.
+	APIVersion1 = 1
+
+	// CurrentAPIVersion is the latest version of the API, used for requests
+	// that do not select a version.
+	CurrentAPIVersion = APIVersion1
+)
+
+// SupportedAPIVersions are the API versions supported by this server and
+// client, from the latest.
+var SupportedAPIVersions = []int{APIVersion1}
+
+// ParseAPIVersion parses the API version selected by a request, such as "1"
+// or "v1", defaulting to the current version when none is selected. Given the
+// versions a client supports by preference, such as "2, 1", it selects the
+// first one supported, so that a newer client keeps working with an older
+// server during rolling upgrades.
+func ParseAPIVersion(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return CurrentAPIVersion, nil
+	}
+
+	var versions []int
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		version, err := strconv.Atoi(strings.TrimPrefix(item, "v"))
+		if err != nil {
+			return 0, errors.Errorf("invalid API version %q", item)
+		}
+		if version >= APIVersion1 && version <= CurrentAPIVersion {
+			return version, nil
+		}
+		versions = append(versions, version)
+	}
+	if len(versions) == 1 {
+		return 0, errors.Errorf("unsupported API version %d: must be between %d and %d", versions[0], APIVersion1, CurrentAPIVersion)
+	}
+
+	return 0, errors.Errorf("unsupported API versions %s: must be between %d and %d", FormatAPIVersions(versions), APIVersion1, CurrentAPIVersion)
+}
+
+// FormatAPIVersions formats the given API versions for HeaderAPIVersion.
+func FormatAPIVersions(versions []int) string {
+	items := make([]string, 0, len(versions))
+	for _, version := range versions {
+		items = append(items, strconv.Itoa(version))
+	}
+
+	return strings.Join(items, ", ")
+}
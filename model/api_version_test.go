@@ -0,0 +1,33 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIVersion(t *testing.T) {
+	for _, value := range []string{"", "1", "v1", " 1 ", "2, 1", "v3,v1"} {
+		version, err := ParseAPIVersion(value)
+		require.NoError(t, err, value)
+		require.Equal(t, APIVersion1, version)
+	}
+
+	for _, value := range []string{"0", "2", "v", "latest", "3, 2", "2, latest"} {
+		_, err := ParseAPIVersion(value)
+		require.Error(t, err, value)
+	}
+
+	_, err := ParseAPIVersion("3, 2")
+	require.EqualError(t, err, "unsupported API versions 3, 2: must be between 1 and 1")
+}
+
+func TestFormatAPIVersions(t *testing.T) {
+	require.Equal(t, "", FormatAPIVersions(nil))
+	require.Equal(t, "1", FormatAPIVersions(SupportedAPIVersions))
+	require.Equal(t, "2, 1", FormatAPIVersions([]int{2, 1}))
+}
@@ -0,0 +1,384 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ApplyActionCreate creates a resource missing from the fleet.
+	ApplyActionCreate = "create"
+	// ApplyActionUpdate updates a resource differing from its spec.
+	ApplyActionUpdate = "update"
+	// ApplyActionDelete deletes a ring, or removes an installation group from
+	// its ring, absent from the spec.
+	ApplyActionDelete = "delete"
+)
+
+// FleetSpec is the declarative spec of every ring and installation group
+// managed by elrond.
+type FleetSpec struct {
+	Rings []*RingSpec `json:"rings"`
+}
+
+// RingSpec is the desired configuration of a ring, identified by its name.
+type RingSpec struct {
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+	SoakTime int    `json:"soakTime,omitempty"`
+	// Image and Version are the initial release of the ring when it is
+	// created. They are ignored for existing rings, which change releases
+	// through the release API.
+	Image                string                   `json:"image,omitempty"`
+	Version              string                   `json:"version,omitempty"`
+	Annotations          Annotations              `json:"annotations,omitempty"`
+	NotificationEmails   NotificationEmails       `json:"notificationEmails,omitempty"`
+	NotificationLanguage string                   `json:"notificationLanguage,omitempty"`
+	JiraProject          string                   `json:"jiraProject,omitempty"`
+	OwnerTeam            string                   `json:"ownerTeam,omitempty"`
+	SlackChannel         string                   `json:"slackChannel,omitempty"`
+	EscalationPolicy     string                   `json:"escalationPolicy,omitempty"`
+	InstallationGroups   []*InstallationGroupSpec `json:"installationGroups,omitempty"`
+}
+
+// InstallationGroupSpec is the desired configuration of an installation
+// group of a ring, identified by its name.
+type InstallationGroupSpec struct {
+	Name               string      `json:"name"`
+	ProvisionerGroupID string      `json:"provisionerGroupID,omitempty"`
+	SoakTime           int         `json:"soakTime,omitempty"`
+	Annotations        Annotations `json:"annotations,omitempty"`
+}
+
+// ApplyChange is a change required to reconcile the fleet with its spec.
+type ApplyChange struct {
+	Action       string `json:"action"`
+	ResourceType string `json:"resourceType"`
+	Name         string `json:"name"`
+	// Ring is the name of the ring of an installation group change.
+	Ring string `json:"ring,omitempty"`
+	// Fields lists the fields changed by an update.
+	Fields []string `json:"fields,omitempty"`
+	// Installations and Customers are the capacity recorded on the ring or
+	// installation group an update or deletion changes. See RingCapacity.
+	Installations int64 `json:"installations,omitempty"`
+	Customers     int64 `json:"customers,omitempty"`
+}
+
+// ApplyPlan lists the changes required to reconcile the fleet with its spec.
+type ApplyPlan struct {
+	Changes []*ApplyChange `json:"changes"`
+	// Validations are the answers of the provisioner on the releases the
+	// changes would make, reported when the plan is not applied.
+	Validations []*ReleaseValidation `json:"validations,omitempty"`
+	// Applied is set once the changes have been made.
+	Applied bool `json:"applied"`
+}
+
+// ReleaseValidation is the answer of the provisioner on whether it would
+// accept releasing an image and version to the provisioner group of an
+// installation group.
+type ReleaseValidation struct {
+	InstallationGroup  string `json:"installationGroup"`
+	Ring               string `json:"ring"`
+	ProvisionerGroupID string `json:"provisionerGroupID"`
+	Image              string `json:"image"`
+	Version            string `json:"version"`
+	Accepted           bool   `json:"accepted"`
+	// Reasons explains why the release would be rejected.
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Rejected returns the validations of the plan the provisioner would reject.
+func (p *ApplyPlan) Rejected() []*ReleaseValidation {
+	var rejected []*ReleaseValidation
+	for _, validation := range p.Validations {
+		if !validation.Accepted {
+			rejected = append(rejected, validation)
+		}
+	}
+
+	return rejected
+}
+
+// Validate validates the values of a fleet spec.
+func (s *FleetSpec) Validate() error {
+	ringNames := make(map[string]bool)
+	installationGroupNames := make(map[string]bool)
+	for _, ring := range s.Rings {
+		if ring.Name == "" {
+			return errors.New("ring name cannot be empty")
+		}
+		if ringNames[ring.Name] {
+			return errors.Errorf("ring %s is specified more than once", ring.Name)
+		}
+		ringNames[ring.Name] = true
+
+		if ring.Priority == 0 {
+			return errors.Errorf("ring %s priority cannot be zero", ring.Name)
+		}
+		if err := ValidateName(ring.Name); err != nil {
+			return errors.Wrap(err, "invalid ring")
+		}
+		if err := ValidateRelease(ring.Image, ring.Version, ring.SoakTime); err != nil {
+			return errors.Wrapf(err, "invalid ring %s", ring.Name)
+		}
+		if err := ring.Annotations.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid ring %s annotations", ring.Name)
+		}
+		if err := ring.NotificationEmails.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid ring %s notification emails", ring.Name)
+		}
+		if err := ValidateLanguage(ring.NotificationLanguage); err != nil {
+			return errors.Wrapf(err, "invalid ring %s notification language", ring.Name)
+		}
+		if err := ValidateJiraProject(ring.JiraProject); err != nil {
+			return errors.Wrapf(err, "invalid ring %s Jira project", ring.Name)
+		}
+		if err := ValidateRingContacts(ring.OwnerTeam, ring.SlackChannel, ring.EscalationPolicy); err != nil {
+			return errors.Wrapf(err, "invalid ring %s contacts", ring.Name)
+		}
+
+		for _, installationGroup := range ring.InstallationGroups {
+			if installationGroup.Name == "" {
+				return errors.Errorf("ring %s installation group name cannot be empty", ring.Name)
+			}
+			if installationGroupNames[installationGroup.Name] {
+				return errors.Errorf("installation group %s is specified more than once", installationGroup.Name)
+			}
+			installationGroupNames[installationGroup.Name] = true
+
+			if err := ValidateName(installationGroup.Name); err != nil {
+				return errors.Wrap(err, "invalid installation group")
+			}
+			if err := ValidateSoakTime(installationGroup.SoakTime); err != nil {
+				return errors.Wrapf(err, "invalid installation group %s", installationGroup.Name)
+			}
+
+			if err := installationGroup.Annotations.Validate(); err != nil {
+				return errors.Wrapf(err, "invalid installation group %s annotations", installationGroup.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// NewFleetSpecFromReader decodes a json-encoded fleet spec from the given
+// io.Reader and validates it.
+func NewFleetSpecFromReader(reader io.Reader) (*FleetSpec, error) {
+	var spec FleetSpec
+	err := json.NewDecoder(reader).Decode(&spec)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode fleet spec")
+	}
+
+	if err = spec.Validate(); err != nil {
+		return nil, errors.Wrap(err, "fleet spec failed validation")
+	}
+
+	return &spec, nil
+}
+
+// ApplyPlanFromReader decodes a json-encoded apply plan from the given io.Reader.
+func ApplyPlanFromReader(reader io.Reader) (*ApplyPlan, error) {
+	plan := ApplyPlan{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&plan)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+// PlanFleet computes the changes reconciling the given rings, along with
+// their installation groups, with the spec. Rings are matched by name, and
+// rings absent from the spec are deleted.
+func PlanFleet(spec *FleetSpec, rings []*Ring) (*ApplyPlan, error) {
+	existing := make(map[string]*Ring)
+	for _, ring := range rings {
+		if ring.DeleteAt != 0 {
+			continue
+		}
+		if existing[ring.Name] != nil {
+			return nil, errors.Errorf("multiple rings are named %s", ring.Name)
+		}
+		existing[ring.Name] = ring
+	}
+
+	plan := &ApplyPlan{Changes: []*ApplyChange{}}
+	for _, ringSpec := range spec.Rings {
+		ring := existing[ringSpec.Name]
+		delete(existing, ringSpec.Name)
+
+		if ring == nil {
+			plan.Changes = append(plan.Changes, &ApplyChange{Action: ApplyActionCreate, ResourceType: TypeRing, Name: ringSpec.Name})
+			for _, installationGroupSpec := range ringSpec.InstallationGroups {
+				plan.Changes = append(plan.Changes, &ApplyChange{Action: ApplyActionCreate, ResourceType: TypeInstallationGroup, Name: installationGroupSpec.Name, Ring: ringSpec.Name})
+			}
+			continue
+		}
+
+		if fields := ringSpec.diff(ring); len(fields) > 0 {
+			plan.Changes = append(plan.Changes, newRingApplyChange(ApplyActionUpdate, ring, fields))
+		}
+		plan.Changes = append(plan.Changes, planInstallationGroups(ringSpec, ring)...)
+	}
+
+	// Delete the remaining rings in a stable order, skipping those already
+	// being deleted.
+	remaining := make([]string, 0, len(existing))
+	for name, ring := range existing {
+		if ring.State == RingStateDeletionRequested || ring.State == RingStateDeletionPending {
+			continue
+		}
+		remaining = append(remaining, name)
+	}
+	sort.Strings(remaining)
+	for _, name := range remaining {
+		plan.Changes = append(plan.Changes, newRingApplyChange(ApplyActionDelete, existing[name], nil))
+	}
+
+	return plan, nil
+}
+
+// newRingApplyChange returns the given change of an existing ring, along with
+// its recorded capacity.
+func newRingApplyChange(action string, ring *Ring, fields []string) *ApplyChange {
+	change := &ApplyChange{Action: action, ResourceType: TypeRing, Name: ring.Name, Fields: fields}
+	impact := RecordedReleaseImpact(ring.InstallationGroups)
+	change.Installations = impact.Installations
+	change.Customers = impact.Customers
+
+	return change
+}
+
+// planInstallationGroups computes the changes reconciling the installation
+// groups of the ring with its spec.
+func planInstallationGroups(ringSpec *RingSpec, ring *Ring) []*ApplyChange {
+	existing := make(map[string]*InstallationGroup)
+	for _, installationGroup := range ring.InstallationGroups {
+		existing[installationGroup.Name] = installationGroup
+	}
+
+	var changes []*ApplyChange
+	for _, installationGroupSpec := range ringSpec.InstallationGroups {
+		installationGroup := existing[installationGroupSpec.Name]
+		delete(existing, installationGroupSpec.Name)
+
+		if installationGroup == nil {
+			changes = append(changes, &ApplyChange{Action: ApplyActionCreate, ResourceType: TypeInstallationGroup, Name: installationGroupSpec.Name, Ring: ring.Name})
+			continue
+		}
+		if fields := installationGroupSpec.diff(installationGroup); len(fields) > 0 {
+			changes = append(changes, &ApplyChange{Action: ApplyActionUpdate, ResourceType: TypeInstallationGroup, Name: installationGroup.Name, Ring: ring.Name, Fields: fields, Installations: installationGroup.InstallationCount, Customers: installationGroup.CustomerCount})
+		}
+	}
+
+	remaining := make([]string, 0, len(existing))
+	for name := range existing {
+		remaining = append(remaining, name)
+	}
+	sort.Strings(remaining)
+	for _, name := range remaining {
+		installationGroup := existing[name]
+		changes = append(changes, &ApplyChange{Action: ApplyActionDelete, ResourceType: TypeInstallationGroup, Name: name, Ring: ring.Name, Installations: installationGroup.InstallationCount, Customers: installationGroup.CustomerCount})
+	}
+
+	return changes
+}
+
+// diff returns the fields of the ring differing from the spec.
+func (s *RingSpec) diff(ring *Ring) []string {
+	var fields []string
+	if s.Priority != ring.Priority {
+		fields = append(fields, "priority")
+	}
+	if s.SoakTime != ring.SoakTime {
+		fields = append(fields, "soakTime")
+	}
+	if !equalAnnotations(s.Annotations, ring.Annotations) {
+		fields = append(fields, "annotations")
+	}
+	if !equalNotificationEmails(s.NotificationEmails, ring.NotificationEmails) {
+		fields = append(fields, "notificationEmails")
+	}
+	if s.NotificationLanguage != ring.NotificationLanguage {
+		fields = append(fields, "notificationLanguage")
+	}
+	if s.JiraProject != ring.JiraProject {
+		fields = append(fields, "jiraProject")
+	}
+	if s.OwnerTeam != ring.OwnerTeam {
+		fields = append(fields, "ownerTeam")
+	}
+	if s.SlackChannel != ring.SlackChannel {
+		fields = append(fields, "slackChannel")
+	}
+	if s.EscalationPolicy != ring.EscalationPolicy {
+		fields = append(fields, "escalationPolicy")
+	}
+
+	return fields
+}
+
+// Apply sets the fields of the ring from the spec.
+func (s *RingSpec) Apply(ring *Ring) {
+	ring.Name = s.Name
+	ring.Priority = s.Priority
+	ring.SoakTime = s.SoakTime
+	ring.Annotations = s.Annotations
+	ring.NotificationEmails = s.NotificationEmails
+	ring.NotificationLanguage = s.NotificationLanguage
+	ring.JiraProject = s.JiraProject
+	ring.OwnerTeam = s.OwnerTeam
+	ring.SlackChannel = s.SlackChannel
+	ring.EscalationPolicy = s.EscalationPolicy
+}
+
+// diff returns the fields of the installation group differing from the spec.
+func (s *InstallationGroupSpec) diff(installationGroup *InstallationGroup) []string {
+	var fields []string
+	if s.ProvisionerGroupID != installationGroup.ProvisionerGroupID {
+		fields = append(fields, "provisionerGroupID")
+	}
+	if s.SoakTime != installationGroup.SoakTime {
+		fields = append(fields, "soakTime")
+	}
+	if !equalAnnotations(s.Annotations, installationGroup.Annotations) {
+		fields = append(fields, "annotations")
+	}
+
+	return fields
+}
+
+// Apply sets the fields of the installation group from the spec.
+func (s *InstallationGroupSpec) Apply(installationGroup *InstallationGroup) {
+	installationGroup.Name = s.Name
+	installationGroup.ProvisionerGroupID = s.ProvisionerGroupID
+	installationGroup.SoakTime = s.SoakTime
+	installationGroup.Annotations = s.Annotations
+}
+
+func equalAnnotations(a, b Annotations) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func equalNotificationEmails(a, b NotificationEmails) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
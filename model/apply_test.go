@@ -0,0 +1,89 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFleetSpecValidate(t *testing.T) {
+	for name, spec := range map[string]*FleetSpec{
+		"empty ring name":    {Rings: []*RingSpec{{Priority: 1}}},
+		"duplicate ring":     {Rings: []*RingSpec{{Name: "ring1", Priority: 1}, {Name: "ring1", Priority: 2}}},
+		"zero priority":      {Rings: []*RingSpec{{Name: "ring1"}}},
+		"invalid jira":       {Rings: []*RingSpec{{Name: "ring1", Priority: 1, JiraProject: "lower"}}},
+		"invalid email":      {Rings: []*RingSpec{{Name: "ring1", Priority: 1, NotificationEmails: NotificationEmails{"invalid"}}}},
+		"empty ig name":      {Rings: []*RingSpec{{Name: "ring1", Priority: 1, InstallationGroups: []*InstallationGroupSpec{{}}}}},
+		"duplicate ig":       {Rings: []*RingSpec{{Name: "ring1", Priority: 1, InstallationGroups: []*InstallationGroupSpec{{Name: "ig1"}}}, {Name: "ring2", Priority: 2, InstallationGroups: []*InstallationGroupSpec{{Name: "ig1"}}}}},
+		"invalid annotation": {Rings: []*RingSpec{{Name: "ring1", Priority: 1, Annotations: Annotations{"in valid": "x"}}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, spec.Validate())
+		})
+	}
+
+	spec, err := NewFleetSpecFromReader(bytes.NewReader([]byte(`{"rings": [{"name": "ring1", "priority": 1, "installationGroups": [{"name": "ig1"}]}]}`)))
+	require.NoError(t, err)
+	require.Equal(t, "ig1", spec.Rings[0].InstallationGroups[0].Name)
+}
+
+func TestPlanFleet(t *testing.T) {
+	rings := []*Ring{
+		{
+			Name:     "ring1",
+			Priority: 1,
+			SoakTime: 60,
+			InstallationGroups: []*InstallationGroup{
+				{Name: "ig1", ProvisionerGroupID: "group1", InstallationCount: 10, CustomerCount: 8},
+				{Name: "ig2", ProvisionerGroupID: "group2", InstallationCount: 5, CustomerCount: 5},
+			},
+		},
+		{Name: "ring2", Priority: 2, Annotations: Annotations{}},
+		{Name: "ring3", Priority: 3},
+		{Name: "ring3", Priority: 3, DeleteAt: 1},
+		{Name: "ring5", Priority: 5, State: RingStateDeletionRequested},
+	}
+
+	spec := &FleetSpec{Rings: []*RingSpec{
+		{
+			Name:     "ring1",
+			Priority: 1,
+			SoakTime: 120,
+			InstallationGroups: []*InstallationGroupSpec{
+				{Name: "ig1", ProvisionerGroupID: "group1", Annotations: Annotations{"Team": "a"}},
+				{Name: "ig3"},
+			},
+		},
+		{Name: "ring2", Priority: 2},
+		{Name: "ring4", Priority: 4, InstallationGroups: []*InstallationGroupSpec{{Name: "ig4"}}},
+	}}
+
+	plan, err := PlanFleet(spec, rings)
+	require.NoError(t, err)
+	require.False(t, plan.Applied)
+	require.Equal(t, []*ApplyChange{
+		{Action: ApplyActionUpdate, ResourceType: TypeRing, Name: "ring1", Fields: []string{"soakTime"}, Installations: 15, Customers: 13},
+		{Action: ApplyActionUpdate, ResourceType: TypeInstallationGroup, Name: "ig1", Ring: "ring1", Fields: []string{"annotations"}, Installations: 10, Customers: 8},
+		{Action: ApplyActionCreate, ResourceType: TypeInstallationGroup, Name: "ig3", Ring: "ring1"},
+		{Action: ApplyActionDelete, ResourceType: TypeInstallationGroup, Name: "ig2", Ring: "ring1", Installations: 5, Customers: 5},
+		{Action: ApplyActionCreate, ResourceType: TypeRing, Name: "ring4"},
+		{Action: ApplyActionCreate, ResourceType: TypeInstallationGroup, Name: "ig4", Ring: "ring4"},
+		{Action: ApplyActionDelete, ResourceType: TypeRing, Name: "ring3"},
+	}, plan.Changes)
+
+	t.Run("no changes", func(t *testing.T) {
+		plan, err := PlanFleet(&FleetSpec{Rings: []*RingSpec{{Name: "ring2", Priority: 2}}}, rings[1:2])
+		require.NoError(t, err)
+		require.Empty(t, plan.Changes)
+	})
+
+	t.Run("ambiguous ring names", func(t *testing.T) {
+		_, err := PlanFleet(spec, append(rings, &Ring{Name: "ring2"}))
+		require.Error(t, err)
+	})
+}
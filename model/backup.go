@@ -0,0 +1,69 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// DatabaseBackup is a copy of every row of the elrond database, taken
+// offline by elrondctl to restore the database in a disaster, possibly into
+// another database driver.
+type DatabaseBackup struct {
+	// SchemaVersion is the schema version of the database the backup was
+	// taken from. A backup is only restored into a database migrated to the
+	// same version.
+	SchemaVersion string
+	// CreateAt is the time, in milliseconds, the backup was taken at.
+	CreateAt int64
+	// Tables are the rows of each table, keyed by column.
+	Tables map[string][]map[string]interface{}
+}
+
+// Rows returns the number of rows of the backup.
+func (b *DatabaseBackup) Rows() int {
+	rows := 0
+	for _, tableRows := range b.Tables {
+		rows += len(tableRows)
+	}
+
+	return rows
+}
+
+// DatabaseBackupFromReader decodes a json-encoded database backup from the
+// given io.Reader. Integer values are decoded as int64 so that timestamps in
+// nanoseconds keep their precision.
+func DatabaseBackupFromReader(reader io.Reader) (*DatabaseBackup, error) {
+	backup := DatabaseBackup{}
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	err := decoder.Decode(&backup)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode database backup")
+	}
+
+	for _, rows := range backup.Tables {
+		for _, row := range rows {
+			for column, value := range row {
+				number, ok := value.(json.Number)
+				if !ok {
+					continue
+				}
+				if integer, err := number.Int64(); err == nil {
+					row[column] = integer
+					continue
+				}
+				if row[column], err = number.Float64(); err != nil {
+					return nil, errors.Wrapf(err, "invalid value of column %s", column)
+				}
+			}
+		}
+	}
+
+	return &backup, nil
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CalendarEntryRelease is a past, ongoing or pending release of a ring.
+	CalendarEntryRelease = "release"
+	// CalendarEntryFreeze is a window during which the releases of a ring
+	// were paused.
+	CalendarEntryFreeze = "freeze"
+	// CalendarEntryDeletion is the scheduled deletion of a ring.
+	CalendarEntryDeletion = "deletion"
+)
+
+// DefaultCalendarRange is the default time range, in milliseconds, of a
+// calendar before and after the current time.
+const DefaultCalendarRange = int64(14 * 24 * time.Hour / time.Millisecond)
+
+// Calendar combines the releases, freeze windows and scheduled work of the
+// rings between two times, in milliseconds.
+type Calendar struct {
+	From    int64
+	To      int64
+	Entries []*CalendarEntry
+}
+
+// CalendarEntry is a single item of a calendar. All timestamps are in
+// milliseconds; an EndAt of 0 means the entry is still ongoing with no known
+// end.
+type CalendarEntry struct {
+	Type      string
+	RingID    string
+	RingName  string
+	ReleaseID string `json:",omitempty"`
+	Image     string `json:",omitempty"`
+	Version   string `json:",omitempty"`
+	// State is the final state of a release, or its current state if it is
+	// still ongoing.
+	State   string `json:",omitempty"`
+	StartAt int64
+	EndAt   int64
+	// Estimated is whether EndAt is the estimated completion of a release in
+	// progress, rather than the time it completed.
+	Estimated bool `json:",omitempty"`
+}
+
+// Summary returns a one-line description of the calendar entry.
+func (e *CalendarEntry) Summary() string {
+	ring := e.RingName
+	if ring == "" {
+		ring = e.RingID
+	}
+
+	switch e.Type {
+	case CalendarEntryRelease:
+		summary := fmt.Sprintf("Release of ring %s", ring)
+		if e.Image != "" || e.Version != "" {
+			summary += fmt.Sprintf(" to %s:%s", e.Image, e.Version)
+		}
+		if e.State != "" {
+			summary += fmt.Sprintf(" (%s)", e.State)
+		}
+		return summary
+	case CalendarEntryFreeze:
+		return fmt.Sprintf("Releases of ring %s paused", ring)
+	case CalendarEntryDeletion:
+		return fmt.Sprintf("Deletion of ring %s", ring)
+	}
+
+	return fmt.Sprintf("%s of ring %s", e.Type, ring)
+}
+
+// overlaps returns whether the entry overlaps the given time range.
+func (e *CalendarEntry) overlaps(from, to int64) bool {
+	return e.StartAt <= to && (e.EndAt == 0 || e.EndAt >= from)
+}
+
+// BuildRingCalendarEntries builds the calendar entries of a ring from its
+// state change events, sorted by timestamp. The estimated completion of the
+// ring, if set, is the end of its release in progress.
+func BuildRingCalendarEntries(ring *Ring, events []*StateChangeEvent) []*CalendarEntry {
+	entries := []*CalendarEntry{}
+
+	timeline := BuildRingTimeline(ring.ID, events, 0)
+	for i, release := range timeline.Releases {
+		entry := &CalendarEntry{
+			Type:      CalendarEntryRelease,
+			RingID:    ring.ID,
+			RingName:  ring.Name,
+			ReleaseID: release.ReleaseID,
+			State:     release.FinalState,
+			StartAt:   release.StartAt,
+			EndAt:     release.EndAt,
+		}
+		if entry.EndAt == 0 && i == len(timeline.Releases)-1 && ring.EstimatedCompletionAt != 0 {
+			entry.EndAt = ring.EstimatedCompletionAt
+			entry.Estimated = true
+		}
+		entries = append(entries, entry)
+	}
+
+	var freeze *CalendarEntry
+	for _, event := range events {
+		if event.ResourceType != TypeRing {
+			continue
+		}
+		if event.NewState == RingStateReleasePaused {
+			if freeze == nil {
+				freeze = &CalendarEntry{
+					Type:      CalendarEntryFreeze,
+					RingID:    ring.ID,
+					RingName:  ring.Name,
+					ReleaseID: event.ReleaseID,
+					StartAt:   event.Timestamp,
+				}
+				entries = append(entries, freeze)
+			}
+			continue
+		}
+		if freeze != nil {
+			freeze.EndAt = event.Timestamp
+			freeze = nil
+		}
+	}
+
+	if ring.DeletionScheduledAt != 0 && ring.DeleteAt == 0 {
+		deletionAt := ring.DeletionScheduledAt / int64(time.Millisecond)
+		entries = append(entries, &CalendarEntry{
+			Type:     CalendarEntryDeletion,
+			RingID:   ring.ID,
+			RingName: ring.Name,
+			StartAt:  deletionAt,
+			EndAt:    deletionAt,
+		})
+	}
+
+	return entries
+}
+
+// AddEntries adds the given entries overlapping the time range of the
+// calendar, keeping the entries sorted by start time.
+func (c *Calendar) AddEntries(entries []*CalendarEntry) {
+	for _, entry := range entries {
+		if entry.overlaps(c.From, c.To) {
+			c.Entries = append(c.Entries, entry)
+		}
+	}
+
+	sort.SliceStable(c.Entries, func(i, j int) bool {
+		return c.Entries[i].StartAt < c.Entries[j].StartAt
+	})
+}
+
+// ICal returns the calendar in the iCalendar format of RFC 5545. Ongoing
+// entries with no known end are shown as ending at the end of the calendar.
+func (c *Calendar) ICal() []byte {
+	var b strings.Builder
+	writeLine := func(line string) {
+		// Lines longer than 75 octets are folded onto continuation lines
+		// starting with a space.
+		for len(line) > 75 {
+			b.WriteString(line[:75] + "\r\n")
+			line = " " + line[75:]
+		}
+		b.WriteString(line + "\r\n")
+	}
+
+	stamp := formatICalTime(GetMillis())
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//Mattermost//Elrond//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	for _, entry := range c.Entries {
+		endAt := entry.EndAt
+		if endAt == 0 {
+			endAt = c.To
+		}
+
+		uid := fmt.Sprintf("%s-%s-%d", entry.Type, entry.RingID, entry.StartAt)
+		if entry.ReleaseID != "" {
+			uid = fmt.Sprintf("%s-%s-%s-%d", entry.Type, entry.RingID, entry.ReleaseID, entry.StartAt)
+		}
+
+		writeLine("BEGIN:VEVENT")
+		writeLine(fmt.Sprintf("UID:%s@elrond", uid))
+		writeLine("DTSTAMP:" + stamp)
+		writeLine("DTSTART:" + formatICalTime(entry.StartAt))
+		writeLine("DTEND:" + formatICalTime(endAt))
+		writeLine("SUMMARY:" + escapeICalText(entry.Summary()))
+		writeLine("CATEGORIES:" + escapeICalText(entry.Type))
+		if entry.Estimated {
+			writeLine("DESCRIPTION:" + escapeICalText("The end is the estimated completion of the release in progress."))
+		}
+		writeLine("END:VEVENT")
+	}
+	writeLine("END:VCALENDAR")
+
+	return []byte(b.String())
+}
+
+// formatICalTime formats a time in milliseconds as an iCalendar UTC time.
+func formatICalTime(millis int64) string {
+	return time.UnixMilli(millis).UTC().Format("20060102T150405Z")
+}
+
+// escapeICalText escapes a value of an iCalendar text property.
+func escapeICalText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
+
+// GetCalendarRequest describes the parameters to request a calendar. A zero
+// From or To uses the default range around the current time.
+type GetCalendarRequest struct {
+	From int64
+	To   int64
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetCalendarRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	if request.From != 0 {
+		q.Add("from", strconv.FormatInt(request.From, 10))
+	}
+	if request.To != 0 {
+		q.Add("to", strconv.FormatInt(request.To, 10))
+	}
+	u.RawQuery = q.Encode()
+}
+
+// CalendarFromReader decodes a json-encoded calendar from the given io.Reader.
+func CalendarFromReader(reader io.Reader) (*Calendar, error) {
+	calendar := Calendar{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&calendar)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &calendar, nil
+}
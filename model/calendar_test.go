@@ -0,0 +1,136 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRingCalendarEntries(t *testing.T) {
+	ringEvent := func(releaseID, state string, timestamp int64) *StateChangeEvent {
+		return &StateChangeEvent{ResourceType: TypeRing, ResourceID: "ring", RingID: "ring", ReleaseID: releaseID, NewState: state, Timestamp: timestamp}
+	}
+
+	t.Run("no events", func(t *testing.T) {
+		entries := BuildRingCalendarEntries(&Ring{ID: "ring", Name: "production"}, nil)
+		require.Empty(t, entries)
+	})
+
+	t.Run("releases and freezes", func(t *testing.T) {
+		ring := &Ring{ID: "ring", Name: "production", EstimatedCompletionAt: 9000}
+		entries := BuildRingCalendarEntries(ring, []*StateChangeEvent{
+			ringEvent("release1", RingStateReleasePending, 100),
+			ringEvent("release1", RingStateReleaseRequested, 200),
+			ringEvent("release1", RingStateStable, 1000),
+			ringEvent("release2", RingStateReleasePending, 2000),
+			ringEvent("release2", RingStateReleasePaused, 2100),
+			ringEvent("release2", RingStateReleasePending, 3000),
+			ringEvent("release2", RingStateReleasePaused, 4000),
+		})
+
+		require.Len(t, entries, 4)
+		require.Equal(t, &CalendarEntry{Type: CalendarEntryRelease, RingID: "ring", RingName: "production", ReleaseID: "release1", State: RingStateStable, StartAt: 100, EndAt: 1000}, entries[0])
+		require.Equal(t, &CalendarEntry{Type: CalendarEntryRelease, RingID: "ring", RingName: "production", ReleaseID: "release2", State: RingStateReleasePaused, StartAt: 2000, EndAt: 9000, Estimated: true}, entries[1])
+		require.Equal(t, &CalendarEntry{Type: CalendarEntryFreeze, RingID: "ring", RingName: "production", ReleaseID: "release2", StartAt: 2100, EndAt: 3000}, entries[2])
+		require.Equal(t, &CalendarEntry{Type: CalendarEntryFreeze, RingID: "ring", RingName: "production", ReleaseID: "release2", StartAt: 4000}, entries[3])
+	})
+
+	t.Run("scheduled deletion", func(t *testing.T) {
+		ring := &Ring{ID: "ring", Name: "production", DeletionScheduledAt: int64(5 * time.Second)}
+		entries := BuildRingCalendarEntries(ring, nil)
+		require.Len(t, entries, 1)
+		require.Equal(t, CalendarEntryDeletion, entries[0].Type)
+		require.Equal(t, int64(5000), entries[0].StartAt)
+
+		ring.DeleteAt = 6000
+		require.Empty(t, BuildRingCalendarEntries(ring, nil))
+	})
+}
+
+func TestCalendarAddEntries(t *testing.T) {
+	calendar := &Calendar{From: 1000, To: 2000}
+	calendar.AddEntries([]*CalendarEntry{
+		{Type: CalendarEntryRelease, StartAt: 1500, EndAt: 1600},
+		{Type: CalendarEntryRelease, StartAt: 100, EndAt: 200},
+		{Type: CalendarEntryFreeze, StartAt: 500},
+		{Type: CalendarEntryRelease, StartAt: 900, EndAt: 1100},
+		{Type: CalendarEntryDeletion, StartAt: 3000, EndAt: 3000},
+	})
+
+	require.Len(t, calendar.Entries, 3)
+	require.Equal(t, int64(500), calendar.Entries[0].StartAt)
+	require.Equal(t, int64(900), calendar.Entries[1].StartAt)
+	require.Equal(t, int64(1500), calendar.Entries[2].StartAt)
+}
+
+func TestCalendarICal(t *testing.T) {
+	calendar := &Calendar{
+		From: 0,
+		To:   time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC).UnixMilli(),
+		Entries: []*CalendarEntry{
+			{
+				Type:      CalendarEntryRelease,
+				RingID:    "ring",
+				RingName:  "production, eu",
+				ReleaseID: "release1",
+				Image:     "mattermost/mattermost-enterprise-edition",
+				Version:   "7.8.0",
+				State:     RingStateStable,
+				StartAt:   time.Date(2026, time.March, 1, 10, 0, 0, 0, time.UTC).UnixMilli(),
+				EndAt:     time.Date(2026, time.March, 1, 11, 30, 0, 0, time.UTC).UnixMilli(),
+			},
+			{
+				Type:     CalendarEntryFreeze,
+				RingID:   "ring",
+				RingName: "production, eu",
+				StartAt:  time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC).UnixMilli(),
+			},
+		},
+	}
+
+	ical := string(calendar.ICal())
+	require.True(t, strings.HasPrefix(ical, "BEGIN:VCALENDAR\r\n"))
+	require.True(t, strings.HasSuffix(ical, "END:VCALENDAR\r\n"))
+	require.Equal(t, 2, strings.Count(ical, "BEGIN:VEVENT\r\n"))
+	require.Contains(t, ical, "DTSTART:20260301T100000Z\r\n")
+	require.Contains(t, ical, "DTEND:20260301T113000Z\r\n")
+	require.Contains(t, ical, "SUMMARY:Releases of ring production\\, eu paused\r\n")
+	require.Contains(t, ical, "DTEND:20260302T000000Z\r\n")
+	for _, line := range strings.Split(ical, "\r\n") {
+		require.LessOrEqual(t, len(line), 75)
+	}
+}
+
+func TestCalendarEntrySummary(t *testing.T) {
+	require.Equal(t, "Release of ring production to mattermost:7.8.0 (stable)", (&CalendarEntry{Type: CalendarEntryRelease, RingName: "production", Image: "mattermost", Version: "7.8.0", State: RingStateStable}).Summary())
+	require.Equal(t, "Release of ring ring1", (&CalendarEntry{Type: CalendarEntryRelease, RingID: "ring1"}).Summary())
+	require.Equal(t, "Deletion of ring production", (&CalendarEntry{Type: CalendarEntryDeletion, RingName: "production"}).Summary())
+}
+
+func TestGetCalendarRequestApplyToURL(t *testing.T) {
+	u, err := url.Parse("http://localhost/api/v1/calendar")
+	require.NoError(t, err)
+	(&GetCalendarRequest{}).ApplyToURL(u)
+	require.Empty(t, u.RawQuery)
+
+	(&GetCalendarRequest{From: 1000, To: 2000}).ApplyToURL(u)
+	require.Equal(t, "from=1000&to=2000", u.RawQuery)
+}
+
+func TestCalendarFromReader(t *testing.T) {
+	calendar, err := CalendarFromReader(bytes.NewReader([]byte(`{"From":1,"To":2,"Entries":[{"Type":"release","RingID":"ring"}]}`)))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), calendar.From)
+	require.Len(t, calendar.Entries, 1)
+
+	_, err = CalendarFromReader(bytes.NewReader([]byte(`{`)))
+	require.Error(t, err)
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"github.com/pkg/errors"
+)
+
+// RingCapacity sums the capacity recorded on the installation groups of a
+// ring, answering how many installations and customers the ring covers.
+type RingCapacity struct {
+	Installations int64
+	Customers     int64
+	// Target is the sum of the capacity targets of the installation groups.
+	Target int64
+	// Synced and Manual count the installation groups whose counts were
+	// synced from the provisioner or set manually, and Unknown those without
+	// counts, which the sums do not cover.
+	Synced  int
+	Manual  int
+	Unknown int
+	// SyncedAt is the time, in milliseconds, the least recently synced
+	// installation group was synced at.
+	SyncedAt int64 `json:",omitempty"`
+}
+
+// ValidateCapacity validates the installation and customer counts and the
+// capacity target of an installation group.
+func ValidateCapacity(installations, customers, target int64) error {
+	if installations < 0 {
+		return errors.Errorf("installation count %d must not be negative", installations)
+	}
+	if customers < 0 {
+		return errors.Errorf("customer count %d must not be negative", customers)
+	}
+	if target < 0 {
+		return errors.Errorf("capacity target %d must not be negative", target)
+	}
+	if customers > installations && installations > 0 {
+		return errors.Errorf("customer count %d must not exceed the installation count %d", customers, installations)
+	}
+
+	return nil
+}
+
+// HasCapacity returns whether installation and customer counts are recorded
+// on the installation group.
+func (ig *InstallationGroup) HasCapacity() bool {
+	return ig.CapacitySyncedAt != 0 || ig.InstallationCount != 0 || ig.CustomerCount != 0
+}
+
+// NewRingCapacity sums the capacity recorded on the given installation
+// groups. It returns nil if none has counts or a capacity target recorded.
+func NewRingCapacity(installationGroups []*InstallationGroup) *RingCapacity {
+	capacity := &RingCapacity{}
+	for _, installationGroup := range installationGroups {
+		capacity.Target += installationGroup.CapacityTarget
+		if !installationGroup.HasCapacity() {
+			capacity.Unknown++
+			continue
+		}
+
+		capacity.Installations += installationGroup.InstallationCount
+		capacity.Customers += installationGroup.CustomerCount
+		if installationGroup.CapacitySyncedAt == 0 {
+			capacity.Manual++
+			continue
+		}
+		capacity.Synced++
+		if capacity.SyncedAt == 0 || installationGroup.CapacitySyncedAt < capacity.SyncedAt {
+			capacity.SyncedAt = installationGroup.CapacitySyncedAt
+		}
+	}
+	if capacity.Unknown == len(installationGroups) && capacity.Target == 0 {
+		return nil
+	}
+
+	return capacity
+}
+
+// RecordedReleaseImpact returns the release impact of the given installation
+// groups from their recorded capacity, for when the provisioner cannot be
+// queried. Customers of several installation groups are counted once per
+// installation group, so it is an upper bound.
+func RecordedReleaseImpact(installationGroups []*InstallationGroup) *ReleaseImpact {
+	impact := &ReleaseImpact{}
+	for _, installationGroup := range installationGroups {
+		impact.Installations += installationGroup.InstallationCount
+		impact.Customers += installationGroup.CustomerCount
+	}
+
+	return impact
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCapacity(t *testing.T) {
+	require.NoError(t, ValidateCapacity(0, 0, 0))
+	require.NoError(t, ValidateCapacity(10, 8, 20))
+	require.NoError(t, ValidateCapacity(0, 8, 0))
+	require.Error(t, ValidateCapacity(-1, 0, 0))
+	require.Error(t, ValidateCapacity(0, -1, 0))
+	require.Error(t, ValidateCapacity(0, 0, -1))
+	require.Error(t, ValidateCapacity(5, 8, 0))
+}
+
+func TestNewRingCapacity(t *testing.T) {
+	t.Run("no capacity", func(t *testing.T) {
+		require.Nil(t, NewRingCapacity(nil))
+		require.Nil(t, NewRingCapacity([]*InstallationGroup{{Name: "ig1"}}))
+	})
+
+	t.Run("capacity", func(t *testing.T) {
+		capacity := NewRingCapacity([]*InstallationGroup{
+			{Name: "ig1", InstallationCount: 10, CustomerCount: 8, CapacityTarget: 20, CapacitySyncedAt: 2000},
+			{Name: "ig2", InstallationCount: 5, CustomerCount: 5, CapacitySyncedAt: 1000},
+			{Name: "ig3", InstallationCount: 3, CustomerCount: 1},
+			{Name: "ig4", CapacityTarget: 10},
+		})
+		require.Equal(t, &RingCapacity{
+			Installations: 18,
+			Customers:     14,
+			Target:        30,
+			Synced:        2,
+			Manual:        1,
+			Unknown:       1,
+			SyncedAt:      1000,
+		}, capacity)
+	})
+}
+
+func TestRecordedReleaseImpact(t *testing.T) {
+	impact := RecordedReleaseImpact([]*InstallationGroup{
+		{Name: "ig1", InstallationCount: 10, CustomerCount: 8},
+		{Name: "ig2", InstallationCount: 5, CustomerCount: 5},
+	})
+	require.Equal(t, &ReleaseImpact{Installations: 15, Customers: 13}, impact)
+}
@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -20,6 +21,9 @@ type Client struct {
 	address    string
 	headers    map[string]string
 	httpClient *http.Client
+	// watchInterval is how often watches poll for new events. See
+	// DefaultWatchInterval.
+	watchInterval time.Duration
 }
 
 // NewClient creates a client to the elrond server at the given address.
@@ -41,6 +45,32 @@ func NewClientWithHeaders(address string, headers map[string]string) *Client {
 	}
 }
 
+// NewClientWithToken creates a client to the elrond server at the given
+// address that authenticates with the given API token.
+func NewClientWithToken(address, token string) *Client {
+	return NewClientWithHeaders(address, map[string]string{
+		"Authorization": authorizationBearerPrefix + token,
+	})
+}
+
+// WithIdempotencyKey returns a copy of the client sending the given
+// idempotency key, so that retrying a create request returns the resource
+// created by the first attempt instead of creating another one.
+func (c *Client) WithIdempotencyKey(key string) *Client {
+	headers := make(map[string]string, len(c.headers)+1)
+	for name, value := range c.headers {
+		headers[name] = value
+	}
+	headers[HeaderIdempotencyKey] = key
+
+	return &Client{
+		address:       c.address,
+		headers:       headers,
+		httpClient:    c.httpClient,
+		watchInterval: c.watchInterval,
+	}
+}
+
 // closeBody ensures the Body of an http.Response is properly closed.
 func closeBody(r *http.Response) {
 	if r.Body != nil {
@@ -49,6 +79,22 @@ func closeBody(r *http.Response) {
 	}
 }
 
+// apiErrorFromResponse builds the typed error for an unexpected response,
+// decoding the error envelope when the server provided one.
+func apiErrorFromResponse(r *http.Response) error {
+	apiErr := &APIError{StatusCode: r.StatusCode}
+	if r.Body != nil {
+		if errorResponse, err := ErrorResponseFromReader(r.Body); err == nil {
+			apiErr.ErrorResponse = *errorResponse
+		}
+	}
+	if apiErr.Code == "" {
+		apiErr.Code = ErrorCodeFromStatus(r.StatusCode)
+	}
+
+	return apiErr
+}
+
 func (c *Client) buildURL(urlPath string, args ...interface{}) string {
 	return fmt.Sprintf("%s%s", c.address, fmt.Sprintf(urlPath, args...))
 }
@@ -61,6 +107,7 @@ func (c *Client) doGet(u string) (*http.Response, error) {
 	for k, v := range c.headers {
 		req.Header.Add(k, v)
 	}
+	req.Header.Set(HeaderAPIVersion, FormatAPIVersions(SupportedAPIVersions))
 
 	return c.httpClient.Do(req)
 }
@@ -70,6 +117,11 @@ func (c *Client) doPost(u string, request interface{}) (*http.Response, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to marshal request")
 	}
+	if schemaName := RequestSchemaName(request); schemaName != "" {
+		if err = ValidateRequestJSON(schemaName, requestBytes); err != nil {
+			return nil, errors.Wrap(err, "request failed pre-flight validation")
+		}
+	}
 
 	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(requestBytes))
 	if err != nil {
@@ -78,6 +130,7 @@ func (c *Client) doPost(u string, request interface{}) (*http.Response, error) {
 	for k, v := range c.headers {
 		req.Header.Add(k, v)
 	}
+	req.Header.Set(HeaderAPIVersion, FormatAPIVersions(SupportedAPIVersions))
 	req.Header.Set("Content-Type", "application/json")
 
 	return c.httpClient.Do(req)
@@ -91,13 +144,14 @@ func (c *Client) doDelete(u string) (*http.Response, error) {
 	for k, v := range c.headers {
 		req.Header.Add(k, v)
 	}
+	req.Header.Set(HeaderAPIVersion, FormatAPIVersions(SupportedAPIVersions))
 
 	return c.httpClient.Do(req)
 }
 
 // CreateRing requests the creation of a ring from the configured elrond server.
 func (c *Client) CreateRing(request *CreateRingRequest) (*Ring, error) {
-	resp, err := c.doPost(c.buildURL("/api/rings"), request)
+	resp, err := c.doPost(c.buildURL("/api/v1/rings"), request)
 	if err != nil {
 		return nil, err
 	}
@@ -108,13 +162,13 @@ func (c *Client) CreateRing(request *CreateRingRequest) (*Ring, error) {
 		return RingFromReader(resp.Body)
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
 // RetryCreateRing retries the creation of a ring from the configured elrond server.
 func (c *Client) RetryCreateRing(ringID string) error {
-	resp, err := c.doPost(c.buildURL("/api/ring/%s", ringID), nil)
+	resp, err := c.doPost(c.buildURL("/api/v1/ring/%s", ringID), nil)
 	if err != nil {
 		return err
 	}
@@ -125,13 +179,13 @@ func (c *Client) RetryCreateRing(ringID string) error {
 		return nil
 
 	default:
-		return errors.Errorf("failed with status code %d", resp.StatusCode)
+		return apiErrorFromResponse(resp)
 	}
 }
 
 // UpdateRing requests the update of a ring from the configured elrond server.
 func (c *Client) UpdateRing(ringID string, request *UpdateRingRequest) (*Ring, error) {
-	resp, err := c.doPost(c.buildURL("/api/ring/%s/update", ringID), request)
+	resp, err := c.doPost(c.buildURL("/api/v1/ring/%s/update", ringID), request)
 	if err != nil {
 		return nil, err
 	}
@@ -142,13 +196,13 @@ func (c *Client) UpdateRing(ringID string, request *UpdateRingRequest) (*Ring, e
 		return RingFromReader(resp.Body)
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
 // ReleaseRing releases a ring deployment form the configured elrond server.
 func (c *Client) ReleaseRing(ringID string, request *RingReleaseRequest) (*Ring, error) {
-	resp, err := c.doPost(c.buildURL("/api/ring/%s/release", ringID), request)
+	resp, err := c.doPost(c.buildURL("/api/v1/ring/%s/release", ringID), request)
 	if err != nil {
 		return nil, err
 	}
@@ -159,13 +213,13 @@ func (c *Client) ReleaseRing(ringID string, request *RingReleaseRequest) (*Ring,
 		return RingFromReader(resp.Body)
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
 // GetRingRelease fetches the specified ring release from the configured elrond server.
 func (c *Client) GetRingRelease(releaseID string) (*RingRelease, error) {
-	resp, err := c.doGet(c.buildURL("/api/release/%s", releaseID))
+	resp, err := c.doGet(c.buildURL("/api/v1/release/%s", releaseID))
 	if err != nil {
 		return nil, err
 	}
@@ -179,13 +233,170 @@ func (c *Client) GetRingRelease(releaseID string) (*RingRelease, error) {
 		return nil, nil
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetRingReleaseHealth compares the health of each installation group
+// released to a ring release before the release with its health after the
+// soak, optionally restricted to the installation groups of one ring.
+func (c *Client) GetRingReleaseHealth(releaseID, ringID string) ([]*HealthSnapshotDiff, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/release/%s/health", releaseID))
+	if err != nil {
+		return nil, err
+	}
+	if ringID != "" {
+		u.RawQuery = url.Values{"ring": []string{ringID}}.Encode()
+	}
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return HealthSnapshotDiffsFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetRingReleaseLinks fetches the links of the given ring release.
+func (c *Client) GetRingReleaseLinks(releaseID string) (ReleaseLinks, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/release/%s/links", releaseID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ReleaseLinksFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// AddRingReleaseLink adds a link to the given ring release, returning its
+// links.
+func (c *Client) AddRingReleaseLink(releaseID string, link *ReleaseLink) (ReleaseLinks, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/release/%s/links", releaseID), link)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ReleaseLinksFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// AddRingReleaseLabels adds the given labels to the given ring release,
+// returning its labels.
+func (c *Client) AddRingReleaseLabels(releaseID string, labels ReleaseLabels) (ReleaseLabels, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/release/%s/labels", releaseID), labels)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ReleaseLabelsFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// RemoveRingReleaseLabel removes the given label from the given ring release.
+func (c *Client) RemoveRingReleaseLabel(releaseID, label string) error {
+	resp, err := c.doDelete(c.buildURL("/api/v1/release/%s/labels?label=%s", releaseID, url.QueryEscape(label)))
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil
+
+	default:
+		return apiErrorFromResponse(resp)
+	}
+}
+
+// GetNotes fetches the notes left on the ring, installation group or release
+// of the given type and ID, oldest first.
+func (c *Client) GetNotes(resourceType, resourceID string) ([]*Note, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/%s/%s/notes", resourceType, resourceID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return NotesFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// AddNote leaves a note on the ring, installation group or release of the
+// given type and ID.
+func (c *Client) AddNote(resourceType, resourceID string, request *NoteRequest) (*Note, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/%s/%s/notes", resourceType, resourceID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return NoteFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// RemoveRingReleaseLink removes the link of the given type and URL from the
+// given ring release.
+func (c *Client) RemoveRingReleaseLink(releaseID, linkType, linkURL string) error {
+	u, err := url.Parse(c.buildURL("/api/v1/release/%s/links", releaseID))
+	if err != nil {
+		return err
+	}
+	u.RawQuery = url.Values{"type": []string{linkType}, "url": []string{linkURL}}.Encode()
+
+	resp, err := c.doDelete(u.String())
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil
+
+	default:
+		return apiErrorFromResponse(resp)
 	}
 }
 
 // ReleaseAllRings releases all ring deployments from the configured elrond server.
 func (c *Client) ReleaseAllRings(request *RingReleaseRequest) ([]*Ring, error) {
-	resp, err := c.doPost(c.buildURL("/api/rings/release"), request)
+	resp, err := c.doPost(c.buildURL("/api/v1/rings/release"), request)
 	if err != nil {
 		return nil, err
 	}
@@ -196,13 +407,48 @@ func (c *Client) ReleaseAllRings(request *RingReleaseRequest) ([]*Ring, error) {
 		return RingsFromReader(resp.Body)
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// PauseRing pauses the pending or in flight release of the given ring.
+func (c *Client) PauseRing(ringID string) (*Ring, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/ring/%s/pause", ringID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return RingFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// ResumeRing resumes the paused release of the given ring where it was
+// paused.
+func (c *Client) ResumeRing(ringID string) (*Ring, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/ring/%s/resume", ringID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return RingFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
 // PauseRelease pauses all ring deployments from the configured elrond server.
 func (c *Client) PauseRelease() error {
-	resp, err := c.doPost(c.buildURL("/api/rings/release/pause"), nil)
+	resp, err := c.doPost(c.buildURL("/api/v1/rings/release/pause"), nil)
 	if err != nil {
 		return err
 	}
@@ -213,30 +459,31 @@ func (c *Client) PauseRelease() error {
 		return nil
 
 	default:
-		return errors.Errorf("failed with status code %d", resp.StatusCode)
+		return apiErrorFromResponse(resp)
 	}
 }
 
-// ResumeRelease resumes all paused ring deployments from the configured elrond server.
-func (c *Client) ResumeRelease() error {
-	resp, err := c.doPost(c.buildURL("/api/rings/release/resume"), nil)
+// ResumeRelease resumes all paused ring deployments from the configured elrond
+// server, returning the resumed rings.
+func (c *Client) ResumeRelease() ([]*Ring, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/rings/release/resume"), nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer closeBody(resp)
 
 	switch resp.StatusCode {
-	case http.StatusOK:
-		return nil
+	case http.StatusAccepted:
+		return RingsFromReader(resp.Body)
 
 	default:
-		return errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
 // CancelRelease cancels all ring deployments in pending stat for the configured elrond server.
 func (c *Client) CancelRelease() error {
-	resp, err := c.doPost(c.buildURL("/api/rings/release/cancel"), nil)
+	resp, err := c.doPost(c.buildURL("/api/v1/rings/release/cancel"), nil)
 	if err != nil {
 		return err
 	}
@@ -247,13 +494,14 @@ func (c *Client) CancelRelease() error {
 		return nil
 
 	default:
-		return errors.Errorf("failed with status code %d", resp.StatusCode)
+		return apiErrorFromResponse(resp)
 	}
 }
 
-// GetRing fetches the specified ring from the configured elrond server.
-func (c *Client) GetRing(ringID string) (*Ring, error) {
-	resp, err := c.doGet(c.buildURL("/api/ring/%s", ringID))
+// GetScheduledReleases fetches the pending releases of the rings scheduled
+// for later from the configured elrond server.
+func (c *Client) GetScheduledReleases() ([]*ScheduledRelease, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/rings/release/scheduled"))
 	if err != nil {
 		return nil, err
 	}
@@ -261,60 +509,52 @@ func (c *Client) GetRing(ringID string) (*Ring, error) {
 
 	switch resp.StatusCode {
 	case http.StatusOK:
-		return RingFromReader(resp.Body)
-
-	case http.StatusNotFound:
-		return nil, nil
+		return ScheduledReleasesFromReader(resp.Body)
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
-// GetRings fetches the list of rings from the configured elrond server.
-func (c *Client) GetRings(request *GetRingsRequest) ([]*Ring, error) {
-	u, err := url.Parse(c.buildURL("/api/rings"))
-	if err != nil {
-		return nil, err
-	}
-
-	request.ApplyToURL(u)
-
-	resp, err := c.doGet(u.String())
+// CancelScheduledRelease cancels the scheduled release of a ring before it
+// starts.
+func (c *Client) CancelScheduledRelease(ringID string) (*Ring, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/ring/%s/release/cancel", ringID), nil)
 	if err != nil {
 		return nil, err
 	}
 	defer closeBody(resp)
 
 	switch resp.StatusCode {
-	case http.StatusOK:
-		return RingsFromReader(resp.Body)
+	case http.StatusAccepted:
+		return RingFromReader(resp.Body)
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
-// DeleteRing deletes the given ring from the configured elrond server.
-func (c *Client) DeleteRing(ringID string) error {
-	resp, err := c.doDelete(c.buildURL("/api/ring/%s", ringID))
+// PrepareRingRelease prepares a release of a ring, to be started later by
+// CommitRingRelease.
+func (c *Client) PrepareRingRelease(ringID string, request *RingReleaseRequest) (*Ring, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/ring/%s/release/prepare", ringID), request)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer closeBody(resp)
 
 	switch resp.StatusCode {
 	case http.StatusAccepted:
-		return nil
+		return RingFromReader(resp.Body)
 
 	default:
-		return errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
-// CreateWebhook requests the creation of a webhook from the configured elrond server.
-func (c *Client) CreateWebhook(request *CreateWebhookRequest) (*Webhook, error) {
-	resp, err := c.doPost(c.buildURL("/api/webhooks"), request)
+// CommitRingRelease starts the prepared release of a ring.
+func (c *Client) CommitRingRelease(ringID string) (*Ring, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/ring/%s/release/commit", ringID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -322,16 +562,16 @@ func (c *Client) CreateWebhook(request *CreateWebhookRequest) (*Webhook, error)
 
 	switch resp.StatusCode {
 	case http.StatusAccepted:
-		return WebhookFromReader(resp.Body)
+		return RingFromReader(resp.Body)
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
-// GetWebhook fetches the webhook from the configured elrond server.
-func (c *Client) GetWebhook(webhookID string) (*Webhook, error) {
-	resp, err := c.doGet(c.buildURL("/api/webhook/%s", webhookID))
+// GetRing fetches the specified ring from the configured elrond server.
+func (c *Client) GetRing(ringID string) (*Ring, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/ring/%s", ringID))
 	if err != nil {
 		return nil, err
 	}
@@ -339,19 +579,19 @@ func (c *Client) GetWebhook(webhookID string) (*Webhook, error) {
 
 	switch resp.StatusCode {
 	case http.StatusOK:
-		return WebhookFromReader(resp.Body)
+		return RingFromReader(resp.Body)
 
 	case http.StatusNotFound:
 		return nil, nil
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
-// GetWebhooks fetches the list of webhooks from the configured elrond server.
-func (c *Client) GetWebhooks(request *GetWebhooksRequest) ([]*Webhook, error) {
-	u, err := url.Parse(c.buildURL("/api/webhooks"))
+// GetRings fetches the list of rings from the configured elrond server.
+func (c *Client) GetRings(request *GetRingsRequest) ([]*Ring, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/rings"))
 	if err != nil {
 		return nil, err
 	}
@@ -366,60 +606,92 @@ func (c *Client) GetWebhooks(request *GetWebhooksRequest) ([]*Webhook, error) {
 
 	switch resp.StatusCode {
 	case http.StatusOK:
-		return WebhooksFromReader(resp.Body)
+		return RingsFromReader(resp.Body)
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
-// DeleteWebhook deletes the given webhook from the configured elrond server.
-func (c *Client) DeleteWebhook(webhookID string) error {
-	resp, err := c.doDelete(c.buildURL("/api/webhook/%s", webhookID))
+// DeleteRing deletes the given ring from the configured elrond server.
+func (c *Client) DeleteRing(ringID string) error {
+	return c.DeleteRingWithRequest(ringID, &DeleteRingRequest{})
+}
+
+// DeleteRingWithRequest requests the deletion of a ring with the given
+// parameters from the configured elrond server.
+func (c *Client) DeleteRingWithRequest(ringID string, request *DeleteRingRequest) error {
+	u, err := url.Parse(c.buildURL("/api/v1/ring/%s", ringID))
+	if err != nil {
+		return err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doDelete(u.String())
 	if err != nil {
 		return err
 	}
 	defer closeBody(resp)
 
 	switch resp.StatusCode {
-	case http.StatusOK:
+	case http.StatusAccepted:
 		return nil
 
 	default:
-		return errors.Errorf("failed with status code %d", resp.StatusCode)
+		return apiErrorFromResponse(resp)
 	}
 }
 
-// LockAPIForRing locks API changes for a given ring.
-func (c *Client) LockAPIForRing(ringID string) error {
-	return c.makeSecurityCall("ring", ringID, "api", "lock")
-}
+// CancelRingDeletion cancels the pending deletion of a ring from the configured elrond server.
+func (c *Client) CancelRingDeletion(ringID string) (*Ring, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/ring/%s/deletion/cancel", ringID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
 
-// UnlockAPIForRing unlocks API changes for a given ring.
-func (c *Client) UnlockAPIForRing(ringID string) error {
-	return c.makeSecurityCall("ring", ringID, "api", "unlock")
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return RingFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
 }
 
-func (c *Client) makeSecurityCall(resourceType, id, securityType, action string) error {
-	resp, err := c.doPost(c.buildURL("/api/security/%s/%s/%s/%s", resourceType, id, securityType, action), nil)
+// GetRingRollbackSnapshot fetches the snapshot of the release the ring would
+// roll back to from the configured elrond server.
+func (c *Client) GetRingRollbackSnapshot(ringID string) (*RingReleaseSnapshot, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/ring/%s/rollback-snapshot", ringID))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer closeBody(resp)
 
 	switch resp.StatusCode {
 	case http.StatusOK:
-		return nil
+		return RingReleaseSnapshotFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
 
 	default:
-		return errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
+}
 
+// GetRingTimeline fetches the timeline of the latest given number of releases
+// of the ring from the configured elrond server.
+func (c *Client) GetRingTimeline(ringID string, releases int) (*RingTimeline, error) {
+	return c.GetRingTimelineForLabel(ringID, releases, "")
 }
 
-// RegisterRingInstallationGroup registers an installation group to the given ring.
-func (c *Client) RegisterRingInstallationGroup(ringID string, installationGroupRequest *RegisterInstallationGroupRequest) (*Ring, error) {
-	resp, err := c.doPost(c.buildURL("/api/ring/%s/installationgroup", ringID), installationGroupRequest)
+// GetRingTimelineForLabel fetches the timeline of the latest releases of the
+// ring with the given label from the configured elrond server. An empty
+// label includes every release.
+func (c *Client) GetRingTimelineForLabel(ringID string, releases int, label string) (*RingTimeline, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/ring/%s/timeline?releases=%d&label=%s", ringID, releases, url.QueryEscape(label)))
 	if err != nil {
 		return nil, err
 	}
@@ -427,42 +699,1218 @@ func (c *Client) RegisterRingInstallationGroup(ringID string, installationGroupR
 
 	switch resp.StatusCode {
 	case http.StatusOK:
-		return RingFromReader(resp.Body)
+		return RingTimelineFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
 
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
-// DeleteRingInstallationGroup deletes installation group from the given ring.
-func (c *Client) DeleteRingInstallationGroup(ringID string, installationGroupID string) error {
-	resp, err := c.doDelete(c.buildURL("/api/ring/%s/installationgroup/%s", ringID, installationGroupID))
+// GetRingBlockers fetches what keeps the release of the ring, or of its
+// installation groups, from moving forward from the configured elrond server.
+func (c *Client) GetRingBlockers(ringID string) (*ReleaseBlockers, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/ring/%s/blockers", ringID))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer closeBody(resp)
 
 	switch resp.StatusCode {
-	case http.StatusNoContent:
-		return nil
+	case http.StatusOK:
+		return ReleaseBlockersFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
 
 	default:
-		return errors.Errorf("failed with status code %d", resp.StatusCode)
+		return nil, apiErrorFromResponse(resp)
 	}
 }
 
-// UpdateInstallationGroup requests the update of an installation group from the configured elrond server.
-func (c *Client) UpdateInstallationGroup(installationGroup string, request *UpdateInstallationGroupRequest) (*InstallationGroup, error) {
-	resp, err := c.doPost(c.buildURL("/api/installationgroup/%s/update", installationGroup), request)
+// GetStateChangeEvents fetches a page of the state change events stream from the configured elrond server.
+func (c *Client) GetStateChangeEvents(request *GetStateChangeEventsRequest) (*StateChangeEventsPage, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/events"))
 	if err != nil {
 		return nil, err
 	}
-	defer closeBody(resp)
 
-	switch resp.StatusCode {
-	case http.StatusAccepted:
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return StateChangeEventsPageFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetRingStateChangeEvents fetches a page of the state change events of the
+// given ring and its installation groups from the configured elrond server.
+// The ring of the request is ignored.
+func (c *Client) GetRingStateChangeEvents(ringID string, request *GetStateChangeEventsRequest) (*StateChangeEventsPage, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/ring/%s/events", ringID))
+	if err != nil {
+		return nil, err
+	}
+
+	ringRequest := *request
+	ringRequest.RingID = ""
+	ringRequest.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return StateChangeEventsPageFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetCalendar fetches the calendar of ring releases, freeze windows and
+// scheduled work from the configured elrond server.
+func (c *Client) GetCalendar(request *GetCalendarRequest) (*Calendar, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/calendar"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return CalendarFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetCalendarICal fetches the calendar from the configured elrond server in
+// the iCalendar format.
+func (c *Client) GetCalendarICal(request *GetCalendarRequest) ([]byte, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/calendar"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+	q := u.Query()
+	q.Set("format", "ical")
+	u.RawQuery = q.Encode()
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ioutil.ReadAll(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetSoakTimeReport fetches the soak time suggested for every ring from the
+// configured elrond server.
+func (c *Client) GetSoakTimeReport() (*SoakTimeReport, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/reports/soak-time"))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return SoakTimeReportFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetVersionReport fetches the active and desired release of every ring and
+// installation group from the configured elrond server.
+func (c *Client) GetVersionReport() (*VersionReport, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/reports/versions"))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return VersionReportFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetVersionReportCSV fetches the version report from the configured elrond
+// server as CSV.
+func (c *Client) GetVersionReportCSV() ([]byte, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/reports/versions?format=csv"))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ioutil.ReadAll(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetAPICapabilities fetches the API versions and capabilities of the
+// configured elrond server. Servers predating capability negotiation, which
+// do not serve them, are assumed to have LegacyAPICapabilities.
+func (c *Client) GetAPICapabilities() (*APICapabilities, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/capabilities"))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return APICapabilitiesFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return LegacyAPICapabilities(), nil
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetStateMachineGraph fetches the transition graph of the rings or
+// installation groups, for the given state machine version or the current
+// one if 0, from the configured elrond server.
+func (c *Client) GetStateMachineGraph(resourceType string, version int) (*StateMachineGraph, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/state-machine?resource_type=%s&version=%d", url.QueryEscape(resourceType), version))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return StateMachineGraphFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// CompareRings fetches the differences between the active release, soak
+// configuration and labels of two rings from the configured elrond server.
+func (c *Client) CompareRings(ringA, ringB string) (*RingComparison, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/reports/compare?ringA=%s&ringB=%s", url.QueryEscape(ringA), url.QueryEscape(ringB)))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return RingComparisonFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetReleaseLeadTimeReport fetches how long the releases with the given
+// label took to reach every ring from the configured elrond server.
+func (c *Client) GetReleaseLeadTimeReport(label string) (*ReleaseLeadTimeReport, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/reports/lead-time?label=%s", url.QueryEscape(label)))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ReleaseLeadTimeReportFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetReplayReport replays the recorded state change events against the
+// current state machine and policy configuration of the configured elrond
+// server, fetching where they would have behaved differently.
+func (c *Client) GetReplayReport(request *GetReplayReportRequest) (*ReplayReport, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/reports/replay"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ReplayReportFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// CreateWebhook requests the creation of a webhook from the configured elrond server.
+func (c *Client) CreateWebhook(request *CreateWebhookRequest) (*Webhook, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/webhooks"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return WebhookFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetWebhook fetches the webhook from the configured elrond server.
+func (c *Client) GetWebhook(webhookID string) (*Webhook, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/webhook/%s", webhookID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return WebhookFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetWebhooks fetches the list of webhooks from the configured elrond server.
+func (c *Client) GetWebhooks(request *GetWebhooksRequest) ([]*Webhook, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/webhooks"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return WebhooksFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// DeleteWebhook deletes the given webhook from the configured elrond server.
+func (c *Client) DeleteWebhook(webhookID string) error {
+	resp, err := c.doDelete(c.buildURL("/api/v1/webhook/%s", webhookID))
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+
+	default:
+		return apiErrorFromResponse(resp)
+	}
+}
+
+// ReplayWebhook requests the given historical events to be re-delivered to
+// the webhook by the configured elrond server.
+func (c *Client) ReplayWebhook(webhookID string, request *ReplayWebhookRequest) (*WebhookReplay, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/webhook/%s/replay", webhookID))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doPost(u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return WebhookReplayFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetWebhookDeliveries fetches the deliveries of the given webhook from the
+// configured elrond server.
+func (c *Client) GetWebhookDeliveries(webhookID string, request *GetWebhookDeliveriesRequest) ([]*WebhookDelivery, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/webhook/%s/deliveries", webhookID))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return WebhookDeliveriesFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// RedeliverWebhookDelivery requests the given failed delivery to be attempted
+// again by the configured elrond server.
+func (c *Client) RedeliverWebhookDelivery(webhookID, deliveryID string) (*WebhookDelivery, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/webhook/%s/delivery/%s/redeliver", webhookID, deliveryID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return WebhookDeliveryFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// CreateToken requests the creation of a token from the configured elrond
+// server. The returned response holds the token secret, which cannot be
+// retrieved again.
+func (c *Client) CreateToken(request *CreateTokenRequest) (*CreateTokenResponse, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/tokens"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return CreateTokenResponseFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetToken fetches the token from the configured elrond server.
+func (c *Client) GetToken(tokenID string) (*Token, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/token/%s", tokenID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return TokenFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetTokens fetches the list of tokens from the configured elrond server.
+func (c *Client) GetTokens(request *GetTokensRequest) ([]*Token, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/tokens"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return TokensFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// DeleteToken revokes the given token on the configured elrond server.
+func (c *Client) DeleteToken(tokenID string) error {
+	resp, err := c.doDelete(c.buildURL("/api/v1/token/%s", tokenID))
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+
+	default:
+		return apiErrorFromResponse(resp)
+	}
+}
+
+// RotateProvisionerCredentials replaces the credentials the configured elrond
+// server uses to authenticate against the provisioner.
+func (c *Client) RotateProvisionerCredentials(request *RotateProvisionerCredentialsRequest) (*ProvisionerCredentials, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/provisioner/credentials"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return ProvisionerCredentialsFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetProvisionerCredentials fetches the metadata of the provisioner
+// credentials in use by the configured elrond server.
+func (c *Client) GetProvisionerCredentials() (*ProvisionerCredentials, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/provisioner/credentials"))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ProvisionerCredentialsFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// LockAPIForRing locks API changes for a given ring.
+func (c *Client) LockAPIForRing(ringID string) error {
+	return c.makeSecurityCall("ring", ringID, "api", "lock")
+}
+
+// UnlockAPIForRing unlocks API changes for a given ring.
+func (c *Client) UnlockAPIForRing(ringID string) error {
+	return c.makeSecurityCall("ring", ringID, "api", "unlock")
+}
+
+// ProtectRing protects the given ring from deletion and forced releases.
+func (c *Client) ProtectRing(ringID string, request *RingProtectionRequest) (*Ring, error) {
+	return c.makeRingProtectionCall(ringID, "protect", request)
+}
+
+// UnprotectRing removes the protection of the given ring. The request must
+// give a reason.
+func (c *Client) UnprotectRing(ringID string, request *RingProtectionRequest) (*Ring, error) {
+	return c.makeRingProtectionCall(ringID, "unprotect", request)
+}
+
+// GetRingProtectionChanges fetches the protection changes of the given ring,
+// newest first.
+func (c *Client) GetRingProtectionChanges(ringID string) ([]*RingProtectionChange, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/security/ring/%s/protection", ringID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return RingProtectionChangesFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+func (c *Client) makeRingProtectionCall(ringID, action string, request *RingProtectionRequest) (*Ring, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/security/ring/%s/%s", ringID, action), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return RingFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// ReloadConfig reloads the configuration file of the configured elrond server.
+func (c *Client) ReloadConfig() error {
+	resp, err := c.doPost(c.buildURL("/api/v1/admin/reload"), nil)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+
+	default:
+		return apiErrorFromResponse(resp)
+	}
+}
+
+// GetDatabaseSchema fetches the schema version, tables, row counts and index
+// health of the database of the configured elrond server.
+func (c *Client) GetDatabaseSchema() (*DatabaseSchema, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/admin/schema"))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return DatabaseSchemaFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// RescheduleWork changes the order in which the supervisors of the
+// configured elrond server work on the given pending work items.
+func (c *Client) RescheduleWork(request *RescheduleWorkRequest) ([]*RescheduledWork, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/admin/reschedule"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return RescheduledWorkFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+func (c *Client) makeSecurityCall(resourceType, id, securityType, action string) error {
+	resp, err := c.doPost(c.buildURL("/api/v1/security/%s/%s/%s/%s", resourceType, id, securityType, action), nil)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+
+	default:
+		return apiErrorFromResponse(resp)
+	}
+
+}
+
+// RegisterRingInstallationGroup registers an installation group to the given ring.
+func (c *Client) RegisterRingInstallationGroup(ringID string, installationGroupRequest *RegisterInstallationGroupRequest) (*Ring, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/ring/%s/installationgroup", ringID), installationGroupRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted:
+		return RingFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// DeleteRingInstallationGroup deletes installation group from the given ring.
+func (c *Client) DeleteRingInstallationGroup(ringID string, installationGroupID string) error {
+	resp, err := c.doDelete(c.buildURL("/api/v1/ring/%s/installationgroup/%s", ringID, installationGroupID))
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusAccepted:
+		return nil
+
+	default:
+		return apiErrorFromResponse(resp)
+	}
+}
+
+// ArchiveInstallationGroup archives the given installation group, allowing its
+// deletion although it is still referenced.
+func (c *Client) ArchiveInstallationGroup(installationGroupID string) (*InstallationGroup, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/installationgroup/%s/archive", installationGroupID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return InstallationGroupFromReader(resp.Body)
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// ReleaseInstallationGroup releases again, on its own, the given installation
+// group whose release failed, resuming the release of its ring.
+func (c *Client) ReleaseInstallationGroup(installationGroupID string, request *InstallationGroupReleaseRequest) (*InstallationGroup, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/installationgroup/%s/release", installationGroupID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationGroupFromReader(resp.Body)
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// UnarchiveInstallationGroup unarchives the given installation group.
+func (c *Client) UnarchiveInstallationGroup(installationGroupID string) (*InstallationGroup, error) {
+	resp, err := c.doDelete(c.buildURL("/api/v1/installationgroup/%s/archive", installationGroupID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return InstallationGroupFromReader(resp.Body)
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetInstallationGroup fetches the specified installation group from the configured elrond server.
+func (c *Client) GetInstallationGroup(installationGroupID string) (*InstallationGroup, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/installationgroup/%s", installationGroupID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return InstallationGroupFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetInstallationGroupSoakChecks fetches the soak check results of an
+// installation group from the configured elrond server, newest first.
+func (c *Client) GetInstallationGroupSoakChecks(installationGroupID string, request *GetSoakCheckResultsRequest) ([]*SoakCheckResult, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/installationgroup/%s/soakchecks", installationGroupID))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return SoakCheckResultsFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// UpdateInstallationGroup requests the update of an installation group from the configured elrond server.
+func (c *Client) UpdateInstallationGroup(installationGroup string, request *UpdateInstallationGroupRequest) (*InstallationGroup, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/installationgroup/%s/update", installationGroup), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return InstallationGroupFromReader(resp.Body)
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// SendProvisionerCallback reports the status of a provisioner group update to
+// the configured elrond server.
+func (c *Client) SendProvisionerCallback(callback *ProvisionerCallback) error {
+	resp, err := c.doPost(c.buildURL("/api/v1/provisioner/callback"), callback)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return nil
+
+	default:
+		return apiErrorFromResponse(resp)
+	}
+}
+
+// Apply requests the changes reconciling the rings and installation groups
+// with the given fleet spec from the configured elrond server. The changes
+// are only made when confirm is set.
+func (c *Client) Apply(spec *FleetSpec, confirm bool) (*ApplyPlan, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/apply"))
+	if err != nil {
+		return nil, err
+	}
+	if confirm {
+		u.RawQuery = url.Values{"confirm": []string{"true"}}.Encode()
+	}
+
+	resp, err := c.doPost(u.String(), spec)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted:
+		return ApplyPlanFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// ImportRing fetches the ring with the given name from the configured elrond
+// server, returning nil if there is none.
+func (c *Client) ImportRing(name string) (*Ring, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/import/ring/%s", url.PathEscape(name)))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return RingFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// ImportInstallationGroup fetches the installation group with the given name
+// from the configured elrond server, returning nil if there is none.
+func (c *Client) ImportInstallationGroup(name string) (*InstallationGroup, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/import/installationgroup/%s", url.PathEscape(name)))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
 		return InstallationGroupFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// CreateRollout requests the creation of a rollout from the configured elrond server.
+func (c *Client) CreateRollout(request *CreateRolloutRequest) (*Rollout, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/rollouts"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return RolloutFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetRollout fetches the specified rollout, with the progress of its rings,
+// from the configured elrond server.
+func (c *Client) GetRollout(rolloutID string) (*Rollout, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/rollout/%s", rolloutID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return RolloutFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetRollouts fetches the list of rollouts, newest first, from the configured elrond server.
+func (c *Client) GetRollouts(request *GetRolloutsRequest) ([]*Rollout, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/rollouts"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return RolloutsFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// CancelRollout cancels the remaining steps of a rollout on the configured elrond server.
+func (c *Client) CancelRollout(rolloutID string) (*Rollout, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/rollout/%s/cancel", rolloutID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return RolloutFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// RollbackRollout releases the rings of a rollout back to their previous
+// release on the configured elrond server.
+func (c *Client) RollbackRollout(rolloutID string) (*Rollout, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/rollout/%s/rollback", rolloutID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return RolloutFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// ResumeRollout resumes a rollout paused by a breached promotion check on
+// the configured elrond server.
+func (c *Client) ResumeRollout(rolloutID string) (*Rollout, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/rollout/%s/resume", rolloutID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return RolloutFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetForceApprovals fetches the list of force approvals, newest first, from
+// the configured elrond server.
+func (c *Client) GetForceApprovals(request *GetForceApprovalsRequest) ([]*ForceApproval, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/force-approvals"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return ForceApprovalsFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// CreateJob queues a background job on the configured elrond server.
+func (c *Client) CreateJob(request *CreateJobRequest) (*Job, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/jobs"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return JobFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetJob fetches the specified job from the configured elrond server.
+func (c *Client) GetJob(jobID string) (*Job, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/job/%s", jobID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return JobFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetJobs fetches the list of jobs, newest first, from the configured elrond server.
+func (c *Client) GetJobs(request *GetJobsRequest) ([]*Job, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/jobs"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return JobsFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// CreateNotificationTemplate creates a notification template on the
+// configured elrond server.
+func (c *Client) CreateNotificationTemplate(request *CreateNotificationTemplateRequest) (*NotificationTemplate, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/notificationtemplates"), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return NotificationTemplateFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetNotificationTemplate fetches the specified notification template from
+// the configured elrond server.
+func (c *Client) GetNotificationTemplate(notificationTemplateID string) (*NotificationTemplate, error) {
+	resp, err := c.doGet(c.buildURL("/api/v1/notificationtemplate/%s", notificationTemplateID))
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return NotificationTemplateFromReader(resp.Body)
+
+	case http.StatusNotFound:
+		return nil, nil
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// GetNotificationTemplates fetches the list of notification templates from
+// the configured elrond server.
+func (c *Client) GetNotificationTemplates(request *GetNotificationTemplatesRequest) ([]*NotificationTemplate, error) {
+	u, err := url.Parse(c.buildURL("/api/v1/notificationtemplates"))
+	if err != nil {
+		return nil, err
+	}
+
+	request.ApplyToURL(u)
+
+	resp, err := c.doGet(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return NotificationTemplatesFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// UpdateNotificationTemplate updates the given notification template on the
+// configured elrond server.
+func (c *Client) UpdateNotificationTemplate(notificationTemplateID string, request *UpdateNotificationTemplateRequest) (*NotificationTemplate, error) {
+	resp, err := c.doPost(c.buildURL("/api/v1/notificationtemplate/%s/update", notificationTemplateID), request)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return NotificationTemplateFromReader(resp.Body)
+
+	default:
+		return nil, apiErrorFromResponse(resp)
+	}
+}
+
+// DeleteNotificationTemplate deletes the given notification template from the
+// configured elrond server.
+func (c *Client) DeleteNotificationTemplate(notificationTemplateID string) error {
+	resp, err := c.doDelete(c.buildURL("/api/v1/notificationtemplate/%s", notificationTemplateID))
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil
+
 	default:
-		return nil, errors.Errorf("failed with status code %d", resp.StatusCode)
+		return apiErrorFromResponse(resp)
 	}
 }
@@ -0,0 +1,200 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DatabaseSchemaFormatDOT renders the entity relationship diagram of a
+	// database schema in the Graphviz DOT language.
+	DatabaseSchemaFormatDOT = "dot"
+	// DatabaseSchemaFormatMermaid renders the entity relationship diagram of
+	// a database schema as a Mermaid ER diagram.
+	DatabaseSchemaFormatMermaid = "mermaid"
+)
+
+// DatabaseSchema describes the tables of the elrond database, so that
+// operators can monitor their growth and check migrations in each
+// environment.
+type DatabaseSchema struct {
+	// Driver is the database driver, sqlite3 or postgres.
+	Driver string
+	// Version is the schema version the database is migrated to.
+	Version string
+	// LatestVersion is the latest schema version known to the server.
+	LatestVersion string
+	Tables        []*DatabaseTable
+	Indexes       DatabaseIndexSummary
+}
+
+// DatabaseTable describes a table of the elrond database.
+type DatabaseTable struct {
+	Name    string
+	Rows    int64
+	Columns []*DatabaseColumn
+	Indexes []*DatabaseIndex
+}
+
+// DatabaseColumn describes a column of a table.
+type DatabaseColumn struct {
+	Name       string
+	Type       string
+	PrimaryKey bool `json:",omitempty"`
+	// References is the table whose ID the column holds, if any. The schema
+	// declares no foreign keys, so these are known by column name.
+	References string `json:",omitempty"`
+}
+
+// DatabaseIndex describes an index of a table.
+type DatabaseIndex struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	// Valid is false for the postgres indexes whose build failed, such as
+	// an interrupted CREATE INDEX CONCURRENTLY, which are not used by
+	// queries but still slow down writes.
+	Valid bool
+	// Scans is the number of scans of the index since the statistics of
+	// the database were last reset. Only postgres reports it.
+	Scans *int64 `json:",omitempty"`
+	// SizeBytes is the size of the index on disk. Only postgres reports it.
+	SizeBytes int64 `json:",omitempty"`
+}
+
+// DatabaseIndexSummary sums up the health of the indexes of a database.
+type DatabaseIndexSummary struct {
+	Total   int
+	Invalid int
+	// Unused is the number of indexes other than unique ones never scanned,
+	// which only slow down writes. Only postgres reports it.
+	Unused int
+}
+
+// IsUnused returns whether the index is known to have never been scanned. A
+// unique index is never unused, as it enforces its constraint.
+func (i *DatabaseIndex) IsUnused() bool {
+	return !i.Unique && i.Scans != nil && *i.Scans == 0
+}
+
+// NewDatabaseIndexSummary sums up the health of the indexes of the given
+// tables.
+func NewDatabaseIndexSummary(tables []*DatabaseTable) DatabaseIndexSummary {
+	summary := DatabaseIndexSummary{}
+	for _, table := range tables {
+		for _, index := range table.Indexes {
+			summary.Total++
+			if !index.Valid {
+				summary.Invalid++
+			}
+			if index.IsUnused() {
+				summary.Unused++
+			}
+		}
+	}
+
+	return summary
+}
+
+// Render renders the entity relationship diagram of the schema in the given
+// format.
+func (s *DatabaseSchema) Render(format string) (string, error) {
+	switch format {
+	case DatabaseSchemaFormatDOT:
+		return s.DOT(), nil
+	case DatabaseSchemaFormatMermaid:
+		return s.Mermaid(), nil
+	}
+
+	return "", errors.Errorf("unsupported format %q, must be %s or %s", format, DatabaseSchemaFormatDOT, DatabaseSchemaFormatMermaid)
+}
+
+// DOT renders the entity relationship diagram of the schema in the Graphviz
+// DOT language, with a record per table and an edge per referencing column.
+func (s *DatabaseSchema) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", fmt.Sprintf("elrond-%s", s.Version))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=record];\n")
+	for _, table := range s.Tables {
+		fields := []string{table.Name}
+		for _, column := range table.Columns {
+			fields = append(fields, fmt.Sprintf("<%s> %s %s", column.Name, column.Name, column.Type))
+		}
+		fmt.Fprintf(&b, "  %q [label=%q];\n", table.Name, strings.Join(fields, "|"))
+	}
+	for _, table := range s.Tables {
+		for _, column := range table.Columns {
+			if column.References != "" {
+				fmt.Fprintf(&b, "  %q:%q -> %q;\n", table.Name, column.Name, column.References)
+			}
+		}
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Mermaid renders the entity relationship diagram of the schema as a Mermaid
+// ER diagram. Column types are declared as identifiers, as Mermaid does not
+// allow spaces nor parentheses in them.
+func (s *DatabaseSchema) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("erDiagram\n")
+	for _, table := range s.Tables {
+		fmt.Fprintf(&b, "  %s {\n", table.Name)
+		for _, column := range table.Columns {
+			key := ""
+			if column.PrimaryKey {
+				key = " PK"
+			} else if column.References != "" {
+				key = " FK"
+			}
+			fmt.Fprintf(&b, "    %s %s%s\n", mermaidType(column.Type), column.Name, key)
+		}
+		b.WriteString("  }\n")
+	}
+	for _, table := range s.Tables {
+		for _, column := range table.Columns {
+			if column.References != "" {
+				fmt.Fprintf(&b, "  %s ||--o{ %s : %s\n", column.References, table.Name, column.Name)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// mermaidType returns the given column type as a Mermaid identifier.
+func mermaidType(columnType string) string {
+	if columnType == "" {
+		return "unknown"
+	}
+
+	return strings.TrimRight(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, columnType), "_")
+}
+
+// DatabaseSchemaFromReader decodes a json-encoded database schema from the
+// given io.Reader.
+func DatabaseSchemaFromReader(reader io.Reader) (*DatabaseSchema, error) {
+	schema := DatabaseSchema{}
+	err := json.NewDecoder(reader).Decode(&schema)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &schema, nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDatabaseIndexSummary(t *testing.T) {
+	scans := int64(0)
+	tables := []*DatabaseTable{
+		{Name: "Ring", Indexes: []*DatabaseIndex{
+			{Name: "Ring_pkey", Unique: true, Valid: true, Scans: &scans},
+			{Name: "Ring_State", Valid: false},
+		}},
+		{Name: "Job", Indexes: []*DatabaseIndex{
+			{Name: "Job_State_CreateAt", Valid: true, Scans: &scans},
+		}},
+	}
+
+	require.Equal(t, DatabaseIndexSummary{Total: 3, Invalid: 1, Unused: 1}, NewDatabaseIndexSummary(tables))
+	require.Equal(t, DatabaseIndexSummary{}, NewDatabaseIndexSummary(nil))
+}
+
+func TestDatabaseSchemaRender(t *testing.T) {
+	schema := &DatabaseSchema{
+		Version: "0.62.0",
+		Tables: []*DatabaseTable{
+			{Name: "Ring", Columns: []*DatabaseColumn{
+				{Name: "ID", Type: "CHAR(26)", PrimaryKey: true},
+			}},
+			{Name: "RingInstallationGroup", Columns: []*DatabaseColumn{
+				{Name: "ID", Type: "TEXT", PrimaryKey: true},
+				{Name: "RingID", Type: "character varying", References: "Ring"},
+			}},
+		},
+	}
+
+	dot, err := schema.Render(DatabaseSchemaFormatDOT)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(dot, `digraph "elrond-0.62.0" {`))
+	require.Contains(t, dot, `"Ring" [label="Ring|<ID> ID CHAR(26)"];`)
+	require.Contains(t, dot, `"RingInstallationGroup":"RingID" -> "Ring";`)
+
+	mermaid, err := schema.Render(DatabaseSchemaFormatMermaid)
+	require.NoError(t, err)
+	require.Equal(t, `erDiagram
+  Ring {
+    CHAR_26 ID PK
+  }
+  RingInstallationGroup {
+    TEXT ID PK
+    character_varying RingID FK
+  }
+  Ring ||--o{ RingInstallationGroup : RingID
+`, mermaid)
+
+	_, err = schema.Render("svg")
+	require.Error(t, err)
+}
+
+func TestDatabaseSchemaFromReader(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		schema, err := DatabaseSchemaFromReader(bytes.NewReader([]byte("")))
+		require.NoError(t, err)
+		require.Equal(t, &DatabaseSchema{}, schema)
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		expected := &DatabaseSchema{
+			Driver:  "sqlite3",
+			Version: "0.62.0",
+			Tables:  []*DatabaseTable{{Name: "Ring", Rows: 2}},
+			Indexes: DatabaseIndexSummary{Total: 1},
+		}
+		data, err := json.Marshal(expected)
+		require.NoError(t, err)
+
+		schema, err := DatabaseSchemaFromReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, expected, schema)
+	})
+}
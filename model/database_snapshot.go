@@ -0,0 +1,68 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+const (
+	// DatabaseSnapshotSourceProvisioner is a snapshot taken as an
+	// installation backup by the provisioner.
+	DatabaseSnapshotSourceProvisioner = "provisioner"
+	// DatabaseSnapshotSourceHook is a snapshot taken by the database snapshot
+	// hook of the server.
+	DatabaseSnapshotSourceHook = "hook"
+)
+
+// DefaultDatabaseSnapshotTimeout is the time, in seconds, the snapshots of the
+// databases of an installation group are waited for at most before its
+// release.
+const DefaultDatabaseSnapshotTimeout = 1800
+
+// DatabaseSnapshot records the snapshot of the database of an installation
+// taken right before its installation group was released, so that a rollback
+// can restore the database as it was before the release.
+type DatabaseSnapshot struct {
+	ID                  string
+	ReleaseID           string
+	RingID              string
+	InstallationGroupID string
+	InstallationID      string
+	// SnapshotID is the restore point as known to its source: the ID of the
+	// installation backup for the provisioner, or the ID returned by the
+	// hook.
+	SnapshotID string
+	// Source is DatabaseSnapshotSourceProvisioner or
+	// DatabaseSnapshotSourceHook.
+	Source   string
+	CreateAt int64
+}
+
+// DatabaseSnapshotFilter describes the parameters used to constrain a set of
+// database snapshots.
+type DatabaseSnapshotFilter struct {
+	ReleaseID           string
+	InstallationGroupID string
+	PerPage             int
+	Page                int
+}
+
+// DatabaseSnapshotRequest is posted to the database snapshot hook to snapshot
+// the database of an installation before its installation group is released.
+// The hook responds once the snapshot is taken, with a
+// DatabaseSnapshotResponse.
+type DatabaseSnapshotRequest struct {
+	InstallationID        string      `json:"installationID"`
+	InstallationGroupID   string      `json:"installationGroupID"`
+	InstallationGroupName string      `json:"installationGroupName"`
+	ProvisionerGroupID    string      `json:"provisionerGroupID"`
+	ReleaseID             string      `json:"releaseID"`
+	Image                 string      `json:"image"`
+	Version               string      `json:"version"`
+	Annotations           Annotations `json:"annotations,omitempty"`
+}
+
+// DatabaseSnapshotResponse is the response of the database snapshot hook,
+// identifying the snapshot taken.
+type DatabaseSnapshotResponse struct {
+	ID string `json:"id"`
+}
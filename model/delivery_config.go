@@ -0,0 +1,584 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TypeNotificationTemplate is the string value that represents a
+	// notification template.
+	TypeNotificationTemplate = "notificationtemplate"
+
+	// SecretRefEnv prefixes the secret references read from an environment
+	// variable.
+	SecretRefEnv = "env:"
+	// SecretRefFile prefixes the secret references read from a file.
+	SecretRefFile = "file:"
+)
+
+// DeliveryConfig is the reviewable configuration of where the notifications
+// of elrond are delivered: the webhooks, and the notifiers of the rings.
+// Secrets are referenced, never inlined. A section left out is not managed
+// by the config.
+type DeliveryConfig struct {
+	Webhooks  []*WebhookConfig `yaml:"webhooks,omitempty"`
+	Notifiers *NotifiersConfig `yaml:"notifiers,omitempty"`
+}
+
+// WebhookConfig is the configuration of a webhook, identified by its owner
+// and URL.
+type WebhookConfig struct {
+	Owner          string `yaml:"owner"`
+	URL            string `yaml:"url"`
+	Format         string `yaml:"format,omitempty"`
+	Language       string `yaml:"language,omitempty"`
+	LabelSelector  string `yaml:"labelSelector,omitempty"`
+	RateLimit      int    `yaml:"rateLimit,omitempty"`
+	Timeout        int    `yaml:"timeout,omitempty"`
+	MaxRedirects   int    `yaml:"maxRedirects,omitempty"`
+	MaxPayloadSize int    `yaml:"maxPayloadSize,omitempty"`
+	// SecretRef references the secret signing the payloads sent to the
+	// webhook, as env:<variable> or file:<path>.
+	SecretRef string `yaml:"secretRef,omitempty"`
+}
+
+// NotifiersConfig is the configuration of the release notifications: the
+// notification targets of the rings, and the notification templates. A list
+// left out is not managed by the config.
+type NotifiersConfig struct {
+	Rings     []*RingNotifierConfig         `yaml:"rings,omitempty"`
+	Templates []*NotificationTemplateConfig `yaml:"templates,omitempty"`
+}
+
+// RingNotifierConfig is the configuration of the release notifications of a
+// ring, identified by its name.
+type RingNotifierConfig struct {
+	Ring     string             `yaml:"ring"`
+	Emails   NotificationEmails `yaml:"emails,omitempty"`
+	Language string             `yaml:"language,omitempty"`
+}
+
+// NotificationTemplateConfig is the configuration of a notification
+// template, identified by its channel, event and language.
+type NotificationTemplateConfig struct {
+	Channel  string `yaml:"channel"`
+	Event    string `yaml:"event"`
+	Language string `yaml:"language"`
+	Subject  string `yaml:"subject,omitempty"`
+	Body     string `yaml:"body"`
+}
+
+// NewWebhookConfigs returns the configuration of the given webhooks, ordered
+// by owner and URL. The secret of a signed webhook is referenced by the
+// environment variable named by WebhookSecretEnv.
+func NewWebhookConfigs(webhooks []*Webhook) []*WebhookConfig {
+	sorted := make([]*Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		if webhook.DeleteAt == 0 {
+			sorted = append(sorted, webhook)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].OwnerID != sorted[j].OwnerID {
+			return sorted[i].OwnerID < sorted[j].OwnerID
+		}
+		return sorted[i].URL < sorted[j].URL
+	})
+
+	configs := make([]*WebhookConfig, 0, len(sorted))
+	secrets := make(map[string]int)
+	for _, webhook := range sorted {
+		config := &WebhookConfig{
+			Owner:          webhook.OwnerID,
+			URL:            webhook.URL,
+			Language:       webhook.Language,
+			LabelSelector:  webhook.LabelSelector,
+			RateLimit:      webhook.RateLimit,
+			Timeout:        webhook.Timeout,
+			MaxRedirects:   webhook.MaxRedirects,
+			MaxPayloadSize: webhook.MaxPayloadSize,
+		}
+		if webhook.Format != WebhookFormatElrond {
+			config.Format = webhook.Format
+		}
+		if webhook.Signed {
+			secrets[webhook.OwnerID]++
+			config.SecretRef = SecretRefEnv + WebhookSecretEnv(webhook.OwnerID, secrets[webhook.OwnerID])
+		}
+		configs = append(configs, config)
+	}
+
+	return configs
+}
+
+// WebhookSecretEnv returns the name of the environment variable referenced
+// for the secret of the given signed webhook of the owner, counting from 1.
+func WebhookSecretEnv(owner string, index int) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(owner))
+
+	return fmt.Sprintf("ELROND_WEBHOOK_SECRET_%s_%d", name, index)
+}
+
+// NewNotifiersConfig returns the configuration of the notification targets
+// of the given rings, ordered by name, and of the given notification
+// templates, ordered by channel, event and language.
+func NewNotifiersConfig(rings []*Ring, templates []*NotificationTemplate) *NotifiersConfig {
+	config := &NotifiersConfig{
+		Rings:     []*RingNotifierConfig{},
+		Templates: []*NotificationTemplateConfig{},
+	}
+	for _, ring := range rings {
+		if ring.DeleteAt != 0 || (len(ring.NotificationEmails) == 0 && ring.NotificationLanguage == "") {
+			continue
+		}
+		config.Rings = append(config.Rings, &RingNotifierConfig{
+			Ring:     ring.Name,
+			Emails:   ring.NotificationEmails,
+			Language: ring.NotificationLanguage,
+		})
+	}
+	sort.SliceStable(config.Rings, func(i, j int) bool {
+		return config.Rings[i].Ring < config.Rings[j].Ring
+	})
+
+	for _, template := range templates {
+		config.Templates = append(config.Templates, &NotificationTemplateConfig{
+			Channel:  template.Channel,
+			Event:    template.Event,
+			Language: template.Language,
+			Subject:  template.Subject,
+			Body:     template.Body,
+		})
+	}
+	sort.SliceStable(config.Templates, func(i, j int) bool {
+		return config.Templates[i].key() < config.Templates[j].key()
+	})
+
+	return config
+}
+
+// Validate validates the values of a delivery config.
+func (c *DeliveryConfig) Validate() error {
+	webhooks := make(map[string]bool)
+	for _, webhook := range c.Webhooks {
+		if err := webhook.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid webhook %s", webhook.key())
+		}
+		if webhooks[webhook.key()] {
+			return errors.Errorf("webhook %s is specified more than once", webhook.key())
+		}
+		webhooks[webhook.key()] = true
+	}
+	if c.Notifiers == nil {
+		return nil
+	}
+
+	rings := make(map[string]bool)
+	for _, ring := range c.Notifiers.Rings {
+		if ring.Ring == "" {
+			return errors.New("ring notifier ring cannot be empty")
+		}
+		if rings[ring.Ring] {
+			return errors.Errorf("notifiers of ring %s are specified more than once", ring.Ring)
+		}
+		rings[ring.Ring] = true
+
+		if err := ring.Emails.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid ring %s notification emails", ring.Ring)
+		}
+		if err := ValidateLanguage(ring.Language); err != nil {
+			return errors.Wrapf(err, "invalid ring %s notification language", ring.Ring)
+		}
+	}
+
+	templates := make(map[string]bool)
+	for _, template := range c.Notifiers.Templates {
+		request := &CreateNotificationTemplateRequest{
+			Channel:  template.Channel,
+			Event:    template.Event,
+			Language: template.Language,
+			Subject:  template.Subject,
+			Body:     template.Body,
+		}
+		if err := request.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid notification template %s", template.key())
+		}
+		if templates[template.key()] {
+			return errors.Errorf("notification template %s is specified more than once", template.key())
+		}
+		templates[template.key()] = true
+	}
+
+	return nil
+}
+
+// Validate validates the values of a webhook config, defaulting its format.
+func (c *WebhookConfig) Validate() error {
+	if c.Owner == "" {
+		return errors.New("must specify owner")
+	}
+	uri, err := url.ParseRequestURI(c.URL)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse callback URL")
+	}
+	if (uri.Scheme != "http" && uri.Scheme != "https") || uri.Host == "" {
+		return errors.Errorf("callback URL %q must be an http or https URL", c.URL)
+	}
+	if c.Format != "" && !ValidWebhookFormat(c.Format) {
+		return errors.Errorf("unsupported webhook format %q", c.Format)
+	}
+	if err = ValidateLanguage(c.Language); err != nil {
+		return err
+	}
+	if _, err = ParseLabelSelector(c.LabelSelector); err != nil {
+		return errors.Wrap(err, "invalid label selector")
+	}
+	if c.RateLimit < 0 {
+		return errors.New("rate limit cannot be negative")
+	}
+	if err = ValidateWebhookLimits(c.Timeout, c.MaxRedirects, c.MaxPayloadSize); err != nil {
+		return err
+	}
+
+	return ValidateSecretRef(c.SecretRef)
+}
+
+// ValidateSecretRef validates a secret reference, as env:<variable> or
+// file:<path>. An empty reference is valid.
+func ValidateSecretRef(ref string) error {
+	if ref == "" {
+		return nil
+	}
+	for _, prefix := range []string{SecretRefEnv, SecretRefFile} {
+		if strings.HasPrefix(ref, prefix) && len(ref) > len(prefix) {
+			return nil
+		}
+	}
+
+	return errors.Errorf("invalid secret reference %q: must be %s<variable> or %s<path>", ref, SecretRefEnv, SecretRefFile)
+}
+
+// key identifies the webhook config.
+func (c *WebhookConfig) key() string {
+	return c.Owner + " " + c.URL
+}
+
+// key identifies the notification template config.
+func (c *NotificationTemplateConfig) key() string {
+	return c.Channel + "/" + c.Event + "/" + c.Language
+}
+
+// CreateWebhookRequest returns the request creating the configured webhook
+// signed with the given secret.
+func (c *WebhookConfig) CreateWebhookRequest(secret string) *CreateWebhookRequest {
+	format := c.Format
+	if format == "" {
+		format = WebhookFormatElrond
+	}
+
+	return &CreateWebhookRequest{
+		OwnerID:        c.Owner,
+		URL:            c.URL,
+		Format:         format,
+		Language:       c.Language,
+		LabelSelector:  c.LabelSelector,
+		Secret:         secret,
+		RateLimit:      c.RateLimit,
+		Timeout:        c.Timeout,
+		MaxRedirects:   c.MaxRedirects,
+		MaxPayloadSize: c.MaxPayloadSize,
+	}
+}
+
+// diff returns the fields of the webhook differing from its config.
+func (c *WebhookConfig) diff(webhook *Webhook) []string {
+	var fields []string
+	format := c.Format
+	if format == "" {
+		format = WebhookFormatElrond
+	}
+	if format != webhook.Format {
+		fields = append(fields, "format")
+	}
+	if c.Language != webhook.Language {
+		fields = append(fields, "language")
+	}
+	labelSelector, _ := ParseLabelSelector(c.LabelSelector)
+	if labelSelector.String() != webhook.LabelSelector {
+		fields = append(fields, "labelSelector")
+	}
+	if c.RateLimit != webhook.RateLimit {
+		fields = append(fields, "rateLimit")
+	}
+	if c.Timeout != webhook.Timeout {
+		fields = append(fields, "timeout")
+	}
+	if c.MaxRedirects != webhook.MaxRedirects {
+		fields = append(fields, "maxRedirects")
+	}
+	if c.MaxPayloadSize != webhook.MaxPayloadSize {
+		fields = append(fields, "maxPayloadSize")
+	}
+	if (c.SecretRef != "") != webhook.Signed {
+		fields = append(fields, "secret")
+	}
+
+	return fields
+}
+
+// DeliveryConfigChange is a change required to reconcile the webhooks and
+// notifiers with a delivery config. ID is the resource updated or deleted.
+type DeliveryConfigChange struct {
+	ApplyChange
+	ID string `json:"id,omitempty"`
+	// Webhook, NotificationTemplate and RingNotifier are the configuration
+	// of the resource created or updated.
+	Webhook              *WebhookConfig              `json:"-"`
+	NotificationTemplate *NotificationTemplateConfig `json:"-"`
+	RingNotifier         *RingNotifierConfig         `json:"-"`
+}
+
+// DeliveryConfigPlan lists the changes required to reconcile the webhooks
+// and notifiers with a delivery config.
+type DeliveryConfigPlan struct {
+	Changes []*DeliveryConfigChange `json:"changes"`
+	// Applied is set once the changes have been made.
+	Applied bool `json:"applied"`
+}
+
+// PlanDeliveryConfig computes the changes reconciling the given webhooks,
+// notification templates and rings with the sections of the config. Webhooks
+// are matched by owner and URL, and are replaced when they differ, as
+// webhooks cannot be updated. Resources absent from the config are deleted,
+// or their notifiers cleared for rings, only when pruning.
+func PlanDeliveryConfig(config *DeliveryConfig, webhooks []*Webhook, templates []*NotificationTemplate, rings []*Ring, prune bool) (*DeliveryConfigPlan, error) {
+	plan := &DeliveryConfigPlan{Changes: []*DeliveryConfigChange{}}
+	if config.Webhooks != nil {
+		plan.Changes = append(plan.Changes, planWebhooks(config.Webhooks, webhooks, prune)...)
+	}
+	if config.Notifiers == nil {
+		return plan, nil
+	}
+	if config.Notifiers.Rings != nil {
+		changes, err := planRingNotifiers(config.Notifiers.Rings, rings, prune)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, changes...)
+	}
+	if config.Notifiers.Templates != nil {
+		plan.Changes = append(plan.Changes, planNotificationTemplates(config.Notifiers.Templates, templates, prune)...)
+	}
+
+	return plan, nil
+}
+
+// planWebhooks computes the changes reconciling the webhooks with their
+// config.
+func planWebhooks(configs []*WebhookConfig, webhooks []*Webhook, prune bool) []*DeliveryConfigChange {
+	existing := make(map[string][]*Webhook)
+	for _, webhook := range webhooks {
+		if webhook.DeleteAt != 0 {
+			continue
+		}
+		key := (&WebhookConfig{Owner: webhook.OwnerID, URL: webhook.URL}).key()
+		existing[key] = append(existing[key], webhook)
+	}
+
+	var changes []*DeliveryConfigChange
+	for _, config := range configs {
+		matches := existing[config.key()]
+		delete(existing, config.key())
+
+		if len(matches) == 0 {
+			changes = append(changes, &DeliveryConfigChange{
+				ApplyChange: ApplyChange{Action: ApplyActionCreate, ResourceType: TypeWebhook, Name: config.key()},
+				Webhook:     config,
+			})
+			continue
+		}
+		if fields := config.diff(matches[0]); len(fields) > 0 {
+			changes = append(changes, &DeliveryConfigChange{
+				ApplyChange: ApplyChange{Action: ApplyActionUpdate, ResourceType: TypeWebhook, Name: config.key(), Fields: fields},
+				ID:          matches[0].ID,
+				Webhook:     config,
+			})
+		}
+		// Duplicates of a configured webhook would deliver every payload
+		// twice.
+		for _, duplicate := range matches[1:] {
+			changes = append(changes, &DeliveryConfigChange{
+				ApplyChange: ApplyChange{Action: ApplyActionDelete, ResourceType: TypeWebhook, Name: config.key()},
+				ID:          duplicate.ID,
+			})
+		}
+	}
+	if !prune {
+		return changes
+	}
+
+	remaining := make([]string, 0, len(existing))
+	for key := range existing {
+		remaining = append(remaining, key)
+	}
+	sort.Strings(remaining)
+	for _, key := range remaining {
+		for _, webhook := range existing[key] {
+			changes = append(changes, &DeliveryConfigChange{
+				ApplyChange: ApplyChange{Action: ApplyActionDelete, ResourceType: TypeWebhook, Name: key},
+				ID:          webhook.ID,
+			})
+		}
+	}
+
+	return changes
+}
+
+// planRingNotifiers computes the changes reconciling the notifiers of the
+// rings with their config. Every configured ring must exist.
+func planRingNotifiers(configs []*RingNotifierConfig, rings []*Ring, prune bool) ([]*DeliveryConfigChange, error) {
+	existing := make(map[string]*Ring)
+	for _, ring := range rings {
+		if ring.DeleteAt == 0 {
+			existing[ring.Name] = ring
+		}
+	}
+
+	var changes []*DeliveryConfigChange
+	configured := make(map[string]bool)
+	for _, config := range configs {
+		configured[config.Ring] = true
+		ring := existing[config.Ring]
+		if ring == nil {
+			return nil, errors.Errorf("ring %s does not exist", config.Ring)
+		}
+		if fields := config.diff(ring); len(fields) > 0 {
+			changes = append(changes, &DeliveryConfigChange{
+				ApplyChange:  ApplyChange{Action: ApplyActionUpdate, ResourceType: TypeRing, Name: ring.Name, Fields: fields},
+				ID:           ring.ID,
+				RingNotifier: config,
+			})
+		}
+	}
+	if !prune {
+		return changes, nil
+	}
+
+	remaining := make([]string, 0, len(existing))
+	for name := range existing {
+		if !configured[name] {
+			remaining = append(remaining, name)
+		}
+	}
+	sort.Strings(remaining)
+	for _, name := range remaining {
+		config := &RingNotifierConfig{Ring: name, Emails: NotificationEmails{}}
+		if fields := config.diff(existing[name]); len(fields) > 0 {
+			changes = append(changes, &DeliveryConfigChange{
+				ApplyChange:  ApplyChange{Action: ApplyActionUpdate, ResourceType: TypeRing, Name: name, Fields: fields},
+				ID:           existing[name].ID,
+				RingNotifier: config,
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+// diff returns the fields of the notifiers of the ring differing from its
+// config.
+func (c *RingNotifierConfig) diff(ring *Ring) []string {
+	var fields []string
+	if !equalNotificationEmails(c.Emails, ring.NotificationEmails) {
+		fields = append(fields, "notificationEmails")
+	}
+	if c.Language != ring.NotificationLanguage {
+		fields = append(fields, "notificationLanguage")
+	}
+
+	return fields
+}
+
+// UpdateRingRequest returns the request updating the notifiers of the ring
+// to their config.
+func (c *RingNotifierConfig) UpdateRingRequest() *UpdateRingRequest {
+	emails := c.Emails
+	if emails == nil {
+		emails = NotificationEmails{}
+	}
+	language := c.Language
+
+	return &UpdateRingRequest{
+		NotificationEmails:   &emails,
+		NotificationLanguage: &language,
+	}
+}
+
+// planNotificationTemplates computes the changes reconciling the
+// notification templates with their config.
+func planNotificationTemplates(configs []*NotificationTemplateConfig, templates []*NotificationTemplate, prune bool) []*DeliveryConfigChange {
+	existing := make(map[string]*NotificationTemplate)
+	for _, template := range templates {
+		existing[(&NotificationTemplateConfig{Channel: template.Channel, Event: template.Event, Language: template.Language}).key()] = template
+	}
+
+	var changes []*DeliveryConfigChange
+	for _, config := range configs {
+		template := existing[config.key()]
+		delete(existing, config.key())
+
+		if template == nil {
+			changes = append(changes, &DeliveryConfigChange{
+				ApplyChange:          ApplyChange{Action: ApplyActionCreate, ResourceType: TypeNotificationTemplate, Name: config.key()},
+				NotificationTemplate: config,
+			})
+			continue
+		}
+
+		var fields []string
+		if config.Subject != template.Subject {
+			fields = append(fields, "subject")
+		}
+		if config.Body != template.Body {
+			fields = append(fields, "body")
+		}
+		if len(fields) > 0 {
+			changes = append(changes, &DeliveryConfigChange{
+				ApplyChange:          ApplyChange{Action: ApplyActionUpdate, ResourceType: TypeNotificationTemplate, Name: config.key(), Fields: fields},
+				ID:                   template.ID,
+				NotificationTemplate: config,
+			})
+		}
+	}
+	if !prune {
+		return changes
+	}
+
+	remaining := make([]string, 0, len(existing))
+	for key := range existing {
+		remaining = append(remaining, key)
+	}
+	sort.Strings(remaining)
+	for _, key := range remaining {
+		changes = append(changes, &DeliveryConfigChange{
+			ApplyChange: ApplyChange{Action: ApplyActionDelete, ResourceType: TypeNotificationTemplate, Name: key},
+			ID:          existing[key].ID,
+		})
+	}
+
+	return changes
+}
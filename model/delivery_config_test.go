@@ -0,0 +1,213 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWebhookConfigs(t *testing.T) {
+	webhooks := []*Webhook{
+		{ID: "webhook3", OwnerID: "team-b", URL: "https://b.example.com", Format: WebhookFormatElrond},
+		{ID: "webhook1", OwnerID: "team-a", URL: "https://a2.example.com", Format: WebhookFormatTeams, Language: "de", Signed: true},
+		{ID: "webhook2", OwnerID: "team-a", URL: "https://a1.example.com", Format: WebhookFormatElrond, LabelSelector: "env=prod", RateLimit: 10, Signed: true},
+		{ID: "webhook4", OwnerID: "team-c", URL: "https://c.example.com", Format: WebhookFormatElrond, DeleteAt: 1},
+	}
+
+	require.Equal(t, []*WebhookConfig{
+		{Owner: "team-a", URL: "https://a1.example.com", LabelSelector: "env=prod", RateLimit: 10, SecretRef: "env:ELROND_WEBHOOK_SECRET_TEAM_A_1"},
+		{Owner: "team-a", URL: "https://a2.example.com", Format: WebhookFormatTeams, Language: "de", SecretRef: "env:ELROND_WEBHOOK_SECRET_TEAM_A_2"},
+		{Owner: "team-b", URL: "https://b.example.com"},
+	}, NewWebhookConfigs(webhooks))
+}
+
+func TestNewNotifiersConfig(t *testing.T) {
+	rings := []*Ring{
+		{ID: "ring2", Name: "staging", NotificationLanguage: "fr"},
+		{ID: "ring1", Name: "production", NotificationEmails: NotificationEmails{"ops@example.com"}},
+		{ID: "ring3", Name: "canary"},
+		{ID: "ring4", Name: "deleted", NotificationEmails: NotificationEmails{"ops@example.com"}, DeleteAt: 1},
+	}
+	templates := []*NotificationTemplate{
+		{ID: "template2", Channel: NotificationChannelTeams, Event: NotificationStateChange, Language: "de", Body: "{{.RingName}}"},
+		{ID: "template1", Channel: NotificationChannelEmail, Event: NotificationReleaseFailed, Language: "de", Subject: "{{.RingName}}", Body: "{{.NewState}}"},
+	}
+
+	require.Equal(t, &NotifiersConfig{
+		Rings: []*RingNotifierConfig{
+			{Ring: "production", Emails: NotificationEmails{"ops@example.com"}},
+			{Ring: "staging", Language: "fr"},
+		},
+		Templates: []*NotificationTemplateConfig{
+			{Channel: NotificationChannelEmail, Event: NotificationReleaseFailed, Language: "de", Subject: "{{.RingName}}", Body: "{{.NewState}}"},
+			{Channel: NotificationChannelTeams, Event: NotificationStateChange, Language: "de", Body: "{{.RingName}}"},
+		},
+	}, NewNotifiersConfig(rings, templates))
+}
+
+func TestDeliveryConfigValidate(t *testing.T) {
+	valid := func() *DeliveryConfig {
+		return &DeliveryConfig{
+			Webhooks: []*WebhookConfig{{Owner: "team-a", URL: "https://a.example.com", SecretRef: "env:SECRET"}},
+			Notifiers: &NotifiersConfig{
+				Rings:     []*RingNotifierConfig{{Ring: "production", Emails: NotificationEmails{"ops@example.com"}}},
+				Templates: []*NotificationTemplateConfig{{Channel: NotificationChannelTeams, Event: NotificationStateChange, Language: "de", Body: "{{.RingName}}"}},
+			},
+		}
+	}
+	require.NoError(t, valid().Validate())
+	require.NoError(t, (&DeliveryConfig{}).Validate())
+
+	testCases := []struct {
+		Description string
+		Change      func(config *DeliveryConfig)
+	}{
+		{"webhook without owner", func(config *DeliveryConfig) { config.Webhooks[0].Owner = "" }},
+		{"webhook with invalid url", func(config *DeliveryConfig) { config.Webhooks[0].URL = "ftp://a.example.com" }},
+		{"webhook with invalid format", func(config *DeliveryConfig) { config.Webhooks[0].Format = "slack" }},
+		{"webhook with invalid label selector", func(config *DeliveryConfig) { config.Webhooks[0].LabelSelector = "env" }},
+		{"webhook with negative rate limit", func(config *DeliveryConfig) { config.Webhooks[0].RateLimit = -1 }},
+		{"webhook with negative timeout", func(config *DeliveryConfig) { config.Webhooks[0].Timeout = -1 }},
+		{"webhook with too small max payload size", func(config *DeliveryConfig) { config.Webhooks[0].MaxPayloadSize = 100 }},
+		{"webhook with inlined secret", func(config *DeliveryConfig) { config.Webhooks[0].SecretRef = "hunter2" }},
+		{"duplicate webhook", func(config *DeliveryConfig) { config.Webhooks = append(config.Webhooks, config.Webhooks[0]) }},
+		{"ring notifier without ring", func(config *DeliveryConfig) { config.Notifiers.Rings[0].Ring = "" }},
+		{"ring notifier with invalid language", func(config *DeliveryConfig) { config.Notifiers.Rings[0].Language = "not a language" }},
+		{"duplicate ring notifier", func(config *DeliveryConfig) {
+			config.Notifiers.Rings = append(config.Notifiers.Rings, config.Notifiers.Rings[0])
+		}},
+		{"template with invalid event", func(config *DeliveryConfig) { config.Notifiers.Templates[0].Event = "release-failed" }},
+		{"duplicate template", func(config *DeliveryConfig) {
+			config.Notifiers.Templates = append(config.Notifiers.Templates, config.Notifiers.Templates[0])
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Description, func(t *testing.T) {
+			config := valid()
+			tc.Change(config)
+			require.Error(t, config.Validate())
+		})
+	}
+}
+
+func TestPlanDeliveryConfig(t *testing.T) {
+	webhooks := []*Webhook{
+		{ID: "webhook1", OwnerID: "team-a", URL: "https://a.example.com", Format: WebhookFormatElrond, Signed: true},
+		{ID: "webhook2", OwnerID: "team-b", URL: "https://b.example.com", Format: WebhookFormatElrond},
+		{ID: "webhook3", OwnerID: "team-c", URL: "https://c.example.com", Format: WebhookFormatElrond},
+		{ID: "webhook4", OwnerID: "team-a", URL: "https://a.example.com", Format: WebhookFormatElrond, Signed: true},
+		{ID: "webhook5", OwnerID: "team-d", URL: "https://d.example.com", Format: WebhookFormatElrond, DeleteAt: 1},
+	}
+	templates := []*NotificationTemplate{
+		{ID: "template1", Channel: NotificationChannelTeams, Event: NotificationStateChange, Language: "de", Body: "old"},
+		{ID: "template2", Channel: NotificationChannelTeams, Event: NotificationStateChange, Language: "fr", Body: "{{.RingName}}"},
+	}
+	rings := []*Ring{
+		{ID: "ring1", Name: "production", NotificationEmails: NotificationEmails{"ops@example.com"}},
+		{ID: "ring2", Name: "staging", NotificationLanguage: "fr"},
+	}
+	config := &DeliveryConfig{
+		Webhooks: []*WebhookConfig{
+			{Owner: "team-a", URL: "https://a.example.com", SecretRef: "env:SECRET"},
+			{Owner: "team-b", URL: "https://b.example.com", Format: WebhookFormatTeams},
+			{Owner: "team-e", URL: "https://e.example.com"},
+		},
+		Notifiers: &NotifiersConfig{
+			Rings: []*RingNotifierConfig{{Ring: "production", Emails: NotificationEmails{"ops@example.com", "oncall@example.com"}}},
+			Templates: []*NotificationTemplateConfig{
+				{Channel: NotificationChannelTeams, Event: NotificationStateChange, Language: "de", Body: "new"},
+				{Channel: NotificationChannelEmail, Event: NotificationReleaseFailed, Language: "de", Body: "{{.RingName}}"},
+			},
+		},
+	}
+
+	summarize := func(plan *DeliveryConfigPlan) []string {
+		var changes []string
+		for _, change := range plan.Changes {
+			summary := change.Action + " " + change.ResourceType + " " + change.Name
+			if change.ID != "" {
+				summary += " (" + change.ID + ")"
+			}
+			for _, field := range change.Fields {
+				summary += " " + field
+			}
+			changes = append(changes, summary)
+		}
+		return changes
+	}
+
+	t.Run("without pruning", func(t *testing.T) {
+		plan, err := PlanDeliveryConfig(config, webhooks, templates, rings, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"delete webhook team-a https://a.example.com (webhook4)",
+			"update webhook team-b https://b.example.com (webhook2) format",
+			"create webhook team-e https://e.example.com",
+			"update ring production (ring1) notificationEmails",
+			"update notificationtemplate teams/state-change/de (template1) body",
+			"create notificationtemplate email/release-failed/de",
+		}, summarize(plan))
+		require.Equal(t, config.Webhooks[1], plan.Changes[1].Webhook)
+		require.Equal(t, config.Notifiers.Rings[0], plan.Changes[3].RingNotifier)
+	})
+
+	t.Run("with pruning", func(t *testing.T) {
+		plan, err := PlanDeliveryConfig(config, webhooks, templates, rings, true)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"delete webhook team-a https://a.example.com (webhook4)",
+			"update webhook team-b https://b.example.com (webhook2) format",
+			"create webhook team-e https://e.example.com",
+			"delete webhook team-c https://c.example.com (webhook3)",
+			"update ring production (ring1) notificationEmails",
+			"update ring staging (ring2) notificationLanguage",
+			"update notificationtemplate teams/state-change/de (template1) body",
+			"create notificationtemplate email/release-failed/de",
+			"delete notificationtemplate teams/state-change/fr (template2)",
+		}, summarize(plan))
+
+		request := plan.Changes[5].RingNotifier.UpdateRingRequest()
+		require.Equal(t, NotificationEmails{}, *request.NotificationEmails)
+		require.Equal(t, "", *request.NotificationLanguage)
+	})
+
+	t.Run("sections left out", func(t *testing.T) {
+		plan, err := PlanDeliveryConfig(&DeliveryConfig{Notifiers: &NotifiersConfig{}}, webhooks, templates, rings, true)
+		require.NoError(t, err)
+		require.Empty(t, plan.Changes)
+	})
+
+	t.Run("secret added", func(t *testing.T) {
+		plan, err := PlanDeliveryConfig(&DeliveryConfig{Webhooks: []*WebhookConfig{
+			{Owner: "team-c", URL: "https://c.example.com", SecretRef: "file:/run/secrets/team-c"},
+		}}, webhooks, nil, nil, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{"update webhook team-c https://c.example.com (webhook3) secret"}, summarize(plan))
+	})
+
+	t.Run("unknown ring", func(t *testing.T) {
+		_, err := PlanDeliveryConfig(&DeliveryConfig{Notifiers: &NotifiersConfig{
+			Rings: []*RingNotifierConfig{{Ring: "unknown"}},
+		}}, nil, nil, rings, false)
+		require.EqualError(t, err, "ring unknown does not exist")
+	})
+}
+
+func TestWebhookConfigCreateWebhookRequest(t *testing.T) {
+	config := &WebhookConfig{Owner: "team-a", URL: "https://a.example.com", LabelSelector: "env=prod", RateLimit: 5, Timeout: 10, MaxPayloadSize: 4096, SecretRef: "env:SECRET"}
+	require.Equal(t, &CreateWebhookRequest{
+		OwnerID:        "team-a",
+		URL:            "https://a.example.com",
+		Format:         WebhookFormatElrond,
+		LabelSelector:  "env=prod",
+		Secret:         "hunter2",
+		RateLimit:      5,
+		Timeout:        10,
+		MaxPayloadSize: 4096,
+	}, config.CreateWebhookRequest("hunter2"))
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// ErrorCodeBadRequest is returned when the request is malformed or invalid.
+	ErrorCodeBadRequest = "bad_request"
+	// ErrorCodeUnauthorized is returned when the request is missing valid credentials.
+	ErrorCodeUnauthorized = "unauthorized"
+	// ErrorCodeForbidden is returned when the request is not allowed.
+	ErrorCodeForbidden = "forbidden"
+	// ErrorCodeNotFound is returned when the requested resource does not exist.
+	ErrorCodeNotFound = "not_found"
+	// ErrorCodeConflict is returned when the request conflicts with the current state.
+	ErrorCodeConflict = "conflict"
+	// ErrorCodeInternal is returned when the server failed to process the request.
+	ErrorCodeInternal = "internal_error"
+	// ErrorCodeLockConflict is returned when the resource is locked by another request.
+	ErrorCodeLockConflict = "lock_conflict"
+	// ErrorCodeAPISecurityLock is returned when the resource has its API security lock enabled.
+	ErrorCodeAPISecurityLock = "api_security_lock"
+	// ErrorCodeInvalidStateTransition is returned when the resource cannot transition to the requested state.
+	ErrorCodeInvalidStateTransition = "invalid_state_transition"
+	// ErrorCodeLimitExceeded is returned when the request would exceed a configured limit.
+	ErrorCodeLimitExceeded = "limit_exceeded"
+	// ErrorCodeForceApprovalRequired is returned when a forced action must be
+	// confirmed by a second token before taking effect.
+	ErrorCodeForceApprovalRequired = "force_approval_required"
+	// ErrorCodeRingProtected is returned when the request would delete, or
+	// force a release of, a protected ring.
+	ErrorCodeRingProtected = "ring_protected"
+	// ErrorCodeReleaseBlocked is returned when a prepared release cannot be
+	// committed yet, listing what blocks it.
+	ErrorCodeReleaseBlocked = "release_blocked"
+	// ErrorCodeInstallationGroupReferenced is returned when an installation
+	// group cannot be deleted before being archived, as it is referenced by
+	// recent release history or a rollback target.
+	ErrorCodeInstallationGroupReferenced = "installation_group_referenced"
+	// ErrorCodePolicyDenied is returned when the authorization policy denies
+	// the request.
+	ErrorCodePolicyDenied = "policy_denied"
+)
+
+// ErrorResponse is the JSON envelope returned by the API on every error.
+type ErrorResponse struct {
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	Details   map[string]string `json:"details,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// ErrorCodeFromStatus returns the generic error code for the given HTTP status code.
+func ErrorCodeFromStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return ErrorCodeBadRequest
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrorCodeForbidden
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusConflict:
+		return ErrorCodeConflict
+	case http.StatusTooManyRequests:
+		return ErrorCodeLimitExceeded
+	default:
+		return ErrorCodeInternal
+	}
+}
+
+// APIError is the error returned by the client when the server responds with
+// an unexpected status code.
+type APIError struct {
+	StatusCode int
+	ErrorResponse
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("failed with status code %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("failed with status code %d: %s", e.StatusCode, e.Message)
+}
+
+// ErrorResponseFromReader decodes a json-encoded error response from the given io.Reader.
+func ErrorResponseFromReader(reader io.Reader) (*ErrorResponse, error) {
+	errorResponse := ErrorResponse{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&errorResponse)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &errorResponse, nil
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIError(t *testing.T) {
+	t.Run("without message", func(t *testing.T) {
+		err := &APIError{StatusCode: http.StatusNotFound}
+		require.EqualError(t, err, "failed with status code 404")
+	})
+
+	t.Run("with message", func(t *testing.T) {
+		err := &APIError{
+			StatusCode:    http.StatusBadRequest,
+			ErrorResponse: ErrorResponse{Code: ErrorCodeBadRequest, Message: "invalid"},
+		}
+		require.EqualError(t, err, "failed with status code 400: invalid")
+	})
+}
+
+func TestErrorResponseFromReader(t *testing.T) {
+	errorResponse, err := ErrorResponseFromReader(strings.NewReader(
+		`{"code":"not_found","message":"ring not found","request_id":"abc"}`,
+	))
+	require.NoError(t, err)
+	require.Equal(t, &ErrorResponse{Code: ErrorCodeNotFound, Message: "ring not found", RequestID: "abc"}, errorResponse)
+}
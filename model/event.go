@@ -0,0 +1,266 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TypeInstallationGroup is the string value that represents an installation group.
+const TypeInstallationGroup = "installationgroup"
+
+const (
+	// BypassReleasePaused marks a rollback transition made while the release
+	// of the ring was paused.
+	BypassReleasePaused = "release-paused"
+	// BypassSupervisorParallelism marks a rollback transition made while the
+	// supervisors were already working on as many rings and installation
+	// groups as allowed at once.
+	BypassSupervisorParallelism = "supervisor-parallelism"
+)
+
+// MaxStateChangeEventsPerQuery is the most state change events loaded at once
+// to build reports, calendars, timelines and release estimates from the
+// history of the rings, which only consider the latest events past it.
+const MaxStateChangeEventsPerQuery = 10000
+
+// StateChangeEvent records a state transition of a ring or installation group.
+type StateChangeEvent struct {
+	ID string
+	// Sequence numbers the events in the order they were recorded.
+	Sequence     int64
+	ResourceType string
+	ResourceID   string
+	// RingID is the ring the resource belongs to, or the ring itself.
+	RingID string
+	// ReleaseID is the desired release of the ring at the time of the transition.
+	ReleaseID string
+	OldState  string
+	NewState  string
+	Timestamp int64
+	// InstanceID is the elrond server that made the transition.
+	InstanceID string `json:",omitempty"`
+	// Error summarizes the failure that caused the transition, if known.
+	Error string `json:",omitempty"`
+	// Bypassed lists, comma separated, the freezes and limits a rollback
+	// transition bypassed, as rollbacks are the emergency path. See
+	// BypassReleasePaused and BypassSupervisorParallelism.
+	Bypassed string `json:",omitempty"`
+	// Metadata is the metadata of the resource at the time of the
+	// transition, and RingMetadata that of the ring an installation group
+	// belongs to.
+	Metadata     Metadata `json:",omitempty"`
+	RingMetadata Metadata `json:",omitempty"`
+}
+
+// StateChangeEventFilter describes the parameters used to constrain a set of state change events.
+type StateChangeEventFilter struct {
+	RingID string
+	// RingIDs, when set, restricts the events to those of the given rings.
+	RingIDs      []string
+	ResourceType string
+	ResourceID   string
+	// ReleaseID, when set, restricts the events to the transitions made
+	// during that ring release.
+	ReleaseID string
+	// State, when set, restricts the events to the transitions to that state.
+	State string
+	// After, when set, restricts the events to those recorded after the
+	// cursor, in the order they were recorded.
+	After *EventCursor
+	// From and To, when set, restrict the events to those at or after From
+	// and before To, in milliseconds.
+	From int64
+	To   int64
+	// Latest, when set, returns the last page of the events instead of the
+	// first, still in chronological order.
+	Latest  bool
+	Page    int
+	PerPage int
+}
+
+// EventCursor is a position in the stream of state change events, which are
+// ordered by sequence number.
+type EventCursor struct {
+	Sequence int64
+	// ID is the ID of the event the cursor points right after. It is only
+	// set for cursors issued before events were numbered, and the sequence
+	// number of the event is looked up in its place.
+	ID string
+}
+
+// StateChangeEventsPage is a page of the state change events stream.
+type StateChangeEventsPage struct {
+	Events []*StateChangeEvent
+	// After is the cursor to request the next page with. It is the cursor the
+	// page was requested with when there are no new events, so consumers can
+	// keep tailing the stream.
+	After string
+}
+
+// GetStateChangeEventsRequest describes the parameters to request a page of
+// the state change events stream.
+type GetStateChangeEventsRequest struct {
+	RingID       string
+	ResourceType string
+	ResourceID   string
+	ReleaseID    string
+	State        string
+	// From and To, when set, restrict the events to those at or after From
+	// and before To, in milliseconds.
+	From    int64
+	To      int64
+	After   string
+	PerPage int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetStateChangeEventsRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	if request.RingID != "" {
+		q.Add("ring", request.RingID)
+	}
+	if request.ResourceType != "" {
+		q.Add("resource_type", request.ResourceType)
+	}
+	if request.ResourceID != "" {
+		q.Add("resource_id", request.ResourceID)
+	}
+	if request.ReleaseID != "" {
+		q.Add("release", request.ReleaseID)
+	}
+	if request.State != "" {
+		q.Add("state", request.State)
+	}
+	if request.From != 0 {
+		q.Add("from", strconv.FormatInt(request.From, 10))
+	}
+	if request.To != 0 {
+		q.Add("to", strconv.FormatInt(request.To, 10))
+	}
+	if request.After != "" {
+		q.Add("after", request.After)
+	}
+	if request.PerPage != 0 {
+		q.Add("per_page", strconv.Itoa(request.PerPage))
+	}
+	u.RawQuery = q.Encode()
+}
+
+// Cursor returns the opaque cursor pointing right after the event.
+func (e *StateChangeEvent) Cursor() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(e.Sequence, 10)))
+}
+
+// ParseEventCursor decodes an opaque event cursor. Cursors issued before
+// events were numbered, made of the timestamp and ID of the event, are still
+// accepted.
+func ParseEventCursor(cursor string) (*EventCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode event cursor")
+	}
+
+	parts := strings.SplitN(string(data), ":", 2)
+	if len(parts) == 2 {
+		if _, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+			return nil, errors.Wrap(err, "malformed event cursor timestamp")
+		}
+		if parts[1] == "" {
+			return nil, errors.New("malformed event cursor")
+		}
+		return &EventCursor{ID: parts[1]}, nil
+	}
+
+	sequence, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || sequence < 0 {
+		return nil, errors.New("malformed event cursor")
+	}
+
+	return &EventCursor{Sequence: sequence}, nil
+}
+
+// NewStateChangeEvent creates the event of the given transition, occurring
+// now. The metadata of installation groups is to be set by the caller.
+func NewStateChangeEvent(resourceType, resourceID string, ring *Ring, oldState, newState string) *StateChangeEvent {
+	event := &StateChangeEvent{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		RingID:       ring.ID,
+		ReleaseID:    ring.DesiredReleaseID,
+		OldState:     oldState,
+		NewState:     newState,
+		Timestamp:    GetMillis(),
+	}
+	if resourceType == TypeRing {
+		event.Metadata = ring.Metadata
+	} else {
+		event.RingMetadata = ring.Metadata
+	}
+
+	return event
+}
+
+// WebhookPayload returns the payload of the webhooks sent for the event, with
+// the given labels of its ring, marked as replayed.
+func (e *StateChangeEvent) WebhookPayload(labels map[string]string) *WebhookPayload {
+	extraData := map[string]string{
+		"Replayed": "true",
+		"EventID":  e.ID,
+	}
+	if e.ReleaseID != "" {
+		extraData["ReleaseID"] = e.ReleaseID
+	}
+	ringID := ""
+	if e.ResourceType == TypeInstallationGroup {
+		ringID = e.RingID
+	}
+
+	return &WebhookPayload{
+		Timestamp:    e.Timestamp * int64(time.Millisecond),
+		ID:           e.ResourceID,
+		Type:         e.ResourceType,
+		NewState:     e.NewState,
+		OldState:     e.OldState,
+		ExtraData:    extraData,
+		RingID:       ringID,
+		Labels:       labels,
+		Metadata:     e.Metadata,
+		RingMetadata: e.RingMetadata,
+	}
+}
+
+// StateChangeEventsPageFromReader decodes a json-encoded page of state change events from the given io.Reader.
+func StateChangeEventsPageFromReader(reader io.Reader) (*StateChangeEventsPage, error) {
+	page := StateChangeEventsPage{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&page)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// StateChangeEventsFromReader decodes a json-encoded list of state change events from the given io.Reader.
+func StateChangeEventsFromReader(reader io.Reader) ([]*StateChangeEvent, error) {
+	events := []*StateChangeEvent{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&events)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return events, nil
+}
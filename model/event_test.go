@@ -0,0 +1,66 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventCursor(t *testing.T) {
+	event := &StateChangeEvent{ID: NewID(), Sequence: 42, Timestamp: 1234}
+
+	cursor, err := ParseEventCursor(event.Cursor())
+	require.NoError(t, err)
+	require.Equal(t, &EventCursor{Sequence: 42}, cursor)
+
+	// Cursors issued before events were numbered point after their event.
+	cursor, err = ParseEventCursor("MTIzNDppZA")
+	require.NoError(t, err)
+	require.Equal(t, &EventCursor{ID: "id"}, cursor)
+
+	for _, invalid := range []string{"not base64!", "bm9jb2xvbg", "YWJjOmlk", "MTIzNDo", "LTE"} {
+		t.Run(invalid, func(t *testing.T) {
+			_, err := ParseEventCursor(invalid)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestGetStateChangeEventsRequestApplyToURL(t *testing.T) {
+	u, err := url.Parse("http://localhost/api/events")
+	require.NoError(t, err)
+
+	request := &GetStateChangeEventsRequest{RingID: "ring", After: "cursor", PerPage: 10}
+	request.ApplyToURL(u)
+
+	require.Equal(t, "after=cursor&per_page=10&ring=ring", u.RawQuery)
+}
+
+func TestStateChangeEventWebhookPayload(t *testing.T) {
+	event := &StateChangeEvent{
+		ID:           "event1",
+		ResourceType: TypeInstallationGroup,
+		ResourceID:   "group1",
+		RingID:       "ring1",
+		ReleaseID:    "release1",
+		OldState:     InstallationGroupReleaseRequested,
+		NewState:     InstallationGroupReleaseSoakingRequested,
+		Timestamp:    1234,
+	}
+
+	require.Equal(t, &WebhookPayload{
+		Timestamp: 1234000000,
+		ID:        "group1",
+		Type:      TypeInstallationGroup,
+		OldState:  InstallationGroupReleaseRequested,
+		NewState:  InstallationGroupReleaseSoakingRequested,
+		ExtraData: map[string]string{"Replayed": "true", "EventID": "event1", "ReleaseID": "release1"},
+		RingID:    "ring1",
+		Labels:    map[string]string{"env": "prod"},
+	}, event.WebhookPayload(map[string]string{"env": "prod"}))
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import "encoding/json"
+
+// ReleaseEvidence is the record of a completed ring release, archived for
+// compliance retention independently of the database.
+type ReleaseEvidence struct {
+	Ring               *Ring
+	Release            *RingRelease
+	InstallationGroups []*InstallationGroup
+	Timeline           *ReleaseTimeline
+	Events             []*StateChangeEvent
+	// Health compares the health of each installation group before the
+	// release with its health after the soak.
+	Health []*HealthSnapshotDiff `json:",omitempty"`
+	// CompletedAt is the time, in milliseconds, at which the release completed.
+	CompletedAt int64
+}
+
+// NewReleaseEvidence builds the evidence of the given completed release of a
+// ring from the state change events of the ring, keeping only the events and
+// timeline of the last run of that release.
+func NewReleaseEvidence(ring *Ring, release *RingRelease, installationGroups []*InstallationGroup, events []*StateChangeEvent, completedAt int64) *ReleaseEvidence {
+	evidence := &ReleaseEvidence{
+		Ring:               ring,
+		Release:            release,
+		InstallationGroups: installationGroups,
+		Events:             []*StateChangeEvent{},
+		CompletedAt:        completedAt,
+	}
+
+	timeline := BuildRingTimeline(ring.ID, events, 0)
+	for i := len(timeline.Releases) - 1; i >= 0; i-- {
+		if timeline.Releases[i].ReleaseID == release.ID {
+			evidence.Timeline = timeline.Releases[i]
+			break
+		}
+	}
+	if evidence.Timeline == nil {
+		return evidence
+	}
+
+	for _, event := range events {
+		if event.ReleaseID != release.ID || event.Timestamp < evidence.Timeline.StartAt {
+			continue
+		}
+		if evidence.Timeline.EndAt > 0 && event.Timestamp > evidence.Timeline.EndAt {
+			continue
+		}
+		evidence.Events = append(evidence.Events, event)
+	}
+
+	return evidence
+}
+
+// ToJSON returns a JSON string representation of the release evidence.
+func (e *ReleaseEvidence) ToJSON() (string, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReleaseEvidence(t *testing.T) {
+	ringEvent := func(releaseID, state string, timestamp int64) *StateChangeEvent {
+		return &StateChangeEvent{ResourceType: TypeRing, ResourceID: "ring", RingID: "ring", ReleaseID: releaseID, NewState: state, Timestamp: timestamp}
+	}
+	groupEvent := func(releaseID, state string, timestamp int64) *StateChangeEvent {
+		return &StateChangeEvent{ResourceType: TypeInstallationGroup, ResourceID: "group", RingID: "ring", ReleaseID: releaseID, NewState: state, Timestamp: timestamp}
+	}
+
+	ring := &Ring{ID: "ring"}
+	release := &RingRelease{ID: "release2"}
+
+	t.Run("completed release", func(t *testing.T) {
+		events := []*StateChangeEvent{
+			ringEvent("release1", RingStateReleasePending, 100),
+			ringEvent("release1", RingStateStable, 200),
+			ringEvent("release2", RingStateReleasePending, 300),
+			ringEvent("release2", RingStateReleaseRequested, 400),
+			groupEvent("release2", InstallationGroupReleaseRequested, 500),
+			groupEvent("release2", InstallationGroupStable, 600),
+			ringEvent("release2", RingStateStable, 700),
+		}
+
+		evidence := NewReleaseEvidence(ring, release, nil, events, 700)
+		require.Equal(t, events[2:], evidence.Events)
+		require.NotNil(t, evidence.Timeline)
+		require.Equal(t, "release2", evidence.Timeline.ReleaseID)
+		require.Equal(t, int64(400), evidence.Timeline.Duration)
+		require.Equal(t, int64(700), evidence.CompletedAt)
+
+		data, err := evidence.ToJSON()
+		require.NoError(t, err)
+		require.Contains(t, data, `"ReleaseID":"release2"`)
+	})
+
+	t.Run("retried release keeps the last run", func(t *testing.T) {
+		events := []*StateChangeEvent{
+			ringEvent("release2", RingStateReleasePending, 100),
+			ringEvent("release2", RingStateStable, 200),
+			ringEvent("release2", RingStateReleasePending, 300),
+			ringEvent("release2", RingStateStable, 400),
+		}
+
+		evidence := NewReleaseEvidence(ring, release, nil, events, 400)
+		require.Equal(t, events[2:], evidence.Events)
+	})
+
+	t.Run("no events", func(t *testing.T) {
+		evidence := NewReleaseEvidence(ring, release, nil, nil, 400)
+		require.Nil(t, evidence.Timeline)
+		require.Empty(t, evidence.Events)
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+var failureDomainRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,62}$`)
+
+// ValidateFailureDomain validates the failure domain of an installation
+// group, such as an availability zone or region. An empty failure domain is
+// valid.
+func ValidateFailureDomain(failureDomain string) error {
+	if failureDomain == "" {
+		return nil
+	}
+	if !failureDomainRegex.MatchString(failureDomain) {
+		return errors.Errorf("invalid failure domain %q: must be alphanumerics, dots, dashes and underscores, up to 63 characters", failureDomain)
+	}
+
+	return nil
+}
+
+// ValidateMaxPerFailureDomain validates the max per failure domain of a ring.
+func ValidateMaxPerFailureDomain(maxPerFailureDomain int) error {
+	if maxPerFailureDomain < 0 {
+		return errors.New("max per failure domain cannot be negative")
+	}
+
+	return nil
+}
+
+// CurrentMaxPerFailureDomain returns the number of installation groups of
+// the ring in the same failure domain released at once, defaulting to one.
+// Installation groups without a failure domain are only limited by the max
+// concurrency of the ring.
+func (r *Ring) CurrentMaxPerFailureDomain() int {
+	if r.MaxPerFailureDomain > 0 {
+		return r.MaxPerFailureDomain
+	}
+
+	return 1
+}
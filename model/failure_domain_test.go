@@ -0,0 +1,37 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFailureDomain(t *testing.T) {
+	require.NoError(t, ValidateFailureDomain(""))
+	require.NoError(t, ValidateFailureDomain("us-east-1a"))
+	require.NoError(t, ValidateFailureDomain("eu_west.2"))
+
+	require.Error(t, ValidateFailureDomain("us east"))
+	require.Error(t, ValidateFailureDomain("-us-east-1a"))
+	require.Error(t, ValidateFailureDomain(strings.Repeat("a", 64)))
+
+	invalid := "us east"
+	require.Error(t, (&UpdateInstallationGroupRequest{FailureDomain: &invalid}).Validate())
+	require.Error(t, (&RegisterInstallationGroupRequest{Name: "ig", ProvisionerGroupID: "pg", FailureDomain: invalid}).Validate())
+}
+
+func TestCurrentMaxPerFailureDomain(t *testing.T) {
+	require.NoError(t, ValidateMaxPerFailureDomain(0))
+	require.Error(t, ValidateMaxPerFailureDomain(-1))
+
+	ring := &Ring{}
+	require.Equal(t, 1, ring.CurrentMaxPerFailureDomain())
+
+	ring.MaxPerFailureDomain = 2
+	require.Equal(t, 2, ring.CurrentMaxPerFailureDomain())
+}
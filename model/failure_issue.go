@@ -0,0 +1,193 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultFailureIssueWindow is the time, in seconds, within which the
+// release failures of a ring are counted towards opening a failure issue.
+const DefaultFailureIssueWindow = 7 * 24 * 3600
+
+// RepeatedFailures are the release failures of a ring within a window,
+// reported in a failure issue once there are enough of them.
+type RepeatedFailures struct {
+	Ring   *Ring
+	Window time.Duration
+	// Failures are the transitions of the ring to a failed state, oldest
+	// first.
+	Failures []*StateChangeEvent
+	// Reasons are the failures of the installation groups, and the errors,
+	// recorded during the failed releases, with the number of times each
+	// occurred.
+	Reasons map[string]int
+	// EventsURL, when set, is the URL of the events of the ring on the
+	// server, linked to from the issue.
+	EventsURL string
+}
+
+// IsRingFailureState returns whether a ring transitioning to the given state
+// failed its release.
+func IsRingFailureState(state string) bool {
+	switch state {
+	case RingStateReleaseFailed, RingStateSoakingFailed, RingStateReleaseRollbackFailed:
+		return true
+	}
+	return false
+}
+
+// NewRepeatedFailures returns the release failures of the ring found in the
+// given events of the ring and its installation groups, within the window
+// ending at the given time, in milliseconds. The events of the installation
+// groups failing during the same releases are aggregated as the reasons of
+// the failures.
+func NewRepeatedFailures(ring *Ring, events []*StateChangeEvent, window time.Duration, now int64) *RepeatedFailures {
+	failures := &RepeatedFailures{
+		Ring:     ring,
+		Window:   window,
+		Failures: []*StateChangeEvent{},
+		Reasons:  make(map[string]int),
+	}
+
+	from := now - window.Milliseconds()
+	failedReleases := make(map[string]bool)
+	for _, event := range events {
+		if event.ResourceType != TypeRing || event.Timestamp < from || !IsRingFailureState(event.NewState) {
+			continue
+		}
+		failures.Failures = append(failures.Failures, event)
+		failedReleases[event.ReleaseID] = true
+		if event.Error != "" {
+			failures.Reasons[event.Error]++
+		}
+	}
+	sort.SliceStable(failures.Failures, func(i, j int) bool {
+		return failures.Failures[i].Timestamp < failures.Failures[j].Timestamp
+	})
+
+	for _, event := range events {
+		if event.ResourceType != TypeInstallationGroup || event.Timestamp < from || !failedReleases[event.ReleaseID] {
+			continue
+		}
+		if !(&InstallationGroup{State: event.NewState}).HasFailedRelease() {
+			continue
+		}
+		reason := fmt.Sprintf("installation group %s moved to %s", event.ResourceID, event.NewState)
+		if event.Error != "" {
+			reason += ": " + event.Error
+		}
+		failures.Reasons[reason]++
+	}
+
+	return failures
+}
+
+// IssueContent returns the summary and description of the issue tracking the
+// repeated failures.
+func (f *RepeatedFailures) IssueContent() (string, string) {
+	window := formatWindow(f.Window)
+	summary := fmt.Sprintf("Ring %s failed %d releases within %s", f.Ring.Name, len(f.Failures), window)
+
+	var description strings.Builder
+	fmt.Fprintf(&description, "Ring %s (%s) failed %d releases within %s.\n", f.Ring.Name, f.Ring.ID, len(f.Failures), window)
+	if contacts := f.Ring.Contacts(); contacts != "" {
+		fmt.Fprintf(&description, "Contacts: %s\n", contacts)
+	}
+
+	description.WriteString("\nFailures:\n")
+	for _, failure := range f.Failures {
+		fmt.Fprintf(&description, "- %s: release %s moved from %s to %s", time.UnixMilli(failure.Timestamp).UTC().Format(time.RFC3339), failure.ReleaseID, failure.OldState, failure.NewState)
+		if f.EventsURL != "" {
+			fmt.Fprintf(&description, " (%s)", f.releaseEventsURL(failure.ReleaseID))
+		} else {
+			fmt.Fprintf(&description, " (event %s)", failure.ID)
+		}
+		description.WriteString("\n")
+	}
+
+	if len(f.Reasons) > 0 {
+		reasons := make([]string, 0, len(f.Reasons))
+		for reason := range f.Reasons {
+			reasons = append(reasons, reason)
+		}
+		sort.Slice(reasons, func(i, j int) bool {
+			if f.Reasons[reasons[i]] != f.Reasons[reasons[j]] {
+				return f.Reasons[reasons[i]] > f.Reasons[reasons[j]]
+			}
+			return reasons[i] < reasons[j]
+		})
+
+		description.WriteString("\nFailure reasons:\n")
+		for _, reason := range reasons {
+			fmt.Fprintf(&description, "- %dx %s\n", f.Reasons[reason], reason)
+		}
+	}
+
+	if f.EventsURL != "" {
+		fmt.Fprintf(&description, "\nAll events of the ring: %s\n", f.EventsURL)
+	}
+
+	return summary, description.String()
+}
+
+// formatWindow returns the window in the largest whole unit of days, hours or
+// minutes, falling back to seconds.
+func formatWindow(window time.Duration) string {
+	for _, unit := range []struct {
+		duration time.Duration
+		name     string
+	}{{24 * time.Hour, "day"}, {time.Hour, "hour"}, {time.Minute, "minute"}} {
+		if window >= unit.duration && window%unit.duration == 0 {
+			count := int64(window / unit.duration)
+			if count == 1 {
+				return "1 " + unit.name
+			}
+			return fmt.Sprintf("%d %ss", count, unit.name)
+		}
+	}
+
+	return fmt.Sprintf("%d seconds", int64(window.Seconds()))
+}
+
+// releaseEventsURL returns the URL of the events of the given release of the
+// ring.
+func (f *RepeatedFailures) releaseEventsURL(releaseID string) string {
+	return f.EventsURL + "?release=" + url.QueryEscape(releaseID)
+}
+
+// RingEventsURL returns the URL of the events of the given ring on the server
+// reachable at the given public URL.
+func RingEventsURL(publicURL, ringID string) string {
+	return fmt.Sprintf("%s/api/v1/ring/%s/events", strings.TrimSuffix(publicURL, "/"), url.PathEscape(ringID))
+}
+
+// FailureIssueSettings configure the issues opened for rings failing
+// releases repeatedly: once a ring failed Threshold releases within Window,
+// in seconds. A zero Threshold disables failure issues. PublicURL, when set,
+// is the URL the events of the ring linked to from the issues are served at.
+type FailureIssueSettings struct {
+	Threshold int
+	Window    int
+	PublicURL string
+}
+
+// Validate validates the failure issue settings.
+func (s FailureIssueSettings) Validate() error {
+	if s.Threshold < 0 {
+		return errors.New("failure issue threshold cannot be negative")
+	}
+	if s.Threshold > 0 && s.Window <= 0 {
+		return errors.New("failure issue window must be greater than 0")
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRepeatedFailures(t *testing.T) {
+	ring := &Ring{ID: "ring1", Name: "production", OwnerTeam: "Platform"}
+	now := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC).UnixMilli()
+	events := []*StateChangeEvent{
+		{ID: "event1", ResourceType: TypeRing, ResourceID: "ring1", ReleaseID: "release0", OldState: RingStateReleaseInProgress, NewState: RingStateReleaseFailed, Timestamp: now - 2*3600*1000},
+		{ID: "event2", ResourceType: TypeRing, ResourceID: "ring1", ReleaseID: "release2", OldState: RingStateSoakingRequested, NewState: RingStateSoakingFailed, Timestamp: now - 60*1000, Error: "soak check failed"},
+		{ID: "event3", ResourceType: TypeRing, ResourceID: "ring1", ReleaseID: "release1", OldState: RingStateReleaseInProgress, NewState: RingStateReleaseFailed, Timestamp: now - 1800*1000},
+		{ID: "event4", ResourceType: TypeRing, ResourceID: "ring1", ReleaseID: "release1", OldState: RingStateReleasePending, NewState: RingStateReleaseRequested, Timestamp: now - 1900*1000},
+		{ID: "event5", ResourceType: TypeInstallationGroup, ResourceID: "group1", ReleaseID: "release1", OldState: InstallationGroupReleaseRequested, NewState: InstallationGroupReleaseFailed, Timestamp: now - 1850*1000},
+		{ID: "event6", ResourceType: TypeInstallationGroup, ResourceID: "group1", ReleaseID: "release2", OldState: InstallationGroupReleaseRequested, NewState: InstallationGroupReleaseFailed, Timestamp: now - 120*1000},
+		{ID: "event7", ResourceType: TypeInstallationGroup, ResourceID: "group2", ReleaseID: "release2", OldState: InstallationGroupReleaseRequested, NewState: InstallationGroupStable, Timestamp: now - 120*1000},
+		{ID: "event8", ResourceType: TypeInstallationGroup, ResourceID: "group3", ReleaseID: "release0", OldState: InstallationGroupReleaseRequested, NewState: InstallationGroupReleaseFailed, Timestamp: now - 2*3600*1000},
+	}
+
+	failures := NewRepeatedFailures(ring, events, time.Hour, now)
+	require.Len(t, failures.Failures, 2)
+	require.Equal(t, "event3", failures.Failures[0].ID)
+	require.Equal(t, "event2", failures.Failures[1].ID)
+	require.Equal(t, map[string]int{
+		"installation group group1 moved to release-failed": 2,
+		"soak check failed": 1,
+	}, failures.Reasons)
+
+	t.Run("issue content", func(t *testing.T) {
+		failures.EventsURL = RingEventsURL("https://elrond.example.com/", ring.ID)
+
+		summary, description := failures.IssueContent()
+		require.Equal(t, "Ring production failed 2 releases within 1 hour", summary)
+		require.Equal(t, "Ring production (ring1) failed 2 releases within 1 hour.\n"+
+			"Contacts: owner team Platform\n"+
+			"\nFailures:\n"+
+			"- 2024-05-04T11:30:00Z: release release1 moved from release-in-progress to release-failed (https://elrond.example.com/api/v1/ring/ring1/events?release=release1)\n"+
+			"- 2024-05-04T11:59:00Z: release release2 moved from soaking-requested to soaking-failed (https://elrond.example.com/api/v1/ring/ring1/events?release=release2)\n"+
+			"\nFailure reasons:\n"+
+			"- 2x installation group group1 moved to release-failed\n"+
+			"- 1x soak check failed\n"+
+			"\nAll events of the ring: https://elrond.example.com/api/v1/ring/ring1/events\n", description)
+	})
+
+	t.Run("issue content without public url", func(t *testing.T) {
+		failures.EventsURL = ""
+
+		_, description := failures.IssueContent()
+		require.Contains(t, description, "release release1 moved from release-in-progress to release-failed (event event3)\n")
+		require.NotContains(t, description, "All events of the ring")
+	})
+}
+
+func TestFormatWindow(t *testing.T) {
+	for window, expected := range map[time.Duration]string{
+		DefaultFailureIssueWindow * time.Second: "7 days",
+		24 * time.Hour:                          "1 day",
+		90 * time.Minute:                        "90 minutes",
+		36 * time.Hour:                          "36 hours",
+		45 * time.Second:                        "45 seconds",
+	} {
+		require.Equal(t, expected, formatWindow(window))
+	}
+}
+
+func TestFailureIssueSettingsValidate(t *testing.T) {
+	require.NoError(t, FailureIssueSettings{}.Validate())
+	require.NoError(t, FailureIssueSettings{Threshold: 3, Window: 3600}.Validate())
+	require.Error(t, FailureIssueSettings{Threshold: -1}.Validate())
+	require.Error(t, FailureIssueSettings{Threshold: 3}.Validate())
+}
+
+func TestRingFailureIssueBlocker(t *testing.T) {
+	require.Nil(t, RingFailureIssueBlocker(&Ring{ID: "ring1", Name: "production"}))
+	require.Equal(t, &ReleaseBlocker{
+		Reason:  ReleaseBlockerRepeatedFailures,
+		Message: "repeated release failures of ring production are tracked in https://jira.example.com/browse/REL-1",
+		RingID:  "ring1",
+		URL:     "https://jira.example.com/browse/REL-1",
+	}, RingFailureIssueBlocker(&Ring{ID: "ring1", Name: "production", FailureIssueURL: "https://jira.example.com/browse/REL-1"}))
+}
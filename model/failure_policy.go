@@ -0,0 +1,38 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+const (
+	// FailurePolicyHalt fails the ring of an installation group whose release
+	// or soak failed, along with every other ring pending work. It is the
+	// default policy.
+	FailurePolicyHalt = "halt"
+	// FailurePolicyRollbackRing rolls back the ring of an installation group
+	// whose release or soak failed, and fails every other ring pending work.
+	FailurePolicyRollbackRing = "rollback-ring"
+	// FailurePolicyRollbackInstallationGroup rolls back the installation
+	// group whose release or soak failed to the release it ran before, and
+	// carries on releasing the other installation groups of the ring.
+	// The ring fails once they are done.
+	FailurePolicyRollbackInstallationGroup = "rollback-ig-only"
+	// FailurePolicyContinue leaves the installation group whose release or
+	// soak failed as is, and carries on releasing the other installation
+	// groups of the ring. The ring fails once they are done.
+	FailurePolicyContinue = "continue-remaining-igs"
+)
+
+// CurrentFailurePolicy returns the failure policy of the ring, defaulting to
+// FailurePolicyRollbackRing for rings with AutoRollback set, and to
+// FailurePolicyHalt otherwise.
+func (c *Ring) CurrentFailurePolicy() string {
+	if c.FailurePolicy == "" {
+		if c.AutoRollback {
+			return FailurePolicyRollbackRing
+		}
+		return FailurePolicyHalt
+	}
+
+	return c.FailurePolicy
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ForceApprovalActionRelease is a forced release of one or more rings.
+	ForceApprovalActionRelease = "release"
+	// ForceApprovalActionUnlock is the removal of the API security lock of a
+	// ring.
+	ForceApprovalActionUnlock = "unlock"
+)
+
+// ForceApproval records a forced action on rings requiring the two-person
+// rule. The action is requested by one token and takes effect once another
+// token sends the same request before the approval expires. Confirmed
+// approvals are kept as the audit log of the forced actions.
+type ForceApproval struct {
+	ID     string
+	Action string
+	// Target is the comma separated, sorted IDs of the rings the action
+	// applies to.
+	Target string
+	// RequestHash identifies the request the approval was created for, so
+	// that only the same request confirms it.
+	RequestHash string
+	// RequestedBy and ConfirmedBy are the IDs of the tokens that requested
+	// and confirmed the action.
+	RequestedBy string
+	ConfirmedBy string
+	CreateAt    int64
+	ExpireAt    int64
+	ConfirmAt   int64
+}
+
+// ForceApprovalFilter describes the parameters used to constrain a set of
+// force approvals.
+type ForceApprovalFilter struct {
+	// RingID, when set, restricts the approvals to those targeting the ring.
+	RingID  string
+	Page    int
+	PerPage int
+}
+
+// GetForceApprovalsRequest describes the parameters to request a list of
+// force approvals.
+type GetForceApprovalsRequest struct {
+	RingID  string
+	Page    int
+	PerPage int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetForceApprovalsRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	if request.RingID != "" {
+		q.Add("ring", request.RingID)
+	}
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	u.RawQuery = q.Encode()
+}
+
+// ForceApprovalTarget returns the target of an action on the given rings.
+func ForceApprovalTarget(ringIDs []string) string {
+	sorted := append([]string(nil), ringIDs...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// IsConfirmed returns whether a second token confirmed the action.
+func (a *ForceApproval) IsConfirmed() bool {
+	return a.ConfirmedBy != ""
+}
+
+// IsExpired returns whether the approval can no longer be confirmed at the
+// given time, in milliseconds.
+func (a *ForceApproval) IsExpired(now int64) bool {
+	return !a.IsConfirmed() && now >= a.ExpireAt
+}
+
+// ForceApprovalsFromReader decodes a json-encoded list of force approvals
+// from the given io.Reader.
+func ForceApprovalsFromReader(reader io.Reader) ([]*ForceApproval, error) {
+	approvals := []*ForceApproval{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&approvals)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return approvals, nil
+}
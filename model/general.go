@@ -4,6 +4,8 @@
 
 package model
 
+import "time"
+
 const (
 	// AllPerPage signals the store to return all results, avoid pagination of any kind.
 	AllPerPage = -1
@@ -12,3 +14,8 @@ const (
 	// of the number of installations using each instance.
 	NoInstallationsLimit = -1
 )
+
+// GetMillis is a convenience method to get milliseconds since epoch.
+func GetMillis() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
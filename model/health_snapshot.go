@@ -0,0 +1,144 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+const (
+	// HealthSnapshotPreRelease is the health of an installation group right
+	// before its release.
+	HealthSnapshotPreRelease = "pre-release"
+	// HealthSnapshotPostSoak is the health of an installation group once it
+	// finished soaking, or right after its release when forced.
+	HealthSnapshotPostSoak = "post-soak"
+
+	// MaxHealthSnapshotsPerQuery is the most health snapshots of a release
+	// loaded at once to compare the health of its installation groups.
+	MaxHealthSnapshotsPerQuery = 10000
+)
+
+// HealthSnapshot records the health of the provisioner group of an
+// installation group, as reported by the provisioner, at a boundary of a
+// release.
+type HealthSnapshot struct {
+	ID                  string
+	InstallationGroupID string
+	RingID              string
+	ReleaseID           string
+	// Stage is HealthSnapshotPreRelease or HealthSnapshotPostSoak.
+	Stage                       string
+	InstallationsTotal          int64
+	InstallationsUpdated        int64
+	InstallationsUpdating       int64
+	InstallationsHibernating    int64
+	InstallationsAwaitingUpdate int64
+	// Error is the error the provisioner returned instead of the health of
+	// the group, if any.
+	Error    string `json:",omitempty"`
+	CreateAt int64
+}
+
+// HealthSnapshotFilter describes the parameters used to constrain a set of
+// health snapshots.
+type HealthSnapshotFilter struct {
+	ReleaseID string
+	RingID    string
+	// Latest, when set, returns the last page of the snapshots instead of the
+	// first, still oldest first.
+	Latest  bool
+	PerPage int
+	Page    int
+}
+
+// HealthSnapshotDiff compares the health of an installation group before a
+// release with its health after the soak.
+type HealthSnapshotDiff struct {
+	InstallationGroupID string
+	RingID              string
+	Before              *HealthSnapshot `json:",omitempty"`
+	After               *HealthSnapshot `json:",omitempty"`
+	// Changes holds, by count name, the difference between the after and
+	// before counts that changed. It is only set when both snapshots were
+	// taken without error.
+	Changes map[string]int64 `json:",omitempty"`
+}
+
+// counts returns the counts of the snapshot by name.
+func (s *HealthSnapshot) counts() map[string]int64 {
+	return map[string]int64{
+		"InstallationsTotal":          s.InstallationsTotal,
+		"InstallationsUpdated":        s.InstallationsUpdated,
+		"InstallationsUpdating":       s.InstallationsUpdating,
+		"InstallationsHibernating":    s.InstallationsHibernating,
+		"InstallationsAwaitingUpdate": s.InstallationsAwaitingUpdate,
+	}
+}
+
+// BuildHealthSnapshotDiffs compares, for each installation group, its latest
+// pre-release and post-soak snapshots among the given ones. The diffs are
+// sorted by installation group ID.
+func BuildHealthSnapshotDiffs(snapshots []*HealthSnapshot) []*HealthSnapshotDiff {
+	diffs := make(map[string]*HealthSnapshotDiff)
+	for _, snapshot := range snapshots {
+		diff := diffs[snapshot.InstallationGroupID]
+		if diff == nil {
+			diff = &HealthSnapshotDiff{
+				InstallationGroupID: snapshot.InstallationGroupID,
+				RingID:              snapshot.RingID,
+			}
+			diffs[snapshot.InstallationGroupID] = diff
+		}
+
+		switch snapshot.Stage {
+		case HealthSnapshotPreRelease:
+			if diff.Before == nil || snapshot.CreateAt >= diff.Before.CreateAt {
+				diff.Before = snapshot
+			}
+		case HealthSnapshotPostSoak:
+			if diff.After == nil || snapshot.CreateAt >= diff.After.CreateAt {
+				diff.After = snapshot
+			}
+		}
+	}
+
+	result := make([]*HealthSnapshotDiff, 0, len(diffs))
+	for _, diff := range diffs {
+		if diff.Before != nil && diff.After != nil && diff.Before.Error == "" && diff.After.Error == "" {
+			after := diff.After.counts()
+			for name, before := range diff.Before.counts() {
+				if after[name] != before {
+					if diff.Changes == nil {
+						diff.Changes = make(map[string]int64)
+					}
+					diff.Changes[name] = after[name] - before
+				}
+			}
+		}
+		result = append(result, diff)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].InstallationGroupID < result[j].InstallationGroupID
+	})
+
+	return result
+}
+
+// HealthSnapshotDiffsFromReader decodes a json-encoded list of health
+// snapshot diffs from the given io.Reader.
+func HealthSnapshotDiffsFromReader(reader io.Reader) ([]*HealthSnapshotDiff, error) {
+	diffs := []*HealthSnapshotDiff{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&diffs)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return diffs, nil
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildHealthSnapshotDiffs(t *testing.T) {
+	snapshots := []*HealthSnapshot{
+		{InstallationGroupID: "ig2", RingID: "ring1", Stage: HealthSnapshotPreRelease, Error: "provisioner unavailable", CreateAt: 1},
+		{InstallationGroupID: "ig1", RingID: "ring1", Stage: HealthSnapshotPreRelease, InstallationsTotal: 4, InstallationsUpdated: 1, CreateAt: 1},
+		{InstallationGroupID: "ig1", RingID: "ring1", Stage: HealthSnapshotPreRelease, InstallationsTotal: 4, InstallationsHibernating: 1, CreateAt: 2},
+		{InstallationGroupID: "ig1", RingID: "ring1", Stage: HealthSnapshotPostSoak, InstallationsTotal: 4, InstallationsUpdated: 3, CreateAt: 3},
+		{InstallationGroupID: "ig2", RingID: "ring1", Stage: HealthSnapshotPostSoak, InstallationsTotal: 2, CreateAt: 3},
+		{InstallationGroupID: "ig3", RingID: "ring1", Stage: HealthSnapshotPreRelease, InstallationsTotal: 2, CreateAt: 3},
+	}
+
+	diffs := BuildHealthSnapshotDiffs(snapshots)
+	require.Len(t, diffs, 3)
+
+	require.Equal(t, "ig1", diffs[0].InstallationGroupID)
+	require.Equal(t, snapshots[2], diffs[0].Before)
+	require.Equal(t, snapshots[3], diffs[0].After)
+	require.Equal(t, map[string]int64{"InstallationsUpdated": 3, "InstallationsHibernating": -1}, diffs[0].Changes)
+
+	require.Equal(t, "ig2", diffs[1].InstallationGroupID)
+	require.NotNil(t, diffs[1].Before)
+	require.Nil(t, diffs[1].Changes)
+
+	require.Equal(t, "ig3", diffs[2].InstallationGroupID)
+	require.Nil(t, diffs[2].After)
+	require.Nil(t, diffs[2].Changes)
+
+	require.Empty(t, BuildHealthSnapshotDiffs(nil))
+}
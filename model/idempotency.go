@@ -0,0 +1,38 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	// HeaderIdempotencyKey is the request header making the creation of a
+	// resource idempotent: creating a resource again with the same key
+	// returns the resource created the first time.
+	HeaderIdempotencyKey = "Idempotency-Key"
+
+	// MaxIdempotencyKeyLength is the maximum length of an idempotency key.
+	MaxIdempotencyKeyLength = 255
+)
+
+// IdempotencyKey records the resource created by a request with an
+// idempotency key.
+type IdempotencyKey struct {
+	// ID is the idempotency key given by the client.
+	ID           string
+	ResourceType string
+	ResourceID   string
+	CreateAt     int64
+}
+
+// ValidateIdempotencyKey validates an idempotency key given by a client.
+func ValidateIdempotencyKey(key string) error {
+	if len(key) > MaxIdempotencyKeyLength {
+		return errors.Errorf("idempotency key cannot be longer than %d characters", MaxIdempotencyKeyLength)
+	}
+
+	return nil
+}
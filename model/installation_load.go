@@ -0,0 +1,78 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultReleaseLoadMaxWait is the time, in seconds, a release is deferred by
+// the load of the installations of an installation group at most, unless the
+// installation group sets its own.
+const DefaultReleaseLoadMaxWait = 3600
+
+// InstallationLoad is the load of an installation, as reported by the
+// installation load source.
+type InstallationLoad struct {
+	InstallationID string
+	ActiveUsers    int64 `json:"activeUsers"`
+	Connections    int64 `json:"connections"`
+}
+
+// InstallationGroupLoad is the load of the installations of an installation
+// group.
+type InstallationGroupLoad struct {
+	Installations []*InstallationLoad
+}
+
+// PeakActiveUsers returns the highest number of active users of any of the
+// installations.
+func (l *InstallationGroupLoad) PeakActiveUsers() int64 {
+	var peak int64
+	for _, installation := range l.Installations {
+		if installation.ActiveUsers > peak {
+			peak = installation.ActiveUsers
+		}
+	}
+
+	return peak
+}
+
+// Connections returns the total number of connections to the installations.
+func (l *InstallationGroupLoad) Connections() int64 {
+	var connections int64
+	for _, installation := range l.Installations {
+		connections += installation.Connections
+	}
+
+	return connections
+}
+
+// CurrentReleaseLoadMaxWait returns the time a release of the installation
+// group is deferred by the load of its installations at most, defaulting to
+// DefaultReleaseLoadMaxWait.
+func (i *InstallationGroup) CurrentReleaseLoadMaxWait() time.Duration {
+	if i.ReleaseLoadMaxWait == 0 {
+		return DefaultReleaseLoadMaxWait * time.Second
+	}
+
+	return time.Duration(i.ReleaseLoadMaxWait) * time.Second
+}
+
+// ValidateReleaseLoad validates the release load threshold and maximum wait,
+// in seconds, of an installation group. Zero disables the threshold and
+// selects the default maximum wait.
+func ValidateReleaseLoad(threshold int64, maxWait int) error {
+	if threshold < 0 {
+		return errors.New("release load threshold cannot be negative")
+	}
+	if maxWait < 0 {
+		return errors.New("release load max wait cannot be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallationGroupLoad(t *testing.T) {
+	load := &model.InstallationGroupLoad{}
+	assert.Zero(t, load.PeakActiveUsers())
+	assert.Zero(t, load.Connections())
+
+	load.Installations = []*model.InstallationLoad{
+		{InstallationID: "installation1", ActiveUsers: 120, Connections: 300},
+		{InstallationID: "installation2", ActiveUsers: 450, Connections: 900},
+		{InstallationID: "installation3", ActiveUsers: 10, Connections: 15},
+	}
+	assert.Equal(t, int64(450), load.PeakActiveUsers())
+	assert.Equal(t, int64(1215), load.Connections())
+}
+
+func TestCurrentReleaseLoadMaxWait(t *testing.T) {
+	installationGroup := &model.InstallationGroup{}
+	assert.Equal(t, time.Hour, installationGroup.CurrentReleaseLoadMaxWait())
+
+	installationGroup.ReleaseLoadMaxWait = 600
+	assert.Equal(t, 10*time.Minute, installationGroup.CurrentReleaseLoadMaxWait())
+}
+
+func TestValidateReleaseLoad(t *testing.T) {
+	assert.NoError(t, model.ValidateReleaseLoad(0, 0))
+	assert.NoError(t, model.ValidateReleaseLoad(500, 1800))
+	assert.Error(t, model.ValidateReleaseLoad(-1, 0))
+	assert.Error(t, model.ValidateReleaseLoad(500, -1))
+}
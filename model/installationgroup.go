@@ -14,21 +14,161 @@ import (
 
 // InstallationGroup represents a provisioner installation group.
 type InstallationGroup struct {
-	ID                 string `json:"id,omitempty"`
-	Name               string `json:"name,omitempty"`
-	State              string `json:"state,omitempty"`
-	ReleaseAt          int64  `json:"releaseAt,omitempty"`
-	SoakTime           int    `json:"soakTime,omitempty"`
-	ProvisionerGroupID string `json:"provisionerGroupID,omitempty"`
-	LockAcquiredBy     *string
-	LockAcquiredAt     int64
+	ID                 string      `json:"id,omitempty"`
+	Name               string      `json:"name,omitempty"`
+	State              string      `json:"state,omitempty"`
+	ReleaseAt          int64       `json:"releaseAt,omitempty"`
+	SoakTime           int         `json:"soakTime,omitempty"`
+	ProvisionerGroupID string      `json:"provisionerGroupID,omitempty"`
+	Annotations        Annotations `json:"annotations,omitempty"`
+	// Metadata is an opaque JSON object included verbatim in the webhook
+	// payloads and events of the installation group.
+	Metadata Metadata `json:"metadata,omitempty"`
+	// FailureDomain is the availability zone, region or other failure
+	// domain of the installation group. The installation groups of a ring
+	// in the same failure domain are released up to the max per failure
+	// domain of the ring at once.
+	FailureDomain string `json:"failureDomain,omitempty"`
+	// ForceRelease is set while the installation group is force released
+	// on its own, skipping its soak. See InstallationGroupReleaseRequest.
+	ForceRelease bool `json:"forceRelease,omitempty"`
+	// ReleaseSoakTime is the soak time, in seconds, resolved for the current
+	// release when it was requested.
+	ReleaseSoakTime int `json:"releaseSoakTime,omitempty"`
+	// Drifted is set when the provisioner group was last seen running a
+	// release other than the one elrond expects.
+	Drifted bool `json:"drifted,omitempty"`
+	// ObservedRelease is the image:version the provisioner group was last
+	// seen running.
+	ObservedRelease string `json:"observedRelease,omitempty"`
+	// ReleaseProgress is the percentage of the current release completed, as
+	// last reported by a provisioner callback.
+	ReleaseProgress int `json:"releaseProgress,omitempty"`
+	// StateMachineVersion is the version of the transition rules the
+	// installation group follows. See CurrentStateMachineVersion.
+	StateMachineVersion int `json:"stateMachineVersion,omitempty"`
+	// WorkPriority orders the installation groups pending work: the
+	// supervisors work on higher priorities first. It is only changed by
+	// rescheduling the work of the installation group.
+	WorkPriority int `json:"workPriority,omitempty"`
+	// ReleaseStrategy is ReleaseStrategyRolling or ReleaseStrategyBlueGreen.
+	// See CurrentReleaseStrategy.
+	ReleaseStrategy string `json:"releaseStrategy,omitempty"`
+	// GreenProvisionerGroupID is the provisioner group stood up by a
+	// blue/green release in progress, which replaces ProvisionerGroupID once
+	// the installations are switched over to it.
+	GreenProvisionerGroupID string `json:"greenProvisionerGroupID,omitempty"`
+	// ReleaseLoadThreshold, when set, defers the releases of the
+	// installation group while any of its installations has more active
+	// users. See ReleaseLoadMaxWait.
+	ReleaseLoadThreshold int64 `json:"releaseLoadThreshold,omitempty"`
+	// ReleaseLoadMaxWait is the time, in seconds, a release is deferred by
+	// the load of the installations at most. See CurrentReleaseLoadMaxWait.
+	ReleaseLoadMaxWait int `json:"releaseLoadMaxWait,omitempty"`
+	// LoadDeferredAt is the time, in nanoseconds, since which the pending
+	// release of the installation group is deferred by the load of its
+	// installations.
+	LoadDeferredAt int64 `json:"loadDeferredAt,omitempty"`
+	// SoakChecks are the metrics queries that must hold while the
+	// installation group soaks. See SoakCheck.
+	SoakChecks SoakChecks `json:"soakChecks,omitempty"`
+	// ReleaseWindows, when set, limit the start of the releases of the
+	// installation group to these windows, such as its maintenance hours.
+	ReleaseWindows TimeWindows `json:"releaseWindows,omitempty"`
+	// VerificationURL, when set, starts the verification job of each
+	// release of the installation group, which must pass before it soaks.
+	// See VerificationJob.
+	VerificationURL string `json:"verificationURL,omitempty"`
+	// VerificationTimeout is the time, in seconds, the verification job runs
+	// at most. See CurrentVerificationTimeout.
+	VerificationTimeout int `json:"verificationTimeout,omitempty"`
+	// VerificationJobID, VerificationStatus and VerificationOutputURL
+	// record the verification job of the current or last release, started
+	// at VerificationStartAt, in nanoseconds.
+	VerificationJobID     string `json:"verificationJobID,omitempty"`
+	VerificationStatus    string `json:"verificationStatus,omitempty"`
+	VerificationOutputURL string `json:"verificationOutputURL,omitempty"`
+	VerificationStartAt   int64  `json:"verificationStartAt,omitempty"`
+	// DatabaseSnapshot, when set, snapshots the databases of the
+	// installations of the installation group before each of its releases.
+	// The snapshots are recorded as DatabaseSnapshots of the release.
+	DatabaseSnapshot bool `json:"databaseSnapshot,omitempty"`
+	// ActiveReleaseID is the ring release the installation group last
+	// completed, and PreviousReleaseID the one it ran before, which it is
+	// rolled back to. See RollbackReleaseID.
+	ActiveReleaseID   string `json:"activeReleaseID,omitempty"`
+	PreviousReleaseID string `json:"previousReleaseID,omitempty"`
+	// InstallationCount and CustomerCount are the installations and
+	// customers behind the installation group, and CapacityTarget the
+	// installations it is planned to hold. The counts are synced from the
+	// provisioner at CapacitySyncedAt, in milliseconds, or set manually, in
+	// which case CapacitySyncedAt is zero.
+	InstallationCount int64 `json:"installationCount,omitempty"`
+	CustomerCount     int64 `json:"customerCount,omitempty"`
+	CapacityTarget    int64 `json:"capacityTarget,omitempty"`
+	CapacitySyncedAt  int64 `json:"capacitySyncedAt,omitempty"`
+	LockAcquiredBy    *string
+	LockAcquiredAt    int64
+
+	// ArchivedAt is the time, in milliseconds, the installation group was
+	// archived at, allowing its deletion although recent release history or
+	// a rollback target of its ring still references it.
+	ArchivedAt int64 `json:"archivedAt,omitempty"`
+
+	// Notes are the notes left by operators on the installation group,
+	// oldest first. They are fetched along with a single installation group
+	// and are not stored with it.
+	Notes []*Note `json:"notes,omitempty"`
+}
+
+// InstallationGroupWork is an installation group pending work together with
+// the ring it is registered to and the release the ring is rolling out, if
+// any.
+type InstallationGroupWork struct {
+	InstallationGroup *InstallationGroup
+	Ring              *Ring
+	Release           *RingRelease
 }
 
 // RegisterInstallationGroupRequest represent parameters passed to register an installation group to the Ring.
 type RegisterInstallationGroupRequest struct {
-	Name               string `json:"name,omitempty"`
-	SoakTime           int    `json:"soakTime,omitempty"`
-	ProvisionerGroupID string `json:"provisionerGroupID,omitempty"`
+	Name               string      `json:"name,omitempty"`
+	SoakTime           int         `json:"soakTime,omitempty"`
+	ProvisionerGroupID string      `json:"provisionerGroupID,omitempty"`
+	Annotations        Annotations `json:"annotations,omitempty"`
+	// Metadata is an opaque JSON object included verbatim in the webhook
+	// payloads and events of the installation group.
+	Metadata Metadata `json:"metadata,omitempty"`
+	// FailureDomain is the availability zone, region or other failure
+	// domain of the installation group.
+	FailureDomain string `json:"failureDomain,omitempty"`
+	// ReleaseStrategy, when set, is the strategy the installation group is
+	// released with: rolling (default) or blue-green.
+	ReleaseStrategy string `json:"releaseStrategy,omitempty"`
+	// ReleaseLoadThreshold, when set, defers the releases of the
+	// installation group while any of its installations has more active
+	// users, for ReleaseLoadMaxWait seconds at most.
+	ReleaseLoadThreshold int64 `json:"releaseLoadThreshold,omitempty"`
+	ReleaseLoadMaxWait   int   `json:"releaseLoadMaxWait,omitempty"`
+	// SoakChecks, when set, are the metrics queries that must hold while
+	// the installation group soaks.
+	SoakChecks SoakChecks `json:"soakChecks,omitempty"`
+	// ReleaseWindows, when set, limit the start of the releases of the
+	// installation group to these windows.
+	ReleaseWindows TimeWindows `json:"releaseWindows,omitempty"`
+	// VerificationURL, when set, starts the verification job of each
+	// release, which runs for VerificationTimeout seconds at most.
+	VerificationURL     string `json:"verificationURL,omitempty"`
+	VerificationTimeout int    `json:"verificationTimeout,omitempty"`
+	// DatabaseSnapshot, when set, snapshots the databases of the
+	// installations before each release.
+	DatabaseSnapshot bool `json:"databaseSnapshot,omitempty"`
+	// InstallationCount, CustomerCount and CapacityTarget, when set, record
+	// the capacity of the installation group until it is synced from the
+	// provisioner.
+	InstallationCount int64 `json:"installationCount,omitempty"`
+	CustomerCount     int64 `json:"customerCount,omitempty"`
+	CapacityTarget    int64 `json:"capacityTarget,omitempty"`
 }
 
 // UpdateInstallationGroupRequest specifies the parameters to update an installation group.
@@ -36,6 +176,48 @@ type UpdateInstallationGroupRequest struct {
 	Name               string `json:"name,omitempty"`
 	SoakTime           int    `json:"soakTime,omitempty"`
 	ProvisionerGroupID string `json:"provisionerGroupID,omitempty"`
+	// Annotations, when set, replace the annotations of the installation group.
+	Annotations Annotations `json:"annotations,omitempty"`
+	// Metadata, when set, replaces the metadata of the installation group.
+	Metadata Metadata `json:"metadata,omitempty"`
+	// FailureDomain, when set, replaces the failure domain of the
+	// installation group. An empty failure domain removes it.
+	FailureDomain *string `json:"failureDomain,omitempty"`
+	// ReleaseStrategy, when set, is the strategy the installation group is
+	// released with: rolling (default) or blue-green.
+	ReleaseStrategy string `json:"releaseStrategy,omitempty"`
+	// ReleaseLoadThreshold and ReleaseLoadMaxWait, when set, replace the
+	// release load threshold and maximum wait of the installation group.
+	// Zero disables the threshold and selects the default maximum wait.
+	ReleaseLoadThreshold *int64 `json:"releaseLoadThreshold,omitempty"`
+	ReleaseLoadMaxWait   *int   `json:"releaseLoadMaxWait,omitempty"`
+	// SoakChecks, when set, replace the soak checks of the installation
+	// group. An empty list removes them.
+	SoakChecks *SoakChecks `json:"soakChecks,omitempty"`
+	// ReleaseWindows, when set, replace the release windows of the
+	// installation group. An empty list removes them.
+	ReleaseWindows *TimeWindows `json:"releaseWindows,omitempty"`
+	// VerificationURL and VerificationTimeout, when set, replace the
+	// verification URL and timeout of the installation group. An empty URL
+	// disables the verification.
+	VerificationURL     *string `json:"verificationURL,omitempty"`
+	VerificationTimeout *int    `json:"verificationTimeout,omitempty"`
+	// DatabaseSnapshot, when set, replaces whether the databases of the
+	// installations are snapshotted before each release.
+	DatabaseSnapshot *bool `json:"databaseSnapshot,omitempty"`
+	// InstallationCount and CustomerCount, when set, replace the counts of
+	// the installation group until it is synced from the provisioner again.
+	// CapacityTarget, when set, replaces its capacity target.
+	InstallationCount *int64 `json:"installationCount,omitempty"`
+	CustomerCount     *int64 `json:"customerCount,omitempty"`
+	CapacityTarget    *int64 `json:"capacityTarget,omitempty"`
+}
+
+// InstallationGroupReleaseRequest specifies the parameters to release again,
+// on its own, an installation group whose release failed.
+type InstallationGroupReleaseRequest struct {
+	// Force, when set, skips the soak of the installation group.
+	Force bool `json:"force,omitempty"`
 }
 
 // SortInstallationGroups sorts installation groups by name alphabetically.
@@ -46,6 +228,114 @@ func SortInstallationGroups(installationGroups []*InstallationGroup) []*Installa
 	return installationGroups
 }
 
+// Validate validates the values of an installation group register request.
+func (request *RegisterInstallationGroupRequest) Validate() error {
+	if err := ValidateName(request.Name); err != nil {
+		return err
+	}
+	if err := ValidateSoakTime(request.SoakTime); err != nil {
+		return err
+	}
+
+	if err := ValidateReleaseStrategy(request.ReleaseStrategy); err != nil {
+		return err
+	}
+	if err := ValidateReleaseLoad(request.ReleaseLoadThreshold, request.ReleaseLoadMaxWait); err != nil {
+		return err
+	}
+	if err := request.SoakChecks.Validate(); err != nil {
+		return err
+	}
+	if err := request.ReleaseWindows.Validate(); err != nil {
+		return errors.Wrap(err, "invalid release windows")
+	}
+	if err := ValidateReleaseVerification(request.VerificationURL, request.VerificationTimeout); err != nil {
+		return err
+	}
+
+	if err := request.Metadata.Validate(); err != nil {
+		return err
+	}
+	if err := ValidateFailureDomain(request.FailureDomain); err != nil {
+		return err
+	}
+	if err := ValidateCapacity(request.InstallationCount, request.CustomerCount, request.CapacityTarget); err != nil {
+		return err
+	}
+
+	return request.Annotations.Validate()
+}
+
+// Validate validates the values of an installation group update request.
+func (request *UpdateInstallationGroupRequest) Validate() error {
+	if err := ValidateName(request.Name); err != nil {
+		return err
+	}
+	if err := ValidateSoakTime(request.SoakTime); err != nil {
+		return err
+	}
+
+	if err := ValidateReleaseStrategy(request.ReleaseStrategy); err != nil {
+		return err
+	}
+	var threshold int64
+	var maxWait int
+	if request.ReleaseLoadThreshold != nil {
+		threshold = *request.ReleaseLoadThreshold
+	}
+	if request.ReleaseLoadMaxWait != nil {
+		maxWait = *request.ReleaseLoadMaxWait
+	}
+	if err := ValidateReleaseLoad(threshold, maxWait); err != nil {
+		return err
+	}
+	if request.SoakChecks != nil {
+		if err := request.SoakChecks.Validate(); err != nil {
+			return err
+		}
+	}
+	if request.ReleaseWindows != nil {
+		if err := request.ReleaseWindows.Validate(); err != nil {
+			return errors.Wrap(err, "invalid release windows")
+		}
+	}
+	var verificationURL string
+	var verificationTimeout int
+	if request.VerificationURL != nil {
+		verificationURL = *request.VerificationURL
+	}
+	if request.VerificationTimeout != nil {
+		verificationTimeout = *request.VerificationTimeout
+	}
+	if err := ValidateReleaseVerification(verificationURL, verificationTimeout); err != nil {
+		return err
+	}
+
+	if err := request.Metadata.Validate(); err != nil {
+		return err
+	}
+	if request.FailureDomain != nil {
+		if err := ValidateFailureDomain(*request.FailureDomain); err != nil {
+			return err
+		}
+	}
+	var installations, customers, target int64
+	if request.InstallationCount != nil {
+		installations = *request.InstallationCount
+	}
+	if request.CustomerCount != nil {
+		customers = *request.CustomerCount
+	}
+	if request.CapacityTarget != nil {
+		target = *request.CapacityTarget
+	}
+	if err := ValidateCapacity(installations, customers, target); err != nil {
+		return err
+	}
+
+	return request.Annotations.Validate()
+}
+
 // NewRegisterInstallationGroupRequestFromReader will create a RegisterInstallationGroupRequest from an
 // io.Reader with JSON data.
 func NewRegisterInstallationGroupRequestFromReader(reader io.Reader) (*RegisterInstallationGroupRequest, error) {
@@ -54,6 +344,9 @@ func NewRegisterInstallationGroupRequestFromReader(reader io.Reader) (*RegisterI
 	if err != nil && err != io.EOF {
 		return nil, errors.Wrap(err, "failed to decode register installation group request")
 	}
+	if err = registerInstallationGroupRequest.Validate(); err != nil {
+		return nil, errors.Wrap(err, "register installation group request failed validation")
+	}
 
 	return &registerInstallationGroupRequest, nil
 }
@@ -65,9 +358,24 @@ func NewUpdateInstallationGroupRequestFromReader(reader io.Reader) (*UpdateInsta
 	if err != nil && err != io.EOF {
 		return nil, errors.Wrap(err, "failed to decode provision ring request")
 	}
+	if err = updateInstallationGroupRequest.Validate(); err != nil {
+		return nil, errors.Wrap(err, "update installation group request failed validation")
+	}
 	return &updateInstallationGroupRequest, nil
 }
 
+// NewInstallationGroupReleaseRequestFromReader will create an
+// InstallationGroupReleaseRequest from an io.Reader with JSON data.
+func NewInstallationGroupReleaseRequestFromReader(reader io.Reader) (*InstallationGroupReleaseRequest, error) {
+	var installationGroupReleaseRequest InstallationGroupReleaseRequest
+	err := json.NewDecoder(reader).Decode(&installationGroupReleaseRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode installation group release request")
+	}
+
+	return &installationGroupReleaseRequest, nil
+}
+
 // ContainsInstallationGroup determines whether slice of InstallationGroups contains a specific installation group.
 func ContainsInstallationGroup(installationGroups []*InstallationGroup, installationGroup *InstallationGroup) bool {
 	for _, ann := range installationGroups {
@@ -17,6 +17,27 @@ const (
 	InstallationGroupReleaseFailed = "release-failed"
 	// InstallationGroupReleaseSoakingFailed is an installation group with a soaking in failed state.
 	InstallationGroupReleaseSoakingFailed = "soaking-failed"
+	// InstallationGroupBlueGreenVerifyRequested is an installation group released blue/green
+	// with its green provisioner group stood up, pending verification.
+	InstallationGroupBlueGreenVerifyRequested = "bluegreen-verify-requested"
+	// InstallationGroupBlueGreenSwitchRequested is an installation group released blue/green
+	// with its installations pending a switch to the verified green provisioner group.
+	InstallationGroupBlueGreenSwitchRequested = "bluegreen-switch-requested"
+	// InstallationGroupBlueGreenTeardownRequested is an installation group released blue/green
+	// with its installations switched over, pending the teardown of the blue provisioner group.
+	InstallationGroupBlueGreenTeardownRequested = "bluegreen-teardown-requested"
+	// InstallationGroupReleaseVerificationRequested is an installation group released, pending
+	// the verification job of the release before it soaks.
+	InstallationGroupReleaseVerificationRequested = "release-verification-requested"
+	// InstallationGroupReleaseRollbackRequested is an installation group whose failed release,
+	// or whose ring, is rolling back to the release it ran before.
+	InstallationGroupReleaseRollbackRequested = "release-rollback-requested"
+	// InstallationGroupReleaseRollbackComplete is an installation group whose failed release
+	// was rolled back.
+	InstallationGroupReleaseRollbackComplete = "release-rollback-complete"
+	// InstallationGroupReleaseRollbackFailed is an installation group whose failed release
+	// could not be rolled back.
+	InstallationGroupReleaseRollbackFailed = "release-rollback-failed"
 )
 
 // AllInstallationGroupStates is a list of all states an installation group can be in.
@@ -29,6 +50,13 @@ var AllInstallationGroupStates = []string{
 	InstallationGroupReleaseSoakingRequested,
 	InstallationGroupReleaseFailed,
 	InstallationGroupReleaseSoakingFailed,
+	InstallationGroupBlueGreenVerifyRequested,
+	InstallationGroupBlueGreenSwitchRequested,
+	InstallationGroupBlueGreenTeardownRequested,
+	InstallationGroupReleaseVerificationRequested,
+	InstallationGroupReleaseRollbackRequested,
+	InstallationGroupReleaseRollbackComplete,
+	InstallationGroupReleaseRollbackFailed,
 }
 
 // AllInstallationGroupStatesPendingWork is a list of all installation group states that the supervisor
@@ -40,12 +68,31 @@ var AllInstallationGroupStatesPendingWork = []string{
 	InstallationGroupReleasePending,
 	InstallationGroupReleaseRequested,
 	InstallationGroupReleaseSoakingRequested,
+	InstallationGroupBlueGreenVerifyRequested,
+	InstallationGroupBlueGreenSwitchRequested,
+	InstallationGroupBlueGreenTeardownRequested,
+	InstallationGroupReleaseVerificationRequested,
+	InstallationGroupReleaseRollbackRequested,
 }
 
 // AllInstallationGroupStatesReleaseInProgress is a list of all installation group states that are part of a release in progress.
 var AllInstallationGroupStatesReleaseInProgress = []string{
 	InstallationGroupReleaseRequested,
 	InstallationGroupReleaseSoakingRequested,
+	InstallationGroupBlueGreenVerifyRequested,
+	InstallationGroupBlueGreenSwitchRequested,
+	InstallationGroupBlueGreenTeardownRequested,
+	InstallationGroupReleaseVerificationRequested,
+	InstallationGroupReleaseRollbackRequested,
+}
+
+// AllInstallationGroupStatesReleaseFailed is a list of all installation group states that end a
+// failed release.
+var AllInstallationGroupStatesReleaseFailed = []string{
+	InstallationGroupReleaseFailed,
+	InstallationGroupReleaseSoakingFailed,
+	InstallationGroupReleaseRollbackComplete,
+	InstallationGroupReleaseRollbackFailed,
 }
 
 // AllInstallationGroupRequestStates is a list of all states that an installation group can be put in
@@ -59,16 +106,39 @@ var AllInstallationGroupRequestStates = []string{
 	InstallationGroupReleaseSoakingRequested,
 }
 
+// HasFailedRelease returns whether the last release of the installation group
+// failed.
+func (i *InstallationGroup) HasFailedRelease() bool {
+	for _, state := range AllInstallationGroupStatesReleaseFailed {
+		if i.State == state {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ValidInstallationGroupTransitionState returns whether an installation group can be transitioned into the
-// new state or not based on its current state.
+// new state or not based on its current state, following the transition rules of the state machine version of
+// the installation group.
 func (i *InstallationGroup) ValidInstallationGroupTransitionState(newState string) bool {
+	validTransition, ok := installationGroupStateMachines[i.CurrentStateMachineVersion()]
+	if !ok {
+		return false
+	}
+
+	return validTransition(i.State, newState)
+}
+
+// validInstallationGroupTransitionV1 holds the installation group transition rules of the state machine version 1.
+func validInstallationGroupTransitionV1(currentState, newState string) bool {
 	switch newState {
 	case InstallationGroupReleasePending:
-		return validTransitionToInstallationGroupStateReleasePending(i.State)
+		return validTransitionToInstallationGroupStateReleasePending(currentState)
 	case InstallationGroupReleaseRequested:
-		return validTransitionToInstallationGroupStateReleaseInProgress(i.State)
+		return validTransitionToInstallationGroupStateReleaseInProgress(currentState)
 	case InstallationGroupReleaseSoakingRequested:
-		return validTransitionToInstallationGroupStateReleaseSoaking(i.State)
+		return validTransitionToInstallationGroupStateReleaseSoaking(currentState)
 	}
 
 	return false
@@ -80,7 +150,9 @@ func validTransitionToInstallationGroupStateReleasePending(currentState string)
 		InstallationGroupReleasePending,
 		InstallationGroupReleaseRequested,
 		InstallationGroupReleaseFailed,
-		InstallationGroupReleaseSoakingFailed:
+		InstallationGroupReleaseSoakingFailed,
+		InstallationGroupReleaseRollbackComplete,
+		InstallationGroupReleaseRollbackFailed:
 		return true
 	}
 
@@ -91,7 +163,9 @@ func validTransitionToInstallationGroupStateReleaseInProgress(currentState strin
 	switch currentState {
 	case InstallationGroupReleaseRequested,
 		InstallationGroupReleaseFailed,
-		InstallationGroupReleaseSoakingFailed:
+		InstallationGroupReleaseSoakingFailed,
+		InstallationGroupReleaseRollbackComplete,
+		InstallationGroupReleaseRollbackFailed:
 		return true
 	}
 
@@ -60,6 +60,24 @@ func TestNewRegisterInstallationGroupRequestFromReader(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, &RegisterInstallationGroupRequest{Name: "super-awesome"}, installationGroupsRequest)
 	})
+	t.Run("release strategy", func(t *testing.T) {
+		installationGroupsRequest, err := NewRegisterInstallationGroupRequestFromReader(bytes.NewReader([]byte(
+			`{"Name": "super-awesome", "releaseStrategy": "blue-green"}`,
+		)))
+		require.NoError(t, err)
+		require.Equal(t, ReleaseStrategyBlueGreen, installationGroupsRequest.ReleaseStrategy)
+
+		installationGroupsRequest, err = NewRegisterInstallationGroupRequestFromReader(bytes.NewReader([]byte(
+			`{"Name": "super-awesome", "releaseStrategy": "canary"}`,
+		)))
+		require.Error(t, err)
+		require.Nil(t, installationGroupsRequest)
+	})
+}
+
+func TestCurrentReleaseStrategy(t *testing.T) {
+	require.Equal(t, ReleaseStrategyRolling, (&InstallationGroup{}).CurrentReleaseStrategy())
+	require.Equal(t, ReleaseStrategyBlueGreen, (&InstallationGroup{ReleaseStrategy: ReleaseStrategyBlueGreen}).CurrentReleaseStrategy())
 }
 
 func TestContainsInstallationGroup(t *testing.T) {
@@ -0,0 +1,240 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// JobStatePending is a job waiting to run, or to be retried.
+	JobStatePending = "pending"
+	// JobStateRunning is a job being run by a server.
+	JobStateRunning = "running"
+	// JobStateSucceeded is a job that completed.
+	JobStateSucceeded = "succeeded"
+	// JobStateFailed is a job that failed every attempt.
+	JobStateFailed = "failed"
+)
+
+const (
+	// JobTypePrune deletes the state change events and the delivered webhook
+	// deliveries older than the JobParameterOlderThanDays parameter.
+	JobTypePrune = "prune"
+	// JobTypeResync compares the release every installation group of the
+	// stable rings runs according to the provisioner with the one elrond
+	// expects, like the drift reconciler.
+	JobTypeResync = "resync"
+
+	// JobParameterOlderThanDays is the age in days of the records deleted by
+	// a prune job.
+	JobParameterOlderThanDays = "older-than-days"
+
+	// DefaultJobMaxAttempts is the number of times a job is attempted unless
+	// requested otherwise.
+	DefaultJobMaxAttempts = 3
+)
+
+// AllJobTypes is a list of all the types of jobs the server runs.
+var AllJobTypes = []string{
+	JobTypePrune,
+	JobTypeResync,
+}
+
+// AllJobStatesPendingWork is a list of all job states that the supervisor
+// works on.
+var AllJobStatesPendingWork = []string{
+	JobStatePending,
+}
+
+// Job is a long administrative action run in the background by the
+// supervisors, instead of blocking the API request asking for it.
+type Job struct {
+	ID         string
+	Type       string
+	Parameters JobParameters
+	State      string
+	// Result summarizes what the job did, once it succeeded.
+	Result string `json:",omitempty"`
+	// Message is the error of the last failed attempt of the job, if any.
+	Message     string `json:",omitempty"`
+	Attempts    int
+	MaxAttempts int
+	// CreatedBy is the ID of the API token that created the job, if any.
+	CreatedBy      string `json:",omitempty"`
+	CreateAt       int64
+	StartAt        int64
+	EndAt          int64
+	LockAcquiredBy *string
+	LockAcquiredAt int64
+}
+
+// JobParameters are the parameters of a job, specific to its type.
+type JobParameters map[string]string
+
+// Value implements driver.Valuer, storing the parameters as JSON.
+func (p JobParameters) Value() (driver.Value, error) {
+	if len(p) == 0 {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(map[string]string(p))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal job parameters")
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner, loading the parameters from JSON.
+func (p *JobParameters) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.Errorf("cannot scan %T into job parameters", src)
+	}
+
+	var parameters JobParameters
+	if err := json.Unmarshal(data, &parameters); err != nil {
+		return errors.Wrap(err, "failed to unmarshal job parameters")
+	}
+	*p = parameters
+
+	return nil
+}
+
+// OlderThanDays returns the JobParameterOlderThanDays parameter of a prune
+// job.
+func (p JobParameters) OlderThanDays() (int, error) {
+	value, ok := p[JobParameterOlderThanDays]
+	if !ok {
+		return 0, errors.Errorf("the %s parameter is required", JobParameterOlderThanDays)
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 {
+		return 0, errors.Errorf("the %s parameter must be a positive number of days", JobParameterOlderThanDays)
+	}
+
+	return days, nil
+}
+
+// JobFilter describes the parameters used to constrain a set of jobs.
+type JobFilter struct {
+	Type    string
+	State   string
+	Page    int
+	PerPage int
+}
+
+// CreateJobRequest specifies the parameters for a new job.
+type CreateJobRequest struct {
+	Type       string
+	Parameters JobParameters
+	// MaxAttempts is the number of times the job is attempted before it
+	// fails, DefaultJobMaxAttempts by default.
+	MaxAttempts int
+}
+
+// SetDefaults sets the default values for a job create request.
+func (request *CreateJobRequest) SetDefaults() {
+	if request.MaxAttempts == 0 {
+		request.MaxAttempts = DefaultJobMaxAttempts
+	}
+}
+
+// Validate validates the values of a job create request.
+func (request *CreateJobRequest) Validate() error {
+	if request.MaxAttempts < 1 {
+		return errors.New("a job must be attempted at least once")
+	}
+
+	switch request.Type {
+	case JobTypePrune:
+		if _, err := request.Parameters.OlderThanDays(); err != nil {
+			return err
+		}
+	case JobTypeResync:
+	default:
+		return errors.Errorf("unknown job type %q, must be one of %s", request.Type, strings.Join(AllJobTypes, " "))
+	}
+
+	return nil
+}
+
+// NewCreateJobRequestFromReader will create a CreateJobRequest from an io.Reader with JSON data.
+func NewCreateJobRequestFromReader(reader io.Reader) (*CreateJobRequest, error) {
+	var createJobRequest CreateJobRequest
+	err := json.NewDecoder(reader).Decode(&createJobRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode create job request")
+	}
+
+	createJobRequest.SetDefaults()
+	err = createJobRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid create job request")
+	}
+
+	return &createJobRequest, nil
+}
+
+// GetJobsRequest describes the parameters to request a list of jobs.
+type GetJobsRequest struct {
+	Type    string
+	State   string
+	Page    int
+	PerPage int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetJobsRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	if request.Type != "" {
+		q.Add("type", request.Type)
+	}
+	if request.State != "" {
+		q.Add("state", request.State)
+	}
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	u.RawQuery = q.Encode()
+}
+
+// JobFromReader decodes a json-encoded job from the given io.Reader.
+func JobFromReader(reader io.Reader) (*Job, error) {
+	job := Job{}
+	err := json.NewDecoder(reader).Decode(&job)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// JobsFromReader decodes a json-encoded list of jobs from the given io.Reader.
+func JobsFromReader(reader io.Reader) ([]*Job, error) {
+	jobs := []*Job{}
+	err := json.NewDecoder(reader).Decode(&jobs)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return jobs, nil
+}
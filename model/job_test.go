@@ -0,0 +1,33 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateJobRequestValidate(t *testing.T) {
+	request := &CreateJobRequest{Type: JobTypeResync}
+	request.SetDefaults()
+	require.Equal(t, DefaultJobMaxAttempts, request.MaxAttempts)
+	require.NoError(t, request.Validate())
+
+	request.Type = "backup"
+	require.EqualError(t, request.Validate(), `unknown job type "backup", must be one of prune resync`)
+
+	request.Type = JobTypePrune
+	require.EqualError(t, request.Validate(), "the older-than-days parameter is required")
+
+	request.Parameters = JobParameters{JobParameterOlderThanDays: "0"}
+	require.EqualError(t, request.Validate(), "the older-than-days parameter must be a positive number of days")
+
+	request.Parameters = JobParameters{JobParameterOlderThanDays: "30"}
+	require.NoError(t, request.Validate())
+
+	request.MaxAttempts = -1
+	require.EqualError(t, request.Validate(), "a job must be attempted at least once")
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LabelSelector selects resources by their labels. Every requirement must
+// match.
+type LabelSelector []LabelRequirement
+
+// LabelRequirement requires a label to have, or not to have, a value.
+type LabelRequirement struct {
+	Key      string
+	Value    string
+	NotEqual bool
+}
+
+// ParseLabelSelector parses a selector of comma-separated key=value or
+// key!=value requirements, such as "env=prod,team!=payments". An empty
+// selector matches everything.
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	labelSelector := LabelSelector{}
+	if strings.TrimSpace(selector) == "" {
+		return labelSelector, nil
+	}
+
+	for _, term := range strings.Split(selector, ",") {
+		requirement := LabelRequirement{}
+		key, value, ok := strings.Cut(term, "!=")
+		if ok {
+			requirement.NotEqual = true
+		} else if key, value, ok = strings.Cut(term, "="); !ok {
+			return nil, errors.Errorf("invalid label requirement %q: must be key=value or key!=value", term)
+		}
+
+		requirement.Key = strings.TrimSpace(key)
+		requirement.Value = strings.TrimSpace(value)
+		if !annotationNameRegex.MatchString(requirement.Key) {
+			return nil, errors.Errorf("invalid label requirement %q: key must be alphanumerics and dashes, up to 63 characters", term)
+		}
+		labelSelector = append(labelSelector, requirement)
+	}
+
+	return labelSelector, nil
+}
+
+// Matches returns whether the given labels meet every requirement of the
+// selector. A missing label never equals a value.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		value, ok := labels[requirement.Key]
+		if (ok && value == requirement.Value) == requirement.NotEqual {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String returns the selector in the format parsed by ParseLabelSelector.
+func (s LabelSelector) String() string {
+	terms := make([]string, 0, len(s))
+	for _, requirement := range s {
+		operator := "="
+		if requirement.NotEqual {
+			operator = "!="
+		}
+		terms = append(terms, requirement.Key+operator+requirement.Value)
+	}
+
+	return strings.Join(terms, ",")
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		selector, err := ParseLabelSelector(" ")
+		require.NoError(t, err)
+		require.Empty(t, selector)
+		require.True(t, selector.Matches(nil))
+	})
+
+	t.Run("requirements", func(t *testing.T) {
+		selector, err := ParseLabelSelector("env=prod, team != payments,tier=")
+		require.NoError(t, err)
+		require.Equal(t, LabelSelector{
+			{Key: "env", Value: "prod"},
+			{Key: "team", Value: "payments", NotEqual: true},
+			{Key: "tier", Value: ""},
+		}, selector)
+		require.Equal(t, "env=prod,team!=payments,tier=", selector.String())
+	})
+
+	for _, invalid := range []string{"env", "env=prod,", "=prod", "env_name=prod"} {
+		t.Run("invalid "+invalid, func(t *testing.T) {
+			_, err := ParseLabelSelector(invalid)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLabelSelectorMatches(t *testing.T) {
+	selector, err := ParseLabelSelector("env=prod,team!=payments")
+	require.NoError(t, err)
+
+	require.True(t, selector.Matches(map[string]string{"env": "prod"}))
+	require.True(t, selector.Matches(map[string]string{"env": "prod", "team": "platform"}))
+	require.False(t, selector.Matches(map[string]string{"env": "prod", "team": "payments"}))
+	require.False(t, selector.Matches(map[string]string{"env": "staging"}))
+	require.False(t, selector.Matches(nil))
+}
+
+func TestWebhookMatches(t *testing.T) {
+	payload := &WebhookPayload{Labels: map[string]string{"env": "prod"}}
+
+	require.True(t, (&Webhook{}).Matches(payload))
+	require.True(t, (&Webhook{}).Matches(nil))
+	require.True(t, (&Webhook{LabelSelector: "env=prod"}).Matches(payload))
+	require.False(t, (&Webhook{LabelSelector: "env=staging"}).Matches(payload))
+	require.False(t, (&Webhook{LabelSelector: "env=prod"}).Matches(nil))
+	require.False(t, (&Webhook{LabelSelector: "invalid"}).Matches(payload))
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MaintenanceConflictQuery is posted to the maintenance conflict URL to look
+// up the infrastructure maintenance overlapping the release of a ring, from
+// From to To, in milliseconds.
+type MaintenanceConflictQuery struct {
+	RingID             string                          `json:"ringID"`
+	RingName           string                          `json:"ringName"`
+	ReleaseID          string                          `json:"releaseID"`
+	InstallationGroups []*MaintenanceInstallationGroup `json:"installationGroups"`
+	From               int64                           `json:"from"`
+	To                 int64                           `json:"to"`
+}
+
+// MaintenanceInstallationGroup is an installation group of the ring of a
+// MaintenanceConflictQuery.
+type MaintenanceInstallationGroup struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	ProvisionerGroupID string `json:"provisionerGroupID"`
+	FailureDomain      string `json:"failureDomain,omitempty"`
+}
+
+// NewMaintenanceConflictQuery returns the query of the maintenance
+// overlapping the release of the given ring and installation groups from the
+// given time to the given time, in milliseconds.
+func NewMaintenanceConflictQuery(ring *Ring, installationGroups []*InstallationGroup, from, to int64) *MaintenanceConflictQuery {
+	query := &MaintenanceConflictQuery{
+		RingID:             ring.ID,
+		RingName:           ring.Name,
+		ReleaseID:          ring.DesiredReleaseID,
+		InstallationGroups: []*MaintenanceInstallationGroup{},
+		From:               from,
+		To:                 to,
+	}
+	for _, installationGroup := range installationGroups {
+		query.InstallationGroups = append(query.InstallationGroups, &MaintenanceInstallationGroup{
+			ID:                 installationGroup.ID,
+			Name:               installationGroup.Name,
+			ProvisionerGroupID: installationGroup.ProvisionerGroupID,
+			FailureDomain:      installationGroup.FailureDomain,
+		})
+	}
+
+	return query
+}
+
+// MaintenanceConflictResponse is the response of the maintenance conflict
+// URL: the maintenance overlapping the queried release, if any.
+type MaintenanceConflictResponse struct {
+	Conflicts []*MaintenanceConflict `json:"conflicts"`
+}
+
+// MaintenanceConflictResponseFromReader decodes a json-encoded maintenance
+// conflict response from the given io.Reader.
+func MaintenanceConflictResponseFromReader(reader io.Reader) (*MaintenanceConflictResponse, error) {
+	response := &MaintenanceConflictResponse{}
+	if err := json.NewDecoder(reader).Decode(response); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// MaintenanceConflict is infrastructure maintenance overlapping the release
+// of a ring. StartAt and EndAt are in milliseconds. InstallationGroups are
+// the names of the installation groups of the ring it affects.
+type MaintenanceConflict struct {
+	Name               string   `json:"name"`
+	StartAt            int64    `json:"startAt"`
+	EndAt              int64    `json:"endAt"`
+	InstallationGroups []string `json:"installationGroups,omitempty"`
+}
+
+// String describes the maintenance and the installation groups it affects.
+func (c *MaintenanceConflict) String() string {
+	description := fmt.Sprintf("maintenance %s from %s to %s",
+		c.Name,
+		time.UnixMilli(c.StartAt).UTC().Format(time.RFC3339),
+		time.UnixMilli(c.EndAt).UTC().Format(time.RFC3339),
+	)
+	if len(c.InstallationGroups) > 0 {
+		description += " affecting " + strings.Join(c.InstallationGroups, ", ")
+	}
+
+	return description
+}
+
+// DescribeMaintenanceConflicts describes the given maintenance conflicts as
+// recorded on a ring held by them.
+func DescribeMaintenanceConflicts(conflicts []*MaintenanceConflict) string {
+	descriptions := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		descriptions = append(descriptions, conflict.String())
+	}
+
+	return strings.Join(descriptions, "; ")
+}
+
+// MaintenanceWindow is infrastructure maintenance planned in the internal
+// maintenance calendar of the server. It affects the given installation
+// groups, by name, or every installation group when none are given.
+type MaintenanceWindow struct {
+	Name               string
+	Start              time.Time
+	End                time.Time
+	InstallationGroups []string
+}
+
+// ParseMaintenanceWindow parses a maintenance window given as
+// <name>=<start>/<end>[@<installation group>,...], with RFC 3339 times, for
+// example db-upgrade=2024-05-04T22:00:00Z/2024-05-05T02:00:00Z@group1,group2.
+func ParseMaintenanceWindow(value string) (*MaintenanceWindow, error) {
+	name, schedule, ok := strings.Cut(value, "=")
+	if !ok {
+		return nil, errors.Errorf("invalid maintenance window %q, must be <name>=<start>/<end>[@<installation group>,...]", value)
+	}
+	schedule, installationGroups, _ := strings.Cut(schedule, "@")
+	start, end, ok := strings.Cut(schedule, "/")
+	if !ok {
+		return nil, errors.Errorf("invalid maintenance window %q, must be <name>=<start>/<end>[@<installation group>,...]", value)
+	}
+
+	window := &MaintenanceWindow{Name: strings.TrimSpace(name)}
+	if window.Name == "" {
+		return nil, errors.Errorf("invalid maintenance window %q, must have a name", value)
+	}
+	if err := ValidateName(window.Name); err != nil {
+		return nil, errors.Wrapf(err, "invalid name of maintenance window %q", value)
+	}
+	var err error
+	if window.Start, err = time.Parse(time.RFC3339, strings.TrimSpace(start)); err != nil {
+		return nil, errors.Wrapf(err, "invalid start of maintenance window %q", value)
+	}
+	if window.End, err = time.Parse(time.RFC3339, strings.TrimSpace(end)); err != nil {
+		return nil, errors.Wrapf(err, "invalid end of maintenance window %q", value)
+	}
+	if !window.End.After(window.Start) {
+		return nil, errors.Errorf("invalid maintenance window %q, must end after it starts", value)
+	}
+	if installationGroups != "" {
+		for _, installationGroup := range strings.Split(installationGroups, ",") {
+			window.InstallationGroups = append(window.InstallationGroups, strings.TrimSpace(installationGroup))
+		}
+	}
+
+	return window, nil
+}
+
+// Conflict returns the conflict of the maintenance window with the queried
+// release, or nil if the window does not overlap it or affects none of its
+// installation groups.
+func (w *MaintenanceWindow) Conflict(query *MaintenanceConflictQuery) *MaintenanceConflict {
+	if w.Start.UnixMilli() > query.To || w.End.UnixMilli() <= query.From {
+		return nil
+	}
+
+	conflict := &MaintenanceConflict{
+		Name:    w.Name,
+		StartAt: w.Start.UnixMilli(),
+		EndAt:   w.End.UnixMilli(),
+	}
+	for _, installationGroup := range query.InstallationGroups {
+		if w.affects(installationGroup.Name) {
+			conflict.InstallationGroups = append(conflict.InstallationGroups, installationGroup.Name)
+		}
+	}
+	if len(conflict.InstallationGroups) == 0 {
+		return nil
+	}
+
+	return conflict
+}
+
+// affects returns whether the maintenance window affects the installation
+// group of the given name.
+func (w *MaintenanceWindow) affects(installationGroupName string) bool {
+	if len(w.InstallationGroups) == 0 {
+		return true
+	}
+	for _, name := range w.InstallationGroups {
+		if name == installationGroupName {
+			return true
+		}
+	}
+
+	return false
+}
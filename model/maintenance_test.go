@@ -0,0 +1,49 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	window, err := ParseMaintenanceWindow("db-upgrade=2024-05-04T22:00:00Z/2024-05-05T02:00:00Z@group1, group2")
+	require.NoError(t, err)
+	require.Equal(t, &MaintenanceWindow{
+		Name:               "db-upgrade",
+		Start:              time.Date(2024, 5, 4, 22, 0, 0, 0, time.UTC),
+		End:                time.Date(2024, 5, 5, 2, 0, 0, 0, time.UTC),
+		InstallationGroups: []string{"group1", "group2"},
+	}, window)
+
+	window, err = ParseMaintenanceWindow("network=2024-05-04T22:00:00+02:00/2024-05-04T23:00:00+02:00")
+	require.NoError(t, err)
+	require.Empty(t, window.InstallationGroups)
+
+	for _, value := range []string{
+		"2024-05-04T22:00:00Z/2024-05-05T02:00:00Z",
+		"db-upgrade=2024-05-04T22:00:00Z",
+		"db-upgrade=2024-05-04/2024-05-05",
+		"db-upgrade=2024-05-05T02:00:00Z/2024-05-04T22:00:00Z",
+		"=2024-05-04T22:00:00Z/2024-05-05T02:00:00Z",
+	} {
+		_, err = ParseMaintenanceWindow(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestDescribeMaintenanceConflicts(t *testing.T) {
+	start := time.Date(2024, 5, 4, 22, 0, 0, 0, time.UTC)
+	require.Equal(t,
+		"maintenance db-upgrade from 2024-05-04T22:00:00Z to 2024-05-05T02:00:00Z affecting group1, group2; maintenance network from 2024-05-04T22:00:00Z to 2024-05-05T02:00:00Z",
+		DescribeMaintenanceConflicts([]*MaintenanceConflict{
+			{Name: "db-upgrade", StartAt: start.UnixMilli(), EndAt: start.Add(4 * time.Hour).UnixMilli(), InstallationGroups: []string{"group1", "group2"}},
+			{Name: "network", StartAt: start.UnixMilli(), EndAt: start.Add(4 * time.Hour).UnixMilli()},
+		}),
+	)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// MaxMetadataLength is the maximum length of the JSON metadata of a ring or
+// installation group.
+const MaxMetadataLength = 4096
+
+// Metadata is an opaque JSON object attached to a ring or installation group,
+// such as the routing key or service of its owner. Elrond does not interpret
+// it: it is included verbatim in the webhook payloads and state change events
+// of the resource, so that receivers can route and enrich them without
+// mapping IDs to context themselves.
+type Metadata []byte
+
+// Validate validates that the metadata, if any, is a JSON object within the
+// maximum length.
+func (m Metadata) Validate() error {
+	if len(m) == 0 {
+		return nil
+	}
+	if len(m) > MaxMetadataLength {
+		return errors.Errorf("metadata cannot be longer than %d bytes", MaxMetadataLength)
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(m, &object); err != nil || object == nil {
+		return errors.New("metadata must be a JSON object")
+	}
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the metadata verbatim.
+func (m Metadata) MarshalJSON() ([]byte, error) {
+	if len(m) == 0 {
+		return []byte("null"), nil
+	}
+
+	return m, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping the metadata verbatim,
+// without insignificant whitespace.
+func (m *Metadata) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, data); err != nil {
+		return errors.Wrap(err, "failed to compact metadata")
+	}
+	*m = compacted.Bytes()
+
+	return nil
+}
+
+// Value implements driver.Valuer, storing the metadata as JSON text.
+func (m Metadata) Value() (driver.Value, error) {
+	return string(m), nil
+}
+
+// Scan implements sql.Scanner, loading the metadata from JSON text.
+func (m *Metadata) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = nil
+	case []byte:
+		*m = nil
+		if len(v) > 0 {
+			*m = append(Metadata{}, v...)
+		}
+	case string:
+		*m = nil
+		if v != "" {
+			*m = Metadata(v)
+		}
+	default:
+		return errors.Errorf("cannot scan %T into metadata", src)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataValidate(t *testing.T) {
+	require.NoError(t, Metadata(nil).Validate())
+	require.NoError(t, Metadata(`{"team":"payments","routing":{"pager":"p1"}}`).Validate())
+	require.NoError(t, Metadata(`{}`).Validate())
+
+	require.Error(t, Metadata(`["team"]`).Validate())
+	require.Error(t, Metadata(`"team"`).Validate())
+	require.Error(t, Metadata(`null`).Validate())
+	require.Error(t, Metadata(`{"team":`).Validate())
+	require.Error(t, Metadata(`{"team":"`+strings.Repeat("a", MaxMetadataLength)+`"}`).Validate())
+}
+
+func TestMetadataJSON(t *testing.T) {
+	request, err := NewUpdateRingRequestFromReader(bytes.NewReader([]byte(`{"metadata": {"team": "payments", "tier": 1}}`)))
+	require.NoError(t, err)
+	require.Equal(t, Metadata(`{"team":"payments","tier":1}`), request.Metadata)
+
+	request, err = NewUpdateRingRequestFromReader(bytes.NewReader([]byte(`{"metadata": null}`)))
+	require.NoError(t, err)
+	require.Nil(t, request.Metadata)
+
+	_, err = NewUpdateRingRequestFromReader(bytes.NewReader([]byte(`{"metadata": ["team"]}`)))
+	require.Error(t, err)
+
+	payload := &WebhookPayload{ID: "ig1", Metadata: Metadata(`{"customer":"acme"}`), RingMetadata: Metadata(`{"team":"payments"}`)}
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"metadata":{"customer":"acme"},"ring_metadata":{"team":"payments"}`)
+
+	data, err = json.Marshal(&WebhookPayload{ID: "ring1"})
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "metadata")
+}
+
+func TestStateChangeEventMetadata(t *testing.T) {
+	ring := &Ring{ID: "ring1", Metadata: Metadata(`{"team":"payments"}`)}
+
+	event := NewStateChangeEvent(TypeRing, ring.ID, ring, RingStateStable, RingStateReleasePending)
+	require.Equal(t, ring.Metadata, event.Metadata)
+	require.Nil(t, event.RingMetadata)
+
+	event = NewStateChangeEvent(TypeInstallationGroup, "ig1", ring, InstallationGroupStable, InstallationGroupReleasePending)
+	require.Nil(t, event.Metadata)
+	require.Equal(t, ring.Metadata, event.RingMetadata)
+
+	event.Metadata = Metadata(`{"customer":"acme"}`)
+	payload := event.WebhookPayload(nil)
+	require.Equal(t, event.Metadata, payload.Metadata)
+	require.Equal(t, ring.Metadata, payload.RingMetadata)
+}
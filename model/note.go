@@ -0,0 +1,93 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TypeRelease is the string value that represents a ring release.
+const TypeRelease = "release"
+
+// MaxNoteLength is the maximum length of the message of a note.
+const MaxNoteLength = 4096
+
+// Note is a timestamped free-text note left by an operator on a ring, an
+// installation group or a release, such as why a ring is held back.
+type Note struct {
+	ID           string
+	ResourceType string
+	ResourceID   string
+	Message      string
+	// Author is the ID of the API token that added the note, if any.
+	Author   string `json:",omitempty"`
+	CreateAt int64
+}
+
+// NoteFilter describes the parameters used to constrain a set of notes.
+type NoteFilter struct {
+	ResourceType string
+	ResourceIDs  []string
+}
+
+// NoteRequest describes the parameters to add a note.
+type NoteRequest struct {
+	Message string
+}
+
+// Validate validates the values of a note request.
+func (request *NoteRequest) Validate() error {
+	if strings.TrimSpace(request.Message) == "" {
+		return errors.New("note message must not be empty")
+	}
+	if len(request.Message) > MaxNoteLength {
+		return errors.Errorf("note message must be at most %d characters", MaxNoteLength)
+	}
+
+	return nil
+}
+
+// NewNoteRequestFromReader will create a NoteRequest from an io.Reader with
+// JSON data.
+func NewNoteRequestFromReader(reader io.Reader) (*NoteRequest, error) {
+	var request NoteRequest
+	err := json.NewDecoder(reader).Decode(&request)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode note request")
+	}
+
+	if err = request.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid note request")
+	}
+
+	return &request, nil
+}
+
+// NoteFromReader decodes a json-encoded note from the given io.Reader.
+func NoteFromReader(reader io.Reader) (*Note, error) {
+	note := Note{}
+	err := json.NewDecoder(reader).Decode(&note)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &note, nil
+}
+
+// NotesFromReader decodes a json-encoded list of notes from the given
+// io.Reader.
+func NotesFromReader(reader io.Reader) ([]*Note, error) {
+	notes := []*Note{}
+	err := json.NewDecoder(reader).Decode(&notes)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return notes, nil
+}
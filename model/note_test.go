@@ -0,0 +1,32 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoteRequestValidate(t *testing.T) {
+	require.NoError(t, (&NoteRequest{Message: "holding for a customer blackout"}).Validate())
+	require.Error(t, (&NoteRequest{}).Validate())
+	require.Error(t, (&NoteRequest{Message: "  \n"}).Validate())
+	require.Error(t, (&NoteRequest{Message: strings.Repeat("a", MaxNoteLength+1)}).Validate())
+}
+
+func TestNewNoteRequestFromReader(t *testing.T) {
+	request, err := NewNoteRequestFromReader(bytes.NewBufferString(`{"Message": "holding"}`))
+	require.NoError(t, err)
+	require.Equal(t, "holding", request.Message)
+
+	_, err = NewNoteRequestFromReader(bytes.NewBufferString(`{}`))
+	require.Error(t, err)
+
+	_, err = NewNoteRequestFromReader(bytes.NewBufferString(`{`))
+	require.Error(t, err)
+}
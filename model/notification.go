@@ -0,0 +1,140 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// NotificationReleaseStarted is sent when a ring starts releasing.
+	NotificationReleaseStarted = "release-started"
+	// NotificationReleaseCompleted is sent when a ring release completes.
+	NotificationReleaseCompleted = "release-completed"
+	// NotificationReleaseFailed is sent when a ring release or its soak fails.
+	NotificationReleaseFailed = "release-failed"
+
+	// MaxNotificationEmails is the maximum number of email recipients of a ring.
+	MaxNotificationEmails = 50
+)
+
+// ReleaseNotification describes a ring release milestone stakeholders are
+// notified of.
+type ReleaseNotification struct {
+	Event     string
+	Ring      *Ring
+	Release   *RingRelease
+	OldState  string
+	NewState  string
+	Timestamp int64
+}
+
+// ReleaseNotificationEvent returns the notification event of the given ring
+// transition, or an empty string if the transition is not notified.
+func ReleaseNotificationEvent(oldState, newState string) string {
+	switch {
+	case (oldState == RingStateReleasePending || oldState == RingStateReleaseBlocked) && newState == RingStateReleaseRequested:
+		return NotificationReleaseStarted
+	case newState == RingStateStable && (oldState == RingStateReleaseInProgress || oldState == RingStateSoakingRequested):
+		return NotificationReleaseCompleted
+	case newState == RingStateReleaseFailed || newState == RingStateSoakingFailed:
+		return NotificationReleaseFailed
+	}
+
+	return ""
+}
+
+// TemplateData returns the data the notification templates of the
+// notification are rendered with.
+func (n *ReleaseNotification) TemplateData() *NotificationTemplateData {
+	data := &NotificationTemplateData{
+		Event:    n.Event,
+		Type:     TypeRing,
+		OldState: n.OldState,
+		NewState: n.NewState,
+		Time:     time.Unix(0, n.Timestamp).UTC().Format(time.RFC3339),
+	}
+	if n.Ring != nil {
+		data.ID = n.Ring.ID
+		data.RingID = n.Ring.ID
+		data.RingName = n.Ring.Name
+		data.Contacts = n.Ring.Contacts()
+		data.Rehearsal = n.Ring.Rehearsal
+		if n.Ring.ReleaseImpactInstallations > 0 {
+			data.Impact = fmt.Sprintf("%d installations, %d customers", n.Ring.ReleaseImpactInstallations, n.Ring.ReleaseImpactCustomers)
+		}
+	}
+	if n.Release != nil {
+		data.Release = fmt.Sprintf("%s:%s", n.Release.Image, n.Release.Version)
+	}
+
+	return data
+}
+
+// NotificationEmails are the email addresses notified of the releases of a ring.
+type NotificationEmails []string
+
+// Validate validates the email addresses.
+func (e NotificationEmails) Validate() error {
+	if len(e) > MaxNotificationEmails {
+		return errors.Errorf("cannot have more than %d notification emails", MaxNotificationEmails)
+	}
+
+	for _, email := range e {
+		address, err := mail.ParseAddress(email)
+		if err != nil || address.Address != email {
+			return errors.Errorf("invalid notification email %q", email)
+		}
+	}
+
+	return nil
+}
+
+// Value implements driver.Valuer, storing the email addresses as JSON.
+func (e NotificationEmails) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+
+	data, err := json.Marshal([]string(e))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal notification emails")
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner, loading the email addresses from JSON.
+func (e *NotificationEmails) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*e = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.Errorf("cannot scan %T into notification emails", src)
+	}
+
+	var emails NotificationEmails
+	if err := json.Unmarshal(data, &emails); err != nil {
+		return errors.Wrap(err, "failed to unmarshal notification emails")
+	}
+	if len(emails) == 0 {
+		emails = nil
+	}
+	*e = emails
+
+	return nil
+}
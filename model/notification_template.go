@@ -0,0 +1,328 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// NotificationChannelEmail is the channel of the release notifications
+	// emailed to the notification emails of a ring.
+	NotificationChannelEmail = "email"
+	// NotificationChannelTeams is the channel of the cards sent to the
+	// webhooks in the Microsoft Teams format.
+	NotificationChannelTeams = "teams"
+
+	// NotificationStateChange is the event of the Teams card sent for every
+	// state change of a ring or installation group.
+	NotificationStateChange = "state-change"
+
+	// DefaultNotificationLanguage is the language of the built-in
+	// notifications, sent when no template matches the language of a target.
+	DefaultNotificationLanguage = "en"
+
+	// MaxNotificationTemplateLength is the maximum length of the subject or
+	// body of a notification template.
+	MaxNotificationTemplateLength = 16384
+)
+
+// NotificationTemplate is the text of the notifications of an event sent
+// through a channel in a language, rendered with Go text/template from a
+// NotificationTemplateData.
+type NotificationTemplate struct {
+	ID       string
+	Channel  string
+	Event    string
+	Language string
+	// Subject is the subject of emails, or the title of Teams cards.
+	Subject string
+	Body    string
+	// CreateAt and UpdateAt are in milliseconds.
+	CreateAt int64
+	UpdateAt int64
+}
+
+// NotificationTemplateFilter describes the parameters used to constrain a set
+// of notification templates.
+type NotificationTemplateFilter struct {
+	Channel  string
+	Event    string
+	Language string
+	Page     int
+	PerPage  int
+}
+
+// NotificationTemplateData is what notification templates are rendered
+// with. Release notifications are about rings, so their resource is the
+// ring.
+type NotificationTemplateData struct {
+	Event string
+	// Type and ID are the type and ID of the resource that changed state.
+	Type     string
+	ID       string
+	RingID   string
+	RingName string
+	// Release is the image:version released, if known.
+	Release  string
+	OldState string
+	NewState string
+	// Time is the time of the notification, formatted as RFC3339.
+	Time string
+	// Impact summarizes the installations and customers impacted by the
+	// release, if known.
+	Impact string
+	// Contacts are the contacts of the team owning the ring, if any.
+	Contacts string
+	// Rehearsal is set for the notifications of rehearsal rings.
+	Rehearsal bool
+	ExtraData map[string]string
+}
+
+// ValidNotificationChannel returns whether the given notification channel is
+// supported.
+func ValidNotificationChannel(channel string) bool {
+	switch channel {
+	case NotificationChannelEmail, NotificationChannelTeams:
+		return true
+	}
+	return false
+}
+
+// ValidNotificationEvent returns whether notifications of the given event
+// are sent through the given channel.
+func ValidNotificationEvent(channel, event string) bool {
+	switch channel {
+	case NotificationChannelEmail:
+		switch event {
+		case NotificationReleaseStarted, NotificationReleaseCompleted, NotificationReleaseFailed:
+			return true
+		}
+	case NotificationChannelTeams:
+		return event == NotificationStateChange
+	}
+	return false
+}
+
+// Render renders the subject and body of the template with the given data.
+func (t *NotificationTemplate) Render(data *NotificationTemplateData) (string, string, error) {
+	subject, err := renderNotificationText("subject", t.Subject, data)
+	if err != nil {
+		return "", "", err
+	}
+	body, err := renderNotificationText("body", t.Body, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return subject, body, nil
+}
+
+func parseNotificationText(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s template", name)
+	}
+
+	return tmpl, nil
+}
+
+func renderNotificationText(name, text string, data *NotificationTemplateData) (string, error) {
+	tmpl, err := parseNotificationText(name, text)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered bytes.Buffer
+	if err = tmpl.Execute(&rendered, data); err != nil {
+		return "", errors.Wrapf(err, "failed to render %s template", name)
+	}
+
+	return rendered.String(), nil
+}
+
+// FindNotificationTemplate returns the template of the given language among
+// the given templates of an event and channel, falling back to the template
+// of its base language, such as pt for pt-BR. It returns nil when none
+// matches, in which case the built-in notification is sent.
+func FindNotificationTemplate(templates []*NotificationTemplate, language string) *NotificationTemplate {
+	if language == "" {
+		return nil
+	}
+
+	base := strings.SplitN(language, "-", 2)[0]
+	var baseTemplate *NotificationTemplate
+	for _, t := range templates {
+		if strings.EqualFold(t.Language, language) {
+			return t
+		}
+		if strings.EqualFold(t.Language, base) {
+			baseTemplate = t
+		}
+	}
+
+	return baseTemplate
+}
+
+// CreateNotificationTemplateRequest specifies the parameters of a new
+// notification template.
+type CreateNotificationTemplateRequest struct {
+	Channel  string
+	Event    string
+	Language string
+	Subject  string
+	Body     string
+}
+
+// Validate validates the values of a notification template create request.
+func (request *CreateNotificationTemplateRequest) Validate() error {
+	if !ValidNotificationChannel(request.Channel) {
+		return errors.Errorf("unsupported notification channel %q", request.Channel)
+	}
+	if !ValidNotificationEvent(request.Channel, request.Event) {
+		return errors.Errorf("unsupported %s notification event %q", request.Channel, request.Event)
+	}
+	if request.Language == "" {
+		return errors.New("must specify language")
+	}
+	if err := ValidateLanguage(request.Language); err != nil {
+		return err
+	}
+
+	return validateNotificationTemplateText(request.Subject, request.Body)
+}
+
+// NewCreateNotificationTemplateRequestFromReader will create a
+// CreateNotificationTemplateRequest from an io.Reader with JSON data.
+func NewCreateNotificationTemplateRequestFromReader(reader io.Reader) (*CreateNotificationTemplateRequest, error) {
+	var request CreateNotificationTemplateRequest
+	err := json.NewDecoder(reader).Decode(&request)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode create notification template request")
+	}
+
+	if err = request.Validate(); err != nil {
+		return nil, errors.Wrap(err, "create notification template request failed validation")
+	}
+
+	return &request, nil
+}
+
+// UpdateNotificationTemplateRequest specifies the changes to a notification
+// template. Fields left unset are not changed.
+type UpdateNotificationTemplateRequest struct {
+	Subject *string `json:",omitempty"`
+	Body    *string `json:",omitempty"`
+}
+
+// NewUpdateNotificationTemplateRequestFromReader will create an
+// UpdateNotificationTemplateRequest from an io.Reader with JSON data.
+func NewUpdateNotificationTemplateRequestFromReader(reader io.Reader) (*UpdateNotificationTemplateRequest, error) {
+	var request UpdateNotificationTemplateRequest
+	err := json.NewDecoder(reader).Decode(&request)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode update notification template request")
+	}
+
+	return &request, nil
+}
+
+// Apply applies the changes of the request to the given template, returning
+// an error if the resulting template is invalid.
+func (request *UpdateNotificationTemplateRequest) Apply(t *NotificationTemplate) error {
+	subject, body := t.Subject, t.Body
+	if request.Subject != nil {
+		subject = *request.Subject
+	}
+	if request.Body != nil {
+		body = *request.Body
+	}
+	if err := validateNotificationTemplateText(subject, body); err != nil {
+		return err
+	}
+
+	t.Subject, t.Body = subject, body
+
+	return nil
+}
+
+// validateNotificationTemplateText validates the subject and body of a
+// notification template.
+func validateNotificationTemplateText(subject, body string) error {
+	if strings.TrimSpace(body) == "" {
+		return errors.New("body cannot be empty")
+	}
+	if len(subject) > MaxNotificationTemplateLength || len(body) > MaxNotificationTemplateLength {
+		return errors.Errorf("subject and body cannot be longer than %d characters", MaxNotificationTemplateLength)
+	}
+	// Rendering the templates with empty data catches references to unknown
+	// fields, not only syntax errors.
+	notificationTemplate := &NotificationTemplate{Subject: subject, Body: body}
+	if _, _, err := notificationTemplate.Render(&NotificationTemplateData{}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetNotificationTemplatesRequest describes the parameters to request a list
+// of notification templates.
+type GetNotificationTemplatesRequest struct {
+	Channel  string
+	Event    string
+	Language string
+	Page     int
+	PerPage  int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetNotificationTemplatesRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	if request.Channel != "" {
+		q.Add("channel", request.Channel)
+	}
+	if request.Event != "" {
+		q.Add("event", request.Event)
+	}
+	if request.Language != "" {
+		q.Add("language", request.Language)
+	}
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	u.RawQuery = q.Encode()
+}
+
+// NotificationTemplateFromReader decodes a json-encoded notification template from the given io.Reader.
+func NotificationTemplateFromReader(reader io.Reader) (*NotificationTemplate, error) {
+	notificationTemplate := NotificationTemplate{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&notificationTemplate)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &notificationTemplate, nil
+}
+
+// NotificationTemplatesFromReader decodes a json-encoded list of notification templates from the given io.Reader.
+func NotificationTemplatesFromReader(reader io.Reader) ([]*NotificationTemplate, error) {
+	notificationTemplates := []*NotificationTemplate{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&notificationTemplates)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return notificationTemplates, nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationTemplateRender(t *testing.T) {
+	notificationTemplate := &NotificationTemplate{
+		Subject: "[Elrond] Release von {{.RingName}} gestartet",
+		Body:    "{{.Release}} wird auf {{.RingName}} ausgerollt.{{if .Contacts}} Kontakt: {{.Contacts}}{{end}}",
+	}
+
+	subject, body, err := notificationTemplate.Render(&NotificationTemplateData{RingName: "canary", Release: "mattermost/mattermost:9.0.0"})
+	require.NoError(t, err)
+	require.Equal(t, "[Elrond] Release von canary gestartet", subject)
+	require.Equal(t, "mattermost/mattermost:9.0.0 wird auf canary ausgerollt.", body)
+
+	notificationTemplate.Body = "{{index .ExtraData \"Team\"}}"
+	_, body, err = notificationTemplate.Render(&NotificationTemplateData{ExtraData: map[string]string{"Team": "cloud"}})
+	require.NoError(t, err)
+	require.Equal(t, "cloud", body)
+}
+
+func TestFindNotificationTemplate(t *testing.T) {
+	de := &NotificationTemplate{ID: "de", Language: "de"}
+	pt := &NotificationTemplate{ID: "pt", Language: "pt"}
+	ptBR := &NotificationTemplate{ID: "pt-BR", Language: "pt-BR"}
+	templates := []*NotificationTemplate{de, pt, ptBR}
+
+	require.Equal(t, de, FindNotificationTemplate(templates, "de"))
+	require.Equal(t, de, FindNotificationTemplate(templates, "DE"))
+	require.Equal(t, de, FindNotificationTemplate(templates, "de-AT"))
+	require.Equal(t, ptBR, FindNotificationTemplate(templates, "pt-br"))
+	require.Equal(t, pt, FindNotificationTemplate(templates, "pt-PT"))
+	require.Nil(t, FindNotificationTemplate(templates, "fr"))
+	require.Nil(t, FindNotificationTemplate(templates, ""))
+}
+
+func TestCreateNotificationTemplateRequestValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		requireError bool
+		request      *CreateNotificationTemplateRequest
+	}{
+		{"email", false, &CreateNotificationTemplateRequest{Channel: NotificationChannelEmail, Event: NotificationReleaseFailed, Language: "de", Subject: "{{.RingName}}", Body: "{{.NewState}}"}},
+		{"teams", false, &CreateNotificationTemplateRequest{Channel: NotificationChannelTeams, Event: NotificationStateChange, Language: "pt-BR", Body: "{{.Type}} {{.ID}}"}},
+		{"unknown channel", true, &CreateNotificationTemplateRequest{Channel: "slack", Event: NotificationStateChange, Language: "de", Body: "body"}},
+		{"event of another channel", true, &CreateNotificationTemplateRequest{Channel: NotificationChannelTeams, Event: NotificationReleaseFailed, Language: "de", Body: "body"}},
+		{"no language", true, &CreateNotificationTemplateRequest{Channel: NotificationChannelEmail, Event: NotificationReleaseFailed, Body: "body"}},
+		{"invalid language", true, &CreateNotificationTemplateRequest{Channel: NotificationChannelEmail, Event: NotificationReleaseFailed, Language: "german", Body: "body"}},
+		{"empty body", true, &CreateNotificationTemplateRequest{Channel: NotificationChannelEmail, Event: NotificationReleaseFailed, Language: "de", Body: " "}},
+		{"syntax error", true, &CreateNotificationTemplateRequest{Channel: NotificationChannelEmail, Event: NotificationReleaseFailed, Language: "de", Body: "{{.RingName"}},
+		{"unknown field", true, &CreateNotificationTemplateRequest{Channel: NotificationChannelEmail, Event: NotificationReleaseFailed, Language: "de", Body: "{{.Ring}}"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				require.Error(t, tc.request.Validate())
+			} else {
+				require.NoError(t, tc.request.Validate())
+			}
+		})
+	}
+}
+
+func TestUpdateNotificationTemplateRequestApply(t *testing.T) {
+	notificationTemplate := &NotificationTemplate{Subject: "subject", Body: "body"}
+
+	request, err := NewUpdateNotificationTemplateRequestFromReader(bytes.NewReader([]byte(`{"Body":"{{.RingName}}"}`)))
+	require.NoError(t, err)
+	require.NoError(t, request.Apply(notificationTemplate))
+	require.Equal(t, "subject", notificationTemplate.Subject)
+	require.Equal(t, "{{.RingName}}", notificationTemplate.Body)
+
+	invalid := "{{.Unknown}}"
+	require.Error(t, (&UpdateNotificationTemplateRequest{Subject: &invalid}).Apply(notificationTemplate))
+	require.Equal(t, "subject", notificationTemplate.Subject)
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationEmailsValidate(t *testing.T) {
+	tooMany := model.NotificationEmails{}
+	for i := 0; i <= model.MaxNotificationEmails; i++ {
+		tooMany = append(tooMany, "user@example.com")
+	}
+
+	var testCases = []struct {
+		testName     string
+		emails       model.NotificationEmails
+		requireError bool
+	}{
+		{"nil", nil, false},
+		{"valid", model.NotificationEmails{"release-managers@example.com", "cto@example.com"}, false},
+		{"invalid", model.NotificationEmails{"not an email"}, true},
+		{"display name", model.NotificationEmails{"Release Managers <release-managers@example.com>"}, true},
+		{"too many", tooMany, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.emails.Validate())
+			} else {
+				assert.NoError(t, tc.emails.Validate())
+			}
+		})
+	}
+}
+
+func TestNotificationEmailsScan(t *testing.T) {
+	var emails model.NotificationEmails
+	require.NoError(t, emails.Scan(`["user@example.com"]`))
+	require.Equal(t, model.NotificationEmails{"user@example.com"}, emails)
+
+	require.NoError(t, emails.Scan([]byte(`[]`)))
+	require.Nil(t, emails)
+
+	value, err := model.NotificationEmails(nil).Value()
+	require.NoError(t, err)
+	require.Equal(t, "[]", value)
+}
+
+func TestReleaseNotificationEvent(t *testing.T) {
+	var testCases = []struct {
+		oldState string
+		newState string
+		expected string
+	}{
+		{model.RingStateReleasePending, model.RingStateReleaseRequested, model.NotificationReleaseStarted},
+		{model.RingStateReleaseRequested, model.RingStateReleaseInProgress, ""},
+		{model.RingStateReleaseInProgress, model.RingStateStable, model.NotificationReleaseCompleted},
+		{model.RingStateSoakingRequested, model.RingStateStable, model.NotificationReleaseCompleted},
+		{model.RingStateCreationRequested, model.RingStateStable, ""},
+		{model.RingStateReleaseInProgress, model.RingStateReleaseFailed, model.NotificationReleaseFailed},
+		{model.RingStateSoakingRequested, model.RingStateSoakingFailed, model.NotificationReleaseFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.oldState+" to "+tc.newState, func(t *testing.T) {
+			require.Equal(t, tc.expected, model.ReleaseNotificationEvent(tc.oldState, tc.newState))
+		})
+	}
+}
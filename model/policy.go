@@ -0,0 +1,82 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+const (
+	// PolicyActionRequest is the evaluation of a request changing elrond
+	// through the API.
+	PolicyActionRequest = "request"
+	// PolicyActionTransition is the evaluation of the work of a supervisor
+	// on a protected ring, before it moves the ring out of its state.
+	PolicyActionTransition = "transition"
+)
+
+// PolicyInput is what the authorization policy is evaluated on, either an API
+// request or a supervisor transition, as given by Action.
+type PolicyInput struct {
+	Action string `json:"action"`
+	// Time is the time of the evaluation, in RFC3339 UTC, so that policies
+	// can restrict changes to business hours.
+	Time string `json:"time"`
+
+	// Method, Path and Vars are the method, path and route variables, such
+	// as ring, of an API request.
+	Method string            `json:"method,omitempty"`
+	Path   string            `json:"path,omitempty"`
+	Vars   map[string]string `json:"vars,omitempty"`
+	// Token is the identity of the API token of the request, if any.
+	Token *PolicyToken `json:"token,omitempty"`
+
+	// Ring is the ring of the request, if any, or the ring of the
+	// transition.
+	Ring *PolicyRing `json:"ring,omitempty"`
+	// State is the state the supervisor works on the ring in.
+	State string `json:"state,omitempty"`
+	// InstanceID is the elrond server making the transition.
+	InstanceID string `json:"instanceID,omitempty"`
+}
+
+// PolicyToken is the identity of an API token given to the authorization
+// policy.
+type PolicyToken struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	TenantID string `json:"tenantID,omitempty"`
+}
+
+// PolicyRing is a ring given to the authorization policy.
+type PolicyRing struct {
+	ID               string            `json:"id"`
+	Name             string            `json:"name"`
+	State            string            `json:"state"`
+	Protected        bool              `json:"protected"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
+	DesiredReleaseID string            `json:"desiredReleaseID,omitempty"`
+}
+
+// NewPolicyRing returns the given ring as given to the authorization policy.
+func NewPolicyRing(ring *Ring) *PolicyRing {
+	if ring == nil {
+		return nil
+	}
+
+	return &PolicyRing{
+		ID:               ring.ID,
+		Name:             ring.Name,
+		State:            ring.State,
+		Protected:        ring.Protected,
+		Annotations:      ring.Annotations,
+		DesiredReleaseID: ring.DesiredReleaseID,
+	}
+}
+
+// PolicyDecision is the outcome of the evaluation of the authorization
+// policy.
+type PolicyDecision struct {
+	Allow bool
+	// Reason explains a denial, when the policy gives one.
+	Reason string `json:",omitempty"`
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ProvisionerCallbackStarted reports that the provisioner started updating a group.
+	ProvisionerCallbackStarted = "started"
+	// ProvisionerCallbackProgress reports the progress of a group update.
+	ProvisionerCallbackProgress = "progress"
+	// ProvisionerCallbackCompleted reports that a group update completed.
+	ProvisionerCallbackCompleted = "completed"
+	// ProvisionerCallbackFailed reports that a group update failed.
+	ProvisionerCallbackFailed = "failed"
+)
+
+// ProvisionerCallback is a status update the provisioner posts about a
+// long-running update of one of its groups.
+type ProvisionerCallback struct {
+	ProvisionerGroupID string
+	Status             string
+	// Progress is the percentage of the group installations updated.
+	Progress int
+	Message  string
+}
+
+// Validate validates the values of a provisioner callback.
+func (c *ProvisionerCallback) Validate() error {
+	if c.ProvisionerGroupID == "" {
+		return errors.New("must specify the provisioner group ID")
+	}
+	switch c.Status {
+	case ProvisionerCallbackStarted, ProvisionerCallbackProgress, ProvisionerCallbackCompleted, ProvisionerCallbackFailed:
+	default:
+		return errors.Errorf("unsupported status %q", c.Status)
+	}
+	if c.Progress < 0 || c.Progress > 100 {
+		return errors.New("progress must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// NewProvisionerCallbackFromReader decodes a json-encoded provisioner callback
+// from the given io.Reader and validates it.
+func NewProvisionerCallbackFromReader(reader io.Reader) (*ProvisionerCallback, error) {
+	var callback ProvisionerCallback
+	err := json.NewDecoder(reader).Decode(&callback)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode provisioner callback")
+	}
+
+	if callback.Status == ProvisionerCallbackCompleted && callback.Progress == 0 {
+		callback.Progress = 100
+	}
+	if err = callback.Validate(); err != nil {
+		return nil, errors.Wrap(err, "provisioner callback failed validation")
+	}
+
+	return &callback, nil
+}
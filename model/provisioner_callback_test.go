@@ -0,0 +1,52 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProvisionerCallbackFromReader(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		body         string
+		expected     *model.ProvisionerCallback
+		requireError bool
+	}{
+		{"empty", "", nil, true},
+		{"invalid json", "{", nil, true},
+		{"missing group", `{"Status":"started"}`, nil, true},
+		{"unknown status", `{"ProvisionerGroupID":"group1","Status":"paused"}`, nil, true},
+		{"invalid progress", `{"ProvisionerGroupID":"group1","Status":"progress","Progress":101}`, nil, true},
+		{
+			"progress",
+			`{"ProvisionerGroupID":"group1","Status":"progress","Progress":40}`,
+			&model.ProvisionerCallback{ProvisionerGroupID: "group1", Status: model.ProvisionerCallbackProgress, Progress: 40},
+			false,
+		},
+		{
+			"completed",
+			`{"ProvisionerGroupID":"group1","Status":"completed"}`,
+			&model.ProvisionerCallback{ProvisionerGroupID: "group1", Status: model.ProvisionerCallbackCompleted, Progress: 100},
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			callback, err := model.NewProvisionerCallbackFromReader(bytes.NewBufferString(tc.body))
+			if tc.requireError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, callback)
+		})
+	}
+}
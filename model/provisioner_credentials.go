@@ -0,0 +1,58 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ProvisionerCredentials represents a version of the credentials used to
+// authenticate against the provisioner. The credentials are a set of HTTP
+// headers that are only ever stored encrypted and never returned by the API.
+type ProvisionerCredentials struct {
+	ID               string
+	EncryptedHeaders []byte `json:"-"`
+	CreateAt         int64
+}
+
+// RotateProvisionerCredentialsRequest specifies the new provisioner credentials.
+type RotateProvisionerCredentialsRequest struct {
+	Headers map[string]string
+}
+
+// NewRotateProvisionerCredentialsRequestFromReader will create a RotateProvisionerCredentialsRequest from an io.Reader with JSON data.
+func NewRotateProvisionerCredentialsRequestFromReader(reader io.Reader) (*RotateProvisionerCredentialsRequest, error) {
+	var request RotateProvisionerCredentialsRequest
+	err := json.NewDecoder(reader).Decode(&request)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode rotate provisioner credentials request")
+	}
+
+	if len(request.Headers) == 0 {
+		return nil, errors.New("must specify at least one header")
+	}
+	for name, value := range request.Headers {
+		if name == "" || value == "" {
+			return nil, errors.New("header names and values must not be empty")
+		}
+	}
+
+	return &request, nil
+}
+
+// ProvisionerCredentialsFromReader decodes a json-encoded provisioner credentials version from the given io.Reader.
+func ProvisionerCredentialsFromReader(reader io.Reader) (*ProvisionerCredentials, error) {
+	credentials := ProvisionerCredentials{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&credentials)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &credentials, nil
+}
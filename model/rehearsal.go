@@ -0,0 +1,95 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultRehearsalSpeedup is the number of times the soaks of rehearsal
+	// rings are accelerated by unless set otherwise, so that an hour of soak
+	// time passes in a minute.
+	DefaultRehearsalSpeedup = 60
+	// MaxRehearsalSpeedup is the highest speedup of rehearsal rings, which
+	// soaks a day in a second.
+	MaxRehearsalSpeedup = 86400
+)
+
+// ValidateRehearsal validates the rehearsal settings of a ring. Only
+// rehearsal rings can have a speedup.
+func ValidateRehearsal(rehearsal bool, speedup int) error {
+	if speedup < 0 {
+		return errors.New("rehearsal speedup cannot be negative")
+	}
+	if speedup > MaxRehearsalSpeedup {
+		return errors.Errorf("rehearsal speedup cannot be greater than %d", MaxRehearsalSpeedup)
+	}
+	if speedup > 0 && !rehearsal {
+		return errors.New("rehearsal speedup can only be set on rehearsal rings")
+	}
+
+	return nil
+}
+
+// CurrentRehearsalSpeedup returns the number of times the soaks of the ring
+// are accelerated by: RehearsalSpeedup, or DefaultRehearsalSpeedup when
+// unset, for rehearsal rings, and 1 for other rings.
+func (r *Ring) CurrentRehearsalSpeedup() int {
+	if !r.Rehearsal {
+		return 1
+	}
+	if r.RehearsalSpeedup <= 0 {
+		return DefaultRehearsalSpeedup
+	}
+
+	return r.RehearsalSpeedup
+}
+
+// SoakElapsed returns the soak time counted for the ring, or its
+// installation groups, once the given time has passed. The soaks of
+// rehearsal rings run on accelerated time.
+func (r *Ring) SoakElapsed(elapsed time.Duration) time.Duration {
+	return elapsed * time.Duration(r.CurrentRehearsalSpeedup())
+}
+
+// RehearsalRings returns the given rings that are rehearsal rings if
+// rehearsal is true, or that are not otherwise. Rehearsal rings and the other
+// rings release independently of each other: they neither hold back nor fail
+// the releases of the rings of the other kind.
+func RehearsalRings(rings []*Ring, rehearsal bool) []*Ring {
+	var filtered []*Ring
+	for _, ring := range rings {
+		if ring.Rehearsal == rehearsal {
+			filtered = append(filtered, ring)
+		}
+	}
+
+	return filtered
+}
+
+// RehearsalInstallationGroups returns the given installation groups, leaving
+// out those registered to rings that are not rehearsal rings if rehearsal is
+// true, or to rehearsal rings otherwise, given every ring and the
+// installation groups of each ring by ring ID. See RehearsalRings.
+func RehearsalInstallationGroups(installationGroups []*InstallationGroup, rehearsal bool, rings []*Ring, ringInstallationGroups map[string][]*InstallationGroup) []*InstallationGroup {
+	excluded := make(map[string]bool)
+	for _, ring := range RehearsalRings(rings, !rehearsal) {
+		for _, installationGroup := range ringInstallationGroups[ring.ID] {
+			excluded[installationGroup.ID] = true
+		}
+	}
+
+	var filtered []*InstallationGroup
+	for _, installationGroup := range installationGroups {
+		if !excluded[installationGroup.ID] {
+			filtered = append(filtered, installationGroup)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRehearsal(t *testing.T) {
+	require.NoError(t, ValidateRehearsal(false, 0))
+	require.NoError(t, ValidateRehearsal(true, 0))
+	require.NoError(t, ValidateRehearsal(true, MaxRehearsalSpeedup))
+	require.EqualError(t, ValidateRehearsal(true, -1), "rehearsal speedup cannot be negative")
+	require.EqualError(t, ValidateRehearsal(true, MaxRehearsalSpeedup+1), "rehearsal speedup cannot be greater than 86400")
+	require.EqualError(t, ValidateRehearsal(false, 10), "rehearsal speedup can only be set on rehearsal rings")
+}
+
+func TestRingSoakElapsed(t *testing.T) {
+	ring := &Ring{}
+	require.Equal(t, 1, ring.CurrentRehearsalSpeedup())
+	require.Equal(t, time.Minute, ring.SoakElapsed(time.Minute))
+
+	ring.Rehearsal = true
+	require.Equal(t, DefaultRehearsalSpeedup, ring.CurrentRehearsalSpeedup())
+	require.Equal(t, time.Hour, ring.SoakElapsed(time.Minute))
+
+	ring.RehearsalSpeedup = 10
+	require.Equal(t, 10, ring.CurrentRehearsalSpeedup())
+	require.Equal(t, 10*time.Minute, ring.SoakElapsed(time.Minute))
+}
+
+func TestRehearsalRings(t *testing.T) {
+	production := &Ring{ID: "production"}
+	rehearsal := &Ring{ID: "rehearsal", Rehearsal: true}
+	rings := []*Ring{production, rehearsal}
+	require.Equal(t, []*Ring{rehearsal}, RehearsalRings(rings, true))
+	require.Equal(t, []*Ring{production}, RehearsalRings(rings, false))
+
+	productionGroup := &InstallationGroup{ID: "production-group"}
+	rehearsalGroup := &InstallationGroup{ID: "rehearsal-group"}
+	orphanGroup := &InstallationGroup{ID: "orphan-group"}
+	installationGroups := []*InstallationGroup{productionGroup, rehearsalGroup, orphanGroup}
+	ringInstallationGroups := map[string][]*InstallationGroup{
+		production.ID: {productionGroup},
+		rehearsal.ID:  {rehearsalGroup},
+	}
+	require.Equal(t, []*InstallationGroup{rehearsalGroup, orphanGroup}, RehearsalInstallationGroups(installationGroups, true, rings, ringInstallationGroups))
+	require.Equal(t, []*InstallationGroup{productionGroup, orphanGroup}, RehearsalInstallationGroups(installationGroups, false, rings, ringInstallationGroups))
+}
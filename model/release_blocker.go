@@ -0,0 +1,342 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// ReleaseBlockerPaused is a ring whose releases are paused.
+	ReleaseBlockerPaused = "release-paused"
+	// ReleaseBlockerRingLocked is another ring under lock, being worked on.
+	ReleaseBlockerRingLocked = "ring-locked"
+	// ReleaseBlockerRingReleasing is another ring with a release in progress.
+	ReleaseBlockerRingReleasing = "ring-releasing"
+	// ReleaseBlockerRingPriority is another ring pending work with a lower
+	// priority number, which is released first.
+	ReleaseBlockerRingPriority = "ring-priority"
+	// ReleaseBlockerRingDependency is a ring the ring depends on, which has
+	// not completed its release.
+	ReleaseBlockerRingDependency = "ring-dependency"
+	// ReleaseBlockerInstallationGroupLocked is another installation group
+	// under lock, being worked on.
+	ReleaseBlockerInstallationGroupLocked = "installation-group-locked"
+	// ReleaseBlockerInstallationGroupReleasing is another installation group
+	// with a release in progress.
+	ReleaseBlockerInstallationGroupReleasing = "installation-group-releasing"
+	// ReleaseBlockerReleaseScheduled is a ring whose release is scheduled
+	// for later.
+	ReleaseBlockerReleaseScheduled = "release-scheduled"
+	// ReleaseBlockerReleaseWindow is a ring, or installation group, outside of
+	// its release windows.
+	ReleaseBlockerReleaseWindow = "release-window"
+	// ReleaseBlockerFailureDomain is another installation group of the ring
+	// in the same failure domain with a release in progress.
+	ReleaseBlockerFailureDomain = "failure-domain"
+	// ReleaseBlockerMaintenanceConflict is infrastructure maintenance
+	// overlapping the release of a ring.
+	ReleaseBlockerMaintenanceConflict = "maintenance-conflict"
+	// ReleaseBlockerRepeatedFailures is the issue tracking the repeated
+	// release failures of a ring, to be looked into before releasing again.
+	ReleaseBlockerRepeatedFailures = "repeated-failures"
+)
+
+// ReleaseBlockers lists what keeps the release of a ring, or of its
+// installation groups, from moving forward.
+type ReleaseBlockers struct {
+	RingID   string
+	State    string
+	Blockers []*ReleaseBlocker
+}
+
+// ReleaseBlocker is a single reason a release is not moving forward. RingID
+// and InstallationGroupID identify the resource causing it, if any, and URL
+// the issue tracking it.
+type ReleaseBlocker struct {
+	Reason              string
+	Message             string
+	RingID              string `json:",omitempty"`
+	InstallationGroupID string `json:",omitempty"`
+	URL                 string `json:",omitempty"`
+}
+
+// RingFailureIssueBlocker returns the blocker linking to the issue tracking
+// the repeated release failures of the ring, or nil if there is none. It is
+// informative: the supervisors do not wait for the issue to be resolved.
+func RingFailureIssueBlocker(ring *Ring) *ReleaseBlocker {
+	if ring.FailureIssueURL == "" {
+		return nil
+	}
+
+	return &ReleaseBlocker{
+		Reason:  ReleaseBlockerRepeatedFailures,
+		Message: fmt.Sprintf("repeated release failures of ring %s are tracked in %s", ring.Name, ring.FailureIssueURL),
+		RingID:  ring.ID,
+		URL:     ring.FailureIssueURL,
+	}
+}
+
+// RingReleaseBlockers returns what keeps the given pending ring from starting
+// its release, given every ring, the rings under lock, the rings with a
+// release in progress and the unlocked rings pending work, at the given time.
+// The ring itself is ignored. Rings ordered by their dependencies wait for
+// the rings they depend on and for the other rings under lock or releasing,
+// while the other rings wait for every ring under lock or releasing and for
+// the rings pending work with a higher work priority, or the same work
+// priority and a lower priority number. Rehearsal rings only
+// wait for, and hold back, other rehearsal rings. See RingOrderedByDependencies
+// and RehearsalRings.
+func RingReleaseBlockers(ring *Ring, rings, ringsLocked, ringsReleaseInProgress, ringsPendingWork []*Ring, now time.Time) []*ReleaseBlocker {
+	blockers := []*ReleaseBlocker{}
+
+	rings = RehearsalRings(rings, ring.Rehearsal)
+	ringsLocked = RehearsalRings(ringsLocked, ring.Rehearsal)
+	ringsReleaseInProgress = RehearsalRings(ringsReleaseInProgress, ring.Rehearsal)
+	ringsPendingWork = RehearsalRings(ringsPendingWork, ring.Rehearsal)
+
+	if ring.ReleaseScheduledAt > now.UnixNano() {
+		blockers = append(blockers, &ReleaseBlocker{
+			Reason:  ReleaseBlockerReleaseScheduled,
+			Message: fmt.Sprintf("release of ring %s is scheduled at %s", ring.Name, time.Unix(0, ring.ReleaseScheduledAt).UTC().Format(time.RFC3339)),
+			RingID:  ring.ID,
+		})
+	}
+
+	if !ring.ReleaseWindows.Contains(now) {
+		blockers = append(blockers, &ReleaseBlocker{
+			Reason:  ReleaseBlockerReleaseWindow,
+			Message: fmt.Sprintf("ring %s is outside of its release windows", ring.Name),
+			RingID:  ring.ID,
+		})
+	}
+
+	if ring.MaintenanceConflict != "" {
+		blockers = append(blockers, &ReleaseBlocker{
+			Reason:  ReleaseBlockerMaintenanceConflict,
+			Message: fmt.Sprintf("release of ring %s is held: %s", ring.Name, ring.MaintenanceConflict),
+			RingID:  ring.ID,
+		})
+	}
+
+	orderedByDependencies := RingOrderedByDependencies(ring, rings)
+	// blocks returns whether the given ring, under lock or releasing, keeps
+	// the ring from starting its release: rings of the dependency graph
+	// release alongside each other.
+	blocks := func(rg *Ring) bool {
+		if rg.ID == ring.ID {
+			return false
+		}
+		return !orderedByDependencies || !RingOrderedByDependencies(rg, rings)
+	}
+
+	for _, rg := range rings {
+		// Rings being deleted no longer hold back the rings depending on
+		// them.
+		if !ring.DependsOn.Contains(rg.ID) || rg.DeleteAt != 0 || rg.State == RingStateStable || rg.State == RingStateDeletionPending {
+			continue
+		}
+		blockers = append(blockers, &ReleaseBlocker{
+			Reason:  ReleaseBlockerRingDependency,
+			Message: fmt.Sprintf("ring %s depends on ring %s, which is %s", ring.Name, rg.Name, rg.State),
+			RingID:  rg.ID,
+		})
+	}
+
+	for _, rg := range ringsLocked {
+		if !blocks(rg) {
+			continue
+		}
+		blockers = append(blockers, &ReleaseBlocker{
+			Reason:  ReleaseBlockerRingLocked,
+			Message: fmt.Sprintf("ring %s is under lock%s", rg.Name, lockHolder(rg.LockAcquiredBy)),
+			RingID:  rg.ID,
+		})
+	}
+
+	for _, rg := range ringsReleaseInProgress {
+		if !blocks(rg) {
+			continue
+		}
+		blockers = append(blockers, &ReleaseBlocker{
+			Reason:  ReleaseBlockerRingReleasing,
+			Message: fmt.Sprintf("ring %s is %s", rg.Name, rg.State),
+			RingID:  rg.ID,
+		})
+	}
+
+	if orderedByDependencies {
+		return blockers
+	}
+
+	for _, rg := range ringsPendingWork {
+		// Rings waiting out their deletion grace period, preparing a release
+		// or waiting for their scheduled release are not releasing, and the
+		// rings of the dependency graph are not ordered by priority.
+		if rg.ID == ring.ID || rg.State == RingStateDeletionPending || rg.State == RingStateReleasePrepareRequested || rg.ReleaseScheduledAt > now.UnixNano() || RingOrderedByDependencies(rg, rings) {
+			continue
+		}
+		// Rings rescheduled ahead by their work priority are released first,
+		// whatever their priority.
+		switch {
+		case rg.WorkPriority > ring.WorkPriority:
+			blockers = append(blockers, &ReleaseBlocker{
+				Reason:  ReleaseBlockerRingPriority,
+				Message: fmt.Sprintf("ring %s with work priority %d is released first", rg.Name, rg.WorkPriority),
+				RingID:  rg.ID,
+			})
+		case rg.WorkPriority == ring.WorkPriority && rg.Priority < ring.Priority:
+			blockers = append(blockers, &ReleaseBlocker{
+				Reason:  ReleaseBlockerRingPriority,
+				Message: fmt.Sprintf("ring %s with priority %d is released first", rg.Name, rg.Priority),
+				RingID:  rg.ID,
+			})
+		}
+	}
+
+	return blockers
+}
+
+// RingBlocker returns the first of the given blockers of a pending ring that
+// is another ring, either one it depends on or one releasing, which blocks
+// the ring until that ring is done, or nil if there is none. Rings under lock
+// are only held for as long as they are worked on, and do not block.
+func RingBlocker(blockers []*ReleaseBlocker) *ReleaseBlocker {
+	for _, blocker := range blockers {
+		switch blocker.Reason {
+		case ReleaseBlockerRingDependency, ReleaseBlockerRingReleasing:
+			return blocker
+		}
+	}
+
+	return nil
+}
+
+// InstallationGroupReleaseBlockers returns what keeps the given pending
+// installation group of the given ring from starting its release, given every
+// ring, the installation groups of the ring, the installation groups under
+// lock and the ones with a release in progress, at the given time. The
+// installation group itself is ignored. The installation groups of the ring
+// are released up to its max concurrency at once, and up to its max per
+// failure domain at once within a failure domain, and wait for the
+// installation groups of other rings unless the ring is ordered by its
+// dependencies.
+func InstallationGroupReleaseBlockers(installationGroup *InstallationGroup, ring *Ring, rings []*Ring, ringInstallationGroups, installationGroupsLocked, installationGroupsReleaseInProgress []*InstallationGroup, now time.Time) []*ReleaseBlocker {
+	blockers := []*ReleaseBlocker{}
+
+	if !installationGroup.ReleaseWindows.Contains(now) {
+		blockers = append(blockers, &ReleaseBlocker{
+			Reason:              ReleaseBlockerReleaseWindow,
+			Message:             fmt.Sprintf("installation group %s is outside of its release windows", installationGroup.Name),
+			InstallationGroupID: installationGroup.ID,
+		})
+	}
+
+	inRing := make(map[string]bool)
+	for _, ig := range ringInstallationGroups {
+		inRing[ig.ID] = true
+	}
+	ringBlockers := []*ReleaseBlocker{}
+	otherBlockers := []*ReleaseBlocker{}
+	add := func(ig *InstallationGroup, blocker *ReleaseBlocker) {
+		if inRing[ig.ID] {
+			ringBlockers = append(ringBlockers, blocker)
+		} else {
+			otherBlockers = append(otherBlockers, blocker)
+		}
+	}
+
+	for _, ig := range installationGroupsLocked {
+		if ig.ID == installationGroup.ID {
+			continue
+		}
+		add(ig, &ReleaseBlocker{
+			Reason:              ReleaseBlockerInstallationGroupLocked,
+			Message:             fmt.Sprintf("installation group %s is under lock%s", ig.Name, lockHolder(ig.LockAcquiredBy)),
+			InstallationGroupID: ig.ID,
+		})
+	}
+
+	for _, ig := range installationGroupsReleaseInProgress {
+		if ig.ID == installationGroup.ID {
+			continue
+		}
+		add(ig, &ReleaseBlocker{
+			Reason:              ReleaseBlockerInstallationGroupReleasing,
+			Message:             fmt.Sprintf("installation group %s is %s", ig.Name, ig.State),
+			InstallationGroupID: ig.ID,
+		})
+	}
+
+	if len(ringBlockers) >= ring.CurrentMaxConcurrency() {
+		blockers = append(blockers, ringBlockers...)
+	}
+	blockers = append(blockers, failureDomainBlockers(installationGroup, ring, ringInstallationGroups, installationGroupsLocked, installationGroupsReleaseInProgress)...)
+	if !RingOrderedByDependencies(ring, rings) {
+		blockers = append(blockers, otherBlockers...)
+	}
+
+	return blockers
+}
+
+// failureDomainBlockers returns the installation groups of the ring in the
+// failure domain of the given installation group that are under lock or have
+// a release in progress, if there are as many as the max per failure domain
+// of the ring.
+func failureDomainBlockers(installationGroup *InstallationGroup, ring *Ring, ringInstallationGroups, installationGroupsLocked, installationGroupsReleaseInProgress []*InstallationGroup) []*ReleaseBlocker {
+	if installationGroup.FailureDomain == "" {
+		return nil
+	}
+
+	inFailureDomain := make(map[string]bool)
+	for _, ig := range ringInstallationGroups {
+		if ig.ID != installationGroup.ID && ig.FailureDomain == installationGroup.FailureDomain {
+			inFailureDomain[ig.ID] = true
+		}
+	}
+
+	blockers := []*ReleaseBlocker{}
+	seen := make(map[string]bool)
+	for _, ig := range append(append([]*InstallationGroup{}, installationGroupsReleaseInProgress...), installationGroupsLocked...) {
+		if !inFailureDomain[ig.ID] || seen[ig.ID] {
+			continue
+		}
+		seen[ig.ID] = true
+		blockers = append(blockers, &ReleaseBlocker{
+			Reason:              ReleaseBlockerFailureDomain,
+			Message:             fmt.Sprintf("installation group %s in failure domain %s is %s", ig.Name, installationGroup.FailureDomain, ig.State),
+			InstallationGroupID: ig.ID,
+		})
+	}
+	if len(blockers) < ring.CurrentMaxPerFailureDomain() {
+		return nil
+	}
+
+	return blockers
+}
+
+// lockHolder describes the holder of a lock, if known.
+func lockHolder(lockAcquiredBy *string) string {
+	if lockAcquiredBy == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(" by %s", *lockAcquiredBy)
+}
+
+// ReleaseBlockersFromReader decodes json-encoded release blockers from the given io.Reader.
+func ReleaseBlockersFromReader(reader io.Reader) (*ReleaseBlockers, error) {
+	blockers := ReleaseBlockers{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&blockers)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &blockers, nil
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingReleaseBlockers(t *testing.T) {
+	holder := "server1"
+	ring := &Ring{ID: "ring1", Name: "ring-1", Priority: 2, State: RingStateReleasePending}
+	now := time.Date(2022, time.June, 6, 12, 0, 0, 0, time.UTC)
+
+	t.Run("no blockers", func(t *testing.T) {
+		blockers := RingReleaseBlockers(ring, nil, []*Ring{ring}, nil, []*Ring{ring, {ID: "ring3", Priority: 3}}, now)
+		require.Empty(t, blockers)
+
+		ring.ReleaseWindows = TimeWindows{{Start: "09:00", End: "17:00"}}
+		blockers = RingReleaseBlockers(ring, nil, nil, nil, nil, now)
+		require.Empty(t, blockers)
+
+		ring.ReleaseScheduledAt = now.UnixNano()
+		blockers = RingReleaseBlockers(ring, nil, nil, nil, nil, now)
+		require.Empty(t, blockers)
+	})
+
+	t.Run("all blockers", func(t *testing.T) {
+		locked := &Ring{ID: "ring2", Name: "ring-2", LockAcquiredBy: &holder}
+		releasing := &Ring{ID: "ring3", Name: "ring-3", State: RingStateSoakingRequested}
+		first := &Ring{ID: "ring4", Name: "ring-4", Priority: 1, State: RingStateReleasePending}
+		deleting := &Ring{ID: "ring5", Name: "ring-5", Priority: 1, State: RingStateDeletionPending}
+		scheduled := &Ring{ID: "ring6", Name: "ring-6", Priority: 1, State: RingStateReleasePending, ReleaseScheduledAt: now.Add(time.Hour).UnixNano()}
+
+		ring.ReleaseWindows = TimeWindows{{Start: "09:00", End: "17:00", TimeZone: "America/Los_Angeles"}}
+		ring.ReleaseScheduledAt = now.Add(2 * time.Hour).UnixNano()
+		ring.MaintenanceConflict = "maintenance db-upgrade from 2022-06-06T11:00:00Z to 2022-06-06T13:00:00Z affecting ig-1"
+		defer func() { ring.MaintenanceConflict = "" }()
+
+		blockers := RingReleaseBlockers(ring, nil, []*Ring{ring, locked}, []*Ring{releasing}, []*Ring{ring, first, deleting, scheduled}, now)
+		require.Equal(t, []*ReleaseBlocker{
+			{Reason: ReleaseBlockerReleaseScheduled, Message: "release of ring ring-1 is scheduled at 2022-06-06T14:00:00Z", RingID: "ring1"},
+			{Reason: ReleaseBlockerReleaseWindow, Message: "ring ring-1 is outside of its release windows", RingID: "ring1"},
+			{Reason: ReleaseBlockerMaintenanceConflict, Message: "release of ring ring-1 is held: maintenance db-upgrade from 2022-06-06T11:00:00Z to 2022-06-06T13:00:00Z affecting ig-1", RingID: "ring1"},
+			{Reason: ReleaseBlockerRingLocked, Message: "ring ring-2 is under lock by server1", RingID: "ring2"},
+			{Reason: ReleaseBlockerRingReleasing, Message: "ring ring-3 is soaking-requested", RingID: "ring3"},
+			{Reason: ReleaseBlockerRingPriority, Message: "ring ring-4 with priority 1 is released first", RingID: "ring4"},
+		}, blockers)
+	})
+
+	t.Run("work priority", func(t *testing.T) {
+		ring.ReleaseWindows = nil
+		ring.ReleaseScheduledAt = 0
+
+		first := &Ring{ID: "ring4", Name: "ring-4", Priority: 1, State: RingStateReleasePending}
+		rescheduled := &Ring{ID: "ring5", Name: "ring-5", Priority: 3, State: RingStateReleasePending, WorkPriority: 1}
+
+		blockers := RingReleaseBlockers(ring, nil, nil, nil, []*Ring{ring, first, rescheduled}, now)
+		require.Equal(t, []*ReleaseBlocker{
+			{Reason: ReleaseBlockerRingPriority, Message: "ring ring-4 with priority 1 is released first", RingID: "ring4"},
+			{Reason: ReleaseBlockerRingPriority, Message: "ring ring-5 with work priority 1 is released first", RingID: "ring5"},
+		}, blockers)
+
+		// Rescheduled to the front, the ring is released ahead of the rings
+		// with a lower priority number.
+		ring.WorkPriority = 2
+		defer func() { ring.WorkPriority = 0 }()
+		blockers = RingReleaseBlockers(ring, nil, nil, nil, []*Ring{ring, first, rescheduled}, now)
+		require.Empty(t, blockers)
+	})
+
+	t.Run("dependency graph", func(t *testing.T) {
+		ring.ReleaseWindows = nil
+		ring.ReleaseScheduledAt = 0
+
+		canary := &Ring{ID: "canary-eu", Name: "canary-eu", Priority: 1, State: RingStateSoakingRequested}
+		otherCanary := &Ring{ID: "canary-us", Name: "canary-us", Priority: 1, State: RingStateReleaseInProgress}
+		otherProduction := &Ring{ID: "production-us", Name: "production-us", Priority: 1, State: RingStateReleasePending, DependsOn: RingDependencies{"canary-us"}}
+		sequential := &Ring{ID: "sequential", Name: "sequential", Priority: 1, State: RingStateReleasePending}
+		production := &Ring{ID: "production-eu", Name: "production-eu", Priority: 2, State: RingStateReleasePending, DependsOn: RingDependencies{"canary-eu"}}
+		rings := []*Ring{canary, otherCanary, otherProduction, sequential, production}
+
+		blockers := RingReleaseBlockers(production, rings, nil, []*Ring{canary, otherCanary}, []*Ring{otherProduction, sequential, production}, now)
+		require.Equal(t, []*ReleaseBlocker{
+			{Reason: ReleaseBlockerRingDependency, Message: "ring production-eu depends on ring canary-eu, which is soaking-requested", RingID: "canary-eu"},
+		}, blockers)
+
+		canary.State = RingStateStable
+		blockers = RingReleaseBlockers(production, rings, nil, []*Ring{otherCanary}, []*Ring{otherProduction, sequential, production}, now)
+		require.Empty(t, blockers)
+
+		// Rings outside of the graph still release one at a time.
+		blockers = RingReleaseBlockers(sequential, rings, nil, []*Ring{otherCanary}, []*Ring{otherProduction, sequential, production}, now)
+		require.Equal(t, []*ReleaseBlocker{
+			{Reason: ReleaseBlockerRingReleasing, Message: "ring canary-us is release-in-progress", RingID: "canary-us"},
+		}, blockers)
+		blockers = RingReleaseBlockers(production, rings, []*Ring{sequential}, []*Ring{otherCanary}, []*Ring{otherProduction, production}, now)
+		require.Equal(t, []*ReleaseBlocker{
+			{Reason: ReleaseBlockerRingLocked, Message: "ring sequential is under lock", RingID: "sequential"},
+		}, blockers)
+
+		// Deleted dependencies no longer block.
+		canary.State = RingStateReleaseFailed
+		canary.DeleteAt = now.UnixNano()
+		blockers = RingReleaseBlockers(production, rings, nil, nil, []*Ring{production}, now)
+		require.Empty(t, blockers)
+	})
+}
+
+func TestRingBlocker(t *testing.T) {
+	require.Nil(t, RingBlocker(nil))
+	require.Nil(t, RingBlocker([]*ReleaseBlocker{
+		{Reason: ReleaseBlockerReleaseWindow, RingID: "production"},
+		{Reason: ReleaseBlockerRingLocked, RingID: "staging"},
+		{Reason: ReleaseBlockerRingPriority, RingID: "canary"},
+	}))
+
+	blocker := RingBlocker([]*ReleaseBlocker{
+		{Reason: ReleaseBlockerRingLocked, RingID: "staging"},
+		{Reason: ReleaseBlockerRingReleasing, RingID: "canary"},
+		{Reason: ReleaseBlockerRingDependency, RingID: "staging"},
+	})
+	require.Equal(t, ReleaseBlockerRingReleasing, blocker.Reason)
+	require.Equal(t, "canary", blocker.RingID)
+}
+
+func TestInstallationGroupReleaseBlockers(t *testing.T) {
+	ring := &Ring{ID: "ring1", Name: "ring-1"}
+	installationGroup := &InstallationGroup{ID: "ig1", Name: "ig-1", State: InstallationGroupReleasePending}
+	now := time.Date(2022, time.June, 6, 3, 0, 0, 0, time.UTC)
+
+	t.Run("no blockers", func(t *testing.T) {
+		blockers := InstallationGroupReleaseBlockers(installationGroup, ring, nil, nil, []*InstallationGroup{installationGroup}, nil, now)
+		require.Empty(t, blockers)
+
+		installationGroup.ReleaseWindows = TimeWindows{{Start: "02:00", End: "05:00"}}
+		blockers = InstallationGroupReleaseBlockers(installationGroup, ring, nil, nil, nil, nil, now)
+		require.Empty(t, blockers)
+	})
+
+	t.Run("all blockers", func(t *testing.T) {
+		locked := &InstallationGroup{ID: "ig2", Name: "ig-2"}
+		releasing := &InstallationGroup{ID: "ig3", Name: "ig-3", State: InstallationGroupReleaseRequested}
+
+		installationGroup.ReleaseWindows = TimeWindows{{Start: "02:00", End: "05:00", TimeZone: "America/Los_Angeles"}}
+
+		blockers := InstallationGroupReleaseBlockers(installationGroup, ring, nil, nil, []*InstallationGroup{installationGroup, locked}, []*InstallationGroup{releasing}, now)
+		require.Equal(t, []*ReleaseBlocker{
+			{Reason: ReleaseBlockerReleaseWindow, Message: "installation group ig-1 is outside of its release windows", InstallationGroupID: "ig1"},
+			{Reason: ReleaseBlockerInstallationGroupLocked, Message: "installation group ig-2 is under lock", InstallationGroupID: "ig2"},
+			{Reason: ReleaseBlockerInstallationGroupReleasing, Message: "installation group ig-3 is release-requested", InstallationGroupID: "ig3"},
+		}, blockers)
+	})
+
+	t.Run("max concurrency", func(t *testing.T) {
+		installationGroup.ReleaseWindows = nil
+		releasing := &InstallationGroup{ID: "ig2", Name: "ig-2", State: InstallationGroupReleaseRequested}
+		other := &InstallationGroup{ID: "ig3", Name: "ig-3", State: InstallationGroupReleaseRequested}
+		ringInstallationGroups := []*InstallationGroup{installationGroup, releasing}
+
+		blockers := InstallationGroupReleaseBlockers(installationGroup, ring, nil, ringInstallationGroups, nil, []*InstallationGroup{releasing}, now)
+		require.Equal(t, []*ReleaseBlocker{
+			{Reason: ReleaseBlockerInstallationGroupReleasing, Message: "installation group ig-2 is release-requested", InstallationGroupID: "ig2"},
+		}, blockers)
+
+		ring.MaxConcurrency = 2
+		blockers = InstallationGroupReleaseBlockers(installationGroup, ring, nil, ringInstallationGroups, nil, []*InstallationGroup{releasing}, now)
+		require.Empty(t, blockers)
+
+		// Installation groups of other rings block unless the ring is
+		// ordered by its dependencies.
+		blockers = InstallationGroupReleaseBlockers(installationGroup, ring, nil, ringInstallationGroups, nil, []*InstallationGroup{releasing, other}, now)
+		require.Equal(t, []*ReleaseBlocker{
+			{Reason: ReleaseBlockerInstallationGroupReleasing, Message: "installation group ig-3 is release-requested", InstallationGroupID: "ig3"},
+		}, blockers)
+
+		ring.DependsOn = RingDependencies{"ring0"}
+		blockers = InstallationGroupReleaseBlockers(installationGroup, ring, nil, ringInstallationGroups, nil, []*InstallationGroup{releasing, other}, now)
+		require.Empty(t, blockers)
+	})
+
+	t.Run("failure domain", func(t *testing.T) {
+		ring.MaxConcurrency = 3
+		installationGroup.FailureDomain = "us-east-1a"
+		sameDomain := &InstallationGroup{ID: "ig2", Name: "ig-2", State: InstallationGroupReleaseRequested, FailureDomain: "us-east-1a"}
+		otherDomain := &InstallationGroup{ID: "ig3", Name: "ig-3", State: InstallationGroupReleaseRequested, FailureDomain: "us-east-1b"}
+		ringInstallationGroups := []*InstallationGroup{installationGroup, sameDomain, otherDomain}
+
+		blockers := InstallationGroupReleaseBlockers(installationGroup, ring, nil, ringInstallationGroups, nil, []*InstallationGroup{otherDomain}, now)
+		require.Empty(t, blockers)
+
+		blockers = InstallationGroupReleaseBlockers(installationGroup, ring, nil, ringInstallationGroups, nil, []*InstallationGroup{sameDomain, otherDomain}, now)
+		require.Equal(t, []*ReleaseBlocker{
+			{Reason: ReleaseBlockerFailureDomain, Message: "installation group ig-2 in failure domain us-east-1a is release-requested", InstallationGroupID: "ig2"},
+		}, blockers)
+
+		ring.MaxPerFailureDomain = 2
+		blockers = InstallationGroupReleaseBlockers(installationGroup, ring, nil, ringInstallationGroups, nil, []*InstallationGroup{sameDomain, otherDomain}, now)
+		require.Empty(t, blockers)
+
+		// Installation groups without a failure domain are only limited by
+		// the max concurrency of the ring.
+		ring.MaxPerFailureDomain = 0
+		installationGroup.FailureDomain = ""
+		blockers = InstallationGroupReleaseBlockers(installationGroup, ring, nil, ringInstallationGroups, nil, []*InstallationGroup{sameDomain, otherDomain}, now)
+		require.Empty(t, blockers)
+	})
+}
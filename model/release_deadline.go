@@ -0,0 +1,59 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ValidateMaxReleaseDuration validates the max release duration of a ring.
+func ValidateMaxReleaseDuration(maxReleaseDuration int) error {
+	if maxReleaseDuration < 0 {
+		return errors.New("max release duration cannot be negative")
+	}
+
+	return nil
+}
+
+// ReleaseDeadline returns the time by which the release of the ring started
+// at ReleaseStartAt must be done, and false when the ring has no max release
+// duration or no release started.
+func (r *Ring) ReleaseDeadline() (time.Time, bool) {
+	if r.MaxReleaseDuration <= 0 || r.ReleaseStartAt == 0 {
+		return time.Time{}, false
+	}
+
+	return time.Unix(0, r.ReleaseStartAt).Add(time.Duration(r.MaxReleaseDuration) * time.Second), true
+}
+
+// ReleaseDeadlineExceeded returns true when the release of the ring has been
+// running for longer than its max release duration at the given time. The
+// time the release spent paused or failed counts towards it.
+func (r *Ring) ReleaseDeadlineExceeded(now time.Time) bool {
+	deadline, ok := r.ReleaseDeadline()
+	return ok && now.After(deadline)
+}
+
+// ReleaseDeadlineState returns the state a ring whose release exceeded its
+// max release duration moves to following its failure policy: the ring is
+// rolled back with FailurePolicyRollbackRing, and fails otherwise. No
+// installation group is left to carry on with, so FailurePolicyContinue and
+// FailurePolicyRollbackInstallationGroup fail the ring too.
+func (r *Ring) ReleaseDeadlineState() string {
+	if r.CurrentFailurePolicy() == FailurePolicyRollbackRing {
+		return RingStateReleaseRollbackRequested
+	}
+
+	return RingStateReleaseFailed
+}
+
+// ReleaseDeadlineError describes the max release duration the release of the
+// ring exceeded, as recorded in its state change event.
+func (r *Ring) ReleaseDeadlineError() string {
+	return fmt.Sprintf("release exceeded the max release duration of %s", time.Duration(r.MaxReleaseDuration)*time.Second)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMaxReleaseDuration(t *testing.T) {
+	require.NoError(t, ValidateMaxReleaseDuration(0))
+	require.NoError(t, ValidateMaxReleaseDuration(3600))
+	require.Error(t, ValidateMaxReleaseDuration(-1))
+}
+
+func TestReleaseDeadlineExceeded(t *testing.T) {
+	start := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("no max release duration", func(t *testing.T) {
+		ring := &Ring{ReleaseStartAt: start.UnixNano()}
+		require.False(t, ring.ReleaseDeadlineExceeded(start.Add(24*time.Hour)))
+	})
+
+	t.Run("no release started", func(t *testing.T) {
+		ring := &Ring{MaxReleaseDuration: 3600}
+		require.False(t, ring.ReleaseDeadlineExceeded(start))
+	})
+
+	t.Run("within the max release duration", func(t *testing.T) {
+		ring := &Ring{MaxReleaseDuration: 3600, ReleaseStartAt: start.UnixNano()}
+		require.False(t, ring.ReleaseDeadlineExceeded(start.Add(time.Hour)))
+	})
+
+	t.Run("past the max release duration", func(t *testing.T) {
+		ring := &Ring{MaxReleaseDuration: 3600, ReleaseStartAt: start.UnixNano()}
+		require.True(t, ring.ReleaseDeadlineExceeded(start.Add(time.Hour+time.Second)))
+	})
+}
+
+func TestReleaseDeadlineState(t *testing.T) {
+	require.Equal(t, RingStateReleaseFailed, (&Ring{}).ReleaseDeadlineState())
+	require.Equal(t, RingStateReleaseFailed, (&Ring{FailurePolicy: FailurePolicyContinue}).ReleaseDeadlineState())
+	require.Equal(t, RingStateReleaseFailed, (&Ring{FailurePolicy: FailurePolicyRollbackInstallationGroup}).ReleaseDeadlineState())
+	require.Equal(t, RingStateReleaseRollbackRequested, (&Ring{FailurePolicy: FailurePolicyRollbackRing}).ReleaseDeadlineState())
+	require.Equal(t, RingStateReleaseRollbackRequested, (&Ring{AutoRollback: true}).ReleaseDeadlineState())
+}
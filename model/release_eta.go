@@ -0,0 +1,119 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import "time"
+
+// HasUnfinishedRelease returns whether the ring has a release that is pending
+// or in progress.
+func (c *Ring) HasUnfinishedRelease() bool {
+	switch c.State {
+	case RingStateReleasePending, RingStateReleaseBlocked, RingStateReleaseRequested, RingStateReleaseInProgress, RingStateSoakingRequested:
+		return true
+	}
+	return false
+}
+
+// EstimateReleaseCompletion returns the estimated time, in milliseconds, at
+// which the release in progress of the ring completes, or 0 if the ring has no
+// release in progress.
+//
+// Installation groups are released one after another, so the estimate is the
+// sum of the remaining release and soak time of every installation group plus
+// the soak time of the ring. The time of each installation group is the
+// average of its previous completed releases recorded in the given state
+// change events, falling back to its configured soak time, which rehearsal
+// rings soak on accelerated time.
+func EstimateReleaseCompletion(ring *Ring, installationGroups []*InstallationGroup, events []*StateChangeEvent, now int64) int64 {
+	if !ring.HasUnfinishedRelease() {
+		return 0
+	}
+
+	timeline := BuildRingTimeline(ring.ID, events, 0)
+
+	var current *ReleaseTimeline
+	totals := make(map[string]int64)
+	counts := make(map[string]int64)
+	for _, release := range timeline.Releases {
+		if release.EndAt == 0 && release.ReleaseID == ring.DesiredReleaseID {
+			current = release
+			continue
+		}
+		if release.FinalState != RingStateStable {
+			continue
+		}
+		for id, duration := range installationGroupReleaseDurations(release) {
+			totals[id] += duration
+			counts[id]++
+		}
+	}
+
+	var remaining int64
+	for _, ig := range installationGroups {
+		expected := int64(ig.CurrentSoakTime()) * 1000 / int64(ring.CurrentRehearsalSpeedup())
+		if counts[ig.ID] > 0 {
+			expected = totals[ig.ID] / counts[ig.ID]
+		}
+
+		var startAt int64
+		if current != nil {
+			for _, span := range current.Spans {
+				if span.ResourceType == TypeInstallationGroup && span.ResourceID == ig.ID && isReleasingTimelineState(span.State) {
+					startAt = span.StartAt
+					break
+				}
+			}
+		}
+
+		switch {
+		case startAt == 0:
+			remaining += expected
+		case ig.State == InstallationGroupStable:
+			// Already released as part of the current release.
+		case now-startAt < expected:
+			remaining += expected - (now - startAt)
+		}
+	}
+
+	// The ring only soaks within its soak windows, if any, on accelerated
+	// time for rehearsal rings.
+	soak := int64(ring.CurrentSoakTime()) * 1000
+	if ring.State == RingStateSoakingRequested && ring.ReleaseAt > 0 {
+		soak -= ring.SoakElapsed(ring.SoakWindows.ActiveDuration(time.Unix(0, ring.ReleaseAt), time.UnixMilli(now))).Milliseconds()
+	}
+	soak /= int64(ring.CurrentRehearsalSpeedup())
+	completion := now + remaining
+	if soak > 0 {
+		completion = ring.SoakWindows.AddActive(time.UnixMilli(completion), time.Duration(soak)*time.Millisecond).UnixMilli()
+	}
+
+	return completion
+}
+
+// installationGroupReleaseDurations returns the time each installation group
+// spent releasing and soaking during the given release.
+func installationGroupReleaseDurations(release *ReleaseTimeline) map[string]int64 {
+	durations := make(map[string]int64)
+	for _, span := range release.Spans {
+		if span.ResourceType != TypeInstallationGroup || span.EndAt == 0 || !isReleasingTimelineState(span.State) {
+			continue
+		}
+		durations[span.ResourceID] += span.Duration
+	}
+
+	return durations
+}
+
+// isReleasingTimelineState returns whether an installation group in the given
+// state is actively being released, as opposed to waiting for its turn.
+func isReleasingTimelineState(state string) bool {
+	for _, releasing := range AllInstallationGroupStatesReleaseInProgress {
+		if state == releasing {
+			return true
+		}
+	}
+
+	return false
+}
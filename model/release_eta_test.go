@@ -0,0 +1,96 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateReleaseCompletion(t *testing.T) {
+	ringEvent := func(releaseID, state string, timestamp int64) *StateChangeEvent {
+		return &StateChangeEvent{ResourceType: TypeRing, ResourceID: "ring", RingID: "ring", ReleaseID: releaseID, NewState: state, Timestamp: timestamp}
+	}
+	groupEvent := func(groupID, releaseID, state string, timestamp int64) *StateChangeEvent {
+		return &StateChangeEvent{ResourceType: TypeInstallationGroup, ResourceID: groupID, RingID: "ring", ReleaseID: releaseID, NewState: state, Timestamp: timestamp}
+	}
+
+	history := []*StateChangeEvent{
+		ringEvent("release1", RingStateReleasePending, 0),
+		ringEvent("release1", RingStateReleaseRequested, 100),
+		groupEvent("group1", "release1", InstallationGroupReleaseRequested, 100),
+		groupEvent("group1", "release1", InstallationGroupReleaseSoakingRequested, 1100),
+		groupEvent("group1", "release1", InstallationGroupStable, 3100),
+		groupEvent("group2", "release1", InstallationGroupReleaseRequested, 3100),
+		groupEvent("group2", "release1", InstallationGroupStable, 4100),
+		ringEvent("release1", RingStateStable, 5000),
+	}
+
+	t.Run("no release in progress", func(t *testing.T) {
+		ring := &Ring{ID: "ring", State: RingStateStable}
+		require.Zero(t, EstimateReleaseCompletion(ring, nil, history, 10000))
+	})
+
+	t.Run("pending release uses history", func(t *testing.T) {
+		ring := &Ring{ID: "ring", State: RingStateReleasePending, DesiredReleaseID: "release2", SoakTime: 1}
+		groups := []*InstallationGroup{
+			{ID: "group1", State: InstallationGroupReleasePending},
+			{ID: "group2", State: InstallationGroupReleasePending},
+		}
+		events := append(history, ringEvent("release2", RingStateReleasePending, 10000))
+
+		// 3000ms for group1, 1000ms for group2 and 1000ms of ring soak.
+		require.Equal(t, int64(15000), EstimateReleaseCompletion(ring, groups, events, 10000))
+	})
+
+	t.Run("release in progress subtracts elapsed time", func(t *testing.T) {
+		ring := &Ring{ID: "ring", State: RingStateReleaseInProgress, DesiredReleaseID: "release2"}
+		groups := []*InstallationGroup{
+			{ID: "group1", State: InstallationGroupStable},
+			{ID: "group2", State: InstallationGroupReleaseRequested},
+		}
+		events := append(history,
+			ringEvent("release2", RingStateReleasePending, 10000),
+			groupEvent("group1", "release2", InstallationGroupReleaseRequested, 10000),
+			groupEvent("group1", "release2", InstallationGroupStable, 13000),
+			groupEvent("group2", "release2", InstallationGroupReleaseRequested, 13000),
+		)
+
+		require.Equal(t, int64(14000), EstimateReleaseCompletion(ring, groups, events, 13500))
+	})
+
+	t.Run("no history falls back to soak times", func(t *testing.T) {
+		ring := &Ring{ID: "ring", State: RingStateReleaseRequested, DesiredReleaseID: "release1", SoakTime: 10}
+		groups := []*InstallationGroup{{ID: "group1", SoakTime: 5}}
+
+		require.Equal(t, int64(1000+15000), EstimateReleaseCompletion(ring, groups, nil, 1000))
+	})
+
+	t.Run("rehearsal rings soak on accelerated time", func(t *testing.T) {
+		ring := &Ring{ID: "ring", State: RingStateReleaseRequested, DesiredReleaseID: "release1", SoakTime: 600, Rehearsal: true}
+		groups := []*InstallationGroup{{ID: "group1", SoakTime: 300}}
+
+		require.Equal(t, int64(1000+15000), EstimateReleaseCompletion(ring, groups, nil, 1000))
+	})
+
+	t.Run("ring soaks within its soak windows", func(t *testing.T) {
+		// Soaking since Friday, June 3rd 2022 at 16:00 UTC, for two hours of
+		// business hours, finishes on Monday at 10:00.
+		releaseAt := time.Date(2022, time.June, 3, 16, 0, 0, 0, time.UTC)
+		now := releaseAt.Add(2 * time.Hour).UnixMilli()
+		ring := &Ring{
+			ID:               "ring",
+			State:            RingStateSoakingRequested,
+			DesiredReleaseID: "release1",
+			SoakTime:         7200,
+			ReleaseAt:        releaseAt.UnixNano(),
+			SoakWindows:      TimeWindows{{Start: "09:00", End: "17:00", Weekdays: []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}}},
+		}
+
+		require.Equal(t, time.Date(2022, time.June, 6, 10, 0, 0, 0, time.UTC).UnixMilli(), EstimateReleaseCompletion(ring, nil, nil, now))
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+var releaseLabelRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,62}$`)
+
+// ReleaseLabels tag a release, such as security-patch or quarterly, so that
+// timelines and reports can be filtered by the kind of release.
+type ReleaseLabels []string
+
+// Validate validates every label.
+func (l ReleaseLabels) Validate() error {
+	for _, label := range l {
+		if !releaseLabelRegex.MatchString(label) {
+			return errors.Errorf("invalid release label %q: must start with a lowercase alphanumeric and contain only lowercase alphanumerics, dots, dashes and underscores, up to 63 characters", label)
+		}
+	}
+
+	return nil
+}
+
+// Has returns whether the given label is one of the labels.
+func (l ReleaseLabels) Has(label string) bool {
+	for _, existing := range l {
+		if existing == label {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Add returns the labels with the given ones added, sorted. Labels already
+// present are not repeated.
+func (l ReleaseLabels) Add(labels ...string) ReleaseLabels {
+	result := append(ReleaseLabels{}, l...)
+	for _, label := range labels {
+		if !result.Has(label) {
+			result = append(result, label)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// Remove returns the labels without the given one, and whether it was
+// present.
+func (l ReleaseLabels) Remove(label string) (ReleaseLabels, bool) {
+	result := make(ReleaseLabels, 0, len(l))
+	for _, existing := range l {
+		if existing != label {
+			result = append(result, existing)
+		}
+	}
+
+	return result, len(result) != len(l)
+}
+
+// Value implements driver.Valuer, storing the release labels as JSON.
+func (l ReleaseLabels) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return "[]", nil
+	}
+
+	data, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal release labels")
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner, loading the release labels from JSON.
+func (l *ReleaseLabels) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.Errorf("cannot scan %T into release labels", src)
+	}
+	if len(data) == 0 {
+		*l = nil
+		return nil
+	}
+
+	var labels ReleaseLabels
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return errors.Wrap(err, "failed to unmarshal release labels")
+	}
+	if len(labels) == 0 {
+		labels = nil
+	}
+	*l = labels
+
+	return nil
+}
+
+// ReleaseLabelsFromReader decodes a json-encoded list of release labels from
+// the given io.Reader.
+func ReleaseLabelsFromReader(reader io.Reader) (ReleaseLabels, error) {
+	labels := ReleaseLabels{}
+	err := json.NewDecoder(reader).Decode(&labels)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return labels, nil
+}
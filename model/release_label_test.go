@@ -0,0 +1,40 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseLabels(t *testing.T) {
+	require.NoError(t, ReleaseLabels{"security-patch", "q3.2022", "hotfix_1"}.Validate())
+	require.Error(t, ReleaseLabels{"Security"}.Validate())
+	require.Error(t, ReleaseLabels{""}.Validate())
+	require.Error(t, ReleaseLabels{`"quoted"`}.Validate())
+
+	labels := ReleaseLabels{"quarterly"}.Add("security-patch", "quarterly")
+	require.Equal(t, ReleaseLabels{"quarterly", "security-patch"}, labels)
+	require.True(t, labels.Has("security-patch"))
+	require.False(t, labels.Has("security"))
+
+	labels, found := labels.Remove("quarterly")
+	require.True(t, found)
+	require.Equal(t, ReleaseLabels{"security-patch"}, labels)
+	_, found = labels.Remove("quarterly")
+	require.False(t, found)
+
+	value, err := labels.Value()
+	require.NoError(t, err)
+	var scanned ReleaseLabels
+	require.NoError(t, scanned.Scan(value))
+	require.Equal(t, labels, scanned)
+
+	value, err = ReleaseLabels(nil).Value()
+	require.NoError(t, err)
+	require.NoError(t, scanned.Scan(value))
+	require.Nil(t, scanned)
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ReleaseLeadTimeReport is how long the releases with a label took to reach
+// every ring, such as how long security patches take to reach production.
+type ReleaseLeadTimeReport struct {
+	GeneratedAt int64
+	Label       string
+	Rings       []*RingLeadTime
+	Releases    []*ReleaseLeadTime
+}
+
+// RingLeadTime summarizes the lead times of the releases of the report to a
+// ring. All times are in milliseconds.
+type RingLeadTime struct {
+	RingID   string
+	RingName string
+	// Releases is the number of releases of the report that reached the
+	// ring.
+	Releases        int
+	AverageLeadTime int64
+	MaxLeadTime     int64
+}
+
+// ReleaseLeadTime is how long a release took to reach the rings it reached.
+// All timestamps are in milliseconds; a StartAt of 0 means the release was
+// never released to a ring.
+type ReleaseLeadTime struct {
+	ReleaseID string
+	Image     string
+	Version   string
+	Labels    ReleaseLabels `json:",omitempty"`
+	// StartAt is when the release was first requested for a ring.
+	StartAt int64
+	Rings   []*RingReached
+}
+
+// RingReached is when a release reached a ring, that is when the ring was
+// first stable on it, and how long it took since the release started.
+type RingReached struct {
+	RingID    string
+	ReachedAt int64
+	LeadTime  int64
+}
+
+// BuildReleaseLeadTimeReport builds the lead time report of the given
+// releases with the label from the state change events of the rings, sorted
+// by timestamp.
+func BuildReleaseLeadTimeReport(label string, rings []*Ring, releases []*RingRelease, events []*StateChangeEvent, now int64) *ReleaseLeadTimeReport {
+	report := &ReleaseLeadTimeReport{
+		GeneratedAt: now,
+		Label:       label,
+		Rings:       []*RingLeadTime{},
+		Releases:    []*ReleaseLeadTime{},
+	}
+
+	leadTimes := make(map[string]*ReleaseLeadTime)
+	for _, release := range releases {
+		leadTime := &ReleaseLeadTime{
+			ReleaseID: release.ID,
+			Image:     release.Image,
+			Version:   release.Version,
+			Labels:    release.Labels,
+			Rings:     []*RingReached{},
+		}
+		leadTimes[release.ID] = leadTime
+		report.Releases = append(report.Releases, leadTime)
+	}
+
+	reached := make(map[string]bool)
+	for _, event := range events {
+		if event.ResourceType != TypeRing {
+			continue
+		}
+		leadTime, ok := leadTimes[event.ReleaseID]
+		if !ok {
+			continue
+		}
+		if leadTime.StartAt == 0 {
+			leadTime.StartAt = event.Timestamp
+		}
+		if event.NewState != RingStateStable || reached[event.ReleaseID+"/"+event.RingID] {
+			continue
+		}
+		reached[event.ReleaseID+"/"+event.RingID] = true
+		leadTime.Rings = append(leadTime.Rings, &RingReached{
+			RingID:    event.RingID,
+			ReachedAt: event.Timestamp,
+			LeadTime:  event.Timestamp - leadTime.StartAt,
+		})
+	}
+
+	for _, ring := range rings {
+		summary := &RingLeadTime{RingID: ring.ID, RingName: ring.Name}
+		var total int64
+		for _, leadTime := range report.Releases {
+			for _, ringReached := range leadTime.Rings {
+				if ringReached.RingID != ring.ID {
+					continue
+				}
+				summary.Releases++
+				total += ringReached.LeadTime
+				if ringReached.LeadTime > summary.MaxLeadTime {
+					summary.MaxLeadTime = ringReached.LeadTime
+				}
+			}
+		}
+		if summary.Releases > 0 {
+			summary.AverageLeadTime = total / int64(summary.Releases)
+		}
+		report.Rings = append(report.Rings, summary)
+	}
+
+	return report
+}
+
+// ReleaseLeadTimeReportFromReader decodes a json-encoded release lead time
+// report from the given io.Reader.
+func ReleaseLeadTimeReportFromReader(reader io.Reader) (*ReleaseLeadTimeReport, error) {
+	report := ReleaseLeadTimeReport{}
+	err := json.NewDecoder(reader).Decode(&report)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildReleaseLeadTimeReport(t *testing.T) {
+	staging := &Ring{ID: "staging", Name: "staging"}
+	prod := &Ring{ID: "prod", Name: "prod"}
+	patch1 := &RingRelease{ID: "patch1", Version: "7.0.1", Labels: ReleaseLabels{"security-patch"}}
+	patch2 := &RingRelease{ID: "patch2", Version: "7.0.2", Labels: ReleaseLabels{"security-patch"}}
+	unreleased := &RingRelease{ID: "unreleased", Version: "7.0.3", Labels: ReleaseLabels{"security-patch"}}
+
+	ringEvent := func(ringID, releaseID, state string, timestamp int64) *StateChangeEvent {
+		return &StateChangeEvent{ResourceType: TypeRing, ResourceID: ringID, RingID: ringID, ReleaseID: releaseID, NewState: state, Timestamp: timestamp}
+	}
+	events := []*StateChangeEvent{
+		ringEvent("staging", "patch1", RingStateReleasePending, 1000),
+		ringEvent("staging", "patch1", RingStateStable, 2000),
+		ringEvent("prod", "patch1", RingStateReleasePending, 2500),
+		ringEvent("prod", "other", RingStateStable, 2600),
+		ringEvent("prod", "patch1", RingStateStable, 5000),
+		ringEvent("staging", "patch2", RingStateReleasePending, 10000),
+		ringEvent("staging", "patch2", RingStateStable, 11000),
+		ringEvent("staging", "patch2", RingStateStable, 12000),
+		{ResourceType: TypeInstallationGroup, ResourceID: "group", RingID: "prod", ReleaseID: "patch2", NewState: InstallationGroupStable, Timestamp: 13000},
+	}
+
+	report := BuildReleaseLeadTimeReport("security-patch", []*Ring{staging, prod}, []*RingRelease{unreleased, patch2, patch1}, events, 20000)
+	require.Equal(t, "security-patch", report.Label)
+	require.Equal(t, int64(20000), report.GeneratedAt)
+
+	require.Len(t, report.Releases, 3)
+	require.Zero(t, report.Releases[0].StartAt)
+	require.Empty(t, report.Releases[0].Rings)
+	require.Equal(t, []*RingReached{{RingID: "staging", ReachedAt: 11000, LeadTime: 1000}}, report.Releases[1].Rings)
+	require.Equal(t, []*RingReached{
+		{RingID: "staging", ReachedAt: 2000, LeadTime: 1000},
+		{RingID: "prod", ReachedAt: 5000, LeadTime: 4000},
+	}, report.Releases[2].Rings)
+
+	require.Equal(t, []*RingLeadTime{
+		{RingID: "staging", RingName: "staging", Releases: 2, AverageLeadTime: 1000, MaxLeadTime: 1000},
+		{RingID: "prod", RingName: "prod", Releases: 1, AverageLeadTime: 4000, MaxLeadTime: 4000},
+	}, report.Rings)
+}
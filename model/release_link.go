@@ -0,0 +1,226 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ReleaseLinkTypePullRequest links a release to a pull request it ships.
+	ReleaseLinkTypePullRequest = "pr"
+	// ReleaseLinkTypeBuild links a release to the build that produced it.
+	ReleaseLinkTypeBuild = "build"
+	// ReleaseLinkTypeIncident links a release to an incident it mitigates or
+	// caused.
+	ReleaseLinkTypeIncident = "incident"
+	// ReleaseLinkTypeChangeTicket links a release to the change ticket
+	// approving it.
+	ReleaseLinkTypeChangeTicket = "change-ticket"
+)
+
+// ReleaseLinkTypes are the supported release link types.
+var ReleaseLinkTypes = []string{
+	ReleaseLinkTypePullRequest,
+	ReleaseLinkTypeBuild,
+	ReleaseLinkTypeIncident,
+	ReleaseLinkTypeChangeTicket,
+}
+
+// ValidReleaseLinkType returns whether the given release link type is
+// supported.
+func ValidReleaseLinkType(linkType string) bool {
+	for _, valid := range ReleaseLinkTypes {
+		if linkType == valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReleaseLink is a typed reference from a release to an external system.
+type ReleaseLink struct {
+	Type  string
+	URL   string
+	Title string `json:",omitempty"`
+}
+
+// Validate validates the values of a release link.
+func (l *ReleaseLink) Validate() error {
+	if !ValidReleaseLinkType(l.Type) {
+		return errors.Errorf("unknown release link type %q, must be one of %s", l.Type, strings.Join(ReleaseLinkTypes, ", "))
+	}
+	u, err := url.Parse(l.URL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid release link URL %q", l.URL)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("release link URL %q must be an absolute http or https URL", l.URL)
+	}
+
+	return nil
+}
+
+// NewReleaseLinkFromReader will create a ReleaseLink from an io.Reader with
+// JSON data.
+func NewReleaseLinkFromReader(reader io.Reader) (*ReleaseLink, error) {
+	var link ReleaseLink
+	err := json.NewDecoder(reader).Decode(&link)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode release link")
+	}
+
+	if err = link.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid release link")
+	}
+
+	return &link, nil
+}
+
+// ReleaseLinks are the links of a release. A link is identified by its type
+// and URL.
+type ReleaseLinks []*ReleaseLink
+
+// Validate validates every link.
+func (l ReleaseLinks) Validate() error {
+	for _, link := range l {
+		if link == nil {
+			return errors.New("release link cannot be empty")
+		}
+		if err := link.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Add returns the links with the given ones added. A link already present
+// has its title updated instead.
+func (l ReleaseLinks) Add(links ...*ReleaseLink) ReleaseLinks {
+	result := append(ReleaseLinks{}, l...)
+	for _, link := range links {
+		index := result.index(link.Type, link.URL)
+		if index < 0 {
+			result = append(result, link)
+			continue
+		}
+		result[index] = link
+	}
+
+	return result
+}
+
+// Remove returns the links without the one of the given type and URL, and
+// whether it was present.
+func (l ReleaseLinks) Remove(linkType, linkURL string) (ReleaseLinks, bool) {
+	index := l.index(linkType, linkURL)
+	if index < 0 {
+		return l, false
+	}
+
+	result := append(ReleaseLinks{}, l[:index]...)
+	return append(result, l[index+1:]...), true
+}
+
+func (l ReleaseLinks) index(linkType, linkURL string) int {
+	for i, link := range l {
+		if link.Type == linkType && link.URL == linkURL {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// ByType returns the URLs of the links, by link type.
+func (l ReleaseLinks) ByType() map[string][]string {
+	if len(l) == 0 {
+		return nil
+	}
+
+	byType := make(map[string][]string)
+	for _, link := range l {
+		byType[link.Type] = append(byType[link.Type], link.URL)
+	}
+
+	return byType
+}
+
+// String returns the links as a space-separated list of type=URL pairs.
+func (l ReleaseLinks) String() string {
+	pairs := make([]string, 0, len(l))
+	for _, link := range l {
+		pairs = append(pairs, link.Type+"="+link.URL)
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// Value implements driver.Valuer, storing the release links as JSON.
+func (l ReleaseLinks) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return "[]", nil
+	}
+
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal release links")
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner, loading the release links from JSON.
+func (l *ReleaseLinks) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.Errorf("cannot scan %T into release links", src)
+	}
+	if len(data) == 0 {
+		*l = nil
+		return nil
+	}
+
+	var links ReleaseLinks
+	if err := json.Unmarshal(data, &links); err != nil {
+		return errors.Wrap(err, "failed to unmarshal release links")
+	}
+	if len(links) == 0 {
+		links = nil
+	}
+	*l = links
+
+	return nil
+}
+
+// ReleaseLinksFromReader decodes a json-encoded list of release links from
+// the given io.Reader.
+func ReleaseLinksFromReader(reader io.Reader) (ReleaseLinks, error) {
+	links := ReleaseLinks{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&links)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return links, nil
+}
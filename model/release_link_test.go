@@ -0,0 +1,51 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseLinkValidate(t *testing.T) {
+	require.NoError(t, (&ReleaseLink{Type: ReleaseLinkTypeIncident, URL: "https://incidents.example.com/1"}).Validate())
+	require.Error(t, (&ReleaseLink{Type: "wiki", URL: "https://wiki.example.com"}).Validate())
+	require.Error(t, (&ReleaseLink{Type: ReleaseLinkTypeBuild, URL: "ci/build/1"}).Validate())
+	require.Error(t, (&ReleaseLink{Type: ReleaseLinkTypeBuild, URL: "ftp://ci.example.com/1"}).Validate())
+	require.Error(t, ReleaseLinks{nil}.Validate())
+}
+
+func TestReleaseLinks(t *testing.T) {
+	pr := &ReleaseLink{Type: ReleaseLinkTypePullRequest, URL: "https://example.com/pull/1"}
+	build := &ReleaseLink{Type: ReleaseLinkTypeBuild, URL: "https://example.com/build/1"}
+	pr2 := &ReleaseLink{Type: ReleaseLinkTypePullRequest, URL: "https://example.com/pull/2", Title: "Fix"}
+
+	links := ReleaseLinks{pr}.Add(build, pr2, &ReleaseLink{Type: pr.Type, URL: pr.URL, Title: "Feature"})
+	require.Len(t, links, 3)
+	require.Equal(t, "Feature", links[0].Title)
+	require.Equal(t, map[string][]string{
+		ReleaseLinkTypePullRequest: {pr.URL, pr2.URL},
+		ReleaseLinkTypeBuild:       {build.URL},
+	}, links.ByType())
+	require.Equal(t, "pr=https://example.com/pull/1 build=https://example.com/build/1 pr=https://example.com/pull/2", links.String())
+
+	links, found := links.Remove(build.Type, build.URL)
+	require.True(t, found)
+	require.Len(t, links, 2)
+	_, found = links.Remove(build.Type, build.URL)
+	require.False(t, found)
+
+	value, err := links.Value()
+	require.NoError(t, err)
+	var scanned ReleaseLinks
+	require.NoError(t, scanned.Scan(value))
+	require.Equal(t, links, scanned)
+
+	value, err = ReleaseLinks(nil).Value()
+	require.NoError(t, err)
+	require.NoError(t, scanned.Scan(value))
+	require.Nil(t, scanned)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+var envVarNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ReleaseParameters overrides, by installation group name, the parameters
+// passed to the provisioner when releasing each installation group, such as
+// different environment variables for EU and US installation groups.
+type ReleaseParameters map[string]*InstallationGroupReleaseParameters
+
+// InstallationGroupReleaseParameters are the release parameters of a single
+// installation group.
+type InstallationGroupReleaseParameters struct {
+	// MattermostEnv sets environment variables of the installations of the
+	// provisioner group, on top of those already set on the group.
+	MattermostEnv map[string]string `json:"mattermostEnv,omitempty"`
+}
+
+// Validate validates the release parameters.
+func (p ReleaseParameters) Validate() error {
+	for name, parameters := range p {
+		if name == "" {
+			return errors.New("installation group name of release parameters cannot be empty")
+		}
+		if parameters == nil {
+			return errors.Errorf("release parameters of installation group %s cannot be empty", name)
+		}
+		for envName := range parameters.MattermostEnv {
+			if !envVarNameRegex.MatchString(envName) {
+				return errors.Errorf("invalid environment variable name %q of installation group %s", envName, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateInstallationGroups checks that every installation group given
+// parameters is one of the given installation groups.
+func (p ReleaseParameters) ValidateInstallationGroups(installationGroups []*InstallationGroup) error {
+	names := make(map[string]bool, len(installationGroups))
+	for _, installationGroup := range installationGroups {
+		names[installationGroup.Name] = true
+	}
+	for name := range p {
+		if !names[name] {
+			return errors.Errorf("release parameters given for unknown installation group %s", name)
+		}
+	}
+
+	return nil
+}
+
+// Equal returns whether the release parameters are the same.
+func (p ReleaseParameters) Equal(other ReleaseParameters) bool {
+	if len(p) == 0 && len(other) == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(p, other)
+}
+
+// Value implements driver.Valuer, storing the release parameters as JSON.
+// Maps are marshaled with sorted keys, so equal parameters are stored alike.
+func (p ReleaseParameters) Value() (driver.Value, error) {
+	if p == nil {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal release parameters")
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner, loading the release parameters from JSON.
+func (p *ReleaseParameters) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.Errorf("cannot scan %T into release parameters", src)
+	}
+
+	var parameters ReleaseParameters
+	if err := json.Unmarshal(data, &parameters); err != nil {
+		return errors.Wrap(err, "failed to unmarshal release parameters")
+	}
+	if len(parameters) == 0 {
+		parameters = nil
+	}
+	*p = parameters
+
+	return nil
+}
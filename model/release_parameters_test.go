@@ -0,0 +1,76 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseParametersValidate(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		parameters   model.ReleaseParameters
+		requireError bool
+	}{
+		{"nil", nil, false},
+		{"valid", model.ReleaseParameters{"eu-ig": {MattermostEnv: map[string]string{"MM_DATA_RESIDENCY": "eu"}}}, false},
+		{"empty installation group name", model.ReleaseParameters{"": {MattermostEnv: map[string]string{"MM_DATA_RESIDENCY": "eu"}}}, true},
+		{"empty parameters", model.ReleaseParameters{"eu-ig": nil}, true},
+		{"invalid env var name", model.ReleaseParameters{"eu-ig": {MattermostEnv: map[string]string{"MM DATA": "eu"}}}, true},
+		{"env var name starting with a digit", model.ReleaseParameters{"eu-ig": {MattermostEnv: map[string]string{"1MM": "eu"}}}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.parameters.Validate())
+			} else {
+				assert.NoError(t, tc.parameters.Validate())
+			}
+		})
+	}
+}
+
+func TestReleaseParametersValidateInstallationGroups(t *testing.T) {
+	installationGroups := []*model.InstallationGroup{{Name: "eu-ig"}, {Name: "us-ig"}}
+
+	parameters := model.ReleaseParameters{"eu-ig": {MattermostEnv: map[string]string{"MM_DATA_RESIDENCY": "eu"}}}
+	require.NoError(t, parameters.ValidateInstallationGroups(installationGroups))
+
+	parameters["apac-ig"] = &model.InstallationGroupReleaseParameters{}
+	require.EqualError(t, parameters.ValidateInstallationGroups(installationGroups), "release parameters given for unknown installation group apac-ig")
+}
+
+func TestReleaseParametersEqual(t *testing.T) {
+	parameters := model.ReleaseParameters{"eu-ig": {MattermostEnv: map[string]string{"MM_DATA_RESIDENCY": "eu"}}}
+
+	require.True(t, model.ReleaseParameters(nil).Equal(model.ReleaseParameters{}))
+	require.True(t, parameters.Equal(model.ReleaseParameters{"eu-ig": {MattermostEnv: map[string]string{"MM_DATA_RESIDENCY": "eu"}}}))
+	require.False(t, parameters.Equal(nil))
+	require.False(t, parameters.Equal(model.ReleaseParameters{"eu-ig": {MattermostEnv: map[string]string{"MM_DATA_RESIDENCY": "us"}}}))
+}
+
+func TestReleaseParametersScan(t *testing.T) {
+	parameters := model.ReleaseParameters{"eu-ig": {MattermostEnv: map[string]string{"MM_DATA_RESIDENCY": "eu"}}}
+
+	value, err := parameters.Value()
+	require.NoError(t, err)
+
+	var scanned model.ReleaseParameters
+	require.NoError(t, scanned.Scan(value))
+	require.Equal(t, parameters, scanned)
+
+	value, err = model.ReleaseParameters(nil).Value()
+	require.NoError(t, err)
+	require.Equal(t, "{}", value)
+	require.NoError(t, scanned.Scan(value))
+	require.Nil(t, scanned)
+
+	require.Error(t, scanned.Scan(42))
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+const (
+	// ReleaseStrategyRolling releases an installation group by updating its
+	// provisioner group in place, restarting its installations a few at a
+	// time. It is the default strategy.
+	ReleaseStrategyRolling = "rolling"
+	// ReleaseStrategyBlueGreen releases an installation group by standing up
+	// a new provisioner group with the release, verifying it, switching the
+	// installations over to it and tearing down the previous group.
+	ReleaseStrategyBlueGreen = "blue-green"
+)
+
+// CurrentReleaseStrategy returns the release strategy of the installation
+// group, defaulting to ReleaseStrategyRolling.
+func (i *InstallationGroup) CurrentReleaseStrategy() string {
+	if i.ReleaseStrategy == "" {
+		return ReleaseStrategyRolling
+	}
+
+	return i.ReleaseStrategy
+}
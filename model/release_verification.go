@@ -0,0 +1,115 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// VerificationJobRunning is a verification job still running.
+	VerificationJobRunning = "running"
+	// VerificationJobPassed is a verification job that passed.
+	VerificationJobPassed = "passed"
+	// VerificationJobFailed is a verification job that failed.
+	VerificationJobFailed = "failed"
+)
+
+// DefaultVerificationTimeout is the time, in seconds, the verification job of
+// an installation group release runs at most, unless the installation group
+// sets its own.
+const DefaultVerificationTimeout = 1800
+
+// VerificationJob is a job verifying the release of an installation group,
+// such as a test suite or a Kubernetes Job, run before the installation group
+// soaks. The verification URL of the installation group starts it and reports
+// its status.
+type VerificationJob struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	// OutputURL references the output of the job, such as its logs or test
+	// report.
+	OutputURL string `json:"outputURL,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// VerificationJobRequest is posted to the verification URL of an
+// installation group to start the verification job of its release.
+type VerificationJobRequest struct {
+	InstallationGroupID   string      `json:"installationGroupID"`
+	InstallationGroupName string      `json:"installationGroupName"`
+	ProvisionerGroupID    string      `json:"provisionerGroupID"`
+	ReleaseID             string      `json:"releaseID"`
+	Image                 string      `json:"image"`
+	Version               string      `json:"version"`
+	Annotations           Annotations `json:"annotations,omitempty"`
+}
+
+// ValidVerificationJobStatus returns whether the given verification job
+// status is known.
+func ValidVerificationJobStatus(status string) bool {
+	switch status {
+	case VerificationJobRunning, VerificationJobPassed, VerificationJobFailed:
+		return true
+	}
+
+	return false
+}
+
+// ValidateReleaseVerification validates the verification URL and timeout, in
+// seconds, of an installation group. An empty URL disables the verification
+// and a zero timeout selects the default one.
+func ValidateReleaseVerification(verificationURL string, timeout int) error {
+	if timeout < 0 {
+		return errors.New("verification timeout cannot be negative")
+	}
+	if verificationURL == "" {
+		return nil
+	}
+	u, err := url.Parse(verificationURL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid verification URL %q", verificationURL)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("verification URL %q must be an absolute http or https URL", verificationURL)
+	}
+
+	return nil
+}
+
+// CurrentVerificationTimeout returns the time the verification job of a
+// release of the installation group runs at most, defaulting to
+// DefaultVerificationTimeout.
+func (i *InstallationGroup) CurrentVerificationTimeout() time.Duration {
+	if i.VerificationTimeout == 0 {
+		return DefaultVerificationTimeout * time.Second
+	}
+
+	return time.Duration(i.VerificationTimeout) * time.Second
+}
+
+// VerificationJobFromReader decodes a json-encoded verification job from the
+// given io.Reader. A job without a status is running.
+func VerificationJobFromReader(reader io.Reader) (*VerificationJob, error) {
+	job := VerificationJob{}
+	err := json.NewDecoder(reader).Decode(&job)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if job.Status == "" {
+		job.Status = VerificationJobRunning
+	}
+	if !ValidVerificationJobStatus(job.Status) {
+		return nil, errors.Errorf("unknown verification job status %q", job.Status)
+	}
+
+	return &job, nil
+}
@@ -0,0 +1,345 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// ReplayDifferenceStateMachine marks a recorded transition the state
+	// machine replayed against does not allow.
+	ReplayDifferenceStateMachine = "state-machine"
+	// ReplayDifferencePolicy marks a recorded supervisor transition of a
+	// protected ring the authorization policy would have held, denying it or
+	// failing to evaluate.
+	ReplayDifferencePolicy = "policy"
+	// ReplayDifferenceFailurePolicy marks a recorded failure of a ring
+	// release the current failure policy of the ring would have handled
+	// differently.
+	ReplayDifferenceFailurePolicy = "failure-policy"
+	// ReplayDifferenceMaxReleaseDuration marks a recorded ring release the
+	// current max release duration of the ring would have failed.
+	ReplayDifferenceMaxReleaseDuration = "max-release-duration"
+)
+
+// ReplayReport is the outcome of replaying a recorded state change event
+// history against the current state machine and policy configuration, listing
+// where their behavior would have differed from the recorded one.
+type ReplayReport struct {
+	StateMachineVersion int
+	// PolicyEvaluated is whether the transitions of protected rings were
+	// evaluated against an authorization policy.
+	PolicyEvaluated bool
+	// From and To are the timestamps, in milliseconds, of the first and last
+	// replayed events.
+	From int64
+	To   int64
+	// Replayed is the number of replayed events, and Skipped the number of
+	// events of rings unknown to the replay, such as deleted rings.
+	Replayed    int
+	Skipped     int
+	Differences []*ReplayDifference
+}
+
+// ReplayDifference is a recorded transition the replayed configuration
+// would have handled differently.
+type ReplayDifference struct {
+	// Reason is ReplayDifferenceStateMachine, ReplayDifferencePolicy,
+	// ReplayDifferenceFailurePolicy or ReplayDifferenceMaxReleaseDuration.
+	Reason       string
+	EventID      string
+	Timestamp    int64
+	RingID       string
+	RingName     string
+	ResourceType string
+	ResourceID   string
+	ReleaseID    string `json:",omitempty"`
+	OldState     string
+	NewState     string
+	// ExpectedState is the state the replayed configuration would have moved
+	// the resource to instead, when known.
+	ExpectedState string `json:",omitempty"`
+	Message       string
+}
+
+// GetReplayReportRequest describes the parameters to replay the recorded
+// state change events.
+type GetReplayReportRequest struct {
+	// RingID, when set, restricts the replay to the events of that ring.
+	RingID string
+	// From and To, when set, restrict the replay to the events at or after
+	// From and before To, in milliseconds.
+	From int64
+	To   int64
+	// FailurePolicy and MaxReleaseDuration, when set, replace the failure
+	// policy and max release duration of every replayed ring, to validate
+	// them before changing the rings.
+	FailurePolicy      string
+	MaxReleaseDuration *int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetReplayReportRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	if request.RingID != "" {
+		q.Add("ring", request.RingID)
+	}
+	if request.From != 0 {
+		q.Add("from", strconv.FormatInt(request.From, 10))
+	}
+	if request.To != 0 {
+		q.Add("to", strconv.FormatInt(request.To, 10))
+	}
+	if request.FailurePolicy != "" {
+		q.Add("failure_policy", request.FailurePolicy)
+	}
+	if request.MaxReleaseDuration != nil {
+		q.Add("max_release_duration", strconv.Itoa(*request.MaxReleaseDuration))
+	}
+	u.RawQuery = q.Encode()
+}
+
+// ReplayPolicyFunc evaluates the authorization policy on a replayed
+// supervisor transition.
+type ReplayPolicyFunc func(input *PolicyInput) (*PolicyDecision, error)
+
+// replayedRelease tracks a ring release during a replay.
+type replayedRelease struct {
+	// startAt is when the release was requested, in milliseconds.
+	startAt int64
+	// failure is the installation group failure the ring has yet to react
+	// to, if any.
+	failure *StateChangeEvent
+}
+
+// ReplayEvents replays the given state change events, in chronological order,
+// against the current state machine version, the given rings as they are
+// configured now, and the given authorization policy, if any. The replay
+// works on its own copies of the rings and never changes them.
+//
+// Every recorded transition is checked against the current transition rules,
+// and the supervisor transitions of protected rings against the policy,
+// evaluated as of the time of the event. The reaction of rings to the failure
+// of their installation groups is checked against their current failure
+// policy, and their releases against their current max release duration.
+// Events of rings missing from the given rings are skipped.
+func ReplayEvents(rings []*Ring, events []*StateChangeEvent, evaluate ReplayPolicyFunc) (*ReplayReport, error) {
+	ringGraph, err := NewStateMachineGraph(TypeRing, CurrentStateMachineVersion)
+	if err != nil {
+		return nil, err
+	}
+	installationGroupGraph, err := NewStateMachineGraph(TypeInstallationGroup, CurrentStateMachineVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReplayReport{
+		StateMachineVersion: CurrentStateMachineVersion,
+		PolicyEvaluated:     evaluate != nil,
+		Differences:         []*ReplayDifference{},
+	}
+
+	ringsByID := make(map[string]*Ring, len(rings))
+	for _, ring := range rings {
+		replayedRing := *ring
+		ringsByID[ring.ID] = &replayedRing
+	}
+	releases := make(map[string]*replayedRelease)
+	pendingRollbacks := make(map[string]*StateChangeEvent)
+
+	for _, event := range events {
+		ring := ringsByID[event.RingID]
+		if ring == nil {
+			report.Skipped++
+			continue
+		}
+		if report.Replayed == 0 {
+			report.From = event.Timestamp
+		}
+		report.To = event.Timestamp
+		report.Replayed++
+
+		difference := func(reason, expectedState, message string) {
+			report.Differences = append(report.Differences, &ReplayDifference{
+				Reason:        reason,
+				EventID:       event.ID,
+				Timestamp:     event.Timestamp,
+				RingID:        ring.ID,
+				RingName:      ring.Name,
+				ResourceType:  event.ResourceType,
+				ResourceID:    event.ResourceID,
+				ReleaseID:     event.ReleaseID,
+				OldState:      event.OldState,
+				NewState:      event.NewState,
+				ExpectedState: expectedState,
+				Message:       message,
+			})
+		}
+
+		switch event.ResourceType {
+		case TypeRing:
+			if !replayedTransitionAllowed(ringGraph, event) && !failsRingPendingWork(event) {
+				difference(ReplayDifferenceStateMachine, "", fmt.Sprintf("state machine version %d does not allow moving a ring from %s to %s", CurrentStateMachineVersion, event.OldState, event.NewState))
+			}
+
+			if evaluate != nil && ring.Protected && isRingSupervisorTransition(event) {
+				policyRing := NewPolicyRing(ring)
+				policyRing.State = event.OldState
+				policyRing.DesiredReleaseID = event.ReleaseID
+				decision, err := evaluate(&PolicyInput{
+					Action:     PolicyActionTransition,
+					Time:       time.Unix(0, event.Timestamp*int64(time.Millisecond)).UTC().Format(time.RFC3339),
+					Ring:       policyRing,
+					State:      event.OldState,
+					InstanceID: event.InstanceID,
+				})
+				switch {
+				case err != nil:
+					difference(ReplayDifferencePolicy, event.OldState, fmt.Sprintf("failed to evaluate the authorization policy: %s", err))
+				case !decision.Allow:
+					message := "authorization policy denied the transition"
+					if decision.Reason != "" {
+						message = fmt.Sprintf("%s: %s", message, decision.Reason)
+					}
+					difference(ReplayDifferencePolicy, event.OldState, message)
+				}
+			}
+
+			release := releases[ring.ID]
+			if event.NewState == RingStateReleaseRequested && event.OldState != RingStateReleaseRequested {
+				release = &replayedRelease{startAt: event.Timestamp}
+				releases[ring.ID] = release
+			}
+			if release == nil {
+				continue
+			}
+
+			if release.failure != nil && (event.NewState == RingStateReleaseFailed || event.NewState == RingStateReleaseRollbackRequested) {
+				expectedState := RingStateReleaseFailed
+				if ring.CurrentFailurePolicy() == FailurePolicyRollbackRing {
+					expectedState = RingStateReleaseRollbackRequested
+				}
+				if event.NewState != expectedState {
+					difference(ReplayDifferenceFailurePolicy, expectedState, fmt.Sprintf("the %s failure policy would have moved the ring to %s after installation group %s ended in state %s", ring.CurrentFailurePolicy(), expectedState, release.failure.ResourceID, release.failure.NewState))
+				}
+				release.failure = nil
+			}
+
+			if event.OldState == RingStateReleaseInProgress && ring.MaxReleaseDuration > 0 {
+				duration := time.Duration(event.Timestamp-release.startAt) * time.Millisecond
+				if duration > time.Duration(ring.MaxReleaseDuration)*time.Second && event.NewState != ring.ReleaseDeadlineState() {
+					difference(ReplayDifferenceMaxReleaseDuration, ring.ReleaseDeadlineState(), fmt.Sprintf("release ran for %s, exceeding the max release duration of %s", duration, time.Duration(ring.MaxReleaseDuration)*time.Second))
+					delete(releases, ring.ID)
+					continue
+				}
+			}
+
+			switch event.NewState {
+			case RingStateStable, RingStateReleaseFailed, RingStateSoakingFailed, RingStateReleaseRollbackRequested:
+				delete(releases, ring.ID)
+			}
+		case TypeInstallationGroup:
+			if !replayedTransitionAllowed(installationGroupGraph, event) {
+				difference(ReplayDifferenceStateMachine, "", fmt.Sprintf("state machine version %d does not allow moving an installation group from %s to %s", CurrentStateMachineVersion, event.OldState, event.NewState))
+			}
+
+			key := ring.ID + "/" + event.ResourceID
+			if failure := pendingRollbacks[key]; failure != nil {
+				delete(pendingRollbacks, key)
+				if event.NewState != InstallationGroupReleaseRollbackRequested {
+					difference(ReplayDifferenceFailurePolicy, InstallationGroupReleaseRollbackRequested, fmt.Sprintf("the %s failure policy would have rolled back the installation group after it ended in state %s", ring.CurrentFailurePolicy(), failure.NewState))
+				}
+			}
+
+			if event.NewState != InstallationGroupReleaseFailed && event.NewState != InstallationGroupReleaseSoakingFailed {
+				continue
+			}
+			switch ring.CurrentFailurePolicy() {
+			case FailurePolicyRollbackInstallationGroup:
+				pendingRollbacks[key] = event
+			case FailurePolicyHalt, FailurePolicyRollbackRing:
+				if release := releases[ring.ID]; release != nil && release.failure == nil {
+					release.failure = event
+				}
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// replayedTransitionAllowed returns whether the given graph allows the
+// transition of the event. Transitions from states unknown to the graph,
+// such as the creation of a ring, are allowed, as are events recording no
+// transition, such as drift.
+func replayedTransitionAllowed(graph *StateMachineGraph, event *StateChangeEvent) bool {
+	if event.OldState == event.NewState {
+		return true
+	}
+
+	known := false
+	for _, state := range graph.States {
+		if state == event.OldState {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return true
+	}
+
+	for _, transition := range graph.Transitions {
+		if transition.From == event.OldState && transition.To == event.NewState {
+			return true
+		}
+	}
+
+	return false
+}
+
+// failsRingPendingWork returns whether the ring event is one of the failures
+// the supervisors apply to every ring pending work when a release fails.
+func failsRingPendingWork(event *StateChangeEvent) bool {
+	if event.NewState != RingStateReleaseFailed && event.NewState != RingStateReleaseRollbackRequested {
+		return false
+	}
+	for _, state := range AllRingStatesPendingWork {
+		if state == event.OldState {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRingSupervisorTransition returns whether the ring transition of the event
+// is made by the supervisors.
+func isRingSupervisorTransition(event *StateChangeEvent) bool {
+	for _, state := range ringSupervisorTransitions[event.OldState] {
+		if state == event.NewState {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReplayReportFromReader decodes a json-encoded replay report from the given
+// io.Reader.
+func ReplayReportFromReader(reader io.Reader) (*ReplayReport, error) {
+	report := &ReplayReport{}
+	err := json.NewDecoder(reader).Decode(report)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayEvents(t *testing.T) {
+	release := []*StateChangeEvent{
+		{ID: "e1", ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", ReleaseID: "release1", OldState: "n/a", NewState: RingStateCreationRequested, Timestamp: 1000},
+		{ID: "e2", ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", ReleaseID: "release1", OldState: RingStateCreationRequested, NewState: RingStateStable, Timestamp: 2000},
+		{ID: "e3", ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", ReleaseID: "release2", OldState: RingStateStable, NewState: RingStateReleasePending, Timestamp: 3000},
+		{ID: "e4", ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", ReleaseID: "release2", OldState: RingStateReleasePending, NewState: RingStateReleaseRequested, Timestamp: 4000},
+		{ID: "e5", ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", ReleaseID: "release2", OldState: RingStateReleaseRequested, NewState: RingStateReleaseInProgress, Timestamp: 5000},
+		{ID: "e6", ResourceType: TypeInstallationGroup, ResourceID: "ig1", RingID: "ring1", ReleaseID: "release2", OldState: InstallationGroupReleasePending, NewState: InstallationGroupReleaseRequested, Timestamp: 6000},
+		{ID: "e7", ResourceType: TypeInstallationGroup, ResourceID: "ig1", RingID: "ring1", ReleaseID: "release2", OldState: InstallationGroupReleaseRequested, NewState: InstallationGroupReleaseFailed, Timestamp: 7000},
+		{ID: "e8", ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", ReleaseID: "release2", OldState: RingStateReleaseInProgress, NewState: RingStateReleaseFailed, Timestamp: 8000},
+		{ID: "e9", ResourceType: TypeRing, ResourceID: "deleted", RingID: "deleted", ReleaseID: "release2", OldState: RingStateStable, NewState: RingStateReleasePending, Timestamp: 9000},
+	}
+
+	t.Run("no differences", func(t *testing.T) {
+		report, err := ReplayEvents([]*Ring{{ID: "ring1", Name: "one"}}, release, nil)
+		require.NoError(t, err)
+		require.Equal(t, CurrentStateMachineVersion, report.StateMachineVersion)
+		require.False(t, report.PolicyEvaluated)
+		require.Equal(t, 8, report.Replayed)
+		require.Equal(t, 1, report.Skipped)
+		require.Equal(t, int64(1000), report.From)
+		require.Equal(t, int64(8000), report.To)
+		require.Empty(t, report.Differences)
+	})
+
+	t.Run("state machine", func(t *testing.T) {
+		events := []*StateChangeEvent{
+			{ID: "e1", ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", OldState: RingStateDeleted, NewState: RingStateStable, Timestamp: 1000},
+		}
+		report, err := ReplayEvents([]*Ring{{ID: "ring1", Name: "one"}}, events, nil)
+		require.NoError(t, err)
+		require.Len(t, report.Differences, 1)
+		require.Equal(t, ReplayDifferenceStateMachine, report.Differences[0].Reason)
+		require.Equal(t, "e1", report.Differences[0].EventID)
+		require.Equal(t, "one", report.Differences[0].RingName)
+
+		// Events recording no transition, such as drift, are not differences.
+		events = []*StateChangeEvent{
+			{ID: "e2", ResourceType: TypeInstallationGroup, ResourceID: "ig1", RingID: "ring1", OldState: InstallationGroupStable, NewState: InstallationGroupStable, Timestamp: 2000},
+		}
+		report, err = ReplayEvents([]*Ring{{ID: "ring1", Name: "one"}}, events, nil)
+		require.NoError(t, err)
+		require.Empty(t, report.Differences)
+	})
+
+	t.Run("rollback ring failure policy", func(t *testing.T) {
+		report, err := ReplayEvents([]*Ring{{ID: "ring1", FailurePolicy: FailurePolicyRollbackRing}}, release, nil)
+		require.NoError(t, err)
+		require.Len(t, report.Differences, 1)
+		difference := report.Differences[0]
+		require.Equal(t, ReplayDifferenceFailurePolicy, difference.Reason)
+		require.Equal(t, "e8", difference.EventID)
+		require.Equal(t, RingStateReleaseRollbackRequested, difference.ExpectedState)
+	})
+
+	t.Run("rollback installation group failure policy", func(t *testing.T) {
+		report, err := ReplayEvents([]*Ring{{ID: "ring1", FailurePolicy: FailurePolicyRollbackInstallationGroup}}, release, nil)
+		require.NoError(t, err)
+		require.Empty(t, report.Differences)
+
+		events := append(append([]*StateChangeEvent{}, release[:7]...),
+			&StateChangeEvent{ID: "e10", ResourceType: TypeInstallationGroup, ResourceID: "ig1", RingID: "ring1", ReleaseID: "release2", OldState: InstallationGroupReleaseFailed, NewState: InstallationGroupStable, Timestamp: 7500},
+		)
+		report, err = ReplayEvents([]*Ring{{ID: "ring1", FailurePolicy: FailurePolicyRollbackInstallationGroup}}, events, nil)
+		require.NoError(t, err)
+		require.Len(t, report.Differences, 2)
+		require.Equal(t, ReplayDifferenceStateMachine, report.Differences[0].Reason)
+		require.Equal(t, ReplayDifferenceFailurePolicy, report.Differences[1].Reason)
+		require.Equal(t, InstallationGroupReleaseRollbackRequested, report.Differences[1].ExpectedState)
+	})
+
+	t.Run("max release duration", func(t *testing.T) {
+		report, err := ReplayEvents([]*Ring{{ID: "ring1", MaxReleaseDuration: 2}}, release, nil)
+		require.NoError(t, err)
+		require.Empty(t, report.Differences)
+
+		events := append(append([]*StateChangeEvent{}, release[:5]...),
+			&StateChangeEvent{ID: "e10", ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", ReleaseID: "release2", OldState: RingStateReleaseInProgress, NewState: RingStateSoakingRequested, Timestamp: 10000},
+		)
+		report, err = ReplayEvents([]*Ring{{ID: "ring1", MaxReleaseDuration: 2}}, events, nil)
+		require.NoError(t, err)
+		require.Len(t, report.Differences, 1)
+		require.Equal(t, ReplayDifferenceMaxReleaseDuration, report.Differences[0].Reason)
+		require.Equal(t, RingStateReleaseFailed, report.Differences[0].ExpectedState)
+	})
+
+	t.Run("policy", func(t *testing.T) {
+		var inputs []*PolicyInput
+		evaluate := func(input *PolicyInput) (*PolicyDecision, error) {
+			inputs = append(inputs, input)
+			switch input.State {
+			case RingStateReleasePending:
+				return &PolicyDecision{Allow: false, Reason: "outside business hours"}, nil
+			case RingStateReleaseInProgress:
+				return nil, errors.New("policy unavailable")
+			}
+			return &PolicyDecision{Allow: true}, nil
+		}
+
+		report, err := ReplayEvents([]*Ring{{ID: "ring1"}}, release, evaluate)
+		require.NoError(t, err)
+		require.True(t, report.PolicyEvaluated)
+		require.Empty(t, inputs)
+		require.Empty(t, report.Differences)
+
+		report, err = ReplayEvents([]*Ring{{ID: "ring1", Protected: true}}, release, evaluate)
+		require.NoError(t, err)
+		require.Len(t, inputs, 4)
+		require.Equal(t, PolicyActionTransition, inputs[0].Action)
+		require.Equal(t, "1970-01-01T00:00:02Z", inputs[0].Time)
+		require.Equal(t, RingStateCreationRequested, inputs[0].Ring.State)
+		require.Len(t, report.Differences, 2)
+		require.Equal(t, ReplayDifferencePolicy, report.Differences[0].Reason)
+		require.Equal(t, "e4", report.Differences[0].EventID)
+		require.Equal(t, RingStateReleasePending, report.Differences[0].ExpectedState)
+		require.Contains(t, report.Differences[0].Message, "outside business hours")
+		require.Equal(t, "e8", report.Differences[1].EventID)
+		require.Contains(t, report.Differences[1].Message, "policy unavailable")
+	})
+}
+
+func TestReplayReportFromReader(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		report, err := ReplayReportFromReader(bytes.NewReader([]byte("")))
+		require.NoError(t, err)
+		require.Equal(t, &ReplayReport{}, report)
+	})
+
+	t.Run("report", func(t *testing.T) {
+		expected := &ReplayReport{
+			StateMachineVersion: CurrentStateMachineVersion,
+			Replayed:            1,
+			Differences:         []*ReplayDifference{{Reason: ReplayDifferencePolicy, EventID: "event1"}},
+		}
+		data, err := json.Marshal(expected)
+		require.NoError(t, err)
+
+		report, err := ReplayReportFromReader(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, expected, report)
+	})
+}
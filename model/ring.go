@@ -5,27 +5,167 @@
 package model
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"io"
+	"strings"
 )
 
 // Ring represents a deployment ring.
 type Ring struct {
-	ID                 string
-	Name               string
-	Priority           int
-	SoakTime           int
-	State              string
-	Provisioner        string
-	ActiveReleaseID    string
-	DesiredReleaseID   string
-	CreateAt           int64
-	DeleteAt           int64
-	ReleaseAt          int64
-	InstallationGroups []*InstallationGroup `json:"installationGroups,omitempty"`
-	APISecurityLock    bool
-	LockAcquiredBy     *string
-	LockAcquiredAt     int64
+	ID               string
+	Name             string
+	Priority         int
+	SoakTime         int
+	State            string
+	Provisioner      string
+	ActiveReleaseID  string
+	DesiredReleaseID string
+	CreateAt         int64
+	DeleteAt         int64
+	ReleaseAt        int64
+	ReleaseStartAt   int64
+	// ReleaseImpactInstallations and ReleaseImpactCustomers summarize the
+	// impact of the current release, as reported by the provisioner when the
+	// release was requested.
+	ReleaseImpactInstallations int64
+	ReleaseImpactCustomers     int64
+	InstallationGroups         []*InstallationGroup `json:"installationGroups,omitempty"`
+	// RollbackSnapshotID references the snapshot of the release that was
+	// active before the current one, to roll back to.
+	RollbackSnapshotID string
+	// DeletionScheduledAt is the time, in nanoseconds, after which a ring
+	// pending deletion is deleted.
+	DeletionScheduledAt int64
+	// PreDeletionState is the state the ring was in when its deletion was
+	// scheduled, which it returns to if the deletion is cancelled.
+	PreDeletionState string `json:",omitempty"`
+	// ReleaseScheduledAt is the time, in nanoseconds, before which the
+	// pending release of the ring does not start. It is cleared once the
+	// release starts.
+	ReleaseScheduledAt int64 `json:",omitempty"`
+	// PausedState is the state the release of a paused ring was paused in,
+	// which it returns to once resumed. Rings paused before their release
+	// started have none, and return to release-pending.
+	PausedState string `json:",omitempty"`
+	// PausedAt is the time, in nanoseconds, the release of the ring was
+	// paused.
+	PausedAt int64 `json:",omitempty"`
+	// ReleaseSoakTime is the soak time, in seconds, resolved for the current
+	// release when it was requested.
+	ReleaseSoakTime int
+	Annotations     Annotations `json:",omitempty"`
+	// Metadata is an opaque JSON object included verbatim in the webhook
+	// payloads and events of the ring and its installation groups.
+	Metadata Metadata `json:",omitempty"`
+	// NotificationEmails are notified by email when a release of the ring
+	// starts, completes or fails.
+	NotificationEmails NotificationEmails `json:",omitempty"`
+	// NotificationLanguage is the language the release notifications of the
+	// ring are emailed in, when a notification template of that language
+	// exists. See NotificationTemplate.
+	NotificationLanguage string `json:",omitempty"`
+	// JiraProject is the key of the Jira project an issue is created in for
+	// every release of the ring, if any.
+	JiraProject string `json:",omitempty"`
+	// JiraIssueKey is the Jira issue tracking the current or last release.
+	JiraIssueKey string `json:",omitempty"`
+	// OwnerTeam, SlackChannel and EscalationPolicy identify the team owning
+	// the ring and how to reach it, and are included in release
+	// notifications and failure alerts.
+	OwnerTeam        string `json:",omitempty"`
+	SlackChannel     string `json:",omitempty"`
+	EscalationPolicy string `json:",omitempty"`
+	// TenantID is the tenant that created the ring, whose quotas the ring
+	// counts against.
+	TenantID string `json:",omitempty"`
+	// InstallationGroupPolicy decides what happens to the installation groups
+	// registered or removed while the ring has a release in progress, either
+	// InstallationGroupPolicyQueue or InstallationGroupPolicyJoin.
+	InstallationGroupPolicy string `json:",omitempty"`
+	// ForceApprovalWindow, when set, requires forced releases and API
+	// unlocks of the ring to be confirmed by a second token within this
+	// many seconds before taking effect.
+	ForceApprovalWindow int `json:",omitempty"`
+	// FailurePolicy decides what happens when the release or soak of an
+	// installation group of the ring fails: FailurePolicyHalt,
+	// FailurePolicyRollbackRing, FailurePolicyRollbackInstallationGroup or
+	// FailurePolicyContinue. See CurrentFailurePolicy.
+	FailurePolicy string `json:",omitempty"`
+	// AutoRollback, when set, rolls back the ring once the release or soak of
+	// one of its installation groups fails, for rings without a failure
+	// policy. See CurrentFailurePolicy.
+	AutoRollback bool `json:",omitempty"`
+	// SoakWindows, when set, limit the soak time of the releases of the ring
+	// and its installation groups to the time spent within them.
+	SoakWindows TimeWindows `json:",omitempty"`
+	// ReleaseWindows, when set, limit the start of the releases of the ring
+	// to the time within them.
+	ReleaseWindows TimeWindows `json:",omitempty"`
+	// DependsOn are the IDs of the rings released before the ring. Rings
+	// with dependencies, or depended on, are released along the dependency
+	// graph instead of by priority. See RingOrderedByDependencies.
+	DependsOn RingDependencies `json:",omitempty"`
+	// MaxConcurrency is the number of installation groups of the ring
+	// released at once. See CurrentMaxConcurrency.
+	MaxConcurrency int `json:",omitempty"`
+	// MaxPerFailureDomain is the number of installation groups of the ring
+	// in the same failure domain released at once. See
+	// CurrentMaxPerFailureDomain.
+	MaxPerFailureDomain int `json:",omitempty"`
+	// MaxReleaseDuration, when set, is the time, in seconds, a release of the
+	// ring may run for across all of its installation groups before it is
+	// failed following the failure policy of the ring. See
+	// ReleaseDeadlineExceeded.
+	MaxReleaseDuration int `json:",omitempty"`
+	// MaintenanceConflict describes the infrastructure maintenance the
+	// pending release of the ring was last found to overlap, or why it could
+	// not be checked, which holds the release. See MaintenanceConflictQuery.
+	MaintenanceConflict string `json:",omitempty"`
+	// BlockedBy is the ring the release of the ring waits for while in
+	// RingStateReleaseBlocked, and BlockedReason describes why. Both are
+	// cleared once the supervisors move the ring on.
+	BlockedBy     string `json:",omitempty"`
+	BlockedReason string `json:",omitempty"`
+	// FailureIssueURL is the issue opened once the ring failed repeated
+	// releases, at FailureIssueAt, in nanoseconds. Both are cleared once a
+	// release of the ring completes. See RepeatedFailures.
+	FailureIssueURL string `json:",omitempty"`
+	FailureIssueAt  int64  `json:",omitempty"`
+	// EstimatedCompletionAt is the estimated time, in milliseconds, at which
+	// the release in progress completes. It is computed when the ring is
+	// fetched and is not stored.
+	EstimatedCompletionAt int64 `json:",omitempty"`
+	// Capacity sums the installations, customers and capacity targets
+	// recorded on the installation groups of the ring. Like
+	// EstimatedCompletionAt, it is computed when the ring is fetched.
+	Capacity *RingCapacity `json:",omitempty"`
+	// AsOf is the time, in milliseconds, the ring was reconstructed at when
+	// fetched as of a past time. See RingsAsOf. It is not stored.
+	AsOf int64 `json:",omitempty"`
+	// Notes are the notes left by operators on the ring, oldest first. They
+	// are fetched along with a single ring and are not stored with it.
+	Notes []*Note `json:",omitempty"`
+	// WorkPriority orders the rings pending work: the supervisors work on
+	// higher priorities first, then on older rings. It is only changed by
+	// rescheduling the work of the ring.
+	WorkPriority int `json:",omitempty"`
+	// Protected rings cannot be deleted nor force released, even by
+	// admins, until their protection is removed. See RingProtectionChange.
+	Protected bool `json:",omitempty"`
+	// Rehearsal rings run their releases through the whole workflow against
+	// a fake provisioner, never touching real installations, with their
+	// soaks accelerated RehearsalSpeedup times. Both are set when the ring
+	// is created and never change. See CurrentRehearsalSpeedup.
+	Rehearsal        bool `json:",omitempty"`
+	RehearsalSpeedup int  `json:",omitempty"`
+	// StateMachineVersion is the version of the transition rules the ring
+	// follows. See CurrentStateMachineVersion.
+	StateMachineVersion int
+	APISecurityLock     bool
+	LockAcquiredBy      *string
+	LockAcquiredAt      int64
 }
 
 // RingRelease stores information neeeded for a ring release.
@@ -35,6 +175,129 @@ type RingRelease struct {
 	Version  string
 	CreateAt int64
 	Force    bool
+	// SoakTime overrides, in seconds, the soak time of every ring and
+	// installation group the release is rolled out to.
+	SoakTime int `json:",omitempty"`
+	// Type is the release type, one of the ReleaseType constants.
+	Type string
+	// Parameters overrides the release parameters of some installation
+	// groups.
+	Parameters ReleaseParameters `json:",omitempty"`
+	// Checksum is the SHA-256 checksum of the content of the release, as
+	// computed by ComputeChecksum.
+	Checksum string `json:",omitempty"`
+	// Immutable is set once the release is applied to a ring, after which
+	// its content can no longer change.
+	Immutable bool
+	// Links reference the pull requests, builds, incidents and change
+	// tickets of the release. They are not part of its content, so they can
+	// be managed after the release is applied.
+	Links ReleaseLinks `json:",omitempty"`
+	// Labels tag the release, such as security-patch. Like links, they are
+	// not part of its content.
+	Labels ReleaseLabels `json:",omitempty"`
+	// Notes are the notes left by operators on the release, oldest first.
+	// They are fetched along with a single release and are not stored with
+	// it.
+	Notes []*Note `json:",omitempty"`
+	// DatabaseSnapshots are the snapshots of the databases of the
+	// installations taken before their installation groups were released,
+	// oldest first. Like notes, they are fetched along with a single
+	// release.
+	DatabaseSnapshots []*DatabaseSnapshot `json:",omitempty"`
+}
+
+// ComputeChecksum returns the hex-encoded SHA-256 checksum of the content of
+// the release, that is every field but its ID, creation time, checksum and
+// immutability.
+func (r *RingRelease) ComputeChecksum() (string, error) {
+	// Releases without parameters are stored with an empty set of them.
+	parameters := r.Parameters
+	if len(parameters) == 0 {
+		parameters = nil
+	}
+	content, err := json.Marshal(struct {
+		Image      string
+		Version    string
+		Force      bool
+		SoakTime   int
+		Type       string
+		Parameters ReleaseParameters
+	}{r.Image, r.Version, r.Force, r.SoakTime, r.Type, parameters})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// VerifyChecksum returns whether the content of the release matches its
+// checksum.
+func (r *RingRelease) VerifyChecksum() bool {
+	checksum, err := r.ComputeChecksum()
+	return err == nil && r.Checksum != "" && checksum == r.Checksum
+}
+
+const (
+	// ReleaseTypeStandard is a regular release.
+	ReleaseTypeStandard = "standard"
+	// ReleaseTypeHotfix is an urgent release, soaked for a shorter time.
+	ReleaseTypeHotfix = "hotfix"
+	// ReleaseTypeRollback is a release back to a previous version.
+	ReleaseTypeRollback = "rollback"
+)
+
+// ValidReleaseType returns whether the given release type is known.
+func ValidReleaseType(releaseType string) bool {
+	switch releaseType {
+	case ReleaseTypeStandard, ReleaseTypeHotfix, ReleaseTypeRollback:
+		return true
+	}
+	return false
+}
+
+// IsHotfix returns whether the release is a hotfix.
+func (r *RingRelease) IsHotfix() bool {
+	return r.Type == ReleaseTypeHotfix
+}
+
+// RingReleaseSnapshot is an immutable copy of a release that was active on a
+// ring, recorded when a newer release replaced it. It guarantees a rollback
+// target exists even if the original ring release is pruned.
+type RingReleaseSnapshot struct {
+	ID              string
+	RingID          string
+	ReleaseID       string
+	Image           string
+	Version         string
+	Force           bool
+	ReleaseCreateAt int64
+	CreateAt        int64
+}
+
+// ReleaseImpact summarizes the installations and customers affected by a
+// ring release.
+type ReleaseImpact struct {
+	Installations int64
+	Customers     int64
+}
+
+// Contacts summarizes the owner team, Slack channel and escalation policy of
+// the ring for alerts, or returns an empty string if none is set.
+func (a *Ring) Contacts() string {
+	var contacts []string
+	if a.OwnerTeam != "" {
+		contacts = append(contacts, "owner team "+a.OwnerTeam)
+	}
+	if a.SlackChannel != "" {
+		contacts = append(contacts, "Slack "+a.SlackChannel)
+	}
+	if a.EscalationPolicy != "" {
+		contacts = append(contacts, "escalation policy "+a.EscalationPolicy)
+	}
+
+	return strings.Join(contacts, ", ")
 }
 
 // Clone returns a deep copy the ring.
@@ -64,6 +327,18 @@ func RingFromReader(reader io.Reader) (*Ring, error) {
 	return &ring, nil
 }
 
+// RingReleaseSnapshotFromReader decodes a json-encoded ring release snapshot from the given io.Reader.
+func RingReleaseSnapshotFromReader(reader io.Reader) (*RingReleaseSnapshot, error) {
+	snapshot := RingReleaseSnapshot{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&snapshot)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
 // RingsFromReader decodes a json-encoded list of rings from the given io.Reader.
 func RingsFromReader(reader io.Reader) ([]*Ring, error) {
 	rings := []*Ring{}
@@ -95,4 +370,17 @@ type RingFilter struct {
 	Page           int
 	PerPage        int
 	IncludeDeleted bool
+	// Name, when set, restricts the rings to those with the given name.
+	Name string
+	// TenantID, when set, restricts the rings to those of the given tenant.
+	TenantID string
+}
+
+// RingReleaseFilter describes the parameters used to constrain a set of ring
+// releases.
+type RingReleaseFilter struct {
+	// Label, when set, restricts the releases to those with the given label.
+	Label   string
+	Page    int
+	PerPage int
 }
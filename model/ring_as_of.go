@@ -0,0 +1,96 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+// RingsAsOf reconstructs, from their state change events, the rings that
+// existed at the given time in milliseconds, with the states their rings and
+// installation groups were in and the releases they ran at that time. The
+// events must be in chronological order, and those after the given time are
+// ignored.
+//
+// The returned rings are copies: only their state, active and desired
+// releases, and those of their installation groups, are reconstructed, and
+// every other field, including which installation groups belong to the
+// ring, is current. Rings and installation groups without events up to the
+// given time, such as those whose events were pruned, keep an empty state.
+func RingsAsOf(rings []*Ring, events []*StateChangeEvent, asOf int64) []*Ring {
+	ringsAsOf := []*Ring{}
+	ringsByID := make(map[string]*Ring)
+	installationGroupsByID := make(map[string]*InstallationGroup)
+	for _, ring := range rings {
+		if ring.CreateAt > asOf || (ring.DeleteAt != 0 && ring.DeleteAt <= asOf) {
+			continue
+		}
+
+		ringAsOf := *ring
+		ringAsOf.State = ""
+		ringAsOf.ActiveReleaseID = ""
+		ringAsOf.DesiredReleaseID = ""
+		ringAsOf.AsOf = asOf
+		ringAsOf.InstallationGroups = nil
+		for _, installationGroup := range ring.InstallationGroups {
+			installationGroupAsOf := *installationGroup
+			installationGroupAsOf.State = ""
+			installationGroupAsOf.ActiveReleaseID = ""
+			installationGroupAsOf.PreviousReleaseID = ""
+			ringAsOf.InstallationGroups = append(ringAsOf.InstallationGroups, &installationGroupAsOf)
+			installationGroupsByID[ring.ID+"/"+installationGroup.ID] = &installationGroupAsOf
+		}
+
+		ringsAsOf = append(ringsAsOf, &ringAsOf)
+		ringsByID[ring.ID] = &ringAsOf
+	}
+
+	for _, event := range events {
+		if event.Timestamp > asOf {
+			break
+		}
+		ring := ringsByID[event.RingID]
+		if ring == nil {
+			continue
+		}
+
+		switch event.ResourceType {
+		case TypeRing:
+			ring.State = event.NewState
+			ring.DesiredReleaseID = event.ReleaseID
+			if activatesRingRelease(event) {
+				ring.ActiveReleaseID = event.ReleaseID
+			}
+		case TypeInstallationGroup:
+			installationGroup := installationGroupsByID[ring.ID+"/"+event.ResourceID]
+			if installationGroup == nil {
+				continue
+			}
+			installationGroup.State = event.NewState
+			switch {
+			case event.NewState == InstallationGroupReleaseRollbackComplete:
+				// Installation groups are rolled back to the active release
+				// of their ring.
+				installationGroup.ActiveReleaseID = ring.ActiveReleaseID
+			case event.NewState == InstallationGroupStable && event.OldState != InstallationGroupReleaseRollbackComplete && event.ReleaseID != installationGroup.ActiveReleaseID:
+				installationGroup.PreviousReleaseID = installationGroup.ActiveReleaseID
+				installationGroup.ActiveReleaseID = event.ReleaseID
+			}
+		}
+	}
+
+	return ringsAsOf
+}
+
+// activatesRingRelease returns whether the ring state change event completes
+// the release of the ring, making its desired release the active one.
+func activatesRingRelease(event *StateChangeEvent) bool {
+	if event.NewState != RingStateStable {
+		return false
+	}
+
+	switch event.OldState {
+	case RingStateCreationRequested, RingStateReleaseInProgress, RingStateSoakingRequested:
+		return true
+	}
+
+	return false
+}
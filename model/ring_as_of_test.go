@@ -0,0 +1,86 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingsAsOf(t *testing.T) {
+	rings := []*Ring{
+		{
+			ID:               "ring1",
+			State:            RingStateReleaseRollbackRequested,
+			ActiveReleaseID:  "release2",
+			DesiredReleaseID: "release3",
+			CreateAt:         100,
+			InstallationGroups: []*InstallationGroup{
+				{ID: "ig1", State: InstallationGroupReleaseRollbackRequested, ActiveReleaseID: "release3", PreviousReleaseID: "release2"},
+			},
+		},
+		{ID: "ring2", State: RingStateStable, CreateAt: 500},
+		{ID: "ring3", State: RingStateDeleted, CreateAt: 100, DeleteAt: 250},
+	}
+
+	events := []*StateChangeEvent{
+		{ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", ReleaseID: "release1", OldState: RingStateCreationRequested, NewState: RingStateStable, Timestamp: 110},
+		{ResourceType: TypeInstallationGroup, ResourceID: "ig1", RingID: "ring1", ReleaseID: "release1", OldState: InstallationGroupReleaseRequested, NewState: InstallationGroupStable, Timestamp: 120},
+		{ResourceType: TypeRing, ResourceID: "ring3", RingID: "ring3", ReleaseID: "release1", OldState: RingStateCreationRequested, NewState: RingStateStable, Timestamp: 130},
+		{ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", ReleaseID: "release2", OldState: RingStateStable, NewState: RingStateReleaseInProgress, Timestamp: 200},
+		{ResourceType: TypeInstallationGroup, ResourceID: "ig1", RingID: "ring1", ReleaseID: "release2", OldState: InstallationGroupStable, NewState: InstallationGroupReleaseRequested, Timestamp: 210},
+		{ResourceType: TypeInstallationGroup, ResourceID: "ig1", RingID: "ring1", ReleaseID: "release2", OldState: InstallationGroupReleaseRequested, NewState: InstallationGroupStable, Timestamp: 220},
+		{ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", ReleaseID: "release2", OldState: RingStateReleaseInProgress, NewState: RingStateSoakingRequested, Timestamp: 230},
+		{ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", ReleaseID: "release2", OldState: RingStateSoakingRequested, NewState: RingStateStable, Timestamp: 300},
+		{ResourceType: TypeRing, ResourceID: "ring1", RingID: "ring1", ReleaseID: "release3", OldState: RingStateStable, NewState: RingStateReleaseInProgress, Timestamp: 400},
+		{ResourceType: TypeInstallationGroup, ResourceID: "ig1", RingID: "ring1", ReleaseID: "release3", OldState: InstallationGroupReleaseRequested, NewState: InstallationGroupStable, Timestamp: 410},
+		{ResourceType: TypeInstallationGroup, ResourceID: "ig1", RingID: "ring1", ReleaseID: "release3", OldState: InstallationGroupReleaseRollbackRequested, NewState: InstallationGroupReleaseRollbackComplete, Timestamp: 420},
+	}
+
+	t.Run("before any ring", func(t *testing.T) {
+		require.Empty(t, RingsAsOf(rings, events, 50))
+	})
+
+	t.Run("during a release", func(t *testing.T) {
+		ringsAsOf := RingsAsOf(rings, events, 230)
+		require.Len(t, ringsAsOf, 2)
+
+		ring := ringsAsOf[0]
+		require.Equal(t, "ring1", ring.ID)
+		require.Equal(t, int64(230), ring.AsOf)
+		require.Equal(t, RingStateSoakingRequested, ring.State)
+		require.Equal(t, "release1", ring.ActiveReleaseID)
+		require.Equal(t, "release2", ring.DesiredReleaseID)
+		require.Equal(t, InstallationGroupStable, ring.InstallationGroups[0].State)
+		require.Equal(t, "release2", ring.InstallationGroups[0].ActiveReleaseID)
+		require.Equal(t, "release1", ring.InstallationGroups[0].PreviousReleaseID)
+
+		require.Equal(t, "ring3", ringsAsOf[1].ID)
+		require.Equal(t, RingStateStable, ringsAsOf[1].State)
+	})
+
+	t.Run("after a rollback", func(t *testing.T) {
+		ringsAsOf := RingsAsOf(rings, events, 1000)
+		require.Len(t, ringsAsOf, 2)
+
+		ring := ringsAsOf[0]
+		require.Equal(t, RingStateReleaseInProgress, ring.State)
+		require.Equal(t, "release2", ring.ActiveReleaseID)
+		require.Equal(t, "release3", ring.DesiredReleaseID)
+		require.Equal(t, InstallationGroupReleaseRollbackComplete, ring.InstallationGroups[0].State)
+		require.Equal(t, "release2", ring.InstallationGroups[0].ActiveReleaseID)
+
+		require.Equal(t, "ring2", ringsAsOf[1].ID)
+		require.Empty(t, ringsAsOf[1].State)
+	})
+
+	t.Run("rings are not modified", func(t *testing.T) {
+		RingsAsOf(rings, events, 230)
+		require.Equal(t, RingStateReleaseRollbackRequested, rings[0].State)
+		require.Equal(t, InstallationGroupReleaseRollbackRequested, rings[0].InstallationGroups[0].State)
+		require.Zero(t, rings[0].AsOf)
+	})
+}
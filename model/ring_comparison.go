@@ -0,0 +1,117 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// RingComparison lists the differences between the active release, soak
+// configuration and labels of two rings, such as a staging and a production
+// ring, to check they are configured alike before promoting a release.
+type RingComparison struct {
+	GeneratedAt int64
+	RingA       *RingComparisonSide
+	RingB       *RingComparisonSide
+	// Identical is set when the rings have no differences.
+	Identical   bool
+	Differences []*RingDifference
+}
+
+// RingComparisonSide is one of the rings compared.
+type RingComparisonSide struct {
+	ID            string
+	Name          string
+	ActiveImage   string
+	ActiveVersion string
+	SoakTime      int
+	SoakWindows   TimeWindows `json:",omitempty"`
+	// Labels are the annotations of the ring, which label selectors match.
+	Labels Annotations `json:",omitempty"`
+}
+
+// RingDifference is a setting whose value differs between two rings. An
+// unset value is empty.
+type RingDifference struct {
+	// Field is the name of the setting, such as SoakTime, or Labels.<name>
+	// for a label.
+	Field string
+	A     string
+	B     string
+}
+
+// newRingComparisonSide returns the side of a comparison of the given ring
+// with its active release, if any.
+func newRingComparisonSide(ring *Ring, active *RingRelease) *RingComparisonSide {
+	if active == nil {
+		active = &RingRelease{}
+	}
+
+	return &RingComparisonSide{
+		ID:            ring.ID,
+		Name:          ring.Name,
+		ActiveImage:   active.Image,
+		ActiveVersion: active.Version,
+		SoakTime:      ring.SoakTime,
+		SoakWindows:   ring.SoakWindows,
+		Labels:        ring.Annotations,
+	}
+}
+
+// BuildRingComparison compares the two given rings with their active
+// releases, if any. Label differences are sorted by label name after the
+// other differences.
+func BuildRingComparison(ringA *Ring, activeA *RingRelease, ringB *Ring, activeB *RingRelease, now int64) *RingComparison {
+	comparison := &RingComparison{
+		GeneratedAt: now,
+		RingA:       newRingComparisonSide(ringA, activeA),
+		RingB:       newRingComparisonSide(ringB, activeB),
+		Differences: []*RingDifference{},
+	}
+	a, b := comparison.RingA, comparison.RingB
+
+	compare := func(field, valueA, valueB string) {
+		if valueA != valueB {
+			comparison.Differences = append(comparison.Differences, &RingDifference{Field: field, A: valueA, B: valueB})
+		}
+	}
+	compare("ActiveImage", a.ActiveImage, b.ActiveImage)
+	compare("ActiveVersion", a.ActiveVersion, b.ActiveVersion)
+	compare("SoakTime", strconv.Itoa(a.SoakTime), strconv.Itoa(b.SoakTime))
+	compare("SoakWindows", a.SoakWindows.String(), b.SoakWindows.String())
+
+	var names []string
+	for name := range a.Labels {
+		names = append(names, name)
+	}
+	for name := range b.Labels {
+		if _, ok := a.Labels[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		compare("Labels."+name, a.Labels[name], b.Labels[name])
+	}
+
+	comparison.Identical = len(comparison.Differences) == 0
+
+	return comparison
+}
+
+// RingComparisonFromReader decodes a json-encoded ring comparison from the
+// given io.Reader.
+func RingComparisonFromReader(reader io.Reader) (*RingComparison, error) {
+	comparison := &RingComparison{}
+	err := json.NewDecoder(reader).Decode(comparison)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return comparison, nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRingComparison(t *testing.T) {
+	release := &RingRelease{Image: "mattermost/mattermost-enterprise-edition", Version: "9.5.0"}
+	staging := &Ring{
+		ID:          "staging",
+		Name:        "staging",
+		SoakTime:    3600,
+		SoakWindows: TimeWindows{{Start: "09:00", End: "17:00", TimeZone: "Europe/Berlin"}},
+		Annotations: Annotations{"team": "cloud", "env": "staging"},
+	}
+
+	t.Run("identical", func(t *testing.T) {
+		production := *staging
+		production.ID = "production"
+
+		comparison := BuildRingComparison(staging, release, &production, release, 1000)
+		require.Equal(t, int64(1000), comparison.GeneratedAt)
+		require.True(t, comparison.Identical)
+		require.Empty(t, comparison.Differences)
+		require.Equal(t, "9.5.0", comparison.RingB.ActiveVersion)
+	})
+
+	t.Run("different", func(t *testing.T) {
+		production := &Ring{
+			ID:          "production",
+			Name:        "production",
+			SoakTime:    7200,
+			Annotations: Annotations{"team": "cloud", "env": "prod", "tier": "1"},
+		}
+
+		comparison := BuildRingComparison(staging, release, production, nil, 1000)
+		require.False(t, comparison.Identical)
+		require.Equal(t, []*RingDifference{
+			{Field: "ActiveImage", A: "mattermost/mattermost-enterprise-edition"},
+			{Field: "ActiveVersion", A: "9.5.0"},
+			{Field: "SoakTime", A: "3600", B: "7200"},
+			{Field: "SoakWindows", A: "09:00-17:00 Europe/Berlin"},
+			{Field: "Labels.env", A: "staging", B: "prod"},
+			{Field: "Labels.tier", B: "1"},
+		}, comparison.Differences)
+	})
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxRingDependencies is the maximum number of rings a ring depends on.
+const MaxRingDependencies = 50
+
+// RingDependencies are the IDs of the rings a ring depends on, which complete
+// their releases, soak included, before the ring starts its own.
+type RingDependencies []string
+
+// Validate validates the ring IDs of the dependencies, without checking the
+// rings exist. See ValidateRingDependencies.
+func (d RingDependencies) Validate() error {
+	if len(d) > MaxRingDependencies {
+		return errors.Errorf("cannot depend on more than %d rings", MaxRingDependencies)
+	}
+
+	seen := make(map[string]bool)
+	for _, ringID := range d {
+		if ringID == "" {
+			return errors.New("ring dependency cannot be empty")
+		}
+		if seen[ringID] {
+			return errors.Errorf("ring dependency %s is repeated", ringID)
+		}
+		seen[ringID] = true
+	}
+
+	return nil
+}
+
+// Contains returns whether the given ring is one of the dependencies.
+func (d RingDependencies) Contains(ringID string) bool {
+	for _, id := range d {
+		if id == ringID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Value implements driver.Valuer, storing the ring IDs as JSON.
+func (d RingDependencies) Value() (driver.Value, error) {
+	if len(d) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal([]string(d))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal ring dependencies")
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner, loading the ring IDs from JSON.
+func (d *RingDependencies) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*d = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.Errorf("cannot scan %T into ring dependencies", src)
+	}
+	if len(data) == 0 {
+		*d = nil
+		return nil
+	}
+
+	var dependencies RingDependencies
+	if err := json.Unmarshal(data, &dependencies); err != nil {
+		return errors.Wrap(err, "failed to unmarshal ring dependencies")
+	}
+	if len(dependencies) == 0 {
+		dependencies = nil
+	}
+	*d = dependencies
+
+	return nil
+}
+
+// ValidateMaxConcurrency validates the max concurrency of a ring.
+func ValidateMaxConcurrency(maxConcurrency int) error {
+	if maxConcurrency < 0 {
+		return errors.New("max concurrency cannot be negative")
+	}
+
+	return nil
+}
+
+// CurrentMaxConcurrency returns the number of installation groups of the ring
+// released at once, defaulting to one.
+func (r *Ring) CurrentMaxConcurrency() int {
+	if r.MaxConcurrency > 0 {
+		return r.MaxConcurrency
+	}
+
+	return 1
+}
+
+// RingOrderedByDependencies returns whether the given ring is released along
+// the dependency graph of the given rings, that is whether it depends on
+// other rings or other rings depend on it. Such rings are only ordered by
+// their dependencies, and release alongside the other rings of the graph
+// they do not depend on, while the other rings are released one at a time
+// by priority.
+func RingOrderedByDependencies(ring *Ring, rings []*Ring) bool {
+	if len(ring.DependsOn) > 0 {
+		return true
+	}
+	for _, rg := range rings {
+		if rg.ID != ring.ID && rg.DeleteAt == 0 && rg.DependsOn.Contains(ring.ID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateRingDependencies returns an error if the ring with the given ID,
+// empty for a new ring, cannot depend on the given rings: the rings must
+// exist among the given ones, and must not depend on the ring themselves,
+// directly or not.
+func ValidateRingDependencies(ringID string, dependsOn RingDependencies, rings []*Ring) error {
+	if err := dependsOn.Validate(); err != nil {
+		return err
+	}
+
+	ringsByID := make(map[string]*Ring)
+	for _, rg := range rings {
+		if rg.DeleteAt == 0 {
+			ringsByID[rg.ID] = rg
+		}
+	}
+	for _, dependencyID := range dependsOn {
+		if dependencyID == ringID {
+			return errors.New("ring cannot depend on itself")
+		}
+		if ringsByID[dependencyID] == nil {
+			return errors.Errorf("ring dependency %s does not exist", dependencyID)
+		}
+	}
+	if ringID == "" {
+		// Nothing depends on a new ring yet.
+		return nil
+	}
+
+	// Look for a path from the dependencies back to the ring.
+	dependencies := func(id string) RingDependencies {
+		if id == ringID {
+			return dependsOn
+		}
+		return ringsByID[id].DependsOn
+	}
+	visited := make(map[string]bool)
+	var path []string
+	var visit func(id string) bool
+	visit = func(id string) bool {
+		path = append(path, ringName(ringsByID, id))
+		if id == ringID && len(path) > 1 {
+			return true
+		}
+		if !visited[id] {
+			visited[id] = true
+			for _, dependencyID := range dependencies(id) {
+				if ringsByID[dependencyID] != nil && visit(dependencyID) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if visit(ringID) {
+		return errors.Errorf("ring dependencies form a cycle: %s", strings.Join(path, " -> "))
+	}
+
+	return nil
+}
+
+// ringName returns the name of the ring with the given ID, or the ID of an
+// unknown ring.
+func ringName(ringsByID map[string]*Ring, id string) string {
+	if ring := ringsByID[id]; ring != nil {
+		return ring.Name
+	}
+
+	return id
+}
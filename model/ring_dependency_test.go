@@ -0,0 +1,95 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRingDependencies(t *testing.T) {
+	newRing := func(id string, dependsOn ...string) *Ring {
+		return &Ring{ID: id, Name: "ring-" + id, DependsOn: dependsOn}
+	}
+
+	testCases := []struct {
+		description   string
+		ringID        string
+		dependsOn     RingDependencies
+		rings         []*Ring
+		expectedError string
+	}{
+		{
+			description:   "self-dependency",
+			ringID:        "a",
+			dependsOn:     RingDependencies{"a"},
+			rings:         []*Ring{newRing("a")},
+			expectedError: "ring cannot depend on itself",
+		},
+		{
+			description:   "two ring cycle",
+			ringID:        "a",
+			dependsOn:     RingDependencies{"b"},
+			rings:         []*Ring{newRing("a"), newRing("b", "a")},
+			expectedError: "ring dependencies form a cycle: ring-a -> ring-b -> ring-a",
+		},
+		{
+			description:   "three ring cycle",
+			ringID:        "a",
+			dependsOn:     RingDependencies{"b"},
+			rings:         []*Ring{newRing("a"), newRing("b", "c"), newRing("c", "a")},
+			expectedError: "ring dependencies form a cycle: ring-a -> ring-b -> ring-c -> ring-a",
+		},
+		{
+			description: "diamond",
+			ringID:      "a",
+			dependsOn:   RingDependencies{"b", "c"},
+			rings:       []*Ring{newRing("a"), newRing("b", "d"), newRing("c", "d"), newRing("d")},
+		},
+		{
+			description: "no dependencies",
+			ringID:      "a",
+			rings:       []*Ring{newRing("a")},
+		},
+		{
+			description:   "repeated dependency",
+			ringID:        "a",
+			dependsOn:     RingDependencies{"b", "b"},
+			rings:         []*Ring{newRing("a"), newRing("b")},
+			expectedError: "ring dependency b is repeated",
+		},
+		{
+			description:   "unknown ring",
+			ringID:        "a",
+			dependsOn:     RingDependencies{"unknown"},
+			rings:         []*Ring{newRing("a")},
+			expectedError: "ring dependency unknown does not exist",
+		},
+		{
+			description:   "deleted ring",
+			ringID:        "a",
+			dependsOn:     RingDependencies{"b"},
+			rings:         []*Ring{newRing("a"), {ID: "b", Name: "ring-b", DeleteAt: 1}},
+			expectedError: "ring dependency b does not exist",
+		},
+		{
+			description: "new ring",
+			dependsOn:   RingDependencies{"b"},
+			rings:       []*Ring{newRing("b")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := ValidateRingDependencies(tc.ringID, tc.dependsOn, tc.rings)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+const (
+	// InstallationGroupPolicyQueue queues the installation group
+	// registrations and removals of a ring with a release in progress until
+	// the release ends. It is the default policy.
+	InstallationGroupPolicyQueue = "queue"
+	// InstallationGroupPolicyJoin releases the installation groups registered
+	// to a ring while its installation groups are releasing with the release
+	// in progress. Removals are still queued until the release ends.
+	InstallationGroupPolicyJoin = "join"
+)
+
+const (
+	// RingInstallationGroupRegister registers an installation group to a ring.
+	RingInstallationGroupRegister = "register"
+	// RingInstallationGroupDeregister removes an installation group from a ring.
+	RingInstallationGroupDeregister = "deregister"
+)
+
+// RingInstallationGroupChange is a change to the installation groups of a
+// ring, queued while the ring has a release in progress.
+type RingInstallationGroupChange struct {
+	ID                  string
+	RingID              string
+	InstallationGroupID string
+	Action              string
+	CreateAt            int64
+}
+
+// CurrentInstallationGroupPolicy returns the installation group policy of
+// the ring, defaulting to InstallationGroupPolicyQueue.
+func (c *Ring) CurrentInstallationGroupPolicy() string {
+	if c.InstallationGroupPolicy == "" {
+		return InstallationGroupPolicyQueue
+	}
+
+	return c.InstallationGroupPolicy
+}
+
+// HasReleaseInProgress returns whether the ring is in one of the states of a
+// release in progress, during which its installation groups are not changed.
+func (c *Ring) HasReleaseInProgress() bool {
+	for _, state := range AllRingStatesReleaseInProgress {
+		if c.State == state {
+			return true
+		}
+	}
+
+	return false
+}
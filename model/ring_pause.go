@@ -0,0 +1,64 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+// PauseRelease pauses the release of the ring at the given time, in
+// nanoseconds, recording the state to resume it in. It returns false if the
+// release of the ring cannot be paused in its current state.
+func (c *Ring) PauseRelease(now int64) bool {
+	if !c.ValidTransitionState(RingStateReleasePaused) {
+		return false
+	}
+
+	c.PausedState = c.State
+	c.PausedAt = now
+	c.State = RingStateReleasePaused
+
+	return true
+}
+
+// ResumeRelease returns a ring with a paused release to the state it was
+// paused in, at the given time in nanoseconds. The time spent paused does
+// not count towards the soak time of a soaking ring. It returns how long the
+// release was paused, in nanoseconds, and false if it was not paused.
+func (c *Ring) ResumeRelease(now int64) (int64, bool) {
+	if c.State != RingStateReleasePaused {
+		return 0, false
+	}
+
+	state := c.PausedState
+	if state == "" {
+		state = RingStateReleasePending
+	}
+	var pausedFor int64
+	if c.PausedAt > 0 && now > c.PausedAt {
+		pausedFor = now - c.PausedAt
+	}
+	if state == RingStateSoakingRequested {
+		c.ReleaseAt += pausedFor
+	}
+
+	c.State = state
+	c.PausedState = ""
+	c.PausedAt = 0
+
+	return pausedFor, true
+}
+
+// ReleasePausedInFlight returns whether the release of the ring was paused
+// after it started, in which case it still holds back the release of the
+// other rings.
+func (c *Ring) ReleasePausedInFlight() bool {
+	if c.State != RingStateReleasePaused {
+		return false
+	}
+	for _, state := range AllRingStatesReleaseInProgress {
+		if c.PausedState == state {
+			return true
+		}
+	}
+
+	return false
+}
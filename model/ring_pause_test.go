@@ -0,0 +1,75 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingPauseRelease(t *testing.T) {
+	t.Run("pending release", func(t *testing.T) {
+		ring := &Ring{State: RingStateReleasePending}
+		require.True(t, ring.PauseRelease(1000))
+		require.Equal(t, RingStateReleasePaused, ring.State)
+		require.False(t, ring.ReleasePausedInFlight())
+
+		pausedFor, resumed := ring.ResumeRelease(3000)
+		require.True(t, resumed)
+		require.Equal(t, int64(2000), pausedFor)
+		require.Equal(t, RingStateReleasePending, ring.State)
+		require.Empty(t, ring.PausedState)
+		require.Zero(t, ring.PausedAt)
+	})
+
+	t.Run("soaking release", func(t *testing.T) {
+		ring := &Ring{State: RingStateSoakingRequested, ReleaseAt: 500}
+		require.True(t, ring.PauseRelease(1000))
+		require.True(t, ring.ReleasePausedInFlight())
+
+		_, resumed := ring.ResumeRelease(4000)
+		require.True(t, resumed)
+		require.Equal(t, RingStateSoakingRequested, ring.State)
+		require.Equal(t, int64(3500), ring.ReleaseAt)
+	})
+
+	t.Run("release in progress", func(t *testing.T) {
+		ring := &Ring{State: RingStateReleaseInProgress, ReleaseAt: 500}
+		require.True(t, ring.PauseRelease(1000))
+		require.True(t, ring.ReleasePausedInFlight())
+
+		_, resumed := ring.ResumeRelease(4000)
+		require.True(t, resumed)
+		require.Equal(t, RingStateReleaseInProgress, ring.State)
+		require.Equal(t, int64(500), ring.ReleaseAt)
+	})
+
+	t.Run("paused before the state was recorded", func(t *testing.T) {
+		ring := &Ring{State: RingStateReleasePaused}
+		_, resumed := ring.ResumeRelease(4000)
+		require.True(t, resumed)
+		require.Equal(t, RingStateReleasePending, ring.State)
+	})
+
+	t.Run("not pausable", func(t *testing.T) {
+		for _, state := range []string{RingStateStable, RingStateReleaseFailed, RingStateReleaseRollbackRequested, RingStateReleasePaused} {
+			ring := &Ring{State: state}
+			require.False(t, ring.PauseRelease(1000), state)
+			require.Equal(t, state, ring.State)
+		}
+
+		_, resumed := (&Ring{State: RingStateStable}).ResumeRelease(1000)
+		require.False(t, resumed)
+	})
+
+	t.Run("in flight releases require state machine version 2", func(t *testing.T) {
+		ring := &Ring{State: RingStateReleaseInProgress, StateMachineVersion: 1}
+		require.False(t, ring.PauseRelease(1000))
+
+		ring = &Ring{State: RingStateReleasePending, StateMachineVersion: 1}
+		require.True(t, ring.PauseRelease(1000))
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ForceApprovalActionUnprotect is the removal of the protection of a
+	// ring.
+	ForceApprovalActionUnprotect = "unprotect"
+	// maxRingProtectionReasonLength is the maximum length of the reason of a
+	// ring protection change.
+	maxRingProtectionReasonLength = 1024
+)
+
+// RingProtectionChange records the protection of a ring being set or removed,
+// as the audit log of the protection of the ring.
+type RingProtectionChange struct {
+	ID        string
+	RingID    string
+	Protected bool
+	Reason    string
+	// ChangedBy is the ID of the API token that made the change, if any.
+	ChangedBy string `json:",omitempty"`
+	CreateAt  int64
+}
+
+// RingProtectionRequest describes the parameters to protect a ring or remove
+// its protection.
+type RingProtectionRequest struct {
+	// Reason explains the change, and is required to remove the protection.
+	Reason string
+}
+
+// Validate validates the values of a ring protection request.
+func (request *RingProtectionRequest) Validate(protected bool) error {
+	if !protected && request.Reason == "" {
+		return errors.New("a reason is required to remove the protection of a ring")
+	}
+	if len(request.Reason) > maxRingProtectionReasonLength {
+		return errors.Errorf("reason cannot be longer than %d characters", maxRingProtectionReasonLength)
+	}
+
+	return nil
+}
+
+// NewRingProtectionRequestFromReader will create a RingProtectionRequest from
+// an io.Reader with JSON data.
+func NewRingProtectionRequestFromReader(reader io.Reader) (*RingProtectionRequest, error) {
+	var request RingProtectionRequest
+	err := json.NewDecoder(reader).Decode(&request)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode ring protection request")
+	}
+
+	return &request, nil
+}
+
+// RingProtectionChangesFromReader decodes a json-encoded list of ring
+// protection changes from the given io.Reader.
+func RingProtectionChangesFromReader(reader io.Reader) ([]*RingProtectionChange, error) {
+	changes := []*RingProtectionChange{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&changes)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return changes, nil
+}
@@ -15,13 +15,59 @@ import (
 
 // CreateRingRequest specifies the parameters for a new ring.
 type CreateRingRequest struct {
-	Name              string             `json:"name,omitempty"`
-	Priority          int                `json:"priority,omitempty"`
-	InstallationGroup *InstallationGroup `json:"installationGroup,omitempty"`
-	SoakTime          int                `json:"soakTime,omitempty"`
-	Image             string             `json:"image,omitempty"`
-	Version           string             `json:"version,omitempty"`
-	APISecurityLock   bool               `json:"apiSecurityLock,omitempty"`
+	Name               string             `json:"name,omitempty"`
+	Priority           int                `json:"priority,omitempty"`
+	InstallationGroup  *InstallationGroup `json:"installationGroup,omitempty"`
+	SoakTime           int                `json:"soakTime,omitempty"`
+	Image              string             `json:"image,omitempty"`
+	Version            string             `json:"version,omitempty"`
+	APISecurityLock    bool               `json:"apiSecurityLock,omitempty"`
+	Annotations        Annotations        `json:"annotations,omitempty"`
+	Metadata           Metadata           `json:"metadata,omitempty"`
+	NotificationEmails NotificationEmails `json:"notificationEmails,omitempty"`
+	// NotificationLanguage is the language of the notifications emailed for
+	// the ring. See NotificationTemplate.
+	NotificationLanguage string `json:"notificationLanguage,omitempty"`
+	JiraProject          string `json:"jiraProject,omitempty"`
+	OwnerTeam            string `json:"ownerTeam,omitempty"`
+	SlackChannel         string `json:"slackChannel,omitempty"`
+	EscalationPolicy     string `json:"escalationPolicy,omitempty"`
+	// InstallationGroupPolicy is the installation group policy of the ring,
+	// defaulting to InstallationGroupPolicyQueue.
+	InstallationGroupPolicy string `json:"installationGroupPolicy,omitempty"`
+	// FailurePolicy is the failure policy of the ring, defaulting to
+	// FailurePolicyHalt.
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+	// AutoRollback rolls back the ring when an installation group release
+	// fails, unless a failure policy is set.
+	AutoRollback bool `json:"autoRollback,omitempty"`
+	// ForceApprovalWindow is the time, in seconds, a second token has to
+	// confirm a forced release or API unlock of the ring. Zero disables the
+	// two-person rule.
+	ForceApprovalWindow int `json:"forceApprovalWindow,omitempty"`
+	// SoakWindows, when set, limit the soak time of the releases of the ring
+	// to the time spent within them.
+	SoakWindows TimeWindows `json:"soakWindows,omitempty"`
+	// ReleaseWindows, when set, limit the start of the releases of the ring
+	// to the time within them.
+	ReleaseWindows TimeWindows `json:"releaseWindows,omitempty"`
+	// DependsOn are the IDs of the rings released before the ring.
+	DependsOn RingDependencies `json:"dependsOn,omitempty"`
+	// MaxConcurrency is the number of installation groups of the ring
+	// released at once, defaulting to one.
+	MaxConcurrency int `json:"maxConcurrency,omitempty"`
+	// MaxPerFailureDomain is the number of installation groups of the ring
+	// in the same failure domain released at once, defaulting to one.
+	MaxPerFailureDomain int `json:"maxPerFailureDomain,omitempty"`
+	// MaxReleaseDuration is the time, in seconds, a release of the ring may
+	// run for. Zero means no limit.
+	MaxReleaseDuration int `json:"maxReleaseDuration,omitempty"`
+	// Protected creates the ring protected. See Ring.Protected.
+	Protected bool `json:"protected,omitempty"`
+	// Rehearsal creates a rehearsal ring, whose soaks are accelerated
+	// RehearsalSpeedup times. See Ring.Rehearsal.
+	Rehearsal        bool `json:"rehearsal,omitempty"`
+	RehearsalSpeedup int  `json:"rehearsalSpeedup,omitempty"`
 }
 
 // UpdateRingRequest specifies the parameters to update a ring.
@@ -32,6 +78,49 @@ type UpdateRingRequest struct {
 	Image           string `json:"image,omitempty"`
 	Version         string `json:"version,omitempty"`
 	APISecurityLock bool   `json:"apiSecurityLock,omitempty"`
+	// Annotations, when set, replace the annotations of the ring.
+	Annotations Annotations `json:"annotations,omitempty"`
+	// Metadata, when set, replaces the metadata of the ring.
+	Metadata Metadata `json:"metadata,omitempty"`
+	// NotificationEmails, when set, replace the notification emails of the
+	// ring. An empty list removes them.
+	NotificationEmails *NotificationEmails `json:"notificationEmails,omitempty"`
+	// NotificationLanguage, when set, replaces the notification language of
+	// the ring. An empty language restores the default one.
+	NotificationLanguage *string `json:"notificationLanguage,omitempty"`
+	// JiraProject, when set, replaces the Jira project of the ring. An empty
+	// project disables Jira issues for the ring.
+	JiraProject *string `json:"jiraProject,omitempty"`
+	// OwnerTeam, SlackChannel and EscalationPolicy, when set, replace the
+	// contacts of the ring. An empty value removes them.
+	OwnerTeam        *string `json:"ownerTeam,omitempty"`
+	SlackChannel     *string `json:"slackChannel,omitempty"`
+	EscalationPolicy *string `json:"escalationPolicy,omitempty"`
+	// InstallationGroupPolicy, when set, replaces the installation group
+	// policy of the ring.
+	InstallationGroupPolicy string `json:"installationGroupPolicy,omitempty"`
+	// FailurePolicy, when set, replaces the failure policy of the ring.
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+	// AutoRollback, when set, replaces the auto rollback setting of the ring.
+	AutoRollback *bool `json:"autoRollback,omitempty"`
+	// ForceApprovalWindow, when set, replaces the force approval window of
+	// the ring. Zero disables the two-person rule.
+	ForceApprovalWindow *int `json:"forceApprovalWindow,omitempty"`
+	// SoakWindows and ReleaseWindows, when set, replace the soak and release
+	// windows of the ring. An empty list removes them.
+	SoakWindows    *TimeWindows `json:"soakWindows,omitempty"`
+	ReleaseWindows *TimeWindows `json:"releaseWindows,omitempty"`
+	// DependsOn, when set, replaces the rings the ring depends on. An empty
+	// list removes them.
+	DependsOn *RingDependencies `json:"dependsOn,omitempty"`
+	// MaxConcurrency, when set, replaces the max concurrency of the ring.
+	MaxConcurrency *int `json:"maxConcurrency,omitempty"`
+	// MaxPerFailureDomain, when set, replaces the max per failure domain of
+	// the ring.
+	MaxPerFailureDomain *int `json:"maxPerFailureDomain,omitempty"`
+	// MaxReleaseDuration, when set, replaces the max release duration of the
+	// ring. Zero removes it.
+	MaxReleaseDuration *int `json:"maxReleaseDuration,omitempty"`
 }
 
 // RingReleaseRequest contains metadata related to changing the installed ring state.
@@ -39,6 +128,21 @@ type RingReleaseRequest struct {
 	Image   string
 	Version string
 	Force   bool
+	// SoakTime, when set, overrides the soak time of the rings and
+	// installation groups for this release.
+	SoakTime int
+	// Type is the release type, defaulting to a standard release.
+	Type string
+	// Parameters overrides, by installation group name, the parameters of
+	// the release of the given installation groups.
+	Parameters ReleaseParameters `json:",omitempty"`
+	// Links are added to the links of the release.
+	Links ReleaseLinks `json:",omitempty"`
+	// Labels are added to the labels of the release.
+	Labels ReleaseLabels `json:",omitempty"`
+	// ScheduleAt, when set, is the time, in nanoseconds, before which the
+	// release does not start. The rings wait for it in release-pending.
+	ScheduleAt int64 `json:",omitempty"`
 }
 
 // GetRingsRequest describes the parameters to request a list of rings.
@@ -46,13 +150,16 @@ type GetRingsRequest struct {
 	Page           int
 	PerPage        int
 	IncludeDeleted bool
+	// AsOf, when set, requests the rings as they were at that time, in
+	// milliseconds. See RingsAsOf.
+	AsOf int64
 }
 
-// SetDefaults sets the default values for a ring create request.
-func (request *CreateRingRequest) SetDefaults() {
-	if request.SoakTime == 0 {
-		request.SoakTime = 7200
-	}
+// DeleteRingRequest describes the parameters to request the deletion of a ring.
+type DeleteRingRequest struct {
+	// DeleteAfter is the grace period, in seconds, during which the deletion
+	// can be cancelled. A ring without a grace period is deleted right away.
+	DeleteAfter int
 }
 
 // Validate validates the values of a ring create request.
@@ -60,10 +167,188 @@ func (request *CreateRingRequest) Validate() error {
 	if request.Priority == 0 {
 		return errors.New("Priority cannot be zero")
 	}
+	if err := ValidateName(request.Name); err != nil {
+		return err
+	}
+	if err := ValidateRelease(request.Image, request.Version, request.SoakTime); err != nil {
+		return err
+	}
+	if err := request.Annotations.Validate(); err != nil {
+		return err
+	}
+	if err := request.Metadata.Validate(); err != nil {
+		return err
+	}
+	if err := request.NotificationEmails.Validate(); err != nil {
+		return err
+	}
+	if err := ValidateLanguage(request.NotificationLanguage); err != nil {
+		return err
+	}
+	if err := ValidateJiraProject(request.JiraProject); err != nil {
+		return err
+	}
+	if err := ValidateRingContacts(request.OwnerTeam, request.SlackChannel, request.EscalationPolicy); err != nil {
+		return err
+	}
+	if err := ValidateInstallationGroupPolicy(request.InstallationGroupPolicy); err != nil {
+		return err
+	}
+	if err := ValidateFailurePolicy(request.FailurePolicy); err != nil {
+		return err
+	}
+	if err := ValidateAutoRollback(request.AutoRollback, request.FailurePolicy); err != nil {
+		return err
+	}
+	if err := ValidateForceApprovalWindow(request.ForceApprovalWindow); err != nil {
+		return err
+	}
+	if err := request.SoakWindows.Validate(); err != nil {
+		return errors.Wrap(err, "invalid soak windows")
+	}
+	if err := request.ReleaseWindows.Validate(); err != nil {
+		return errors.Wrap(err, "invalid release windows")
+	}
+	if err := request.DependsOn.Validate(); err != nil {
+		return err
+	}
+	if err := ValidateMaxConcurrency(request.MaxConcurrency); err != nil {
+		return err
+	}
+	if err := ValidateMaxPerFailureDomain(request.MaxPerFailureDomain); err != nil {
+		return err
+	}
+	if err := ValidateMaxReleaseDuration(request.MaxReleaseDuration); err != nil {
+		return err
+	}
+	if err := ValidateRehearsal(request.Rehearsal, request.RehearsalSpeedup); err != nil {
+		return err
+	}
+	if request.InstallationGroup != nil {
+		if err := ValidateName(request.InstallationGroup.Name); err != nil {
+			return errors.Wrap(err, "invalid installation group")
+		}
+		if err := ValidateSoakTime(request.InstallationGroup.SoakTime); err != nil {
+			return errors.Wrap(err, "invalid installation group")
+		}
+		if err := ValidateReleaseStrategy(request.InstallationGroup.ReleaseStrategy); err != nil {
+			return errors.Wrap(err, "invalid installation group")
+		}
+		if err := ValidateReleaseLoad(request.InstallationGroup.ReleaseLoadThreshold, request.InstallationGroup.ReleaseLoadMaxWait); err != nil {
+			return errors.Wrap(err, "invalid installation group")
+		}
+		if err := request.InstallationGroup.SoakChecks.Validate(); err != nil {
+			return errors.Wrap(err, "invalid installation group")
+		}
+		if err := request.InstallationGroup.ReleaseWindows.Validate(); err != nil {
+			return errors.Wrap(err, "invalid installation group release windows")
+		}
+		if err := ValidateReleaseVerification(request.InstallationGroup.VerificationURL, request.InstallationGroup.VerificationTimeout); err != nil {
+			return errors.Wrap(err, "invalid installation group")
+		}
+		if err := request.InstallationGroup.Annotations.Validate(); err != nil {
+			return errors.Wrap(err, "invalid installation group annotations")
+		}
+		if err := request.InstallationGroup.Metadata.Validate(); err != nil {
+			return errors.Wrap(err, "invalid installation group metadata")
+		}
+		if err := ValidateFailureDomain(request.InstallationGroup.FailureDomain); err != nil {
+			return errors.Wrap(err, "invalid installation group")
+		}
+	}
 
 	return nil
 }
 
+// Validate validates the values of a ring update request.
+func (request *UpdateRingRequest) Validate() error {
+	if err := ValidateName(request.Name); err != nil {
+		return err
+	}
+	if err := ValidateRelease(request.Image, request.Version, request.SoakTime); err != nil {
+		return err
+	}
+	if err := request.Annotations.Validate(); err != nil {
+		return err
+	}
+	if err := request.Metadata.Validate(); err != nil {
+		return err
+	}
+	if request.NotificationEmails != nil {
+		if err := request.NotificationEmails.Validate(); err != nil {
+			return err
+		}
+	}
+	if request.NotificationLanguage != nil {
+		if err := ValidateLanguage(*request.NotificationLanguage); err != nil {
+			return err
+		}
+	}
+	if request.JiraProject != nil {
+		if err := ValidateJiraProject(*request.JiraProject); err != nil {
+			return err
+		}
+	}
+	var ownerTeam, slackChannel, escalationPolicy string
+	if request.OwnerTeam != nil {
+		ownerTeam = *request.OwnerTeam
+	}
+	if request.SlackChannel != nil {
+		slackChannel = *request.SlackChannel
+	}
+	if request.EscalationPolicy != nil {
+		escalationPolicy = *request.EscalationPolicy
+	}
+	if err := ValidateRingContacts(ownerTeam, slackChannel, escalationPolicy); err != nil {
+		return err
+	}
+	if err := ValidateFailurePolicy(request.FailurePolicy); err != nil {
+		return err
+	}
+	if request.AutoRollback != nil {
+		if err := ValidateAutoRollback(*request.AutoRollback, request.FailurePolicy); err != nil {
+			return err
+		}
+	}
+	if request.ForceApprovalWindow != nil {
+		if err := ValidateForceApprovalWindow(*request.ForceApprovalWindow); err != nil {
+			return err
+		}
+	}
+	if request.SoakWindows != nil {
+		if err := request.SoakWindows.Validate(); err != nil {
+			return errors.Wrap(err, "invalid soak windows")
+		}
+	}
+	if request.ReleaseWindows != nil {
+		if err := request.ReleaseWindows.Validate(); err != nil {
+			return errors.Wrap(err, "invalid release windows")
+		}
+	}
+	if request.DependsOn != nil {
+		if err := request.DependsOn.Validate(); err != nil {
+			return err
+		}
+	}
+	if request.MaxConcurrency != nil {
+		if err := ValidateMaxConcurrency(*request.MaxConcurrency); err != nil {
+			return err
+		}
+	}
+	if request.MaxPerFailureDomain != nil {
+		if err := ValidateMaxPerFailureDomain(*request.MaxPerFailureDomain); err != nil {
+			return err
+		}
+	}
+	if request.MaxReleaseDuration != nil {
+		if err := ValidateMaxReleaseDuration(*request.MaxReleaseDuration); err != nil {
+			return err
+		}
+	}
+
+	return ValidateInstallationGroupPolicy(request.InstallationGroupPolicy)
+}
+
 // NewCreateRingRequestFromReader will create a CreateRingRequest from an
 // io.Reader with JSON data.
 func NewCreateRingRequestFromReader(reader io.Reader) (*CreateRingRequest, error) {
@@ -73,7 +358,6 @@ func NewCreateRingRequestFromReader(reader io.Reader) (*CreateRingRequest, error
 		return nil, errors.Wrap(err, "failed to decode create ring request")
 	}
 
-	createRingRequest.SetDefaults()
 	if err = createRingRequest.Validate(); err != nil {
 		return nil, errors.Wrap(err, "create ring request failed validation")
 	}
@@ -88,6 +372,9 @@ func NewUpdateRingRequestFromReader(reader io.Reader) (*UpdateRingRequest, error
 	if err != nil && err != io.EOF {
 		return nil, errors.Wrap(err, "failed to decode provision ring request")
 	}
+	if err = updateRingRequest.Validate(); err != nil {
+		return nil, errors.Wrap(err, "update ring request failed validation")
+	}
 	return &updateRingRequest, nil
 }
 
@@ -99,6 +386,27 @@ func (request *GetRingsRequest) ApplyToURL(u *url.URL) {
 	if request.IncludeDeleted {
 		q.Add("include_deleted", "true")
 	}
+	if request.AsOf != 0 {
+		q.Add("as_of", strconv.FormatInt(request.AsOf, 10))
+	}
+	u.RawQuery = q.Encode()
+}
+
+// Validate validates the values of a ring delete request.
+func (request *DeleteRingRequest) Validate() error {
+	if request.DeleteAfter < 0 {
+		return errors.New("DeleteAfter cannot be negative")
+	}
+
+	return nil
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *DeleteRingRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	if request.DeleteAfter > 0 {
+		q.Add("delete_after", strconv.Itoa(request.DeleteAfter))
+	}
 	u.RawQuery = q.Encode()
 }
 
@@ -110,6 +418,7 @@ func NewRingReleaseRequestFromReader(reader io.Reader) (*RingReleaseRequest, err
 		return nil, errors.Wrap(err, "failed to decode provision ring request")
 	}
 
+	ringReleaseRequest.SetDefaults()
 	err = ringReleaseRequest.Validate()
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid ring release request")
@@ -118,8 +427,34 @@ func NewRingReleaseRequestFromReader(reader io.Reader) (*RingReleaseRequest, err
 	return &ringReleaseRequest, nil
 }
 
+// SetDefaults sets the default values for a ring release request.
+func (request *RingReleaseRequest) SetDefaults() {
+	if request.Type == "" {
+		request.Type = ReleaseTypeStandard
+	}
+}
+
 // Validate validates the values of a ring release request.
 func (request *RingReleaseRequest) Validate() error {
+	if err := ValidateRelease(request.Image, request.Version, request.SoakTime); err != nil {
+		return err
+	}
+	if !ValidReleaseType(request.Type) {
+		return errors.Errorf("unknown release type %q", request.Type)
+	}
+	if err := request.Parameters.Validate(); err != nil {
+		return errors.Wrap(err, "invalid release parameters")
+	}
+	if err := request.Links.Validate(); err != nil {
+		return errors.Wrap(err, "invalid release links")
+	}
+	if err := request.Labels.Validate(); err != nil {
+		return err
+	}
+	if request.ScheduleAt < 0 {
+		return errors.New("schedule time must not be negative")
+	}
+
 	//TODO find another way to validate the docker image
 	// ctx := context.Background()
 	// cli, err := dclient.NewClientWithOpts()
@@ -19,6 +19,41 @@ func TestCreateRingRequestValid(t *testing.T) {
 	}{
 		{"defaults", &model.CreateRingRequest{SoakTime: 3600, Priority: 1, InstallationGroup: &model.InstallationGroup{Name: "test2"}}, false},
 		{"invalid priority", &model.CreateRingRequest{Priority: 0}, true},
+		{"contacts", &model.CreateRingRequest{Priority: 1, OwnerTeam: "Platform Team", SlackChannel: "#platform-alerts", EscalationPolicy: "PX12345"}, false},
+		{"escalation policy URL", &model.CreateRingRequest{Priority: 1, EscalationPolicy: "https://example.pagerduty.com/escalation_policies/PX12345"}, false},
+		{"invalid owner team", &model.CreateRingRequest{Priority: 1, OwnerTeam: "Platform\nTeam"}, true},
+		{"slack channel without #", &model.CreateRingRequest{Priority: 1, SlackChannel: "platform-alerts"}, true},
+		{"uppercase slack channel", &model.CreateRingRequest{Priority: 1, SlackChannel: "#Platform"}, true},
+		{"invalid escalation policy", &model.CreateRingRequest{Priority: 1, EscalationPolicy: "page the platform team"}, true},
+		{"release", &model.CreateRingRequest{Priority: 1, Name: "production-eu", Image: "mattermost/mattermost-enterprise-edition", Version: "9.5.1"}, false},
+		{"invalid name", &model.CreateRingRequest{Priority: 1, Name: "production, eu"}, true},
+		{"negative soak time", &model.CreateRingRequest{Priority: 1, SoakTime: -1}, true},
+		{"image with tag", &model.CreateRingRequest{Priority: 1, Image: "mattermost/mattermost-enterprise-edition:9.5.1"}, true},
+		{"invalid installation group name", &model.CreateRingRequest{Priority: 1, InstallationGroup: &model.InstallationGroup{Name: "group 1"}}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			if tc.requireError {
+				assert.Error(t, tc.request.Validate())
+			} else {
+				assert.NoError(t, tc.request.Validate())
+			}
+		})
+	}
+}
+
+func TestRingReleaseRequestValid(t *testing.T) {
+	var testCases = []struct {
+		testName     string
+		request      *model.RingReleaseRequest
+		requireError bool
+	}{
+		{"defaults", &model.RingReleaseRequest{}, false},
+		{"hotfix", &model.RingReleaseRequest{Type: model.ReleaseTypeHotfix}, false},
+		{"rollback", &model.RingReleaseRequest{Type: model.ReleaseTypeRollback}, false},
+		{"unknown type", &model.RingReleaseRequest{Type: "urgent"}, true},
+		{"negative soak time", &model.RingReleaseRequest{SoakTime: -1}, true},
 	}
 
 	for _, tc := range testCases {
@@ -19,8 +19,19 @@ const (
 	RingStateReleaseFailed = "release-failed"
 	// RingStateReleaseInProgress is a ring that the release is in progress.
 	RingStateReleaseInProgress = "release-in-progress"
-	// RingStateReleasePaused is a ring that the release is paused.
+	// RingStateReleasePaused is a ring whose release is paused, either
+	// before it started or while in flight. See PauseRelease.
 	RingStateReleasePaused = "release-paused"
+	// RingStateReleaseBlocked is a ring whose pending release waits for
+	// another ring, one it depends on or one releasing, recorded in
+	// BlockedBy. The release resumes on its own once the other ring is done.
+	RingStateReleaseBlocked = "release-blocked"
+	// RingStateReleasePrepareRequested is a ring whose release is being
+	// prepared ahead of being committed.
+	RingStateReleasePrepareRequested = "release-prepare-requested"
+	// RingStateReleasePrepared is a ring whose release is prepared and waits
+	// to be committed.
+	RingStateReleasePrepared = "release-prepared"
 	// RingStateSoakingRequested is a ring that is undergoing soak period.
 	RingStateSoakingRequested = "soaking-requested"
 	// RingStateSoakingFailed is a ring that is undergoing soak period.
@@ -31,6 +42,9 @@ const (
 	RingStateReleaseRollbackComplete = "release-rollback-complete"
 	// RingStateReleaseRollbackFailed is a ring that the release rollback has failed.
 	RingStateReleaseRollbackFailed = "release-rollback-failed"
+	// RingStateDeletionPending is a ring scheduled for deletion once its
+	// deletion grace period ends.
+	RingStateDeletionPending = "deletion-pending"
 	// RingStateDeletionRequested is a ring in the process of being deleted.
 	RingStateDeletionRequested = "deletion-requested"
 	// RingStateDeletionFailed is a ring that failed deletion.
@@ -51,11 +65,15 @@ var AllRingStates = []string{
 	RingStateReleaseFailed,
 	RingStateReleaseInProgress,
 	RingStateReleasePaused,
+	RingStateReleaseBlocked,
+	RingStateReleasePrepareRequested,
+	RingStateReleasePrepared,
 	RingStateSoakingRequested,
 	RingStateSoakingFailed,
 	RingStateReleaseRollbackRequested,
 	RingStateReleaseRollbackFailed,
 	RingStateReleaseRollbackComplete,
+	RingStateDeletionPending,
 	RingStateDeletionRequested,
 	RingStateDeletionFailed,
 	RingStateDeleted,
@@ -69,10 +87,13 @@ var AllRingStates = []string{
 var AllRingStatesPendingWork = []string{
 	RingStateCreationRequested,
 	RingStateReleasePending,
+	RingStateReleaseBlocked,
+	RingStateReleasePrepareRequested,
 	RingStateReleaseRequested,
 	RingStateReleaseInProgress,
 	RingStateSoakingRequested,
 	RingStateReleaseRollbackRequested,
+	RingStateDeletionPending,
 	RingStateDeletionRequested,
 }
 
@@ -88,6 +109,9 @@ var AllRingStatesReleaseInProgress = []string{
 var AllRingStatesReleasePending = []string{
 	RingStateReleasePaused,
 	RingStateReleasePending,
+	RingStateReleaseBlocked,
+	RingStateReleasePrepareRequested,
+	RingStateReleasePrepared,
 }
 
 // AllRingRequestStates is a list of all states that a ring can be put in
@@ -97,37 +121,113 @@ var AllRingStatesReleasePending = []string{
 // endpoint should put the ring in this state.
 var AllRingRequestStates = []string{
 	RingStateCreationRequested,
+	RingStateReleasePrepareRequested,
 	RingStateReleaseRequested,
+	RingStateReleaseInProgress,
 	RingStateSoakingRequested,
 	RingStateReleaseRollbackRequested,
+	RingStateDeletionPending,
 	RingStateDeletionRequested,
 }
 
 // ValidTransitionState returns whether a ring can be transitioned into the
-// new state or not based on its current state.
+// new state or not based on its current state, following the transition
+// rules of the state machine version of the ring.
 func (c *Ring) ValidTransitionState(newState string) bool {
+	validTransition, ok := ringStateMachines[c.CurrentStateMachineVersion()]
+	if !ok {
+		return false
+	}
+
+	return validTransition(c.State, newState)
+}
+
+// validRingTransitionV1 holds the ring transition rules of the state machine
+// version 1.
+func validRingTransitionV1(currentState, newState string) bool {
 	switch newState {
 	case RingStateCreationRequested:
-		return validTransitionToRingStateCreationRequested(c.State)
+		return validTransitionToRingStateCreationRequested(currentState)
 	case RingStateReleasePending:
-		return validTransitionToRingStateReleasePending(c.State)
+		return validTransitionToRingStateReleasePending(currentState)
 	case RingStateReleasePaused:
-		return validTransitionToRingStateReleasePaused(c.State)
+		return validTransitionToRingStateReleasePaused(currentState)
 	case RingStateReleaseRequested:
-		return validTransitionToRingStateReleaseRequested(c.State)
+		return validTransitionToRingStateReleaseRequested(currentState)
 	case RingStateReleaseInProgress:
-		return validTransitionToRingStateReleaseInProgress(c.State)
+		return validTransitionToRingStateReleaseInProgress(currentState)
+	case RingStateDeletionPending:
+		return validTransitionToRingStateDeletionPending(currentState)
 	case RingStateDeletionRequested:
-		return validTransitionToRingStateDeletionRequested(c.State)
+		return validTransitionToRingStateDeletionRequested(currentState)
 	case RingStateSoakingRequested:
-		return validTransitionToRingStateSoakingRequested(c.State)
+		return validTransitionToRingStateSoakingRequested(currentState)
 	case RingStateReleaseRollbackRequested:
-		return validTransitionToRingStateRollbackRequested(c.State)
+		return validTransitionToRingStateRollbackRequested(currentState)
 	}
 
 	return false
 }
 
+// validRingTransitionV2 holds the ring transition rules of the state machine
+// version 2, which also allows pausing a release in flight, and resuming it
+// in the state it was paused in.
+func validRingTransitionV2(currentState, newState string) bool {
+	switch newState {
+	case RingStateReleasePaused:
+		return validTransitionToRingStateReleasePausedV2(currentState)
+	case RingStateReleaseInProgress, RingStateSoakingRequested:
+		if currentState == RingStateReleasePaused {
+			return true
+		}
+	}
+
+	return validRingTransitionV1(currentState, newState)
+}
+
+// validRingTransitionV3 holds the ring transition rules of the state machine
+// version 3, which also allows preparing a release ahead of committing it.
+func validRingTransitionV3(currentState, newState string) bool {
+	switch newState {
+	case RingStateReleasePrepareRequested:
+		return validTransitionToRingStateReleasePrepareRequested(currentState)
+	case RingStateReleasePending,
+		RingStateReleaseRequested,
+		RingStateDeletionPending,
+		RingStateDeletionRequested:
+		if currentState == RingStateReleasePrepared {
+			return true
+		}
+	}
+
+	return validRingTransitionV2(currentState, newState)
+}
+
+// validRingTransitionV4 holds the ring transition rules of the state machine
+// version 4, which also allows a failed release to go on once some of its
+// failed installation groups are released again on their own.
+func validRingTransitionV4(currentState, newState string) bool {
+	if newState == RingStateReleaseInProgress && currentState == RingStateReleaseFailed {
+		return true
+	}
+
+	return validRingTransitionV3(currentState, newState)
+}
+
+// validRingTransitionV5 holds the ring transition rules of the state machine
+// version 5, which also allows a pending release to be blocked by another
+// ring. A blocked ring moves on like a pending one.
+func validRingTransitionV5(currentState, newState string) bool {
+	if newState == RingStateReleaseBlocked {
+		return currentState == RingStateReleasePending || currentState == RingStateReleaseBlocked
+	}
+	if currentState == RingStateReleaseBlocked {
+		currentState = RingStateReleasePending
+	}
+
+	return validRingTransitionV4(currentState, newState)
+}
+
 func validTransitionToRingStateCreationRequested(currentState string) bool {
 	switch currentState {
 	case RingStateCreationRequested,
@@ -151,6 +251,19 @@ func validTransitionToRingStateReleasePending(currentState string) bool {
 	return false
 }
 
+func validTransitionToRingStateReleasePrepareRequested(currentState string) bool {
+	switch currentState {
+	case RingStateStable,
+		RingStateReleaseFailed,
+		RingStateSoakingFailed,
+		RingStateReleasePrepareRequested,
+		RingStateReleasePrepared:
+		return true
+	}
+
+	return false
+}
+
 func validTransitionToRingStateReleasePaused(currentState string) bool {
 	switch currentState {
 	case RingStateReleasePending:
@@ -160,6 +273,18 @@ func validTransitionToRingStateReleasePaused(currentState string) bool {
 	return false
 }
 
+func validTransitionToRingStateReleasePausedV2(currentState string) bool {
+	switch currentState {
+	case RingStateReleasePending,
+		RingStateReleaseRequested,
+		RingStateReleaseInProgress,
+		RingStateSoakingRequested:
+		return true
+	}
+
+	return false
+}
+
 func validTransitionToRingStateReleaseRequested(currentState string) bool {
 	switch currentState {
 	case RingStateReleasePending,
@@ -182,12 +307,27 @@ func validTransitionToRingStateReleaseInProgress(currentState string) bool {
 	return false
 }
 
+func validTransitionToRingStateDeletionPending(currentState string) bool {
+	switch currentState {
+	case RingStateStable,
+		RingStateCreationRequested,
+		RingStateCreationFailed,
+		RingStateReleaseFailed,
+		RingStateDeletionPending,
+		RingStateDeletionFailed:
+		return true
+	}
+
+	return false
+}
+
 func validTransitionToRingStateDeletionRequested(currentState string) bool {
 	switch currentState {
 	case RingStateStable,
 		RingStateCreationRequested,
 		RingStateCreationFailed,
 		RingStateReleaseFailed,
+		RingStateDeletionPending,
 		RingStateDeletionRequested,
 		RingStateDeletionFailed:
 		return true
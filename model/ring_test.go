@@ -26,6 +26,16 @@ func TestRingClone(t *testing.T) {
 	require.NotEqual(t, ring, clone)
 }
 
+func TestRingContacts(t *testing.T) {
+	ring := &Ring{}
+	require.Empty(t, ring.Contacts())
+
+	ring.OwnerTeam = "Platform"
+	ring.SlackChannel = "#platform-alerts"
+	ring.EscalationPolicy = "PX12345"
+	require.Equal(t, "owner team Platform, Slack #platform-alerts, escalation policy PX12345", ring.Contacts())
+}
+
 func TestRingFromReader(t *testing.T) {
 	t.Run("empty request", func(t *testing.T) {
 		ring, err := RingFromReader(bytes.NewReader([]byte(
@@ -80,3 +90,39 @@ func TestRingsFromReader(t *testing.T) {
 		}, ring)
 	})
 }
+
+func TestRingReleaseChecksum(t *testing.T) {
+	release := &RingRelease{
+		ID:       "id",
+		Image:    "mattermost/mattermost-enterprise-edition",
+		Version:  "7.0.1",
+		CreateAt: 10,
+		Type:     ReleaseTypeStandard,
+	}
+	require.False(t, release.VerifyChecksum())
+
+	checksum, err := release.ComputeChecksum()
+	require.NoError(t, err)
+	require.Len(t, checksum, 64)
+	release.Checksum = checksum
+	require.True(t, release.VerifyChecksum())
+
+	t.Run("identity and metadata are not content", func(t *testing.T) {
+		other := *release
+		other.ID = "other"
+		other.CreateAt = 20
+		other.Immutable = true
+		other.Parameters = ReleaseParameters{}
+		require.True(t, other.VerifyChecksum())
+	})
+
+	t.Run("content changes", func(t *testing.T) {
+		other := *release
+		other.Version = "7.0.2"
+		require.False(t, other.VerifyChecksum())
+
+		other = *release
+		other.Parameters = ReleaseParameters{"group": {MattermostEnv: map[string]string{"KEY": "value"}}}
+		require.False(t, other.VerifyChecksum())
+	})
+}
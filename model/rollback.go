@@ -0,0 +1,35 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+// RollbackReleaseID returns the release the installation group is rolled back
+// to when the release of the given ring fails: the release it ran before if it
+// completed the desired release of the ring, its active release otherwise,
+// and the active release of the ring if it never completed a release.
+func (i *InstallationGroup) RollbackReleaseID(ring *Ring) string {
+	if i.ActiveReleaseID == "" {
+		return ring.ActiveReleaseID
+	}
+	if i.ActiveReleaseID == ring.DesiredReleaseID && i.ActiveReleaseID != ring.ActiveReleaseID {
+		if i.PreviousReleaseID != "" {
+			return i.PreviousReleaseID
+		}
+		return ring.ActiveReleaseID
+	}
+
+	return i.ActiveReleaseID
+}
+
+// NeedsRollback returns whether the installation group failed to release or
+// soak the desired release of the given ring, or completed it, and must be
+// rolled back along with the ring.
+func (i *InstallationGroup) NeedsRollback(ring *Ring) bool {
+	switch i.State {
+	case InstallationGroupReleaseFailed, InstallationGroupReleaseSoakingFailed:
+		return true
+	}
+
+	return ring.DesiredReleaseID != "" && ring.DesiredReleaseID != ring.ActiveReleaseID && i.ActiveReleaseID == ring.DesiredReleaseID
+}
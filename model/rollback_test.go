@@ -0,0 +1,61 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallationGroupRollback(t *testing.T) {
+	ring := &model.Ring{ActiveReleaseID: "release1", DesiredReleaseID: "release2"}
+
+	var testCases = []struct {
+		testName             string
+		installationGroup    *model.InstallationGroup
+		expectedRollbackID   string
+		expectedNeedRollback bool
+	}{
+		{
+			"never released",
+			&model.InstallationGroup{State: model.InstallationGroupStable},
+			"release1", false,
+		},
+		{
+			"failed release",
+			&model.InstallationGroup{State: model.InstallationGroupReleaseFailed, ActiveReleaseID: "release0"},
+			"release0", true,
+		},
+		{
+			"failed soak",
+			&model.InstallationGroup{State: model.InstallationGroupReleaseSoakingFailed, ActiveReleaseID: "release2", PreviousReleaseID: "release0"},
+			"release0", true,
+		},
+		{
+			"completed desired release",
+			&model.InstallationGroup{State: model.InstallationGroupStable, ActiveReleaseID: "release2", PreviousReleaseID: "release1"},
+			"release1", true,
+		},
+		{
+			"completed desired release without previous release",
+			&model.InstallationGroup{State: model.InstallationGroupStable, ActiveReleaseID: "release2"},
+			"release1", true,
+		},
+		{
+			"active release",
+			&model.InstallationGroup{State: model.InstallationGroupStable, ActiveReleaseID: "release1"},
+			"release1", false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			assert.Equal(t, tc.expectedRollbackID, tc.installationGroup.RollbackReleaseID(ring))
+			assert.Equal(t, tc.expectedNeedRollback, tc.installationGroup.NeedsRollback(ring))
+		})
+	}
+}
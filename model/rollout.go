@@ -0,0 +1,251 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// RolloutStateInProgress is a rollout releasing its steps in sequence.
+	RolloutStateInProgress = "in-progress"
+	// RolloutStateComplete is a rollout whose rings all run its release.
+	RolloutStateComplete = "complete"
+	// RolloutStateFailed is a rollout stopped by a ring that did not release.
+	RolloutStateFailed = "failed"
+	// RolloutStateCancelled is a rollout whose remaining steps were cancelled.
+	RolloutStateCancelled = "cancelled"
+	// RolloutStateRolledBack is a rollout whose rings were released back to
+	// the release they ran before it.
+	RolloutStateRolledBack = "rolled-back"
+	// RolloutStatePaused is a rollout whose promotion to its next step was
+	// stopped by a breached promotion check, until it is resumed.
+	RolloutStatePaused = "paused"
+)
+
+// AllRolloutStatesHoldingRings is a list of all rollout states in which the
+// rollout still releases its rings.
+var AllRolloutStatesHoldingRings = []string{
+	RolloutStateInProgress,
+	RolloutStatePaused,
+}
+
+// AllRolloutStatesPendingWork is a list of all rollout states that the
+// supervisor works on.
+var AllRolloutStatesPendingWork = []string{
+	RolloutStateInProgress,
+}
+
+// Rollout releases a single release to rings in a defined sequence of steps,
+// such as one step per environment, starting each step once every ring of
+// the previous step has released.
+type Rollout struct {
+	ID        string
+	Name      string
+	ReleaseID string
+	Steps     RolloutSteps
+	// CurrentStep is the index of the step being released.
+	CurrentStep int
+	// StepStartAt is when the current step started releasing, in
+	// milliseconds, or 0 if it has not started yet.
+	StepStartAt int64
+	// StepReleasedAt is when every ring of the current step was found
+	// running the release, in milliseconds, or 0 if they do not yet. The
+	// promotion window of the step starts then.
+	StepReleasedAt int64 `json:",omitempty"`
+	State          string
+	// Message explains why the rollout failed or was paused, if it did.
+	Message  string `json:",omitempty"`
+	CreateAt int64
+	// Progress summarizes the rings of the rollout. It is computed when the
+	// rollout is fetched and is not stored.
+	Progress       *RolloutProgress `json:",omitempty"`
+	LockAcquiredBy *string
+	LockAcquiredAt int64
+}
+
+// RolloutStep is a named group of rings released together.
+type RolloutStep struct {
+	Name    string
+	RingIDs []string
+	// PromotionWindow is how long, in seconds, the rings of the step must
+	// run the release with passing promotion checks before the next step
+	// starts.
+	PromotionWindow int64 `json:",omitempty"`
+	// PromotionChecks are the metrics that must hold during the promotion
+	// window. Any breach pauses the rollout.
+	PromotionChecks SoakChecks `json:",omitempty"`
+}
+
+// HasPromotionGate returns whether the promotion to the step after this one
+// waits for a window or checks.
+func (s *RolloutStep) HasPromotionGate() bool {
+	return s.PromotionWindow > 0 || len(s.PromotionChecks) > 0
+}
+
+// RolloutSteps is the ordered list of steps of a rollout.
+type RolloutSteps []*RolloutStep
+
+// Value implements driver.Valuer, storing the steps as JSON.
+func (s RolloutSteps) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+
+	data, err := json.Marshal([]*RolloutStep(s))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal rollout steps")
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner, loading the steps from JSON.
+func (s *RolloutSteps) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.Errorf("cannot scan %T into rollout steps", src)
+	}
+
+	var steps RolloutSteps
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return errors.Wrap(err, "failed to unmarshal rollout steps")
+	}
+	*s = steps
+
+	return nil
+}
+
+// RolloutProgress summarizes how far the release of a rollout got.
+type RolloutProgress struct {
+	ReleasedRings int
+	TotalRings    int
+	Rings         []*RolloutRing
+}
+
+// RolloutRing is the status of a ring of a rollout.
+type RolloutRing struct {
+	Step     int
+	RingID   string
+	RingName string `json:",omitempty"`
+	State    string `json:",omitempty"`
+	Released bool
+}
+
+// RolloutFilter describes the parameters used to constrain a set of rollouts.
+type RolloutFilter struct {
+	States  []string
+	Page    int
+	PerPage int
+}
+
+// RingIDs returns the rings of every step of the rollout, in order.
+func (r *Rollout) RingIDs() []string {
+	var ringIDs []string
+	for _, step := range r.Steps {
+		ringIDs = append(ringIDs, step.RingIDs...)
+	}
+
+	return ringIDs
+}
+
+// StartedRingIDs returns the rings of the steps of the rollout that started
+// releasing.
+func (r *Rollout) StartedRingIDs() []string {
+	var ringIDs []string
+	for i, step := range r.Steps {
+		if i > r.CurrentStep || (i == r.CurrentStep && r.StepStartAt == 0) {
+			break
+		}
+		ringIDs = append(ringIDs, step.RingIDs...)
+	}
+
+	return ringIDs
+}
+
+// RingReleased returns whether the given ring runs the release of the rollout.
+func (r *Rollout) RingReleased(ring *Ring) bool {
+	return ring.State == RingStateStable && ring.ActiveReleaseID == r.ReleaseID
+}
+
+// RingFailure returns why the given ring of a started step of the rollout
+// will not release, or an empty string if it is released or releasing.
+func (r *Rollout) RingFailure(ringID string, ring *Ring) string {
+	if ring == nil || ring.DeleteAt != 0 {
+		return fmt.Sprintf("ring %s was deleted", ringID)
+	}
+
+	switch ring.State {
+	case RingStateReleaseFailed, RingStateSoakingFailed, RingStateReleaseRollbackRequested,
+		RingStateReleaseRollbackComplete, RingStateReleaseRollbackFailed:
+		return fmt.Sprintf("ring %s is %s", ring.Name, ring.State)
+	case RingStateStable:
+		if ring.ActiveReleaseID != r.ReleaseID && ring.DesiredReleaseID != r.ReleaseID {
+			return fmt.Sprintf("the release of ring %s was cancelled", ring.Name)
+		}
+	}
+
+	return ""
+}
+
+// NewRolloutProgress summarizes the given rings, by ID, of the rollout.
+func NewRolloutProgress(rollout *Rollout, rings map[string]*Ring) *RolloutProgress {
+	progress := &RolloutProgress{Rings: []*RolloutRing{}}
+	for i, step := range rollout.Steps {
+		for _, ringID := range step.RingIDs {
+			status := &RolloutRing{Step: i, RingID: ringID}
+			if ring := rings[ringID]; ring != nil {
+				status.RingName = ring.Name
+				status.State = ring.State
+				status.Released = rollout.RingReleased(ring)
+			}
+			if status.Released {
+				progress.ReleasedRings++
+			}
+			progress.TotalRings++
+			progress.Rings = append(progress.Rings, status)
+		}
+	}
+
+	return progress
+}
+
+// RolloutFromReader decodes a json-encoded rollout from the given io.Reader.
+func RolloutFromReader(reader io.Reader) (*Rollout, error) {
+	rollout := Rollout{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&rollout)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &rollout, nil
+}
+
+// RolloutsFromReader decodes a json-encoded list of rollouts from the given io.Reader.
+func RolloutsFromReader(reader io.Reader) ([]*Rollout, error) {
+	rollouts := []*Rollout{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&rollouts)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return rollouts, nil
+}
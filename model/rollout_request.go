@@ -0,0 +1,92 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// CreateRolloutRequest specifies the parameters for a new rollout. The
+// release is released to the rings of each step in turn.
+type CreateRolloutRequest struct {
+	Name string
+	RingReleaseRequest
+	Steps []*RolloutStep
+}
+
+// GetRolloutsRequest describes the parameters to request a list of rollouts.
+type GetRolloutsRequest struct {
+	Page    int
+	PerPage int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetRolloutsRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	u.RawQuery = q.Encode()
+}
+
+// SetDefaults sets the default values for a rollout create request.
+func (request *CreateRolloutRequest) SetDefaults() {
+	request.RingReleaseRequest.SetDefaults()
+}
+
+// Validate validates the values of a rollout create request.
+func (request *CreateRolloutRequest) Validate() error {
+	if err := request.RingReleaseRequest.Validate(); err != nil {
+		return err
+	}
+	if len(request.Steps) == 0 {
+		return errors.New("a rollout must have at least one step")
+	}
+
+	ringIDs := make(map[string]bool)
+	for i, step := range request.Steps {
+		if step == nil || len(step.RingIDs) == 0 {
+			return errors.Errorf("step %d must have at least one ring", i)
+		}
+		for _, ringID := range step.RingIDs {
+			if ringIDs[ringID] {
+				return errors.Errorf("ring %s is in more than one step", ringID)
+			}
+			ringIDs[ringID] = true
+		}
+		if step.PromotionWindow < 0 {
+			return errors.Errorf("the promotion window of step %d must not be negative", i)
+		}
+		if err := step.PromotionChecks.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid promotion checks of step %d", i)
+		}
+		if i == len(request.Steps)-1 && step.HasPromotionGate() {
+			return errors.New("the last step has no step to promote to and must not have a promotion window or checks")
+		}
+	}
+
+	return nil
+}
+
+// NewCreateRolloutRequestFromReader will create a CreateRolloutRequest from an io.Reader with JSON data.
+func NewCreateRolloutRequestFromReader(reader io.Reader) (*CreateRolloutRequest, error) {
+	var createRolloutRequest CreateRolloutRequest
+	err := json.NewDecoder(reader).Decode(&createRolloutRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode create rollout request")
+	}
+
+	createRolloutRequest.SetDefaults()
+	err = createRolloutRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid create rollout request")
+	}
+
+	return &createRolloutRequest, nil
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolloutRings(t *testing.T) {
+	rollout := &Rollout{
+		ReleaseID: "release",
+		Steps: RolloutSteps{
+			{Name: "staging", RingIDs: []string{"ring1"}},
+			{Name: "production", RingIDs: []string{"ring2", "ring3"}},
+		},
+		State: RolloutStateInProgress,
+	}
+
+	require.Equal(t, []string{"ring1", "ring2", "ring3"}, rollout.RingIDs())
+	require.Empty(t, rollout.StartedRingIDs())
+
+	rollout.StepStartAt = 1
+	require.Equal(t, []string{"ring1"}, rollout.StartedRingIDs())
+
+	rollout.CurrentStep = 1
+	rollout.StepStartAt = 0
+	require.Equal(t, []string{"ring1"}, rollout.StartedRingIDs())
+
+	rollout.CurrentStep = 2
+	require.Equal(t, []string{"ring1", "ring2", "ring3"}, rollout.StartedRingIDs())
+}
+
+func TestRolloutRingFailure(t *testing.T) {
+	rollout := &Rollout{ReleaseID: "release"}
+
+	for name, tc := range map[string]struct {
+		ring    *Ring
+		failure string
+	}{
+		"missing":  {nil, "ring ring1 was deleted"},
+		"deleted":  {&Ring{Name: "one", State: RingStateDeleted, DeleteAt: 1}, "ring ring1 was deleted"},
+		"failed":   {&Ring{Name: "one", State: RingStateReleaseFailed}, "ring one is release-failed"},
+		"pending":  {&Ring{Name: "one", State: RingStateReleasePending, DesiredReleaseID: "release"}, ""},
+		"paused":   {&Ring{Name: "one", State: RingStateReleasePaused, DesiredReleaseID: "release"}, ""},
+		"released": {&Ring{Name: "one", State: RingStateStable, ActiveReleaseID: "release", DesiredReleaseID: "release"}, ""},
+		"cancelled": {
+			&Ring{Name: "one", State: RingStateStable, ActiveReleaseID: "previous", DesiredReleaseID: "previous"},
+			"the release of ring one was cancelled",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.failure, rollout.RingFailure("ring1", tc.ring))
+		})
+	}
+}
+
+func TestNewRolloutProgress(t *testing.T) {
+	rollout := &Rollout{
+		ReleaseID: "release",
+		Steps: RolloutSteps{
+			{RingIDs: []string{"ring1"}},
+			{RingIDs: []string{"ring2", "ring3"}},
+		},
+	}
+	rings := map[string]*Ring{
+		"ring1": {Name: "one", State: RingStateStable, ActiveReleaseID: "release"},
+		"ring2": {Name: "two", State: RingStateReleasePending, ActiveReleaseID: "previous"},
+	}
+
+	progress := NewRolloutProgress(rollout, rings)
+	require.Equal(t, &RolloutProgress{
+		ReleasedRings: 1,
+		TotalRings:    3,
+		Rings: []*RolloutRing{
+			{Step: 0, RingID: "ring1", RingName: "one", State: RingStateStable, Released: true},
+			{Step: 1, RingID: "ring2", RingName: "two", State: RingStateReleasePending},
+			{Step: 1, RingID: "ring3"},
+		},
+	}, progress)
+}
+
+func TestCreateRolloutRequestValidate(t *testing.T) {
+	request := &CreateRolloutRequest{
+		Steps: []*RolloutStep{{RingIDs: []string{"ring1"}}, {RingIDs: []string{"ring2"}}},
+	}
+	request.SetDefaults()
+	require.Equal(t, ReleaseTypeStandard, request.Type)
+	require.NoError(t, request.Validate())
+
+	request.Steps = nil
+	require.EqualError(t, request.Validate(), "a rollout must have at least one step")
+
+	request.Steps = []*RolloutStep{{RingIDs: []string{"ring1"}}, {}}
+	require.EqualError(t, request.Validate(), "step 1 must have at least one ring")
+
+	request.Steps = []*RolloutStep{{RingIDs: []string{"ring1"}}, {RingIDs: []string{"ring1"}}}
+	require.EqualError(t, request.Validate(), "ring ring1 is in more than one step")
+
+	request.Steps = []*RolloutStep{{RingIDs: []string{"ring1"}, PromotionWindow: 60}, {RingIDs: []string{"ring2"}}}
+	require.NoError(t, request.Validate())
+
+	request.Steps[0].PromotionWindow = -1
+	require.EqualError(t, request.Validate(), "the promotion window of step 0 must not be negative")
+
+	request.Steps[0].PromotionWindow = 0
+	request.Steps[0].PromotionChecks = SoakChecks{{Name: "error-rate", Comparison: SoakCheckBelow}}
+	require.EqualError(t, request.Validate(), "invalid promotion checks of step 0: soak check error-rate must have a query")
+
+	request.Steps = []*RolloutStep{{RingIDs: []string{"ring1"}}, {RingIDs: []string{"ring2"}, PromotionWindow: 60}}
+	require.EqualError(t, request.Validate(), "the last step has no step to promote to and must not have a promotion window or checks")
+
+	request.Steps = []*RolloutStep{{RingIDs: []string{"ring1"}}}
+	request.Type = "unknown"
+	require.Error(t, request.Validate())
+}
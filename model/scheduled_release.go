@@ -0,0 +1,55 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ScheduledRelease is the pending release of a ring scheduled for later.
+type ScheduledRelease struct {
+	RingID    string
+	RingName  string
+	ReleaseID string
+	Image     string
+	Version   string
+	Type      string
+	// ScheduleAt is the time, in nanoseconds, the release starts at the
+	// earliest.
+	ScheduleAt int64
+}
+
+// NewScheduledRelease returns the scheduled release of the given ring, to
+// the given desired release.
+func NewScheduledRelease(ring *Ring, release *RingRelease) *ScheduledRelease {
+	scheduledRelease := &ScheduledRelease{
+		RingID:     ring.ID,
+		RingName:   ring.Name,
+		ReleaseID:  ring.DesiredReleaseID,
+		ScheduleAt: ring.ReleaseScheduledAt,
+	}
+	if release != nil {
+		scheduledRelease.Image = release.Image
+		scheduledRelease.Version = release.Version
+		scheduledRelease.Type = release.Type
+	}
+
+	return scheduledRelease
+}
+
+// ScheduledReleasesFromReader decodes a json-encoded list of scheduled
+// releases from the given io.Reader.
+func ScheduledReleasesFromReader(reader io.Reader) ([]*ScheduledRelease, error) {
+	scheduledReleases := []*ScheduledRelease{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&scheduledReleases)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return scheduledReleases, nil
+}
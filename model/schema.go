@@ -0,0 +1,417 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	goencoding "encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SchemaDraft is the JSON Schema draft of the request schemas.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// The names of the schemas of the request payloads of the API, which are
+// the names of their types.
+const (
+	SchemaCreateRingRequest                   = "CreateRingRequest"
+	SchemaUpdateRingRequest                   = "UpdateRingRequest"
+	SchemaRingReleaseRequest                  = "RingReleaseRequest"
+	SchemaRegisterInstallationGroupRequest    = "RegisterInstallationGroupRequest"
+	SchemaUpdateInstallationGroupRequest      = "UpdateInstallationGroupRequest"
+	SchemaInstallationGroupReleaseRequest     = "InstallationGroupReleaseRequest"
+	SchemaCreateWebhookRequest                = "CreateWebhookRequest"
+	SchemaCreateTokenRequest                  = "CreateTokenRequest"
+	SchemaCreateRolloutRequest                = "CreateRolloutRequest"
+	SchemaCreateJobRequest                    = "CreateJobRequest"
+	SchemaNoteRequest                         = "NoteRequest"
+	SchemaReleaseLink                         = "ReleaseLink"
+	SchemaReleaseLabels                       = "ReleaseLabels"
+	SchemaRingProtectionRequest               = "RingProtectionRequest"
+	SchemaRescheduleWorkRequest               = "RescheduleWorkRequest"
+	SchemaRotateProvisionerCredentialsRequest = "RotateProvisionerCredentialsRequest"
+	SchemaProvisionerCallback                 = "ProvisionerCallback"
+	SchemaFleetSpec                           = "FleetSpec"
+	SchemaCreateNotificationTemplateRequest   = "CreateNotificationTemplateRequest"
+	SchemaUpdateNotificationTemplateRequest   = "UpdateNotificationTemplateRequest"
+)
+
+// maxSchemaErrors bounds the errors reported for a single payload.
+const maxSchemaErrors = 20
+
+// requestSchemaTypes are the types of the request payloads by schema name.
+var requestSchemaTypes = map[string]reflect.Type{
+	SchemaCreateRingRequest:                   reflect.TypeOf(CreateRingRequest{}),
+	SchemaUpdateRingRequest:                   reflect.TypeOf(UpdateRingRequest{}),
+	SchemaRingReleaseRequest:                  reflect.TypeOf(RingReleaseRequest{}),
+	SchemaRegisterInstallationGroupRequest:    reflect.TypeOf(RegisterInstallationGroupRequest{}),
+	SchemaUpdateInstallationGroupRequest:      reflect.TypeOf(UpdateInstallationGroupRequest{}),
+	SchemaInstallationGroupReleaseRequest:     reflect.TypeOf(InstallationGroupReleaseRequest{}),
+	SchemaCreateWebhookRequest:                reflect.TypeOf(CreateWebhookRequest{}),
+	SchemaCreateTokenRequest:                  reflect.TypeOf(CreateTokenRequest{}),
+	SchemaCreateRolloutRequest:                reflect.TypeOf(CreateRolloutRequest{}),
+	SchemaCreateJobRequest:                    reflect.TypeOf(CreateJobRequest{}),
+	SchemaNoteRequest:                         reflect.TypeOf(NoteRequest{}),
+	SchemaReleaseLink:                         reflect.TypeOf(ReleaseLink{}),
+	SchemaReleaseLabels:                       reflect.TypeOf(ReleaseLabels{}),
+	SchemaRingProtectionRequest:               reflect.TypeOf(RingProtectionRequest{}),
+	SchemaRescheduleWorkRequest:               reflect.TypeOf(RescheduleWorkRequest{}),
+	SchemaRotateProvisionerCredentialsRequest: reflect.TypeOf(RotateProvisionerCredentialsRequest{}),
+	SchemaProvisionerCallback:                 reflect.TypeOf(ProvisionerCallback{}),
+	SchemaFleetSpec:                           reflect.TypeOf(FleetSpec{}),
+	SchemaCreateNotificationTemplateRequest:   reflect.TypeOf(CreateNotificationTemplateRequest{}),
+	SchemaUpdateNotificationTemplateRequest:   reflect.TypeOf(UpdateNotificationTemplateRequest{}),
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*goencoding.TextUnmarshaler)(nil)).Elem()
+	schemaPathKeyRegex  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Schema is the subset of JSON Schema describing the request payloads of the
+// API, generated from the types they are decoded into.
+type Schema struct {
+	Schema string `json:"$schema,omitempty"`
+	Title  string `json:"title,omitempty"`
+	// Type lists the JSON types of the value. An empty list allows any
+	// value.
+	Type       []string           `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// AdditionalProperties is false for objects decoded into structs, which
+	// have no other properties, and the *Schema of the values of maps.
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	Items                *Schema     `json:"items,omitempty"`
+}
+
+// SchemaError is a value of a request payload not matching its schema, at
+// the JSON path of the value, such as $.rings[0].name.
+type SchemaError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Error implements error.
+func (e *SchemaError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// SchemaErrors are the values of a request payload not matching its schema.
+type SchemaErrors []*SchemaError
+
+// Error implements error.
+func (e SchemaErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, schemaError := range e {
+		messages = append(messages, schemaError.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Details returns the messages of the errors by path, as reported in the
+// details of API errors.
+func (e SchemaErrors) Details() map[string]string {
+	details := make(map[string]string, len(e))
+	for _, schemaError := range e {
+		details[schemaError.Path] = schemaError.Message
+	}
+
+	return details
+}
+
+// RequestSchemaNames returns the names of the request schemas, sorted.
+func RequestSchemaNames() []string {
+	names := make([]string, 0, len(requestSchemaTypes))
+	for name := range requestSchemaTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// RequestSchema returns the schema of the request payload with the given
+// name, or nil if there is no such schema.
+func RequestSchema(name string) *Schema {
+	requestType, ok := requestSchemaTypes[name]
+	if !ok {
+		return nil
+	}
+
+	schema := schemaForType(requestType, make(map[reflect.Type]bool))
+	schema.Schema = SchemaDraft
+	schema.Title = name
+
+	return schema
+}
+
+// RequestSchemas returns the schemas of every request payload by name.
+func RequestSchemas() map[string]*Schema {
+	schemas := make(map[string]*Schema, len(requestSchemaTypes))
+	for name := range requestSchemaTypes {
+		schemas[name] = RequestSchema(name)
+	}
+
+	return schemas
+}
+
+// RequestSchemaName returns the name of the schema of the given request
+// payload, or an empty string if it has none.
+func RequestSchemaName(request interface{}) string {
+	requestType := reflect.TypeOf(request)
+	for requestType != nil && requestType.Kind() == reflect.Ptr {
+		requestType = requestType.Elem()
+	}
+	for name, schemaType := range requestSchemaTypes {
+		if schemaType == requestType {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// ValidateRequestJSON validates a json-encoded request payload against the
+// schema with the given name, returning SchemaErrors locating every value
+// not matching it. An empty or null payload is valid, like for the request
+// decoders.
+func ValidateRequestJSON(name string, data []byte) error {
+	schema := RequestSchema(name)
+	if schema == nil {
+		return errors.Errorf("unknown request schema %q", name)
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		message := err.Error()
+		if syntaxErr, ok := err.(*json.SyntaxError); ok {
+			message = fmt.Sprintf("invalid JSON at offset %d: %s", syntaxErr.Offset, syntaxErr)
+		} else if err == io.ErrUnexpectedEOF {
+			message = "invalid JSON: unexpected end of input"
+		}
+		return SchemaErrors{{Path: "$", Message: message}}
+	}
+	if value == nil {
+		return nil
+	}
+
+	var schemaErrors SchemaErrors
+	schema.validate("$", value, &schemaErrors)
+	if len(schemaErrors) > 0 {
+		return schemaErrors
+	}
+
+	return nil
+}
+
+// schemaForType generates the schema of the values decoded into the given
+// type by encoding/json. Types seen are those being generated, allowing any
+// value for recursive types.
+func schemaForType(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	if t.Kind() == reflect.Ptr {
+		schema := schemaForType(t.Elem(), seen)
+		if len(schema.Type) > 0 && !schema.allowsType("null") {
+			schema.Type = append(schema.Type, "null")
+		}
+		return schema
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return &Schema{}
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return &Schema{Type: []string{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: []string{"string"}}
+	case reflect.Bool:
+		return &Schema{Type: []string{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: []string{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: []string{"number"}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: []string{"string", "null"}, Format: "byte"}
+		}
+		return &Schema{Type: []string{"array", "null"}, Items: schemaForType(t.Elem(), seen)}
+	case reflect.Map:
+		return &Schema{Type: []string{"object", "null"}, AdditionalProperties: schemaForType(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return &Schema{}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		schema := &Schema{
+			Type:                 []string{"object"},
+			Properties:           make(map[string]*Schema),
+			AdditionalProperties: false,
+		}
+		addStructProperties(schema, t, seen)
+		return schema
+	}
+
+	return &Schema{}
+}
+
+// addStructProperties adds the properties of the fields of the given struct
+// type to the schema, including those of embedded structs.
+func addStructProperties(schema *Schema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			addStructProperties(schema, fieldType, seen)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = schemaForType(field.Type, seen)
+	}
+}
+
+// validate adds the errors of the given value decoded from JSON, at the
+// given path, not matching the schema.
+func (s *Schema) validate(path string, value interface{}, schemaErrors *SchemaErrors) {
+	if len(*schemaErrors) >= maxSchemaErrors {
+		return
+	}
+
+	valueType := jsonType(value)
+	if len(s.Type) > 0 && !s.allowsType(valueType) {
+		*schemaErrors = append(*schemaErrors, &SchemaError{
+			Path:    path,
+			Message: fmt.Sprintf("expected %s, got %s", strings.Join(s.Type, " or "), valueType),
+		})
+		return
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		if s.Items == nil {
+			return
+		}
+		for i, item := range v {
+			s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, schemaErrors)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			keyPath := schemaPropertyPath(path, key)
+			if property := s.property(key); property != nil {
+				property.validate(keyPath, v[key], schemaErrors)
+				continue
+			}
+			switch additional := s.AdditionalProperties.(type) {
+			case *Schema:
+				additional.validate(keyPath, v[key], schemaErrors)
+			case bool:
+				if !additional && len(*schemaErrors) < maxSchemaErrors {
+					*schemaErrors = append(*schemaErrors, &SchemaError{Path: keyPath, Message: "unknown property"})
+				}
+			}
+		}
+	}
+}
+
+// allowsType returns whether the schema allows values of the given JSON
+// type. Integers are numbers.
+func (s *Schema) allowsType(valueType string) bool {
+	for _, schemaType := range s.Type {
+		if schemaType == valueType || (schemaType == "number" && valueType == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// property returns the schema of the property with the given name, matched
+// case-insensitively when there is no exact match, like encoding/json does.
+func (s *Schema) property(name string) *Schema {
+	if property, ok := s.Properties[name]; ok {
+		return property
+	}
+	for propertyName, property := range s.Properties {
+		if strings.EqualFold(propertyName, name) {
+			return property
+		}
+	}
+
+	return nil
+}
+
+// jsonType returns the JSON type of a value decoded with json.Number
+// numbers.
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+// schemaPropertyPath returns the path of the property with the given name of
+// the object at the given path.
+func schemaPropertyPath(path, name string) string {
+	if schemaPathKeyRegex.MatchString(name) {
+		return path + "." + name
+	}
+
+	return fmt.Sprintf("%s[%q]", path, name)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestSchema(t *testing.T) {
+	require.Nil(t, RequestSchema("Unknown"))
+	require.Len(t, RequestSchemas(), len(RequestSchemaNames()))
+
+	schema := RequestSchema(SchemaUpdateRingRequest)
+	require.Equal(t, SchemaDraft, schema.Schema)
+	require.Equal(t, SchemaUpdateRingRequest, schema.Title)
+	require.Equal(t, []string{"object"}, schema.Type)
+	require.Equal(t, false, schema.AdditionalProperties)
+	require.Equal(t, []string{"integer"}, schema.Properties["soakTime"].Type)
+	require.Equal(t, []string{"integer", "null"}, schema.Properties["maxConcurrency"].Type)
+	require.Equal(t, []string{"array", "null"}, schema.Properties["dependsOn"].Type)
+	require.Equal(t, []string{"object", "null"}, schema.Properties["annotations"].Type)
+
+	schema = RequestSchema(SchemaRingReleaseRequest)
+	require.Contains(t, schema.Properties, "Image")
+	require.Equal(t, []string{"string"}, schema.Properties["Links"].Items.Properties["URL"].Type)
+
+	data, err := json.Marshal(RequestSchema(SchemaReleaseLabels))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"$schema":"`+SchemaDraft+`","title":"ReleaseLabels","type":["array","null"],"items":{"type":["string"]}}`, string(data))
+}
+
+func TestRequestSchemaName(t *testing.T) {
+	require.Equal(t, SchemaCreateRingRequest, RequestSchemaName(&CreateRingRequest{}))
+	require.Equal(t, SchemaReleaseLabels, RequestSchemaName(ReleaseLabels{}))
+	require.Equal(t, "", RequestSchemaName(&Ring{}))
+	require.Equal(t, "", RequestSchemaName(nil))
+}
+
+func TestValidateRequestJSON(t *testing.T) {
+	requireSchemaErrors := func(t *testing.T, err error, expected map[string]string) {
+		t.Helper()
+		require.Error(t, err)
+		schemaErrors, ok := err.(SchemaErrors)
+		require.True(t, ok, "expected schema errors, got %v", err)
+		require.Equal(t, expected, schemaErrors.Details())
+	}
+
+	t.Run("unknown schema", func(t *testing.T) {
+		err := ValidateRequestJSON("Unknown", []byte(`{}`))
+		require.Error(t, err)
+		_, ok := err.(SchemaErrors)
+		require.False(t, ok)
+	})
+
+	t.Run("empty or null", func(t *testing.T) {
+		require.NoError(t, ValidateRequestJSON(SchemaCreateRingRequest, nil))
+		require.NoError(t, ValidateRequestJSON(SchemaCreateRingRequest, []byte(" \n")))
+		require.NoError(t, ValidateRequestJSON(SchemaCreateRingRequest, []byte(`null`)))
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		data, err := json.Marshal(&CreateRingRequest{
+			Name:           "ring1",
+			Priority:       1,
+			Annotations:    Annotations{"team": "cloud"},
+			ReleaseWindows: TimeWindows{{Start: "09:00", End: "17:00"}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, ValidateRequestJSON(SchemaCreateRingRequest, data))
+
+		require.NoError(t, ValidateRequestJSON(SchemaUpdateRingRequest, []byte(`{"maxConcurrency":null,"dependsOn":[]}`)))
+	})
+
+	t.Run("properties are matched case-insensitively", func(t *testing.T) {
+		require.NoError(t, ValidateRequestJSON(SchemaRingReleaseRequest, []byte(`{"image":"mattermost/mattermost","VERSION":"7.0.0"}`)))
+	})
+
+	t.Run("wrong types", func(t *testing.T) {
+		err := ValidateRequestJSON(SchemaCreateRingRequest, []byte(`{"priority":"1","soakTime":1.5,"apiSecurityLock":"yes","annotations":{"team-owner":1}}`))
+		requireSchemaErrors(t, err, map[string]string{
+			`$.annotations["team-owner"]`: "expected string, got integer",
+			"$.apiSecurityLock":           "expected boolean, got string",
+			"$.priority":                  "expected integer, got string",
+			"$.soakTime":                  "expected integer, got number",
+		})
+	})
+
+	t.Run("nested paths", func(t *testing.T) {
+		err := ValidateRequestJSON(SchemaFleetSpec, []byte(`{"rings":[{"name":"ring1","priority":1},{"name":"ring2","priority":2,"installationGroups":[{"name":"ig1","soakTime":"1h"}]}]}`))
+		requireSchemaErrors(t, err, map[string]string{
+			"$.rings[1].installationGroups[0].soakTime": "expected integer, got string",
+		})
+
+		err = ValidateRequestJSON(SchemaReleaseLabels, []byte(`["quarterly",1]`))
+		requireSchemaErrors(t, err, map[string]string{"$[1]": "expected string, got integer"})
+	})
+
+	t.Run("unknown properties", func(t *testing.T) {
+		err := ValidateRequestJSON(SchemaFleetSpec, []byte(`{"rings":[{"name":"ring1","priority":1,"soakTme":60}]}`))
+		requireSchemaErrors(t, err, map[string]string{"$.rings[0].soakTme": "unknown property"})
+		require.EqualError(t, err, "$.rings[0].soakTme: unknown property")
+	})
+
+	t.Run("null values", func(t *testing.T) {
+		err := ValidateRequestJSON(SchemaCreateRingRequest, []byte(`{"priority":null}`))
+		requireSchemaErrors(t, err, map[string]string{"$.priority": "expected integer, got null"})
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		err := ValidateRequestJSON(SchemaCreateRingRequest, []byte(`{"priority":1,}`))
+		requireSchemaErrors(t, err, map[string]string{"$": "invalid JSON at offset 15: invalid character '}' looking for beginning of object key string"})
+
+		err = ValidateRequestJSON(SchemaCreateRingRequest, []byte(`{"priority":1`))
+		requireSchemaErrors(t, err, map[string]string{"$": "invalid JSON: unexpected end of input"})
+	})
+
+	t.Run("errors are bounded", func(t *testing.T) {
+		err := ValidateRequestJSON(SchemaReleaseLabels, []byte(`[1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20,21,22]`))
+		require.Error(t, err)
+		require.Len(t, err.(SchemaErrors), maxSchemaErrors)
+	})
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SoakCheckSoakTime is the check that the soak time of an installation
+	// group elapsed. Its observed value and threshold are in seconds.
+	SoakCheckSoakTime = "soak-time"
+	// SoakCheckProvisioner is the check that the provisioner reports the
+	// installation group as healthy after soaking. Its observed value is 1
+	// when the provisioner reported no error.
+	SoakCheckProvisioner = "provisioner"
+)
+
+const (
+	// SoakCheckBelow passes while the observed value is below the threshold.
+	SoakCheckBelow = "<"
+	// SoakCheckAtMost passes while the observed value is at most the
+	// threshold.
+	SoakCheckAtMost = "<="
+	// SoakCheckAbove passes while the observed value is above the threshold.
+	SoakCheckAbove = ">"
+	// SoakCheckAtLeast passes while the observed value is at least the
+	// threshold.
+	SoakCheckAtLeast = ">="
+	// SoakCheckEqual passes while the observed value equals the threshold.
+	SoakCheckEqual = "=="
+	// SoakCheckNotEqual passes while the observed value differs from the
+	// threshold.
+	SoakCheckNotEqual = "!="
+)
+
+// SoakCheckComparisons are the supported soak check comparisons.
+var SoakCheckComparisons = []string{
+	SoakCheckBelow,
+	SoakCheckAtMost,
+	SoakCheckAbove,
+	SoakCheckAtLeast,
+	SoakCheckEqual,
+	SoakCheckNotEqual,
+}
+
+var soakCheckRegex = regexp.MustCompile(`^(.+?)\s*(<=|>=|==|!=|<|>)\s*([-+]?[0-9.]+(?:[eE][-+]?[0-9]+)?)$`)
+
+// SoakCheck is a metrics query an installation group declares, whose result
+// must compare to the threshold for as long as the installation group soaks.
+type SoakCheck struct {
+	Name       string  `json:"name"`
+	Query      string  `json:"query"`
+	Comparison string  `json:"comparison"`
+	Threshold  float64 `json:"threshold"`
+}
+
+// ParseSoakCheck parses a soak check given as <name>=<query> <comparison>
+// <threshold>, for example error-rate=sum(rate(errors[5m])) < 0.01.
+func ParseSoakCheck(value string) (*SoakCheck, error) {
+	name, expression, ok := strings.Cut(value, "=")
+	if !ok {
+		return nil, errors.Errorf("invalid soak check %q, must be <name>=<query> <comparison> <threshold>", value)
+	}
+	matches := soakCheckRegex.FindStringSubmatch(strings.TrimSpace(expression))
+	if matches == nil {
+		return nil, errors.Errorf("invalid soak check %q, must be <name>=<query> <comparison> <threshold>", value)
+	}
+	threshold, err := strconv.ParseFloat(matches[3], 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid threshold of soak check %q", value)
+	}
+
+	check := &SoakCheck{
+		Name:       strings.TrimSpace(name),
+		Query:      matches[1],
+		Comparison: matches[2],
+		Threshold:  threshold,
+	}
+	if err = check.Validate(); err != nil {
+		return nil, err
+	}
+
+	return check, nil
+}
+
+// Validate validates the values of a soak check.
+func (c *SoakCheck) Validate() error {
+	if err := ValidateName(c.Name); err != nil {
+		return errors.Wrap(err, "invalid soak check name")
+	}
+	if c.Name == SoakCheckSoakTime || c.Name == SoakCheckProvisioner {
+		return errors.Errorf("soak check name %q is reserved", c.Name)
+	}
+	if strings.TrimSpace(c.Query) == "" {
+		return errors.Errorf("soak check %s must have a query", c.Name)
+	}
+	for _, comparison := range SoakCheckComparisons {
+		if c.Comparison == comparison {
+			return nil
+		}
+	}
+
+	return errors.Errorf("unknown comparison %q of soak check %s, must be one of %s", c.Comparison, c.Name, strings.Join(SoakCheckComparisons, " "))
+}
+
+// Passes returns whether the given observed value satisfies the check.
+func (c *SoakCheck) Passes(value float64) bool {
+	switch c.Comparison {
+	case SoakCheckBelow:
+		return value < c.Threshold
+	case SoakCheckAtMost:
+		return value <= c.Threshold
+	case SoakCheckAbove:
+		return value > c.Threshold
+	case SoakCheckAtLeast:
+		return value >= c.Threshold
+	case SoakCheckEqual:
+		return value == c.Threshold
+	case SoakCheckNotEqual:
+		return value != c.Threshold
+	}
+
+	return false
+}
+
+// String returns the check as <name>=<query> <comparison> <threshold>.
+func (c *SoakCheck) String() string {
+	return c.Name + "=" + c.Query + " " + c.Comparison + " " + strconv.FormatFloat(c.Threshold, 'g', -1, 64)
+}
+
+// SoakChecks are the soak checks of an installation group.
+type SoakChecks []*SoakCheck
+
+// Validate validates every check, and that their names are unique.
+func (c SoakChecks) Validate() error {
+	names := make(map[string]bool, len(c))
+	for _, check := range c {
+		if check == nil {
+			return errors.New("soak check cannot be empty")
+		}
+		if err := check.Validate(); err != nil {
+			return err
+		}
+		if names[check.Name] {
+			return errors.Errorf("duplicate soak check %s", check.Name)
+		}
+		names[check.Name] = true
+	}
+
+	return nil
+}
+
+// Value implements driver.Valuer, storing the soak checks as JSON.
+func (c SoakChecks) Value() (driver.Value, error) {
+	if len(c) == 0 {
+		return "[]", nil
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal soak checks")
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner, loading the soak checks from JSON.
+func (c *SoakChecks) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.Errorf("cannot scan %T into soak checks", src)
+	}
+	if len(data) == 0 {
+		*c = nil
+		return nil
+	}
+
+	var checks SoakChecks
+	if err := json.Unmarshal(data, &checks); err != nil {
+		return errors.Wrap(err, "failed to unmarshal soak checks")
+	}
+	if len(checks) == 0 {
+		checks = nil
+	}
+	*c = checks
+
+	return nil
+}
+
+// SoakCheckResult records a single evaluation of a soak check of an
+// installation group.
+type SoakCheckResult struct {
+	ID                  string
+	InstallationGroupID string
+	RingID              string
+	ReleaseID           string
+	Name                string
+	// Query is the query evaluated by the check, if any.
+	Query         string
+	ObservedValue float64
+	Threshold     float64
+	Passed        bool
+	// Message explains why the check failed, if it did.
+	Message  string `json:",omitempty"`
+	CreateAt int64
+}
+
+// SoakCheckResultFilter describes the parameters used to constrain a set of
+// soak check results.
+type SoakCheckResultFilter struct {
+	InstallationGroupID string
+	Page                int
+	PerPage             int
+}
+
+// GetSoakCheckResultsRequest describes the parameters to request a page of
+// soak check results of an installation group.
+type GetSoakCheckResultsRequest struct {
+	Page    int
+	PerPage int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetSoakCheckResultsRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	u.RawQuery = q.Encode()
+}
+
+// SoakCheckResultsFromReader decodes a json-encoded list of soak check results from the given io.Reader.
+func SoakCheckResultsFromReader(reader io.Reader) ([]*SoakCheckResult, error) {
+	results := []*SoakCheckResult{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&results)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return results, nil
+}
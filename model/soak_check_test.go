@@ -0,0 +1,99 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSoakCheck(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		testCases := []struct {
+			value    string
+			expected SoakCheck
+		}{
+			{"error-rate=sum(rate(errors[5m])) < 0.01", SoakCheck{Name: "error-rate", Query: "sum(rate(errors[5m]))", Comparison: SoakCheckBelow, Threshold: 0.01}},
+			{"up=min(up{job=\"app\"})>=1", SoakCheck{Name: "up", Query: "min(up{job=\"app\"})", Comparison: SoakCheckAtLeast, Threshold: 1}},
+			{"latency=histogram_quantile(0.99, rate(latency_bucket[5m])) <= 2.5e-1", SoakCheck{Name: "latency", Query: "histogram_quantile(0.99, rate(latency_bucket[5m]))", Comparison: SoakCheckAtMost, Threshold: 0.25}},
+			{"restarts=sum(restarts) > 0 == 0", SoakCheck{Name: "restarts", Query: "sum(restarts) > 0", Comparison: SoakCheckEqual, Threshold: 0}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.value, func(t *testing.T) {
+				check, err := ParseSoakCheck(tc.value)
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, *check)
+			})
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, value := range []string{
+			"sum(rate(errors[5m])) < 0.01",
+			"error-rate=sum(rate(errors[5m]))",
+			"error-rate=< 0.01",
+			"error-rate=sum(errors) < high",
+			"soak-time=sum(errors) < 1",
+			"bad name=sum(errors) < 1",
+		} {
+			_, err := ParseSoakCheck(value)
+			require.Error(t, err, value)
+		}
+	})
+}
+
+func TestSoakCheckPasses(t *testing.T) {
+	testCases := []struct {
+		comparison string
+		passing    []float64
+		failing    []float64
+	}{
+		{SoakCheckBelow, []float64{0}, []float64{1, 2}},
+		{SoakCheckAtMost, []float64{0, 1}, []float64{2}},
+		{SoakCheckAbove, []float64{2}, []float64{0, 1}},
+		{SoakCheckAtLeast, []float64{1, 2}, []float64{0}},
+		{SoakCheckEqual, []float64{1}, []float64{0, 2}},
+		{SoakCheckNotEqual, []float64{0, 2}, []float64{1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.comparison, func(t *testing.T) {
+			check := &SoakCheck{Name: "check", Query: "q", Comparison: tc.comparison, Threshold: 1}
+			for _, value := range tc.passing {
+				require.True(t, check.Passes(value), value)
+			}
+			for _, value := range tc.failing {
+				require.False(t, check.Passes(value), value)
+			}
+		})
+	}
+}
+
+func TestSoakChecksValidate(t *testing.T) {
+	check := &SoakCheck{Name: "error-rate", Query: "sum(errors)", Comparison: SoakCheckBelow, Threshold: 1}
+
+	require.NoError(t, SoakChecks{check}.Validate())
+	require.Error(t, SoakChecks{check, check}.Validate())
+	require.Error(t, SoakChecks{nil}.Validate())
+	require.Error(t, SoakChecks{{Name: "empty", Comparison: SoakCheckBelow}}.Validate())
+	require.Error(t, SoakChecks{{Name: "unknown", Query: "sum(errors)", Comparison: "=<"}}.Validate())
+}
+
+func TestSoakChecksScan(t *testing.T) {
+	checks := SoakChecks{{Name: "error-rate", Query: "sum(errors)", Comparison: SoakCheckBelow, Threshold: 1}}
+	value, err := checks.Value()
+	require.NoError(t, err)
+
+	var scanned SoakChecks
+	require.NoError(t, scanned.Scan(value))
+	require.Equal(t, checks, scanned)
+
+	value, err = SoakChecks{}.Value()
+	require.NoError(t, err)
+	require.NoError(t, scanned.Scan(value))
+	require.Nil(t, scanned)
+}
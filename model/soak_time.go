@@ -0,0 +1,87 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+// DefaultSoakTime is the soak time, in seconds, used when no level of the soak
+// time hierarchy configures one.
+const DefaultSoakTime = 7200
+
+// SoakTimeDefaults are the server-wide soak times, in seconds, inherited by the
+// rings and installation groups that do not configure their own.
+//
+// Soak times are resolved from the least to the most specific level: server
+// default, environment, ring, installation group and release override. The
+// most specific non-zero value wins. Hotfix releases without an override are
+// soaked for at most the hotfix soak time.
+type SoakTimeDefaults struct {
+	Server      int
+	Environment int
+	Hotfix      int
+}
+
+// ResolveRingSoakTime returns the soak time of the given ring for the given
+// release, which may be nil.
+func (d SoakTimeDefaults) ResolveRingSoakTime(ring *Ring, release *RingRelease) int {
+	if release != nil && release.SoakTime > 0 {
+		return release.SoakTime
+	}
+
+	return d.capHotfix(firstSoakTime(ring.SoakTime, d.Environment, d.Server), release)
+}
+
+// ResolveInstallationGroupSoakTime returns the soak time of the given
+// installation group of the ring for the given release, which may be nil.
+func (d SoakTimeDefaults) ResolveInstallationGroupSoakTime(installationGroup *InstallationGroup, ring *Ring, release *RingRelease) int {
+	if release != nil && release.SoakTime > 0 {
+		return release.SoakTime
+	}
+
+	return d.capHotfix(firstSoakTime(installationGroup.SoakTime, ring.SoakTime, d.Environment, d.Server), release)
+}
+
+// capHotfix shortens the given soak time to the hotfix soak time for hotfix
+// releases.
+func (d SoakTimeDefaults) capHotfix(soakTime int, release *RingRelease) int {
+	if release == nil || !release.IsHotfix() || d.Hotfix <= 0 {
+		return soakTime
+	}
+	if soakTime == 0 || soakTime > d.Hotfix {
+		return d.Hotfix
+	}
+
+	return soakTime
+}
+
+// CurrentSoakTime returns the soak time resolved for the current release of the
+// ring, falling back to its configured soak time for releases started before
+// soak times were resolved.
+func (c *Ring) CurrentSoakTime() int {
+	if c.ReleaseSoakTime != 0 {
+		return c.ReleaseSoakTime
+	}
+
+	return c.SoakTime
+}
+
+// CurrentSoakTime returns the soak time resolved for the current release of the
+// installation group, falling back to its configured soak time for releases
+// started before soak times were resolved.
+func (ig *InstallationGroup) CurrentSoakTime() int {
+	if ig.ReleaseSoakTime != 0 {
+		return ig.ReleaseSoakTime
+	}
+
+	return ig.SoakTime
+}
+
+func firstSoakTime(soakTimes ...int) int {
+	for _, soakTime := range soakTimes {
+		if soakTime > 0 {
+			return soakTime
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	// MinSoakTimeSamples is the number of completed soaks of a ring required
+	// before its soak time is adjusted.
+	MinSoakTimeSamples = 5
+	// MinSuggestedSoakTime is the shortest soak time, in seconds, suggested
+	// when shortening the soak time of a ring.
+	MinSuggestedSoakTime = 15 * 60
+)
+
+// SoakTimeReport suggests soak time adjustments for every ring from the
+// outcomes of its previous soaks.
+type SoakTimeReport struct {
+	GeneratedAt int64
+	Rings       []*SoakTimeSuggestion
+}
+
+// SoakTimeSuggestion is the suggested soak time of a ring. All times are in
+// seconds.
+type SoakTimeSuggestion struct {
+	RingID            string
+	RingName          string
+	SoakTime          int
+	SuggestedSoakTime int
+	// Soaks and Failures are the number of completed and failed soaks the
+	// suggestion is based on.
+	Soaks    int
+	Failures int
+	// MaxTimeToFailure is the longest a failed soak ran before failing.
+	MaxTimeToFailure int `json:",omitempty"`
+	Reason           string
+}
+
+// Changed returns whether the suggested soak time differs from the current one.
+func (s *SoakTimeSuggestion) Changed() bool {
+	return s.SuggestedSoakTime != s.SoakTime
+}
+
+// SuggestSoakTime suggests the soak time of a ring from its state change
+// events, sorted by timestamp.
+//
+// Failures late in a soak suggest more are missed by ending it, so the soak
+// time is raised to half again the longest time to failure. Without such
+// failures, a ring that soaked enough times is shortened by a quarter, down
+// to the longest time to failure with the same margin, or to
+// MinSuggestedSoakTime.
+func SuggestSoakTime(ring *Ring, events []*StateChangeEvent) *SoakTimeSuggestion {
+	suggestion := &SoakTimeSuggestion{
+		RingID:            ring.ID,
+		RingName:          ring.Name,
+		SoakTime:          ring.SoakTime,
+		SuggestedSoakTime: ring.SoakTime,
+	}
+
+	var soaking bool
+	var soakStartAt int64
+	for _, event := range events {
+		if event.ResourceType != TypeRing {
+			continue
+		}
+		if event.NewState == RingStateSoakingRequested {
+			soaking = true
+			soakStartAt = event.Timestamp
+			continue
+		}
+		if !soaking {
+			continue
+		}
+
+		switch event.NewState {
+		case RingStateStable:
+			suggestion.Soaks++
+		case RingStateSoakingFailed:
+			suggestion.Soaks++
+			suggestion.Failures++
+			timeToFailure := int((event.Timestamp - soakStartAt) / 1000)
+			if timeToFailure > suggestion.MaxTimeToFailure {
+				suggestion.MaxTimeToFailure = timeToFailure
+			}
+		}
+		soaking = false
+	}
+
+	if suggestion.Soaks < MinSoakTimeSamples {
+		suggestion.Reason = fmt.Sprintf("only %d soaks completed, at least %d are needed", suggestion.Soaks, MinSoakTimeSamples)
+		return suggestion
+	}
+
+	required := roundUpToMinute(suggestion.MaxTimeToFailure * 3 / 2)
+	if required > ring.SoakTime {
+		suggestion.SuggestedSoakTime = required
+		suggestion.Reason = fmt.Sprintf("%d of %d soaks failed, up to %ds after the soak started", suggestion.Failures, suggestion.Soaks, suggestion.MaxTimeToFailure)
+		return suggestion
+	}
+
+	shortened := roundUpToMinute(ring.SoakTime * 3 / 4)
+	if shortened < required {
+		shortened = required
+	}
+	if shortened < MinSuggestedSoakTime {
+		shortened = MinSuggestedSoakTime
+	}
+	if shortened >= ring.SoakTime {
+		suggestion.Reason = "the soak time cannot be shortened further"
+		return suggestion
+	}
+
+	suggestion.SuggestedSoakTime = shortened
+	if suggestion.Failures == 0 {
+		suggestion.Reason = fmt.Sprintf("none of %d soaks failed", suggestion.Soaks)
+	} else {
+		suggestion.Reason = fmt.Sprintf("%d of %d soaks failed, all within %ds after the soak started", suggestion.Failures, suggestion.Soaks, suggestion.MaxTimeToFailure)
+	}
+
+	return suggestion
+}
+
+// BuildSoakTimeReport suggests the soak time of each of the given rings from
+// the given state change events of all rings, sorted by timestamp.
+func BuildSoakTimeReport(rings []*Ring, events []*StateChangeEvent, now int64) *SoakTimeReport {
+	ringEvents := make(map[string][]*StateChangeEvent)
+	for _, event := range events {
+		ringEvents[event.RingID] = append(ringEvents[event.RingID], event)
+	}
+
+	report := &SoakTimeReport{GeneratedAt: now, Rings: []*SoakTimeSuggestion{}}
+	for _, ring := range rings {
+		report.Rings = append(report.Rings, SuggestSoakTime(ring, ringEvents[ring.ID]))
+	}
+
+	return report
+}
+
+// roundUpToMinute rounds the given number of seconds up to a whole minute.
+func roundUpToMinute(seconds int) int {
+	return (seconds + 59) / 60 * 60
+}
+
+// SoakTimeReportFromReader decodes a json-encoded soak time report from the given io.Reader.
+func SoakTimeReportFromReader(reader io.Reader) (*SoakTimeReport, error) {
+	report := SoakTimeReport{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&report)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &report, nil
+}
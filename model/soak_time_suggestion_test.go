@@ -0,0 +1,89 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestSoakTime(t *testing.T) {
+	// soaks returns the events of the given number of soaks, failing after
+	// the given number of seconds if not 0.
+	soaks := func(count int, failAfter int64) []*StateChangeEvent {
+		var events []*StateChangeEvent
+		for i := 0; i < count; i++ {
+			start := int64(i) * 100000000
+			events = append(events, &StateChangeEvent{ResourceType: TypeRing, RingID: "ring", NewState: RingStateSoakingRequested, Timestamp: start})
+			if failAfter != 0 {
+				events = append(events, &StateChangeEvent{ResourceType: TypeRing, RingID: "ring", NewState: RingStateSoakingFailed, Timestamp: start + failAfter*1000})
+			} else {
+				events = append(events, &StateChangeEvent{ResourceType: TypeRing, RingID: "ring", NewState: RingStateStable, Timestamp: start + 1000})
+			}
+		}
+		return events
+	}
+	ring := &Ring{ID: "ring", Name: "production", SoakTime: 4 * 3600}
+
+	t.Run("not enough soaks", func(t *testing.T) {
+		suggestion := SuggestSoakTime(ring, soaks(MinSoakTimeSamples-1, 0))
+		require.False(t, suggestion.Changed())
+		require.Equal(t, MinSoakTimeSamples-1, suggestion.Soaks)
+	})
+
+	t.Run("no failures", func(t *testing.T) {
+		suggestion := SuggestSoakTime(ring, soaks(MinSoakTimeSamples, 0))
+		require.True(t, suggestion.Changed())
+		require.Equal(t, 3*3600, suggestion.SuggestedSoakTime)
+		require.Zero(t, suggestion.Failures)
+	})
+
+	t.Run("shortened down to the minimum", func(t *testing.T) {
+		suggestion := SuggestSoakTime(&Ring{ID: "ring", SoakTime: 1000}, soaks(MinSoakTimeSamples, 0))
+		require.Equal(t, MinSuggestedSoakTime, suggestion.SuggestedSoakTime)
+
+		suggestion = SuggestSoakTime(&Ring{ID: "ring", SoakTime: MinSuggestedSoakTime}, soaks(MinSoakTimeSamples, 0))
+		require.False(t, suggestion.Changed())
+	})
+
+	t.Run("late failures", func(t *testing.T) {
+		events := append(soaks(MinSoakTimeSamples, 0), soaks(1, 3*3600+1800)...)
+		suggestion := SuggestSoakTime(ring, events)
+		require.Equal(t, 1, suggestion.Failures)
+		require.Equal(t, 3*3600+1800, suggestion.MaxTimeToFailure)
+		require.Equal(t, 18900, suggestion.SuggestedSoakTime)
+	})
+
+	t.Run("early failures", func(t *testing.T) {
+		suggestion := SuggestSoakTime(ring, soaks(MinSoakTimeSamples, 2*3600+1))
+		require.Equal(t, MinSoakTimeSamples, suggestion.Failures)
+		require.Equal(t, 3*3600+60, suggestion.SuggestedSoakTime)
+	})
+
+	t.Run("interrupted soaks", func(t *testing.T) {
+		events := soaks(MinSoakTimeSamples, 0)
+		events = append(events,
+			&StateChangeEvent{ResourceType: TypeRing, RingID: "ring", NewState: RingStateSoakingRequested, Timestamp: 1},
+			&StateChangeEvent{ResourceType: TypeInstallationGroup, RingID: "ring", NewState: InstallationGroupStable, Timestamp: 2},
+			&StateChangeEvent{ResourceType: TypeRing, RingID: "ring", NewState: RingStateDeletionRequested, Timestamp: 3},
+			&StateChangeEvent{ResourceType: TypeRing, RingID: "ring", NewState: RingStateStable, Timestamp: 4},
+		)
+		suggestion := SuggestSoakTime(ring, events)
+		require.Equal(t, MinSoakTimeSamples, suggestion.Soaks)
+	})
+}
+
+func TestBuildSoakTimeReport(t *testing.T) {
+	report := BuildSoakTimeReport([]*Ring{{ID: "ring1"}, {ID: "ring2"}}, []*StateChangeEvent{
+		{ResourceType: TypeRing, RingID: "ring2", NewState: RingStateSoakingRequested, Timestamp: 1},
+		{ResourceType: TypeRing, RingID: "ring2", NewState: RingStateStable, Timestamp: 2},
+	}, 10)
+
+	require.Equal(t, int64(10), report.GeneratedAt)
+	require.Len(t, report.Rings, 2)
+	require.Zero(t, report.Rings[0].Soaks)
+	require.Equal(t, 1, report.Rings[1].Soaks)
+}
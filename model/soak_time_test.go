@@ -0,0 +1,92 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSoakTime(t *testing.T) {
+	var testCases = []struct {
+		testName                          string
+		defaults                          model.SoakTimeDefaults
+		ring                              *model.Ring
+		installationGroup                 *model.InstallationGroup
+		release                           *model.RingRelease
+		expectedRingSoakTime              int
+		expectedInstallationGroupSoakTime int
+	}{
+		{
+			"server default",
+			model.SoakTimeDefaults{Server: 7200},
+			&model.Ring{}, &model.InstallationGroup{}, nil,
+			7200, 7200,
+		},
+		{
+			"environment",
+			model.SoakTimeDefaults{Server: 7200, Environment: 3600},
+			&model.Ring{}, &model.InstallationGroup{}, &model.RingRelease{},
+			3600, 3600,
+		},
+		{
+			"ring",
+			model.SoakTimeDefaults{Server: 7200, Environment: 3600},
+			&model.Ring{SoakTime: 1800}, &model.InstallationGroup{}, nil,
+			1800, 1800,
+		},
+		{
+			"installation group",
+			model.SoakTimeDefaults{Server: 7200, Environment: 3600},
+			&model.Ring{SoakTime: 1800}, &model.InstallationGroup{SoakTime: 600}, nil,
+			1800, 600,
+		},
+		{
+			"release override",
+			model.SoakTimeDefaults{Server: 7200, Environment: 3600},
+			&model.Ring{SoakTime: 1800}, &model.InstallationGroup{SoakTime: 600}, &model.RingRelease{SoakTime: 60},
+			60, 60,
+		},
+		{
+			"hotfix",
+			model.SoakTimeDefaults{Server: 7200, Hotfix: 600},
+			&model.Ring{SoakTime: 1800}, &model.InstallationGroup{SoakTime: 300}, &model.RingRelease{Type: model.ReleaseTypeHotfix},
+			600, 300,
+		},
+		{
+			"hotfix with release override",
+			model.SoakTimeDefaults{Server: 7200, Hotfix: 600},
+			&model.Ring{}, &model.InstallationGroup{}, &model.RingRelease{Type: model.ReleaseTypeHotfix, SoakTime: 1200},
+			1200, 1200,
+		},
+		{
+			"hotfix without hotfix soak time",
+			model.SoakTimeDefaults{Server: 7200},
+			&model.Ring{}, &model.InstallationGroup{}, &model.RingRelease{Type: model.ReleaseTypeHotfix},
+			7200, 7200,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			assert.Equal(t, tc.expectedRingSoakTime, tc.defaults.ResolveRingSoakTime(tc.ring, tc.release))
+			assert.Equal(t, tc.expectedInstallationGroupSoakTime, tc.defaults.ResolveInstallationGroupSoakTime(tc.installationGroup, tc.ring, tc.release))
+		})
+	}
+}
+
+func TestCurrentSoakTime(t *testing.T) {
+	ring := &model.Ring{SoakTime: 1800}
+	assert.Equal(t, 1800, ring.CurrentSoakTime())
+	ring.ReleaseSoakTime = 60
+	assert.Equal(t, 60, ring.CurrentSoakTime())
+
+	installationGroup := &model.InstallationGroup{SoakTime: 600}
+	assert.Equal(t, 600, installationGroup.CurrentSoakTime())
+	installationGroup.ReleaseSoakTime = 60
+	assert.Equal(t, 60, installationGroup.CurrentSoakTime())
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+// CurrentStateMachineVersion is the version of the ring and installation
+// group transition rules new entities are created with.
+//
+// To change the transition rules, add a new version with its rules to
+// ringStateMachines and installationGroupStateMachines and bump this
+// version, keeping the rules of the previous versions. Entities with a
+// release in progress during an upgrade then finish it under the rules they
+// started it with, and move to the current version once back to stable.
+const CurrentStateMachineVersion = 5
+
+// ringStateMachines holds the ring transition rules of each supported state
+// machine version.
+var ringStateMachines = map[int]func(currentState, newState string) bool{
+	1: validRingTransitionV1,
+	2: validRingTransitionV2,
+	3: validRingTransitionV3,
+	4: validRingTransitionV4,
+	5: validRingTransitionV5,
+}
+
+// installationGroupStateMachines holds the installation group transition
+// rules of each supported state machine version.
+var installationGroupStateMachines = map[int]func(currentState, newState string) bool{
+	1: validInstallationGroupTransitionV1,
+	2: validInstallationGroupTransitionV1,
+	3: validInstallationGroupTransitionV1,
+	4: validInstallationGroupTransitionV1,
+	5: validInstallationGroupTransitionV1,
+}
+
+// CurrentStateMachineVersion returns the state machine version of the ring.
+// Rings not stored yet follow the current version.
+func (c *Ring) CurrentStateMachineVersion() int {
+	if c.StateMachineVersion == 0 {
+		return CurrentStateMachineVersion
+	}
+
+	return c.StateMachineVersion
+}
+
+// SupportedStateMachine returns whether this server knows the transition
+// rules of the state machine version of the ring. Rings of a newer version
+// are left to the servers that know it, such as during a rolling upgrade.
+func (c *Ring) SupportedStateMachine() bool {
+	_, ok := ringStateMachines[c.CurrentStateMachineVersion()]
+	return ok
+}
+
+// UpgradeStateMachine moves a stable ring of an older state machine version
+// to the current version, and returns whether it did.
+func (c *Ring) UpgradeStateMachine() bool {
+	if c.State != RingStateStable || c.CurrentStateMachineVersion() >= CurrentStateMachineVersion {
+		return false
+	}
+
+	c.StateMachineVersion = CurrentStateMachineVersion
+	return true
+}
+
+// CurrentStateMachineVersion returns the state machine version of the
+// installation group. Installation groups not stored yet follow the current
+// version.
+func (i *InstallationGroup) CurrentStateMachineVersion() int {
+	if i.StateMachineVersion == 0 {
+		return CurrentStateMachineVersion
+	}
+
+	return i.StateMachineVersion
+}
+
+// SupportedStateMachine returns whether this server knows the transition
+// rules of the state machine version of the installation group.
+func (i *InstallationGroup) SupportedStateMachine() bool {
+	_, ok := installationGroupStateMachines[i.CurrentStateMachineVersion()]
+	return ok
+}
+
+// UpgradeStateMachine moves a stable installation group of an older state
+// machine version to the current version, and returns whether it did.
+func (i *InstallationGroup) UpgradeStateMachine() bool {
+	if i.State != InstallationGroupStable || i.CurrentStateMachineVersion() >= CurrentStateMachineVersion {
+		return false
+	}
+
+	i.StateMachineVersion = CurrentStateMachineVersion
+	return true
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// StateTransitionTriggerAPI marks the transitions requested through the
+	// API, validated by the state machine.
+	StateTransitionTriggerAPI = "api"
+	// StateTransitionTriggerSupervisor marks the transitions made by the
+	// supervisors while working on a ring or installation group.
+	StateTransitionTriggerSupervisor = "supervisor"
+)
+
+const (
+	// StateMachineFormatDOT renders a state machine graph in the Graphviz
+	// DOT language.
+	StateMachineFormatDOT = "dot"
+	// StateMachineFormatMermaid renders a state machine graph as a Mermaid
+	// state diagram.
+	StateMachineFormatMermaid = "mermaid"
+)
+
+// StateTransition is a transition of a state machine graph.
+type StateTransition struct {
+	From    string
+	To      string
+	Trigger string
+}
+
+// StateMachineGraph is the graph of the states of a ring or installation
+// group and the transitions between them, for a state machine version.
+type StateMachineGraph struct {
+	// ResourceType is TypeRing or TypeInstallationGroup.
+	ResourceType string
+	Version      int
+	States       []string
+	Transitions  []*StateTransition
+}
+
+// ringSupervisorTransitions are the ring transitions made by the supervisors,
+// by state the ring is worked on in.
+var ringSupervisorTransitions = map[string][]string{
+	RingStateCreationRequested:        {RingStateCreationFailed, RingStateStable},
+	RingStateReleasePending:           {RingStateReleaseBlocked, RingStateReleaseFailed, RingStateReleaseRequested},
+	RingStateReleaseBlocked:           {RingStateReleaseFailed, RingStateReleasePending, RingStateReleaseRequested},
+	RingStateReleasePrepareRequested:  {RingStateReleaseFailed, RingStateReleasePrepared},
+	RingStateReleaseRequested:         {RingStateReleaseFailed, RingStateReleaseInProgress, RingStateSoakingRequested},
+	RingStateReleaseInProgress:        {RingStateReleaseFailed, RingStateReleaseRollbackRequested, RingStateSoakingRequested, RingStateStable},
+	RingStateSoakingRequested:         {RingStateSoakingFailed, RingStateStable},
+	RingStateReleaseRollbackRequested: {RingStateReleaseRollbackComplete, RingStateReleaseRollbackFailed},
+	RingStateDeletionPending:          {RingStateDeletionRequested},
+	RingStateDeletionRequested:        {RingStateDeleted, RingStateDeletionFailed},
+}
+
+// installationGroupSupervisorTransitions are the installation group
+// transitions made by the supervisors, by state the installation group is
+// worked on in.
+var installationGroupSupervisorTransitions = map[string][]string{
+	InstallationGroupStable:                       {InstallationGroupReleaseRollbackRequested},
+	InstallationGroupReleasePending:               {InstallationGroupReleaseFailed, InstallationGroupReleaseRequested, InstallationGroupStable},
+	InstallationGroupReleaseRequested:             {InstallationGroupBlueGreenVerifyRequested, InstallationGroupReleaseFailed, InstallationGroupReleaseSoakingRequested, InstallationGroupReleaseVerificationRequested, InstallationGroupStable},
+	InstallationGroupReleaseSoakingRequested:      {InstallationGroupReleaseSoakingFailed, InstallationGroupStable},
+	InstallationGroupReleaseFailed:                {InstallationGroupReleaseRollbackRequested},
+	InstallationGroupReleaseSoakingFailed:         {InstallationGroupReleaseRollbackRequested},
+	InstallationGroupBlueGreenVerifyRequested:     {InstallationGroupBlueGreenSwitchRequested, InstallationGroupReleaseFailed},
+	InstallationGroupBlueGreenSwitchRequested:     {InstallationGroupBlueGreenTeardownRequested, InstallationGroupReleaseFailed},
+	InstallationGroupBlueGreenTeardownRequested:   {InstallationGroupReleaseFailed, InstallationGroupReleaseSoakingRequested, InstallationGroupReleaseVerificationRequested, InstallationGroupStable},
+	InstallationGroupReleaseVerificationRequested: {InstallationGroupReleaseFailed, InstallationGroupReleaseSoakingRequested, InstallationGroupStable},
+	InstallationGroupReleaseRollbackRequested:     {InstallationGroupReleaseRollbackComplete, InstallationGroupReleaseRollbackFailed},
+}
+
+// NewStateMachineGraph returns the graph of the state machine of the given
+// resource type and version, 0 meaning the current version. It includes the
+// transitions requested through the API, following the transition rules of
+// the version, and those made by the supervisors.
+func NewStateMachineGraph(resourceType string, version int) (*StateMachineGraph, error) {
+	if version == 0 {
+		version = CurrentStateMachineVersion
+	}
+
+	var states []string
+	var validTransition func(currentState, newState string) bool
+	var supervisorTransitions map[string][]string
+	switch resourceType {
+	case TypeRing:
+		states = AllRingStates
+		validTransition = ringStateMachines[version]
+		supervisorTransitions = ringSupervisorTransitions
+	case TypeInstallationGroup:
+		states = AllInstallationGroupStates
+		validTransition = installationGroupStateMachines[version]
+		supervisorTransitions = installationGroupSupervisorTransitions
+	default:
+		return nil, errors.Errorf("unsupported resource type %q, must be %s or %s", resourceType, TypeRing, TypeInstallationGroup)
+	}
+	if validTransition == nil {
+		return nil, errors.Errorf("unsupported state machine version %d", version)
+	}
+
+	graph := &StateMachineGraph{
+		ResourceType: resourceType,
+		Version:      version,
+		States:       append([]string{}, states...),
+		Transitions:  []*StateTransition{},
+	}
+	for _, from := range states {
+		for _, to := range states {
+			if from != to && validTransition(from, to) {
+				graph.Transitions = append(graph.Transitions, &StateTransition{From: from, To: to, Trigger: StateTransitionTriggerAPI})
+			}
+		}
+		for _, to := range supervisorTransitions[from] {
+			graph.Transitions = append(graph.Transitions, &StateTransition{From: from, To: to, Trigger: StateTransitionTriggerSupervisor})
+		}
+	}
+	sort.SliceStable(graph.Transitions, func(i, j int) bool {
+		if graph.Transitions[i].From != graph.Transitions[j].From {
+			return graph.Transitions[i].From < graph.Transitions[j].From
+		}
+		return graph.Transitions[i].To < graph.Transitions[j].To
+	})
+
+	return graph, nil
+}
+
+// Render renders the graph in the given format.
+func (g *StateMachineGraph) Render(format string) (string, error) {
+	switch format {
+	case StateMachineFormatDOT:
+		return g.DOT(), nil
+	case StateMachineFormatMermaid:
+		return g.Mermaid(), nil
+	}
+
+	return "", errors.Errorf("unsupported format %q, must be %s or %s", format, StateMachineFormatDOT, StateMachineFormatMermaid)
+}
+
+// DOT renders the graph in the Graphviz DOT language. Supervisor transitions
+// are dashed.
+func (g *StateMachineGraph) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", fmt.Sprintf("%s-v%d", g.ResourceType, g.Version))
+	b.WriteString("  rankdir=LR;\n")
+	for _, state := range g.States {
+		fmt.Fprintf(&b, "  %q;\n", state)
+	}
+	for _, transition := range g.Transitions {
+		style := "solid"
+		if transition.Trigger == StateTransitionTriggerSupervisor {
+			style = "dashed"
+		}
+		fmt.Fprintf(&b, "  %q -> %q [label=%q, style=%s];\n", transition.From, transition.To, transition.Trigger, style)
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid state diagram. States are declared
+// with identifiers, as Mermaid does not allow dashes in them.
+func (g *StateMachineGraph) Mermaid() string {
+	id := func(state string) string {
+		return strings.ReplaceAll(state, "-", "_")
+	}
+
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n")
+	for _, state := range g.States {
+		fmt.Fprintf(&b, "  state \"%s\" as %s\n", state, id(state))
+	}
+	for _, transition := range g.Transitions {
+		fmt.Fprintf(&b, "  %s --> %s: %s\n", id(transition.From), id(transition.To), transition.Trigger)
+	}
+
+	return b.String()
+}
+
+// StateMachineGraphFromReader decodes a json-encoded state machine graph from
+// the given io.Reader.
+func StateMachineGraphFromReader(reader io.Reader) (*StateMachineGraph, error) {
+	graph := StateMachineGraph{}
+	err := json.NewDecoder(reader).Decode(&graph)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &graph, nil
+}
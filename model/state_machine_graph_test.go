@@ -0,0 +1,95 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func hasTransition(graph *StateMachineGraph, from, to, trigger string) bool {
+	for _, transition := range graph.Transitions {
+		if transition.From == from && transition.To == to && transition.Trigger == trigger {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestNewStateMachineGraph(t *testing.T) {
+	t.Run("ring", func(t *testing.T) {
+		graph, err := NewStateMachineGraph(TypeRing, 0)
+		require.NoError(t, err)
+		require.Equal(t, CurrentStateMachineVersion, graph.Version)
+		require.Equal(t, AllRingStates, graph.States)
+		require.True(t, hasTransition(graph, RingStateStable, RingStateReleasePending, StateTransitionTriggerAPI))
+		require.True(t, hasTransition(graph, RingStateReleaseInProgress, RingStateReleasePaused, StateTransitionTriggerAPI))
+		require.True(t, hasTransition(graph, RingStateSoakingRequested, RingStateStable, StateTransitionTriggerSupervisor))
+		require.False(t, hasTransition(graph, RingStateStable, RingStateStable, StateTransitionTriggerAPI))
+
+		for _, transition := range graph.Transitions {
+			require.Contains(t, graph.States, transition.From)
+			require.Contains(t, graph.States, transition.To)
+		}
+	})
+
+	t.Run("ring version 1", func(t *testing.T) {
+		graph, err := NewStateMachineGraph(TypeRing, 1)
+		require.NoError(t, err)
+		require.False(t, hasTransition(graph, RingStateReleaseInProgress, RingStateReleasePaused, StateTransitionTriggerAPI))
+	})
+
+	t.Run("installation group", func(t *testing.T) {
+		graph, err := NewStateMachineGraph(TypeInstallationGroup, 0)
+		require.NoError(t, err)
+		require.Equal(t, AllInstallationGroupStates, graph.States)
+		require.True(t, hasTransition(graph, InstallationGroupBlueGreenVerifyRequested, InstallationGroupBlueGreenSwitchRequested, StateTransitionTriggerSupervisor))
+
+		for _, transition := range graph.Transitions {
+			require.Contains(t, graph.States, transition.From)
+			require.Contains(t, graph.States, transition.To)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := NewStateMachineGraph("installation", 0)
+		require.Error(t, err)
+		_, err = NewStateMachineGraph(TypeRing, CurrentStateMachineVersion+1)
+		require.EqualError(t, err, "unsupported state machine version 6")
+	})
+}
+
+func TestStateMachineGraphRender(t *testing.T) {
+	graph := &StateMachineGraph{
+		ResourceType: TypeRing,
+		Version:      2,
+		States:       []string{RingStateStable, RingStateReleasePending},
+		Transitions: []*StateTransition{
+			{From: RingStateStable, To: RingStateReleasePending, Trigger: StateTransitionTriggerAPI},
+			{From: RingStateReleasePending, To: RingStateStable, Trigger: StateTransitionTriggerSupervisor},
+		},
+	}
+
+	dot, err := graph.Render(StateMachineFormatDOT)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(dot, `digraph "ring-v2" {`))
+	require.Contains(t, dot, `"stable" -> "release-pending" [label="api", style=solid];`)
+	require.Contains(t, dot, `"release-pending" -> "stable" [label="supervisor", style=dashed];`)
+
+	mermaid, err := graph.Render(StateMachineFormatMermaid)
+	require.NoError(t, err)
+	require.Equal(t, `stateDiagram-v2
+  state "stable" as stable
+  state "release-pending" as release_pending
+  stable --> release_pending: api
+  release_pending --> stable: supervisor
+`, mermaid)
+
+	_, err = graph.Render("svg")
+	require.Error(t, err)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingStateMachineVersion(t *testing.T) {
+	ring := &Ring{State: RingStateStable}
+	require.Equal(t, CurrentStateMachineVersion, ring.CurrentStateMachineVersion())
+	require.True(t, ring.SupportedStateMachine())
+	require.True(t, ring.ValidTransitionState(RingStateReleasePending))
+	require.False(t, ring.UpgradeStateMachine())
+
+	// Rings of a newer version are not transitioned by older servers.
+	ring.StateMachineVersion = CurrentStateMachineVersion + 1
+	require.False(t, ring.SupportedStateMachine())
+	require.False(t, ring.ValidTransitionState(RingStateReleasePending))
+	require.False(t, ring.UpgradeStateMachine())
+	require.Equal(t, CurrentStateMachineVersion+1, ring.StateMachineVersion)
+}
+
+func TestRingStateMachineVersion3(t *testing.T) {
+	ring := &Ring{State: RingStateStable, StateMachineVersion: 2}
+	require.False(t, ring.ValidTransitionState(RingStateReleasePrepareRequested))
+	require.True(t, ring.UpgradeStateMachine())
+	require.True(t, ring.ValidTransitionState(RingStateReleasePrepareRequested))
+
+	ring.State = RingStateReleasePrepared
+	require.True(t, ring.ValidTransitionState(RingStateReleasePrepareRequested))
+	require.True(t, ring.ValidTransitionState(RingStateReleaseRequested))
+	require.True(t, ring.ValidTransitionState(RingStateReleasePending))
+	require.True(t, ring.ValidTransitionState(RingStateDeletionRequested))
+	require.False(t, ring.ValidTransitionState(RingStateReleaseInProgress))
+	require.False(t, ring.ValidTransitionState(RingStateReleasePaused))
+}
+
+func TestRingStateMachineVersion4(t *testing.T) {
+	ring := &Ring{State: RingStateReleaseFailed, StateMachineVersion: 3}
+	require.False(t, ring.ValidTransitionState(RingStateReleaseInProgress))
+	require.False(t, ring.UpgradeStateMachine())
+
+	ring.StateMachineVersion = 4
+	require.True(t, ring.ValidTransitionState(RingStateReleaseInProgress))
+	require.True(t, ring.ValidTransitionState(RingStateReleasePending))
+
+	ring.State = RingStateSoakingFailed
+	require.False(t, ring.ValidTransitionState(RingStateReleaseInProgress))
+}
+
+func TestRingStateMachineVersion5(t *testing.T) {
+	ring := &Ring{State: RingStateReleasePending, StateMachineVersion: 4}
+	require.False(t, ring.ValidTransitionState(RingStateReleaseBlocked))
+
+	ring.StateMachineVersion = 5
+	require.True(t, ring.ValidTransitionState(RingStateReleaseBlocked))
+
+	ring.State = RingStateReleaseBlocked
+	require.True(t, ring.ValidTransitionState(RingStateReleaseBlocked))
+	require.True(t, ring.ValidTransitionState(RingStateReleasePending))
+	require.True(t, ring.ValidTransitionState(RingStateReleaseRequested))
+	require.True(t, ring.ValidTransitionState(RingStateReleasePaused))
+	require.False(t, ring.ValidTransitionState(RingStateReleaseInProgress))
+
+	ring.State = RingStateStable
+	require.False(t, ring.ValidTransitionState(RingStateReleaseBlocked))
+}
+
+func TestInstallationGroupStateMachineVersion(t *testing.T) {
+	installationGroup := &InstallationGroup{State: InstallationGroupStable}
+	require.Equal(t, CurrentStateMachineVersion, installationGroup.CurrentStateMachineVersion())
+	require.True(t, installationGroup.SupportedStateMachine())
+	require.True(t, installationGroup.ValidInstallationGroupTransitionState(InstallationGroupReleasePending))
+
+	installationGroup.StateMachineVersion = CurrentStateMachineVersion + 1
+	require.False(t, installationGroup.SupportedStateMachine())
+	require.False(t, installationGroup.ValidInstallationGroupTransitionState(InstallationGroupReleasePending))
+}
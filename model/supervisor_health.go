@@ -0,0 +1,65 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// SupervisorHealth reports the cycles of a supervisor of the server.
+type SupervisorHealth struct {
+	Name string
+	// Period is the interval, in seconds, the supervisor runs at. Zero
+	// means it is only run on demand.
+	Period int64
+	Cycles int64
+	// Errors is the number of cycles that failed.
+	Errors int64
+	// LastSuccessAt and LastErrorAt are the times, in milliseconds, the
+	// supervisor last completed a cycle and last failed one.
+	LastSuccessAt int64
+	LastErrorAt   int64  `json:",omitempty"`
+	LastError     string `json:",omitempty"`
+	// Healthy is unset once the supervisor has not completed a cycle in
+	// too many of its periods.
+	Healthy bool
+}
+
+// DatabaseHealth reports the connection of the server to its database.
+type DatabaseHealth struct {
+	// Connected is unset while the server cannot reach a database accepting
+	// writes, e.g. during a failover.
+	Connected bool
+	// Host is the database currently connected to, out of the Databases
+	// configured.
+	Host      string `json:",omitempty"`
+	Databases int
+	// Failovers is the number of times the server switched databases,
+	// LastFailoverAt the time in milliseconds it last did.
+	Failovers      int64
+	LastFailoverAt int64  `json:",omitempty"`
+	LastError      string `json:",omitempty"`
+}
+
+// Readiness reports whether the server is ready, that is whether every
+// supervisor is healthy and the database is connected.
+type Readiness struct {
+	Ready       bool
+	Supervisors []*SupervisorHealth
+	Database    *DatabaseHealth `json:",omitempty"`
+}
+
+// ReadinessFromReader decodes a json-encoded readiness report from the given
+// io.Reader.
+func ReadinessFromReader(reader io.Reader) (*Readiness, error) {
+	readiness := Readiness{}
+	err := json.NewDecoder(reader).Decode(&readiness)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &readiness, nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TenantQuotaMaxRings limits the rings a tenant can create.
+	TenantQuotaMaxRings = "max-rings"
+	// TenantQuotaMaxInstallationGroupsPerRing limits the installation groups
+	// registered to each ring of a tenant.
+	TenantQuotaMaxInstallationGroupsPerRing = "max-installation-groups-per-ring"
+	// TenantQuotaMaxConcurrentReleases limits the rings of a tenant with a
+	// release pending or in progress at the same time.
+	TenantQuotaMaxConcurrentReleases = "max-concurrent-releases"
+	// TenantQuotaMaxWebhooks limits the webhooks owned by a tenant.
+	TenantQuotaMaxWebhooks = "max-webhooks"
+)
+
+// TenantQuotaNames are the names of the supported tenant quotas.
+var TenantQuotaNames = []string{
+	TenantQuotaMaxRings,
+	TenantQuotaMaxInstallationGroupsPerRing,
+	TenantQuotaMaxConcurrentReleases,
+	TenantQuotaMaxWebhooks,
+}
+
+// TenantQuota are the limits of the resources a single tenant can use. A limit
+// of 0 means no limit.
+type TenantQuota struct {
+	MaxRings                     int
+	MaxInstallationGroupsPerRing int
+	MaxConcurrentReleases        int
+	MaxWebhooks                  int
+}
+
+// Limit returns the limit of the quota with the given name.
+func (q TenantQuota) Limit(name string) int {
+	switch name {
+	case TenantQuotaMaxRings:
+		return q.MaxRings
+	case TenantQuotaMaxInstallationGroupsPerRing:
+		return q.MaxInstallationGroupsPerRing
+	case TenantQuotaMaxConcurrentReleases:
+		return q.MaxConcurrentReleases
+	case TenantQuotaMaxWebhooks:
+		return q.MaxWebhooks
+	}
+
+	return 0
+}
+
+func (q *TenantQuota) setLimit(name string, limit int) error {
+	switch name {
+	case TenantQuotaMaxRings:
+		q.MaxRings = limit
+	case TenantQuotaMaxInstallationGroupsPerRing:
+		q.MaxInstallationGroupsPerRing = limit
+	case TenantQuotaMaxConcurrentReleases:
+		q.MaxConcurrentReleases = limit
+	case TenantQuotaMaxWebhooks:
+		q.MaxWebhooks = limit
+	default:
+		return errors.Errorf("unknown quota %q, must be one of %s", name, strings.Join(TenantQuotaNames, ", "))
+	}
+
+	return nil
+}
+
+// TenantQuotas are the quotas of every tenant: the default ones, and the
+// overrides of some tenants.
+type TenantQuotas struct {
+	Default TenantQuota
+	Tenants map[string]TenantQuota
+}
+
+// NewTenantQuotas returns the tenant quotas with the given defaults and
+// overrides. Overrides are keyed by tenant and quota name, as
+// <tenant>.<quota>, and replace the default limit of that quota for the
+// tenant only.
+func NewTenantQuotas(defaults TenantQuota, overrides map[string]int) (*TenantQuotas, error) {
+	quotas := &TenantQuotas{
+		Default: defaults,
+		Tenants: make(map[string]TenantQuota),
+	}
+	for key, limit := range overrides {
+		separator := strings.LastIndex(key, ".")
+		if separator <= 0 {
+			return nil, errors.Errorf("invalid quota override %q, must be <tenant>.<quota>", key)
+		}
+		if limit < 0 {
+			return nil, errors.Errorf("invalid quota override %q: limit cannot be negative, got %d", key, limit)
+		}
+
+		tenantID := key[:separator]
+		quota, ok := quotas.Tenants[tenantID]
+		if !ok {
+			quota = defaults
+		}
+		if err := quota.setLimit(key[separator+1:], limit); err != nil {
+			return nil, errors.Wrapf(err, "invalid quota override %q", key)
+		}
+		quotas.Tenants[tenantID] = quota
+	}
+
+	return quotas, nil
+}
+
+// For returns the quota of the given tenant.
+func (q *TenantQuotas) For(tenantID string) TenantQuota {
+	if q == nil {
+		return TenantQuota{}
+	}
+	if quota, ok := q.Tenants[tenantID]; ok {
+		return quota
+	}
+
+	return q.Default
+}
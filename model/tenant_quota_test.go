@@ -0,0 +1,52 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTenantQuotas(t *testing.T) {
+	defaults := model.TenantQuota{MaxRings: 10, MaxWebhooks: 5}
+
+	t.Run("defaults", func(t *testing.T) {
+		quotas, err := model.NewTenantQuotas(defaults, nil)
+		require.NoError(t, err)
+		require.Equal(t, defaults, quotas.For("team-a"))
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		quotas, err := model.NewTenantQuotas(defaults, map[string]int{
+			"team-a.max-rings":               50,
+			"team-a.max-concurrent-releases": 2,
+			"team.b.max-webhooks":            0,
+		})
+		require.NoError(t, err)
+		require.Equal(t, model.TenantQuota{MaxRings: 50, MaxConcurrentReleases: 2, MaxWebhooks: 5}, quotas.For("team-a"))
+		require.Equal(t, model.TenantQuota{MaxRings: 10}, quotas.For("team.b"))
+		require.Equal(t, defaults, quotas.For("team-c"))
+		require.Equal(t, 50, quotas.For("team-a").Limit(model.TenantQuotaMaxRings))
+	})
+
+	t.Run("invalid overrides", func(t *testing.T) {
+		for _, overrides := range []map[string]int{
+			{"max-rings": 1},
+			{".max-rings": 1},
+			{"team-a.max-teams": 1},
+			{"team-a.max-rings": -1},
+		} {
+			_, err := model.NewTenantQuotas(defaults, overrides)
+			require.Error(t, err, overrides)
+		}
+	})
+
+	t.Run("no quotas", func(t *testing.T) {
+		var quotas *model.TenantQuotas
+		require.Equal(t, model.TenantQuota{}, quotas.For("team-a"))
+	})
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TimeWindow is a daily window of time in a named time zone, such as the
+// business hours 09:00-17:00 Europe/Berlin from Monday to Friday. A window
+// ending at or before its start ends on the next day.
+type TimeWindow struct {
+	// Start and End are the times of day the window starts and ends, as
+	// HH:MM.
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// TimeZone is the IANA name of the time zone of the window, defaulting
+	// to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+	// Weekdays are the days, such as Monday, the window starts on,
+	// defaulting to every day.
+	Weekdays []string `json:"weekdays,omitempty"`
+}
+
+// TimeWindows are time windows combined. No windows means all the time.
+type TimeWindows []TimeWindow
+
+// maxTimeWindowDays bounds the days searched for the end of a duration spent
+// within time windows.
+const maxTimeWindowDays = 3660
+
+// timeInterval is a span of time, from start inclusive to end exclusive.
+type timeInterval struct {
+	start, end time.Time
+}
+
+// parseTimeOfDay parses a time of day as HH:MM, returning the hours and
+// minutes.
+func parseTimeOfDay(value string) (int, int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, errors.Errorf("invalid time of day %q: must be HH:MM", value)
+	}
+
+	return t.Hour(), t.Minute(), nil
+}
+
+// parseWeekday parses the English name of a day of the week.
+func parseWeekday(value string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), value) {
+			return day, nil
+		}
+	}
+
+	return 0, errors.Errorf("invalid weekday %q", value)
+}
+
+// ParseTimeWindow parses a time window written as
+// "<start>-<end> [<time zone>] [<weekday>,...]", such as
+// "09:00-17:00 Europe/Berlin Monday,Tuesday", and validates it.
+func ParseTimeWindow(value string) (TimeWindow, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || len(fields) > 3 {
+		return TimeWindow{}, errors.Errorf("invalid time window %q: expected <start>-<end> [<time zone>] [<weekday>,...]", value)
+	}
+	times := strings.SplitN(fields[0], "-", 2)
+	if len(times) != 2 {
+		return TimeWindow{}, errors.Errorf("invalid time window %q: expected <start>-<end> [<time zone>] [<weekday>,...]", value)
+	}
+
+	window := TimeWindow{Start: times[0], End: times[1]}
+	for _, field := range fields[1:] {
+		weekdays := strings.Split(field, ",")
+		isWeekdays := true
+		for _, weekday := range weekdays {
+			if _, err := parseWeekday(weekday); err != nil {
+				isWeekdays = false
+				break
+			}
+		}
+		if isWeekdays && window.Weekdays == nil {
+			window.Weekdays = weekdays
+		} else if window.TimeZone == "" {
+			window.TimeZone = field
+		} else {
+			return TimeWindow{}, errors.Errorf("invalid time window %q: expected <start>-<end> [<time zone>] [<weekday>,...]", value)
+		}
+	}
+
+	if err := window.Validate(); err != nil {
+		return TimeWindow{}, errors.Wrapf(err, "invalid time window %q", value)
+	}
+
+	return window, nil
+}
+
+// String returns the time window in the format parsed by ParseTimeWindow.
+func (w *TimeWindow) String() string {
+	value := w.Start + "-" + w.End
+	if w.TimeZone != "" {
+		value += " " + w.TimeZone
+	}
+	if len(w.Weekdays) > 0 {
+		value += " " + strings.Join(w.Weekdays, ",")
+	}
+
+	return value
+}
+
+// String returns the time windows separated by semicolons.
+func (windows TimeWindows) String() string {
+	values := make([]string, 0, len(windows))
+	for i := range windows {
+		values = append(values, windows[i].String())
+	}
+
+	return strings.Join(values, "; ")
+}
+
+// Validate validates the time window.
+func (w *TimeWindow) Validate() error {
+	startHour, startMinute, err := parseTimeOfDay(w.Start)
+	if err != nil {
+		return errors.Wrap(err, "invalid window start")
+	}
+	endHour, endMinute, err := parseTimeOfDay(w.End)
+	if err != nil {
+		return errors.Wrap(err, "invalid window end")
+	}
+	if startHour == endHour && startMinute == endMinute {
+		return errors.New("window start and end cannot be the same")
+	}
+	if _, err = time.LoadLocation(w.TimeZone); err != nil {
+		return errors.Errorf("invalid time zone %q", w.TimeZone)
+	}
+	for _, weekday := range w.Weekdays {
+		if _, err = parseWeekday(weekday); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Validate validates every time window.
+func (windows TimeWindows) Validate() error {
+	for i := range windows {
+		if err := windows[i].Validate(); err != nil {
+			return errors.Wrapf(err, "invalid time window %d", i)
+		}
+	}
+
+	return nil
+}
+
+// intervals returns the occurrences of the window overlapping the given
+// span of time. The window must be valid.
+func (w *TimeWindow) intervals(from, to time.Time) []timeInterval {
+	location, err := time.LoadLocation(w.TimeZone)
+	if err != nil {
+		return nil
+	}
+	startHour, startMinute, _ := parseTimeOfDay(w.Start)
+	endHour, endMinute, _ := parseTimeOfDay(w.End)
+	weekdays := make(map[time.Weekday]bool)
+	for _, weekday := range w.Weekdays {
+		day, _ := parseWeekday(weekday)
+		weekdays[day] = true
+	}
+
+	var intervals []timeInterval
+	// Start a day early for windows running overnight.
+	first := from.In(location).AddDate(0, 0, -1)
+	for day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, location); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if len(weekdays) > 0 && !weekdays[day.Weekday()] {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), startHour, startMinute, 0, 0, location)
+		end := time.Date(day.Year(), day.Month(), day.Day(), endHour, endMinute, 0, 0, location)
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+		if end.After(from) && start.Before(to) {
+			intervals = append(intervals, timeInterval{start: start, end: end})
+		}
+	}
+
+	return intervals
+}
+
+// intervals returns the merged occurrences of the windows overlapping the
+// given span of time, clipped to it.
+func (windows TimeWindows) intervals(from, to time.Time) []timeInterval {
+	var intervals []timeInterval
+	for i := range windows {
+		intervals = append(intervals, windows[i].intervals(from, to)...)
+	}
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Before(intervals[j].start)
+	})
+
+	var merged []timeInterval
+	for _, interval := range intervals {
+		if interval.start.Before(from) {
+			interval.start = from
+		}
+		if interval.end.After(to) {
+			interval.end = to
+		}
+		if len(merged) > 0 && !interval.start.After(merged[len(merged)-1].end) {
+			if interval.end.After(merged[len(merged)-1].end) {
+				merged[len(merged)-1].end = interval.end
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+
+	return merged
+}
+
+// Contains returns whether the given time is within the windows.
+func (windows TimeWindows) Contains(t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+
+	return len(windows.intervals(t, t.Add(time.Nanosecond))) > 0
+}
+
+// ActiveDuration returns the time spent within the windows between the
+// given times.
+func (windows TimeWindows) ActiveDuration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	if len(windows) == 0 {
+		return to.Sub(from)
+	}
+
+	var duration time.Duration
+	for _, interval := range windows.intervals(from, to) {
+		duration += interval.end.Sub(interval.start)
+	}
+
+	return duration
+}
+
+// AddActive returns the time at which the given duration has been spent
+// within the windows, counting from the given time.
+func (windows TimeWindows) AddActive(from time.Time, duration time.Duration) time.Time {
+	if len(windows) == 0 || duration <= 0 {
+		return from.Add(duration)
+	}
+
+	// Search a week at a time, as every week of valid windows has time
+	// within them.
+	for start := from; start.Before(from.AddDate(0, 0, maxTimeWindowDays)); start = start.AddDate(0, 0, 7) {
+		for _, interval := range windows.intervals(start, start.AddDate(0, 0, 7)) {
+			length := interval.end.Sub(interval.start)
+			if length >= duration {
+				return interval.start.Add(duration)
+			}
+			duration -= length
+		}
+	}
+
+	return from.AddDate(0, 0, maxTimeWindowDays)
+}
+
+// Value implements driver.Valuer, storing the windows as JSON.
+func (windows TimeWindows) Value() (driver.Value, error) {
+	if len(windows) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(windows)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal time windows")
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner, loading the windows from JSON.
+func (windows *TimeWindows) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*windows = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.Errorf("cannot scan %T into time windows", src)
+	}
+	if len(data) == 0 {
+		*windows = nil
+		return nil
+	}
+
+	var scanned TimeWindows
+	if err := json.Unmarshal(data, &scanned); err != nil {
+		return errors.Wrap(err, "failed to unmarshal time windows")
+	}
+	if len(scanned) == 0 {
+		scanned = nil
+	}
+	*windows = scanned
+
+	return nil
+}
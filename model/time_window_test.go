@@ -0,0 +1,133 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeWindowsValidate(t *testing.T) {
+	require.NoError(t, TimeWindows(nil).Validate())
+	require.NoError(t, TimeWindows{{Start: "09:00", End: "17:00", TimeZone: "Europe/Berlin", Weekdays: []string{"monday", "Friday"}}}.Validate())
+	require.NoError(t, TimeWindows{{Start: "22:00", End: "02:00"}}.Validate())
+
+	for name, window := range map[string]TimeWindow{
+		"bad start":    {Start: "9am", End: "17:00"},
+		"bad end":      {Start: "09:00", End: "25:00"},
+		"empty window": {Start: "09:00", End: "09:00"},
+		"bad zone":     {Start: "09:00", End: "17:00", TimeZone: "Europe/Nowhere"},
+		"bad weekday":  {Start: "09:00", End: "17:00", Weekdays: []string{"Someday"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, TimeWindows{window}.Validate())
+		})
+	}
+}
+
+func TestParseTimeWindow(t *testing.T) {
+	window, err := ParseTimeWindow("09:00-17:00")
+	require.NoError(t, err)
+	assert.Equal(t, TimeWindow{Start: "09:00", End: "17:00"}, window)
+
+	window, err = ParseTimeWindow("09:00-17:00 Europe/Berlin Monday,friday")
+	require.NoError(t, err)
+	assert.Equal(t, TimeWindow{Start: "09:00", End: "17:00", TimeZone: "Europe/Berlin", Weekdays: []string{"Monday", "friday"}}, window)
+	assert.Equal(t, "09:00-17:00 Europe/Berlin Monday,friday", window.String())
+
+	window, err = ParseTimeWindow("22:00-02:00 Saturday")
+	require.NoError(t, err)
+	assert.Equal(t, TimeWindow{Start: "22:00", End: "02:00", Weekdays: []string{"Saturday"}}, window)
+
+	for _, value := range []string{"", "09:00", "09:00-17:00 Europe/Berlin UTC", "09:00-17:00 Europe/Nowhere", "09:00-17:00 UTC Monday extra"} {
+		_, err = ParseTimeWindow(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestTimeWindows(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	businessHours := TimeWindows{{
+		Start:    "09:00",
+		End:      "17:00",
+		TimeZone: "Europe/Berlin",
+		Weekdays: []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
+	}}
+	// Friday, June 3rd 2022.
+	friday := func(hour, minute int) time.Time {
+		return time.Date(2022, time.June, 3, hour, minute, 0, 0, berlin)
+	}
+
+	t.Run("no windows", func(t *testing.T) {
+		assert.True(t, TimeWindows(nil).Contains(friday(3, 0)))
+		assert.Equal(t, 2*time.Hour, TimeWindows(nil).ActiveDuration(friday(3, 0), friday(5, 0)))
+		assert.Equal(t, friday(5, 0), TimeWindows(nil).AddActive(friday(3, 0), 2*time.Hour))
+	})
+
+	t.Run("contains", func(t *testing.T) {
+		assert.True(t, businessHours.Contains(friday(9, 0)))
+		assert.True(t, businessHours.Contains(friday(16, 59)))
+		assert.False(t, businessHours.Contains(friday(17, 0)))
+		assert.False(t, businessHours.Contains(friday(8, 59)))
+		assert.False(t, businessHours.Contains(friday(12, 0).AddDate(0, 0, 1)))
+		// 09:00 UTC is 11:00 in Berlin.
+		assert.True(t, businessHours.Contains(time.Date(2022, time.June, 3, 9, 0, 0, 0, time.UTC)))
+		assert.False(t, businessHours.Contains(time.Date(2022, time.June, 3, 16, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("overnight window", func(t *testing.T) {
+		overnight := TimeWindows{{Start: "22:00", End: "02:00", TimeZone: "Europe/Berlin", Weekdays: []string{"Friday"}}}
+		assert.True(t, overnight.Contains(friday(23, 0)))
+		assert.True(t, overnight.Contains(friday(1, 0).AddDate(0, 0, 1)))
+		assert.False(t, overnight.Contains(friday(1, 0)))
+		assert.Equal(t, 4*time.Hour, overnight.ActiveDuration(friday(0, 0), friday(0, 0).AddDate(0, 0, 7)))
+	})
+
+	t.Run("active duration", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), businessHours.ActiveDuration(friday(17, 0), friday(17, 0).AddDate(0, 0, 2)))
+		assert.Equal(t, 3*time.Hour, businessHours.ActiveDuration(friday(14, 0), friday(20, 0)))
+		// Friday afternoon and Monday morning.
+		assert.Equal(t, 5*time.Hour, businessHours.ActiveDuration(friday(14, 0), friday(11, 0).AddDate(0, 0, 3)))
+		assert.Equal(t, 40*time.Hour, businessHours.ActiveDuration(friday(0, 0), friday(0, 0).AddDate(0, 0, 7)))
+	})
+
+	t.Run("add active", func(t *testing.T) {
+		assert.Equal(t, friday(12, 0), businessHours.AddActive(friday(10, 0), 2*time.Hour))
+		assert.Equal(t, friday(11, 0), businessHours.AddActive(friday(3, 0), 2*time.Hour))
+		// Soaking from Friday afternoon continues on Monday.
+		assert.True(t, friday(11, 0).AddDate(0, 0, 3).Equal(businessHours.AddActive(friday(15, 0), 4*time.Hour)))
+		assert.True(t, friday(17, 0).AddDate(0, 0, 7).Equal(businessHours.AddActive(friday(9, 0), 48*time.Hour)))
+	})
+
+	t.Run("combined windows", func(t *testing.T) {
+		windows := TimeWindows{
+			{Start: "09:00", End: "12:00", TimeZone: "Europe/Berlin"},
+			{Start: "11:00", End: "14:00", TimeZone: "Europe/Berlin"},
+		}
+		assert.Equal(t, 5*time.Hour, windows.ActiveDuration(friday(0, 0), friday(23, 0)))
+	})
+}
+
+func TestTimeWindowsValue(t *testing.T) {
+	value, err := TimeWindows(nil).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "", value)
+
+	var windows TimeWindows
+	require.NoError(t, windows.Scan(""))
+	assert.Nil(t, windows)
+
+	expected := TimeWindows{{Start: "09:00", End: "17:00", TimeZone: "Europe/Berlin", Weekdays: []string{"Monday"}}}
+	value, err = expected.Value()
+	require.NoError(t, err)
+	require.NoError(t, windows.Scan(value))
+	assert.Equal(t, expected, windows)
+
+	assert.Error(t, windows.Scan(1))
+}
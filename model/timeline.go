@@ -0,0 +1,140 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// DefaultTimelineReleases is the default number of releases in a ring timeline.
+const DefaultTimelineReleases = 5
+
+// RingTimeline is the chronological history of the latest releases of a ring.
+type RingTimeline struct {
+	RingID   string
+	Releases []*ReleaseTimeline
+	// Notes are the notes left on the ring, its installation groups and the
+	// releases of the timeline, oldest first.
+	Notes []*Note `json:",omitempty"`
+}
+
+// ReleaseTimeline is the history of a single release of a ring. All
+// timestamps are in milliseconds; an EndAt of 0 means the release or span is
+// still ongoing.
+type ReleaseTimeline struct {
+	ReleaseID string
+	// Labels are the labels of the release.
+	Labels     ReleaseLabels `json:",omitempty"`
+	StartAt    int64
+	EndAt      int64
+	Duration   int64
+	FinalState string
+	Spans      []*TimelineSpan
+}
+
+// TimelineSpan is the time a ring or installation group spent in a state
+// during a release.
+type TimelineSpan struct {
+	ResourceType string
+	ResourceID   string
+	State        string
+	StartAt      int64
+	EndAt        int64
+	Duration     int64
+}
+
+// BuildRingTimeline reconstructs the timeline of the last given number of
+// releases of a ring from its state change events, sorted by timestamp. A
+// number of releases of 0 returns every release.
+func BuildRingTimeline(ringID string, events []*StateChangeEvent, releases int) *RingTimeline {
+	timeline := &RingTimeline{RingID: ringID, Releases: []*ReleaseTimeline{}}
+
+	var release *ReleaseTimeline
+	openSpans := make(map[string]*TimelineSpan)
+	for _, event := range events {
+		// A release starts when a ring is requested to release, or to prepare
+		// a release, unless it is retrying the current release after a
+		// failure. Events outside of a release, such as the ring creation,
+		// are not part of the timeline.
+		if event.ResourceType == TypeRing && (event.NewState == RingStateReleasePending || event.NewState == RingStateReleasePrepareRequested) &&
+			(release == nil || event.ReleaseID != release.ReleaseID || release.FinalState == RingStateStable) {
+			release = &ReleaseTimeline{ReleaseID: event.ReleaseID, StartAt: event.Timestamp, Spans: []*TimelineSpan{}}
+			timeline.Releases = append(timeline.Releases, release)
+			openSpans = make(map[string]*TimelineSpan)
+		}
+		if release == nil || event.ReleaseID != release.ReleaseID {
+			continue
+		}
+
+		spanKey := event.ResourceType + "/" + event.ResourceID
+		if span, ok := openSpans[spanKey]; ok {
+			span.EndAt = event.Timestamp
+			span.Duration = span.EndAt - span.StartAt
+			delete(openSpans, spanKey)
+		}
+
+		if event.ResourceType == TypeRing {
+			release.FinalState = event.NewState
+		}
+		if isTerminalTimelineState(event.ResourceType, event.NewState) {
+			if event.ResourceType == TypeRing {
+				release.EndAt = event.Timestamp
+				release.Duration = release.EndAt - release.StartAt
+			}
+			continue
+		}
+		if event.ResourceType == TypeRing {
+			// The release was retried after a failure.
+			release.EndAt = 0
+			release.Duration = 0
+		}
+
+		span := &TimelineSpan{
+			ResourceType: event.ResourceType,
+			ResourceID:   event.ResourceID,
+			State:        event.NewState,
+			StartAt:      event.Timestamp,
+		}
+		release.Spans = append(release.Spans, span)
+		openSpans[spanKey] = span
+	}
+
+	if releases > 0 && len(timeline.Releases) > releases {
+		timeline.Releases = timeline.Releases[len(timeline.Releases)-releases:]
+	}
+
+	return timeline
+}
+
+// isTerminalTimelineState returns whether the given state ends the release of
+// a resource, rather than starting a new span.
+func isTerminalTimelineState(resourceType, state string) bool {
+	if resourceType == TypeInstallationGroup {
+		switch state {
+		case InstallationGroupStable, InstallationGroupReleaseFailed, InstallationGroupReleaseSoakingFailed, InstallationGroupReleaseRollbackComplete, InstallationGroupReleaseRollbackFailed:
+			return true
+		}
+		return false
+	}
+
+	switch state {
+	case RingStateStable, RingStateReleaseFailed, RingStateSoakingFailed, RingStateReleaseRollbackComplete, RingStateReleaseRollbackFailed:
+		return true
+	}
+	return false
+}
+
+// RingTimelineFromReader decodes a json-encoded ring timeline from the given io.Reader.
+func RingTimelineFromReader(reader io.Reader) (*RingTimeline, error) {
+	timeline := RingTimeline{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&timeline)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &timeline, nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRingTimeline(t *testing.T) {
+	ringEvent := func(releaseID, state string, timestamp int64) *StateChangeEvent {
+		return &StateChangeEvent{ResourceType: TypeRing, ResourceID: "ring", RingID: "ring", ReleaseID: releaseID, NewState: state, Timestamp: timestamp}
+	}
+	groupEvent := func(releaseID, state string, timestamp int64) *StateChangeEvent {
+		return &StateChangeEvent{ResourceType: TypeInstallationGroup, ResourceID: "group", RingID: "ring", ReleaseID: releaseID, NewState: state, Timestamp: timestamp}
+	}
+
+	t.Run("no events", func(t *testing.T) {
+		timeline := BuildRingTimeline("ring", nil, DefaultTimelineReleases)
+		require.Equal(t, "ring", timeline.RingID)
+		require.Empty(t, timeline.Releases)
+	})
+
+	t.Run("completed release", func(t *testing.T) {
+		timeline := BuildRingTimeline("ring", []*StateChangeEvent{
+			ringEvent("release1", RingStateReleasePending, 100),
+			ringEvent("release1", RingStateReleaseRequested, 200),
+			groupEvent("release1", InstallationGroupReleaseRequested, 300),
+			groupEvent("release1", InstallationGroupReleaseSoakingRequested, 500),
+			groupEvent("release1", InstallationGroupStable, 900),
+			ringEvent("release1", RingStateSoakingRequested, 1000),
+			ringEvent("release1", RingStateStable, 1500),
+		}, DefaultTimelineReleases)
+
+		require.Len(t, timeline.Releases, 1)
+		release := timeline.Releases[0]
+		require.Equal(t, int64(100), release.StartAt)
+		require.Equal(t, int64(1500), release.EndAt)
+		require.Equal(t, int64(1400), release.Duration)
+		require.Equal(t, RingStateStable, release.FinalState)
+		require.Len(t, release.Spans, 5)
+
+		groupSoak := release.Spans[3]
+		require.Equal(t, TypeInstallationGroup, groupSoak.ResourceType)
+		require.Equal(t, InstallationGroupReleaseSoakingRequested, groupSoak.State)
+		require.Equal(t, int64(400), groupSoak.Duration)
+	})
+
+	t.Run("ongoing release", func(t *testing.T) {
+		timeline := BuildRingTimeline("ring", []*StateChangeEvent{
+			ringEvent("release1", RingStateReleasePending, 100),
+			ringEvent("release1", RingStateReleaseRequested, 200),
+		}, DefaultTimelineReleases)
+
+		require.Len(t, timeline.Releases, 1)
+		require.Zero(t, timeline.Releases[0].EndAt)
+		require.Zero(t, timeline.Releases[0].Spans[1].EndAt)
+	})
+
+	t.Run("staged release", func(t *testing.T) {
+		timeline := BuildRingTimeline("ring", []*StateChangeEvent{
+			ringEvent("release1", RingStateReleasePrepareRequested, 100),
+			ringEvent("release1", RingStateReleasePrepared, 200),
+			ringEvent("release1", RingStateReleaseRequested, 1000),
+		}, DefaultTimelineReleases)
+
+		require.Len(t, timeline.Releases, 1)
+		require.Equal(t, int64(100), timeline.Releases[0].StartAt)
+		require.Len(t, timeline.Releases[0].Spans, 3)
+		require.Equal(t, int64(800), timeline.Releases[0].Spans[1].Duration)
+	})
+
+	t.Run("retried release", func(t *testing.T) {
+		timeline := BuildRingTimeline("ring", []*StateChangeEvent{
+			ringEvent("release1", RingStateCreationRequested, 50),
+			ringEvent("release1", RingStateReleasePending, 100),
+			ringEvent("release1", RingStateReleaseFailed, 200),
+			ringEvent("release1", RingStateReleasePending, 300),
+			ringEvent("release1", RingStateStable, 400),
+		}, DefaultTimelineReleases)
+
+		require.Len(t, timeline.Releases, 1)
+		require.Equal(t, int64(100), timeline.Releases[0].StartAt)
+		require.Equal(t, int64(400), timeline.Releases[0].EndAt)
+		require.Equal(t, RingStateStable, timeline.Releases[0].FinalState)
+	})
+
+	t.Run("limited to the latest releases", func(t *testing.T) {
+		timeline := BuildRingTimeline("ring", []*StateChangeEvent{
+			ringEvent("release1", RingStateReleasePending, 100),
+			ringEvent("release1", RingStateStable, 200),
+			ringEvent("release2", RingStateReleasePending, 300),
+			ringEvent("release2", RingStateStable, 400),
+			ringEvent("release3", RingStateReleasePending, 500),
+			ringEvent("release3", RingStateReleaseFailed, 600),
+		}, 2)
+
+		require.Len(t, timeline.Releases, 2)
+		require.Equal(t, "release2", timeline.Releases[0].ReleaseID)
+		require.Equal(t, "release3", timeline.Releases[1].ReleaseID)
+		require.Equal(t, RingStateReleaseFailed, timeline.Releases[1].FinalState)
+	})
+}
+
+func TestRingTimelineFromReader(t *testing.T) {
+	timeline, err := RingTimelineFromReader(bytes.NewReader([]byte(`{"RingID":"ring","Releases":[{"ReleaseID":"release1"}]}`)))
+	require.NoError(t, err)
+	require.Equal(t, "ring", timeline.RingID)
+	require.Len(t, timeline.Releases, 1)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// TokenRoleAdmin grants full access, including token management.
+	TokenRoleAdmin = "admin"
+	// TokenRoleWrite grants read and write access to rings, installation groups and webhooks.
+	TokenRoleWrite = "write"
+	// TokenRoleRead grants read-only access.
+	TokenRoleRead = "read"
+
+	// tokenSecretPrefix makes elrond token secrets easy to recognize, e.g. by secret scanners.
+	tokenSecretPrefix = "elrond_"
+	// authorizationBearerPrefix is the prefix of the Authorization header value carrying a token.
+	authorizationBearerPrefix = "Bearer "
+)
+
+// Token represents a scoped API token for a service account. Only the hash of
+// the secret is stored; the secret itself is returned once at creation.
+type Token struct {
+	ID        string
+	Name      string
+	TenantID  string
+	Role      string
+	TokenHash string `json:"-"`
+	CreateAt  int64
+	ExpireAt  int64
+	DeleteAt  int64
+
+	// RingIDs lists, comma separated, the rings the token is restricted to
+	// changing, such as a CI token that can only release the dev ring.
+	RingIDs string `json:",omitempty"`
+	// RingSelector restricts the token to changing the rings whose
+	// annotations match it, such as "env=dev", in addition to RingIDs.
+	RingSelector string `json:",omitempty"`
+}
+
+// TokenFilter describes the parameters used to constrain a set of tokens.
+type TokenFilter struct {
+	TenantID       string
+	Page           int
+	PerPage        int
+	IncludeDeleted bool
+}
+
+// CreateTokenResponse is returned when a token is created. It is the only
+// response that includes the token secret.
+type CreateTokenResponse struct {
+	Token  *Token
+	Secret string
+}
+
+// IsDeleted returns whether the token was revoked or not.
+func (t *Token) IsDeleted() bool {
+	return t.DeleteAt != 0
+}
+
+// IsExpired returns whether the token is expired at the given time in milliseconds.
+func (t *Token) IsExpired(now int64) bool {
+	return t.ExpireAt != 0 && t.ExpireAt <= now
+}
+
+// IsRingScoped returns whether the token is restricted to changing some rings.
+func (t *Token) IsRingScoped() bool {
+	return t.RingIDs != "" || t.RingSelector != ""
+}
+
+// AllowsRing returns whether the token may change the given ring: any ring
+// if it is not ring scoped, otherwise the rings it lists or whose annotations
+// match its selector.
+func (t *Token) AllowsRing(ring *Ring) bool {
+	if !t.IsRingScoped() {
+		return true
+	}
+
+	for _, ringID := range strings.Split(t.RingIDs, ",") {
+		if ringID != "" && ringID == ring.ID {
+			return true
+		}
+	}
+
+	if t.RingSelector == "" {
+		return false
+	}
+	selector, err := ParseLabelSelector(t.RingSelector)
+	if err != nil {
+		return false
+	}
+
+	return selector.Matches(ring.Annotations)
+}
+
+// IsValidTokenRole returns whether the given role is a known token role.
+func IsValidTokenRole(role string) bool {
+	switch role {
+	case TokenRoleAdmin, TokenRoleWrite, TokenRoleRead:
+		return true
+	}
+
+	return false
+}
+
+// NewTokenSecret generates a new random token secret.
+func NewTokenSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate token secret")
+	}
+
+	return tokenSecretPrefix + hex.EncodeToString(b), nil
+}
+
+// HashTokenSecret returns the hash under which the given token secret is stored.
+func HashTokenSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// TokenSecretFromAuthorization extracts the token secret from the value of an
+// Authorization header, returning an empty string if none is present.
+func TokenSecretFromAuthorization(authorization string) string {
+	if !strings.HasPrefix(authorization, authorizationBearerPrefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(authorization, authorizationBearerPrefix))
+}
+
+// TokenFromReader decodes a json-encoded token from the given io.Reader.
+func TokenFromReader(reader io.Reader) (*Token, error) {
+	token := Token{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&token)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// TokensFromReader decodes a json-encoded list of tokens from the given io.Reader.
+func TokensFromReader(reader io.Reader) ([]*Token, error) {
+	tokens := []*Token{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&tokens)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// CreateTokenResponseFromReader decodes a json-encoded token creation response from the given io.Reader.
+func CreateTokenResponseFromReader(reader io.Reader) (*CreateTokenResponse, error) {
+	response := CreateTokenResponse{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&response)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultTokenExpiresIn is the default lifetime of a token, in seconds.
+const DefaultTokenExpiresIn = 90 * 24 * 60 * 60
+
+// CreateTokenRequest specifies the parameters for a new token.
+type CreateTokenRequest struct {
+	Name     string
+	TenantID string
+	Role     string
+	// ExpiresIn is the lifetime of the token in seconds. Set to -1 for a
+	// token that never expires.
+	ExpiresIn int64
+
+	// RingIDs restricts the token to changing the given rings.
+	RingIDs []string `json:",omitempty"`
+	// RingSelector restricts the token to changing the rings whose
+	// annotations match it, in addition to RingIDs.
+	RingSelector string `json:",omitempty"`
+}
+
+// SetDefaults sets the default values for a token create request.
+func (request *CreateTokenRequest) SetDefaults() {
+	if request.Role == "" {
+		request.Role = TokenRoleRead
+	}
+	if request.ExpiresIn == 0 {
+		request.ExpiresIn = DefaultTokenExpiresIn
+	}
+}
+
+// Validate validates the values of a token create request.
+func (request *CreateTokenRequest) Validate() error {
+	if request.Name == "" {
+		return errors.New("must specify name")
+	}
+	if !IsValidTokenRole(request.Role) {
+		return errors.Errorf("unsupported role %s", request.Role)
+	}
+	if request.Role != TokenRoleAdmin && request.TenantID == "" {
+		return errors.New("must specify tenant for non-admin tokens")
+	}
+	if request.ExpiresIn < -1 {
+		return errors.New("expires in must be positive, or -1 to never expire")
+	}
+	if len(request.RingIDs) > 0 || request.RingSelector != "" {
+		if request.Role == TokenRoleAdmin {
+			return errors.New("admin tokens cannot be restricted to rings")
+		}
+		for _, ringID := range request.RingIDs {
+			if ringID == "" || strings.Contains(ringID, ",") {
+				return errors.Errorf("invalid ring ID %q", ringID)
+			}
+		}
+		if _, err := ParseLabelSelector(request.RingSelector); err != nil {
+			return errors.Wrap(err, "invalid ring selector")
+		}
+	}
+
+	return nil
+}
+
+// NewCreateTokenRequestFromReader will create a CreateTokenRequest from an io.Reader with JSON data.
+func NewCreateTokenRequestFromReader(reader io.Reader) (*CreateTokenRequest, error) {
+	var createTokenRequest CreateTokenRequest
+	err := json.NewDecoder(reader).Decode(&createTokenRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode create token request")
+	}
+
+	createTokenRequest.SetDefaults()
+	err = createTokenRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "create token request failed validation")
+	}
+
+	return &createTokenRequest, nil
+}
+
+// GetTokensRequest describes the parameters to request a list of tokens.
+type GetTokensRequest struct {
+	TenantID       string
+	Page           int
+	PerPage        int
+	IncludeDeleted bool
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetTokensRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	q.Add("tenant", request.TenantID)
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	if request.IncludeDeleted {
+		q.Add("include_deleted", "true")
+	}
+	u.RawQuery = q.Encode()
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenAllowsRing(t *testing.T) {
+	dev := &Ring{ID: NewID(), Annotations: Annotations{"env": "dev"}}
+	prod := &Ring{ID: NewID(), Annotations: Annotations{"env": "prod"}}
+
+	token := &Token{}
+	require.False(t, token.IsRingScoped())
+	require.True(t, token.AllowsRing(prod))
+
+	token = &Token{RingIDs: dev.ID + "," + NewID()}
+	require.True(t, token.IsRingScoped())
+	require.True(t, token.AllowsRing(dev))
+	require.False(t, token.AllowsRing(prod))
+
+	token = &Token{RingSelector: "env=dev"}
+	require.True(t, token.AllowsRing(dev))
+	require.False(t, token.AllowsRing(prod))
+
+	token = &Token{RingIDs: prod.ID, RingSelector: "env=dev"}
+	require.True(t, token.AllowsRing(dev))
+	require.True(t, token.AllowsRing(prod))
+
+	token = &Token{RingSelector: "invalid"}
+	require.False(t, token.AllowsRing(dev))
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The validators below check the values of API requests. They are used by
+// the server to reject invalid requests, and can be used by clients to
+// report the same errors before sending a request.
+
+// MaxSoakTime is the longest soak time, in seconds, of a ring, installation
+// group or release.
+const MaxSoakTime = 30 * 24 * 60 * 60
+
+// MaxForceApprovalWindow is the longest time, in seconds, a forced action can
+// wait for the confirmation of a second token.
+const MaxForceApprovalWindow = 24 * 60 * 60
+
+var (
+	nameRegex             = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+	imageRegex            = regexp.MustCompile(`^(?:(?:localhost|[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+)(?::[0-9]+)?/|[A-Za-z0-9-]+:[0-9]+/)?[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	versionRegex          = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	jiraProjectRegex      = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,254}$`)
+	ownerTeamRegex        = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _.-]{0,63}$`)
+	slackChannelRegex     = regexp.MustCompile(`^#[a-z0-9][a-z0-9_-]{0,79}$`)
+	escalationPolicyRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+	languageRegex         = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+)
+
+// ValidateName validates the name of a ring or installation group. An empty
+// name is valid.
+func ValidateName(name string) error {
+	if name != "" && !nameRegex.MatchString(name) {
+		return errors.Errorf("invalid name %q: must start with an alphanumeric and contain only alphanumerics, dots, dashes and underscores, up to 64 characters", name)
+	}
+	return nil
+}
+
+// ValidateSoakTime validates a soak time in seconds. A zero soak time is
+// valid and stands for the default soak time.
+func ValidateSoakTime(soakTime int) error {
+	if soakTime < 0 {
+		return errors.New("soak time cannot be negative")
+	}
+	if soakTime > MaxSoakTime {
+		return errors.Errorf("soak time cannot be longer than %d seconds", MaxSoakTime)
+	}
+	return nil
+}
+
+// ValidateForceApprovalWindow validates the force approval window of a ring,
+// in seconds. A zero window is valid and disables the two-person rule.
+func ValidateForceApprovalWindow(window int) error {
+	if window < 0 {
+		return errors.New("force approval window cannot be negative")
+	}
+	if window > MaxForceApprovalWindow {
+		return errors.Errorf("force approval window cannot be longer than %d seconds", MaxForceApprovalWindow)
+	}
+	return nil
+}
+
+// ValidateImage validates a container image repository, without its tag,
+// such as mattermost/mattermost-enterprise-edition. An empty image is valid.
+func ValidateImage(image string) error {
+	if image != "" && !imageRegex.MatchString(image) {
+		return errors.Errorf("invalid image %q: must be an image repository without a tag", image)
+	}
+	return nil
+}
+
+// ValidateVersion validates a version, which is the tag of the image. An
+// empty version is valid.
+func ValidateVersion(version string) error {
+	if version != "" && !versionRegex.MatchString(version) {
+		return errors.Errorf("invalid version %q: must be a valid image tag", version)
+	}
+	return nil
+}
+
+// ValidateRelease validates the image, version and soak time of a release.
+func ValidateRelease(image, version string, soakTime int) error {
+	if err := ValidateImage(image); err != nil {
+		return err
+	}
+	if err := ValidateVersion(version); err != nil {
+		return err
+	}
+	return ValidateSoakTime(soakTime)
+}
+
+// ValidateJiraProject validates a Jira project key. An empty key is valid and
+// disables Jira issues.
+func ValidateJiraProject(project string) error {
+	if project != "" && !jiraProjectRegex.MatchString(project) {
+		return errors.Errorf("invalid Jira project key %q", project)
+	}
+	return nil
+}
+
+// ValidateLanguage validates a language tag, such as fr or pt-BR. An empty
+// language is valid and is the default language.
+func ValidateLanguage(language string) error {
+	if language != "" && !languageRegex.MatchString(language) {
+		return errors.Errorf("invalid language %q: must be a language tag such as fr or pt-BR", language)
+	}
+	return nil
+}
+
+// ValidateRingContacts validates the owner team, Slack channel and
+// escalation policy of a ring. Each of them is optional.
+func ValidateRingContacts(ownerTeam, slackChannel, escalationPolicy string) error {
+	if ownerTeam != "" && !ownerTeamRegex.MatchString(ownerTeam) {
+		return errors.Errorf("invalid owner team %q: must be alphanumerics, spaces, dots, dashes and underscores, up to 64 characters", ownerTeam)
+	}
+	if slackChannel != "" && !slackChannelRegex.MatchString(slackChannel) {
+		return errors.Errorf("invalid Slack channel %q: must be a channel name starting with #", slackChannel)
+	}
+	if escalationPolicy != "" && !escalationPolicyRegex.MatchString(escalationPolicy) {
+		if _, err := url.ParseRequestURI(escalationPolicy); err != nil || !strings.HasPrefix(escalationPolicy, "https://") {
+			return errors.Errorf("invalid escalation policy %q: must be an ID or an https URL", escalationPolicy)
+		}
+	}
+	return nil
+}
+
+// ValidateInstallationGroupPolicy validates an installation group policy. An
+// empty policy is valid and is the default policy.
+func ValidateInstallationGroupPolicy(policy string) error {
+	switch policy {
+	case "", InstallationGroupPolicyQueue, InstallationGroupPolicyJoin:
+		return nil
+	}
+
+	return errors.Errorf("invalid installation group policy %q: must be %s or %s", policy, InstallationGroupPolicyQueue, InstallationGroupPolicyJoin)
+}
+
+// ValidateFailurePolicy validates a ring failure policy. An empty policy is
+// valid and is the default policy.
+func ValidateFailurePolicy(policy string) error {
+	switch policy {
+	case "", FailurePolicyHalt, FailurePolicyRollbackRing, FailurePolicyRollbackInstallationGroup, FailurePolicyContinue:
+		return nil
+	}
+
+	return errors.Errorf("invalid failure policy %q: must be %s, %s, %s or %s", policy, FailurePolicyHalt, FailurePolicyRollbackRing, FailurePolicyRollbackInstallationGroup, FailurePolicyContinue)
+}
+
+// ValidateAutoRollback validates that auto rollback is not enabled along with
+// a failure policy other than FailurePolicyRollbackRing, which would take
+// precedence over it.
+func ValidateAutoRollback(autoRollback bool, policy string) error {
+	if autoRollback && policy != "" && policy != FailurePolicyRollbackRing {
+		return errors.Errorf("auto rollback cannot be enabled with the %s failure policy", policy)
+	}
+
+	return nil
+}
+
+// ValidateReleaseStrategy validates an installation group release strategy.
+// An empty strategy is valid and is the default strategy.
+func ValidateReleaseStrategy(strategy string) error {
+	switch strategy {
+	case "", ReleaseStrategyRolling, ReleaseStrategyBlueGreen:
+		return nil
+	}
+
+	return errors.Errorf("invalid release strategy %q: must be %s or %s", strategy, ReleaseStrategyRolling, ReleaseStrategyBlueGreen)
+}
+
+// ValidateRingTransition validates that a ring in the current state can be
+// put in the new state through the API.
+func ValidateRingTransition(currentState, newState string) error {
+	if !(&Ring{State: currentState}).ValidTransitionState(newState) {
+		return errors.Errorf("ring cannot transition from %s to %s", currentState, newState)
+	}
+	return nil
+}
+
+// ValidateInstallationGroupTransition validates that an installation group in
+// the current state can be put in the new state through the API.
+func ValidateInstallationGroupTransition(currentState, newState string) error {
+	if !(&InstallationGroup{State: currentState}).ValidInstallationGroupTransitionState(newState) {
+		return errors.Errorf("installation group cannot transition from %s to %s", currentState, newState)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/elrond/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"":                      true,
+		"production":            true,
+		"eu-west-1.canary_2":    true,
+		"-production":           false,
+		"production eu":         false,
+		strings.Repeat("a", 64): true,
+		strings.Repeat("a", 65): false,
+		"production/eu":         false,
+		"production\n":          false,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, valid, model.ValidateName(name) == nil)
+		})
+	}
+}
+
+func TestValidateSoakTime(t *testing.T) {
+	assert.NoError(t, model.ValidateSoakTime(0))
+	assert.NoError(t, model.ValidateSoakTime(model.MaxSoakTime))
+	assert.EqualError(t, model.ValidateSoakTime(-1), "soak time cannot be negative")
+	assert.Error(t, model.ValidateSoakTime(model.MaxSoakTime+1))
+}
+
+func TestValidateForceApprovalWindow(t *testing.T) {
+	assert.NoError(t, model.ValidateForceApprovalWindow(0))
+	assert.NoError(t, model.ValidateForceApprovalWindow(model.MaxForceApprovalWindow))
+	assert.EqualError(t, model.ValidateForceApprovalWindow(-1), "force approval window cannot be negative")
+	assert.Error(t, model.ValidateForceApprovalWindow(model.MaxForceApprovalWindow+1))
+}
+
+func TestValidateReleaseStrategy(t *testing.T) {
+	assert.NoError(t, model.ValidateReleaseStrategy(""))
+	assert.NoError(t, model.ValidateReleaseStrategy(model.ReleaseStrategyRolling))
+	assert.NoError(t, model.ValidateReleaseStrategy(model.ReleaseStrategyBlueGreen))
+	assert.EqualError(t, model.ValidateReleaseStrategy("canary"), `invalid release strategy "canary": must be rolling or blue-green`)
+}
+
+func TestValidateFailurePolicy(t *testing.T) {
+	assert.NoError(t, model.ValidateFailurePolicy(""))
+	assert.NoError(t, model.ValidateFailurePolicy(model.FailurePolicyHalt))
+	assert.NoError(t, model.ValidateFailurePolicy(model.FailurePolicyRollbackRing))
+	assert.NoError(t, model.ValidateFailurePolicy(model.FailurePolicyRollbackInstallationGroup))
+	assert.NoError(t, model.ValidateFailurePolicy(model.FailurePolicyContinue))
+	assert.EqualError(t, model.ValidateFailurePolicy("retry"), `invalid failure policy "retry": must be halt, rollback-ring, rollback-ig-only or continue-remaining-igs`)
+}
+
+func TestValidateAutoRollback(t *testing.T) {
+	assert.NoError(t, model.ValidateAutoRollback(false, model.FailurePolicyContinue))
+	assert.NoError(t, model.ValidateAutoRollback(true, ""))
+	assert.NoError(t, model.ValidateAutoRollback(true, model.FailurePolicyRollbackRing))
+	assert.EqualError(t, model.ValidateAutoRollback(true, model.FailurePolicyHalt), "auto rollback cannot be enabled with the halt failure policy")
+}
+
+func TestValidateImage(t *testing.T) {
+	for image, valid := range map[string]bool{
+		"": true,
+		"mattermost/mattermost-enterprise-edition":                     true,
+		"registry.example.com:5000/mattermost/mattermost-team-edition": true,
+		"mattermost":                     true,
+		"localhost:5000/mattermost":      true,
+		"Mattermost/mattermost":          false,
+		"mattermost/mattermost:9.5.1":    false,
+		"mattermost/mattermost@sha256:0": false,
+		"mattermost//mattermost":         false,
+	} {
+		t.Run(image, func(t *testing.T) {
+			assert.Equal(t, valid, model.ValidateImage(image) == nil)
+		})
+	}
+}
+
+func TestValidateVersion(t *testing.T) {
+	for version, valid := range map[string]bool{
+		"":                       true,
+		"9.5.1":                  true,
+		"9.5.1-rc1":              true,
+		"latest":                 true,
+		".9.5.1":                 false,
+		"9.5.1 ":                 false,
+		"9.5.1:latest":           false,
+		strings.Repeat("1", 129): false,
+	} {
+		t.Run(version, func(t *testing.T) {
+			assert.Equal(t, valid, model.ValidateVersion(version) == nil)
+		})
+	}
+}
+
+func TestValidateRingTransition(t *testing.T) {
+	assert.NoError(t, model.ValidateRingTransition(model.RingStateStable, model.RingStateReleasePending))
+	assert.EqualError(t,
+		model.ValidateRingTransition(model.RingStateDeletionRequested, model.RingStateReleasePending),
+		"ring cannot transition from deletion-requested to release-pending",
+	)
+}
+
+func TestValidateInstallationGroupTransition(t *testing.T) {
+	assert.NoError(t, model.ValidateInstallationGroupTransition(model.InstallationGroupStable, model.InstallationGroupReleasePending))
+	assert.Error(t, model.ValidateInstallationGroupTransition(model.InstallationGroupStable, "unknown"))
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// VersionReport lists the release of every ring and of each of its
+// installation groups.
+type VersionReport struct {
+	GeneratedAt int64
+	Entries     []*VersionReportEntry
+}
+
+// VersionReportEntry is the release of a ring or installation group.
+type VersionReportEntry struct {
+	// Type is either TypeRing or TypeInstallationGroup.
+	Type string
+	ID   string
+	Name string
+	// RingID and RingName identify the ring of an installation group.
+	RingID   string `json:",omitempty"`
+	RingName string `json:",omitempty"`
+	State    string
+	// ActiveImage and ActiveVersion are the release running, and
+	// DesiredImage and DesiredVersion the release being rolled out, if any.
+	ActiveImage    string
+	ActiveVersion  string
+	DesiredImage   string
+	DesiredVersion string
+	// Drifted is set for installation groups last seen running a release
+	// other than the one elrond expects, and for their rings.
+	Drifted bool
+	// ObservedRelease is the image:version a drifted installation group was
+	// last seen running.
+	ObservedRelease string `json:",omitempty"`
+	// LastReleaseAt is the time, in milliseconds, of the last completed
+	// release.
+	LastReleaseAt int64
+	// ActiveLinks and DesiredLinks are the links of the active and desired
+	// releases.
+	ActiveLinks  ReleaseLinks `json:",omitempty"`
+	DesiredLinks ReleaseLinks `json:",omitempty"`
+}
+
+// versionReportCSVHeader is the header row of the CSV version report.
+var versionReportCSVHeader = []string{
+	"type", "id", "name", "ring_id", "ring_name", "state",
+	"active_image", "active_version", "desired_image", "desired_version",
+	"drifted", "observed_release", "last_release_at",
+	"active_links", "desired_links",
+}
+
+// BuildVersionReport builds the version report of the given rings, with
+// their installation groups by ring ID and their releases by ID.
+func BuildVersionReport(rings []*Ring, installationGroups map[string][]*InstallationGroup, releases map[string]*RingRelease, now int64) *VersionReport {
+	report := &VersionReport{GeneratedAt: now, Entries: []*VersionReportEntry{}}
+
+	for _, ring := range rings {
+		active := releases[ring.ActiveReleaseID]
+		if active == nil {
+			active = &RingRelease{}
+		}
+		desired := releases[ring.DesiredReleaseID]
+		if desired == nil {
+			desired = active
+		}
+
+		ringEntry := &VersionReportEntry{
+			Type:           TypeRing,
+			ID:             ring.ID,
+			Name:           ring.Name,
+			State:          ring.State,
+			ActiveImage:    active.Image,
+			ActiveVersion:  active.Version,
+			DesiredImage:   desired.Image,
+			DesiredVersion: desired.Version,
+			LastReleaseAt:  ring.ReleaseAt / 1000000,
+			ActiveLinks:    active.Links,
+			DesiredLinks:   desired.Links,
+		}
+		report.Entries = append(report.Entries, ringEntry)
+
+		for _, installationGroup := range SortInstallationGroups(installationGroups[ring.ID]) {
+			// Installation groups released since the release of the ring
+			// started already run its desired release.
+			running := active
+			if ring.ReleaseStartAt > 0 && installationGroup.ReleaseAt >= ring.ReleaseStartAt && installationGroup.State == InstallationGroupStable {
+				running = desired
+			}
+
+			entry := &VersionReportEntry{
+				Type:           TypeInstallationGroup,
+				ID:             installationGroup.ID,
+				Name:           installationGroup.Name,
+				RingID:         ring.ID,
+				RingName:       ring.Name,
+				State:          installationGroup.State,
+				ActiveImage:    running.Image,
+				ActiveVersion:  running.Version,
+				DesiredImage:   desired.Image,
+				DesiredVersion: desired.Version,
+				Drifted:        installationGroup.Drifted,
+				LastReleaseAt:  installationGroup.ReleaseAt / 1000000,
+				ActiveLinks:    running.Links,
+				DesiredLinks:   desired.Links,
+			}
+			if installationGroup.Drifted {
+				entry.ObservedRelease = installationGroup.ObservedRelease
+				ringEntry.Drifted = true
+			}
+			report.Entries = append(report.Entries, entry)
+		}
+	}
+
+	return report
+}
+
+// CSV returns the version report as CSV, with a header row and one row per
+// ring and installation group.
+func (r *VersionReport) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(versionReportCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, entry := range r.Entries {
+		if err := writer.Write([]string{
+			entry.Type,
+			entry.ID,
+			entry.Name,
+			entry.RingID,
+			entry.RingName,
+			entry.State,
+			entry.ActiveImage,
+			entry.ActiveVersion,
+			entry.DesiredImage,
+			entry.DesiredVersion,
+			strconv.FormatBool(entry.Drifted),
+			entry.ObservedRelease,
+			strconv.FormatInt(entry.LastReleaseAt, 10),
+			entry.ActiveLinks.String(),
+			entry.DesiredLinks.String(),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+
+	return buf.Bytes(), writer.Error()
+}
+
+// VersionReportFromReader decodes a json-encoded version report from the
+// given io.Reader.
+func VersionReportFromReader(reader io.Reader) (*VersionReport, error) {
+	report := &VersionReport{}
+	err := json.NewDecoder(reader).Decode(report)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return report, nil
+}
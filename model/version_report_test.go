@@ -0,0 +1,65 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildVersionReport(t *testing.T) {
+	releases := map[string]*RingRelease{
+		"previous": {ID: "previous", Image: "mattermost/mattermost-enterprise-edition", Version: "9.5.0"},
+		"release":  {ID: "release", Image: "mattermost/mattermost-enterprise-edition", Version: "9.5.1", Links: ReleaseLinks{{Type: ReleaseLinkTypeChangeTicket, URL: "https://tickets.example.com/CHG-1"}}},
+	}
+	links := releases["release"].Links
+	rings := []*Ring{
+		{ID: "ring1", Name: "production", State: RingStateReleaseInProgress, ActiveReleaseID: "previous", DesiredReleaseID: "release", ReleaseAt: 1000000000, ReleaseStartAt: 5000000000},
+	}
+	installationGroups := map[string][]*InstallationGroup{
+		"ring1": {
+			{ID: "group2", Name: "group2", State: InstallationGroupReleaseRequested, ReleaseAt: 1000000000},
+			{ID: "group1", Name: "group1", State: InstallationGroupStable, ReleaseAt: 6000000000, Drifted: true, ObservedRelease: "mattermost/mattermost-enterprise-edition:9.4.0"},
+		},
+	}
+
+	report := BuildVersionReport(rings, installationGroups, releases, 7000)
+	require.Equal(t, int64(7000), report.GeneratedAt)
+	require.Equal(t, []*VersionReportEntry{
+		{
+			Type: TypeRing, ID: "ring1", Name: "production", State: RingStateReleaseInProgress,
+			ActiveImage: "mattermost/mattermost-enterprise-edition", ActiveVersion: "9.5.0",
+			DesiredImage: "mattermost/mattermost-enterprise-edition", DesiredVersion: "9.5.1",
+			Drifted: true, LastReleaseAt: 1000, DesiredLinks: links,
+		},
+		{
+			Type: TypeInstallationGroup, ID: "group1", Name: "group1", RingID: "ring1", RingName: "production", State: InstallationGroupStable,
+			ActiveImage: "mattermost/mattermost-enterprise-edition", ActiveVersion: "9.5.1",
+			DesiredImage: "mattermost/mattermost-enterprise-edition", DesiredVersion: "9.5.1",
+			Drifted: true, ObservedRelease: "mattermost/mattermost-enterprise-edition:9.4.0", LastReleaseAt: 6000,
+			ActiveLinks: links, DesiredLinks: links,
+		},
+		{
+			Type: TypeInstallationGroup, ID: "group2", Name: "group2", RingID: "ring1", RingName: "production", State: InstallationGroupReleaseRequested,
+			ActiveImage: "mattermost/mattermost-enterprise-edition", ActiveVersion: "9.5.0",
+			DesiredImage: "mattermost/mattermost-enterprise-edition", DesiredVersion: "9.5.1",
+			LastReleaseAt: 1000, DesiredLinks: links,
+		},
+	}, report.Entries)
+}
+
+func TestVersionReportCSV(t *testing.T) {
+	report := &VersionReport{
+		Entries: []*VersionReportEntry{
+			{Type: TypeRing, ID: "ring1", Name: "production, eu", State: RingStateStable, ActiveImage: "image", ActiveVersion: "1.0.0", DesiredImage: "image", DesiredVersion: "1.0.0", LastReleaseAt: 1000},
+		},
+	}
+
+	data, err := report.CSV()
+	require.NoError(t, err)
+	require.Equal(t, "type,id,name,ring_id,ring_name,state,active_image,active_version,desired_image,desired_version,drifted,observed_release,last_release_at,active_links,desired_links\n"+
+		"ring,ring1,\"production, eu\",,,stable,image,1.0.0,image,1.0.0,false,,1000,,\n", string(data))
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultWatchInterval is how often watches poll the state change events
+	// stream for new events.
+	DefaultWatchInterval = 5 * time.Second
+	// maxWatchRetryInterval caps the backoff of a watch failing to reach the
+	// server.
+	maxWatchRetryInterval = time.Minute
+	// watchPerPage is the number of events a watch requests at once.
+	watchPerPage = 100
+)
+
+// RingWatch follows the state change events of a ring and its installation
+// groups. See Client.WatchRing.
+type RingWatch struct {
+	// Events receives the events in order, and is closed once the watch
+	// stops. See Err.
+	Events <-chan *StateChangeEvent
+
+	lock   sync.Mutex
+	cursor string
+	err    error
+}
+
+// Cursor returns the cursor following the last event received, to resume the
+// watch from with Client.WatchRingAfter.
+func (w *RingWatch) Cursor() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.cursor
+}
+
+// Err returns why the watch stopped once Events is closed: the error of the
+// context, or the ring not being found.
+func (w *RingWatch) Err() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.err
+}
+
+// WithWatchInterval returns a copy of the client whose watches poll for new
+// events at the given interval.
+func (c *Client) WithWatchInterval(interval time.Duration) *Client {
+	client := *c
+	client.watchInterval = interval
+
+	return &client
+}
+
+// WatchRing watches the state change events of the given ring and its
+// installation groups from now on, until the context is done. Failures to
+// reach the server are retried with a backoff, resuming after the last event
+// received, so no event is missed.
+func (c *Client) WatchRing(ctx context.Context, ringID string) (*RingWatch, error) {
+	// Skip the past events to start from the end of the stream.
+	after := ""
+	for {
+		page, err := c.GetRingStateChangeEvents(ringID, &GetStateChangeEventsRequest{After: after, PerPage: watchPerPage})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get ring events")
+		}
+		after = page.After
+		if len(page.Events) < watchPerPage {
+			break
+		}
+	}
+
+	return c.WatchRingAfter(ctx, ringID, after)
+}
+
+// WatchRingAfter watches the state change events of the given ring and its
+// installation groups following the given cursor, such as the cursor of a
+// previous watch, until the context is done. An empty cursor starts from the
+// first event of the ring.
+func (c *Client) WatchRingAfter(ctx context.Context, ringID, after string) (*RingWatch, error) {
+	page, err := c.GetRingStateChangeEvents(ringID, &GetStateChangeEventsRequest{After: after, PerPage: watchPerPage})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ring events")
+	}
+
+	events := make(chan *StateChangeEvent)
+	watch := &RingWatch{Events: events, cursor: after}
+	go c.watchRing(ctx, ringID, watch, events, page)
+
+	return watch, nil
+}
+
+// watchRing sends the events of the given first page and of the following
+// ones to the watch until the context is done or the ring is not found.
+func (c *Client) watchRing(ctx context.Context, ringID string, watch *RingWatch, events chan<- *StateChangeEvent, page *StateChangeEventsPage) {
+	defer close(events)
+	stop := func(err error) {
+		watch.lock.Lock()
+		watch.err = err
+		watch.lock.Unlock()
+	}
+
+	interval := c.watchInterval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	retryInterval := interval
+
+	for {
+		for _, event := range page.Events {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				stop(ctx.Err())
+				return
+			}
+			watch.lock.Lock()
+			watch.cursor = event.Cursor()
+			watch.lock.Unlock()
+		}
+
+		wait := interval
+		if len(page.Events) == watchPerPage {
+			wait = 0
+		}
+		for {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				stop(ctx.Err())
+				return
+			}
+
+			var err error
+			page, err = c.GetRingStateChangeEvents(ringID, &GetStateChangeEventsRequest{After: watch.Cursor(), PerPage: watchPerPage})
+			if err == nil {
+				retryInterval = interval
+				break
+			}
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+				stop(errors.Errorf("ring %s not found", ringID))
+				return
+			}
+
+			// Reconnect with a backoff, resuming from the cursor.
+			wait = retryInterval
+			retryInterval *= 2
+			if retryInterval > maxWatchRetryInterval {
+				retryInterval = maxWatchRetryInterval
+			}
+		}
+	}
+}
+
+// WaitForRingState waits until the given ring reaches the given state, or the
+// context is done, and returns the ring as of then.
+func (c *Client) WaitForRingState(ctx context.Context, ringID, state string) (*Ring, error) {
+	// Watch before checking the ring, so a transition in between is not
+	// missed.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	watch, err := c.WatchRing(ctx, ringID)
+	if err != nil {
+		return nil, err
+	}
+
+	ring, err := c.GetRing(ringID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ring")
+	}
+	if ring == nil {
+		return nil, errors.Errorf("ring %s not found", ringID)
+	}
+	if ring.State == state {
+		return ring, nil
+	}
+
+	for event := range watch.Events {
+		if event.ResourceType != TypeRing || event.ResourceID != ringID || event.NewState != state {
+			continue
+		}
+		ring, err = c.GetRing(ringID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get ring")
+		}
+		if ring == nil {
+			return nil, errors.Errorf("ring %s not found", ringID)
+		}
+		return ring, nil
+	}
+
+	return nil, watch.Err()
+}
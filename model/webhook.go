@@ -7,20 +7,87 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 const (
 	// TypeRing is the string value that represents a ring
 	TypeRing = "ring"
+	// TypeDigest is the string value that represents a batch of webhook payloads
+	TypeDigest = "digest"
+	// TypeBatch is the string value that represents a burst of webhook
+	// payloads sent together
+	TypeBatch = "batch"
+	// TypeWebhook is the string value that represents a webhook
+	TypeWebhook = "webhook"
+	// TypeRollout is the string value that represents a rollout
+	TypeRollout = "rollout"
+	// TypeSuppressed is the string value that represents a summary of the
+	// payloads suppressed by the rate limit of a webhook
+	TypeSuppressed = "suppressed"
+
+	// WebhookFormatElrond is the format of webhooks receiving the raw JSON
+	// payload.
+	WebhookFormatElrond = "elrond"
+	// WebhookFormatTeams is the format of Microsoft Teams incoming webhooks,
+	// receiving the payload as an Adaptive Card.
+	WebhookFormatTeams = "teams"
 )
 
+// MinWebhookMaxPayloadSize is the smallest maximum payload size of a
+// webhook, leaving room for the truncated payloads to keep their shape.
+const MinWebhookMaxPayloadSize = 1024
+
+// ValidWebhookFormat returns whether the given webhook format is supported.
+func ValidWebhookFormat(format string) bool {
+	switch format {
+	case WebhookFormatElrond, WebhookFormatTeams:
+		return true
+	}
+	return false
+}
+
 // Webhook represents a elrond webhook
 type Webhook struct {
-	ID       string
-	OwnerID  string
-	URL      string
-	CreateAt int64
-	DeleteAt int64
+	ID      string
+	OwnerID string
+	URL     string
+	Format  string
+	// Language is the language of the Teams cards sent to the webhook, when
+	// a notification template of that language exists. See
+	// NotificationTemplate.
+	Language string `json:",omitempty"`
+	// LabelSelector restricts the webhook to the payloads of resources whose
+	// ring annotations match it, such as "env=prod". An empty selector
+	// matches every payload.
+	LabelSelector string `json:",omitempty"`
+	// Secret signs the payloads sent to the webhook. It is never returned
+	// by the API.
+	Secret string `json:"-"`
+	// Signed is whether the payloads sent to the webhook are signed with a
+	// secret.
+	Signed bool `json:",omitempty"`
+	// RateLimit is the maximum number of payloads sent to the webhook per
+	// minute. The payloads over the limit are suppressed and summarized once
+	// the minute is over. Failures are always sent. 0 means no limit.
+	RateLimit int `json:",omitempty"`
+	// Timeout is the time, in seconds, a request to the webhook may take.
+	// 0 uses the timeout of the server.
+	Timeout int `json:",omitempty"`
+	// MaxRedirects is the maximum number of redirects followed when sending
+	// to the webhook. 0 uses the limit of the server, and -1 follows none.
+	MaxRedirects int `json:",omitempty"`
+	// MaxPayloadSize is the maximum size, in bytes, of the payloads sent to
+	// the webhook. Larger payloads are truncated and flagged as such. 0 uses
+	// the limit of the server.
+	MaxPayloadSize int `json:",omitempty"`
+	CreateAt       int64
+	DeleteAt       int64
 }
 
 // WebhookFilter describes the parameters used to constrain a set of webhooks.
@@ -39,6 +106,82 @@ type WebhookPayload struct {
 	NewState  string            `json:"new_state"`
 	OldState  string            `json:"old_state"`
 	ExtraData map[string]string `json:"extra_data,omitempty"`
+	// RingID is the ID of the ring an installation group belongs to, set
+	// on the events of installation groups.
+	RingID string `json:"ring_id,omitempty"`
+	// Labels are the annotations of the ring the resource belongs to,
+	// matched against the label selector of each webhook.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Metadata is the metadata of the resource, and RingMetadata that of the
+	// ring an installation group belongs to, included verbatim.
+	Metadata     Metadata `json:"metadata,omitempty"`
+	RingMetadata Metadata `json:"ring_metadata,omitempty"`
+}
+
+// WebhookDigestPayload is the payload sent when non-critical events are
+// batched together by the webhook digest mode.
+type WebhookDigestPayload struct {
+	Timestamp int64             `json:"timestamp"`
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	Events    []*WebhookPayload `json:"events"`
+}
+
+// WebhookBatchPayload is the payload sent when the events of a burst, such as
+// the installation group transitions following a ring transition, are sent
+// together by the webhook batch mode.
+type WebhookBatchPayload struct {
+	Timestamp int64             `json:"timestamp"`
+	Type      string            `json:"type"`
+	Events    []*WebhookPayload `json:"events"`
+}
+
+// WebhookReplay describes the historical events re-delivered to a webhook.
+type WebhookReplay struct {
+	WebhookID string
+	// From and To are the times, in milliseconds, the replayed events
+	// occurred between.
+	From int64
+	To   int64
+	// Events is the number of events queued for delivery, that is those
+	// matching the label selector of the webhook.
+	Events int
+}
+
+// ReplayWebhookRequest describes the historical events to re-deliver to a
+// webhook. A zero To replays the events up to now.
+type ReplayWebhookRequest struct {
+	From int64
+	To   int64
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *ReplayWebhookRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	q.Add("from", strconv.FormatInt(request.From, 10))
+	if request.To != 0 {
+		q.Add("to", strconv.FormatInt(request.To, 10))
+	}
+	u.RawQuery = q.Encode()
+}
+
+// ValidateWebhookLimits validates the timeout, redirect and payload size
+// limits of a webhook.
+func ValidateWebhookLimits(timeout, maxRedirects, maxPayloadSize int) error {
+	if timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	if maxRedirects < -1 {
+		return errors.New("max redirects must be -1 to follow none, 0 for the server default, or positive")
+	}
+	if maxPayloadSize < 0 {
+		return errors.New("max payload size cannot be negative")
+	}
+	if maxPayloadSize > 0 && maxPayloadSize < MinWebhookMaxPayloadSize {
+		return errors.Errorf("max payload size must be at least %d bytes", MinWebhookMaxPayloadSize)
+	}
+
+	return nil
 }
 
 // IsDeleted returns whether the webhook was marked as deleted or not.
@@ -46,6 +189,25 @@ func (w *Webhook) IsDeleted() bool {
 	return w.DeleteAt != 0
 }
 
+// Matches returns whether the given payload is to be sent to the webhook,
+// according to its label selector. An invalid selector matches nothing.
+func (w *Webhook) Matches(payload *WebhookPayload) bool {
+	if w.LabelSelector == "" {
+		return true
+	}
+	selector, err := ParseLabelSelector(w.LabelSelector)
+	if err != nil {
+		return false
+	}
+
+	var labels map[string]string
+	if payload != nil {
+		labels = payload.Labels
+	}
+
+	return selector.Matches(labels)
+}
+
 // ToJSON returns a JSON string representation of the webhook payload.
 func (p *WebhookPayload) ToJSON() (string, error) {
 	b, err := json.Marshal(p)
@@ -56,6 +218,52 @@ func (p *WebhookPayload) ToJSON() (string, error) {
 	return string(b), nil
 }
 
+// IsFailure returns whether the payload reports a transition into a failed state.
+func (p *WebhookPayload) IsFailure() bool {
+	return strings.HasSuffix(p.NewState, "-failed")
+}
+
+// TemplateData returns the data the notification templates of the payload
+// are rendered with.
+func (p *WebhookPayload) TemplateData() *NotificationTemplateData {
+	data := &NotificationTemplateData{
+		Event:     NotificationStateChange,
+		Type:      p.Type,
+		ID:        p.ID,
+		RingID:    p.ExtraData["RingID"],
+		OldState:  p.OldState,
+		NewState:  p.NewState,
+		Time:      time.Unix(0, p.Timestamp).UTC().Format(time.RFC3339),
+		Rehearsal: p.ExtraData["Rehearsal"] == "true",
+		ExtraData: p.ExtraData,
+	}
+	if p.Type == TypeRing {
+		data.RingID = p.ID
+	}
+
+	return data
+}
+
+// ToJSON returns a JSON string representation of the webhook digest payload.
+func (p *WebhookDigestPayload) ToJSON() (string, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// ToJSON returns a JSON string representation of the webhook batch payload.
+func (p *WebhookBatchPayload) ToJSON() (string, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
 // WebhookFromReader decodes a json-encoded webhook from the given io.Reader.
 func WebhookFromReader(reader io.Reader) (*Webhook, error) {
 	webhook := Webhook{}
@@ -92,3 +300,16 @@ func WebhookPayloadFromReader(reader io.Reader) (*WebhookPayload, error) {
 
 	return &payload, nil
 }
+
+// WebhookReplayFromReader decodes a json-encoded webhook replay from the
+// given io.Reader.
+func WebhookReplayFromReader(reader io.Reader) (*WebhookReplay, error) {
+	replay := WebhookReplay{}
+	decoder := json.NewDecoder(reader)
+	err := decoder.Decode(&replay)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &replay, nil
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+const (
+	// WebhookDeliveryPending is a delivery yet to be made, or to be retried.
+	WebhookDeliveryPending = "pending"
+	// WebhookDeliveryDelivered is a delivery accepted by the webhook.
+	WebhookDeliveryDelivered = "delivered"
+	// WebhookDeliveryFailed is a delivery that failed every attempt.
+	WebhookDeliveryFailed = "failed"
+
+	// WebhookSignatureHeader is the header carrying the HMAC-SHA256 signature
+	// of the payload, for webhooks with a secret.
+	WebhookSignatureHeader = "X-Elrond-Signature"
+	// WebhookDeliveryHeader is the header carrying the ID of the delivery, the
+	// same for every attempt, so that receivers can ignore redeliveries.
+	WebhookDeliveryHeader = "X-Elrond-Delivery"
+)
+
+// WebhookDelivery is a payload to deliver to a webhook, retried until the
+// webhook accepts it or the attempts run out.
+type WebhookDelivery struct {
+	ID        string
+	WebhookID string
+	// Payload is the body sent to the webhook.
+	Payload        string
+	State          string
+	Attempts       int
+	LastStatusCode int
+	LastError      string
+	// NextAttemptAt is when the delivery is to be attempted next, in
+	// milliseconds.
+	NextAttemptAt int64
+	LastAttemptAt int64
+	CreateAt      int64
+}
+
+// WebhookDeliveryFilter describes the parameters used to constrain a set of
+// webhook deliveries.
+type WebhookDeliveryFilter struct {
+	WebhookID string
+	State     string
+	Page      int
+	PerPage   int
+}
+
+// GetWebhookDeliveriesRequest describes the parameters to request a list of
+// the deliveries of a webhook.
+type GetWebhookDeliveriesRequest struct {
+	State   string
+	Page    int
+	PerPage int
+}
+
+// ApplyToURL modifies the given url to include query string parameters for the request.
+func (request *GetWebhookDeliveriesRequest) ApplyToURL(u *url.URL) {
+	q := u.Query()
+	if request.State != "" {
+		q.Add("state", request.State)
+	}
+	q.Add("page", strconv.Itoa(request.Page))
+	q.Add("per_page", strconv.Itoa(request.PerPage))
+	u.RawQuery = q.Encode()
+}
+
+// ValidWebhookDeliveryState returns whether the given webhook delivery state
+// is known.
+func ValidWebhookDeliveryState(state string) bool {
+	switch state {
+	case WebhookDeliveryPending, WebhookDeliveryDelivered, WebhookDeliveryFailed:
+		return true
+	}
+	return false
+}
+
+// SignWebhookPayload returns the signature of the given payload with the given
+// secret, as sent in the WebhookSignatureHeader: "sha256=" followed by the
+// hex-encoded HMAC-SHA256 of the payload.
+func SignWebhookPayload(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookDeliveryFromReader decodes a json-encoded webhook delivery from the
+// given io.Reader.
+func WebhookDeliveryFromReader(reader io.Reader) (*WebhookDelivery, error) {
+	delivery := WebhookDelivery{}
+	err := json.NewDecoder(reader).Decode(&delivery)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// WebhookDeliveriesFromReader decodes a json-encoded list of webhook
+// deliveries from the given io.Reader.
+func WebhookDeliveriesFromReader(reader io.Reader) ([]*WebhookDelivery, error) {
+	deliveries := []*WebhookDelivery{}
+	err := json.NewDecoder(reader).Decode(&deliveries)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,17 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	// echo -n '{"id":"ring"}' | openssl dgst -sha256 -hmac secret
+	require.Equal(t, "sha256=f70b096f42cb78c4d76392537c72d44056e1c34c603020e448d3d71572c91a32", SignWebhookPayload("secret", `{"id":"ring"}`))
+	require.NotEqual(t, SignWebhookPayload("secret", `{"id":"ring"}`), SignWebhookPayload("other", `{"id":"ring"}`))
+}
@@ -18,19 +18,63 @@ import (
 type CreateWebhookRequest struct {
 	OwnerID string
 	URL     string
+	// Format is the format of the payloads sent to the webhook, defaulting to
+	// the raw elrond payload.
+	Format string
+	// Language is the language of the Teams cards sent to the webhook,
+	// defaulting to DefaultNotificationLanguage.
+	Language string `json:",omitempty"`
+	// LabelSelector restricts the webhook to the payloads of resources whose
+	// ring annotations match it.
+	LabelSelector string `json:",omitempty"`
+	// Secret, if set, signs the payloads sent to the webhook with
+	// HMAC-SHA256.
+	Secret string `json:",omitempty"`
+	// RateLimit, if set, caps the number of payloads sent to the webhook per
+	// minute.
+	RateLimit int `json:",omitempty"`
+	// Timeout, MaxRedirects and MaxPayloadSize, if set, override the limits
+	// of the server for the requests to the webhook. See Webhook.
+	Timeout        int `json:",omitempty"`
+	MaxRedirects   int `json:",omitempty"`
+	MaxPayloadSize int `json:",omitempty"`
 }
 
 // NewCreateWebhookRequestFromReader will create a CreateWebhookRequest from an io.Reader with JSON data.
-func NewCreateWebhookRequestFromReader(reader io.Reader) (*CreateWebhookRequest, error) {
+// If the owner is omitted, it defaults to the given tenant.
+func NewCreateWebhookRequestFromReader(reader io.Reader, tenantID string) (*CreateWebhookRequest, error) {
 	var createWebhookRequest CreateWebhookRequest
 	err := json.NewDecoder(reader).Decode(&createWebhookRequest)
 	if err != nil && err != io.EOF {
 		return nil, errors.Wrap(err, "failed to decode create webhook request")
 	}
 
+	if createWebhookRequest.OwnerID == "" {
+		createWebhookRequest.OwnerID = tenantID
+	}
 	if createWebhookRequest.OwnerID == "" {
 		return nil, errors.New("must specify owner")
 	}
+	if createWebhookRequest.Format == "" {
+		createWebhookRequest.Format = WebhookFormatElrond
+	}
+	if !ValidWebhookFormat(createWebhookRequest.Format) {
+		return nil, errors.Errorf("unsupported webhook format %q", createWebhookRequest.Format)
+	}
+	if err = ValidateLanguage(createWebhookRequest.Language); err != nil {
+		return nil, err
+	}
+	labelSelector, err := ParseLabelSelector(createWebhookRequest.LabelSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid label selector")
+	}
+	createWebhookRequest.LabelSelector = labelSelector.String()
+	if createWebhookRequest.RateLimit < 0 {
+		return nil, errors.New("rate limit cannot be negative")
+	}
+	if err = ValidateWebhookLimits(createWebhookRequest.Timeout, createWebhookRequest.MaxRedirects, createWebhookRequest.MaxPayloadSize); err != nil {
+		return nil, err
+	}
 	if createWebhookRequest.URL == "" {
 		return nil, errors.New("must specify callback URL")
 	}
@@ -168,3 +168,12 @@ func TestWebhookPayloadFromReader(t *testing.T) {
 		}, payload)
 	})
 }
+
+func TestValidateWebhookLimits(t *testing.T) {
+	require.NoError(t, ValidateWebhookLimits(0, 0, 0))
+	require.NoError(t, ValidateWebhookLimits(30, -1, MinWebhookMaxPayloadSize))
+	require.EqualError(t, ValidateWebhookLimits(-1, 0, 0), "timeout cannot be negative")
+	require.Error(t, ValidateWebhookLimits(0, -2, 0))
+	require.EqualError(t, ValidateWebhookLimits(0, 0, -1), "max payload size cannot be negative")
+	require.EqualError(t, ValidateWebhookLimits(0, 0, 100), "max payload size must be at least 1024 bytes")
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// WorkPositionFront reschedules pending work ahead of all other pending
+	// work of the same type.
+	WorkPositionFront = "front"
+	// WorkPositionBack reschedules pending work behind all other pending
+	// work of the same type.
+	WorkPositionBack = "back"
+)
+
+// RescheduleWorkItem reschedules the pending work of a ring or installation
+// group, either to an explicit work priority or to the front or back of the
+// queue.
+type RescheduleWorkItem struct {
+	// Type is TypeRing or TypeInstallationGroup.
+	Type     string
+	ID       string
+	Priority *int   `json:",omitempty"`
+	Position string `json:",omitempty"`
+}
+
+// RescheduleWorkRequest reschedules pending work items, in order.
+type RescheduleWorkRequest struct {
+	Items []*RescheduleWorkItem
+}
+
+// RescheduledWork is the work priority of a rescheduled work item.
+type RescheduledWork struct {
+	Type         string
+	ID           string
+	State        string
+	WorkPriority int
+}
+
+// Validate validates the values of a reschedule work request.
+func (request *RescheduleWorkRequest) Validate() error {
+	if len(request.Items) == 0 {
+		return errors.New("must specify at least one item")
+	}
+
+	for _, item := range request.Items {
+		if item == nil {
+			return errors.New("items cannot be null")
+		}
+		if item.Type != TypeRing && item.Type != TypeInstallationGroup {
+			return errors.Errorf("unsupported type %q, must be %s or %s", item.Type, TypeRing, TypeInstallationGroup)
+		}
+		if item.ID == "" {
+			return errors.New("must specify the ID of every item")
+		}
+		if (item.Priority == nil) == (item.Position == "") {
+			return errors.Errorf("must specify either a priority or a position for %s %s", item.Type, item.ID)
+		}
+		if item.Position != "" && item.Position != WorkPositionFront && item.Position != WorkPositionBack {
+			return errors.Errorf("unsupported position %q, must be %s or %s", item.Position, WorkPositionFront, WorkPositionBack)
+		}
+	}
+
+	return nil
+}
+
+// NewRescheduleWorkRequestFromReader will create a RescheduleWorkRequest from an io.Reader with JSON data.
+func NewRescheduleWorkRequestFromReader(reader io.Reader) (*RescheduleWorkRequest, error) {
+	var rescheduleWorkRequest RescheduleWorkRequest
+	err := json.NewDecoder(reader).Decode(&rescheduleWorkRequest)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "failed to decode reschedule work request")
+	}
+
+	err = rescheduleWorkRequest.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "reschedule work request failed validation")
+	}
+
+	return &rescheduleWorkRequest, nil
+}
+
+// RescheduledWorkFromReader decodes a json-encoded list of rescheduled work
+// from the given io.Reader.
+func RescheduledWorkFromReader(reader io.Reader) ([]*RescheduledWork, error) {
+	rescheduledWork := []*RescheduledWork{}
+	decoder := json.NewDecoder(reader)
+
+	err := decoder.Decode(&rescheduledWork)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return rescheduledWork, nil
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+//
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRescheduleWorkRequestFromReader(t *testing.T) {
+	t.Run("valid request", func(t *testing.T) {
+		request, err := NewRescheduleWorkRequestFromReader(bytes.NewReader([]byte(
+			`{"Items":[{"Type":"ring","ID":"id1","Position":"back"},{"Type":"installationgroup","ID":"id2","Priority":-5}]}`,
+		)))
+		require.NoError(t, err)
+		require.Len(t, request.Items, 2)
+		require.Equal(t, WorkPositionBack, request.Items[0].Position)
+		require.Equal(t, -5, *request.Items[1].Priority)
+	})
+
+	for _, body := range []string{
+		``,
+		`{"Items":[]}`,
+		`{"Items":[null]}`,
+		`{"Items":[{"Type":"rollout","ID":"id1","Position":"back"}]}`,
+		`{"Items":[{"Type":"ring","Position":"back"}]}`,
+		`{"Items":[{"Type":"ring","ID":"id1"}]}`,
+		`{"Items":[{"Type":"ring","ID":"id1","Position":"back","Priority":1}]}`,
+		`{"Items":[{"Type":"ring","ID":"id1","Position":"middle"}]}`,
+	} {
+		_, err := NewRescheduleWorkRequestFromReader(bytes.NewReader([]byte(body)))
+		require.Error(t, err, body)
+	}
+}